@@ -23,6 +23,168 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type AgentDeltaKind int32
+
+const (
+	AgentDeltaKind_AGENT_DELTA_KIND_UNSPECIFIED AgentDeltaKind = 0
+	AgentDeltaKind_AGENT_DELTA_KIND_ADDED       AgentDeltaKind = 1 // Registered or now matching the watch
+	AgentDeltaKind_AGENT_DELTA_KIND_UPDATED     AgentDeltaKind = 2 // Status, labels, groups, maintenance or circuit changed
+	AgentDeltaKind_AGENT_DELTA_KIND_REMOVED     AgentDeltaKind = 3 // No longer matching the watch; agent as last sent
+	AgentDeltaKind_AGENT_DELTA_KIND_SYNCED      AgentDeltaKind = 4 // Every agent at the start of the watch was sent
+)
+
+// Enum value maps for AgentDeltaKind.
+var (
+	AgentDeltaKind_name = map[int32]string{
+		0: "AGENT_DELTA_KIND_UNSPECIFIED",
+		1: "AGENT_DELTA_KIND_ADDED",
+		2: "AGENT_DELTA_KIND_UPDATED",
+		3: "AGENT_DELTA_KIND_REMOVED",
+		4: "AGENT_DELTA_KIND_SYNCED",
+	}
+	AgentDeltaKind_value = map[string]int32{
+		"AGENT_DELTA_KIND_UNSPECIFIED": 0,
+		"AGENT_DELTA_KIND_ADDED":       1,
+		"AGENT_DELTA_KIND_UPDATED":     2,
+		"AGENT_DELTA_KIND_REMOVED":     3,
+		"AGENT_DELTA_KIND_SYNCED":      4,
+	}
+)
+
+func (x AgentDeltaKind) Enum() *AgentDeltaKind {
+	p := new(AgentDeltaKind)
+	*p = x
+	return p
+}
+
+func (x AgentDeltaKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AgentDeltaKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_agent_proto_enumTypes[0].Descriptor()
+}
+
+func (AgentDeltaKind) Type() protoreflect.EnumType {
+	return &file_api_v1_agent_proto_enumTypes[0]
+}
+
+func (x AgentDeltaKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AgentDeltaKind.Descriptor instead.
+func (AgentDeltaKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{0}
+}
+
+type ApprovalState int32
+
+const (
+	ApprovalState_APPROVAL_STATE_UNKNOWN  ApprovalState = 0
+	ApprovalState_APPROVAL_STATE_PENDING  ApprovalState = 1
+	ApprovalState_APPROVAL_STATE_APPROVED ApprovalState = 2
+	ApprovalState_APPROVAL_STATE_REJECTED ApprovalState = 3
+	ApprovalState_APPROVAL_STATE_USED     ApprovalState = 4
+	ApprovalState_APPROVAL_STATE_EXPIRED  ApprovalState = 5
+)
+
+// Enum value maps for ApprovalState.
+var (
+	ApprovalState_name = map[int32]string{
+		0: "APPROVAL_STATE_UNKNOWN",
+		1: "APPROVAL_STATE_PENDING",
+		2: "APPROVAL_STATE_APPROVED",
+		3: "APPROVAL_STATE_REJECTED",
+		4: "APPROVAL_STATE_USED",
+		5: "APPROVAL_STATE_EXPIRED",
+	}
+	ApprovalState_value = map[string]int32{
+		"APPROVAL_STATE_UNKNOWN":  0,
+		"APPROVAL_STATE_PENDING":  1,
+		"APPROVAL_STATE_APPROVED": 2,
+		"APPROVAL_STATE_REJECTED": 3,
+		"APPROVAL_STATE_USED":     4,
+		"APPROVAL_STATE_EXPIRED":  5,
+	}
+)
+
+func (x ApprovalState) Enum() *ApprovalState {
+	p := new(ApprovalState)
+	*p = x
+	return p
+}
+
+func (x ApprovalState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ApprovalState) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_agent_proto_enumTypes[1].Descriptor()
+}
+
+func (ApprovalState) Type() protoreflect.EnumType {
+	return &file_api_v1_agent_proto_enumTypes[1]
+}
+
+func (x ApprovalState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ApprovalState.Descriptor instead.
+func (ApprovalState) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{1}
+}
+
+type CheckStatus int32
+
+const (
+	CheckStatus_CHECK_STATUS_OK   CheckStatus = 0
+	CheckStatus_CHECK_STATUS_WARN CheckStatus = 1
+	CheckStatus_CHECK_STATUS_FAIL CheckStatus = 2
+)
+
+// Enum value maps for CheckStatus.
+var (
+	CheckStatus_name = map[int32]string{
+		0: "CHECK_STATUS_OK",
+		1: "CHECK_STATUS_WARN",
+		2: "CHECK_STATUS_FAIL",
+	}
+	CheckStatus_value = map[string]int32{
+		"CHECK_STATUS_OK":   0,
+		"CHECK_STATUS_WARN": 1,
+		"CHECK_STATUS_FAIL": 2,
+	}
+)
+
+func (x CheckStatus) Enum() *CheckStatus {
+	p := new(CheckStatus)
+	*p = x
+	return p
+}
+
+func (x CheckStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CheckStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_agent_proto_enumTypes[2].Descriptor()
+}
+
+func (CheckStatus) Type() protoreflect.EnumType {
+	return &file_api_v1_agent_proto_enumTypes[2]
+}
+
+func (x CheckStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CheckStatus.Descriptor instead.
+func (CheckStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{2}
+}
+
 type StackState int32
 
 const (
@@ -62,11 +224,11 @@ func (x StackState) String() string {
 }
 
 func (StackState) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_v1_agent_proto_enumTypes[0].Descriptor()
+	return file_api_v1_agent_proto_enumTypes[3].Descriptor()
 }
 
 func (StackState) Type() protoreflect.EnumType {
-	return &file_api_v1_agent_proto_enumTypes[0]
+	return &file_api_v1_agent_proto_enumTypes[3]
 }
 
 func (x StackState) Number() protoreflect.EnumNumber {
@@ -75,7 +237,59 @@ func (x StackState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use StackState.Descriptor instead.
 func (StackState) EnumDescriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{0}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{3}
+}
+
+type OrphanKind int32
+
+const (
+	OrphanKind_ORPHAN_KIND_UNSPECIFIED OrphanKind = 0
+	OrphanKind_ORPHAN_KIND_STACK_DIR   OrphanKind = 1 // Stack directory without containers
+	OrphanKind_ORPHAN_KIND_CONTAINERS  OrphanKind = 2 // Containers of a stack with no directory
+	OrphanKind_ORPHAN_KIND_STALE_FILE  OrphanKind = 3 // Backup or .env file left in a stack
+)
+
+// Enum value maps for OrphanKind.
+var (
+	OrphanKind_name = map[int32]string{
+		0: "ORPHAN_KIND_UNSPECIFIED",
+		1: "ORPHAN_KIND_STACK_DIR",
+		2: "ORPHAN_KIND_CONTAINERS",
+		3: "ORPHAN_KIND_STALE_FILE",
+	}
+	OrphanKind_value = map[string]int32{
+		"ORPHAN_KIND_UNSPECIFIED": 0,
+		"ORPHAN_KIND_STACK_DIR":   1,
+		"ORPHAN_KIND_CONTAINERS":  2,
+		"ORPHAN_KIND_STALE_FILE":  3,
+	}
+)
+
+func (x OrphanKind) Enum() *OrphanKind {
+	p := new(OrphanKind)
+	*p = x
+	return p
+}
+
+func (x OrphanKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrphanKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_agent_proto_enumTypes[4].Descriptor()
+}
+
+func (OrphanKind) Type() protoreflect.EnumType {
+	return &file_api_v1_agent_proto_enumTypes[4]
+}
+
+func (x OrphanKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrphanKind.Descriptor instead.
+func (OrphanKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{4}
 }
 
 type DiffAction int32
@@ -114,11 +328,11 @@ func (x DiffAction) String() string {
 }
 
 func (DiffAction) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_v1_agent_proto_enumTypes[1].Descriptor()
+	return file_api_v1_agent_proto_enumTypes[5].Descriptor()
 }
 
 func (DiffAction) Type() protoreflect.EnumType {
-	return &file_api_v1_agent_proto_enumTypes[1]
+	return &file_api_v1_agent_proto_enumTypes[5]
 }
 
 func (x DiffAction) Number() protoreflect.EnumNumber {
@@ -127,7 +341,56 @@ func (x DiffAction) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use DiffAction.Descriptor instead.
 func (DiffAction) EnumDescriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{1}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{5}
+}
+
+type TransferKind int32
+
+const (
+	TransferKind_TRANSFER_KIND_UNSPECIFIED  TransferKind = 0
+	TransferKind_TRANSFER_KIND_FILE         TransferKind = 1 // Placed at path in the stack directory
+	TransferKind_TRANSFER_KIND_IMAGE_BUNDLE TransferKind = 2 // Loaded into Docker, as by docker load
+)
+
+// Enum value maps for TransferKind.
+var (
+	TransferKind_name = map[int32]string{
+		0: "TRANSFER_KIND_UNSPECIFIED",
+		1: "TRANSFER_KIND_FILE",
+		2: "TRANSFER_KIND_IMAGE_BUNDLE",
+	}
+	TransferKind_value = map[string]int32{
+		"TRANSFER_KIND_UNSPECIFIED":  0,
+		"TRANSFER_KIND_FILE":         1,
+		"TRANSFER_KIND_IMAGE_BUNDLE": 2,
+	}
+)
+
+func (x TransferKind) Enum() *TransferKind {
+	p := new(TransferKind)
+	*p = x
+	return p
+}
+
+func (x TransferKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransferKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_agent_proto_enumTypes[6].Descriptor()
+}
+
+func (TransferKind) Type() protoreflect.EnumType {
+	return &file_api_v1_agent_proto_enumTypes[6]
+}
+
+func (x TransferKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransferKind.Descriptor instead.
+func (TransferKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{6}
 }
 
 type OperationState int32
@@ -169,11 +432,11 @@ func (x OperationState) String() string {
 }
 
 func (OperationState) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_v1_agent_proto_enumTypes[2].Descriptor()
+	return file_api_v1_agent_proto_enumTypes[7].Descriptor()
 }
 
 func (OperationState) Type() protoreflect.EnumType {
-	return &file_api_v1_agent_proto_enumTypes[2]
+	return &file_api_v1_agent_proto_enumTypes[7]
 }
 
 func (x OperationState) Number() protoreflect.EnumNumber {
@@ -182,11 +445,13 @@ func (x OperationState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use OperationState.Descriptor instead.
 func (OperationState) EnumDescriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{2}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{7}
 }
 
 type ListAgentsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Only return agents matching all labels
+	Group         string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`                                                                             // Only return members of this group
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -221,6 +486,20 @@ func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *ListAgentsRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListAgentsRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
 type ListAgentsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Agents        []*Agent               `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
@@ -265,32 +544,28 @@ func (x *ListAgentsResponse) GetAgents() []*Agent {
 	return nil
 }
 
-type Agent struct {
+type WatchAgentsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Capabilities  []string               `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
-	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Only watch agents matching all labels
+	Group         string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`                                                                             // Only watch members of this group
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Agent) Reset() {
-	*x = Agent{}
+func (x *WatchAgentsRequest) Reset() {
+	*x = WatchAgentsRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Agent) String() string {
+func (x *WatchAgentsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Agent) ProtoMessage() {}
+func (*WatchAgentsRequest) ProtoMessage() {}
 
-func (x *Agent) ProtoReflect() protoreflect.Message {
+func (x *WatchAgentsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -302,78 +577,50 @@ func (x *Agent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
-func (*Agent) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchAgentsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAgentsRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Agent) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *Agent) GetHostname() string {
-	if x != nil {
-		return x.Hostname
-	}
-	return ""
-}
-
-func (x *Agent) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
-}
-
-func (x *Agent) GetLabels() map[string]string {
+func (x *WatchAgentsRequest) GetLabels() map[string]string {
 	if x != nil {
 		return x.Labels
 	}
 	return nil
 }
 
-func (x *Agent) GetCapabilities() []string {
-	if x != nil {
-		return x.Capabilities
-	}
-	return nil
-}
-
-func (x *Agent) GetLastSeen() *timestamppb.Timestamp {
+func (x *WatchAgentsRequest) GetGroup() string {
 	if x != nil {
-		return x.LastSeen
+		return x.Group
 	}
-	return nil
+	return ""
 }
 
-type RegisterRequest struct {
+// AgentDelta is one change to the agents a watch follows. Heartbeats alone
+// do not make deltas; last_seen and clock_skew are as of the delta.
+type AgentDelta struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	AgentId       string                 `protobuf:"bytes,5,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Optional persistent agent ID
-	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Capabilities  []string               `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Kind          AgentDeltaKind         `protobuf:"varint,1,opt,name=kind,proto3,enum=mandau.agent.v1.AgentDeltaKind" json:"kind,omitempty"`
+	Agent         *Agent                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"` // Unset for SYNCED
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterRequest) Reset() {
-	*x = RegisterRequest{}
+func (x *AgentDelta) Reset() {
+	*x = AgentDelta{}
 	mi := &file_api_v1_agent_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterRequest) String() string {
+func (x *AgentDelta) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterRequest) ProtoMessage() {}
+func (*AgentDelta) ProtoMessage() {}
 
-func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+func (x *AgentDelta) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -385,69 +632,55 @@ func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
-func (*RegisterRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AgentDelta.ProtoReflect.Descriptor instead.
+func (*AgentDelta) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *RegisterRequest) GetHostname() string {
-	if x != nil {
-		return x.Hostname
-	}
-	return ""
-}
-
-func (x *RegisterRequest) GetVersion() string {
+func (x *AgentDelta) GetKind() AgentDeltaKind {
 	if x != nil {
-		return x.Version
-	}
-	return ""
-}
-
-func (x *RegisterRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
+		return x.Kind
 	}
-	return ""
+	return AgentDeltaKind_AGENT_DELTA_KIND_UNSPECIFIED
 }
 
-func (x *RegisterRequest) GetLabels() map[string]string {
+func (x *AgentDelta) GetAgent() *Agent {
 	if x != nil {
-		return x.Labels
+		return x.Agent
 	}
 	return nil
 }
 
-func (x *RegisterRequest) GetCapabilities() []string {
+func (x *AgentDelta) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Capabilities
+		return x.Time
 	}
 	return nil
 }
 
-type RegisterResponse struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Certificate       []byte                 `protobuf:"bytes,2,opt,name=certificate,proto3" json:"certificate,omitempty"`
-	HeartbeatInterval *durationpb.Duration   `protobuf:"bytes,3,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+type UpdateAgentLabelsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Set           map[string]string      `protobuf:"bytes,2,rep,name=set,proto3" json:"set,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Remove        []string               `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterResponse) Reset() {
-	*x = RegisterResponse{}
+func (x *UpdateAgentLabelsRequest) Reset() {
+	*x = UpdateAgentLabelsRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterResponse) String() string {
+func (x *UpdateAgentLabelsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterResponse) ProtoMessage() {}
+func (*UpdateAgentLabelsRequest) ProtoMessage() {}
 
-func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateAgentLabelsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -459,60 +692,53 @@ func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
-func (*RegisterResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateAgentLabelsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAgentLabelsRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *RegisterResponse) GetAgentId() string {
+func (x *UpdateAgentLabelsRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RegisterResponse) GetCertificate() []byte {
+func (x *UpdateAgentLabelsRequest) GetSet() map[string]string {
 	if x != nil {
-		return x.Certificate
+		return x.Set
 	}
 	return nil
 }
 
-func (x *RegisterResponse) GetHeartbeatInterval() *durationpb.Duration {
+func (x *UpdateAgentLabelsRequest) GetRemove() []string {
 	if x != nil {
-		return x.HeartbeatInterval
+		return x.Remove
 	}
 	return nil
 }
 
-type Stack struct {
+type UpdateAgentLabelsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	State         StackState             `protobuf:"varint,4,opt,name=state,proto3,enum=mandau.agent.v1.StackState" json:"state,omitempty"`
-	Containers    []*Container           `protobuf:"bytes,5,rep,name=containers,proto3" json:"containers,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	Labels        map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Agent         *Agent                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Stack) Reset() {
-	*x = Stack{}
+func (x *UpdateAgentLabelsResponse) Reset() {
+	*x = UpdateAgentLabelsResponse{}
 	mi := &file_api_v1_agent_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Stack) String() string {
+func (x *UpdateAgentLabelsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Stack) ProtoMessage() {}
+func (*UpdateAgentLabelsResponse) ProtoMessage() {}
 
-func (x *Stack) ProtoReflect() protoreflect.Message {
+func (x *UpdateAgentLabelsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -524,94 +750,42 @@ func (x *Stack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Stack.ProtoReflect.Descriptor instead.
-func (*Stack) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateAgentLabelsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAgentLabelsResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *Stack) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *Stack) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *Stack) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *Stack) GetState() StackState {
-	if x != nil {
-		return x.State
-	}
-	return StackState_STACK_STATE_UNKNOWN
-}
-
-func (x *Stack) GetContainers() []*Container {
-	if x != nil {
-		return x.Containers
-	}
-	return nil
-}
-
-func (x *Stack) GetCreatedAt() *timestamppb.Timestamp {
-	if x != nil {
-		return x.CreatedAt
-	}
-	return nil
-}
-
-func (x *Stack) GetUpdatedAt() *timestamppb.Timestamp {
-	if x != nil {
-		return x.UpdatedAt
-	}
-	return nil
-}
-
-func (x *Stack) GetLabels() map[string]string {
+func (x *UpdateAgentLabelsResponse) GetAgent() *Agent {
 	if x != nil {
-		return x.Labels
+		return x.Agent
 	}
 	return nil
 }
 
-type ApplyStackRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	StackName      string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	ComposeContent string                 `protobuf:"bytes,3,opt,name=compose_content,json=composeContent,proto3" json:"compose_content,omitempty"`
-	EnvVars        map[string]string      `protobuf:"bytes,4,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ForceRecreate  bool                   `protobuf:"varint,5,opt,name=force_recreate,json=forceRecreate,proto3" json:"force_recreate,omitempty"`
-	Services       []string               `protobuf:"bytes,6,rep,name=services,proto3" json:"services,omitempty"`
-	PullImages     bool                   `protobuf:"varint,7,opt,name=pull_images,json=pullImages,proto3" json:"pull_images,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type SetAgentMaintenanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"` // Expiry, defaults to one hour
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApplyStackRequest) Reset() {
-	*x = ApplyStackRequest{}
+func (x *SetAgentMaintenanceRequest) Reset() {
+	*x = SetAgentMaintenanceRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApplyStackRequest) String() string {
+func (x *SetAgentMaintenanceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApplyStackRequest) ProtoMessage() {}
+func (*SetAgentMaintenanceRequest) ProtoMessage() {}
 
-func (x *ApplyStackRequest) ProtoReflect() protoreflect.Message {
+func (x *SetAgentMaintenanceRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -623,82 +797,60 @@ func (x *ApplyStackRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApplyStackRequest.ProtoReflect.Descriptor instead.
-func (*ApplyStackRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SetAgentMaintenanceRequest.ProtoReflect.Descriptor instead.
+func (*SetAgentMaintenanceRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ApplyStackRequest) GetAgentId() string {
+func (x *SetAgentMaintenanceRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *ApplyStackRequest) GetStackName() string {
-	if x != nil {
-		return x.StackName
-	}
-	return ""
-}
-
-func (x *ApplyStackRequest) GetComposeContent() string {
-	if x != nil {
-		return x.ComposeContent
-	}
-	return ""
-}
-
-func (x *ApplyStackRequest) GetEnvVars() map[string]string {
-	if x != nil {
-		return x.EnvVars
-	}
-	return nil
-}
-
-func (x *ApplyStackRequest) GetForceRecreate() bool {
+func (x *SetAgentMaintenanceRequest) GetEnabled() bool {
 	if x != nil {
-		return x.ForceRecreate
+		return x.Enabled
 	}
 	return false
 }
 
-func (x *ApplyStackRequest) GetServices() []string {
+func (x *SetAgentMaintenanceRequest) GetDuration() *durationpb.Duration {
 	if x != nil {
-		return x.Services
+		return x.Duration
 	}
 	return nil
 }
 
-func (x *ApplyStackRequest) GetPullImages() bool {
+func (x *SetAgentMaintenanceRequest) GetReason() string {
 	if x != nil {
-		return x.PullImages
+		return x.Reason
 	}
-	return false
+	return ""
 }
 
-type DiffStackRequest struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	StackName         string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	NewComposeContent string                 `protobuf:"bytes,2,opt,name=new_compose_content,json=newComposeContent,proto3" json:"new_compose_content,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+type SetAgentMaintenanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agent         *Agent                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiffStackRequest) Reset() {
-	*x = DiffStackRequest{}
+func (x *SetAgentMaintenanceResponse) Reset() {
+	*x = SetAgentMaintenanceResponse{}
 	mi := &file_api_v1_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiffStackRequest) String() string {
+func (x *SetAgentMaintenanceResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiffStackRequest) ProtoMessage() {}
+func (*SetAgentMaintenanceResponse) ProtoMessage() {}
 
-func (x *DiffStackRequest) ProtoReflect() protoreflect.Message {
+func (x *SetAgentMaintenanceResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -710,47 +862,43 @@ func (x *DiffStackRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiffStackRequest.ProtoReflect.Descriptor instead.
-func (*DiffStackRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SetAgentMaintenanceResponse.ProtoReflect.Descriptor instead.
+func (*SetAgentMaintenanceResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *DiffStackRequest) GetStackName() string {
-	if x != nil {
-		return x.StackName
-	}
-	return ""
-}
-
-func (x *DiffStackRequest) GetNewComposeContent() string {
+func (x *SetAgentMaintenanceResponse) GetAgent() *Agent {
 	if x != nil {
-		return x.NewComposeContent
+		return x.Agent
 	}
-	return ""
+	return nil
 }
 
-type DiffStackResponse struct {
+// Maintenance describes a planned downtime window on an agent
+type Maintenance struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Services      []*ServiceDiff         `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
-	HasChanges    bool                   `protobuf:"varint,2,opt,name=has_changes,json=hasChanges,proto3" json:"has_changes,omitempty"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	SetBy         string                 `protobuf:"bytes,2,opt,name=set_by,json=setBy,proto3" json:"set_by,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiffStackResponse) Reset() {
-	*x = DiffStackResponse{}
+func (x *Maintenance) Reset() {
+	*x = Maintenance{}
 	mi := &file_api_v1_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiffStackResponse) String() string {
+func (x *Maintenance) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiffStackResponse) ProtoMessage() {}
+func (*Maintenance) ProtoMessage() {}
 
-func (x *DiffStackResponse) ProtoReflect() protoreflect.Message {
+func (x *Maintenance) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -762,48 +910,73 @@ func (x *DiffStackResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiffStackResponse.ProtoReflect.Descriptor instead.
-func (*DiffStackResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Maintenance.ProtoReflect.Descriptor instead.
+func (*Maintenance) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *DiffStackResponse) GetServices() []*ServiceDiff {
+func (x *Maintenance) GetReason() string {
 	if x != nil {
-		return x.Services
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Maintenance) GetSetBy() string {
+	if x != nil {
+		return x.SetBy
+	}
+	return ""
+}
+
+func (x *Maintenance) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
 	}
 	return nil
 }
 
-func (x *DiffStackResponse) GetHasChanges() bool {
+func (x *Maintenance) GetUntil() *timestamppb.Timestamp {
 	if x != nil {
-		return x.HasChanges
+		return x.Until
 	}
-	return false
+	return nil
 }
 
-type ServiceDiff struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Action        DiffAction             `protobuf:"varint,2,opt,name=action,proto3,enum=mandau.agent.v1.DiffAction" json:"action,omitempty"`
-	Changes       []string               `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type Agent struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hostname     string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Status       string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Labels       map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Capabilities []string               `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	LastSeen     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Groups       []string               `protobuf:"bytes,7,rep,name=groups,proto3" json:"groups,omitempty"`
+	Maintenance  *Maintenance           `protobuf:"bytes,8,opt,name=maintenance,proto3" json:"maintenance,omitempty"` // Unset when not in maintenance
+	// Agent clock minus core clock from heartbeats; unset until reported
+	ClockSkew *durationpb.Duration `protobuf:"bytes,9,opt,name=clock_skew,json=clockSkew,proto3" json:"clock_skew,omitempty"`
+	Circuit   *AgentCircuit        `protobuf:"bytes,10,opt,name=circuit,proto3" json:"circuit,omitempty"` // The core's circuit breaker for calls to it
+	// Host service plugins enabled in the agent config that its host cannot
+	// run, so their services are not served
+	UnavailablePlugins []*PluginAvailability `protobuf:"bytes,11,rep,name=unavailable_plugins,json=unavailablePlugins,proto3" json:"unavailable_plugins,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
-func (x *ServiceDiff) Reset() {
-	*x = ServiceDiff{}
+func (x *Agent) Reset() {
+	*x = Agent{}
 	mi := &file_api_v1_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceDiff) String() string {
+func (x *Agent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceDiff) ProtoMessage() {}
+func (*Agent) ProtoMessage() {}
 
-func (x *ServiceDiff) ProtoReflect() protoreflect.Message {
+func (x *Agent) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -815,157 +988,119 @@ func (x *ServiceDiff) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceDiff.ProtoReflect.Descriptor instead.
-func (*ServiceDiff) Descriptor() ([]byte, []int) {
+// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
+func (*Agent) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ServiceDiff) GetName() string {
+func (x *Agent) GetId() string {
 	if x != nil {
-		return x.Name
+		return x.Id
 	}
 	return ""
 }
 
-func (x *ServiceDiff) GetAction() DiffAction {
+func (x *Agent) GetHostname() string {
 	if x != nil {
-		return x.Action
+		return x.Hostname
 	}
-	return DiffAction_DIFF_ACTION_NONE
+	return ""
 }
 
-func (x *ServiceDiff) GetChanges() []string {
+func (x *Agent) GetStatus() string {
 	if x != nil {
-		return x.Changes
+		return x.Status
 	}
-	return nil
+	return ""
 }
 
-type Container struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
-	State         string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
-	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
-	Created       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created,proto3" json:"created,omitempty"`
-	Labels        map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Ports         []*Port                `protobuf:"bytes,8,rep,name=ports,proto3" json:"ports,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Agent) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
 }
 
-func (x *Container) Reset() {
-	*x = Container{}
-	mi := &file_api_v1_agent_proto_msgTypes[10]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Agent) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
 }
 
-func (x *Container) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Agent) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
 }
 
-func (*Container) ProtoMessage() {}
-
-func (x *Container) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[10]
+func (x *Agent) GetGroups() []string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Groups
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use Container.ProtoReflect.Descriptor instead.
-func (*Container) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{10}
+func (x *Agent) GetMaintenance() *Maintenance {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
 }
 
-func (x *Container) GetId() string {
+func (x *Agent) GetClockSkew() *durationpb.Duration {
 	if x != nil {
-		return x.Id
+		return x.ClockSkew
 	}
-	return ""
+	return nil
 }
 
-func (x *Container) GetName() string {
+func (x *Agent) GetCircuit() *AgentCircuit {
 	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *Container) GetImage() string {
-	if x != nil {
-		return x.Image
-	}
-	return ""
-}
-
-func (x *Container) GetState() string {
-	if x != nil {
-		return x.State
-	}
-	return ""
-}
-
-func (x *Container) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
-}
-
-func (x *Container) GetCreated() *timestamppb.Timestamp {
-	if x != nil {
-		return x.Created
-	}
-	return nil
-}
-
-func (x *Container) GetLabels() map[string]string {
-	if x != nil {
-		return x.Labels
+		return x.Circuit
 	}
 	return nil
 }
 
-func (x *Container) GetPorts() []*Port {
+func (x *Agent) GetUnavailablePlugins() []*PluginAvailability {
 	if x != nil {
-		return x.Ports
+		return x.UnavailablePlugins
 	}
 	return nil
 }
 
-type Port struct {
+// AgentCircuit is the state of the core's circuit breaker for an agent. An
+// open circuit fails calls to the agent fast; after a cooldown one probe
+// call goes through and closes it again when it succeeds.
+type AgentCircuit struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PrivatePort   uint32                 `protobuf:"varint,1,opt,name=private_port,json=privatePort,proto3" json:"private_port,omitempty"`
-	PublicPort    uint32                 `protobuf:"varint,2,opt,name=public_port,json=publicPort,proto3" json:"public_port,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
-	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`        // closed, open or half-open
+	Since         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`        // When it opened; unset while closed
+	Failures      int32                  `protobuf:"varint,4,opt,name=failures,proto3" json:"failures,omitempty"` // Consecutive failed calls
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Trips         int64                  `protobuf:"varint,6,opt,name=trips,proto3" json:"trips,omitempty"`       // Times it opened since the core started
+	Rejected      int64                  `protobuf:"varint,7,opt,name=rejected,proto3" json:"rejected,omitempty"` // Calls failed fast while open
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Port) Reset() {
-	*x = Port{}
-	mi := &file_api_v1_agent_proto_msgTypes[11]
+func (x *AgentCircuit) Reset() {
+	*x = AgentCircuit{}
+	mi := &file_api_v1_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Port) String() string {
+func (x *AgentCircuit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Port) ProtoMessage() {}
+func (*AgentCircuit) ProtoMessage() {}
 
-func (x *Port) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[11]
+func (x *AgentCircuit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -976,66 +1111,88 @@ func (x *Port) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Port.ProtoReflect.Descriptor instead.
-func (*Port) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use AgentCircuit.ProtoReflect.Descriptor instead.
+func (*AgentCircuit) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Port) GetPrivatePort() uint32 {
+func (x *AgentCircuit) GetAgentId() string {
 	if x != nil {
-		return x.PrivatePort
+		return x.AgentId
 	}
-	return 0
+	return ""
 }
 
-func (x *Port) GetPublicPort() uint32 {
+func (x *AgentCircuit) GetState() string {
 	if x != nil {
-		return x.PublicPort
+		return x.State
+	}
+	return ""
+}
+
+func (x *AgentCircuit) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *AgentCircuit) GetFailures() int32 {
+	if x != nil {
+		return x.Failures
 	}
 	return 0
 }
 
-func (x *Port) GetType() string {
+func (x *AgentCircuit) GetLastError() string {
 	if x != nil {
-		return x.Type
+		return x.LastError
 	}
 	return ""
 }
 
-func (x *Port) GetIp() string {
+func (x *AgentCircuit) GetTrips() int64 {
 	if x != nil {
-		return x.Ip
+		return x.Trips
 	}
-	return ""
+	return 0
 }
 
-type ExecRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*ExecRequest_Start
-	//	*ExecRequest_Stdin
-	//	*ExecRequest_Resize
-	Payload       isExecRequest_Payload `protobuf_oneof:"payload"`
+func (x *AgentCircuit) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+// AgentGroup is a named set of agents, either listed explicitly or matched
+// by a label selector
+type AgentGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Agents        []string               `protobuf:"bytes,3,rep,name=agents,proto3" json:"agents,omitempty"`                                                                               // Agent IDs or hostnames
+	Selector      map[string]string      `protobuf:"bytes,4,rep,name=selector,proto3" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Labels an agent must carry
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecRequest) Reset() {
-	*x = ExecRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[12]
+func (x *AgentGroup) Reset() {
+	*x = AgentGroup{}
+	mi := &file_api_v1_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecRequest) String() string {
+func (x *AgentGroup) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecRequest) ProtoMessage() {}
+func (*AgentGroup) ProtoMessage() {}
 
-func (x *ExecRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[12]
+func (x *AgentGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1046,94 +1203,68 @@ func (x *ExecRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
-func (*ExecRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use AgentGroup.ProtoReflect.Descriptor instead.
+func (*AgentGroup) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ExecRequest) GetPayload() isExecRequest_Payload {
+func (x *AgentGroup) GetName() string {
 	if x != nil {
-		return x.Payload
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *ExecRequest) GetStart() *ExecStart {
+func (x *AgentGroup) GetDescription() string {
 	if x != nil {
-		if x, ok := x.Payload.(*ExecRequest_Start); ok {
-			return x.Start
-		}
+		return x.Description
 	}
-	return nil
+	return ""
 }
 
-func (x *ExecRequest) GetStdin() []byte {
+func (x *AgentGroup) GetAgents() []string {
 	if x != nil {
-		if x, ok := x.Payload.(*ExecRequest_Stdin); ok {
-			return x.Stdin
-		}
+		return x.Agents
 	}
 	return nil
 }
 
-func (x *ExecRequest) GetResize() *ExecResize {
+func (x *AgentGroup) GetSelector() map[string]string {
 	if x != nil {
-		if x, ok := x.Payload.(*ExecRequest_Resize); ok {
-			return x.Resize
-		}
+		return x.Selector
 	}
 	return nil
 }
 
-type isExecRequest_Payload interface {
-	isExecRequest_Payload()
-}
-
-type ExecRequest_Start struct {
-	Start *ExecStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
-}
-
-type ExecRequest_Stdin struct {
-	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3,oneof"`
-}
-
-type ExecRequest_Resize struct {
-	Resize *ExecResize `protobuf:"bytes,3,opt,name=resize,proto3,oneof"`
+func (x *AgentGroup) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (*ExecRequest_Start) isExecRequest_Payload() {}
-
-func (*ExecRequest_Stdin) isExecRequest_Payload() {}
-
-func (*ExecRequest_Resize) isExecRequest_Payload() {}
-
-type ExecStart struct {
+type CreateAgentGroupRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	Cmd           []string               `protobuf:"bytes,2,rep,name=cmd,proto3" json:"cmd,omitempty"`
-	Tty           bool                   `protobuf:"varint,3,opt,name=tty,proto3" json:"tty,omitempty"`
-	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	WorkingDir    string                 `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
-	User          string                 `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	Group         *AgentGroup            `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecStart) Reset() {
-	*x = ExecStart{}
-	mi := &file_api_v1_agent_proto_msgTypes[13]
+func (x *CreateAgentGroupRequest) Reset() {
+	*x = CreateAgentGroupRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecStart) String() string {
+func (x *CreateAgentGroupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecStart) ProtoMessage() {}
+func (*CreateAgentGroupRequest) ProtoMessage() {}
 
-func (x *ExecStart) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[13]
+func (x *CreateAgentGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1144,75 +1275,84 @@ func (x *ExecStart) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecStart.ProtoReflect.Descriptor instead.
-func (*ExecStart) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use CreateAgentGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateAgentGroupRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *ExecStart) GetContainerId() string {
+func (x *CreateAgentGroupRequest) GetGroup() *AgentGroup {
 	if x != nil {
-		return x.ContainerId
+		return x.Group
 	}
-	return ""
+	return nil
 }
 
-func (x *ExecStart) GetCmd() []string {
-	if x != nil {
-		return x.Cmd
-	}
-	return nil
+type GetAgentGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecStart) GetTty() bool {
-	if x != nil {
-		return x.Tty
-	}
-	return false
+func (x *GetAgentGroupRequest) Reset() {
+	*x = GetAgentGroupRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecStart) GetEnv() map[string]string {
-	if x != nil {
-		return x.Env
-	}
-	return nil
+func (x *GetAgentGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ExecStart) GetWorkingDir() string {
+func (*GetAgentGroupRequest) ProtoMessage() {}
+
+func (x *GetAgentGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[13]
 	if x != nil {
-		return x.WorkingDir
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *ExecStart) GetUser() string {
+// Deprecated: Use GetAgentGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentGroupRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetAgentGroupRequest) GetName() string {
 	if x != nil {
-		return x.User
+		return x.Name
 	}
 	return ""
 }
 
-type ExecResize struct {
+type GetAgentGroupResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Height        uint32                 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
-	Width         uint32                 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Group         *AgentGroup            `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Members       []*Agent               `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecResize) Reset() {
-	*x = ExecResize{}
+func (x *GetAgentGroupResponse) Reset() {
+	*x = GetAgentGroupResponse{}
 	mi := &file_api_v1_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecResize) String() string {
+func (x *GetAgentGroupResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecResize) ProtoMessage() {}
+func (*GetAgentGroupResponse) ProtoMessage() {}
 
-func (x *ExecResize) ProtoReflect() protoreflect.Message {
+func (x *GetAgentGroupResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1224,52 +1364,45 @@ func (x *ExecResize) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecResize.ProtoReflect.Descriptor instead.
-func (*ExecResize) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetAgentGroupResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentGroupResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *ExecResize) GetHeight() uint32 {
+func (x *GetAgentGroupResponse) GetGroup() *AgentGroup {
 	if x != nil {
-		return x.Height
+		return x.Group
 	}
-	return 0
+	return nil
 }
 
-func (x *ExecResize) GetWidth() uint32 {
+func (x *GetAgentGroupResponse) GetMembers() []*Agent {
 	if x != nil {
-		return x.Width
+		return x.Members
 	}
-	return 0
+	return nil
 }
 
-type ExecResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*ExecResponse_Stdout
-	//	*ExecResponse_Stderr
-	//	*ExecResponse_ExitCode
-	//	*ExecResponse_Error
-	Payload       isExecResponse_Payload `protobuf_oneof:"payload"`
+type ListAgentGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecResponse) Reset() {
-	*x = ExecResponse{}
+func (x *ListAgentGroupsRequest) Reset() {
+	*x = ListAgentGroupsRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecResponse) String() string {
+func (x *ListAgentGroupsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecResponse) ProtoMessage() {}
+func (*ListAgentGroupsRequest) ProtoMessage() {}
 
-func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+func (x *ListAgentGroupsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1281,107 +1414,32 @@ func (x *ExecResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
-func (*ExecResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListAgentGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentGroupsRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ExecResponse) GetPayload() isExecResponse_Payload {
-	if x != nil {
-		return x.Payload
-	}
-	return nil
-}
-
-func (x *ExecResponse) GetStdout() []byte {
-	if x != nil {
-		if x, ok := x.Payload.(*ExecResponse_Stdout); ok {
-			return x.Stdout
-		}
-	}
-	return nil
-}
-
-func (x *ExecResponse) GetStderr() []byte {
-	if x != nil {
-		if x, ok := x.Payload.(*ExecResponse_Stderr); ok {
-			return x.Stderr
-		}
-	}
-	return nil
-}
-
-func (x *ExecResponse) GetExitCode() int32 {
-	if x != nil {
-		if x, ok := x.Payload.(*ExecResponse_ExitCode); ok {
-			return x.ExitCode
-		}
-	}
-	return 0
-}
-
-func (x *ExecResponse) GetError() string {
-	if x != nil {
-		if x, ok := x.Payload.(*ExecResponse_Error); ok {
-			return x.Error
-		}
-	}
-	return ""
-}
-
-type isExecResponse_Payload interface {
-	isExecResponse_Payload()
-}
-
-type ExecResponse_Stdout struct {
-	Stdout []byte `protobuf:"bytes,1,opt,name=stdout,proto3,oneof"`
-}
-
-type ExecResponse_Stderr struct {
-	Stderr []byte `protobuf:"bytes,2,opt,name=stderr,proto3,oneof"`
-}
-
-type ExecResponse_ExitCode struct {
-	ExitCode int32 `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3,oneof"`
-}
-
-type ExecResponse_Error struct {
-	Error string `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
-}
-
-func (*ExecResponse_Stdout) isExecResponse_Payload() {}
-
-func (*ExecResponse_Stderr) isExecResponse_Payload() {}
-
-func (*ExecResponse_ExitCode) isExecResponse_Payload() {}
-
-func (*ExecResponse_Error) isExecResponse_Payload() {}
-
-type LogEntry struct {
+type ListAgentGroupsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Stream        string                 `protobuf:"bytes,2,opt,name=stream,proto3" json:"stream,omitempty"` // stdout or stderr
-	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	ContainerId   string                 `protobuf:"bytes,4,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	ServiceName   string                 `protobuf:"bytes,5,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Groups        []*AgentGroup          `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogEntry) Reset() {
-	*x = LogEntry{}
+func (x *ListAgentGroupsResponse) Reset() {
+	*x = ListAgentGroupsResponse{}
 	mi := &file_api_v1_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogEntry) String() string {
+func (x *ListAgentGroupsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogEntry) ProtoMessage() {}
+func (*ListAgentGroupsResponse) ProtoMessage() {}
 
-func (x *LogEntry) ProtoReflect() protoreflect.Message {
+func (x *ListAgentGroupsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1393,72 +1451,44 @@ func (x *LogEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
-func (*LogEntry) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListAgentGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentGroupsResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *LogEntry) GetTimestamp() *timestamppb.Timestamp {
-	if x != nil {
-		return x.Timestamp
-	}
-	return nil
-}
-
-func (x *LogEntry) GetStream() string {
+func (x *ListAgentGroupsResponse) GetGroups() []*AgentGroup {
 	if x != nil {
-		return x.Stream
-	}
-	return ""
-}
-
-func (x *LogEntry) GetContent() []byte {
-	if x != nil {
-		return x.Content
+		return x.Groups
 	}
 	return nil
 }
 
-func (x *LogEntry) GetContainerId() string {
-	if x != nil {
-		return x.ContainerId
-	}
-	return ""
-}
-
-func (x *LogEntry) GetServiceName() string {
-	if x != nil {
-		return x.ServiceName
-	}
-	return ""
-}
-
-type ContainerStats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Cpu           *CPUStats              `protobuf:"bytes,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	Memory        *MemoryStats           `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
-	Network       *NetworkStats          `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
-	BlockIo       *BlockIOStats          `protobuf:"bytes,6,opt,name=block_io,json=blockIo,proto3" json:"block_io,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type UpdateAgentGroupRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description     *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	AddAgents       []string               `protobuf:"bytes,3,rep,name=add_agents,json=addAgents,proto3" json:"add_agents,omitempty"`
+	RemoveAgents    []string               `protobuf:"bytes,4,rep,name=remove_agents,json=removeAgents,proto3" json:"remove_agents,omitempty"`
+	Selector        map[string]string      `protobuf:"bytes,5,rep,name=selector,proto3" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ReplaceSelector bool                   `protobuf:"varint,6,opt,name=replace_selector,json=replaceSelector,proto3" json:"replace_selector,omitempty"` // Replace the selector instead of merging
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *ContainerStats) Reset() {
-	*x = ContainerStats{}
+func (x *UpdateAgentGroupRequest) Reset() {
+	*x = UpdateAgentGroupRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContainerStats) String() string {
+func (x *UpdateAgentGroupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerStats) ProtoMessage() {}
+func (*UpdateAgentGroupRequest) ProtoMessage() {}
 
-func (x *ContainerStats) ProtoReflect() protoreflect.Message {
+func (x *UpdateAgentGroupRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1470,75 +1500,74 @@ func (x *ContainerStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerStats.ProtoReflect.Descriptor instead.
-func (*ContainerStats) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateAgentGroupRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAgentGroupRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *ContainerStats) GetContainerId() string {
+func (x *UpdateAgentGroupRequest) GetName() string {
 	if x != nil {
-		return x.ContainerId
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ContainerStats) GetTimestamp() *timestamppb.Timestamp {
-	if x != nil {
-		return x.Timestamp
+func (x *UpdateAgentGroupRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
 	}
-	return nil
+	return ""
 }
 
-func (x *ContainerStats) GetCpu() *CPUStats {
+func (x *UpdateAgentGroupRequest) GetAddAgents() []string {
 	if x != nil {
-		return x.Cpu
+		return x.AddAgents
 	}
 	return nil
 }
 
-func (x *ContainerStats) GetMemory() *MemoryStats {
+func (x *UpdateAgentGroupRequest) GetRemoveAgents() []string {
 	if x != nil {
-		return x.Memory
+		return x.RemoveAgents
 	}
 	return nil
 }
 
-func (x *ContainerStats) GetNetwork() *NetworkStats {
+func (x *UpdateAgentGroupRequest) GetSelector() map[string]string {
 	if x != nil {
-		return x.Network
+		return x.Selector
 	}
 	return nil
 }
 
-func (x *ContainerStats) GetBlockIo() *BlockIOStats {
+func (x *UpdateAgentGroupRequest) GetReplaceSelector() bool {
 	if x != nil {
-		return x.BlockIo
+		return x.ReplaceSelector
 	}
-	return nil
+	return false
 }
 
-type ListFilesRequest struct {
+type DeleteAgentGroupRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // Relative to stack root
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesRequest) Reset() {
-	*x = ListFilesRequest{}
+func (x *DeleteAgentGroupRequest) Reset() {
+	*x = DeleteAgentGroupRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesRequest) String() string {
+func (x *DeleteAgentGroupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesRequest) ProtoMessage() {}
+func (*DeleteAgentGroupRequest) ProtoMessage() {}
 
-func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+func (x *DeleteAgentGroupRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1550,46 +1579,38 @@ func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
-func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteAgentGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAgentGroupRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *ListFilesRequest) GetStackName() string {
-	if x != nil {
-		return x.StackName
-	}
-	return ""
-}
-
-func (x *ListFilesRequest) GetPath() string {
+func (x *DeleteAgentGroupRequest) GetName() string {
 	if x != nil {
-		return x.Path
+		return x.Name
 	}
 	return ""
 }
 
-type ListFilesResponse struct {
+type DeleteAgentGroupResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesResponse) Reset() {
-	*x = ListFilesResponse{}
+func (x *DeleteAgentGroupResponse) Reset() {
+	*x = DeleteAgentGroupResponse{}
 	mi := &file_api_v1_agent_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesResponse) String() string {
+func (x *DeleteAgentGroupResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesResponse) ProtoMessage() {}
+func (*DeleteAgentGroupResponse) ProtoMessage() {}
 
-func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteAgentGroupResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1601,44 +1622,51 @@ func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
-func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteAgentGroupResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAgentGroupResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ListFilesResponse) GetFiles() []*FileInfo {
-	if x != nil {
-		return x.Files
-	}
-	return nil
-}
-
-type FileInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	IsDir         bool                   `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
-	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
-	Modified      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=modified,proto3" json:"modified,omitempty"`
-	Mode          uint32                 `protobuf:"varint,6,opt,name=mode,proto3" json:"mode,omitempty"`
+// Approval is a parked request for an action that policy says needs a second
+// identity to sign off. Once approved, the requester re-submits the original
+// request with the approval ID.
+type Approval struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RequestedBy string                 `protobuf:"bytes,2,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	AgentId     string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Action      string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Resource    string                 `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	State       ApprovalState          `protobuf:"varint,6,opt,name=state,proto3,enum=mandau.agent.v1.ApprovalState" json:"state,omitempty"`
+	ReviewedBy  string                 `protobuf:"bytes,7,opt,name=reviewed_by,json=reviewedBy,proto3" json:"reviewed_by,omitempty"`
+	Comment     string                 `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ReviewedAt  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// SHA-256 of the request content the approval was granted for
+	Digest string `protobuf:"bytes,12,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Set when the request targeted a group; the approval then covers each
+	// listed member once
+	Group         string   `protobuf:"bytes,13,opt,name=group,proto3" json:"group,omitempty"`
+	Agents        []string `protobuf:"bytes,14,rep,name=agents,proto3" json:"agents,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileInfo) Reset() {
-	*x = FileInfo{}
+func (x *Approval) Reset() {
+	*x = Approval{}
 	mi := &file_api_v1_agent_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileInfo) String() string {
+func (x *Approval) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileInfo) ProtoMessage() {}
+func (*Approval) ProtoMessage() {}
 
-func (x *FileInfo) ProtoReflect() protoreflect.Message {
+func (x *Approval) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1650,128 +1678,131 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
-func (*FileInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use Approval.ProtoReflect.Descriptor instead.
+func (*Approval) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *FileInfo) GetName() string {
+func (x *Approval) GetId() string {
 	if x != nil {
-		return x.Name
+		return x.Id
 	}
 	return ""
 }
 
-func (x *FileInfo) GetPath() string {
+func (x *Approval) GetRequestedBy() string {
 	if x != nil {
-		return x.Path
+		return x.RequestedBy
 	}
 	return ""
 }
 
-func (x *FileInfo) GetIsDir() bool {
+func (x *Approval) GetAgentId() string {
 	if x != nil {
-		return x.IsDir
+		return x.AgentId
 	}
-	return false
+	return ""
 }
 
-func (x *FileInfo) GetSize() int64 {
+func (x *Approval) GetAction() string {
 	if x != nil {
-		return x.Size
+		return x.Action
 	}
-	return 0
+	return ""
 }
 
-func (x *FileInfo) GetModified() *timestamppb.Timestamp {
+func (x *Approval) GetResource() string {
 	if x != nil {
-		return x.Modified
+		return x.Resource
 	}
-	return nil
+	return ""
 }
 
-func (x *FileInfo) GetMode() uint32 {
+func (x *Approval) GetState() ApprovalState {
 	if x != nil {
-		return x.Mode
+		return x.State
 	}
-	return 0
+	return ApprovalState_APPROVAL_STATE_UNKNOWN
 }
 
-type ReadFileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Approval) GetReviewedBy() string {
+	if x != nil {
+		return x.ReviewedBy
+	}
+	return ""
 }
 
-func (x *ReadFileRequest) Reset() {
-	*x = ReadFileRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[21]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Approval) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
 }
 
-func (x *ReadFileRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Approval) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (*ReadFileRequest) ProtoMessage() {}
-
-func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[21]
+func (x *Approval) GetReviewedAt() *timestamppb.Timestamp {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ReviewedAt
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use ReadFileRequest.ProtoReflect.Descriptor instead.
-func (*ReadFileRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{21}
+func (x *Approval) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
 }
 
-func (x *ReadFileRequest) GetStackName() string {
+func (x *Approval) GetDigest() string {
 	if x != nil {
-		return x.StackName
+		return x.Digest
 	}
 	return ""
 }
 
-func (x *ReadFileRequest) GetPath() string {
+func (x *Approval) GetGroup() string {
 	if x != nil {
-		return x.Path
+		return x.Group
 	}
 	return ""
 }
 
-type ReadFileResponse struct {
+func (x *Approval) GetAgents() []string {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type ListApprovalsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Info          *FileInfo              `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	State         ApprovalState          `protobuf:"varint,1,opt,name=state,proto3,enum=mandau.agent.v1.ApprovalState" json:"state,omitempty"` // Unset returns all approvals
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadFileResponse) Reset() {
-	*x = ReadFileResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[22]
+func (x *ListApprovalsRequest) Reset() {
+	*x = ListApprovalsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadFileResponse) String() string {
+func (x *ListApprovalsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadFileResponse) ProtoMessage() {}
+func (*ListApprovalsRequest) ProtoMessage() {}
 
-func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[22]
+func (x *ListApprovalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1782,49 +1813,85 @@ func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadFileResponse.ProtoReflect.Descriptor instead.
-func (*ReadFileResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ListApprovalsRequest.ProtoReflect.Descriptor instead.
+func (*ListApprovalsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *ReadFileResponse) GetContent() []byte {
+func (x *ListApprovalsRequest) GetState() ApprovalState {
 	if x != nil {
-		return x.Content
+		return x.State
 	}
-	return nil
+	return ApprovalState_APPROVAL_STATE_UNKNOWN
 }
 
-func (x *ReadFileResponse) GetInfo() *FileInfo {
+type ListApprovalsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Approvals     []*Approval            `protobuf:"bytes,1,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListApprovalsResponse) Reset() {
+	*x = ListApprovalsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListApprovalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListApprovalsResponse) ProtoMessage() {}
+
+func (x *ListApprovalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[22]
 	if x != nil {
-		return x.Info
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListApprovalsResponse.ProtoReflect.Descriptor instead.
+func (*ListApprovalsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListApprovalsResponse) GetApprovals() []*Approval {
+	if x != nil {
+		return x.Approvals
 	}
 	return nil
 }
 
-type WriteFileRequest struct {
+type ReviewApprovalRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Approve       bool                   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	Comment       string                 `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WriteFileRequest) Reset() {
-	*x = WriteFileRequest{}
+func (x *ReviewApprovalRequest) Reset() {
+	*x = ReviewApprovalRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WriteFileRequest) String() string {
+func (x *ReviewApprovalRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteFileRequest) ProtoMessage() {}
+func (*ReviewApprovalRequest) ProtoMessage() {}
 
-func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+func (x *ReviewApprovalRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1836,67 +1903,63 @@ func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
-func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReviewApprovalRequest.ProtoReflect.Descriptor instead.
+func (*ReviewApprovalRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *WriteFileRequest) GetStackName() string {
-	if x != nil {
-		return x.StackName
-	}
-	return ""
-}
-
-func (x *WriteFileRequest) GetPath() string {
+func (x *ReviewApprovalRequest) GetId() string {
 	if x != nil {
-		return x.Path
+		return x.Id
 	}
 	return ""
 }
 
-func (x *WriteFileRequest) GetContent() []byte {
+func (x *ReviewApprovalRequest) GetApprove() bool {
 	if x != nil {
-		return x.Content
+		return x.Approve
 	}
-	return nil
+	return false
 }
 
-func (x *WriteFileRequest) GetMode() uint32 {
+func (x *ReviewApprovalRequest) GetComment() string {
 	if x != nil {
-		return x.Mode
+		return x.Comment
 	}
-	return 0
+	return ""
 }
 
-type Operation struct {
+// BreakGlassGrant temporarily adds roles to an identity
+type BreakGlassGrant struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
-	State         OperationState         `protobuf:"varint,3,opt,name=state,proto3,enum=mandau.agent.v1.OperationState" json:"state,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Progress      int32                  `protobuf:"varint,8,opt,name=progress,proto3" json:"progress,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Roles         []string               `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	GrantedBy     string                 `protobuf:"bytes,5,opt,name=granted_by,json=grantedBy,proto3" json:"granted_by,omitempty"`
+	GrantedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=granted_at,json=grantedAt,proto3" json:"granted_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RevokedBy     string                 `protobuf:"bytes,8,opt,name=revoked_by,json=revokedBy,proto3" json:"revoked_by,omitempty"`
+	RevokedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	Active        bool                   `protobuf:"varint,10,opt,name=active,proto3" json:"active,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Operation) Reset() {
-	*x = Operation{}
+func (x *BreakGlassGrant) Reset() {
+	*x = BreakGlassGrant{}
 	mi := &file_api_v1_agent_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Operation) String() string {
+func (x *BreakGlassGrant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Operation) ProtoMessage() {}
+func (*BreakGlassGrant) ProtoMessage() {}
 
-func (x *Operation) ProtoReflect() protoreflect.Message {
+func (x *BreakGlassGrant) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1908,93 +1971,105 @@ func (x *Operation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
-func (*Operation) Descriptor() ([]byte, []int) {
+// Deprecated: Use BreakGlassGrant.ProtoReflect.Descriptor instead.
+func (*BreakGlassGrant) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *Operation) GetId() string {
+func (x *BreakGlassGrant) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *Operation) GetType() string {
+func (x *BreakGlassGrant) GetUserId() string {
 	if x != nil {
-		return x.Type
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *Operation) GetState() OperationState {
+func (x *BreakGlassGrant) GetRoles() []string {
 	if x != nil {
-		return x.State
+		return x.Roles
 	}
-	return OperationState_OPERATION_STATE_PENDING
+	return nil
 }
 
-func (x *Operation) GetCreatedAt() *timestamppb.Timestamp {
+func (x *BreakGlassGrant) GetReason() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BreakGlassGrant) GetGrantedBy() string {
+	if x != nil {
+		return x.GrantedBy
+	}
+	return ""
+}
+
+func (x *BreakGlassGrant) GetGrantedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GrantedAt
 	}
 	return nil
 }
 
-func (x *Operation) GetCompletedAt() *timestamppb.Timestamp {
+func (x *BreakGlassGrant) GetExpiresAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.CompletedAt
+		return x.ExpiresAt
 	}
 	return nil
 }
 
-func (x *Operation) GetError() string {
+func (x *BreakGlassGrant) GetRevokedBy() string {
 	if x != nil {
-		return x.Error
+		return x.RevokedBy
 	}
 	return ""
 }
 
-func (x *Operation) GetMetadata() map[string]string {
+func (x *BreakGlassGrant) GetRevokedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Metadata
+		return x.RevokedAt
 	}
 	return nil
 }
 
-func (x *Operation) GetProgress() int32 {
+func (x *BreakGlassGrant) GetActive() bool {
 	if x != nil {
-		return x.Progress
+		return x.Active
 	}
-	return 0
+	return false
 }
 
-type OperationEvent struct {
+type GrantBreakGlassRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
-	State         OperationState         `protobuf:"varint,2,opt,name=state,proto3,enum=mandau.agent.v1.OperationState" json:"state,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Defaults to the caller
+	Roles         []string               `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // Required
+	Ttl           *durationpb.Duration   `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OperationEvent) Reset() {
-	*x = OperationEvent{}
+func (x *GrantBreakGlassRequest) Reset() {
+	*x = GrantBreakGlassRequest{}
 	mi := &file_api_v1_agent_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OperationEvent) String() string {
+func (x *GrantBreakGlassRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OperationEvent) ProtoMessage() {}
+func (*GrantBreakGlassRequest) ProtoMessage() {}
 
-func (x *OperationEvent) ProtoReflect() protoreflect.Message {
+func (x *GrantBreakGlassRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_agent_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2006,77 +2081,9386 @@ func (x *OperationEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OperationEvent.ProtoReflect.Descriptor instead.
-func (*OperationEvent) Descriptor() ([]byte, []int) {
+// Deprecated: Use GrantBreakGlassRequest.ProtoReflect.Descriptor instead.
+func (*GrantBreakGlassRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_agent_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *OperationEvent) GetOperationId() string {
+func (x *GrantBreakGlassRequest) GetUserId() string {
 	if x != nil {
-		return x.OperationId
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *OperationEvent) GetState() OperationState {
+func (x *GrantBreakGlassRequest) GetRoles() []string {
 	if x != nil {
-		return x.State
+		return x.Roles
 	}
-	return OperationState_OPERATION_STATE_PENDING
+	return nil
 }
 
-func (x *OperationEvent) GetTimestamp() *timestamppb.Timestamp {
+func (x *GrantBreakGlassRequest) GetReason() string {
 	if x != nil {
-		return x.Timestamp
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *GrantBreakGlassRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
 	}
 	return nil
 }
 
-func (x *OperationEvent) GetMessage() string {
+type RevokeBreakGlassRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeBreakGlassRequest) Reset() {
+	*x = RevokeBreakGlassRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeBreakGlassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeBreakGlassRequest) ProtoMessage() {}
+
+func (x *RevokeBreakGlassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[26]
 	if x != nil {
-		return x.Message
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeBreakGlassRequest.ProtoReflect.Descriptor instead.
+func (*RevokeBreakGlassRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RevokeBreakGlassRequest) GetId() string {
+	if x != nil {
+		return x.Id
 	}
 	return ""
 }
 
-func (x *OperationEvent) GetProgress() int32 {
-	if x != nil {
-		return x.Progress
-	}
-	return 0
+type ListBreakGlassGrantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveOnly    bool                   `protobuf:"varint,1,opt,name=active_only,json=activeOnly,proto3" json:"active_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBreakGlassGrantsRequest) Reset() {
+	*x = ListBreakGlassGrantsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBreakGlassGrantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBreakGlassGrantsRequest) ProtoMessage() {}
+
+func (x *ListBreakGlassGrantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBreakGlassGrantsRequest.ProtoReflect.Descriptor instead.
+func (*ListBreakGlassGrantsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListBreakGlassGrantsRequest) GetActiveOnly() bool {
+	if x != nil {
+		return x.ActiveOnly
+	}
+	return false
+}
+
+type ListBreakGlassGrantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Grants        []*BreakGlassGrant     `protobuf:"bytes,1,rep,name=grants,proto3" json:"grants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBreakGlassGrantsResponse) Reset() {
+	*x = ListBreakGlassGrantsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBreakGlassGrantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBreakGlassGrantsResponse) ProtoMessage() {}
+
+func (x *ListBreakGlassGrantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBreakGlassGrantsResponse.ProtoReflect.Descriptor instead.
+func (*ListBreakGlassGrantsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListBreakGlassGrantsResponse) GetGrants() []*BreakGlassGrant {
+	if x != nil {
+		return x.Grants
+	}
+	return nil
+}
+
+type SetFreezeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Frozen        bool                   `protobuf:"varint,1,opt,name=frozen,proto3" json:"frozen,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Required to freeze
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFreezeRequest) Reset() {
+	*x = SetFreezeRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFreezeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFreezeRequest) ProtoMessage() {}
+
+func (x *SetFreezeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFreezeRequest.ProtoReflect.Descriptor instead.
+func (*SetFreezeRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SetFreezeRequest) GetFrozen() bool {
+	if x != nil {
+		return x.Frozen
+	}
+	return false
+}
+
+func (x *SetFreezeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetFreezeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFreezeRequest) Reset() {
+	*x = GetFreezeRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFreezeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFreezeRequest) ProtoMessage() {}
+
+func (x *GetFreezeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFreezeRequest.ProtoReflect.Descriptor instead.
+func (*GetFreezeRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{30}
+}
+
+type GetClusterStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterStatusRequest) Reset() {
+	*x = GetClusterStatusRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterStatusRequest) ProtoMessage() {}
+
+func (x *GetClusterStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetClusterStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{31}
+}
+
+type ClusterStatus struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Version              string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	StartedAt            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Agents               map[string]int32       `protobuf:"bytes,3,rep,name=agents,proto3" json:"agents,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Agent count by status
+	Maintenance          []string               `protobuf:"bytes,4,rep,name=maintenance,proto3" json:"maintenance,omitempty"`                                                                  // Agents in maintenance
+	Freeze               *FreezeState           `protobuf:"bytes,5,opt,name=freeze,proto3" json:"freeze,omitempty"`
+	Running              []*ClusterOperation    `protobuf:"bytes,6,rep,name=running,proto3" json:"running,omitempty"`   // Stack changes in flight through the core
+	Failures             []*ClusterOperation    `protobuf:"bytes,7,rep,name=failures,proto3" json:"failures,omitempty"` // Recent failures, newest first
+	QueuedInstructions   int32                  `protobuf:"varint,8,opt,name=queued_instructions,json=queuedInstructions,proto3" json:"queued_instructions,omitempty"`
+	ExpiringCertificates []*ExpiringCertificate `protobuf:"bytes,9,rep,name=expiring_certificates,json=expiringCertificates,proto3" json:"expiring_certificates,omitempty"`
+	ClockSkew            []*AgentClockSkew      `protobuf:"bytes,10,rep,name=clock_skew,json=clockSkew,proto3" json:"clock_skew,omitempty"`          // Agents over agent_management.max_clock_skew
+	OpenCircuits         []*AgentCircuit        `protobuf:"bytes,11,rep,name=open_circuits,json=openCircuits,proto3" json:"open_circuits,omitempty"` // Agents the core fails calls to fast
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ClusterStatus) Reset() {
+	*x = ClusterStatus{}
+	mi := &file_api_v1_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClusterStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClusterStatus) ProtoMessage() {}
+
+func (x *ClusterStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClusterStatus.ProtoReflect.Descriptor instead.
+func (*ClusterStatus) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ClusterStatus) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ClusterStatus) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetAgents() map[string]int32 {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetMaintenance() []string {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetFreeze() *FreezeState {
+	if x != nil {
+		return x.Freeze
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetRunning() []*ClusterOperation {
+	if x != nil {
+		return x.Running
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetFailures() []*ClusterOperation {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetQueuedInstructions() int32 {
+	if x != nil {
+		return x.QueuedInstructions
+	}
+	return 0
+}
+
+func (x *ClusterStatus) GetExpiringCertificates() []*ExpiringCertificate {
+	if x != nil {
+		return x.ExpiringCertificates
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetClockSkew() []*AgentClockSkew {
+	if x != nil {
+		return x.ClockSkew
+	}
+	return nil
+}
+
+func (x *ClusterStatus) GetOpenCircuits() []*AgentCircuit {
+	if x != nil {
+		return x.OpenCircuits
+	}
+	return nil
+}
+
+type AgentClockSkew struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Skew          *durationpb.Duration   `protobuf:"bytes,2,opt,name=skew,proto3" json:"skew,omitempty"` // Agent clock minus core clock
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentClockSkew) Reset() {
+	*x = AgentClockSkew{}
+	mi := &file_api_v1_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentClockSkew) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentClockSkew) ProtoMessage() {}
+
+func (x *AgentClockSkew) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentClockSkew.ProtoReflect.Descriptor instead.
+func (*AgentClockSkew) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *AgentClockSkew) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentClockSkew) GetSkew() *durationpb.Duration {
+	if x != nil {
+		return x.Skew
+	}
+	return nil
+}
+
+// ClusterOperation is a stack change made through the core, directly or as
+// a queued instruction
+type ClusterOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // apply_stack, remove_stack, config or drain
+	Stack         string                 `protobuf:"bytes,3,opt,name=stack,proto3" json:"stack,omitempty"`
+	RequestedBy   string                 `protobuf:"bytes,4,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	RequestId     string                 `protobuf:"bytes,8,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // Of the command that made the change
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClusterOperation) Reset() {
+	*x = ClusterOperation{}
+	mi := &file_api_v1_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClusterOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClusterOperation) ProtoMessage() {}
+
+func (x *ClusterOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClusterOperation.ProtoReflect.Descriptor instead.
+func (*ClusterOperation) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ClusterOperation) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ClusterOperation) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ClusterOperation) GetStack() string {
+	if x != nil {
+		return x.Stack
+	}
+	return ""
+}
+
+func (x *ClusterOperation) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *ClusterOperation) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ClusterOperation) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *ClusterOperation) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ClusterOperation) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type ExpiringCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // "core", "ca" or "agent:<id>"
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	NotAfter      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpiringCertificate) Reset() {
+	*x = ExpiringCertificate{}
+	mi := &file_api_v1_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpiringCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpiringCertificate) ProtoMessage() {}
+
+func (x *ExpiringCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpiringCertificate.ProtoReflect.Descriptor instead.
+func (*ExpiringCertificate) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ExpiringCertificate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExpiringCertificate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *ExpiringCertificate) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
+type FreezeState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Frozen        bool                   `protobuf:"varint,1,opt,name=frozen,proto3" json:"frozen,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	SetBy         string                 `protobuf:"bytes,3,opt,name=set_by,json=setBy,proto3" json:"set_by,omitempty"`
+	SetAt         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=set_at,json=setAt,proto3" json:"set_at,omitempty"` // When frozen or last unfrozen
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FreezeState) Reset() {
+	*x = FreezeState{}
+	mi := &file_api_v1_agent_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreezeState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreezeState) ProtoMessage() {}
+
+func (x *FreezeState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreezeState.ProtoReflect.Descriptor instead.
+func (*FreezeState) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FreezeState) GetFrozen() bool {
+	if x != nil {
+		return x.Frozen
+	}
+	return false
+}
+
+func (x *FreezeState) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FreezeState) GetSetBy() string {
+	if x != nil {
+		return x.SetBy
+	}
+	return ""
+}
+
+func (x *FreezeState) GetSetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SetAt
+	}
+	return nil
+}
+
+type GetQuotaUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Only this agent
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`            // Only this namespace
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaUsageRequest) Reset() {
+	*x = GetQuotaUsageRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageRequest) ProtoMessage() {}
+
+func (x *GetQuotaUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetQuotaUsageRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetQuotaUsageRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+// QuotaUsage compares current usage with configured limits; a zero limit
+// means unlimited
+type QuotaUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*AgentQuotaUsage     `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	Namespaces    []*NamespaceQuotaUsage `protobuf:"bytes,2,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_api_v1_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *QuotaUsage) GetAgents() []*AgentQuotaUsage {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *QuotaUsage) GetNamespaces() []*NamespaceQuotaUsage {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type AgentQuotaUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Stacks        int32                  `protobuf:"varint,2,opt,name=stacks,proto3" json:"stacks,omitempty"`
+	MaxStacks     int32                  `protobuf:"varint,3,opt,name=max_stacks,json=maxStacks,proto3" json:"max_stacks,omitempty"`
+	Containers    int32                  `protobuf:"varint,4,opt,name=containers,proto3" json:"containers,omitempty"`
+	MaxContainers int32                  `protobuf:"varint,5,opt,name=max_containers,json=maxContainers,proto3" json:"max_containers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentQuotaUsage) Reset() {
+	*x = AgentQuotaUsage{}
+	mi := &file_api_v1_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentQuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentQuotaUsage) ProtoMessage() {}
+
+func (x *AgentQuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentQuotaUsage.ProtoReflect.Descriptor instead.
+func (*AgentQuotaUsage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *AgentQuotaUsage) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentQuotaUsage) GetStacks() int32 {
+	if x != nil {
+		return x.Stacks
+	}
+	return 0
+}
+
+func (x *AgentQuotaUsage) GetMaxStacks() int32 {
+	if x != nil {
+		return x.MaxStacks
+	}
+	return 0
+}
+
+func (x *AgentQuotaUsage) GetContainers() int32 {
+	if x != nil {
+		return x.Containers
+	}
+	return 0
+}
+
+func (x *AgentQuotaUsage) GetMaxContainers() int32 {
+	if x != nil {
+		return x.MaxContainers
+	}
+	return 0
+}
+
+type DiagnoseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Empty diagnoses the core
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnoseRequest) Reset() {
+	*x = DiagnoseRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnoseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnoseRequest) ProtoMessage() {}
+
+func (x *DiagnoseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnoseRequest.ProtoReflect.Descriptor instead.
+func (*DiagnoseRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *DiagnoseRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type DiagnoseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*DiagnosticCheck     `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"` // Responder's clock, for skew checks
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnoseResponse) Reset() {
+	*x = DiagnoseResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnoseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnoseResponse) ProtoMessage() {}
+
+func (x *DiagnoseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnoseResponse.ProtoReflect.Descriptor instead.
+func (*DiagnoseResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DiagnoseResponse) GetChecks() []*DiagnosticCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *DiagnoseResponse) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type DiagnosticCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // "core" or "agent:<id>"
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Status        CheckStatus            `protobuf:"varint,3,opt,name=status,proto3,enum=mandau.agent.v1.CheckStatus" json:"status,omitempty"`
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Remediation   string                 `protobuf:"bytes,5,opt,name=remediation,proto3" json:"remediation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnosticCheck) Reset() {
+	*x = DiagnosticCheck{}
+	mi := &file_api_v1_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnosticCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnosticCheck) ProtoMessage() {}
+
+func (x *DiagnosticCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnosticCheck.ProtoReflect.Descriptor instead.
+func (*DiagnosticCheck) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DiagnosticCheck) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DiagnosticCheck) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DiagnosticCheck) GetStatus() CheckStatus {
+	if x != nil {
+		return x.Status
+	}
+	return CheckStatus_CHECK_STATUS_OK
+}
+
+func (x *DiagnosticCheck) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *DiagnosticCheck) GetRemediation() string {
+	if x != nil {
+		return x.Remediation
+	}
+	return ""
+}
+
+type DiagnoseAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Pings         int32                  `protobuf:"varint,2,opt,name=pings,proto3" json:"pings,omitempty"` // Round trips to measure, 0 means 5
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnoseAgentRequest) Reset() {
+	*x = DiagnoseAgentRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnoseAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnoseAgentRequest) ProtoMessage() {}
+
+func (x *DiagnoseAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnoseAgentRequest.ProtoReflect.Descriptor instead.
+func (*DiagnoseAgentRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DiagnoseAgentRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DiagnoseAgentRequest) GetPings() int32 {
+	if x != nil {
+		return x.Pings
+	}
+	return 0
+}
+
+type AgentConnectionReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"` // Where the core dials the agent
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`   // online, offline or error
+	LastHeartbeat *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_heartbeat,json=lastHeartbeat,proto3" json:"last_heartbeat,omitempty"`
+	DialOutOnly   bool                   `protobuf:"varint,5,opt,name=dial_out_only,json=dialOutOnly,proto3" json:"dial_out_only,omitempty"` // The core never dials it; only heartbeats apply
+	// State of the core's gRPC channel to the agent after the round trips,
+	// such as READY or TRANSIENT_FAILURE
+	ChannelState      string             `protobuf:"bytes,6,opt,name=channel_state,json=channelState,proto3" json:"channel_state,omitempty"`
+	RoundTrips        *RoundTrips        `protobuf:"bytes,7,opt,name=round_trips,json=roundTrips,proto3" json:"round_trips,omitempty"`
+	ServerCertificate *PeerCertificate   `protobuf:"bytes,8,opt,name=server_certificate,json=serverCertificate,proto3" json:"server_certificate,omitempty"` // What the agent serves the core
+	ClientCertificate *PeerCertificate   `protobuf:"bytes,9,opt,name=client_certificate,json=clientCertificate,proto3" json:"client_certificate,omitempty"` // What the agent last presented
+	Reverse           *CoreConnectivity  `protobuf:"bytes,10,opt,name=reverse,proto3" json:"reverse,omitempty"`                                             // The agent's own check of the core
+	Checks            []*DiagnosticCheck `protobuf:"bytes,11,rep,name=checks,proto3" json:"checks,omitempty"`                                               // Findings, with remediations
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AgentConnectionReport) Reset() {
+	*x = AgentConnectionReport{}
+	mi := &file_api_v1_agent_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConnectionReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConnectionReport) ProtoMessage() {}
+
+func (x *AgentConnectionReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConnectionReport.ProtoReflect.Descriptor instead.
+func (*AgentConnectionReport) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *AgentConnectionReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentConnectionReport) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AgentConnectionReport) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AgentConnectionReport) GetLastHeartbeat() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastHeartbeat
+	}
+	return nil
+}
+
+func (x *AgentConnectionReport) GetDialOutOnly() bool {
+	if x != nil {
+		return x.DialOutOnly
+	}
+	return false
+}
+
+func (x *AgentConnectionReport) GetChannelState() string {
+	if x != nil {
+		return x.ChannelState
+	}
+	return ""
+}
+
+func (x *AgentConnectionReport) GetRoundTrips() *RoundTrips {
+	if x != nil {
+		return x.RoundTrips
+	}
+	return nil
+}
+
+func (x *AgentConnectionReport) GetServerCertificate() *PeerCertificate {
+	if x != nil {
+		return x.ServerCertificate
+	}
+	return nil
+}
+
+func (x *AgentConnectionReport) GetClientCertificate() *PeerCertificate {
+	if x != nil {
+		return x.ClientCertificate
+	}
+	return nil
+}
+
+func (x *AgentConnectionReport) GetReverse() *CoreConnectivity {
+	if x != nil {
+		return x.Reverse
+	}
+	return nil
+}
+
+func (x *AgentConnectionReport) GetChecks() []*DiagnosticCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+type RoundTrips struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sent          int32                  `protobuf:"varint,1,opt,name=sent,proto3" json:"sent,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Min           *durationpb.Duration   `protobuf:"bytes,3,opt,name=min,proto3" json:"min,omitempty"`
+	Avg           *durationpb.Duration   `protobuf:"bytes,4,opt,name=avg,proto3" json:"avg,omitempty"`
+	Max           *durationpb.Duration   `protobuf:"bytes,5,opt,name=max,proto3" json:"max,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"` // Of the last failed round trip
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoundTrips) Reset() {
+	*x = RoundTrips{}
+	mi := &file_api_v1_agent_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoundTrips) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundTrips) ProtoMessage() {}
+
+func (x *RoundTrips) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundTrips.ProtoReflect.Descriptor instead.
+func (*RoundTrips) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *RoundTrips) GetSent() int32 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *RoundTrips) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *RoundTrips) GetMin() *durationpb.Duration {
+	if x != nil {
+		return x.Min
+	}
+	return nil
+}
+
+func (x *RoundTrips) GetAvg() *durationpb.Duration {
+	if x != nil {
+		return x.Avg
+	}
+	return nil
+}
+
+func (x *RoundTrips) GetMax() *durationpb.Duration {
+	if x != nil {
+		return x.Max
+	}
+	return nil
+}
+
+func (x *RoundTrips) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// PeerCertificate is a certificate presented on a connection
+type PeerCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Issuer        string                 `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Sans          []string               `protobuf:"bytes,3,rep,name=sans,proto3" json:"sans,omitempty"`
+	NotAfter      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	ExpectedName  string                 `protobuf:"bytes,5,opt,name=expected_name,json=expectedName,proto3" json:"expected_name,omitempty"` // The name peers verify it against
+	NameMatches   bool                   `protobuf:"varint,6,opt,name=name_matches,json=nameMatches,proto3" json:"name_matches,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // Why the handshake or its verification failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerCertificate) Reset() {
+	*x = PeerCertificate{}
+	mi := &file_api_v1_agent_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerCertificate) ProtoMessage() {}
+
+func (x *PeerCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerCertificate.ProtoReflect.Descriptor instead.
+func (*PeerCertificate) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PeerCertificate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *PeerCertificate) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *PeerCertificate) GetSans() []string {
+	if x != nil {
+		return x.Sans
+	}
+	return nil
+}
+
+func (x *PeerCertificate) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
+func (x *PeerCertificate) GetExpectedName() string {
+	if x != nil {
+		return x.ExpectedName
+	}
+	return ""
+}
+
+func (x *PeerCertificate) GetNameMatches() bool {
+	if x != nil {
+		return x.NameMatches
+	}
+	return false
+}
+
+func (x *PeerCertificate) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CheckCoreConnectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckCoreConnectionRequest) Reset() {
+	*x = CheckCoreConnectionRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckCoreConnectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCoreConnectionRequest) ProtoMessage() {}
+
+func (x *CheckCoreConnectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCoreConnectionRequest.ProtoReflect.Descriptor instead.
+func (*CheckCoreConnectionRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{47}
+}
+
+// CoreConnectivity is an agent's check of its connection to the core
+type CoreConnectivity struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CoreAddress     string                 `protobuf:"bytes,1,opt,name=core_address,json=coreAddress,proto3" json:"core_address,omitempty"`
+	ChannelState    string                 `protobuf:"bytes,2,opt,name=channel_state,json=channelState,proto3" json:"channel_state,omitempty"` // Of the agent's gRPC channel to the core
+	Handshake       *durationpb.Duration   `protobuf:"bytes,3,opt,name=handshake,proto3" json:"handshake,omitempty"`                           // Of a fresh TLS connection
+	CoreCertificate *PeerCertificate       `protobuf:"bytes,4,opt,name=core_certificate,json=coreCertificate,proto3" json:"core_certificate,omitempty"`
+	Error           string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CoreConnectivity) Reset() {
+	*x = CoreConnectivity{}
+	mi := &file_api_v1_agent_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoreConnectivity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoreConnectivity) ProtoMessage() {}
+
+func (x *CoreConnectivity) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoreConnectivity.ProtoReflect.Descriptor instead.
+func (*CoreConnectivity) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *CoreConnectivity) GetCoreAddress() string {
+	if x != nil {
+		return x.CoreAddress
+	}
+	return ""
+}
+
+func (x *CoreConnectivity) GetChannelState() string {
+	if x != nil {
+		return x.ChannelState
+	}
+	return ""
+}
+
+func (x *CoreConnectivity) GetHandshake() *durationpb.Duration {
+	if x != nil {
+		return x.Handshake
+	}
+	return nil
+}
+
+func (x *CoreConnectivity) GetCoreCertificate() *PeerCertificate {
+	if x != nil {
+		return x.CoreCertificate
+	}
+	return nil
+}
+
+func (x *CoreConnectivity) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetResourceReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refresh       bool                   `protobuf:"varint,1,opt,name=refresh,proto3" json:"refresh,omitempty"` // Take a new snapshot instead of returning the latest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResourceReportRequest) Reset() {
+	*x = GetResourceReportRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResourceReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResourceReportRequest) ProtoMessage() {}
+
+func (x *GetResourceReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResourceReportRequest.ProtoReflect.Descriptor instead.
+func (*GetResourceReportRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetResourceReportRequest) GetRefresh() bool {
+	if x != nil {
+		return x.Refresh
+	}
+	return false
+}
+
+// ResourceReport is a snapshot of stack resources for chargeback
+type ResourceReport struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	GeneratedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	Stacks      []*StackUsage          `protobuf:"bytes,2,rep,name=stacks,proto3" json:"stacks,omitempty"`
+	// Agents whose stacks could not be read, with the error, by agent ID
+	AgentErrors   map[string]string `protobuf:"bytes,3,rep,name=agent_errors,json=agentErrors,proto3" json:"agent_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceReport) Reset() {
+	*x = ResourceReport{}
+	mi := &file_api_v1_agent_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceReport) ProtoMessage() {}
+
+func (x *ResourceReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceReport.ProtoReflect.Descriptor instead.
+func (*ResourceReport) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ResourceReport) GetGeneratedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return nil
+}
+
+func (x *ResourceReport) GetStacks() []*StackUsage {
+	if x != nil {
+		return x.Stacks
+	}
+	return nil
+}
+
+func (x *ResourceReport) GetAgentErrors() map[string]string {
+	if x != nil {
+		return x.AgentErrors
+	}
+	return nil
+}
+
+type StackUsage struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Namespace         string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Stack             string                 `protobuf:"bytes,3,opt,name=stack,proto3" json:"stack,omitempty"`
+	State             StackState             `protobuf:"varint,4,opt,name=state,proto3,enum=mandau.agent.v1.StackState" json:"state,omitempty"`
+	Owner             *StackOwner            `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	Labels            map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Containers        int32                  `protobuf:"varint,7,opt,name=containers,proto3" json:"containers,omitempty"` // Declared in compose
+	RunningContainers int32                  `protobuf:"varint,8,opt,name=running_containers,json=runningContainers,proto3" json:"running_containers,omitempty"`
+	Cpus              float64                `protobuf:"fixed64,9,opt,name=cpus,proto3" json:"cpus,omitempty"`                                  // Reserved
+	MemoryBytes       int64                  `protobuf:"varint,10,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"` // Reserved
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StackUsage) Reset() {
+	*x = StackUsage{}
+	mi := &file_api_v1_agent_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackUsage) ProtoMessage() {}
+
+func (x *StackUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackUsage.ProtoReflect.Descriptor instead.
+func (*StackUsage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *StackUsage) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *StackUsage) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *StackUsage) GetStack() string {
+	if x != nil {
+		return x.Stack
+	}
+	return ""
+}
+
+func (x *StackUsage) GetState() StackState {
+	if x != nil {
+		return x.State
+	}
+	return StackState_STACK_STATE_UNKNOWN
+}
+
+func (x *StackUsage) GetOwner() *StackOwner {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *StackUsage) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *StackUsage) GetContainers() int32 {
+	if x != nil {
+		return x.Containers
+	}
+	return 0
+}
+
+func (x *StackUsage) GetRunningContainers() int32 {
+	if x != nil {
+		return x.RunningContainers
+	}
+	return 0
+}
+
+func (x *StackUsage) GetCpus() float64 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *StackUsage) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+type GetPatchComplianceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"` // Only members of this group
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPatchComplianceRequest) Reset() {
+	*x = GetPatchComplianceRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPatchComplianceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPatchComplianceRequest) ProtoMessage() {}
+
+func (x *GetPatchComplianceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPatchComplianceRequest.ProtoReflect.Descriptor instead.
+func (*GetPatchComplianceRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *GetPatchComplianceRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+// PatchCompliance is the patch status of the agents serving the host
+// environment that the caller can read
+type PatchCompliance struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	GeneratedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	Agents      []*AgentPatchStatus    `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"`
+	// Agents whose status could not be read, with the error, by agent ID
+	AgentErrors   map[string]string `protobuf:"bytes,3,rep,name=agent_errors,json=agentErrors,proto3" json:"agent_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchCompliance) Reset() {
+	*x = PatchCompliance{}
+	mi := &file_api_v1_agent_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchCompliance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchCompliance) ProtoMessage() {}
+
+func (x *PatchCompliance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchCompliance.ProtoReflect.Descriptor instead.
+func (*PatchCompliance) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *PatchCompliance) GetGeneratedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return nil
+}
+
+func (x *PatchCompliance) GetAgents() []*AgentPatchStatus {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *PatchCompliance) GetAgentErrors() map[string]string {
+	if x != nil {
+		return x.AgentErrors
+	}
+	return nil
+}
+
+// RunFleetCommandRequest selects agents by labels, group and IDs; the
+// selected agents match all that are set. Selecting every agent takes all.
+type RunFleetCommandRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Command     string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"` // Name in the agents' commands allowlist
+	Labels      map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Group       string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	AgentIds    []string               `protobuf:"bytes,4,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	All         bool                   `protobuf:"varint,5,opt,name=all,proto3" json:"all,omitempty"`
+	Parallelism int32                  `protobuf:"varint,6,opt,name=parallelism,proto3" json:"parallelism,omitempty"` // Agents running it at once; default and at most fan_out.parallelism
+	// Per agent, cut short further by the command's own timeout; default 1m
+	Timeout       *durationpb.Duration `protobuf:"bytes,7,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunFleetCommandRequest) Reset() {
+	*x = RunFleetCommandRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunFleetCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunFleetCommandRequest) ProtoMessage() {}
+
+func (x *RunFleetCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunFleetCommandRequest.ProtoReflect.Descriptor instead.
+func (*RunFleetCommandRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *RunFleetCommandRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *RunFleetCommandRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RunFleetCommandRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *RunFleetCommandRequest) GetAgentIds() []string {
+	if x != nil {
+		return x.AgentIds
+	}
+	return nil
+}
+
+func (x *RunFleetCommandRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *RunFleetCommandRequest) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+func (x *RunFleetCommandRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+// FleetCommandReport is how a fleet command went on each selected agent
+type FleetCommandReport struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Command   string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Duration  *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	Results   []*CommandResult       `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"` // Of the agents it ran on, by agent ID
+	// Selected agents it could not be run on, with the reason, by agent ID
+	AgentErrors   map[string]string `protobuf:"bytes,5,rep,name=agent_errors,json=agentErrors,proto3" json:"agent_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FleetCommandReport) Reset() {
+	*x = FleetCommandReport{}
+	mi := &file_api_v1_agent_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FleetCommandReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FleetCommandReport) ProtoMessage() {}
+
+func (x *FleetCommandReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FleetCommandReport.ProtoReflect.Descriptor instead.
+func (*FleetCommandReport) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *FleetCommandReport) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *FleetCommandReport) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *FleetCommandReport) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *FleetCommandReport) GetResults() []*CommandResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *FleetCommandReport) GetAgentErrors() map[string]string {
+	if x != nil {
+		return x.AgentErrors
+	}
+	return nil
+}
+
+// CommandResult is how a run of an allowlisted command ended on an agent
+type CommandResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"` // -1 when it did not exit by itself
+	Stdout        []byte                 `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`                      // The end of its output, with secrets masked
+	Stderr        []byte                 `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Truncated     bool                   `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"` // Output was longer than the sample kept
+	Duration      *durationpb.Duration   `protobuf:"bytes,6,opt,name=duration,proto3" json:"duration,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // Why it did not run to completion: not started or timed out
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandResult) Reset() {
+	*x = CommandResult{}
+	mi := &file_api_v1_agent_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandResult) ProtoMessage() {}
+
+func (x *CommandResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
+func (*CommandResult) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *CommandResult) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *CommandResult) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandResult) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *CommandResult) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *CommandResult) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *CommandResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *CommandResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`                                                                             // Only members of this group
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Only agents carrying all of these labels
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInventoryRequest) Reset() {
+	*x = GetInventoryRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInventoryRequest) ProtoMessage() {}
+
+func (x *GetInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInventoryRequest.ProtoReflect.Descriptor instead.
+func (*GetInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetInventoryRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GetInventoryRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+// Inventory is what the selected agents run, limited to what the caller may
+// read on each
+type Inventory struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	GeneratedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	Agents      []*AgentInventory      `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"` // By agent ID
+	// Agents that could not be reached, with the error, by agent ID; they are
+	// listed in agents with their registration alone
+	AgentErrors   map[string]string `protobuf:"bytes,3,rep,name=agent_errors,json=agentErrors,proto3" json:"agent_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Inventory) Reset() {
+	*x = Inventory{}
+	mi := &file_api_v1_agent_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Inventory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Inventory) ProtoMessage() {}
+
+func (x *Inventory) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Inventory.ProtoReflect.Descriptor instead.
+func (*Inventory) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *Inventory) GetGeneratedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return nil
+}
+
+func (x *Inventory) GetAgents() []*AgentInventory {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *Inventory) GetAgentErrors() map[string]string {
+	if x != nil {
+		return x.AgentErrors
+	}
+	return nil
+}
+
+// AgentInventory is one agent's part of the inventory. Parts the agent does
+// not serve or the caller may not read are left empty.
+type AgentInventory struct {
+	state        protoimpl.MessageState  `protogen:"open.v1"`
+	Agent        *Agent                  `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	Host         *InventoryHost          `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Stacks       []*Stack                `protobuf:"bytes,3,rep,name=stacks,proto3" json:"stacks,omitempty"` // With image IDs and digests of their containers
+	VirtualHosts []*InventoryVirtualHost `protobuf:"bytes,4,rep,name=virtual_hosts,json=virtualHosts,proto3" json:"virtual_hosts,omitempty"`
+	DnsZones     []string                `protobuf:"bytes,5,rep,name=dns_zones,json=dnsZones,proto3" json:"dns_zones,omitempty"`
+	Certificates []*InventoryCertificate `protobuf:"bytes,6,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	// Parts that could not be read, with the error, by part: "host",
+	// "stacks", "virtual_hosts", "dns_zones" or "certificates"
+	Errors        map[string]string `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentInventory) Reset() {
+	*x = AgentInventory{}
+	mi := &file_api_v1_agent_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentInventory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentInventory) ProtoMessage() {}
+
+func (x *AgentInventory) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentInventory.ProtoReflect.Descriptor instead.
+func (*AgentInventory) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *AgentInventory) GetAgent() *Agent {
+	if x != nil {
+		return x.Agent
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetHost() *InventoryHost {
+	if x != nil {
+		return x.Host
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetStacks() []*Stack {
+	if x != nil {
+		return x.Stacks
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetVirtualHosts() []*InventoryVirtualHost {
+	if x != nil {
+		return x.VirtualHosts
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetDnsZones() []string {
+	if x != nil {
+		return x.DnsZones
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetCertificates() []*InventoryCertificate {
+	if x != nil {
+		return x.Certificates
+	}
+	return nil
+}
+
+func (x *AgentInventory) GetErrors() map[string]string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type InventoryHost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Kernel        string                 `protobuf:"bytes,3,opt,name=kernel,proto3" json:"kernel,omitempty"`
+	Architecture  string                 `protobuf:"bytes,4,opt,name=architecture,proto3" json:"architecture,omitempty"`
+	CpuCores      int32                  `protobuf:"varint,5,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
+	MemoryMb      int64                  `protobuf:"varint,6,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	DiskGb        int64                  `protobuf:"varint,7,opt,name=disk_gb,json=diskGb,proto3" json:"disk_gb,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InventoryHost) Reset() {
+	*x = InventoryHost{}
+	mi := &file_api_v1_agent_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryHost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryHost) ProtoMessage() {}
+
+func (x *InventoryHost) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryHost.ProtoReflect.Descriptor instead.
+func (*InventoryHost) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *InventoryHost) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *InventoryHost) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *InventoryHost) GetKernel() string {
+	if x != nil {
+		return x.Kernel
+	}
+	return ""
+}
+
+func (x *InventoryHost) GetArchitecture() string {
+	if x != nil {
+		return x.Architecture
+	}
+	return ""
+}
+
+func (x *InventoryHost) GetCpuCores() int32 {
+	if x != nil {
+		return x.CpuCores
+	}
+	return 0
+}
+
+func (x *InventoryHost) GetMemoryMb() int64 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *InventoryHost) GetDiskGb() int64 {
+	if x != nil {
+		return x.DiskGb
+	}
+	return 0
+}
+
+type InventoryVirtualHost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InventoryVirtualHost) Reset() {
+	*x = InventoryVirtualHost{}
+	mi := &file_api_v1_agent_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryVirtualHost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryVirtualHost) ProtoMessage() {}
+
+func (x *InventoryVirtualHost) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryVirtualHost.ProtoReflect.Descriptor instead.
+func (*InventoryVirtualHost) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *InventoryVirtualHost) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InventoryVirtualHost) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type InventoryCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Issuer        string                 `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // As the ACME client reports it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InventoryCertificate) Reset() {
+	*x = InventoryCertificate{}
+	mi := &file_api_v1_agent_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryCertificate) ProtoMessage() {}
+
+func (x *InventoryCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryCertificate.ProtoReflect.Descriptor instead.
+func (*InventoryCertificate) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *InventoryCertificate) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *InventoryCertificate) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *InventoryCertificate) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type AgentPatchStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status        *PatchStatus           `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentPatchStatus) Reset() {
+	*x = AgentPatchStatus{}
+	mi := &file_api_v1_agent_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentPatchStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentPatchStatus) ProtoMessage() {}
+
+func (x *AgentPatchStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentPatchStatus.ProtoReflect.Descriptor instead.
+func (*AgentPatchStatus) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *AgentPatchStatus) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentPatchStatus) GetStatus() *PatchStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// PatchStatus is what a host's package manager has pending, as of its last
+// package index refresh
+type PatchStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PackageManager  string                 `protobuf:"bytes,1,opt,name=package_manager,json=packageManager,proto3" json:"package_manager,omitempty"` // apt or yum
+	SecurityUpdates []*PackageUpdate       `protobuf:"bytes,2,rep,name=security_updates,json=securityUpdates,proto3" json:"security_updates,omitempty"`
+	Updates         int32                  `protobuf:"varint,3,opt,name=updates,proto3" json:"updates,omitempty"` // Pending updates, security ones included
+	RebootRequired  bool                   `protobuf:"varint,4,opt,name=reboot_required,json=rebootRequired,proto3" json:"reboot_required,omitempty"`
+	// Packages asking for the reboot, where the host records them
+	RebootPackages  []string               `protobuf:"bytes,5,rep,name=reboot_packages,json=rebootPackages,proto3" json:"reboot_packages,omitempty"`
+	RunningKernel   string                 `protobuf:"bytes,6,opt,name=running_kernel,json=runningKernel,proto3" json:"running_kernel,omitempty"`
+	InstalledKernel string                 `protobuf:"bytes,7,opt,name=installed_kernel,json=installedKernel,proto3" json:"installed_kernel,omitempty"` // Newest kernel installed
+	CheckedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PatchStatus) Reset() {
+	*x = PatchStatus{}
+	mi := &file_api_v1_agent_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchStatus) ProtoMessage() {}
+
+func (x *PatchStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchStatus.ProtoReflect.Descriptor instead.
+func (*PatchStatus) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *PatchStatus) GetPackageManager() string {
+	if x != nil {
+		return x.PackageManager
+	}
+	return ""
+}
+
+func (x *PatchStatus) GetSecurityUpdates() []*PackageUpdate {
+	if x != nil {
+		return x.SecurityUpdates
+	}
+	return nil
+}
+
+func (x *PatchStatus) GetUpdates() int32 {
+	if x != nil {
+		return x.Updates
+	}
+	return 0
+}
+
+func (x *PatchStatus) GetRebootRequired() bool {
+	if x != nil {
+		return x.RebootRequired
+	}
+	return false
+}
+
+func (x *PatchStatus) GetRebootPackages() []string {
+	if x != nil {
+		return x.RebootPackages
+	}
+	return nil
+}
+
+func (x *PatchStatus) GetRunningKernel() string {
+	if x != nil {
+		return x.RunningKernel
+	}
+	return ""
+}
+
+func (x *PatchStatus) GetInstalledKernel() string {
+	if x != nil {
+		return x.InstalledKernel
+	}
+	return ""
+}
+
+func (x *PatchStatus) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+type PackageUpdate struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	CurrentVersion   string                 `protobuf:"bytes,2,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"` // Empty where the package manager omits it
+	AvailableVersion string                 `protobuf:"bytes,3,opt,name=available_version,json=availableVersion,proto3" json:"available_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PackageUpdate) Reset() {
+	*x = PackageUpdate{}
+	mi := &file_api_v1_agent_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackageUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackageUpdate) ProtoMessage() {}
+
+func (x *PackageUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackageUpdate.ProtoReflect.Descriptor instead.
+func (*PackageUpdate) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *PackageUpdate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PackageUpdate) GetCurrentVersion() string {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return ""
+}
+
+func (x *PackageUpdate) GetAvailableVersion() string {
+	if x != nil {
+		return x.AvailableVersion
+	}
+	return ""
+}
+
+type NamespaceQuotaUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Namespace      string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Stacks         int32                  `protobuf:"varint,2,opt,name=stacks,proto3" json:"stacks,omitempty"`
+	MaxStacks      int32                  `protobuf:"varint,3,opt,name=max_stacks,json=maxStacks,proto3" json:"max_stacks,omitempty"`
+	Cpus           float64                `protobuf:"fixed64,4,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	MaxCpus        float64                `protobuf:"fixed64,5,opt,name=max_cpus,json=maxCpus,proto3" json:"max_cpus,omitempty"`
+	MemoryBytes    int64                  `protobuf:"varint,6,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	MaxMemoryBytes int64                  `protobuf:"varint,7,opt,name=max_memory_bytes,json=maxMemoryBytes,proto3" json:"max_memory_bytes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NamespaceQuotaUsage) Reset() {
+	*x = NamespaceQuotaUsage{}
+	mi := &file_api_v1_agent_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamespaceQuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceQuotaUsage) ProtoMessage() {}
+
+func (x *NamespaceQuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceQuotaUsage.ProtoReflect.Descriptor instead.
+func (*NamespaceQuotaUsage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *NamespaceQuotaUsage) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceQuotaUsage) GetStacks() int32 {
+	if x != nil {
+		return x.Stacks
+	}
+	return 0
+}
+
+func (x *NamespaceQuotaUsage) GetMaxStacks() int32 {
+	if x != nil {
+		return x.MaxStacks
+	}
+	return 0
+}
+
+func (x *NamespaceQuotaUsage) GetCpus() float64 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *NamespaceQuotaUsage) GetMaxCpus() float64 {
+	if x != nil {
+		return x.MaxCpus
+	}
+	return 0
+}
+
+func (x *NamespaceQuotaUsage) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *NamespaceQuotaUsage) GetMaxMemoryBytes() int64 {
+	if x != nil {
+		return x.MaxMemoryBytes
+	}
+	return 0
+}
+
+type RegisterRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Hostname     string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Version      string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	AgentId      string                 `protobuf:"bytes,5,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Optional persistent agent ID
+	Labels       map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Capabilities []string               `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// The agent accepts no inbound connections; the core hands it work as
+	// instructions on heartbeat responses instead of calling it
+	DialOutOnly bool               `protobuf:"varint,6,opt,name=dial_out_only,json=dialOutOnly,proto3" json:"dial_out_only,omitempty"`
+	Plugins     []*InstalledPlugin `protobuf:"bytes,7,rep,name=plugins,proto3" json:"plugins,omitempty"` // Installed from the plugin index
+	// Host service plugins enabled in the agent config, and whether the host
+	// can run them
+	PluginAvailability []*PluginAvailability `protobuf:"bytes,8,rep,name=plugin_availability,json=pluginAvailability,proto3" json:"plugin_availability,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *RegisterRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetDialOutOnly() bool {
+	if x != nil {
+		return x.DialOutOnly
+	}
+	return false
+}
+
+func (x *RegisterRequest) GetPlugins() []*InstalledPlugin {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetPluginAvailability() []*PluginAvailability {
+	if x != nil {
+		return x.PluginAvailability
+	}
+	return nil
+}
+
+// PluginAvailability is whether a host service plugin enabled in the agent
+// config can run on the agent's host, probed at startup
+type PluginAvailability struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plugin        string                 `protobuf:"bytes,1,opt,name=plugin,proto3" json:"plugin,omitempty"`         // e.g. "systemd-manager"
+	Capability    string                 `protobuf:"bytes,2,opt,name=capability,proto3" json:"capability,omitempty"` // The agent capability it provides, e.g. "systemd"
+	Available     bool                   `protobuf:"varint,3,opt,name=available,proto3" json:"available,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"` // Why not, e.g. "systemd runs only on Linux, not darwin"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginAvailability) Reset() {
+	*x = PluginAvailability{}
+	mi := &file_api_v1_agent_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginAvailability) ProtoMessage() {}
+
+func (x *PluginAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginAvailability.ProtoReflect.Descriptor instead.
+func (*PluginAvailability) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *PluginAvailability) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+func (x *PluginAvailability) GetCapability() string {
+	if x != nil {
+		return x.Capability
+	}
+	return ""
+}
+
+func (x *PluginAvailability) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *PluginAvailability) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Certificate       []byte                 `protobuf:"bytes,2,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	HeartbeatInterval *durationpb.Duration   `protobuf:"bytes,3,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *RegisterResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegisterResponse) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *RegisterResponse) GetHeartbeatInterval() *durationpb.Duration {
+	if x != nil {
+		return x.HeartbeatInterval
+	}
+	return nil
+}
+
+type GetPluginIndexRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPluginIndexRequest) Reset() {
+	*x = GetPluginIndexRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPluginIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPluginIndexRequest) ProtoMessage() {}
+
+func (x *GetPluginIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPluginIndexRequest.ProtoReflect.Descriptor instead.
+func (*GetPluginIndexRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{70}
+}
+
+// PluginIndex is what the core's plugin index offers
+type PluginIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plugins       []*IndexedPlugin       `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	KeyId         string                 `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"` // Key the index is signed with
+	Published     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=published,proto3" json:"published,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginIndex) Reset() {
+	*x = PluginIndex{}
+	mi := &file_api_v1_agent_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginIndex) ProtoMessage() {}
+
+func (x *PluginIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginIndex.ProtoReflect.Descriptor instead.
+func (*PluginIndex) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *PluginIndex) GetPlugins() []*IndexedPlugin {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+func (x *PluginIndex) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *PluginIndex) GetPublished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Published
+	}
+	return nil
+}
+
+type IndexedPlugin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Versions      []string               `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty"`   // Newest first
+	Platforms     []string               `protobuf:"bytes,4,rep,name=platforms,proto3" json:"platforms,omitempty"` // os/arch with a build in any release
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexedPlugin) Reset() {
+	*x = IndexedPlugin{}
+	mi := &file_api_v1_agent_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexedPlugin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexedPlugin) ProtoMessage() {}
+
+func (x *IndexedPlugin) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexedPlugin.ProtoReflect.Descriptor instead.
+func (*IndexedPlugin) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *IndexedPlugin) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *IndexedPlugin) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *IndexedPlugin) GetVersions() []string {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *IndexedPlugin) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+type InstallPluginRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"` // Empty installs the newest release
+	// The signed index, set by the core when it forwards the request
+	SignedIndex   []byte `protobuf:"bytes,4,opt,name=signed_index,json=signedIndex,proto3" json:"signed_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallPluginRequest) Reset() {
+	*x = InstallPluginRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallPluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallPluginRequest) ProtoMessage() {}
+
+func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallPluginRequest.ProtoReflect.Descriptor instead.
+func (*InstallPluginRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *InstallPluginRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetSignedIndex() []byte {
+	if x != nil {
+		return x.SignedIndex
+	}
+	return nil
+}
+
+type InstalledPlugin struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Sha256  string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Path    string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Enabled bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Running in the agent. An upgraded plugin runs once the agent restarts.
+	Loaded        bool                   `protobuf:"varint,6,opt,name=loaded,proto3" json:"loaded,omitempty"`
+	InstalledAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=installed_at,json=installedAt,proto3" json:"installed_at,omitempty"`
+	AgentId       string                 `protobuf:"bytes,8,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Set in listings
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstalledPlugin) Reset() {
+	*x = InstalledPlugin{}
+	mi := &file_api_v1_agent_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstalledPlugin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstalledPlugin) ProtoMessage() {}
+
+func (x *InstalledPlugin) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstalledPlugin.ProtoReflect.Descriptor instead.
+func (*InstalledPlugin) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *InstalledPlugin) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InstalledPlugin) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *InstalledPlugin) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *InstalledPlugin) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *InstalledPlugin) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *InstalledPlugin) GetLoaded() bool {
+	if x != nil {
+		return x.Loaded
+	}
+	return false
+}
+
+func (x *InstalledPlugin) GetInstalledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.InstalledAt
+	}
+	return nil
+}
+
+func (x *InstalledPlugin) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListInstalledPluginsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Empty lists every agent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInstalledPluginsRequest) Reset() {
+	*x = ListInstalledPluginsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInstalledPluginsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInstalledPluginsRequest) ProtoMessage() {}
+
+func (x *ListInstalledPluginsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInstalledPluginsRequest.ProtoReflect.Descriptor instead.
+func (*ListInstalledPluginsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ListInstalledPluginsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListInstalledPluginsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plugins       []*InstalledPlugin     `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInstalledPluginsResponse) Reset() {
+	*x = ListInstalledPluginsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInstalledPluginsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInstalledPluginsResponse) ProtoMessage() {}
+
+func (x *ListInstalledPluginsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInstalledPluginsResponse.ProtoReflect.Descriptor instead.
+func (*ListInstalledPluginsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *ListInstalledPluginsResponse) GetPlugins() []*InstalledPlugin {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+type DescribePluginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribePluginRequest) Reset() {
+	*x = DescribePluginRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribePluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribePluginRequest) ProtoMessage() {}
+
+func (x *DescribePluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribePluginRequest.ProtoReflect.Descriptor instead.
+func (*DescribePluginRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *DescribePluginRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DescribePluginRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// PluginPermissions is the host access a plugin is confined to
+type PluginPermissions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Exec          []string               `protobuf:"bytes,1,rep,name=exec,proto3" json:"exec,omitempty"`       // Binaries it may run
+	Write         []string               `protobuf:"bytes,2,rep,name=write,proto3" json:"write,omitempty"`     // Paths it may write under
+	Network       []string               `protobuf:"bytes,3,rep,name=network,proto3" json:"network,omitempty"` // host:port it may dial
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginPermissions) Reset() {
+	*x = PluginPermissions{}
+	mi := &file_api_v1_agent_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginPermissions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginPermissions) ProtoMessage() {}
+
+func (x *PluginPermissions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginPermissions.ProtoReflect.Descriptor instead.
+func (*PluginPermissions) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *PluginPermissions) GetExec() []string {
+	if x != nil {
+		return x.Exec
+	}
+	return nil
+}
+
+func (x *PluginPermissions) GetWrite() []string {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *PluginPermissions) GetNetwork() []string {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+type PluginDescription struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version      string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Capabilities []string               `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Source       string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"` // "builtin", "service" or "installed"
+	// Host access goes through a sandbox limited to permissions. Plugins
+	// that are not sandboxed have whatever access the agent has.
+	Sandboxed     bool               `protobuf:"varint,5,opt,name=sandboxed,proto3" json:"sandboxed,omitempty"`
+	Permissions   *PluginPermissions `protobuf:"bytes,6,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	AgentId       string             `protobuf:"bytes,7,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginDescription) Reset() {
+	*x = PluginDescription{}
+	mi := &file_api_v1_agent_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginDescription) ProtoMessage() {}
+
+func (x *PluginDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginDescription.ProtoReflect.Descriptor instead.
+func (*PluginDescription) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *PluginDescription) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PluginDescription) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PluginDescription) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *PluginDescription) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PluginDescription) GetSandboxed() bool {
+	if x != nil {
+		return x.Sandboxed
+	}
+	return false
+}
+
+func (x *PluginDescription) GetPermissions() *PluginPermissions {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *PluginDescription) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type Stack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	State         StackState             `protobuf:"varint,4,opt,name=state,proto3,enum=mandau.agent.v1.StackState" json:"state,omitempty"`
+	Containers    []*Container           `protobuf:"bytes,5,rep,name=containers,proto3" json:"containers,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Owner         *StackOwner            `protobuf:"bytes,9,opt,name=owner,proto3" json:"owner,omitempty"`
+	Namespace     string                 `protobuf:"bytes,10,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Resources     *StackResources        `protobuf:"bytes,11,opt,name=resources,proto3" json:"resources,omitempty"`
+	AgentId       string                 `protobuf:"bytes,12,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`       // Set in fleet-wide listings
+	DiskUsage     *StackDiskUsage        `protobuf:"bytes,13,opt,name=disk_usage,json=diskUsage,proto3" json:"disk_usage,omitempty"` // Set when the listing asks for it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stack) Reset() {
+	*x = Stack{}
+	mi := &file_api_v1_agent_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stack) ProtoMessage() {}
+
+func (x *Stack) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stack.ProtoReflect.Descriptor instead.
+func (*Stack) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *Stack) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Stack) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Stack) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Stack) GetState() StackState {
+	if x != nil {
+		return x.State
+	}
+	return StackState_STACK_STATE_UNKNOWN
+}
+
+func (x *Stack) GetContainers() []*Container {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+func (x *Stack) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Stack) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Stack) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Stack) GetOwner() *StackOwner {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *Stack) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Stack) GetResources() *StackResources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *Stack) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Stack) GetDiskUsage() *StackDiskUsage {
+	if x != nil {
+		return x.DiskUsage
+	}
+	return nil
+}
+
+// StackDiskUsage is what a stack takes on its agent's disk, in bytes
+type StackDiskUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DirectoryBytes int64                  `protobuf:"varint,1,opt,name=directory_bytes,json=directoryBytes,proto3" json:"directory_bytes,omitempty"` // Compose, .env, hooks and metadata
+	VolumeBytes    int64                  `protobuf:"varint,2,opt,name=volume_bytes,json=volumeBytes,proto3" json:"volume_bytes,omitempty"`          // Named volumes of the compose project
+	ContainerBytes int64                  `protobuf:"varint,3,opt,name=container_bytes,json=containerBytes,proto3" json:"container_bytes,omitempty"` // Writable layers of its containers
+	// Images its containers run; they may be shared with other stacks and do
+	// not count towards the limit
+	ImageBytes    int64 `protobuf:"varint,4,opt,name=image_bytes,json=imageBytes,proto3" json:"image_bytes,omitempty"`
+	LimitBytes    int64 `protobuf:"varint,5,opt,name=limit_bytes,json=limitBytes,proto3" json:"limit_bytes,omitempty"` // The agent's stacks.max_size; zero means none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackDiskUsage) Reset() {
+	*x = StackDiskUsage{}
+	mi := &file_api_v1_agent_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackDiskUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackDiskUsage) ProtoMessage() {}
+
+func (x *StackDiskUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackDiskUsage.ProtoReflect.Descriptor instead.
+func (*StackDiskUsage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *StackDiskUsage) GetDirectoryBytes() int64 {
+	if x != nil {
+		return x.DirectoryBytes
+	}
+	return 0
+}
+
+func (x *StackDiskUsage) GetVolumeBytes() int64 {
+	if x != nil {
+		return x.VolumeBytes
+	}
+	return 0
+}
+
+func (x *StackDiskUsage) GetContainerBytes() int64 {
+	if x != nil {
+		return x.ContainerBytes
+	}
+	return 0
+}
+
+func (x *StackDiskUsage) GetImageBytes() int64 {
+	if x != nil {
+		return x.ImageBytes
+	}
+	return 0
+}
+
+func (x *StackDiskUsage) GetLimitBytes() int64 {
+	if x != nil {
+		return x.LimitBytes
+	}
+	return 0
+}
+
+// StackResources are what a stack's compose file declares
+type StackResources struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Containers    int32                  `protobuf:"varint,1,opt,name=containers,proto3" json:"containers,omitempty"`
+	Cpus          float64                `protobuf:"fixed64,2,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	MemoryBytes   int64                  `protobuf:"varint,3,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackResources) Reset() {
+	*x = StackResources{}
+	mi := &file_api_v1_agent_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackResources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackResources) ProtoMessage() {}
+
+func (x *StackResources) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackResources.ProtoReflect.Descriptor instead.
+func (*StackResources) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *StackResources) GetContainers() int32 {
+	if x != nil {
+		return x.Containers
+	}
+	return 0
+}
+
+func (x *StackResources) GetCpus() float64 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *StackResources) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+// StackOwner records who is accountable for a stack
+type StackOwner struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          string                 `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Ticket        string                 `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackOwner) Reset() {
+	*x = StackOwner{}
+	mi := &file_api_v1_agent_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackOwner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackOwner) ProtoMessage() {}
+
+func (x *StackOwner) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackOwner.ProtoReflect.Descriptor instead.
+func (*StackOwner) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *StackOwner) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *StackOwner) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *StackOwner) GetTicket() string {
+	if x != nil {
+		return x.Ticket
+	}
+	return ""
+}
+
+type ApplyStackRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StackName      string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	ComposeContent string                 `protobuf:"bytes,3,opt,name=compose_content,json=composeContent,proto3" json:"compose_content,omitempty"`
+	EnvVars        map[string]string      `protobuf:"bytes,4,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ForceRecreate  bool                   `protobuf:"varint,5,opt,name=force_recreate,json=forceRecreate,proto3" json:"force_recreate,omitempty"`
+	Services       []string               `protobuf:"bytes,6,rep,name=services,proto3" json:"services,omitempty"`
+	PullImages     bool                   `protobuf:"varint,7,opt,name=pull_images,json=pullImages,proto3" json:"pull_images,omitempty"`
+	Emergency      bool                   `protobuf:"varint,8,opt,name=emergency,proto3" json:"emergency,omitempty"` // Allowed while the agent is in maintenance
+	ApprovalId     string                 `protobuf:"bytes,9,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Replaces stored labels when set
+	Owner          *StackOwner            `protobuf:"bytes,11,opt,name=owner,proto3" json:"owner,omitempty"`                                                                             // Replaces stored ownership when set
+	Namespace      string                 `protobuf:"bytes,12,opt,name=namespace,proto3" json:"namespace,omitempty"`                                                                     // Empty means "default"
+	Group          string                 `protobuf:"bytes,13,opt,name=group,proto3" json:"group,omitempty"`                                                                             // Set when fanned out to a group; one approval covers it
+	Queue          bool                   `protobuf:"varint,14,opt,name=queue,proto3" json:"queue,omitempty"`                                                                            // Queue for delivery if the agent is offline
+	QueueTtl       *durationpb.Duration   `protobuf:"bytes,15,opt,name=queue_ttl,json=queueTtl,proto3" json:"queue_ttl,omitempty"`                                                       // Zero uses the core default
+	// Retries carrying the same key get the operation the first request
+	// started instead of a new one
+	IdempotencyKey string `protobuf:"bytes,16,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Complete as soon as compose up returns instead of waiting for every
+	// service to run and pass its healthcheck
+	NoWait       bool                 `protobuf:"varint,17,opt,name=no_wait,json=noWait,proto3" json:"no_wait,omitempty"`
+	ReadyTimeout *durationpb.Duration `protobuf:"bytes,18,opt,name=ready_timeout,json=readyTimeout,proto3" json:"ready_timeout,omitempty"` // Zero uses the agent default
+	// Replaces the stored hooks when set; needs the "hooks" permission
+	Hooks *StackHooks `protobuf:"bytes,19,opt,name=hooks,proto3" json:"hooks,omitempty"`
+	// Brings the files next to the compose file to these when set: staged
+	// ones are put in place, unchanged ones kept and ones an earlier apply
+	// synced but this one does not list removed. The apply fails if a file
+	// is neither staged nor unchanged.
+	Files *StackFiles `protobuf:"bytes,20,opt,name=files,proto3" json:"files,omitempty"`
+	// Compose files merged over compose_content in this order, as repeated
+	// docker compose -f flags are, such as compose.prod.yaml. Stored and
+	// sealed like the compose file; every apply replaces them.
+	ComposeOverrides []*StackContent `protobuf:"bytes,21,rep,name=compose_overrides,json=composeOverrides,proto3" json:"compose_overrides,omitempty"`
+	// Files the compose project reads: configs/ and secrets/ entries and
+	// dotenv variants such as .env.prod. Written together with the compose
+	// file, replacing every stored one, when set.
+	ProjectFiles  *StackContents `protobuf:"bytes,22,opt,name=project_files,json=projectFiles,proto3" json:"project_files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyStackRequest) Reset() {
+	*x = ApplyStackRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyStackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyStackRequest) ProtoMessage() {}
+
+func (x *ApplyStackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyStackRequest.ProtoReflect.Descriptor instead.
+func (*ApplyStackRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *ApplyStackRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetComposeContent() string {
+	if x != nil {
+		return x.ComposeContent
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetEnvVars() map[string]string {
+	if x != nil {
+		return x.EnvVars
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetForceRecreate() bool {
+	if x != nil {
+		return x.ForceRecreate
+	}
+	return false
+}
+
+func (x *ApplyStackRequest) GetServices() []string {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetPullImages() bool {
+	if x != nil {
+		return x.PullImages
+	}
+	return false
+}
+
+func (x *ApplyStackRequest) GetEmergency() bool {
+	if x != nil {
+		return x.Emergency
+	}
+	return false
+}
+
+func (x *ApplyStackRequest) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetOwner() *StackOwner {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetQueue() bool {
+	if x != nil {
+		return x.Queue
+	}
+	return false
+}
+
+func (x *ApplyStackRequest) GetQueueTtl() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTtl
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *ApplyStackRequest) GetNoWait() bool {
+	if x != nil {
+		return x.NoWait
+	}
+	return false
+}
+
+func (x *ApplyStackRequest) GetReadyTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ReadyTimeout
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetHooks() *StackHooks {
+	if x != nil {
+		return x.Hooks
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetFiles() *StackFiles {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetComposeOverrides() []*StackContent {
+	if x != nil {
+		return x.ComposeOverrides
+	}
+	return nil
+}
+
+func (x *ApplyStackRequest) GetProjectFiles() *StackContents {
+	if x != nil {
+		return x.ProjectFiles
+	}
+	return nil
+}
+
+// StackContent is a file stored with a stack's compose file
+type StackContent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // Relative to the stack's directory
+	Content []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// Permission bits; zero means 0644 under configs/ and 0600 elsewhere
+	Mode          uint32 `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackContent) Reset() {
+	*x = StackContent{}
+	mi := &file_api_v1_agent_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackContent) ProtoMessage() {}
+
+func (x *StackContent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackContent.ProtoReflect.Descriptor instead.
+func (*StackContent) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *StackContent) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StackContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *StackContent) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type StackContents struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*StackContent        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackContents) Reset() {
+	*x = StackContents{}
+	mi := &file_api_v1_agent_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackContents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackContents) ProtoMessage() {}
+
+func (x *StackContents) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackContents.ProtoReflect.Descriptor instead.
+func (*StackContents) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *StackContents) GetFiles() []*StackContent {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// StackFile is a file next to a stack's compose file, by its hash
+type StackFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`     // Relative to the stack's directory
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"` // Hex
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"` // Permission bits
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackFile) Reset() {
+	*x = StackFile{}
+	mi := &file_api_v1_agent_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackFile) ProtoMessage() {}
+
+func (x *StackFile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackFile.ProtoReflect.Descriptor instead.
+func (*StackFile) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *StackFile) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StackFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *StackFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *StackFile) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type StackFiles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*StackFile           `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackFiles) Reset() {
+	*x = StackFiles{}
+	mi := &file_api_v1_agent_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackFiles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackFiles) ProtoMessage() {}
+
+func (x *StackFiles) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackFiles.ProtoReflect.Descriptor instead.
+func (*StackFiles) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *StackFiles) GetFiles() []*StackFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type DiffStackFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StackName     string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Files         []*StackFile           `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffStackFilesRequest) Reset() {
+	*x = DiffStackFilesRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffStackFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffStackFilesRequest) ProtoMessage() {}
+
+func (x *DiffStackFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffStackFilesRequest.ProtoReflect.Descriptor instead.
+func (*DiffStackFilesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *DiffStackFilesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DiffStackFilesRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *DiffStackFilesRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *DiffStackFilesRequest) GetFiles() []*StackFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type DiffStackFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changed       []string               `protobuf:"bytes,1,rep,name=changed,proto3" json:"changed,omitempty"` // Missing or different on the agent
+	Removed       []string               `protobuf:"bytes,2,rep,name=removed,proto3" json:"removed,omitempty"` // Synced by an earlier apply, no longer listed
+	Unchanged     int32                  `protobuf:"varint,3,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffStackFilesResponse) Reset() {
+	*x = DiffStackFilesResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffStackFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffStackFilesResponse) ProtoMessage() {}
+
+func (x *DiffStackFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffStackFilesResponse.ProtoReflect.Descriptor instead.
+func (*DiffStackFilesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *DiffStackFilesResponse) GetChanged() []string {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *DiffStackFilesResponse) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *DiffStackFilesResponse) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+type StackFileChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set on the first chunk
+	AgentId       string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StackName     string `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Namespace     string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Emergency     bool   `protobuf:"varint,4,opt,name=emergency,proto3" json:"emergency,omitempty"`
+	Path          string `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"` // Set on the first chunk of each file
+	Data          []byte `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackFileChunk) Reset() {
+	*x = StackFileChunk{}
+	mi := &file_api_v1_agent_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackFileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackFileChunk) ProtoMessage() {}
+
+func (x *StackFileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackFileChunk.ProtoReflect.Descriptor instead.
+func (*StackFileChunk) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *StackFileChunk) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *StackFileChunk) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *StackFileChunk) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *StackFileChunk) GetEmergency() bool {
+	if x != nil {
+		return x.Emergency
+	}
+	return false
+}
+
+func (x *StackFileChunk) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StackFileChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UploadStackFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         int32                  `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStackFilesResponse) Reset() {
+	*x = UploadStackFilesResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStackFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStackFilesResponse) ProtoMessage() {}
+
+func (x *UploadStackFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStackFilesResponse.ProtoReflect.Descriptor instead.
+func (*UploadStackFilesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *UploadStackFilesResponse) GetFiles() int32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *UploadStackFilesResponse) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+// StackHooks are shell scripts the agent runs around compose up, for tasks
+// like database migrations or cache warmups. Their output is streamed to
+// the operation. An empty script removes a stored one.
+type StackHooks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PreApply      string                 `protobuf:"bytes,1,opt,name=pre_apply,json=preApply,proto3" json:"pre_apply,omitempty"`    // Before compose up; a failure stops the apply
+	PostApply     string                 `protobuf:"bytes,2,opt,name=post_apply,json=postApply,proto3" json:"post_apply,omitempty"` // Once services are ready; a failure fails the apply
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackHooks) Reset() {
+	*x = StackHooks{}
+	mi := &file_api_v1_agent_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackHooks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackHooks) ProtoMessage() {}
+
+func (x *StackHooks) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackHooks.ProtoReflect.Descriptor instead.
+func (*StackHooks) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *StackHooks) GetPreApply() string {
+	if x != nil {
+		return x.PreApply
+	}
+	return ""
+}
+
+func (x *StackHooks) GetPostApply() string {
+	if x != nil {
+		return x.PostApply
+	}
+	return ""
+}
+
+type DiffStackRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	StackName         string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	NewComposeContent string                 `protobuf:"bytes,2,opt,name=new_compose_content,json=newComposeContent,proto3" json:"new_compose_content,omitempty"`
+	Namespace         string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	ComposeOverrides  []*StackContent        `protobuf:"bytes,4,rep,name=compose_overrides,json=composeOverrides,proto3" json:"compose_overrides,omitempty"`
+	ProjectFiles      *StackContents         `protobuf:"bytes,5,opt,name=project_files,json=projectFiles,proto3" json:"project_files,omitempty"` // Unset compares only the compose files
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DiffStackRequest) Reset() {
+	*x = DiffStackRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffStackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffStackRequest) ProtoMessage() {}
+
+func (x *DiffStackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffStackRequest.ProtoReflect.Descriptor instead.
+func (*DiffStackRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *DiffStackRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *DiffStackRequest) GetNewComposeContent() string {
+	if x != nil {
+		return x.NewComposeContent
+	}
+	return ""
+}
+
+func (x *DiffStackRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *DiffStackRequest) GetComposeOverrides() []*StackContent {
+	if x != nil {
+		return x.ComposeOverrides
+	}
+	return nil
+}
+
+func (x *DiffStackRequest) GetProjectFiles() *StackContents {
+	if x != nil {
+		return x.ProjectFiles
+	}
+	return nil
+}
+
+type DiffStackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*ServiceDiff         `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	HasChanges    bool                   `protobuf:"varint,2,opt,name=has_changes,json=hasChanges,proto3" json:"has_changes,omitempty"`
+	Files         []*StackFileDiff       `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"` // Compose overrides and project files
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffStackResponse) Reset() {
+	*x = DiffStackResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffStackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffStackResponse) ProtoMessage() {}
+
+func (x *DiffStackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffStackResponse.ProtoReflect.Descriptor instead.
+func (*DiffStackResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *DiffStackResponse) GetServices() []*ServiceDiff {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *DiffStackResponse) GetHasChanges() bool {
+	if x != nil {
+		return x.HasChanges
+	}
+	return false
+}
+
+func (x *DiffStackResponse) GetFiles() []*StackFileDiff {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type StackFileDiff struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Action        DiffAction             `protobuf:"varint,2,opt,name=action,proto3,enum=mandau.agent.v1.DiffAction" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackFileDiff) Reset() {
+	*x = StackFileDiff{}
+	mi := &file_api_v1_agent_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackFileDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackFileDiff) ProtoMessage() {}
+
+func (x *StackFileDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackFileDiff.ProtoReflect.Descriptor instead.
+func (*StackFileDiff) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *StackFileDiff) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StackFileDiff) GetAction() DiffAction {
+	if x != nil {
+		return x.Action
+	}
+	return DiffAction_DIFF_ACTION_NONE
+}
+
+type ExportStackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	AgentId       string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                    // Empty means look the stack up across agents
+	RevealSecrets bool                   `protobuf:"varint,4,opt,name=reveal_secrets,json=revealSecrets,proto3" json:"reveal_secrets,omitempty"` // Keep secret values; needs write on the stack
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportStackRequest) Reset() {
+	*x = ExportStackRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportStackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportStackRequest) ProtoMessage() {}
+
+func (x *ExportStackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportStackRequest.ProtoReflect.Descriptor instead.
+func (*ExportStackRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *ExportStackRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *ExportStackRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ExportStackRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ExportStackRequest) GetRevealSecrets() bool {
+	if x != nil {
+		return x.RevealSecrets
+	}
+	return false
+}
+
+type StackExport struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace      string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	AgentId        string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	ComposeContent string                 `protobuf:"bytes,4,opt,name=compose_content,json=composeContent,proto3" json:"compose_content,omitempty"`
+	EnvVars        map[string]string      `protobuf:"bytes,5,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // The stored .env
+	Labels         map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Owner          *StackOwner            `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	State          StackState             `protobuf:"varint,8,opt,name=state,proto3,enum=mandau.agent.v1.StackState" json:"state,omitempty"`
+	Containers     []*Container           `protobuf:"bytes,9,rep,name=containers,proto3" json:"containers,omitempty"`
+	ExportedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=exported_at,json=exportedAt,proto3" json:"exported_at,omitempty"`
+	// Whether secret values in the compose files and env_vars are masked,
+	// and the content of secrets/ files and dotenv variants left out
+	Redacted         bool            `protobuf:"varint,11,opt,name=redacted,proto3" json:"redacted,omitempty"`
+	ComposeOverrides []*StackContent `protobuf:"bytes,12,rep,name=compose_overrides,json=composeOverrides,proto3" json:"compose_overrides,omitempty"`
+	ProjectFiles     []*StackContent `protobuf:"bytes,13,rep,name=project_files,json=projectFiles,proto3" json:"project_files,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StackExport) Reset() {
+	*x = StackExport{}
+	mi := &file_api_v1_agent_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackExport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackExport) ProtoMessage() {}
+
+func (x *StackExport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackExport.ProtoReflect.Descriptor instead.
+func (*StackExport) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *StackExport) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StackExport) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *StackExport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *StackExport) GetComposeContent() string {
+	if x != nil {
+		return x.ComposeContent
+	}
+	return ""
+}
+
+func (x *StackExport) GetEnvVars() map[string]string {
+	if x != nil {
+		return x.EnvVars
+	}
+	return nil
+}
+
+func (x *StackExport) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *StackExport) GetOwner() *StackOwner {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *StackExport) GetState() StackState {
+	if x != nil {
+		return x.State
+	}
+	return StackState_STACK_STATE_UNKNOWN
+}
+
+func (x *StackExport) GetContainers() []*Container {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+func (x *StackExport) GetExportedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExportedAt
+	}
+	return nil
+}
+
+func (x *StackExport) GetRedacted() bool {
+	if x != nil {
+		return x.Redacted
+	}
+	return false
+}
+
+func (x *StackExport) GetComposeOverrides() []*StackContent {
+	if x != nil {
+		return x.ComposeOverrides
+	}
+	return nil
+}
+
+func (x *StackExport) GetProjectFiles() []*StackContent {
+	if x != nil {
+		return x.ProjectFiles
+	}
+	return nil
+}
+
+type CollectStackGarbageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Apply         bool                   `protobuf:"varint,2,opt,name=apply,proto3" json:"apply,omitempty"` // Remove what is found instead of only reporting it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectStackGarbageRequest) Reset() {
+	*x = CollectStackGarbageRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectStackGarbageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectStackGarbageRequest) ProtoMessage() {}
+
+func (x *CollectStackGarbageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectStackGarbageRequest.ProtoReflect.Descriptor instead.
+func (*CollectStackGarbageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *CollectStackGarbageRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *CollectStackGarbageRequest) GetApply() bool {
+	if x != nil {
+		return x.Apply
+	}
+	return false
+}
+
+type CollectStackGarbageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orphans       []*StackOrphan         `protobuf:"bytes,1,rep,name=orphans,proto3" json:"orphans,omitempty"`
+	Applied       bool                   `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectStackGarbageResponse) Reset() {
+	*x = CollectStackGarbageResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectStackGarbageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectStackGarbageResponse) ProtoMessage() {}
+
+func (x *CollectStackGarbageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectStackGarbageResponse.ProtoReflect.Descriptor instead.
+func (*CollectStackGarbageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{100}
+}
+
+func (x *CollectStackGarbageResponse) GetOrphans() []*StackOrphan {
+	if x != nil {
+		return x.Orphans
+	}
+	return nil
+}
+
+func (x *CollectStackGarbageResponse) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+type GetStackEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Empty means look the stack up across agents
+	StackName     string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Kinds         []string               `protobuf:"bytes,5,rep,name=kinds,proto3" json:"kinds,omitempty"`  // Such as apply, crash or restart; empty is all
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"` // Only the last this many, 0 means 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStackEventsRequest) Reset() {
+	*x = GetStackEventsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStackEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStackEventsRequest) ProtoMessage() {}
+
+func (x *GetStackEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStackEventsRequest.ProtoReflect.Descriptor instead.
+func (*GetStackEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *GetStackEventsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetStackEventsRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *GetStackEventsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetStackEventsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetStackEventsRequest) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+func (x *GetStackEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetStackEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Events        []*StackEvent          `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStackEventsResponse) Reset() {
+	*x = GetStackEventsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStackEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStackEventsResponse) ProtoMessage() {}
+
+func (x *GetStackEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStackEventsResponse.ProtoReflect.Descriptor instead.
+func (*GetStackEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *GetStackEventsResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetStackEventsResponse) GetEvents() []*StackEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type StackEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Service       string                 `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	Summary       string                 `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	Details       []string               `protobuf:"bytes,5,rep,name=details,proto3" json:"details,omitempty"` // Such as the changes an apply made
+	Failed        bool                   `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`
+	OperationId   string                 `protobuf:"bytes,7,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackEvent) Reset() {
+	*x = StackEvent{}
+	mi := &file_api_v1_agent_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackEvent) ProtoMessage() {}
+
+func (x *StackEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackEvent.ProtoReflect.Descriptor instead.
+func (*StackEvent) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *StackEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *StackEvent) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *StackEvent) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *StackEvent) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *StackEvent) GetDetails() []string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *StackEvent) GetFailed() bool {
+	if x != nil {
+		return x.Failed
+	}
+	return false
+}
+
+func (x *StackEvent) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type StackOrphan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          OrphanKind             `protobuf:"varint,1,opt,name=kind,proto3,enum=mandau.agent.v1.OrphanKind" json:"kind,omitempty"`
+	StackName     string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"` // Directory or file on the agent
+	ContainerIds  []string               `protobuf:"bytes,4,rep,name=container_ids,json=containerIds,proto3" json:"container_ids,omitempty"`
+	Detail        string                 `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	Removed       bool                   `protobuf:"varint,6,opt,name=removed,proto3" json:"removed,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // Why removing it failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackOrphan) Reset() {
+	*x = StackOrphan{}
+	mi := &file_api_v1_agent_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackOrphan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackOrphan) ProtoMessage() {}
+
+func (x *StackOrphan) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackOrphan.ProtoReflect.Descriptor instead.
+func (*StackOrphan) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *StackOrphan) GetKind() OrphanKind {
+	if x != nil {
+		return x.Kind
+	}
+	return OrphanKind_ORPHAN_KIND_UNSPECIFIED
+}
+
+func (x *StackOrphan) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *StackOrphan) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StackOrphan) GetContainerIds() []string {
+	if x != nil {
+		return x.ContainerIds
+	}
+	return nil
+}
+
+func (x *StackOrphan) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *StackOrphan) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+func (x *StackOrphan) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ServiceDiff struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Action        DiffAction             `protobuf:"varint,2,opt,name=action,proto3,enum=mandau.agent.v1.DiffAction" json:"action,omitempty"`
+	Changes       []string               `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceDiff) Reset() {
+	*x = ServiceDiff{}
+	mi := &file_api_v1_agent_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceDiff) ProtoMessage() {}
+
+func (x *ServiceDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[105]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceDiff.ProtoReflect.Descriptor instead.
+func (*ServiceDiff) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *ServiceDiff) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceDiff) GetAction() DiffAction {
+	if x != nil {
+		return x.Action
+	}
+	return DiffAction_DIFF_ACTION_NONE
+}
+
+func (x *ServiceDiff) GetChanges() []string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type Container struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Image   string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	State   string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	Status  string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Created *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created,proto3" json:"created,omitempty"`
+	Labels  map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Ports   []*Port                `protobuf:"bytes,8,rep,name=ports,proto3" json:"ports,omitempty"`
+	ImageId string                 `protobuf:"bytes,9,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	// Registry digests of the image, set when the listing asks for them;
+	// empty for images that were built locally and never pushed
+	ImageDigests  []string `protobuf:"bytes,10,rep,name=image_digests,json=imageDigests,proto3" json:"image_digests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Container) Reset() {
+	*x = Container{}
+	mi := &file_api_v1_agent_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Container) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Container) ProtoMessage() {}
+
+func (x *Container) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[106]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Container.ProtoReflect.Descriptor instead.
+func (*Container) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *Container) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Container) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Container) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Container) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Container) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Container) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *Container) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Container) GetPorts() []*Port {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *Container) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *Container) GetImageDigests() []string {
+	if x != nil {
+		return x.ImageDigests
+	}
+	return nil
+}
+
+type Port struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PrivatePort   uint32                 `protobuf:"varint,1,opt,name=private_port,json=privatePort,proto3" json:"private_port,omitempty"`
+	PublicPort    uint32                 `protobuf:"varint,2,opt,name=public_port,json=publicPort,proto3" json:"public_port,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Port) Reset() {
+	*x = Port{}
+	mi := &file_api_v1_agent_proto_msgTypes[107]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Port) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Port) ProtoMessage() {}
+
+func (x *Port) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[107]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Port.ProtoReflect.Descriptor instead.
+func (*Port) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{107}
+}
+
+func (x *Port) GetPrivatePort() uint32 {
+	if x != nil {
+		return x.PrivatePort
+	}
+	return 0
+}
+
+func (x *Port) GetPublicPort() uint32 {
+	if x != nil {
+		return x.PublicPort
+	}
+	return 0
+}
+
+func (x *Port) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Port) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+type ExecRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExecRequest_Start
+	//	*ExecRequest_Stdin
+	//	*ExecRequest_Resize
+	Payload       isExecRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[108]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[108]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{108}
+}
+
+func (x *ExecRequest) GetPayload() isExecRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetStart() *ExecStart {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetStdin() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Stdin); ok {
+			return x.Stdin
+		}
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetResize() *ExecResize {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Resize); ok {
+			return x.Resize
+		}
+	}
+	return nil
+}
+
+type isExecRequest_Payload interface {
+	isExecRequest_Payload()
+}
+
+type ExecRequest_Start struct {
+	Start *ExecStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type ExecRequest_Stdin struct {
+	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3,oneof"`
+}
+
+type ExecRequest_Resize struct {
+	Resize *ExecResize `protobuf:"bytes,3,opt,name=resize,proto3,oneof"`
+}
+
+func (*ExecRequest_Start) isExecRequest_Payload() {}
+
+func (*ExecRequest_Stdin) isExecRequest_Payload() {}
+
+func (*ExecRequest_Resize) isExecRequest_Payload() {}
+
+type ExecStart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Cmd           []string               `protobuf:"bytes,2,rep,name=cmd,proto3" json:"cmd,omitempty"`
+	Tty           bool                   `protobuf:"varint,3,opt,name=tty,proto3" json:"tty,omitempty"`
+	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	WorkingDir    string                 `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	User          string                 `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecStart) Reset() {
+	*x = ExecStart{}
+	mi := &file_api_v1_agent_proto_msgTypes[109]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecStart) ProtoMessage() {}
+
+func (x *ExecStart) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[109]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecStart.ProtoReflect.Descriptor instead.
+func (*ExecStart) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{109}
+}
+
+func (x *ExecStart) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ExecStart) GetCmd() []string {
+	if x != nil {
+		return x.Cmd
+	}
+	return nil
+}
+
+func (x *ExecStart) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+func (x *ExecStart) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ExecStart) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *ExecStart) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type ExecResize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        uint32                 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Width         uint32                 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResize) Reset() {
+	*x = ExecResize{}
+	mi := &file_api_v1_agent_proto_msgTypes[110]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResize) ProtoMessage() {}
+
+func (x *ExecResize) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[110]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResize.ProtoReflect.Descriptor instead.
+func (*ExecResize) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{110}
+}
+
+func (x *ExecResize) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ExecResize) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+type ExecResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExecResponse_Stdout
+	//	*ExecResponse_Stderr
+	//	*ExecResponse_ExitCode
+	//	*ExecResponse_Error
+	Payload       isExecResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[111]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResponse) ProtoMessage() {}
+
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[111]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{111}
+}
+
+func (x *ExecResponse) GetPayload() isExecResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetStdout() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Stdout); ok {
+			return x.Stdout
+		}
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetStderr() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Stderr); ok {
+			return x.Stderr
+		}
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetExitCode() int32 {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_ExitCode); ok {
+			return x.ExitCode
+		}
+	}
+	return 0
+}
+
+func (x *ExecResponse) GetError() string {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isExecResponse_Payload interface {
+	isExecResponse_Payload()
+}
+
+type ExecResponse_Stdout struct {
+	Stdout []byte `protobuf:"bytes,1,opt,name=stdout,proto3,oneof"`
+}
+
+type ExecResponse_Stderr struct {
+	Stderr []byte `protobuf:"bytes,2,opt,name=stderr,proto3,oneof"`
+}
+
+type ExecResponse_ExitCode struct {
+	ExitCode int32 `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3,oneof"`
+}
+
+type ExecResponse_Error struct {
+	Error string `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*ExecResponse_Stdout) isExecResponse_Payload() {}
+
+func (*ExecResponse_Stderr) isExecResponse_Payload() {}
+
+func (*ExecResponse_ExitCode) isExecResponse_Payload() {}
+
+func (*ExecResponse_Error) isExecResponse_Payload() {}
+
+type LogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Stream        string                 `protobuf:"bytes,2,opt,name=stream,proto3" json:"stream,omitempty"` // stdout or stderr
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,4,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	ServiceName   string                 `protobuf:"bytes,5,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_api_v1_agent_proto_msgTypes[112]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[112]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{112}
+}
+
+func (x *LogEntry) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *LogEntry) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *LogEntry) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *LogEntry) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+type ContainerStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Cpu           *CPUStats              `protobuf:"bytes,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory        *MemoryStats           `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
+	Network       *NetworkStats          `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
+	BlockIo       *BlockIOStats          `protobuf:"bytes,6,opt,name=block_io,json=blockIo,proto3" json:"block_io,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerStats) Reset() {
+	*x = ContainerStats{}
+	mi := &file_api_v1_agent_proto_msgTypes[113]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerStats) ProtoMessage() {}
+
+func (x *ContainerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[113]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerStats.ProtoReflect.Descriptor instead.
+func (*ContainerStats) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{113}
+}
+
+func (x *ContainerStats) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerStats) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ContainerStats) GetCpu() *CPUStats {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *ContainerStats) GetMemory() *MemoryStats {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+func (x *ContainerStats) GetNetwork() *NetworkStats {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *ContainerStats) GetBlockIo() *BlockIOStats {
+	if x != nil {
+		return x.BlockIo
+	}
+	return nil
+}
+
+type ListFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // Relative to stack root
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[114]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[114]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{114}
+}
+
+func (x *ListFilesRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *ListFilesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ListFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesResponse) Reset() {
+	*x = ListFilesResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[115]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesResponse) ProtoMessage() {}
+
+func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[115]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{115}
+}
+
+func (x *ListFilesResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	IsDir         bool                   `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Modified      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=modified,proto3" json:"modified,omitempty"`
+	Mode          uint32                 `protobuf:"varint,6,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_api_v1_agent_proto_msgTypes[116]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[116]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{116}
+}
+
+func (x *FileInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileInfo) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Modified
+	}
+	return nil
+}
+
+func (x *FileInfo) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type ReadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileRequest) Reset() {
+	*x = ReadFileRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[117]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileRequest) ProtoMessage() {}
+
+func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[117]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileRequest.ProtoReflect.Descriptor instead.
+func (*ReadFileRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{117}
+}
+
+func (x *ReadFileRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *ReadFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ReadFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Info          *FileInfo              `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileResponse) Reset() {
+	*x = ReadFileResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileResponse) ProtoMessage() {}
+
+func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[118]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileResponse.ProtoReflect.Descriptor instead.
+func (*ReadFileResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *ReadFileResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ReadFileResponse) GetInfo() *FileInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type WriteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StackName     string                 `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRequest) Reset() {
+	*x = WriteFileRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[119]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRequest) ProtoMessage() {}
+
+func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[119]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
+func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{119}
+}
+
+func (x *WriteFileRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *WriteFileRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+// TransferTarget is what an upload is for
+type TransferTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          TransferKind           `protobuf:"varint,1,opt,name=kind,proto3,enum=mandau.agent.v1.TransferKind" json:"kind,omitempty"`
+	StackName     string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Path          string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"` // Relative to the stack directory, for files
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferTarget) Reset() {
+	*x = TransferTarget{}
+	mi := &file_api_v1_agent_proto_msgTypes[120]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferTarget) ProtoMessage() {}
+
+func (x *TransferTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[120]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferTarget.ProtoReflect.Descriptor instead.
+func (*TransferTarget) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{120}
+}
+
+func (x *TransferTarget) GetKind() TransferKind {
+	if x != nil {
+		return x.Kind
+	}
+	return TransferKind_TRANSFER_KIND_UNSPECIFIED
+}
+
+func (x *TransferTarget) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *TransferTarget) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *TransferTarget) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type UploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set in the first request
+	AgentId       string          `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	TransferId    string          `protobuf:"bytes,2,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"` // The same when resuming an upload
+	Target        *TransferTarget `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Size          int64           `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string          `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"` // Hex digest of the whole payload
+	Offset        int64           `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	Emergency     bool            `protobuf:"varint,7,opt,name=emergency,proto3" json:"emergency,omitempty"` // Allowed while the agent is in maintenance
+	Chunk         []byte          `protobuf:"bytes,8,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadRequest) Reset() {
+	*x = UploadRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[121]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadRequest) ProtoMessage() {}
+
+func (x *UploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[121]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{121}
+}
+
+func (x *UploadRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetTarget() *TransferTarget {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UploadRequest) GetEmergency() bool {
+	if x != nil {
+		return x.Emergency
+	}
+	return false
+}
+
+func (x *UploadRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type TransferStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransferId    string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	Target        *TransferTarget        `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Received      int64                  `protobuf:"varint,4,opt,name=received,proto3" json:"received,omitempty"`
+	Delivered     bool                   `protobuf:"varint,5,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	Images        []string               `protobuf:"bytes,6,rep,name=images,proto3" json:"images,omitempty"` // Loaded from an image bundle
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferStatus) Reset() {
+	*x = TransferStatus{}
+	mi := &file_api_v1_agent_proto_msgTypes[122]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStatus) ProtoMessage() {}
+
+func (x *TransferStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[122]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStatus.ProtoReflect.Descriptor instead.
+func (*TransferStatus) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{122}
+}
+
+func (x *TransferStatus) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *TransferStatus) GetTarget() *TransferTarget {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *TransferStatus) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *TransferStatus) GetReceived() int64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *TransferStatus) GetDelivered() bool {
+	if x != nil {
+		return x.Delivered
+	}
+	return false
+}
+
+func (x *TransferStatus) GetImages() []string {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *TransferStatus) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetTransferRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	TransferId    string                 `protobuf:"bytes,2,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	Target        *TransferTarget        `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"` // Must be the upload's
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransferRequest) Reset() {
+	*x = GetTransferRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[123]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransferRequest) ProtoMessage() {}
+
+func (x *GetTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[123]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransferRequest.ProtoReflect.Descriptor instead.
+func (*GetTransferRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{123}
+}
+
+func (x *GetTransferRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetTransferRequest) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *GetTransferRequest) GetTarget() *TransferTarget {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type ListBackupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsRequest) Reset() {
+	*x = ListBackupsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[124]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsRequest) ProtoMessage() {}
+
+func (x *ListBackupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[124]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsRequest.ProtoReflect.Descriptor instead.
+func (*ListBackupsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{124}
+}
+
+func (x *ListBackupsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListBackupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backups       []*FileInfo            `protobuf:"bytes,1,rep,name=backups,proto3" json:"backups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsResponse) Reset() {
+	*x = ListBackupsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[125]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsResponse) ProtoMessage() {}
+
+func (x *ListBackupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[125]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsResponse.ProtoReflect.Descriptor instead.
+func (*ListBackupsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{125}
+}
+
+func (x *ListBackupsResponse) GetBackups() []*FileInfo {
+	if x != nil {
+		return x.Backups
+	}
+	return nil
+}
+
+type DownloadBackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // As listed by ListBackups
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadBackupRequest) Reset() {
+	*x = DownloadBackupRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[126]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadBackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadBackupRequest) ProtoMessage() {}
+
+func (x *DownloadBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[126]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadBackupRequest.ProtoReflect.Descriptor instead.
+func (*DownloadBackupRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{126}
+}
+
+func (x *DownloadBackupRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DownloadBackupRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DownloadBackupRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type DownloadChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // Of the whole file
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadChunk) Reset() {
+	*x = DownloadChunk{}
+	mi := &file_api_v1_agent_proto_msgTypes[127]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadChunk) ProtoMessage() {}
+
+func (x *DownloadChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[127]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadChunk.ProtoReflect.Descriptor instead.
+func (*DownloadChunk) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{127}
+}
+
+func (x *DownloadChunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DownloadChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type FetchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Target        *TransferTarget        `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Digest        string                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`        // Hex SHA-256 of the artifact
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`           // Set by the core
+	Emergency     bool                   `protobuf:"varint,5,opt,name=emergency,proto3" json:"emergency,omitempty"` // Allowed while the agent is in maintenance
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchRequest) Reset() {
+	*x = FetchRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[128]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchRequest) ProtoMessage() {}
+
+func (x *FetchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[128]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchRequest.ProtoReflect.Descriptor instead.
+func (*FetchRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{128}
+}
+
+func (x *FetchRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetTarget() *TransferTarget {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *FetchRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FetchRequest) GetEmergency() bool {
+	if x != nil {
+		return x.Emergency
+	}
+	return false
+}
+
+type Artifact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"` // Hex SHA-256
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`     // compose, template, script, image-bundle or file
+	Names         []string               `protobuf:"bytes,3,rep,name=names,proto3" json:"names,omitempty"`   // Every name it was pushed under
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Pinned        bool                   `protobuf:"varint,5,opt,name=pinned,proto3" json:"pinned,omitempty"` // Never collected as garbage
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UsedAt        *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=used_at,json=usedAt,proto3" json:"used_at,omitempty"` // Last pushed or fetched
+	Pushes        int64                  `protobuf:"varint,8,opt,name=pushes,proto3" json:"pushes,omitempty"`
+	Fetches       int64                  `protobuf:"varint,9,opt,name=fetches,proto3" json:"fetches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_api_v1_agent_proto_msgTypes[129]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[129]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{129}
+}
+
+func (x *Artifact) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Artifact) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Artifact) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *Artifact) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Artifact) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *Artifact) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UsedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetPushes() int64 {
+	if x != nil {
+		return x.Pushes
+	}
+	return 0
+}
+
+func (x *Artifact) GetFetches() int64 {
+	if x != nil {
+		return x.Fetches
+	}
+	return 0
+}
+
+type PutArtifactRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set in the first request
+	Digest        string `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Kind          string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Pin           bool   `protobuf:"varint,5,opt,name=pin,proto3" json:"pin,omitempty"`
+	Chunk         []byte `protobuf:"bytes,6,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutArtifactRequest) Reset() {
+	*x = PutArtifactRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[130]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutArtifactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutArtifactRequest) ProtoMessage() {}
+
+func (x *PutArtifactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[130]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutArtifactRequest.ProtoReflect.Descriptor instead.
+func (*PutArtifactRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{130}
+}
+
+func (x *PutArtifactRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *PutArtifactRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *PutArtifactRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PutArtifactRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PutArtifactRequest) GetPin() bool {
+	if x != nil {
+		return x.Pin
+	}
+	return false
+}
+
+func (x *PutArtifactRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type GetArtifactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetArtifactRequest) Reset() {
+	*x = GetArtifactRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[131]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetArtifactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArtifactRequest) ProtoMessage() {}
+
+func (x *GetArtifactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[131]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArtifactRequest.ProtoReflect.Descriptor instead.
+func (*GetArtifactRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{131}
+}
+
+func (x *GetArtifactRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+type ListArtifactsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // Empty lists every kind
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArtifactsRequest) Reset() {
+	*x = ListArtifactsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[132]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArtifactsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArtifactsRequest) ProtoMessage() {}
+
+func (x *ListArtifactsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[132]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArtifactsRequest.ProtoReflect.Descriptor instead.
+func (*ListArtifactsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{132}
+}
+
+func (x *ListArtifactsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type ListArtifactsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Artifacts     []*Artifact            `protobuf:"bytes,1,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArtifactsResponse) Reset() {
+	*x = ListArtifactsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[133]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArtifactsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArtifactsResponse) ProtoMessage() {}
+
+func (x *ListArtifactsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[133]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArtifactsResponse.ProtoReflect.Descriptor instead.
+func (*ListArtifactsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{133}
+}
+
+func (x *ListArtifactsResponse) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+type DeleteArtifactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteArtifactRequest) Reset() {
+	*x = DeleteArtifactRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[134]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteArtifactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteArtifactRequest) ProtoMessage() {}
+
+func (x *DeleteArtifactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[134]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteArtifactRequest.ProtoReflect.Descriptor instead.
+func (*DeleteArtifactRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{134}
+}
+
+func (x *DeleteArtifactRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+type CollectArtifactGarbageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Report what would be removed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectArtifactGarbageRequest) Reset() {
+	*x = CollectArtifactGarbageRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[135]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectArtifactGarbageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectArtifactGarbageRequest) ProtoMessage() {}
+
+func (x *CollectArtifactGarbageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[135]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectArtifactGarbageRequest.ProtoReflect.Descriptor instead.
+func (*CollectArtifactGarbageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{135}
+}
+
+func (x *CollectArtifactGarbageRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CollectArtifactGarbageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Removed       []*Artifact            `protobuf:"bytes,1,rep,name=removed,proto3" json:"removed,omitempty"`
+	Freed         int64                  `protobuf:"varint,2,opt,name=freed,proto3" json:"freed,omitempty"` // Bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectArtifactGarbageResponse) Reset() {
+	*x = CollectArtifactGarbageResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[136]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectArtifactGarbageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectArtifactGarbageResponse) ProtoMessage() {}
+
+func (x *CollectArtifactGarbageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[136]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectArtifactGarbageResponse.ProtoReflect.Descriptor instead.
+func (*CollectArtifactGarbageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{136}
+}
+
+func (x *CollectArtifactGarbageResponse) GetRemoved() []*Artifact {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *CollectArtifactGarbageResponse) GetFreed() int64 {
+	if x != nil {
+		return x.Freed
+	}
+	return 0
+}
+
+type FetchArtifactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Digest        string                 `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchArtifactRequest) Reset() {
+	*x = FetchArtifactRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[137]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchArtifactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchArtifactRequest) ProtoMessage() {}
+
+func (x *FetchArtifactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[137]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchArtifactRequest.ProtoReflect.Descriptor instead.
+func (*FetchArtifactRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{137}
+}
+
+func (x *FetchArtifactRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *FetchArtifactRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *FetchArtifactRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ArtifactChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // Of the whole artifact
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtifactChunk) Reset() {
+	*x = ArtifactChunk{}
+	mi := &file_api_v1_agent_proto_msgTypes[138]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtifactChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtifactChunk) ProtoMessage() {}
+
+func (x *ArtifactChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[138]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtifactChunk.ProtoReflect.Descriptor instead.
+func (*ArtifactChunk) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{138}
+}
+
+func (x *ArtifactChunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ArtifactChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ArtifactChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type GetProfileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// goroutine, heap, allocs, threadcreate, block, mutex or cpu
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// How long cpu samples for, default 30s and at most 5m
+	Duration *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// As pprof's debug parameter: 0 is the gzipped protobuf go tool pprof
+	// reads, 1 and 2 are text. cpu profiles are always protobuf.
+	Debug         int32 `protobuf:"varint,3,opt,name=debug,proto3" json:"debug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileRequest) Reset() {
+	*x = GetProfileRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[139]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileRequest) ProtoMessage() {}
+
+func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[139]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{139}
+}
+
+func (x *GetProfileRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetProfileRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *GetProfileRequest) GetDebug() int32 {
+	if x != nil {
+		return x.Debug
+	}
+	return 0
+}
+
+type ProfileChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProfileChunk) Reset() {
+	*x = ProfileChunk{}
+	mi := &file_api_v1_agent_proto_msgTypes[140]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileChunk) ProtoMessage() {}
+
+func (x *ProfileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[140]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileChunk.ProtoReflect.Descriptor instead.
+func (*ProfileChunk) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{140}
+}
+
+func (x *ProfileChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ListConnectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsRequest) Reset() {
+	*x = ListConnectionsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[141]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsRequest) ProtoMessage() {}
+
+func (x *ListConnectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[141]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsRequest.ProtoReflect.Descriptor instead.
+func (*ListConnectionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{141}
+}
+
+type ListConnectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Connections   []*AgentChannel        `protobuf:"bytes,1,rep,name=connections,proto3" json:"connections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsResponse) Reset() {
+	*x = ListConnectionsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[142]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsResponse) ProtoMessage() {}
+
+func (x *ListConnectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[142]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsResponse.ProtoReflect.Descriptor instead.
+func (*ListConnectionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{142}
+}
+
+func (x *ListConnectionsResponse) GetConnections() []*AgentChannel {
+	if x != nil {
+		return x.Connections
+	}
+	return nil
+}
+
+// AgentChannel is the core's connection to one agent
+type AgentChannel struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Address string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Status  string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // online, offline, ...
+	// State of the gRPC channel: IDLE, CONNECTING, READY, TRANSIENT_FAILURE
+	// or SHUTDOWN; empty when the core has none, as for dial-out agents
+	ChannelState       string                 `protobuf:"bytes,4,opt,name=channel_state,json=channelState,proto3" json:"channel_state,omitempty"`
+	DialOutOnly        bool                   `protobuf:"varint,5,opt,name=dial_out_only,json=dialOutOnly,proto3" json:"dial_out_only,omitempty"`
+	LastSeen           *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Circuit            *AgentCircuit          `protobuf:"bytes,7,opt,name=circuit,proto3" json:"circuit,omitempty"`
+	CertificateSubject string                 `protobuf:"bytes,8,opt,name=certificate_subject,json=certificateSubject,proto3" json:"certificate_subject,omitempty"` // Last presented client certificate
+	CertificateExpires *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=certificate_expires,json=certificateExpires,proto3" json:"certificate_expires,omitempty"`
+	ClockSkew          *durationpb.Duration   `protobuf:"bytes,10,opt,name=clock_skew,json=clockSkew,proto3" json:"clock_skew,omitempty"` // Unset until reported
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *AgentChannel) Reset() {
+	*x = AgentChannel{}
+	mi := &file_api_v1_agent_proto_msgTypes[143]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentChannel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentChannel) ProtoMessage() {}
+
+func (x *AgentChannel) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[143]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentChannel.ProtoReflect.Descriptor instead.
+func (*AgentChannel) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{143}
+}
+
+func (x *AgentChannel) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentChannel) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AgentChannel) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AgentChannel) GetChannelState() string {
+	if x != nil {
+		return x.ChannelState
+	}
+	return ""
+}
+
+func (x *AgentChannel) GetDialOutOnly() bool {
+	if x != nil {
+		return x.DialOutOnly
+	}
+	return false
+}
+
+func (x *AgentChannel) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *AgentChannel) GetCircuit() *AgentCircuit {
+	if x != nil {
+		return x.Circuit
+	}
+	return nil
+}
+
+func (x *AgentChannel) GetCertificateSubject() string {
+	if x != nil {
+		return x.CertificateSubject
+	}
+	return ""
+}
+
+func (x *AgentChannel) GetCertificateExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CertificateExpires
+	}
+	return nil
+}
+
+func (x *AgentChannel) GetClockSkew() *durationpb.Duration {
+	if x != nil {
+		return x.ClockSkew
+	}
+	return nil
+}
+
+type GetRegistryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryRequest) Reset() {
+	*x = GetRegistryRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[144]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryRequest) ProtoMessage() {}
+
+func (x *GetRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[144]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{144}
+}
+
+type CoreRegistry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*RegistryAgent       `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	Plugins       []*CorePlugin          `protobuf:"bytes,2,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoreRegistry) Reset() {
+	*x = CoreRegistry{}
+	mi := &file_api_v1_agent_proto_msgTypes[145]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoreRegistry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoreRegistry) ProtoMessage() {}
+
+func (x *CoreRegistry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[145]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoreRegistry.ProtoReflect.Descriptor instead.
+func (*CoreRegistry) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{145}
+}
+
+func (x *CoreRegistry) GetAgents() []*RegistryAgent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *CoreRegistry) GetPlugins() []*CorePlugin {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+// RegistryAgent is the core's record of one agent
+type RegistryAgent struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Hostname       string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Effective
+	ReportedLabels map[string]string      `protobuf:"bytes,5,rep,name=reported_labels,json=reportedLabels,proto3" json:"reported_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OperatorLabels map[string]string      `protobuf:"bytes,6,rep,name=operator_labels,json=operatorLabels,proto3" json:"operator_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RemovedLabels  []string               `protobuf:"bytes,7,rep,name=removed_labels,json=removedLabels,proto3" json:"removed_labels,omitempty"`
+	Capabilities   []string               `protobuf:"bytes,8,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Stacks         []string               `protobuf:"bytes,9,rep,name=stacks,proto3" json:"stacks,omitempty"`
+	Plugins        []string               `protobuf:"bytes,10,rep,name=plugins,proto3" json:"plugins,omitempty"` // Installed from the plugin index
+	InMaintenance  bool                   `protobuf:"varint,11,opt,name=in_maintenance,json=inMaintenance,proto3" json:"in_maintenance,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RegistryAgent) Reset() {
+	*x = RegistryAgent{}
+	mi := &file_api_v1_agent_proto_msgTypes[146]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryAgent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryAgent) ProtoMessage() {}
+
+func (x *RegistryAgent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[146]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryAgent.ProtoReflect.Descriptor instead.
+func (*RegistryAgent) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{146}
+}
+
+func (x *RegistryAgent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegistryAgent) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *RegistryAgent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RegistryAgent) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetReportedLabels() map[string]string {
+	if x != nil {
+		return x.ReportedLabels
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetOperatorLabels() map[string]string {
+	if x != nil {
+		return x.OperatorLabels
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetRemovedLabels() []string {
+	if x != nil {
+		return x.RemovedLabels
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetStacks() []string {
+	if x != nil {
+		return x.Stacks
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetPlugins() []string {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+func (x *RegistryAgent) GetInMaintenance() bool {
+	if x != nil {
+		return x.InMaintenance
+	}
+	return false
+}
+
+type CorePlugin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CorePlugin) Reset() {
+	*x = CorePlugin{}
+	mi := &file_api_v1_agent_proto_msgTypes[147]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CorePlugin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CorePlugin) ProtoMessage() {}
+
+func (x *CorePlugin) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[147]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CorePlugin.ProtoReflect.Descriptor instead.
+func (*CorePlugin) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{147}
+}
+
+func (x *CorePlugin) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CorePlugin) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CorePlugin) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[148]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[148]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{148}
+}
+
+type ConfigDump struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Yaml          string                 `protobuf:"bytes,1,opt,name=yaml,proto3" json:"yaml,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigDump) Reset() {
+	*x = ConfigDump{}
+	mi := &file_api_v1_agent_proto_msgTypes[149]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigDump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigDump) ProtoMessage() {}
+
+func (x *ConfigDump) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[149]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigDump.ProtoReflect.Descriptor instead.
+func (*ConfigDump) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{149}
+}
+
+func (x *ConfigDump) GetYaml() string {
+	if x != nil {
+		return x.Yaml
+	}
+	return ""
+}
+
+type Operation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type           string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	State          OperationState         `protobuf:"varint,3,opt,name=state,proto3,enum=mandau.agent.v1.OperationState" json:"state,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Error          string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Metadata       map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Progress       int32                  `protobuf:"varint,8,opt,name=progress,proto3" json:"progress,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Key of the request that started it, if any
+	Message        string                 `protobuf:"bytes,10,opt,name=message,proto3" json:"message,omitempty"`                                    // Last event message
+	RequestId      string                 `protobuf:"bytes,11,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`               // Of the command that started it, if any
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_api_v1_agent_proto_msgTypes[150]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[150]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{150}
+}
+
+func (x *Operation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Operation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Operation) GetState() OperationState {
+	if x != nil {
+		return x.State
+	}
+	return OperationState_OPERATION_STATE_PENDING
+}
+
+func (x *Operation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Operation) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Operation) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Operation) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *Operation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Operation) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type OperationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	State         OperationState         `protobuf:"varint,2,opt,name=state,proto3,enum=mandau.agent.v1.OperationState" json:"state,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationEvent) Reset() {
+	*x = OperationEvent{}
+	mi := &file_api_v1_agent_proto_msgTypes[151]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationEvent) ProtoMessage() {}
+
+func (x *OperationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[151]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationEvent.ProtoReflect.Descriptor instead.
+func (*OperationEvent) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{151}
+}
+
+func (x *OperationEvent) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetState() OperationState {
+	if x != nil {
+		return x.State
+	}
+	return OperationState_OPERATION_STATE_PENDING
+}
+
+func (x *OperationEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *OperationEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *OperationEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Missing messages
+type HeartbeatRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status  map[string]string      `protobuf:"bytes,2,rep,name=status,proto3" json:"status,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Outcomes of instructions received on earlier heartbeats
+	Results []*InstructionResult   `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	SentAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"` // Agent clock
+	// Agent clock minus core clock, measured on the previous heartbeat from
+	// its round trip; unset on the first
+	ClockOffset   *durationpb.Duration `protobuf:"bytes,5,opt,name=clock_offset,json=clockOffset,proto3" json:"clock_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[152]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[152]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{152}
+}
+
+func (x *HeartbeatRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetStatus() map[string]string {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetResults() []*InstructionResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetClockOffset() *durationpb.Duration {
+	if x != nil {
+		return x.ClockOffset
+	}
+	return nil
+}
+
+type HeartbeatResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// When to send the next heartbeat; the core stretches it as the fleet
+	// grows
+	NextHeartbeat *durationpb.Duration `protobuf:"bytes,2,opt,name=next_heartbeat,json=nextHeartbeat,proto3" json:"next_heartbeat,omitempty"`
+	// Work pending for the agent; redelivered until a result is reported
+	Instructions []*AgentInstruction    `protobuf:"bytes,3,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	CoreTime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=core_time,json=coreTime,proto3" json:"core_time,omitempty"` // For the agent to measure its clock offset
+	// The agent's clock is off by more than agent_management.max_clock_skew
+	ClockSkewed   bool `protobuf:"varint,5,opt,name=clock_skewed,json=clockSkewed,proto3" json:"clock_skewed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[153]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[153]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{153}
+}
+
+func (x *HeartbeatResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetNextHeartbeat() *durationpb.Duration {
+	if x != nil {
+		return x.NextHeartbeat
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetInstructions() []*AgentInstruction {
+	if x != nil {
+		return x.Instructions
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetCoreTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CoreTime
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetClockSkewed() bool {
+	if x != nil {
+		return x.ClockSkewed
+	}
+	return false
+}
+
+// AgentInstruction is work the core piggybacks on a heartbeat response
+type AgentInstruction struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RequestedBy string                 `protobuf:"bytes,3,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*AgentInstruction_Config
+	//	*AgentInstruction_ApplyStack
+	//	*AgentInstruction_RemoveStack
+	//	*AgentInstruction_Drain
+	Kind    isAgentInstruction_Kind `protobuf_oneof:"kind"`
+	AgentId string                  `protobuf:"bytes,8,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Dropped if still undelivered by then; unset never expires
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RequestId     string                 `protobuf:"bytes,10,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // Of the command that queued it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentInstruction) Reset() {
+	*x = AgentInstruction{}
+	mi := &file_api_v1_agent_proto_msgTypes[154]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentInstruction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentInstruction) ProtoMessage() {}
+
+func (x *AgentInstruction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[154]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentInstruction.ProtoReflect.Descriptor instead.
+func (*AgentInstruction) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{154}
+}
+
+func (x *AgentInstruction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AgentInstruction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *AgentInstruction) GetKind() isAgentInstruction_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetConfig() *ConfigInstruction {
+	if x != nil {
+		if x, ok := x.Kind.(*AgentInstruction_Config); ok {
+			return x.Config
+		}
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetApplyStack() *ApplyStackRequest {
+	if x != nil {
+		if x, ok := x.Kind.(*AgentInstruction_ApplyStack); ok {
+			return x.ApplyStack
+		}
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetRemoveStack() *RemoveStackRequest {
+	if x != nil {
+		if x, ok := x.Kind.(*AgentInstruction_RemoveStack); ok {
+			return x.RemoveStack
+		}
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetDrain() *DrainInstruction {
+	if x != nil {
+		if x, ok := x.Kind.(*AgentInstruction_Drain); ok {
+			return x.Drain
+		}
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentInstruction) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *AgentInstruction) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type isAgentInstruction_Kind interface {
+	isAgentInstruction_Kind()
+}
+
+type AgentInstruction_Config struct {
+	Config *ConfigInstruction `protobuf:"bytes,4,opt,name=config,proto3,oneof"`
+}
+
+type AgentInstruction_ApplyStack struct {
+	ApplyStack *ApplyStackRequest `protobuf:"bytes,5,opt,name=apply_stack,json=applyStack,proto3,oneof"`
+}
+
+type AgentInstruction_RemoveStack struct {
+	RemoveStack *RemoveStackRequest `protobuf:"bytes,6,opt,name=remove_stack,json=removeStack,proto3,oneof"`
+}
+
+type AgentInstruction_Drain struct {
+	Drain *DrainInstruction `protobuf:"bytes,7,opt,name=drain,proto3,oneof"`
+}
+
+func (*AgentInstruction_Config) isAgentInstruction_Kind() {}
+
+func (*AgentInstruction_ApplyStack) isAgentInstruction_Kind() {}
+
+func (*AgentInstruction_RemoveStack) isAgentInstruction_Kind() {}
+
+func (*AgentInstruction_Drain) isAgentInstruction_Kind() {}
+
+// ConfigInstruction asks the agent to fetch its configuration again
+type ConfigInstruction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigInstruction) Reset() {
+	*x = ConfigInstruction{}
+	mi := &file_api_v1_agent_proto_msgTypes[155]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigInstruction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigInstruction) ProtoMessage() {}
+
+func (x *ConfigInstruction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[155]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigInstruction.ProtoReflect.Descriptor instead.
+func (*ConfigInstruction) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{155}
+}
+
+func (x *ConfigInstruction) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// DrainInstruction stops (or resumes) accepting new stack changes
+type DrainInstruction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainInstruction) Reset() {
+	*x = DrainInstruction{}
+	mi := &file_api_v1_agent_proto_msgTypes[156]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainInstruction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainInstruction) ProtoMessage() {}
+
+func (x *DrainInstruction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[156]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainInstruction.ProtoReflect.Descriptor instead.
+func (*DrainInstruction) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{156}
+}
+
+func (x *DrainInstruction) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *DrainInstruction) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type QueueAgentInstructionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instruction   *AgentInstruction      `protobuf:"bytes,2,opt,name=instruction,proto3" json:"instruction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueAgentInstructionRequest) Reset() {
+	*x = QueueAgentInstructionRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[157]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueAgentInstructionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueAgentInstructionRequest) ProtoMessage() {}
+
+func (x *QueueAgentInstructionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[157]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueAgentInstructionRequest.ProtoReflect.Descriptor instead.
+func (*QueueAgentInstructionRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{157}
+}
+
+func (x *QueueAgentInstructionRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *QueueAgentInstructionRequest) GetInstruction() *AgentInstruction {
+	if x != nil {
+		return x.Instruction
+	}
+	return nil
+}
+
+// An empty agent_id lists the pending instructions of every agent
+type ListAgentInstructionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentInstructionsRequest) Reset() {
+	*x = ListAgentInstructionsRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[158]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentInstructionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentInstructionsRequest) ProtoMessage() {}
+
+func (x *ListAgentInstructionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[158]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentInstructionsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentInstructionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{158}
+}
+
+func (x *ListAgentInstructionsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListAgentInstructionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pending       []*AgentInstruction    `protobuf:"bytes,1,rep,name=pending,proto3" json:"pending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentInstructionsResponse) Reset() {
+	*x = ListAgentInstructionsResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[159]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentInstructionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentInstructionsResponse) ProtoMessage() {}
+
+func (x *ListAgentInstructionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[159]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentInstructionsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentInstructionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{159}
 }
 
-func (x *OperationEvent) GetError() string {
+func (x *ListAgentInstructionsResponse) GetPending() []*AgentInstruction {
 	if x != nil {
-		return x.Error
+		return x.Pending
 	}
-	return ""
+	return nil
 }
 
-// Missing messages
-type HeartbeatRequest struct {
+// Only instructions not yet delivered to the agent can be cancelled
+type CancelAgentInstructionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Status        map[string]string      `protobuf:"bytes,2,rep,name=status,proto3" json:"status,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	InstructionId string                 `protobuf:"bytes,2,opt,name=instruction_id,json=instructionId,proto3" json:"instruction_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatRequest) Reset() {
-	*x = HeartbeatRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[26]
+func (x *CancelAgentInstructionRequest) Reset() {
+	*x = CancelAgentInstructionRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[160]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatRequest) String() string {
+func (x *CancelAgentInstructionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatRequest) ProtoMessage() {}
+func (*CancelAgentInstructionRequest) ProtoMessage() {}
 
-func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[26]
+func (x *CancelAgentInstructionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[160]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2087,48 +11471,50 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
-func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use CancelAgentInstructionRequest.ProtoReflect.Descriptor instead.
+func (*CancelAgentInstructionRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{160}
 }
 
-func (x *HeartbeatRequest) GetAgentId() string {
+func (x *CancelAgentInstructionRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *HeartbeatRequest) GetStatus() map[string]string {
+func (x *CancelAgentInstructionRequest) GetInstructionId() string {
 	if x != nil {
-		return x.Status
+		return x.InstructionId
 	}
-	return nil
+	return ""
 }
 
-type HeartbeatResponse struct {
+type InstructionResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	NextHeartbeat *durationpb.Duration   `protobuf:"bytes,2,opt,name=next_heartbeat,json=nextHeartbeat,proto3" json:"next_heartbeat,omitempty"`
+	InstructionId string                 `protobuf:"bytes,1,opt,name=instruction_id,json=instructionId,proto3" json:"instruction_id,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	OperationId   string                 `protobuf:"bytes,4,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"` // Agent operation the instruction ran as, if any
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatResponse) Reset() {
-	*x = HeartbeatResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[27]
+func (x *InstructionResult) Reset() {
+	*x = InstructionResult{}
+	mi := &file_api_v1_agent_proto_msgTypes[161]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatResponse) String() string {
+func (x *InstructionResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatResponse) ProtoMessage() {}
+func (*InstructionResult) ProtoMessage() {}
 
-func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[27]
+func (x *InstructionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[161]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2139,23 +11525,37 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
-func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use InstructionResult.ProtoReflect.Descriptor instead.
+func (*InstructionResult) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{161}
 }
 
-func (x *HeartbeatResponse) GetStatus() string {
+func (x *InstructionResult) GetInstructionId() string {
 	if x != nil {
-		return x.Status
+		return x.InstructionId
 	}
 	return ""
 }
 
-func (x *HeartbeatResponse) GetNextHeartbeat() *durationpb.Duration {
+func (x *InstructionResult) GetSuccess() bool {
 	if x != nil {
-		return x.NextHeartbeat
+		return x.Success
 	}
-	return nil
+	return false
+}
+
+func (x *InstructionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *InstructionResult) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
 }
 
 type CapabilitiesRequest struct {
@@ -2166,7 +11566,7 @@ type CapabilitiesRequest struct {
 
 func (x *CapabilitiesRequest) Reset() {
 	*x = CapabilitiesRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[28]
+	mi := &file_api_v1_agent_proto_msgTypes[162]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2178,7 +11578,7 @@ func (x *CapabilitiesRequest) String() string {
 func (*CapabilitiesRequest) ProtoMessage() {}
 
 func (x *CapabilitiesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[28]
+	mi := &file_api_v1_agent_proto_msgTypes[162]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2191,19 +11591,20 @@ func (x *CapabilitiesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CapabilitiesRequest.ProtoReflect.Descriptor instead.
 func (*CapabilitiesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{28}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{162}
 }
 
 type CapabilitiesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Capabilities  []string               `protobuf:"bytes,1,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Plugins       []*PluginAvailability  `protobuf:"bytes,2,rep,name=plugins,proto3" json:"plugins,omitempty"` // Host service plugins enabled in the agent config
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CapabilitiesResponse) Reset() {
 	*x = CapabilitiesResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[29]
+	mi := &file_api_v1_agent_proto_msgTypes[163]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2215,7 +11616,7 @@ func (x *CapabilitiesResponse) String() string {
 func (*CapabilitiesResponse) ProtoMessage() {}
 
 func (x *CapabilitiesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[29]
+	mi := &file_api_v1_agent_proto_msgTypes[163]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2228,7 +11629,7 @@ func (x *CapabilitiesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CapabilitiesResponse.ProtoReflect.Descriptor instead.
 func (*CapabilitiesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{29}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{163}
 }
 
 func (x *CapabilitiesResponse) GetCapabilities() []string {
@@ -2238,6 +11639,13 @@ func (x *CapabilitiesResponse) GetCapabilities() []string {
 	return nil
 }
 
+func (x *CapabilitiesResponse) GetPlugins() []*PluginAvailability {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
 type HealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -2246,7 +11654,7 @@ type HealthRequest struct {
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[30]
+	mi := &file_api_v1_agent_proto_msgTypes[164]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2258,7 +11666,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[30]
+	mi := &file_api_v1_agent_proto_msgTypes[164]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2271,7 +11679,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{30}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{164}
 }
 
 type HealthResponse struct {
@@ -2284,7 +11692,7 @@ type HealthResponse struct {
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[31]
+	mi := &file_api_v1_agent_proto_msgTypes[165]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2296,7 +11704,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[31]
+	mi := &file_api_v1_agent_proto_msgTypes[165]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2309,7 +11717,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{31}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{165}
 }
 
 func (x *HealthResponse) GetHealthy() bool {
@@ -2328,14 +11736,18 @@ func (x *HealthResponse) GetStatus() map[string]string {
 
 type ListStacksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                                                          // Empty lists every online agent
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Only stacks carrying all of these labels
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`                                                                     // Empty lists every namespace
+	DiskUsage     bool                   `protobuf:"varint,4,opt,name=disk_usage,json=diskUsage,proto3" json:"disk_usage,omitempty"`                                                   // Measure each stack's disk usage, which is slower
+	ImageDigests  bool                   `protobuf:"varint,5,opt,name=image_digests,json=imageDigests,proto3" json:"image_digests,omitempty"`                                          // Look up the registry digests of container images
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListStacksRequest) Reset() {
 	*x = ListStacksRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[32]
+	mi := &file_api_v1_agent_proto_msgTypes[166]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2347,7 +11759,7 @@ func (x *ListStacksRequest) String() string {
 func (*ListStacksRequest) ProtoMessage() {}
 
 func (x *ListStacksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[32]
+	mi := &file_api_v1_agent_proto_msgTypes[166]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2360,7 +11772,7 @@ func (x *ListStacksRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListStacksRequest.ProtoReflect.Descriptor instead.
 func (*ListStacksRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{32}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{166}
 }
 
 func (x *ListStacksRequest) GetAgentId() string {
@@ -2370,16 +11782,45 @@ func (x *ListStacksRequest) GetAgentId() string {
 	return ""
 }
 
+func (x *ListStacksRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListStacksRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ListStacksRequest) GetDiskUsage() bool {
+	if x != nil {
+		return x.DiskUsage
+	}
+	return false
+}
+
+func (x *ListStacksRequest) GetImageDigests() bool {
+	if x != nil {
+		return x.ImageDigests
+	}
+	return false
+}
+
 type ListStacksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Stacks        []*Stack               `protobuf:"bytes,1,rep,name=stacks,proto3" json:"stacks,omitempty"`
+	AgentErrors   map[string]string      `protobuf:"bytes,2,rep,name=agent_errors,json=agentErrors,proto3" json:"agent_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Fleet-wide listings: agents that did not answer
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListStacksResponse) Reset() {
 	*x = ListStacksResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[33]
+	mi := &file_api_v1_agent_proto_msgTypes[167]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2391,7 +11832,7 @@ func (x *ListStacksResponse) String() string {
 func (*ListStacksResponse) ProtoMessage() {}
 
 func (x *ListStacksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[33]
+	mi := &file_api_v1_agent_proto_msgTypes[167]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2404,7 +11845,7 @@ func (x *ListStacksResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListStacksResponse.ProtoReflect.Descriptor instead.
 func (*ListStacksResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{33}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{167}
 }
 
 func (x *ListStacksResponse) GetStacks() []*Stack {
@@ -2414,16 +11855,24 @@ func (x *ListStacksResponse) GetStacks() []*Stack {
 	return nil
 }
 
+func (x *ListStacksResponse) GetAgentErrors() map[string]string {
+	if x != nil {
+		return x.AgentErrors
+	}
+	return nil
+}
+
 type GetStackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetStackRequest) Reset() {
 	*x = GetStackRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[34]
+	mi := &file_api_v1_agent_proto_msgTypes[168]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2435,7 +11884,7 @@ func (x *GetStackRequest) String() string {
 func (*GetStackRequest) ProtoMessage() {}
 
 func (x *GetStackRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[34]
+	mi := &file_api_v1_agent_proto_msgTypes[168]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2448,7 +11897,7 @@ func (x *GetStackRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStackRequest.ProtoReflect.Descriptor instead.
 func (*GetStackRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{34}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{168}
 }
 
 func (x *GetStackRequest) GetStackId() string {
@@ -2458,6 +11907,13 @@ func (x *GetStackRequest) GetStackId() string {
 	return ""
 }
 
+func (x *GetStackRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
 type GetStackResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Stack         *Stack                 `protobuf:"bytes,1,opt,name=stack,proto3" json:"stack,omitempty"`
@@ -2467,7 +11923,7 @@ type GetStackResponse struct {
 
 func (x *GetStackResponse) Reset() {
 	*x = GetStackResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[35]
+	mi := &file_api_v1_agent_proto_msgTypes[169]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2479,7 +11935,7 @@ func (x *GetStackResponse) String() string {
 func (*GetStackResponse) ProtoMessage() {}
 
 func (x *GetStackResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[35]
+	mi := &file_api_v1_agent_proto_msgTypes[169]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2492,7 +11948,7 @@ func (x *GetStackResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStackResponse.ProtoReflect.Descriptor instead.
 func (*GetStackResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{35}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{169}
 }
 
 func (x *GetStackResponse) GetStack() *Stack {
@@ -2503,15 +11959,23 @@ func (x *GetStackResponse) GetStack() *Stack {
 }
 
 type RemoveStackRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	StackId        string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	Emergency      bool                   `protobuf:"varint,2,opt,name=emergency,proto3" json:"emergency,omitempty"` // Allowed while the agent is in maintenance
+	ApprovalId     string                 `protobuf:"bytes,3,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	Namespace      string                 `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	AgentId        string                 `protobuf:"bytes,5,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                      // Empty means look the stack up across agents
+	Group          string                 `protobuf:"bytes,6,opt,name=group,proto3" json:"group,omitempty"`                                         // Set when fanned out to a group; one approval covers it
+	Queue          bool                   `protobuf:"varint,7,opt,name=queue,proto3" json:"queue,omitempty"`                                        // Queue for delivery if the agent is offline
+	QueueTtl       *durationpb.Duration   `protobuf:"bytes,8,opt,name=queue_ttl,json=queueTtl,proto3" json:"queue_ttl,omitempty"`                   // Zero uses the core default
+	IdempotencyKey string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // As in ApplyStackRequest
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *RemoveStackRequest) Reset() {
 	*x = RemoveStackRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[36]
+	mi := &file_api_v1_agent_proto_msgTypes[170]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2523,7 +11987,7 @@ func (x *RemoveStackRequest) String() string {
 func (*RemoveStackRequest) ProtoMessage() {}
 
 func (x *RemoveStackRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[36]
+	mi := &file_api_v1_agent_proto_msgTypes[170]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2536,7 +12000,7 @@ func (x *RemoveStackRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveStackRequest.ProtoReflect.Descriptor instead.
 func (*RemoveStackRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{36}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{170}
 }
 
 func (x *RemoveStackRequest) GetStackId() string {
@@ -2546,18 +12010,84 @@ func (x *RemoveStackRequest) GetStackId() string {
 	return ""
 }
 
+func (x *RemoveStackRequest) GetEmergency() bool {
+	if x != nil {
+		return x.Emergency
+	}
+	return false
+}
+
+func (x *RemoveStackRequest) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *RemoveStackRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RemoveStackRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RemoveStackRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *RemoveStackRequest) GetQueue() bool {
+	if x != nil {
+		return x.Queue
+	}
+	return false
+}
+
+func (x *RemoveStackRequest) GetQueueTtl() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTtl
+	}
+	return nil
+}
+
+func (x *RemoveStackRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type GetStackLogsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	StackName     string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
-	Follow        bool                   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StackName       string                 `protobuf:"bytes,2,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Follow          bool                   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	Namespace       string                 `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	BatchSize       int32                  `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`                     // GetStackLogsBatched only; 0 uses the default
+	BatchIntervalMs int32                  `protobuf:"varint,6,opt,name=batch_interval_ms,json=batchIntervalMs,proto3" json:"batch_interval_ms,omitempty"` // GetStackLogsBatched only; 0 uses the default
+	// Searched in the agent's log index when logs.index is enabled, else in
+	// the logs Docker still has. With follow, entries since then are sent
+	// before following.
+	Since         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=until,proto3" json:"until,omitempty"` // Not with follow
+	Grep          string                 `protobuf:"bytes,9,opt,name=grep,proto3" json:"grep,omitempty"`   // Regular expression content must match
+	Tail          int32                  `protobuf:"varint,10,opt,name=tail,proto3" json:"tail,omitempty"` // Last entries to send first; 0 is 1000 without since, all with it
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetStackLogsRequest) Reset() {
 	*x = GetStackLogsRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[37]
+	mi := &file_api_v1_agent_proto_msgTypes[171]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2569,7 +12099,114 @@ func (x *GetStackLogsRequest) String() string {
 func (*GetStackLogsRequest) ProtoMessage() {}
 
 func (x *GetStackLogsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[37]
+	mi := &file_api_v1_agent_proto_msgTypes[171]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStackLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetStackLogsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{171}
+}
+
+func (x *GetStackLogsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetStackLogsRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *GetStackLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *GetStackLogsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetStackLogsRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *GetStackLogsRequest) GetBatchIntervalMs() int32 {
+	if x != nil {
+		return x.BatchIntervalMs
+	}
+	return 0
+}
+
+func (x *GetStackLogsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetStackLogsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *GetStackLogsRequest) GetGrep() string {
+	if x != nil {
+		return x.Grep
+	}
+	return ""
+}
+
+func (x *GetStackLogsRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+type LogBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*LogEntry            `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogBatch) Reset() {
+	*x = LogBatch{}
+	mi := &file_api_v1_agent_proto_msgTypes[172]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogBatch) ProtoMessage() {}
+
+func (x *LogBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[172]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2580,30 +12217,16 @@ func (x *GetStackLogsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStackLogsRequest.ProtoReflect.Descriptor instead.
-func (*GetStackLogsRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{37}
-}
-
-func (x *GetStackLogsRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
-}
-
-func (x *GetStackLogsRequest) GetStackName() string {
-	if x != nil {
-		return x.StackName
-	}
-	return ""
+// Deprecated: Use LogBatch.ProtoReflect.Descriptor instead.
+func (*LogBatch) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{172}
 }
 
-func (x *GetStackLogsRequest) GetFollow() bool {
+func (x *LogBatch) GetEntries() []*LogEntry {
 	if x != nil {
-		return x.Follow
+		return x.Entries
 	}
-	return false
+	return nil
 }
 
 type ListContainersRequest struct {
@@ -2614,7 +12237,7 @@ type ListContainersRequest struct {
 
 func (x *ListContainersRequest) Reset() {
 	*x = ListContainersRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[38]
+	mi := &file_api_v1_agent_proto_msgTypes[173]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2626,7 +12249,7 @@ func (x *ListContainersRequest) String() string {
 func (*ListContainersRequest) ProtoMessage() {}
 
 func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[38]
+	mi := &file_api_v1_agent_proto_msgTypes[173]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2639,7 +12262,7 @@ func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListContainersRequest.ProtoReflect.Descriptor instead.
 func (*ListContainersRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{38}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{173}
 }
 
 type ListContainersResponse struct {
@@ -2651,7 +12274,7 @@ type ListContainersResponse struct {
 
 func (x *ListContainersResponse) Reset() {
 	*x = ListContainersResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[39]
+	mi := &file_api_v1_agent_proto_msgTypes[174]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2663,7 +12286,7 @@ func (x *ListContainersResponse) String() string {
 func (*ListContainersResponse) ProtoMessage() {}
 
 func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[39]
+	mi := &file_api_v1_agent_proto_msgTypes[174]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2676,7 +12299,7 @@ func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListContainersResponse.ProtoReflect.Descriptor instead.
 func (*ListContainersResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{39}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{174}
 }
 
 func (x *ListContainersResponse) GetContainers() []*Container {
@@ -2695,7 +12318,7 @@ type InspectContainerRequest struct {
 
 func (x *InspectContainerRequest) Reset() {
 	*x = InspectContainerRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[40]
+	mi := &file_api_v1_agent_proto_msgTypes[175]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2707,7 +12330,7 @@ func (x *InspectContainerRequest) String() string {
 func (*InspectContainerRequest) ProtoMessage() {}
 
 func (x *InspectContainerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[40]
+	mi := &file_api_v1_agent_proto_msgTypes[175]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2720,7 +12343,7 @@ func (x *InspectContainerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InspectContainerRequest.ProtoReflect.Descriptor instead.
 func (*InspectContainerRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{40}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{175}
 }
 
 func (x *InspectContainerRequest) GetContainerId() string {
@@ -2739,7 +12362,7 @@ type InspectContainerResponse struct {
 
 func (x *InspectContainerResponse) Reset() {
 	*x = InspectContainerResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[41]
+	mi := &file_api_v1_agent_proto_msgTypes[176]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2751,7 +12374,7 @@ func (x *InspectContainerResponse) String() string {
 func (*InspectContainerResponse) ProtoMessage() {}
 
 func (x *InspectContainerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[41]
+	mi := &file_api_v1_agent_proto_msgTypes[176]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2764,7 +12387,7 @@ func (x *InspectContainerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InspectContainerResponse.ProtoReflect.Descriptor instead.
 func (*InspectContainerResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{41}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{176}
 }
 
 func (x *InspectContainerResponse) GetContainer() *Container {
@@ -2783,7 +12406,7 @@ type StreamLogsRequest struct {
 
 func (x *StreamLogsRequest) Reset() {
 	*x = StreamLogsRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[42]
+	mi := &file_api_v1_agent_proto_msgTypes[177]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2795,7 +12418,7 @@ func (x *StreamLogsRequest) String() string {
 func (*StreamLogsRequest) ProtoMessage() {}
 
 func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[42]
+	mi := &file_api_v1_agent_proto_msgTypes[177]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2808,7 +12431,7 @@ func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
 func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{42}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{177}
 }
 
 func (x *StreamLogsRequest) GetContainerId() string {
@@ -2827,7 +12450,7 @@ type GetStatsRequest struct {
 
 func (x *GetStatsRequest) Reset() {
 	*x = GetStatsRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[43]
+	mi := &file_api_v1_agent_proto_msgTypes[178]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2839,7 +12462,7 @@ func (x *GetStatsRequest) String() string {
 func (*GetStatsRequest) ProtoMessage() {}
 
 func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[43]
+	mi := &file_api_v1_agent_proto_msgTypes[178]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2852,7 +12475,7 @@ func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetStatsRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{43}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{178}
 }
 
 func (x *GetStatsRequest) GetContainerId() string {
@@ -2871,7 +12494,7 @@ type StartContainerRequest struct {
 
 func (x *StartContainerRequest) Reset() {
 	*x = StartContainerRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[44]
+	mi := &file_api_v1_agent_proto_msgTypes[179]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2883,7 +12506,7 @@ func (x *StartContainerRequest) String() string {
 func (*StartContainerRequest) ProtoMessage() {}
 
 func (x *StartContainerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[44]
+	mi := &file_api_v1_agent_proto_msgTypes[179]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2896,7 +12519,7 @@ func (x *StartContainerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartContainerRequest.ProtoReflect.Descriptor instead.
 func (*StartContainerRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{44}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{179}
 }
 
 func (x *StartContainerRequest) GetContainerId() string {
@@ -2914,7 +12537,7 @@ type StartContainerResponse struct {
 
 func (x *StartContainerResponse) Reset() {
 	*x = StartContainerResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[45]
+	mi := &file_api_v1_agent_proto_msgTypes[180]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2926,7 +12549,7 @@ func (x *StartContainerResponse) String() string {
 func (*StartContainerResponse) ProtoMessage() {}
 
 func (x *StartContainerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[45]
+	mi := &file_api_v1_agent_proto_msgTypes[180]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2939,7 +12562,7 @@ func (x *StartContainerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartContainerResponse.ProtoReflect.Descriptor instead.
 func (*StartContainerResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{45}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{180}
 }
 
 type StopContainerRequest struct {
@@ -2951,7 +12574,7 @@ type StopContainerRequest struct {
 
 func (x *StopContainerRequest) Reset() {
 	*x = StopContainerRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[46]
+	mi := &file_api_v1_agent_proto_msgTypes[181]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2963,7 +12586,7 @@ func (x *StopContainerRequest) String() string {
 func (*StopContainerRequest) ProtoMessage() {}
 
 func (x *StopContainerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[46]
+	mi := &file_api_v1_agent_proto_msgTypes[181]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2976,7 +12599,7 @@ func (x *StopContainerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopContainerRequest.ProtoReflect.Descriptor instead.
 func (*StopContainerRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{46}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{181}
 }
 
 func (x *StopContainerRequest) GetContainerId() string {
@@ -2994,7 +12617,7 @@ type StopContainerResponse struct {
 
 func (x *StopContainerResponse) Reset() {
 	*x = StopContainerResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[47]
+	mi := &file_api_v1_agent_proto_msgTypes[182]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3006,7 +12629,7 @@ func (x *StopContainerResponse) String() string {
 func (*StopContainerResponse) ProtoMessage() {}
 
 func (x *StopContainerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[47]
+	mi := &file_api_v1_agent_proto_msgTypes[182]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3019,7 +12642,7 @@ func (x *StopContainerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopContainerResponse.ProtoReflect.Descriptor instead.
 func (*StopContainerResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{47}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{182}
 }
 
 type RestartContainerRequest struct {
@@ -3031,7 +12654,7 @@ type RestartContainerRequest struct {
 
 func (x *RestartContainerRequest) Reset() {
 	*x = RestartContainerRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[48]
+	mi := &file_api_v1_agent_proto_msgTypes[183]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3043,7 +12666,7 @@ func (x *RestartContainerRequest) String() string {
 func (*RestartContainerRequest) ProtoMessage() {}
 
 func (x *RestartContainerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[48]
+	mi := &file_api_v1_agent_proto_msgTypes[183]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3056,7 +12679,7 @@ func (x *RestartContainerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartContainerRequest.ProtoReflect.Descriptor instead.
 func (*RestartContainerRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{48}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{183}
 }
 
 func (x *RestartContainerRequest) GetContainerId() string {
@@ -3074,7 +12697,7 @@ type RestartContainerResponse struct {
 
 func (x *RestartContainerResponse) Reset() {
 	*x = RestartContainerResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[49]
+	mi := &file_api_v1_agent_proto_msgTypes[184]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3086,7 +12709,7 @@ func (x *RestartContainerResponse) String() string {
 func (*RestartContainerResponse) ProtoMessage() {}
 
 func (x *RestartContainerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[49]
+	mi := &file_api_v1_agent_proto_msgTypes[184]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3099,7 +12722,7 @@ func (x *RestartContainerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartContainerResponse.ProtoReflect.Descriptor instead.
 func (*RestartContainerResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{49}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{184}
 }
 
 type WriteFileResponse struct {
@@ -3110,7 +12733,7 @@ type WriteFileResponse struct {
 
 func (x *WriteFileResponse) Reset() {
 	*x = WriteFileResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[50]
+	mi := &file_api_v1_agent_proto_msgTypes[185]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3122,7 +12745,7 @@ func (x *WriteFileResponse) String() string {
 func (*WriteFileResponse) ProtoMessage() {}
 
 func (x *WriteFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[50]
+	mi := &file_api_v1_agent_proto_msgTypes[185]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3135,7 +12758,7 @@ func (x *WriteFileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteFileResponse.ProtoReflect.Descriptor instead.
 func (*WriteFileResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{50}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{185}
 }
 
 type DeleteFileRequest struct {
@@ -3147,7 +12770,7 @@ type DeleteFileRequest struct {
 
 func (x *DeleteFileRequest) Reset() {
 	*x = DeleteFileRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[51]
+	mi := &file_api_v1_agent_proto_msgTypes[186]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3159,7 +12782,7 @@ func (x *DeleteFileRequest) String() string {
 func (*DeleteFileRequest) ProtoMessage() {}
 
 func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[51]
+	mi := &file_api_v1_agent_proto_msgTypes[186]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3172,7 +12795,7 @@ func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
 func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{51}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{186}
 }
 
 func (x *DeleteFileRequest) GetPath() string {
@@ -3190,7 +12813,7 @@ type DeleteFileResponse struct {
 
 func (x *DeleteFileResponse) Reset() {
 	*x = DeleteFileResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[52]
+	mi := &file_api_v1_agent_proto_msgTypes[187]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3202,7 +12825,7 @@ func (x *DeleteFileResponse) String() string {
 func (*DeleteFileResponse) ProtoMessage() {}
 
 func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[52]
+	mi := &file_api_v1_agent_proto_msgTypes[187]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3215,7 +12838,7 @@ func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
 func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{52}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{187}
 }
 
 type CreateDirectoryRequest struct {
@@ -3227,7 +12850,7 @@ type CreateDirectoryRequest struct {
 
 func (x *CreateDirectoryRequest) Reset() {
 	*x = CreateDirectoryRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[53]
+	mi := &file_api_v1_agent_proto_msgTypes[188]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3239,7 +12862,7 @@ func (x *CreateDirectoryRequest) String() string {
 func (*CreateDirectoryRequest) ProtoMessage() {}
 
 func (x *CreateDirectoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[53]
+	mi := &file_api_v1_agent_proto_msgTypes[188]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3252,7 +12875,7 @@ func (x *CreateDirectoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateDirectoryRequest.ProtoReflect.Descriptor instead.
 func (*CreateDirectoryRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{53}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{188}
 }
 
 func (x *CreateDirectoryRequest) GetPath() string {
@@ -3270,7 +12893,7 @@ type CreateDirectoryResponse struct {
 
 func (x *CreateDirectoryResponse) Reset() {
 	*x = CreateDirectoryResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[54]
+	mi := &file_api_v1_agent_proto_msgTypes[189]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3282,7 +12905,7 @@ func (x *CreateDirectoryResponse) String() string {
 func (*CreateDirectoryResponse) ProtoMessage() {}
 
 func (x *CreateDirectoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[54]
+	mi := &file_api_v1_agent_proto_msgTypes[189]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3295,19 +12918,20 @@ func (x *CreateDirectoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateDirectoryResponse.ProtoReflect.Descriptor instead.
 func (*CreateDirectoryResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{54}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{189}
 }
 
 type GetOperationRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetOperationRequest) Reset() {
 	*x = GetOperationRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[55]
+	mi := &file_api_v1_agent_proto_msgTypes[190]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3319,7 +12943,7 @@ func (x *GetOperationRequest) String() string {
 func (*GetOperationRequest) ProtoMessage() {}
 
 func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[55]
+	mi := &file_api_v1_agent_proto_msgTypes[190]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3332,7 +12956,7 @@ func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOperationRequest.ProtoReflect.Descriptor instead.
 func (*GetOperationRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{55}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{190}
 }
 
 func (x *GetOperationRequest) GetOperationId() string {
@@ -3342,15 +12966,24 @@ func (x *GetOperationRequest) GetOperationId() string {
 	return ""
 }
 
+func (x *GetOperationRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
 type ListOperationsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Only the operation started with this key
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ListOperationsRequest) Reset() {
 	*x = ListOperationsRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[56]
+	mi := &file_api_v1_agent_proto_msgTypes[191]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3362,7 +12995,7 @@ func (x *ListOperationsRequest) String() string {
 func (*ListOperationsRequest) ProtoMessage() {}
 
 func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[56]
+	mi := &file_api_v1_agent_proto_msgTypes[191]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3375,18 +13008,33 @@ func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListOperationsRequest.ProtoReflect.Descriptor instead.
 func (*ListOperationsRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{56}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{191}
+}
+
+func (x *ListOperationsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ListOperationsRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
 }
 
 type ListOperationsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operations    []*Operation           `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListOperationsResponse) Reset() {
 	*x = ListOperationsResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[57]
+	mi := &file_api_v1_agent_proto_msgTypes[192]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3398,7 +13046,7 @@ func (x *ListOperationsResponse) String() string {
 func (*ListOperationsResponse) ProtoMessage() {}
 
 func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[57]
+	mi := &file_api_v1_agent_proto_msgTypes[192]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3411,7 +13059,14 @@ func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListOperationsResponse.ProtoReflect.Descriptor instead.
 func (*ListOperationsResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{57}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{192}
+}
+
+func (x *ListOperationsResponse) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
 }
 
 type CancelOperationRequest struct {
@@ -3423,7 +13078,7 @@ type CancelOperationRequest struct {
 
 func (x *CancelOperationRequest) Reset() {
 	*x = CancelOperationRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[58]
+	mi := &file_api_v1_agent_proto_msgTypes[193]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3435,7 +13090,7 @@ func (x *CancelOperationRequest) String() string {
 func (*CancelOperationRequest) ProtoMessage() {}
 
 func (x *CancelOperationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[58]
+	mi := &file_api_v1_agent_proto_msgTypes[193]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3448,37 +13103,189 @@ func (x *CancelOperationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelOperationRequest.ProtoReflect.Descriptor instead.
 func (*CancelOperationRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{58}
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{193}
+}
+
+func (x *CancelOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type CancelOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOperationResponse) Reset() {
+	*x = CancelOperationResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[194]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOperationResponse) ProtoMessage() {}
+
+func (x *CancelOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[194]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOperationResponse.ProtoReflect.Descriptor instead.
+func (*CancelOperationResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{194}
+}
+
+type StreamOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOperationRequest) Reset() {
+	*x = StreamOperationRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[195]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOperationRequest) ProtoMessage() {}
+
+func (x *StreamOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[195]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOperationRequest.ProtoReflect.Descriptor instead.
+func (*StreamOperationRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{195}
+}
+
+func (x *StreamOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type CPUStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPUStats) Reset() {
+	*x = CPUStats{}
+	mi := &file_api_v1_agent_proto_msgTypes[196]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPUStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPUStats) ProtoMessage() {}
+
+func (x *CPUStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[196]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPUStats.ProtoReflect.Descriptor instead.
+func (*CPUStats) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{196}
+}
+
+type MemoryStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryStats) Reset() {
+	*x = MemoryStats{}
+	mi := &file_api_v1_agent_proto_msgTypes[197]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryStats) ProtoMessage() {}
+
+func (x *MemoryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[197]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *CancelOperationRequest) GetOperationId() string {
-	if x != nil {
-		return x.OperationId
-	}
-	return ""
+// Deprecated: Use MemoryStats.ProtoReflect.Descriptor instead.
+func (*MemoryStats) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{197}
 }
 
-type CancelOperationResponse struct {
+type NetworkStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelOperationResponse) Reset() {
-	*x = CancelOperationResponse{}
-	mi := &file_api_v1_agent_proto_msgTypes[59]
+func (x *NetworkStats) Reset() {
+	*x = NetworkStats{}
+	mi := &file_api_v1_agent_proto_msgTypes[198]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelOperationResponse) String() string {
+func (x *NetworkStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelOperationResponse) ProtoMessage() {}
+func (*NetworkStats) ProtoMessage() {}
 
-func (x *CancelOperationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[59]
+func (x *NetworkStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[198]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3489,33 +13296,32 @@ func (x *CancelOperationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelOperationResponse.ProtoReflect.Descriptor instead.
-func (*CancelOperationResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{59}
+// Deprecated: Use NetworkStats.ProtoReflect.Descriptor instead.
+func (*NetworkStats) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{198}
 }
 
-type StreamOperationRequest struct {
+type BlockIOStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamOperationRequest) Reset() {
-	*x = StreamOperationRequest{}
-	mi := &file_api_v1_agent_proto_msgTypes[60]
+func (x *BlockIOStats) Reset() {
+	*x = BlockIOStats{}
+	mi := &file_api_v1_agent_proto_msgTypes[199]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamOperationRequest) String() string {
+func (x *BlockIOStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamOperationRequest) ProtoMessage() {}
+func (*BlockIOStats) ProtoMessage() {}
 
-func (x *StreamOperationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[60]
+func (x *BlockIOStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[199]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3526,39 +13332,32 @@ func (x *StreamOperationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamOperationRequest.ProtoReflect.Descriptor instead.
-func (*StreamOperationRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{60}
-}
-
-func (x *StreamOperationRequest) GetOperationId() string {
-	if x != nil {
-		return x.OperationId
-	}
-	return ""
+// Deprecated: Use BlockIOStats.ProtoReflect.Descriptor instead.
+func (*BlockIOStats) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{199}
 }
 
-type CPUStats struct {
+type GetEnrollmentCARequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CPUStats) Reset() {
-	*x = CPUStats{}
-	mi := &file_api_v1_agent_proto_msgTypes[61]
+func (x *GetEnrollmentCARequest) Reset() {
+	*x = GetEnrollmentCARequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[200]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CPUStats) String() string {
+func (x *GetEnrollmentCARequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CPUStats) ProtoMessage() {}
+func (*GetEnrollmentCARequest) ProtoMessage() {}
 
-func (x *CPUStats) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[61]
+func (x *GetEnrollmentCARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[200]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3569,32 +13368,33 @@ func (x *CPUStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CPUStats.ProtoReflect.Descriptor instead.
-func (*CPUStats) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{61}
+// Deprecated: Use GetEnrollmentCARequest.ProtoReflect.Descriptor instead.
+func (*GetEnrollmentCARequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{200}
 }
 
-type MemoryStats struct {
+type GetEnrollmentCAResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	CaPem         []byte                 `protobuf:"bytes,1,opt,name=ca_pem,json=caPem,proto3" json:"ca_pem,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MemoryStats) Reset() {
-	*x = MemoryStats{}
-	mi := &file_api_v1_agent_proto_msgTypes[62]
+func (x *GetEnrollmentCAResponse) Reset() {
+	*x = GetEnrollmentCAResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[201]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MemoryStats) String() string {
+func (x *GetEnrollmentCAResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MemoryStats) ProtoMessage() {}
+func (*GetEnrollmentCAResponse) ProtoMessage() {}
 
-func (x *MemoryStats) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[62]
+func (x *GetEnrollmentCAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[201]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3605,32 +13405,43 @@ func (x *MemoryStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MemoryStats.ProtoReflect.Descriptor instead.
-func (*MemoryStats) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{62}
+// Deprecated: Use GetEnrollmentCAResponse.ProtoReflect.Descriptor instead.
+func (*GetEnrollmentCAResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{201}
 }
 
-type NetworkStats struct {
+func (x *GetEnrollmentCAResponse) GetCaPem() []byte {
+	if x != nil {
+		return x.CaPem
+	}
+	return nil
+}
+
+type EnrollRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	CsrPem        []byte                 `protobuf:"bytes,2,opt,name=csr_pem,json=csrPem,proto3" json:"csr_pem,omitempty"` // PKCS#10 request for the agent key
+	Hostname      string                 `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	AgentId       string                 `protobuf:"bytes,4,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Empty derives the ID from the hostname
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkStats) Reset() {
-	*x = NetworkStats{}
-	mi := &file_api_v1_agent_proto_msgTypes[63]
+func (x *EnrollRequest) Reset() {
+	*x = EnrollRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[202]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkStats) String() string {
+func (x *EnrollRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkStats) ProtoMessage() {}
+func (*EnrollRequest) ProtoMessage() {}
 
-func (x *NetworkStats) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[63]
+func (x *EnrollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[202]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3641,32 +13452,65 @@ func (x *NetworkStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkStats.ProtoReflect.Descriptor instead.
-func (*NetworkStats) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{63}
+// Deprecated: Use EnrollRequest.ProtoReflect.Descriptor instead.
+func (*EnrollRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{202}
 }
 
-type BlockIOStats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *EnrollRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
 }
 
-func (x *BlockIOStats) Reset() {
-	*x = BlockIOStats{}
-	mi := &file_api_v1_agent_proto_msgTypes[64]
+func (x *EnrollRequest) GetCsrPem() []byte {
+	if x != nil {
+		return x.CsrPem
+	}
+	return nil
+}
+
+func (x *EnrollRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *EnrollRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type EnrollResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CertificatePem []byte                 `protobuf:"bytes,2,opt,name=certificate_pem,json=certificatePem,proto3" json:"certificate_pem,omitempty"`
+	CaPem          []byte                 `protobuf:"bytes,3,opt,name=ca_pem,json=caPem,proto3" json:"ca_pem,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Labels the token assigns
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EnrollResponse) Reset() {
+	*x = EnrollResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[203]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BlockIOStats) String() string {
+func (x *EnrollResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlockIOStats) ProtoMessage() {}
+func (*EnrollResponse) ProtoMessage() {}
 
-func (x *BlockIOStats) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_agent_proto_msgTypes[64]
+func (x *EnrollResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[203]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3677,42 +13521,523 @@ func (x *BlockIOStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlockIOStats.ProtoReflect.Descriptor instead.
-func (*BlockIOStats) Descriptor() ([]byte, []int) {
-	return file_api_v1_agent_proto_rawDescGZIP(), []int{64}
+// Deprecated: Use EnrollResponse.ProtoReflect.Descriptor instead.
+func (*EnrollResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{203}
+}
+
+func (x *EnrollResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *EnrollResponse) GetCertificatePem() []byte {
+	if x != nil {
+		return x.CertificatePem
+	}
+	return nil
+}
+
+func (x *EnrollResponse) GetCaPem() []byte {
+	if x != nil {
+		return x.CaPem
+	}
+	return nil
+}
+
+func (x *EnrollResponse) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *EnrollResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
 }
 
 var File_api_v1_agent_proto protoreflect.FileDescriptor
 
 const file_api_v1_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x12api/v1/agent.proto\x12\x0fmandau.agent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\x13\n" +
-	"\x11ListAgentsRequest\"D\n" +
+	"\x12api/v1/agent.proto\x12\x0fmandau.agent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\xac\x01\n" +
+	"\x11ListAgentsRequest\x12F\n" +
+	"\x06labels\x18\x01 \x03(\v2..mandau.agent.v1.ListAgentsRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
 	"\x12ListAgentsResponse\x12.\n" +
-	"\x06agents\x18\x01 \x03(\v2\x16.mandau.agent.v1.AgentR\x06agents\"\x9f\x02\n" +
+	"\x06agents\x18\x01 \x03(\v2\x16.mandau.agent.v1.AgentR\x06agents\"\xae\x01\n" +
+	"\x12WatchAgentsRequest\x12G\n" +
+	"\x06labels\x18\x01 \x03(\v2/.mandau.agent.v1.WatchAgentsRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9f\x01\n" +
+	"\n" +
+	"AgentDelta\x123\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1f.mandau.agent.v1.AgentDeltaKindR\x04kind\x12,\n" +
+	"\x05agent\x18\x02 \x01(\v2\x16.mandau.agent.v1.AgentR\x05agent\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\xcb\x01\n" +
+	"\x18UpdateAgentLabelsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12D\n" +
+	"\x03set\x18\x02 \x03(\v22.mandau.agent.v1.UpdateAgentLabelsRequest.SetEntryR\x03set\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\x1a6\n" +
+	"\bSetEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"I\n" +
+	"\x19UpdateAgentLabelsResponse\x12,\n" +
+	"\x05agent\x18\x01 \x01(\v2\x16.mandau.agent.v1.AgentR\x05agent\"\xa0\x01\n" +
+	"\x1aSetAgentMaintenanceRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"K\n" +
+	"\x1bSetAgentMaintenanceResponse\x12,\n" +
+	"\x05agent\x18\x01 \x01(\v2\x16.mandau.agent.v1.AgentR\x05agent\"\xa0\x01\n" +
+	"\vMaintenance\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x15\n" +
+	"\x06set_by\x18\x02 \x01(\tR\x05setBy\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"\xc0\x04\n" +
 	"\x05Agent\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
 	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12:\n" +
 	"\x06labels\x18\x04 \x03(\v2\".mandau.agent.v1.Agent.LabelsEntryR\x06labels\x12\"\n" +
 	"\fcapabilities\x18\x05 \x03(\tR\fcapabilities\x127\n" +
-	"\tlast_seen\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x1a9\n" +
+	"\tlast_seen\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x16\n" +
+	"\x06groups\x18\a \x03(\tR\x06groups\x12>\n" +
+	"\vmaintenance\x18\b \x01(\v2\x1c.mandau.agent.v1.MaintenanceR\vmaintenance\x128\n" +
+	"\n" +
+	"clock_skew\x18\t \x01(\v2\x19.google.protobuf.DurationR\tclockSkew\x127\n" +
+	"\acircuit\x18\n" +
+	" \x01(\v2\x1d.mandau.agent.v1.AgentCircuitR\acircuit\x12T\n" +
+	"\x13unavailable_plugins\x18\v \x03(\v2#.mandau.agent.v1.PluginAvailabilityR\x12unavailablePlugins\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xde\x01\n" +
+	"\fAgentCircuit\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x1a\n" +
+	"\bfailures\x18\x04 \x01(\x05R\bfailures\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x12\x14\n" +
+	"\x05trips\x18\x06 \x01(\x03R\x05trips\x12\x1a\n" +
+	"\brejected\x18\a \x01(\x03R\brejected\"\x99\x02\n" +
+	"\n" +
+	"AgentGroup\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06agents\x18\x03 \x03(\tR\x06agents\x12E\n" +
+	"\bselector\x18\x04 \x03(\v2).mandau.agent.v1.AgentGroup.SelectorEntryR\bselector\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a;\n" +
+	"\rSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"L\n" +
+	"\x17CreateAgentGroupRequest\x121\n" +
+	"\x05group\x18\x01 \x01(\v2\x1b.mandau.agent.v1.AgentGroupR\x05group\"*\n" +
+	"\x14GetAgentGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"|\n" +
+	"\x15GetAgentGroupResponse\x121\n" +
+	"\x05group\x18\x01 \x01(\v2\x1b.mandau.agent.v1.AgentGroupR\x05group\x120\n" +
+	"\amembers\x18\x02 \x03(\v2\x16.mandau.agent.v1.AgentR\amembers\"\x18\n" +
+	"\x16ListAgentGroupsRequest\"N\n" +
+	"\x17ListAgentGroupsResponse\x123\n" +
+	"\x06groups\x18\x01 \x03(\v2\x1b.mandau.agent.v1.AgentGroupR\x06groups\"\xe4\x02\n" +
+	"\x17UpdateAgentGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
+	"\vdescription\x18\x02 \x01(\tH\x00R\vdescription\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"add_agents\x18\x03 \x03(\tR\taddAgents\x12#\n" +
+	"\rremove_agents\x18\x04 \x03(\tR\fremoveAgents\x12R\n" +
+	"\bselector\x18\x05 \x03(\v26.mandau.agent.v1.UpdateAgentGroupRequest.SelectorEntryR\bselector\x12)\n" +
+	"\x10replace_selector\x18\x06 \x01(\bR\x0freplaceSelector\x1a;\n" +
+	"\rSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_description\"-\n" +
+	"\x17DeleteAgentGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x1a\n" +
+	"\x18DeleteAgentGroupResponse\"\xf6\x03\n" +
+	"\bApproval\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\frequested_by\x18\x02 \x01(\tR\vrequestedBy\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x124\n" +
+	"\x05state\x18\x06 \x01(\x0e2\x1e.mandau.agent.v1.ApprovalStateR\x05state\x12\x1f\n" +
+	"\vreviewed_by\x18\a \x01(\tR\n" +
+	"reviewedBy\x12\x18\n" +
+	"\acomment\x18\b \x01(\tR\acomment\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vreviewed_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"reviewedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x16\n" +
+	"\x06digest\x18\f \x01(\tR\x06digest\x12\x14\n" +
+	"\x05group\x18\r \x01(\tR\x05group\x12\x16\n" +
+	"\x06agents\x18\x0e \x03(\tR\x06agents\"L\n" +
+	"\x14ListApprovalsRequest\x124\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x1e.mandau.agent.v1.ApprovalStateR\x05state\"P\n" +
+	"\x15ListApprovalsResponse\x127\n" +
+	"\tapprovals\x18\x01 \x03(\v2\x19.mandau.agent.v1.ApprovalR\tapprovals\"[\n" +
+	"\x15ReviewApprovalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x12\x18\n" +
+	"\acomment\x18\x03 \x01(\tR\acomment\"\xef\x02\n" +
+	"\x0fBreakGlassGrant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05roles\x18\x03 \x03(\tR\x05roles\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"granted_by\x18\x05 \x01(\tR\tgrantedBy\x129\n" +
+	"\n" +
+	"granted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tgrantedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1d\n" +
+	"\n" +
+	"revoked_by\x18\b \x01(\tR\trevokedBy\x129\n" +
+	"\n" +
+	"revoked_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\x12\x16\n" +
+	"\x06active\x18\n" +
+	" \x01(\bR\x06active\"\x8c\x01\n" +
+	"\x16GrantBreakGlassRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05roles\x18\x02 \x03(\tR\x05roles\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\")\n" +
+	"\x17RevokeBreakGlassRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\">\n" +
+	"\x1bListBreakGlassGrantsRequest\x12\x1f\n" +
+	"\vactive_only\x18\x01 \x01(\bR\n" +
+	"activeOnly\"X\n" +
+	"\x1cListBreakGlassGrantsResponse\x128\n" +
+	"\x06grants\x18\x01 \x03(\v2 .mandau.agent.v1.BreakGlassGrantR\x06grants\"B\n" +
+	"\x10SetFreezeRequest\x12\x16\n" +
+	"\x06frozen\x18\x01 \x01(\bR\x06frozen\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x12\n" +
+	"\x10GetFreezeRequest\"\x19\n" +
+	"\x17GetClusterStatusRequest\"\xc7\x05\n" +
+	"\rClusterStatus\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x129\n" +
+	"\n" +
+	"started_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12B\n" +
+	"\x06agents\x18\x03 \x03(\v2*.mandau.agent.v1.ClusterStatus.AgentsEntryR\x06agents\x12 \n" +
+	"\vmaintenance\x18\x04 \x03(\tR\vmaintenance\x124\n" +
+	"\x06freeze\x18\x05 \x01(\v2\x1c.mandau.agent.v1.FreezeStateR\x06freeze\x12;\n" +
+	"\arunning\x18\x06 \x03(\v2!.mandau.agent.v1.ClusterOperationR\arunning\x12=\n" +
+	"\bfailures\x18\a \x03(\v2!.mandau.agent.v1.ClusterOperationR\bfailures\x12/\n" +
+	"\x13queued_instructions\x18\b \x01(\x05R\x12queuedInstructions\x12Y\n" +
+	"\x15expiring_certificates\x18\t \x03(\v2$.mandau.agent.v1.ExpiringCertificateR\x14expiringCertificates\x12>\n" +
+	"\n" +
+	"clock_skew\x18\n" +
+	" \x03(\v2\x1f.mandau.agent.v1.AgentClockSkewR\tclockSkew\x12B\n" +
+	"\ropen_circuits\x18\v \x03(\v2\x1d.mandau.agent.v1.AgentCircuitR\fopenCircuits\x1a9\n" +
+	"\vAgentsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"Z\n" +
+	"\x0eAgentClockSkew\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12-\n" +
+	"\x04skew\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x04skew\"\xa7\x02\n" +
+	"\x10ClusterOperation\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05stack\x18\x03 \x01(\tR\x05stack\x12!\n" +
+	"\frequested_by\x18\x04 \x01(\tR\vrequestedBy\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\b \x01(\tR\trequestId\"|\n" +
+	"\x13ExpiringCertificate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x127\n" +
+	"\tnot_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bnotAfter\"\x87\x01\n" +
+	"\vFreezeState\x12\x16\n" +
+	"\x06frozen\x18\x01 \x01(\bR\x06frozen\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x15\n" +
+	"\x06set_by\x18\x03 \x01(\tR\x05setBy\x121\n" +
+	"\x06set_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05setAt\"O\n" +
+	"\x14GetQuotaUsageRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"\x8c\x01\n" +
+	"\n" +
+	"QuotaUsage\x128\n" +
+	"\x06agents\x18\x01 \x03(\v2 .mandau.agent.v1.AgentQuotaUsageR\x06agents\x12D\n" +
+	"\n" +
+	"namespaces\x18\x02 \x03(\v2$.mandau.agent.v1.NamespaceQuotaUsageR\n" +
+	"namespaces\"\xaa\x01\n" +
+	"\x0fAgentQuotaUsage\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06stacks\x18\x02 \x01(\x05R\x06stacks\x12\x1d\n" +
+	"\n" +
+	"max_stacks\x18\x03 \x01(\x05R\tmaxStacks\x12\x1e\n" +
+	"\n" +
+	"containers\x18\x04 \x01(\x05R\n" +
+	"containers\x12%\n" +
+	"\x0emax_containers\x18\x05 \x01(\x05R\rmaxContainers\",\n" +
+	"\x0fDiagnoseRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"|\n" +
+	"\x10DiagnoseResponse\x128\n" +
+	"\x06checks\x18\x01 \x03(\v2 .mandau.agent.v1.DiagnosticCheckR\x06checks\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\xad\x01\n" +
+	"\x0fDiagnosticCheck\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x124\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x1c.mandau.agent.v1.CheckStatusR\x06status\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12 \n" +
+	"\vremediation\x18\x05 \x01(\tR\vremediation\"G\n" +
+	"\x14DiagnoseAgentRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05pings\x18\x02 \x01(\x05R\x05pings\"\xc7\x04\n" +
+	"\x15AgentConnectionReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12A\n" +
+	"\x0elast_heartbeat\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rlastHeartbeat\x12\"\n" +
+	"\rdial_out_only\x18\x05 \x01(\bR\vdialOutOnly\x12#\n" +
+	"\rchannel_state\x18\x06 \x01(\tR\fchannelState\x12<\n" +
+	"\vround_trips\x18\a \x01(\v2\x1b.mandau.agent.v1.RoundTripsR\n" +
+	"roundTrips\x12O\n" +
+	"\x12server_certificate\x18\b \x01(\v2 .mandau.agent.v1.PeerCertificateR\x11serverCertificate\x12O\n" +
+	"\x12client_certificate\x18\t \x01(\v2 .mandau.agent.v1.PeerCertificateR\x11clientCertificate\x12;\n" +
+	"\areverse\x18\n" +
+	" \x01(\v2!.mandau.agent.v1.CoreConnectivityR\areverse\x128\n" +
+	"\x06checks\x18\v \x03(\v2 .mandau.agent.v1.DiagnosticCheckR\x06checks\"\xd5\x01\n" +
+	"\n" +
+	"RoundTrips\x12\x12\n" +
+	"\x04sent\x18\x01 \x01(\x05R\x04sent\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12+\n" +
+	"\x03min\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03min\x12+\n" +
+	"\x03avg\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03avg\x12+\n" +
+	"\x03max\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x03max\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xee\x01\n" +
+	"\x0fPeerCertificate\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x12\n" +
+	"\x04sans\x18\x03 \x03(\tR\x04sans\x127\n" +
+	"\tnot_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bnotAfter\x12#\n" +
+	"\rexpected_name\x18\x05 \x01(\tR\fexpectedName\x12!\n" +
+	"\fname_matches\x18\x06 \x01(\bR\vnameMatches\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\x1c\n" +
+	"\x1aCheckCoreConnectionRequest\"\xf6\x01\n" +
+	"\x10CoreConnectivity\x12!\n" +
+	"\fcore_address\x18\x01 \x01(\tR\vcoreAddress\x12#\n" +
+	"\rchannel_state\x18\x02 \x01(\tR\fchannelState\x127\n" +
+	"\thandshake\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\thandshake\x12K\n" +
+	"\x10core_certificate\x18\x04 \x01(\v2 .mandau.agent.v1.PeerCertificateR\x0fcoreCertificate\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"4\n" +
+	"\x18GetResourceReportRequest\x12\x18\n" +
+	"\arefresh\x18\x01 \x01(\bR\arefresh\"\x99\x02\n" +
+	"\x0eResourceReport\x12=\n" +
+	"\fgenerated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vgeneratedAt\x123\n" +
+	"\x06stacks\x18\x02 \x03(\v2\x1b.mandau.agent.v1.StackUsageR\x06stacks\x12S\n" +
+	"\fagent_errors\x18\x03 \x03(\v20.mandau.agent.v1.ResourceReport.AgentErrorsEntryR\vagentErrors\x1a>\n" +
+	"\x10AgentErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc3\x03\n" +
+	"\n" +
+	"StackUsage\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x14\n" +
+	"\x05stack\x18\x03 \x01(\tR\x05stack\x121\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x1b.mandau.agent.v1.StackStateR\x05state\x121\n" +
+	"\x05owner\x18\x05 \x01(\v2\x1b.mandau.agent.v1.StackOwnerR\x05owner\x12?\n" +
+	"\x06labels\x18\x06 \x03(\v2'.mandau.agent.v1.StackUsage.LabelsEntryR\x06labels\x12\x1e\n" +
+	"\n" +
+	"containers\x18\a \x01(\x05R\n" +
+	"containers\x12-\n" +
+	"\x12running_containers\x18\b \x01(\x05R\x11runningContainers\x12\x12\n" +
+	"\x04cpus\x18\t \x01(\x01R\x04cpus\x12!\n" +
+	"\fmemory_bytes\x18\n" +
+	" \x01(\x03R\vmemoryBytes\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"1\n" +
+	"\x19GetPatchComplianceRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\"\xa1\x02\n" +
+	"\x0fPatchCompliance\x12=\n" +
+	"\fgenerated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vgeneratedAt\x129\n" +
+	"\x06agents\x18\x02 \x03(\v2!.mandau.agent.v1.AgentPatchStatusR\x06agents\x12T\n" +
+	"\fagent_errors\x18\x03 \x03(\v21.mandau.agent.v1.PatchCompliance.AgentErrorsEntryR\vagentErrors\x1a>\n" +
+	"\x10AgentErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd6\x02\n" +
+	"\x16RunFleetCommandRequest\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12K\n" +
+	"\x06labels\x18\x02 \x03(\v23.mandau.agent.v1.RunFleetCommandRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12\x1b\n" +
+	"\tagent_ids\x18\x04 \x03(\tR\bagentIds\x12\x10\n" +
+	"\x03all\x18\x05 \x01(\bR\x03all\x12 \n" +
+	"\vparallelism\x18\x06 \x01(\x05R\vparallelism\x123\n" +
+	"\atimeout\x18\a \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf3\x02\n" +
+	"\x12FleetCommandReport\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x129\n" +
+	"\n" +
+	"started_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\x128\n" +
+	"\aresults\x18\x04 \x03(\v2\x1e.mandau.agent.v1.CommandResultR\aresults\x12W\n" +
+	"\fagent_errors\x18\x05 \x03(\v24.mandau.agent.v1.FleetCommandReport.AgentErrorsEntryR\vagentErrors\x1a>\n" +
+	"\x10AgentErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe2\x01\n" +
+	"\rCommandResult\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1b\n" +
+	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\x03 \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x04 \x01(\fR\x06stderr\x12\x1c\n" +
+	"\ttruncated\x18\x05 \x01(\bR\ttruncated\x125\n" +
+	"\bduration\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xb0\x01\n" +
+	"\x13GetInventoryRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12H\n" +
+	"\x06labels\x18\x02 \x03(\v20.mandau.agent.v1.GetInventoryRequest.LabelsEntryR\x06labels\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x02\n" +
+	"\tInventory\x12=\n" +
+	"\fgenerated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vgeneratedAt\x127\n" +
+	"\x06agents\x18\x02 \x03(\v2\x1f.mandau.agent.v1.AgentInventoryR\x06agents\x12N\n" +
+	"\fagent_errors\x18\x03 \x03(\v2+.mandau.agent.v1.Inventory.AgentErrorsEntryR\vagentErrors\x1a>\n" +
+	"\x10AgentErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd6\x03\n" +
+	"\x0eAgentInventory\x12,\n" +
+	"\x05agent\x18\x01 \x01(\v2\x16.mandau.agent.v1.AgentR\x05agent\x122\n" +
+	"\x04host\x18\x02 \x01(\v2\x1e.mandau.agent.v1.InventoryHostR\x04host\x12.\n" +
+	"\x06stacks\x18\x03 \x03(\v2\x16.mandau.agent.v1.StackR\x06stacks\x12J\n" +
+	"\rvirtual_hosts\x18\x04 \x03(\v2%.mandau.agent.v1.InventoryVirtualHostR\fvirtualHosts\x12\x1b\n" +
+	"\tdns_zones\x18\x05 \x03(\tR\bdnsZones\x12I\n" +
+	"\fcertificates\x18\x06 \x03(\v2%.mandau.agent.v1.InventoryCertificateR\fcertificates\x12C\n" +
+	"\x06errors\x18\a \x03(\v2+.mandau.agent.v1.AgentInventory.ErrorsEntryR\x06errors\x1a9\n" +
+	"\vErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xca\x01\n" +
+	"\rInventoryHost\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x16\n" +
+	"\x06kernel\x18\x03 \x01(\tR\x06kernel\x12\"\n" +
+	"\farchitecture\x18\x04 \x01(\tR\farchitecture\x12\x1b\n" +
+	"\tcpu_cores\x18\x05 \x01(\x05R\bcpuCores\x12\x1b\n" +
+	"\tmemory_mb\x18\x06 \x01(\x03R\bmemoryMb\x12\x17\n" +
+	"\adisk_gb\x18\a \x01(\x03R\x06diskGb\"D\n" +
+	"\x14InventoryVirtualHost\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"e\n" +
+	"\x14InventoryCertificate\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\"c\n" +
+	"\x10AgentPatchStatus\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x124\n" +
+	"\x06status\x18\x02 \x01(\v2\x1c.mandau.agent.v1.PatchStatusR\x06status\"\xfa\x02\n" +
+	"\vPatchStatus\x12'\n" +
+	"\x0fpackage_manager\x18\x01 \x01(\tR\x0epackageManager\x12I\n" +
+	"\x10security_updates\x18\x02 \x03(\v2\x1e.mandau.agent.v1.PackageUpdateR\x0fsecurityUpdates\x12\x18\n" +
+	"\aupdates\x18\x03 \x01(\x05R\aupdates\x12'\n" +
+	"\x0freboot_required\x18\x04 \x01(\bR\x0erebootRequired\x12'\n" +
+	"\x0freboot_packages\x18\x05 \x03(\tR\x0erebootPackages\x12%\n" +
+	"\x0erunning_kernel\x18\x06 \x01(\tR\rrunningKernel\x12)\n" +
+	"\x10installed_kernel\x18\a \x01(\tR\x0finstalledKernel\x129\n" +
+	"\n" +
+	"checked_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"y\n" +
+	"\rPackageUpdate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12'\n" +
+	"\x0fcurrent_version\x18\x02 \x01(\tR\x0ecurrentVersion\x12+\n" +
+	"\x11available_version\x18\x03 \x01(\tR\x10availableVersion\"\xe6\x01\n" +
+	"\x13NamespaceQuotaUsage\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x16\n" +
+	"\x06stacks\x18\x02 \x01(\x05R\x06stacks\x12\x1d\n" +
+	"\n" +
+	"max_stacks\x18\x03 \x01(\x05R\tmaxStacks\x12\x12\n" +
+	"\x04cpus\x18\x04 \x01(\x01R\x04cpus\x12\x19\n" +
+	"\bmax_cpus\x18\x05 \x01(\x01R\amaxCpus\x12!\n" +
+	"\fmemory_bytes\x18\x06 \x01(\x03R\vmemoryBytes\x12(\n" +
+	"\x10max_memory_bytes\x18\a \x01(\x03R\x0emaxMemoryBytes\"\xbd\x03\n" +
 	"\x0fRegisterRequest\x12\x1a\n" +
 	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x19\n" +
 	"\bagent_id\x18\x05 \x01(\tR\aagentId\x12D\n" +
 	"\x06labels\x18\x03 \x03(\v2,.mandau.agent.v1.RegisterRequest.LabelsEntryR\x06labels\x12\"\n" +
-	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\x1a9\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\x12\"\n" +
+	"\rdial_out_only\x18\x06 \x01(\bR\vdialOutOnly\x12:\n" +
+	"\aplugins\x18\a \x03(\v2 .mandau.agent.v1.InstalledPluginR\aplugins\x12T\n" +
+	"\x13plugin_availability\x18\b \x03(\v2#.mandau.agent.v1.PluginAvailabilityR\x12pluginAvailability\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x82\x01\n" +
+	"\x12PluginAvailability\x12\x16\n" +
+	"\x06plugin\x18\x01 \x01(\tR\x06plugin\x12\x1e\n" +
+	"\n" +
+	"capability\x18\x02 \x01(\tR\n" +
+	"capability\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\bR\tavailable\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\x99\x01\n" +
 	"\x10RegisterResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12 \n" +
 	"\vcertificate\x18\x02 \x01(\fR\vcertificate\x12H\n" +
-	"\x12heartbeat_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\"\x9b\x03\n" +
+	"\x12heartbeat_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\"\x17\n" +
+	"\x15GetPluginIndexRequest\"\x98\x01\n" +
+	"\vPluginIndex\x128\n" +
+	"\aplugins\x18\x01 \x03(\v2\x1e.mandau.agent.v1.IndexedPluginR\aplugins\x12\x15\n" +
+	"\x06key_id\x18\x02 \x01(\tR\x05keyId\x128\n" +
+	"\tpublished\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tpublished\"\x7f\n" +
+	"\rIndexedPlugin\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bversions\x18\x03 \x03(\tR\bversions\x12\x1c\n" +
+	"\tplatforms\x18\x04 \x03(\tR\tplatforms\"\x82\x01\n" +
+	"\x14InstallPluginRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12!\n" +
+	"\fsigned_index\x18\x04 \x01(\fR\vsignedIndex\"\xf7\x01\n" +
+	"\x0fInstalledPlugin\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x12\x18\n" +
+	"\aenabled\x18\x05 \x01(\bR\aenabled\x12\x16\n" +
+	"\x06loaded\x18\x06 \x01(\bR\x06loaded\x12=\n" +
+	"\finstalled_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vinstalledAt\x12\x19\n" +
+	"\bagent_id\x18\b \x01(\tR\aagentId\"8\n" +
+	"\x1bListInstalledPluginsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"Z\n" +
+	"\x1cListInstalledPluginsResponse\x12:\n" +
+	"\aplugins\x18\x01 \x03(\v2 .mandau.agent.v1.InstalledPluginR\aplugins\"F\n" +
+	"\x15DescribePluginRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"W\n" +
+	"\x11PluginPermissions\x12\x12\n" +
+	"\x04exec\x18\x01 \x03(\tR\x04exec\x12\x14\n" +
+	"\x05write\x18\x02 \x03(\tR\x05write\x12\x18\n" +
+	"\anetwork\x18\x03 \x03(\tR\anetwork\"\xfc\x01\n" +
+	"\x11PluginDescription\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\"\n" +
+	"\fcapabilities\x18\x03 \x03(\tR\fcapabilities\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x1c\n" +
+	"\tsandboxed\x18\x05 \x01(\bR\tsandboxed\x12D\n" +
+	"\vpermissions\x18\x06 \x01(\v2\".mandau.agent.v1.PluginPermissionsR\vpermissions\x12\x19\n" +
+	"\bagent_id\x18\a \x01(\tR\aagentId\"\x86\x05\n" +
 	"\x05Stack\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -3725,10 +14050,36 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12:\n" +
-	"\x06labels\x18\b \x03(\v2\".mandau.agent.v1.Stack.LabelsEntryR\x06labels\x1a9\n" +
+	"\x06labels\x18\b \x03(\v2\".mandau.agent.v1.Stack.LabelsEntryR\x06labels\x121\n" +
+	"\x05owner\x18\t \x01(\v2\x1b.mandau.agent.v1.StackOwnerR\x05owner\x12\x1c\n" +
+	"\tnamespace\x18\n" +
+	" \x01(\tR\tnamespace\x12=\n" +
+	"\tresources\x18\v \x01(\v2\x1f.mandau.agent.v1.StackResourcesR\tresources\x12\x19\n" +
+	"\bagent_id\x18\f \x01(\tR\aagentId\x12>\n" +
+	"\n" +
+	"disk_usage\x18\r \x01(\v2\x1f.mandau.agent.v1.StackDiskUsageR\tdiskUsage\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe2\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc7\x01\n" +
+	"\x0eStackDiskUsage\x12'\n" +
+	"\x0fdirectory_bytes\x18\x01 \x01(\x03R\x0edirectoryBytes\x12!\n" +
+	"\fvolume_bytes\x18\x02 \x01(\x03R\vvolumeBytes\x12'\n" +
+	"\x0fcontainer_bytes\x18\x03 \x01(\x03R\x0econtainerBytes\x12\x1f\n" +
+	"\vimage_bytes\x18\x04 \x01(\x03R\n" +
+	"imageBytes\x12\x1f\n" +
+	"\vlimit_bytes\x18\x05 \x01(\x03R\n" +
+	"limitBytes\"g\n" +
+	"\x0eStackResources\x12\x1e\n" +
+	"\n" +
+	"containers\x18\x01 \x01(\x05R\n" +
+	"containers\x12\x12\n" +
+	"\x04cpus\x18\x02 \x01(\x01R\x04cpus\x12!\n" +
+	"\fmemory_bytes\x18\x03 \x01(\x03R\vmemoryBytes\"N\n" +
+	"\n" +
+	"StackOwner\x12\x12\n" +
+	"\x04team\x18\x01 \x01(\tR\x04team\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x16\n" +
+	"\x06ticket\x18\x03 \x01(\tR\x06ticket\"\xd2\b\n" +
 	"\x11ApplyStackRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
@@ -3738,22 +14089,154 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\x0eforce_recreate\x18\x05 \x01(\bR\rforceRecreate\x12\x1a\n" +
 	"\bservices\x18\x06 \x03(\tR\bservices\x12\x1f\n" +
 	"\vpull_images\x18\a \x01(\bR\n" +
-	"pullImages\x1a:\n" +
+	"pullImages\x12\x1c\n" +
+	"\temergency\x18\b \x01(\bR\temergency\x12\x1f\n" +
+	"\vapproval_id\x18\t \x01(\tR\n" +
+	"approvalId\x12F\n" +
+	"\x06labels\x18\n" +
+	" \x03(\v2..mandau.agent.v1.ApplyStackRequest.LabelsEntryR\x06labels\x121\n" +
+	"\x05owner\x18\v \x01(\v2\x1b.mandau.agent.v1.StackOwnerR\x05owner\x12\x1c\n" +
+	"\tnamespace\x18\f \x01(\tR\tnamespace\x12\x14\n" +
+	"\x05group\x18\r \x01(\tR\x05group\x12\x14\n" +
+	"\x05queue\x18\x0e \x01(\bR\x05queue\x126\n" +
+	"\tqueue_ttl\x18\x0f \x01(\v2\x19.google.protobuf.DurationR\bqueueTtl\x12'\n" +
+	"\x0fidempotency_key\x18\x10 \x01(\tR\x0eidempotencyKey\x12\x17\n" +
+	"\ano_wait\x18\x11 \x01(\bR\x06noWait\x12>\n" +
+	"\rready_timeout\x18\x12 \x01(\v2\x19.google.protobuf.DurationR\freadyTimeout\x121\n" +
+	"\x05hooks\x18\x13 \x01(\v2\x1b.mandau.agent.v1.StackHooksR\x05hooks\x121\n" +
+	"\x05files\x18\x14 \x01(\v2\x1b.mandau.agent.v1.StackFilesR\x05files\x12J\n" +
+	"\x11compose_overrides\x18\x15 \x03(\v2\x1d.mandau.agent.v1.StackContentR\x10composeOverrides\x12C\n" +
+	"\rproject_files\x18\x16 \x01(\v2\x1e.mandau.agent.v1.StackContentsR\fprojectFiles\x1a:\n" +
 	"\fEnvVarsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"a\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"P\n" +
+	"\fStackContent\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\rR\x04mode\"D\n" +
+	"\rStackContents\x123\n" +
+	"\x05files\x18\x01 \x03(\v2\x1d.mandau.agent.v1.StackContentR\x05files\"_\n" +
+	"\tStackFile\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\rR\x04mode\">\n" +
+	"\n" +
+	"StackFiles\x120\n" +
+	"\x05files\x18\x01 \x03(\v2\x1a.mandau.agent.v1.StackFileR\x05files\"\xa1\x01\n" +
+	"\x15DiffStackFilesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x120\n" +
+	"\x05files\x18\x04 \x03(\v2\x1a.mandau.agent.v1.StackFileR\x05files\"j\n" +
+	"\x16DiffStackFilesResponse\x12\x18\n" +
+	"\achanged\x18\x01 \x03(\tR\achanged\x12\x18\n" +
+	"\aremoved\x18\x02 \x03(\tR\aremoved\x12\x1c\n" +
+	"\tunchanged\x18\x03 \x01(\x05R\tunchanged\"\xae\x01\n" +
+	"\x0eStackFileChunk\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x1c\n" +
+	"\temergency\x18\x04 \x01(\bR\temergency\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x06 \x01(\fR\x04data\"F\n" +
+	"\x18UploadStackFilesResponse\x12\x14\n" +
+	"\x05files\x18\x01 \x01(\x05R\x05files\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\"H\n" +
+	"\n" +
+	"StackHooks\x12\x1b\n" +
+	"\tpre_apply\x18\x01 \x01(\tR\bpreApply\x12\x1d\n" +
+	"\n" +
+	"post_apply\x18\x02 \x01(\tR\tpostApply\"\x90\x02\n" +
 	"\x10DiffStackRequest\x12\x1d\n" +
 	"\n" +
 	"stack_name\x18\x01 \x01(\tR\tstackName\x12.\n" +
-	"\x13new_compose_content\x18\x02 \x01(\tR\x11newComposeContent\"n\n" +
+	"\x13new_compose_content\x18\x02 \x01(\tR\x11newComposeContent\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12J\n" +
+	"\x11compose_overrides\x18\x04 \x03(\v2\x1d.mandau.agent.v1.StackContentR\x10composeOverrides\x12C\n" +
+	"\rproject_files\x18\x05 \x01(\v2\x1e.mandau.agent.v1.StackContentsR\fprojectFiles\"\xa4\x01\n" +
 	"\x11DiffStackResponse\x128\n" +
 	"\bservices\x18\x01 \x03(\v2\x1c.mandau.agent.v1.ServiceDiffR\bservices\x12\x1f\n" +
 	"\vhas_changes\x18\x02 \x01(\bR\n" +
-	"hasChanges\"p\n" +
+	"hasChanges\x124\n" +
+	"\x05files\x18\x03 \x03(\v2\x1e.mandau.agent.v1.StackFileDiffR\x05files\"X\n" +
+	"\rStackFileDiff\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x123\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x1b.mandau.agent.v1.DiffActionR\x06action\"\x93\x01\n" +
+	"\x12ExportStackRequest\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x01 \x01(\tR\tstackName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12%\n" +
+	"\x0ereveal_secrets\x18\x04 \x01(\bR\rrevealSecrets\"\x8d\x06\n" +
+	"\vStackExport\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12'\n" +
+	"\x0fcompose_content\x18\x04 \x01(\tR\x0ecomposeContent\x12D\n" +
+	"\benv_vars\x18\x05 \x03(\v2).mandau.agent.v1.StackExport.EnvVarsEntryR\aenvVars\x12@\n" +
+	"\x06labels\x18\x06 \x03(\v2(.mandau.agent.v1.StackExport.LabelsEntryR\x06labels\x121\n" +
+	"\x05owner\x18\a \x01(\v2\x1b.mandau.agent.v1.StackOwnerR\x05owner\x121\n" +
+	"\x05state\x18\b \x01(\x0e2\x1b.mandau.agent.v1.StackStateR\x05state\x12:\n" +
+	"\n" +
+	"containers\x18\t \x03(\v2\x1a.mandau.agent.v1.ContainerR\n" +
+	"containers\x12;\n" +
+	"\vexported_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"exportedAt\x12\x1a\n" +
+	"\bredacted\x18\v \x01(\bR\bredacted\x12J\n" +
+	"\x11compose_overrides\x18\f \x03(\v2\x1d.mandau.agent.v1.StackContentR\x10composeOverrides\x12B\n" +
+	"\rproject_files\x18\r \x03(\v2\x1d.mandau.agent.v1.StackContentR\fprojectFiles\x1a:\n" +
+	"\fEnvVarsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"M\n" +
+	"\x1aCollectStackGarbageRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05apply\x18\x02 \x01(\bR\x05apply\"o\n" +
+	"\x1bCollectStackGarbageResponse\x126\n" +
+	"\aorphans\x18\x01 \x03(\v2\x1c.mandau.agent.v1.StackOrphanR\aorphans\x12\x18\n" +
+	"\aapplied\x18\x02 \x01(\bR\aapplied\"\xcd\x01\n" +
+	"\x15GetStackEventsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x120\n" +
+	"\x05since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x14\n" +
+	"\x05kinds\x18\x05 \x03(\tR\x05kinds\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"h\n" +
+	"\x16GetStackEventsResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x123\n" +
+	"\x06events\x18\x02 \x03(\v2\x1b.mandau.agent.v1.StackEventR\x06events\"\xd9\x01\n" +
+	"\n" +
+	"StackEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x18\n" +
+	"\aservice\x18\x03 \x01(\tR\aservice\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\tR\asummary\x12\x18\n" +
+	"\adetails\x18\x05 \x03(\tR\adetails\x12\x16\n" +
+	"\x06failed\x18\x06 \x01(\bR\x06failed\x12!\n" +
+	"\foperation_id\x18\a \x01(\tR\voperationId\"\xde\x01\n" +
+	"\vStackOrphan\x12/\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1b.mandau.agent.v1.OrphanKindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12#\n" +
+	"\rcontainer_ids\x18\x04 \x03(\tR\fcontainerIds\x12\x16\n" +
+	"\x06detail\x18\x05 \x01(\tR\x06detail\x12\x18\n" +
+	"\aremoved\x18\x06 \x01(\bR\aremoved\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"p\n" +
 	"\vServiceDiff\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x123\n" +
 	"\x06action\x18\x02 \x01(\x0e2\x1b.mandau.agent.v1.DiffActionR\x06action\x12\x18\n" +
-	"\achanges\x18\x03 \x03(\tR\achanges\"\xd1\x02\n" +
+	"\achanges\x18\x03 \x03(\tR\achanges\"\x91\x03\n" +
 	"\tContainer\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -3762,7 +14245,10 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\x06status\x18\x05 \x01(\tR\x06status\x124\n" +
 	"\acreated\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x12>\n" +
 	"\x06labels\x18\a \x03(\v2&.mandau.agent.v1.Container.LabelsEntryR\x06labels\x12+\n" +
-	"\x05ports\x18\b \x03(\v2\x15.mandau.agent.v1.PortR\x05ports\x1a9\n" +
+	"\x05ports\x18\b \x03(\v2\x15.mandau.agent.v1.PortR\x05ports\x12\x19\n" +
+	"\bimage_id\x18\t \x01(\tR\aimageId\x12#\n" +
+	"\rimage_digests\x18\n" +
+	" \x03(\tR\fimageDigests\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
@@ -3836,7 +14322,152 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"stack_name\x18\x01 \x01(\tR\tstackName\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\fR\acontent\x12\x12\n" +
-	"\x04mode\x18\x04 \x01(\rR\x04mode\"\x95\x03\n" +
+	"\x04mode\x18\x04 \x01(\rR\x04mode\"\x94\x01\n" +
+	"\x0eTransferTarget\x121\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1d.mandau.agent.v1.TransferKindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\"\xfc\x01\n" +
+	"\rUploadRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vtransfer_id\x18\x02 \x01(\tR\n" +
+	"transferId\x127\n" +
+	"\x06target\x18\x03 \x01(\v2\x1f.mandau.agent.v1.TransferTargetR\x06target\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x03R\x06offset\x12\x1c\n" +
+	"\temergency\x18\a \x01(\bR\temergency\x12\x14\n" +
+	"\x05chunk\x18\b \x01(\fR\x05chunk\"\x8b\x02\n" +
+	"\x0eTransferStatus\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x127\n" +
+	"\x06target\x18\x02 \x01(\v2\x1f.mandau.agent.v1.TransferTargetR\x06target\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1a\n" +
+	"\breceived\x18\x04 \x01(\x03R\breceived\x12\x1c\n" +
+	"\tdelivered\x18\x05 \x01(\bR\tdelivered\x12\x16\n" +
+	"\x06images\x18\x06 \x03(\tR\x06images\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x89\x01\n" +
+	"\x12GetTransferRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vtransfer_id\x18\x02 \x01(\tR\n" +
+	"transferId\x127\n" +
+	"\x06target\x18\x03 \x01(\v2\x1f.mandau.agent.v1.TransferTargetR\x06target\"/\n" +
+	"\x12ListBackupsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"J\n" +
+	"\x13ListBackupsResponse\x123\n" +
+	"\abackups\x18\x01 \x03(\v2\x19.mandau.agent.v1.FileInfoR\abackups\"^\n" +
+	"\x15DownloadBackupRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"O\n" +
+	"\rDownloadChunk\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"\xac\x01\n" +
+	"\fFetchRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x127\n" +
+	"\x06target\x18\x02 \x01(\v2\x1f.mandau.agent.v1.TransferTargetR\x06target\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1c\n" +
+	"\temergency\x18\x05 \x01(\bR\temergency\"\x9a\x02\n" +
+	"\bArtifact\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05names\x18\x03 \x03(\tR\x05names\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06pinned\x18\x05 \x01(\bR\x06pinned\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x123\n" +
+	"\aused_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x06usedAt\x12\x16\n" +
+	"\x06pushes\x18\b \x01(\x03R\x06pushes\x12\x18\n" +
+	"\afetches\x18\t \x01(\x03R\afetches\"\x90\x01\n" +
+	"\x12PutArtifactRequest\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x10\n" +
+	"\x03pin\x18\x05 \x01(\bR\x03pin\x12\x14\n" +
+	"\x05chunk\x18\x06 \x01(\fR\x05chunk\",\n" +
+	"\x12GetArtifactRequest\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\"*\n" +
+	"\x14ListArtifactsRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\"P\n" +
+	"\x15ListArtifactsResponse\x127\n" +
+	"\tartifacts\x18\x01 \x03(\v2\x19.mandau.agent.v1.ArtifactR\tartifacts\"/\n" +
+	"\x15DeleteArtifactRequest\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\"8\n" +
+	"\x1dCollectArtifactGarbageRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"k\n" +
+	"\x1eCollectArtifactGarbageResponse\x123\n" +
+	"\aremoved\x18\x01 \x03(\v2\x19.mandau.agent.v1.ArtifactR\aremoved\x12\x14\n" +
+	"\x05freed\x18\x02 \x01(\x03R\x05freed\"a\n" +
+	"\x14FetchArtifactRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06digest\x18\x02 \x01(\tR\x06digest\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"O\n" +
+	"\rArtifactChunk\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"t\n" +
+	"\x11GetProfileRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x14\n" +
+	"\x05debug\x18\x03 \x01(\x05R\x05debug\"\"\n" +
+	"\fProfileChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x18\n" +
+	"\x16ListConnectionsRequest\"Z\n" +
+	"\x17ListConnectionsResponse\x12?\n" +
+	"\vconnections\x18\x01 \x03(\v2\x1d.mandau.agent.v1.AgentChannelR\vconnections\"\xce\x03\n" +
+	"\fAgentChannel\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12#\n" +
+	"\rchannel_state\x18\x04 \x01(\tR\fchannelState\x12\"\n" +
+	"\rdial_out_only\x18\x05 \x01(\bR\vdialOutOnly\x127\n" +
+	"\tlast_seen\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x127\n" +
+	"\acircuit\x18\a \x01(\v2\x1d.mandau.agent.v1.AgentCircuitR\acircuit\x12/\n" +
+	"\x13certificate_subject\x18\b \x01(\tR\x12certificateSubject\x12K\n" +
+	"\x13certificate_expires\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x12certificateExpires\x128\n" +
+	"\n" +
+	"clock_skew\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\tclockSkew\"\x14\n" +
+	"\x12GetRegistryRequest\"}\n" +
+	"\fCoreRegistry\x126\n" +
+	"\x06agents\x18\x01 \x03(\v2\x1e.mandau.agent.v1.RegistryAgentR\x06agents\x125\n" +
+	"\aplugins\x18\x02 \x03(\v2\x1b.mandau.agent.v1.CorePluginR\aplugins\"\xc1\x05\n" +
+	"\rRegistryAgent\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12B\n" +
+	"\x06labels\x18\x04 \x03(\v2*.mandau.agent.v1.RegistryAgent.LabelsEntryR\x06labels\x12[\n" +
+	"\x0freported_labels\x18\x05 \x03(\v22.mandau.agent.v1.RegistryAgent.ReportedLabelsEntryR\x0ereportedLabels\x12[\n" +
+	"\x0foperator_labels\x18\x06 \x03(\v22.mandau.agent.v1.RegistryAgent.OperatorLabelsEntryR\x0eoperatorLabels\x12%\n" +
+	"\x0eremoved_labels\x18\a \x03(\tR\rremovedLabels\x12\"\n" +
+	"\fcapabilities\x18\b \x03(\tR\fcapabilities\x12\x16\n" +
+	"\x06stacks\x18\t \x03(\tR\x06stacks\x12\x18\n" +
+	"\aplugins\x18\n" +
+	" \x03(\tR\aplugins\x12%\n" +
+	"\x0ein_maintenance\x18\v \x01(\bR\rinMaintenance\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aA\n" +
+	"\x13ReportedLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aA\n" +
+	"\x13OperatorLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
+	"\n" +
+	"CorePlugin\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\"\n" +
+	"\fcapabilities\x18\x03 \x03(\tR\fcapabilities\"\x12\n" +
+	"\x10GetConfigRequest\" \n" +
+	"\n" +
+	"ConfigDump\x12\x12\n" +
+	"\x04yaml\x18\x01 \x01(\tR\x04yaml\"\xf7\x03\n" +
 	"\tOperation\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x125\n" +
@@ -3846,7 +14477,12 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\fcompleted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x14\n" +
 	"\x05error\x18\x06 \x01(\tR\x05error\x12D\n" +
 	"\bmetadata\x18\a \x03(\v2(.mandau.agent.v1.Operation.MetadataEntryR\bmetadata\x12\x1a\n" +
-	"\bprogress\x18\b \x01(\x05R\bprogress\x1a;\n" +
+	"\bprogress\x18\b \x01(\x05R\bprogress\x12'\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x12\x18\n" +
+	"\amessage\x18\n" +
+	" \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\v \x01(\tR\trequestId\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf0\x01\n" +
@@ -3856,41 +14492,118 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
 	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1a\n" +
 	"\bprogress\x18\x05 \x01(\x05R\bprogress\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"\xaf\x01\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xe0\x02\n" +
 	"\x10HeartbeatRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12E\n" +
-	"\x06status\x18\x02 \x03(\v2-.mandau.agent.v1.HeartbeatRequest.StatusEntryR\x06status\x1a9\n" +
+	"\x06status\x18\x02 \x03(\v2-.mandau.agent.v1.HeartbeatRequest.StatusEntryR\x06status\x12<\n" +
+	"\aresults\x18\x03 \x03(\v2\".mandau.agent.v1.InstructionResultR\aresults\x123\n" +
+	"\asent_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06sentAt\x12<\n" +
+	"\fclock_offset\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vclockOffset\x1a9\n" +
 	"\vStatusEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"m\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x90\x02\n" +
 	"\x11HeartbeatResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12@\n" +
-	"\x0enext_heartbeat\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\rnextHeartbeat\"\x15\n" +
-	"\x13CapabilitiesRequest\":\n" +
+	"\x0enext_heartbeat\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\rnextHeartbeat\x12E\n" +
+	"\finstructions\x18\x03 \x03(\v2!.mandau.agent.v1.AgentInstructionR\finstructions\x127\n" +
+	"\tcore_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bcoreTime\x12!\n" +
+	"\fclock_skewed\x18\x05 \x01(\bR\vclockSkewed\"\x87\x04\n" +
+	"\x10AgentInstruction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12!\n" +
+	"\frequested_by\x18\x03 \x01(\tR\vrequestedBy\x12<\n" +
+	"\x06config\x18\x04 \x01(\v2\".mandau.agent.v1.ConfigInstructionH\x00R\x06config\x12E\n" +
+	"\vapply_stack\x18\x05 \x01(\v2\".mandau.agent.v1.ApplyStackRequestH\x00R\n" +
+	"applyStack\x12H\n" +
+	"\fremove_stack\x18\x06 \x01(\v2#.mandau.agent.v1.RemoveStackRequestH\x00R\vremoveStack\x129\n" +
+	"\x05drain\x18\a \x01(\v2!.mandau.agent.v1.DrainInstructionH\x00R\x05drain\x12\x19\n" +
+	"\bagent_id\x18\b \x01(\tR\aagentId\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\n" +
+	" \x01(\tR\trequestIdB\x06\n" +
+	"\x04kind\"-\n" +
+	"\x11ConfigInstruction\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"D\n" +
+	"\x10DrainInstruction\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"~\n" +
+	"\x1cQueueAgentInstructionRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12C\n" +
+	"\vinstruction\x18\x02 \x01(\v2!.mandau.agent.v1.AgentInstructionR\vinstruction\"9\n" +
+	"\x1cListAgentInstructionsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\\\n" +
+	"\x1dListAgentInstructionsResponse\x12;\n" +
+	"\apending\x18\x01 \x03(\v2!.mandau.agent.v1.AgentInstructionR\apending\"a\n" +
+	"\x1dCancelAgentInstructionRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12%\n" +
+	"\x0einstruction_id\x18\x02 \x01(\tR\rinstructionId\"\x8d\x01\n" +
+	"\x11InstructionResult\x12%\n" +
+	"\x0einstruction_id\x18\x01 \x01(\tR\rinstructionId\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12!\n" +
+	"\foperation_id\x18\x04 \x01(\tR\voperationId\"\x15\n" +
+	"\x13CapabilitiesRequest\"y\n" +
 	"\x14CapabilitiesResponse\x12\"\n" +
-	"\fcapabilities\x18\x01 \x03(\tR\fcapabilities\"\x0f\n" +
+	"\fcapabilities\x18\x01 \x03(\tR\fcapabilities\x12=\n" +
+	"\aplugins\x18\x02 \x03(\v2#.mandau.agent.v1.PluginAvailabilityR\aplugins\"\x0f\n" +
 	"\rHealthRequest\"\xaa\x01\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12C\n" +
 	"\x06status\x18\x02 \x03(\v2+.mandau.agent.v1.HealthResponse.StatusEntryR\x06status\x1a9\n" +
 	"\vStatusEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x02\n" +
 	"\x11ListStacksRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\"D\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12F\n" +
+	"\x06labels\x18\x02 \x03(\v2..mandau.agent.v1.ListStacksRequest.LabelsEntryR\x06labels\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x1d\n" +
+	"\n" +
+	"disk_usage\x18\x04 \x01(\bR\tdiskUsage\x12#\n" +
+	"\rimage_digests\x18\x05 \x01(\bR\fimageDigests\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xdd\x01\n" +
 	"\x12ListStacksResponse\x12.\n" +
-	"\x06stacks\x18\x01 \x03(\v2\x16.mandau.agent.v1.StackR\x06stacks\",\n" +
+	"\x06stacks\x18\x01 \x03(\v2\x16.mandau.agent.v1.StackR\x06stacks\x12W\n" +
+	"\fagent_errors\x18\x02 \x03(\v24.mandau.agent.v1.ListStacksResponse.AgentErrorsEntryR\vagentErrors\x1a>\n" +
+	"\x10AgentErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"J\n" +
 	"\x0fGetStackRequest\x12\x19\n" +
-	"\bstack_id\x18\x01 \x01(\tR\astackId\"@\n" +
+	"\bstack_id\x18\x01 \x01(\tR\astackId\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"@\n" +
 	"\x10GetStackResponse\x12,\n" +
-	"\x05stack\x18\x01 \x01(\v2\x16.mandau.agent.v1.StackR\x05stack\"/\n" +
+	"\x05stack\x18\x01 \x01(\v2\x16.mandau.agent.v1.StackR\x05stack\"\xb4\x02\n" +
 	"\x12RemoveStackRequest\x12\x19\n" +
-	"\bstack_id\x18\x01 \x01(\tR\astackId\"g\n" +
+	"\bstack_id\x18\x01 \x01(\tR\astackId\x12\x1c\n" +
+	"\temergency\x18\x02 \x01(\bR\temergency\x12\x1f\n" +
+	"\vapproval_id\x18\x03 \x01(\tR\n" +
+	"approvalId\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bagent_id\x18\x05 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05group\x18\x06 \x01(\tR\x05group\x12\x14\n" +
+	"\x05queue\x18\a \x01(\bR\x05queue\x126\n" +
+	"\tqueue_ttl\x18\b \x01(\v2\x19.google.protobuf.DurationR\bqueueTtl\x12'\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\"\xdc\x02\n" +
 	"\x13GetStackLogsRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
 	"stack_name\x18\x02 \x01(\tR\tstackName\x12\x16\n" +
-	"\x06follow\x18\x03 \x01(\bR\x06follow\"\x17\n" +
+	"\x06follow\x18\x03 \x01(\bR\x06follow\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x05 \x01(\x05R\tbatchSize\x12*\n" +
+	"\x11batch_interval_ms\x18\x06 \x01(\x05R\x0fbatchIntervalMs\x120\n" +
+	"\x05since\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x12\n" +
+	"\x04grep\x18\t \x01(\tR\x04grep\x12\x12\n" +
+	"\x04tail\x18\n" +
+	" \x01(\x05R\x04tail\"?\n" +
+	"\bLogBatch\x123\n" +
+	"\aentries\x18\x01 \x03(\v2\x19.mandau.agent.v1.LogEntryR\aentries\"\x17\n" +
 	"\x15ListContainersRequest\"T\n" +
 	"\x16ListContainersResponse\x12:\n" +
 	"\n" +
@@ -3919,11 +14632,17 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\x12DeleteFileResponse\",\n" +
 	"\x16CreateDirectoryRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\"\x19\n" +
-	"\x17CreateDirectoryResponse\"8\n" +
+	"\x17CreateDirectoryResponse\"S\n" +
 	"\x13GetOperationRequest\x12!\n" +
-	"\foperation_id\x18\x01 \x01(\tR\voperationId\"\x17\n" +
-	"\x15ListOperationsRequest\"\x18\n" +
-	"\x16ListOperationsResponse\";\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\"[\n" +
+	"\x15ListOperationsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\"T\n" +
+	"\x16ListOperationsResponse\x12:\n" +
+	"\n" +
+	"operations\x18\x01 \x03(\v2\x1a.mandau.agent.v1.OperationR\n" +
+	"operations\";\n" +
 	"\x16CancelOperationRequest\x12!\n" +
 	"\foperation_id\x18\x01 \x01(\tR\voperationId\"\x19\n" +
 	"\x17CancelOperationResponse\";\n" +
@@ -3933,36 +14652,116 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\bCPUStats\"\r\n" +
 	"\vMemoryStats\"\x0e\n" +
 	"\fNetworkStats\"\x0e\n" +
-	"\fBlockIOStats*\x87\x01\n" +
+	"\fBlockIOStats\"\x18\n" +
+	"\x16GetEnrollmentCARequest\"0\n" +
+	"\x17GetEnrollmentCAResponse\x12\x15\n" +
+	"\x06ca_pem\x18\x01 \x01(\fR\x05caPem\"u\n" +
+	"\rEnrollRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\acsr_pem\x18\x02 \x01(\fR\x06csrPem\x12\x1a\n" +
+	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x19\n" +
+	"\bagent_id\x18\x04 \x01(\tR\aagentId\"\xa6\x02\n" +
+	"\x0eEnrollResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0fcertificate_pem\x18\x02 \x01(\fR\x0ecertificatePem\x12\x15\n" +
+	"\x06ca_pem\x18\x03 \x01(\fR\x05caPem\x12C\n" +
+	"\x06labels\x18\x04 \x03(\v2+.mandau.agent.v1.EnrollResponse.LabelsEntryR\x06labels\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xa7\x01\n" +
+	"\x0eAgentDeltaKind\x12 \n" +
+	"\x1cAGENT_DELTA_KIND_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16AGENT_DELTA_KIND_ADDED\x10\x01\x12\x1c\n" +
+	"\x18AGENT_DELTA_KIND_UPDATED\x10\x02\x12\x1c\n" +
+	"\x18AGENT_DELTA_KIND_REMOVED\x10\x03\x12\x1b\n" +
+	"\x17AGENT_DELTA_KIND_SYNCED\x10\x04*\xb6\x01\n" +
+	"\rApprovalState\x12\x1a\n" +
+	"\x16APPROVAL_STATE_UNKNOWN\x10\x00\x12\x1a\n" +
+	"\x16APPROVAL_STATE_PENDING\x10\x01\x12\x1b\n" +
+	"\x17APPROVAL_STATE_APPROVED\x10\x02\x12\x1b\n" +
+	"\x17APPROVAL_STATE_REJECTED\x10\x03\x12\x17\n" +
+	"\x13APPROVAL_STATE_USED\x10\x04\x12\x1a\n" +
+	"\x16APPROVAL_STATE_EXPIRED\x10\x05*P\n" +
+	"\vCheckStatus\x12\x13\n" +
+	"\x0fCHECK_STATUS_OK\x10\x00\x12\x15\n" +
+	"\x11CHECK_STATUS_WARN\x10\x01\x12\x15\n" +
+	"\x11CHECK_STATUS_FAIL\x10\x02*\x87\x01\n" +
 	"\n" +
 	"StackState\x12\x17\n" +
 	"\x13STACK_STATE_UNKNOWN\x10\x00\x12\x17\n" +
 	"\x13STACK_STATE_RUNNING\x10\x01\x12\x17\n" +
 	"\x13STACK_STATE_STOPPED\x10\x02\x12\x15\n" +
 	"\x11STACK_STATE_ERROR\x10\x03\x12\x17\n" +
-	"\x13STACK_STATE_PARTIAL\x10\x04*j\n" +
+	"\x13STACK_STATE_PARTIAL\x10\x04*|\n" +
+	"\n" +
+	"OrphanKind\x12\x1b\n" +
+	"\x17ORPHAN_KIND_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ORPHAN_KIND_STACK_DIR\x10\x01\x12\x1a\n" +
+	"\x16ORPHAN_KIND_CONTAINERS\x10\x02\x12\x1a\n" +
+	"\x16ORPHAN_KIND_STALE_FILE\x10\x03*j\n" +
 	"\n" +
 	"DiffAction\x12\x14\n" +
 	"\x10DIFF_ACTION_NONE\x10\x00\x12\x16\n" +
 	"\x12DIFF_ACTION_CREATE\x10\x01\x12\x16\n" +
 	"\x12DIFF_ACTION_UPDATE\x10\x02\x12\x16\n" +
-	"\x12DIFF_ACTION_DELETE\x10\x03*\xa4\x01\n" +
+	"\x12DIFF_ACTION_DELETE\x10\x03*e\n" +
+	"\fTransferKind\x12\x1d\n" +
+	"\x19TRANSFER_KIND_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12TRANSFER_KIND_FILE\x10\x01\x12\x1e\n" +
+	"\x1aTRANSFER_KIND_IMAGE_BUNDLE\x10\x02*\xa4\x01\n" +
 	"\x0eOperationState\x12\x1b\n" +
 	"\x17OPERATION_STATE_PENDING\x10\x00\x12\x1b\n" +
 	"\x17OPERATION_STATE_RUNNING\x10\x01\x12\x1d\n" +
 	"\x19OPERATION_STATE_COMPLETED\x10\x02\x12\x1a\n" +
 	"\x16OPERATION_STATE_FAILED\x10\x03\x12\x1d\n" +
-	"\x19OPERATION_STATE_CANCELLED\x10\x042\x8e\x02\n" +
+	"\x19OPERATION_STATE_CANCELLED\x10\x042\xb7\x19\n" +
 	"\vCoreService\x12U\n" +
 	"\n" +
-	"ListAgents\x12\".mandau.agent.v1.ListAgentsRequest\x1a#.mandau.agent.v1.ListAgentsResponse\x12T\n" +
+	"ListAgents\x12\".mandau.agent.v1.ListAgentsRequest\x1a#.mandau.agent.v1.ListAgentsResponse\x12Q\n" +
+	"\vWatchAgents\x12#.mandau.agent.v1.WatchAgentsRequest\x1a\x1b.mandau.agent.v1.AgentDelta0\x01\x12T\n" +
 	"\rRegisterAgent\x12 .mandau.agent.v1.RegisterRequest\x1a!.mandau.agent.v1.RegisterResponse\x12R\n" +
-	"\tHeartbeat\x12!.mandau.agent.v1.HeartbeatRequest\x1a\".mandau.agent.v1.HeartbeatResponse2\xe1\x02\n" +
+	"\tHeartbeat\x12!.mandau.agent.v1.HeartbeatRequest\x1a\".mandau.agent.v1.HeartbeatResponse\x12\\\n" +
+	"\x0fHeartbeatStream\x12!.mandau.agent.v1.HeartbeatRequest\x1a\".mandau.agent.v1.HeartbeatResponse(\x010\x01\x12j\n" +
+	"\x11UpdateAgentLabels\x12).mandau.agent.v1.UpdateAgentLabelsRequest\x1a*.mandau.agent.v1.UpdateAgentLabelsResponse\x12p\n" +
+	"\x13SetAgentMaintenance\x12+.mandau.agent.v1.SetAgentMaintenanceRequest\x1a,.mandau.agent.v1.SetAgentMaintenanceResponse\x12i\n" +
+	"\x15QueueAgentInstruction\x12-.mandau.agent.v1.QueueAgentInstructionRequest\x1a!.mandau.agent.v1.AgentInstruction\x12v\n" +
+	"\x15ListAgentInstructions\x12-.mandau.agent.v1.ListAgentInstructionsRequest\x1a..mandau.agent.v1.ListAgentInstructionsResponse\x12k\n" +
+	"\x16CancelAgentInstruction\x12..mandau.agent.v1.CancelAgentInstructionRequest\x1a!.mandau.agent.v1.AgentInstruction\x12Y\n" +
+	"\x10CreateAgentGroup\x12(.mandau.agent.v1.CreateAgentGroupRequest\x1a\x1b.mandau.agent.v1.AgentGroup\x12^\n" +
+	"\rGetAgentGroup\x12%.mandau.agent.v1.GetAgentGroupRequest\x1a&.mandau.agent.v1.GetAgentGroupResponse\x12d\n" +
+	"\x0fListAgentGroups\x12'.mandau.agent.v1.ListAgentGroupsRequest\x1a(.mandau.agent.v1.ListAgentGroupsResponse\x12Y\n" +
+	"\x10UpdateAgentGroup\x12(.mandau.agent.v1.UpdateAgentGroupRequest\x1a\x1b.mandau.agent.v1.AgentGroup\x12g\n" +
+	"\x10DeleteAgentGroup\x12(.mandau.agent.v1.DeleteAgentGroupRequest\x1a).mandau.agent.v1.DeleteAgentGroupResponse\x12^\n" +
+	"\rListApprovals\x12%.mandau.agent.v1.ListApprovalsRequest\x1a&.mandau.agent.v1.ListApprovalsResponse\x12S\n" +
+	"\x0eReviewApproval\x12&.mandau.agent.v1.ReviewApprovalRequest\x1a\x19.mandau.agent.v1.Approval\x12\\\n" +
+	"\x0fGrantBreakGlass\x12'.mandau.agent.v1.GrantBreakGlassRequest\x1a .mandau.agent.v1.BreakGlassGrant\x12^\n" +
+	"\x10RevokeBreakGlass\x12(.mandau.agent.v1.RevokeBreakGlassRequest\x1a .mandau.agent.v1.BreakGlassGrant\x12s\n" +
+	"\x14ListBreakGlassGrants\x12,.mandau.agent.v1.ListBreakGlassGrantsRequest\x1a-.mandau.agent.v1.ListBreakGlassGrantsResponse\x12L\n" +
+	"\tSetFreeze\x12!.mandau.agent.v1.SetFreezeRequest\x1a\x1c.mandau.agent.v1.FreezeState\x12L\n" +
+	"\tGetFreeze\x12!.mandau.agent.v1.GetFreezeRequest\x1a\x1c.mandau.agent.v1.FreezeState\x12S\n" +
+	"\rGetQuotaUsage\x12%.mandau.agent.v1.GetQuotaUsageRequest\x1a\x1b.mandau.agent.v1.QuotaUsage\x12_\n" +
+	"\x11GetResourceReport\x12).mandau.agent.v1.GetResourceReportRequest\x1a\x1f.mandau.agent.v1.ResourceReport\x12O\n" +
+	"\bDiagnose\x12 .mandau.agent.v1.DiagnoseRequest\x1a!.mandau.agent.v1.DiagnoseResponse\x12^\n" +
+	"\rDiagnoseAgent\x12%.mandau.agent.v1.DiagnoseAgentRequest\x1a&.mandau.agent.v1.AgentConnectionReport\x12\\\n" +
+	"\x10GetClusterStatus\x12(.mandau.agent.v1.GetClusterStatusRequest\x1a\x1e.mandau.agent.v1.ClusterStatus\x12b\n" +
+	"\x12GetPatchCompliance\x12*.mandau.agent.v1.GetPatchComplianceRequest\x1a .mandau.agent.v1.PatchCompliance\x12V\n" +
+	"\x0eGetPluginIndex\x12&.mandau.agent.v1.GetPluginIndexRequest\x1a\x1c.mandau.agent.v1.PluginIndex\x12X\n" +
+	"\rInstallPlugin\x12%.mandau.agent.v1.InstallPluginRequest\x1a .mandau.agent.v1.InstalledPlugin\x12s\n" +
+	"\x14ListInstalledPlugins\x12,.mandau.agent.v1.ListInstalledPluginsRequest\x1a-.mandau.agent.v1.ListInstalledPluginsResponse\x12\\\n" +
+	"\x0eDescribePlugin\x12&.mandau.agent.v1.DescribePluginRequest\x1a\".mandau.agent.v1.PluginDescription\x12_\n" +
+	"\x0fRunFleetCommand\x12'.mandau.agent.v1.RunFleetCommandRequest\x1a#.mandau.agent.v1.FleetCommandReport\x12P\n" +
+	"\fGetInventory\x12$.mandau.agent.v1.GetInventoryRequest\x1a\x1a.mandau.agent.v1.Inventory2\xd1\x05\n" +
 	"\fAgentService\x12O\n" +
 	"\bRegister\x12 .mandau.agent.v1.RegisterRequest\x1a!.mandau.agent.v1.RegisterResponse\x12R\n" +
 	"\tHeartbeat\x12!.mandau.agent.v1.HeartbeatRequest\x1a\".mandau.agent.v1.HeartbeatResponse\x12^\n" +
 	"\x0fGetCapabilities\x12$.mandau.agent.v1.CapabilitiesRequest\x1a%.mandau.agent.v1.CapabilitiesResponse\x12L\n" +
-	"\tGetHealth\x12\x1e.mandau.agent.v1.HealthRequest\x1a\x1f.mandau.agent.v1.HealthResponse2\x89\x04\n" +
+	"\tGetHealth\x12\x1e.mandau.agent.v1.HealthRequest\x1a\x1f.mandau.agent.v1.HealthResponse\x12O\n" +
+	"\bDiagnose\x12 .mandau.agent.v1.DiagnoseRequest\x1a!.mandau.agent.v1.DiagnoseResponse\x12e\n" +
+	"\x13CheckCoreConnection\x12+.mandau.agent.v1.CheckCoreConnectionRequest\x1a!.mandau.agent.v1.CoreConnectivity\x12X\n" +
+	"\rInstallPlugin\x12%.mandau.agent.v1.InstallPluginRequest\x1a .mandau.agent.v1.InstalledPlugin\x12\\\n" +
+	"\x0eDescribePlugin\x12&.mandau.agent.v1.DescribePluginRequest\x1a\".mandau.agent.v1.PluginDescription2\xcf\b\n" +
 	"\fStackService\x12U\n" +
 	"\n" +
 	"ListStacks\x12\".mandau.agent.v1.ListStacksRequest\x1a#.mandau.agent.v1.ListStacksResponse\x12O\n" +
@@ -3971,7 +14770,13 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"ApplyStack\x12\".mandau.agent.v1.ApplyStackRequest\x1a\x1f.mandau.agent.v1.OperationEvent0\x01\x12U\n" +
 	"\vRemoveStack\x12#.mandau.agent.v1.RemoveStackRequest\x1a\x1f.mandau.agent.v1.OperationEvent0\x01\x12R\n" +
 	"\tDiffStack\x12!.mandau.agent.v1.DiffStackRequest\x1a\".mandau.agent.v1.DiffStackResponse\x12Q\n" +
-	"\fGetStackLogs\x12$.mandau.agent.v1.GetStackLogsRequest\x1a\x19.mandau.agent.v1.LogEntry0\x012\xf3\x05\n" +
+	"\fGetStackLogs\x12$.mandau.agent.v1.GetStackLogsRequest\x1a\x19.mandau.agent.v1.LogEntry0\x01\x12X\n" +
+	"\x13GetStackLogsBatched\x12$.mandau.agent.v1.GetStackLogsRequest\x1a\x19.mandau.agent.v1.LogBatch0\x01\x12P\n" +
+	"\vExportStack\x12#.mandau.agent.v1.ExportStackRequest\x1a\x1c.mandau.agent.v1.StackExport\x12p\n" +
+	"\x13CollectStackGarbage\x12+.mandau.agent.v1.CollectStackGarbageRequest\x1a,.mandau.agent.v1.CollectStackGarbageResponse\x12a\n" +
+	"\x0eGetStackEvents\x12&.mandau.agent.v1.GetStackEventsRequest\x1a'.mandau.agent.v1.GetStackEventsResponse\x12a\n" +
+	"\x0eDiffStackFiles\x12&.mandau.agent.v1.DiffStackFilesRequest\x1a'.mandau.agent.v1.DiffStackFilesResponse\x12`\n" +
+	"\x10UploadStackFiles\x12\x1f.mandau.agent.v1.StackFileChunk\x1a).mandau.agent.v1.UploadStackFilesResponse(\x012\xf3\x05\n" +
 	"\x10ContainerService\x12a\n" +
 	"\x0eListContainers\x12&.mandau.agent.v1.ListContainersRequest\x1a'.mandau.agent.v1.ListContainersResponse\x12g\n" +
 	"\x10InspectContainer\x12(.mandau.agent.v1.InspectContainerRequest\x1a).mandau.agent.v1.InspectContainerResponse\x12M\n" +
@@ -3988,12 +14793,34 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\tWriteFile\x12!.mandau.agent.v1.WriteFileRequest\x1a\".mandau.agent.v1.WriteFileResponse\x12U\n" +
 	"\n" +
 	"DeleteFile\x12\".mandau.agent.v1.DeleteFileRequest\x1a#.mandau.agent.v1.DeleteFileResponse\x12d\n" +
-	"\x0fCreateDirectory\x12'.mandau.agent.v1.CreateDirectoryRequest\x1a(.mandau.agent.v1.CreateDirectoryResponse2\x8d\x03\n" +
+	"\x0fCreateDirectory\x12'.mandau.agent.v1.CreateDirectoryRequest\x1a(.mandau.agent.v1.CreateDirectoryResponse2\xb2\x03\n" +
+	"\x0fTransferService\x12K\n" +
+	"\x06Upload\x12\x1e.mandau.agent.v1.UploadRequest\x1a\x1f.mandau.agent.v1.TransferStatus(\x01\x12S\n" +
+	"\vGetTransfer\x12#.mandau.agent.v1.GetTransferRequest\x1a\x1f.mandau.agent.v1.TransferStatus\x12X\n" +
+	"\vListBackups\x12#.mandau.agent.v1.ListBackupsRequest\x1a$.mandau.agent.v1.ListBackupsResponse\x12Z\n" +
+	"\x0eDownloadBackup\x12&.mandau.agent.v1.DownloadBackupRequest\x1a\x1e.mandau.agent.v1.DownloadChunk0\x01\x12G\n" +
+	"\x05Fetch\x12\x1d.mandau.agent.v1.FetchRequest\x1a\x1f.mandau.agent.v1.TransferStatus2\xb9\x04\n" +
+	"\x0fArtifactService\x12O\n" +
+	"\vPutArtifact\x12#.mandau.agent.v1.PutArtifactRequest\x1a\x19.mandau.agent.v1.Artifact(\x01\x12M\n" +
+	"\vGetArtifact\x12#.mandau.agent.v1.GetArtifactRequest\x1a\x19.mandau.agent.v1.Artifact\x12^\n" +
+	"\rListArtifacts\x12%.mandau.agent.v1.ListArtifactsRequest\x1a&.mandau.agent.v1.ListArtifactsResponse\x12S\n" +
+	"\x0eDeleteArtifact\x12&.mandau.agent.v1.DeleteArtifactRequest\x1a\x19.mandau.agent.v1.Artifact\x12y\n" +
+	"\x16CollectArtifactGarbage\x12..mandau.agent.v1.CollectArtifactGarbageRequest\x1a/.mandau.agent.v1.CollectArtifactGarbageResponse\x12V\n" +
+	"\rFetchArtifact\x12%.mandau.agent.v1.FetchArtifactRequest\x1a\x1e.mandau.agent.v1.ArtifactChunk2\xe7\x02\n" +
+	"\fAdminService\x12Q\n" +
+	"\n" +
+	"GetProfile\x12\".mandau.agent.v1.GetProfileRequest\x1a\x1d.mandau.agent.v1.ProfileChunk0\x01\x12d\n" +
+	"\x0fListConnections\x12'.mandau.agent.v1.ListConnectionsRequest\x1a(.mandau.agent.v1.ListConnectionsResponse\x12Q\n" +
+	"\vGetRegistry\x12#.mandau.agent.v1.GetRegistryRequest\x1a\x1d.mandau.agent.v1.CoreRegistry\x12K\n" +
+	"\tGetConfig\x12!.mandau.agent.v1.GetConfigRequest\x1a\x1b.mandau.agent.v1.ConfigDump2\x8d\x03\n" +
 	"\x11OperationsService\x12P\n" +
 	"\fGetOperation\x12$.mandau.agent.v1.GetOperationRequest\x1a\x1a.mandau.agent.v1.Operation\x12a\n" +
 	"\x0eListOperations\x12&.mandau.agent.v1.ListOperationsRequest\x1a'.mandau.agent.v1.ListOperationsResponse\x12d\n" +
 	"\x0fCancelOperation\x12'.mandau.agent.v1.CancelOperationRequest\x1a(.mandau.agent.v1.CancelOperationResponse\x12]\n" +
-	"\x0fStreamOperation\x12'.mandau.agent.v1.StreamOperationRequest\x1a\x1f.mandau.agent.v1.OperationEvent0\x01B%Z#github.com/bhangun/mandau/api/v1;v1b\x06proto3"
+	"\x0fStreamOperation\x12'.mandau.agent.v1.StreamOperationRequest\x1a\x1f.mandau.agent.v1.OperationEvent0\x012\xc4\x01\n" +
+	"\x11EnrollmentService\x12d\n" +
+	"\x0fGetEnrollmentCA\x12'.mandau.agent.v1.GetEnrollmentCARequest\x1a(.mandau.agent.v1.GetEnrollmentCAResponse\x12I\n" +
+	"\x06Enroll\x12\x1e.mandau.agent.v1.EnrollRequest\x1a\x1f.mandau.agent.v1.EnrollResponseB%Z#github.com/bhangun/mandau/api/v1;v1b\x06proto3"
 
 var (
 	file_api_v1_agent_proto_rawDescOnce sync.Once
@@ -4007,196 +14834,655 @@ func file_api_v1_agent_proto_rawDescGZIP() []byte {
 	return file_api_v1_agent_proto_rawDescData
 }
 
-var file_api_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_api_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 74)
+var file_api_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_api_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 236)
 var file_api_v1_agent_proto_goTypes = []any{
-	(StackState)(0),                  // 0: mandau.agent.v1.StackState
-	(DiffAction)(0),                  // 1: mandau.agent.v1.DiffAction
-	(OperationState)(0),              // 2: mandau.agent.v1.OperationState
-	(*ListAgentsRequest)(nil),        // 3: mandau.agent.v1.ListAgentsRequest
-	(*ListAgentsResponse)(nil),       // 4: mandau.agent.v1.ListAgentsResponse
-	(*Agent)(nil),                    // 5: mandau.agent.v1.Agent
-	(*RegisterRequest)(nil),          // 6: mandau.agent.v1.RegisterRequest
-	(*RegisterResponse)(nil),         // 7: mandau.agent.v1.RegisterResponse
-	(*Stack)(nil),                    // 8: mandau.agent.v1.Stack
-	(*ApplyStackRequest)(nil),        // 9: mandau.agent.v1.ApplyStackRequest
-	(*DiffStackRequest)(nil),         // 10: mandau.agent.v1.DiffStackRequest
-	(*DiffStackResponse)(nil),        // 11: mandau.agent.v1.DiffStackResponse
-	(*ServiceDiff)(nil),              // 12: mandau.agent.v1.ServiceDiff
-	(*Container)(nil),                // 13: mandau.agent.v1.Container
-	(*Port)(nil),                     // 14: mandau.agent.v1.Port
-	(*ExecRequest)(nil),              // 15: mandau.agent.v1.ExecRequest
-	(*ExecStart)(nil),                // 16: mandau.agent.v1.ExecStart
-	(*ExecResize)(nil),               // 17: mandau.agent.v1.ExecResize
-	(*ExecResponse)(nil),             // 18: mandau.agent.v1.ExecResponse
-	(*LogEntry)(nil),                 // 19: mandau.agent.v1.LogEntry
-	(*ContainerStats)(nil),           // 20: mandau.agent.v1.ContainerStats
-	(*ListFilesRequest)(nil),         // 21: mandau.agent.v1.ListFilesRequest
-	(*ListFilesResponse)(nil),        // 22: mandau.agent.v1.ListFilesResponse
-	(*FileInfo)(nil),                 // 23: mandau.agent.v1.FileInfo
-	(*ReadFileRequest)(nil),          // 24: mandau.agent.v1.ReadFileRequest
-	(*ReadFileResponse)(nil),         // 25: mandau.agent.v1.ReadFileResponse
-	(*WriteFileRequest)(nil),         // 26: mandau.agent.v1.WriteFileRequest
-	(*Operation)(nil),                // 27: mandau.agent.v1.Operation
-	(*OperationEvent)(nil),           // 28: mandau.agent.v1.OperationEvent
-	(*HeartbeatRequest)(nil),         // 29: mandau.agent.v1.HeartbeatRequest
-	(*HeartbeatResponse)(nil),        // 30: mandau.agent.v1.HeartbeatResponse
-	(*CapabilitiesRequest)(nil),      // 31: mandau.agent.v1.CapabilitiesRequest
-	(*CapabilitiesResponse)(nil),     // 32: mandau.agent.v1.CapabilitiesResponse
-	(*HealthRequest)(nil),            // 33: mandau.agent.v1.HealthRequest
-	(*HealthResponse)(nil),           // 34: mandau.agent.v1.HealthResponse
-	(*ListStacksRequest)(nil),        // 35: mandau.agent.v1.ListStacksRequest
-	(*ListStacksResponse)(nil),       // 36: mandau.agent.v1.ListStacksResponse
-	(*GetStackRequest)(nil),          // 37: mandau.agent.v1.GetStackRequest
-	(*GetStackResponse)(nil),         // 38: mandau.agent.v1.GetStackResponse
-	(*RemoveStackRequest)(nil),       // 39: mandau.agent.v1.RemoveStackRequest
-	(*GetStackLogsRequest)(nil),      // 40: mandau.agent.v1.GetStackLogsRequest
-	(*ListContainersRequest)(nil),    // 41: mandau.agent.v1.ListContainersRequest
-	(*ListContainersResponse)(nil),   // 42: mandau.agent.v1.ListContainersResponse
-	(*InspectContainerRequest)(nil),  // 43: mandau.agent.v1.InspectContainerRequest
-	(*InspectContainerResponse)(nil), // 44: mandau.agent.v1.InspectContainerResponse
-	(*StreamLogsRequest)(nil),        // 45: mandau.agent.v1.StreamLogsRequest
-	(*GetStatsRequest)(nil),          // 46: mandau.agent.v1.GetStatsRequest
-	(*StartContainerRequest)(nil),    // 47: mandau.agent.v1.StartContainerRequest
-	(*StartContainerResponse)(nil),   // 48: mandau.agent.v1.StartContainerResponse
-	(*StopContainerRequest)(nil),     // 49: mandau.agent.v1.StopContainerRequest
-	(*StopContainerResponse)(nil),    // 50: mandau.agent.v1.StopContainerResponse
-	(*RestartContainerRequest)(nil),  // 51: mandau.agent.v1.RestartContainerRequest
-	(*RestartContainerResponse)(nil), // 52: mandau.agent.v1.RestartContainerResponse
-	(*WriteFileResponse)(nil),        // 53: mandau.agent.v1.WriteFileResponse
-	(*DeleteFileRequest)(nil),        // 54: mandau.agent.v1.DeleteFileRequest
-	(*DeleteFileResponse)(nil),       // 55: mandau.agent.v1.DeleteFileResponse
-	(*CreateDirectoryRequest)(nil),   // 56: mandau.agent.v1.CreateDirectoryRequest
-	(*CreateDirectoryResponse)(nil),  // 57: mandau.agent.v1.CreateDirectoryResponse
-	(*GetOperationRequest)(nil),      // 58: mandau.agent.v1.GetOperationRequest
-	(*ListOperationsRequest)(nil),    // 59: mandau.agent.v1.ListOperationsRequest
-	(*ListOperationsResponse)(nil),   // 60: mandau.agent.v1.ListOperationsResponse
-	(*CancelOperationRequest)(nil),   // 61: mandau.agent.v1.CancelOperationRequest
-	(*CancelOperationResponse)(nil),  // 62: mandau.agent.v1.CancelOperationResponse
-	(*StreamOperationRequest)(nil),   // 63: mandau.agent.v1.StreamOperationRequest
-	(*CPUStats)(nil),                 // 64: mandau.agent.v1.CPUStats
-	(*MemoryStats)(nil),              // 65: mandau.agent.v1.MemoryStats
-	(*NetworkStats)(nil),             // 66: mandau.agent.v1.NetworkStats
-	(*BlockIOStats)(nil),             // 67: mandau.agent.v1.BlockIOStats
-	nil,                              // 68: mandau.agent.v1.Agent.LabelsEntry
-	nil,                              // 69: mandau.agent.v1.RegisterRequest.LabelsEntry
-	nil,                              // 70: mandau.agent.v1.Stack.LabelsEntry
-	nil,                              // 71: mandau.agent.v1.ApplyStackRequest.EnvVarsEntry
-	nil,                              // 72: mandau.agent.v1.Container.LabelsEntry
-	nil,                              // 73: mandau.agent.v1.ExecStart.EnvEntry
-	nil,                              // 74: mandau.agent.v1.Operation.MetadataEntry
-	nil,                              // 75: mandau.agent.v1.HeartbeatRequest.StatusEntry
-	nil,                              // 76: mandau.agent.v1.HealthResponse.StatusEntry
-	(*timestamppb.Timestamp)(nil),    // 77: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),      // 78: google.protobuf.Duration
+	(AgentDeltaKind)(0),                    // 0: mandau.agent.v1.AgentDeltaKind
+	(ApprovalState)(0),                     // 1: mandau.agent.v1.ApprovalState
+	(CheckStatus)(0),                       // 2: mandau.agent.v1.CheckStatus
+	(StackState)(0),                        // 3: mandau.agent.v1.StackState
+	(OrphanKind)(0),                        // 4: mandau.agent.v1.OrphanKind
+	(DiffAction)(0),                        // 5: mandau.agent.v1.DiffAction
+	(TransferKind)(0),                      // 6: mandau.agent.v1.TransferKind
+	(OperationState)(0),                    // 7: mandau.agent.v1.OperationState
+	(*ListAgentsRequest)(nil),              // 8: mandau.agent.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),             // 9: mandau.agent.v1.ListAgentsResponse
+	(*WatchAgentsRequest)(nil),             // 10: mandau.agent.v1.WatchAgentsRequest
+	(*AgentDelta)(nil),                     // 11: mandau.agent.v1.AgentDelta
+	(*UpdateAgentLabelsRequest)(nil),       // 12: mandau.agent.v1.UpdateAgentLabelsRequest
+	(*UpdateAgentLabelsResponse)(nil),      // 13: mandau.agent.v1.UpdateAgentLabelsResponse
+	(*SetAgentMaintenanceRequest)(nil),     // 14: mandau.agent.v1.SetAgentMaintenanceRequest
+	(*SetAgentMaintenanceResponse)(nil),    // 15: mandau.agent.v1.SetAgentMaintenanceResponse
+	(*Maintenance)(nil),                    // 16: mandau.agent.v1.Maintenance
+	(*Agent)(nil),                          // 17: mandau.agent.v1.Agent
+	(*AgentCircuit)(nil),                   // 18: mandau.agent.v1.AgentCircuit
+	(*AgentGroup)(nil),                     // 19: mandau.agent.v1.AgentGroup
+	(*CreateAgentGroupRequest)(nil),        // 20: mandau.agent.v1.CreateAgentGroupRequest
+	(*GetAgentGroupRequest)(nil),           // 21: mandau.agent.v1.GetAgentGroupRequest
+	(*GetAgentGroupResponse)(nil),          // 22: mandau.agent.v1.GetAgentGroupResponse
+	(*ListAgentGroupsRequest)(nil),         // 23: mandau.agent.v1.ListAgentGroupsRequest
+	(*ListAgentGroupsResponse)(nil),        // 24: mandau.agent.v1.ListAgentGroupsResponse
+	(*UpdateAgentGroupRequest)(nil),        // 25: mandau.agent.v1.UpdateAgentGroupRequest
+	(*DeleteAgentGroupRequest)(nil),        // 26: mandau.agent.v1.DeleteAgentGroupRequest
+	(*DeleteAgentGroupResponse)(nil),       // 27: mandau.agent.v1.DeleteAgentGroupResponse
+	(*Approval)(nil),                       // 28: mandau.agent.v1.Approval
+	(*ListApprovalsRequest)(nil),           // 29: mandau.agent.v1.ListApprovalsRequest
+	(*ListApprovalsResponse)(nil),          // 30: mandau.agent.v1.ListApprovalsResponse
+	(*ReviewApprovalRequest)(nil),          // 31: mandau.agent.v1.ReviewApprovalRequest
+	(*BreakGlassGrant)(nil),                // 32: mandau.agent.v1.BreakGlassGrant
+	(*GrantBreakGlassRequest)(nil),         // 33: mandau.agent.v1.GrantBreakGlassRequest
+	(*RevokeBreakGlassRequest)(nil),        // 34: mandau.agent.v1.RevokeBreakGlassRequest
+	(*ListBreakGlassGrantsRequest)(nil),    // 35: mandau.agent.v1.ListBreakGlassGrantsRequest
+	(*ListBreakGlassGrantsResponse)(nil),   // 36: mandau.agent.v1.ListBreakGlassGrantsResponse
+	(*SetFreezeRequest)(nil),               // 37: mandau.agent.v1.SetFreezeRequest
+	(*GetFreezeRequest)(nil),               // 38: mandau.agent.v1.GetFreezeRequest
+	(*GetClusterStatusRequest)(nil),        // 39: mandau.agent.v1.GetClusterStatusRequest
+	(*ClusterStatus)(nil),                  // 40: mandau.agent.v1.ClusterStatus
+	(*AgentClockSkew)(nil),                 // 41: mandau.agent.v1.AgentClockSkew
+	(*ClusterOperation)(nil),               // 42: mandau.agent.v1.ClusterOperation
+	(*ExpiringCertificate)(nil),            // 43: mandau.agent.v1.ExpiringCertificate
+	(*FreezeState)(nil),                    // 44: mandau.agent.v1.FreezeState
+	(*GetQuotaUsageRequest)(nil),           // 45: mandau.agent.v1.GetQuotaUsageRequest
+	(*QuotaUsage)(nil),                     // 46: mandau.agent.v1.QuotaUsage
+	(*AgentQuotaUsage)(nil),                // 47: mandau.agent.v1.AgentQuotaUsage
+	(*DiagnoseRequest)(nil),                // 48: mandau.agent.v1.DiagnoseRequest
+	(*DiagnoseResponse)(nil),               // 49: mandau.agent.v1.DiagnoseResponse
+	(*DiagnosticCheck)(nil),                // 50: mandau.agent.v1.DiagnosticCheck
+	(*DiagnoseAgentRequest)(nil),           // 51: mandau.agent.v1.DiagnoseAgentRequest
+	(*AgentConnectionReport)(nil),          // 52: mandau.agent.v1.AgentConnectionReport
+	(*RoundTrips)(nil),                     // 53: mandau.agent.v1.RoundTrips
+	(*PeerCertificate)(nil),                // 54: mandau.agent.v1.PeerCertificate
+	(*CheckCoreConnectionRequest)(nil),     // 55: mandau.agent.v1.CheckCoreConnectionRequest
+	(*CoreConnectivity)(nil),               // 56: mandau.agent.v1.CoreConnectivity
+	(*GetResourceReportRequest)(nil),       // 57: mandau.agent.v1.GetResourceReportRequest
+	(*ResourceReport)(nil),                 // 58: mandau.agent.v1.ResourceReport
+	(*StackUsage)(nil),                     // 59: mandau.agent.v1.StackUsage
+	(*GetPatchComplianceRequest)(nil),      // 60: mandau.agent.v1.GetPatchComplianceRequest
+	(*PatchCompliance)(nil),                // 61: mandau.agent.v1.PatchCompliance
+	(*RunFleetCommandRequest)(nil),         // 62: mandau.agent.v1.RunFleetCommandRequest
+	(*FleetCommandReport)(nil),             // 63: mandau.agent.v1.FleetCommandReport
+	(*CommandResult)(nil),                  // 64: mandau.agent.v1.CommandResult
+	(*GetInventoryRequest)(nil),            // 65: mandau.agent.v1.GetInventoryRequest
+	(*Inventory)(nil),                      // 66: mandau.agent.v1.Inventory
+	(*AgentInventory)(nil),                 // 67: mandau.agent.v1.AgentInventory
+	(*InventoryHost)(nil),                  // 68: mandau.agent.v1.InventoryHost
+	(*InventoryVirtualHost)(nil),           // 69: mandau.agent.v1.InventoryVirtualHost
+	(*InventoryCertificate)(nil),           // 70: mandau.agent.v1.InventoryCertificate
+	(*AgentPatchStatus)(nil),               // 71: mandau.agent.v1.AgentPatchStatus
+	(*PatchStatus)(nil),                    // 72: mandau.agent.v1.PatchStatus
+	(*PackageUpdate)(nil),                  // 73: mandau.agent.v1.PackageUpdate
+	(*NamespaceQuotaUsage)(nil),            // 74: mandau.agent.v1.NamespaceQuotaUsage
+	(*RegisterRequest)(nil),                // 75: mandau.agent.v1.RegisterRequest
+	(*PluginAvailability)(nil),             // 76: mandau.agent.v1.PluginAvailability
+	(*RegisterResponse)(nil),               // 77: mandau.agent.v1.RegisterResponse
+	(*GetPluginIndexRequest)(nil),          // 78: mandau.agent.v1.GetPluginIndexRequest
+	(*PluginIndex)(nil),                    // 79: mandau.agent.v1.PluginIndex
+	(*IndexedPlugin)(nil),                  // 80: mandau.agent.v1.IndexedPlugin
+	(*InstallPluginRequest)(nil),           // 81: mandau.agent.v1.InstallPluginRequest
+	(*InstalledPlugin)(nil),                // 82: mandau.agent.v1.InstalledPlugin
+	(*ListInstalledPluginsRequest)(nil),    // 83: mandau.agent.v1.ListInstalledPluginsRequest
+	(*ListInstalledPluginsResponse)(nil),   // 84: mandau.agent.v1.ListInstalledPluginsResponse
+	(*DescribePluginRequest)(nil),          // 85: mandau.agent.v1.DescribePluginRequest
+	(*PluginPermissions)(nil),              // 86: mandau.agent.v1.PluginPermissions
+	(*PluginDescription)(nil),              // 87: mandau.agent.v1.PluginDescription
+	(*Stack)(nil),                          // 88: mandau.agent.v1.Stack
+	(*StackDiskUsage)(nil),                 // 89: mandau.agent.v1.StackDiskUsage
+	(*StackResources)(nil),                 // 90: mandau.agent.v1.StackResources
+	(*StackOwner)(nil),                     // 91: mandau.agent.v1.StackOwner
+	(*ApplyStackRequest)(nil),              // 92: mandau.agent.v1.ApplyStackRequest
+	(*StackContent)(nil),                   // 93: mandau.agent.v1.StackContent
+	(*StackContents)(nil),                  // 94: mandau.agent.v1.StackContents
+	(*StackFile)(nil),                      // 95: mandau.agent.v1.StackFile
+	(*StackFiles)(nil),                     // 96: mandau.agent.v1.StackFiles
+	(*DiffStackFilesRequest)(nil),          // 97: mandau.agent.v1.DiffStackFilesRequest
+	(*DiffStackFilesResponse)(nil),         // 98: mandau.agent.v1.DiffStackFilesResponse
+	(*StackFileChunk)(nil),                 // 99: mandau.agent.v1.StackFileChunk
+	(*UploadStackFilesResponse)(nil),       // 100: mandau.agent.v1.UploadStackFilesResponse
+	(*StackHooks)(nil),                     // 101: mandau.agent.v1.StackHooks
+	(*DiffStackRequest)(nil),               // 102: mandau.agent.v1.DiffStackRequest
+	(*DiffStackResponse)(nil),              // 103: mandau.agent.v1.DiffStackResponse
+	(*StackFileDiff)(nil),                  // 104: mandau.agent.v1.StackFileDiff
+	(*ExportStackRequest)(nil),             // 105: mandau.agent.v1.ExportStackRequest
+	(*StackExport)(nil),                    // 106: mandau.agent.v1.StackExport
+	(*CollectStackGarbageRequest)(nil),     // 107: mandau.agent.v1.CollectStackGarbageRequest
+	(*CollectStackGarbageResponse)(nil),    // 108: mandau.agent.v1.CollectStackGarbageResponse
+	(*GetStackEventsRequest)(nil),          // 109: mandau.agent.v1.GetStackEventsRequest
+	(*GetStackEventsResponse)(nil),         // 110: mandau.agent.v1.GetStackEventsResponse
+	(*StackEvent)(nil),                     // 111: mandau.agent.v1.StackEvent
+	(*StackOrphan)(nil),                    // 112: mandau.agent.v1.StackOrphan
+	(*ServiceDiff)(nil),                    // 113: mandau.agent.v1.ServiceDiff
+	(*Container)(nil),                      // 114: mandau.agent.v1.Container
+	(*Port)(nil),                           // 115: mandau.agent.v1.Port
+	(*ExecRequest)(nil),                    // 116: mandau.agent.v1.ExecRequest
+	(*ExecStart)(nil),                      // 117: mandau.agent.v1.ExecStart
+	(*ExecResize)(nil),                     // 118: mandau.agent.v1.ExecResize
+	(*ExecResponse)(nil),                   // 119: mandau.agent.v1.ExecResponse
+	(*LogEntry)(nil),                       // 120: mandau.agent.v1.LogEntry
+	(*ContainerStats)(nil),                 // 121: mandau.agent.v1.ContainerStats
+	(*ListFilesRequest)(nil),               // 122: mandau.agent.v1.ListFilesRequest
+	(*ListFilesResponse)(nil),              // 123: mandau.agent.v1.ListFilesResponse
+	(*FileInfo)(nil),                       // 124: mandau.agent.v1.FileInfo
+	(*ReadFileRequest)(nil),                // 125: mandau.agent.v1.ReadFileRequest
+	(*ReadFileResponse)(nil),               // 126: mandau.agent.v1.ReadFileResponse
+	(*WriteFileRequest)(nil),               // 127: mandau.agent.v1.WriteFileRequest
+	(*TransferTarget)(nil),                 // 128: mandau.agent.v1.TransferTarget
+	(*UploadRequest)(nil),                  // 129: mandau.agent.v1.UploadRequest
+	(*TransferStatus)(nil),                 // 130: mandau.agent.v1.TransferStatus
+	(*GetTransferRequest)(nil),             // 131: mandau.agent.v1.GetTransferRequest
+	(*ListBackupsRequest)(nil),             // 132: mandau.agent.v1.ListBackupsRequest
+	(*ListBackupsResponse)(nil),            // 133: mandau.agent.v1.ListBackupsResponse
+	(*DownloadBackupRequest)(nil),          // 134: mandau.agent.v1.DownloadBackupRequest
+	(*DownloadChunk)(nil),                  // 135: mandau.agent.v1.DownloadChunk
+	(*FetchRequest)(nil),                   // 136: mandau.agent.v1.FetchRequest
+	(*Artifact)(nil),                       // 137: mandau.agent.v1.Artifact
+	(*PutArtifactRequest)(nil),             // 138: mandau.agent.v1.PutArtifactRequest
+	(*GetArtifactRequest)(nil),             // 139: mandau.agent.v1.GetArtifactRequest
+	(*ListArtifactsRequest)(nil),           // 140: mandau.agent.v1.ListArtifactsRequest
+	(*ListArtifactsResponse)(nil),          // 141: mandau.agent.v1.ListArtifactsResponse
+	(*DeleteArtifactRequest)(nil),          // 142: mandau.agent.v1.DeleteArtifactRequest
+	(*CollectArtifactGarbageRequest)(nil),  // 143: mandau.agent.v1.CollectArtifactGarbageRequest
+	(*CollectArtifactGarbageResponse)(nil), // 144: mandau.agent.v1.CollectArtifactGarbageResponse
+	(*FetchArtifactRequest)(nil),           // 145: mandau.agent.v1.FetchArtifactRequest
+	(*ArtifactChunk)(nil),                  // 146: mandau.agent.v1.ArtifactChunk
+	(*GetProfileRequest)(nil),              // 147: mandau.agent.v1.GetProfileRequest
+	(*ProfileChunk)(nil),                   // 148: mandau.agent.v1.ProfileChunk
+	(*ListConnectionsRequest)(nil),         // 149: mandau.agent.v1.ListConnectionsRequest
+	(*ListConnectionsResponse)(nil),        // 150: mandau.agent.v1.ListConnectionsResponse
+	(*AgentChannel)(nil),                   // 151: mandau.agent.v1.AgentChannel
+	(*GetRegistryRequest)(nil),             // 152: mandau.agent.v1.GetRegistryRequest
+	(*CoreRegistry)(nil),                   // 153: mandau.agent.v1.CoreRegistry
+	(*RegistryAgent)(nil),                  // 154: mandau.agent.v1.RegistryAgent
+	(*CorePlugin)(nil),                     // 155: mandau.agent.v1.CorePlugin
+	(*GetConfigRequest)(nil),               // 156: mandau.agent.v1.GetConfigRequest
+	(*ConfigDump)(nil),                     // 157: mandau.agent.v1.ConfigDump
+	(*Operation)(nil),                      // 158: mandau.agent.v1.Operation
+	(*OperationEvent)(nil),                 // 159: mandau.agent.v1.OperationEvent
+	(*HeartbeatRequest)(nil),               // 160: mandau.agent.v1.HeartbeatRequest
+	(*HeartbeatResponse)(nil),              // 161: mandau.agent.v1.HeartbeatResponse
+	(*AgentInstruction)(nil),               // 162: mandau.agent.v1.AgentInstruction
+	(*ConfigInstruction)(nil),              // 163: mandau.agent.v1.ConfigInstruction
+	(*DrainInstruction)(nil),               // 164: mandau.agent.v1.DrainInstruction
+	(*QueueAgentInstructionRequest)(nil),   // 165: mandau.agent.v1.QueueAgentInstructionRequest
+	(*ListAgentInstructionsRequest)(nil),   // 166: mandau.agent.v1.ListAgentInstructionsRequest
+	(*ListAgentInstructionsResponse)(nil),  // 167: mandau.agent.v1.ListAgentInstructionsResponse
+	(*CancelAgentInstructionRequest)(nil),  // 168: mandau.agent.v1.CancelAgentInstructionRequest
+	(*InstructionResult)(nil),              // 169: mandau.agent.v1.InstructionResult
+	(*CapabilitiesRequest)(nil),            // 170: mandau.agent.v1.CapabilitiesRequest
+	(*CapabilitiesResponse)(nil),           // 171: mandau.agent.v1.CapabilitiesResponse
+	(*HealthRequest)(nil),                  // 172: mandau.agent.v1.HealthRequest
+	(*HealthResponse)(nil),                 // 173: mandau.agent.v1.HealthResponse
+	(*ListStacksRequest)(nil),              // 174: mandau.agent.v1.ListStacksRequest
+	(*ListStacksResponse)(nil),             // 175: mandau.agent.v1.ListStacksResponse
+	(*GetStackRequest)(nil),                // 176: mandau.agent.v1.GetStackRequest
+	(*GetStackResponse)(nil),               // 177: mandau.agent.v1.GetStackResponse
+	(*RemoveStackRequest)(nil),             // 178: mandau.agent.v1.RemoveStackRequest
+	(*GetStackLogsRequest)(nil),            // 179: mandau.agent.v1.GetStackLogsRequest
+	(*LogBatch)(nil),                       // 180: mandau.agent.v1.LogBatch
+	(*ListContainersRequest)(nil),          // 181: mandau.agent.v1.ListContainersRequest
+	(*ListContainersResponse)(nil),         // 182: mandau.agent.v1.ListContainersResponse
+	(*InspectContainerRequest)(nil),        // 183: mandau.agent.v1.InspectContainerRequest
+	(*InspectContainerResponse)(nil),       // 184: mandau.agent.v1.InspectContainerResponse
+	(*StreamLogsRequest)(nil),              // 185: mandau.agent.v1.StreamLogsRequest
+	(*GetStatsRequest)(nil),                // 186: mandau.agent.v1.GetStatsRequest
+	(*StartContainerRequest)(nil),          // 187: mandau.agent.v1.StartContainerRequest
+	(*StartContainerResponse)(nil),         // 188: mandau.agent.v1.StartContainerResponse
+	(*StopContainerRequest)(nil),           // 189: mandau.agent.v1.StopContainerRequest
+	(*StopContainerResponse)(nil),          // 190: mandau.agent.v1.StopContainerResponse
+	(*RestartContainerRequest)(nil),        // 191: mandau.agent.v1.RestartContainerRequest
+	(*RestartContainerResponse)(nil),       // 192: mandau.agent.v1.RestartContainerResponse
+	(*WriteFileResponse)(nil),              // 193: mandau.agent.v1.WriteFileResponse
+	(*DeleteFileRequest)(nil),              // 194: mandau.agent.v1.DeleteFileRequest
+	(*DeleteFileResponse)(nil),             // 195: mandau.agent.v1.DeleteFileResponse
+	(*CreateDirectoryRequest)(nil),         // 196: mandau.agent.v1.CreateDirectoryRequest
+	(*CreateDirectoryResponse)(nil),        // 197: mandau.agent.v1.CreateDirectoryResponse
+	(*GetOperationRequest)(nil),            // 198: mandau.agent.v1.GetOperationRequest
+	(*ListOperationsRequest)(nil),          // 199: mandau.agent.v1.ListOperationsRequest
+	(*ListOperationsResponse)(nil),         // 200: mandau.agent.v1.ListOperationsResponse
+	(*CancelOperationRequest)(nil),         // 201: mandau.agent.v1.CancelOperationRequest
+	(*CancelOperationResponse)(nil),        // 202: mandau.agent.v1.CancelOperationResponse
+	(*StreamOperationRequest)(nil),         // 203: mandau.agent.v1.StreamOperationRequest
+	(*CPUStats)(nil),                       // 204: mandau.agent.v1.CPUStats
+	(*MemoryStats)(nil),                    // 205: mandau.agent.v1.MemoryStats
+	(*NetworkStats)(nil),                   // 206: mandau.agent.v1.NetworkStats
+	(*BlockIOStats)(nil),                   // 207: mandau.agent.v1.BlockIOStats
+	(*GetEnrollmentCARequest)(nil),         // 208: mandau.agent.v1.GetEnrollmentCARequest
+	(*GetEnrollmentCAResponse)(nil),        // 209: mandau.agent.v1.GetEnrollmentCAResponse
+	(*EnrollRequest)(nil),                  // 210: mandau.agent.v1.EnrollRequest
+	(*EnrollResponse)(nil),                 // 211: mandau.agent.v1.EnrollResponse
+	nil,                                    // 212: mandau.agent.v1.ListAgentsRequest.LabelsEntry
+	nil,                                    // 213: mandau.agent.v1.WatchAgentsRequest.LabelsEntry
+	nil,                                    // 214: mandau.agent.v1.UpdateAgentLabelsRequest.SetEntry
+	nil,                                    // 215: mandau.agent.v1.Agent.LabelsEntry
+	nil,                                    // 216: mandau.agent.v1.AgentGroup.SelectorEntry
+	nil,                                    // 217: mandau.agent.v1.UpdateAgentGroupRequest.SelectorEntry
+	nil,                                    // 218: mandau.agent.v1.ClusterStatus.AgentsEntry
+	nil,                                    // 219: mandau.agent.v1.ResourceReport.AgentErrorsEntry
+	nil,                                    // 220: mandau.agent.v1.StackUsage.LabelsEntry
+	nil,                                    // 221: mandau.agent.v1.PatchCompliance.AgentErrorsEntry
+	nil,                                    // 222: mandau.agent.v1.RunFleetCommandRequest.LabelsEntry
+	nil,                                    // 223: mandau.agent.v1.FleetCommandReport.AgentErrorsEntry
+	nil,                                    // 224: mandau.agent.v1.GetInventoryRequest.LabelsEntry
+	nil,                                    // 225: mandau.agent.v1.Inventory.AgentErrorsEntry
+	nil,                                    // 226: mandau.agent.v1.AgentInventory.ErrorsEntry
+	nil,                                    // 227: mandau.agent.v1.RegisterRequest.LabelsEntry
+	nil,                                    // 228: mandau.agent.v1.Stack.LabelsEntry
+	nil,                                    // 229: mandau.agent.v1.ApplyStackRequest.EnvVarsEntry
+	nil,                                    // 230: mandau.agent.v1.ApplyStackRequest.LabelsEntry
+	nil,                                    // 231: mandau.agent.v1.StackExport.EnvVarsEntry
+	nil,                                    // 232: mandau.agent.v1.StackExport.LabelsEntry
+	nil,                                    // 233: mandau.agent.v1.Container.LabelsEntry
+	nil,                                    // 234: mandau.agent.v1.ExecStart.EnvEntry
+	nil,                                    // 235: mandau.agent.v1.RegistryAgent.LabelsEntry
+	nil,                                    // 236: mandau.agent.v1.RegistryAgent.ReportedLabelsEntry
+	nil,                                    // 237: mandau.agent.v1.RegistryAgent.OperatorLabelsEntry
+	nil,                                    // 238: mandau.agent.v1.Operation.MetadataEntry
+	nil,                                    // 239: mandau.agent.v1.HeartbeatRequest.StatusEntry
+	nil,                                    // 240: mandau.agent.v1.HealthResponse.StatusEntry
+	nil,                                    // 241: mandau.agent.v1.ListStacksRequest.LabelsEntry
+	nil,                                    // 242: mandau.agent.v1.ListStacksResponse.AgentErrorsEntry
+	nil,                                    // 243: mandau.agent.v1.EnrollResponse.LabelsEntry
+	(*timestamppb.Timestamp)(nil),          // 244: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),            // 245: google.protobuf.Duration
 }
 var file_api_v1_agent_proto_depIdxs = []int32{
-	5,  // 0: mandau.agent.v1.ListAgentsResponse.agents:type_name -> mandau.agent.v1.Agent
-	68, // 1: mandau.agent.v1.Agent.labels:type_name -> mandau.agent.v1.Agent.LabelsEntry
-	77, // 2: mandau.agent.v1.Agent.last_seen:type_name -> google.protobuf.Timestamp
-	69, // 3: mandau.agent.v1.RegisterRequest.labels:type_name -> mandau.agent.v1.RegisterRequest.LabelsEntry
-	78, // 4: mandau.agent.v1.RegisterResponse.heartbeat_interval:type_name -> google.protobuf.Duration
-	0,  // 5: mandau.agent.v1.Stack.state:type_name -> mandau.agent.v1.StackState
-	13, // 6: mandau.agent.v1.Stack.containers:type_name -> mandau.agent.v1.Container
-	77, // 7: mandau.agent.v1.Stack.created_at:type_name -> google.protobuf.Timestamp
-	77, // 8: mandau.agent.v1.Stack.updated_at:type_name -> google.protobuf.Timestamp
-	70, // 9: mandau.agent.v1.Stack.labels:type_name -> mandau.agent.v1.Stack.LabelsEntry
-	71, // 10: mandau.agent.v1.ApplyStackRequest.env_vars:type_name -> mandau.agent.v1.ApplyStackRequest.EnvVarsEntry
-	12, // 11: mandau.agent.v1.DiffStackResponse.services:type_name -> mandau.agent.v1.ServiceDiff
-	1,  // 12: mandau.agent.v1.ServiceDiff.action:type_name -> mandau.agent.v1.DiffAction
-	77, // 13: mandau.agent.v1.Container.created:type_name -> google.protobuf.Timestamp
-	72, // 14: mandau.agent.v1.Container.labels:type_name -> mandau.agent.v1.Container.LabelsEntry
-	14, // 15: mandau.agent.v1.Container.ports:type_name -> mandau.agent.v1.Port
-	16, // 16: mandau.agent.v1.ExecRequest.start:type_name -> mandau.agent.v1.ExecStart
-	17, // 17: mandau.agent.v1.ExecRequest.resize:type_name -> mandau.agent.v1.ExecResize
-	73, // 18: mandau.agent.v1.ExecStart.env:type_name -> mandau.agent.v1.ExecStart.EnvEntry
-	77, // 19: mandau.agent.v1.LogEntry.timestamp:type_name -> google.protobuf.Timestamp
-	77, // 20: mandau.agent.v1.ContainerStats.timestamp:type_name -> google.protobuf.Timestamp
-	64, // 21: mandau.agent.v1.ContainerStats.cpu:type_name -> mandau.agent.v1.CPUStats
-	65, // 22: mandau.agent.v1.ContainerStats.memory:type_name -> mandau.agent.v1.MemoryStats
-	66, // 23: mandau.agent.v1.ContainerStats.network:type_name -> mandau.agent.v1.NetworkStats
-	67, // 24: mandau.agent.v1.ContainerStats.block_io:type_name -> mandau.agent.v1.BlockIOStats
-	23, // 25: mandau.agent.v1.ListFilesResponse.files:type_name -> mandau.agent.v1.FileInfo
-	77, // 26: mandau.agent.v1.FileInfo.modified:type_name -> google.protobuf.Timestamp
-	23, // 27: mandau.agent.v1.ReadFileResponse.info:type_name -> mandau.agent.v1.FileInfo
-	2,  // 28: mandau.agent.v1.Operation.state:type_name -> mandau.agent.v1.OperationState
-	77, // 29: mandau.agent.v1.Operation.created_at:type_name -> google.protobuf.Timestamp
-	77, // 30: mandau.agent.v1.Operation.completed_at:type_name -> google.protobuf.Timestamp
-	74, // 31: mandau.agent.v1.Operation.metadata:type_name -> mandau.agent.v1.Operation.MetadataEntry
-	2,  // 32: mandau.agent.v1.OperationEvent.state:type_name -> mandau.agent.v1.OperationState
-	77, // 33: mandau.agent.v1.OperationEvent.timestamp:type_name -> google.protobuf.Timestamp
-	75, // 34: mandau.agent.v1.HeartbeatRequest.status:type_name -> mandau.agent.v1.HeartbeatRequest.StatusEntry
-	78, // 35: mandau.agent.v1.HeartbeatResponse.next_heartbeat:type_name -> google.protobuf.Duration
-	76, // 36: mandau.agent.v1.HealthResponse.status:type_name -> mandau.agent.v1.HealthResponse.StatusEntry
-	8,  // 37: mandau.agent.v1.ListStacksResponse.stacks:type_name -> mandau.agent.v1.Stack
-	8,  // 38: mandau.agent.v1.GetStackResponse.stack:type_name -> mandau.agent.v1.Stack
-	13, // 39: mandau.agent.v1.ListContainersResponse.containers:type_name -> mandau.agent.v1.Container
-	13, // 40: mandau.agent.v1.InspectContainerResponse.container:type_name -> mandau.agent.v1.Container
-	3,  // 41: mandau.agent.v1.CoreService.ListAgents:input_type -> mandau.agent.v1.ListAgentsRequest
-	6,  // 42: mandau.agent.v1.CoreService.RegisterAgent:input_type -> mandau.agent.v1.RegisterRequest
-	29, // 43: mandau.agent.v1.CoreService.Heartbeat:input_type -> mandau.agent.v1.HeartbeatRequest
-	6,  // 44: mandau.agent.v1.AgentService.Register:input_type -> mandau.agent.v1.RegisterRequest
-	29, // 45: mandau.agent.v1.AgentService.Heartbeat:input_type -> mandau.agent.v1.HeartbeatRequest
-	31, // 46: mandau.agent.v1.AgentService.GetCapabilities:input_type -> mandau.agent.v1.CapabilitiesRequest
-	33, // 47: mandau.agent.v1.AgentService.GetHealth:input_type -> mandau.agent.v1.HealthRequest
-	35, // 48: mandau.agent.v1.StackService.ListStacks:input_type -> mandau.agent.v1.ListStacksRequest
-	37, // 49: mandau.agent.v1.StackService.GetStack:input_type -> mandau.agent.v1.GetStackRequest
-	9,  // 50: mandau.agent.v1.StackService.ApplyStack:input_type -> mandau.agent.v1.ApplyStackRequest
-	39, // 51: mandau.agent.v1.StackService.RemoveStack:input_type -> mandau.agent.v1.RemoveStackRequest
-	10, // 52: mandau.agent.v1.StackService.DiffStack:input_type -> mandau.agent.v1.DiffStackRequest
-	40, // 53: mandau.agent.v1.StackService.GetStackLogs:input_type -> mandau.agent.v1.GetStackLogsRequest
-	41, // 54: mandau.agent.v1.ContainerService.ListContainers:input_type -> mandau.agent.v1.ListContainersRequest
-	43, // 55: mandau.agent.v1.ContainerService.InspectContainer:input_type -> mandau.agent.v1.InspectContainerRequest
-	45, // 56: mandau.agent.v1.ContainerService.StreamLogs:input_type -> mandau.agent.v1.StreamLogsRequest
-	15, // 57: mandau.agent.v1.ContainerService.Exec:input_type -> mandau.agent.v1.ExecRequest
-	46, // 58: mandau.agent.v1.ContainerService.GetStats:input_type -> mandau.agent.v1.GetStatsRequest
-	47, // 59: mandau.agent.v1.ContainerService.StartContainer:input_type -> mandau.agent.v1.StartContainerRequest
-	49, // 60: mandau.agent.v1.ContainerService.StopContainer:input_type -> mandau.agent.v1.StopContainerRequest
-	51, // 61: mandau.agent.v1.ContainerService.RestartContainer:input_type -> mandau.agent.v1.RestartContainerRequest
-	21, // 62: mandau.agent.v1.FilesystemService.ListFiles:input_type -> mandau.agent.v1.ListFilesRequest
-	24, // 63: mandau.agent.v1.FilesystemService.ReadFile:input_type -> mandau.agent.v1.ReadFileRequest
-	26, // 64: mandau.agent.v1.FilesystemService.WriteFile:input_type -> mandau.agent.v1.WriteFileRequest
-	54, // 65: mandau.agent.v1.FilesystemService.DeleteFile:input_type -> mandau.agent.v1.DeleteFileRequest
-	56, // 66: mandau.agent.v1.FilesystemService.CreateDirectory:input_type -> mandau.agent.v1.CreateDirectoryRequest
-	58, // 67: mandau.agent.v1.OperationsService.GetOperation:input_type -> mandau.agent.v1.GetOperationRequest
-	59, // 68: mandau.agent.v1.OperationsService.ListOperations:input_type -> mandau.agent.v1.ListOperationsRequest
-	61, // 69: mandau.agent.v1.OperationsService.CancelOperation:input_type -> mandau.agent.v1.CancelOperationRequest
-	63, // 70: mandau.agent.v1.OperationsService.StreamOperation:input_type -> mandau.agent.v1.StreamOperationRequest
-	4,  // 71: mandau.agent.v1.CoreService.ListAgents:output_type -> mandau.agent.v1.ListAgentsResponse
-	7,  // 72: mandau.agent.v1.CoreService.RegisterAgent:output_type -> mandau.agent.v1.RegisterResponse
-	30, // 73: mandau.agent.v1.CoreService.Heartbeat:output_type -> mandau.agent.v1.HeartbeatResponse
-	7,  // 74: mandau.agent.v1.AgentService.Register:output_type -> mandau.agent.v1.RegisterResponse
-	30, // 75: mandau.agent.v1.AgentService.Heartbeat:output_type -> mandau.agent.v1.HeartbeatResponse
-	32, // 76: mandau.agent.v1.AgentService.GetCapabilities:output_type -> mandau.agent.v1.CapabilitiesResponse
-	34, // 77: mandau.agent.v1.AgentService.GetHealth:output_type -> mandau.agent.v1.HealthResponse
-	36, // 78: mandau.agent.v1.StackService.ListStacks:output_type -> mandau.agent.v1.ListStacksResponse
-	38, // 79: mandau.agent.v1.StackService.GetStack:output_type -> mandau.agent.v1.GetStackResponse
-	28, // 80: mandau.agent.v1.StackService.ApplyStack:output_type -> mandau.agent.v1.OperationEvent
-	28, // 81: mandau.agent.v1.StackService.RemoveStack:output_type -> mandau.agent.v1.OperationEvent
-	11, // 82: mandau.agent.v1.StackService.DiffStack:output_type -> mandau.agent.v1.DiffStackResponse
-	19, // 83: mandau.agent.v1.StackService.GetStackLogs:output_type -> mandau.agent.v1.LogEntry
-	42, // 84: mandau.agent.v1.ContainerService.ListContainers:output_type -> mandau.agent.v1.ListContainersResponse
-	44, // 85: mandau.agent.v1.ContainerService.InspectContainer:output_type -> mandau.agent.v1.InspectContainerResponse
-	19, // 86: mandau.agent.v1.ContainerService.StreamLogs:output_type -> mandau.agent.v1.LogEntry
-	18, // 87: mandau.agent.v1.ContainerService.Exec:output_type -> mandau.agent.v1.ExecResponse
-	20, // 88: mandau.agent.v1.ContainerService.GetStats:output_type -> mandau.agent.v1.ContainerStats
-	48, // 89: mandau.agent.v1.ContainerService.StartContainer:output_type -> mandau.agent.v1.StartContainerResponse
-	50, // 90: mandau.agent.v1.ContainerService.StopContainer:output_type -> mandau.agent.v1.StopContainerResponse
-	52, // 91: mandau.agent.v1.ContainerService.RestartContainer:output_type -> mandau.agent.v1.RestartContainerResponse
-	22, // 92: mandau.agent.v1.FilesystemService.ListFiles:output_type -> mandau.agent.v1.ListFilesResponse
-	25, // 93: mandau.agent.v1.FilesystemService.ReadFile:output_type -> mandau.agent.v1.ReadFileResponse
-	53, // 94: mandau.agent.v1.FilesystemService.WriteFile:output_type -> mandau.agent.v1.WriteFileResponse
-	55, // 95: mandau.agent.v1.FilesystemService.DeleteFile:output_type -> mandau.agent.v1.DeleteFileResponse
-	57, // 96: mandau.agent.v1.FilesystemService.CreateDirectory:output_type -> mandau.agent.v1.CreateDirectoryResponse
-	27, // 97: mandau.agent.v1.OperationsService.GetOperation:output_type -> mandau.agent.v1.Operation
-	60, // 98: mandau.agent.v1.OperationsService.ListOperations:output_type -> mandau.agent.v1.ListOperationsResponse
-	62, // 99: mandau.agent.v1.OperationsService.CancelOperation:output_type -> mandau.agent.v1.CancelOperationResponse
-	28, // 100: mandau.agent.v1.OperationsService.StreamOperation:output_type -> mandau.agent.v1.OperationEvent
-	71, // [71:101] is the sub-list for method output_type
-	41, // [41:71] is the sub-list for method input_type
-	41, // [41:41] is the sub-list for extension type_name
-	41, // [41:41] is the sub-list for extension extendee
-	0,  // [0:41] is the sub-list for field type_name
+	212, // 0: mandau.agent.v1.ListAgentsRequest.labels:type_name -> mandau.agent.v1.ListAgentsRequest.LabelsEntry
+	17,  // 1: mandau.agent.v1.ListAgentsResponse.agents:type_name -> mandau.agent.v1.Agent
+	213, // 2: mandau.agent.v1.WatchAgentsRequest.labels:type_name -> mandau.agent.v1.WatchAgentsRequest.LabelsEntry
+	0,   // 3: mandau.agent.v1.AgentDelta.kind:type_name -> mandau.agent.v1.AgentDeltaKind
+	17,  // 4: mandau.agent.v1.AgentDelta.agent:type_name -> mandau.agent.v1.Agent
+	244, // 5: mandau.agent.v1.AgentDelta.time:type_name -> google.protobuf.Timestamp
+	214, // 6: mandau.agent.v1.UpdateAgentLabelsRequest.set:type_name -> mandau.agent.v1.UpdateAgentLabelsRequest.SetEntry
+	17,  // 7: mandau.agent.v1.UpdateAgentLabelsResponse.agent:type_name -> mandau.agent.v1.Agent
+	245, // 8: mandau.agent.v1.SetAgentMaintenanceRequest.duration:type_name -> google.protobuf.Duration
+	17,  // 9: mandau.agent.v1.SetAgentMaintenanceResponse.agent:type_name -> mandau.agent.v1.Agent
+	244, // 10: mandau.agent.v1.Maintenance.since:type_name -> google.protobuf.Timestamp
+	244, // 11: mandau.agent.v1.Maintenance.until:type_name -> google.protobuf.Timestamp
+	215, // 12: mandau.agent.v1.Agent.labels:type_name -> mandau.agent.v1.Agent.LabelsEntry
+	244, // 13: mandau.agent.v1.Agent.last_seen:type_name -> google.protobuf.Timestamp
+	16,  // 14: mandau.agent.v1.Agent.maintenance:type_name -> mandau.agent.v1.Maintenance
+	245, // 15: mandau.agent.v1.Agent.clock_skew:type_name -> google.protobuf.Duration
+	18,  // 16: mandau.agent.v1.Agent.circuit:type_name -> mandau.agent.v1.AgentCircuit
+	76,  // 17: mandau.agent.v1.Agent.unavailable_plugins:type_name -> mandau.agent.v1.PluginAvailability
+	244, // 18: mandau.agent.v1.AgentCircuit.since:type_name -> google.protobuf.Timestamp
+	216, // 19: mandau.agent.v1.AgentGroup.selector:type_name -> mandau.agent.v1.AgentGroup.SelectorEntry
+	244, // 20: mandau.agent.v1.AgentGroup.created_at:type_name -> google.protobuf.Timestamp
+	19,  // 21: mandau.agent.v1.CreateAgentGroupRequest.group:type_name -> mandau.agent.v1.AgentGroup
+	19,  // 22: mandau.agent.v1.GetAgentGroupResponse.group:type_name -> mandau.agent.v1.AgentGroup
+	17,  // 23: mandau.agent.v1.GetAgentGroupResponse.members:type_name -> mandau.agent.v1.Agent
+	19,  // 24: mandau.agent.v1.ListAgentGroupsResponse.groups:type_name -> mandau.agent.v1.AgentGroup
+	217, // 25: mandau.agent.v1.UpdateAgentGroupRequest.selector:type_name -> mandau.agent.v1.UpdateAgentGroupRequest.SelectorEntry
+	1,   // 26: mandau.agent.v1.Approval.state:type_name -> mandau.agent.v1.ApprovalState
+	244, // 27: mandau.agent.v1.Approval.created_at:type_name -> google.protobuf.Timestamp
+	244, // 28: mandau.agent.v1.Approval.reviewed_at:type_name -> google.protobuf.Timestamp
+	244, // 29: mandau.agent.v1.Approval.expires_at:type_name -> google.protobuf.Timestamp
+	1,   // 30: mandau.agent.v1.ListApprovalsRequest.state:type_name -> mandau.agent.v1.ApprovalState
+	28,  // 31: mandau.agent.v1.ListApprovalsResponse.approvals:type_name -> mandau.agent.v1.Approval
+	244, // 32: mandau.agent.v1.BreakGlassGrant.granted_at:type_name -> google.protobuf.Timestamp
+	244, // 33: mandau.agent.v1.BreakGlassGrant.expires_at:type_name -> google.protobuf.Timestamp
+	244, // 34: mandau.agent.v1.BreakGlassGrant.revoked_at:type_name -> google.protobuf.Timestamp
+	245, // 35: mandau.agent.v1.GrantBreakGlassRequest.ttl:type_name -> google.protobuf.Duration
+	32,  // 36: mandau.agent.v1.ListBreakGlassGrantsResponse.grants:type_name -> mandau.agent.v1.BreakGlassGrant
+	244, // 37: mandau.agent.v1.ClusterStatus.started_at:type_name -> google.protobuf.Timestamp
+	218, // 38: mandau.agent.v1.ClusterStatus.agents:type_name -> mandau.agent.v1.ClusterStatus.AgentsEntry
+	44,  // 39: mandau.agent.v1.ClusterStatus.freeze:type_name -> mandau.agent.v1.FreezeState
+	42,  // 40: mandau.agent.v1.ClusterStatus.running:type_name -> mandau.agent.v1.ClusterOperation
+	42,  // 41: mandau.agent.v1.ClusterStatus.failures:type_name -> mandau.agent.v1.ClusterOperation
+	43,  // 42: mandau.agent.v1.ClusterStatus.expiring_certificates:type_name -> mandau.agent.v1.ExpiringCertificate
+	41,  // 43: mandau.agent.v1.ClusterStatus.clock_skew:type_name -> mandau.agent.v1.AgentClockSkew
+	18,  // 44: mandau.agent.v1.ClusterStatus.open_circuits:type_name -> mandau.agent.v1.AgentCircuit
+	245, // 45: mandau.agent.v1.AgentClockSkew.skew:type_name -> google.protobuf.Duration
+	244, // 46: mandau.agent.v1.ClusterOperation.started_at:type_name -> google.protobuf.Timestamp
+	244, // 47: mandau.agent.v1.ClusterOperation.finished_at:type_name -> google.protobuf.Timestamp
+	244, // 48: mandau.agent.v1.ExpiringCertificate.not_after:type_name -> google.protobuf.Timestamp
+	244, // 49: mandau.agent.v1.FreezeState.set_at:type_name -> google.protobuf.Timestamp
+	47,  // 50: mandau.agent.v1.QuotaUsage.agents:type_name -> mandau.agent.v1.AgentQuotaUsage
+	74,  // 51: mandau.agent.v1.QuotaUsage.namespaces:type_name -> mandau.agent.v1.NamespaceQuotaUsage
+	50,  // 52: mandau.agent.v1.DiagnoseResponse.checks:type_name -> mandau.agent.v1.DiagnosticCheck
+	244, // 53: mandau.agent.v1.DiagnoseResponse.time:type_name -> google.protobuf.Timestamp
+	2,   // 54: mandau.agent.v1.DiagnosticCheck.status:type_name -> mandau.agent.v1.CheckStatus
+	244, // 55: mandau.agent.v1.AgentConnectionReport.last_heartbeat:type_name -> google.protobuf.Timestamp
+	53,  // 56: mandau.agent.v1.AgentConnectionReport.round_trips:type_name -> mandau.agent.v1.RoundTrips
+	54,  // 57: mandau.agent.v1.AgentConnectionReport.server_certificate:type_name -> mandau.agent.v1.PeerCertificate
+	54,  // 58: mandau.agent.v1.AgentConnectionReport.client_certificate:type_name -> mandau.agent.v1.PeerCertificate
+	56,  // 59: mandau.agent.v1.AgentConnectionReport.reverse:type_name -> mandau.agent.v1.CoreConnectivity
+	50,  // 60: mandau.agent.v1.AgentConnectionReport.checks:type_name -> mandau.agent.v1.DiagnosticCheck
+	245, // 61: mandau.agent.v1.RoundTrips.min:type_name -> google.protobuf.Duration
+	245, // 62: mandau.agent.v1.RoundTrips.avg:type_name -> google.protobuf.Duration
+	245, // 63: mandau.agent.v1.RoundTrips.max:type_name -> google.protobuf.Duration
+	244, // 64: mandau.agent.v1.PeerCertificate.not_after:type_name -> google.protobuf.Timestamp
+	245, // 65: mandau.agent.v1.CoreConnectivity.handshake:type_name -> google.protobuf.Duration
+	54,  // 66: mandau.agent.v1.CoreConnectivity.core_certificate:type_name -> mandau.agent.v1.PeerCertificate
+	244, // 67: mandau.agent.v1.ResourceReport.generated_at:type_name -> google.protobuf.Timestamp
+	59,  // 68: mandau.agent.v1.ResourceReport.stacks:type_name -> mandau.agent.v1.StackUsage
+	219, // 69: mandau.agent.v1.ResourceReport.agent_errors:type_name -> mandau.agent.v1.ResourceReport.AgentErrorsEntry
+	3,   // 70: mandau.agent.v1.StackUsage.state:type_name -> mandau.agent.v1.StackState
+	91,  // 71: mandau.agent.v1.StackUsage.owner:type_name -> mandau.agent.v1.StackOwner
+	220, // 72: mandau.agent.v1.StackUsage.labels:type_name -> mandau.agent.v1.StackUsage.LabelsEntry
+	244, // 73: mandau.agent.v1.PatchCompliance.generated_at:type_name -> google.protobuf.Timestamp
+	71,  // 74: mandau.agent.v1.PatchCompliance.agents:type_name -> mandau.agent.v1.AgentPatchStatus
+	221, // 75: mandau.agent.v1.PatchCompliance.agent_errors:type_name -> mandau.agent.v1.PatchCompliance.AgentErrorsEntry
+	222, // 76: mandau.agent.v1.RunFleetCommandRequest.labels:type_name -> mandau.agent.v1.RunFleetCommandRequest.LabelsEntry
+	245, // 77: mandau.agent.v1.RunFleetCommandRequest.timeout:type_name -> google.protobuf.Duration
+	244, // 78: mandau.agent.v1.FleetCommandReport.started_at:type_name -> google.protobuf.Timestamp
+	245, // 79: mandau.agent.v1.FleetCommandReport.duration:type_name -> google.protobuf.Duration
+	64,  // 80: mandau.agent.v1.FleetCommandReport.results:type_name -> mandau.agent.v1.CommandResult
+	223, // 81: mandau.agent.v1.FleetCommandReport.agent_errors:type_name -> mandau.agent.v1.FleetCommandReport.AgentErrorsEntry
+	245, // 82: mandau.agent.v1.CommandResult.duration:type_name -> google.protobuf.Duration
+	224, // 83: mandau.agent.v1.GetInventoryRequest.labels:type_name -> mandau.agent.v1.GetInventoryRequest.LabelsEntry
+	244, // 84: mandau.agent.v1.Inventory.generated_at:type_name -> google.protobuf.Timestamp
+	67,  // 85: mandau.agent.v1.Inventory.agents:type_name -> mandau.agent.v1.AgentInventory
+	225, // 86: mandau.agent.v1.Inventory.agent_errors:type_name -> mandau.agent.v1.Inventory.AgentErrorsEntry
+	17,  // 87: mandau.agent.v1.AgentInventory.agent:type_name -> mandau.agent.v1.Agent
+	68,  // 88: mandau.agent.v1.AgentInventory.host:type_name -> mandau.agent.v1.InventoryHost
+	88,  // 89: mandau.agent.v1.AgentInventory.stacks:type_name -> mandau.agent.v1.Stack
+	69,  // 90: mandau.agent.v1.AgentInventory.virtual_hosts:type_name -> mandau.agent.v1.InventoryVirtualHost
+	70,  // 91: mandau.agent.v1.AgentInventory.certificates:type_name -> mandau.agent.v1.InventoryCertificate
+	226, // 92: mandau.agent.v1.AgentInventory.errors:type_name -> mandau.agent.v1.AgentInventory.ErrorsEntry
+	72,  // 93: mandau.agent.v1.AgentPatchStatus.status:type_name -> mandau.agent.v1.PatchStatus
+	73,  // 94: mandau.agent.v1.PatchStatus.security_updates:type_name -> mandau.agent.v1.PackageUpdate
+	244, // 95: mandau.agent.v1.PatchStatus.checked_at:type_name -> google.protobuf.Timestamp
+	227, // 96: mandau.agent.v1.RegisterRequest.labels:type_name -> mandau.agent.v1.RegisterRequest.LabelsEntry
+	82,  // 97: mandau.agent.v1.RegisterRequest.plugins:type_name -> mandau.agent.v1.InstalledPlugin
+	76,  // 98: mandau.agent.v1.RegisterRequest.plugin_availability:type_name -> mandau.agent.v1.PluginAvailability
+	245, // 99: mandau.agent.v1.RegisterResponse.heartbeat_interval:type_name -> google.protobuf.Duration
+	80,  // 100: mandau.agent.v1.PluginIndex.plugins:type_name -> mandau.agent.v1.IndexedPlugin
+	244, // 101: mandau.agent.v1.PluginIndex.published:type_name -> google.protobuf.Timestamp
+	244, // 102: mandau.agent.v1.InstalledPlugin.installed_at:type_name -> google.protobuf.Timestamp
+	82,  // 103: mandau.agent.v1.ListInstalledPluginsResponse.plugins:type_name -> mandau.agent.v1.InstalledPlugin
+	86,  // 104: mandau.agent.v1.PluginDescription.permissions:type_name -> mandau.agent.v1.PluginPermissions
+	3,   // 105: mandau.agent.v1.Stack.state:type_name -> mandau.agent.v1.StackState
+	114, // 106: mandau.agent.v1.Stack.containers:type_name -> mandau.agent.v1.Container
+	244, // 107: mandau.agent.v1.Stack.created_at:type_name -> google.protobuf.Timestamp
+	244, // 108: mandau.agent.v1.Stack.updated_at:type_name -> google.protobuf.Timestamp
+	228, // 109: mandau.agent.v1.Stack.labels:type_name -> mandau.agent.v1.Stack.LabelsEntry
+	91,  // 110: mandau.agent.v1.Stack.owner:type_name -> mandau.agent.v1.StackOwner
+	90,  // 111: mandau.agent.v1.Stack.resources:type_name -> mandau.agent.v1.StackResources
+	89,  // 112: mandau.agent.v1.Stack.disk_usage:type_name -> mandau.agent.v1.StackDiskUsage
+	229, // 113: mandau.agent.v1.ApplyStackRequest.env_vars:type_name -> mandau.agent.v1.ApplyStackRequest.EnvVarsEntry
+	230, // 114: mandau.agent.v1.ApplyStackRequest.labels:type_name -> mandau.agent.v1.ApplyStackRequest.LabelsEntry
+	91,  // 115: mandau.agent.v1.ApplyStackRequest.owner:type_name -> mandau.agent.v1.StackOwner
+	245, // 116: mandau.agent.v1.ApplyStackRequest.queue_ttl:type_name -> google.protobuf.Duration
+	245, // 117: mandau.agent.v1.ApplyStackRequest.ready_timeout:type_name -> google.protobuf.Duration
+	101, // 118: mandau.agent.v1.ApplyStackRequest.hooks:type_name -> mandau.agent.v1.StackHooks
+	96,  // 119: mandau.agent.v1.ApplyStackRequest.files:type_name -> mandau.agent.v1.StackFiles
+	93,  // 120: mandau.agent.v1.ApplyStackRequest.compose_overrides:type_name -> mandau.agent.v1.StackContent
+	94,  // 121: mandau.agent.v1.ApplyStackRequest.project_files:type_name -> mandau.agent.v1.StackContents
+	93,  // 122: mandau.agent.v1.StackContents.files:type_name -> mandau.agent.v1.StackContent
+	95,  // 123: mandau.agent.v1.StackFiles.files:type_name -> mandau.agent.v1.StackFile
+	95,  // 124: mandau.agent.v1.DiffStackFilesRequest.files:type_name -> mandau.agent.v1.StackFile
+	93,  // 125: mandau.agent.v1.DiffStackRequest.compose_overrides:type_name -> mandau.agent.v1.StackContent
+	94,  // 126: mandau.agent.v1.DiffStackRequest.project_files:type_name -> mandau.agent.v1.StackContents
+	113, // 127: mandau.agent.v1.DiffStackResponse.services:type_name -> mandau.agent.v1.ServiceDiff
+	104, // 128: mandau.agent.v1.DiffStackResponse.files:type_name -> mandau.agent.v1.StackFileDiff
+	5,   // 129: mandau.agent.v1.StackFileDiff.action:type_name -> mandau.agent.v1.DiffAction
+	231, // 130: mandau.agent.v1.StackExport.env_vars:type_name -> mandau.agent.v1.StackExport.EnvVarsEntry
+	232, // 131: mandau.agent.v1.StackExport.labels:type_name -> mandau.agent.v1.StackExport.LabelsEntry
+	91,  // 132: mandau.agent.v1.StackExport.owner:type_name -> mandau.agent.v1.StackOwner
+	3,   // 133: mandau.agent.v1.StackExport.state:type_name -> mandau.agent.v1.StackState
+	114, // 134: mandau.agent.v1.StackExport.containers:type_name -> mandau.agent.v1.Container
+	244, // 135: mandau.agent.v1.StackExport.exported_at:type_name -> google.protobuf.Timestamp
+	93,  // 136: mandau.agent.v1.StackExport.compose_overrides:type_name -> mandau.agent.v1.StackContent
+	93,  // 137: mandau.agent.v1.StackExport.project_files:type_name -> mandau.agent.v1.StackContent
+	112, // 138: mandau.agent.v1.CollectStackGarbageResponse.orphans:type_name -> mandau.agent.v1.StackOrphan
+	244, // 139: mandau.agent.v1.GetStackEventsRequest.since:type_name -> google.protobuf.Timestamp
+	111, // 140: mandau.agent.v1.GetStackEventsResponse.events:type_name -> mandau.agent.v1.StackEvent
+	244, // 141: mandau.agent.v1.StackEvent.time:type_name -> google.protobuf.Timestamp
+	4,   // 142: mandau.agent.v1.StackOrphan.kind:type_name -> mandau.agent.v1.OrphanKind
+	5,   // 143: mandau.agent.v1.ServiceDiff.action:type_name -> mandau.agent.v1.DiffAction
+	244, // 144: mandau.agent.v1.Container.created:type_name -> google.protobuf.Timestamp
+	233, // 145: mandau.agent.v1.Container.labels:type_name -> mandau.agent.v1.Container.LabelsEntry
+	115, // 146: mandau.agent.v1.Container.ports:type_name -> mandau.agent.v1.Port
+	117, // 147: mandau.agent.v1.ExecRequest.start:type_name -> mandau.agent.v1.ExecStart
+	118, // 148: mandau.agent.v1.ExecRequest.resize:type_name -> mandau.agent.v1.ExecResize
+	234, // 149: mandau.agent.v1.ExecStart.env:type_name -> mandau.agent.v1.ExecStart.EnvEntry
+	244, // 150: mandau.agent.v1.LogEntry.timestamp:type_name -> google.protobuf.Timestamp
+	244, // 151: mandau.agent.v1.ContainerStats.timestamp:type_name -> google.protobuf.Timestamp
+	204, // 152: mandau.agent.v1.ContainerStats.cpu:type_name -> mandau.agent.v1.CPUStats
+	205, // 153: mandau.agent.v1.ContainerStats.memory:type_name -> mandau.agent.v1.MemoryStats
+	206, // 154: mandau.agent.v1.ContainerStats.network:type_name -> mandau.agent.v1.NetworkStats
+	207, // 155: mandau.agent.v1.ContainerStats.block_io:type_name -> mandau.agent.v1.BlockIOStats
+	124, // 156: mandau.agent.v1.ListFilesResponse.files:type_name -> mandau.agent.v1.FileInfo
+	244, // 157: mandau.agent.v1.FileInfo.modified:type_name -> google.protobuf.Timestamp
+	124, // 158: mandau.agent.v1.ReadFileResponse.info:type_name -> mandau.agent.v1.FileInfo
+	6,   // 159: mandau.agent.v1.TransferTarget.kind:type_name -> mandau.agent.v1.TransferKind
+	128, // 160: mandau.agent.v1.UploadRequest.target:type_name -> mandau.agent.v1.TransferTarget
+	128, // 161: mandau.agent.v1.TransferStatus.target:type_name -> mandau.agent.v1.TransferTarget
+	244, // 162: mandau.agent.v1.TransferStatus.updated_at:type_name -> google.protobuf.Timestamp
+	128, // 163: mandau.agent.v1.GetTransferRequest.target:type_name -> mandau.agent.v1.TransferTarget
+	124, // 164: mandau.agent.v1.ListBackupsResponse.backups:type_name -> mandau.agent.v1.FileInfo
+	128, // 165: mandau.agent.v1.FetchRequest.target:type_name -> mandau.agent.v1.TransferTarget
+	244, // 166: mandau.agent.v1.Artifact.created_at:type_name -> google.protobuf.Timestamp
+	244, // 167: mandau.agent.v1.Artifact.used_at:type_name -> google.protobuf.Timestamp
+	137, // 168: mandau.agent.v1.ListArtifactsResponse.artifacts:type_name -> mandau.agent.v1.Artifact
+	137, // 169: mandau.agent.v1.CollectArtifactGarbageResponse.removed:type_name -> mandau.agent.v1.Artifact
+	245, // 170: mandau.agent.v1.GetProfileRequest.duration:type_name -> google.protobuf.Duration
+	151, // 171: mandau.agent.v1.ListConnectionsResponse.connections:type_name -> mandau.agent.v1.AgentChannel
+	244, // 172: mandau.agent.v1.AgentChannel.last_seen:type_name -> google.protobuf.Timestamp
+	18,  // 173: mandau.agent.v1.AgentChannel.circuit:type_name -> mandau.agent.v1.AgentCircuit
+	244, // 174: mandau.agent.v1.AgentChannel.certificate_expires:type_name -> google.protobuf.Timestamp
+	245, // 175: mandau.agent.v1.AgentChannel.clock_skew:type_name -> google.protobuf.Duration
+	154, // 176: mandau.agent.v1.CoreRegistry.agents:type_name -> mandau.agent.v1.RegistryAgent
+	155, // 177: mandau.agent.v1.CoreRegistry.plugins:type_name -> mandau.agent.v1.CorePlugin
+	235, // 178: mandau.agent.v1.RegistryAgent.labels:type_name -> mandau.agent.v1.RegistryAgent.LabelsEntry
+	236, // 179: mandau.agent.v1.RegistryAgent.reported_labels:type_name -> mandau.agent.v1.RegistryAgent.ReportedLabelsEntry
+	237, // 180: mandau.agent.v1.RegistryAgent.operator_labels:type_name -> mandau.agent.v1.RegistryAgent.OperatorLabelsEntry
+	7,   // 181: mandau.agent.v1.Operation.state:type_name -> mandau.agent.v1.OperationState
+	244, // 182: mandau.agent.v1.Operation.created_at:type_name -> google.protobuf.Timestamp
+	244, // 183: mandau.agent.v1.Operation.completed_at:type_name -> google.protobuf.Timestamp
+	238, // 184: mandau.agent.v1.Operation.metadata:type_name -> mandau.agent.v1.Operation.MetadataEntry
+	7,   // 185: mandau.agent.v1.OperationEvent.state:type_name -> mandau.agent.v1.OperationState
+	244, // 186: mandau.agent.v1.OperationEvent.timestamp:type_name -> google.protobuf.Timestamp
+	239, // 187: mandau.agent.v1.HeartbeatRequest.status:type_name -> mandau.agent.v1.HeartbeatRequest.StatusEntry
+	169, // 188: mandau.agent.v1.HeartbeatRequest.results:type_name -> mandau.agent.v1.InstructionResult
+	244, // 189: mandau.agent.v1.HeartbeatRequest.sent_at:type_name -> google.protobuf.Timestamp
+	245, // 190: mandau.agent.v1.HeartbeatRequest.clock_offset:type_name -> google.protobuf.Duration
+	245, // 191: mandau.agent.v1.HeartbeatResponse.next_heartbeat:type_name -> google.protobuf.Duration
+	162, // 192: mandau.agent.v1.HeartbeatResponse.instructions:type_name -> mandau.agent.v1.AgentInstruction
+	244, // 193: mandau.agent.v1.HeartbeatResponse.core_time:type_name -> google.protobuf.Timestamp
+	244, // 194: mandau.agent.v1.AgentInstruction.created_at:type_name -> google.protobuf.Timestamp
+	163, // 195: mandau.agent.v1.AgentInstruction.config:type_name -> mandau.agent.v1.ConfigInstruction
+	92,  // 196: mandau.agent.v1.AgentInstruction.apply_stack:type_name -> mandau.agent.v1.ApplyStackRequest
+	178, // 197: mandau.agent.v1.AgentInstruction.remove_stack:type_name -> mandau.agent.v1.RemoveStackRequest
+	164, // 198: mandau.agent.v1.AgentInstruction.drain:type_name -> mandau.agent.v1.DrainInstruction
+	244, // 199: mandau.agent.v1.AgentInstruction.expires_at:type_name -> google.protobuf.Timestamp
+	162, // 200: mandau.agent.v1.QueueAgentInstructionRequest.instruction:type_name -> mandau.agent.v1.AgentInstruction
+	162, // 201: mandau.agent.v1.ListAgentInstructionsResponse.pending:type_name -> mandau.agent.v1.AgentInstruction
+	76,  // 202: mandau.agent.v1.CapabilitiesResponse.plugins:type_name -> mandau.agent.v1.PluginAvailability
+	240, // 203: mandau.agent.v1.HealthResponse.status:type_name -> mandau.agent.v1.HealthResponse.StatusEntry
+	241, // 204: mandau.agent.v1.ListStacksRequest.labels:type_name -> mandau.agent.v1.ListStacksRequest.LabelsEntry
+	88,  // 205: mandau.agent.v1.ListStacksResponse.stacks:type_name -> mandau.agent.v1.Stack
+	242, // 206: mandau.agent.v1.ListStacksResponse.agent_errors:type_name -> mandau.agent.v1.ListStacksResponse.AgentErrorsEntry
+	88,  // 207: mandau.agent.v1.GetStackResponse.stack:type_name -> mandau.agent.v1.Stack
+	245, // 208: mandau.agent.v1.RemoveStackRequest.queue_ttl:type_name -> google.protobuf.Duration
+	244, // 209: mandau.agent.v1.GetStackLogsRequest.since:type_name -> google.protobuf.Timestamp
+	244, // 210: mandau.agent.v1.GetStackLogsRequest.until:type_name -> google.protobuf.Timestamp
+	120, // 211: mandau.agent.v1.LogBatch.entries:type_name -> mandau.agent.v1.LogEntry
+	114, // 212: mandau.agent.v1.ListContainersResponse.containers:type_name -> mandau.agent.v1.Container
+	114, // 213: mandau.agent.v1.InspectContainerResponse.container:type_name -> mandau.agent.v1.Container
+	158, // 214: mandau.agent.v1.ListOperationsResponse.operations:type_name -> mandau.agent.v1.Operation
+	243, // 215: mandau.agent.v1.EnrollResponse.labels:type_name -> mandau.agent.v1.EnrollResponse.LabelsEntry
+	244, // 216: mandau.agent.v1.EnrollResponse.expires_at:type_name -> google.protobuf.Timestamp
+	8,   // 217: mandau.agent.v1.CoreService.ListAgents:input_type -> mandau.agent.v1.ListAgentsRequest
+	10,  // 218: mandau.agent.v1.CoreService.WatchAgents:input_type -> mandau.agent.v1.WatchAgentsRequest
+	75,  // 219: mandau.agent.v1.CoreService.RegisterAgent:input_type -> mandau.agent.v1.RegisterRequest
+	160, // 220: mandau.agent.v1.CoreService.Heartbeat:input_type -> mandau.agent.v1.HeartbeatRequest
+	160, // 221: mandau.agent.v1.CoreService.HeartbeatStream:input_type -> mandau.agent.v1.HeartbeatRequest
+	12,  // 222: mandau.agent.v1.CoreService.UpdateAgentLabels:input_type -> mandau.agent.v1.UpdateAgentLabelsRequest
+	14,  // 223: mandau.agent.v1.CoreService.SetAgentMaintenance:input_type -> mandau.agent.v1.SetAgentMaintenanceRequest
+	165, // 224: mandau.agent.v1.CoreService.QueueAgentInstruction:input_type -> mandau.agent.v1.QueueAgentInstructionRequest
+	166, // 225: mandau.agent.v1.CoreService.ListAgentInstructions:input_type -> mandau.agent.v1.ListAgentInstructionsRequest
+	168, // 226: mandau.agent.v1.CoreService.CancelAgentInstruction:input_type -> mandau.agent.v1.CancelAgentInstructionRequest
+	20,  // 227: mandau.agent.v1.CoreService.CreateAgentGroup:input_type -> mandau.agent.v1.CreateAgentGroupRequest
+	21,  // 228: mandau.agent.v1.CoreService.GetAgentGroup:input_type -> mandau.agent.v1.GetAgentGroupRequest
+	23,  // 229: mandau.agent.v1.CoreService.ListAgentGroups:input_type -> mandau.agent.v1.ListAgentGroupsRequest
+	25,  // 230: mandau.agent.v1.CoreService.UpdateAgentGroup:input_type -> mandau.agent.v1.UpdateAgentGroupRequest
+	26,  // 231: mandau.agent.v1.CoreService.DeleteAgentGroup:input_type -> mandau.agent.v1.DeleteAgentGroupRequest
+	29,  // 232: mandau.agent.v1.CoreService.ListApprovals:input_type -> mandau.agent.v1.ListApprovalsRequest
+	31,  // 233: mandau.agent.v1.CoreService.ReviewApproval:input_type -> mandau.agent.v1.ReviewApprovalRequest
+	33,  // 234: mandau.agent.v1.CoreService.GrantBreakGlass:input_type -> mandau.agent.v1.GrantBreakGlassRequest
+	34,  // 235: mandau.agent.v1.CoreService.RevokeBreakGlass:input_type -> mandau.agent.v1.RevokeBreakGlassRequest
+	35,  // 236: mandau.agent.v1.CoreService.ListBreakGlassGrants:input_type -> mandau.agent.v1.ListBreakGlassGrantsRequest
+	37,  // 237: mandau.agent.v1.CoreService.SetFreeze:input_type -> mandau.agent.v1.SetFreezeRequest
+	38,  // 238: mandau.agent.v1.CoreService.GetFreeze:input_type -> mandau.agent.v1.GetFreezeRequest
+	45,  // 239: mandau.agent.v1.CoreService.GetQuotaUsage:input_type -> mandau.agent.v1.GetQuotaUsageRequest
+	57,  // 240: mandau.agent.v1.CoreService.GetResourceReport:input_type -> mandau.agent.v1.GetResourceReportRequest
+	48,  // 241: mandau.agent.v1.CoreService.Diagnose:input_type -> mandau.agent.v1.DiagnoseRequest
+	51,  // 242: mandau.agent.v1.CoreService.DiagnoseAgent:input_type -> mandau.agent.v1.DiagnoseAgentRequest
+	39,  // 243: mandau.agent.v1.CoreService.GetClusterStatus:input_type -> mandau.agent.v1.GetClusterStatusRequest
+	60,  // 244: mandau.agent.v1.CoreService.GetPatchCompliance:input_type -> mandau.agent.v1.GetPatchComplianceRequest
+	78,  // 245: mandau.agent.v1.CoreService.GetPluginIndex:input_type -> mandau.agent.v1.GetPluginIndexRequest
+	81,  // 246: mandau.agent.v1.CoreService.InstallPlugin:input_type -> mandau.agent.v1.InstallPluginRequest
+	83,  // 247: mandau.agent.v1.CoreService.ListInstalledPlugins:input_type -> mandau.agent.v1.ListInstalledPluginsRequest
+	85,  // 248: mandau.agent.v1.CoreService.DescribePlugin:input_type -> mandau.agent.v1.DescribePluginRequest
+	62,  // 249: mandau.agent.v1.CoreService.RunFleetCommand:input_type -> mandau.agent.v1.RunFleetCommandRequest
+	65,  // 250: mandau.agent.v1.CoreService.GetInventory:input_type -> mandau.agent.v1.GetInventoryRequest
+	75,  // 251: mandau.agent.v1.AgentService.Register:input_type -> mandau.agent.v1.RegisterRequest
+	160, // 252: mandau.agent.v1.AgentService.Heartbeat:input_type -> mandau.agent.v1.HeartbeatRequest
+	170, // 253: mandau.agent.v1.AgentService.GetCapabilities:input_type -> mandau.agent.v1.CapabilitiesRequest
+	172, // 254: mandau.agent.v1.AgentService.GetHealth:input_type -> mandau.agent.v1.HealthRequest
+	48,  // 255: mandau.agent.v1.AgentService.Diagnose:input_type -> mandau.agent.v1.DiagnoseRequest
+	55,  // 256: mandau.agent.v1.AgentService.CheckCoreConnection:input_type -> mandau.agent.v1.CheckCoreConnectionRequest
+	81,  // 257: mandau.agent.v1.AgentService.InstallPlugin:input_type -> mandau.agent.v1.InstallPluginRequest
+	85,  // 258: mandau.agent.v1.AgentService.DescribePlugin:input_type -> mandau.agent.v1.DescribePluginRequest
+	174, // 259: mandau.agent.v1.StackService.ListStacks:input_type -> mandau.agent.v1.ListStacksRequest
+	176, // 260: mandau.agent.v1.StackService.GetStack:input_type -> mandau.agent.v1.GetStackRequest
+	92,  // 261: mandau.agent.v1.StackService.ApplyStack:input_type -> mandau.agent.v1.ApplyStackRequest
+	178, // 262: mandau.agent.v1.StackService.RemoveStack:input_type -> mandau.agent.v1.RemoveStackRequest
+	102, // 263: mandau.agent.v1.StackService.DiffStack:input_type -> mandau.agent.v1.DiffStackRequest
+	179, // 264: mandau.agent.v1.StackService.GetStackLogs:input_type -> mandau.agent.v1.GetStackLogsRequest
+	179, // 265: mandau.agent.v1.StackService.GetStackLogsBatched:input_type -> mandau.agent.v1.GetStackLogsRequest
+	105, // 266: mandau.agent.v1.StackService.ExportStack:input_type -> mandau.agent.v1.ExportStackRequest
+	107, // 267: mandau.agent.v1.StackService.CollectStackGarbage:input_type -> mandau.agent.v1.CollectStackGarbageRequest
+	109, // 268: mandau.agent.v1.StackService.GetStackEvents:input_type -> mandau.agent.v1.GetStackEventsRequest
+	97,  // 269: mandau.agent.v1.StackService.DiffStackFiles:input_type -> mandau.agent.v1.DiffStackFilesRequest
+	99,  // 270: mandau.agent.v1.StackService.UploadStackFiles:input_type -> mandau.agent.v1.StackFileChunk
+	181, // 271: mandau.agent.v1.ContainerService.ListContainers:input_type -> mandau.agent.v1.ListContainersRequest
+	183, // 272: mandau.agent.v1.ContainerService.InspectContainer:input_type -> mandau.agent.v1.InspectContainerRequest
+	185, // 273: mandau.agent.v1.ContainerService.StreamLogs:input_type -> mandau.agent.v1.StreamLogsRequest
+	116, // 274: mandau.agent.v1.ContainerService.Exec:input_type -> mandau.agent.v1.ExecRequest
+	186, // 275: mandau.agent.v1.ContainerService.GetStats:input_type -> mandau.agent.v1.GetStatsRequest
+	187, // 276: mandau.agent.v1.ContainerService.StartContainer:input_type -> mandau.agent.v1.StartContainerRequest
+	189, // 277: mandau.agent.v1.ContainerService.StopContainer:input_type -> mandau.agent.v1.StopContainerRequest
+	191, // 278: mandau.agent.v1.ContainerService.RestartContainer:input_type -> mandau.agent.v1.RestartContainerRequest
+	122, // 279: mandau.agent.v1.FilesystemService.ListFiles:input_type -> mandau.agent.v1.ListFilesRequest
+	125, // 280: mandau.agent.v1.FilesystemService.ReadFile:input_type -> mandau.agent.v1.ReadFileRequest
+	127, // 281: mandau.agent.v1.FilesystemService.WriteFile:input_type -> mandau.agent.v1.WriteFileRequest
+	194, // 282: mandau.agent.v1.FilesystemService.DeleteFile:input_type -> mandau.agent.v1.DeleteFileRequest
+	196, // 283: mandau.agent.v1.FilesystemService.CreateDirectory:input_type -> mandau.agent.v1.CreateDirectoryRequest
+	129, // 284: mandau.agent.v1.TransferService.Upload:input_type -> mandau.agent.v1.UploadRequest
+	131, // 285: mandau.agent.v1.TransferService.GetTransfer:input_type -> mandau.agent.v1.GetTransferRequest
+	132, // 286: mandau.agent.v1.TransferService.ListBackups:input_type -> mandau.agent.v1.ListBackupsRequest
+	134, // 287: mandau.agent.v1.TransferService.DownloadBackup:input_type -> mandau.agent.v1.DownloadBackupRequest
+	136, // 288: mandau.agent.v1.TransferService.Fetch:input_type -> mandau.agent.v1.FetchRequest
+	138, // 289: mandau.agent.v1.ArtifactService.PutArtifact:input_type -> mandau.agent.v1.PutArtifactRequest
+	139, // 290: mandau.agent.v1.ArtifactService.GetArtifact:input_type -> mandau.agent.v1.GetArtifactRequest
+	140, // 291: mandau.agent.v1.ArtifactService.ListArtifacts:input_type -> mandau.agent.v1.ListArtifactsRequest
+	142, // 292: mandau.agent.v1.ArtifactService.DeleteArtifact:input_type -> mandau.agent.v1.DeleteArtifactRequest
+	143, // 293: mandau.agent.v1.ArtifactService.CollectArtifactGarbage:input_type -> mandau.agent.v1.CollectArtifactGarbageRequest
+	145, // 294: mandau.agent.v1.ArtifactService.FetchArtifact:input_type -> mandau.agent.v1.FetchArtifactRequest
+	147, // 295: mandau.agent.v1.AdminService.GetProfile:input_type -> mandau.agent.v1.GetProfileRequest
+	149, // 296: mandau.agent.v1.AdminService.ListConnections:input_type -> mandau.agent.v1.ListConnectionsRequest
+	152, // 297: mandau.agent.v1.AdminService.GetRegistry:input_type -> mandau.agent.v1.GetRegistryRequest
+	156, // 298: mandau.agent.v1.AdminService.GetConfig:input_type -> mandau.agent.v1.GetConfigRequest
+	198, // 299: mandau.agent.v1.OperationsService.GetOperation:input_type -> mandau.agent.v1.GetOperationRequest
+	199, // 300: mandau.agent.v1.OperationsService.ListOperations:input_type -> mandau.agent.v1.ListOperationsRequest
+	201, // 301: mandau.agent.v1.OperationsService.CancelOperation:input_type -> mandau.agent.v1.CancelOperationRequest
+	203, // 302: mandau.agent.v1.OperationsService.StreamOperation:input_type -> mandau.agent.v1.StreamOperationRequest
+	208, // 303: mandau.agent.v1.EnrollmentService.GetEnrollmentCA:input_type -> mandau.agent.v1.GetEnrollmentCARequest
+	210, // 304: mandau.agent.v1.EnrollmentService.Enroll:input_type -> mandau.agent.v1.EnrollRequest
+	9,   // 305: mandau.agent.v1.CoreService.ListAgents:output_type -> mandau.agent.v1.ListAgentsResponse
+	11,  // 306: mandau.agent.v1.CoreService.WatchAgents:output_type -> mandau.agent.v1.AgentDelta
+	77,  // 307: mandau.agent.v1.CoreService.RegisterAgent:output_type -> mandau.agent.v1.RegisterResponse
+	161, // 308: mandau.agent.v1.CoreService.Heartbeat:output_type -> mandau.agent.v1.HeartbeatResponse
+	161, // 309: mandau.agent.v1.CoreService.HeartbeatStream:output_type -> mandau.agent.v1.HeartbeatResponse
+	13,  // 310: mandau.agent.v1.CoreService.UpdateAgentLabels:output_type -> mandau.agent.v1.UpdateAgentLabelsResponse
+	15,  // 311: mandau.agent.v1.CoreService.SetAgentMaintenance:output_type -> mandau.agent.v1.SetAgentMaintenanceResponse
+	162, // 312: mandau.agent.v1.CoreService.QueueAgentInstruction:output_type -> mandau.agent.v1.AgentInstruction
+	167, // 313: mandau.agent.v1.CoreService.ListAgentInstructions:output_type -> mandau.agent.v1.ListAgentInstructionsResponse
+	162, // 314: mandau.agent.v1.CoreService.CancelAgentInstruction:output_type -> mandau.agent.v1.AgentInstruction
+	19,  // 315: mandau.agent.v1.CoreService.CreateAgentGroup:output_type -> mandau.agent.v1.AgentGroup
+	22,  // 316: mandau.agent.v1.CoreService.GetAgentGroup:output_type -> mandau.agent.v1.GetAgentGroupResponse
+	24,  // 317: mandau.agent.v1.CoreService.ListAgentGroups:output_type -> mandau.agent.v1.ListAgentGroupsResponse
+	19,  // 318: mandau.agent.v1.CoreService.UpdateAgentGroup:output_type -> mandau.agent.v1.AgentGroup
+	27,  // 319: mandau.agent.v1.CoreService.DeleteAgentGroup:output_type -> mandau.agent.v1.DeleteAgentGroupResponse
+	30,  // 320: mandau.agent.v1.CoreService.ListApprovals:output_type -> mandau.agent.v1.ListApprovalsResponse
+	28,  // 321: mandau.agent.v1.CoreService.ReviewApproval:output_type -> mandau.agent.v1.Approval
+	32,  // 322: mandau.agent.v1.CoreService.GrantBreakGlass:output_type -> mandau.agent.v1.BreakGlassGrant
+	32,  // 323: mandau.agent.v1.CoreService.RevokeBreakGlass:output_type -> mandau.agent.v1.BreakGlassGrant
+	36,  // 324: mandau.agent.v1.CoreService.ListBreakGlassGrants:output_type -> mandau.agent.v1.ListBreakGlassGrantsResponse
+	44,  // 325: mandau.agent.v1.CoreService.SetFreeze:output_type -> mandau.agent.v1.FreezeState
+	44,  // 326: mandau.agent.v1.CoreService.GetFreeze:output_type -> mandau.agent.v1.FreezeState
+	46,  // 327: mandau.agent.v1.CoreService.GetQuotaUsage:output_type -> mandau.agent.v1.QuotaUsage
+	58,  // 328: mandau.agent.v1.CoreService.GetResourceReport:output_type -> mandau.agent.v1.ResourceReport
+	49,  // 329: mandau.agent.v1.CoreService.Diagnose:output_type -> mandau.agent.v1.DiagnoseResponse
+	52,  // 330: mandau.agent.v1.CoreService.DiagnoseAgent:output_type -> mandau.agent.v1.AgentConnectionReport
+	40,  // 331: mandau.agent.v1.CoreService.GetClusterStatus:output_type -> mandau.agent.v1.ClusterStatus
+	61,  // 332: mandau.agent.v1.CoreService.GetPatchCompliance:output_type -> mandau.agent.v1.PatchCompliance
+	79,  // 333: mandau.agent.v1.CoreService.GetPluginIndex:output_type -> mandau.agent.v1.PluginIndex
+	82,  // 334: mandau.agent.v1.CoreService.InstallPlugin:output_type -> mandau.agent.v1.InstalledPlugin
+	84,  // 335: mandau.agent.v1.CoreService.ListInstalledPlugins:output_type -> mandau.agent.v1.ListInstalledPluginsResponse
+	87,  // 336: mandau.agent.v1.CoreService.DescribePlugin:output_type -> mandau.agent.v1.PluginDescription
+	63,  // 337: mandau.agent.v1.CoreService.RunFleetCommand:output_type -> mandau.agent.v1.FleetCommandReport
+	66,  // 338: mandau.agent.v1.CoreService.GetInventory:output_type -> mandau.agent.v1.Inventory
+	77,  // 339: mandau.agent.v1.AgentService.Register:output_type -> mandau.agent.v1.RegisterResponse
+	161, // 340: mandau.agent.v1.AgentService.Heartbeat:output_type -> mandau.agent.v1.HeartbeatResponse
+	171, // 341: mandau.agent.v1.AgentService.GetCapabilities:output_type -> mandau.agent.v1.CapabilitiesResponse
+	173, // 342: mandau.agent.v1.AgentService.GetHealth:output_type -> mandau.agent.v1.HealthResponse
+	49,  // 343: mandau.agent.v1.AgentService.Diagnose:output_type -> mandau.agent.v1.DiagnoseResponse
+	56,  // 344: mandau.agent.v1.AgentService.CheckCoreConnection:output_type -> mandau.agent.v1.CoreConnectivity
+	82,  // 345: mandau.agent.v1.AgentService.InstallPlugin:output_type -> mandau.agent.v1.InstalledPlugin
+	87,  // 346: mandau.agent.v1.AgentService.DescribePlugin:output_type -> mandau.agent.v1.PluginDescription
+	175, // 347: mandau.agent.v1.StackService.ListStacks:output_type -> mandau.agent.v1.ListStacksResponse
+	177, // 348: mandau.agent.v1.StackService.GetStack:output_type -> mandau.agent.v1.GetStackResponse
+	159, // 349: mandau.agent.v1.StackService.ApplyStack:output_type -> mandau.agent.v1.OperationEvent
+	159, // 350: mandau.agent.v1.StackService.RemoveStack:output_type -> mandau.agent.v1.OperationEvent
+	103, // 351: mandau.agent.v1.StackService.DiffStack:output_type -> mandau.agent.v1.DiffStackResponse
+	120, // 352: mandau.agent.v1.StackService.GetStackLogs:output_type -> mandau.agent.v1.LogEntry
+	180, // 353: mandau.agent.v1.StackService.GetStackLogsBatched:output_type -> mandau.agent.v1.LogBatch
+	106, // 354: mandau.agent.v1.StackService.ExportStack:output_type -> mandau.agent.v1.StackExport
+	108, // 355: mandau.agent.v1.StackService.CollectStackGarbage:output_type -> mandau.agent.v1.CollectStackGarbageResponse
+	110, // 356: mandau.agent.v1.StackService.GetStackEvents:output_type -> mandau.agent.v1.GetStackEventsResponse
+	98,  // 357: mandau.agent.v1.StackService.DiffStackFiles:output_type -> mandau.agent.v1.DiffStackFilesResponse
+	100, // 358: mandau.agent.v1.StackService.UploadStackFiles:output_type -> mandau.agent.v1.UploadStackFilesResponse
+	182, // 359: mandau.agent.v1.ContainerService.ListContainers:output_type -> mandau.agent.v1.ListContainersResponse
+	184, // 360: mandau.agent.v1.ContainerService.InspectContainer:output_type -> mandau.agent.v1.InspectContainerResponse
+	120, // 361: mandau.agent.v1.ContainerService.StreamLogs:output_type -> mandau.agent.v1.LogEntry
+	119, // 362: mandau.agent.v1.ContainerService.Exec:output_type -> mandau.agent.v1.ExecResponse
+	121, // 363: mandau.agent.v1.ContainerService.GetStats:output_type -> mandau.agent.v1.ContainerStats
+	188, // 364: mandau.agent.v1.ContainerService.StartContainer:output_type -> mandau.agent.v1.StartContainerResponse
+	190, // 365: mandau.agent.v1.ContainerService.StopContainer:output_type -> mandau.agent.v1.StopContainerResponse
+	192, // 366: mandau.agent.v1.ContainerService.RestartContainer:output_type -> mandau.agent.v1.RestartContainerResponse
+	123, // 367: mandau.agent.v1.FilesystemService.ListFiles:output_type -> mandau.agent.v1.ListFilesResponse
+	126, // 368: mandau.agent.v1.FilesystemService.ReadFile:output_type -> mandau.agent.v1.ReadFileResponse
+	193, // 369: mandau.agent.v1.FilesystemService.WriteFile:output_type -> mandau.agent.v1.WriteFileResponse
+	195, // 370: mandau.agent.v1.FilesystemService.DeleteFile:output_type -> mandau.agent.v1.DeleteFileResponse
+	197, // 371: mandau.agent.v1.FilesystemService.CreateDirectory:output_type -> mandau.agent.v1.CreateDirectoryResponse
+	130, // 372: mandau.agent.v1.TransferService.Upload:output_type -> mandau.agent.v1.TransferStatus
+	130, // 373: mandau.agent.v1.TransferService.GetTransfer:output_type -> mandau.agent.v1.TransferStatus
+	133, // 374: mandau.agent.v1.TransferService.ListBackups:output_type -> mandau.agent.v1.ListBackupsResponse
+	135, // 375: mandau.agent.v1.TransferService.DownloadBackup:output_type -> mandau.agent.v1.DownloadChunk
+	130, // 376: mandau.agent.v1.TransferService.Fetch:output_type -> mandau.agent.v1.TransferStatus
+	137, // 377: mandau.agent.v1.ArtifactService.PutArtifact:output_type -> mandau.agent.v1.Artifact
+	137, // 378: mandau.agent.v1.ArtifactService.GetArtifact:output_type -> mandau.agent.v1.Artifact
+	141, // 379: mandau.agent.v1.ArtifactService.ListArtifacts:output_type -> mandau.agent.v1.ListArtifactsResponse
+	137, // 380: mandau.agent.v1.ArtifactService.DeleteArtifact:output_type -> mandau.agent.v1.Artifact
+	144, // 381: mandau.agent.v1.ArtifactService.CollectArtifactGarbage:output_type -> mandau.agent.v1.CollectArtifactGarbageResponse
+	146, // 382: mandau.agent.v1.ArtifactService.FetchArtifact:output_type -> mandau.agent.v1.ArtifactChunk
+	148, // 383: mandau.agent.v1.AdminService.GetProfile:output_type -> mandau.agent.v1.ProfileChunk
+	150, // 384: mandau.agent.v1.AdminService.ListConnections:output_type -> mandau.agent.v1.ListConnectionsResponse
+	153, // 385: mandau.agent.v1.AdminService.GetRegistry:output_type -> mandau.agent.v1.CoreRegistry
+	157, // 386: mandau.agent.v1.AdminService.GetConfig:output_type -> mandau.agent.v1.ConfigDump
+	158, // 387: mandau.agent.v1.OperationsService.GetOperation:output_type -> mandau.agent.v1.Operation
+	200, // 388: mandau.agent.v1.OperationsService.ListOperations:output_type -> mandau.agent.v1.ListOperationsResponse
+	202, // 389: mandau.agent.v1.OperationsService.CancelOperation:output_type -> mandau.agent.v1.CancelOperationResponse
+	159, // 390: mandau.agent.v1.OperationsService.StreamOperation:output_type -> mandau.agent.v1.OperationEvent
+	209, // 391: mandau.agent.v1.EnrollmentService.GetEnrollmentCA:output_type -> mandau.agent.v1.GetEnrollmentCAResponse
+	211, // 392: mandau.agent.v1.EnrollmentService.Enroll:output_type -> mandau.agent.v1.EnrollResponse
+	305, // [305:393] is the sub-list for method output_type
+	217, // [217:305] is the sub-list for method input_type
+	217, // [217:217] is the sub-list for extension type_name
+	217, // [217:217] is the sub-list for extension extendee
+	0,   // [0:217] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_agent_proto_init() }
@@ -4204,26 +15490,33 @@ func file_api_v1_agent_proto_init() {
 	if File_api_v1_agent_proto != nil {
 		return
 	}
-	file_api_v1_agent_proto_msgTypes[12].OneofWrappers = []any{
+	file_api_v1_agent_proto_msgTypes[17].OneofWrappers = []any{}
+	file_api_v1_agent_proto_msgTypes[108].OneofWrappers = []any{
 		(*ExecRequest_Start)(nil),
 		(*ExecRequest_Stdin)(nil),
 		(*ExecRequest_Resize)(nil),
 	}
-	file_api_v1_agent_proto_msgTypes[15].OneofWrappers = []any{
+	file_api_v1_agent_proto_msgTypes[111].OneofWrappers = []any{
 		(*ExecResponse_Stdout)(nil),
 		(*ExecResponse_Stderr)(nil),
 		(*ExecResponse_ExitCode)(nil),
 		(*ExecResponse_Error)(nil),
 	}
+	file_api_v1_agent_proto_msgTypes[154].OneofWrappers = []any{
+		(*AgentInstruction_Config)(nil),
+		(*AgentInstruction_ApplyStack)(nil),
+		(*AgentInstruction_RemoveStack)(nil),
+		(*AgentInstruction_Drain)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_v1_agent_proto_rawDesc), len(file_api_v1_agent_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   74,
+			NumEnums:      8,
+			NumMessages:   236,
 			NumExtensions: 0,
-			NumServices:   6,
+			NumServices:   10,
 		},
 		GoTypes:           file_api_v1_agent_proto_goTypes,
 		DependencyIndexes: file_api_v1_agent_proto_depIdxs,
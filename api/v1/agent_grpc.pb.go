@@ -19,9 +19,40 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CoreService_ListAgents_FullMethodName    = "/mandau.agent.v1.CoreService/ListAgents"
-	CoreService_RegisterAgent_FullMethodName = "/mandau.agent.v1.CoreService/RegisterAgent"
-	CoreService_Heartbeat_FullMethodName     = "/mandau.agent.v1.CoreService/Heartbeat"
+	CoreService_ListAgents_FullMethodName             = "/mandau.agent.v1.CoreService/ListAgents"
+	CoreService_WatchAgents_FullMethodName            = "/mandau.agent.v1.CoreService/WatchAgents"
+	CoreService_RegisterAgent_FullMethodName          = "/mandau.agent.v1.CoreService/RegisterAgent"
+	CoreService_Heartbeat_FullMethodName              = "/mandau.agent.v1.CoreService/Heartbeat"
+	CoreService_HeartbeatStream_FullMethodName        = "/mandau.agent.v1.CoreService/HeartbeatStream"
+	CoreService_UpdateAgentLabels_FullMethodName      = "/mandau.agent.v1.CoreService/UpdateAgentLabels"
+	CoreService_SetAgentMaintenance_FullMethodName    = "/mandau.agent.v1.CoreService/SetAgentMaintenance"
+	CoreService_QueueAgentInstruction_FullMethodName  = "/mandau.agent.v1.CoreService/QueueAgentInstruction"
+	CoreService_ListAgentInstructions_FullMethodName  = "/mandau.agent.v1.CoreService/ListAgentInstructions"
+	CoreService_CancelAgentInstruction_FullMethodName = "/mandau.agent.v1.CoreService/CancelAgentInstruction"
+	CoreService_CreateAgentGroup_FullMethodName       = "/mandau.agent.v1.CoreService/CreateAgentGroup"
+	CoreService_GetAgentGroup_FullMethodName          = "/mandau.agent.v1.CoreService/GetAgentGroup"
+	CoreService_ListAgentGroups_FullMethodName        = "/mandau.agent.v1.CoreService/ListAgentGroups"
+	CoreService_UpdateAgentGroup_FullMethodName       = "/mandau.agent.v1.CoreService/UpdateAgentGroup"
+	CoreService_DeleteAgentGroup_FullMethodName       = "/mandau.agent.v1.CoreService/DeleteAgentGroup"
+	CoreService_ListApprovals_FullMethodName          = "/mandau.agent.v1.CoreService/ListApprovals"
+	CoreService_ReviewApproval_FullMethodName         = "/mandau.agent.v1.CoreService/ReviewApproval"
+	CoreService_GrantBreakGlass_FullMethodName        = "/mandau.agent.v1.CoreService/GrantBreakGlass"
+	CoreService_RevokeBreakGlass_FullMethodName       = "/mandau.agent.v1.CoreService/RevokeBreakGlass"
+	CoreService_ListBreakGlassGrants_FullMethodName   = "/mandau.agent.v1.CoreService/ListBreakGlassGrants"
+	CoreService_SetFreeze_FullMethodName              = "/mandau.agent.v1.CoreService/SetFreeze"
+	CoreService_GetFreeze_FullMethodName              = "/mandau.agent.v1.CoreService/GetFreeze"
+	CoreService_GetQuotaUsage_FullMethodName          = "/mandau.agent.v1.CoreService/GetQuotaUsage"
+	CoreService_GetResourceReport_FullMethodName      = "/mandau.agent.v1.CoreService/GetResourceReport"
+	CoreService_Diagnose_FullMethodName               = "/mandau.agent.v1.CoreService/Diagnose"
+	CoreService_DiagnoseAgent_FullMethodName          = "/mandau.agent.v1.CoreService/DiagnoseAgent"
+	CoreService_GetClusterStatus_FullMethodName       = "/mandau.agent.v1.CoreService/GetClusterStatus"
+	CoreService_GetPatchCompliance_FullMethodName     = "/mandau.agent.v1.CoreService/GetPatchCompliance"
+	CoreService_GetPluginIndex_FullMethodName         = "/mandau.agent.v1.CoreService/GetPluginIndex"
+	CoreService_InstallPlugin_FullMethodName          = "/mandau.agent.v1.CoreService/InstallPlugin"
+	CoreService_ListInstalledPlugins_FullMethodName   = "/mandau.agent.v1.CoreService/ListInstalledPlugins"
+	CoreService_DescribePlugin_FullMethodName         = "/mandau.agent.v1.CoreService/DescribePlugin"
+	CoreService_RunFleetCommand_FullMethodName        = "/mandau.agent.v1.CoreService/RunFleetCommand"
+	CoreService_GetInventory_FullMethodName           = "/mandau.agent.v1.CoreService/GetInventory"
 )
 
 // CoreServiceClient is the client API for CoreService service.
@@ -31,8 +62,71 @@ const (
 // Core Service - Central control plane
 type CoreServiceClient interface {
 	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
+	// WatchAgents sends the agents ListAgents would, then a delta each time
+	// an agent joins, changes in or leaves that set
+	WatchAgents(ctx context.Context, in *WatchAgentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentDelta], error)
 	RegisterAgent(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// HeartbeatStream carries an agent's heartbeats over one long-lived
+	// call in place of a Heartbeat call each interval; every request is
+	// answered with one response
+	HeartbeatStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HeartbeatRequest, HeartbeatResponse], error)
+	UpdateAgentLabels(ctx context.Context, in *UpdateAgentLabelsRequest, opts ...grpc.CallOption) (*UpdateAgentLabelsResponse, error)
+	SetAgentMaintenance(ctx context.Context, in *SetAgentMaintenanceRequest, opts ...grpc.CallOption) (*SetAgentMaintenanceResponse, error)
+	// Instructions delivered on heartbeat responses. Only config and drain
+	// instructions can be queued directly; stack changes are queued by
+	// ApplyStack and RemoveStack for dial-out-only agents, and for offline
+	// agents when the request sets queue.
+	QueueAgentInstruction(ctx context.Context, in *QueueAgentInstructionRequest, opts ...grpc.CallOption) (*AgentInstruction, error)
+	ListAgentInstructions(ctx context.Context, in *ListAgentInstructionsRequest, opts ...grpc.CallOption) (*ListAgentInstructionsResponse, error)
+	CancelAgentInstruction(ctx context.Context, in *CancelAgentInstructionRequest, opts ...grpc.CallOption) (*AgentInstruction, error)
+	// Agent groups
+	CreateAgentGroup(ctx context.Context, in *CreateAgentGroupRequest, opts ...grpc.CallOption) (*AgentGroup, error)
+	GetAgentGroup(ctx context.Context, in *GetAgentGroupRequest, opts ...grpc.CallOption) (*GetAgentGroupResponse, error)
+	ListAgentGroups(ctx context.Context, in *ListAgentGroupsRequest, opts ...grpc.CallOption) (*ListAgentGroupsResponse, error)
+	UpdateAgentGroup(ctx context.Context, in *UpdateAgentGroupRequest, opts ...grpc.CallOption) (*AgentGroup, error)
+	DeleteAgentGroup(ctx context.Context, in *DeleteAgentGroupRequest, opts ...grpc.CallOption) (*DeleteAgentGroupResponse, error)
+	// Approvals (two-person rule)
+	ListApprovals(ctx context.Context, in *ListApprovalsRequest, opts ...grpc.CallOption) (*ListApprovalsResponse, error)
+	ReviewApproval(ctx context.Context, in *ReviewApprovalRequest, opts ...grpc.CallOption) (*Approval, error)
+	// Break-glass elevation
+	GrantBreakGlass(ctx context.Context, in *GrantBreakGlassRequest, opts ...grpc.CallOption) (*BreakGlassGrant, error)
+	RevokeBreakGlass(ctx context.Context, in *RevokeBreakGlassRequest, opts ...grpc.CallOption) (*BreakGlassGrant, error)
+	ListBreakGlassGrants(ctx context.Context, in *ListBreakGlassGrantsRequest, opts ...grpc.CallOption) (*ListBreakGlassGrantsResponse, error)
+	// Cluster freeze: while frozen, mutating calls are rejected except for
+	// identities elevated by break-glass
+	SetFreeze(ctx context.Context, in *SetFreezeRequest, opts ...grpc.CallOption) (*FreezeState, error)
+	GetFreeze(ctx context.Context, in *GetFreezeRequest, opts ...grpc.CallOption) (*FreezeState, error)
+	// Quotas
+	GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*QuotaUsage, error)
+	// Resource reporting
+	GetResourceReport(ctx context.Context, in *GetResourceReportRequest, opts ...grpc.CallOption) (*ResourceReport, error)
+	// Diagnostics of the core, or of one agent as seen from the core
+	Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error)
+	// Connection diagnostics of one agent, as shown by "mandau agent ping":
+	// round trips, the certificates both sides present, whether the agent
+	// reaches the core and the state of the core's channel to it
+	DiagnoseAgent(ctx context.Context, in *DiagnoseAgentRequest, opts ...grpc.CallOption) (*AgentConnectionReport, error)
+	// Overview of the cluster for operators, as shown by "mandau status"
+	GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*ClusterStatus, error)
+	// Pending security updates and reboots of every agent, as shown by
+	// "mandau compliance packages"
+	GetPatchCompliance(ctx context.Context, in *GetPatchComplianceRequest, opts ...grpc.CallOption) (*PatchCompliance, error)
+	// Plugin marketplace: the signed plugin index the core hosts, installing
+	// plugins from it on agents and the plugins installed on each agent
+	GetPluginIndex(ctx context.Context, in *GetPluginIndexRequest, opts ...grpc.CallOption) (*PluginIndex, error)
+	InstallPlugin(ctx context.Context, in *InstallPluginRequest, opts ...grpc.CallOption) (*InstalledPlugin, error)
+	ListInstalledPlugins(ctx context.Context, in *ListInstalledPluginsRequest, opts ...grpc.CallOption) (*ListInstalledPluginsResponse, error)
+	// A plugin running on an agent and the host access it is allowed
+	DescribePlugin(ctx context.Context, in *DescribePluginRequest, opts ...grpc.CallOption) (*PluginDescription, error)
+	// Runs a command from the agents' commands allowlist on every agent
+	// matching a selector, a bounded number at a time, and collects their
+	// exit codes and output, as "mandau fleet run" does
+	RunFleetCommand(ctx context.Context, in *RunFleetCommandRequest, opts ...grpc.CallOption) (*FleetCommandReport, error)
+	// Everything the fleet runs, for CMDB ingestion, as exported by "mandau
+	// inventory export": host facts, stacks and their images, vhosts, DNS
+	// zones and certificates of every agent
+	GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error)
 }
 
 type coreServiceClient struct {
@@ -53,6 +147,25 @@ func (c *coreServiceClient) ListAgents(ctx context.Context, in *ListAgentsReques
 	return out, nil
 }
 
+func (c *coreServiceClient) WatchAgents(ctx context.Context, in *WatchAgentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentDelta], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoreService_ServiceDesc.Streams[0], CoreService_WatchAgents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAgentsRequest, AgentDelta]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoreService_WatchAgentsClient = grpc.ServerStreamingClient[AgentDelta]
+
 func (c *coreServiceClient) RegisterAgent(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RegisterResponse)
@@ -73,6 +186,309 @@ func (c *coreServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest,
 	return out, nil
 }
 
+func (c *coreServiceClient) HeartbeatStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HeartbeatRequest, HeartbeatResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoreService_ServiceDesc.Streams[1], CoreService_HeartbeatStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HeartbeatRequest, HeartbeatResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoreService_HeartbeatStreamClient = grpc.BidiStreamingClient[HeartbeatRequest, HeartbeatResponse]
+
+func (c *coreServiceClient) UpdateAgentLabels(ctx context.Context, in *UpdateAgentLabelsRequest, opts ...grpc.CallOption) (*UpdateAgentLabelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAgentLabelsResponse)
+	err := c.cc.Invoke(ctx, CoreService_UpdateAgentLabels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) SetAgentMaintenance(ctx context.Context, in *SetAgentMaintenanceRequest, opts ...grpc.CallOption) (*SetAgentMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAgentMaintenanceResponse)
+	err := c.cc.Invoke(ctx, CoreService_SetAgentMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) QueueAgentInstruction(ctx context.Context, in *QueueAgentInstructionRequest, opts ...grpc.CallOption) (*AgentInstruction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentInstruction)
+	err := c.cc.Invoke(ctx, CoreService_QueueAgentInstruction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ListAgentInstructions(ctx context.Context, in *ListAgentInstructionsRequest, opts ...grpc.CallOption) (*ListAgentInstructionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentInstructionsResponse)
+	err := c.cc.Invoke(ctx, CoreService_ListAgentInstructions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) CancelAgentInstruction(ctx context.Context, in *CancelAgentInstructionRequest, opts ...grpc.CallOption) (*AgentInstruction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentInstruction)
+	err := c.cc.Invoke(ctx, CoreService_CancelAgentInstruction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) CreateAgentGroup(ctx context.Context, in *CreateAgentGroupRequest, opts ...grpc.CallOption) (*AgentGroup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentGroup)
+	err := c.cc.Invoke(ctx, CoreService_CreateAgentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetAgentGroup(ctx context.Context, in *GetAgentGroupRequest, opts ...grpc.CallOption) (*GetAgentGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAgentGroupResponse)
+	err := c.cc.Invoke(ctx, CoreService_GetAgentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ListAgentGroups(ctx context.Context, in *ListAgentGroupsRequest, opts ...grpc.CallOption) (*ListAgentGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentGroupsResponse)
+	err := c.cc.Invoke(ctx, CoreService_ListAgentGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) UpdateAgentGroup(ctx context.Context, in *UpdateAgentGroupRequest, opts ...grpc.CallOption) (*AgentGroup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentGroup)
+	err := c.cc.Invoke(ctx, CoreService_UpdateAgentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) DeleteAgentGroup(ctx context.Context, in *DeleteAgentGroupRequest, opts ...grpc.CallOption) (*DeleteAgentGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAgentGroupResponse)
+	err := c.cc.Invoke(ctx, CoreService_DeleteAgentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ListApprovals(ctx context.Context, in *ListApprovalsRequest, opts ...grpc.CallOption) (*ListApprovalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListApprovalsResponse)
+	err := c.cc.Invoke(ctx, CoreService_ListApprovals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ReviewApproval(ctx context.Context, in *ReviewApprovalRequest, opts ...grpc.CallOption) (*Approval, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Approval)
+	err := c.cc.Invoke(ctx, CoreService_ReviewApproval_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GrantBreakGlass(ctx context.Context, in *GrantBreakGlassRequest, opts ...grpc.CallOption) (*BreakGlassGrant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BreakGlassGrant)
+	err := c.cc.Invoke(ctx, CoreService_GrantBreakGlass_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) RevokeBreakGlass(ctx context.Context, in *RevokeBreakGlassRequest, opts ...grpc.CallOption) (*BreakGlassGrant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BreakGlassGrant)
+	err := c.cc.Invoke(ctx, CoreService_RevokeBreakGlass_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ListBreakGlassGrants(ctx context.Context, in *ListBreakGlassGrantsRequest, opts ...grpc.CallOption) (*ListBreakGlassGrantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBreakGlassGrantsResponse)
+	err := c.cc.Invoke(ctx, CoreService_ListBreakGlassGrants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) SetFreeze(ctx context.Context, in *SetFreezeRequest, opts ...grpc.CallOption) (*FreezeState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreezeState)
+	err := c.cc.Invoke(ctx, CoreService_SetFreeze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetFreeze(ctx context.Context, in *GetFreezeRequest, opts ...grpc.CallOption) (*FreezeState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreezeState)
+	err := c.cc.Invoke(ctx, CoreService_GetFreeze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*QuotaUsage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuotaUsage)
+	err := c.cc.Invoke(ctx, CoreService_GetQuotaUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetResourceReport(ctx context.Context, in *GetResourceReportRequest, opts ...grpc.CallOption) (*ResourceReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResourceReport)
+	err := c.cc.Invoke(ctx, CoreService_GetResourceReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnoseResponse)
+	err := c.cc.Invoke(ctx, CoreService_Diagnose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) DiagnoseAgent(ctx context.Context, in *DiagnoseAgentRequest, opts ...grpc.CallOption) (*AgentConnectionReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentConnectionReport)
+	err := c.cc.Invoke(ctx, CoreService_DiagnoseAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*ClusterStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClusterStatus)
+	err := c.cc.Invoke(ctx, CoreService_GetClusterStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetPatchCompliance(ctx context.Context, in *GetPatchComplianceRequest, opts ...grpc.CallOption) (*PatchCompliance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PatchCompliance)
+	err := c.cc.Invoke(ctx, CoreService_GetPatchCompliance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetPluginIndex(ctx context.Context, in *GetPluginIndexRequest, opts ...grpc.CallOption) (*PluginIndex, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PluginIndex)
+	err := c.cc.Invoke(ctx, CoreService_GetPluginIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) InstallPlugin(ctx context.Context, in *InstallPluginRequest, opts ...grpc.CallOption) (*InstalledPlugin, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstalledPlugin)
+	err := c.cc.Invoke(ctx, CoreService_InstallPlugin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ListInstalledPlugins(ctx context.Context, in *ListInstalledPluginsRequest, opts ...grpc.CallOption) (*ListInstalledPluginsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInstalledPluginsResponse)
+	err := c.cc.Invoke(ctx, CoreService_ListInstalledPlugins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) DescribePlugin(ctx context.Context, in *DescribePluginRequest, opts ...grpc.CallOption) (*PluginDescription, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PluginDescription)
+	err := c.cc.Invoke(ctx, CoreService_DescribePlugin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) RunFleetCommand(ctx context.Context, in *RunFleetCommandRequest, opts ...grpc.CallOption) (*FleetCommandReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FleetCommandReport)
+	err := c.cc.Invoke(ctx, CoreService_RunFleetCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Inventory)
+	err := c.cc.Invoke(ctx, CoreService_GetInventory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CoreServiceServer is the server API for CoreService service.
 // All implementations must embed UnimplementedCoreServiceServer
 // for forward compatibility.
@@ -80,340 +496,2511 @@ func (c *coreServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest,
 // Core Service - Central control plane
 type CoreServiceServer interface {
 	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
+	// WatchAgents sends the agents ListAgents would, then a delta each time
+	// an agent joins, changes in or leaves that set
+	WatchAgents(*WatchAgentsRequest, grpc.ServerStreamingServer[AgentDelta]) error
 	RegisterAgent(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// HeartbeatStream carries an agent's heartbeats over one long-lived
+	// call in place of a Heartbeat call each interval; every request is
+	// answered with one response
+	HeartbeatStream(grpc.BidiStreamingServer[HeartbeatRequest, HeartbeatResponse]) error
+	UpdateAgentLabels(context.Context, *UpdateAgentLabelsRequest) (*UpdateAgentLabelsResponse, error)
+	SetAgentMaintenance(context.Context, *SetAgentMaintenanceRequest) (*SetAgentMaintenanceResponse, error)
+	// Instructions delivered on heartbeat responses. Only config and drain
+	// instructions can be queued directly; stack changes are queued by
+	// ApplyStack and RemoveStack for dial-out-only agents, and for offline
+	// agents when the request sets queue.
+	QueueAgentInstruction(context.Context, *QueueAgentInstructionRequest) (*AgentInstruction, error)
+	ListAgentInstructions(context.Context, *ListAgentInstructionsRequest) (*ListAgentInstructionsResponse, error)
+	CancelAgentInstruction(context.Context, *CancelAgentInstructionRequest) (*AgentInstruction, error)
+	// Agent groups
+	CreateAgentGroup(context.Context, *CreateAgentGroupRequest) (*AgentGroup, error)
+	GetAgentGroup(context.Context, *GetAgentGroupRequest) (*GetAgentGroupResponse, error)
+	ListAgentGroups(context.Context, *ListAgentGroupsRequest) (*ListAgentGroupsResponse, error)
+	UpdateAgentGroup(context.Context, *UpdateAgentGroupRequest) (*AgentGroup, error)
+	DeleteAgentGroup(context.Context, *DeleteAgentGroupRequest) (*DeleteAgentGroupResponse, error)
+	// Approvals (two-person rule)
+	ListApprovals(context.Context, *ListApprovalsRequest) (*ListApprovalsResponse, error)
+	ReviewApproval(context.Context, *ReviewApprovalRequest) (*Approval, error)
+	// Break-glass elevation
+	GrantBreakGlass(context.Context, *GrantBreakGlassRequest) (*BreakGlassGrant, error)
+	RevokeBreakGlass(context.Context, *RevokeBreakGlassRequest) (*BreakGlassGrant, error)
+	ListBreakGlassGrants(context.Context, *ListBreakGlassGrantsRequest) (*ListBreakGlassGrantsResponse, error)
+	// Cluster freeze: while frozen, mutating calls are rejected except for
+	// identities elevated by break-glass
+	SetFreeze(context.Context, *SetFreezeRequest) (*FreezeState, error)
+	GetFreeze(context.Context, *GetFreezeRequest) (*FreezeState, error)
+	// Quotas
+	GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*QuotaUsage, error)
+	// Resource reporting
+	GetResourceReport(context.Context, *GetResourceReportRequest) (*ResourceReport, error)
+	// Diagnostics of the core, or of one agent as seen from the core
+	Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error)
+	// Connection diagnostics of one agent, as shown by "mandau agent ping":
+	// round trips, the certificates both sides present, whether the agent
+	// reaches the core and the state of the core's channel to it
+	DiagnoseAgent(context.Context, *DiagnoseAgentRequest) (*AgentConnectionReport, error)
+	// Overview of the cluster for operators, as shown by "mandau status"
+	GetClusterStatus(context.Context, *GetClusterStatusRequest) (*ClusterStatus, error)
+	// Pending security updates and reboots of every agent, as shown by
+	// "mandau compliance packages"
+	GetPatchCompliance(context.Context, *GetPatchComplianceRequest) (*PatchCompliance, error)
+	// Plugin marketplace: the signed plugin index the core hosts, installing
+	// plugins from it on agents and the plugins installed on each agent
+	GetPluginIndex(context.Context, *GetPluginIndexRequest) (*PluginIndex, error)
+	InstallPlugin(context.Context, *InstallPluginRequest) (*InstalledPlugin, error)
+	ListInstalledPlugins(context.Context, *ListInstalledPluginsRequest) (*ListInstalledPluginsResponse, error)
+	// A plugin running on an agent and the host access it is allowed
+	DescribePlugin(context.Context, *DescribePluginRequest) (*PluginDescription, error)
+	// Runs a command from the agents' commands allowlist on every agent
+	// matching a selector, a bounded number at a time, and collects their
+	// exit codes and output, as "mandau fleet run" does
+	RunFleetCommand(context.Context, *RunFleetCommandRequest) (*FleetCommandReport, error)
+	// Everything the fleet runs, for CMDB ingestion, as exported by "mandau
+	// inventory export": host facts, stacks and their images, vhosts, DNS
+	// zones and certificates of every agent
+	GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error)
 	mustEmbedUnimplementedCoreServiceServer()
 }
 
-// UnimplementedCoreServiceServer must be embedded to have
+// UnimplementedCoreServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCoreServiceServer struct{}
+
+func (UnimplementedCoreServiceServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedCoreServiceServer) WatchAgents(*WatchAgentsRequest, grpc.ServerStreamingServer[AgentDelta]) error {
+	return status.Error(codes.Unimplemented, "method WatchAgents not implemented")
+}
+func (UnimplementedCoreServiceServer) RegisterAgent(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterAgent not implemented")
+}
+func (UnimplementedCoreServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedCoreServiceServer) HeartbeatStream(grpc.BidiStreamingServer[HeartbeatRequest, HeartbeatResponse]) error {
+	return status.Error(codes.Unimplemented, "method HeartbeatStream not implemented")
+}
+func (UnimplementedCoreServiceServer) UpdateAgentLabels(context.Context, *UpdateAgentLabelsRequest) (*UpdateAgentLabelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateAgentLabels not implemented")
+}
+func (UnimplementedCoreServiceServer) SetAgentMaintenance(context.Context, *SetAgentMaintenanceRequest) (*SetAgentMaintenanceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetAgentMaintenance not implemented")
+}
+func (UnimplementedCoreServiceServer) QueueAgentInstruction(context.Context, *QueueAgentInstructionRequest) (*AgentInstruction, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueueAgentInstruction not implemented")
+}
+func (UnimplementedCoreServiceServer) ListAgentInstructions(context.Context, *ListAgentInstructionsRequest) (*ListAgentInstructionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgentInstructions not implemented")
+}
+func (UnimplementedCoreServiceServer) CancelAgentInstruction(context.Context, *CancelAgentInstructionRequest) (*AgentInstruction, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelAgentInstruction not implemented")
+}
+func (UnimplementedCoreServiceServer) CreateAgentGroup(context.Context, *CreateAgentGroupRequest) (*AgentGroup, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateAgentGroup not implemented")
+}
+func (UnimplementedCoreServiceServer) GetAgentGroup(context.Context, *GetAgentGroupRequest) (*GetAgentGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAgentGroup not implemented")
+}
+func (UnimplementedCoreServiceServer) ListAgentGroups(context.Context, *ListAgentGroupsRequest) (*ListAgentGroupsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgentGroups not implemented")
+}
+func (UnimplementedCoreServiceServer) UpdateAgentGroup(context.Context, *UpdateAgentGroupRequest) (*AgentGroup, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateAgentGroup not implemented")
+}
+func (UnimplementedCoreServiceServer) DeleteAgentGroup(context.Context, *DeleteAgentGroupRequest) (*DeleteAgentGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAgentGroup not implemented")
+}
+func (UnimplementedCoreServiceServer) ListApprovals(context.Context, *ListApprovalsRequest) (*ListApprovalsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListApprovals not implemented")
+}
+func (UnimplementedCoreServiceServer) ReviewApproval(context.Context, *ReviewApprovalRequest) (*Approval, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReviewApproval not implemented")
+}
+func (UnimplementedCoreServiceServer) GrantBreakGlass(context.Context, *GrantBreakGlassRequest) (*BreakGlassGrant, error) {
+	return nil, status.Error(codes.Unimplemented, "method GrantBreakGlass not implemented")
+}
+func (UnimplementedCoreServiceServer) RevokeBreakGlass(context.Context, *RevokeBreakGlassRequest) (*BreakGlassGrant, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeBreakGlass not implemented")
+}
+func (UnimplementedCoreServiceServer) ListBreakGlassGrants(context.Context, *ListBreakGlassGrantsRequest) (*ListBreakGlassGrantsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBreakGlassGrants not implemented")
+}
+func (UnimplementedCoreServiceServer) SetFreeze(context.Context, *SetFreezeRequest) (*FreezeState, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetFreeze not implemented")
+}
+func (UnimplementedCoreServiceServer) GetFreeze(context.Context, *GetFreezeRequest) (*FreezeState, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetFreeze not implemented")
+}
+func (UnimplementedCoreServiceServer) GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*QuotaUsage, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetQuotaUsage not implemented")
+}
+func (UnimplementedCoreServiceServer) GetResourceReport(context.Context, *GetResourceReportRequest) (*ResourceReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetResourceReport not implemented")
+}
+func (UnimplementedCoreServiceServer) Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Diagnose not implemented")
+}
+func (UnimplementedCoreServiceServer) DiagnoseAgent(context.Context, *DiagnoseAgentRequest) (*AgentConnectionReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiagnoseAgent not implemented")
+}
+func (UnimplementedCoreServiceServer) GetClusterStatus(context.Context, *GetClusterStatusRequest) (*ClusterStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetClusterStatus not implemented")
+}
+func (UnimplementedCoreServiceServer) GetPatchCompliance(context.Context, *GetPatchComplianceRequest) (*PatchCompliance, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPatchCompliance not implemented")
+}
+func (UnimplementedCoreServiceServer) GetPluginIndex(context.Context, *GetPluginIndexRequest) (*PluginIndex, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPluginIndex not implemented")
+}
+func (UnimplementedCoreServiceServer) InstallPlugin(context.Context, *InstallPluginRequest) (*InstalledPlugin, error) {
+	return nil, status.Error(codes.Unimplemented, "method InstallPlugin not implemented")
+}
+func (UnimplementedCoreServiceServer) ListInstalledPlugins(context.Context, *ListInstalledPluginsRequest) (*ListInstalledPluginsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListInstalledPlugins not implemented")
+}
+func (UnimplementedCoreServiceServer) DescribePlugin(context.Context, *DescribePluginRequest) (*PluginDescription, error) {
+	return nil, status.Error(codes.Unimplemented, "method DescribePlugin not implemented")
+}
+func (UnimplementedCoreServiceServer) RunFleetCommand(context.Context, *RunFleetCommandRequest) (*FleetCommandReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunFleetCommand not implemented")
+}
+func (UnimplementedCoreServiceServer) GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInventory not implemented")
+}
+func (UnimplementedCoreServiceServer) mustEmbedUnimplementedCoreServiceServer() {}
+func (UnimplementedCoreServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeCoreServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoreServiceServer will
+// result in compilation errors.
+type UnsafeCoreServiceServer interface {
+	mustEmbedUnimplementedCoreServiceServer()
+}
+
+func RegisterCoreServiceServer(s grpc.ServiceRegistrar, srv CoreServiceServer) {
+	// If the following call panics, it indicates UnimplementedCoreServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CoreService_ServiceDesc, srv)
+}
+
+func _CoreService_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListAgents(ctx, req.(*ListAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_WatchAgents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAgentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreServiceServer).WatchAgents(m, &grpc.GenericServerStream[WatchAgentsRequest, AgentDelta]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoreService_WatchAgentsServer = grpc.ServerStreamingServer[AgentDelta]
+
+func _CoreService_RegisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).RegisterAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_RegisterAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).RegisterAgent(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_HeartbeatStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CoreServiceServer).HeartbeatStream(&grpc.GenericServerStream[HeartbeatRequest, HeartbeatResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoreService_HeartbeatStreamServer = grpc.BidiStreamingServer[HeartbeatRequest, HeartbeatResponse]
+
+func _CoreService_UpdateAgentLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAgentLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).UpdateAgentLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_UpdateAgentLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).UpdateAgentLabels(ctx, req.(*UpdateAgentLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_SetAgentMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAgentMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).SetAgentMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_SetAgentMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).SetAgentMaintenance(ctx, req.(*SetAgentMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_QueueAgentInstruction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueAgentInstructionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).QueueAgentInstruction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_QueueAgentInstruction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).QueueAgentInstruction(ctx, req.(*QueueAgentInstructionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListAgentInstructions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentInstructionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListAgentInstructions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListAgentInstructions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListAgentInstructions(ctx, req.(*ListAgentInstructionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_CancelAgentInstruction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelAgentInstructionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).CancelAgentInstruction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_CancelAgentInstruction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).CancelAgentInstruction(ctx, req.(*CancelAgentInstructionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_CreateAgentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAgentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).CreateAgentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_CreateAgentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).CreateAgentGroup(ctx, req.(*CreateAgentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetAgentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAgentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetAgentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetAgentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetAgentGroup(ctx, req.(*GetAgentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListAgentGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListAgentGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListAgentGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListAgentGroups(ctx, req.(*ListAgentGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_UpdateAgentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAgentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).UpdateAgentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_UpdateAgentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).UpdateAgentGroup(ctx, req.(*UpdateAgentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_DeleteAgentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAgentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).DeleteAgentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_DeleteAgentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).DeleteAgentGroup(ctx, req.(*DeleteAgentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListApprovals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApprovalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListApprovals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListApprovals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListApprovals(ctx, req.(*ListApprovalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ReviewApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ReviewApproval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ReviewApproval_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ReviewApproval(ctx, req.(*ReviewApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GrantBreakGlass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrantBreakGlassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GrantBreakGlass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GrantBreakGlass_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GrantBreakGlass(ctx, req.(*GrantBreakGlassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_RevokeBreakGlass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeBreakGlassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).RevokeBreakGlass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_RevokeBreakGlass_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).RevokeBreakGlass(ctx, req.(*RevokeBreakGlassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListBreakGlassGrants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakGlassGrantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListBreakGlassGrants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListBreakGlassGrants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListBreakGlassGrants(ctx, req.(*ListBreakGlassGrantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_SetFreeze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFreezeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).SetFreeze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_SetFreeze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).SetFreeze(ctx, req.(*SetFreezeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetFreeze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFreezeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetFreeze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetFreeze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetFreeze(ctx, req.(*GetFreezeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetQuotaUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetQuotaUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetQuotaUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetQuotaUsage(ctx, req.(*GetQuotaUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetResourceReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResourceReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetResourceReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetResourceReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetResourceReport(ctx, req.(*GetResourceReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_Diagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnoseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).Diagnose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_Diagnose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).Diagnose(ctx, req.(*DiagnoseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_DiagnoseAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnoseAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).DiagnoseAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_DiagnoseAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).DiagnoseAgent(ctx, req.(*DiagnoseAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetClusterStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetClusterStatus(ctx, req.(*GetClusterStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetPatchCompliance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPatchComplianceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetPatchCompliance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetPatchCompliance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetPatchCompliance(ctx, req.(*GetPatchComplianceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetPluginIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPluginIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetPluginIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetPluginIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetPluginIndex(ctx, req.(*GetPluginIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_InstallPlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallPluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).InstallPlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_InstallPlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).InstallPlugin(ctx, req.(*InstallPluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ListInstalledPlugins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInstalledPluginsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ListInstalledPlugins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ListInstalledPlugins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ListInstalledPlugins(ctx, req.(*ListInstalledPluginsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_DescribePlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribePluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).DescribePlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_DescribePlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).DescribePlugin(ctx, req.(*DescribePluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_RunFleetCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunFleetCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).RunFleetCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_RunFleetCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).RunFleetCommand(ctx, req.(*RunFleetCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_GetInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).GetInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_GetInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).GetInventory(ctx, req.(*GetInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CoreService_ServiceDesc is the grpc.ServiceDesc for CoreService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.CoreService",
+	HandlerType: (*CoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAgents",
+			Handler:    _CoreService_ListAgents_Handler,
+		},
+		{
+			MethodName: "RegisterAgent",
+			Handler:    _CoreService_RegisterAgent_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _CoreService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "UpdateAgentLabels",
+			Handler:    _CoreService_UpdateAgentLabels_Handler,
+		},
+		{
+			MethodName: "SetAgentMaintenance",
+			Handler:    _CoreService_SetAgentMaintenance_Handler,
+		},
+		{
+			MethodName: "QueueAgentInstruction",
+			Handler:    _CoreService_QueueAgentInstruction_Handler,
+		},
+		{
+			MethodName: "ListAgentInstructions",
+			Handler:    _CoreService_ListAgentInstructions_Handler,
+		},
+		{
+			MethodName: "CancelAgentInstruction",
+			Handler:    _CoreService_CancelAgentInstruction_Handler,
+		},
+		{
+			MethodName: "CreateAgentGroup",
+			Handler:    _CoreService_CreateAgentGroup_Handler,
+		},
+		{
+			MethodName: "GetAgentGroup",
+			Handler:    _CoreService_GetAgentGroup_Handler,
+		},
+		{
+			MethodName: "ListAgentGroups",
+			Handler:    _CoreService_ListAgentGroups_Handler,
+		},
+		{
+			MethodName: "UpdateAgentGroup",
+			Handler:    _CoreService_UpdateAgentGroup_Handler,
+		},
+		{
+			MethodName: "DeleteAgentGroup",
+			Handler:    _CoreService_DeleteAgentGroup_Handler,
+		},
+		{
+			MethodName: "ListApprovals",
+			Handler:    _CoreService_ListApprovals_Handler,
+		},
+		{
+			MethodName: "ReviewApproval",
+			Handler:    _CoreService_ReviewApproval_Handler,
+		},
+		{
+			MethodName: "GrantBreakGlass",
+			Handler:    _CoreService_GrantBreakGlass_Handler,
+		},
+		{
+			MethodName: "RevokeBreakGlass",
+			Handler:    _CoreService_RevokeBreakGlass_Handler,
+		},
+		{
+			MethodName: "ListBreakGlassGrants",
+			Handler:    _CoreService_ListBreakGlassGrants_Handler,
+		},
+		{
+			MethodName: "SetFreeze",
+			Handler:    _CoreService_SetFreeze_Handler,
+		},
+		{
+			MethodName: "GetFreeze",
+			Handler:    _CoreService_GetFreeze_Handler,
+		},
+		{
+			MethodName: "GetQuotaUsage",
+			Handler:    _CoreService_GetQuotaUsage_Handler,
+		},
+		{
+			MethodName: "GetResourceReport",
+			Handler:    _CoreService_GetResourceReport_Handler,
+		},
+		{
+			MethodName: "Diagnose",
+			Handler:    _CoreService_Diagnose_Handler,
+		},
+		{
+			MethodName: "DiagnoseAgent",
+			Handler:    _CoreService_DiagnoseAgent_Handler,
+		},
+		{
+			MethodName: "GetClusterStatus",
+			Handler:    _CoreService_GetClusterStatus_Handler,
+		},
+		{
+			MethodName: "GetPatchCompliance",
+			Handler:    _CoreService_GetPatchCompliance_Handler,
+		},
+		{
+			MethodName: "GetPluginIndex",
+			Handler:    _CoreService_GetPluginIndex_Handler,
+		},
+		{
+			MethodName: "InstallPlugin",
+			Handler:    _CoreService_InstallPlugin_Handler,
+		},
+		{
+			MethodName: "ListInstalledPlugins",
+			Handler:    _CoreService_ListInstalledPlugins_Handler,
+		},
+		{
+			MethodName: "DescribePlugin",
+			Handler:    _CoreService_DescribePlugin_Handler,
+		},
+		{
+			MethodName: "RunFleetCommand",
+			Handler:    _CoreService_RunFleetCommand_Handler,
+		},
+		{
+			MethodName: "GetInventory",
+			Handler:    _CoreService_GetInventory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAgents",
+			Handler:       _CoreService_WatchAgents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "HeartbeatStream",
+			Handler:       _CoreService_HeartbeatStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/v1/agent.proto",
+}
+
+const (
+	AgentService_Register_FullMethodName            = "/mandau.agent.v1.AgentService/Register"
+	AgentService_Heartbeat_FullMethodName           = "/mandau.agent.v1.AgentService/Heartbeat"
+	AgentService_GetCapabilities_FullMethodName     = "/mandau.agent.v1.AgentService/GetCapabilities"
+	AgentService_GetHealth_FullMethodName           = "/mandau.agent.v1.AgentService/GetHealth"
+	AgentService_Diagnose_FullMethodName            = "/mandau.agent.v1.AgentService/Diagnose"
+	AgentService_CheckCoreConnection_FullMethodName = "/mandau.agent.v1.AgentService/CheckCoreConnection"
+	AgentService_InstallPlugin_FullMethodName       = "/mandau.agent.v1.AgentService/InstallPlugin"
+	AgentService_DescribePlugin_FullMethodName      = "/mandau.agent.v1.AgentService/DescribePlugin"
+)
+
+// AgentServiceClient is the client API for AgentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Agent Identity & Lifecycle Service
+type AgentServiceClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesResponse, error)
+	GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error)
+	// CheckCoreConnection dials the core afresh, for "mandau agent ping"
+	CheckCoreConnection(ctx context.Context, in *CheckCoreConnectionRequest, opts ...grpc.CallOption) (*CoreConnectivity, error)
+	// InstallPlugin downloads a plugin named in the signed index the core
+	// passes along, checks it against the index and loads it
+	InstallPlugin(ctx context.Context, in *InstallPluginRequest, opts ...grpc.CallOption) (*InstalledPlugin, error)
+	DescribePlugin(ctx context.Context, in *DescribePluginRequest, opts ...grpc.CallOption) (*PluginDescription, error)
+}
+
+type agentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentServiceClient(cc grpc.ClientConnInterface) AgentServiceClient {
+	return &agentServiceClient{cc}
+}
+
+func (c *agentServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, AgentService_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, AgentService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CapabilitiesResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetCapabilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnoseResponse)
+	err := c.cc.Invoke(ctx, AgentService_Diagnose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CheckCoreConnection(ctx context.Context, in *CheckCoreConnectionRequest, opts ...grpc.CallOption) (*CoreConnectivity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CoreConnectivity)
+	err := c.cc.Invoke(ctx, AgentService_CheckCoreConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) InstallPlugin(ctx context.Context, in *InstallPluginRequest, opts ...grpc.CallOption) (*InstalledPlugin, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstalledPlugin)
+	err := c.cc.Invoke(ctx, AgentService_InstallPlugin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) DescribePlugin(ctx context.Context, in *DescribePluginRequest, opts ...grpc.CallOption) (*PluginDescription, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PluginDescription)
+	err := c.cc.Invoke(ctx, AgentService_DescribePlugin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServiceServer is the server API for AgentService service.
+// All implementations must embed UnimplementedAgentServiceServer
+// for forward compatibility.
+//
+// Agent Identity & Lifecycle Service
+type AgentServiceServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	GetCapabilities(context.Context, *CapabilitiesRequest) (*CapabilitiesResponse, error)
+	GetHealth(context.Context, *HealthRequest) (*HealthResponse, error)
+	Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error)
+	// CheckCoreConnection dials the core afresh, for "mandau agent ping"
+	CheckCoreConnection(context.Context, *CheckCoreConnectionRequest) (*CoreConnectivity, error)
+	// InstallPlugin downloads a plugin named in the signed index the core
+	// passes along, checks it against the index and loads it
+	InstallPlugin(context.Context, *InstallPluginRequest) (*InstalledPlugin, error)
+	DescribePlugin(context.Context, *DescribePluginRequest) (*PluginDescription, error)
+	mustEmbedUnimplementedAgentServiceServer()
+}
+
+// UnimplementedAgentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentServiceServer struct{}
+
+func (UnimplementedAgentServiceServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedAgentServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedAgentServiceServer) GetCapabilities(context.Context, *CapabilitiesRequest) (*CapabilitiesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (UnimplementedAgentServiceServer) GetHealth(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHealth not implemented")
+}
+func (UnimplementedAgentServiceServer) Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Diagnose not implemented")
+}
+func (UnimplementedAgentServiceServer) CheckCoreConnection(context.Context, *CheckCoreConnectionRequest) (*CoreConnectivity, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckCoreConnection not implemented")
+}
+func (UnimplementedAgentServiceServer) InstallPlugin(context.Context, *InstallPluginRequest) (*InstalledPlugin, error) {
+	return nil, status.Error(codes.Unimplemented, "method InstallPlugin not implemented")
+}
+func (UnimplementedAgentServiceServer) DescribePlugin(context.Context, *DescribePluginRequest) (*PluginDescription, error) {
+	return nil, status.Error(codes.Unimplemented, "method DescribePlugin not implemented")
+}
+func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
+func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAgentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentServiceServer will
+// result in compilation errors.
+type UnsafeAgentServiceServer interface {
+	mustEmbedUnimplementedAgentServiceServer()
+}
+
+func RegisterAgentServiceServer(s grpc.ServiceRegistrar, srv AgentServiceServer) {
+	// If the following call panics, it indicates UnimplementedAgentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AgentService_ServiceDesc, srv)
+}
+
+func _AgentService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetCapabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetCapabilities(ctx, req.(*CapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetHealth(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_Diagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnoseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Diagnose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Diagnose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Diagnose(ctx, req.(*DiagnoseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CheckCoreConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckCoreConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CheckCoreConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CheckCoreConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CheckCoreConnection(ctx, req.(*CheckCoreConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_InstallPlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallPluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).InstallPlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_InstallPlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).InstallPlugin(ctx, req.(*InstallPluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DescribePlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribePluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DescribePlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DescribePlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DescribePlugin(ctx, req.(*DescribePluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.AgentService",
+	HandlerType: (*AgentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _AgentService_Register_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _AgentService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _AgentService_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "GetHealth",
+			Handler:    _AgentService_GetHealth_Handler,
+		},
+		{
+			MethodName: "Diagnose",
+			Handler:    _AgentService_Diagnose_Handler,
+		},
+		{
+			MethodName: "CheckCoreConnection",
+			Handler:    _AgentService_CheckCoreConnection_Handler,
+		},
+		{
+			MethodName: "InstallPlugin",
+			Handler:    _AgentService_InstallPlugin_Handler,
+		},
+		{
+			MethodName: "DescribePlugin",
+			Handler:    _AgentService_DescribePlugin_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/agent.proto",
+}
+
+const (
+	StackService_ListStacks_FullMethodName          = "/mandau.agent.v1.StackService/ListStacks"
+	StackService_GetStack_FullMethodName            = "/mandau.agent.v1.StackService/GetStack"
+	StackService_ApplyStack_FullMethodName          = "/mandau.agent.v1.StackService/ApplyStack"
+	StackService_RemoveStack_FullMethodName         = "/mandau.agent.v1.StackService/RemoveStack"
+	StackService_DiffStack_FullMethodName           = "/mandau.agent.v1.StackService/DiffStack"
+	StackService_GetStackLogs_FullMethodName        = "/mandau.agent.v1.StackService/GetStackLogs"
+	StackService_GetStackLogsBatched_FullMethodName = "/mandau.agent.v1.StackService/GetStackLogsBatched"
+	StackService_ExportStack_FullMethodName         = "/mandau.agent.v1.StackService/ExportStack"
+	StackService_CollectStackGarbage_FullMethodName = "/mandau.agent.v1.StackService/CollectStackGarbage"
+	StackService_GetStackEvents_FullMethodName      = "/mandau.agent.v1.StackService/GetStackEvents"
+	StackService_DiffStackFiles_FullMethodName      = "/mandau.agent.v1.StackService/DiffStackFiles"
+	StackService_UploadStackFiles_FullMethodName    = "/mandau.agent.v1.StackService/UploadStackFiles"
+)
+
+// StackServiceClient is the client API for StackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Stack Management Service
+type StackServiceClient interface {
+	ListStacks(ctx context.Context, in *ListStacksRequest, opts ...grpc.CallOption) (*ListStacksResponse, error)
+	GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error)
+	ApplyStack(ctx context.Context, in *ApplyStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error)
+	RemoveStack(ctx context.Context, in *RemoveStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error)
+	DiffStack(ctx context.Context, in *DiffStackRequest, opts ...grpc.CallOption) (*DiffStackResponse, error)
+	GetStackLogs(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
+	// GetStackLogsBatched streams the same entries grouped into frames of up
+	// to batch_size entries or batch_interval_ms, whichever comes first
+	GetStackLogsBatched(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogBatch], error)
+	// ExportStack returns what is stored for a stack and how it runs, for
+	// backup, review or applying it on another agent
+	ExportStack(ctx context.Context, in *ExportStackRequest, opts ...grpc.CallOption) (*StackExport, error)
+	// CollectStackGarbage finds what stacks left behind on an agent: stack
+	// directories without containers, containers of stacks whose directory is
+	// gone and stale backup or .env files. With apply set they are removed.
+	CollectStackGarbage(ctx context.Context, in *CollectStackGarbageRequest, opts ...grpc.CallOption) (*CollectStackGarbageResponse, error)
+	// GetStackEvents returns a stack's timeline: applies with what they
+	// changed, scaling, removals, crashes, restarts, health changes and
+	// certificate renewals, oldest first. It outlives the stack's removal.
+	GetStackEvents(ctx context.Context, in *GetStackEventsRequest, opts ...grpc.CallOption) (*GetStackEventsResponse, error)
+	// DiffStackFiles compares the hashes of the files a caller means to apply
+	// with those in the stack's directory and returns the ones to upload
+	DiffStackFiles(ctx context.Context, in *DiffStackFilesRequest, opts ...grpc.CallOption) (*DiffStackFilesResponse, error)
+	// UploadStackFiles stages files for the next apply listing them. A file's
+	// chunks follow each other; a chunk with a path starts the next file.
+	UploadStackFiles(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[StackFileChunk, UploadStackFilesResponse], error)
+}
+
+type stackServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStackServiceClient(cc grpc.ClientConnInterface) StackServiceClient {
+	return &stackServiceClient{cc}
+}
+
+func (c *stackServiceClient) ListStacks(ctx context.Context, in *ListStacksRequest, opts ...grpc.CallOption) (*ListStacksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStacksResponse)
+	err := c.cc.Invoke(ctx, StackService_ListStacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStackResponse)
+	err := c.cc.Invoke(ctx, StackService_GetStack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) ApplyStack(ctx context.Context, in *ApplyStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[0], StackService_ApplyStack_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ApplyStackRequest, OperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_ApplyStackClient = grpc.ServerStreamingClient[OperationEvent]
+
+func (c *stackServiceClient) RemoveStack(ctx context.Context, in *RemoveStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[1], StackService_RemoveStack_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RemoveStackRequest, OperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_RemoveStackClient = grpc.ServerStreamingClient[OperationEvent]
+
+func (c *stackServiceClient) DiffStack(ctx context.Context, in *DiffStackRequest, opts ...grpc.CallOption) (*DiffStackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffStackResponse)
+	err := c.cc.Invoke(ctx, StackService_DiffStack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) GetStackLogs(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[2], StackService_GetStackLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetStackLogsRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_GetStackLogsClient = grpc.ServerStreamingClient[LogEntry]
+
+func (c *stackServiceClient) GetStackLogsBatched(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogBatch], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[3], StackService_GetStackLogsBatched_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetStackLogsRequest, LogBatch]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_GetStackLogsBatchedClient = grpc.ServerStreamingClient[LogBatch]
+
+func (c *stackServiceClient) ExportStack(ctx context.Context, in *ExportStackRequest, opts ...grpc.CallOption) (*StackExport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StackExport)
+	err := c.cc.Invoke(ctx, StackService_ExportStack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) CollectStackGarbage(ctx context.Context, in *CollectStackGarbageRequest, opts ...grpc.CallOption) (*CollectStackGarbageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectStackGarbageResponse)
+	err := c.cc.Invoke(ctx, StackService_CollectStackGarbage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) GetStackEvents(ctx context.Context, in *GetStackEventsRequest, opts ...grpc.CallOption) (*GetStackEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStackEventsResponse)
+	err := c.cc.Invoke(ctx, StackService_GetStackEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) DiffStackFiles(ctx context.Context, in *DiffStackFilesRequest, opts ...grpc.CallOption) (*DiffStackFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffStackFilesResponse)
+	err := c.cc.Invoke(ctx, StackService_DiffStackFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) UploadStackFiles(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[StackFileChunk, UploadStackFilesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[4], StackService_UploadStackFiles_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StackFileChunk, UploadStackFilesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_UploadStackFilesClient = grpc.ClientStreamingClient[StackFileChunk, UploadStackFilesResponse]
+
+// StackServiceServer is the server API for StackService service.
+// All implementations must embed UnimplementedStackServiceServer
+// for forward compatibility.
+//
+// Stack Management Service
+type StackServiceServer interface {
+	ListStacks(context.Context, *ListStacksRequest) (*ListStacksResponse, error)
+	GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error)
+	ApplyStack(*ApplyStackRequest, grpc.ServerStreamingServer[OperationEvent]) error
+	RemoveStack(*RemoveStackRequest, grpc.ServerStreamingServer[OperationEvent]) error
+	DiffStack(context.Context, *DiffStackRequest) (*DiffStackResponse, error)
+	GetStackLogs(*GetStackLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
+	// GetStackLogsBatched streams the same entries grouped into frames of up
+	// to batch_size entries or batch_interval_ms, whichever comes first
+	GetStackLogsBatched(*GetStackLogsRequest, grpc.ServerStreamingServer[LogBatch]) error
+	// ExportStack returns what is stored for a stack and how it runs, for
+	// backup, review or applying it on another agent
+	ExportStack(context.Context, *ExportStackRequest) (*StackExport, error)
+	// CollectStackGarbage finds what stacks left behind on an agent: stack
+	// directories without containers, containers of stacks whose directory is
+	// gone and stale backup or .env files. With apply set they are removed.
+	CollectStackGarbage(context.Context, *CollectStackGarbageRequest) (*CollectStackGarbageResponse, error)
+	// GetStackEvents returns a stack's timeline: applies with what they
+	// changed, scaling, removals, crashes, restarts, health changes and
+	// certificate renewals, oldest first. It outlives the stack's removal.
+	GetStackEvents(context.Context, *GetStackEventsRequest) (*GetStackEventsResponse, error)
+	// DiffStackFiles compares the hashes of the files a caller means to apply
+	// with those in the stack's directory and returns the ones to upload
+	DiffStackFiles(context.Context, *DiffStackFilesRequest) (*DiffStackFilesResponse, error)
+	// UploadStackFiles stages files for the next apply listing them. A file's
+	// chunks follow each other; a chunk with a path starts the next file.
+	UploadStackFiles(grpc.ClientStreamingServer[StackFileChunk, UploadStackFilesResponse]) error
+	mustEmbedUnimplementedStackServiceServer()
+}
+
+// UnimplementedStackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStackServiceServer struct{}
+
+func (UnimplementedStackServiceServer) ListStacks(context.Context, *ListStacksRequest) (*ListStacksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListStacks not implemented")
+}
+func (UnimplementedStackServiceServer) GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStack not implemented")
+}
+func (UnimplementedStackServiceServer) ApplyStack(*ApplyStackRequest, grpc.ServerStreamingServer[OperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method ApplyStack not implemented")
+}
+func (UnimplementedStackServiceServer) RemoveStack(*RemoveStackRequest, grpc.ServerStreamingServer[OperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method RemoveStack not implemented")
+}
+func (UnimplementedStackServiceServer) DiffStack(context.Context, *DiffStackRequest) (*DiffStackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffStack not implemented")
+}
+func (UnimplementedStackServiceServer) GetStackLogs(*GetStackLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Error(codes.Unimplemented, "method GetStackLogs not implemented")
+}
+func (UnimplementedStackServiceServer) GetStackLogsBatched(*GetStackLogsRequest, grpc.ServerStreamingServer[LogBatch]) error {
+	return status.Error(codes.Unimplemented, "method GetStackLogsBatched not implemented")
+}
+func (UnimplementedStackServiceServer) ExportStack(context.Context, *ExportStackRequest) (*StackExport, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExportStack not implemented")
+}
+func (UnimplementedStackServiceServer) CollectStackGarbage(context.Context, *CollectStackGarbageRequest) (*CollectStackGarbageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CollectStackGarbage not implemented")
+}
+func (UnimplementedStackServiceServer) GetStackEvents(context.Context, *GetStackEventsRequest) (*GetStackEventsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStackEvents not implemented")
+}
+func (UnimplementedStackServiceServer) DiffStackFiles(context.Context, *DiffStackFilesRequest) (*DiffStackFilesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffStackFiles not implemented")
+}
+func (UnimplementedStackServiceServer) UploadStackFiles(grpc.ClientStreamingServer[StackFileChunk, UploadStackFilesResponse]) error {
+	return status.Error(codes.Unimplemented, "method UploadStackFiles not implemented")
+}
+func (UnimplementedStackServiceServer) mustEmbedUnimplementedStackServiceServer() {}
+func (UnimplementedStackServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeStackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StackServiceServer will
+// result in compilation errors.
+type UnsafeStackServiceServer interface {
+	mustEmbedUnimplementedStackServiceServer()
+}
+
+func RegisterStackServiceServer(s grpc.ServiceRegistrar, srv StackServiceServer) {
+	// If the following call panics, it indicates UnimplementedStackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StackService_ServiceDesc, srv)
+}
+
+func _StackService_ListStacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).ListStacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_ListStacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).ListStacks(ctx, req.(*ListStacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_GetStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).GetStack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_GetStack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).GetStack(ctx, req.(*GetStackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_ApplyStack_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ApplyStackRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StackServiceServer).ApplyStack(m, &grpc.GenericServerStream[ApplyStackRequest, OperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_ApplyStackServer = grpc.ServerStreamingServer[OperationEvent]
+
+func _StackService_RemoveStack_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RemoveStackRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StackServiceServer).RemoveStack(m, &grpc.GenericServerStream[RemoveStackRequest, OperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_RemoveStackServer = grpc.ServerStreamingServer[OperationEvent]
+
+func _StackService_DiffStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffStackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).DiffStack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_DiffStack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).DiffStack(ctx, req.(*DiffStackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_GetStackLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetStackLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StackServiceServer).GetStackLogs(m, &grpc.GenericServerStream[GetStackLogsRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_GetStackLogsServer = grpc.ServerStreamingServer[LogEntry]
+
+func _StackService_GetStackLogsBatched_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetStackLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StackServiceServer).GetStackLogsBatched(m, &grpc.GenericServerStream[GetStackLogsRequest, LogBatch]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_GetStackLogsBatchedServer = grpc.ServerStreamingServer[LogBatch]
+
+func _StackService_ExportStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportStackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).ExportStack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_ExportStack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).ExportStack(ctx, req.(*ExportStackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_CollectStackGarbage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectStackGarbageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).CollectStackGarbage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_CollectStackGarbage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).CollectStackGarbage(ctx, req.(*CollectStackGarbageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_GetStackEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStackEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).GetStackEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_GetStackEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).GetStackEvents(ctx, req.(*GetStackEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_DiffStackFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffStackFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).DiffStackFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_DiffStackFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).DiffStackFiles(ctx, req.(*DiffStackFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_UploadStackFiles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StackServiceServer).UploadStackFiles(&grpc.GenericServerStream[StackFileChunk, UploadStackFilesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_UploadStackFilesServer = grpc.ClientStreamingServer[StackFileChunk, UploadStackFilesResponse]
+
+// StackService_ServiceDesc is the grpc.ServiceDesc for StackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.StackService",
+	HandlerType: (*StackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStacks",
+			Handler:    _StackService_ListStacks_Handler,
+		},
+		{
+			MethodName: "GetStack",
+			Handler:    _StackService_GetStack_Handler,
+		},
+		{
+			MethodName: "DiffStack",
+			Handler:    _StackService_DiffStack_Handler,
+		},
+		{
+			MethodName: "ExportStack",
+			Handler:    _StackService_ExportStack_Handler,
+		},
+		{
+			MethodName: "CollectStackGarbage",
+			Handler:    _StackService_CollectStackGarbage_Handler,
+		},
+		{
+			MethodName: "GetStackEvents",
+			Handler:    _StackService_GetStackEvents_Handler,
+		},
+		{
+			MethodName: "DiffStackFiles",
+			Handler:    _StackService_DiffStackFiles_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ApplyStack",
+			Handler:       _StackService_ApplyStack_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RemoveStack",
+			Handler:       _StackService_RemoveStack_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetStackLogs",
+			Handler:       _StackService_GetStackLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetStackLogsBatched",
+			Handler:       _StackService_GetStackLogsBatched_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadStackFiles",
+			Handler:       _StackService_UploadStackFiles_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/v1/agent.proto",
+}
+
+const (
+	ContainerService_ListContainers_FullMethodName   = "/mandau.agent.v1.ContainerService/ListContainers"
+	ContainerService_InspectContainer_FullMethodName = "/mandau.agent.v1.ContainerService/InspectContainer"
+	ContainerService_StreamLogs_FullMethodName       = "/mandau.agent.v1.ContainerService/StreamLogs"
+	ContainerService_Exec_FullMethodName             = "/mandau.agent.v1.ContainerService/Exec"
+	ContainerService_GetStats_FullMethodName         = "/mandau.agent.v1.ContainerService/GetStats"
+	ContainerService_StartContainer_FullMethodName   = "/mandau.agent.v1.ContainerService/StartContainer"
+	ContainerService_StopContainer_FullMethodName    = "/mandau.agent.v1.ContainerService/StopContainer"
+	ContainerService_RestartContainer_FullMethodName = "/mandau.agent.v1.ContainerService/RestartContainer"
+)
+
+// ContainerServiceClient is the client API for ContainerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Container Management Service
+type ContainerServiceClient interface {
+	ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error)
+	InspectContainer(ctx context.Context, in *InspectContainerRequest, opts ...grpc.CallOption) (*InspectContainerResponse, error)
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
+	Exec(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerStats], error)
+	StartContainer(ctx context.Context, in *StartContainerRequest, opts ...grpc.CallOption) (*StartContainerResponse, error)
+	StopContainer(ctx context.Context, in *StopContainerRequest, opts ...grpc.CallOption) (*StopContainerResponse, error)
+	RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error)
+}
+
+type containerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContainerServiceClient(cc grpc.ClientConnInterface) ContainerServiceClient {
+	return &containerServiceClient{cc}
+}
+
+func (c *containerServiceClient) ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListContainersResponse)
+	err := c.cc.Invoke(ctx, ContainerService_ListContainers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServiceClient) InspectContainer(ctx context.Context, in *InspectContainerRequest, opts ...grpc.CallOption) (*InspectContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerService_InspectContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServiceClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[0], ContainerService_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_StreamLogsClient = grpc.ServerStreamingClient[LogEntry]
+
+func (c *containerServiceClient) Exec(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[1], ContainerService_Exec_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecRequest, ExecResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_ExecClient = grpc.BidiStreamingClient[ExecRequest, ExecResponse]
+
+func (c *containerServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerStats], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[2], ContainerService_GetStats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetStatsRequest, ContainerStats]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_GetStatsClient = grpc.ServerStreamingClient[ContainerStats]
+
+func (c *containerServiceClient) StartContainer(ctx context.Context, in *StartContainerRequest, opts ...grpc.CallOption) (*StartContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerService_StartContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServiceClient) StopContainer(ctx context.Context, in *StopContainerRequest, opts ...grpc.CallOption) (*StopContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerService_StopContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServiceClient) RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartContainerResponse)
+	err := c.cc.Invoke(ctx, ContainerService_RestartContainer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContainerServiceServer is the server API for ContainerService service.
+// All implementations must embed UnimplementedContainerServiceServer
+// for forward compatibility.
+//
+// Container Management Service
+type ContainerServiceServer interface {
+	ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error)
+	InspectContainer(context.Context, *InspectContainerRequest) (*InspectContainerResponse, error)
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
+	Exec(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error
+	GetStats(*GetStatsRequest, grpc.ServerStreamingServer[ContainerStats]) error
+	StartContainer(context.Context, *StartContainerRequest) (*StartContainerResponse, error)
+	StopContainer(context.Context, *StopContainerRequest) (*StopContainerResponse, error)
+	RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error)
+	mustEmbedUnimplementedContainerServiceServer()
+}
+
+// UnimplementedContainerServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedCoreServiceServer struct{}
+type UnimplementedContainerServiceServer struct{}
 
-func (UnimplementedCoreServiceServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method ListAgents not implemented")
+func (UnimplementedContainerServiceServer) ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListContainers not implemented")
 }
-func (UnimplementedCoreServiceServer) RegisterAgent(context.Context, *RegisterRequest) (*RegisterResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method RegisterAgent not implemented")
+func (UnimplementedContainerServiceServer) InspectContainer(context.Context, *InspectContainerRequest) (*InspectContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method InspectContainer not implemented")
 }
-func (UnimplementedCoreServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+func (UnimplementedContainerServiceServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Error(codes.Unimplemented, "method StreamLogs not implemented")
 }
-func (UnimplementedCoreServiceServer) mustEmbedUnimplementedCoreServiceServer() {}
-func (UnimplementedCoreServiceServer) testEmbeddedByValue()                     {}
+func (UnimplementedContainerServiceServer) Exec(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error {
+	return status.Error(codes.Unimplemented, "method Exec not implemented")
+}
+func (UnimplementedContainerServiceServer) GetStats(*GetStatsRequest, grpc.ServerStreamingServer[ContainerStats]) error {
+	return status.Error(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedContainerServiceServer) StartContainer(context.Context, *StartContainerRequest) (*StartContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartContainer not implemented")
+}
+func (UnimplementedContainerServiceServer) StopContainer(context.Context, *StopContainerRequest) (*StopContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopContainer not implemented")
+}
+func (UnimplementedContainerServiceServer) RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestartContainer not implemented")
+}
+func (UnimplementedContainerServiceServer) mustEmbedUnimplementedContainerServiceServer() {}
+func (UnimplementedContainerServiceServer) testEmbeddedByValue()                          {}
 
-// UnsafeCoreServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to CoreServiceServer will
+// UnsafeContainerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContainerServiceServer will
 // result in compilation errors.
-type UnsafeCoreServiceServer interface {
-	mustEmbedUnimplementedCoreServiceServer()
+type UnsafeContainerServiceServer interface {
+	mustEmbedUnimplementedContainerServiceServer()
 }
 
-func RegisterCoreServiceServer(s grpc.ServiceRegistrar, srv CoreServiceServer) {
-	// If the following call panics, it indicates UnimplementedCoreServiceServer was
+func RegisterContainerServiceServer(s grpc.ServiceRegistrar, srv ContainerServiceServer) {
+	// If the following call panics, it indicates UnimplementedContainerServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&CoreService_ServiceDesc, srv)
+	s.RegisterService(&ContainerService_ServiceDesc, srv)
 }
 
-func _CoreService_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListAgentsRequest)
+func _ContainerService_ListContainers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContainersRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CoreServiceServer).ListAgents(ctx, in)
+		return srv.(ContainerServiceServer).ListContainers(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CoreService_ListAgents_FullMethodName,
+		FullMethod: ContainerService_ListContainers_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CoreServiceServer).ListAgents(ctx, req.(*ListAgentsRequest))
+		return srv.(ContainerServiceServer).ListContainers(ctx, req.(*ListContainersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CoreService_RegisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterRequest)
+func _ContainerService_InspectContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectContainerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CoreServiceServer).RegisterAgent(ctx, in)
+		return srv.(ContainerServiceServer).InspectContainer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CoreService_RegisterAgent_FullMethodName,
+		FullMethod: ContainerService_InspectContainer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CoreServiceServer).RegisterAgent(ctx, req.(*RegisterRequest))
+		return srv.(ContainerServiceServer).InspectContainer(ctx, req.(*InspectContainerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CoreService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HeartbeatRequest)
+func _ContainerService_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerServiceServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_StreamLogsServer = grpc.ServerStreamingServer[LogEntry]
+
+func _ContainerService_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContainerServiceServer).Exec(&grpc.GenericServerStream[ExecRequest, ExecResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_ExecServer = grpc.BidiStreamingServer[ExecRequest, ExecResponse]
+
+func _ContainerService_GetStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerServiceServer).GetStats(m, &grpc.GenericServerStream[GetStatsRequest, ContainerStats]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContainerService_GetStatsServer = grpc.ServerStreamingServer[ContainerStats]
+
+func _ContainerService_StartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartContainerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CoreServiceServer).Heartbeat(ctx, in)
+		return srv.(ContainerServiceServer).StartContainer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CoreService_Heartbeat_FullMethodName,
+		FullMethod: ContainerService_StartContainer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CoreServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+		return srv.(ContainerServiceServer).StartContainer(ctx, req.(*StartContainerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// CoreService_ServiceDesc is the grpc.ServiceDesc for CoreService service.
+func _ContainerService_StopContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServiceServer).StopContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerService_StopContainer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServiceServer).StopContainer(ctx, req.(*StopContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerService_RestartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServiceServer).RestartContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContainerService_RestartContainer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServiceServer).RestartContainer(ctx, req.(*RestartContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ContainerService_ServiceDesc is the grpc.ServiceDesc for ContainerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var CoreService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.agent.v1.CoreService",
-	HandlerType: (*CoreServiceServer)(nil),
+var ContainerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.ContainerService",
+	HandlerType: (*ContainerServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ListAgents",
-			Handler:    _CoreService_ListAgents_Handler,
+			MethodName: "ListContainers",
+			Handler:    _ContainerService_ListContainers_Handler,
 		},
 		{
-			MethodName: "RegisterAgent",
-			Handler:    _CoreService_RegisterAgent_Handler,
+			MethodName: "InspectContainer",
+			Handler:    _ContainerService_InspectContainer_Handler,
 		},
 		{
-			MethodName: "Heartbeat",
-			Handler:    _CoreService_Heartbeat_Handler,
+			MethodName: "StartContainer",
+			Handler:    _ContainerService_StartContainer_Handler,
+		},
+		{
+			MethodName: "StopContainer",
+			Handler:    _ContainerService_StopContainer_Handler,
+		},
+		{
+			MethodName: "RestartContainer",
+			Handler:    _ContainerService_RestartContainer_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _ContainerService_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Exec",
+			Handler:       _ContainerService_Exec_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetStats",
+			Handler:       _ContainerService_GetStats_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/agent.proto",
 }
 
 const (
-	AgentService_Register_FullMethodName        = "/mandau.agent.v1.AgentService/Register"
-	AgentService_Heartbeat_FullMethodName       = "/mandau.agent.v1.AgentService/Heartbeat"
-	AgentService_GetCapabilities_FullMethodName = "/mandau.agent.v1.AgentService/GetCapabilities"
-	AgentService_GetHealth_FullMethodName       = "/mandau.agent.v1.AgentService/GetHealth"
+	FilesystemService_ListFiles_FullMethodName       = "/mandau.agent.v1.FilesystemService/ListFiles"
+	FilesystemService_ReadFile_FullMethodName        = "/mandau.agent.v1.FilesystemService/ReadFile"
+	FilesystemService_WriteFile_FullMethodName       = "/mandau.agent.v1.FilesystemService/WriteFile"
+	FilesystemService_DeleteFile_FullMethodName      = "/mandau.agent.v1.FilesystemService/DeleteFile"
+	FilesystemService_CreateDirectory_FullMethodName = "/mandau.agent.v1.FilesystemService/CreateDirectory"
 )
 
-// AgentServiceClient is the client API for AgentService service.
+// FilesystemServiceClient is the client API for FilesystemService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Agent Identity & Lifecycle Service
-type AgentServiceClient interface {
-	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
-	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
-	GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesResponse, error)
-	GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+// Filesystem Service
+type FilesystemServiceClient interface {
+	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error)
+	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error)
+	WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error)
+	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error)
+	CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*CreateDirectoryResponse, error)
 }
 
-type agentServiceClient struct {
+type filesystemServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewAgentServiceClient(cc grpc.ClientConnInterface) AgentServiceClient {
-	return &agentServiceClient{cc}
+func NewFilesystemServiceClient(cc grpc.ClientConnInterface) FilesystemServiceClient {
+	return &filesystemServiceClient{cc}
 }
 
-func (c *agentServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+func (c *filesystemServiceClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(RegisterResponse)
-	err := c.cc.Invoke(ctx, AgentService_Register_FullMethodName, in, out, cOpts...)
+	out := new(ListFilesResponse)
+	err := c.cc.Invoke(ctx, FilesystemService_ListFiles_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *agentServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+func (c *filesystemServiceClient) ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(HeartbeatResponse)
-	err := c.cc.Invoke(ctx, AgentService_Heartbeat_FullMethodName, in, out, cOpts...)
+	out := new(ReadFileResponse)
+	err := c.cc.Invoke(ctx, FilesystemService_ReadFile_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *agentServiceClient) GetCapabilities(ctx context.Context, in *CapabilitiesRequest, opts ...grpc.CallOption) (*CapabilitiesResponse, error) {
+func (c *filesystemServiceClient) WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(CapabilitiesResponse)
-	err := c.cc.Invoke(ctx, AgentService_GetCapabilities_FullMethodName, in, out, cOpts...)
+	out := new(WriteFileResponse)
+	err := c.cc.Invoke(ctx, FilesystemService_WriteFile_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *agentServiceClient) GetHealth(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+func (c *filesystemServiceClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(HealthResponse)
-	err := c.cc.Invoke(ctx, AgentService_GetHealth_FullMethodName, in, out, cOpts...)
+	out := new(DeleteFileResponse)
+	err := c.cc.Invoke(ctx, FilesystemService_DeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesystemServiceClient) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*CreateDirectoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDirectoryResponse)
+	err := c.cc.Invoke(ctx, FilesystemService_CreateDirectory_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// AgentServiceServer is the server API for AgentService service.
-// All implementations must embed UnimplementedAgentServiceServer
+// FilesystemServiceServer is the server API for FilesystemService service.
+// All implementations must embed UnimplementedFilesystemServiceServer
 // for forward compatibility.
 //
-// Agent Identity & Lifecycle Service
-type AgentServiceServer interface {
-	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
-	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
-	GetCapabilities(context.Context, *CapabilitiesRequest) (*CapabilitiesResponse, error)
-	GetHealth(context.Context, *HealthRequest) (*HealthResponse, error)
-	mustEmbedUnimplementedAgentServiceServer()
+// Filesystem Service
+type FilesystemServiceServer interface {
+	ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error)
+	ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error)
+	WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error)
+	DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error)
+	CreateDirectory(context.Context, *CreateDirectoryRequest) (*CreateDirectoryResponse, error)
+	mustEmbedUnimplementedFilesystemServiceServer()
 }
 
-// UnimplementedAgentServiceServer must be embedded to have
+// UnimplementedFilesystemServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedAgentServiceServer struct{}
+type UnimplementedFilesystemServiceServer struct{}
 
-func (UnimplementedAgentServiceServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Register not implemented")
+func (UnimplementedFilesystemServiceServer) ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListFiles not implemented")
 }
-func (UnimplementedAgentServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+func (UnimplementedFilesystemServiceServer) ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadFile not implemented")
 }
-func (UnimplementedAgentServiceServer) GetCapabilities(context.Context, *CapabilitiesRequest) (*CapabilitiesResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method GetCapabilities not implemented")
+func (UnimplementedFilesystemServiceServer) WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteFile not implemented")
 }
-func (UnimplementedAgentServiceServer) GetHealth(context.Context, *HealthRequest) (*HealthResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method GetHealth not implemented")
+func (UnimplementedFilesystemServiceServer) DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteFile not implemented")
 }
-func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
-func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
+func (UnimplementedFilesystemServiceServer) CreateDirectory(context.Context, *CreateDirectoryRequest) (*CreateDirectoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateDirectory not implemented")
+}
+func (UnimplementedFilesystemServiceServer) mustEmbedUnimplementedFilesystemServiceServer() {}
+func (UnimplementedFilesystemServiceServer) testEmbeddedByValue()                           {}
 
-// UnsafeAgentServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to AgentServiceServer will
+// UnsafeFilesystemServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FilesystemServiceServer will
 // result in compilation errors.
-type UnsafeAgentServiceServer interface {
-	mustEmbedUnimplementedAgentServiceServer()
+type UnsafeFilesystemServiceServer interface {
+	mustEmbedUnimplementedFilesystemServiceServer()
 }
 
-func RegisterAgentServiceServer(s grpc.ServiceRegistrar, srv AgentServiceServer) {
-	// If the following call panics, it indicates UnimplementedAgentServiceServer was
+func RegisterFilesystemServiceServer(s grpc.ServiceRegistrar, srv FilesystemServiceServer) {
+	// If the following call panics, it indicates UnimplementedFilesystemServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&AgentService_ServiceDesc, srv)
+	s.RegisterService(&FilesystemService_ServiceDesc, srv)
 }
 
-func _AgentService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterRequest)
+func _FilesystemService_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFilesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AgentServiceServer).Register(ctx, in)
+		return srv.(FilesystemServiceServer).ListFiles(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AgentService_Register_FullMethodName,
+		FullMethod: FilesystemService_ListFiles_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServiceServer).Register(ctx, req.(*RegisterRequest))
+		return srv.(FilesystemServiceServer).ListFiles(ctx, req.(*ListFilesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AgentService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HeartbeatRequest)
+func _FilesystemService_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AgentServiceServer).Heartbeat(ctx, in)
+		return srv.(FilesystemServiceServer).ReadFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AgentService_Heartbeat_FullMethodName,
+		FullMethod: FilesystemService_ReadFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+		return srv.(FilesystemServiceServer).ReadFile(ctx, req.(*ReadFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AgentService_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CapabilitiesRequest)
+func _FilesystemService_WriteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AgentServiceServer).GetCapabilities(ctx, in)
+		return srv.(FilesystemServiceServer).WriteFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AgentService_GetCapabilities_FullMethodName,
+		FullMethod: FilesystemService_WriteFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServiceServer).GetCapabilities(ctx, req.(*CapabilitiesRequest))
+		return srv.(FilesystemServiceServer).WriteFile(ctx, req.(*WriteFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AgentService_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HealthRequest)
+func _FilesystemService_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AgentServiceServer).GetHealth(ctx, in)
+		return srv.(FilesystemServiceServer).DeleteFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AgentService_GetHealth_FullMethodName,
+		FullMethod: FilesystemService_DeleteFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServiceServer).GetHealth(ctx, req.(*HealthRequest))
+		return srv.(FilesystemServiceServer).DeleteFile(ctx, req.(*DeleteFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
+func _FilesystemService_CreateDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesystemServiceServer).CreateDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesystemService_CreateDirectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesystemServiceServer).CreateDirectory(ctx, req.(*CreateDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FilesystemService_ServiceDesc is the grpc.ServiceDesc for FilesystemService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var AgentService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.agent.v1.AgentService",
-	HandlerType: (*AgentServiceServer)(nil),
+var FilesystemService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.FilesystemService",
+	HandlerType: (*FilesystemServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Register",
-			Handler:    _AgentService_Register_Handler,
+			MethodName: "ListFiles",
+			Handler:    _FilesystemService_ListFiles_Handler,
 		},
 		{
-			MethodName: "Heartbeat",
-			Handler:    _AgentService_Heartbeat_Handler,
+			MethodName: "ReadFile",
+			Handler:    _FilesystemService_ReadFile_Handler,
 		},
 		{
-			MethodName: "GetCapabilities",
-			Handler:    _AgentService_GetCapabilities_Handler,
+			MethodName: "WriteFile",
+			Handler:    _FilesystemService_WriteFile_Handler,
 		},
 		{
-			MethodName: "GetHealth",
-			Handler:    _AgentService_GetHealth_Handler,
+			MethodName: "DeleteFile",
+			Handler:    _FilesystemService_DeleteFile_Handler,
+		},
+		{
+			MethodName: "CreateDirectory",
+			Handler:    _FilesystemService_CreateDirectory_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -421,82 +3008,85 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	StackService_ListStacks_FullMethodName   = "/mandau.agent.v1.StackService/ListStacks"
-	StackService_GetStack_FullMethodName     = "/mandau.agent.v1.StackService/GetStack"
-	StackService_ApplyStack_FullMethodName   = "/mandau.agent.v1.StackService/ApplyStack"
-	StackService_RemoveStack_FullMethodName  = "/mandau.agent.v1.StackService/RemoveStack"
-	StackService_DiffStack_FullMethodName    = "/mandau.agent.v1.StackService/DiffStack"
-	StackService_GetStackLogs_FullMethodName = "/mandau.agent.v1.StackService/GetStackLogs"
+	TransferService_Upload_FullMethodName         = "/mandau.agent.v1.TransferService/Upload"
+	TransferService_GetTransfer_FullMethodName    = "/mandau.agent.v1.TransferService/GetTransfer"
+	TransferService_ListBackups_FullMethodName    = "/mandau.agent.v1.TransferService/ListBackups"
+	TransferService_DownloadBackup_FullMethodName = "/mandau.agent.v1.TransferService/DownloadBackup"
+	TransferService_Fetch_FullMethodName          = "/mandau.agent.v1.TransferService/Fetch"
 )
 
-// StackServiceClient is the client API for StackService service.
+// TransferServiceClient is the client API for TransferService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Stack Management Service
-type StackServiceClient interface {
-	ListStacks(ctx context.Context, in *ListStacksRequest, opts ...grpc.CallOption) (*ListStacksResponse, error)
-	GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error)
-	ApplyStack(ctx context.Context, in *ApplyStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error)
-	RemoveStack(ctx context.Context, in *RemoveStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error)
-	DiffStack(ctx context.Context, in *DiffStackRequest, opts ...grpc.CallOption) (*DiffStackResponse, error)
-	GetStackLogs(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
-}
-
-type stackServiceClient struct {
+// TransferService moves large payloads to and from agents in chunks. An
+// interrupted upload resumes from what the agent holds of it and a download
+// from any offset, so transfers over flaky links do not restart from zero.
+// Agents pace all transfers together to transfers.max_rate.
+type TransferServiceClient interface {
+	// Upload sends a payload from offset, which must be what the agent holds
+	// of it. The first request describes the payload; once all of it is there
+	// the agent checks its digest and delivers it to its target.
+	Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, TransferStatus], error)
+	// GetTransfer returns how much of an upload the agent holds
+	GetTransfer(ctx context.Context, in *GetTransferRequest, opts ...grpc.CallOption) (*TransferStatus, error)
+	// ListBackups lists the files in the agent's backup directories
+	ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error)
+	// DownloadBackup streams a backup file from offset
+	DownloadBackup(ctx context.Context, in *DownloadBackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadChunk], error)
+	// Fetch has the agent fetch an artifact from the core's artifact store by
+	// digest, resuming what it holds of it, and deliver it like an upload
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*TransferStatus, error)
+}
+
+type transferServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewStackServiceClient(cc grpc.ClientConnInterface) StackServiceClient {
-	return &stackServiceClient{cc}
+func NewTransferServiceClient(cc grpc.ClientConnInterface) TransferServiceClient {
+	return &transferServiceClient{cc}
 }
 
-func (c *stackServiceClient) ListStacks(ctx context.Context, in *ListStacksRequest, opts ...grpc.CallOption) (*ListStacksResponse, error) {
+func (c *transferServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, TransferStatus], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListStacksResponse)
-	err := c.cc.Invoke(ctx, StackService_ListStacks_FullMethodName, in, out, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &TransferService_ServiceDesc.Streams[0], TransferService_Upload_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &grpc.GenericClientStream[UploadRequest, TransferStatus]{ClientStream: stream}
+	return x, nil
 }
 
-func (c *stackServiceClient) GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error) {
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransferService_UploadClient = grpc.ClientStreamingClient[UploadRequest, TransferStatus]
+
+func (c *transferServiceClient) GetTransfer(ctx context.Context, in *GetTransferRequest, opts ...grpc.CallOption) (*TransferStatus, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(GetStackResponse)
-	err := c.cc.Invoke(ctx, StackService_GetStack_FullMethodName, in, out, cOpts...)
+	out := new(TransferStatus)
+	err := c.cc.Invoke(ctx, TransferService_GetTransfer_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *stackServiceClient) ApplyStack(ctx context.Context, in *ApplyStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error) {
+func (c *transferServiceClient) ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[0], StackService_ApplyStack_FullMethodName, cOpts...)
+	out := new(ListBackupsResponse)
+	err := c.cc.Invoke(ctx, TransferService_ListBackups_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[ApplyStackRequest, OperationEvent]{ClientStream: stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+	return out, nil
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_ApplyStackClient = grpc.ServerStreamingClient[OperationEvent]
-
-func (c *stackServiceClient) RemoveStack(ctx context.Context, in *RemoveStackRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error) {
+func (c *transferServiceClient) DownloadBackup(ctx context.Context, in *DownloadBackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadChunk], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[1], StackService_RemoveStack_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &TransferService_ServiceDesc.Streams[1], TransferService_DownloadBackup_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[RemoveStackRequest, OperationEvent]{ClientStream: stream}
+	x := &grpc.GenericClientStream[DownloadBackupRequest, DownloadChunk]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -507,219 +3097,187 @@ func (c *stackServiceClient) RemoveStack(ctx context.Context, in *RemoveStackReq
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_RemoveStackClient = grpc.ServerStreamingClient[OperationEvent]
+type TransferService_DownloadBackupClient = grpc.ServerStreamingClient[DownloadChunk]
 
-func (c *stackServiceClient) DiffStack(ctx context.Context, in *DiffStackRequest, opts ...grpc.CallOption) (*DiffStackResponse, error) {
+func (c *transferServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*TransferStatus, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(DiffStackResponse)
-	err := c.cc.Invoke(ctx, StackService_DiffStack_FullMethodName, in, out, cOpts...)
+	out := new(TransferStatus)
+	err := c.cc.Invoke(ctx, TransferService_Fetch_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *stackServiceClient) GetStackLogs(ctx context.Context, in *GetStackLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[2], StackService_GetStackLogs_FullMethodName, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &grpc.GenericClientStream[GetStackLogsRequest, LogEntry]{ClientStream: stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_GetStackLogsClient = grpc.ServerStreamingClient[LogEntry]
-
-// StackServiceServer is the server API for StackService service.
-// All implementations must embed UnimplementedStackServiceServer
+// TransferServiceServer is the server API for TransferService service.
+// All implementations must embed UnimplementedTransferServiceServer
 // for forward compatibility.
 //
-// Stack Management Service
-type StackServiceServer interface {
-	ListStacks(context.Context, *ListStacksRequest) (*ListStacksResponse, error)
-	GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error)
-	ApplyStack(*ApplyStackRequest, grpc.ServerStreamingServer[OperationEvent]) error
-	RemoveStack(*RemoveStackRequest, grpc.ServerStreamingServer[OperationEvent]) error
-	DiffStack(context.Context, *DiffStackRequest) (*DiffStackResponse, error)
-	GetStackLogs(*GetStackLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
-	mustEmbedUnimplementedStackServiceServer()
-}
-
-// UnimplementedStackServiceServer must be embedded to have
+// TransferService moves large payloads to and from agents in chunks. An
+// interrupted upload resumes from what the agent holds of it and a download
+// from any offset, so transfers over flaky links do not restart from zero.
+// Agents pace all transfers together to transfers.max_rate.
+type TransferServiceServer interface {
+	// Upload sends a payload from offset, which must be what the agent holds
+	// of it. The first request describes the payload; once all of it is there
+	// the agent checks its digest and delivers it to its target.
+	Upload(grpc.ClientStreamingServer[UploadRequest, TransferStatus]) error
+	// GetTransfer returns how much of an upload the agent holds
+	GetTransfer(context.Context, *GetTransferRequest) (*TransferStatus, error)
+	// ListBackups lists the files in the agent's backup directories
+	ListBackups(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error)
+	// DownloadBackup streams a backup file from offset
+	DownloadBackup(*DownloadBackupRequest, grpc.ServerStreamingServer[DownloadChunk]) error
+	// Fetch has the agent fetch an artifact from the core's artifact store by
+	// digest, resuming what it holds of it, and deliver it like an upload
+	Fetch(context.Context, *FetchRequest) (*TransferStatus, error)
+	mustEmbedUnimplementedTransferServiceServer()
+}
+
+// UnimplementedTransferServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedStackServiceServer struct{}
+type UnimplementedTransferServiceServer struct{}
 
-func (UnimplementedStackServiceServer) ListStacks(context.Context, *ListStacksRequest) (*ListStacksResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method ListStacks not implemented")
+func (UnimplementedTransferServiceServer) Upload(grpc.ClientStreamingServer[UploadRequest, TransferStatus]) error {
+	return status.Error(codes.Unimplemented, "method Upload not implemented")
 }
-func (UnimplementedStackServiceServer) GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method GetStack not implemented")
-}
-func (UnimplementedStackServiceServer) ApplyStack(*ApplyStackRequest, grpc.ServerStreamingServer[OperationEvent]) error {
-	return status.Error(codes.Unimplemented, "method ApplyStack not implemented")
+func (UnimplementedTransferServiceServer) GetTransfer(context.Context, *GetTransferRequest) (*TransferStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTransfer not implemented")
 }
-func (UnimplementedStackServiceServer) RemoveStack(*RemoveStackRequest, grpc.ServerStreamingServer[OperationEvent]) error {
-	return status.Error(codes.Unimplemented, "method RemoveStack not implemented")
+func (UnimplementedTransferServiceServer) ListBackups(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBackups not implemented")
 }
-func (UnimplementedStackServiceServer) DiffStack(context.Context, *DiffStackRequest) (*DiffStackResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method DiffStack not implemented")
+func (UnimplementedTransferServiceServer) DownloadBackup(*DownloadBackupRequest, grpc.ServerStreamingServer[DownloadChunk]) error {
+	return status.Error(codes.Unimplemented, "method DownloadBackup not implemented")
 }
-func (UnimplementedStackServiceServer) GetStackLogs(*GetStackLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
-	return status.Error(codes.Unimplemented, "method GetStackLogs not implemented")
+func (UnimplementedTransferServiceServer) Fetch(context.Context, *FetchRequest) (*TransferStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method Fetch not implemented")
 }
-func (UnimplementedStackServiceServer) mustEmbedUnimplementedStackServiceServer() {}
-func (UnimplementedStackServiceServer) testEmbeddedByValue()                      {}
+func (UnimplementedTransferServiceServer) mustEmbedUnimplementedTransferServiceServer() {}
+func (UnimplementedTransferServiceServer) testEmbeddedByValue()                         {}
 
-// UnsafeStackServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to StackServiceServer will
+// UnsafeTransferServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransferServiceServer will
 // result in compilation errors.
-type UnsafeStackServiceServer interface {
-	mustEmbedUnimplementedStackServiceServer()
+type UnsafeTransferServiceServer interface {
+	mustEmbedUnimplementedTransferServiceServer()
 }
 
-func RegisterStackServiceServer(s grpc.ServiceRegistrar, srv StackServiceServer) {
-	// If the following call panics, it indicates UnimplementedStackServiceServer was
+func RegisterTransferServiceServer(s grpc.ServiceRegistrar, srv TransferServiceServer) {
+	// If the following call panics, it indicates UnimplementedTransferServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&StackService_ServiceDesc, srv)
+	s.RegisterService(&TransferService_ServiceDesc, srv)
 }
 
-func _StackService_ListStacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListStacksRequest)
+func _TransferService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TransferServiceServer).Upload(&grpc.GenericServerStream[UploadRequest, TransferStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransferService_UploadServer = grpc.ClientStreamingServer[UploadRequest, TransferStatus]
+
+func _TransferService_GetTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransferRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StackServiceServer).ListStacks(ctx, in)
+		return srv.(TransferServiceServer).GetTransfer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: StackService_ListStacks_FullMethodName,
+		FullMethod: TransferService_GetTransfer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StackServiceServer).ListStacks(ctx, req.(*ListStacksRequest))
+		return srv.(TransferServiceServer).GetTransfer(ctx, req.(*GetTransferRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _StackService_GetStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetStackRequest)
+func _TransferService_ListBackups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBackupsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StackServiceServer).GetStack(ctx, in)
+		return srv.(TransferServiceServer).ListBackups(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: StackService_GetStack_FullMethodName,
+		FullMethod: TransferService_ListBackups_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StackServiceServer).GetStack(ctx, req.(*GetStackRequest))
+		return srv.(TransferServiceServer).ListBackups(ctx, req.(*ListBackupsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _StackService_ApplyStack_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ApplyStackRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(StackServiceServer).ApplyStack(m, &grpc.GenericServerStream[ApplyStackRequest, OperationEvent]{ServerStream: stream})
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_ApplyStackServer = grpc.ServerStreamingServer[OperationEvent]
-
-func _StackService_RemoveStack_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(RemoveStackRequest)
+func _TransferService_DownloadBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadBackupRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(StackServiceServer).RemoveStack(m, &grpc.GenericServerStream[RemoveStackRequest, OperationEvent]{ServerStream: stream})
+	return srv.(TransferServiceServer).DownloadBackup(m, &grpc.GenericServerStream[DownloadBackupRequest, DownloadChunk]{ServerStream: stream})
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_RemoveStackServer = grpc.ServerStreamingServer[OperationEvent]
+type TransferService_DownloadBackupServer = grpc.ServerStreamingServer[DownloadChunk]
 
-func _StackService_DiffStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DiffStackRequest)
+func _TransferService_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StackServiceServer).DiffStack(ctx, in)
+		return srv.(TransferServiceServer).Fetch(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: StackService_DiffStack_FullMethodName,
+		FullMethod: TransferService_Fetch_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StackServiceServer).DiffStack(ctx, req.(*DiffStackRequest))
+		return srv.(TransferServiceServer).Fetch(ctx, req.(*FetchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _StackService_GetStackLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(GetStackLogsRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(StackServiceServer).GetStackLogs(m, &grpc.GenericServerStream[GetStackLogsRequest, LogEntry]{ServerStream: stream})
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type StackService_GetStackLogsServer = grpc.ServerStreamingServer[LogEntry]
-
-// StackService_ServiceDesc is the grpc.ServiceDesc for StackService service.
+// TransferService_ServiceDesc is the grpc.ServiceDesc for TransferService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var StackService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.agent.v1.StackService",
-	HandlerType: (*StackServiceServer)(nil),
+var TransferService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.TransferService",
+	HandlerType: (*TransferServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ListStacks",
-			Handler:    _StackService_ListStacks_Handler,
+			MethodName: "GetTransfer",
+			Handler:    _TransferService_GetTransfer_Handler,
 		},
 		{
-			MethodName: "GetStack",
-			Handler:    _StackService_GetStack_Handler,
+			MethodName: "ListBackups",
+			Handler:    _TransferService_ListBackups_Handler,
 		},
 		{
-			MethodName: "DiffStack",
-			Handler:    _StackService_DiffStack_Handler,
+			MethodName: "Fetch",
+			Handler:    _TransferService_Fetch_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "ApplyStack",
-			Handler:       _StackService_ApplyStack_Handler,
-			ServerStreams: true,
-		},
-		{
-			StreamName:    "RemoveStack",
-			Handler:       _StackService_RemoveStack_Handler,
-			ServerStreams: true,
+			StreamName:    "Upload",
+			Handler:       _TransferService_Upload_Handler,
+			ClientStreams: true,
 		},
 		{
-			StreamName:    "GetStackLogs",
-			Handler:       _StackService_GetStackLogs_Handler,
+			StreamName:    "DownloadBackup",
+			Handler:       _TransferService_DownloadBackup_Handler,
 			ServerStreams: true,
 		},
 	},
@@ -727,633 +3285,550 @@ var StackService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	ContainerService_ListContainers_FullMethodName   = "/mandau.agent.v1.ContainerService/ListContainers"
-	ContainerService_InspectContainer_FullMethodName = "/mandau.agent.v1.ContainerService/InspectContainer"
-	ContainerService_StreamLogs_FullMethodName       = "/mandau.agent.v1.ContainerService/StreamLogs"
-	ContainerService_Exec_FullMethodName             = "/mandau.agent.v1.ContainerService/Exec"
-	ContainerService_GetStats_FullMethodName         = "/mandau.agent.v1.ContainerService/GetStats"
-	ContainerService_StartContainer_FullMethodName   = "/mandau.agent.v1.ContainerService/StartContainer"
-	ContainerService_StopContainer_FullMethodName    = "/mandau.agent.v1.ContainerService/StopContainer"
-	ContainerService_RestartContainer_FullMethodName = "/mandau.agent.v1.ContainerService/RestartContainer"
+	ArtifactService_PutArtifact_FullMethodName            = "/mandau.agent.v1.ArtifactService/PutArtifact"
+	ArtifactService_GetArtifact_FullMethodName            = "/mandau.agent.v1.ArtifactService/GetArtifact"
+	ArtifactService_ListArtifacts_FullMethodName          = "/mandau.agent.v1.ArtifactService/ListArtifacts"
+	ArtifactService_DeleteArtifact_FullMethodName         = "/mandau.agent.v1.ArtifactService/DeleteArtifact"
+	ArtifactService_CollectArtifactGarbage_FullMethodName = "/mandau.agent.v1.ArtifactService/CollectArtifactGarbage"
+	ArtifactService_FetchArtifact_FullMethodName          = "/mandau.agent.v1.ArtifactService/FetchArtifact"
 )
 
-// ContainerServiceClient is the client API for ContainerService service.
+// ArtifactServiceClient is the client API for ArtifactService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Container Management Service
-type ContainerServiceClient interface {
-	ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error)
-	InspectContainer(ctx context.Context, in *InspectContainerRequest, opts ...grpc.CallOption) (*InspectContainerResponse, error)
-	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
-	Exec(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error)
-	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerStats], error)
-	StartContainer(ctx context.Context, in *StartContainerRequest, opts ...grpc.CallOption) (*StartContainerResponse, error)
-	StopContainer(ctx context.Context, in *StopContainerRequest, opts ...grpc.CallOption) (*StopContainerResponse, error)
-	RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error)
-}
-
-type containerServiceClient struct {
+// ArtifactService keeps content on the core by its SHA-256 digest: compose
+// bundles, templates, scripts and image bundles. Content deployed to many
+// agents is uploaded to the core once and each agent fetches it by digest.
+// Content pushed again is stored once.
+type ArtifactServiceClient interface {
+	// PutArtifact stores a payload. The first request describes it; the core
+	// checks its digest once all of it is there.
+	PutArtifact(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutArtifactRequest, Artifact], error)
+	GetArtifact(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*Artifact, error)
+	ListArtifacts(ctx context.Context, in *ListArtifactsRequest, opts ...grpc.CallOption) (*ListArtifactsResponse, error)
+	DeleteArtifact(ctx context.Context, in *DeleteArtifactRequest, opts ...grpc.CallOption) (*Artifact, error)
+	// CollectArtifactGarbage removes the unpinned artifacts neither pushed
+	// nor fetched within artifacts.retention
+	CollectArtifactGarbage(ctx context.Context, in *CollectArtifactGarbageRequest, opts ...grpc.CallOption) (*CollectArtifactGarbageResponse, error)
+	// FetchArtifact returns a chunk of an artifact from offset. Agents call
+	// it with their own certificate.
+	FetchArtifact(ctx context.Context, in *FetchArtifactRequest, opts ...grpc.CallOption) (*ArtifactChunk, error)
+}
+
+type artifactServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewContainerServiceClient(cc grpc.ClientConnInterface) ContainerServiceClient {
-	return &containerServiceClient{cc}
-}
-
-func (c *containerServiceClient) ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListContainersResponse)
-	err := c.cc.Invoke(ctx, ContainerService_ListContainers_FullMethodName, in, out, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *containerServiceClient) InspectContainer(ctx context.Context, in *InspectContainerRequest, opts ...grpc.CallOption) (*InspectContainerResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(InspectContainerResponse)
-	err := c.cc.Invoke(ctx, ContainerService_InspectContainer_FullMethodName, in, out, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func NewArtifactServiceClient(cc grpc.ClientConnInterface) ArtifactServiceClient {
+	return &artifactServiceClient{cc}
 }
 
-func (c *containerServiceClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+func (c *artifactServiceClient) PutArtifact(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutArtifactRequest, Artifact], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[0], ContainerService_StreamLogs_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &ArtifactService_ServiceDesc.Streams[0], ArtifactService_PutArtifact_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[StreamLogsRequest, LogEntry]{ClientStream: stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
+	x := &grpc.GenericClientStream[PutArtifactRequest, Artifact]{ClientStream: stream}
 	return x, nil
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_StreamLogsClient = grpc.ServerStreamingClient[LogEntry]
+type ArtifactService_PutArtifactClient = grpc.ClientStreamingClient[PutArtifactRequest, Artifact]
 
-func (c *containerServiceClient) Exec(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error) {
+func (c *artifactServiceClient) GetArtifact(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*Artifact, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[1], ContainerService_Exec_FullMethodName, cOpts...)
+	out := new(Artifact)
+	err := c.cc.Invoke(ctx, ArtifactService_GetArtifact_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[ExecRequest, ExecResponse]{ClientStream: stream}
-	return x, nil
+	return out, nil
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_ExecClient = grpc.BidiStreamingClient[ExecRequest, ExecResponse]
-
-func (c *containerServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ContainerStats], error) {
+func (c *artifactServiceClient) ListArtifacts(ctx context.Context, in *ListArtifactsRequest, opts ...grpc.CallOption) (*ListArtifactsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ContainerService_ServiceDesc.Streams[2], ContainerService_GetStats_FullMethodName, cOpts...)
+	out := new(ListArtifactsResponse)
+	err := c.cc.Invoke(ctx, ArtifactService_ListArtifacts_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[GetStatsRequest, ContainerStats]{ClientStream: stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+	return out, nil
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_GetStatsClient = grpc.ServerStreamingClient[ContainerStats]
-
-func (c *containerServiceClient) StartContainer(ctx context.Context, in *StartContainerRequest, opts ...grpc.CallOption) (*StartContainerResponse, error) {
+func (c *artifactServiceClient) DeleteArtifact(ctx context.Context, in *DeleteArtifactRequest, opts ...grpc.CallOption) (*Artifact, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(StartContainerResponse)
-	err := c.cc.Invoke(ctx, ContainerService_StartContainer_FullMethodName, in, out, cOpts...)
+	out := new(Artifact)
+	err := c.cc.Invoke(ctx, ArtifactService_DeleteArtifact_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *containerServiceClient) StopContainer(ctx context.Context, in *StopContainerRequest, opts ...grpc.CallOption) (*StopContainerResponse, error) {
+func (c *artifactServiceClient) CollectArtifactGarbage(ctx context.Context, in *CollectArtifactGarbageRequest, opts ...grpc.CallOption) (*CollectArtifactGarbageResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(StopContainerResponse)
-	err := c.cc.Invoke(ctx, ContainerService_StopContainer_FullMethodName, in, out, cOpts...)
+	out := new(CollectArtifactGarbageResponse)
+	err := c.cc.Invoke(ctx, ArtifactService_CollectArtifactGarbage_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *containerServiceClient) RestartContainer(ctx context.Context, in *RestartContainerRequest, opts ...grpc.CallOption) (*RestartContainerResponse, error) {
+func (c *artifactServiceClient) FetchArtifact(ctx context.Context, in *FetchArtifactRequest, opts ...grpc.CallOption) (*ArtifactChunk, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(RestartContainerResponse)
-	err := c.cc.Invoke(ctx, ContainerService_RestartContainer_FullMethodName, in, out, cOpts...)
+	out := new(ArtifactChunk)
+	err := c.cc.Invoke(ctx, ArtifactService_FetchArtifact_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// ContainerServiceServer is the server API for ContainerService service.
-// All implementations must embed UnimplementedContainerServiceServer
+// ArtifactServiceServer is the server API for ArtifactService service.
+// All implementations must embed UnimplementedArtifactServiceServer
 // for forward compatibility.
 //
-// Container Management Service
-type ContainerServiceServer interface {
-	ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error)
-	InspectContainer(context.Context, *InspectContainerRequest) (*InspectContainerResponse, error)
-	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
-	Exec(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error
-	GetStats(*GetStatsRequest, grpc.ServerStreamingServer[ContainerStats]) error
-	StartContainer(context.Context, *StartContainerRequest) (*StartContainerResponse, error)
-	StopContainer(context.Context, *StopContainerRequest) (*StopContainerResponse, error)
-	RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error)
-	mustEmbedUnimplementedContainerServiceServer()
-}
-
-// UnimplementedContainerServiceServer must be embedded to have
+// ArtifactService keeps content on the core by its SHA-256 digest: compose
+// bundles, templates, scripts and image bundles. Content deployed to many
+// agents is uploaded to the core once and each agent fetches it by digest.
+// Content pushed again is stored once.
+type ArtifactServiceServer interface {
+	// PutArtifact stores a payload. The first request describes it; the core
+	// checks its digest once all of it is there.
+	PutArtifact(grpc.ClientStreamingServer[PutArtifactRequest, Artifact]) error
+	GetArtifact(context.Context, *GetArtifactRequest) (*Artifact, error)
+	ListArtifacts(context.Context, *ListArtifactsRequest) (*ListArtifactsResponse, error)
+	DeleteArtifact(context.Context, *DeleteArtifactRequest) (*Artifact, error)
+	// CollectArtifactGarbage removes the unpinned artifacts neither pushed
+	// nor fetched within artifacts.retention
+	CollectArtifactGarbage(context.Context, *CollectArtifactGarbageRequest) (*CollectArtifactGarbageResponse, error)
+	// FetchArtifact returns a chunk of an artifact from offset. Agents call
+	// it with their own certificate.
+	FetchArtifact(context.Context, *FetchArtifactRequest) (*ArtifactChunk, error)
+	mustEmbedUnimplementedArtifactServiceServer()
+}
+
+// UnimplementedArtifactServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedContainerServiceServer struct{}
+type UnimplementedArtifactServiceServer struct{}
 
-func (UnimplementedContainerServiceServer) ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method ListContainers not implemented")
-}
-func (UnimplementedContainerServiceServer) InspectContainer(context.Context, *InspectContainerRequest) (*InspectContainerResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method InspectContainer not implemented")
-}
-func (UnimplementedContainerServiceServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
-	return status.Error(codes.Unimplemented, "method StreamLogs not implemented")
+func (UnimplementedArtifactServiceServer) PutArtifact(grpc.ClientStreamingServer[PutArtifactRequest, Artifact]) error {
+	return status.Error(codes.Unimplemented, "method PutArtifact not implemented")
 }
-func (UnimplementedContainerServiceServer) Exec(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error {
-	return status.Error(codes.Unimplemented, "method Exec not implemented")
+func (UnimplementedArtifactServiceServer) GetArtifact(context.Context, *GetArtifactRequest) (*Artifact, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetArtifact not implemented")
 }
-func (UnimplementedContainerServiceServer) GetStats(*GetStatsRequest, grpc.ServerStreamingServer[ContainerStats]) error {
-	return status.Error(codes.Unimplemented, "method GetStats not implemented")
+func (UnimplementedArtifactServiceServer) ListArtifacts(context.Context, *ListArtifactsRequest) (*ListArtifactsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListArtifacts not implemented")
 }
-func (UnimplementedContainerServiceServer) StartContainer(context.Context, *StartContainerRequest) (*StartContainerResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method StartContainer not implemented")
+func (UnimplementedArtifactServiceServer) DeleteArtifact(context.Context, *DeleteArtifactRequest) (*Artifact, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteArtifact not implemented")
 }
-func (UnimplementedContainerServiceServer) StopContainer(context.Context, *StopContainerRequest) (*StopContainerResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method StopContainer not implemented")
+func (UnimplementedArtifactServiceServer) CollectArtifactGarbage(context.Context, *CollectArtifactGarbageRequest) (*CollectArtifactGarbageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CollectArtifactGarbage not implemented")
 }
-func (UnimplementedContainerServiceServer) RestartContainer(context.Context, *RestartContainerRequest) (*RestartContainerResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method RestartContainer not implemented")
+func (UnimplementedArtifactServiceServer) FetchArtifact(context.Context, *FetchArtifactRequest) (*ArtifactChunk, error) {
+	return nil, status.Error(codes.Unimplemented, "method FetchArtifact not implemented")
 }
-func (UnimplementedContainerServiceServer) mustEmbedUnimplementedContainerServiceServer() {}
-func (UnimplementedContainerServiceServer) testEmbeddedByValue()                          {}
+func (UnimplementedArtifactServiceServer) mustEmbedUnimplementedArtifactServiceServer() {}
+func (UnimplementedArtifactServiceServer) testEmbeddedByValue()                         {}
 
-// UnsafeContainerServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to ContainerServiceServer will
+// UnsafeArtifactServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArtifactServiceServer will
 // result in compilation errors.
-type UnsafeContainerServiceServer interface {
-	mustEmbedUnimplementedContainerServiceServer()
+type UnsafeArtifactServiceServer interface {
+	mustEmbedUnimplementedArtifactServiceServer()
 }
 
-func RegisterContainerServiceServer(s grpc.ServiceRegistrar, srv ContainerServiceServer) {
-	// If the following call panics, it indicates UnimplementedContainerServiceServer was
+func RegisterArtifactServiceServer(s grpc.ServiceRegistrar, srv ArtifactServiceServer) {
+	// If the following call panics, it indicates UnimplementedArtifactServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&ContainerService_ServiceDesc, srv)
+	s.RegisterService(&ArtifactService_ServiceDesc, srv)
 }
 
-func _ContainerService_ListContainers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListContainersRequest)
+func _ArtifactService_PutArtifact_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ArtifactServiceServer).PutArtifact(&grpc.GenericServerStream[PutArtifactRequest, Artifact]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ArtifactService_PutArtifactServer = grpc.ClientStreamingServer[PutArtifactRequest, Artifact]
+
+func _ArtifactService_GetArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServiceServer).ListContainers(ctx, in)
+		return srv.(ArtifactServiceServer).GetArtifact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ContainerService_ListContainers_FullMethodName,
+		FullMethod: ArtifactService_GetArtifact_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServiceServer).ListContainers(ctx, req.(*ListContainersRequest))
+		return srv.(ArtifactServiceServer).GetArtifact(ctx, req.(*GetArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerService_InspectContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InspectContainerRequest)
+func _ArtifactService_ListArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArtifactsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServiceServer).InspectContainer(ctx, in)
+		return srv.(ArtifactServiceServer).ListArtifacts(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ContainerService_InspectContainer_FullMethodName,
+		FullMethod: ArtifactService_ListArtifacts_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServiceServer).InspectContainer(ctx, req.(*InspectContainerRequest))
+		return srv.(ArtifactServiceServer).ListArtifacts(ctx, req.(*ListArtifactsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerService_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamLogsRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(ContainerServiceServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogEntry]{ServerStream: stream})
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_StreamLogsServer = grpc.ServerStreamingServer[LogEntry]
-
-func _ContainerService_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ContainerServiceServer).Exec(&grpc.GenericServerStream[ExecRequest, ExecResponse]{ServerStream: stream})
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_ExecServer = grpc.BidiStreamingServer[ExecRequest, ExecResponse]
-
-func _ContainerService_GetStats_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(GetStatsRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(ContainerServiceServer).GetStats(m, &grpc.GenericServerStream[GetStatsRequest, ContainerStats]{ServerStream: stream})
-}
-
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ContainerService_GetStatsServer = grpc.ServerStreamingServer[ContainerStats]
-
-func _ContainerService_StartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StartContainerRequest)
+func _ArtifactService_DeleteArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServiceServer).StartContainer(ctx, in)
+		return srv.(ArtifactServiceServer).DeleteArtifact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ContainerService_StartContainer_FullMethodName,
+		FullMethod: ArtifactService_DeleteArtifact_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServiceServer).StartContainer(ctx, req.(*StartContainerRequest))
+		return srv.(ArtifactServiceServer).DeleteArtifact(ctx, req.(*DeleteArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerService_StopContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StopContainerRequest)
+func _ArtifactService_CollectArtifactGarbage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectArtifactGarbageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServiceServer).StopContainer(ctx, in)
+		return srv.(ArtifactServiceServer).CollectArtifactGarbage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ContainerService_StopContainer_FullMethodName,
+		FullMethod: ArtifactService_CollectArtifactGarbage_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServiceServer).StopContainer(ctx, req.(*StopContainerRequest))
+		return srv.(ArtifactServiceServer).CollectArtifactGarbage(ctx, req.(*CollectArtifactGarbageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerService_RestartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RestartContainerRequest)
+func _ArtifactService_FetchArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchArtifactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServiceServer).RestartContainer(ctx, in)
+		return srv.(ArtifactServiceServer).FetchArtifact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ContainerService_RestartContainer_FullMethodName,
+		FullMethod: ArtifactService_FetchArtifact_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServiceServer).RestartContainer(ctx, req.(*RestartContainerRequest))
+		return srv.(ArtifactServiceServer).FetchArtifact(ctx, req.(*FetchArtifactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// ContainerService_ServiceDesc is the grpc.ServiceDesc for ContainerService service.
+// ArtifactService_ServiceDesc is the grpc.ServiceDesc for ArtifactService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var ContainerService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.agent.v1.ContainerService",
-	HandlerType: (*ContainerServiceServer)(nil),
+var ArtifactService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.ArtifactService",
+	HandlerType: (*ArtifactServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ListContainers",
-			Handler:    _ContainerService_ListContainers_Handler,
+			MethodName: "GetArtifact",
+			Handler:    _ArtifactService_GetArtifact_Handler,
 		},
 		{
-			MethodName: "InspectContainer",
-			Handler:    _ContainerService_InspectContainer_Handler,
+			MethodName: "ListArtifacts",
+			Handler:    _ArtifactService_ListArtifacts_Handler,
 		},
-		{
-			MethodName: "StartContainer",
-			Handler:    _ContainerService_StartContainer_Handler,
+		{
+			MethodName: "DeleteArtifact",
+			Handler:    _ArtifactService_DeleteArtifact_Handler,
 		},
 		{
-			MethodName: "StopContainer",
-			Handler:    _ContainerService_StopContainer_Handler,
+			MethodName: "CollectArtifactGarbage",
+			Handler:    _ArtifactService_CollectArtifactGarbage_Handler,
 		},
 		{
-			MethodName: "RestartContainer",
-			Handler:    _ContainerService_RestartContainer_Handler,
+			MethodName: "FetchArtifact",
+			Handler:    _ArtifactService_FetchArtifact_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "StreamLogs",
-			Handler:       _ContainerService_StreamLogs_Handler,
-			ServerStreams: true,
-		},
-		{
-			StreamName:    "Exec",
-			Handler:       _ContainerService_Exec_Handler,
-			ServerStreams: true,
+			StreamName:    "PutArtifact",
+			Handler:       _ArtifactService_PutArtifact_Handler,
 			ClientStreams: true,
 		},
-		{
-			StreamName:    "GetStats",
-			Handler:       _ContainerService_GetStats_Handler,
-			ServerStreams: true,
-		},
 	},
 	Metadata: "api/v1/agent.proto",
 }
 
 const (
-	FilesystemService_ListFiles_FullMethodName       = "/mandau.agent.v1.FilesystemService/ListFiles"
-	FilesystemService_ReadFile_FullMethodName        = "/mandau.agent.v1.FilesystemService/ReadFile"
-	FilesystemService_WriteFile_FullMethodName       = "/mandau.agent.v1.FilesystemService/WriteFile"
-	FilesystemService_DeleteFile_FullMethodName      = "/mandau.agent.v1.FilesystemService/DeleteFile"
-	FilesystemService_CreateDirectory_FullMethodName = "/mandau.agent.v1.FilesystemService/CreateDirectory"
+	AdminService_GetProfile_FullMethodName      = "/mandau.agent.v1.AdminService/GetProfile"
+	AdminService_ListConnections_FullMethodName = "/mandau.agent.v1.AdminService/ListConnections"
+	AdminService_GetRegistry_FullMethodName     = "/mandau.agent.v1.AdminService/GetRegistry"
+	AdminService_GetConfig_FullMethodName       = "/mandau.agent.v1.AdminService/GetConfig"
 )
 
-// FilesystemServiceClient is the client API for FilesystemService service.
+// AdminServiceClient is the client API for AdminService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Filesystem Service
-type FilesystemServiceClient interface {
-	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error)
-	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error)
-	WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error)
-	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error)
-	CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*CreateDirectoryResponse, error)
-}
-
-type filesystemServiceClient struct {
+// AdminService shows the core's runtime state, to debug production
+// incidents. Every method needs the admin action on core:admin, which only
+// the admin role grants by default.
+type AdminServiceClient interface {
+	// GetProfile streams a runtime/pprof profile of the core
+	GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProfileChunk], error)
+	// ListConnections returns the core's connection to each agent it knows
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	// GetRegistry returns what the core has recorded of each agent and the
+	// plugins it loaded
+	GetRegistry(ctx context.Context, in *GetRegistryRequest, opts ...grpc.CallOption) (*CoreRegistry, error)
+	// GetConfig returns the configuration the core runs with, the values of
+	// secret-looking keys masked
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ConfigDump, error)
+}
+
+type adminServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewFilesystemServiceClient(cc grpc.ClientConnInterface) FilesystemServiceClient {
-	return &filesystemServiceClient{cc}
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
 }
 
-func (c *filesystemServiceClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error) {
+func (c *adminServiceClient) GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProfileChunk], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListFilesResponse)
-	err := c.cc.Invoke(ctx, FilesystemService_ListFiles_FullMethodName, in, out, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &AdminService_ServiceDesc.Streams[0], AdminService_GetProfile_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-func (c *filesystemServiceClient) ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ReadFileResponse)
-	err := c.cc.Invoke(ctx, FilesystemService_ReadFile_FullMethodName, in, out, cOpts...)
-	if err != nil {
+	x := &grpc.GenericClientStream[GetProfileRequest, ProfileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *filesystemServiceClient) WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error) {
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AdminService_GetProfileClient = grpc.ServerStreamingClient[ProfileChunk]
+
+func (c *adminServiceClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(WriteFileResponse)
-	err := c.cc.Invoke(ctx, FilesystemService_WriteFile_FullMethodName, in, out, cOpts...)
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListConnections_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *filesystemServiceClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error) {
+func (c *adminServiceClient) GetRegistry(ctx context.Context, in *GetRegistryRequest, opts ...grpc.CallOption) (*CoreRegistry, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(DeleteFileResponse)
-	err := c.cc.Invoke(ctx, FilesystemService_DeleteFile_FullMethodName, in, out, cOpts...)
+	out := new(CoreRegistry)
+	err := c.cc.Invoke(ctx, AdminService_GetRegistry_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *filesystemServiceClient) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*CreateDirectoryResponse, error) {
+func (c *adminServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ConfigDump, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(CreateDirectoryResponse)
-	err := c.cc.Invoke(ctx, FilesystemService_CreateDirectory_FullMethodName, in, out, cOpts...)
+	out := new(ConfigDump)
+	err := c.cc.Invoke(ctx, AdminService_GetConfig_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// FilesystemServiceServer is the server API for FilesystemService service.
-// All implementations must embed UnimplementedFilesystemServiceServer
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
 // for forward compatibility.
 //
-// Filesystem Service
-type FilesystemServiceServer interface {
-	ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error)
-	ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error)
-	WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error)
-	DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error)
-	CreateDirectory(context.Context, *CreateDirectoryRequest) (*CreateDirectoryResponse, error)
-	mustEmbedUnimplementedFilesystemServiceServer()
-}
-
-// UnimplementedFilesystemServiceServer must be embedded to have
+// AdminService shows the core's runtime state, to debug production
+// incidents. Every method needs the admin action on core:admin, which only
+// the admin role grants by default.
+type AdminServiceServer interface {
+	// GetProfile streams a runtime/pprof profile of the core
+	GetProfile(*GetProfileRequest, grpc.ServerStreamingServer[ProfileChunk]) error
+	// ListConnections returns the core's connection to each agent it knows
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	// GetRegistry returns what the core has recorded of each agent and the
+	// plugins it loaded
+	GetRegistry(context.Context, *GetRegistryRequest) (*CoreRegistry, error)
+	// GetConfig returns the configuration the core runs with, the values of
+	// secret-looking keys masked
+	GetConfig(context.Context, *GetConfigRequest) (*ConfigDump, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedFilesystemServiceServer struct{}
+type UnimplementedAdminServiceServer struct{}
 
-func (UnimplementedFilesystemServiceServer) ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method ListFiles not implemented")
+func (UnimplementedAdminServiceServer) GetProfile(*GetProfileRequest, grpc.ServerStreamingServer[ProfileChunk]) error {
+	return status.Error(codes.Unimplemented, "method GetProfile not implemented")
 }
-func (UnimplementedFilesystemServiceServer) ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method ReadFile not implemented")
-}
-func (UnimplementedFilesystemServiceServer) WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method WriteFile not implemented")
+func (UnimplementedAdminServiceServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListConnections not implemented")
 }
-func (UnimplementedFilesystemServiceServer) DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method DeleteFile not implemented")
+func (UnimplementedAdminServiceServer) GetRegistry(context.Context, *GetRegistryRequest) (*CoreRegistry, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRegistry not implemented")
 }
-func (UnimplementedFilesystemServiceServer) CreateDirectory(context.Context, *CreateDirectoryRequest) (*CreateDirectoryResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method CreateDirectory not implemented")
+func (UnimplementedAdminServiceServer) GetConfig(context.Context, *GetConfigRequest) (*ConfigDump, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
 }
-func (UnimplementedFilesystemServiceServer) mustEmbedUnimplementedFilesystemServiceServer() {}
-func (UnimplementedFilesystemServiceServer) testEmbeddedByValue()                           {}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
 
-// UnsafeFilesystemServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to FilesystemServiceServer will
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
 // result in compilation errors.
-type UnsafeFilesystemServiceServer interface {
-	mustEmbedUnimplementedFilesystemServiceServer()
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
 }
 
-func RegisterFilesystemServiceServer(s grpc.ServiceRegistrar, srv FilesystemServiceServer) {
-	// If the following call panics, it indicates UnimplementedFilesystemServiceServer was
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call panics, it indicates UnimplementedAdminServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&FilesystemService_ServiceDesc, srv)
+	s.RegisterService(&AdminService_ServiceDesc, srv)
 }
 
-func _FilesystemService_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListFilesRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(FilesystemServiceServer).ListFiles(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: FilesystemService_ListFiles_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FilesystemServiceServer).ListFiles(ctx, req.(*ListFilesRequest))
+func _AdminService_GetProfile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetProfileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(AdminServiceServer).GetProfile(m, &grpc.GenericServerStream[GetProfileRequest, ProfileChunk]{ServerStream: stream})
 }
 
-func _FilesystemService_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadFileRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(FilesystemServiceServer).ReadFile(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: FilesystemService_ReadFile_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FilesystemServiceServer).ReadFile(ctx, req.(*ReadFileRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AdminService_GetProfileServer = grpc.ServerStreamingServer[ProfileChunk]
 
-func _FilesystemService_WriteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(WriteFileRequest)
+func _AdminService_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(FilesystemServiceServer).WriteFile(ctx, in)
+		return srv.(AdminServiceServer).ListConnections(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: FilesystemService_WriteFile_FullMethodName,
+		FullMethod: AdminService_ListConnections_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FilesystemServiceServer).WriteFile(ctx, req.(*WriteFileRequest))
+		return srv.(AdminServiceServer).ListConnections(ctx, req.(*ListConnectionsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _FilesystemService_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteFileRequest)
+func _AdminService_GetRegistry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistryRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(FilesystemServiceServer).DeleteFile(ctx, in)
+		return srv.(AdminServiceServer).GetRegistry(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: FilesystemService_DeleteFile_FullMethodName,
+		FullMethod: AdminService_GetRegistry_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FilesystemServiceServer).DeleteFile(ctx, req.(*DeleteFileRequest))
+		return srv.(AdminServiceServer).GetRegistry(ctx, req.(*GetRegistryRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _FilesystemService_CreateDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateDirectoryRequest)
+func _AdminService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(FilesystemServiceServer).CreateDirectory(ctx, in)
+		return srv.(AdminServiceServer).GetConfig(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: FilesystemService_CreateDirectory_FullMethodName,
+		FullMethod: AdminService_GetConfig_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FilesystemServiceServer).CreateDirectory(ctx, req.(*CreateDirectoryRequest))
+		return srv.(AdminServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// FilesystemService_ServiceDesc is the grpc.ServiceDesc for FilesystemService service.
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var FilesystemService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.agent.v1.FilesystemService",
-	HandlerType: (*FilesystemServiceServer)(nil),
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ListFiles",
-			Handler:    _FilesystemService_ListFiles_Handler,
-		},
-		{
-			MethodName: "ReadFile",
-			Handler:    _FilesystemService_ReadFile_Handler,
+			MethodName: "ListConnections",
+			Handler:    _AdminService_ListConnections_Handler,
 		},
 		{
-			MethodName: "WriteFile",
-			Handler:    _FilesystemService_WriteFile_Handler,
+			MethodName: "GetRegistry",
+			Handler:    _AdminService_GetRegistry_Handler,
 		},
 		{
-			MethodName: "DeleteFile",
-			Handler:    _FilesystemService_DeleteFile_Handler,
+			MethodName: "GetConfig",
+			Handler:    _AdminService_GetConfig_Handler,
 		},
+	},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "CreateDirectory",
-			Handler:    _FilesystemService_CreateDirectory_Handler,
+			StreamName:    "GetProfile",
+			Handler:       _AdminService_GetProfile_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/agent.proto",
 }
 
@@ -1580,3 +4055,153 @@ var OperationsService_ServiceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "api/v1/agent.proto",
 }
+
+const (
+	EnrollmentService_GetEnrollmentCA_FullMethodName = "/mandau.agent.v1.EnrollmentService/GetEnrollmentCA"
+	EnrollmentService_Enroll_FullMethodName          = "/mandau.agent.v1.EnrollmentService/Enroll"
+)
+
+// EnrollmentServiceClient is the client API for EnrollmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Enrollment of new agents, served on its own listener that does not
+// require a client certificate
+type EnrollmentServiceClient interface {
+	// The core's CA, for a new host to check against a pinned hash before it
+	// sends its token
+	GetEnrollmentCA(ctx context.Context, in *GetEnrollmentCARequest, opts ...grpc.CallOption) (*GetEnrollmentCAResponse, error)
+	Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*EnrollResponse, error)
+}
+
+type enrollmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEnrollmentServiceClient(cc grpc.ClientConnInterface) EnrollmentServiceClient {
+	return &enrollmentServiceClient{cc}
+}
+
+func (c *enrollmentServiceClient) GetEnrollmentCA(ctx context.Context, in *GetEnrollmentCARequest, opts ...grpc.CallOption) (*GetEnrollmentCAResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEnrollmentCAResponse)
+	err := c.cc.Invoke(ctx, EnrollmentService_GetEnrollmentCA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enrollmentServiceClient) Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*EnrollResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollResponse)
+	err := c.cc.Invoke(ctx, EnrollmentService_Enroll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnrollmentServiceServer is the server API for EnrollmentService service.
+// All implementations must embed UnimplementedEnrollmentServiceServer
+// for forward compatibility.
+//
+// Enrollment of new agents, served on its own listener that does not
+// require a client certificate
+type EnrollmentServiceServer interface {
+	// The core's CA, for a new host to check against a pinned hash before it
+	// sends its token
+	GetEnrollmentCA(context.Context, *GetEnrollmentCARequest) (*GetEnrollmentCAResponse, error)
+	Enroll(context.Context, *EnrollRequest) (*EnrollResponse, error)
+	mustEmbedUnimplementedEnrollmentServiceServer()
+}
+
+// UnimplementedEnrollmentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEnrollmentServiceServer struct{}
+
+func (UnimplementedEnrollmentServiceServer) GetEnrollmentCA(context.Context, *GetEnrollmentCARequest) (*GetEnrollmentCAResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEnrollmentCA not implemented")
+}
+func (UnimplementedEnrollmentServiceServer) Enroll(context.Context, *EnrollRequest) (*EnrollResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Enroll not implemented")
+}
+func (UnimplementedEnrollmentServiceServer) mustEmbedUnimplementedEnrollmentServiceServer() {}
+func (UnimplementedEnrollmentServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeEnrollmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EnrollmentServiceServer will
+// result in compilation errors.
+type UnsafeEnrollmentServiceServer interface {
+	mustEmbedUnimplementedEnrollmentServiceServer()
+}
+
+func RegisterEnrollmentServiceServer(s grpc.ServiceRegistrar, srv EnrollmentServiceServer) {
+	// If the following call panics, it indicates UnimplementedEnrollmentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EnrollmentService_ServiceDesc, srv)
+}
+
+func _EnrollmentService_GetEnrollmentCA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEnrollmentCARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnrollmentServiceServer).GetEnrollmentCA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnrollmentService_GetEnrollmentCA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnrollmentServiceServer).GetEnrollmentCA(ctx, req.(*GetEnrollmentCARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnrollmentService_Enroll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnrollmentServiceServer).Enroll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnrollmentService_Enroll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnrollmentServiceServer).Enroll(ctx, req.(*EnrollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EnrollmentService_ServiceDesc is the grpc.ServiceDesc for EnrollmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EnrollmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.agent.v1.EnrollmentService",
+	HandlerType: (*EnrollmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetEnrollmentCA",
+			Handler:    _EnrollmentService_GetEnrollmentCA_Handler,
+		},
+		{
+			MethodName: "Enroll",
+			Handler:    _EnrollmentService_Enroll_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/agent.proto",
+}
@@ -0,0 +1,286 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: api/v1/errors.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED         ErrorCode = 0
+	ErrorCode_ERROR_CODE_INTERNAL            ErrorCode = 1
+	ErrorCode_ERROR_CODE_INVALID_ARGUMENT    ErrorCode = 2
+	ErrorCode_ERROR_CODE_NOT_FOUND           ErrorCode = 3
+	ErrorCode_ERROR_CODE_ALREADY_EXISTS      ErrorCode = 4
+	ErrorCode_ERROR_CODE_UNAUTHENTICATED     ErrorCode = 5
+	ErrorCode_ERROR_CODE_PERMISSION_DENIED   ErrorCode = 6
+	ErrorCode_ERROR_CODE_FAILED_PRECONDITION ErrorCode = 7
+	ErrorCode_ERROR_CODE_UNAVAILABLE         ErrorCode = 8
+	ErrorCode_ERROR_CODE_TIMEOUT             ErrorCode = 9
+	ErrorCode_ERROR_CODE_UNIMPLEMENTED       ErrorCode = 10
+	ErrorCode_ERROR_CODE_AGENT_NOT_FOUND     ErrorCode = 11
+	ErrorCode_ERROR_CODE_AGENT_OFFLINE       ErrorCode = 12
+	ErrorCode_ERROR_CODE_AGENT_DIAL_OUT_ONLY ErrorCode = 13
+	ErrorCode_ERROR_CODE_CAPABILITY_MISSING  ErrorCode = 14
+	ErrorCode_ERROR_CODE_APPROVAL_REQUIRED   ErrorCode = 15
+	ErrorCode_ERROR_CODE_FROZEN              ErrorCode = 16
+	ErrorCode_ERROR_CODE_MAINTENANCE         ErrorCode = 17
+	ErrorCode_ERROR_CODE_QUOTA_EXCEEDED      ErrorCode = 18
+	ErrorCode_ERROR_CODE_AGENT_UNHEALTHY     ErrorCode = 19
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:  "ERROR_CODE_UNSPECIFIED",
+		1:  "ERROR_CODE_INTERNAL",
+		2:  "ERROR_CODE_INVALID_ARGUMENT",
+		3:  "ERROR_CODE_NOT_FOUND",
+		4:  "ERROR_CODE_ALREADY_EXISTS",
+		5:  "ERROR_CODE_UNAUTHENTICATED",
+		6:  "ERROR_CODE_PERMISSION_DENIED",
+		7:  "ERROR_CODE_FAILED_PRECONDITION",
+		8:  "ERROR_CODE_UNAVAILABLE",
+		9:  "ERROR_CODE_TIMEOUT",
+		10: "ERROR_CODE_UNIMPLEMENTED",
+		11: "ERROR_CODE_AGENT_NOT_FOUND",
+		12: "ERROR_CODE_AGENT_OFFLINE",
+		13: "ERROR_CODE_AGENT_DIAL_OUT_ONLY",
+		14: "ERROR_CODE_CAPABILITY_MISSING",
+		15: "ERROR_CODE_APPROVAL_REQUIRED",
+		16: "ERROR_CODE_FROZEN",
+		17: "ERROR_CODE_MAINTENANCE",
+		18: "ERROR_CODE_QUOTA_EXCEEDED",
+		19: "ERROR_CODE_AGENT_UNHEALTHY",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":         0,
+		"ERROR_CODE_INTERNAL":            1,
+		"ERROR_CODE_INVALID_ARGUMENT":    2,
+		"ERROR_CODE_NOT_FOUND":           3,
+		"ERROR_CODE_ALREADY_EXISTS":      4,
+		"ERROR_CODE_UNAUTHENTICATED":     5,
+		"ERROR_CODE_PERMISSION_DENIED":   6,
+		"ERROR_CODE_FAILED_PRECONDITION": 7,
+		"ERROR_CODE_UNAVAILABLE":         8,
+		"ERROR_CODE_TIMEOUT":             9,
+		"ERROR_CODE_UNIMPLEMENTED":       10,
+		"ERROR_CODE_AGENT_NOT_FOUND":     11,
+		"ERROR_CODE_AGENT_OFFLINE":       12,
+		"ERROR_CODE_AGENT_DIAL_OUT_ONLY": 13,
+		"ERROR_CODE_CAPABILITY_MISSING":  14,
+		"ERROR_CODE_APPROVAL_REQUIRED":   15,
+		"ERROR_CODE_FROZEN":              16,
+		"ERROR_CODE_MAINTENANCE":         17,
+		"ERROR_CODE_QUOTA_EXCEEDED":      18,
+		"ERROR_CODE_AGENT_UNHEALTHY":     19,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_errors_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_api_v1_errors_proto_enumTypes[0]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_errors_proto_rawDescGZIP(), []int{0}
+}
+
+// ErrorDetail rides in the details of every error status the core returns,
+// so clients can branch on what went wrong without parsing messages
+type ErrorDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=mandau.agent.v1.ErrorCode" json:"code,omitempty"`
+	Resource      string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`              // What the call was about, e.g. "stack:web"
+	AgentId       string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Agent the call was for, if any
+	Retryable     bool                   `protobuf:"varint,4,opt,name=retryable,proto3" json:"retryable,omitempty"`           // The same call may succeed later unchanged
+	Hint          string                 `protobuf:"bytes,5,opt,name=hint,proto3" json:"hint,omitempty"`                      // What the caller can do about it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_api_v1_errors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_errors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_api_v1_errors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorDetail) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *ErrorDetail) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *ErrorDetail) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+var File_api_v1_errors_proto protoreflect.FileDescriptor
+
+const file_api_v1_errors_proto_rawDesc = "" +
+	"\n" +
+	"\x13api/v1/errors.proto\x12\x0fmandau.agent.v1\"\xa6\x01\n" +
+	"\vErrorDetail\x12.\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x1a.mandau.agent.v1.ErrorCodeR\x04code\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12\x1c\n" +
+	"\tretryable\x18\x04 \x01(\bR\tretryable\x12\x12\n" +
+	"\x04hint\x18\x05 \x01(\tR\x04hint*\xeb\x04\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13ERROR_CODE_INTERNAL\x10\x01\x12\x1f\n" +
+	"\x1bERROR_CODE_INVALID_ARGUMENT\x10\x02\x12\x18\n" +
+	"\x14ERROR_CODE_NOT_FOUND\x10\x03\x12\x1d\n" +
+	"\x19ERROR_CODE_ALREADY_EXISTS\x10\x04\x12\x1e\n" +
+	"\x1aERROR_CODE_UNAUTHENTICATED\x10\x05\x12 \n" +
+	"\x1cERROR_CODE_PERMISSION_DENIED\x10\x06\x12\"\n" +
+	"\x1eERROR_CODE_FAILED_PRECONDITION\x10\a\x12\x1a\n" +
+	"\x16ERROR_CODE_UNAVAILABLE\x10\b\x12\x16\n" +
+	"\x12ERROR_CODE_TIMEOUT\x10\t\x12\x1c\n" +
+	"\x18ERROR_CODE_UNIMPLEMENTED\x10\n" +
+	"\x12\x1e\n" +
+	"\x1aERROR_CODE_AGENT_NOT_FOUND\x10\v\x12\x1c\n" +
+	"\x18ERROR_CODE_AGENT_OFFLINE\x10\f\x12\"\n" +
+	"\x1eERROR_CODE_AGENT_DIAL_OUT_ONLY\x10\r\x12!\n" +
+	"\x1dERROR_CODE_CAPABILITY_MISSING\x10\x0e\x12 \n" +
+	"\x1cERROR_CODE_APPROVAL_REQUIRED\x10\x0f\x12\x15\n" +
+	"\x11ERROR_CODE_FROZEN\x10\x10\x12\x1a\n" +
+	"\x16ERROR_CODE_MAINTENANCE\x10\x11\x12\x1d\n" +
+	"\x19ERROR_CODE_QUOTA_EXCEEDED\x10\x12\x12\x1e\n" +
+	"\x1aERROR_CODE_AGENT_UNHEALTHY\x10\x13B%Z#github.com/bhangun/mandau/api/v1;v1b\x06proto3"
+
+var (
+	file_api_v1_errors_proto_rawDescOnce sync.Once
+	file_api_v1_errors_proto_rawDescData []byte
+)
+
+func file_api_v1_errors_proto_rawDescGZIP() []byte {
+	file_api_v1_errors_proto_rawDescOnce.Do(func() {
+		file_api_v1_errors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_v1_errors_proto_rawDesc), len(file_api_v1_errors_proto_rawDesc)))
+	})
+	return file_api_v1_errors_proto_rawDescData
+}
+
+var file_api_v1_errors_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_v1_errors_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_v1_errors_proto_goTypes = []any{
+	(ErrorCode)(0),      // 0: mandau.agent.v1.ErrorCode
+	(*ErrorDetail)(nil), // 1: mandau.agent.v1.ErrorDetail
+}
+var file_api_v1_errors_proto_depIdxs = []int32{
+	0, // 0: mandau.agent.v1.ErrorDetail.code:type_name -> mandau.agent.v1.ErrorCode
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_api_v1_errors_proto_init() }
+func file_api_v1_errors_proto_init() {
+	if File_api_v1_errors_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_v1_errors_proto_rawDesc), len(file_api_v1_errors_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_v1_errors_proto_goTypes,
+		DependencyIndexes: file_api_v1_errors_proto_depIdxs,
+		EnumInfos:         file_api_v1_errors_proto_enumTypes,
+		MessageInfos:      file_api_v1_errors_proto_msgTypes,
+	}.Build()
+	File_api_v1_errors_proto = out.File
+	file_api_v1_errors_proto_goTypes = nil
+	file_api_v1_errors_proto_depIdxs = nil
+}
@@ -9,6 +9,7 @@ package v1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -32,6 +33,9 @@ type CreateVirtualHostRequest struct {
 	Locations     []*Location            `protobuf:"bytes,6,rep,name=locations,proto3" json:"locations,omitempty"`
 	Ssl           *SSLConfig             `protobuf:"bytes,7,opt,name=ssl,proto3" json:"ssl,omitempty"`
 	ProxyPass     string                 `protobuf:"bytes,8,opt,name=proxy_pass,json=proxyPass,proto3" json:"proxy_pass,omitempty"`
+	DryRun        bool                   `protobuf:"varint,9,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Snippet       string                 `protobuf:"bytes,10,opt,name=snippet,proto3" json:"snippet,omitempty"`             // Extra directives at the end of the server block
+	Force         bool                   `protobuf:"varint,11,opt,name=force,proto3" json:"force,omitempty"`                // Overwrite files edited outside Mandau
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -122,10 +126,32 @@ func (x *CreateVirtualHostRequest) GetProxyPass() string {
 	return ""
 }
 
+func (x *CreateVirtualHostRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateVirtualHostRequest) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *CreateVirtualHostRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type CreateVirtualHostResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -174,6 +200,13 @@ func (x *CreateVirtualHostResponse) GetError() string {
 	return ""
 }
 
+func (x *CreateVirtualHostResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
 type EnableVirtualHostRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -386,6 +419,8 @@ type DeleteVirtualHostRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	ServerName    string                 `protobuf:"bytes,2,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	DryRun        bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`                 // Remove files edited outside Mandau
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -434,10 +469,25 @@ func (x *DeleteVirtualHostRequest) GetServerName() string {
 	return ""
 }
 
+func (x *DeleteVirtualHostRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *DeleteVirtualHostRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type DeleteVirtualHostResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -486,6 +536,13 @@ func (x *DeleteVirtualHostResponse) GetError() string {
 	return ""
 }
 
+func (x *DeleteVirtualHostResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
 type ListVirtualHostsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -533,6 +590,7 @@ func (x *ListVirtualHostsRequest) GetAgentId() string {
 type ListVirtualHostsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Vhosts        []string               `protobuf:"bytes,1,rep,name=vhosts,proto3" json:"vhosts,omitempty"`
+	Enabled       []string               `protobuf:"bytes,2,rep,name=enabled,proto3" json:"enabled,omitempty"` // The vhosts nginx serves
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -574,6 +632,13 @@ func (x *ListVirtualHostsResponse) GetVhosts() []string {
 	return nil
 }
 
+func (x *ListVirtualHostsResponse) GetEnabled() []string {
+	if x != nil {
+		return x.Enabled
+	}
+	return nil
+}
+
 type Location struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
@@ -724,6 +789,9 @@ type CreateReverseProxyRequest struct {
 	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
 	Upstream      string                 `protobuf:"bytes,3,opt,name=upstream,proto3" json:"upstream,omitempty"`
 	Port          int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Snippet       string                 `protobuf:"bytes,6,opt,name=snippet,proto3" json:"snippet,omitempty"`              // Extra directives at the end of the server block
+	Force         bool                   `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -786,10 +854,32 @@ func (x *CreateReverseProxyRequest) GetPort() int32 {
 	return 0
 }
 
+func (x *CreateReverseProxyRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateReverseProxyRequest) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *CreateReverseProxyRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type CreateReverseProxyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -838,12 +928,21 @@ func (x *CreateReverseProxyResponse) GetError() string {
 	return ""
 }
 
+func (x *CreateReverseProxyResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
 type CreateLoadBalancerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Backends      []string               `protobuf:"bytes,3,rep,name=backends,proto3" json:"backends,omitempty"`
 	Algorithm     string                 `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -906,10 +1005,25 @@ func (x *CreateLoadBalancerRequest) GetAlgorithm() string {
 	return ""
 }
 
+func (x *CreateLoadBalancerRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateLoadBalancerRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type CreateLoadBalancerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -958,6 +1072,13 @@ func (x *CreateLoadBalancerResponse) GetError() string {
 	return ""
 }
 
+func (x *CreateLoadBalancerResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
 type CreateServiceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -977,6 +1098,15 @@ type CreateServiceRequest struct {
 	MemoryLimit   string                 `protobuf:"bytes,15,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
 	PrivateTmp    bool                   `protobuf:"varint,16,opt,name=private_tmp,json=privateTmp,proto3" json:"private_tmp,omitempty"`
 	ProtectSystem string                 `protobuf:"bytes,17,opt,name=protect_system,json=protectSystem,proto3" json:"protect_system,omitempty"`
+	DryRun        bool                   `protobuf:"varint,18,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	// Extra lines at the end of the [Unit], [Service] and [Install] sections
+	UnitSnippet    string `protobuf:"bytes,19,opt,name=unit_snippet,json=unitSnippet,proto3" json:"unit_snippet,omitempty"`
+	ServiceSnippet string `protobuf:"bytes,20,opt,name=service_snippet,json=serviceSnippet,proto3" json:"service_snippet,omitempty"`
+	InstallSnippet string `protobuf:"bytes,21,opt,name=install_snippet,json=installSnippet,proto3" json:"install_snippet,omitempty"`
+	Force          bool   `protobuf:"varint,22,opt,name=force,proto3" json:"force,omitempty"` // Overwrite files edited outside Mandau
+	// Create the template unit name@.service, whose instances each read
+	// their own environment file
+	Template      bool `protobuf:"varint,23,opt,name=template,proto3" json:"template,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1130,10 +1260,53 @@ func (x *CreateServiceRequest) GetProtectSystem() string {
 	return ""
 }
 
+func (x *CreateServiceRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateServiceRequest) GetUnitSnippet() string {
+	if x != nil {
+		return x.UnitSnippet
+	}
+	return ""
+}
+
+func (x *CreateServiceRequest) GetServiceSnippet() string {
+	if x != nil {
+		return x.ServiceSnippet
+	}
+	return ""
+}
+
+func (x *CreateServiceRequest) GetInstallSnippet() string {
+	if x != nil {
+		return x.InstallSnippet
+	}
+	return ""
+}
+
+func (x *CreateServiceRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *CreateServiceRequest) GetTemplate() bool {
+	if x != nil {
+		return x.Template
+	}
+	return false
+}
+
 type CreateServiceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1182,6 +1355,13 @@ func (x *CreateServiceResponse) GetError() string {
 	return ""
 }
 
+func (x *CreateServiceResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
 type EnableServiceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -1894,34 +2074,28 @@ func (x *ListServicesResponse) GetServices() []string {
 	return nil
 }
 
-type AddFirewallRuleRequest struct {
+type ListInstancesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // allow, deny, reject
-	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`   // tcp, udp, any
-	FromIp        string                 `protobuf:"bytes,4,opt,name=from_ip,json=fromIp,proto3" json:"from_ip,omitempty"`
-	FromPort      int32                  `protobuf:"varint,5,opt,name=from_port,json=fromPort,proto3" json:"from_port,omitempty"`
-	ToIp          string                 `protobuf:"bytes,6,opt,name=to_ip,json=toIp,proto3" json:"to_ip,omitempty"`
-	ToPort        int32                  `protobuf:"varint,7,opt,name=to_port,json=toPort,proto3" json:"to_port,omitempty"`
-	Comment       string                 `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	Template      string                 `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"` // Template name, without the @
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddFirewallRuleRequest) Reset() {
-	*x = AddFirewallRuleRequest{}
+func (x *ListInstancesRequest) Reset() {
+	*x = ListInstancesRequest{}
 	mi := &file_api_v1_service_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddFirewallRuleRequest) String() string {
+func (x *ListInstancesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddFirewallRuleRequest) ProtoMessage() {}
+func (*ListInstancesRequest) ProtoMessage() {}
 
-func (x *AddFirewallRuleRequest) ProtoReflect() protoreflect.Message {
+func (x *ListInstancesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_service_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1933,90 +2107,123 @@ func (x *AddFirewallRuleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddFirewallRuleRequest.ProtoReflect.Descriptor instead.
-func (*AddFirewallRuleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListInstancesRequest.ProtoReflect.Descriptor instead.
+func (*ListInstancesRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_service_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *AddFirewallRuleRequest) GetAgentId() string {
+func (x *ListInstancesRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AddFirewallRuleRequest) GetAction() string {
+func (x *ListInstancesRequest) GetTemplate() string {
 	if x != nil {
-		return x.Action
+		return x.Template
 	}
 	return ""
 }
 
-func (x *AddFirewallRuleRequest) GetProto() string {
+type ServiceInstance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`         // worker@2
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // 2
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`       // active, inactive, failed...
+	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Environment   map[string]string      `protobuf:"bytes,5,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceInstance) Reset() {
+	*x = ServiceInstance{}
+	mi := &file_api_v1_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceInstance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceInstance) ProtoMessage() {}
+
+func (x *ServiceInstance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[33]
 	if x != nil {
-		return x.Proto
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *AddFirewallRuleRequest) GetFromIp() string {
+// Deprecated: Use ServiceInstance.ProtoReflect.Descriptor instead.
+func (*ServiceInstance) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ServiceInstance) GetName() string {
 	if x != nil {
-		return x.FromIp
+		return x.Name
 	}
 	return ""
 }
 
-func (x *AddFirewallRuleRequest) GetFromPort() int32 {
+func (x *ServiceInstance) GetInstance() string {
 	if x != nil {
-		return x.FromPort
+		return x.Instance
 	}
-	return 0
+	return ""
 }
 
-func (x *AddFirewallRuleRequest) GetToIp() string {
+func (x *ServiceInstance) GetState() string {
 	if x != nil {
-		return x.ToIp
+		return x.State
 	}
 	return ""
 }
 
-func (x *AddFirewallRuleRequest) GetToPort() int32 {
+func (x *ServiceInstance) GetEnabled() bool {
 	if x != nil {
-		return x.ToPort
+		return x.Enabled
 	}
-	return 0
+	return false
 }
 
-func (x *AddFirewallRuleRequest) GetComment() string {
+func (x *ServiceInstance) GetEnvironment() map[string]string {
 	if x != nil {
-		return x.Comment
+		return x.Environment
 	}
-	return ""
+	return nil
 }
 
-type AddFirewallRuleResponse struct {
+type ListInstancesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Instances     []*ServiceInstance     `protobuf:"bytes,1,rep,name=instances,proto3" json:"instances,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddFirewallRuleResponse) Reset() {
-	*x = AddFirewallRuleResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[33]
+func (x *ListInstancesResponse) Reset() {
+	*x = ListInstancesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddFirewallRuleResponse) String() string {
+func (x *ListInstancesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddFirewallRuleResponse) ProtoMessage() {}
+func (*ListInstancesResponse) ProtoMessage() {}
 
-func (x *AddFirewallRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[33]
+func (x *ListInstancesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2027,48 +2234,45 @@ func (x *AddFirewallRuleResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddFirewallRuleResponse.ProtoReflect.Descriptor instead.
-func (*AddFirewallRuleResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{33}
-}
-
-func (x *AddFirewallRuleResponse) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+// Deprecated: Use ListInstancesResponse.ProtoReflect.Descriptor instead.
+func (*ListInstancesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *AddFirewallRuleResponse) GetError() string {
+func (x *ListInstancesResponse) GetInstances() []*ServiceInstance {
 	if x != nil {
-		return x.Error
+		return x.Instances
 	}
-	return ""
+	return nil
 }
 
-type DeleteFirewallRuleRequest struct {
+type SetInstanceEnvironmentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	RuleNumber    int32                  `protobuf:"varint,2,opt,name=rule_number,json=ruleNumber,proto3" json:"rule_number,omitempty"`
+	Template      string                 `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	Instance      string                 `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"`
+	Environment   map[string]string      `protobuf:"bytes,4,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Empty removes the file
+	Restart       bool                   `protobuf:"varint,5,opt,name=restart,proto3" json:"restart,omitempty"`                                                                                  // Restart the instance so it reads the file
+	Force         bool                   `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`                                                                                      // Overwrite a file edited outside Mandau
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteFirewallRuleRequest) Reset() {
-	*x = DeleteFirewallRuleRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[34]
+func (x *SetInstanceEnvironmentRequest) Reset() {
+	*x = SetInstanceEnvironmentRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteFirewallRuleRequest) String() string {
+func (x *SetInstanceEnvironmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteFirewallRuleRequest) ProtoMessage() {}
+func (*SetInstanceEnvironmentRequest) ProtoMessage() {}
 
-func (x *DeleteFirewallRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[34]
+func (x *SetInstanceEnvironmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2079,48 +2283,75 @@ func (x *DeleteFirewallRuleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteFirewallRuleRequest.ProtoReflect.Descriptor instead.
-func (*DeleteFirewallRuleRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{34}
+// Deprecated: Use SetInstanceEnvironmentRequest.ProtoReflect.Descriptor instead.
+func (*SetInstanceEnvironmentRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *DeleteFirewallRuleRequest) GetAgentId() string {
+func (x *SetInstanceEnvironmentRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *DeleteFirewallRuleRequest) GetRuleNumber() int32 {
+func (x *SetInstanceEnvironmentRequest) GetTemplate() string {
 	if x != nil {
-		return x.RuleNumber
+		return x.Template
 	}
-	return 0
+	return ""
 }
 
-type DeleteFirewallRuleResponse struct {
+func (x *SetInstanceEnvironmentRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *SetInstanceEnvironmentRequest) GetEnvironment() map[string]string {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+func (x *SetInstanceEnvironmentRequest) GetRestart() bool {
+	if x != nil {
+		return x.Restart
+	}
+	return false
+}
+
+func (x *SetInstanceEnvironmentRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type SetInstanceEnvironmentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteFirewallRuleResponse) Reset() {
-	*x = DeleteFirewallRuleResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[35]
+func (x *SetInstanceEnvironmentResponse) Reset() {
+	*x = SetInstanceEnvironmentResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteFirewallRuleResponse) String() string {
+func (x *SetInstanceEnvironmentResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteFirewallRuleResponse) ProtoMessage() {}
+func (*SetInstanceEnvironmentResponse) ProtoMessage() {}
 
-func (x *DeleteFirewallRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[35]
+func (x *SetInstanceEnvironmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2131,47 +2362,42 @@ func (x *DeleteFirewallRuleResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteFirewallRuleResponse.ProtoReflect.Descriptor instead.
-func (*DeleteFirewallRuleResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{35}
+// Deprecated: Use SetInstanceEnvironmentResponse.ProtoReflect.Descriptor instead.
+func (*SetInstanceEnvironmentResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *DeleteFirewallRuleResponse) GetStatus() string {
+func (x *SetInstanceEnvironmentResponse) GetStatus() string {
 	if x != nil {
 		return x.Status
 	}
 	return ""
 }
 
-func (x *DeleteFirewallRuleResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
-type ListFirewallRulesRequest struct {
+type ScaleInstancesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Template      string                 `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"` // Run instances 1 to count, stop those above
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFirewallRulesRequest) Reset() {
-	*x = ListFirewallRulesRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[36]
+func (x *ScaleInstancesRequest) Reset() {
+	*x = ScaleInstancesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFirewallRulesRequest) String() string {
+func (x *ScaleInstancesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFirewallRulesRequest) ProtoMessage() {}
+func (*ScaleInstancesRequest) ProtoMessage() {}
 
-func (x *ListFirewallRulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[36]
+func (x *ScaleInstancesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2182,40 +2408,56 @@ func (x *ListFirewallRulesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFirewallRulesRequest.ProtoReflect.Descriptor instead.
-func (*ListFirewallRulesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{36}
+// Deprecated: Use ScaleInstancesRequest.ProtoReflect.Descriptor instead.
+func (*ScaleInstancesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *ListFirewallRulesRequest) GetAgentId() string {
+func (x *ScaleInstancesRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-type ListFirewallRulesResponse struct {
+func (x *ScaleInstancesRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *ScaleInstancesRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type ScaleInstancesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rules         []string               `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Started       []string               `protobuf:"bytes,2,rep,name=started,proto3" json:"started,omitempty"`
+	Stopped       []string               `protobuf:"bytes,3,rep,name=stopped,proto3" json:"stopped,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFirewallRulesResponse) Reset() {
-	*x = ListFirewallRulesResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[37]
+func (x *ScaleInstancesResponse) Reset() {
+	*x = ScaleInstancesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFirewallRulesResponse) String() string {
+func (x *ScaleInstancesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFirewallRulesResponse) ProtoMessage() {}
+func (*ScaleInstancesResponse) ProtoMessage() {}
 
-func (x *ListFirewallRulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[37]
+func (x *ScaleInstancesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2226,42 +2468,65 @@ func (x *ListFirewallRulesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFirewallRulesResponse.ProtoReflect.Descriptor instead.
-func (*ListFirewallRulesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{37}
+// Deprecated: Use ScaleInstancesResponse.ProtoReflect.Descriptor instead.
+func (*ScaleInstancesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *ListFirewallRulesResponse) GetRules() []string {
+func (x *ScaleInstancesResponse) GetStatus() string {
 	if x != nil {
-		return x.Rules
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScaleInstancesResponse) GetStarted() []string {
+	if x != nil {
+		return x.Started
 	}
 	return nil
 }
 
-type AllowPortRequest struct {
+func (x *ScaleInstancesResponse) GetStopped() []string {
+	if x != nil {
+		return x.Stopped
+	}
+	return nil
+}
+
+type AddFirewallRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // allow, deny, reject
+	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`   // tcp, udp, any
+	FromIp        string                 `protobuf:"bytes,4,opt,name=from_ip,json=fromIp,proto3" json:"from_ip,omitempty"`
+	FromPort      int32                  `protobuf:"varint,5,opt,name=from_port,json=fromPort,proto3" json:"from_port,omitempty"`
+	ToIp          string                 `protobuf:"bytes,6,opt,name=to_ip,json=toIp,proto3" json:"to_ip,omitempty"`
+	ToPort        int32                  `protobuf:"varint,7,opt,name=to_port,json=toPort,proto3" json:"to_port,omitempty"`
+	Comment       string                 `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	DryRun        bool                   `protobuf:"varint,9,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`           // Diff what would change without changing it
+	RuleSet       string                 `protobuf:"bytes,10,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`        // Named set the rule is removed with
+	RateLimit     string                 `protobuf:"bytes,11,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`  // Accept new connections up to e.g. "10/minute"
+	RateBurst     int32                  `protobuf:"varint,12,opt,name=rate_burst,json=rateBurst,proto3" json:"rate_burst,omitempty"` // Connections allowed over the rate at once
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AllowPortRequest) Reset() {
-	*x = AllowPortRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[38]
+func (x *AddFirewallRuleRequest) Reset() {
+	*x = AddFirewallRuleRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AllowPortRequest) String() string {
+func (x *AddFirewallRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AllowPortRequest) ProtoMessage() {}
+func (*AddFirewallRuleRequest) ProtoMessage() {}
 
-func (x *AllowPortRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[38]
+func (x *AddFirewallRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2272,107 +2537,118 @@ func (x *AllowPortRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AllowPortRequest.ProtoReflect.Descriptor instead.
-func (*AllowPortRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{38}
+// Deprecated: Use AddFirewallRuleRequest.ProtoReflect.Descriptor instead.
+func (*AddFirewallRuleRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *AllowPortRequest) GetAgentId() string {
+func (x *AddFirewallRuleRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AllowPortRequest) GetPort() int32 {
+func (x *AddFirewallRuleRequest) GetAction() string {
 	if x != nil {
-		return x.Port
+		return x.Action
 	}
-	return 0
+	return ""
 }
 
-func (x *AllowPortRequest) GetProto() string {
+func (x *AddFirewallRuleRequest) GetProto() string {
 	if x != nil {
 		return x.Proto
 	}
 	return ""
 }
 
-type AllowPortResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AddFirewallRuleRequest) GetFromIp() string {
+	if x != nil {
+		return x.FromIp
+	}
+	return ""
 }
 
-func (x *AllowPortResponse) Reset() {
-	*x = AllowPortResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[39]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *AddFirewallRuleRequest) GetFromPort() int32 {
+	if x != nil {
+		return x.FromPort
+	}
+	return 0
 }
 
-func (x *AllowPortResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *AddFirewallRuleRequest) GetToIp() string {
+	if x != nil {
+		return x.ToIp
+	}
+	return ""
 }
 
-func (*AllowPortResponse) ProtoMessage() {}
+func (x *AddFirewallRuleRequest) GetToPort() int32 {
+	if x != nil {
+		return x.ToPort
+	}
+	return 0
+}
 
-func (x *AllowPortResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[39]
+func (x *AddFirewallRuleRequest) GetComment() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Comment
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use AllowPortResponse.ProtoReflect.Descriptor instead.
-func (*AllowPortResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{39}
+func (x *AddFirewallRuleRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
 }
 
-func (x *AllowPortResponse) GetStatus() string {
+func (x *AddFirewallRuleRequest) GetRuleSet() string {
 	if x != nil {
-		return x.Status
+		return x.RuleSet
 	}
 	return ""
 }
 
-func (x *AllowPortResponse) GetError() string {
+func (x *AddFirewallRuleRequest) GetRateLimit() string {
 	if x != nil {
-		return x.Error
+		return x.RateLimit
 	}
 	return ""
 }
 
-type DenyPortRequest struct {
+func (x *AddFirewallRuleRequest) GetRateBurst() int32 {
+	if x != nil {
+		return x.RateBurst
+	}
+	return 0
+}
+
+type AddFirewallRuleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DenyPortRequest) Reset() {
-	*x = DenyPortRequest{}
+func (x *AddFirewallRuleResponse) Reset() {
+	*x = AddFirewallRuleResponse{}
 	mi := &file_api_v1_service_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DenyPortRequest) String() string {
+func (x *AddFirewallRuleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DenyPortRequest) ProtoMessage() {}
+func (*AddFirewallRuleResponse) ProtoMessage() {}
 
-func (x *DenyPortRequest) ProtoReflect() protoreflect.Message {
+func (x *AddFirewallRuleResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_service_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2384,54 +2660,54 @@ func (x *DenyPortRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DenyPortRequest.ProtoReflect.Descriptor instead.
-func (*DenyPortRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddFirewallRuleResponse.ProtoReflect.Descriptor instead.
+func (*AddFirewallRuleResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_service_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *DenyPortRequest) GetAgentId() string {
+func (x *AddFirewallRuleResponse) GetStatus() string {
 	if x != nil {
-		return x.AgentId
+		return x.Status
 	}
 	return ""
 }
 
-func (x *DenyPortRequest) GetPort() int32 {
+func (x *AddFirewallRuleResponse) GetError() string {
 	if x != nil {
-		return x.Port
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-func (x *DenyPortRequest) GetProto() string {
+func (x *AddFirewallRuleResponse) GetDiff() string {
 	if x != nil {
-		return x.Proto
+		return x.Diff
 	}
 	return ""
 }
 
-type DenyPortResponse struct {
+type DeleteFirewallRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	RuleNumber    int32                  `protobuf:"varint,2,opt,name=rule_number,json=ruleNumber,proto3" json:"rule_number,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DenyPortResponse) Reset() {
-	*x = DenyPortResponse{}
+func (x *DeleteFirewallRuleRequest) Reset() {
+	*x = DeleteFirewallRuleRequest{}
 	mi := &file_api_v1_service_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DenyPortResponse) String() string {
+func (x *DeleteFirewallRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DenyPortResponse) ProtoMessage() {}
+func (*DeleteFirewallRuleRequest) ProtoMessage() {}
 
-func (x *DenyPortResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteFirewallRuleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_service_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2443,46 +2719,47 @@ func (x *DenyPortResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DenyPortResponse.ProtoReflect.Descriptor instead.
-func (*DenyPortResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteFirewallRuleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFirewallRuleRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_service_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *DenyPortResponse) GetStatus() string {
+func (x *DeleteFirewallRuleRequest) GetAgentId() string {
 	if x != nil {
-		return x.Status
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *DenyPortResponse) GetError() string {
+func (x *DeleteFirewallRuleRequest) GetRuleNumber() int32 {
 	if x != nil {
-		return x.Error
+		return x.RuleNumber
 	}
-	return ""
+	return 0
 }
 
-type EnableFirewallRequest struct {
+type DeleteFirewallRuleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EnableFirewallRequest) Reset() {
-	*x = EnableFirewallRequest{}
+func (x *DeleteFirewallRuleResponse) Reset() {
+	*x = DeleteFirewallRuleResponse{}
 	mi := &file_api_v1_service_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EnableFirewallRequest) String() string {
+func (x *DeleteFirewallRuleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EnableFirewallRequest) ProtoMessage() {}
+func (*DeleteFirewallRuleResponse) ProtoMessage() {}
 
-func (x *EnableFirewallRequest) ProtoReflect() protoreflect.Message {
+func (x *DeleteFirewallRuleResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_service_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2494,40 +2771,46 @@ func (x *EnableFirewallRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EnableFirewallRequest.ProtoReflect.Descriptor instead.
-func (*EnableFirewallRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteFirewallRuleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFirewallRuleResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_service_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *EnableFirewallRequest) GetAgentId() string {
+func (x *DeleteFirewallRuleResponse) GetStatus() string {
 	if x != nil {
-		return x.AgentId
+		return x.Status
 	}
 	return ""
 }
 
-type EnableFirewallResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+func (x *DeleteFirewallRuleResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListFirewallRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EnableFirewallResponse) Reset() {
-	*x = EnableFirewallResponse{}
+func (x *ListFirewallRulesRequest) Reset() {
+	*x = ListFirewallRulesRequest{}
 	mi := &file_api_v1_service_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EnableFirewallResponse) String() string {
+func (x *ListFirewallRulesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EnableFirewallResponse) ProtoMessage() {}
+func (*ListFirewallRulesRequest) ProtoMessage() {}
 
-func (x *EnableFirewallResponse) ProtoReflect() protoreflect.Message {
+func (x *ListFirewallRulesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_service_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2539,47 +2822,4889 @@ func (x *EnableFirewallResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EnableFirewallResponse.ProtoReflect.Descriptor instead.
-func (*EnableFirewallResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListFirewallRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListFirewallRulesRequest) Descriptor() ([]byte, []int) {
 	return file_api_v1_service_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *EnableFirewallResponse) GetStatus() string {
+func (x *ListFirewallRulesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListFirewallRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []string               `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFirewallRulesResponse) Reset() {
+	*x = ListFirewallRulesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFirewallRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFirewallRulesResponse) ProtoMessage() {}
+
+func (x *ListFirewallRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFirewallRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListFirewallRulesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ListFirewallRulesResponse) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type AllowPortRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllowPortRequest) Reset() {
+	*x = AllowPortRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllowPortRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllowPortRequest) ProtoMessage() {}
+
+func (x *AllowPortRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllowPortRequest.ProtoReflect.Descriptor instead.
+func (*AllowPortRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AllowPortRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AllowPortRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *AllowPortRequest) GetProto() string {
+	if x != nil {
+		return x.Proto
+	}
+	return ""
+}
+
+func (x *AllowPortRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type AllowPortResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllowPortResponse) Reset() {
+	*x = AllowPortResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllowPortResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllowPortResponse) ProtoMessage() {}
+
+func (x *AllowPortResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllowPortResponse.ProtoReflect.Descriptor instead.
+func (*AllowPortResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *AllowPortResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AllowPortResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AllowPortResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type DenyPortRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Proto         string                 `protobuf:"bytes,3,opt,name=proto,proto3" json:"proto,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyPortRequest) Reset() {
+	*x = DenyPortRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyPortRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyPortRequest) ProtoMessage() {}
+
+func (x *DenyPortRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyPortRequest.ProtoReflect.Descriptor instead.
+func (*DenyPortRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *DenyPortRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DenyPortRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *DenyPortRequest) GetProto() string {
+	if x != nil {
+		return x.Proto
+	}
+	return ""
+}
+
+func (x *DenyPortRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type DenyPortResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyPortResponse) Reset() {
+	*x = DenyPortResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyPortResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyPortResponse) ProtoMessage() {}
+
+func (x *DenyPortResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyPortResponse.ProtoReflect.Descriptor instead.
+func (*DenyPortResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DenyPortResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DenyPortResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DenyPortResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type EnableFirewallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableFirewallRequest) Reset() {
+	*x = EnableFirewallRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableFirewallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableFirewallRequest) ProtoMessage() {}
+
+func (x *EnableFirewallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableFirewallRequest.ProtoReflect.Descriptor instead.
+func (*EnableFirewallRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *EnableFirewallRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type EnableFirewallResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableFirewallResponse) Reset() {
+	*x = EnableFirewallResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableFirewallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableFirewallResponse) ProtoMessage() {}
+
+func (x *EnableFirewallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableFirewallResponse.ProtoReflect.Descriptor instead.
+func (*EnableFirewallResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *EnableFirewallResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EnableFirewallResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DisableFirewallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableFirewallRequest) Reset() {
+	*x = DisableFirewallRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableFirewallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableFirewallRequest) ProtoMessage() {}
+
+func (x *DisableFirewallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableFirewallRequest.ProtoReflect.Descriptor instead.
+func (*DisableFirewallRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *DisableFirewallRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type DisableFirewallResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableFirewallResponse) Reset() {
+	*x = DisableFirewallResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableFirewallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableFirewallResponse) ProtoMessage() {}
+
+func (x *DisableFirewallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableFirewallResponse.ProtoReflect.Descriptor instead.
+func (*DisableFirewallResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *DisableFirewallResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DisableFirewallResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RemoveFirewallRuleSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DryRun        bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveFirewallRuleSetRequest) Reset() {
+	*x = RemoveFirewallRuleSetRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveFirewallRuleSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFirewallRuleSetRequest) ProtoMessage() {}
+
+func (x *RemoveFirewallRuleSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFirewallRuleSetRequest.ProtoReflect.Descriptor instead.
+func (*RemoveFirewallRuleSetRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *RemoveFirewallRuleSetRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RemoveFirewallRuleSetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RemoveFirewallRuleSetRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type RemoveFirewallRuleSetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Removed       int32                  `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"` // Rules deleted
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"`        // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveFirewallRuleSetResponse) Reset() {
+	*x = RemoveFirewallRuleSetResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveFirewallRuleSetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFirewallRuleSetResponse) ProtoMessage() {}
+
+func (x *RemoveFirewallRuleSetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFirewallRuleSetResponse.ProtoReflect.Descriptor instead.
+func (*RemoveFirewallRuleSetResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *RemoveFirewallRuleSetResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RemoveFirewallRuleSetResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *RemoveFirewallRuleSetResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type ObtainCertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Production    bool                   `protobuf:"varint,4,opt,name=production,proto3" json:"production,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObtainCertificateRequest) Reset() {
+	*x = ObtainCertificateRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObtainCertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObtainCertificateRequest) ProtoMessage() {}
+
+func (x *ObtainCertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObtainCertificateRequest.ProtoReflect.Descriptor instead.
+func (*ObtainCertificateRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ObtainCertificateRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ObtainCertificateRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ObtainCertificateRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ObtainCertificateRequest) GetProduction() bool {
+	if x != nil {
+		return x.Production
+	}
+	return false
+}
+
+type ObtainCertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificate   *Certificate           `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObtainCertificateResponse) Reset() {
+	*x = ObtainCertificateResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObtainCertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObtainCertificateResponse) ProtoMessage() {}
+
+func (x *ObtainCertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObtainCertificateResponse.ProtoReflect.Descriptor instead.
+func (*ObtainCertificateResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ObtainCertificateResponse) GetCertificate() *Certificate {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *ObtainCertificateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RenewCertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewCertificateRequest) Reset() {
+	*x = RenewCertificateRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewCertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewCertificateRequest) ProtoMessage() {}
+
+func (x *RenewCertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewCertificateRequest.ProtoReflect.Descriptor instead.
+func (*RenewCertificateRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *RenewCertificateRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RenewCertificateRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type RenewCertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewCertificateResponse) Reset() {
+	*x = RenewCertificateResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewCertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewCertificateResponse) ProtoMessage() {}
+
+func (x *RenewCertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewCertificateResponse.ProtoReflect.Descriptor instead.
+func (*RenewCertificateResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *RenewCertificateResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RenewCertificateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RenewAllCertificatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewAllCertificatesRequest) Reset() {
+	*x = RenewAllCertificatesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewAllCertificatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewAllCertificatesRequest) ProtoMessage() {}
+
+func (x *RenewAllCertificatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewAllCertificatesRequest.ProtoReflect.Descriptor instead.
+func (*RenewAllCertificatesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *RenewAllCertificatesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type RenewAllCertificatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewAllCertificatesResponse) Reset() {
+	*x = RenewAllCertificatesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewAllCertificatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewAllCertificatesResponse) ProtoMessage() {}
+
+func (x *RenewAllCertificatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewAllCertificatesResponse.ProtoReflect.Descriptor instead.
+func (*RenewAllCertificatesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *RenewAllCertificatesResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RenewAllCertificatesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RevokeCertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeCertificateRequest) Reset() {
+	*x = RevokeCertificateRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCertificateRequest) ProtoMessage() {}
+
+func (x *RevokeCertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCertificateRequest.ProtoReflect.Descriptor instead.
+func (*RevokeCertificateRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *RevokeCertificateRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RevokeCertificateRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type RevokeCertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeCertificateResponse) Reset() {
+	*x = RevokeCertificateResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCertificateResponse) ProtoMessage() {}
+
+func (x *RevokeCertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCertificateResponse.ProtoReflect.Descriptor instead.
+func (*RevokeCertificateResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *RevokeCertificateResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RevokeCertificateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListCertificatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCertificatesRequest) Reset() {
+	*x = ListCertificatesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCertificatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCertificatesRequest) ProtoMessage() {}
+
+func (x *ListCertificatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCertificatesRequest.ProtoReflect.Descriptor instead.
+func (*ListCertificatesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *ListCertificatesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListCertificatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificates  []*Certificate         `protobuf:"bytes,1,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCertificatesResponse) Reset() {
+	*x = ListCertificatesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCertificatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCertificatesResponse) ProtoMessage() {}
+
+func (x *ListCertificatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCertificatesResponse.ProtoReflect.Descriptor instead.
+func (*ListCertificatesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ListCertificatesResponse) GetCertificates() []*Certificate {
+	if x != nil {
+		return x.Certificates
+	}
+	return nil
+}
+
+type Certificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	CertPath      string                 `protobuf:"bytes,2,opt,name=cert_path,json=certPath,proto3" json:"cert_path,omitempty"`
+	KeyPath       string                 `protobuf:"bytes,3,opt,name=key_path,json=keyPath,proto3" json:"key_path,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	IssuedAt      string                 `protobuf:"bytes,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	Issuer        string                 `protobuf:"bytes,6,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Certificate) Reset() {
+	*x = Certificate{}
+	mi := &file_api_v1_service_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Certificate) ProtoMessage() {}
+
+func (x *Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *Certificate) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Certificate) GetCertPath() string {
+	if x != nil {
+		return x.CertPath
+	}
+	return ""
+}
+
+func (x *Certificate) GetKeyPath() string {
+	if x != nil {
+		return x.KeyPath
+	}
+	return ""
+}
+
+func (x *Certificate) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *Certificate) GetIssuedAt() string {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return ""
+}
+
+func (x *Certificate) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+type ListSysctlProfilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSysctlProfilesRequest) Reset() {
+	*x = ListSysctlProfilesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSysctlProfilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSysctlProfilesRequest) ProtoMessage() {}
+
+func (x *ListSysctlProfilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSysctlProfilesRequest.ProtoReflect.Descriptor instead.
+func (*ListSysctlProfilesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *ListSysctlProfilesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListSysctlProfilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Profiles      []*SysctlProfile       `protobuf:"bytes,1,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSysctlProfilesResponse) Reset() {
+	*x = ListSysctlProfilesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSysctlProfilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSysctlProfilesResponse) ProtoMessage() {}
+
+func (x *ListSysctlProfilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSysctlProfilesResponse.ProtoReflect.Descriptor instead.
+func (*ListSysctlProfilesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *ListSysctlProfilesResponse) GetProfiles() []*SysctlProfile {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+// SysctlProfile is a built-in set of kernel parameters for one workload
+type SysctlProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Settings      map[string]string      `protobuf:"bytes,3,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Applied       bool                   `protobuf:"varint,4,opt,name=applied,proto3" json:"applied,omitempty"` // Persisted on the host
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SysctlProfile) Reset() {
+	*x = SysctlProfile{}
+	mi := &file_api_v1_service_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SysctlProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SysctlProfile) ProtoMessage() {}
+
+func (x *SysctlProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SysctlProfile.ProtoReflect.Descriptor instead.
+func (*SysctlProfile) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *SysctlProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SysctlProfile) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SysctlProfile) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *SysctlProfile) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+// Persists a profile to /etc/sysctl.d and loads it, or deletes it
+type ApplySysctlProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Remove        bool                   `protobuf:"varint,3,opt,name=remove,proto3" json:"remove,omitempty"` // The running values stay until the next boot
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`   // Replace or delete a file edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplySysctlProfileRequest) Reset() {
+	*x = ApplySysctlProfileRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplySysctlProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplySysctlProfileRequest) ProtoMessage() {}
+
+func (x *ApplySysctlProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplySysctlProfileRequest.ProtoReflect.Descriptor instead.
+func (*ApplySysctlProfileRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *ApplySysctlProfileRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ApplySysctlProfileRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ApplySysctlProfileRequest) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+func (x *ApplySysctlProfileRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ApplySysctlProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplySysctlProfileResponse) Reset() {
+	*x = ApplySysctlProfileResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplySysctlProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplySysctlProfileResponse) ProtoMessage() {}
+
+func (x *ApplySysctlProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplySysctlProfileResponse.ProtoReflect.Descriptor instead.
+func (*ApplySysctlProfileResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *ApplySysctlProfileResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type VerifySysctlsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifySysctlsRequest) Reset() {
+	*x = VerifySysctlsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifySysctlsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifySysctlsRequest) ProtoMessage() {}
+
+func (x *VerifySysctlsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifySysctlsRequest.ProtoReflect.Descriptor instead.
+func (*VerifySysctlsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *VerifySysctlsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type VerifySysctlsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*SysctlCheck         `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifySysctlsResponse) Reset() {
+	*x = VerifySysctlsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifySysctlsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifySysctlsResponse) ProtoMessage() {}
+
+func (x *VerifySysctlsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifySysctlsResponse.ProtoReflect.Descriptor instead.
+func (*VerifySysctlsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *VerifySysctlsResponse) GetChecks() []*SysctlCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+// SysctlCheck compares a kernel parameter Mandau persisted with its running
+// value
+type SysctlCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Persisted     string                 `protobuf:"bytes,2,opt,name=persisted,proto3" json:"persisted,omitempty"`
+	Runtime       string                 `protobuf:"bytes,3,opt,name=runtime,proto3" json:"runtime,omitempty"` // Empty when the kernel does not know the key
+	File          string                 `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`       // Where the persisted value comes from
+	Ok            bool                   `protobuf:"varint,5,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SysctlCheck) Reset() {
+	*x = SysctlCheck{}
+	mi := &file_api_v1_service_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SysctlCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SysctlCheck) ProtoMessage() {}
+
+func (x *SysctlCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SysctlCheck.ProtoReflect.Descriptor instead.
+func (*SysctlCheck) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *SysctlCheck) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SysctlCheck) GetPersisted() string {
+	if x != nil {
+		return x.Persisted
+	}
+	return ""
+}
+
+func (x *SysctlCheck) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *SysctlCheck) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *SysctlCheck) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// Limit is a line of limits.conf, applied by pam_limits to login sessions
+type Limit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"` // User, @group or *
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`     // soft, hard or - for both
+	Item          string                 `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`     // nofile, nproc, memlock...
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`   // Number, or unlimited
+	File          string                 `protobuf:"bytes,5,opt,name=file,proto3" json:"file,omitempty"`
+	Managed       bool                   `protobuf:"varint,6,opt,name=managed,proto3" json:"managed,omitempty"` // In a file Mandau wrote
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Limit) Reset() {
+	*x = Limit{}
+	mi := &file_api_v1_service_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limit) ProtoMessage() {}
+
+func (x *Limit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limit.ProtoReflect.Descriptor instead.
+func (*Limit) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *Limit) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Limit) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Limit) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+func (x *Limit) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Limit) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Limit) GetManaged() bool {
+	if x != nil {
+		return x.Managed
+	}
+	return false
+}
+
+type GetLimitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLimitsRequest) Reset() {
+	*x = GetLimitsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLimitsRequest) ProtoMessage() {}
+
+func (x *GetLimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLimitsRequest.ProtoReflect.Descriptor instead.
+func (*GetLimitsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *GetLimitsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type GetLimitsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limits        []*Limit               `protobuf:"bytes,1,rep,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLimitsResponse) Reset() {
+	*x = GetLimitsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLimitsResponse) ProtoMessage() {}
+
+func (x *GetLimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLimitsResponse.ProtoReflect.Descriptor instead.
+func (*GetLimitsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *GetLimitsResponse) GetLimits() []*Limit {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+// Replaces the limits of /etc/security/limits.d/60-mandau-<name>.conf;
+// without limits the file is deleted
+type SetLimitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Limits        []*Limit               `protobuf:"bytes,3,rep,name=limits,proto3" json:"limits,omitempty"`
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"` // Replace or delete a file edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLimitsRequest) Reset() {
+	*x = SetLimitsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLimitsRequest) ProtoMessage() {}
+
+func (x *SetLimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLimitsRequest.ProtoReflect.Descriptor instead.
+func (*SetLimitsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *SetLimitsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SetLimitsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SetLimitsRequest) GetLimits() []*Limit {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *SetLimitsRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type SetLimitsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLimitsResponse) Reset() {
+	*x = SetLimitsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLimitsResponse) ProtoMessage() {}
+
+func (x *SetLimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLimitsResponse.ProtoReflect.Descriptor instead.
+func (*SetLimitsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *SetLimitsResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// The settings of /etc/docker/daemon.json Mandau manages. Empty settings
+// are removed from the file; other keys are kept.
+type DockerDaemonSettings struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	LogDriver           string                 `protobuf:"bytes,1,opt,name=log_driver,json=logDriver,proto3" json:"log_driver,omitempty"`
+	LogOpts             map[string]string      `protobuf:"bytes,2,rep,name=log_opts,json=logOpts,proto3" json:"log_opts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RegistryMirrors     []string               `protobuf:"bytes,3,rep,name=registry_mirrors,json=registryMirrors,proto3" json:"registry_mirrors,omitempty"`
+	StorageDriver       string                 `protobuf:"bytes,4,opt,name=storage_driver,json=storageDriver,proto3" json:"storage_driver,omitempty"`
+	StorageOpts         []string               `protobuf:"bytes,5,rep,name=storage_opts,json=storageOpts,proto3" json:"storage_opts,omitempty"`
+	DefaultAddressPools []*DockerAddressPool   `protobuf:"bytes,6,rep,name=default_address_pools,json=defaultAddressPools,proto3" json:"default_address_pools,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DockerDaemonSettings) Reset() {
+	*x = DockerDaemonSettings{}
+	mi := &file_api_v1_service_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DockerDaemonSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DockerDaemonSettings) ProtoMessage() {}
+
+func (x *DockerDaemonSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DockerDaemonSettings.ProtoReflect.Descriptor instead.
+func (*DockerDaemonSettings) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *DockerDaemonSettings) GetLogDriver() string {
+	if x != nil {
+		return x.LogDriver
+	}
+	return ""
+}
+
+func (x *DockerDaemonSettings) GetLogOpts() map[string]string {
+	if x != nil {
+		return x.LogOpts
+	}
+	return nil
+}
+
+func (x *DockerDaemonSettings) GetRegistryMirrors() []string {
+	if x != nil {
+		return x.RegistryMirrors
+	}
+	return nil
+}
+
+func (x *DockerDaemonSettings) GetStorageDriver() string {
+	if x != nil {
+		return x.StorageDriver
+	}
+	return ""
+}
+
+func (x *DockerDaemonSettings) GetStorageOpts() []string {
+	if x != nil {
+		return x.StorageOpts
+	}
+	return nil
+}
+
+func (x *DockerDaemonSettings) GetDefaultAddressPools() []*DockerAddressPool {
+	if x != nil {
+		return x.DefaultAddressPools
+	}
+	return nil
+}
+
+type DockerAddressPool struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Base          string                 `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`  // CIDR, such as 10.200.0.0/16
+	Size          int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // Prefix length of the networks carved from it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DockerAddressPool) Reset() {
+	*x = DockerAddressPool{}
+	mi := &file_api_v1_service_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DockerAddressPool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DockerAddressPool) ProtoMessage() {}
+
+func (x *DockerAddressPool) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DockerAddressPool.ProtoReflect.Descriptor instead.
+func (*DockerAddressPool) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *DockerAddressPool) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *DockerAddressPool) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type GetDockerDaemonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDockerDaemonRequest) Reset() {
+	*x = GetDockerDaemonRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDockerDaemonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDockerDaemonRequest) ProtoMessage() {}
+
+func (x *GetDockerDaemonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDockerDaemonRequest.ProtoReflect.Descriptor instead.
+func (*GetDockerDaemonRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *GetDockerDaemonRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type GetDockerDaemonResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Settings             *DockerDaemonSettings  `protobuf:"bytes,1,opt,name=settings,proto3" json:"settings,omitempty"`
+	Content              string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // All of daemon.json
+	Running              bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	RunningLogDriver     string                 `protobuf:"bytes,4,opt,name=running_log_driver,json=runningLogDriver,proto3" json:"running_log_driver,omitempty"`
+	RunningStorageDriver string                 `protobuf:"bytes,5,opt,name=running_storage_driver,json=runningStorageDriver,proto3" json:"running_storage_driver,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetDockerDaemonResponse) Reset() {
+	*x = GetDockerDaemonResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDockerDaemonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDockerDaemonResponse) ProtoMessage() {}
+
+func (x *GetDockerDaemonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDockerDaemonResponse.ProtoReflect.Descriptor instead.
+func (*GetDockerDaemonResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *GetDockerDaemonResponse) GetSettings() *DockerDaemonSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *GetDockerDaemonResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *GetDockerDaemonResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *GetDockerDaemonResponse) GetRunningLogDriver() string {
+	if x != nil {
+		return x.RunningLogDriver
+	}
+	return ""
+}
+
+func (x *GetDockerDaemonResponse) GetRunningStorageDriver() string {
+	if x != nil {
+		return x.RunningStorageDriver
+	}
+	return ""
+}
+
+type SetDockerDaemonRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AgentId  string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Settings *DockerDaemonSettings  `protobuf:"bytes,2,opt,name=settings,proto3" json:"settings,omitempty"`
+	// Restart Docker to take the settings up, restoring the previous
+	// daemon.json if it does not come back
+	Restart       bool `protobuf:"varint,3,opt,name=restart,proto3" json:"restart,omitempty"`
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDockerDaemonRequest) Reset() {
+	*x = SetDockerDaemonRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDockerDaemonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDockerDaemonRequest) ProtoMessage() {}
+
+func (x *SetDockerDaemonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDockerDaemonRequest.ProtoReflect.Descriptor instead.
+func (*SetDockerDaemonRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *SetDockerDaemonRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SetDockerDaemonRequest) GetSettings() *DockerDaemonSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *SetDockerDaemonRequest) GetRestart() bool {
+	if x != nil {
+		return x.Restart
+	}
+	return false
+}
+
+func (x *SetDockerDaemonRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SetDockerDaemonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Changed       bool                   `protobuf:"varint,2,opt,name=changed,proto3" json:"changed,omitempty"`
+	Restarted     bool                   `protobuf:"varint,3,opt,name=restarted,proto3" json:"restarted,omitempty"`
+	Diff          string                 `protobuf:"bytes,4,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDockerDaemonResponse) Reset() {
+	*x = SetDockerDaemonResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDockerDaemonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDockerDaemonResponse) ProtoMessage() {}
+
+func (x *SetDockerDaemonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDockerDaemonResponse.ProtoReflect.Descriptor instead.
+func (*SetDockerDaemonResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *SetDockerDaemonResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SetDockerDaemonResponse) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+func (x *SetDockerDaemonResponse) GetRestarted() bool {
+	if x != nil {
+		return x.Restarted
+	}
+	return false
+}
+
+func (x *SetDockerDaemonResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *GetUserRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetUserRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetUserResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Exists         bool                   `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	Shell          string                 `protobuf:"bytes,2,opt,name=shell,proto3" json:"shell,omitempty"`
+	Home           string                 `protobuf:"bytes,3,opt,name=home,proto3" json:"home,omitempty"`
+	Groups         []string               `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`                                       // Primary and supplementary groups
+	AuthorizedKeys []string               `protobuf:"bytes,5,rep,name=authorized_keys,json=authorizedKeys,proto3" json:"authorized_keys,omitempty"` // From ~/.ssh/authorized_keys
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetUserResponse) Reset() {
+	*x = GetUserResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserResponse) ProtoMessage() {}
+
+func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserResponse.ProtoReflect.Descriptor instead.
+func (*GetUserResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *GetUserResponse) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *GetUserResponse) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *GetUserResponse) GetHome() string {
+	if x != nil {
+		return x.Home
+	}
+	return ""
+}
+
+func (x *GetUserResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *GetUserResponse) GetAuthorizedKeys() []string {
+	if x != nil {
+		return x.AuthorizedKeys
+	}
+	return nil
+}
+
+// Creates the user when missing, otherwise brings its shell, groups and
+// keys in line
+type EnsureUserRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Shell   string                 `protobuf:"bytes,3,opt,name=shell,proto3" json:"shell,omitempty"`   // Empty keeps the default or current shell
+	Groups  []string               `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"` // Added to; the user is never removed from any
+	// Replace ~/.ssh/authorized_keys when set
+	AuthorizedKeys []string `protobuf:"bytes,5,rep,name=authorized_keys,json=authorizedKeys,proto3" json:"authorized_keys,omitempty"`
+	Force          bool     `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"` // Replace an authorized_keys file edited outside Mandau
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EnsureUserRequest) Reset() {
+	*x = EnsureUserRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnsureUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnsureUserRequest) ProtoMessage() {}
+
+func (x *EnsureUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnsureUserRequest.ProtoReflect.Descriptor instead.
+func (*EnsureUserRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *EnsureUserRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *EnsureUserRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EnsureUserRequest) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *EnsureUserRequest) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *EnsureUserRequest) GetAuthorizedKeys() []string {
+	if x != nil {
+		return x.AuthorizedKeys
+	}
+	return nil
+}
+
+func (x *EnsureUserRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type EnsureUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnsureUserResponse) Reset() {
+	*x = EnsureUserResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnsureUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnsureUserResponse) ProtoMessage() {}
+
+func (x *EnsureUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnsureUserResponse.ProtoReflect.Descriptor instead.
+func (*EnsureUserResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *EnsureUserResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetPatchStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPatchStatusRequest) Reset() {
+	*x = GetPatchStatusRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPatchStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPatchStatusRequest) ProtoMessage() {}
+
+func (x *GetPatchStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPatchStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetPatchStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *GetPatchStatusRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type GetTimeSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTimeSyncRequest) Reset() {
+	*x = GetTimeSyncRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTimeSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTimeSyncRequest) ProtoMessage() {}
+
+func (x *GetTimeSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTimeSyncRequest.ProtoReflect.Descriptor instead.
+func (*GetTimeSyncRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *GetTimeSyncRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type GetTimeSyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Daemon        string                 `protobuf:"bytes,1,opt,name=daemon,proto3" json:"daemon,omitempty"` // chrony, systemd-timesyncd or ntpd; empty when none runs
+	Synchronized  bool                   `protobuf:"varint,2,opt,name=synchronized,proto3" json:"synchronized,omitempty"`
+	OffsetSeconds float64                `protobuf:"fixed64,3,opt,name=offset_seconds,json=offsetSeconds,proto3" json:"offset_seconds,omitempty"` // From the time sources, as chrony reports it
+	Stratum       int32                  `protobuf:"varint,4,opt,name=stratum,proto3" json:"stratum,omitempty"`
+	Servers       []string               `protobuf:"bytes,5,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTimeSyncResponse) Reset() {
+	*x = GetTimeSyncResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTimeSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTimeSyncResponse) ProtoMessage() {}
+
+func (x *GetTimeSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTimeSyncResponse.ProtoReflect.Descriptor instead.
+func (*GetTimeSyncResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *GetTimeSyncResponse) GetDaemon() string {
+	if x != nil {
+		return x.Daemon
+	}
+	return ""
+}
+
+func (x *GetTimeSyncResponse) GetSynchronized() bool {
+	if x != nil {
+		return x.Synchronized
+	}
+	return false
+}
+
+func (x *GetTimeSyncResponse) GetOffsetSeconds() float64 {
+	if x != nil {
+		return x.OffsetSeconds
+	}
+	return 0
+}
+
+func (x *GetTimeSyncResponse) GetStratum() int32 {
+	if x != nil {
+		return x.Stratum
+	}
+	return 0
+}
+
+func (x *GetTimeSyncResponse) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+// Installs chrony when missing and points it at servers
+type ConfigureTimeSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Servers       []string               `protobuf:"bytes,2,rep,name=servers,proto3" json:"servers,omitempty"` // NTP servers or pools
+	Step          bool                   `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`      // Step the clock now instead of slewing it
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`    // Replace a sources file edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureTimeSyncRequest) Reset() {
+	*x = ConfigureTimeSyncRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureTimeSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureTimeSyncRequest) ProtoMessage() {}
+
+func (x *ConfigureTimeSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureTimeSyncRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureTimeSyncRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *ConfigureTimeSyncRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ConfigureTimeSyncRequest) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+func (x *ConfigureTimeSyncRequest) GetStep() bool {
+	if x != nil {
+		return x.Step
+	}
+	return false
+}
+
+func (x *ConfigureTimeSyncRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ConfigureTimeSyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureTimeSyncResponse) Reset() {
+	*x = ConfigureTimeSyncResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureTimeSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureTimeSyncResponse) ProtoMessage() {}
+
+func (x *ConfigureTimeSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureTimeSyncResponse.ProtoReflect.Descriptor instead.
+func (*ConfigureTimeSyncResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *ConfigureTimeSyncResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetHostInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostInfoRequest) Reset() {
+	*x = GetHostInfoRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostInfoRequest) ProtoMessage() {}
+
+func (x *GetHostInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetHostInfoRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *GetHostInfoRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type GetHostInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Kernel        string                 `protobuf:"bytes,3,opt,name=kernel,proto3" json:"kernel,omitempty"`
+	Architecture  string                 `protobuf:"bytes,4,opt,name=architecture,proto3" json:"architecture,omitempty"`
+	CpuCores      int32                  `protobuf:"varint,5,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
+	MemoryMb      int64                  `protobuf:"varint,6,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	DiskGb        int64                  `protobuf:"varint,7,opt,name=disk_gb,json=diskGb,proto3" json:"disk_gb,omitempty"`
+	Uptime        string                 `protobuf:"bytes,8,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostInfoResponse) Reset() {
+	*x = GetHostInfoResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostInfoResponse) ProtoMessage() {}
+
+func (x *GetHostInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetHostInfoResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *GetHostInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *GetHostInfoResponse) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *GetHostInfoResponse) GetKernel() string {
+	if x != nil {
+		return x.Kernel
+	}
+	return ""
+}
+
+func (x *GetHostInfoResponse) GetArchitecture() string {
+	if x != nil {
+		return x.Architecture
+	}
+	return ""
+}
+
+func (x *GetHostInfoResponse) GetCpuCores() int32 {
+	if x != nil {
+		return x.CpuCores
+	}
+	return 0
+}
+
+func (x *GetHostInfoResponse) GetMemoryMb() int64 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *GetHostInfoResponse) GetDiskGb() int64 {
+	if x != nil {
+		return x.DiskGb
+	}
+	return 0
+}
+
+func (x *GetHostInfoResponse) GetUptime() string {
+	if x != nil {
+		return x.Uptime
+	}
+	return ""
+}
+
+type InstallPackageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	PackageName   string                 `protobuf:"bytes,2,opt,name=package_name,json=packageName,proto3" json:"package_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallPackageRequest) Reset() {
+	*x = InstallPackageRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallPackageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallPackageRequest) ProtoMessage() {}
+
+func (x *InstallPackageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallPackageRequest.ProtoReflect.Descriptor instead.
+func (*InstallPackageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *InstallPackageRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InstallPackageRequest) GetPackageName() string {
+	if x != nil {
+		return x.PackageName
+	}
+	return ""
+}
+
+type InstallPackageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallPackageResponse) Reset() {
+	*x = InstallPackageResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallPackageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallPackageResponse) ProtoMessage() {}
+
+func (x *InstallPackageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallPackageResponse.ProtoReflect.Descriptor instead.
+func (*InstallPackageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *InstallPackageResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *InstallPackageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RemovePackageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	PackageName   string                 `protobuf:"bytes,2,opt,name=package_name,json=packageName,proto3" json:"package_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemovePackageRequest) Reset() {
+	*x = RemovePackageRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemovePackageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePackageRequest) ProtoMessage() {}
+
+func (x *RemovePackageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePackageRequest.ProtoReflect.Descriptor instead.
+func (*RemovePackageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *RemovePackageRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RemovePackageRequest) GetPackageName() string {
+	if x != nil {
+		return x.PackageName
+	}
+	return ""
+}
+
+type RemovePackageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemovePackageResponse) Reset() {
+	*x = RemovePackageResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemovePackageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePackageResponse) ProtoMessage() {}
+
+func (x *RemovePackageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePackageResponse.ProtoReflect.Descriptor instead.
+func (*RemovePackageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *RemovePackageResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RemovePackageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdatePackagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackagesRequest) Reset() {
+	*x = UpdatePackagesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackagesRequest) ProtoMessage() {}
+
+func (x *UpdatePackagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackagesRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePackagesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{100}
+}
+
+func (x *UpdatePackagesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type UpdatePackagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackagesResponse) Reset() {
+	*x = UpdatePackagesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackagesResponse) ProtoMessage() {}
+
+func (x *UpdatePackagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackagesResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePackagesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *UpdatePackagesResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdatePackagesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListPackagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPackagesRequest) Reset() {
+	*x = ListPackagesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPackagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPackagesRequest) ProtoMessage() {}
+
+func (x *ListPackagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPackagesRequest.ProtoReflect.Descriptor instead.
+func (*ListPackagesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *ListPackagesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListPackagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Packages      []string               `protobuf:"bytes,1,rep,name=packages,proto3" json:"packages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPackagesResponse) Reset() {
+	*x = ListPackagesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPackagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPackagesResponse) ProtoMessage() {}
+
+func (x *ListPackagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPackagesResponse.ProtoReflect.Descriptor instead.
+func (*ListPackagesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *ListPackagesResponse) GetPackages() []string {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+type SetSysctlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Persist       bool                   `protobuf:"varint,4,opt,name=persist,proto3" json:"persist,omitempty"` // Also write it to /etc/sysctl.d to survive reboots
+	Force         bool                   `protobuf:"varint,5,opt,name=force,proto3" json:"force,omitempty"`     // Replace a sysctl.d file edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSysctlRequest) Reset() {
+	*x = SetSysctlRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSysctlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSysctlRequest) ProtoMessage() {}
+
+func (x *SetSysctlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSysctlRequest.ProtoReflect.Descriptor instead.
+func (*SetSysctlRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *SetSysctlRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SetSysctlRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SetSysctlRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SetSysctlRequest) GetPersist() bool {
+	if x != nil {
+		return x.Persist
+	}
+	return false
+}
+
+func (x *SetSysctlRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type SetSysctlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSysctlResponse) Reset() {
+	*x = SetSysctlResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSysctlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSysctlResponse) ProtoMessage() {}
+
+func (x *SetSysctlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[105]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSysctlResponse.ProtoReflect.Descriptor instead.
+func (*SetSysctlResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *SetSysctlResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SetSysctlResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetSysctlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSysctlRequest) Reset() {
+	*x = GetSysctlRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSysctlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSysctlRequest) ProtoMessage() {}
+
+func (x *GetSysctlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[106]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSysctlRequest.ProtoReflect.Descriptor instead.
+func (*GetSysctlRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *GetSysctlRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetSysctlRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetSysctlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSysctlResponse) Reset() {
+	*x = GetSysctlResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[107]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSysctlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSysctlResponse) ProtoMessage() {}
+
+func (x *GetSysctlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[107]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSysctlResponse.ProtoReflect.Descriptor instead.
+func (*GetSysctlResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{107}
+}
+
+func (x *GetSysctlResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *GetSysctlResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CronJob struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Schedule      string                 `protobuf:"bytes,2,opt,name=schedule,proto3" json:"schedule,omitempty"` // Cron expression
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	User          string                 `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"` // The plugin's configured user when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CronJob) Reset() {
+	*x = CronJob{}
+	mi := &file_api_v1_service_proto_msgTypes[108]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CronJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CronJob) ProtoMessage() {}
+
+func (x *CronJob) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[108]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CronJob.ProtoReflect.Descriptor instead.
+func (*CronJob) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{108}
+}
+
+func (x *CronJob) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CronJob) GetSchedule() string {
+	if x != nil {
+		return x.Schedule
+	}
+	return ""
+}
+
+func (x *CronJob) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *CronJob) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type AddCronJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Job           *CronJob               `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	DryRun        bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCronJobRequest) Reset() {
+	*x = AddCronJobRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[109]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCronJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCronJobRequest) ProtoMessage() {}
+
+func (x *AddCronJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[109]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCronJobRequest.ProtoReflect.Descriptor instead.
+func (*AddCronJobRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{109}
+}
+
+func (x *AddCronJobRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AddCronJobRequest) GetJob() *CronJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *AddCronJobRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *AddCronJobRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type AddCronJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCronJobResponse) Reset() {
+	*x = AddCronJobResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[110]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCronJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCronJobResponse) ProtoMessage() {}
+
+func (x *AddCronJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[110]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCronJobResponse.ProtoReflect.Descriptor instead.
+func (*AddCronJobResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{110}
+}
+
+func (x *AddCronJobResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddCronJobResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AddCronJobResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type RemoveCronJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DryRun        bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`                 // Remove files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveCronJobRequest) Reset() {
+	*x = RemoveCronJobRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[111]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveCronJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveCronJobRequest) ProtoMessage() {}
+
+func (x *RemoveCronJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[111]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveCronJobRequest.ProtoReflect.Descriptor instead.
+func (*RemoveCronJobRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{111}
+}
+
+func (x *RemoveCronJobRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RemoveCronJobRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RemoveCronJobRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *RemoveCronJobRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type RemoveCronJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveCronJobResponse) Reset() {
+	*x = RemoveCronJobResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[112]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveCronJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveCronJobResponse) ProtoMessage() {}
+
+func (x *RemoveCronJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[112]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveCronJobResponse.ProtoReflect.Descriptor instead.
+func (*RemoveCronJobResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{112}
+}
+
+func (x *RemoveCronJobResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RemoveCronJobResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RemoveCronJobResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type ListCronJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCronJobsRequest) Reset() {
+	*x = ListCronJobsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[113]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCronJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCronJobsRequest) ProtoMessage() {}
+
+func (x *ListCronJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[113]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCronJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListCronJobsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{113}
+}
+
+func (x *ListCronJobsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListCronJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*CronJob             `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCronJobsResponse) Reset() {
+	*x = ListCronJobsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[114]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCronJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCronJobsResponse) ProtoMessage() {}
+
+func (x *ListCronJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[114]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCronJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListCronJobsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{114}
+}
+
+func (x *ListCronJobsResponse) GetJobs() []*CronJob {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type CreateZoneRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`                     // 3600 when zero
+	Nameservers   []string               `protobuf:"bytes,4,rep,name=nameservers,proto3" json:"nameservers,omitempty"`      // ns1.<domain> when empty
+	Admin         string                 `protobuf:"bytes,5,opt,name=admin,proto3" json:"admin,omitempty"`                  // SOA contact, hostmaster.<domain> when empty
+	DryRun        bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateZoneRequest) Reset() {
+	*x = CreateZoneRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[115]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateZoneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateZoneRequest) ProtoMessage() {}
+
+func (x *CreateZoneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[115]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateZoneRequest.ProtoReflect.Descriptor instead.
+func (*CreateZoneRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{115}
+}
+
+func (x *CreateZoneRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *CreateZoneRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *CreateZoneRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *CreateZoneRequest) GetNameservers() []string {
+	if x != nil {
+		return x.Nameservers
+	}
+	return nil
+}
+
+func (x *CreateZoneRequest) GetAdmin() string {
+	if x != nil {
+		return x.Admin
+	}
+	return ""
+}
+
+func (x *CreateZoneRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateZoneRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type CreateZoneResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateZoneResponse) Reset() {
+	*x = CreateZoneResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[116]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateZoneResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateZoneResponse) ProtoMessage() {}
+
+func (x *CreateZoneResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[116]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateZoneResponse.ProtoReflect.Descriptor instead.
+func (*CreateZoneResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{116}
+}
+
+func (x *CreateZoneResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateZoneResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreateZoneResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type AddARecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	Ttl           int32                  `protobuf:"varint,5,opt,name=ttl,proto3" json:"ttl,omitempty"`                     // 3600 when zero
+	DryRun        bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddARecordRequest) Reset() {
+	*x = AddARecordRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[117]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddARecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddARecordRequest) ProtoMessage() {}
+
+func (x *AddARecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[117]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddARecordRequest.ProtoReflect.Descriptor instead.
+func (*AddARecordRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{117}
+}
+
+func (x *AddARecordRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AddARecordRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *AddARecordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddARecordRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *AddARecordRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *AddARecordRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *AddARecordRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type AddARecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddARecordResponse) Reset() {
+	*x = AddARecordResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddARecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddARecordResponse) ProtoMessage() {}
+
+func (x *AddARecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[118]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddARecordResponse.ProtoReflect.Descriptor instead.
+func (*AddARecordResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *AddARecordResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddARecordResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AddARecordResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type AddCNAMERecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Ttl           int32                  `protobuf:"varint,5,opt,name=ttl,proto3" json:"ttl,omitempty"`                     // 3600 when zero
+	DryRun        bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Diff what would change without changing it
+	Force         bool                   `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`                 // Overwrite files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCNAMERecordRequest) Reset() {
+	*x = AddCNAMERecordRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[119]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCNAMERecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCNAMERecordRequest) ProtoMessage() {}
+
+func (x *AddCNAMERecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[119]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCNAMERecordRequest.ProtoReflect.Descriptor instead.
+func (*AddCNAMERecordRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{119}
+}
+
+func (x *AddCNAMERecordRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *AddCNAMERecordRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *AddCNAMERecordRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type AddCNAMERecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // Unified diff of the change, on dry runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCNAMERecordResponse) Reset() {
+	*x = AddCNAMERecordResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[120]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCNAMERecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCNAMERecordResponse) ProtoMessage() {}
+
+func (x *AddCNAMERecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[120]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCNAMERecordResponse.ProtoReflect.Descriptor instead.
+func (*AddCNAMERecordResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{120}
+}
+
+func (x *AddCNAMERecordResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AddCNAMERecordResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type ListZonesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListZonesRequest) Reset() {
+	*x = ListZonesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[121]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListZonesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListZonesRequest) ProtoMessage() {}
+
+func (x *ListZonesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[121]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListZonesRequest.ProtoReflect.Descriptor instead.
+func (*ListZonesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{121}
+}
+
+func (x *ListZonesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListZonesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Zones         []string               `protobuf:"bytes,1,rep,name=zones,proto3" json:"zones,omitempty"` // Domains with a zone file, sorted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListZonesResponse) Reset() {
+	*x = ListZonesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[122]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListZonesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListZonesResponse) ProtoMessage() {}
+
+func (x *ListZonesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[122]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListZonesResponse.ProtoReflect.Descriptor instead.
+func (*ListZonesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{122}
+}
+
+func (x *ListZonesResponse) GetZones() []string {
+	if x != nil {
+		return x.Zones
+	}
+	return nil
+}
+
+// ServiceOperationEvent - used for streaming service deployment operations
+type ServiceOperationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceOperationEvent) Reset() {
+	*x = ServiceOperationEvent{}
+	mi := &file_api_v1_service_proto_msgTypes[123]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceOperationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceOperationEvent) ProtoMessage() {}
+
+func (x *ServiceOperationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[123]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceOperationEvent.ProtoReflect.Descriptor instead.
+func (*ServiceOperationEvent) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{123}
+}
+
+func (x *ServiceOperationEvent) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *ServiceOperationEvent) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ServiceOperationEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ServiceOperationEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServiceOperationEvent) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *ServiceOperationEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeployWebServiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Domain        string                 `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
+	Port          int32                  `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	Command       string                 `protobuf:"bytes,6,opt,name=command,proto3" json:"command,omitempty"`
+	WorkingDir    string                 `protobuf:"bytes,7,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	User          string                 `protobuf:"bytes,8,opt,name=user,proto3" json:"user,omitempty"`
+	Ssl           bool                   `protobuf:"varint,9,opt,name=ssl,proto3" json:"ssl,omitempty"`
+	Environment   map[string]string      `protobuf:"bytes,10,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeployWebServiceRequest) Reset() {
+	*x = DeployWebServiceRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[124]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeployWebServiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeployWebServiceRequest) ProtoMessage() {}
+
+func (x *DeployWebServiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[124]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeployWebServiceRequest.ProtoReflect.Descriptor instead.
+func (*DeployWebServiceRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{124}
+}
+
+func (x *DeployWebServiceRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *DeployWebServiceRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *DeployWebServiceRequest) GetSsl() bool {
+	if x != nil {
+		return x.Ssl
+	}
+	return false
+}
+
+func (x *DeployWebServiceRequest) GetEnvironment() map[string]string {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+// Removes a deployment of any kind and everything its manifest records
+type RemoveWebServiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Force         bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"` // Remove files edited outside Mandau
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveWebServiceRequest) Reset() {
+	*x = RemoveWebServiceRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[125]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveWebServiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveWebServiceRequest) ProtoMessage() {}
+
+func (x *RemoveWebServiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[125]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveWebServiceRequest.ProtoReflect.Descriptor instead.
+func (*RemoveWebServiceRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{125}
+}
+
+func (x *RemoveWebServiceRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RemoveWebServiceRequest) GetName() string {
 	if x != nil {
-		return x.Status
+		return x.Name
 	}
 	return ""
 }
 
-func (x *EnableFirewallResponse) GetError() string {
+func (x *RemoveWebServiceRequest) GetForce() bool {
 	if x != nil {
-		return x.Error
+		return x.Force
 	}
-	return ""
+	return false
 }
 
-type DisableFirewallRequest struct {
+type ListDeployedServicesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisableFirewallRequest) Reset() {
-	*x = DisableFirewallRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[44]
+func (x *ListDeployedServicesRequest) Reset() {
+	*x = ListDeployedServicesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[126]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisableFirewallRequest) String() string {
+func (x *ListDeployedServicesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisableFirewallRequest) ProtoMessage() {}
+func (*ListDeployedServicesRequest) ProtoMessage() {}
 
-func (x *DisableFirewallRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[44]
+func (x *ListDeployedServicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[126]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2590,41 +7715,40 @@ func (x *DisableFirewallRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisableFirewallRequest.ProtoReflect.Descriptor instead.
-func (*DisableFirewallRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{44}
+// Deprecated: Use ListDeployedServicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeployedServicesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{126}
 }
 
-func (x *DisableFirewallRequest) GetAgentId() string {
+func (x *ListDeployedServicesRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-type DisableFirewallResponse struct {
+type ListDeployedServicesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Services      []*DeployedService     `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisableFirewallResponse) Reset() {
-	*x = DisableFirewallResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[45]
+func (x *ListDeployedServicesResponse) Reset() {
+	*x = ListDeployedServicesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[127]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisableFirewallResponse) String() string {
+func (x *ListDeployedServicesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisableFirewallResponse) ProtoMessage() {}
+func (*ListDeployedServicesResponse) ProtoMessage() {}
 
-func (x *DisableFirewallResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[45]
+func (x *ListDeployedServicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[127]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2635,50 +7759,46 @@ func (x *DisableFirewallResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisableFirewallResponse.ProtoReflect.Descriptor instead.
-func (*DisableFirewallResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{45}
-}
-
-func (x *DisableFirewallResponse) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+// Deprecated: Use ListDeployedServicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeployedServicesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{127}
 }
 
-func (x *DisableFirewallResponse) GetError() string {
+func (x *ListDeployedServicesResponse) GetServices() []*DeployedService {
 	if x != nil {
-		return x.Error
+		return x.Services
 	}
-	return ""
+	return nil
 }
 
-type ObtainCertificateRequest struct {
+// Manifest of a deployment: what it created on the host
+type DeployedService struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
-	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	Production    bool                   `protobuf:"varint,4,opt,name=production,proto3" json:"production,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // web service, static site, database or worker
+	Domain        string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	DeployedAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=deployed_at,json=deployedAt,proto3" json:"deployed_at,omitempty"`
+	Resources     []*DeployedResource    `protobuf:"bytes,5,rep,name=resources,proto3" json:"resources,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"` // DEPLOYING, DEPLOYED or FAILED (resources left behind)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ObtainCertificateRequest) Reset() {
-	*x = ObtainCertificateRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[46]
+func (x *DeployedService) Reset() {
+	*x = DeployedService{}
+	mi := &file_api_v1_service_proto_msgTypes[128]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ObtainCertificateRequest) String() string {
+func (x *DeployedService) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ObtainCertificateRequest) ProtoMessage() {}
+func (*DeployedService) ProtoMessage() {}
 
-func (x *ObtainCertificateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[46]
+func (x *DeployedService) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[128]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2689,62 +7809,76 @@ func (x *ObtainCertificateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ObtainCertificateRequest.ProtoReflect.Descriptor instead.
-func (*ObtainCertificateRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{46}
+// Deprecated: Use DeployedService.ProtoReflect.Descriptor instead.
+func (*DeployedService) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{128}
 }
 
-func (x *ObtainCertificateRequest) GetAgentId() string {
+func (x *DeployedService) GetName() string {
 	if x != nil {
-		return x.AgentId
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ObtainCertificateRequest) GetDomain() string {
+func (x *DeployedService) GetKind() string {
 	if x != nil {
-		return x.Domain
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *ObtainCertificateRequest) GetEmail() string {
+func (x *DeployedService) GetDomain() string {
 	if x != nil {
-		return x.Email
+		return x.Domain
 	}
 	return ""
 }
 
-func (x *ObtainCertificateRequest) GetProduction() bool {
+func (x *DeployedService) GetDeployedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Production
+		return x.DeployedAt
 	}
-	return false
+	return nil
 }
 
-type ObtainCertificateResponse struct {
+func (x *DeployedService) GetResources() []*DeployedResource {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *DeployedService) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type DeployedResource struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Certificate   *Certificate           `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // unit, vhost, port, certificate or cron
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ObtainCertificateResponse) Reset() {
-	*x = ObtainCertificateResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[47]
+func (x *DeployedResource) Reset() {
+	*x = DeployedResource{}
+	mi := &file_api_v1_service_proto_msgTypes[129]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ObtainCertificateResponse) String() string {
+func (x *DeployedResource) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ObtainCertificateResponse) ProtoMessage() {}
+func (*DeployedResource) ProtoMessage() {}
 
-func (x *ObtainCertificateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[47]
+func (x *DeployedResource) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[129]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2755,48 +7889,53 @@ func (x *ObtainCertificateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ObtainCertificateResponse.ProtoReflect.Descriptor instead.
-func (*ObtainCertificateResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{47}
+// Deprecated: Use DeployedResource.ProtoReflect.Descriptor instead.
+func (*DeployedResource) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{129}
 }
 
-func (x *ObtainCertificateResponse) GetCertificate() *Certificate {
+func (x *DeployedResource) GetKind() string {
 	if x != nil {
-		return x.Certificate
+		return x.Kind
 	}
-	return nil
+	return ""
 }
 
-func (x *ObtainCertificateResponse) GetError() string {
+func (x *DeployedResource) GetName() string {
 	if x != nil {
-		return x.Error
+		return x.Name
 	}
 	return ""
 }
 
-type RenewCertificateRequest struct {
+// Static site served by nginx from a directory on the host
+type DeployStaticSiteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Domain        string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	Root          string                 `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"` // Directory holding the site
+	Index         []string               `protobuf:"bytes,5,rep,name=index,proto3" json:"index,omitempty"`
+	Ssl           bool                   `protobuf:"varint,6,opt,name=ssl,proto3" json:"ssl,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RenewCertificateRequest) Reset() {
-	*x = RenewCertificateRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[48]
+func (x *DeployStaticSiteRequest) Reset() {
+	*x = DeployStaticSiteRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[130]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RenewCertificateRequest) String() string {
+func (x *DeployStaticSiteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RenewCertificateRequest) ProtoMessage() {}
+func (*DeployStaticSiteRequest) ProtoMessage() {}
 
-func (x *RenewCertificateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[48]
+func (x *DeployStaticSiteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[130]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2807,48 +7946,85 @@ func (x *RenewCertificateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RenewCertificateRequest.ProtoReflect.Descriptor instead.
-func (*RenewCertificateRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{48}
+// Deprecated: Use DeployStaticSiteRequest.ProtoReflect.Descriptor instead.
+func (*DeployStaticSiteRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{130}
 }
 
-func (x *RenewCertificateRequest) GetAgentId() string {
+func (x *DeployStaticSiteRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RenewCertificateRequest) GetDomain() string {
+func (x *DeployStaticSiteRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeployStaticSiteRequest) GetDomain() string {
 	if x != nil {
 		return x.Domain
 	}
 	return ""
 }
 
-type RenewCertificateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DeployStaticSiteRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
 }
 
-func (x *RenewCertificateResponse) Reset() {
-	*x = RenewCertificateResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[49]
+func (x *DeployStaticSiteRequest) GetIndex() []string {
+	if x != nil {
+		return x.Index
+	}
+	return nil
+}
+
+func (x *DeployStaticSiteRequest) GetSsl() bool {
+	if x != nil {
+		return x.Ssl
+	}
+	return false
+}
+
+// Database container run by systemd, with scheduled backups
+type DeployDatabaseRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AgentId             string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name                string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Engine              string                 `protobuf:"bytes,3,opt,name=engine,proto3" json:"engine,omitempty"`   // postgres, mysql or mariadb
+	Version             string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"` // Image tag; "latest" when empty
+	Port                int32                  `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`      // Host port, bound to 127.0.0.1
+	DataDir             string                 `protobuf:"bytes,6,opt,name=data_dir,json=dataDir,proto3" json:"data_dir,omitempty"`
+	Password            string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	BackupSchedule      string                 `protobuf:"bytes,8,opt,name=backup_schedule,json=backupSchedule,proto3" json:"backup_schedule,omitempty"` // Cron schedule; no backups when empty
+	BackupDir           string                 `protobuf:"bytes,9,opt,name=backup_dir,json=backupDir,proto3" json:"backup_dir,omitempty"`
+	BackupRetentionDays int32                  `protobuf:"varint,10,opt,name=backup_retention_days,json=backupRetentionDays,proto3" json:"backup_retention_days,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DeployDatabaseRequest) Reset() {
+	*x = DeployDatabaseRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[131]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RenewCertificateResponse) String() string {
+func (x *DeployDatabaseRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RenewCertificateResponse) ProtoMessage() {}
+func (*DeployDatabaseRequest) ProtoMessage() {}
 
-func (x *RenewCertificateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[49]
+func (x *DeployDatabaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[131]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2859,92 +8035,110 @@ func (x *RenewCertificateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RenewCertificateResponse.ProtoReflect.Descriptor instead.
-func (*RenewCertificateResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{49}
+// Deprecated: Use DeployDatabaseRequest.ProtoReflect.Descriptor instead.
+func (*DeployDatabaseRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{131}
 }
 
-func (x *RenewCertificateResponse) GetStatus() string {
+func (x *DeployDatabaseRequest) GetAgentId() string {
 	if x != nil {
-		return x.Status
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RenewCertificateResponse) GetError() string {
+func (x *DeployDatabaseRequest) GetName() string {
 	if x != nil {
-		return x.Error
+		return x.Name
 	}
 	return ""
 }
 
-type RenewAllCertificatesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DeployDatabaseRequest) GetEngine() string {
+	if x != nil {
+		return x.Engine
+	}
+	return ""
 }
 
-func (x *RenewAllCertificatesRequest) Reset() {
-	*x = RenewAllCertificatesRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[50]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *DeployDatabaseRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
 }
 
-func (x *RenewAllCertificatesRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DeployDatabaseRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
 }
 
-func (*RenewAllCertificatesRequest) ProtoMessage() {}
+func (x *DeployDatabaseRequest) GetDataDir() string {
+	if x != nil {
+		return x.DataDir
+	}
+	return ""
+}
 
-func (x *RenewAllCertificatesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[50]
+func (x *DeployDatabaseRequest) GetPassword() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Password
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use RenewAllCertificatesRequest.ProtoReflect.Descriptor instead.
-func (*RenewAllCertificatesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{50}
+func (x *DeployDatabaseRequest) GetBackupSchedule() string {
+	if x != nil {
+		return x.BackupSchedule
+	}
+	return ""
 }
 
-func (x *RenewAllCertificatesRequest) GetAgentId() string {
+func (x *DeployDatabaseRequest) GetBackupDir() string {
 	if x != nil {
-		return x.AgentId
+		return x.BackupDir
 	}
 	return ""
 }
 
-type RenewAllCertificatesResponse struct {
+func (x *DeployDatabaseRequest) GetBackupRetentionDays() int32 {
+	if x != nil {
+		return x.BackupRetentionDays
+	}
+	return 0
+}
+
+// Background worker run by systemd, without nginx or open ports
+type DeployWorkerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Command       string                 `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	WorkingDir    string                 `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	User          string                 `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	Environment   map[string]string      `protobuf:"bytes,7,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RenewAllCertificatesResponse) Reset() {
-	*x = RenewAllCertificatesResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[51]
+func (x *DeployWorkerRequest) Reset() {
+	*x = DeployWorkerRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[132]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RenewAllCertificatesResponse) String() string {
+func (x *DeployWorkerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RenewAllCertificatesResponse) ProtoMessage() {}
+func (*DeployWorkerRequest) ProtoMessage() {}
 
-func (x *RenewAllCertificatesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[51]
+func (x *DeployWorkerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[132]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2952,51 +8146,85 @@ func (x *RenewAllCertificatesResponse) ProtoReflect() protoreflect.Message {
 		}
 		return ms
 	}
-	return mi.MessageOf(x)
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeployWorkerRequest.ProtoReflect.Descriptor instead.
+func (*DeployWorkerRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{132}
+}
+
+func (x *DeployWorkerRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DeployWorkerRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeployWorkerRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DeployWorkerRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
 }
 
-// Deprecated: Use RenewAllCertificatesResponse.ProtoReflect.Descriptor instead.
-func (*RenewAllCertificatesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{51}
+func (x *DeployWorkerRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
 }
 
-func (x *RenewAllCertificatesResponse) GetStatus() string {
+func (x *DeployWorkerRequest) GetUser() string {
 	if x != nil {
-		return x.Status
+		return x.User
 	}
 	return ""
 }
 
-func (x *RenewAllCertificatesResponse) GetError() string {
+func (x *DeployWorkerRequest) GetEnvironment() map[string]string {
 	if x != nil {
-		return x.Error
+		return x.Environment
 	}
-	return ""
+	return nil
 }
 
-type RevokeCertificateRequest struct {
+type GetDriftReportRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RevokeCertificateRequest) Reset() {
-	*x = RevokeCertificateRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[52]
+func (x *GetDriftReportRequest) Reset() {
+	*x = GetDriftReportRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[133]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RevokeCertificateRequest) String() string {
+func (x *GetDriftReportRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RevokeCertificateRequest) ProtoMessage() {}
+func (*GetDriftReportRequest) ProtoMessage() {}
 
-func (x *RevokeCertificateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[52]
+func (x *GetDriftReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[133]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3007,48 +8235,41 @@ func (x *RevokeCertificateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RevokeCertificateRequest.ProtoReflect.Descriptor instead.
-func (*RevokeCertificateRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{52}
+// Deprecated: Use GetDriftReportRequest.ProtoReflect.Descriptor instead.
+func (*GetDriftReportRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{133}
 }
 
-func (x *RevokeCertificateRequest) GetAgentId() string {
+func (x *GetDriftReportRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RevokeCertificateRequest) GetDomain() string {
-	if x != nil {
-		return x.Domain
-	}
-	return ""
-}
-
-type RevokeCertificateResponse struct {
+type DriftReport struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	ScannedAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=scanned_at,json=scannedAt,proto3" json:"scanned_at,omitempty"`
+	Drift         []*HostDrift           `protobuf:"bytes,2,rep,name=drift,proto3" json:"drift,omitempty"` // Empty when everything matches
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RevokeCertificateResponse) Reset() {
-	*x = RevokeCertificateResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[53]
+func (x *DriftReport) Reset() {
+	*x = DriftReport{}
+	mi := &file_api_v1_service_proto_msgTypes[134]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RevokeCertificateResponse) String() string {
+func (x *DriftReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RevokeCertificateResponse) ProtoMessage() {}
+func (*DriftReport) ProtoMessage() {}
 
-func (x *RevokeCertificateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[53]
+func (x *DriftReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[134]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3059,47 +8280,50 @@ func (x *RevokeCertificateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RevokeCertificateResponse.ProtoReflect.Descriptor instead.
-func (*RevokeCertificateResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{53}
+// Deprecated: Use DriftReport.ProtoReflect.Descriptor instead.
+func (*DriftReport) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{134}
 }
 
-func (x *RevokeCertificateResponse) GetStatus() string {
+func (x *DriftReport) GetScannedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Status
+		return x.ScannedAt
 	}
-	return ""
+	return nil
 }
 
-func (x *RevokeCertificateResponse) GetError() string {
+func (x *DriftReport) GetDrift() []*HostDrift {
 	if x != nil {
-		return x.Error
+		return x.Drift
 	}
-	return ""
+	return nil
 }
 
-type ListCertificatesRequest struct {
+type HostDrift struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`   // vhost, unit, cron, zone or port
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`   // File path, or "<port>/<proto>" for firewall rules
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"` // modified or missing
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListCertificatesRequest) Reset() {
-	*x = ListCertificatesRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[54]
+func (x *HostDrift) Reset() {
+	*x = HostDrift{}
+	mi := &file_api_v1_service_proto_msgTypes[135]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListCertificatesRequest) String() string {
+func (x *HostDrift) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListCertificatesRequest) ProtoMessage() {}
+func (*HostDrift) ProtoMessage() {}
 
-func (x *ListCertificatesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[54]
+func (x *HostDrift) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[135]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3110,40 +8334,62 @@ func (x *ListCertificatesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListCertificatesRequest.ProtoReflect.Descriptor instead.
-func (*ListCertificatesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{54}
+// Deprecated: Use HostDrift.ProtoReflect.Descriptor instead.
+func (*HostDrift) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{135}
 }
 
-func (x *ListCertificatesRequest) GetAgentId() string {
+func (x *HostDrift) GetKind() string {
 	if x != nil {
-		return x.AgentId
+		return x.Kind
 	}
 	return ""
 }
 
-type ListCertificatesResponse struct {
+func (x *HostDrift) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostDrift) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *HostDrift) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ListListeningPortsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Certificates  []*Certificate         `protobuf:"bytes,1,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"` // Only this port when set
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListCertificatesResponse) Reset() {
-	*x = ListCertificatesResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[55]
+func (x *ListListeningPortsRequest) Reset() {
+	*x = ListListeningPortsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[136]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListCertificatesResponse) String() string {
+func (x *ListListeningPortsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListCertificatesResponse) ProtoMessage() {}
+func (*ListListeningPortsRequest) ProtoMessage() {}
 
-func (x *ListCertificatesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[55]
+func (x *ListListeningPortsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[136]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3154,45 +8400,54 @@ func (x *ListCertificatesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListCertificatesResponse.ProtoReflect.Descriptor instead.
-func (*ListCertificatesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{55}
+// Deprecated: Use ListListeningPortsRequest.ProtoReflect.Descriptor instead.
+func (*ListListeningPortsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{136}
 }
 
-func (x *ListCertificatesResponse) GetCertificates() []*Certificate {
+func (x *ListListeningPortsRequest) GetAgentId() string {
 	if x != nil {
-		return x.Certificates
+		return x.AgentId
 	}
-	return nil
+	return ""
 }
 
-type Certificate struct {
+func (x *ListListeningPortsRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+type ListeningPort struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
-	CertPath      string                 `protobuf:"bytes,2,opt,name=cert_path,json=certPath,proto3" json:"cert_path,omitempty"`
-	KeyPath       string                 `protobuf:"bytes,3,opt,name=key_path,json=keyPath,proto3" json:"key_path,omitempty"`
-	ExpiresAt     string                 `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
-	IssuedAt      string                 `protobuf:"bytes,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
-	Issuer        string                 `protobuf:"bytes,6,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Proto         string                 `protobuf:"bytes,1,opt,name=proto,proto3" json:"proto,omitempty"` // tcp, tcp6, udp or udp6
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"` // 0 when no process holding the socket is visible
+	Process       string                 `protobuf:"bytes,5,opt,name=process,proto3" json:"process,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,6,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Container     string                 `protobuf:"bytes,7,opt,name=container,proto3" json:"container,omitempty"`
+	Connections   int32                  `protobuf:"varint,8,opt,name=connections,proto3" json:"connections,omitempty"` // Established TCP connections to the port
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Certificate) Reset() {
-	*x = Certificate{}
-	mi := &file_api_v1_service_proto_msgTypes[56]
+func (x *ListeningPort) Reset() {
+	*x = ListeningPort{}
+	mi := &file_api_v1_service_proto_msgTypes[137]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Certificate) String() string {
+func (x *ListeningPort) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Certificate) ProtoMessage() {}
+func (*ListeningPort) ProtoMessage() {}
 
-func (x *Certificate) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[56]
+func (x *ListeningPort) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[137]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3203,75 +8458,89 @@ func (x *Certificate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
-func (*Certificate) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{56}
+// Deprecated: Use ListeningPort.ProtoReflect.Descriptor instead.
+func (*ListeningPort) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{137}
 }
 
-func (x *Certificate) GetDomain() string {
+func (x *ListeningPort) GetProto() string {
 	if x != nil {
-		return x.Domain
+		return x.Proto
 	}
 	return ""
 }
 
-func (x *Certificate) GetCertPath() string {
+func (x *ListeningPort) GetAddress() string {
 	if x != nil {
-		return x.CertPath
+		return x.Address
 	}
 	return ""
 }
 
-func (x *Certificate) GetKeyPath() string {
+func (x *ListeningPort) GetPort() int32 {
 	if x != nil {
-		return x.KeyPath
+		return x.Port
 	}
-	return ""
+	return 0
 }
 
-func (x *Certificate) GetExpiresAt() string {
+func (x *ListeningPort) GetPid() int32 {
 	if x != nil {
-		return x.ExpiresAt
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ListeningPort) GetProcess() string {
+	if x != nil {
+		return x.Process
 	}
 	return ""
 }
 
-func (x *Certificate) GetIssuedAt() string {
+func (x *ListeningPort) GetContainerId() string {
 	if x != nil {
-		return x.IssuedAt
+		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *Certificate) GetIssuer() string {
+func (x *ListeningPort) GetContainer() string {
 	if x != nil {
-		return x.Issuer
+		return x.Container
 	}
 	return ""
 }
 
-type GetHostInfoRequest struct {
+func (x *ListeningPort) GetConnections() int32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+type ListListeningPortsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Ports         []*ListeningPort       `protobuf:"bytes,1,rep,name=ports,proto3" json:"ports,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHostInfoRequest) Reset() {
-	*x = GetHostInfoRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[57]
+func (x *ListListeningPortsResponse) Reset() {
+	*x = ListListeningPortsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[138]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHostInfoRequest) String() string {
+func (x *ListListeningPortsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHostInfoRequest) ProtoMessage() {}
+func (*ListListeningPortsResponse) ProtoMessage() {}
 
-func (x *GetHostInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[57]
+func (x *ListListeningPortsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[138]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3282,47 +8551,44 @@ func (x *GetHostInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHostInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetHostInfoRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{57}
+// Deprecated: Use ListListeningPortsResponse.ProtoReflect.Descriptor instead.
+func (*ListListeningPortsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{138}
 }
 
-func (x *GetHostInfoRequest) GetAgentId() string {
+func (x *ListListeningPortsResponse) GetPorts() []*ListeningPort {
 	if x != nil {
-		return x.AgentId
+		return x.Ports
 	}
-	return ""
+	return nil
 }
 
-type GetHostInfoResponse struct {
+type ListProcessesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
-	Kernel        string                 `protobuf:"bytes,3,opt,name=kernel,proto3" json:"kernel,omitempty"`
-	Architecture  string                 `protobuf:"bytes,4,opt,name=architecture,proto3" json:"architecture,omitempty"`
-	CpuCores      int32                  `protobuf:"varint,5,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
-	MemoryMb      int64                  `protobuf:"varint,6,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
-	DiskGb        int64                  `protobuf:"varint,7,opt,name=disk_gb,json=diskGb,proto3" json:"disk_gb,omitempty"`
-	Uptime        string                 `protobuf:"bytes,8,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`    // Only processes whose name or command contains this
+	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`   // Only processes listening on this port
+	Sort          string                 `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`    // cpu, mem, pid (default) or name
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // At most this many when set
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHostInfoResponse) Reset() {
-	*x = GetHostInfoResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[58]
+func (x *ListProcessesRequest) Reset() {
+	*x = ListProcessesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[139]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHostInfoResponse) String() string {
+func (x *ListProcessesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHostInfoResponse) ProtoMessage() {}
+func (*ListProcessesRequest) ProtoMessage() {}
 
-func (x *GetHostInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[58]
+func (x *ListProcessesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[139]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3333,142 +8599,192 @@ func (x *GetHostInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHostInfoResponse.ProtoReflect.Descriptor instead.
-func (*GetHostInfoResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{58}
+// Deprecated: Use ListProcessesRequest.ProtoReflect.Descriptor instead.
+func (*ListProcessesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{139}
 }
 
-func (x *GetHostInfoResponse) GetHostname() string {
+func (x *ListProcessesRequest) GetAgentId() string {
 	if x != nil {
-		return x.Hostname
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *GetHostInfoResponse) GetOs() string {
+func (x *ListProcessesRequest) GetName() string {
 	if x != nil {
-		return x.Os
+		return x.Name
 	}
 	return ""
 }
 
-func (x *GetHostInfoResponse) GetKernel() string {
+func (x *ListProcessesRequest) GetPort() int32 {
 	if x != nil {
-		return x.Kernel
+		return x.Port
 	}
-	return ""
+	return 0
 }
 
-func (x *GetHostInfoResponse) GetArchitecture() string {
+func (x *ListProcessesRequest) GetSort() string {
 	if x != nil {
-		return x.Architecture
+		return x.Sort
 	}
 	return ""
 }
 
-func (x *GetHostInfoResponse) GetCpuCores() int32 {
+func (x *ListProcessesRequest) GetLimit() int32 {
 	if x != nil {
-		return x.CpuCores
+		return x.Limit
 	}
 	return 0
 }
 
-func (x *GetHostInfoResponse) GetMemoryMb() int64 {
+type HostProcess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Ppid          int32                  `protobuf:"varint,2,opt,name=ppid,proto3" json:"ppid,omitempty"`
+	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Command       string                 `protobuf:"bytes,5,opt,name=command,proto3" json:"command,omitempty"`                             // Empty for kernel threads
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`                                 // R, S, D, Z, T...
+	CpuPercent    float64                `protobuf:"fixed64,7,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`   // Of one CPU, sampled over a second
+	MemoryBytes   uint64                 `protobuf:"varint,8,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"` // Resident
+	MemoryPercent float64                `protobuf:"fixed64,9,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	ContainerId   string                 `protobuf:"bytes,10,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Container     string                 `protobuf:"bytes,11,opt,name=container,proto3" json:"container,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostProcess) Reset() {
+	*x = HostProcess{}
+	mi := &file_api_v1_service_proto_msgTypes[140]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostProcess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostProcess) ProtoMessage() {}
+
+func (x *HostProcess) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[140]
 	if x != nil {
-		return x.MemoryMb
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostProcess.ProtoReflect.Descriptor instead.
+func (*HostProcess) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{140}
+}
+
+func (x *HostProcess) GetPid() int32 {
+	if x != nil {
+		return x.Pid
 	}
 	return 0
 }
 
-func (x *GetHostInfoResponse) GetDiskGb() int64 {
+func (x *HostProcess) GetPpid() int32 {
 	if x != nil {
-		return x.DiskGb
+		return x.Ppid
 	}
 	return 0
 }
 
-func (x *GetHostInfoResponse) GetUptime() string {
+func (x *HostProcess) GetUser() string {
 	if x != nil {
-		return x.Uptime
+		return x.User
 	}
 	return ""
 }
 
-type InstallPackageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	PackageName   string                 `protobuf:"bytes,2,opt,name=package_name,json=packageName,proto3" json:"package_name,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *HostProcess) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *InstallPackageRequest) Reset() {
-	*x = InstallPackageRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[59]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *HostProcess) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
 }
 
-func (x *InstallPackageRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *HostProcess) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
 }
 
-func (*InstallPackageRequest) ProtoMessage() {}
+func (x *HostProcess) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
 
-func (x *InstallPackageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[59]
+func (x *HostProcess) GetMemoryBytes() uint64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.MemoryBytes
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use InstallPackageRequest.ProtoReflect.Descriptor instead.
-func (*InstallPackageRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{59}
+func (x *HostProcess) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
 }
 
-func (x *InstallPackageRequest) GetAgentId() string {
+func (x *HostProcess) GetContainerId() string {
 	if x != nil {
-		return x.AgentId
+		return x.ContainerId
 	}
 	return ""
 }
 
-func (x *InstallPackageRequest) GetPackageName() string {
+func (x *HostProcess) GetContainer() string {
 	if x != nil {
-		return x.PackageName
+		return x.Container
 	}
 	return ""
 }
 
-type InstallPackageResponse struct {
+type ListProcessesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Processes     []*HostProcess         `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InstallPackageResponse) Reset() {
-	*x = InstallPackageResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[60]
+func (x *ListProcessesResponse) Reset() {
+	*x = ListProcessesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[141]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InstallPackageResponse) String() string {
+func (x *ListProcessesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InstallPackageResponse) ProtoMessage() {}
+func (*ListProcessesResponse) ProtoMessage() {}
 
-func (x *InstallPackageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[60]
+func (x *ListProcessesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[141]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3479,48 +8795,42 @@ func (x *InstallPackageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InstallPackageResponse.ProtoReflect.Descriptor instead.
-func (*InstallPackageResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{60}
-}
-
-func (x *InstallPackageResponse) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+// Deprecated: Use ListProcessesResponse.ProtoReflect.Descriptor instead.
+func (*ListProcessesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{141}
 }
 
-func (x *InstallPackageResponse) GetError() string {
+func (x *ListProcessesResponse) GetProcesses() []*HostProcess {
 	if x != nil {
-		return x.Error
+		return x.Processes
 	}
-	return ""
+	return nil
 }
 
-type RemovePackageRequest struct {
+type SignalProcessRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	PackageName   string                 `protobuf:"bytes,2,opt,name=package_name,json=packageName,proto3" json:"package_name,omitempty"`
+	Pid           int32                  `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`
+	Signal        string                 `protobuf:"bytes,3,opt,name=signal,proto3" json:"signal,omitempty"` // HUP, INT, QUIT, KILL, USR1, USR2, TERM (default), CONT or STOP
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RemovePackageRequest) Reset() {
-	*x = RemovePackageRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[61]
+func (x *SignalProcessRequest) Reset() {
+	*x = SignalProcessRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[142]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RemovePackageRequest) String() string {
+func (x *SignalProcessRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemovePackageRequest) ProtoMessage() {}
+func (*SignalProcessRequest) ProtoMessage() {}
 
-func (x *RemovePackageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[61]
+func (x *SignalProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[142]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3531,48 +8841,55 @@ func (x *RemovePackageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemovePackageRequest.ProtoReflect.Descriptor instead.
-func (*RemovePackageRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{61}
+// Deprecated: Use SignalProcessRequest.ProtoReflect.Descriptor instead.
+func (*SignalProcessRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{142}
 }
 
-func (x *RemovePackageRequest) GetAgentId() string {
+func (x *SignalProcessRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RemovePackageRequest) GetPackageName() string {
+func (x *SignalProcessRequest) GetPid() int32 {
 	if x != nil {
-		return x.PackageName
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *SignalProcessRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
 	}
 	return ""
 }
 
-type RemovePackageResponse struct {
+type SignalProcessResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // Of the process signaled
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RemovePackageResponse) Reset() {
-	*x = RemovePackageResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[62]
+func (x *SignalProcessResponse) Reset() {
+	*x = SignalProcessResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[143]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RemovePackageResponse) String() string {
+func (x *SignalProcessResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemovePackageResponse) ProtoMessage() {}
+func (*SignalProcessResponse) ProtoMessage() {}
 
-func (x *RemovePackageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[62]
+func (x *SignalProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[143]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3583,47 +8900,47 @@ func (x *RemovePackageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemovePackageResponse.ProtoReflect.Descriptor instead.
-func (*RemovePackageResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{62}
+// Deprecated: Use SignalProcessResponse.ProtoReflect.Descriptor instead.
+func (*SignalProcessResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{143}
 }
 
-func (x *RemovePackageResponse) GetStatus() string {
+func (x *SignalProcessResponse) GetStatus() string {
 	if x != nil {
 		return x.Status
 	}
 	return ""
 }
 
-func (x *RemovePackageResponse) GetError() string {
+func (x *SignalProcessResponse) GetName() string {
 	if x != nil {
-		return x.Error
+		return x.Name
 	}
 	return ""
 }
 
-type UpdatePackagesRequest struct {
+type ListLogFilesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdatePackagesRequest) Reset() {
-	*x = UpdatePackagesRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[63]
+func (x *ListLogFilesRequest) Reset() {
+	*x = ListLogFilesRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[144]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePackagesRequest) String() string {
+func (x *ListLogFilesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePackagesRequest) ProtoMessage() {}
+func (*ListLogFilesRequest) ProtoMessage() {}
 
-func (x *UpdatePackagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[63]
+func (x *ListLogFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[144]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3634,41 +8951,42 @@ func (x *UpdatePackagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePackagesRequest.ProtoReflect.Descriptor instead.
-func (*UpdatePackagesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{63}
+// Deprecated: Use ListLogFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListLogFilesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{144}
 }
 
-func (x *UpdatePackagesRequest) GetAgentId() string {
+func (x *ListLogFilesRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-type UpdatePackagesResponse struct {
+type LogFile struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Modified      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=modified,proto3" json:"modified,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdatePackagesResponse) Reset() {
-	*x = UpdatePackagesResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[64]
+func (x *LogFile) Reset() {
+	*x = LogFile{}
+	mi := &file_api_v1_service_proto_msgTypes[145]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePackagesResponse) String() string {
+func (x *LogFile) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePackagesResponse) ProtoMessage() {}
+func (*LogFile) ProtoMessage() {}
 
-func (x *UpdatePackagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[64]
+func (x *LogFile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[145]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3679,47 +8997,54 @@ func (x *UpdatePackagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePackagesResponse.ProtoReflect.Descriptor instead.
-func (*UpdatePackagesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{64}
+// Deprecated: Use LogFile.ProtoReflect.Descriptor instead.
+func (*LogFile) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{145}
 }
 
-func (x *UpdatePackagesResponse) GetStatus() string {
+func (x *LogFile) GetPath() string {
 	if x != nil {
-		return x.Status
+		return x.Path
 	}
 	return ""
 }
 
-func (x *UpdatePackagesResponse) GetError() string {
+func (x *LogFile) GetSize() int64 {
 	if x != nil {
-		return x.Error
+		return x.Size
 	}
-	return ""
+	return 0
 }
 
-type ListPackagesRequest struct {
+func (x *LogFile) GetModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Modified
+	}
+	return nil
+}
+
+type ListLogFilesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Files         []*LogFile             `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPackagesRequest) Reset() {
-	*x = ListPackagesRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[65]
+func (x *ListLogFilesResponse) Reset() {
+	*x = ListLogFilesResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[146]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPackagesRequest) String() string {
+func (x *ListLogFilesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPackagesRequest) ProtoMessage() {}
+func (*ListLogFilesResponse) ProtoMessage() {}
 
-func (x *ListPackagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[65]
+func (x *ListLogFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[146]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3730,40 +9055,48 @@ func (x *ListPackagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPackagesRequest.ProtoReflect.Descriptor instead.
-func (*ListPackagesRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{65}
+// Deprecated: Use ListLogFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListLogFilesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{146}
 }
 
-func (x *ListPackagesRequest) GetAgentId() string {
+func (x *ListLogFilesResponse) GetFiles() []*LogFile {
 	if x != nil {
-		return x.AgentId
+		return x.Files
 	}
-	return ""
+	return nil
 }
 
-type ListPackagesResponse struct {
+// Entries carry the file path as service_name. Their timestamp is the one
+// the line carries, or the line before it for continuations such as stack
+// traces; else the time it was read.
+type TailLogFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Packages      []string               `protobuf:"bytes,1,rep,name=packages,proto3" json:"packages,omitempty"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Lines         int32                  `protobuf:"varint,3,opt,name=lines,proto3" json:"lines,omitempty"` // Last lines to start from; 0 is 100, -1 the whole file
+	Follow        bool                   `protobuf:"varint,4,opt,name=follow,proto3" json:"follow,omitempty"`
+	Grep          string                 `protobuf:"bytes,5,opt,name=grep,proto3" json:"grep,omitempty"`   // Regular expression lines must match
+	Since         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=since,proto3" json:"since,omitempty"` // Only lines stamped at or after, from the start of the file
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPackagesResponse) Reset() {
-	*x = ListPackagesResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[66]
+func (x *TailLogFileRequest) Reset() {
+	*x = TailLogFileRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[147]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPackagesResponse) String() string {
+func (x *TailLogFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPackagesResponse) ProtoMessage() {}
+func (*TailLogFileRequest) ProtoMessage() {}
 
-func (x *ListPackagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[66]
+func (x *TailLogFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[147]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3774,101 +9107,78 @@ func (x *ListPackagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPackagesResponse.ProtoReflect.Descriptor instead.
-func (*ListPackagesResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{66}
+// Deprecated: Use TailLogFileRequest.ProtoReflect.Descriptor instead.
+func (*TailLogFileRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{147}
 }
 
-func (x *ListPackagesResponse) GetPackages() []string {
+func (x *TailLogFileRequest) GetAgentId() string {
 	if x != nil {
-		return x.Packages
+		return x.AgentId
 	}
-	return nil
-}
-
-type SetSysctlRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *SetSysctlRequest) Reset() {
-	*x = SetSysctlRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[67]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *SetSysctlRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+	return ""
 }
 
-func (*SetSysctlRequest) ProtoMessage() {}
-
-func (x *SetSysctlRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[67]
+func (x *TailLogFileRequest) GetPath() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Path
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use SetSysctlRequest.ProtoReflect.Descriptor instead.
-func (*SetSysctlRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{67}
+func (x *TailLogFileRequest) GetLines() int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
 }
 
-func (x *SetSysctlRequest) GetAgentId() string {
+func (x *TailLogFileRequest) GetFollow() bool {
 	if x != nil {
-		return x.AgentId
+		return x.Follow
 	}
-	return ""
+	return false
 }
 
-func (x *SetSysctlRequest) GetKey() string {
+func (x *TailLogFileRequest) GetGrep() string {
 	if x != nil {
-		return x.Key
+		return x.Grep
 	}
 	return ""
 }
 
-func (x *SetSysctlRequest) GetValue() string {
+func (x *TailLogFileRequest) GetSince() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Value
+		return x.Since
 	}
-	return ""
+	return nil
 }
 
-type SetSysctlResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+type RebootHostRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Stop the running stacks first and start them again after the reboot
+	Drain         bool   `protobuf:"varint,2,opt,name=drain,proto3" json:"drain,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // Recorded on the operation and in the system log
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SetSysctlResponse) Reset() {
-	*x = SetSysctlResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[68]
+func (x *RebootHostRequest) Reset() {
+	*x = RebootHostRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[148]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SetSysctlResponse) String() string {
+func (x *RebootHostRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetSysctlResponse) ProtoMessage() {}
+func (*RebootHostRequest) ProtoMessage() {}
 
-func (x *SetSysctlResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[68]
+func (x *RebootHostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[148]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3879,48 +9189,54 @@ func (x *SetSysctlResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetSysctlResponse.ProtoReflect.Descriptor instead.
-func (*SetSysctlResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{68}
+// Deprecated: Use RebootHostRequest.ProtoReflect.Descriptor instead.
+func (*RebootHostRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{148}
 }
 
-func (x *SetSysctlResponse) GetStatus() string {
+func (x *RebootHostRequest) GetAgentId() string {
 	if x != nil {
-		return x.Status
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *SetSysctlResponse) GetError() string {
+func (x *RebootHostRequest) GetDrain() bool {
 	if x != nil {
-		return x.Error
+		return x.Drain
+	}
+	return false
+}
+
+func (x *RebootHostRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
-type GetSysctlRequest struct {
+type GetLogShippingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetSysctlRequest) Reset() {
-	*x = GetSysctlRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[69]
+func (x *GetLogShippingRequest) Reset() {
+	*x = GetLogShippingRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[149]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetSysctlRequest) String() string {
+func (x *GetLogShippingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSysctlRequest) ProtoMessage() {}
+func (*GetLogShippingRequest) ProtoMessage() {}
 
-func (x *GetSysctlRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[69]
+func (x *GetLogShippingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[149]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3931,48 +9247,47 @@ func (x *GetSysctlRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSysctlRequest.ProtoReflect.Descriptor instead.
-func (*GetSysctlRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{69}
-}
-
-func (x *GetSysctlRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
+// Deprecated: Use GetLogShippingRequest.ProtoReflect.Descriptor instead.
+func (*GetLogShippingRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{149}
 }
 
-func (x *GetSysctlRequest) GetKey() string {
+func (x *GetLogShippingRequest) GetAgentId() string {
 	if x != nil {
-		return x.Key
+		return x.AgentId
 	}
 	return ""
 }
 
-type GetSysctlResponse struct {
+type LogShipping struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Sink          string                 `protobuf:"bytes,1,opt,name=sink,proto3" json:"sink,omitempty"`        // loki, elasticsearch or s3
+	Shipped       int64                  `protobuf:"varint,2,opt,name=shipped,proto3" json:"shipped,omitempty"` // Entries stored since the agent started
+	Dropped       int64                  `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"` // Lost because the buffer was full
+	Pending       int32                  `protobuf:"varint,4,opt,name=pending,proto3" json:"pending,omitempty"` // Read but not yet stored
+	LastShipped   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_shipped,json=lastShipped,proto3" json:"last_shipped,omitempty"`
+	LastError     string                 `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"` // Of the last failed batch, cleared by a stored one
+	LastErrorAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_error_at,json=lastErrorAt,proto3" json:"last_error_at,omitempty"`
+	Streams       []*ShippedStream       `protobuf:"bytes,8,rep,name=streams,proto3" json:"streams,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetSysctlResponse) Reset() {
-	*x = GetSysctlResponse{}
-	mi := &file_api_v1_service_proto_msgTypes[70]
+func (x *LogShipping) Reset() {
+	*x = LogShipping{}
+	mi := &file_api_v1_service_proto_msgTypes[150]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetSysctlResponse) String() string {
+func (x *LogShipping) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSysctlResponse) ProtoMessage() {}
+func (*LogShipping) ProtoMessage() {}
 
-func (x *GetSysctlResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[70]
+func (x *LogShipping) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[150]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3983,53 +9298,93 @@ func (x *GetSysctlResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSysctlResponse.ProtoReflect.Descriptor instead.
-func (*GetSysctlResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{70}
+// Deprecated: Use LogShipping.ProtoReflect.Descriptor instead.
+func (*LogShipping) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{150}
 }
 
-func (x *GetSysctlResponse) GetValue() string {
+func (x *LogShipping) GetSink() string {
 	if x != nil {
-		return x.Value
+		return x.Sink
 	}
 	return ""
 }
 
-func (x *GetSysctlResponse) GetError() string {
+func (x *LogShipping) GetShipped() int64 {
 	if x != nil {
-		return x.Error
+		return x.Shipped
+	}
+	return 0
+}
+
+func (x *LogShipping) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *LogShipping) GetPending() int32 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *LogShipping) GetLastShipped() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastShipped
+	}
+	return nil
+}
+
+func (x *LogShipping) GetLastError() string {
+	if x != nil {
+		return x.LastError
 	}
 	return ""
 }
 
-// ServiceOperationEvent - used for streaming service deployment operations
-type ServiceOperationEvent struct {
+func (x *LogShipping) GetLastErrorAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastErrorAt
+	}
+	return nil
+}
+
+func (x *LogShipping) GetStreams() []*ShippedStream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+// A container or the journal whose logs are followed
+type ShippedStream struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
-	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // Container ID, or journal
+	Stack         string                 `protobuf:"bytes,2,opt,name=stack,proto3" json:"stack,omitempty"`
+	Service       string                 `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	Position      string                 `protobuf:"bytes,4,opt,name=position,proto3" json:"position,omitempty"` // Where reading resumes after a restart
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceOperationEvent) Reset() {
-	*x = ServiceOperationEvent{}
-	mi := &file_api_v1_service_proto_msgTypes[71]
+func (x *ShippedStream) Reset() {
+	*x = ShippedStream{}
+	mi := &file_api_v1_service_proto_msgTypes[151]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceOperationEvent) String() string {
+func (x *ShippedStream) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceOperationEvent) ProtoMessage() {}
+func (*ShippedStream) ProtoMessage() {}
 
-func (x *ServiceOperationEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[71]
+func (x *ShippedStream) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[151]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4040,84 +9395,107 @@ func (x *ServiceOperationEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceOperationEvent.ProtoReflect.Descriptor instead.
-func (*ServiceOperationEvent) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{71}
+// Deprecated: Use ShippedStream.ProtoReflect.Descriptor instead.
+func (*ShippedStream) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{151}
 }
 
-func (x *ServiceOperationEvent) GetOperationId() string {
+func (x *ShippedStream) GetSource() string {
 	if x != nil {
-		return x.OperationId
+		return x.Source
 	}
 	return ""
 }
 
-func (x *ServiceOperationEvent) GetState() string {
+func (x *ShippedStream) GetStack() string {
 	if x != nil {
-		return x.State
+		return x.Stack
 	}
 	return ""
 }
 
-func (x *ServiceOperationEvent) GetTimestamp() *timestamppb.Timestamp {
+func (x *ShippedStream) GetService() string {
 	if x != nil {
-		return x.Timestamp
+		return x.Service
 	}
-	return nil
+	return ""
 }
 
-func (x *ServiceOperationEvent) GetMessage() string {
+func (x *ShippedStream) GetPosition() string {
 	if x != nil {
-		return x.Message
+		return x.Position
 	}
 	return ""
 }
 
-func (x *ServiceOperationEvent) GetProgress() int32 {
+type ListCommandsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommandsRequest) Reset() {
+	*x = ListCommandsRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[152]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommandsRequest) ProtoMessage() {}
+
+func (x *ListCommandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[152]
 	if x != nil {
-		return x.Progress
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ServiceOperationEvent) GetError() string {
+// Deprecated: Use ListCommandsRequest.ProtoReflect.Descriptor instead.
+func (*ListCommandsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{152}
+}
+
+func (x *ListCommandsRequest) GetAgentId() string {
 	if x != nil {
-		return x.Error
+		return x.AgentId
 	}
 	return ""
 }
 
-type DeployWebServiceRequest struct {
+type AllowedCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Domain        string                 `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
-	Port          int32                  `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
-	Command       string                 `protobuf:"bytes,6,opt,name=command,proto3" json:"command,omitempty"`
-	WorkingDir    string                 `protobuf:"bytes,7,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
-	User          string                 `protobuf:"bytes,8,opt,name=user,proto3" json:"user,omitempty"`
-	Ssl           bool                   `protobuf:"varint,9,opt,name=ssl,proto3" json:"ssl,omitempty"`
-	Environment   map[string]string      `protobuf:"bytes,10,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeployWebServiceRequest) Reset() {
-	*x = DeployWebServiceRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[72]
+func (x *AllowedCommand) Reset() {
+	*x = AllowedCommand{}
+	mi := &file_api_v1_service_proto_msgTypes[153]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeployWebServiceRequest) String() string {
+func (x *AllowedCommand) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeployWebServiceRequest) ProtoMessage() {}
+func (*AllowedCommand) ProtoMessage() {}
 
-func (x *DeployWebServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[72]
+func (x *AllowedCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[153]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4128,104 +9506,101 @@ func (x *DeployWebServiceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeployWebServiceRequest.ProtoReflect.Descriptor instead.
-func (*DeployWebServiceRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{72}
-}
-
-func (x *DeployWebServiceRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
+// Deprecated: Use AllowedCommand.ProtoReflect.Descriptor instead.
+func (*AllowedCommand) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{153}
 }
 
-func (x *DeployWebServiceRequest) GetName() string {
+func (x *AllowedCommand) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *DeployWebServiceRequest) GetDescription() string {
+func (x *AllowedCommand) GetDescription() string {
 	if x != nil {
 		return x.Description
 	}
 	return ""
 }
 
-func (x *DeployWebServiceRequest) GetDomain() string {
+func (x *AllowedCommand) GetTimeout() *durationpb.Duration {
 	if x != nil {
-		return x.Domain
+		return x.Timeout
 	}
-	return ""
+	return nil
 }
 
-func (x *DeployWebServiceRequest) GetPort() int32 {
-	if x != nil {
-		return x.Port
-	}
-	return 0
+type ListCommandsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*AllowedCommand      `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeployWebServiceRequest) GetCommand() string {
-	if x != nil {
-		return x.Command
-	}
-	return ""
+func (x *ListCommandsResponse) Reset() {
+	*x = ListCommandsResponse{}
+	mi := &file_api_v1_service_proto_msgTypes[154]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeployWebServiceRequest) GetWorkingDir() string {
-	if x != nil {
-		return x.WorkingDir
-	}
-	return ""
+func (x *ListCommandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *DeployWebServiceRequest) GetUser() string {
+func (*ListCommandsResponse) ProtoMessage() {}
+
+func (x *ListCommandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[154]
 	if x != nil {
-		return x.User
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *DeployWebServiceRequest) GetSsl() bool {
-	if x != nil {
-		return x.Ssl
-	}
-	return false
+// Deprecated: Use ListCommandsResponse.ProtoReflect.Descriptor instead.
+func (*ListCommandsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{154}
 }
 
-func (x *DeployWebServiceRequest) GetEnvironment() map[string]string {
+func (x *ListCommandsResponse) GetCommands() []*AllowedCommand {
 	if x != nil {
-		return x.Environment
+		return x.Commands
 	}
 	return nil
 }
 
-type RemoveWebServiceRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+type RunCommandRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Cuts the command's own timeout short when shorter
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RemoveWebServiceRequest) Reset() {
-	*x = RemoveWebServiceRequest{}
-	mi := &file_api_v1_service_proto_msgTypes[73]
+func (x *RunCommandRequest) Reset() {
+	*x = RunCommandRequest{}
+	mi := &file_api_v1_service_proto_msgTypes[155]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RemoveWebServiceRequest) String() string {
+func (x *RunCommandRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveWebServiceRequest) ProtoMessage() {}
+func (*RunCommandRequest) ProtoMessage() {}
 
-func (x *RemoveWebServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_service_proto_msgTypes[73]
+func (x *RunCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_service_proto_msgTypes[155]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4236,30 +9611,37 @@ func (x *RemoveWebServiceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveWebServiceRequest.ProtoReflect.Descriptor instead.
-func (*RemoveWebServiceRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_service_proto_rawDescGZIP(), []int{73}
+// Deprecated: Use RunCommandRequest.ProtoReflect.Descriptor instead.
+func (*RunCommandRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_service_proto_rawDescGZIP(), []int{155}
 }
 
-func (x *RemoveWebServiceRequest) GetAgentId() string {
+func (x *RunCommandRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RemoveWebServiceRequest) GetName() string {
+func (x *RunCommandRequest) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
+func (x *RunCommandRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
 var File_api_v1_service_proto protoreflect.FileDescriptor
 
 const file_api_v1_service_proto_rawDesc = "" +
 	"\n" +
-	"\x14api/v1/service.proto\x12\x12mandau.services.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa4\x02\n" +
+	"\x14api/v1/service.proto\x12\x12mandau.services.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x12api/v1/agent.proto\"\xed\x02\n" +
 	"\x18CreateVirtualHostRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vserver_name\x18\x02 \x01(\tR\n" +
@@ -4270,10 +9652,15 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\tlocations\x18\x06 \x03(\v2\x1c.mandau.services.v1.LocationR\tlocations\x12/\n" +
 	"\x03ssl\x18\a \x01(\v2\x1d.mandau.services.v1.SSLConfigR\x03ssl\x12\x1d\n" +
 	"\n" +
-	"proxy_pass\x18\b \x01(\tR\tproxyPass\"I\n" +
+	"proxy_pass\x18\b \x01(\tR\tproxyPass\x12\x17\n" +
+	"\adry_run\x18\t \x01(\bR\x06dryRun\x12\x18\n" +
+	"\asnippet\x18\n" +
+	" \x01(\tR\asnippet\x12\x14\n" +
+	"\x05force\x18\v \x01(\bR\x05force\"]\n" +
 	"\x19CreateVirtualHostResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"V\n" +
 	"\x18EnableVirtualHostRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vserver_name\x18\x02 \x01(\tR\n" +
@@ -4287,18 +9674,22 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"serverName\"J\n" +
 	"\x1aDisableVirtualHostResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x85\x01\n" +
 	"\x18DeleteVirtualHostRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vserver_name\x18\x02 \x01(\tR\n" +
-	"serverName\"I\n" +
+	"serverName\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"]\n" +
 	"\x19DeleteVirtualHostResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"4\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"4\n" +
 	"\x17ListVirtualHostsRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\"2\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"L\n" +
 	"\x18ListVirtualHostsResponse\x12\x16\n" +
-	"\x06vhosts\x18\x01 \x03(\tR\x06vhosts\"\xef\x01\n" +
+	"\x06vhosts\x18\x01 \x03(\tR\x06vhosts\x12\x18\n" +
+	"\aenabled\x18\x02 \x03(\tR\aenabled\"\xef\x01\n" +
 	"\bLocation\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
 	"\n" +
@@ -4313,23 +9704,30 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\vcertificate\x18\x01 \x01(\tR\vcertificate\x12'\n" +
 	"\x0fcertificate_key\x18\x02 \x01(\tR\x0ecertificateKey\x12\x1c\n" +
 	"\tprotocols\x18\x03 \x03(\tR\tprotocols\x12\x18\n" +
-	"\aciphers\x18\x04 \x01(\tR\aciphers\"~\n" +
+	"\aciphers\x18\x04 \x01(\tR\aciphers\"\xc7\x01\n" +
 	"\x19CreateReverseProxyRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
 	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x1a\n" +
 	"\bupstream\x18\x03 \x01(\tR\bupstream\x12\x12\n" +
-	"\x04port\x18\x04 \x01(\x05R\x04port\"J\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\x12\x18\n" +
+	"\asnippet\x18\x06 \x01(\tR\asnippet\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\"^\n" +
 	"\x1aCreateReverseProxyResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x84\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\xb3\x01\n" +
 	"\x19CreateLoadBalancerRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
 	"\bbackends\x18\x03 \x03(\tR\bbackends\x12\x1c\n" +
-	"\talgorithm\x18\x04 \x01(\tR\talgorithm\"J\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05force\"^\n" +
 	"\x1aCreateLoadBalancerResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\xfe\x04\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\xbe\x06\n" +
 	"\x14CreateServiceRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
@@ -4352,13 +9750,20 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\fmemory_limit\x18\x0f \x01(\tR\vmemoryLimit\x12\x1f\n" +
 	"\vprivate_tmp\x18\x10 \x01(\bR\n" +
 	"privateTmp\x12%\n" +
-	"\x0eprotect_system\x18\x11 \x01(\tR\rprotectSystem\x1a>\n" +
+	"\x0eprotect_system\x18\x11 \x01(\tR\rprotectSystem\x12\x17\n" +
+	"\adry_run\x18\x12 \x01(\bR\x06dryRun\x12!\n" +
+	"\funit_snippet\x18\x13 \x01(\tR\vunitSnippet\x12'\n" +
+	"\x0fservice_snippet\x18\x14 \x01(\tR\x0eserviceSnippet\x12'\n" +
+	"\x0finstall_snippet\x18\x15 \x01(\tR\x0einstallSnippet\x12\x14\n" +
+	"\x05force\x18\x16 \x01(\bR\x05force\x12\x1a\n" +
+	"\btemplate\x18\x17 \x01(\bR\btemplate\x1a>\n" +
 	"\x10EnvironmentEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"E\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Y\n" +
 	"\x15CreateServiceResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"E\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"E\n" +
 	"\x14EnableServiceRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\"E\n" +
@@ -4398,7 +9803,41 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x13ListServicesRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"2\n" +
 	"\x14ListServicesResponse\x12\x1a\n" +
-	"\bservices\x18\x01 \x03(\tR\bservices\"\xdf\x01\n" +
+	"\bservices\x18\x01 \x03(\tR\bservices\"M\n" +
+	"\x14ListInstancesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\"\x89\x02\n" +
+	"\x0fServiceInstance\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\x12V\n" +
+	"\venvironment\x18\x05 \x03(\v24.mandau.services.v1.ServiceInstance.EnvironmentEntryR\venvironment\x1a>\n" +
+	"\x10EnvironmentEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Z\n" +
+	"\x15ListInstancesResponse\x12A\n" +
+	"\tinstances\x18\x01 \x03(\v2#.mandau.services.v1.ServiceInstanceR\tinstances\"\xc8\x02\n" +
+	"\x1dSetInstanceEnvironmentRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\x12\x1a\n" +
+	"\binstance\x18\x03 \x01(\tR\binstance\x12d\n" +
+	"\venvironment\x18\x04 \x03(\v2B.mandau.services.v1.SetInstanceEnvironmentRequest.EnvironmentEntryR\venvironment\x12\x18\n" +
+	"\arestart\x18\x05 \x01(\bR\arestart\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05force\x1a>\n" +
+	"\x10EnvironmentEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"8\n" +
+	"\x1eSetInstanceEnvironmentResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"d\n" +
+	"\x15ScaleInstancesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"d\n" +
+	"\x16ScaleInstancesResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\astarted\x18\x02 \x03(\tR\astarted\x12\x18\n" +
+	"\astopped\x18\x03 \x03(\tR\astopped\"\xd1\x02\n" +
 	"\x16AddFirewallRuleRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
 	"\x06action\x18\x02 \x01(\tR\x06action\x12\x14\n" +
@@ -4407,10 +9846,18 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\tfrom_port\x18\x05 \x01(\x05R\bfromPort\x12\x13\n" +
 	"\x05to_ip\x18\x06 \x01(\tR\x04toIp\x12\x17\n" +
 	"\ato_port\x18\a \x01(\x05R\x06toPort\x12\x18\n" +
-	"\acomment\x18\b \x01(\tR\acomment\"G\n" +
+	"\acomment\x18\b \x01(\tR\acomment\x12\x17\n" +
+	"\adry_run\x18\t \x01(\bR\x06dryRun\x12\x19\n" +
+	"\brule_set\x18\n" +
+	" \x01(\tR\aruleSet\x12\x1d\n" +
+	"\n" +
+	"rate_limit\x18\v \x01(\tR\trateLimit\x12\x1d\n" +
+	"\n" +
+	"rate_burst\x18\f \x01(\x05R\trateBurst\"[\n" +
 	"\x17AddFirewallRuleResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"W\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"W\n" +
 	"\x19DeleteFirewallRuleRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vrule_number\x18\x02 \x01(\x05R\n" +
@@ -4421,21 +9868,25 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x18ListFirewallRulesRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"1\n" +
 	"\x19ListFirewallRulesResponse\x12\x14\n" +
-	"\x05rules\x18\x01 \x03(\tR\x05rules\"W\n" +
+	"\x05rules\x18\x01 \x03(\tR\x05rules\"p\n" +
 	"\x10AllowPortRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x14\n" +
-	"\x05proto\x18\x03 \x01(\tR\x05proto\"A\n" +
+	"\x05proto\x18\x03 \x01(\tR\x05proto\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"U\n" +
 	"\x11AllowPortResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"o\n" +
 	"\x0fDenyPortRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x14\n" +
-	"\x05proto\x18\x03 \x01(\tR\x05proto\"@\n" +
+	"\x05proto\x18\x03 \x01(\tR\x05proto\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"T\n" +
 	"\x10DenyPortResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"2\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"2\n" +
 	"\x15EnableFirewallRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"F\n" +
 	"\x16EnableFirewallResponse\x12\x16\n" +
@@ -4445,7 +9896,15 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"G\n" +
 	"\x17DisableFirewallResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x83\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"f\n" +
+	"\x1cRemoveFirewallRuleSetRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"e\n" +
+	"\x1dRemoveFirewallRuleSetResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\x05R\aremoved\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\x83\x01\n" +
 	"\x18ObtainCertificateRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
 	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x14\n" +
@@ -4484,7 +9943,121 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\n" +
 	"expires_at\x18\x04 \x01(\tR\texpiresAt\x12\x1b\n" +
 	"\tissued_at\x18\x05 \x01(\tR\bissuedAt\x12\x16\n" +
-	"\x06issuer\x18\x06 \x01(\tR\x06issuer\"/\n" +
+	"\x06issuer\x18\x06 \x01(\tR\x06issuer\"6\n" +
+	"\x19ListSysctlProfilesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"[\n" +
+	"\x1aListSysctlProfilesResponse\x12=\n" +
+	"\bprofiles\x18\x01 \x03(\v2!.mandau.services.v1.SysctlProfileR\bprofiles\"\xe9\x01\n" +
+	"\rSysctlProfile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12K\n" +
+	"\bsettings\x18\x03 \x03(\v2/.mandau.services.v1.SysctlProfile.SettingsEntryR\bsettings\x12\x18\n" +
+	"\aapplied\x18\x04 \x01(\bR\aapplied\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"x\n" +
+	"\x19ApplySysctlProfileRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06remove\x18\x03 \x01(\bR\x06remove\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"4\n" +
+	"\x1aApplySysctlProfileResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"1\n" +
+	"\x14VerifySysctlsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"P\n" +
+	"\x15VerifySysctlsResponse\x127\n" +
+	"\x06checks\x18\x01 \x03(\v2\x1f.mandau.services.v1.SysctlCheckR\x06checks\"{\n" +
+	"\vSysctlCheck\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1c\n" +
+	"\tpersisted\x18\x02 \x01(\tR\tpersisted\x12\x18\n" +
+	"\aruntime\x18\x03 \x01(\tR\aruntime\x12\x12\n" +
+	"\x04file\x18\x04 \x01(\tR\x04file\x12\x0e\n" +
+	"\x02ok\x18\x05 \x01(\bR\x02ok\"\x8b\x01\n" +
+	"\x05Limit\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04item\x18\x03 \x01(\tR\x04item\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x12\x12\n" +
+	"\x04file\x18\x05 \x01(\tR\x04file\x12\x18\n" +
+	"\amanaged\x18\x06 \x01(\bR\amanaged\"-\n" +
+	"\x10GetLimitsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"F\n" +
+	"\x11GetLimitsResponse\x121\n" +
+	"\x06limits\x18\x01 \x03(\v2\x19.mandau.services.v1.LimitR\x06limits\"\x8a\x01\n" +
+	"\x10SetLimitsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x121\n" +
+	"\x06limits\x18\x03 \x03(\v2\x19.mandau.services.v1.LimitR\x06limits\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"+\n" +
+	"\x11SetLimitsResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x93\x03\n" +
+	"\x14DockerDaemonSettings\x12\x1d\n" +
+	"\n" +
+	"log_driver\x18\x01 \x01(\tR\tlogDriver\x12P\n" +
+	"\blog_opts\x18\x02 \x03(\v25.mandau.services.v1.DockerDaemonSettings.LogOptsEntryR\alogOpts\x12)\n" +
+	"\x10registry_mirrors\x18\x03 \x03(\tR\x0fregistryMirrors\x12%\n" +
+	"\x0estorage_driver\x18\x04 \x01(\tR\rstorageDriver\x12!\n" +
+	"\fstorage_opts\x18\x05 \x03(\tR\vstorageOpts\x12Y\n" +
+	"\x15default_address_pools\x18\x06 \x03(\v2%.mandau.services.v1.DockerAddressPoolR\x13defaultAddressPools\x1a:\n" +
+	"\fLogOptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\";\n" +
+	"\x11DockerAddressPool\x12\x12\n" +
+	"\x04base\x18\x01 \x01(\tR\x04base\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\"3\n" +
+	"\x16GetDockerDaemonRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xf7\x01\n" +
+	"\x17GetDockerDaemonResponse\x12D\n" +
+	"\bsettings\x18\x01 \x01(\v2(.mandau.services.v1.DockerDaemonSettingsR\bsettings\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x18\n" +
+	"\arunning\x18\x03 \x01(\bR\arunning\x12,\n" +
+	"\x12running_log_driver\x18\x04 \x01(\tR\x10runningLogDriver\x124\n" +
+	"\x16running_storage_driver\x18\x05 \x01(\tR\x14runningStorageDriver\"\xac\x01\n" +
+	"\x16SetDockerDaemonRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12D\n" +
+	"\bsettings\x18\x02 \x01(\v2(.mandau.services.v1.DockerDaemonSettingsR\bsettings\x12\x18\n" +
+	"\arestart\x18\x03 \x01(\bR\arestart\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"}\n" +
+	"\x17SetDockerDaemonResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\achanged\x18\x02 \x01(\bR\achanged\x12\x1c\n" +
+	"\trestarted\x18\x03 \x01(\bR\trestarted\x12\x12\n" +
+	"\x04diff\x18\x04 \x01(\tR\x04diff\"?\n" +
+	"\x0eGetUserRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x94\x01\n" +
+	"\x0fGetUserResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x12\x14\n" +
+	"\x05shell\x18\x02 \x01(\tR\x05shell\x12\x12\n" +
+	"\x04home\x18\x03 \x01(\tR\x04home\x12\x16\n" +
+	"\x06groups\x18\x04 \x03(\tR\x06groups\x12'\n" +
+	"\x0fauthorized_keys\x18\x05 \x03(\tR\x0eauthorizedKeys\"\xaf\x01\n" +
+	"\x11EnsureUserRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05shell\x18\x03 \x01(\tR\x05shell\x12\x16\n" +
+	"\x06groups\x18\x04 \x03(\tR\x06groups\x12'\n" +
+	"\x0fauthorized_keys\x18\x05 \x03(\tR\x0eauthorizedKeys\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05force\",\n" +
+	"\x12EnsureUserResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"2\n" +
+	"\x15GetPatchStatusRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"/\n" +
+	"\x12GetTimeSyncRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xac\x01\n" +
+	"\x13GetTimeSyncResponse\x12\x16\n" +
+	"\x06daemon\x18\x01 \x01(\tR\x06daemon\x12\"\n" +
+	"\fsynchronized\x18\x02 \x01(\bR\fsynchronized\x12%\n" +
+	"\x0eoffset_seconds\x18\x03 \x01(\x01R\roffsetSeconds\x12\x18\n" +
+	"\astratum\x18\x04 \x01(\x05R\astratum\x12\x18\n" +
+	"\aservers\x18\x05 \x03(\tR\aservers\"y\n" +
+	"\x18ConfigureTimeSyncRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aservers\x18\x02 \x03(\tR\aservers\x12\x12\n" +
+	"\x04step\x18\x03 \x01(\bR\x04step\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"3\n" +
+	"\x19ConfigureTimeSyncResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"/\n" +
 	"\x12GetHostInfoRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xe8\x01\n" +
 	"\x13GetHostInfoResponse\x12\x1a\n" +
@@ -4516,11 +10089,13 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x13ListPackagesRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"2\n" +
 	"\x14ListPackagesResponse\x12\x1a\n" +
-	"\bpackages\x18\x01 \x03(\tR\bpackages\"U\n" +
+	"\bpackages\x18\x01 \x03(\tR\bpackages\"\x85\x01\n" +
 	"\x10SetSysctlRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x10\n" +
 	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\tR\x05value\"A\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x18\n" +
+	"\apersist\x18\x04 \x01(\bR\apersist\x12\x14\n" +
+	"\x05force\x18\x05 \x01(\bR\x05force\"A\n" +
 	"\x11SetSysctlResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"?\n" +
@@ -4529,7 +10104,74 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x03key\x18\x02 \x01(\tR\x03key\"?\n" +
 	"\x11GetSysctlResponse\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\xd6\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"g\n" +
+	"\aCronJob\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bschedule\x18\x02 \x01(\tR\bschedule\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\x12\x12\n" +
+	"\x04user\x18\x04 \x01(\tR\x04user\"\x8c\x01\n" +
+	"\x11AddCronJobRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12-\n" +
+	"\x03job\x18\x02 \x01(\v2\x1b.mandau.services.v1.CronJobR\x03job\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"V\n" +
+	"\x12AddCronJobResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"t\n" +
+	"\x14RemoveCronJobRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"Y\n" +
+	"\x15RemoveCronJobResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"0\n" +
+	"\x13ListCronJobsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"G\n" +
+	"\x14ListCronJobsResponse\x12/\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x1b.mandau.services.v1.CronJobR\x04jobs\"\xbf\x01\n" +
+	"\x11CreateZoneRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x12 \n" +
+	"\vnameservers\x18\x04 \x03(\tR\vnameservers\x12\x14\n" +
+	"\x05admin\x18\x05 \x01(\tR\x05admin\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\"V\n" +
+	"\x12CreateZoneResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\xab\x01\n" +
+	"\x11AddARecordRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x10\n" +
+	"\x03ttl\x18\x05 \x01(\x05R\x03ttl\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\"V\n" +
+	"\x12AddARecordResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\xb7\x01\n" +
+	"\x15AddCNAMERecordRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x10\n" +
+	"\x03ttl\x18\x05 \x01(\x05R\x03ttl\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\"Z\n" +
+	"\x16AddCNAMERecordResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"-\n" +
+	"\x10ListZonesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\")\n" +
+	"\x11ListZonesResponse\x12\x14\n" +
+	"\x05zones\x18\x01 \x03(\tR\x05zones\"\xd6\x01\n" +
 	"\x15ServiceOperationEvent\x12!\n" +
 	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x14\n" +
 	"\x05state\x18\x02 \x01(\tR\x05state\x128\n" +
@@ -4552,10 +10194,160 @@ const file_api_v1_service_proto_rawDesc = "" +
 	" \x03(\v2<.mandau.services.v1.DeployWebServiceRequest.EnvironmentEntryR\venvironment\x1a>\n" +
 	"\x10EnvironmentEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
 	"\x17RemoveWebServiceRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name2\xb2\x06\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"8\n" +
+	"\x1bListDeployedServicesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"_\n" +
+	"\x1cListDeployedServicesResponse\x12?\n" +
+	"\bservices\x18\x01 \x03(\v2#.mandau.services.v1.DeployedServiceR\bservices\"\xe8\x01\n" +
+	"\x0fDeployedService\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\x12;\n" +
+	"\vdeployed_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"deployedAt\x12B\n" +
+	"\tresources\x18\x05 \x03(\v2$.mandau.services.v1.DeployedResourceR\tresources\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\":\n" +
+	"\x10DeployedResource\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x9c\x01\n" +
+	"\x17DeployStaticSiteRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04root\x18\x04 \x01(\tR\x04root\x12\x14\n" +
+	"\x05index\x18\x05 \x03(\tR\x05index\x12\x10\n" +
+	"\x03ssl\x18\x06 \x01(\bR\x03ssl\"\xbf\x02\n" +
+	"\x15DeployDatabaseRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06engine\x18\x03 \x01(\tR\x06engine\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x12\n" +
+	"\x04port\x18\x05 \x01(\x05R\x04port\x12\x19\n" +
+	"\bdata_dir\x18\x06 \x01(\tR\adataDir\x12\x1a\n" +
+	"\bpassword\x18\a \x01(\tR\bpassword\x12'\n" +
+	"\x0fbackup_schedule\x18\b \x01(\tR\x0ebackupSchedule\x12\x1d\n" +
+	"\n" +
+	"backup_dir\x18\t \x01(\tR\tbackupDir\x122\n" +
+	"\x15backup_retention_days\x18\n" +
+	" \x01(\x05R\x13backupRetentionDays\"\xd1\x02\n" +
+	"\x13DeployWorkerRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\acommand\x18\x04 \x01(\tR\acommand\x12\x1f\n" +
+	"\vworking_dir\x18\x05 \x01(\tR\n" +
+	"workingDir\x12\x12\n" +
+	"\x04user\x18\x06 \x01(\tR\x04user\x12Z\n" +
+	"\venvironment\x18\a \x03(\v28.mandau.services.v1.DeployWorkerRequest.EnvironmentEntryR\venvironment\x1a>\n" +
+	"\x10EnvironmentEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"2\n" +
+	"\x15GetDriftReportRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"}\n" +
+	"\vDriftReport\x129\n" +
+	"\n" +
+	"scanned_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tscannedAt\x123\n" +
+	"\x05drift\x18\x02 \x03(\v2\x1d.mandau.services.v1.HostDriftR\x05drift\"a\n" +
+	"\tHostDrift\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\"J\n" +
+	"\x19ListListeningPortsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\"\xe2\x01\n" +
+	"\rListeningPort\x12\x14\n" +
+	"\x05proto\x18\x01 \x01(\tR\x05proto\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12\x18\n" +
+	"\aprocess\x18\x05 \x01(\tR\aprocess\x12!\n" +
+	"\fcontainer_id\x18\x06 \x01(\tR\vcontainerId\x12\x1c\n" +
+	"\tcontainer\x18\a \x01(\tR\tcontainer\x12 \n" +
+	"\vconnections\x18\b \x01(\x05R\vconnections\"U\n" +
+	"\x1aListListeningPortsResponse\x127\n" +
+	"\x05ports\x18\x01 \x03(\v2!.mandau.services.v1.ListeningPortR\x05ports\"\x83\x01\n" +
+	"\x14ListProcessesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x12\n" +
+	"\x04sort\x18\x04 \x01(\tR\x04sort\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"\xb7\x02\n" +
+	"\vHostProcess\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04ppid\x18\x02 \x01(\x05R\x04ppid\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x18\n" +
+	"\acommand\x18\x05 \x01(\tR\acommand\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x1f\n" +
+	"\vcpu_percent\x18\a \x01(\x01R\n" +
+	"cpuPercent\x12!\n" +
+	"\fmemory_bytes\x18\b \x01(\x04R\vmemoryBytes\x12%\n" +
+	"\x0ememory_percent\x18\t \x01(\x01R\rmemoryPercent\x12!\n" +
+	"\fcontainer_id\x18\n" +
+	" \x01(\tR\vcontainerId\x12\x1c\n" +
+	"\tcontainer\x18\v \x01(\tR\tcontainer\"V\n" +
+	"\x15ListProcessesResponse\x12=\n" +
+	"\tprocesses\x18\x01 \x03(\v2\x1f.mandau.services.v1.HostProcessR\tprocesses\"[\n" +
+	"\x14SignalProcessRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x10\n" +
+	"\x03pid\x18\x02 \x01(\x05R\x03pid\x12\x16\n" +
+	"\x06signal\x18\x03 \x01(\tR\x06signal\"C\n" +
+	"\x15SignalProcessResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"0\n" +
+	"\x13ListLogFilesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"i\n" +
+	"\aLogFile\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x126\n" +
+	"\bmodified\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bmodified\"I\n" +
+	"\x14ListLogFilesResponse\x121\n" +
+	"\x05files\x18\x01 \x03(\v2\x1b.mandau.services.v1.LogFileR\x05files\"\xb7\x01\n" +
+	"\x12TailLogFileRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05lines\x18\x03 \x01(\x05R\x05lines\x12\x16\n" +
+	"\x06follow\x18\x04 \x01(\bR\x06follow\x12\x12\n" +
+	"\x04grep\x18\x05 \x01(\tR\x04grep\x120\n" +
+	"\x05since\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\"\\\n" +
+	"\x11RebootHostRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05drain\x18\x02 \x01(\bR\x05drain\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"2\n" +
+	"\x15GetLogShippingRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xca\x02\n" +
+	"\vLogShipping\x12\x12\n" +
+	"\x04sink\x18\x01 \x01(\tR\x04sink\x12\x18\n" +
+	"\ashipped\x18\x02 \x01(\x03R\ashipped\x12\x18\n" +
+	"\adropped\x18\x03 \x01(\x03R\adropped\x12\x18\n" +
+	"\apending\x18\x04 \x01(\x05R\apending\x12=\n" +
+	"\flast_shipped\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastShipped\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x12>\n" +
+	"\rlast_error_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vlastErrorAt\x12;\n" +
+	"\astreams\x18\b \x03(\v2!.mandau.services.v1.ShippedStreamR\astreams\"s\n" +
+	"\rShippedStream\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x14\n" +
+	"\x05stack\x18\x02 \x01(\tR\x05stack\x12\x18\n" +
+	"\aservice\x18\x03 \x01(\tR\aservice\x12\x1a\n" +
+	"\bposition\x18\x04 \x01(\tR\bposition\"0\n" +
+	"\x13ListCommandsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"{\n" +
+	"\x0eAllowedCommand\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"V\n" +
+	"\x14ListCommandsResponse\x12>\n" +
+	"\bcommands\x18\x01 \x03(\v2\".mandau.services.v1.AllowedCommandR\bcommands\"w\n" +
+	"\x11RunCommandRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout2\xb2\x06\n" +
 	"\fNginxService\x12p\n" +
 	"\x11CreateVirtualHost\x12,.mandau.services.v1.CreateVirtualHostRequest\x1a-.mandau.services.v1.CreateVirtualHostResponse\x12p\n" +
 	"\x11EnableVirtualHost\x12,.mandau.services.v1.EnableVirtualHostRequest\x1a-.mandau.services.v1.EnableVirtualHostResponse\x12s\n" +
@@ -4563,7 +10355,7 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x11DeleteVirtualHost\x12,.mandau.services.v1.DeleteVirtualHostRequest\x1a-.mandau.services.v1.DeleteVirtualHostResponse\x12m\n" +
 	"\x10ListVirtualHosts\x12+.mandau.services.v1.ListVirtualHostsRequest\x1a,.mandau.services.v1.ListVirtualHostsResponse\x12s\n" +
 	"\x12CreateReverseProxy\x12-.mandau.services.v1.CreateReverseProxyRequest\x1a..mandau.services.v1.CreateReverseProxyResponse\x12s\n" +
-	"\x12CreateLoadBalancer\x12-.mandau.services.v1.CreateLoadBalancerRequest\x1a..mandau.services.v1.CreateLoadBalancerResponse2\xc3\x06\n" +
+	"\x12CreateLoadBalancer\x12-.mandau.services.v1.CreateLoadBalancerRequest\x1a..mandau.services.v1.CreateLoadBalancerResponse2\x93\t\n" +
 	"\x0eSystemdService\x12d\n" +
 	"\rCreateService\x12(.mandau.services.v1.CreateServiceRequest\x1a).mandau.services.v1.CreateServiceResponse\x12d\n" +
 	"\rEnableService\x12(.mandau.services.v1.EnableServiceRequest\x1a).mandau.services.v1.EnableServiceResponse\x12g\n" +
@@ -4572,7 +10364,10 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\vStopService\x12&.mandau.services.v1.StopServiceRequest\x1a'.mandau.services.v1.StopServiceResponse\x12g\n" +
 	"\x0eRestartService\x12).mandau.services.v1.RestartServiceRequest\x1a*.mandau.services.v1.RestartServiceResponse\x12m\n" +
 	"\x10GetServiceStatus\x12+.mandau.services.v1.GetServiceStatusRequest\x1a,.mandau.services.v1.GetServiceStatusResponse\x12a\n" +
-	"\fListServices\x12'.mandau.services.v1.ListServicesRequest\x1a(.mandau.services.v1.ListServicesResponse2\xc2\x05\n" +
+	"\fListServices\x12'.mandau.services.v1.ListServicesRequest\x1a(.mandau.services.v1.ListServicesResponse\x12d\n" +
+	"\rListInstances\x12(.mandau.services.v1.ListInstancesRequest\x1a).mandau.services.v1.ListInstancesResponse\x12\x7f\n" +
+	"\x16SetInstanceEnvironment\x121.mandau.services.v1.SetInstanceEnvironmentRequest\x1a2.mandau.services.v1.SetInstanceEnvironmentResponse\x12g\n" +
+	"\x0eScaleInstances\x12).mandau.services.v1.ScaleInstancesRequest\x1a*.mandau.services.v1.ScaleInstancesResponse2\xb8\x06\n" +
 	"\x0fFirewallService\x12b\n" +
 	"\aAddRule\x12*.mandau.services.v1.AddFirewallRuleRequest\x1a+.mandau.services.v1.AddFirewallRuleResponse\x12k\n" +
 	"\n" +
@@ -4581,13 +10376,14 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\tAllowPort\x12$.mandau.services.v1.AllowPortRequest\x1a%.mandau.services.v1.AllowPortResponse\x12U\n" +
 	"\bDenyPort\x12#.mandau.services.v1.DenyPortRequest\x1a$.mandau.services.v1.DenyPortResponse\x12_\n" +
 	"\x06Enable\x12).mandau.services.v1.EnableFirewallRequest\x1a*.mandau.services.v1.EnableFirewallResponse\x12b\n" +
-	"\aDisable\x12*.mandau.services.v1.DisableFirewallRequest\x1a+.mandau.services.v1.DisableFirewallResponse2\xbe\x04\n" +
+	"\aDisable\x12*.mandau.services.v1.DisableFirewallRequest\x1a+.mandau.services.v1.DisableFirewallResponse\x12t\n" +
+	"\rRemoveRuleSet\x120.mandau.services.v1.RemoveFirewallRuleSetRequest\x1a1.mandau.services.v1.RemoveFirewallRuleSetResponse2\xbe\x04\n" +
 	"\vACMEService\x12p\n" +
 	"\x11ObtainCertificate\x12,.mandau.services.v1.ObtainCertificateRequest\x1a-.mandau.services.v1.ObtainCertificateResponse\x12m\n" +
 	"\x10RenewCertificate\x12+.mandau.services.v1.RenewCertificateRequest\x1a,.mandau.services.v1.RenewCertificateResponse\x12m\n" +
 	"\bRenewAll\x12/.mandau.services.v1.RenewAllCertificatesRequest\x1a0.mandau.services.v1.RenewAllCertificatesResponse\x12p\n" +
 	"\x11RevokeCertificate\x12,.mandau.services.v1.RevokeCertificateRequest\x1a-.mandau.services.v1.RevokeCertificateResponse\x12m\n" +
-	"\x10ListCertificates\x12+.mandau.services.v1.ListCertificatesRequest\x1a,.mandau.services.v1.ListCertificatesResponse2\xc7\x05\n" +
+	"\x10ListCertificates\x12+.mandau.services.v1.ListCertificatesRequest\x1a,.mandau.services.v1.ListCertificatesResponse2\x81\x0f\n" +
 	"\x16HostEnvironmentService\x12^\n" +
 	"\vGetHostInfo\x12&.mandau.services.v1.GetHostInfoRequest\x1a'.mandau.services.v1.GetHostInfoResponse\x12g\n" +
 	"\x0eInstallPackage\x12).mandau.services.v1.InstallPackageRequest\x1a*.mandau.services.v1.InstallPackageResponse\x12d\n" +
@@ -4595,10 +10391,59 @@ const file_api_v1_service_proto_rawDesc = "" +
 	"\x0eUpdatePackages\x12).mandau.services.v1.UpdatePackagesRequest\x1a*.mandau.services.v1.UpdatePackagesResponse\x12a\n" +
 	"\fListPackages\x12'.mandau.services.v1.ListPackagesRequest\x1a(.mandau.services.v1.ListPackagesResponse\x12X\n" +
 	"\tSetSysctl\x12$.mandau.services.v1.SetSysctlRequest\x1a%.mandau.services.v1.SetSysctlResponse\x12X\n" +
-	"\tGetSysctl\x12$.mandau.services.v1.GetSysctlRequest\x1a%.mandau.services.v1.GetSysctlResponse2\xf6\x01\n" +
+	"\tGetSysctl\x12$.mandau.services.v1.GetSysctlRequest\x1a%.mandau.services.v1.GetSysctlResponse\x12^\n" +
+	"\vGetTimeSync\x12&.mandau.services.v1.GetTimeSyncRequest\x1a'.mandau.services.v1.GetTimeSyncResponse\x12p\n" +
+	"\x11ConfigureTimeSync\x12,.mandau.services.v1.ConfigureTimeSyncRequest\x1a-.mandau.services.v1.ConfigureTimeSyncResponse\x12Y\n" +
+	"\x0eGetPatchStatus\x12).mandau.services.v1.GetPatchStatusRequest\x1a\x1c.mandau.agent.v1.PatchStatus\x12R\n" +
+	"\aGetUser\x12\".mandau.services.v1.GetUserRequest\x1a#.mandau.services.v1.GetUserResponse\x12[\n" +
+	"\n" +
+	"EnsureUser\x12%.mandau.services.v1.EnsureUserRequest\x1a&.mandau.services.v1.EnsureUserResponse\x12s\n" +
+	"\x12ListSysctlProfiles\x12-.mandau.services.v1.ListSysctlProfilesRequest\x1a..mandau.services.v1.ListSysctlProfilesResponse\x12s\n" +
+	"\x12ApplySysctlProfile\x12-.mandau.services.v1.ApplySysctlProfileRequest\x1a..mandau.services.v1.ApplySysctlProfileResponse\x12d\n" +
+	"\rVerifySysctls\x12(.mandau.services.v1.VerifySysctlsRequest\x1a).mandau.services.v1.VerifySysctlsResponse\x12X\n" +
+	"\tGetLimits\x12$.mandau.services.v1.GetLimitsRequest\x1a%.mandau.services.v1.GetLimitsResponse\x12X\n" +
+	"\tSetLimits\x12$.mandau.services.v1.SetLimitsRequest\x1a%.mandau.services.v1.SetLimitsResponse\x12j\n" +
+	"\x0fGetDockerDaemon\x12*.mandau.services.v1.GetDockerDaemonRequest\x1a+.mandau.services.v1.GetDockerDaemonResponse\x12j\n" +
+	"\x0fSetDockerDaemon\x12*.mandau.services.v1.SetDockerDaemonRequest\x1a+.mandau.services.v1.SetDockerDaemonResponse2\xb3\x02\n" +
+	"\vCronService\x12[\n" +
+	"\n" +
+	"AddCronJob\x12%.mandau.services.v1.AddCronJobRequest\x1a&.mandau.services.v1.AddCronJobResponse\x12d\n" +
+	"\rRemoveCronJob\x12(.mandau.services.v1.RemoveCronJobRequest\x1a).mandau.services.v1.RemoveCronJobResponse\x12a\n" +
+	"\fListCronJobs\x12'.mandau.services.v1.ListCronJobsRequest\x1a(.mandau.services.v1.ListCronJobsResponse2\x89\x03\n" +
+	"\n" +
+	"DNSService\x12[\n" +
+	"\n" +
+	"CreateZone\x12%.mandau.services.v1.CreateZoneRequest\x1a&.mandau.services.v1.CreateZoneResponse\x12[\n" +
+	"\n" +
+	"AddARecord\x12%.mandau.services.v1.AddARecordRequest\x1a&.mandau.services.v1.AddARecordResponse\x12g\n" +
+	"\x0eAddCNAMERecord\x12).mandau.services.v1.AddCNAMERecordRequest\x1a*.mandau.services.v1.AddCNAMERecordResponse\x12X\n" +
+	"\tListZones\x12$.mandau.services.v1.ListZonesRequest\x1a%.mandau.services.v1.ListZonesResponse2\xaf\x05\n" +
 	"\x18ServiceDeploymentService\x12l\n" +
 	"\x10DeployWebService\x12+.mandau.services.v1.DeployWebServiceRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01\x12l\n" +
-	"\x10RemoveWebService\x12+.mandau.services.v1.RemoveWebServiceRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01B%Z#github.com/bhangun/mandau/api/v1;v1b\x06proto3"
+	"\x10RemoveWebService\x12+.mandau.services.v1.RemoveWebServiceRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01\x12l\n" +
+	"\x10DeployStaticSite\x12+.mandau.services.v1.DeployStaticSiteRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01\x12h\n" +
+	"\x0eDeployDatabase\x12).mandau.services.v1.DeployDatabaseRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01\x12d\n" +
+	"\fDeployWorker\x12'.mandau.services.v1.DeployWorkerRequest\x1a).mandau.services.v1.ServiceOperationEvent0\x01\x12y\n" +
+	"\x14ListDeployedServices\x12/.mandau.services.v1.ListDeployedServicesRequest\x1a0.mandau.services.v1.ListDeployedServicesResponse2l\n" +
+	"\fDriftService\x12\\\n" +
+	"\x0eGetDriftReport\x12).mandau.services.v1.GetDriftReportRequest\x1a\x1f.mandau.services.v1.DriftReport2\x82\x01\n" +
+	"\vPortService\x12s\n" +
+	"\x12ListListeningPorts\x12-.mandau.services.v1.ListListeningPortsRequest\x1a..mandau.services.v1.ListListeningPortsResponse2\xdc\x01\n" +
+	"\x0eProcessService\x12d\n" +
+	"\rListProcesses\x12(.mandau.services.v1.ListProcessesRequest\x1a).mandau.services.v1.ListProcessesResponse\x12d\n" +
+	"\rSignalProcess\x12(.mandau.services.v1.SignalProcessRequest\x1a).mandau.services.v1.SignalProcessResponse2\xc7\x01\n" +
+	"\x0eHostLogService\x12a\n" +
+	"\fListLogFiles\x12'.mandau.services.v1.ListLogFilesRequest\x1a(.mandau.services.v1.ListLogFilesResponse\x12R\n" +
+	"\vTailLogFile\x12&.mandau.services.v1.TailLogFileRequest\x1a\x19.mandau.agent.v1.LogEntry0\x012j\n" +
+	"\x10HostPowerService\x12V\n" +
+	"\n" +
+	"RebootHost\x12%.mandau.services.v1.RebootHostRequest\x1a\x1f.mandau.agent.v1.OperationEvent0\x012n\n" +
+	"\x0eLogShipService\x12\\\n" +
+	"\x0eGetLogShipping\x12).mandau.services.v1.GetLogShippingRequest\x1a\x1f.mandau.services.v1.LogShipping2\xc8\x01\n" +
+	"\x0eCommandService\x12a\n" +
+	"\fListCommands\x12'.mandau.services.v1.ListCommandsRequest\x1a(.mandau.services.v1.ListCommandsResponse\x12S\n" +
+	"\n" +
+	"RunCommand\x12%.mandau.services.v1.RunCommandRequest\x1a\x1e.mandau.agent.v1.CommandResultB%Z#github.com/bhangun/mandau/api/v1;v1b\x06proto3"
 
 var (
 	file_api_v1_service_proto_rawDescOnce sync.Once
@@ -4612,173 +10457,370 @@ func file_api_v1_service_proto_rawDescGZIP() []byte {
 	return file_api_v1_service_proto_rawDescData
 }
 
-var file_api_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 77)
+var file_api_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 164)
 var file_api_v1_service_proto_goTypes = []any{
-	(*CreateVirtualHostRequest)(nil),     // 0: mandau.services.v1.CreateVirtualHostRequest
-	(*CreateVirtualHostResponse)(nil),    // 1: mandau.services.v1.CreateVirtualHostResponse
-	(*EnableVirtualHostRequest)(nil),     // 2: mandau.services.v1.EnableVirtualHostRequest
-	(*EnableVirtualHostResponse)(nil),    // 3: mandau.services.v1.EnableVirtualHostResponse
-	(*DisableVirtualHostRequest)(nil),    // 4: mandau.services.v1.DisableVirtualHostRequest
-	(*DisableVirtualHostResponse)(nil),   // 5: mandau.services.v1.DisableVirtualHostResponse
-	(*DeleteVirtualHostRequest)(nil),     // 6: mandau.services.v1.DeleteVirtualHostRequest
-	(*DeleteVirtualHostResponse)(nil),    // 7: mandau.services.v1.DeleteVirtualHostResponse
-	(*ListVirtualHostsRequest)(nil),      // 8: mandau.services.v1.ListVirtualHostsRequest
-	(*ListVirtualHostsResponse)(nil),     // 9: mandau.services.v1.ListVirtualHostsResponse
-	(*Location)(nil),                     // 10: mandau.services.v1.Location
-	(*SSLConfig)(nil),                    // 11: mandau.services.v1.SSLConfig
-	(*CreateReverseProxyRequest)(nil),    // 12: mandau.services.v1.CreateReverseProxyRequest
-	(*CreateReverseProxyResponse)(nil),   // 13: mandau.services.v1.CreateReverseProxyResponse
-	(*CreateLoadBalancerRequest)(nil),    // 14: mandau.services.v1.CreateLoadBalancerRequest
-	(*CreateLoadBalancerResponse)(nil),   // 15: mandau.services.v1.CreateLoadBalancerResponse
-	(*CreateServiceRequest)(nil),         // 16: mandau.services.v1.CreateServiceRequest
-	(*CreateServiceResponse)(nil),        // 17: mandau.services.v1.CreateServiceResponse
-	(*EnableServiceRequest)(nil),         // 18: mandau.services.v1.EnableServiceRequest
-	(*EnableServiceResponse)(nil),        // 19: mandau.services.v1.EnableServiceResponse
-	(*DisableServiceRequest)(nil),        // 20: mandau.services.v1.DisableServiceRequest
-	(*DisableServiceResponse)(nil),       // 21: mandau.services.v1.DisableServiceResponse
-	(*StartServiceRequest)(nil),          // 22: mandau.services.v1.StartServiceRequest
-	(*StartServiceResponse)(nil),         // 23: mandau.services.v1.StartServiceResponse
-	(*StopServiceRequest)(nil),           // 24: mandau.services.v1.StopServiceRequest
-	(*StopServiceResponse)(nil),          // 25: mandau.services.v1.StopServiceResponse
-	(*RestartServiceRequest)(nil),        // 26: mandau.services.v1.RestartServiceRequest
-	(*RestartServiceResponse)(nil),       // 27: mandau.services.v1.RestartServiceResponse
-	(*GetServiceStatusRequest)(nil),      // 28: mandau.services.v1.GetServiceStatusRequest
-	(*GetServiceStatusResponse)(nil),     // 29: mandau.services.v1.GetServiceStatusResponse
-	(*ListServicesRequest)(nil),          // 30: mandau.services.v1.ListServicesRequest
-	(*ListServicesResponse)(nil),         // 31: mandau.services.v1.ListServicesResponse
-	(*AddFirewallRuleRequest)(nil),       // 32: mandau.services.v1.AddFirewallRuleRequest
-	(*AddFirewallRuleResponse)(nil),      // 33: mandau.services.v1.AddFirewallRuleResponse
-	(*DeleteFirewallRuleRequest)(nil),    // 34: mandau.services.v1.DeleteFirewallRuleRequest
-	(*DeleteFirewallRuleResponse)(nil),   // 35: mandau.services.v1.DeleteFirewallRuleResponse
-	(*ListFirewallRulesRequest)(nil),     // 36: mandau.services.v1.ListFirewallRulesRequest
-	(*ListFirewallRulesResponse)(nil),    // 37: mandau.services.v1.ListFirewallRulesResponse
-	(*AllowPortRequest)(nil),             // 38: mandau.services.v1.AllowPortRequest
-	(*AllowPortResponse)(nil),            // 39: mandau.services.v1.AllowPortResponse
-	(*DenyPortRequest)(nil),              // 40: mandau.services.v1.DenyPortRequest
-	(*DenyPortResponse)(nil),             // 41: mandau.services.v1.DenyPortResponse
-	(*EnableFirewallRequest)(nil),        // 42: mandau.services.v1.EnableFirewallRequest
-	(*EnableFirewallResponse)(nil),       // 43: mandau.services.v1.EnableFirewallResponse
-	(*DisableFirewallRequest)(nil),       // 44: mandau.services.v1.DisableFirewallRequest
-	(*DisableFirewallResponse)(nil),      // 45: mandau.services.v1.DisableFirewallResponse
-	(*ObtainCertificateRequest)(nil),     // 46: mandau.services.v1.ObtainCertificateRequest
-	(*ObtainCertificateResponse)(nil),    // 47: mandau.services.v1.ObtainCertificateResponse
-	(*RenewCertificateRequest)(nil),      // 48: mandau.services.v1.RenewCertificateRequest
-	(*RenewCertificateResponse)(nil),     // 49: mandau.services.v1.RenewCertificateResponse
-	(*RenewAllCertificatesRequest)(nil),  // 50: mandau.services.v1.RenewAllCertificatesRequest
-	(*RenewAllCertificatesResponse)(nil), // 51: mandau.services.v1.RenewAllCertificatesResponse
-	(*RevokeCertificateRequest)(nil),     // 52: mandau.services.v1.RevokeCertificateRequest
-	(*RevokeCertificateResponse)(nil),    // 53: mandau.services.v1.RevokeCertificateResponse
-	(*ListCertificatesRequest)(nil),      // 54: mandau.services.v1.ListCertificatesRequest
-	(*ListCertificatesResponse)(nil),     // 55: mandau.services.v1.ListCertificatesResponse
-	(*Certificate)(nil),                  // 56: mandau.services.v1.Certificate
-	(*GetHostInfoRequest)(nil),           // 57: mandau.services.v1.GetHostInfoRequest
-	(*GetHostInfoResponse)(nil),          // 58: mandau.services.v1.GetHostInfoResponse
-	(*InstallPackageRequest)(nil),        // 59: mandau.services.v1.InstallPackageRequest
-	(*InstallPackageResponse)(nil),       // 60: mandau.services.v1.InstallPackageResponse
-	(*RemovePackageRequest)(nil),         // 61: mandau.services.v1.RemovePackageRequest
-	(*RemovePackageResponse)(nil),        // 62: mandau.services.v1.RemovePackageResponse
-	(*UpdatePackagesRequest)(nil),        // 63: mandau.services.v1.UpdatePackagesRequest
-	(*UpdatePackagesResponse)(nil),       // 64: mandau.services.v1.UpdatePackagesResponse
-	(*ListPackagesRequest)(nil),          // 65: mandau.services.v1.ListPackagesRequest
-	(*ListPackagesResponse)(nil),         // 66: mandau.services.v1.ListPackagesResponse
-	(*SetSysctlRequest)(nil),             // 67: mandau.services.v1.SetSysctlRequest
-	(*SetSysctlResponse)(nil),            // 68: mandau.services.v1.SetSysctlResponse
-	(*GetSysctlRequest)(nil),             // 69: mandau.services.v1.GetSysctlRequest
-	(*GetSysctlResponse)(nil),            // 70: mandau.services.v1.GetSysctlResponse
-	(*ServiceOperationEvent)(nil),        // 71: mandau.services.v1.ServiceOperationEvent
-	(*DeployWebServiceRequest)(nil),      // 72: mandau.services.v1.DeployWebServiceRequest
-	(*RemoveWebServiceRequest)(nil),      // 73: mandau.services.v1.RemoveWebServiceRequest
-	nil,                                  // 74: mandau.services.v1.Location.HeadersEntry
-	nil,                                  // 75: mandau.services.v1.CreateServiceRequest.EnvironmentEntry
-	nil,                                  // 76: mandau.services.v1.DeployWebServiceRequest.EnvironmentEntry
-	(*timestamppb.Timestamp)(nil),        // 77: google.protobuf.Timestamp
+	(*CreateVirtualHostRequest)(nil),       // 0: mandau.services.v1.CreateVirtualHostRequest
+	(*CreateVirtualHostResponse)(nil),      // 1: mandau.services.v1.CreateVirtualHostResponse
+	(*EnableVirtualHostRequest)(nil),       // 2: mandau.services.v1.EnableVirtualHostRequest
+	(*EnableVirtualHostResponse)(nil),      // 3: mandau.services.v1.EnableVirtualHostResponse
+	(*DisableVirtualHostRequest)(nil),      // 4: mandau.services.v1.DisableVirtualHostRequest
+	(*DisableVirtualHostResponse)(nil),     // 5: mandau.services.v1.DisableVirtualHostResponse
+	(*DeleteVirtualHostRequest)(nil),       // 6: mandau.services.v1.DeleteVirtualHostRequest
+	(*DeleteVirtualHostResponse)(nil),      // 7: mandau.services.v1.DeleteVirtualHostResponse
+	(*ListVirtualHostsRequest)(nil),        // 8: mandau.services.v1.ListVirtualHostsRequest
+	(*ListVirtualHostsResponse)(nil),       // 9: mandau.services.v1.ListVirtualHostsResponse
+	(*Location)(nil),                       // 10: mandau.services.v1.Location
+	(*SSLConfig)(nil),                      // 11: mandau.services.v1.SSLConfig
+	(*CreateReverseProxyRequest)(nil),      // 12: mandau.services.v1.CreateReverseProxyRequest
+	(*CreateReverseProxyResponse)(nil),     // 13: mandau.services.v1.CreateReverseProxyResponse
+	(*CreateLoadBalancerRequest)(nil),      // 14: mandau.services.v1.CreateLoadBalancerRequest
+	(*CreateLoadBalancerResponse)(nil),     // 15: mandau.services.v1.CreateLoadBalancerResponse
+	(*CreateServiceRequest)(nil),           // 16: mandau.services.v1.CreateServiceRequest
+	(*CreateServiceResponse)(nil),          // 17: mandau.services.v1.CreateServiceResponse
+	(*EnableServiceRequest)(nil),           // 18: mandau.services.v1.EnableServiceRequest
+	(*EnableServiceResponse)(nil),          // 19: mandau.services.v1.EnableServiceResponse
+	(*DisableServiceRequest)(nil),          // 20: mandau.services.v1.DisableServiceRequest
+	(*DisableServiceResponse)(nil),         // 21: mandau.services.v1.DisableServiceResponse
+	(*StartServiceRequest)(nil),            // 22: mandau.services.v1.StartServiceRequest
+	(*StartServiceResponse)(nil),           // 23: mandau.services.v1.StartServiceResponse
+	(*StopServiceRequest)(nil),             // 24: mandau.services.v1.StopServiceRequest
+	(*StopServiceResponse)(nil),            // 25: mandau.services.v1.StopServiceResponse
+	(*RestartServiceRequest)(nil),          // 26: mandau.services.v1.RestartServiceRequest
+	(*RestartServiceResponse)(nil),         // 27: mandau.services.v1.RestartServiceResponse
+	(*GetServiceStatusRequest)(nil),        // 28: mandau.services.v1.GetServiceStatusRequest
+	(*GetServiceStatusResponse)(nil),       // 29: mandau.services.v1.GetServiceStatusResponse
+	(*ListServicesRequest)(nil),            // 30: mandau.services.v1.ListServicesRequest
+	(*ListServicesResponse)(nil),           // 31: mandau.services.v1.ListServicesResponse
+	(*ListInstancesRequest)(nil),           // 32: mandau.services.v1.ListInstancesRequest
+	(*ServiceInstance)(nil),                // 33: mandau.services.v1.ServiceInstance
+	(*ListInstancesResponse)(nil),          // 34: mandau.services.v1.ListInstancesResponse
+	(*SetInstanceEnvironmentRequest)(nil),  // 35: mandau.services.v1.SetInstanceEnvironmentRequest
+	(*SetInstanceEnvironmentResponse)(nil), // 36: mandau.services.v1.SetInstanceEnvironmentResponse
+	(*ScaleInstancesRequest)(nil),          // 37: mandau.services.v1.ScaleInstancesRequest
+	(*ScaleInstancesResponse)(nil),         // 38: mandau.services.v1.ScaleInstancesResponse
+	(*AddFirewallRuleRequest)(nil),         // 39: mandau.services.v1.AddFirewallRuleRequest
+	(*AddFirewallRuleResponse)(nil),        // 40: mandau.services.v1.AddFirewallRuleResponse
+	(*DeleteFirewallRuleRequest)(nil),      // 41: mandau.services.v1.DeleteFirewallRuleRequest
+	(*DeleteFirewallRuleResponse)(nil),     // 42: mandau.services.v1.DeleteFirewallRuleResponse
+	(*ListFirewallRulesRequest)(nil),       // 43: mandau.services.v1.ListFirewallRulesRequest
+	(*ListFirewallRulesResponse)(nil),      // 44: mandau.services.v1.ListFirewallRulesResponse
+	(*AllowPortRequest)(nil),               // 45: mandau.services.v1.AllowPortRequest
+	(*AllowPortResponse)(nil),              // 46: mandau.services.v1.AllowPortResponse
+	(*DenyPortRequest)(nil),                // 47: mandau.services.v1.DenyPortRequest
+	(*DenyPortResponse)(nil),               // 48: mandau.services.v1.DenyPortResponse
+	(*EnableFirewallRequest)(nil),          // 49: mandau.services.v1.EnableFirewallRequest
+	(*EnableFirewallResponse)(nil),         // 50: mandau.services.v1.EnableFirewallResponse
+	(*DisableFirewallRequest)(nil),         // 51: mandau.services.v1.DisableFirewallRequest
+	(*DisableFirewallResponse)(nil),        // 52: mandau.services.v1.DisableFirewallResponse
+	(*RemoveFirewallRuleSetRequest)(nil),   // 53: mandau.services.v1.RemoveFirewallRuleSetRequest
+	(*RemoveFirewallRuleSetResponse)(nil),  // 54: mandau.services.v1.RemoveFirewallRuleSetResponse
+	(*ObtainCertificateRequest)(nil),       // 55: mandau.services.v1.ObtainCertificateRequest
+	(*ObtainCertificateResponse)(nil),      // 56: mandau.services.v1.ObtainCertificateResponse
+	(*RenewCertificateRequest)(nil),        // 57: mandau.services.v1.RenewCertificateRequest
+	(*RenewCertificateResponse)(nil),       // 58: mandau.services.v1.RenewCertificateResponse
+	(*RenewAllCertificatesRequest)(nil),    // 59: mandau.services.v1.RenewAllCertificatesRequest
+	(*RenewAllCertificatesResponse)(nil),   // 60: mandau.services.v1.RenewAllCertificatesResponse
+	(*RevokeCertificateRequest)(nil),       // 61: mandau.services.v1.RevokeCertificateRequest
+	(*RevokeCertificateResponse)(nil),      // 62: mandau.services.v1.RevokeCertificateResponse
+	(*ListCertificatesRequest)(nil),        // 63: mandau.services.v1.ListCertificatesRequest
+	(*ListCertificatesResponse)(nil),       // 64: mandau.services.v1.ListCertificatesResponse
+	(*Certificate)(nil),                    // 65: mandau.services.v1.Certificate
+	(*ListSysctlProfilesRequest)(nil),      // 66: mandau.services.v1.ListSysctlProfilesRequest
+	(*ListSysctlProfilesResponse)(nil),     // 67: mandau.services.v1.ListSysctlProfilesResponse
+	(*SysctlProfile)(nil),                  // 68: mandau.services.v1.SysctlProfile
+	(*ApplySysctlProfileRequest)(nil),      // 69: mandau.services.v1.ApplySysctlProfileRequest
+	(*ApplySysctlProfileResponse)(nil),     // 70: mandau.services.v1.ApplySysctlProfileResponse
+	(*VerifySysctlsRequest)(nil),           // 71: mandau.services.v1.VerifySysctlsRequest
+	(*VerifySysctlsResponse)(nil),          // 72: mandau.services.v1.VerifySysctlsResponse
+	(*SysctlCheck)(nil),                    // 73: mandau.services.v1.SysctlCheck
+	(*Limit)(nil),                          // 74: mandau.services.v1.Limit
+	(*GetLimitsRequest)(nil),               // 75: mandau.services.v1.GetLimitsRequest
+	(*GetLimitsResponse)(nil),              // 76: mandau.services.v1.GetLimitsResponse
+	(*SetLimitsRequest)(nil),               // 77: mandau.services.v1.SetLimitsRequest
+	(*SetLimitsResponse)(nil),              // 78: mandau.services.v1.SetLimitsResponse
+	(*DockerDaemonSettings)(nil),           // 79: mandau.services.v1.DockerDaemonSettings
+	(*DockerAddressPool)(nil),              // 80: mandau.services.v1.DockerAddressPool
+	(*GetDockerDaemonRequest)(nil),         // 81: mandau.services.v1.GetDockerDaemonRequest
+	(*GetDockerDaemonResponse)(nil),        // 82: mandau.services.v1.GetDockerDaemonResponse
+	(*SetDockerDaemonRequest)(nil),         // 83: mandau.services.v1.SetDockerDaemonRequest
+	(*SetDockerDaemonResponse)(nil),        // 84: mandau.services.v1.SetDockerDaemonResponse
+	(*GetUserRequest)(nil),                 // 85: mandau.services.v1.GetUserRequest
+	(*GetUserResponse)(nil),                // 86: mandau.services.v1.GetUserResponse
+	(*EnsureUserRequest)(nil),              // 87: mandau.services.v1.EnsureUserRequest
+	(*EnsureUserResponse)(nil),             // 88: mandau.services.v1.EnsureUserResponse
+	(*GetPatchStatusRequest)(nil),          // 89: mandau.services.v1.GetPatchStatusRequest
+	(*GetTimeSyncRequest)(nil),             // 90: mandau.services.v1.GetTimeSyncRequest
+	(*GetTimeSyncResponse)(nil),            // 91: mandau.services.v1.GetTimeSyncResponse
+	(*ConfigureTimeSyncRequest)(nil),       // 92: mandau.services.v1.ConfigureTimeSyncRequest
+	(*ConfigureTimeSyncResponse)(nil),      // 93: mandau.services.v1.ConfigureTimeSyncResponse
+	(*GetHostInfoRequest)(nil),             // 94: mandau.services.v1.GetHostInfoRequest
+	(*GetHostInfoResponse)(nil),            // 95: mandau.services.v1.GetHostInfoResponse
+	(*InstallPackageRequest)(nil),          // 96: mandau.services.v1.InstallPackageRequest
+	(*InstallPackageResponse)(nil),         // 97: mandau.services.v1.InstallPackageResponse
+	(*RemovePackageRequest)(nil),           // 98: mandau.services.v1.RemovePackageRequest
+	(*RemovePackageResponse)(nil),          // 99: mandau.services.v1.RemovePackageResponse
+	(*UpdatePackagesRequest)(nil),          // 100: mandau.services.v1.UpdatePackagesRequest
+	(*UpdatePackagesResponse)(nil),         // 101: mandau.services.v1.UpdatePackagesResponse
+	(*ListPackagesRequest)(nil),            // 102: mandau.services.v1.ListPackagesRequest
+	(*ListPackagesResponse)(nil),           // 103: mandau.services.v1.ListPackagesResponse
+	(*SetSysctlRequest)(nil),               // 104: mandau.services.v1.SetSysctlRequest
+	(*SetSysctlResponse)(nil),              // 105: mandau.services.v1.SetSysctlResponse
+	(*GetSysctlRequest)(nil),               // 106: mandau.services.v1.GetSysctlRequest
+	(*GetSysctlResponse)(nil),              // 107: mandau.services.v1.GetSysctlResponse
+	(*CronJob)(nil),                        // 108: mandau.services.v1.CronJob
+	(*AddCronJobRequest)(nil),              // 109: mandau.services.v1.AddCronJobRequest
+	(*AddCronJobResponse)(nil),             // 110: mandau.services.v1.AddCronJobResponse
+	(*RemoveCronJobRequest)(nil),           // 111: mandau.services.v1.RemoveCronJobRequest
+	(*RemoveCronJobResponse)(nil),          // 112: mandau.services.v1.RemoveCronJobResponse
+	(*ListCronJobsRequest)(nil),            // 113: mandau.services.v1.ListCronJobsRequest
+	(*ListCronJobsResponse)(nil),           // 114: mandau.services.v1.ListCronJobsResponse
+	(*CreateZoneRequest)(nil),              // 115: mandau.services.v1.CreateZoneRequest
+	(*CreateZoneResponse)(nil),             // 116: mandau.services.v1.CreateZoneResponse
+	(*AddARecordRequest)(nil),              // 117: mandau.services.v1.AddARecordRequest
+	(*AddARecordResponse)(nil),             // 118: mandau.services.v1.AddARecordResponse
+	(*AddCNAMERecordRequest)(nil),          // 119: mandau.services.v1.AddCNAMERecordRequest
+	(*AddCNAMERecordResponse)(nil),         // 120: mandau.services.v1.AddCNAMERecordResponse
+	(*ListZonesRequest)(nil),               // 121: mandau.services.v1.ListZonesRequest
+	(*ListZonesResponse)(nil),              // 122: mandau.services.v1.ListZonesResponse
+	(*ServiceOperationEvent)(nil),          // 123: mandau.services.v1.ServiceOperationEvent
+	(*DeployWebServiceRequest)(nil),        // 124: mandau.services.v1.DeployWebServiceRequest
+	(*RemoveWebServiceRequest)(nil),        // 125: mandau.services.v1.RemoveWebServiceRequest
+	(*ListDeployedServicesRequest)(nil),    // 126: mandau.services.v1.ListDeployedServicesRequest
+	(*ListDeployedServicesResponse)(nil),   // 127: mandau.services.v1.ListDeployedServicesResponse
+	(*DeployedService)(nil),                // 128: mandau.services.v1.DeployedService
+	(*DeployedResource)(nil),               // 129: mandau.services.v1.DeployedResource
+	(*DeployStaticSiteRequest)(nil),        // 130: mandau.services.v1.DeployStaticSiteRequest
+	(*DeployDatabaseRequest)(nil),          // 131: mandau.services.v1.DeployDatabaseRequest
+	(*DeployWorkerRequest)(nil),            // 132: mandau.services.v1.DeployWorkerRequest
+	(*GetDriftReportRequest)(nil),          // 133: mandau.services.v1.GetDriftReportRequest
+	(*DriftReport)(nil),                    // 134: mandau.services.v1.DriftReport
+	(*HostDrift)(nil),                      // 135: mandau.services.v1.HostDrift
+	(*ListListeningPortsRequest)(nil),      // 136: mandau.services.v1.ListListeningPortsRequest
+	(*ListeningPort)(nil),                  // 137: mandau.services.v1.ListeningPort
+	(*ListListeningPortsResponse)(nil),     // 138: mandau.services.v1.ListListeningPortsResponse
+	(*ListProcessesRequest)(nil),           // 139: mandau.services.v1.ListProcessesRequest
+	(*HostProcess)(nil),                    // 140: mandau.services.v1.HostProcess
+	(*ListProcessesResponse)(nil),          // 141: mandau.services.v1.ListProcessesResponse
+	(*SignalProcessRequest)(nil),           // 142: mandau.services.v1.SignalProcessRequest
+	(*SignalProcessResponse)(nil),          // 143: mandau.services.v1.SignalProcessResponse
+	(*ListLogFilesRequest)(nil),            // 144: mandau.services.v1.ListLogFilesRequest
+	(*LogFile)(nil),                        // 145: mandau.services.v1.LogFile
+	(*ListLogFilesResponse)(nil),           // 146: mandau.services.v1.ListLogFilesResponse
+	(*TailLogFileRequest)(nil),             // 147: mandau.services.v1.TailLogFileRequest
+	(*RebootHostRequest)(nil),              // 148: mandau.services.v1.RebootHostRequest
+	(*GetLogShippingRequest)(nil),          // 149: mandau.services.v1.GetLogShippingRequest
+	(*LogShipping)(nil),                    // 150: mandau.services.v1.LogShipping
+	(*ShippedStream)(nil),                  // 151: mandau.services.v1.ShippedStream
+	(*ListCommandsRequest)(nil),            // 152: mandau.services.v1.ListCommandsRequest
+	(*AllowedCommand)(nil),                 // 153: mandau.services.v1.AllowedCommand
+	(*ListCommandsResponse)(nil),           // 154: mandau.services.v1.ListCommandsResponse
+	(*RunCommandRequest)(nil),              // 155: mandau.services.v1.RunCommandRequest
+	nil,                                    // 156: mandau.services.v1.Location.HeadersEntry
+	nil,                                    // 157: mandau.services.v1.CreateServiceRequest.EnvironmentEntry
+	nil,                                    // 158: mandau.services.v1.ServiceInstance.EnvironmentEntry
+	nil,                                    // 159: mandau.services.v1.SetInstanceEnvironmentRequest.EnvironmentEntry
+	nil,                                    // 160: mandau.services.v1.SysctlProfile.SettingsEntry
+	nil,                                    // 161: mandau.services.v1.DockerDaemonSettings.LogOptsEntry
+	nil,                                    // 162: mandau.services.v1.DeployWebServiceRequest.EnvironmentEntry
+	nil,                                    // 163: mandau.services.v1.DeployWorkerRequest.EnvironmentEntry
+	(*timestamppb.Timestamp)(nil),          // 164: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),            // 165: google.protobuf.Duration
+	(*PatchStatus)(nil),                    // 166: mandau.agent.v1.PatchStatus
+	(*LogEntry)(nil),                       // 167: mandau.agent.v1.LogEntry
+	(*OperationEvent)(nil),                 // 168: mandau.agent.v1.OperationEvent
+	(*CommandResult)(nil),                  // 169: mandau.agent.v1.CommandResult
 }
 var file_api_v1_service_proto_depIdxs = []int32{
-	10, // 0: mandau.services.v1.CreateVirtualHostRequest.locations:type_name -> mandau.services.v1.Location
-	11, // 1: mandau.services.v1.CreateVirtualHostRequest.ssl:type_name -> mandau.services.v1.SSLConfig
-	74, // 2: mandau.services.v1.Location.headers:type_name -> mandau.services.v1.Location.HeadersEntry
-	75, // 3: mandau.services.v1.CreateServiceRequest.environment:type_name -> mandau.services.v1.CreateServiceRequest.EnvironmentEntry
-	56, // 4: mandau.services.v1.ObtainCertificateResponse.certificate:type_name -> mandau.services.v1.Certificate
-	56, // 5: mandau.services.v1.ListCertificatesResponse.certificates:type_name -> mandau.services.v1.Certificate
-	77, // 6: mandau.services.v1.ServiceOperationEvent.timestamp:type_name -> google.protobuf.Timestamp
-	76, // 7: mandau.services.v1.DeployWebServiceRequest.environment:type_name -> mandau.services.v1.DeployWebServiceRequest.EnvironmentEntry
-	0,  // 8: mandau.services.v1.NginxService.CreateVirtualHost:input_type -> mandau.services.v1.CreateVirtualHostRequest
-	2,  // 9: mandau.services.v1.NginxService.EnableVirtualHost:input_type -> mandau.services.v1.EnableVirtualHostRequest
-	4,  // 10: mandau.services.v1.NginxService.DisableVirtualHost:input_type -> mandau.services.v1.DisableVirtualHostRequest
-	6,  // 11: mandau.services.v1.NginxService.DeleteVirtualHost:input_type -> mandau.services.v1.DeleteVirtualHostRequest
-	8,  // 12: mandau.services.v1.NginxService.ListVirtualHosts:input_type -> mandau.services.v1.ListVirtualHostsRequest
-	12, // 13: mandau.services.v1.NginxService.CreateReverseProxy:input_type -> mandau.services.v1.CreateReverseProxyRequest
-	14, // 14: mandau.services.v1.NginxService.CreateLoadBalancer:input_type -> mandau.services.v1.CreateLoadBalancerRequest
-	16, // 15: mandau.services.v1.SystemdService.CreateService:input_type -> mandau.services.v1.CreateServiceRequest
-	18, // 16: mandau.services.v1.SystemdService.EnableService:input_type -> mandau.services.v1.EnableServiceRequest
-	20, // 17: mandau.services.v1.SystemdService.DisableService:input_type -> mandau.services.v1.DisableServiceRequest
-	22, // 18: mandau.services.v1.SystemdService.StartService:input_type -> mandau.services.v1.StartServiceRequest
-	24, // 19: mandau.services.v1.SystemdService.StopService:input_type -> mandau.services.v1.StopServiceRequest
-	26, // 20: mandau.services.v1.SystemdService.RestartService:input_type -> mandau.services.v1.RestartServiceRequest
-	28, // 21: mandau.services.v1.SystemdService.GetServiceStatus:input_type -> mandau.services.v1.GetServiceStatusRequest
-	30, // 22: mandau.services.v1.SystemdService.ListServices:input_type -> mandau.services.v1.ListServicesRequest
-	32, // 23: mandau.services.v1.FirewallService.AddRule:input_type -> mandau.services.v1.AddFirewallRuleRequest
-	34, // 24: mandau.services.v1.FirewallService.DeleteRule:input_type -> mandau.services.v1.DeleteFirewallRuleRequest
-	36, // 25: mandau.services.v1.FirewallService.ListRules:input_type -> mandau.services.v1.ListFirewallRulesRequest
-	38, // 26: mandau.services.v1.FirewallService.AllowPort:input_type -> mandau.services.v1.AllowPortRequest
-	40, // 27: mandau.services.v1.FirewallService.DenyPort:input_type -> mandau.services.v1.DenyPortRequest
-	42, // 28: mandau.services.v1.FirewallService.Enable:input_type -> mandau.services.v1.EnableFirewallRequest
-	44, // 29: mandau.services.v1.FirewallService.Disable:input_type -> mandau.services.v1.DisableFirewallRequest
-	46, // 30: mandau.services.v1.ACMEService.ObtainCertificate:input_type -> mandau.services.v1.ObtainCertificateRequest
-	48, // 31: mandau.services.v1.ACMEService.RenewCertificate:input_type -> mandau.services.v1.RenewCertificateRequest
-	50, // 32: mandau.services.v1.ACMEService.RenewAll:input_type -> mandau.services.v1.RenewAllCertificatesRequest
-	52, // 33: mandau.services.v1.ACMEService.RevokeCertificate:input_type -> mandau.services.v1.RevokeCertificateRequest
-	54, // 34: mandau.services.v1.ACMEService.ListCertificates:input_type -> mandau.services.v1.ListCertificatesRequest
-	57, // 35: mandau.services.v1.HostEnvironmentService.GetHostInfo:input_type -> mandau.services.v1.GetHostInfoRequest
-	59, // 36: mandau.services.v1.HostEnvironmentService.InstallPackage:input_type -> mandau.services.v1.InstallPackageRequest
-	61, // 37: mandau.services.v1.HostEnvironmentService.RemovePackage:input_type -> mandau.services.v1.RemovePackageRequest
-	63, // 38: mandau.services.v1.HostEnvironmentService.UpdatePackages:input_type -> mandau.services.v1.UpdatePackagesRequest
-	65, // 39: mandau.services.v1.HostEnvironmentService.ListPackages:input_type -> mandau.services.v1.ListPackagesRequest
-	67, // 40: mandau.services.v1.HostEnvironmentService.SetSysctl:input_type -> mandau.services.v1.SetSysctlRequest
-	69, // 41: mandau.services.v1.HostEnvironmentService.GetSysctl:input_type -> mandau.services.v1.GetSysctlRequest
-	72, // 42: mandau.services.v1.ServiceDeploymentService.DeployWebService:input_type -> mandau.services.v1.DeployWebServiceRequest
-	73, // 43: mandau.services.v1.ServiceDeploymentService.RemoveWebService:input_type -> mandau.services.v1.RemoveWebServiceRequest
-	1,  // 44: mandau.services.v1.NginxService.CreateVirtualHost:output_type -> mandau.services.v1.CreateVirtualHostResponse
-	3,  // 45: mandau.services.v1.NginxService.EnableVirtualHost:output_type -> mandau.services.v1.EnableVirtualHostResponse
-	5,  // 46: mandau.services.v1.NginxService.DisableVirtualHost:output_type -> mandau.services.v1.DisableVirtualHostResponse
-	7,  // 47: mandau.services.v1.NginxService.DeleteVirtualHost:output_type -> mandau.services.v1.DeleteVirtualHostResponse
-	9,  // 48: mandau.services.v1.NginxService.ListVirtualHosts:output_type -> mandau.services.v1.ListVirtualHostsResponse
-	13, // 49: mandau.services.v1.NginxService.CreateReverseProxy:output_type -> mandau.services.v1.CreateReverseProxyResponse
-	15, // 50: mandau.services.v1.NginxService.CreateLoadBalancer:output_type -> mandau.services.v1.CreateLoadBalancerResponse
-	17, // 51: mandau.services.v1.SystemdService.CreateService:output_type -> mandau.services.v1.CreateServiceResponse
-	19, // 52: mandau.services.v1.SystemdService.EnableService:output_type -> mandau.services.v1.EnableServiceResponse
-	21, // 53: mandau.services.v1.SystemdService.DisableService:output_type -> mandau.services.v1.DisableServiceResponse
-	23, // 54: mandau.services.v1.SystemdService.StartService:output_type -> mandau.services.v1.StartServiceResponse
-	25, // 55: mandau.services.v1.SystemdService.StopService:output_type -> mandau.services.v1.StopServiceResponse
-	27, // 56: mandau.services.v1.SystemdService.RestartService:output_type -> mandau.services.v1.RestartServiceResponse
-	29, // 57: mandau.services.v1.SystemdService.GetServiceStatus:output_type -> mandau.services.v1.GetServiceStatusResponse
-	31, // 58: mandau.services.v1.SystemdService.ListServices:output_type -> mandau.services.v1.ListServicesResponse
-	33, // 59: mandau.services.v1.FirewallService.AddRule:output_type -> mandau.services.v1.AddFirewallRuleResponse
-	35, // 60: mandau.services.v1.FirewallService.DeleteRule:output_type -> mandau.services.v1.DeleteFirewallRuleResponse
-	37, // 61: mandau.services.v1.FirewallService.ListRules:output_type -> mandau.services.v1.ListFirewallRulesResponse
-	39, // 62: mandau.services.v1.FirewallService.AllowPort:output_type -> mandau.services.v1.AllowPortResponse
-	41, // 63: mandau.services.v1.FirewallService.DenyPort:output_type -> mandau.services.v1.DenyPortResponse
-	43, // 64: mandau.services.v1.FirewallService.Enable:output_type -> mandau.services.v1.EnableFirewallResponse
-	45, // 65: mandau.services.v1.FirewallService.Disable:output_type -> mandau.services.v1.DisableFirewallResponse
-	47, // 66: mandau.services.v1.ACMEService.ObtainCertificate:output_type -> mandau.services.v1.ObtainCertificateResponse
-	49, // 67: mandau.services.v1.ACMEService.RenewCertificate:output_type -> mandau.services.v1.RenewCertificateResponse
-	51, // 68: mandau.services.v1.ACMEService.RenewAll:output_type -> mandau.services.v1.RenewAllCertificatesResponse
-	53, // 69: mandau.services.v1.ACMEService.RevokeCertificate:output_type -> mandau.services.v1.RevokeCertificateResponse
-	55, // 70: mandau.services.v1.ACMEService.ListCertificates:output_type -> mandau.services.v1.ListCertificatesResponse
-	58, // 71: mandau.services.v1.HostEnvironmentService.GetHostInfo:output_type -> mandau.services.v1.GetHostInfoResponse
-	60, // 72: mandau.services.v1.HostEnvironmentService.InstallPackage:output_type -> mandau.services.v1.InstallPackageResponse
-	62, // 73: mandau.services.v1.HostEnvironmentService.RemovePackage:output_type -> mandau.services.v1.RemovePackageResponse
-	64, // 74: mandau.services.v1.HostEnvironmentService.UpdatePackages:output_type -> mandau.services.v1.UpdatePackagesResponse
-	66, // 75: mandau.services.v1.HostEnvironmentService.ListPackages:output_type -> mandau.services.v1.ListPackagesResponse
-	68, // 76: mandau.services.v1.HostEnvironmentService.SetSysctl:output_type -> mandau.services.v1.SetSysctlResponse
-	70, // 77: mandau.services.v1.HostEnvironmentService.GetSysctl:output_type -> mandau.services.v1.GetSysctlResponse
-	71, // 78: mandau.services.v1.ServiceDeploymentService.DeployWebService:output_type -> mandau.services.v1.ServiceOperationEvent
-	71, // 79: mandau.services.v1.ServiceDeploymentService.RemoveWebService:output_type -> mandau.services.v1.ServiceOperationEvent
-	44, // [44:80] is the sub-list for method output_type
-	8,  // [8:44] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	10,  // 0: mandau.services.v1.CreateVirtualHostRequest.locations:type_name -> mandau.services.v1.Location
+	11,  // 1: mandau.services.v1.CreateVirtualHostRequest.ssl:type_name -> mandau.services.v1.SSLConfig
+	156, // 2: mandau.services.v1.Location.headers:type_name -> mandau.services.v1.Location.HeadersEntry
+	157, // 3: mandau.services.v1.CreateServiceRequest.environment:type_name -> mandau.services.v1.CreateServiceRequest.EnvironmentEntry
+	158, // 4: mandau.services.v1.ServiceInstance.environment:type_name -> mandau.services.v1.ServiceInstance.EnvironmentEntry
+	33,  // 5: mandau.services.v1.ListInstancesResponse.instances:type_name -> mandau.services.v1.ServiceInstance
+	159, // 6: mandau.services.v1.SetInstanceEnvironmentRequest.environment:type_name -> mandau.services.v1.SetInstanceEnvironmentRequest.EnvironmentEntry
+	65,  // 7: mandau.services.v1.ObtainCertificateResponse.certificate:type_name -> mandau.services.v1.Certificate
+	65,  // 8: mandau.services.v1.ListCertificatesResponse.certificates:type_name -> mandau.services.v1.Certificate
+	68,  // 9: mandau.services.v1.ListSysctlProfilesResponse.profiles:type_name -> mandau.services.v1.SysctlProfile
+	160, // 10: mandau.services.v1.SysctlProfile.settings:type_name -> mandau.services.v1.SysctlProfile.SettingsEntry
+	73,  // 11: mandau.services.v1.VerifySysctlsResponse.checks:type_name -> mandau.services.v1.SysctlCheck
+	74,  // 12: mandau.services.v1.GetLimitsResponse.limits:type_name -> mandau.services.v1.Limit
+	74,  // 13: mandau.services.v1.SetLimitsRequest.limits:type_name -> mandau.services.v1.Limit
+	161, // 14: mandau.services.v1.DockerDaemonSettings.log_opts:type_name -> mandau.services.v1.DockerDaemonSettings.LogOptsEntry
+	80,  // 15: mandau.services.v1.DockerDaemonSettings.default_address_pools:type_name -> mandau.services.v1.DockerAddressPool
+	79,  // 16: mandau.services.v1.GetDockerDaemonResponse.settings:type_name -> mandau.services.v1.DockerDaemonSettings
+	79,  // 17: mandau.services.v1.SetDockerDaemonRequest.settings:type_name -> mandau.services.v1.DockerDaemonSettings
+	108, // 18: mandau.services.v1.AddCronJobRequest.job:type_name -> mandau.services.v1.CronJob
+	108, // 19: mandau.services.v1.ListCronJobsResponse.jobs:type_name -> mandau.services.v1.CronJob
+	164, // 20: mandau.services.v1.ServiceOperationEvent.timestamp:type_name -> google.protobuf.Timestamp
+	162, // 21: mandau.services.v1.DeployWebServiceRequest.environment:type_name -> mandau.services.v1.DeployWebServiceRequest.EnvironmentEntry
+	128, // 22: mandau.services.v1.ListDeployedServicesResponse.services:type_name -> mandau.services.v1.DeployedService
+	164, // 23: mandau.services.v1.DeployedService.deployed_at:type_name -> google.protobuf.Timestamp
+	129, // 24: mandau.services.v1.DeployedService.resources:type_name -> mandau.services.v1.DeployedResource
+	163, // 25: mandau.services.v1.DeployWorkerRequest.environment:type_name -> mandau.services.v1.DeployWorkerRequest.EnvironmentEntry
+	164, // 26: mandau.services.v1.DriftReport.scanned_at:type_name -> google.protobuf.Timestamp
+	135, // 27: mandau.services.v1.DriftReport.drift:type_name -> mandau.services.v1.HostDrift
+	137, // 28: mandau.services.v1.ListListeningPortsResponse.ports:type_name -> mandau.services.v1.ListeningPort
+	140, // 29: mandau.services.v1.ListProcessesResponse.processes:type_name -> mandau.services.v1.HostProcess
+	164, // 30: mandau.services.v1.LogFile.modified:type_name -> google.protobuf.Timestamp
+	145, // 31: mandau.services.v1.ListLogFilesResponse.files:type_name -> mandau.services.v1.LogFile
+	164, // 32: mandau.services.v1.TailLogFileRequest.since:type_name -> google.protobuf.Timestamp
+	164, // 33: mandau.services.v1.LogShipping.last_shipped:type_name -> google.protobuf.Timestamp
+	164, // 34: mandau.services.v1.LogShipping.last_error_at:type_name -> google.protobuf.Timestamp
+	151, // 35: mandau.services.v1.LogShipping.streams:type_name -> mandau.services.v1.ShippedStream
+	165, // 36: mandau.services.v1.AllowedCommand.timeout:type_name -> google.protobuf.Duration
+	153, // 37: mandau.services.v1.ListCommandsResponse.commands:type_name -> mandau.services.v1.AllowedCommand
+	165, // 38: mandau.services.v1.RunCommandRequest.timeout:type_name -> google.protobuf.Duration
+	0,   // 39: mandau.services.v1.NginxService.CreateVirtualHost:input_type -> mandau.services.v1.CreateVirtualHostRequest
+	2,   // 40: mandau.services.v1.NginxService.EnableVirtualHost:input_type -> mandau.services.v1.EnableVirtualHostRequest
+	4,   // 41: mandau.services.v1.NginxService.DisableVirtualHost:input_type -> mandau.services.v1.DisableVirtualHostRequest
+	6,   // 42: mandau.services.v1.NginxService.DeleteVirtualHost:input_type -> mandau.services.v1.DeleteVirtualHostRequest
+	8,   // 43: mandau.services.v1.NginxService.ListVirtualHosts:input_type -> mandau.services.v1.ListVirtualHostsRequest
+	12,  // 44: mandau.services.v1.NginxService.CreateReverseProxy:input_type -> mandau.services.v1.CreateReverseProxyRequest
+	14,  // 45: mandau.services.v1.NginxService.CreateLoadBalancer:input_type -> mandau.services.v1.CreateLoadBalancerRequest
+	16,  // 46: mandau.services.v1.SystemdService.CreateService:input_type -> mandau.services.v1.CreateServiceRequest
+	18,  // 47: mandau.services.v1.SystemdService.EnableService:input_type -> mandau.services.v1.EnableServiceRequest
+	20,  // 48: mandau.services.v1.SystemdService.DisableService:input_type -> mandau.services.v1.DisableServiceRequest
+	22,  // 49: mandau.services.v1.SystemdService.StartService:input_type -> mandau.services.v1.StartServiceRequest
+	24,  // 50: mandau.services.v1.SystemdService.StopService:input_type -> mandau.services.v1.StopServiceRequest
+	26,  // 51: mandau.services.v1.SystemdService.RestartService:input_type -> mandau.services.v1.RestartServiceRequest
+	28,  // 52: mandau.services.v1.SystemdService.GetServiceStatus:input_type -> mandau.services.v1.GetServiceStatusRequest
+	30,  // 53: mandau.services.v1.SystemdService.ListServices:input_type -> mandau.services.v1.ListServicesRequest
+	32,  // 54: mandau.services.v1.SystemdService.ListInstances:input_type -> mandau.services.v1.ListInstancesRequest
+	35,  // 55: mandau.services.v1.SystemdService.SetInstanceEnvironment:input_type -> mandau.services.v1.SetInstanceEnvironmentRequest
+	37,  // 56: mandau.services.v1.SystemdService.ScaleInstances:input_type -> mandau.services.v1.ScaleInstancesRequest
+	39,  // 57: mandau.services.v1.FirewallService.AddRule:input_type -> mandau.services.v1.AddFirewallRuleRequest
+	41,  // 58: mandau.services.v1.FirewallService.DeleteRule:input_type -> mandau.services.v1.DeleteFirewallRuleRequest
+	43,  // 59: mandau.services.v1.FirewallService.ListRules:input_type -> mandau.services.v1.ListFirewallRulesRequest
+	45,  // 60: mandau.services.v1.FirewallService.AllowPort:input_type -> mandau.services.v1.AllowPortRequest
+	47,  // 61: mandau.services.v1.FirewallService.DenyPort:input_type -> mandau.services.v1.DenyPortRequest
+	49,  // 62: mandau.services.v1.FirewallService.Enable:input_type -> mandau.services.v1.EnableFirewallRequest
+	51,  // 63: mandau.services.v1.FirewallService.Disable:input_type -> mandau.services.v1.DisableFirewallRequest
+	53,  // 64: mandau.services.v1.FirewallService.RemoveRuleSet:input_type -> mandau.services.v1.RemoveFirewallRuleSetRequest
+	55,  // 65: mandau.services.v1.ACMEService.ObtainCertificate:input_type -> mandau.services.v1.ObtainCertificateRequest
+	57,  // 66: mandau.services.v1.ACMEService.RenewCertificate:input_type -> mandau.services.v1.RenewCertificateRequest
+	59,  // 67: mandau.services.v1.ACMEService.RenewAll:input_type -> mandau.services.v1.RenewAllCertificatesRequest
+	61,  // 68: mandau.services.v1.ACMEService.RevokeCertificate:input_type -> mandau.services.v1.RevokeCertificateRequest
+	63,  // 69: mandau.services.v1.ACMEService.ListCertificates:input_type -> mandau.services.v1.ListCertificatesRequest
+	94,  // 70: mandau.services.v1.HostEnvironmentService.GetHostInfo:input_type -> mandau.services.v1.GetHostInfoRequest
+	96,  // 71: mandau.services.v1.HostEnvironmentService.InstallPackage:input_type -> mandau.services.v1.InstallPackageRequest
+	98,  // 72: mandau.services.v1.HostEnvironmentService.RemovePackage:input_type -> mandau.services.v1.RemovePackageRequest
+	100, // 73: mandau.services.v1.HostEnvironmentService.UpdatePackages:input_type -> mandau.services.v1.UpdatePackagesRequest
+	102, // 74: mandau.services.v1.HostEnvironmentService.ListPackages:input_type -> mandau.services.v1.ListPackagesRequest
+	104, // 75: mandau.services.v1.HostEnvironmentService.SetSysctl:input_type -> mandau.services.v1.SetSysctlRequest
+	106, // 76: mandau.services.v1.HostEnvironmentService.GetSysctl:input_type -> mandau.services.v1.GetSysctlRequest
+	90,  // 77: mandau.services.v1.HostEnvironmentService.GetTimeSync:input_type -> mandau.services.v1.GetTimeSyncRequest
+	92,  // 78: mandau.services.v1.HostEnvironmentService.ConfigureTimeSync:input_type -> mandau.services.v1.ConfigureTimeSyncRequest
+	89,  // 79: mandau.services.v1.HostEnvironmentService.GetPatchStatus:input_type -> mandau.services.v1.GetPatchStatusRequest
+	85,  // 80: mandau.services.v1.HostEnvironmentService.GetUser:input_type -> mandau.services.v1.GetUserRequest
+	87,  // 81: mandau.services.v1.HostEnvironmentService.EnsureUser:input_type -> mandau.services.v1.EnsureUserRequest
+	66,  // 82: mandau.services.v1.HostEnvironmentService.ListSysctlProfiles:input_type -> mandau.services.v1.ListSysctlProfilesRequest
+	69,  // 83: mandau.services.v1.HostEnvironmentService.ApplySysctlProfile:input_type -> mandau.services.v1.ApplySysctlProfileRequest
+	71,  // 84: mandau.services.v1.HostEnvironmentService.VerifySysctls:input_type -> mandau.services.v1.VerifySysctlsRequest
+	75,  // 85: mandau.services.v1.HostEnvironmentService.GetLimits:input_type -> mandau.services.v1.GetLimitsRequest
+	77,  // 86: mandau.services.v1.HostEnvironmentService.SetLimits:input_type -> mandau.services.v1.SetLimitsRequest
+	81,  // 87: mandau.services.v1.HostEnvironmentService.GetDockerDaemon:input_type -> mandau.services.v1.GetDockerDaemonRequest
+	83,  // 88: mandau.services.v1.HostEnvironmentService.SetDockerDaemon:input_type -> mandau.services.v1.SetDockerDaemonRequest
+	109, // 89: mandau.services.v1.CronService.AddCronJob:input_type -> mandau.services.v1.AddCronJobRequest
+	111, // 90: mandau.services.v1.CronService.RemoveCronJob:input_type -> mandau.services.v1.RemoveCronJobRequest
+	113, // 91: mandau.services.v1.CronService.ListCronJobs:input_type -> mandau.services.v1.ListCronJobsRequest
+	115, // 92: mandau.services.v1.DNSService.CreateZone:input_type -> mandau.services.v1.CreateZoneRequest
+	117, // 93: mandau.services.v1.DNSService.AddARecord:input_type -> mandau.services.v1.AddARecordRequest
+	119, // 94: mandau.services.v1.DNSService.AddCNAMERecord:input_type -> mandau.services.v1.AddCNAMERecordRequest
+	121, // 95: mandau.services.v1.DNSService.ListZones:input_type -> mandau.services.v1.ListZonesRequest
+	124, // 96: mandau.services.v1.ServiceDeploymentService.DeployWebService:input_type -> mandau.services.v1.DeployWebServiceRequest
+	125, // 97: mandau.services.v1.ServiceDeploymentService.RemoveWebService:input_type -> mandau.services.v1.RemoveWebServiceRequest
+	130, // 98: mandau.services.v1.ServiceDeploymentService.DeployStaticSite:input_type -> mandau.services.v1.DeployStaticSiteRequest
+	131, // 99: mandau.services.v1.ServiceDeploymentService.DeployDatabase:input_type -> mandau.services.v1.DeployDatabaseRequest
+	132, // 100: mandau.services.v1.ServiceDeploymentService.DeployWorker:input_type -> mandau.services.v1.DeployWorkerRequest
+	126, // 101: mandau.services.v1.ServiceDeploymentService.ListDeployedServices:input_type -> mandau.services.v1.ListDeployedServicesRequest
+	133, // 102: mandau.services.v1.DriftService.GetDriftReport:input_type -> mandau.services.v1.GetDriftReportRequest
+	136, // 103: mandau.services.v1.PortService.ListListeningPorts:input_type -> mandau.services.v1.ListListeningPortsRequest
+	139, // 104: mandau.services.v1.ProcessService.ListProcesses:input_type -> mandau.services.v1.ListProcessesRequest
+	142, // 105: mandau.services.v1.ProcessService.SignalProcess:input_type -> mandau.services.v1.SignalProcessRequest
+	144, // 106: mandau.services.v1.HostLogService.ListLogFiles:input_type -> mandau.services.v1.ListLogFilesRequest
+	147, // 107: mandau.services.v1.HostLogService.TailLogFile:input_type -> mandau.services.v1.TailLogFileRequest
+	148, // 108: mandau.services.v1.HostPowerService.RebootHost:input_type -> mandau.services.v1.RebootHostRequest
+	149, // 109: mandau.services.v1.LogShipService.GetLogShipping:input_type -> mandau.services.v1.GetLogShippingRequest
+	152, // 110: mandau.services.v1.CommandService.ListCommands:input_type -> mandau.services.v1.ListCommandsRequest
+	155, // 111: mandau.services.v1.CommandService.RunCommand:input_type -> mandau.services.v1.RunCommandRequest
+	1,   // 112: mandau.services.v1.NginxService.CreateVirtualHost:output_type -> mandau.services.v1.CreateVirtualHostResponse
+	3,   // 113: mandau.services.v1.NginxService.EnableVirtualHost:output_type -> mandau.services.v1.EnableVirtualHostResponse
+	5,   // 114: mandau.services.v1.NginxService.DisableVirtualHost:output_type -> mandau.services.v1.DisableVirtualHostResponse
+	7,   // 115: mandau.services.v1.NginxService.DeleteVirtualHost:output_type -> mandau.services.v1.DeleteVirtualHostResponse
+	9,   // 116: mandau.services.v1.NginxService.ListVirtualHosts:output_type -> mandau.services.v1.ListVirtualHostsResponse
+	13,  // 117: mandau.services.v1.NginxService.CreateReverseProxy:output_type -> mandau.services.v1.CreateReverseProxyResponse
+	15,  // 118: mandau.services.v1.NginxService.CreateLoadBalancer:output_type -> mandau.services.v1.CreateLoadBalancerResponse
+	17,  // 119: mandau.services.v1.SystemdService.CreateService:output_type -> mandau.services.v1.CreateServiceResponse
+	19,  // 120: mandau.services.v1.SystemdService.EnableService:output_type -> mandau.services.v1.EnableServiceResponse
+	21,  // 121: mandau.services.v1.SystemdService.DisableService:output_type -> mandau.services.v1.DisableServiceResponse
+	23,  // 122: mandau.services.v1.SystemdService.StartService:output_type -> mandau.services.v1.StartServiceResponse
+	25,  // 123: mandau.services.v1.SystemdService.StopService:output_type -> mandau.services.v1.StopServiceResponse
+	27,  // 124: mandau.services.v1.SystemdService.RestartService:output_type -> mandau.services.v1.RestartServiceResponse
+	29,  // 125: mandau.services.v1.SystemdService.GetServiceStatus:output_type -> mandau.services.v1.GetServiceStatusResponse
+	31,  // 126: mandau.services.v1.SystemdService.ListServices:output_type -> mandau.services.v1.ListServicesResponse
+	34,  // 127: mandau.services.v1.SystemdService.ListInstances:output_type -> mandau.services.v1.ListInstancesResponse
+	36,  // 128: mandau.services.v1.SystemdService.SetInstanceEnvironment:output_type -> mandau.services.v1.SetInstanceEnvironmentResponse
+	38,  // 129: mandau.services.v1.SystemdService.ScaleInstances:output_type -> mandau.services.v1.ScaleInstancesResponse
+	40,  // 130: mandau.services.v1.FirewallService.AddRule:output_type -> mandau.services.v1.AddFirewallRuleResponse
+	42,  // 131: mandau.services.v1.FirewallService.DeleteRule:output_type -> mandau.services.v1.DeleteFirewallRuleResponse
+	44,  // 132: mandau.services.v1.FirewallService.ListRules:output_type -> mandau.services.v1.ListFirewallRulesResponse
+	46,  // 133: mandau.services.v1.FirewallService.AllowPort:output_type -> mandau.services.v1.AllowPortResponse
+	48,  // 134: mandau.services.v1.FirewallService.DenyPort:output_type -> mandau.services.v1.DenyPortResponse
+	50,  // 135: mandau.services.v1.FirewallService.Enable:output_type -> mandau.services.v1.EnableFirewallResponse
+	52,  // 136: mandau.services.v1.FirewallService.Disable:output_type -> mandau.services.v1.DisableFirewallResponse
+	54,  // 137: mandau.services.v1.FirewallService.RemoveRuleSet:output_type -> mandau.services.v1.RemoveFirewallRuleSetResponse
+	56,  // 138: mandau.services.v1.ACMEService.ObtainCertificate:output_type -> mandau.services.v1.ObtainCertificateResponse
+	58,  // 139: mandau.services.v1.ACMEService.RenewCertificate:output_type -> mandau.services.v1.RenewCertificateResponse
+	60,  // 140: mandau.services.v1.ACMEService.RenewAll:output_type -> mandau.services.v1.RenewAllCertificatesResponse
+	62,  // 141: mandau.services.v1.ACMEService.RevokeCertificate:output_type -> mandau.services.v1.RevokeCertificateResponse
+	64,  // 142: mandau.services.v1.ACMEService.ListCertificates:output_type -> mandau.services.v1.ListCertificatesResponse
+	95,  // 143: mandau.services.v1.HostEnvironmentService.GetHostInfo:output_type -> mandau.services.v1.GetHostInfoResponse
+	97,  // 144: mandau.services.v1.HostEnvironmentService.InstallPackage:output_type -> mandau.services.v1.InstallPackageResponse
+	99,  // 145: mandau.services.v1.HostEnvironmentService.RemovePackage:output_type -> mandau.services.v1.RemovePackageResponse
+	101, // 146: mandau.services.v1.HostEnvironmentService.UpdatePackages:output_type -> mandau.services.v1.UpdatePackagesResponse
+	103, // 147: mandau.services.v1.HostEnvironmentService.ListPackages:output_type -> mandau.services.v1.ListPackagesResponse
+	105, // 148: mandau.services.v1.HostEnvironmentService.SetSysctl:output_type -> mandau.services.v1.SetSysctlResponse
+	107, // 149: mandau.services.v1.HostEnvironmentService.GetSysctl:output_type -> mandau.services.v1.GetSysctlResponse
+	91,  // 150: mandau.services.v1.HostEnvironmentService.GetTimeSync:output_type -> mandau.services.v1.GetTimeSyncResponse
+	93,  // 151: mandau.services.v1.HostEnvironmentService.ConfigureTimeSync:output_type -> mandau.services.v1.ConfigureTimeSyncResponse
+	166, // 152: mandau.services.v1.HostEnvironmentService.GetPatchStatus:output_type -> mandau.agent.v1.PatchStatus
+	86,  // 153: mandau.services.v1.HostEnvironmentService.GetUser:output_type -> mandau.services.v1.GetUserResponse
+	88,  // 154: mandau.services.v1.HostEnvironmentService.EnsureUser:output_type -> mandau.services.v1.EnsureUserResponse
+	67,  // 155: mandau.services.v1.HostEnvironmentService.ListSysctlProfiles:output_type -> mandau.services.v1.ListSysctlProfilesResponse
+	70,  // 156: mandau.services.v1.HostEnvironmentService.ApplySysctlProfile:output_type -> mandau.services.v1.ApplySysctlProfileResponse
+	72,  // 157: mandau.services.v1.HostEnvironmentService.VerifySysctls:output_type -> mandau.services.v1.VerifySysctlsResponse
+	76,  // 158: mandau.services.v1.HostEnvironmentService.GetLimits:output_type -> mandau.services.v1.GetLimitsResponse
+	78,  // 159: mandau.services.v1.HostEnvironmentService.SetLimits:output_type -> mandau.services.v1.SetLimitsResponse
+	82,  // 160: mandau.services.v1.HostEnvironmentService.GetDockerDaemon:output_type -> mandau.services.v1.GetDockerDaemonResponse
+	84,  // 161: mandau.services.v1.HostEnvironmentService.SetDockerDaemon:output_type -> mandau.services.v1.SetDockerDaemonResponse
+	110, // 162: mandau.services.v1.CronService.AddCronJob:output_type -> mandau.services.v1.AddCronJobResponse
+	112, // 163: mandau.services.v1.CronService.RemoveCronJob:output_type -> mandau.services.v1.RemoveCronJobResponse
+	114, // 164: mandau.services.v1.CronService.ListCronJobs:output_type -> mandau.services.v1.ListCronJobsResponse
+	116, // 165: mandau.services.v1.DNSService.CreateZone:output_type -> mandau.services.v1.CreateZoneResponse
+	118, // 166: mandau.services.v1.DNSService.AddARecord:output_type -> mandau.services.v1.AddARecordResponse
+	120, // 167: mandau.services.v1.DNSService.AddCNAMERecord:output_type -> mandau.services.v1.AddCNAMERecordResponse
+	122, // 168: mandau.services.v1.DNSService.ListZones:output_type -> mandau.services.v1.ListZonesResponse
+	123, // 169: mandau.services.v1.ServiceDeploymentService.DeployWebService:output_type -> mandau.services.v1.ServiceOperationEvent
+	123, // 170: mandau.services.v1.ServiceDeploymentService.RemoveWebService:output_type -> mandau.services.v1.ServiceOperationEvent
+	123, // 171: mandau.services.v1.ServiceDeploymentService.DeployStaticSite:output_type -> mandau.services.v1.ServiceOperationEvent
+	123, // 172: mandau.services.v1.ServiceDeploymentService.DeployDatabase:output_type -> mandau.services.v1.ServiceOperationEvent
+	123, // 173: mandau.services.v1.ServiceDeploymentService.DeployWorker:output_type -> mandau.services.v1.ServiceOperationEvent
+	127, // 174: mandau.services.v1.ServiceDeploymentService.ListDeployedServices:output_type -> mandau.services.v1.ListDeployedServicesResponse
+	134, // 175: mandau.services.v1.DriftService.GetDriftReport:output_type -> mandau.services.v1.DriftReport
+	138, // 176: mandau.services.v1.PortService.ListListeningPorts:output_type -> mandau.services.v1.ListListeningPortsResponse
+	141, // 177: mandau.services.v1.ProcessService.ListProcesses:output_type -> mandau.services.v1.ListProcessesResponse
+	143, // 178: mandau.services.v1.ProcessService.SignalProcess:output_type -> mandau.services.v1.SignalProcessResponse
+	146, // 179: mandau.services.v1.HostLogService.ListLogFiles:output_type -> mandau.services.v1.ListLogFilesResponse
+	167, // 180: mandau.services.v1.HostLogService.TailLogFile:output_type -> mandau.agent.v1.LogEntry
+	168, // 181: mandau.services.v1.HostPowerService.RebootHost:output_type -> mandau.agent.v1.OperationEvent
+	150, // 182: mandau.services.v1.LogShipService.GetLogShipping:output_type -> mandau.services.v1.LogShipping
+	154, // 183: mandau.services.v1.CommandService.ListCommands:output_type -> mandau.services.v1.ListCommandsResponse
+	169, // 184: mandau.services.v1.CommandService.RunCommand:output_type -> mandau.agent.v1.CommandResult
+	112, // [112:185] is the sub-list for method output_type
+	39,  // [39:112] is the sub-list for method input_type
+	39,  // [39:39] is the sub-list for extension type_name
+	39,  // [39:39] is the sub-list for extension extendee
+	0,   // [0:39] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_service_proto_init() }
@@ -4786,15 +10828,16 @@ func file_api_v1_service_proto_init() {
 	if File_api_v1_service_proto != nil {
 		return
 	}
+	file_api_v1_agent_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_v1_service_proto_rawDesc), len(file_api_v1_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   77,
+			NumMessages:   164,
 			NumExtensions: 0,
-			NumServices:   6,
+			NumServices:   15,
 		},
 		GoTypes:           file_api_v1_service_proto_goTypes,
 		DependencyIndexes: file_api_v1_service_proto_depIdxs,
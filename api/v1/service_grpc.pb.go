@@ -353,14 +353,17 @@ var NginxService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	SystemdService_CreateService_FullMethodName    = "/mandau.services.v1.SystemdService/CreateService"
-	SystemdService_EnableService_FullMethodName    = "/mandau.services.v1.SystemdService/EnableService"
-	SystemdService_DisableService_FullMethodName   = "/mandau.services.v1.SystemdService/DisableService"
-	SystemdService_StartService_FullMethodName     = "/mandau.services.v1.SystemdService/StartService"
-	SystemdService_StopService_FullMethodName      = "/mandau.services.v1.SystemdService/StopService"
-	SystemdService_RestartService_FullMethodName   = "/mandau.services.v1.SystemdService/RestartService"
-	SystemdService_GetServiceStatus_FullMethodName = "/mandau.services.v1.SystemdService/GetServiceStatus"
-	SystemdService_ListServices_FullMethodName     = "/mandau.services.v1.SystemdService/ListServices"
+	SystemdService_CreateService_FullMethodName          = "/mandau.services.v1.SystemdService/CreateService"
+	SystemdService_EnableService_FullMethodName          = "/mandau.services.v1.SystemdService/EnableService"
+	SystemdService_DisableService_FullMethodName         = "/mandau.services.v1.SystemdService/DisableService"
+	SystemdService_StartService_FullMethodName           = "/mandau.services.v1.SystemdService/StartService"
+	SystemdService_StopService_FullMethodName            = "/mandau.services.v1.SystemdService/StopService"
+	SystemdService_RestartService_FullMethodName         = "/mandau.services.v1.SystemdService/RestartService"
+	SystemdService_GetServiceStatus_FullMethodName       = "/mandau.services.v1.SystemdService/GetServiceStatus"
+	SystemdService_ListServices_FullMethodName           = "/mandau.services.v1.SystemdService/ListServices"
+	SystemdService_ListInstances_FullMethodName          = "/mandau.services.v1.SystemdService/ListInstances"
+	SystemdService_SetInstanceEnvironment_FullMethodName = "/mandau.services.v1.SystemdService/SetInstanceEnvironment"
+	SystemdService_ScaleInstances_FullMethodName         = "/mandau.services.v1.SystemdService/ScaleInstances"
 )
 
 // SystemdServiceClient is the client API for SystemdService service.
@@ -377,6 +380,9 @@ type SystemdServiceClient interface {
 	RestartService(ctx context.Context, in *RestartServiceRequest, opts ...grpc.CallOption) (*RestartServiceResponse, error)
 	GetServiceStatus(ctx context.Context, in *GetServiceStatusRequest, opts ...grpc.CallOption) (*GetServiceStatusResponse, error)
 	ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error)
+	ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error)
+	SetInstanceEnvironment(ctx context.Context, in *SetInstanceEnvironmentRequest, opts ...grpc.CallOption) (*SetInstanceEnvironmentResponse, error)
+	ScaleInstances(ctx context.Context, in *ScaleInstancesRequest, opts ...grpc.CallOption) (*ScaleInstancesResponse, error)
 }
 
 type systemdServiceClient struct {
@@ -467,6 +473,36 @@ func (c *systemdServiceClient) ListServices(ctx context.Context, in *ListService
 	return out, nil
 }
 
+func (c *systemdServiceClient) ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInstancesResponse)
+	err := c.cc.Invoke(ctx, SystemdService_ListInstances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemdServiceClient) SetInstanceEnvironment(ctx context.Context, in *SetInstanceEnvironmentRequest, opts ...grpc.CallOption) (*SetInstanceEnvironmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetInstanceEnvironmentResponse)
+	err := c.cc.Invoke(ctx, SystemdService_SetInstanceEnvironment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemdServiceClient) ScaleInstances(ctx context.Context, in *ScaleInstancesRequest, opts ...grpc.CallOption) (*ScaleInstancesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScaleInstancesResponse)
+	err := c.cc.Invoke(ctx, SystemdService_ScaleInstances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SystemdServiceServer is the server API for SystemdService service.
 // All implementations must embed UnimplementedSystemdServiceServer
 // for forward compatibility.
@@ -481,6 +517,9 @@ type SystemdServiceServer interface {
 	RestartService(context.Context, *RestartServiceRequest) (*RestartServiceResponse, error)
 	GetServiceStatus(context.Context, *GetServiceStatusRequest) (*GetServiceStatusResponse, error)
 	ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error)
+	ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error)
+	SetInstanceEnvironment(context.Context, *SetInstanceEnvironmentRequest) (*SetInstanceEnvironmentResponse, error)
+	ScaleInstances(context.Context, *ScaleInstancesRequest) (*ScaleInstancesResponse, error)
 	mustEmbedUnimplementedSystemdServiceServer()
 }
 
@@ -515,6 +554,15 @@ func (UnimplementedSystemdServiceServer) GetServiceStatus(context.Context, *GetS
 func (UnimplementedSystemdServiceServer) ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListServices not implemented")
 }
+func (UnimplementedSystemdServiceServer) ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListInstances not implemented")
+}
+func (UnimplementedSystemdServiceServer) SetInstanceEnvironment(context.Context, *SetInstanceEnvironmentRequest) (*SetInstanceEnvironmentResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetInstanceEnvironment not implemented")
+}
+func (UnimplementedSystemdServiceServer) ScaleInstances(context.Context, *ScaleInstancesRequest) (*ScaleInstancesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScaleInstances not implemented")
+}
 func (UnimplementedSystemdServiceServer) mustEmbedUnimplementedSystemdServiceServer() {}
 func (UnimplementedSystemdServiceServer) testEmbeddedByValue()                        {}
 
@@ -680,6 +728,60 @@ func _SystemdService_ListServices_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SystemdService_ListInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInstancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemdServiceServer).ListInstances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SystemdService_ListInstances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemdServiceServer).ListInstances(ctx, req.(*ListInstancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SystemdService_SetInstanceEnvironment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetInstanceEnvironmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemdServiceServer).SetInstanceEnvironment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SystemdService_SetInstanceEnvironment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemdServiceServer).SetInstanceEnvironment(ctx, req.(*SetInstanceEnvironmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SystemdService_ScaleInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScaleInstancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemdServiceServer).ScaleInstances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SystemdService_ScaleInstances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemdServiceServer).ScaleInstances(ctx, req.(*ScaleInstancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SystemdService_ServiceDesc is the grpc.ServiceDesc for SystemdService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -719,19 +821,32 @@ var SystemdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListServices",
 			Handler:    _SystemdService_ListServices_Handler,
 		},
+		{
+			MethodName: "ListInstances",
+			Handler:    _SystemdService_ListInstances_Handler,
+		},
+		{
+			MethodName: "SetInstanceEnvironment",
+			Handler:    _SystemdService_SetInstanceEnvironment_Handler,
+		},
+		{
+			MethodName: "ScaleInstances",
+			Handler:    _SystemdService_ScaleInstances_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/service.proto",
 }
 
 const (
-	FirewallService_AddRule_FullMethodName    = "/mandau.services.v1.FirewallService/AddRule"
-	FirewallService_DeleteRule_FullMethodName = "/mandau.services.v1.FirewallService/DeleteRule"
-	FirewallService_ListRules_FullMethodName  = "/mandau.services.v1.FirewallService/ListRules"
-	FirewallService_AllowPort_FullMethodName  = "/mandau.services.v1.FirewallService/AllowPort"
-	FirewallService_DenyPort_FullMethodName   = "/mandau.services.v1.FirewallService/DenyPort"
-	FirewallService_Enable_FullMethodName     = "/mandau.services.v1.FirewallService/Enable"
-	FirewallService_Disable_FullMethodName    = "/mandau.services.v1.FirewallService/Disable"
+	FirewallService_AddRule_FullMethodName       = "/mandau.services.v1.FirewallService/AddRule"
+	FirewallService_DeleteRule_FullMethodName    = "/mandau.services.v1.FirewallService/DeleteRule"
+	FirewallService_ListRules_FullMethodName     = "/mandau.services.v1.FirewallService/ListRules"
+	FirewallService_AllowPort_FullMethodName     = "/mandau.services.v1.FirewallService/AllowPort"
+	FirewallService_DenyPort_FullMethodName      = "/mandau.services.v1.FirewallService/DenyPort"
+	FirewallService_Enable_FullMethodName        = "/mandau.services.v1.FirewallService/Enable"
+	FirewallService_Disable_FullMethodName       = "/mandau.services.v1.FirewallService/Disable"
+	FirewallService_RemoveRuleSet_FullMethodName = "/mandau.services.v1.FirewallService/RemoveRuleSet"
 )
 
 // FirewallServiceClient is the client API for FirewallService service.
@@ -747,6 +862,7 @@ type FirewallServiceClient interface {
 	DenyPort(ctx context.Context, in *DenyPortRequest, opts ...grpc.CallOption) (*DenyPortResponse, error)
 	Enable(ctx context.Context, in *EnableFirewallRequest, opts ...grpc.CallOption) (*EnableFirewallResponse, error)
 	Disable(ctx context.Context, in *DisableFirewallRequest, opts ...grpc.CallOption) (*DisableFirewallResponse, error)
+	RemoveRuleSet(ctx context.Context, in *RemoveFirewallRuleSetRequest, opts ...grpc.CallOption) (*RemoveFirewallRuleSetResponse, error)
 }
 
 type firewallServiceClient struct {
@@ -827,6 +943,16 @@ func (c *firewallServiceClient) Disable(ctx context.Context, in *DisableFirewall
 	return out, nil
 }
 
+func (c *firewallServiceClient) RemoveRuleSet(ctx context.Context, in *RemoveFirewallRuleSetRequest, opts ...grpc.CallOption) (*RemoveFirewallRuleSetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveFirewallRuleSetResponse)
+	err := c.cc.Invoke(ctx, FirewallService_RemoveRuleSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FirewallServiceServer is the server API for FirewallService service.
 // All implementations must embed UnimplementedFirewallServiceServer
 // for forward compatibility.
@@ -840,6 +966,7 @@ type FirewallServiceServer interface {
 	DenyPort(context.Context, *DenyPortRequest) (*DenyPortResponse, error)
 	Enable(context.Context, *EnableFirewallRequest) (*EnableFirewallResponse, error)
 	Disable(context.Context, *DisableFirewallRequest) (*DisableFirewallResponse, error)
+	RemoveRuleSet(context.Context, *RemoveFirewallRuleSetRequest) (*RemoveFirewallRuleSetResponse, error)
 	mustEmbedUnimplementedFirewallServiceServer()
 }
 
@@ -871,6 +998,9 @@ func (UnimplementedFirewallServiceServer) Enable(context.Context, *EnableFirewal
 func (UnimplementedFirewallServiceServer) Disable(context.Context, *DisableFirewallRequest) (*DisableFirewallResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Disable not implemented")
 }
+func (UnimplementedFirewallServiceServer) RemoveRuleSet(context.Context, *RemoveFirewallRuleSetRequest) (*RemoveFirewallRuleSetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveRuleSet not implemented")
+}
 func (UnimplementedFirewallServiceServer) mustEmbedUnimplementedFirewallServiceServer() {}
 func (UnimplementedFirewallServiceServer) testEmbeddedByValue()                         {}
 
@@ -1018,6 +1148,24 @@ func _FirewallService_Disable_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FirewallService_RemoveRuleSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveFirewallRuleSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FirewallServiceServer).RemoveRuleSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FirewallService_RemoveRuleSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FirewallServiceServer).RemoveRuleSet(ctx, req.(*RemoveFirewallRuleSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FirewallService_ServiceDesc is the grpc.ServiceDesc for FirewallService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1053,6 +1201,10 @@ var FirewallService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Disable",
 			Handler:    _FirewallService_Disable_Handler,
 		},
+		{
+			MethodName: "RemoveRuleSet",
+			Handler:    _FirewallService_RemoveRuleSet_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/service.proto",
@@ -1317,13 +1469,25 @@ var ACMEService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	HostEnvironmentService_GetHostInfo_FullMethodName    = "/mandau.services.v1.HostEnvironmentService/GetHostInfo"
-	HostEnvironmentService_InstallPackage_FullMethodName = "/mandau.services.v1.HostEnvironmentService/InstallPackage"
-	HostEnvironmentService_RemovePackage_FullMethodName  = "/mandau.services.v1.HostEnvironmentService/RemovePackage"
-	HostEnvironmentService_UpdatePackages_FullMethodName = "/mandau.services.v1.HostEnvironmentService/UpdatePackages"
-	HostEnvironmentService_ListPackages_FullMethodName   = "/mandau.services.v1.HostEnvironmentService/ListPackages"
-	HostEnvironmentService_SetSysctl_FullMethodName      = "/mandau.services.v1.HostEnvironmentService/SetSysctl"
-	HostEnvironmentService_GetSysctl_FullMethodName      = "/mandau.services.v1.HostEnvironmentService/GetSysctl"
+	HostEnvironmentService_GetHostInfo_FullMethodName        = "/mandau.services.v1.HostEnvironmentService/GetHostInfo"
+	HostEnvironmentService_InstallPackage_FullMethodName     = "/mandau.services.v1.HostEnvironmentService/InstallPackage"
+	HostEnvironmentService_RemovePackage_FullMethodName      = "/mandau.services.v1.HostEnvironmentService/RemovePackage"
+	HostEnvironmentService_UpdatePackages_FullMethodName     = "/mandau.services.v1.HostEnvironmentService/UpdatePackages"
+	HostEnvironmentService_ListPackages_FullMethodName       = "/mandau.services.v1.HostEnvironmentService/ListPackages"
+	HostEnvironmentService_SetSysctl_FullMethodName          = "/mandau.services.v1.HostEnvironmentService/SetSysctl"
+	HostEnvironmentService_GetSysctl_FullMethodName          = "/mandau.services.v1.HostEnvironmentService/GetSysctl"
+	HostEnvironmentService_GetTimeSync_FullMethodName        = "/mandau.services.v1.HostEnvironmentService/GetTimeSync"
+	HostEnvironmentService_ConfigureTimeSync_FullMethodName  = "/mandau.services.v1.HostEnvironmentService/ConfigureTimeSync"
+	HostEnvironmentService_GetPatchStatus_FullMethodName     = "/mandau.services.v1.HostEnvironmentService/GetPatchStatus"
+	HostEnvironmentService_GetUser_FullMethodName            = "/mandau.services.v1.HostEnvironmentService/GetUser"
+	HostEnvironmentService_EnsureUser_FullMethodName         = "/mandau.services.v1.HostEnvironmentService/EnsureUser"
+	HostEnvironmentService_ListSysctlProfiles_FullMethodName = "/mandau.services.v1.HostEnvironmentService/ListSysctlProfiles"
+	HostEnvironmentService_ApplySysctlProfile_FullMethodName = "/mandau.services.v1.HostEnvironmentService/ApplySysctlProfile"
+	HostEnvironmentService_VerifySysctls_FullMethodName      = "/mandau.services.v1.HostEnvironmentService/VerifySysctls"
+	HostEnvironmentService_GetLimits_FullMethodName          = "/mandau.services.v1.HostEnvironmentService/GetLimits"
+	HostEnvironmentService_SetLimits_FullMethodName          = "/mandau.services.v1.HostEnvironmentService/SetLimits"
+	HostEnvironmentService_GetDockerDaemon_FullMethodName    = "/mandau.services.v1.HostEnvironmentService/GetDockerDaemon"
+	HostEnvironmentService_SetDockerDaemon_FullMethodName    = "/mandau.services.v1.HostEnvironmentService/SetDockerDaemon"
 )
 
 // HostEnvironmentServiceClient is the client API for HostEnvironmentService service.
@@ -1339,6 +1503,18 @@ type HostEnvironmentServiceClient interface {
 	ListPackages(ctx context.Context, in *ListPackagesRequest, opts ...grpc.CallOption) (*ListPackagesResponse, error)
 	SetSysctl(ctx context.Context, in *SetSysctlRequest, opts ...grpc.CallOption) (*SetSysctlResponse, error)
 	GetSysctl(ctx context.Context, in *GetSysctlRequest, opts ...grpc.CallOption) (*GetSysctlResponse, error)
+	GetTimeSync(ctx context.Context, in *GetTimeSyncRequest, opts ...grpc.CallOption) (*GetTimeSyncResponse, error)
+	ConfigureTimeSync(ctx context.Context, in *ConfigureTimeSyncRequest, opts ...grpc.CallOption) (*ConfigureTimeSyncResponse, error)
+	GetPatchStatus(ctx context.Context, in *GetPatchStatusRequest, opts ...grpc.CallOption) (*PatchStatus, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	EnsureUser(ctx context.Context, in *EnsureUserRequest, opts ...grpc.CallOption) (*EnsureUserResponse, error)
+	ListSysctlProfiles(ctx context.Context, in *ListSysctlProfilesRequest, opts ...grpc.CallOption) (*ListSysctlProfilesResponse, error)
+	ApplySysctlProfile(ctx context.Context, in *ApplySysctlProfileRequest, opts ...grpc.CallOption) (*ApplySysctlProfileResponse, error)
+	VerifySysctls(ctx context.Context, in *VerifySysctlsRequest, opts ...grpc.CallOption) (*VerifySysctlsResponse, error)
+	GetLimits(ctx context.Context, in *GetLimitsRequest, opts ...grpc.CallOption) (*GetLimitsResponse, error)
+	SetLimits(ctx context.Context, in *SetLimitsRequest, opts ...grpc.CallOption) (*SetLimitsResponse, error)
+	GetDockerDaemon(ctx context.Context, in *GetDockerDaemonRequest, opts ...grpc.CallOption) (*GetDockerDaemonResponse, error)
+	SetDockerDaemon(ctx context.Context, in *SetDockerDaemonRequest, opts ...grpc.CallOption) (*SetDockerDaemonResponse, error)
 }
 
 type hostEnvironmentServiceClient struct {
@@ -1419,6 +1595,126 @@ func (c *hostEnvironmentServiceClient) GetSysctl(ctx context.Context, in *GetSys
 	return out, nil
 }
 
+func (c *hostEnvironmentServiceClient) GetTimeSync(ctx context.Context, in *GetTimeSyncRequest, opts ...grpc.CallOption) (*GetTimeSyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTimeSyncResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_GetTimeSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) ConfigureTimeSync(ctx context.Context, in *ConfigureTimeSyncRequest, opts ...grpc.CallOption) (*ConfigureTimeSyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfigureTimeSyncResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_ConfigureTimeSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) GetPatchStatus(ctx context.Context, in *GetPatchStatusRequest, opts ...grpc.CallOption) (*PatchStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PatchStatus)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_GetPatchStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) EnsureUser(ctx context.Context, in *EnsureUserRequest, opts ...grpc.CallOption) (*EnsureUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnsureUserResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_EnsureUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) ListSysctlProfiles(ctx context.Context, in *ListSysctlProfilesRequest, opts ...grpc.CallOption) (*ListSysctlProfilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSysctlProfilesResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_ListSysctlProfiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) ApplySysctlProfile(ctx context.Context, in *ApplySysctlProfileRequest, opts ...grpc.CallOption) (*ApplySysctlProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplySysctlProfileResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_ApplySysctlProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) VerifySysctls(ctx context.Context, in *VerifySysctlsRequest, opts ...grpc.CallOption) (*VerifySysctlsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifySysctlsResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_VerifySysctls_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) GetLimits(ctx context.Context, in *GetLimitsRequest, opts ...grpc.CallOption) (*GetLimitsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLimitsResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_GetLimits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) SetLimits(ctx context.Context, in *SetLimitsRequest, opts ...grpc.CallOption) (*SetLimitsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLimitsResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_SetLimits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) GetDockerDaemon(ctx context.Context, in *GetDockerDaemonRequest, opts ...grpc.CallOption) (*GetDockerDaemonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDockerDaemonResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_GetDockerDaemon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostEnvironmentServiceClient) SetDockerDaemon(ctx context.Context, in *SetDockerDaemonRequest, opts ...grpc.CallOption) (*SetDockerDaemonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDockerDaemonResponse)
+	err := c.cc.Invoke(ctx, HostEnvironmentService_SetDockerDaemon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HostEnvironmentServiceServer is the server API for HostEnvironmentService service.
 // All implementations must embed UnimplementedHostEnvironmentServiceServer
 // for forward compatibility.
@@ -1432,6 +1728,18 @@ type HostEnvironmentServiceServer interface {
 	ListPackages(context.Context, *ListPackagesRequest) (*ListPackagesResponse, error)
 	SetSysctl(context.Context, *SetSysctlRequest) (*SetSysctlResponse, error)
 	GetSysctl(context.Context, *GetSysctlRequest) (*GetSysctlResponse, error)
+	GetTimeSync(context.Context, *GetTimeSyncRequest) (*GetTimeSyncResponse, error)
+	ConfigureTimeSync(context.Context, *ConfigureTimeSyncRequest) (*ConfigureTimeSyncResponse, error)
+	GetPatchStatus(context.Context, *GetPatchStatusRequest) (*PatchStatus, error)
+	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	EnsureUser(context.Context, *EnsureUserRequest) (*EnsureUserResponse, error)
+	ListSysctlProfiles(context.Context, *ListSysctlProfilesRequest) (*ListSysctlProfilesResponse, error)
+	ApplySysctlProfile(context.Context, *ApplySysctlProfileRequest) (*ApplySysctlProfileResponse, error)
+	VerifySysctls(context.Context, *VerifySysctlsRequest) (*VerifySysctlsResponse, error)
+	GetLimits(context.Context, *GetLimitsRequest) (*GetLimitsResponse, error)
+	SetLimits(context.Context, *SetLimitsRequest) (*SetLimitsResponse, error)
+	GetDockerDaemon(context.Context, *GetDockerDaemonRequest) (*GetDockerDaemonResponse, error)
+	SetDockerDaemon(context.Context, *SetDockerDaemonRequest) (*SetDockerDaemonResponse, error)
 	mustEmbedUnimplementedHostEnvironmentServiceServer()
 }
 
@@ -1463,6 +1771,42 @@ func (UnimplementedHostEnvironmentServiceServer) SetSysctl(context.Context, *Set
 func (UnimplementedHostEnvironmentServiceServer) GetSysctl(context.Context, *GetSysctlRequest) (*GetSysctlResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSysctl not implemented")
 }
+func (UnimplementedHostEnvironmentServiceServer) GetTimeSync(context.Context, *GetTimeSyncRequest) (*GetTimeSyncResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTimeSync not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) ConfigureTimeSync(context.Context, *ConfigureTimeSyncRequest) (*ConfigureTimeSyncResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ConfigureTimeSync not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) GetPatchStatus(context.Context, *GetPatchStatusRequest) (*PatchStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPatchStatus not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) EnsureUser(context.Context, *EnsureUserRequest) (*EnsureUserResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnsureUser not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) ListSysctlProfiles(context.Context, *ListSysctlProfilesRequest) (*ListSysctlProfilesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSysctlProfiles not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) ApplySysctlProfile(context.Context, *ApplySysctlProfileRequest) (*ApplySysctlProfileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApplySysctlProfile not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) VerifySysctls(context.Context, *VerifySysctlsRequest) (*VerifySysctlsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method VerifySysctls not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) GetLimits(context.Context, *GetLimitsRequest) (*GetLimitsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLimits not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) SetLimits(context.Context, *SetLimitsRequest) (*SetLimitsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLimits not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) GetDockerDaemon(context.Context, *GetDockerDaemonRequest) (*GetDockerDaemonResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDockerDaemon not implemented")
+}
+func (UnimplementedHostEnvironmentServiceServer) SetDockerDaemon(context.Context, *SetDockerDaemonRequest) (*SetDockerDaemonResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetDockerDaemon not implemented")
+}
 func (UnimplementedHostEnvironmentServiceServer) mustEmbedUnimplementedHostEnvironmentServiceServer() {
 }
 func (UnimplementedHostEnvironmentServiceServer) testEmbeddedByValue() {}
@@ -1611,76 +1955,1565 @@ func _HostEnvironmentService_GetSysctl_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
-// HostEnvironmentService_ServiceDesc is the grpc.ServiceDesc for HostEnvironmentService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var HostEnvironmentService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.services.v1.HostEnvironmentService",
-	HandlerType: (*HostEnvironmentServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetHostInfo",
-			Handler:    _HostEnvironmentService_GetHostInfo_Handler,
-		},
-		{
-			MethodName: "InstallPackage",
-			Handler:    _HostEnvironmentService_InstallPackage_Handler,
-		},
-		{
-			MethodName: "RemovePackage",
-			Handler:    _HostEnvironmentService_RemovePackage_Handler,
-		},
-		{
-			MethodName: "UpdatePackages",
-			Handler:    _HostEnvironmentService_UpdatePackages_Handler,
-		},
-		{
-			MethodName: "ListPackages",
-			Handler:    _HostEnvironmentService_ListPackages_Handler,
-		},
-		{
-			MethodName: "SetSysctl",
-			Handler:    _HostEnvironmentService_SetSysctl_Handler,
-		},
-		{
-			MethodName: "GetSysctl",
-			Handler:    _HostEnvironmentService_GetSysctl_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "api/v1/service.proto",
+func _HostEnvironmentService_GetTimeSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimeSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).GetTimeSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_GetTimeSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).GetTimeSync(ctx, req.(*GetTimeSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-const (
-	ServiceDeploymentService_DeployWebService_FullMethodName = "/mandau.services.v1.ServiceDeploymentService/DeployWebService"
-	ServiceDeploymentService_RemoveWebService_FullMethodName = "/mandau.services.v1.ServiceDeploymentService/RemoveWebService"
-)
+func _HostEnvironmentService_ConfigureTimeSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureTimeSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).ConfigureTimeSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_ConfigureTimeSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).ConfigureTimeSync(ctx, req.(*ConfigureTimeSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-// ServiceDeploymentServiceClient is the client API for ServiceDeploymentService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-//
-// Complete Service Deployment
+func _HostEnvironmentService_GetPatchStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPatchStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).GetPatchStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_GetPatchStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).GetPatchStatus(ctx, req.(*GetPatchStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_EnsureUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnsureUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).EnsureUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_EnsureUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).EnsureUser(ctx, req.(*EnsureUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_ListSysctlProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSysctlProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).ListSysctlProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_ListSysctlProfiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).ListSysctlProfiles(ctx, req.(*ListSysctlProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_ApplySysctlProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplySysctlProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).ApplySysctlProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_ApplySysctlProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).ApplySysctlProfile(ctx, req.(*ApplySysctlProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_VerifySysctls_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifySysctlsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).VerifySysctls(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_VerifySysctls_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).VerifySysctls(ctx, req.(*VerifySysctlsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_GetLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).GetLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_GetLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).GetLimits(ctx, req.(*GetLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_SetLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).SetLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_SetLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).SetLimits(ctx, req.(*SetLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_GetDockerDaemon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDockerDaemonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).GetDockerDaemon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_GetDockerDaemon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).GetDockerDaemon(ctx, req.(*GetDockerDaemonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostEnvironmentService_SetDockerDaemon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDockerDaemonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostEnvironmentServiceServer).SetDockerDaemon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostEnvironmentService_SetDockerDaemon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostEnvironmentServiceServer).SetDockerDaemon(ctx, req.(*SetDockerDaemonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HostEnvironmentService_ServiceDesc is the grpc.ServiceDesc for HostEnvironmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostEnvironmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.HostEnvironmentService",
+	HandlerType: (*HostEnvironmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetHostInfo",
+			Handler:    _HostEnvironmentService_GetHostInfo_Handler,
+		},
+		{
+			MethodName: "InstallPackage",
+			Handler:    _HostEnvironmentService_InstallPackage_Handler,
+		},
+		{
+			MethodName: "RemovePackage",
+			Handler:    _HostEnvironmentService_RemovePackage_Handler,
+		},
+		{
+			MethodName: "UpdatePackages",
+			Handler:    _HostEnvironmentService_UpdatePackages_Handler,
+		},
+		{
+			MethodName: "ListPackages",
+			Handler:    _HostEnvironmentService_ListPackages_Handler,
+		},
+		{
+			MethodName: "SetSysctl",
+			Handler:    _HostEnvironmentService_SetSysctl_Handler,
+		},
+		{
+			MethodName: "GetSysctl",
+			Handler:    _HostEnvironmentService_GetSysctl_Handler,
+		},
+		{
+			MethodName: "GetTimeSync",
+			Handler:    _HostEnvironmentService_GetTimeSync_Handler,
+		},
+		{
+			MethodName: "ConfigureTimeSync",
+			Handler:    _HostEnvironmentService_ConfigureTimeSync_Handler,
+		},
+		{
+			MethodName: "GetPatchStatus",
+			Handler:    _HostEnvironmentService_GetPatchStatus_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _HostEnvironmentService_GetUser_Handler,
+		},
+		{
+			MethodName: "EnsureUser",
+			Handler:    _HostEnvironmentService_EnsureUser_Handler,
+		},
+		{
+			MethodName: "ListSysctlProfiles",
+			Handler:    _HostEnvironmentService_ListSysctlProfiles_Handler,
+		},
+		{
+			MethodName: "ApplySysctlProfile",
+			Handler:    _HostEnvironmentService_ApplySysctlProfile_Handler,
+		},
+		{
+			MethodName: "VerifySysctls",
+			Handler:    _HostEnvironmentService_VerifySysctls_Handler,
+		},
+		{
+			MethodName: "GetLimits",
+			Handler:    _HostEnvironmentService_GetLimits_Handler,
+		},
+		{
+			MethodName: "SetLimits",
+			Handler:    _HostEnvironmentService_SetLimits_Handler,
+		},
+		{
+			MethodName: "GetDockerDaemon",
+			Handler:    _HostEnvironmentService_GetDockerDaemon_Handler,
+		},
+		{
+			MethodName: "SetDockerDaemon",
+			Handler:    _HostEnvironmentService_SetDockerDaemon_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	CronService_AddCronJob_FullMethodName    = "/mandau.services.v1.CronService/AddCronJob"
+	CronService_RemoveCronJob_FullMethodName = "/mandau.services.v1.CronService/RemoveCronJob"
+	CronService_ListCronJobs_FullMethodName  = "/mandau.services.v1.CronService/ListCronJobs"
+)
+
+// CronServiceClient is the client API for CronService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Cron Service
+type CronServiceClient interface {
+	AddCronJob(ctx context.Context, in *AddCronJobRequest, opts ...grpc.CallOption) (*AddCronJobResponse, error)
+	RemoveCronJob(ctx context.Context, in *RemoveCronJobRequest, opts ...grpc.CallOption) (*RemoveCronJobResponse, error)
+	ListCronJobs(ctx context.Context, in *ListCronJobsRequest, opts ...grpc.CallOption) (*ListCronJobsResponse, error)
+}
+
+type cronServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCronServiceClient(cc grpc.ClientConnInterface) CronServiceClient {
+	return &cronServiceClient{cc}
+}
+
+func (c *cronServiceClient) AddCronJob(ctx context.Context, in *AddCronJobRequest, opts ...grpc.CallOption) (*AddCronJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddCronJobResponse)
+	err := c.cc.Invoke(ctx, CronService_AddCronJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronServiceClient) RemoveCronJob(ctx context.Context, in *RemoveCronJobRequest, opts ...grpc.CallOption) (*RemoveCronJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveCronJobResponse)
+	err := c.cc.Invoke(ctx, CronService_RemoveCronJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronServiceClient) ListCronJobs(ctx context.Context, in *ListCronJobsRequest, opts ...grpc.CallOption) (*ListCronJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCronJobsResponse)
+	err := c.cc.Invoke(ctx, CronService_ListCronJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CronServiceServer is the server API for CronService service.
+// All implementations must embed UnimplementedCronServiceServer
+// for forward compatibility.
+//
+// Cron Service
+type CronServiceServer interface {
+	AddCronJob(context.Context, *AddCronJobRequest) (*AddCronJobResponse, error)
+	RemoveCronJob(context.Context, *RemoveCronJobRequest) (*RemoveCronJobResponse, error)
+	ListCronJobs(context.Context, *ListCronJobsRequest) (*ListCronJobsResponse, error)
+	mustEmbedUnimplementedCronServiceServer()
+}
+
+// UnimplementedCronServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCronServiceServer struct{}
+
+func (UnimplementedCronServiceServer) AddCronJob(context.Context, *AddCronJobRequest) (*AddCronJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddCronJob not implemented")
+}
+func (UnimplementedCronServiceServer) RemoveCronJob(context.Context, *RemoveCronJobRequest) (*RemoveCronJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveCronJob not implemented")
+}
+func (UnimplementedCronServiceServer) ListCronJobs(context.Context, *ListCronJobsRequest) (*ListCronJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCronJobs not implemented")
+}
+func (UnimplementedCronServiceServer) mustEmbedUnimplementedCronServiceServer() {}
+func (UnimplementedCronServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeCronServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CronServiceServer will
+// result in compilation errors.
+type UnsafeCronServiceServer interface {
+	mustEmbedUnimplementedCronServiceServer()
+}
+
+func RegisterCronServiceServer(s grpc.ServiceRegistrar, srv CronServiceServer) {
+	// If the following call panics, it indicates UnimplementedCronServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CronService_ServiceDesc, srv)
+}
+
+func _CronService_AddCronJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCronJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronServiceServer).AddCronJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronService_AddCronJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronServiceServer).AddCronJob(ctx, req.(*AddCronJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronService_RemoveCronJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCronJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronServiceServer).RemoveCronJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronService_RemoveCronJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronServiceServer).RemoveCronJob(ctx, req.(*RemoveCronJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronService_ListCronJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCronJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronServiceServer).ListCronJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronService_ListCronJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronServiceServer).ListCronJobs(ctx, req.(*ListCronJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CronService_ServiceDesc is the grpc.ServiceDesc for CronService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CronService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.CronService",
+	HandlerType: (*CronServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddCronJob",
+			Handler:    _CronService_AddCronJob_Handler,
+		},
+		{
+			MethodName: "RemoveCronJob",
+			Handler:    _CronService_RemoveCronJob_Handler,
+		},
+		{
+			MethodName: "ListCronJobs",
+			Handler:    _CronService_ListCronJobs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	DNSService_CreateZone_FullMethodName     = "/mandau.services.v1.DNSService/CreateZone"
+	DNSService_AddARecord_FullMethodName     = "/mandau.services.v1.DNSService/AddARecord"
+	DNSService_AddCNAMERecord_FullMethodName = "/mandau.services.v1.DNSService/AddCNAMERecord"
+	DNSService_ListZones_FullMethodName      = "/mandau.services.v1.DNSService/ListZones"
+)
+
+// DNSServiceClient is the client API for DNSService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DNS Service
+type DNSServiceClient interface {
+	CreateZone(ctx context.Context, in *CreateZoneRequest, opts ...grpc.CallOption) (*CreateZoneResponse, error)
+	AddARecord(ctx context.Context, in *AddARecordRequest, opts ...grpc.CallOption) (*AddARecordResponse, error)
+	AddCNAMERecord(ctx context.Context, in *AddCNAMERecordRequest, opts ...grpc.CallOption) (*AddCNAMERecordResponse, error)
+	ListZones(ctx context.Context, in *ListZonesRequest, opts ...grpc.CallOption) (*ListZonesResponse, error)
+}
+
+type dNSServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDNSServiceClient(cc grpc.ClientConnInterface) DNSServiceClient {
+	return &dNSServiceClient{cc}
+}
+
+func (c *dNSServiceClient) CreateZone(ctx context.Context, in *CreateZoneRequest, opts ...grpc.CallOption) (*CreateZoneResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateZoneResponse)
+	err := c.cc.Invoke(ctx, DNSService_CreateZone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSServiceClient) AddARecord(ctx context.Context, in *AddARecordRequest, opts ...grpc.CallOption) (*AddARecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddARecordResponse)
+	err := c.cc.Invoke(ctx, DNSService_AddARecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSServiceClient) AddCNAMERecord(ctx context.Context, in *AddCNAMERecordRequest, opts ...grpc.CallOption) (*AddCNAMERecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddCNAMERecordResponse)
+	err := c.cc.Invoke(ctx, DNSService_AddCNAMERecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSServiceClient) ListZones(ctx context.Context, in *ListZonesRequest, opts ...grpc.CallOption) (*ListZonesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListZonesResponse)
+	err := c.cc.Invoke(ctx, DNSService_ListZones_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DNSServiceServer is the server API for DNSService service.
+// All implementations must embed UnimplementedDNSServiceServer
+// for forward compatibility.
+//
+// DNS Service
+type DNSServiceServer interface {
+	CreateZone(context.Context, *CreateZoneRequest) (*CreateZoneResponse, error)
+	AddARecord(context.Context, *AddARecordRequest) (*AddARecordResponse, error)
+	AddCNAMERecord(context.Context, *AddCNAMERecordRequest) (*AddCNAMERecordResponse, error)
+	ListZones(context.Context, *ListZonesRequest) (*ListZonesResponse, error)
+	mustEmbedUnimplementedDNSServiceServer()
+}
+
+// UnimplementedDNSServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDNSServiceServer struct{}
+
+func (UnimplementedDNSServiceServer) CreateZone(context.Context, *CreateZoneRequest) (*CreateZoneResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateZone not implemented")
+}
+func (UnimplementedDNSServiceServer) AddARecord(context.Context, *AddARecordRequest) (*AddARecordResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddARecord not implemented")
+}
+func (UnimplementedDNSServiceServer) AddCNAMERecord(context.Context, *AddCNAMERecordRequest) (*AddCNAMERecordResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddCNAMERecord not implemented")
+}
+func (UnimplementedDNSServiceServer) ListZones(context.Context, *ListZonesRequest) (*ListZonesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListZones not implemented")
+}
+func (UnimplementedDNSServiceServer) mustEmbedUnimplementedDNSServiceServer() {}
+func (UnimplementedDNSServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeDNSServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DNSServiceServer will
+// result in compilation errors.
+type UnsafeDNSServiceServer interface {
+	mustEmbedUnimplementedDNSServiceServer()
+}
+
+func RegisterDNSServiceServer(s grpc.ServiceRegistrar, srv DNSServiceServer) {
+	// If the following call panics, it indicates UnimplementedDNSServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DNSService_ServiceDesc, srv)
+}
+
+func _DNSService_CreateZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateZoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSServiceServer).CreateZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DNSService_CreateZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSServiceServer).CreateZone(ctx, req.(*CreateZoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSService_AddARecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddARecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSServiceServer).AddARecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DNSService_AddARecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSServiceServer).AddARecord(ctx, req.(*AddARecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSService_AddCNAMERecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCNAMERecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSServiceServer).AddCNAMERecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DNSService_AddCNAMERecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSServiceServer).AddCNAMERecord(ctx, req.(*AddCNAMERecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSService_ListZones_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListZonesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSServiceServer).ListZones(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DNSService_ListZones_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSServiceServer).ListZones(ctx, req.(*ListZonesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DNSService_ServiceDesc is the grpc.ServiceDesc for DNSService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DNSService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.DNSService",
+	HandlerType: (*DNSServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateZone",
+			Handler:    _DNSService_CreateZone_Handler,
+		},
+		{
+			MethodName: "AddARecord",
+			Handler:    _DNSService_AddARecord_Handler,
+		},
+		{
+			MethodName: "AddCNAMERecord",
+			Handler:    _DNSService_AddCNAMERecord_Handler,
+		},
+		{
+			MethodName: "ListZones",
+			Handler:    _DNSService_ListZones_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	ServiceDeploymentService_DeployWebService_FullMethodName     = "/mandau.services.v1.ServiceDeploymentService/DeployWebService"
+	ServiceDeploymentService_RemoveWebService_FullMethodName     = "/mandau.services.v1.ServiceDeploymentService/RemoveWebService"
+	ServiceDeploymentService_DeployStaticSite_FullMethodName     = "/mandau.services.v1.ServiceDeploymentService/DeployStaticSite"
+	ServiceDeploymentService_DeployDatabase_FullMethodName       = "/mandau.services.v1.ServiceDeploymentService/DeployDatabase"
+	ServiceDeploymentService_DeployWorker_FullMethodName         = "/mandau.services.v1.ServiceDeploymentService/DeployWorker"
+	ServiceDeploymentService_ListDeployedServices_FullMethodName = "/mandau.services.v1.ServiceDeploymentService/ListDeployedServices"
+)
+
+// ServiceDeploymentServiceClient is the client API for ServiceDeploymentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Complete Service Deployment
 type ServiceDeploymentServiceClient interface {
 	DeployWebService(ctx context.Context, in *DeployWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error)
 	RemoveWebService(ctx context.Context, in *RemoveWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error)
+	DeployStaticSite(ctx context.Context, in *DeployStaticSiteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error)
+	DeployDatabase(ctx context.Context, in *DeployDatabaseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error)
+	DeployWorker(ctx context.Context, in *DeployWorkerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error)
+	ListDeployedServices(ctx context.Context, in *ListDeployedServicesRequest, opts ...grpc.CallOption) (*ListDeployedServicesResponse, error)
+}
+
+type serviceDeploymentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServiceDeploymentServiceClient(cc grpc.ClientConnInterface) ServiceDeploymentServiceClient {
+	return &serviceDeploymentServiceClient{cc}
+}
+
+func (c *serviceDeploymentServiceClient) DeployWebService(ctx context.Context, in *DeployWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[0], ServiceDeploymentService_DeployWebService_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeployWebServiceRequest, ServiceOperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployWebServiceClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+
+func (c *serviceDeploymentServiceClient) RemoveWebService(ctx context.Context, in *RemoveWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[1], ServiceDeploymentService_RemoveWebService_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RemoveWebServiceRequest, ServiceOperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_RemoveWebServiceClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+
+func (c *serviceDeploymentServiceClient) DeployStaticSite(ctx context.Context, in *DeployStaticSiteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[2], ServiceDeploymentService_DeployStaticSite_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeployStaticSiteRequest, ServiceOperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployStaticSiteClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+
+func (c *serviceDeploymentServiceClient) DeployDatabase(ctx context.Context, in *DeployDatabaseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[3], ServiceDeploymentService_DeployDatabase_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeployDatabaseRequest, ServiceOperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployDatabaseClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+
+func (c *serviceDeploymentServiceClient) DeployWorker(ctx context.Context, in *DeployWorkerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[4], ServiceDeploymentService_DeployWorker_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeployWorkerRequest, ServiceOperationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployWorkerClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+
+func (c *serviceDeploymentServiceClient) ListDeployedServices(ctx context.Context, in *ListDeployedServicesRequest, opts ...grpc.CallOption) (*ListDeployedServicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeployedServicesResponse)
+	err := c.cc.Invoke(ctx, ServiceDeploymentService_ListDeployedServices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServiceDeploymentServiceServer is the server API for ServiceDeploymentService service.
+// All implementations must embed UnimplementedServiceDeploymentServiceServer
+// for forward compatibility.
+//
+// Complete Service Deployment
+type ServiceDeploymentServiceServer interface {
+	DeployWebService(*DeployWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
+	RemoveWebService(*RemoveWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
+	DeployStaticSite(*DeployStaticSiteRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
+	DeployDatabase(*DeployDatabaseRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
+	DeployWorker(*DeployWorkerRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
+	ListDeployedServices(context.Context, *ListDeployedServicesRequest) (*ListDeployedServicesResponse, error)
+	mustEmbedUnimplementedServiceDeploymentServiceServer()
+}
+
+// UnimplementedServiceDeploymentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedServiceDeploymentServiceServer struct{}
+
+func (UnimplementedServiceDeploymentServiceServer) DeployWebService(*DeployWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method DeployWebService not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) RemoveWebService(*RemoveWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method RemoveWebService not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) DeployStaticSite(*DeployStaticSiteRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method DeployStaticSite not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) DeployDatabase(*DeployDatabaseRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method DeployDatabase not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) DeployWorker(*DeployWorkerRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method DeployWorker not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) ListDeployedServices(context.Context, *ListDeployedServicesRequest) (*ListDeployedServicesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDeployedServices not implemented")
+}
+func (UnimplementedServiceDeploymentServiceServer) mustEmbedUnimplementedServiceDeploymentServiceServer() {
+}
+func (UnimplementedServiceDeploymentServiceServer) testEmbeddedByValue() {}
+
+// UnsafeServiceDeploymentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServiceDeploymentServiceServer will
+// result in compilation errors.
+type UnsafeServiceDeploymentServiceServer interface {
+	mustEmbedUnimplementedServiceDeploymentServiceServer()
+}
+
+func RegisterServiceDeploymentServiceServer(s grpc.ServiceRegistrar, srv ServiceDeploymentServiceServer) {
+	// If the following call panics, it indicates UnimplementedServiceDeploymentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ServiceDeploymentService_ServiceDesc, srv)
+}
+
+func _ServiceDeploymentService_DeployWebService_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeployWebServiceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceDeploymentServiceServer).DeployWebService(m, &grpc.GenericServerStream[DeployWebServiceRequest, ServiceOperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployWebServiceServer = grpc.ServerStreamingServer[ServiceOperationEvent]
+
+func _ServiceDeploymentService_RemoveWebService_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RemoveWebServiceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceDeploymentServiceServer).RemoveWebService(m, &grpc.GenericServerStream[RemoveWebServiceRequest, ServiceOperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_RemoveWebServiceServer = grpc.ServerStreamingServer[ServiceOperationEvent]
+
+func _ServiceDeploymentService_DeployStaticSite_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeployStaticSiteRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceDeploymentServiceServer).DeployStaticSite(m, &grpc.GenericServerStream[DeployStaticSiteRequest, ServiceOperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployStaticSiteServer = grpc.ServerStreamingServer[ServiceOperationEvent]
+
+func _ServiceDeploymentService_DeployDatabase_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeployDatabaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceDeploymentServiceServer).DeployDatabase(m, &grpc.GenericServerStream[DeployDatabaseRequest, ServiceOperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployDatabaseServer = grpc.ServerStreamingServer[ServiceOperationEvent]
+
+func _ServiceDeploymentService_DeployWorker_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeployWorkerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceDeploymentServiceServer).DeployWorker(m, &grpc.GenericServerStream[DeployWorkerRequest, ServiceOperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ServiceDeploymentService_DeployWorkerServer = grpc.ServerStreamingServer[ServiceOperationEvent]
+
+func _ServiceDeploymentService_ListDeployedServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeployedServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceDeploymentServiceServer).ListDeployedServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServiceDeploymentService_ListDeployedServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceDeploymentServiceServer).ListDeployedServices(ctx, req.(*ListDeployedServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ServiceDeploymentService_ServiceDesc is the grpc.ServiceDesc for ServiceDeploymentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServiceDeploymentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.ServiceDeploymentService",
+	HandlerType: (*ServiceDeploymentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDeployedServices",
+			Handler:    _ServiceDeploymentService_ListDeployedServices_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DeployWebService",
+			Handler:       _ServiceDeploymentService_DeployWebService_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RemoveWebService",
+			Handler:       _ServiceDeploymentService_RemoveWebService_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DeployStaticSite",
+			Handler:       _ServiceDeploymentService_DeployStaticSite_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DeployDatabase",
+			Handler:       _ServiceDeploymentService_DeployDatabase_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DeployWorker",
+			Handler:       _ServiceDeploymentService_DeployWorker_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	DriftService_GetDriftReport_FullMethodName = "/mandau.services.v1.DriftService/GetDriftReport"
+)
+
+// DriftServiceClient is the client API for DriftService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Drift of the host artifacts Mandau manages from what it rendered: nginx
+// configs, units, cron files and zone files edited or removed since they
+// were written, and firewall rules deployments opened that are gone
+type DriftServiceClient interface {
+	GetDriftReport(ctx context.Context, in *GetDriftReportRequest, opts ...grpc.CallOption) (*DriftReport, error)
+}
+
+type driftServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDriftServiceClient(cc grpc.ClientConnInterface) DriftServiceClient {
+	return &driftServiceClient{cc}
+}
+
+func (c *driftServiceClient) GetDriftReport(ctx context.Context, in *GetDriftReportRequest, opts ...grpc.CallOption) (*DriftReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DriftReport)
+	err := c.cc.Invoke(ctx, DriftService_GetDriftReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DriftServiceServer is the server API for DriftService service.
+// All implementations must embed UnimplementedDriftServiceServer
+// for forward compatibility.
+//
+// Drift of the host artifacts Mandau manages from what it rendered: nginx
+// configs, units, cron files and zone files edited or removed since they
+// were written, and firewall rules deployments opened that are gone
+type DriftServiceServer interface {
+	GetDriftReport(context.Context, *GetDriftReportRequest) (*DriftReport, error)
+	mustEmbedUnimplementedDriftServiceServer()
+}
+
+// UnimplementedDriftServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDriftServiceServer struct{}
+
+func (UnimplementedDriftServiceServer) GetDriftReport(context.Context, *GetDriftReportRequest) (*DriftReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDriftReport not implemented")
+}
+func (UnimplementedDriftServiceServer) mustEmbedUnimplementedDriftServiceServer() {}
+func (UnimplementedDriftServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeDriftServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DriftServiceServer will
+// result in compilation errors.
+type UnsafeDriftServiceServer interface {
+	mustEmbedUnimplementedDriftServiceServer()
+}
+
+func RegisterDriftServiceServer(s grpc.ServiceRegistrar, srv DriftServiceServer) {
+	// If the following call panics, it indicates UnimplementedDriftServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DriftService_ServiceDesc, srv)
+}
+
+func _DriftService_GetDriftReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDriftReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriftServiceServer).GetDriftReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DriftService_GetDriftReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriftServiceServer).GetDriftReport(ctx, req.(*GetDriftReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DriftService_ServiceDesc is the grpc.ServiceDesc for DriftService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DriftService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.DriftService",
+	HandlerType: (*DriftServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDriftReport",
+			Handler:    _DriftService_GetDriftReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	PortService_ListListeningPorts_FullMethodName = "/mandau.services.v1.PortService/ListListeningPorts"
+)
+
+// PortServiceClient is the client API for PortService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Port inventory: what listens on the host and who owns it
+type PortServiceClient interface {
+	ListListeningPorts(ctx context.Context, in *ListListeningPortsRequest, opts ...grpc.CallOption) (*ListListeningPortsResponse, error)
+}
+
+type portServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPortServiceClient(cc grpc.ClientConnInterface) PortServiceClient {
+	return &portServiceClient{cc}
+}
+
+func (c *portServiceClient) ListListeningPorts(ctx context.Context, in *ListListeningPortsRequest, opts ...grpc.CallOption) (*ListListeningPortsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListListeningPortsResponse)
+	err := c.cc.Invoke(ctx, PortService_ListListeningPorts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PortServiceServer is the server API for PortService service.
+// All implementations must embed UnimplementedPortServiceServer
+// for forward compatibility.
+//
+// Port inventory: what listens on the host and who owns it
+type PortServiceServer interface {
+	ListListeningPorts(context.Context, *ListListeningPortsRequest) (*ListListeningPortsResponse, error)
+	mustEmbedUnimplementedPortServiceServer()
+}
+
+// UnimplementedPortServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPortServiceServer struct{}
+
+func (UnimplementedPortServiceServer) ListListeningPorts(context.Context, *ListListeningPortsRequest) (*ListListeningPortsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListListeningPorts not implemented")
+}
+func (UnimplementedPortServiceServer) mustEmbedUnimplementedPortServiceServer() {}
+func (UnimplementedPortServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafePortServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PortServiceServer will
+// result in compilation errors.
+type UnsafePortServiceServer interface {
+	mustEmbedUnimplementedPortServiceServer()
+}
+
+func RegisterPortServiceServer(s grpc.ServiceRegistrar, srv PortServiceServer) {
+	// If the following call panics, it indicates UnimplementedPortServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PortService_ServiceDesc, srv)
+}
+
+func _PortService_ListListeningPorts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListListeningPortsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PortServiceServer).ListListeningPorts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PortService_ListListeningPorts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PortServiceServer).ListListeningPorts(ctx, req.(*ListListeningPortsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PortService_ServiceDesc is the grpc.ServiceDesc for PortService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PortService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.PortService",
+	HandlerType: (*PortServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListListeningPorts",
+			Handler:    _PortService_ListListeningPorts_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	ProcessService_ListProcesses_FullMethodName = "/mandau.services.v1.ProcessService/ListProcesses"
+	ProcessService_SignalProcess_FullMethodName = "/mandau.services.v1.ProcessService/SignalProcess"
+)
+
+// ProcessServiceClient is the client API for ProcessService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Process inventory and control, for troubleshooting a host
+type ProcessServiceClient interface {
+	ListProcesses(ctx context.Context, in *ListProcessesRequest, opts ...grpc.CallOption) (*ListProcessesResponse, error)
+	SignalProcess(ctx context.Context, in *SignalProcessRequest, opts ...grpc.CallOption) (*SignalProcessResponse, error)
+}
+
+type processServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProcessServiceClient(cc grpc.ClientConnInterface) ProcessServiceClient {
+	return &processServiceClient{cc}
+}
+
+func (c *processServiceClient) ListProcesses(ctx context.Context, in *ListProcessesRequest, opts ...grpc.CallOption) (*ListProcessesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProcessesResponse)
+	err := c.cc.Invoke(ctx, ProcessService_ListProcesses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *processServiceClient) SignalProcess(ctx context.Context, in *SignalProcessRequest, opts ...grpc.CallOption) (*SignalProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignalProcessResponse)
+	err := c.cc.Invoke(ctx, ProcessService_SignalProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProcessServiceServer is the server API for ProcessService service.
+// All implementations must embed UnimplementedProcessServiceServer
+// for forward compatibility.
+//
+// Process inventory and control, for troubleshooting a host
+type ProcessServiceServer interface {
+	ListProcesses(context.Context, *ListProcessesRequest) (*ListProcessesResponse, error)
+	SignalProcess(context.Context, *SignalProcessRequest) (*SignalProcessResponse, error)
+	mustEmbedUnimplementedProcessServiceServer()
+}
+
+// UnimplementedProcessServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProcessServiceServer struct{}
+
+func (UnimplementedProcessServiceServer) ListProcesses(context.Context, *ListProcessesRequest) (*ListProcessesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListProcesses not implemented")
+}
+func (UnimplementedProcessServiceServer) SignalProcess(context.Context, *SignalProcessRequest) (*SignalProcessResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SignalProcess not implemented")
+}
+func (UnimplementedProcessServiceServer) mustEmbedUnimplementedProcessServiceServer() {}
+func (UnimplementedProcessServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeProcessServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProcessServiceServer will
+// result in compilation errors.
+type UnsafeProcessServiceServer interface {
+	mustEmbedUnimplementedProcessServiceServer()
+}
+
+func RegisterProcessServiceServer(s grpc.ServiceRegistrar, srv ProcessServiceServer) {
+	// If the following call panics, it indicates UnimplementedProcessServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProcessService_ServiceDesc, srv)
+}
+
+func _ProcessService_ListProcesses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProcessesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcessServiceServer).ListProcesses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProcessService_ListProcesses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcessServiceServer).ListProcesses(ctx, req.(*ListProcessesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProcessService_SignalProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcessServiceServer).SignalProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProcessService_SignalProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcessServiceServer).SignalProcess(ctx, req.(*SignalProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProcessService_ServiceDesc is the grpc.ServiceDesc for ProcessService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProcessService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.ProcessService",
+	HandlerType: (*ProcessServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListProcesses",
+			Handler:    _ProcessService_ListProcesses_Handler,
+		},
+		{
+			MethodName: "SignalProcess",
+			Handler:    _ProcessService_SignalProcess_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	HostLogService_ListLogFiles_FullMethodName = "/mandau.services.v1.HostLogService/ListLogFiles"
+	HostLogService_TailLogFile_FullMethodName  = "/mandau.services.v1.HostLogService/TailLogFile"
+)
+
+// HostLogServiceClient is the client API for HostLogService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Host log files, limited to the paths in the agent's logs.files
+type HostLogServiceClient interface {
+	ListLogFiles(ctx context.Context, in *ListLogFilesRequest, opts ...grpc.CallOption) (*ListLogFilesResponse, error)
+	TailLogFile(ctx context.Context, in *TailLogFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
+}
+
+type hostLogServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostLogServiceClient(cc grpc.ClientConnInterface) HostLogServiceClient {
+	return &hostLogServiceClient{cc}
+}
+
+func (c *hostLogServiceClient) ListLogFiles(ctx context.Context, in *ListLogFilesRequest, opts ...grpc.CallOption) (*ListLogFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLogFilesResponse)
+	err := c.cc.Invoke(ctx, HostLogService_ListLogFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostLogServiceClient) TailLogFile(ctx context.Context, in *TailLogFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HostLogService_ServiceDesc.Streams[0], HostLogService_TailLogFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TailLogFileRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HostLogService_TailLogFileClient = grpc.ServerStreamingClient[LogEntry]
+
+// HostLogServiceServer is the server API for HostLogService service.
+// All implementations must embed UnimplementedHostLogServiceServer
+// for forward compatibility.
+//
+// Host log files, limited to the paths in the agent's logs.files
+type HostLogServiceServer interface {
+	ListLogFiles(context.Context, *ListLogFilesRequest) (*ListLogFilesResponse, error)
+	TailLogFile(*TailLogFileRequest, grpc.ServerStreamingServer[LogEntry]) error
+	mustEmbedUnimplementedHostLogServiceServer()
+}
+
+// UnimplementedHostLogServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHostLogServiceServer struct{}
+
+func (UnimplementedHostLogServiceServer) ListLogFiles(context.Context, *ListLogFilesRequest) (*ListLogFilesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLogFiles not implemented")
+}
+func (UnimplementedHostLogServiceServer) TailLogFile(*TailLogFileRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Error(codes.Unimplemented, "method TailLogFile not implemented")
+}
+func (UnimplementedHostLogServiceServer) mustEmbedUnimplementedHostLogServiceServer() {}
+func (UnimplementedHostLogServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeHostLogServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostLogServiceServer will
+// result in compilation errors.
+type UnsafeHostLogServiceServer interface {
+	mustEmbedUnimplementedHostLogServiceServer()
+}
+
+func RegisterHostLogServiceServer(s grpc.ServiceRegistrar, srv HostLogServiceServer) {
+	// If the following call panics, it indicates UnimplementedHostLogServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HostLogService_ServiceDesc, srv)
 }
 
-type serviceDeploymentServiceClient struct {
+func _HostLogService_ListLogFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLogFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostLogServiceServer).ListLogFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostLogService_ListLogFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostLogServiceServer).ListLogFiles(ctx, req.(*ListLogFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostLogService_TailLogFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailLogFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HostLogServiceServer).TailLogFile(m, &grpc.GenericServerStream[TailLogFileRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HostLogService_TailLogFileServer = grpc.ServerStreamingServer[LogEntry]
+
+// HostLogService_ServiceDesc is the grpc.ServiceDesc for HostLogService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostLogService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.HostLogService",
+	HandlerType: (*HostLogServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLogFiles",
+			Handler:    _HostLogService_ListLogFiles_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TailLogFile",
+			Handler:       _HostLogService_TailLogFile_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	HostPowerService_RebootHost_FullMethodName = "/mandau.services.v1.HostPowerService/RebootHost"
+)
+
+// HostPowerServiceClient is the client API for HostPowerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Host reboots through systemd, each tracked as one operation that spans
+// the reboot
+type HostPowerServiceClient interface {
+	// Streams the operation until the host goes down. Once the agent is back
+	// and registered with the core it finishes the operation, whose outcome
+	// GetOperation returns.
+	RebootHost(ctx context.Context, in *RebootHostRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error)
+}
+
+type hostPowerServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewServiceDeploymentServiceClient(cc grpc.ClientConnInterface) ServiceDeploymentServiceClient {
-	return &serviceDeploymentServiceClient{cc}
+func NewHostPowerServiceClient(cc grpc.ClientConnInterface) HostPowerServiceClient {
+	return &hostPowerServiceClient{cc}
 }
 
-func (c *serviceDeploymentServiceClient) DeployWebService(ctx context.Context, in *DeployWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+func (c *hostPowerServiceClient) RebootHost(ctx context.Context, in *RebootHostRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[0], ServiceDeploymentService_DeployWebService_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &HostPowerService_ServiceDesc.Streams[0], HostPowerService_RebootHost_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[DeployWebServiceRequest, ServiceOperationEvent]{ClientStream: stream}
+	x := &grpc.GenericClientStream[RebootHostRequest, OperationEvent]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -1691,113 +3524,329 @@ func (c *serviceDeploymentServiceClient) DeployWebService(ctx context.Context, i
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ServiceDeploymentService_DeployWebServiceClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+type HostPowerService_RebootHostClient = grpc.ServerStreamingClient[OperationEvent]
 
-func (c *serviceDeploymentServiceClient) RemoveWebService(ctx context.Context, in *RemoveWebServiceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceOperationEvent], error) {
+// HostPowerServiceServer is the server API for HostPowerService service.
+// All implementations must embed UnimplementedHostPowerServiceServer
+// for forward compatibility.
+//
+// Host reboots through systemd, each tracked as one operation that spans
+// the reboot
+type HostPowerServiceServer interface {
+	// Streams the operation until the host goes down. Once the agent is back
+	// and registered with the core it finishes the operation, whose outcome
+	// GetOperation returns.
+	RebootHost(*RebootHostRequest, grpc.ServerStreamingServer[OperationEvent]) error
+	mustEmbedUnimplementedHostPowerServiceServer()
+}
+
+// UnimplementedHostPowerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHostPowerServiceServer struct{}
+
+func (UnimplementedHostPowerServiceServer) RebootHost(*RebootHostRequest, grpc.ServerStreamingServer[OperationEvent]) error {
+	return status.Error(codes.Unimplemented, "method RebootHost not implemented")
+}
+func (UnimplementedHostPowerServiceServer) mustEmbedUnimplementedHostPowerServiceServer() {}
+func (UnimplementedHostPowerServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeHostPowerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostPowerServiceServer will
+// result in compilation errors.
+type UnsafeHostPowerServiceServer interface {
+	mustEmbedUnimplementedHostPowerServiceServer()
+}
+
+func RegisterHostPowerServiceServer(s grpc.ServiceRegistrar, srv HostPowerServiceServer) {
+	// If the following call panics, it indicates UnimplementedHostPowerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HostPowerService_ServiceDesc, srv)
+}
+
+func _HostPowerService_RebootHost_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RebootHostRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HostPowerServiceServer).RebootHost(m, &grpc.GenericServerStream[RebootHostRequest, OperationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HostPowerService_RebootHostServer = grpc.ServerStreamingServer[OperationEvent]
+
+// HostPowerService_ServiceDesc is the grpc.ServiceDesc for HostPowerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostPowerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.HostPowerService",
+	HandlerType: (*HostPowerServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RebootHost",
+			Handler:       _HostPowerService_RebootHost_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	LogShipService_GetLogShipping_FullMethodName = "/mandau.services.v1.LogShipService/GetLogShipping"
+)
+
+// LogShipServiceClient is the client API for LogShipService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Shipping of container and journal logs to the sink in the agent's
+// logs.ship
+type LogShipServiceClient interface {
+	GetLogShipping(ctx context.Context, in *GetLogShippingRequest, opts ...grpc.CallOption) (*LogShipping, error)
+}
+
+type logShipServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogShipServiceClient(cc grpc.ClientConnInterface) LogShipServiceClient {
+	return &logShipServiceClient{cc}
+}
+
+func (c *logShipServiceClient) GetLogShipping(ctx context.Context, in *GetLogShippingRequest, opts ...grpc.CallOption) (*LogShipping, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ServiceDeploymentService_ServiceDesc.Streams[1], ServiceDeploymentService_RemoveWebService_FullMethodName, cOpts...)
+	out := new(LogShipping)
+	err := c.cc.Invoke(ctx, LogShipService_GetLogShipping_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[RemoveWebServiceRequest, ServiceOperationEvent]{ClientStream: stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	return out, nil
+}
+
+// LogShipServiceServer is the server API for LogShipService service.
+// All implementations must embed UnimplementedLogShipServiceServer
+// for forward compatibility.
+//
+// Shipping of container and journal logs to the sink in the agent's
+// logs.ship
+type LogShipServiceServer interface {
+	GetLogShipping(context.Context, *GetLogShippingRequest) (*LogShipping, error)
+	mustEmbedUnimplementedLogShipServiceServer()
+}
+
+// UnimplementedLogShipServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLogShipServiceServer struct{}
+
+func (UnimplementedLogShipServiceServer) GetLogShipping(context.Context, *GetLogShippingRequest) (*LogShipping, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLogShipping not implemented")
+}
+func (UnimplementedLogShipServiceServer) mustEmbedUnimplementedLogShipServiceServer() {}
+func (UnimplementedLogShipServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeLogShipServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogShipServiceServer will
+// result in compilation errors.
+type UnsafeLogShipServiceServer interface {
+	mustEmbedUnimplementedLogShipServiceServer()
+}
+
+func RegisterLogShipServiceServer(s grpc.ServiceRegistrar, srv LogShipServiceServer) {
+	// If the following call panics, it indicates UnimplementedLogShipServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LogShipService_ServiceDesc, srv)
+}
+
+func _LogShipService_GetLogShipping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogShippingRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	if interceptor == nil {
+		return srv.(LogShipServiceServer).GetLogShipping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogShipService_GetLogShipping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogShipServiceServer).GetLogShipping(ctx, req.(*GetLogShippingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LogShipService_ServiceDesc is the grpc.ServiceDesc for LogShipService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogShipService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.LogShipService",
+	HandlerType: (*LogShipServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogShipping",
+			Handler:    _LogShipService_GetLogShipping_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/service.proto",
+}
+
+const (
+	CommandService_ListCommands_FullMethodName = "/mandau.services.v1.CommandService/ListCommands"
+	CommandService_RunCommand_FullMethodName   = "/mandau.services.v1.CommandService/RunCommand"
+)
+
+// CommandServiceClient is the client API for CommandService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Commands from the agent config's commands allowlist, run by name
+type CommandServiceClient interface {
+	ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error)
+	RunCommand(ctx context.Context, in *RunCommandRequest, opts ...grpc.CallOption) (*CommandResult, error)
+}
+
+type commandServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCommandServiceClient(cc grpc.ClientConnInterface) CommandServiceClient {
+	return &commandServiceClient{cc}
+}
+
+func (c *commandServiceClient) ListCommands(ctx context.Context, in *ListCommandsRequest, opts ...grpc.CallOption) (*ListCommandsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCommandsResponse)
+	err := c.cc.Invoke(ctx, CommandService_ListCommands_FullMethodName, in, out, cOpts...)
+	if err != nil {
 		return nil, err
 	}
-	return x, nil
+	return out, nil
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ServiceDeploymentService_RemoveWebServiceClient = grpc.ServerStreamingClient[ServiceOperationEvent]
+func (c *commandServiceClient) RunCommand(ctx context.Context, in *RunCommandRequest, opts ...grpc.CallOption) (*CommandResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommandResult)
+	err := c.cc.Invoke(ctx, CommandService_RunCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// ServiceDeploymentServiceServer is the server API for ServiceDeploymentService service.
-// All implementations must embed UnimplementedServiceDeploymentServiceServer
+// CommandServiceServer is the server API for CommandService service.
+// All implementations must embed UnimplementedCommandServiceServer
 // for forward compatibility.
 //
-// Complete Service Deployment
-type ServiceDeploymentServiceServer interface {
-	DeployWebService(*DeployWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
-	RemoveWebService(*RemoveWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error
-	mustEmbedUnimplementedServiceDeploymentServiceServer()
+// Commands from the agent config's commands allowlist, run by name
+type CommandServiceServer interface {
+	ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error)
+	RunCommand(context.Context, *RunCommandRequest) (*CommandResult, error)
+	mustEmbedUnimplementedCommandServiceServer()
 }
 
-// UnimplementedServiceDeploymentServiceServer must be embedded to have
+// UnimplementedCommandServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedServiceDeploymentServiceServer struct{}
+type UnimplementedCommandServiceServer struct{}
 
-func (UnimplementedServiceDeploymentServiceServer) DeployWebService(*DeployWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
-	return status.Error(codes.Unimplemented, "method DeployWebService not implemented")
-}
-func (UnimplementedServiceDeploymentServiceServer) RemoveWebService(*RemoveWebServiceRequest, grpc.ServerStreamingServer[ServiceOperationEvent]) error {
-	return status.Error(codes.Unimplemented, "method RemoveWebService not implemented")
+func (UnimplementedCommandServiceServer) ListCommands(context.Context, *ListCommandsRequest) (*ListCommandsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCommands not implemented")
 }
-func (UnimplementedServiceDeploymentServiceServer) mustEmbedUnimplementedServiceDeploymentServiceServer() {
+func (UnimplementedCommandServiceServer) RunCommand(context.Context, *RunCommandRequest) (*CommandResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunCommand not implemented")
 }
-func (UnimplementedServiceDeploymentServiceServer) testEmbeddedByValue() {}
+func (UnimplementedCommandServiceServer) mustEmbedUnimplementedCommandServiceServer() {}
+func (UnimplementedCommandServiceServer) testEmbeddedByValue()                        {}
 
-// UnsafeServiceDeploymentServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to ServiceDeploymentServiceServer will
+// UnsafeCommandServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CommandServiceServer will
 // result in compilation errors.
-type UnsafeServiceDeploymentServiceServer interface {
-	mustEmbedUnimplementedServiceDeploymentServiceServer()
+type UnsafeCommandServiceServer interface {
+	mustEmbedUnimplementedCommandServiceServer()
 }
 
-func RegisterServiceDeploymentServiceServer(s grpc.ServiceRegistrar, srv ServiceDeploymentServiceServer) {
-	// If the following call panics, it indicates UnimplementedServiceDeploymentServiceServer was
+func RegisterCommandServiceServer(s grpc.ServiceRegistrar, srv CommandServiceServer) {
+	// If the following call panics, it indicates UnimplementedCommandServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&ServiceDeploymentService_ServiceDesc, srv)
+	s.RegisterService(&CommandService_ServiceDesc, srv)
 }
 
-func _ServiceDeploymentService_DeployWebService_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(DeployWebServiceRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _CommandService_ListCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(ServiceDeploymentServiceServer).DeployWebService(m, &grpc.GenericServerStream[DeployWebServiceRequest, ServiceOperationEvent]{ServerStream: stream})
+	if interceptor == nil {
+		return srv.(CommandServiceServer).ListCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandService_ListCommands_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).ListCommands(ctx, req.(*ListCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ServiceDeploymentService_DeployWebServiceServer = grpc.ServerStreamingServer[ServiceOperationEvent]
-
-func _ServiceDeploymentService_RemoveWebService_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(RemoveWebServiceRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _CommandService_RunCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(ServiceDeploymentServiceServer).RemoveWebService(m, &grpc.GenericServerStream[RemoveWebServiceRequest, ServiceOperationEvent]{ServerStream: stream})
+	if interceptor == nil {
+		return srv.(CommandServiceServer).RunCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandService_RunCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).RunCommand(ctx, req.(*RunCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ServiceDeploymentService_RemoveWebServiceServer = grpc.ServerStreamingServer[ServiceOperationEvent]
-
-// ServiceDeploymentService_ServiceDesc is the grpc.ServiceDesc for ServiceDeploymentService service.
+// CommandService_ServiceDesc is the grpc.ServiceDesc for CommandService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var ServiceDeploymentService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "mandau.services.v1.ServiceDeploymentService",
-	HandlerType: (*ServiceDeploymentServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
-	Streams: []grpc.StreamDesc{
+var CommandService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandau.services.v1.CommandService",
+	HandlerType: (*CommandServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
 		{
-			StreamName:    "DeployWebService",
-			Handler:       _ServiceDeploymentService_DeployWebService_Handler,
-			ServerStreams: true,
+			MethodName: "ListCommands",
+			Handler:    _CommandService_ListCommands_Handler,
 		},
 		{
-			StreamName:    "RemoveWebService",
-			Handler:       _ServiceDeploymentService_RemoveWebService_Handler,
-			ServerStreams: true,
+			MethodName: "RunCommand",
+			Handler:    _CommandService_RunCommand_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/service.proto",
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// clockState tracks this host's clock offset from the core's, measured
+// from heartbeat round trips. Only the heartbeat routine uses it.
+type clockState struct {
+	offset *time.Duration // Agent clock minus core clock, once measured
+	skewed bool           // The core last flagged the offset as too large
+}
+
+// report returns the offset to send with the next heartbeat
+func (c *clockState) report() *durationpb.Duration {
+	if c.offset == nil {
+		return nil
+	}
+	return durationpb.New(*c.offset)
+}
+
+// observe measures the offset from a heartbeat sent at sent and answered
+// at received, taking the core's time to be read halfway between, as NTP
+// does. It warns when the core starts or stops flagging the clock.
+func (c *clockState) observe(resp *agentv1.HeartbeatResponse, sent, received time.Time) {
+	if resp.CoreTime == nil {
+		return // Cores predating skew checks
+	}
+	offset := sent.Add(received.Sub(sent) / 2).Sub(resp.CoreTime.AsTime())
+	c.offset = &offset
+
+	if resp.ClockSkewed == c.skewed {
+		return
+	}
+	c.skewed = resp.ClockSkewed
+	if c.skewed {
+		fmt.Printf("Warning: clock is off by %s from the core's; sync it with chrony or NTP\n", offset.Round(time.Millisecond))
+	} else {
+		fmt.Println("Clock is back in sync with the core's")
+	}
+}
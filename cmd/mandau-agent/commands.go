@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/command"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// commandAllowlist reads commands, leaving out malformed and duplicate
+// entries
+func commandAllowlist(cfgs []config.CommandConfig) command.Allowlist {
+	allow := make(command.Allowlist)
+	for _, cfg := range cfgs {
+		cmd, err := command.New(cfg)
+		if err != nil {
+			fmt.Printf("Warning: ignoring commands entry: %v\n", err)
+			continue
+		}
+		if _, ok := allow[cmd.Name]; ok {
+			fmt.Printf("Warning: ignoring commands entry: %s is listed twice\n", cmd.Name)
+			continue
+		}
+		allow[cmd.Name] = cmd
+	}
+	return allow
+}
+
+// ListCommands lists the commands the agent config allows
+func (a *Agent) ListCommands(ctx context.Context, req *agentv1.ListCommandsRequest) (*agentv1.ListCommandsResponse, error) {
+	resp := &agentv1.ListCommandsResponse{}
+	for _, cmd := range a.commands.Commands() {
+		resp.Commands = append(resp.Commands, &agentv1.AllowedCommand{
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Timeout:     durationpb.New(cmd.Timeout),
+		})
+	}
+	return resp, nil
+}
+
+// RunCommand runs an allowed command and returns the end of its output.
+// Secrets in it are masked as in stack logs. The caller's exec timeout
+// bounds it as it bounds their exec sessions.
+func (a *Agent) RunCommand(ctx context.Context, req *agentv1.RunCommandRequest) (*agentv1.CommandResult, error) {
+	cmd, err := a.commands.Get(req.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v: add it to commands in the agent config", err)
+	}
+
+	result := cmd.Run(ctx, req.Timeout.AsDuration(), a.execTimeout(ctx))
+	requestid.Logf(ctx, "Ran command %s: exit code %d in %s", cmd.Name, result.ExitCode, result.Duration.Round(time.Millisecond))
+	resp := &agentv1.CommandResult{
+		AgentId:   a.config.AgentID,
+		ExitCode:  int32(result.ExitCode),
+		Stdout:    []byte(a.redactor.String(string(result.Stdout))),
+		Stderr:    []byte(a.redactor.String(string(result.Stderr))),
+		Truncated: result.Truncated,
+		Duration:  durationpb.New(result.Duration),
+	}
+	if result.Err != nil {
+		resp.Error = result.Err.Error()
+	}
+	return resp, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/command"
+	"github.com/bhangun/mandau/pkg/redact"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRunCommandExecTimeout(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{
+		config:    &Config{AgentID: "agent-1"},
+		redactor:  redactor,
+		execLimit: 100 * time.Millisecond,
+		commands: command.Allowlist{"wait": {
+			Name:    "wait",
+			Argv:    []string{"/bin/sh", "-c", "sleep 10"},
+			Timeout: time.Minute,
+		}},
+	}
+
+	// The exec timeout bounds commands as it bounds exec sessions, below
+	// the command's own timeout and the caller's
+	resp, err := a.RunCommand(context.Background(), &agentv1.RunCommandRequest{Name: "wait", Timeout: durationpb.New(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Error, "timed out after 100ms") || resp.ExitCode != -1 {
+		t.Errorf("result = %+v, want the exec timeout", resp)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/diagnose"
+	"github.com/bhangun/mandau/pkg/transport"
+	"github.com/moby/moby/client"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Diagnose runs the agent-side checks of "mandau doctor"
+func (a *Agent) Diagnose(ctx context.Context, req *agentv1.DiagnoseRequest) (*agentv1.DiagnoseResponse, error) {
+	checks := []diagnose.Check{
+		// The core dials agents with this server name
+		diagnose.ServerCertFile("agent certificate", a.config.CertPath, "mandau-agent"),
+		diagnose.CertFile("agent CA", a.config.CAPath),
+		a.checkDocker(ctx),
+		checkCompose(ctx),
+		a.checkPlugins(),
+		diagnose.Writable("stack root", a.config.StackRoot),
+		diagnose.DiskSpace("disk space", a.config.StackRoot),
+	}
+	checks = append(checks, a.checkMirrors()...)
+
+	return &agentv1.DiagnoseResponse{
+		Checks: diagnose.ToProto("agent:"+a.config.AgentID, checks),
+		Time:   timestamppb.Now(),
+	}, nil
+}
+
+// CheckCoreConnection dials the core afresh for "mandau agent ping", which
+// shows whether the agent could reconnect if its channel broke
+func (a *Agent) CheckCoreConnection(ctx context.Context, req *agentv1.CheckCoreConnectionRequest) (*agentv1.CoreConnectivity, error) {
+	resp := &agentv1.CoreConnectivity{CoreAddress: a.config.ServerAddr}
+	if a.serverConn != nil {
+		resp.ChannelState = a.serverConn.GetState().String()
+	}
+
+	tlsConfig, err := transport.ClientConfig(peerTLS(a.config, func(c *config.AgentConfig) config.TLSConfig {
+		return c.ServerConnection.TLS
+	}), "mandau-core")
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	probe := diagnose.ProbeTLS(ctx, nil, a.config.ServerAddr, tlsConfig)
+	resp.Handshake = durationpb.New(probe.Handshake)
+	resp.CoreCertificate = diagnose.PeerCertificateToProto(probe.Certificate, tlsConfig.ServerName, probe.Err)
+	if probe.Err != nil {
+		resp.Error = probe.Err.Error()
+	}
+	return resp, nil
+}
+
+func (a *Agent) checkDocker(ctx context.Context) diagnose.Check {
+	if _, err := a.docker.Ping(ctx, client.PingOptions{}); err != nil {
+		return diagnose.Fail("docker daemon", err.Error(),
+			"Start Docker (systemctl start docker) and make sure the agent user can access the Docker socket")
+	}
+	return diagnose.OK("docker daemon", "reachable")
+}
+
+func checkCompose(ctx context.Context) diagnose.Check {
+	out, err := exec.CommandContext(ctx, "docker", "compose", "version", "--short").CombinedOutput()
+	if err != nil {
+		return diagnose.Fail("docker compose", strings.TrimSpace(string(out)),
+			"Install the Docker Compose v2 plugin (docker-compose-plugin package)")
+	}
+	return diagnose.OK("docker compose", "version "+strings.TrimSpace(string(out)))
+}
+
+// checkPlugins lists loaded plugins; the agent refuses to start when a
+// plugin fails Init, so every listed plugin is initialized
+func (a *Agent) checkPlugins() diagnose.Check {
+	var names []string
+	for _, p := range a.plugins.ListAll() {
+		names = append(names, p.Name()+"@"+p.Version())
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return diagnose.OK("plugins", "none loaded")
+	}
+	return diagnose.OK("plugins", strings.Join(names, ", "))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/agent/service"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// parseDriftInterval parses plugins.drift_interval; zero turns drift scans
+// off
+func parseDriftInterval(value string) time.Duration {
+	if value == "" {
+		return service.DefaultDriftInterval
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		fmt.Printf("Warning: invalid plugins.drift_interval %q, using %s\n", value, service.DefaultDriftInterval)
+		return service.DefaultDriftInterval
+	}
+	return d
+}
+
+// watchDrift scans the host service artifacts for drift until the agent
+// stops. Each artifact that drifts, and each that matches again, is audited
+// as host.drift so anomaly rules can notify about it.
+func (a *Agent) watchDrift(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stop
+		cancel()
+	}()
+
+	a.services.WatchDrift(ctx, interval, func(d service.Drift, resolved bool) {
+		result := "drifted"
+		if resolved {
+			result = "resolved"
+		}
+		fmt.Printf("Drift %s: %s %s (%s)\n", result, d.Kind, d.Name, d.State)
+
+		md := map[string]string{"kind": d.Kind, "state": d.State}
+		if d.Detail != "" {
+			md["detail"] = d.Detail
+		}
+		a.plugins.AuditAll(ctx, &plugin.AuditEntry{
+			Timestamp: time.Now(),
+			AgentID:   a.config.AgentID,
+			Identity:  &plugin.Identity{UserID: "agent:" + a.config.AgentID},
+			Action:    "host.drift",
+			Resource:  "host:" + d.Name,
+			Result:    result,
+			Metadata:  md,
+		})
+	})
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/procs"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultExecTimeout applies when security.exec_timeout is unset or invalid
+const defaultExecTimeout = time.Hour
+
+// execWarning is how long before the timeout the user is warned
+var execWarning = time.Minute
+
+// procRoot is where the processes of timed out exec sessions are checked
+// before they are killed
+var procRoot = "/proc"
+
+// parseExecTimeout reads security.exec_timeout; "0" disables the limit
+func parseExecTimeout(value string) time.Duration {
+	if value == "" {
+		return defaultExecTimeout
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		fmt.Printf("Warning: invalid security.exec_timeout %q, using %s\n", value, defaultExecTimeout)
+		return defaultExecTimeout
+	}
+	return d
+}
+
+// execTimeout returns how long an exec session of the caller may run: the
+// exec_timeout policy obligation if present, else security.exec_timeout.
+// Zero means no limit.
+func (a *Agent) execTimeout(ctx context.Context) time.Duration {
+	decision := policyDecision(ctx)
+	if decision == nil {
+		return a.execLimit
+	}
+	value, ok := decision.Obligation(plugin.ObligationExecTimeout)
+	if !ok {
+		return a.execLimit
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		fmt.Printf("Warning: ignoring invalid %s obligation %q\n", plugin.ObligationExecTimeout, value)
+		return a.execLimit
+	}
+	return d
+}
+
+// Exec runs a command in a container, streaming stdin, stdout and stderr.
+// Sessions running past the exec timeout are warned, then terminated: the
+// command is killed and the connection hung up.
+func (a *Agent) Exec(stream agentv1.ContainerService_ExecServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "first exec message must be start")
+	}
+	if start.ContainerId == "" || len(start.Cmd) == 0 {
+		return status.Error(codes.InvalidArgument, "container_id and cmd are required")
+	}
+
+	ctx := stream.Context()
+	created, err := a.docker.ExecCreate(ctx, start.ContainerId, client.ExecCreateOptions{
+		User:         start.User,
+		TTY:          start.Tty,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          execEnv(start.Env),
+		WorkingDir:   start.WorkingDir,
+		Cmd:          start.Cmd,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "exec create: %v", err)
+	}
+	attached, err := a.docker.ExecAttach(ctx, created.ID, client.ExecAttachOptions{TTY: start.Tty})
+	if err != nil {
+		return status.Errorf(codes.Internal, "exec attach: %v", err)
+	}
+	defer attached.Close()
+
+	out := &execSender{stream: stream}
+	go a.execInput(ctx, stream, created.ID, attached)
+
+	done := make(chan error, 1)
+	go func() {
+		if start.Tty {
+			_, err := io.Copy(out.writer(false), attached.Reader)
+			done <- err
+			return
+		}
+		_, err := stdcopy.StdCopy(out.writer(false), out.writer(true), attached.Reader)
+		done <- err
+	}()
+
+	timeout := a.execTimeout(ctx)
+	var expired, warn <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+		if timeout > 2*execWarning {
+			warning := time.NewTimer(timeout - execWarning)
+			defer warning.Stop()
+			warn = warning.C
+		}
+	}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil && ctx.Err() == nil {
+				return status.Errorf(codes.Internal, "exec output: %v", err)
+			}
+			inspect, err := a.docker.ExecInspect(ctx, created.ID, client.ExecInspectOptions{})
+			if err != nil {
+				return status.Errorf(codes.Internal, "exec inspect: %v", err)
+			}
+			return out.send(&agentv1.ExecResponse{
+				Payload: &agentv1.ExecResponse_ExitCode{ExitCode: int32(inspect.ExitCode)},
+			})
+		case <-warn:
+			out.send(&agentv1.ExecResponse{
+				Payload: &agentv1.ExecResponse_Stderr{
+					Stderr: []byte(fmt.Sprintf("\r\nmandau: this session will be terminated in %s (exec timeout %s)\r\n", execWarning, timeout)),
+				},
+			})
+		case <-expired:
+			killErr := a.killExec(ctx, created.ID)
+			if killErr != nil {
+				requestid.Logf(ctx, "Exec %s timed out but could not be killed: %v", created.ID, killErr)
+			}
+			attached.Close()
+			out.send(&agentv1.ExecResponse{
+				Payload: &agentv1.ExecResponse_Error{
+					Error: fmt.Sprintf("session terminated: exec timeout of %s reached", timeout),
+				},
+			})
+			a.auditExecTimeout(ctx, start, timeout, killErr)
+			return status.Errorf(codes.DeadlineExceeded, "exec timeout of %s reached", timeout)
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// execInput forwards stdin and resizes from the client until it closes
+// the stream, then closes the command's stdin
+func (a *Agent) execInput(ctx context.Context, stream agentv1.ContainerService_ExecServer, execID string, attached client.ExecAttachResult) {
+	defer attached.CloseWrite()
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		switch p := req.Payload.(type) {
+		case *agentv1.ExecRequest_Stdin:
+			if _, err := attached.Conn.Write(p.Stdin); err != nil {
+				return
+			}
+		case *agentv1.ExecRequest_Resize:
+			a.docker.ExecResize(ctx, execID, client.ExecResizeOptions{
+				Height: uint(p.Resize.Height),
+				Width:  uint(p.Resize.Width),
+			})
+		}
+	}
+}
+
+// killExec kills the process of an exec session with its process group,
+// since commands may ignore the hangup of their connection. Docker reports
+// the PID in the host's namespace, so it is signalled only when /proc shows
+// it inside the exec's container; an agent that does not share the host's
+// PIDs would otherwise hit an unrelated process.
+func (a *Agent) killExec(ctx context.Context, execID string) error {
+	inspect, err := a.docker.ExecInspect(ctx, execID, client.ExecInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("exec inspect: %w", err)
+	}
+	if !inspect.Running || inspect.PID <= 0 {
+		return nil
+	}
+	p, err := procs.Get(procRoot, inspect.PID)
+	if err != nil {
+		return err
+	}
+	if p.ContainerID == "" || !strings.HasPrefix(inspect.ContainerID, p.ContainerID) {
+		return fmt.Errorf("process %d is not in container %s", inspect.PID, inspect.ContainerID)
+	}
+	// Fails harmlessly when the process does not lead its group
+	syscall.Kill(-inspect.PID, syscall.SIGKILL)
+	if err := syscall.Kill(inspect.PID, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("kill %d: %w", inspect.PID, err)
+	}
+	return nil
+}
+
+// auditExecTimeout records a session terminated by the exec timeout, next
+// to the entry of the Exec call itself. killErr is why its command could
+// not be killed, if it could not.
+func (a *Agent) auditExecTimeout(ctx context.Context, start *agentv1.ExecStart, timeout time.Duration, killErr error) {
+	md := map[string]string{
+		"command": start.Cmd[0],
+		"timeout": timeout.String(),
+		"tty":     strconv.FormatBool(start.Tty),
+		"killed":  strconv.FormatBool(killErr == nil),
+	}
+	if killErr != nil {
+		md["error"] = killErr.Error()
+	}
+	a.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		AgentID:   a.config.AgentID,
+		Identity:  plugin.IdentityFromContext(ctx),
+		Action:    "exec.timeout",
+		Resource:  "container:" + start.ContainerId,
+		Result:    "terminated",
+		Metadata:  md,
+	})
+}
+
+// execSender serializes sends from the output copier and the timeout loop
+type execSender struct {
+	mu     sync.Mutex
+	stream agentv1.ContainerService_ExecServer
+}
+
+func (s *execSender) send(resp *agentv1.ExecResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Send(resp)
+}
+
+// writer returns an io.Writer sending what is written as stdout or stderr
+func (s *execSender) writer(stderr bool) io.Writer {
+	return execWriter{sender: s, stderr: stderr}
+}
+
+type execWriter struct {
+	sender *execSender
+	stderr bool
+}
+
+func (w execWriter) Write(p []byte) (int, error) {
+	data := append([]byte(nil), p...)
+	resp := &agentv1.ExecResponse{Payload: &agentv1.ExecResponse_Stdout{Stdout: data}}
+	if w.stderr {
+		resp.Payload = &agentv1.ExecResponse_Stderr{Stderr: data}
+	}
+	if err := w.sender.send(resp); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// execStart returns the start message of an exec request, or nil
+func execStart(req interface{}) *agentv1.ExecStart {
+	if r, ok := req.(*agentv1.ExecRequest); ok {
+		return r.GetStart()
+	}
+	return nil
+}
+
+// execEnv turns the requested environment into KEY=value pairs
+func execEnv(env map[string]string) []string {
+	pairs := make([]string, 0, len(env))
+	for k, v := range env {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
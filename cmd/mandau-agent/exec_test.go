@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/moby/moby/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeDocker serves a Docker API without containers whose exec sessions
+// print one line and then run until the client hangs up
+func fakeDocker(t *testing.T) *client.Client {
+	t.Helper()
+	return fakeDockerExec(t, `{"ID":"exec-1","Running":false,"ExitCode":0}`)
+}
+
+// fakeDockerExec is fakeDocker answering exec inspections with inspect
+func fakeDockerExec(t *testing.T, inspect string) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			w.Header().Set("Api-Version", client.MaxAPIVersion)
+			io.WriteString(w, "OK")
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/exec"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"Id":"exec-1"}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/exec/exec-1/start"):
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			io.WriteString(conn, "HTTP/1.1 101 UPGRADED\r\n"+
+				"Content-Type: application/vnd.docker.multiplexed-stream\r\n"+
+				"Connection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			// One stdout frame, then nothing until the session is closed
+			frame := make([]byte, 8, 8+len("started\n"))
+			frame[0] = 1
+			binary.BigEndian.PutUint32(frame[4:], uint32(len("started\n")))
+			conn.Write(append(frame, "started\n"...))
+			io.Copy(io.Discard, conn)
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, "[]")
+		case strings.HasSuffix(r.URL.Path, "/exec/exec-1/json"):
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, inspect)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	docker, err := client.New(client.WithHost("tcp://" + server.Listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { docker.Close() })
+	return docker
+}
+
+// execPolicy allows everything, with the obligations listed
+type execPolicy struct{ obligations []string }
+
+func (p *execPolicy) Name() string    { return "exec-policy" }
+func (p *execPolicy) Version() string { return "test" }
+func (p *execPolicy) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityPolicy}
+}
+func (p *execPolicy) Init(ctx context.Context, config map[string]interface{}) error { return nil }
+func (p *execPolicy) Shutdown(ctx context.Context) error                            { return nil }
+func (p *execPolicy) Evaluate(ctx context.Context, req *plugin.PolicyRequest) (*plugin.PolicyDecision, error) {
+	return &plugin.PolicyDecision{Allowed: true, Obligations: p.obligations}, nil
+}
+
+type execAudit struct {
+	mu      sync.Mutex
+	entries []*plugin.AuditEntry
+}
+
+func (r *execAudit) Name() string    { return "exec-audit" }
+func (r *execAudit) Version() string { return "test" }
+func (r *execAudit) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityAudit}
+}
+func (r *execAudit) Init(ctx context.Context, config map[string]interface{}) error { return nil }
+func (r *execAudit) Shutdown(ctx context.Context) error                            { return nil }
+func (r *execAudit) Log(ctx context.Context, entry *plugin.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+func (r *execAudit) Query(ctx context.Context, filter *plugin.AuditFilter) ([]plugin.AuditEntry, error) {
+	return nil, nil
+}
+
+func (r *execAudit) find(action string) *plugin.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range r.entries {
+		if entry.Action == action {
+			return entry
+		}
+	}
+	return nil
+}
+
+// serveExec serves the container service of a through the agent's stream
+// interceptors, authenticating every caller as alice
+func serveExec(t *testing.T, a *Agent) agentv1.ContainerServiceClient {
+	t.Helper()
+	asAlice := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{
+			ServerStream: ss,
+			ctx:          plugin.WithIdentity(ss.Context(), &plugin.Identity{UserID: "alice"}),
+		})
+	}
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainStreamInterceptor(
+		requestid.StreamServerInterceptor,
+		asAlice,
+		a.auditStreamInterceptor,
+		a.policyStreamInterceptor,
+		a.recoveryStreamInterceptor,
+	))
+	agentv1.RegisterContainerServiceServer(server, a)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///agent",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return agentv1.NewContainerServiceClient(conn)
+}
+
+func TestExecTimeout(t *testing.T) {
+	defer func(warning time.Duration) { execWarning = warning }(execWarning)
+	execWarning = 100 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		limit       time.Duration // security.exec_timeout
+		obligations []string
+		want        time.Duration
+	}{
+		{name: "configured", limit: 400 * time.Millisecond, want: 400 * time.Millisecond},
+		{
+			name:        "obligation",
+			limit:       time.Hour,
+			obligations: []string{plugin.ObligationExecTimeout + "=300ms"},
+			want:        300 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The exec's command is a process of the test, placed in the
+			// container by a fake /proc
+			const containerID = "4f1c2a9e8b7d6c5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f"
+			command := osexec.Command("sleep", "30")
+			if err := command.Start(); err != nil {
+				t.Fatal(err)
+			}
+			exited := make(chan error, 1)
+			go func() { exited <- command.Wait() }()
+			defer command.Process.Kill()
+			defer func(root string) { procRoot = root }(procRoot)
+			procRoot = t.TempDir()
+			pid := strconv.Itoa(command.Process.Pid)
+			stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+			if err != nil {
+				t.Skipf("no procfs: %v", err)
+			}
+			os.Mkdir(filepath.Join(procRoot, pid), 0755)
+			os.WriteFile(filepath.Join(procRoot, pid, "stat"), stat, 0644)
+			os.WriteFile(filepath.Join(procRoot, pid, "cgroup"), []byte("0::/system.slice/docker-"+containerID+".scope\n"), 0644)
+
+			auditor := &execAudit{}
+			plugins := plugin.NewRegistry()
+			for _, p := range []plugin.Plugin{auditor, &execPolicy{obligations: tt.obligations}} {
+				if err := plugins.Register(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a := &Agent{
+				config:    &Config{AgentID: "agent-1"},
+				docker:    fakeDockerExec(t, `{"ID":"exec-1","ContainerID":"`+containerID+`","Running":true,"Pid":`+pid+`}`),
+				plugins:   plugins,
+				execLimit: tt.limit,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			exec, err := serveExec(t, a).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = exec.Send(&agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Start{Start: &agentv1.ExecStart{
+				ContainerId: "web-1",
+				Cmd:         []string{"sh"},
+			}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			began := time.Now()
+			var stdout, warning, terminated string
+			for {
+				resp, err := exec.Recv()
+				if err != nil {
+					if status.Code(err) != codes.DeadlineExceeded || ctx.Err() != nil {
+						t.Fatalf("session ended with %v, want the exec timeout", err)
+					}
+					break
+				}
+				switch p := resp.Payload.(type) {
+				case *agentv1.ExecResponse_Stdout:
+					stdout += string(p.Stdout)
+				case *agentv1.ExecResponse_Stderr:
+					warning += string(p.Stderr)
+				case *agentv1.ExecResponse_Error:
+					terminated = p.Error
+				case *agentv1.ExecResponse_ExitCode:
+					t.Fatalf("session exited with %d, want it terminated", p.ExitCode)
+				}
+			}
+			if elapsed := time.Since(began); elapsed < tt.want {
+				t.Errorf("terminated after %s, before the %s timeout", elapsed, tt.want)
+			}
+
+			if stdout != "started\n" {
+				t.Errorf("stdout = %q", stdout)
+			}
+			if !strings.Contains(warning, "will be terminated in 100ms (exec timeout "+tt.want.String()+")") {
+				t.Errorf("warning = %q", warning)
+			}
+			if terminated != "session terminated: exec timeout of "+tt.want.String()+" reached" {
+				t.Errorf("error frame = %q", terminated)
+			}
+
+			entry := auditor.find("exec.timeout")
+			if entry == nil {
+				t.Fatal("no exec.timeout audit entry")
+			}
+			if entry.Resource != "container:web-1" || entry.Result != "terminated" || entry.AgentID != "agent-1" ||
+				entry.Identity == nil || entry.Identity.UserID != "alice" {
+				t.Errorf("exec.timeout entry = %+v", entry)
+			}
+			if entry.Metadata["timeout"] != tt.want.String() || entry.Metadata["command"] != "sh" || entry.Metadata["killed"] != "true" {
+				t.Errorf("exec.timeout metadata = %v", entry.Metadata)
+			}
+
+			// The command no longer runs once the session is terminated
+			select {
+			case err := <-exited:
+				if status, ok := command.ProcessState.Sys().(syscall.WaitStatus); !ok || status.Signal() != syscall.SIGKILL {
+					t.Errorf("command ended with %v, want it killed", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("command still running after the exec timeout")
+			}
+			if call := auditor.find(agentv1.ContainerService_Exec_FullMethodName); call == nil || call.Result != "error" {
+				t.Errorf("Exec call entry = %+v, want an error", call)
+			}
+		})
+	}
+}
+
+func TestKillExecChecksContainer(t *testing.T) {
+	command := osexec.Command("sleep", "30")
+	if err := command.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer command.Process.Kill()
+	defer func(root string) { procRoot = root }(procRoot)
+	procRoot = t.TempDir()
+	pid := strconv.Itoa(command.Process.Pid)
+	stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+	if err != nil {
+		t.Skipf("no procfs: %v", err)
+	}
+	os.Mkdir(filepath.Join(procRoot, pid), 0755)
+	os.WriteFile(filepath.Join(procRoot, pid, "stat"), stat, 0644)
+	os.WriteFile(filepath.Join(procRoot, pid, "cgroup"), []byte("0::/user.slice\n"), 0644)
+
+	// A PID outside the exec's container is some other process to this
+	// agent, and is left alone
+	a := &Agent{docker: fakeDockerExec(t, `{"ID":"exec-1","ContainerID":"4f1c2a9e8b7d","Running":true,"Pid":`+pid+`}`)}
+	if err := a.killExec(context.Background(), "exec-1"); err == nil || !strings.Contains(err.Error(), "not in container") {
+		t.Errorf("killExec = %v, want a refusal", err)
+	}
+	if err := command.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("process outside the container was signalled: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// defaultHeartbeatInterval is used until the core names one
+	defaultHeartbeatInterval = 30 * time.Second
+	// heartbeatTimeout bounds the round trip of one heartbeat
+	heartbeatTimeout = 5 * time.Second
+)
+
+// heartbeatChannel sends heartbeats over one HeartbeatStream per core
+// connection, or a Heartbeat call each for cores without the stream. Only
+// the heartbeat routine sends; registrations, which may run on reload, set
+// the interval too.
+type heartbeatChannel struct {
+	conn     *grpc.ClientConn // Connection the stream is on
+	stream   agentv1.CoreService_HeartbeatStreamClient
+	cancel   context.CancelFunc // Ends the stream
+	unary    bool               // The core does not serve HeartbeatStream
+	interval atomic.Int64       // As the core last asked; zero until it does
+}
+
+// exchange sends req on conn and returns the core's answer
+func (h *heartbeatChannel) exchange(conn *grpc.ClientConn, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	if conn != h.conn {
+		// Reconnected; the new core may serve the stream
+		h.close()
+		h.conn, h.unary = conn, false
+	}
+	if h.unary {
+		return h.call(conn, req)
+	}
+
+	if h.stream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := agentv1.NewCoreServiceClient(conn).HeartbeatStream(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		h.stream, h.cancel = stream, cancel
+	}
+
+	resp, err := h.roundTrip(req)
+	if err != nil {
+		h.close()
+		if status.Code(err) == codes.Unimplemented {
+			fmt.Println("Core does not serve heartbeat streams; sending a call per heartbeat")
+			h.unary = true
+			return h.call(conn, req)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// roundTrip sends req on the stream and waits for its answer, ending the
+// stream if none comes within heartbeatTimeout
+func (h *heartbeatChannel) roundTrip(req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	timer := time.AfterFunc(heartbeatTimeout, h.cancel)
+
+	// Send reports io.EOF once the stream ended; Recv says why
+	if err := h.stream.Send(req); err != nil && !errors.Is(err, io.EOF) {
+		timer.Stop()
+		return nil, err
+	}
+	resp, err := h.stream.Recv()
+	if !timer.Stop() && err != nil {
+		return nil, status.Errorf(codes.DeadlineExceeded, "heartbeat unanswered after %s", heartbeatTimeout)
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, status.Error(codes.Unavailable, "core ended the heartbeat stream")
+	}
+	return resp, err
+}
+
+// call sends req as a Heartbeat call of its own
+func (h *heartbeatChannel) call(conn *grpc.ClientConn, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), heartbeatTimeout)
+	defer cancel()
+	return agentv1.NewCoreServiceClient(conn).Heartbeat(ctx, req)
+}
+
+// close ends the stream, if any
+func (h *heartbeatChannel) close() {
+	if h.stream == nil {
+		return
+	}
+	h.stream.CloseSend()
+	h.cancel()
+	h.stream, h.cancel = nil, nil
+}
+
+// nextInterval returns how long to wait for the next heartbeat: as the core
+// last asked, or defaultHeartbeatInterval
+func (h *heartbeatChannel) nextInterval() time.Duration {
+	if d := time.Duration(h.interval.Load()); d > 0 {
+		return d
+	}
+	return defaultHeartbeatInterval
+}
+
+// setInterval keeps the interval the core asked for, if it named one
+func (h *heartbeatChannel) setInterval(d *durationpb.Duration) {
+	if d != nil {
+		h.interval.Store(int64(d.AsDuration()))
+	}
+}
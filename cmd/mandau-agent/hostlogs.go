@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/hostlogs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxGrep caps the expression lines are filtered with
+const maxGrep = 1024
+
+// logFileAllowlist reads logs.files, leaving out relative paths and
+// malformed patterns
+func logFileAllowlist(patterns []string) hostlogs.Allowlist {
+	var allow hostlogs.Allowlist
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil || !filepath.IsAbs(pattern) {
+			fmt.Printf("Warning: ignoring logs.files entry %q: want an absolute path or pattern\n", pattern)
+			continue
+		}
+		allow = append(allow, filepath.Clean(pattern))
+	}
+	return allow
+}
+
+// ListLogFiles lists the log files logs.files allows that exist
+func (a *Agent) ListLogFiles(ctx context.Context, req *agentv1.ListLogFilesRequest) (*agentv1.ListLogFilesResponse, error) {
+	resp := &agentv1.ListLogFilesResponse{}
+	for _, path := range a.logFiles.Files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		resp.Files = append(resp.Files, &agentv1.LogFile{
+			Path:     path,
+			Size:     info.Size(),
+			Modified: timestamppb.New(info.ModTime()),
+		})
+	}
+	return resp, nil
+}
+
+// TailLogFile streams the lines of an allowed log file. Secrets in them
+// are masked as in stack logs.
+func (a *Agent) TailLogFile(req *agentv1.TailLogFileRequest, stream agentv1.HostLogService_TailLogFileServer) error {
+	path, err := a.logFiles.Resolve(req.Path)
+	if errors.Is(err, hostlogs.ErrNotAllowed) {
+		return status.Errorf(codes.PermissionDenied, "%v: add it to logs.files in the agent config", err)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return status.Errorf(codes.NotFound, "%s does not exist", req.Path)
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.Lines < -1 {
+		return status.Errorf(codes.InvalidArgument, "invalid lines %d", req.Lines)
+	}
+	opts := hostlogs.Options{Lines: int(req.Lines), Follow: req.Follow}
+	if req.Since != nil {
+		opts.Since = req.Since.AsTime()
+	}
+	if req.Grep != "" {
+		if len(req.Grep) > maxGrep {
+			return status.Errorf(codes.InvalidArgument, "grep expression longer than %d bytes", maxGrep)
+		}
+		if opts.Grep, err = regexp.Compile(req.Grep); err != nil {
+			return status.Errorf(codes.InvalidArgument, "grep: %v", err)
+		}
+	}
+
+	ctx := stream.Context()
+	err = a.logFiles.Tail(ctx, path, opts, func(line hostlogs.Line) error {
+		ts := line.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		return stream.Send(&agentv1.LogEntry{
+			Timestamp:   timestamppb.New(ts),
+			Content:     []byte(a.redactor.String(line.Text)),
+			ServiceName: path,
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		if errors.Is(err, hostlogs.ErrNotAllowed) || errors.Is(err, os.ErrPermission) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handledRetention is how long instruction outcomes are remembered, so a
+// redelivered instruction is answered again instead of run twice
+const handledRetention = time.Hour
+
+// instructionState tracks work received on heartbeat responses
+type instructionState struct {
+	mu          sync.Mutex
+	outbox      []*agentv1.InstructionResult // Results for the next heartbeat
+	handled     map[string]*handledInstruction
+	draining    bool
+	drainReason string
+	wake        chan struct{} // Asks for an early heartbeat to report results
+}
+
+type handledInstruction struct {
+	result *agentv1.InstructionResult // Nil while running
+	at     time.Time
+}
+
+func newInstructionState() *instructionState {
+	return &instructionState{
+		handled: make(map[string]*handledInstruction),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// takeResults empties the outbox for a heartbeat
+func (s *instructionState) takeResults() []*agentv1.InstructionResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := s.outbox
+	s.outbox = nil
+	return results
+}
+
+// requeue puts back results a failed heartbeat did not deliver
+func (s *instructionState) requeue(results []*agentv1.InstructionResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outbox = append(results, s.outbox...)
+}
+
+// start records inst as running. It reports false for instructions seen
+// before, re-sending the outcome if there is one.
+func (s *instructionState) start(inst *agentv1.AgentInstruction, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, h := range s.handled {
+		if h.result != nil && now.Sub(h.at) > handledRetention {
+			delete(s.handled, id)
+		}
+	}
+
+	if h, ok := s.handled[inst.Id]; ok {
+		if h.result != nil {
+			s.outbox = append(s.outbox, h.result)
+		}
+		return false
+	}
+	s.handled[inst.Id] = &handledInstruction{at: now}
+	return true
+}
+
+func (s *instructionState) finish(result *agentv1.InstructionResult, now time.Time) {
+	s.mu.Lock()
+	s.handled[result.InstructionId] = &handledInstruction{result: result, at: now}
+	s.outbox = append(s.outbox, result)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *instructionState) setDrain(enabled bool, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.draining = enabled
+	s.drainReason = reason
+}
+
+// drainError is the error for stack changes refused while draining
+func (s *instructionState) drainError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.draining {
+		return nil
+	}
+	return status.Errorf(codes.Unavailable, "agent is draining: %s", s.drainReason)
+}
+
+// handleInstructions starts every new instruction from a heartbeat response
+func (a *Agent) handleInstructions(instructions []*agentv1.AgentInstruction) {
+	for _, inst := range instructions {
+		if !a.instructions.start(inst, time.Now()) {
+			continue
+		}
+
+		go func(inst *agentv1.AgentInstruction) {
+			// Operations the instruction starts carry the request ID of the
+			// command that queued it
+			ctx := requestid.With(context.Background(), inst.RequestId)
+			opID, err := a.runInstruction(ctx, inst)
+			result := &agentv1.InstructionResult{
+				InstructionId: inst.Id,
+				Success:       err == nil,
+				OperationId:   opID,
+			}
+			if err != nil {
+				result.Error = err.Error()
+				fmt.Printf("Instruction %s (request %s) failed: %v\n", inst.Id, inst.RequestId, err)
+			}
+			a.instructions.finish(result, time.Now())
+		}(inst)
+	}
+}
+
+// runInstruction carries out inst, waiting for any operation it starts
+func (a *Agent) runInstruction(ctx context.Context, inst *agentv1.AgentInstruction) (string, error) {
+	ctx = stack.WithAuthor(ctx, inst.RequestedBy)
+	switch kind := inst.Kind.(type) {
+	case *agentv1.AgentInstruction_Config:
+		fmt.Printf("Instruction %s: refreshing configuration (version %q)\n", inst.Id, kind.Config.Version)
+		a.Reload()
+		return "", nil
+
+	case *agentv1.AgentInstruction_Drain:
+		a.instructions.setDrain(kind.Drain.Enabled, kind.Drain.Reason)
+		fmt.Printf("Instruction %s: draining %v (%s)\n", inst.Id, kind.Drain.Enabled, kind.Drain.Reason)
+		return "", nil
+
+	case *agentv1.AgentInstruction_ApplyStack:
+		if err := a.instructions.drainError(); err != nil {
+			return "", err
+		}
+		opID, err := a.stackMgr.ApplyStack(ctx, applyRequestFromProto(kind.ApplyStack))
+		if err != nil {
+			return "", fmt.Errorf("apply stack: %w", err)
+		}
+		return opID, a.waitOperation(ctx, opID)
+
+	case *agentv1.AgentInstruction_RemoveStack:
+		if err := a.instructions.drainError(); err != nil {
+			return "", err
+		}
+		req := kind.RemoveStack
+		if err := a.requireStackNamespace(req.StackId, req.Namespace); err != nil {
+			return "", err
+		}
+		opID, err := a.stackMgr.RemoveStack(ctx, req.StackId, false, operation.NewIdempotency(req.IdempotencyKey, req))
+		if err != nil {
+			return "", fmt.Errorf("remove stack: %w", err)
+		}
+		return opID, a.waitOperation(ctx, opID)
+	}
+
+	return "", fmt.Errorf("unsupported instruction")
+}
+
+// waitOperation blocks until opID finishes and returns its error
+func (a *Agent) waitOperation(ctx context.Context, opID string) error {
+	events := a.opMgr.Subscribe(opID)
+	defer a.opMgr.Unsubscribe(opID, events)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return errors.New("operation events closed")
+			}
+			switch event.State {
+			case operation.OperationStateCompleted:
+				return nil
+			case operation.OperationStateFailed:
+				if op, err := a.opMgr.GetOperation(opID); err == nil && op.Error != nil {
+					return op.Error
+				}
+				return errors.New("operation failed")
+			case operation.OperationStateCancelled:
+				return errors.New("operation cancelled")
+			}
+		}
+	}
+}
+
+// applyRequestFromProto converts an apply request for the stack manager
+func applyRequestFromProto(req *agentv1.ApplyStackRequest) *stack.ApplyStackRequest {
+	internalReq := &stack.ApplyStackRequest{
+		StackName:      req.StackName,
+		ComposeContent: req.ComposeContent,
+		EnvVars:        req.EnvVars,
+		ForceRecreate:  req.ForceRecreate,
+		Services:       req.Services,
+		PullImages:     req.PullImages,
+		Labels:         req.Labels,
+		Namespace:      req.Namespace,
+		Idempotency:    operation.NewIdempotency(req.IdempotencyKey, req),
+		NoWait:         req.NoWait,
+		ReadyTimeout:   req.ReadyTimeout.AsDuration(),
+	}
+	if req.Owner != nil {
+		owner := convertOwnerFromProto(req.Owner)
+		internalReq.Owner = &owner
+	}
+	if req.Hooks != nil {
+		internalReq.Hooks = &stack.Hooks{PreApply: req.Hooks.PreApply, PostApply: req.Hooks.PostApply}
+	}
+	if req.Files != nil {
+		internalReq.Files = stackFilesFromProto(req.Files.Files)
+	}
+	if len(req.ComposeOverrides) > 0 {
+		internalReq.Overrides = contentsFromProto(req.ComposeOverrides)
+	}
+	if req.ProjectFiles != nil {
+		internalReq.ProjectFiles = contentsFromProto(req.ProjectFiles.Files)
+	}
+	return internalReq
+}
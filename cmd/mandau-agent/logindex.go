@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/logindex"
+	"github.com/bhangun/mandau/pkg/agent/logs"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/quota"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// indexScanInterval is how often stacks are checked for containers to
+	// index, and buffered entries written out
+	indexScanInterval = 15 * time.Second
+	// indexPruneInterval is how often expired segments are removed
+	indexPruneInterval = time.Hour
+)
+
+// openLogIndex opens the index logs.index configures, or returns nil when it
+// is not enabled
+func openLogIndex(cfg config.LogIndexConfig) (*logindex.Index, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = logindex.DefaultDir
+	}
+	var (
+		maxSize   int64
+		retention time.Duration
+		err       error
+	)
+	if cfg.MaxSize != "" {
+		if maxSize, err = quota.ParseMemory(cfg.MaxSize); err != nil {
+			return nil, fmt.Errorf("logs.index.max_size: %w", err)
+		}
+	}
+	if cfg.Retention != "" {
+		if retention, err = time.ParseDuration(cfg.Retention); err != nil || retention <= 0 {
+			return nil, fmt.Errorf("logs.index.retention: invalid duration %q", cfg.Retention)
+		}
+	}
+	ix, err := logindex.Open(dir, maxSize, retention)
+	if err != nil {
+		return nil, fmt.Errorf("logs.index: %w", err)
+	}
+	return ix, nil
+}
+
+// indexLogs keeps the logs of the running containers of every stack in the
+// log index until the agent stops, picking containers up as they start
+func (a *Agent) indexLogs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stop
+		cancel()
+	}()
+
+	var (
+		mu       sync.Mutex
+		followed = make(map[string]bool)
+		wg       sync.WaitGroup
+	)
+	scan := func() {
+		stacks, err := a.stackMgr.ListStacks(ctx)
+		if err != nil {
+			fmt.Printf("Warning: log index: %v\n", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, st := range stacks {
+			for _, c := range st.Containers {
+				if c.State != "running" || followed[c.ID] {
+					continue
+				}
+				followed[c.ID] = true
+				wg.Add(1)
+				go func(stackName, id, serviceName string) {
+					defer wg.Done()
+					a.indexContainer(ctx, stackName, id, serviceName)
+					mu.Lock()
+					delete(followed, id)
+					mu.Unlock()
+				}(st.Name, c.ID, c.Service)
+			}
+		}
+	}
+
+	if err := a.logIndex.Prune(); err != nil {
+		fmt.Printf("Warning: log index: %v\n", err)
+	}
+	ticker := time.NewTicker(indexScanInterval)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+	for {
+		scan()
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			if err := a.logIndex.Close(); err != nil {
+				fmt.Printf("Warning: log index: %v\n", err)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if err := a.logIndex.Flush(); err != nil {
+			fmt.Printf("Warning: log index: %v\n", err)
+		}
+		if time.Since(lastPrune) >= indexPruneInterval {
+			if err := a.logIndex.Prune(); err != nil {
+				fmt.Printf("Warning: log index: %v\n", err)
+			}
+			lastPrune = time.Now()
+		}
+	}
+}
+
+// indexContainer indexes the logs of a container until it stops, from just
+// after the last entry indexed. Entries are masked before they reach the
+// disk. A failed write stops it until the next scan picks the container up
+// again.
+func (a *Agent) indexContainer(ctx context.Context, stackName, id, serviceName string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var since time.Time
+	if last := a.logIndex.Last(stackName, id); !last.IsZero() {
+		since = last.Add(time.Nanosecond)
+	}
+	var addErr error
+	source := logs.DockerSource(a.docker, []logs.Container{{ID: id, Service: serviceName, Since: since}}, true, "all")
+	err := source(ctx, func(entry *agentv1.LogEntry) {
+		if addErr != nil {
+			return
+		}
+		entry.Content = []byte(a.redactor.String(string(entry.Content)))
+		if addErr = a.logIndex.Add(stackName, entry); addErr != nil {
+			cancel()
+		}
+	})
+	if addErr != nil {
+		err = addErr
+	}
+	if err != nil && (addErr != nil || ctx.Err() == nil) {
+		fmt.Printf("Warning: log index: container %s of %s: %v\n", id, stackName, err)
+	}
+}
+
+// stackLogQuery is what GetStackLogs asks for besides following
+type stackLogQuery struct {
+	since, until time.Time
+	grep         *regexp.Regexp
+	tail         int // Zero sends every match
+}
+
+// parseStackLogQuery checks the search fields of a GetStackLogs request
+func parseStackLogQuery(req *agentv1.GetStackLogsRequest) (stackLogQuery, error) {
+	var q stackLogQuery
+	if req.Grep != "" {
+		if len(req.Grep) > maxGrep {
+			return q, status.Errorf(codes.InvalidArgument, "grep expression longer than %d bytes", maxGrep)
+		}
+		var err error
+		if q.grep, err = regexp.Compile(req.Grep); err != nil {
+			return q, status.Errorf(codes.InvalidArgument, "invalid grep expression: %v", err)
+		}
+	}
+	if req.Since != nil {
+		q.since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		if req.Follow {
+			return q, status.Error(codes.InvalidArgument, "until cannot be combined with follow")
+		}
+		q.until = req.Until.AsTime()
+		if !q.since.IsZero() && !q.until.After(q.since) {
+			return q, status.Error(codes.InvalidArgument, "until must be after since")
+		}
+	}
+	switch {
+	case req.Tail < 0:
+		return q, status.Errorf(codes.InvalidArgument, "invalid tail %d", req.Tail)
+	case req.Tail > 0:
+		q.tail = int(req.Tail)
+	case q.since.IsZero():
+		q.tail, _ = strconv.Atoi(readLogTail)
+	}
+	return q, nil
+}
+
+// stackLogHistory sends the entries of a stack matching q: from the log
+// index when there is one, so they outlive the containers, otherwise from
+// the logs Docker keeps for the containers there are now. Send receives
+// entries in the order they were read and must mask them itself.
+func (a *Agent) stackLogHistory(ctx context.Context, stackName string, containers []logs.Container, q stackLogQuery, send func(*agentv1.LogEntry) error) error {
+	if a.logIndex != nil {
+		var sendErr error
+		err := a.logIndex.Search(stackName, logindex.Query{Since: q.since, Until: q.until, Grep: q.grep, Tail: q.tail}, func(entry *agentv1.LogEntry) error {
+			sendErr = send(entry)
+			return sendErr
+		})
+		if sendErr != nil {
+			return sendErr
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "search log index: %v", err)
+		}
+		return nil
+	}
+
+	tail := "all"
+	if q.since.IsZero() && q.grep == nil {
+		tail = strconv.Itoa(q.tail)
+	}
+	withSince := make([]logs.Container, len(containers))
+	for i, c := range containers {
+		c.Since = q.since
+		withSince[i] = c
+	}
+
+	// Sources emit from one goroutine per container. Matches are held back
+	// in a ring when only the last ones are wanted.
+	var (
+		mu      sync.Mutex
+		sendErr error
+		ring    []*agentv1.LogEntry
+		next    int
+	)
+	err := logs.DockerSource(a.docker, withSince, false, tail)(ctx, func(entry *agentv1.LogEntry) {
+		if !q.until.IsZero() && !entry.Timestamp.AsTime().Before(q.until) {
+			return
+		}
+		if q.grep != nil && !q.grep.Match([]byte(a.redactor.String(string(entry.Content)))) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case sendErr != nil:
+		case q.tail == 0 || tail != "all":
+			sendErr = send(entry)
+		case len(ring) < q.tail:
+			ring = append(ring, entry)
+		default:
+			ring[next] = entry
+			next = (next + 1) % q.tail
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "read logs: %v", err)
+	}
+	for i := range ring {
+		if err := send(ring[(next+i)%len(ring)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/logs"
+	"github.com/bhangun/mandau/pkg/agent/logship"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// shipScanInterval is how often stacks are checked for containers to
+	// follow
+	shipScanInterval = 15 * time.Second
+	// journalRetry is the pause before journalctl is started again
+	journalRetry = 30 * time.Second
+)
+
+// logShipping follows the logs logs.ship asks for into its shipper
+type logShipping struct {
+	*logship.Shipper
+	started time.Time // Containers never shipped are read from then on
+
+	mu      sync.Mutex
+	streams map[string]*agentv1.ShippedStream // By source
+}
+
+// newLogShipping returns the shipper for logs.ship, or nil when it names no
+// sink. The password file wins over the secrets plugin when both are
+// configured.
+func newLogShipping(ctx context.Context, cfg config.LogShipConfig, agentID string, plugins *plugin.Registry) (*logShipping, error) {
+	if cfg.Sink == "" {
+		return nil, nil
+	}
+
+	var secret string
+	switch {
+	case cfg.PasswordFile != "":
+		data, err := os.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("logs.ship.password_file: %w", err)
+		}
+		secret = strings.TrimSpace(string(data))
+	case cfg.SecretKey != "":
+		secrets := plugins.Secrets()
+		if secrets == nil {
+			return nil, fmt.Errorf("logs.ship.secret_key needs a secrets plugin")
+		}
+		data, err := secrets.Get(ctx, cfg.SecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("logs.ship.secret_key: %w", err)
+		}
+		secret = strings.TrimSpace(string(data))
+	}
+
+	shipper, err := logship.New(cfg, agentID, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &logShipping{
+		Shipper: shipper,
+		started: time.Now(),
+		streams: make(map[string]*agentv1.ShippedStream),
+	}, nil
+}
+
+// shipLogs ships logs until the agent stops: the containers of the stacks
+// shipped, picked up as they start, and the journal of the units listed
+func (a *Agent) shipLogs(units []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stop
+		cancel()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		a.logShip.Run(ctx)
+		close(done)
+	}()
+	if len(units) > 0 {
+		go a.shipJournal(ctx, units)
+	}
+
+	ticker := time.NewTicker(shipScanInterval)
+	defer ticker.Stop()
+	for {
+		a.followStacks(ctx)
+		select {
+		case <-ctx.Done():
+			// Let the shipper send what it holds before the agent exits
+			<-done
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// followStacks starts following the running containers of shipped stacks
+// not followed yet
+func (a *Agent) followStacks(ctx context.Context) {
+	stacks, err := a.stackMgr.ListStacks(ctx)
+	if err != nil {
+		fmt.Printf("Warning: log shipping: %v\n", err)
+		return
+	}
+
+	s := a.logShip
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range stacks {
+		if !s.Ships(st.Labels) {
+			continue
+		}
+		for _, c := range st.Containers {
+			if c.State != "running" || s.streams[c.ID] != nil {
+				continue
+			}
+			s.streams[c.ID] = &agentv1.ShippedStream{Source: c.ID, Stack: st.Name, Service: c.Service}
+			go a.followContainer(ctx, st.Name, c.ID, c.Service)
+		}
+	}
+}
+
+// followContainer ships the logs of a container until it stops, from just
+// after the last line shipped
+func (a *Agent) followContainer(ctx context.Context, stackName, id, serviceName string) {
+	s := a.logShip
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, id)
+		s.mu.Unlock()
+	}()
+
+	since := s.started
+	if pos, err := time.Parse(time.RFC3339Nano, s.Position(id)); err == nil {
+		since = pos.Add(time.Nanosecond)
+	}
+	source := logs.DockerSource(a.docker, []logs.Container{{ID: id, Service: serviceName, Since: since}}, true, "all")
+	err := source(ctx, func(entry *agentv1.LogEntry) {
+		ts := entry.Timestamp.AsTime()
+		s.Add(logship.Entry{
+			Time: ts,
+			Line: string(entry.Content),
+			Labels: map[string]string{
+				logship.LabelStack:   stackName,
+				logship.LabelService: serviceName,
+				logship.LabelStream:  entry.Stream,
+				logship.LabelSource:  "container",
+			},
+			Container: id,
+			Source:    id,
+			Position:  ts.Format(time.RFC3339Nano),
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("Warning: log shipping: container %s of %s: %v\n", id, stackName, err)
+	}
+}
+
+// shipJournal follows the journal of units, starting journalctl again when
+// it ends
+func (a *Agent) shipJournal(ctx context.Context, units []string) {
+	s := a.logShip
+	s.mu.Lock()
+	s.streams[logship.JournalSource] = &agentv1.ShippedStream{Source: logship.JournalSource, Service: strings.Join(units, ",")}
+	s.mu.Unlock()
+
+	for {
+		if err := s.FollowJournal(ctx, units); err != nil {
+			fmt.Printf("Warning: log shipping: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(journalRetry):
+		}
+	}
+}
+
+// GetLogShipping reports how log shipping is going and what is followed
+func (a *Agent) GetLogShipping(ctx context.Context, req *agentv1.GetLogShippingRequest) (*agentv1.LogShipping, error) {
+	s := a.logShip
+	st := s.Status()
+	resp := &agentv1.LogShipping{
+		Sink:      st.Sink,
+		Shipped:   st.Shipped,
+		Dropped:   st.Dropped,
+		Pending:   int32(st.Pending),
+		LastError: st.LastError,
+	}
+	if !st.LastShipped.IsZero() {
+		resp.LastShipped = timestamppb.New(st.LastShipped)
+	}
+	if !st.LastErrorAt.IsZero() {
+		resp.LastErrorAt = timestamppb.New(st.LastErrorAt)
+	}
+
+	s.mu.Lock()
+	for source, stream := range s.streams {
+		resp.Streams = append(resp.Streams, &agentv1.ShippedStream{
+			Source:   source,
+			Stack:    stream.Stack,
+			Service:  stream.Service,
+			Position: s.Position(source),
+		})
+	}
+	s.mu.Unlock()
+	sort.Slice(resp.Streams, func(i, j int) bool {
+		if resp.Streams[i].Stack != resp.Streams[j].Stack {
+			return resp.Streams[i].Stack < resp.Streams[j].Stack
+		}
+		return resp.Streams[i].Service < resp.Streams[j].Service
+	})
+	return resp, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -12,18 +13,39 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/command"
 	"github.com/bhangun/mandau/pkg/agent/container"
 	"github.com/bhangun/mandau/pkg/agent/filesystem"
+	"github.com/bhangun/mandau/pkg/agent/hostlogs"
+	"github.com/bhangun/mandau/pkg/agent/logindex"
+	"github.com/bhangun/mandau/pkg/agent/logs"
+	"github.com/bhangun/mandau/pkg/agent/mirror"
 	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/bhangun/mandau/pkg/agent/seal"
+	"github.com/bhangun/mandau/pkg/agent/service"
 	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/agent/timeline"
+	"github.com/bhangun/mandau/pkg/audit"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/certidentity"
+	"github.com/bhangun/mandau/pkg/chaos"
 	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/daemon"
+	"github.com/bhangun/mandau/pkg/marketplace"
 	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/bhangun/mandau/pkg/transport"
 	"github.com/bhangun/mandau/plugins/auth/rbac"
+	"github.com/bhangun/mandau/plugins/services/systemd"
 	"github.com/moby/moby/client"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
@@ -31,6 +53,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -44,6 +67,13 @@ type Agent struct {
 	agentv1.UnimplementedContainerServiceServer
 	agentv1.UnimplementedFilesystemServiceServer
 	agentv1.UnimplementedOperationsServiceServer
+	agentv1.UnimplementedPortServiceServer
+	agentv1.UnimplementedProcessServiceServer
+	agentv1.UnimplementedHostLogServiceServer
+	agentv1.UnimplementedHostPowerServiceServer
+	agentv1.UnimplementedLogShipServiceServer
+	agentv1.UnimplementedCommandServiceServer
+	agentv1.UnimplementedTransferServiceServer
 
 	config       *Config
 	serverConn   *grpc.ClientConn
@@ -53,6 +83,30 @@ type Agent struct {
 	stackMgr     *stack.Manager
 	containerMgr *container.Manager
 	fsMgr        *filesystem.Manager
+	services     *service.ServiceManager // Host service plugins enabled in the config
+	installer    *marketplace.Installer  // Plugins installed from the core's index
+	capabilities []string
+	logHub       *logs.Hub
+	logPolicy    logs.Policy
+	redactor     *redact.Redactor     // Masks secrets in log lines
+	identities   *certidentity.Mapper // server.identity; nil names callers by CN
+	mu           sync.RWMutex         // Guards grpcServer and serverCert
+	grpcServer   *grpc.Server
+	serverCert   *tls.Certificate // Reloaded on SIGHUP
+	instructions *instructionState
+	execLimit    time.Duration      // security.exec_timeout; policy may override per caller
+	protected    []string           // security.protected_processes
+	logFiles     hostlogs.Allowlist // logs.files
+	commands     command.Allowlist  // commands
+	logShip      *logShipping       // nil unless logs.ship names a sink
+	logIndex     *logindex.Index    // nil unless logs.index is enabled
+	timeline     *timeline.Timeline // Per-stack events
+	mirrors      *mirror.Set        // nil unless docker.registry_mirrors lists any
+	transfers    *transfers         // Resumable uploads and backup downloads
+	clock        clockState         // Offset from the core, measured by heartbeats
+	beats        heartbeatChannel   // Heartbeat stream to the core
+	rebootMu     sync.Mutex         // Held while a reboot operation is started
+	stop         chan struct{}      // Closed on shutdown
 }
 
 type Config struct {
@@ -65,7 +119,11 @@ type Config struct {
 	CAPath     string
 	StackRoot  string
 	PluginDir  string
+	PIDFile    string
 	Labels     map[string]string
+	// InstallService writes a systemd unit running the agent with the
+	// other flags given, then exits
+	InstallService bool
 	// Add a field to hold the full configuration
 	FullConfig *config.AgentConfig
 }
@@ -194,6 +252,9 @@ func main() {
 	if agentConfig.Stacks.RootDir != "" {
 		cfg.StackRoot = agentConfig.Stacks.RootDir
 	}
+	if agentConfig.Agent.PIDFile != "" && cfg.PIDFile == "" {
+		cfg.PIDFile = agentConfig.Agent.PIDFile
+	}
 	if agentConfig.Agent.Labels != nil {
 		for k, v := range agentConfig.Agent.Labels {
 			cfg.Labels[k] = v
@@ -203,29 +264,98 @@ func main() {
 	// Store the full configuration
 	cfg.FullConfig = agentConfig
 
+	if cfg.InstallService {
+		if err := installService(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to install service: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Installed and enabled mandau-agent.service")
+		return
+	}
+
+	if cfg.PIDFile != "" {
+		if err := daemon.WritePIDFile(cfg.PIDFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write PID file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	agent, err := NewAgent(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create agent: %v\n", err)
+		removePIDFile(cfg)
 		os.Exit(1)
 	}
 
-	// Graceful shutdown
+	// SIGHUP reloads, SIGINT and SIGTERM stop gracefully
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	errChan := make(chan error, 1)
 	go func() {
 		errChan <- agent.Serve()
 	}()
 
-	select {
-	case err := <-errChan:
-		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
-		os.Exit(1)
-	case sig := <-sigChan:
-		fmt.Printf("\nReceived signal %v, shutting down...\n", sig)
-		agent.Shutdown()
+	stopWatchdog := make(chan struct{})
+	go daemon.Watchdog(stopWatchdog)
+
+	for {
+		select {
+		case err := <-errChan:
+			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			close(stopWatchdog)
+			removePIDFile(cfg)
+			os.Exit(1)
+		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				fmt.Println("Received SIGHUP, reloading...")
+				agent.Reload()
+				continue
+			}
+			fmt.Printf("\nReceived signal %v, shutting down...\n", sig)
+			close(stopWatchdog)
+			agent.Shutdown()
+			removePIDFile(cfg)
+			return
+		}
+	}
+}
+
+func removePIDFile(cfg *Config) {
+	if cfg.PIDFile == "" {
+		return
+	}
+	if err := daemon.RemovePIDFile(cfg.PIDFile); err != nil {
+		fmt.Printf("Warning: could not remove PID file: %v\n", err)
+	}
+}
+
+// installService installs a systemd unit that starts this binary with the
+// flags it was given, minus --install-service
+func installService(cfg *Config) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate agent binary: %w", err)
+	}
+
+	command := []string{exe}
+	for _, arg := range os.Args[1:] {
+		if strings.TrimLeft(arg, "-") == "install-service" {
+			continue
+		}
+		command = append(command, arg)
 	}
+
+	sd := systemd.New()
+	if err := sd.Init(context.Background(), nil); err != nil {
+		return err
+	}
+	return sd.InstallAgent(context.Background(), systemd.AgentUnitOptions{
+		Command:     command,
+		PIDFile:     cfg.PIDFile,
+		WatchdogSec: 60,
+		WritePaths:  []string{cfg.StackRoot, filepath.Clean(cfg.StackRoot) + ".operations", filepath.Clean(cfg.StackRoot) + ".timeline", filepath.Clean(cfg.StackRoot) + ".transfers", filepath.Clean(cfg.StackRoot) + ".staging", "/var/lib/mandau", "/run"},
+	})
 }
 
 // filterConfigArgs removes config-related arguments from the command line args
@@ -263,6 +393,8 @@ func parseFlags(configArgs []string) *Config {
 	flagSet.StringVar(&cfg.CAPath, "ca", "/etc/mandau/ca.crt", "CA certificate path")
 	flagSet.StringVar(&cfg.StackRoot, "stack-root", "/var/lib/mandau/stacks", "Stack root directory")
 	flagSet.StringVar(&cfg.PluginDir, "plugin-dir", "/usr/lib/mandau/plugins", "Plugin directory")
+	flagSet.StringVar(&cfg.PIDFile, "pid-file", "", "Write the agent PID to this file")
+	flagSet.BoolVar(&cfg.InstallService, "install-service", false, "Install and enable a systemd unit for the agent, then exit")
 
 	// Parse the filtered arguments
 	flagSet.Parse(configArgs)
@@ -329,8 +461,24 @@ func NewAgent(cfg *Config) (*Agent, error) {
 		return nil, fmt.Errorf("docker ping: %w", err)
 	}
 
+	redactor, err := redact.New(cfg.FullConfig.Redaction.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := certidentity.New(cfg.FullConfig.Server.Identity)
+	if err != nil {
+		return nil, fmt.Errorf("server.%w", err)
+	}
+
 	// Plugin registry
 	plugins := plugin.NewRegistry()
+	plugins.SetRedactor(redactor)
+	hostAuditor, err := plugin.NewHostAuditor(cfg.FullConfig.Plugins.HostAudit, cfg.AgentID, plugins.AuditAll)
+	if err != nil {
+		return nil, fmt.Errorf("plugins.host_audit: %w", err)
+	}
+	plugins.SetHostAuditor(hostAuditor)
 
 	// Load plugins
 	if err := loadPluginsFromDir(plugins, cfg.PluginDir, cfg.FullConfig.Plugins); err != nil {
@@ -343,12 +491,107 @@ func NewAgent(cfg *Config) (*Agent, error) {
 		return nil, fmt.Errorf("plugin init: %w", err)
 	}
 
+	manifestDir := cfg.FullConfig.Deployments.ManifestDir
+	if manifestDir == "" {
+		manifestDir = service.DefaultManifestDir
+	}
+	managedPath := cfg.FullConfig.Plugins.ManagedFiles
+	if managedPath == "" {
+		managedPath = plugin.DefaultManagedFiles
+	}
+	managed, err := plugin.NewManagedFiles(managedPath)
+	if err != nil {
+		return nil, err
+	}
+	services, err := service.NewServiceManager(ctx, cfg.FullConfig.Plugins, manifestDir, hostAuditor, managed)
+	if err != nil {
+		return nil, fmt.Errorf("service plugins: %w", err)
+	}
+	if err := services.SetSecurityLabels(cfg.FullConfig.Deployments.SecurityLabels); err != nil {
+		return nil, err
+	}
+	for _, a := range services.Availability() {
+		if !a.Available {
+			fmt.Printf("Warning: %s is unavailable on this host, its services are not served: %s\n", a.Plugin, a.Reason)
+		}
+	}
+	if confinement := services.Confinement(); confinement.Enforcing() {
+		fmt.Printf("Host services: %s\n", confinement)
+	}
+
 	// Create managers
-	opMgr := operation.NewManager()
-	stackMgr := stack.NewManager(cfg.StackRoot, docker, opMgr)
+	opsDir := cfg.FullConfig.Stacks.OperationsDir
+	if opsDir == "" {
+		opsDir = filepath.Clean(cfg.StackRoot) + ".operations"
+	}
+	opMgr, interrupted, err := operation.NewPersistentManager(opsDir)
+	if err != nil {
+		return nil, fmt.Errorf("operation store: %w", err)
+	}
+	opMgr.SetRedactor(redactor)
+	sealer, err := stackSealer(ctx, cfg.FullConfig.Stacks.Encryption, plugins)
+	if err != nil {
+		return nil, err
+	}
+	stackMgr := stack.NewManager(cfg.StackRoot, docker, opMgr, sealer, redactor)
+	if err := stackMgr.LockRoot(); err != nil {
+		return nil, err
+	}
+	storage, err := stackStorage(cfg.FullConfig.Stacks.Storage, cfg.StackRoot)
+	if err != nil {
+		return nil, err
+	}
+	stackMgr.SetStorage(storage)
+	if value := cfg.FullConfig.Stacks.ReadyTimeout; value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			stackMgr.SetReadyTimeout(d)
+		} else {
+			fmt.Printf("Warning: invalid stacks.ready_timeout %q, using %s\n", value, stack.DefaultReadyTimeout)
+		}
+	}
+	stackMgr.SetHooks(stackHookConfig(cfg.FullConfig.Stacks.Hooks))
+	if value := cfg.FullConfig.Stacks.MaxSize; value != "" {
+		if size, err := quota.ParseMemory(value); err == nil {
+			stackMgr.SetMaxSize(size)
+		} else {
+			fmt.Printf("Warning: invalid stacks.max_size %q, stacks are not limited\n", value)
+		}
+	}
+	stackTimeline, err := openTimeline(cfg.FullConfig.Stacks, cfg.StackRoot)
+	if err != nil {
+		return nil, err
+	}
+	stackMgr.SetTimeline(stackTimeline)
+	mirrors, err := mirror.New(cfg.FullConfig.Docker.RegistryMirrors)
+	if err != nil {
+		return nil, fmt.Errorf("docker.registry_mirrors: %w", err)
+	}
+	stackMgr.SetMirrors(mirrors)
+	transfers, err := openTransfers(cfg.FullConfig.Transfers, cfg.StackRoot)
+	if err != nil {
+		return nil, err
+	}
+	stackMgr.RecoverFiles()
+	stackMgr.ResumeInterrupted(interrupted, cfg.FullConfig.Stacks.ReconcileInterrupted)
 	containerMgr := container.NewManager()
 	fsMgr := filesystem.NewManager()
 
+	logPolicy, err := logs.ParsePolicy(cfg.FullConfig.Logs.SlowPolicy)
+	if err != nil {
+		return nil, err
+	}
+	logShip, err := newLogShipping(ctx, cfg.FullConfig.Logs.Ship, cfg.AgentID, plugins)
+	if err != nil {
+		return nil, err
+	}
+	if logShip != nil {
+		logShip.SetRedactor(redactor)
+	}
+	logIndex, err := openLogIndex(cfg.FullConfig.Logs.Index)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create gRPC connection to core server
 	serverConn, err := createServerConnection(cfg)
 	if err != nil {
@@ -364,51 +607,114 @@ func NewAgent(cfg *Config) (*Agent, error) {
 		stackMgr:     stackMgr,
 		containerMgr: containerMgr,
 		fsMgr:        fsMgr,
-	}
+		services:     services,
+		installer: marketplace.NewInstaller(cfg.FullConfig.Plugins.Marketplace.InstallDir,
+			cfg.FullConfig.Plugins.Marketplace.TrustedKeys),
+		capabilities: append(capability.Detect(), services.Capabilities()...),
+		logHub:       logs.NewHub(cfg.FullConfig.Logs.SubscriberBuffer),
+		logPolicy:    logPolicy,
+		redactor:     redactor,
+		identities:   identities,
+		instructions: newInstructionState(),
+		execLimit:    parseExecTimeout(cfg.FullConfig.Security.ExecTimeout),
+		protected:    protectedProcesses(cfg.FullConfig.Security.ProtectedProcesses),
+		logFiles:     logFileAllowlist(cfg.FullConfig.Logs.Files),
+		commands:     commandAllowlist(cfg.FullConfig.Commands),
+		logShip:      logShip,
+		logIndex:     logIndex,
+		timeline:     stackTimeline,
+		mirrors:      mirrors,
+		transfers:    transfers,
+		stop:         make(chan struct{}),
+	}
+	agent.capabilities = append(agent.capabilities, capability.Timeline)
+	if len(agent.logFiles) > 0 {
+		agent.capabilities = append(agent.capabilities, capability.LogFiles)
+	}
+	if logShip != nil {
+		agent.capabilities = append(agent.capabilities, capability.LogShip)
+	}
+	if len(agent.commands) > 0 {
+		agent.capabilities = append(agent.capabilities, capability.Commands)
+	}
+
+	agent.loadInstalledPlugins(ctx)
 
 	// Register with core server
 	if err := agent.registerWithServer(); err != nil {
 		return nil, fmt.Errorf("register with server: %w", err)
 	}
+	go agent.resumeReboot(interrupted)
 
 	// Start heartbeat goroutine
 	go agent.startHeartbeat()
 
+	interval := parseDriftInterval(cfg.FullConfig.Plugins.DriftInterval)
+	if interval > 0 && capability.Has(services.Capabilities(), capability.Drift) {
+		go agent.watchDrift(interval)
+	}
+	if logShip != nil {
+		go agent.shipLogs(cfg.FullConfig.Logs.Ship.Journal)
+	}
+	if logIndex != nil {
+		go agent.indexLogs()
+	}
+	go agent.watchStackEvents()
+	go agent.expireTransfers()
+	if mirrors != nil {
+		go agent.watchMirrors()
+	}
+	if acme := services.ACME(); acme != nil {
+		go agent.watchCertificates(acme.CertDir())
+	}
+
 	return agent, nil
 }
 
-// createServerConnection creates a secure gRPC connection to the core server with retry logic
-func createServerConnection(cfg *Config) (*grpc.ClientConn, error) {
-	// Load certificates
-	cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("load cert: %w", err)
+// stackSealer returns what encrypts stack files, or nil when encryption is
+// off. The key file wins over the secrets plugin when both are configured.
+func stackSealer(ctx context.Context, cfg config.StackEncryptionConfig, plugins *plugin.Registry) (*seal.Sealer, error) {
+	if !cfg.Enabled {
+		return nil, nil
 	}
-
-	// Load CA
-	caCert, err := ioutil.ReadFile(cfg.CAPath)
-	if err != nil {
-		return nil, fmt.Errorf("load CA: %w", err)
+	if cfg.KeyFile != "" {
+		return seal.FromFile(cfg.KeyFile)
 	}
-
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("parse CA cert")
+	if cfg.SecretKey == "" {
+		return nil, fmt.Errorf("stacks.encryption needs key_file or secret_key")
+	}
+	secrets := plugins.Secrets()
+	if secrets == nil {
+		return nil, fmt.Errorf("stacks.encryption.secret_key needs a secrets plugin")
 	}
+	return seal.FromSecrets(ctx, secrets, cfg.SecretKey)
+}
 
+// createServerConnection creates a secure gRPC connection to the core server with retry logic
+func createServerConnection(cfg *Config) (*grpc.ClientConn, error) {
 	// mTLS configuration
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool,
-		ServerName:   "mandau-core", // Use the server name from the certificate
-		MinVersion:   tls.VersionTLS13,
+	tlsConfig, err := transport.ClientConfig(peerTLS(cfg, func(c *config.AgentConfig) config.TLSConfig {
+		return c.ServerConnection.TLS
+	}), "mandau-core")
+	if err != nil {
+		return nil, err
 	}
 
 	creds := credentials.NewTLS(tlsConfig)
 
+	var compression string
+	if cfg.FullConfig != nil {
+		compression = cfg.FullConfig.ServerConnection.Compression
+	}
+	compress, err := transport.CompressionOption(compression)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create connection with retry options
 	conn, err := grpc.Dial(cfg.ServerAddr,
 		grpc.WithTransportCredentials(creds),
+		compress,
 		grpc.WithConnectParams(grpc.ConnectParams{
 			Backoff: backoff.Config{
 				BaseDelay:  1.0 * time.Second,
@@ -440,22 +746,30 @@ func (a *Agent) registerWithServer() error {
 	defer cancel()
 
 	resp, err := client.RegisterAgent(ctx, &agentv1.RegisterRequest{
-		Hostname:     a.config.Hostname,
-		AgentId:      a.config.AgentID,    // Send persistent agent ID
-		Labels:       map[string]string{}, // Add agent labels
-		Capabilities: []string{"docker", "stack", "container", "logs", "exec"},
+		Hostname:           a.config.Hostname,
+		AgentId:            a.config.AgentID, // Send persistent agent ID
+		Labels:             a.config.Labels,
+		Capabilities:       a.capabilities,
+		DialOutOnly:        a.config.FullConfig.ServerConnection.DialOutOnly,
+		Plugins:            a.installedPlugins(),
+		PluginAvailability: a.services.Availability(),
 	})
 	if err != nil {
 		return fmt.Errorf("register agent: %w", err)
 	}
 
+	a.beats.setInterval(resp.HeartbeatInterval)
+
 	fmt.Printf("Agent registered with ID: %s\n", resp.AgentId)
 	return nil
 }
 
 // startHeartbeat starts the periodic heartbeat to the core server with reconnection logic
 func (a *Agent) startHeartbeat() {
-	ticker := time.NewTicker(30 * time.Second) // Heartbeat every 30 seconds
+	// Heartbeat as often as the core asks, which is less often in large
+	// fleets
+	interval := a.beats.nextInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Create a context that will be cancelled when the agent shuts down
@@ -465,23 +779,37 @@ func (a *Agent) startHeartbeat() {
 	for {
 		select {
 		case <-ticker.C:
-			if err := a.sendHeartbeat(); err != nil {
-				fmt.Printf("Heartbeat failed: %v\n", err)
-				// Try to reconnect if heartbeat fails
-				if a.shouldReconnect(err) {
-					fmt.Println("Attempting to reconnect to core server...")
-					if err := a.reconnectToServer(); err != nil {
-						fmt.Printf("Reconnection failed: %v\n", err)
-					} else {
-						fmt.Println("Reconnected to core server successfully")
-					}
-				}
-			}
+			a.heartbeat()
+		case <-a.instructions.wake:
+			// Report finished instructions without waiting for the tick
+			a.heartbeat()
 		case <-ctx.Done():
 			// Agent is shutting down
 			fmt.Println("Heartbeat routine stopped")
 			return
 		}
+
+		if next := a.beats.nextInterval(); next != interval {
+			fmt.Printf("Heartbeat interval is now %s\n", next)
+			interval = next
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// heartbeat sends one heartbeat, reconnecting if the core is unreachable
+func (a *Agent) heartbeat() {
+	if err := a.sendHeartbeat(); err != nil {
+		fmt.Printf("Heartbeat failed: %v\n", err)
+		// Try to reconnect if heartbeat fails
+		if a.shouldReconnect(err) {
+			fmt.Println("Attempting to reconnect to core server...")
+			if err := a.reconnectToServer(); err != nil {
+				fmt.Printf("Reconnection failed: %v\n", err)
+			} else {
+				fmt.Println("Reconnected to core server successfully")
+			}
+		}
 	}
 }
 
@@ -521,72 +849,132 @@ func (a *Agent) reconnectToServer() error {
 
 // sendHeartbeat sends a heartbeat to the core server
 func (a *Agent) sendHeartbeat() error {
-	client := agentv1.NewCoreServiceClient(a.serverConn)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	_, err := client.Heartbeat(ctx, &agentv1.HeartbeatRequest{
-		AgentId: a.config.AgentID,
-		Status:  map[string]string{"status": "healthy"},
+	health := map[string]string{"status": "healthy"}
+	if a.instructions.drainError() != nil {
+		health["draining"] = "true"
+	}
+
+	results := a.instructions.takeResults()
+	sent := time.Now()
+	resp, err := a.beats.exchange(a.serverConn, &agentv1.HeartbeatRequest{
+		AgentId:     a.config.AgentID,
+		Status:      health,
+		Results:     results,
+		SentAt:      timestamppb.New(sent),
+		ClockOffset: a.clock.report(),
 	})
 	if err != nil {
+		a.instructions.requeue(results)
 		return fmt.Errorf("send heartbeat: %w", err)
 	}
+	a.clock.observe(resp, sent, time.Now())
+	a.beats.setInterval(resp.NextHeartbeat)
 
+	a.handleInstructions(resp.Instructions)
 	return nil
 }
 
 func (a *Agent) Serve() error {
-	// Load certificates
-	cert, err := tls.LoadX509KeyPair(a.config.CertPath, a.config.KeyPath)
-	if err != nil {
-		return fmt.Errorf("load cert: %w", err)
-	}
-
-	// Load CA
-	caCert, err := ioutil.ReadFile(a.config.CAPath)
-	if err != nil {
-		return fmt.Errorf("load CA: %w", err)
+	// Without a listener the agent only takes work from heartbeats
+	if a.config.FullConfig.ServerConnection.DialOutOnly {
+		fmt.Printf("Mandau Agent %s running dial-out only\n", a.config.AgentID)
+		if _, err := daemon.Notify(daemon.StateReady); err != nil {
+			fmt.Printf("Warning: systemd notify failed: %v\n", err)
+		}
+		<-a.stop
+		return nil
 	}
 
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return fmt.Errorf("parse CA cert")
+	// Load certificates
+	if err := a.loadServerCert(); err != nil {
+		return err
 	}
 
 	// mTLS configuration
 	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		ClientCAs:    caCertPool,
-		MinVersion:   tls.VersionTLS13,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			a.mu.RLock()
+			defer a.mu.RUnlock()
+			return a.serverCert, nil
+		},
+		MinVersion: tls.VersionTLS13,
 		CipherSuites: []uint16{
 			tls.TLS_AES_256_GCM_SHA384,
 			tls.TLS_AES_128_GCM_SHA256,
 			tls.TLS_CHACHA20_POLY1305_SHA256,
 		},
 	}
+	serverTLS := peerTLS(a.config, func(c *config.AgentConfig) config.TLSConfig { return c.Server.TLS })
+	if err := transport.ServerClientAuth(tlsConfig, serverTLS); err != nil {
+		return err
+	}
 
 	creds := credentials.NewTLS(tlsConfig)
+	server, err := a.newServer(grpc.Creds(creds))
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.grpcServer = server
+	a.mu.Unlock()
+
+	// Listen
+	lis, err := net.Listen("tcp", a.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	fmt.Printf("Mandau Agent %s listening on %s\n", a.config.AgentID, a.config.ListenAddr)
+	fmt.Printf("Hostname: %s\n", a.config.Hostname)
+	fmt.Printf("Stack root: %s\n", a.config.StackRoot)
+	fmt.Printf("Plugins loaded: %d\n", len(a.plugins.ListAll()))
+
+	if _, err := daemon.Notify(daemon.StateReady); err != nil {
+		fmt.Printf("Warning: systemd notify failed: %v\n", err)
+	}
+
+	return server.Serve(lis)
+}
+
+// newServer returns a gRPC server with the agent's interceptors and the
+// services its capabilities enable. opts carry the transport credentials;
+// Serve passes mTLS ones.
+func (a *Agent) newServer(opts ...grpc.ServerOption) (*grpc.Server, error) {
+	unary := []grpc.UnaryServerInterceptor{
+		requestid.UnaryServerInterceptor,
+		a.authInterceptor,
+		a.auditInterceptor,
+		a.policyInterceptor,
+		a.recoveryInterceptor,
+	}
+	stream := []grpc.StreamServerInterceptor{
+		requestid.StreamServerInterceptor,
+		a.authStreamInterceptor,
+		a.auditStreamInterceptor,
+		a.policyStreamInterceptor,
+		a.recoveryStreamInterceptor,
+	}
+	injector, err := chaos.New(a.config.FullConfig.Chaos)
+	if err != nil {
+		return nil, err
+	}
+	if injector != nil {
+		// Faults hit authorized calls only, so they look like the failures
+		// of a host that is up
+		fmt.Printf("Warning: chaos testing is enabled: %s\n", injector)
+		unary = append(unary, injector.UnaryServerInterceptor)
+		stream = append(stream, injector.StreamServerInterceptor)
+	}
 
 	// gRPC server with security interceptors
-	server := grpc.NewServer(
-		grpc.Creds(creds),
+	opts = append(opts,
 		grpc.MaxRecvMsgSize(10*1024*1024), // 10MB
 		grpc.MaxSendMsgSize(10*1024*1024),
-		grpc.ChainUnaryInterceptor(
-			a.authInterceptor,
-			a.auditInterceptor,
-			a.policyInterceptor,
-			a.recoveryInterceptor,
-		),
-		grpc.ChainStreamInterceptor(
-			a.authStreamInterceptor,
-			a.auditStreamInterceptor,
-			a.recoveryStreamInterceptor,
-		),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
+	server := grpc.NewServer(opts...)
 
 	// Register all services
 	agentv1.RegisterAgentServiceServer(server, a)
@@ -594,31 +982,105 @@ func (a *Agent) Serve() error {
 	agentv1.RegisterContainerServiceServer(server, a)
 	agentv1.RegisterFilesystemServiceServer(server, a)
 	agentv1.RegisterOperationsServiceServer(server, a)
+	agentv1.RegisterTransferServiceServer(server, a)
+	if capability.Has(a.capabilities, capability.Ports) {
+		agentv1.RegisterPortServiceServer(server, a)
+	}
+	if capability.Has(a.capabilities, capability.Processes) {
+		agentv1.RegisterProcessServiceServer(server, a)
+	}
+	if capability.Has(a.capabilities, capability.LogFiles) {
+		agentv1.RegisterHostLogServiceServer(server, a)
+	}
+	if capability.Has(a.capabilities, capability.Reboot) {
+		agentv1.RegisterHostPowerServiceServer(server, a)
+	}
+	if capability.Has(a.capabilities, capability.LogShip) {
+		agentv1.RegisterLogShipServiceServer(server, a)
+	}
+	if capability.Has(a.capabilities, capability.Commands) {
+		agentv1.RegisterCommandServiceServer(server, a)
+	}
+	service.NewServicesHandler(a.services).Register(server)
 
-	// Listen
-	lis, err := net.Listen("tcp", a.config.ListenAddr)
+	if a.config.FullConfig.Server.Reflection {
+		reflection.Register(server)
+	}
+
+	return server, nil
+}
+
+// peerTLS returns the TLS settings for one peer relation from the config
+// file, with the certificate and CA paths the agent resolved at startup
+func peerTLS(cfg *Config, section func(*config.AgentConfig) config.TLSConfig) config.TLSConfig {
+	var tlsCfg config.TLSConfig
+	if cfg.FullConfig != nil {
+		tlsCfg = section(cfg.FullConfig)
+	}
+	tlsCfg.CertPath = cfg.CertPath
+	tlsCfg.KeyPath = cfg.KeyPath
+	tlsCfg.CAPath = cfg.CAPath
+	return tlsCfg
+}
+
+func (a *Agent) loadServerCert() error {
+	cert, err := tls.LoadX509KeyPair(a.config.CertPath, a.config.KeyPath)
 	if err != nil {
-		return fmt.Errorf("listen: %w", err)
+		return fmt.Errorf("load cert: %w", err)
 	}
 
-	fmt.Printf("Mandau Agent %s listening on %s\n", a.config.AgentID, a.config.ListenAddr)
-	fmt.Printf("Hostname: %s\n", a.config.Hostname)
-	fmt.Printf("Stack root: %s\n", a.config.StackRoot)
-	fmt.Printf("Plugins loaded: %d\n", len(a.plugins.ListAll()))
+	a.mu.Lock()
+	a.serverCert = &cert
+	a.mu.Unlock()
+	return nil
+}
 
-	return server.Serve(lis)
+// Reload picks up a rotated serving certificate and re-registers with the
+// core so it sees current labels and capabilities. A failed step keeps the
+// previous state; CA or listener changes still need a restart.
+func (a *Agent) Reload() {
+	daemon.Notify(daemon.StateReloading)
+	defer daemon.Notify(daemon.StateReady)
+
+	if err := a.loadServerCert(); err != nil {
+		fmt.Printf("Reload: keeping previous certificate: %v\n", err)
+	}
+	if err := a.registerWithServer(); err != nil {
+		fmt.Printf("Reload: re-register failed: %v\n", err)
+	}
+	fmt.Println("Reload complete")
 }
 
 func (a *Agent) Shutdown() {
 	fmt.Println("Shutting down agent...")
+	daemon.Notify(daemon.StateStopping)
+	close(a.stop)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Let in-flight calls finish, but never wait past the deadline
+	a.mu.RLock()
+	server := a.grpcServer
+	a.mu.RUnlock()
+	if server != nil {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			server.Stop()
+		}
+	}
+
 	// Shutdown plugins
 	if err := a.plugins.ShutdownAll(ctx); err != nil {
 		fmt.Printf("Plugin shutdown error: %v\n", err)
 	}
+	a.services.Shutdown(ctx)
 
 	// Close server connection
 	if a.serverConn != nil {
@@ -669,24 +1131,52 @@ func (a *Agent) policyInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	identity := plugin.IdentityFromContext(ctx)
+	decision, err := a.evaluatePolicy(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
 
-	// Policy evaluation
-	if policy := a.plugins.Policy(); policy != nil {
-		decision, err := policy.Evaluate(ctx, &plugin.PolicyRequest{
-			Identity: identity,
-			Action: &plugin.Action{
-				Method: info.FullMethod,
-			},
-			Resource: extractResourceFromRequest(req),
-		})
+	return handler(context.WithValue(ctx, callPolicyKey{}, &callPolicy{decision: decision}), req)
+}
 
-		if err != nil || !decision.Allowed {
-			return nil, status.Errorf(codes.PermissionDenied, "access denied: %s", decision.Reason)
-		}
+// callPolicy holds the policy decision of a call so handlers can honour its
+// obligations; streams fill it in when the first message arrives
+type callPolicy struct {
+	decision *plugin.PolicyDecision
+}
+
+type callPolicyKey struct{}
+
+// policyDecision returns the policy decision of the call in ctx, or nil
+func policyDecision(ctx context.Context) *plugin.PolicyDecision {
+	if p, ok := ctx.Value(callPolicyKey{}).(*callPolicy); ok {
+		return p.decision
 	}
+	return nil
+}
 
-	return handler(ctx, req)
+// evaluatePolicy asks the policy plugin, if any, whether the caller may
+// invoke method with req
+func (a *Agent) evaluatePolicy(ctx context.Context, method string, req interface{}) (*plugin.PolicyDecision, error) {
+	policy := a.plugins.Policy()
+	if policy == nil {
+		return nil, nil
+	}
+
+	decision, err := policy.Evaluate(ctx, &plugin.PolicyRequest{
+		Identity: plugin.IdentityFromContext(ctx),
+		Action: &plugin.Action{
+			Method: method,
+		},
+		Resource: a.extractResourceFromRequest(req),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "access denied: %v", err)
+	}
+	if !decision.Allowed {
+		return nil, status.Errorf(codes.PermissionDenied, "access denied: %s", decision.Reason)
+	}
+	return decision, nil
 }
 
 func (a *Agent) auditInterceptor(
@@ -706,7 +1196,7 @@ func (a *Agent) auditInterceptor(
 		AgentID:   a.config.AgentID,
 		Identity:  identity,
 		Action:    info.FullMethod,
-		Resource:  extractResourceFromRequest(req).Identifier,
+		Resource:  a.extractResourceFromRequest(req).Identifier,
 		Result:    resultString(err),
 		Duration:  time.Since(start),
 		Metadata:  extractMetadata(req),
@@ -723,12 +1213,14 @@ func (a *Agent) recoveryInterceptor(
 ) (resp interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("PANIC in %s: %v\n", info.FullMethod, r)
+			fmt.Printf("PANIC in %s (request %s): %v\n", info.FullMethod, requestid.From(ctx), r)
 			err = status.Errorf(codes.Internal, "internal error")
 		}
 	}()
 
-	return handler(ctx, req)
+	resp, err = handler(ctx, req)
+	requestid.LogFailure(ctx, info.FullMethod, err)
+	return resp, err
 }
 
 func (a *Agent) authStreamInterceptor(
@@ -772,20 +1264,72 @@ func (a *Agent) auditStreamInterceptor(
 	ctx := ss.Context()
 	identity := plugin.IdentityFromContext(ctx)
 
-	err := handler(srv, ss)
+	recorder := audit.NewStream(ss)
+	err := handler(srv, recorder)
 
 	a.plugins.AuditAll(ctx, &plugin.AuditEntry{
 		Timestamp: start,
 		AgentID:   a.config.AgentID,
 		Identity:  identity,
 		Action:    info.FullMethod,
+		Resource:  a.extractResourceFromRequest(recorder.FirstRequest()).Identifier,
 		Result:    resultString(err),
 		Duration:  time.Since(start),
+		Metadata:  recorder.Metadata(),
 	})
 
 	return err
 }
 
+// policyStreamInterceptor evaluates policy against the request of streaming
+// RPCs, which arrives with the first message rather than with the call
+func (a *Agent) policyStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if a.plugins.Policy() == nil {
+		return handler(srv, ss)
+	}
+
+	call := &callPolicy{}
+	return handler(srv, &policyStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), callPolicyKey{}, call),
+		agent:        a,
+		method:       info.FullMethod,
+		call:         call,
+	})
+}
+
+// policyStream checks the first received message against policy
+type policyStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	agent   *Agent
+	method  string
+	call    *callPolicy
+	checked bool
+}
+
+func (p *policyStream) Context() context.Context {
+	return p.ctx
+}
+
+func (p *policyStream) RecvMsg(m interface{}) error {
+	if err := p.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if p.checked {
+		return nil
+	}
+	p.checked = true
+	decision, err := p.agent.evaluatePolicy(p.ctx, p.method, m)
+	p.call.decision = decision
+	return err
+}
+
 func (a *Agent) recoveryStreamInterceptor(
 	srv interface{},
 	ss grpc.ServerStream,
@@ -794,12 +1338,14 @@ func (a *Agent) recoveryStreamInterceptor(
 ) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("PANIC in stream %s: %v\n", info.FullMethod, r)
+			fmt.Printf("PANIC in stream %s (request %s): %v\n", info.FullMethod, requestid.From(ss.Context()), r)
 			err = status.Errorf(codes.Internal, "internal error")
 		}
 	}()
 
-	return handler(srv, ss)
+	err = handler(srv, ss)
+	requestid.LogFailure(ss.Context(), info.FullMethod, err)
+	return err
 }
 
 type wrappedStream struct {
@@ -829,12 +1375,13 @@ func (a *Agent) extractIdentity(ctx context.Context) (*plugin.Identity, error) {
 
 	cert := tlsInfo.State.VerifiedChains[0][0]
 
-	return &plugin.Identity{
-		UserID:      cert.Subject.CommonName,
-		DeviceID:    extractDeviceID(cert),
-		Certificate: cert.Raw,
-		Attributes:  make(map[string]string),
-	}, nil
+	identity := a.identities.Identity(cert)
+	if identity.UserID == "" {
+		return nil, fmt.Errorf("certificate names no user")
+	}
+	identity.DeviceID = extractDeviceID(cert)
+	identity.Certificate = cert.Raw
+	return identity, nil
 }
 
 func extractDeviceID(cert *x509.Certificate) string {
@@ -842,25 +1389,69 @@ func extractDeviceID(cert *x509.Certificate) string {
 	return cert.Subject.CommonName
 }
 
-func extractResourceFromRequest(req interface{}) *plugin.Resource {
-	// Extract resource info based on request type
-	// This is simplified - production would use type assertions
+func (a *Agent) extractResourceFromRequest(req interface{}) *plugin.Resource {
+	if start := execStart(req); start != nil {
+		return &plugin.Resource{
+			Type:       "container",
+			Identifier: start.ContainerId,
+			Labels:     make(map[string]string),
+		}
+	}
+
+	var stackName string
+	switch r := req.(type) {
+	case *agentv1.ApplyStackRequest:
+		stackName = r.StackName
+	case *agentv1.DiffStackRequest:
+		stackName = r.StackName
+	case *agentv1.ExportStackRequest:
+		stackName = r.StackName
+	case *agentv1.GetStackEventsRequest:
+		stackName = r.StackName
+	case *agentv1.GetStackRequest:
+		stackName = r.StackId
+	case *agentv1.RemoveStackRequest:
+		stackName = r.StackId
+	case *agentv1.GetStackLogsRequest:
+		stackName = r.StackName
+	}
+
+	if stackName == "" {
+		return &plugin.Resource{
+			Type:       "unknown",
+			Identifier: "",
+			Labels:     make(map[string]string),
+		}
+	}
+
+	// Stack labels and ownership let policies decide by owning team. Only
+	// the stored metadata is trusted: an apply must not be able to claim
+	// ownership of a stack to pass the policy that protects it.
+	md, err := a.stackMgr.Metadata(stackName)
+	if err != nil {
+		md = &stack.Metadata{}
+	}
+
 	return &plugin.Resource{
-		Type:       "unknown",
-		Identifier: "",
-		Labels:     make(map[string]string),
+		Type:       "stack",
+		Identifier: stackName,
+		Labels:     md.AsLabels(),
 	}
 }
 
 func extractMetadata(req interface{}) map[string]string {
-	return make(map[string]string)
+	return audit.Metadata(req)
 }
 
 func resultString(err error) string {
-	if err != nil {
+	switch status.Code(err) {
+	case codes.OK:
+		return "success"
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return "denied"
+	default:
 		return "error"
 	}
-	return "success"
 }
 
 // =============================================================================
@@ -882,13 +1473,8 @@ func (a *Agent) Heartbeat(ctx context.Context, req *agentv1.HeartbeatRequest) (*
 
 func (a *Agent) GetCapabilities(ctx context.Context, req *agentv1.CapabilitiesRequest) (*agentv1.CapabilitiesResponse, error) {
 	return &agentv1.CapabilitiesResponse{
-		Capabilities: []string{
-			"stack.apply",
-			"stack.remove",
-			"container.exec",
-			"logs.stream",
-			"files.manage",
-		},
+		Capabilities: a.capabilities,
+		Plugins:      a.services.Availability(),
 	}, nil
 }
 
@@ -916,18 +1502,45 @@ func (a *Agent) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest)
 		return nil, status.Errorf(codes.Internal, "list stacks: %v", err)
 	}
 
-	result := make([]*agentv1.Stack, len(stacks))
-	for i, stack := range stacks {
-		result[i] = &agentv1.Stack{
+	var usage map[string]*stack.DiskUsage
+	if req.DiskUsage {
+		if usage, err = a.stackMgr.DiskUsage(ctx); err != nil {
+			return nil, status.Errorf(codes.Internal, "stack disk usage: %v", err)
+		}
+	}
+	var digests map[string][]string
+	if req.ImageDigests {
+		if digests, err = a.stackMgr.ImageDigests(ctx); err != nil {
+			return nil, status.Errorf(codes.Internal, "image digests: %v", err)
+		}
+	}
+
+	result := make([]*agentv1.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		if req.Namespace != "" && stack.Namespace != req.Namespace {
+			continue
+		}
+		if !matchLabels(stack.Labels, req.Labels) {
+			continue
+		}
+		containers := convertContainers(stack.Containers)
+		for _, container := range containers {
+			container.ImageDigests = digests[container.ImageId]
+		}
+		result = append(result, &agentv1.Stack{
 			Id:         stack.ID,
 			Name:       stack.Name,
 			Path:       stack.Path,
 			State:      convertStackState(stack.State),
-			Containers: convertContainers(stack.Containers),
+			Containers: containers,
 			CreatedAt:  convertTimeToProto(stack.CreatedAt),
 			UpdatedAt:  convertTimeToProto(stack.UpdatedAt),
 			Labels:     stack.Labels,
-		}
+			Owner:      convertOwner(stack.Owner),
+			Namespace:  stack.Namespace,
+			Resources:  convertResources(stack.Resources),
+			DiskUsage:  a.convertDiskUsage(usage[stack.Name]),
+		})
 	}
 
 	return &agentv1.ListStacksResponse{
@@ -936,6 +1549,10 @@ func (a *Agent) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest)
 }
 
 func (a *Agent) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*agentv1.GetStackResponse, error) {
+	if err := a.requireStackNamespace(req.StackId, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	stack, err := a.stackMgr.GetStack(ctx, req.StackId)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "get stack: %v", err)
@@ -951,6 +1568,9 @@ func (a *Agent) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*ag
 			CreatedAt:  convertTimeToProto(stack.CreatedAt),
 			UpdatedAt:  convertTimeToProto(stack.UpdatedAt),
 			Labels:     stack.Labels,
+			Owner:      convertOwner(stack.Owner),
+			Namespace:  stack.Namespace,
+			Resources:  convertResources(stack.Resources),
 		},
 	}, nil
 }
@@ -958,17 +1578,26 @@ func (a *Agent) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*ag
 func (a *Agent) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackService_ApplyStackServer) error {
 	ctx := stream.Context()
 
-	// Convert proto request to internal request
-	internalReq := &stack.ApplyStackRequest{
-		StackName:      req.StackName,
-		ComposeContent: req.ComposeContent,
-		EnvVars:        req.EnvVars,
-		ForceRecreate:  req.ForceRecreate,
-		Services:       req.Services,
-		PullImages:     req.PullImages,
+	if err := a.instructions.drainError(); err != nil {
+		return err
 	}
 
-	opID, err := a.stackMgr.ApplyStack(ctx, internalReq)
+	opID, err := a.stackMgr.ApplyStack(withAuthor(ctx), applyRequestFromProto(req))
+	if errors.Is(err, stack.ErrNamespaceMismatch) || errors.Is(err, stack.ErrHooksDisabled) || errors.Is(err, stack.ErrFilesMissing) {
+		return status.Errorf(codes.FailedPrecondition, "apply stack: %v", err)
+	}
+	if errors.Is(err, operation.ErrKeyReused) {
+		return status.Errorf(codes.AlreadyExists, "apply stack: %v", err)
+	}
+	if errors.Is(err, stack.ErrStackTooLarge) {
+		return status.Errorf(codes.ResourceExhausted, "apply stack: %v", err)
+	}
+	if errors.Is(err, stack.ErrInvalidFile) || errors.Is(err, stack.ErrInvalidStackName) {
+		return status.Errorf(codes.InvalidArgument, "apply stack: %v", err)
+	}
+	if errors.Is(err, stack.ErrStackLocked) {
+		return status.Errorf(codes.Aborted, "apply stack: %v", err)
+	}
 	if err != nil {
 		return status.Errorf(codes.Internal, "apply stack: %v", err)
 	}
@@ -977,6 +1606,10 @@ func (a *Agent) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackS
 	events := a.opMgr.Subscribe(opID)
 	defer a.opMgr.Unsubscribe(opID, events)
 
+	if done, err := a.sendFinished(opID, stream.Send); done {
+		return err
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -1018,7 +1651,22 @@ func (a *Agent) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.Stac
 	// Extract stack name from stack ID (in our case, stack ID is the name)
 	stackName := req.StackId
 
-	opID, err := a.stackMgr.RemoveStack(ctx, stackName, false) // Don't remove volumes by default
+	if err := a.instructions.drainError(); err != nil {
+		return err
+	}
+
+	if err := a.requireStackNamespace(stackName, req.Namespace); err != nil {
+		return err
+	}
+
+	// Don't remove volumes by default
+	opID, err := a.stackMgr.RemoveStack(withAuthor(ctx), stackName, false, operation.NewIdempotency(req.IdempotencyKey, req))
+	if errors.Is(err, operation.ErrKeyReused) {
+		return status.Errorf(codes.AlreadyExists, "remove stack: %v", err)
+	}
+	if errors.Is(err, stack.ErrStackLocked) {
+		return status.Errorf(codes.Aborted, "remove stack: %v", err)
+	}
 	if err != nil {
 		return status.Errorf(codes.Internal, "remove stack: %v", err)
 	}
@@ -1027,6 +1675,10 @@ func (a *Agent) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.Stac
 	events := a.opMgr.Subscribe(opID)
 	defer a.opMgr.Unsubscribe(opID, events)
 
+	if done, err := a.sendFinished(opID, stream.Send); done {
+		return err
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -1063,7 +1715,18 @@ func (a *Agent) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.Stac
 }
 
 func (a *Agent) DiffStack(ctx context.Context, req *agentv1.DiffStackRequest) (*agentv1.DiffStackResponse, error) {
-	result, err := a.stackMgr.DiffStack(ctx, req.StackName, req.NewComposeContent)
+	if err := a.requireStackNamespace(req.StackName, req.Namespace); err != nil {
+		return nil, err
+	}
+
+	var files []stack.Content
+	if req.ProjectFiles != nil {
+		files = contentsFromProto(req.ProjectFiles.Files)
+	}
+	result, err := a.stackMgr.DiffStack(ctx, req.StackName, req.NewComposeContent, contentsFromProto(req.ComposeOverrides), files)
+	if errors.Is(err, stack.ErrInvalidFile) || errors.Is(err, stack.ErrInvalidStackName) {
+		return nil, status.Errorf(codes.InvalidArgument, "diff stack: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "diff stack: %v", err)
 	}
@@ -1078,38 +1741,228 @@ func (a *Agent) DiffStack(ctx context.Context, req *agentv1.DiffStackRequest) (*
 		}
 	}
 
+	protoFiles := make([]*agentv1.StackFileDiff, len(result.Files))
+	for i, f := range result.Files {
+		protoFiles[i] = &agentv1.StackFileDiff{Path: f.Path, Action: convertDiffAction(f.Action)}
+	}
+
 	return &agentv1.DiffStackResponse{
 		Services:   protoServices,
+		Files:      protoFiles,
 		HasChanges: result.HasChanges,
 	}, nil
 }
 
+// stackHookConfig reads stacks.hooks
+func stackHookConfig(cfg config.StackHooksConfig) stack.HookConfig {
+	hooks := stack.HookConfig{Enabled: cfg.Enabled, Shell: cfg.Shell}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || d <= 0 {
+			fmt.Printf("Warning: invalid stacks.hooks.timeout %q, using %s\n", cfg.Timeout, stack.DefaultHookTimeout)
+		} else {
+			hooks.Timeout = d
+		}
+	}
+	if cfg.Enabled {
+		fmt.Println("Stack hooks are enabled: applies may run shell scripts on this host")
+	}
+	return hooks
+}
+
+func (a *Agent) ExportStack(ctx context.Context, req *agentv1.ExportStackRequest) (*agentv1.StackExport, error) {
+	if err := a.requireStackNamespace(req.StackName, req.Namespace); err != nil {
+		return nil, err
+	}
+
+	export, err := a.stackMgr.Export(ctx, req.StackName)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "export stack: %v", err)
+	}
+
+	compose, env := export.Compose, export.Env
+	overrides, files := contentsToProto(export.Overrides), contentsToProto(export.ProjectFiles)
+	if !req.RevealSecrets {
+		compose = a.redactor.String(compose)
+		env = a.redactor.Env(env)
+		for _, o := range overrides {
+			o.Content = []byte(a.redactor.String(string(o.Content)))
+		}
+		for _, f := range files {
+			if stack.SecretFile(f.Path) {
+				f.Content = nil
+			}
+		}
+	}
+
+	s := export.Stack
+	return &agentv1.StackExport{
+		Name:           s.Name,
+		Namespace:      s.Namespace,
+		ComposeContent: compose,
+		EnvVars:        env,
+		Labels:         s.Labels,
+		Owner:          convertOwner(s.Owner),
+		State:          convertStackState(s.State),
+		Containers:     convertContainers(s.Containers),
+		ExportedAt:     timestamppb.Now(),
+		Redacted:       !req.RevealSecrets,
+
+		ComposeOverrides: overrides,
+		ProjectFiles:     files,
+	}, nil
+}
+
+func (a *Agent) CollectStackGarbage(ctx context.Context, req *agentv1.CollectStackGarbageRequest) (*agentv1.CollectStackGarbageResponse, error) {
+	orphans, err := a.stackMgr.CollectGarbage(ctx, req.Apply)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "collect stack garbage: %v", err)
+	}
+
+	resp := &agentv1.CollectStackGarbageResponse{Applied: req.Apply}
+	for _, o := range orphans {
+		orphan := &agentv1.StackOrphan{
+			Kind:         convertOrphanKind(o.Kind),
+			StackName:    o.Stack,
+			Path:         o.Path,
+			ContainerIds: o.Containers,
+			Detail:       o.Detail,
+			Removed:      o.Removed,
+		}
+		if o.Err != nil {
+			orphan.Error = o.Err.Error()
+		}
+		resp.Orphans = append(resp.Orphans, orphan)
+	}
+	return resp, nil
+}
+
+func convertOrphanKind(kind stack.OrphanKind) agentv1.OrphanKind {
+	switch kind {
+	case stack.OrphanStackDir:
+		return agentv1.OrphanKind_ORPHAN_KIND_STACK_DIR
+	case stack.OrphanContainers:
+		return agentv1.OrphanKind_ORPHAN_KIND_CONTAINERS
+	case stack.OrphanStaleFile:
+		return agentv1.OrphanKind_ORPHAN_KIND_STALE_FILE
+	default:
+		return agentv1.OrphanKind_ORPHAN_KIND_UNSPECIFIED
+	}
+}
+
 func (a *Agent) GetStackLogs(req *agentv1.GetStackLogsRequest, stream agentv1.StackService_GetStackLogsServer) error {
-	ctx := stream.Context()
+	return a.streamStackLogs(stream.Context(), req, stream.Send)
+}
+
+func (a *Agent) GetStackLogsBatched(req *agentv1.GetStackLogsRequest, stream agentv1.StackService_GetStackLogsBatchedServer) error {
+	size, interval := transport.BatchLimits(req)
+	batcher := transport.NewLogBatcher(size, interval, stream.Send)
+
+	if err := a.streamStackLogs(stream.Context(), req, batcher.Add); err != nil {
+		return err
+	}
+	return batcher.Flush()
+}
+
+// Lines of history sent before following, and read without following
+const (
+	followLogTail = "100"
+	readLogTail   = "1000"
+)
+
+// streamStackLogs passes the log entries of a stack's containers to send.
+// Followers share one Docker log stream per stack through the log hub.
+func (a *Agent) streamStackLogs(ctx context.Context, req *agentv1.GetStackLogsRequest, send func(*agentv1.LogEntry) error) error {
+	if err := a.requireStackNamespace(req.StackName, req.Namespace); err != nil {
+		return err
+	}
+	query, err := parseStackLogQuery(req)
+	if err != nil {
+		return err
+	}
 
-	// Get containers for the stack to stream logs from
 	stack, err := a.stackMgr.GetStack(ctx, req.StackName)
 	if err != nil {
 		return status.Errorf(codes.NotFound, "get stack: %v", err)
 	}
 
-	// Stream logs from each container in the stack
-	for _, container := range stack.Containers {
-		// For now, we'll send a simple log entry - in production this would connect to the actual container logs
-		logEntry := &agentv1.LogEntry{
-			Timestamp:   timestamppb.Now(),
-			Stream:      "stdout",
-			Content:     []byte(fmt.Sprintf("Logs for container %s in stack %s", container.Name, req.StackName)),
-			ContainerId: container.ID,
-			ServiceName: container.Service,
+	// Containers often print their configuration on startup. Followed
+	// entries are shared between viewers, so masked ones are copies.
+	// Grep matches what the viewer sees, so secrets cannot be probed for.
+	sendRaw := send
+	send = func(entry *agentv1.LogEntry) error {
+		if content := a.redactor.String(string(entry.Content)); content != string(entry.Content) {
+			entry = &agentv1.LogEntry{
+				Timestamp:   entry.Timestamp,
+				Stream:      entry.Stream,
+				Content:     []byte(content),
+				ContainerId: entry.ContainerId,
+				ServiceName: entry.ServiceName,
+			}
+		}
+		if query.grep != nil && !query.grep.Match(entry.Content) {
+			return nil
 		}
+		return sendRaw(entry)
+	}
 
-		if err := stream.Send(logEntry); err != nil {
+	containers := make([]logs.Container, len(stack.Containers))
+	for i, c := range stack.Containers {
+		containers[i] = logs.Container{ID: c.ID, Service: c.Service}
+	}
+
+	// History is sent first when searching or before following from a
+	// point; followed entries it already covered are skipped
+	var sentUntil time.Time
+	if !req.Follow || !query.since.IsZero() || req.Tail > 0 {
+		err := a.stackLogHistory(ctx, req.StackName, containers, query, func(entry *agentv1.LogEntry) error {
+			if ts := entry.Timestamp.AsTime(); ts.After(sentUntil) {
+				sentUntil = ts
+			}
+			return send(entry)
+		})
+		if err != nil || !req.Follow {
 			return err
 		}
 	}
 
-	return nil
+	sub := a.logHub.Subscribe(req.StackName, a.logPolicy, logs.DockerSource(a.docker, containers, true, followLogTail))
+	defer sub.Close()
+
+	var reported int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-sub.Entries():
+			if !ok {
+				if err := sub.Err(); err != nil {
+					return status.Errorf(codes.Internal, "read logs: %v", err)
+				}
+				return nil
+			}
+
+			// Tell the viewer when it fell behind and lost entries
+			if dropped := sub.Dropped(); dropped > reported {
+				notice := &agentv1.LogEntry{
+					Timestamp: timestamppb.Now(),
+					Stream:    "system",
+					Content:   []byte(fmt.Sprintf("%d log entries dropped: viewer too slow", dropped-reported)),
+				}
+				reported = dropped
+				if err := sendRaw(notice); err != nil {
+					return err
+				}
+			}
+			if !sentUntil.IsZero() && !entry.Timestamp.AsTime().After(sentUntil) {
+				continue
+			}
+
+			if err := send(entry); err != nil {
+				return err
+			}
+		}
+	}
 }
 
 func healthStatus(err error) string {
@@ -1139,21 +1992,161 @@ func convertContainers(containers []stack.ContainerInfo) []*agentv1.Container {
 	result := make([]*agentv1.Container, len(containers))
 	for i, container := range containers {
 		result[i] = &agentv1.Container{
-			Id:     container.ID,
-			Name:   container.Name,
-			Image:  container.Image,
-			State:  container.State,
-			Status: container.Status,
-			Labels: map[string]string{}, // Add labels if available
+			Id:      container.ID,
+			Name:    container.Name,
+			Image:   container.Image,
+			ImageId: container.ImageID,
+			State:   container.State,
+			Status:  container.Status,
+			Labels:  map[string]string{}, // Add labels if available
 		}
 	}
 	return result
 }
 
+// requireStackNamespace hides stacks of other namespaces: they are reported
+// as not found rather than forbidden so their names do not leak
+func (a *Agent) requireStackNamespace(name, namespace string) error {
+	err := a.stackMgr.CheckNamespace(name, namespace)
+	if errors.Is(err, stack.ErrNamespaceMismatch) {
+		return status.Errorf(codes.NotFound, "stack not found: %s", name)
+	}
+	if errors.Is(err, stack.ErrInvalidStackName) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "check namespace: %v", err)
+	}
+	return nil
+}
+
+func convertOwner(owner stack.Owner) *agentv1.StackOwner {
+	if owner == (stack.Owner{}) {
+		return nil
+	}
+	return &agentv1.StackOwner{
+		Team:   owner.Team,
+		Owner:  owner.Owner,
+		Ticket: owner.Ticket,
+	}
+}
+
+func convertOwnerFromProto(owner *agentv1.StackOwner) stack.Owner {
+	return stack.Owner{
+		Team:   owner.GetTeam(),
+		Owner:  owner.GetOwner(),
+		Ticket: owner.GetTicket(),
+	}
+}
+
+func convertResources(r quota.Reservations) *agentv1.StackResources {
+	return &agentv1.StackResources{
+		Containers:  int32(r.Containers),
+		Cpus:        r.CPUs,
+		MemoryBytes: r.MemoryBytes,
+	}
+}
+
+// convertDiskUsage returns nil for stacks that were not measured
+func (a *Agent) convertDiskUsage(u *stack.DiskUsage) *agentv1.StackDiskUsage {
+	if u == nil {
+		return nil
+	}
+	return &agentv1.StackDiskUsage{
+		DirectoryBytes: u.DirectoryBytes,
+		VolumeBytes:    u.VolumeBytes,
+		ContainerBytes: u.ContainerBytes,
+		ImageBytes:     u.ImageBytes,
+		LimitBytes:     a.stackMgr.MaxSize(),
+	}
+}
+
+// matchLabels reports whether labels contain every key/value in selector
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func convertTimeToProto(t time.Time) *timestamppb.Timestamp {
 	return timestamppb.New(t)
 }
 
+// sendFinished sends the final event of opID when the operation is over
+// already, as it is when a retried request is handed the operation its first
+// attempt started. It reports whether it did.
+func (a *Agent) sendFinished(opID string, send func(*agentv1.OperationEvent) error) (bool, error) {
+	op, err := a.opMgr.GetOperation(opID)
+	if err != nil {
+		return false, nil
+	}
+	switch op.State {
+	case operation.OperationStateCompleted, operation.OperationStateFailed, operation.OperationStateCancelled:
+	default:
+		return false, nil
+	}
+
+	errorMsg := ""
+	if op.Error != nil {
+		errorMsg = op.Error.Error()
+	}
+	return true, send(&agentv1.OperationEvent{
+		OperationId: op.ID,
+		State:       convertOperationState(op.State),
+		Timestamp:   timestamppb.Now(),
+		Message:     op.Message,
+		Progress:    int32(op.Progress),
+		Error:       errorMsg,
+	})
+}
+
+// GetOperation returns one operation of this agent
+func (a *Agent) GetOperation(ctx context.Context, req *agentv1.GetOperationRequest) (*agentv1.Operation, error) {
+	op, err := a.opMgr.GetOperation(req.OperationId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+	return convertOperation(op), nil
+}
+
+// ListOperations returns the operations of this agent, newest first
+func (a *Agent) ListOperations(ctx context.Context, req *agentv1.ListOperationsRequest) (*agentv1.ListOperationsResponse, error) {
+	ops := a.opMgr.ListOperations(func(op *operation.Operation) bool {
+		return req.IdempotencyKey == "" || op.IdempotencyKey == req.IdempotencyKey
+	})
+	sort.Slice(ops, func(i, j int) bool { return ops[i].CreatedAt.After(ops[j].CreatedAt) })
+
+	resp := &agentv1.ListOperationsResponse{}
+	for _, op := range ops {
+		resp.Operations = append(resp.Operations, convertOperation(op))
+	}
+	return resp, nil
+}
+
+func convertOperation(op *operation.Operation) *agentv1.Operation {
+	result := &agentv1.Operation{
+		Id:             op.ID,
+		Type:           string(op.Type),
+		State:          convertOperationState(op.State),
+		CreatedAt:      convertTimeToProto(op.CreatedAt),
+		Metadata:       op.Metadata,
+		Progress:       int32(op.Progress),
+		IdempotencyKey: op.IdempotencyKey,
+		Message:        op.Message,
+		RequestId:      op.RequestID,
+	}
+	if op.CompletedAt != nil {
+		result.CompletedAt = convertTimeToProto(*op.CompletedAt)
+	}
+	if op.Error != nil {
+		result.Error = op.Error.Error()
+	}
+	return result
+}
+
 func convertOperationState(state operation.OperationState) agentv1.OperationState {
 	switch state {
 	case operation.OperationStateRunning:
@@ -1196,7 +2189,10 @@ func loadPluginsFromDir(registry *plugin.Registry, dir string, pluginConfig conf
 				return fmt.Errorf("register rbac plugin: %w", err)
 			}
 		default:
-			fmt.Printf("Unknown plugin: %s\n", pluginName)
+			// Host service plugins are started by the service manager
+			if !service.IsServicePlugin(pluginName) {
+				fmt.Printf("Unknown plugin: %s\n", pluginName)
+			}
 		}
 	}
 
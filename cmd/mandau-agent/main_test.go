@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/service"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"env": "prod", "tier": "frontend"}
+	tests := []struct {
+		selector map[string]string
+		want     bool
+	}{
+		{nil, true},
+		{map[string]string{"env": "prod"}, true},
+		{map[string]string{"env": "prod", "tier": "frontend"}, true},
+		{map[string]string{"env": "staging"}, false},
+		{map[string]string{"env": "prod", "team": "payments"}, false},
+		{map[string]string{"env": ""}, false},
+	}
+	for _, tt := range tests {
+		if got := matchLabels(labels, tt.selector); got != tt.want {
+			t.Errorf("matchLabels(%v) = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestListStacksByLabels(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "stacks")
+	for name, metadata := range map[string]string{
+		"shop":    "labels:\n  env: prod\n  tier: frontend\n",
+		"api":     "labels:\n  env: prod\n  tier: backend\n",
+		"sandbox": "labels:\n  env: staging\n",
+		"legacy":  "",
+	} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services:\n  app:\n    image: nginx\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if metadata != "" {
+			if err := os.WriteFile(filepath.Join(dir, ".mandau.yaml"), []byte(metadata), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	docker := fakeDocker(t)
+	a := &Agent{docker: docker, stackMgr: stack.NewManager(root, docker, nil, nil, nil)}
+
+	tests := []struct {
+		selector map[string]string
+		want     string
+	}{
+		{nil, "api legacy sandbox shop"},
+		{map[string]string{"env": "prod"}, "api shop"},
+		{map[string]string{"env": "prod", "tier": "frontend"}, "shop"},
+		{map[string]string{"env": "qa"}, ""},
+	}
+	for _, tt := range tests {
+		resp, err := a.ListStacks(context.Background(), &agentv1.ListStacksRequest{Labels: tt.selector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range resp.Stacks {
+			names = append(names, s.Name)
+			if s.Name == "shop" && s.Labels["tier"] != "frontend" {
+				t.Errorf("shop labels = %v", s.Labels)
+			}
+		}
+		sort.Strings(names)
+		if got := strings.Join(names, " "); got != tt.want {
+			t.Errorf("ListStacks(%v) = %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+}
+
+// userAuth knows the users listed and nobody else
+type userAuth struct{ users []string }
+
+func (a *userAuth) Name() string    { return "user-auth" }
+func (a *userAuth) Version() string { return "test" }
+func (a *userAuth) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityAuth}
+}
+func (a *userAuth) Init(ctx context.Context, config map[string]interface{}) error { return nil }
+func (a *userAuth) Shutdown(ctx context.Context) error                            { return nil }
+func (a *userAuth) Authenticate(ctx context.Context, req *plugin.AuthRequest) (*plugin.Identity, error) {
+	if !slices.Contains(a.users, req.Identity.UserID) {
+		return nil, fmt.Errorf("user not found: %s", req.Identity.UserID)
+	}
+	return req.Identity, nil
+}
+func (a *userAuth) Authorize(ctx context.Context, identity *plugin.Identity, action *plugin.Action) error {
+	return nil
+}
+
+// listServices asks the agent served as user, or to callers without a
+// certificate when user is empty, for its services by reflection and
+// returns once the call has ended
+func listServices(t *testing.T, a *Agent, user string) ([]string, error) {
+	t.Helper()
+	withCert := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if user == "" {
+			return handler(srv, ss)
+		}
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: user}}
+		ctx := peer.NewContext(ss.Context(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+	server, err := a.newServer(grpc.StreamInterceptor(withCert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///agent",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		return nil, err
+	}
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.Name)
+	}
+	return names, nil
+}
+
+func TestReflection(t *testing.T) {
+	const method = "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"
+	newAgent := func(reflection bool) (*Agent, *execAudit) {
+		auditor := &execAudit{}
+		plugins := plugin.NewRegistry()
+		for _, p := range []plugin.Plugin{auditor, &userAuth{users: []string{"alice"}}} {
+			if err := plugins.Register(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return &Agent{
+			config: &Config{
+				AgentID:    "agent-1",
+				FullConfig: &config.AgentConfig{Server: config.ServerConfig{Reflection: reflection}},
+			},
+			plugins:  plugins,
+			services: &service.ServiceManager{},
+		}, auditor
+	}
+
+	// Enabled, it describes the agent's API to users the auth plugin knows,
+	// and the call is audited as theirs
+	a, auditor := newAgent(true)
+	services, err := listServices(t, a, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"mandau.agent.v1.StackService", "mandau.agent.v1.ContainerService", "grpc.reflection.v1.ServerReflection"} {
+		if !slices.Contains(services, want) {
+			t.Errorf("services = %v, missing %s", services, want)
+		}
+	}
+	if entry := auditor.find(method); entry == nil || entry.Result != "success" || entry.AgentID != "agent-1" ||
+		entry.Identity == nil || entry.Identity.UserID != "alice" {
+		t.Errorf("entry = %+v, want alice's call", entry)
+	}
+
+	// Users the auth plugin rejects, and callers without a certificate, are
+	// refused as on any other call
+	for _, user := range []string{"mallory", ""} {
+		if _, err := listServices(t, a, user); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%q: %v, want Unauthenticated", user, err)
+		}
+	}
+
+	// Disabled, there is no reflection service to call
+	a, _ = newAgent(false)
+	if _, err := listServices(t, a, "alice"); status.Code(err) != codes.Unimplemented {
+		t.Errorf("disabled reflection: %v, want Unimplemented", err)
+	}
+}
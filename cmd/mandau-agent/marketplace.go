@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goplugin "plugin"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/marketplace"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// InstallPlugin downloads a plugin from the signed index the core passes
+// along and loads it. A plugin that is already running keeps running until
+// the agent restarts, since Go cannot unload a plugin.
+func (a *Agent) InstallPlugin(ctx context.Context, req *agentv1.InstallPluginRequest) (*agentv1.InstalledPlugin, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if len(req.SignedIndex) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "signed_index is required")
+	}
+
+	installed, err := a.installer.Install(ctx, req.SignedIndex, req.Name, req.Version)
+	switch {
+	case errors.Is(err, marketplace.ErrDisabled):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, marketplace.ErrUntrusted):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "install plugin: %v", err)
+	}
+
+	loaded := false
+	if !a.pluginLoaded(installed.Name) {
+		if err := a.loadInstalledPlugin(ctx, *installed); err != nil {
+			return nil, status.Errorf(codes.Internal, "load plugin: %v", err)
+		}
+		loaded = true
+	}
+	return toProtoInstalledPlugin(*installed, loaded), nil
+}
+
+// installedPlugins is the inventory sent to the core on registration
+func (a *Agent) installedPlugins() []*agentv1.InstalledPlugin {
+	inventory, err := a.installer.Inventory()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return nil
+	}
+	result := make([]*agentv1.InstalledPlugin, len(inventory))
+	for i, p := range inventory {
+		result[i] = toProtoInstalledPlugin(p, a.pluginLoaded(p.Name))
+	}
+	return result
+}
+
+func (a *Agent) pluginLoaded(name string) bool {
+	for _, p := range a.plugins.ListAll() {
+		if p.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadInstalledPlugins loads the enabled plugins of the inventory. A plugin
+// that fails to load is reported and skipped, like plugins from the config.
+func (a *Agent) loadInstalledPlugins(ctx context.Context) {
+	inventory, err := a.installer.Inventory()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	for _, p := range inventory {
+		if !p.Enabled || a.pluginLoaded(p.Name) {
+			continue
+		}
+		if err := a.loadInstalledPlugin(ctx, p); err != nil {
+			fmt.Printf("Warning: installed plugin %s: %v\n", p.Name, err)
+		}
+	}
+}
+
+// loadInstalledPlugin opens a plugin binary, which must export
+// "func New() plugin.Plugin", initializes it with its section of
+// plugins.configs and confines it to the permissions of its release
+func (a *Agent) loadInstalledPlugin(ctx context.Context, p marketplace.Installed) error {
+	lib, err := goplugin.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	sym, err := lib.Lookup("New")
+	if err != nil {
+		return err
+	}
+	newPlugin, ok := sym.(func() plugin.Plugin)
+	if !ok {
+		return fmt.Errorf("%s: New is %T, want func() plugin.Plugin", p.Path, sym)
+	}
+
+	instance := newPlugin()
+	if instance.Name() != p.Name {
+		return fmt.Errorf("%s: plugin calls itself %s", p.Path, instance.Name())
+	}
+	if err := instance.Init(ctx, a.config.FullConfig.Plugins.Configs[p.Name]); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+
+	// The index entry is what the publisher signed, so it bounds what the
+	// binary may ask for
+	sandboxed, ok := instance.(plugin.SandboxedPlugin)
+	if !ok {
+		instance.Shutdown(ctx)
+		return fmt.Errorf("%s does not declare its permissions (plugin.SandboxedPlugin)", p.Path)
+	}
+	if extra := sandboxed.Permissions().Exceeding(p.Permissions); len(extra) > 0 {
+		instance.Shutdown(ctx)
+		return fmt.Errorf("plugin needs permissions its index entry does not declare: %s", strings.Join(extra, ", "))
+	}
+	a.plugins.Confine(instance, &p.Permissions)
+	return a.plugins.Register(instance)
+}
+
+func toProtoInstalledPlugin(p marketplace.Installed, loaded bool) *agentv1.InstalledPlugin {
+	return &agentv1.InstalledPlugin{
+		Name:        p.Name,
+		Version:     p.Version,
+		Sha256:      p.SHA256,
+		Path:        p.Path,
+		Enabled:     p.Enabled,
+		Loaded:      loaded,
+		InstalledAt: timestamppb.New(p.InstalledAt),
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/agent/mirror"
+	"github.com/bhangun/mandau/pkg/diagnose"
+)
+
+// watchMirrors health-checks the registry mirrors until the agent stops,
+// reporting mirrors that go down or come back
+func (a *Agent) watchMirrors() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stop
+		cancel()
+	}()
+
+	a.mirrors.Run(ctx, func(st mirror.Status) {
+		if st.Healthy {
+			fmt.Printf("Registry mirror %s is healthy again\n", st.Mirror)
+		} else {
+			fmt.Printf("Warning: registry mirror %s is unhealthy, pulls try it last: %s\n", st.Mirror, st.Error)
+		}
+	})
+}
+
+// checkMirrors reports the health of each registry mirror. A registry whose
+// mirrors are all down fails when pulls may not fall back to it.
+func (a *Agent) checkMirrors() []diagnose.Check {
+	status := a.mirrors.Status()
+	down := make(map[string]int)
+	total := make(map[string]int)
+	for _, st := range status {
+		total[st.Registry]++
+		if !st.Healthy {
+			down[st.Registry]++
+		}
+	}
+
+	checks := make([]diagnose.Check, 0, len(status))
+	for _, st := range status {
+		name := "registry mirror " + st.Mirror.String()
+		if st.Healthy {
+			detail := "not checked yet"
+			if !st.CheckedAt.IsZero() {
+				detail = fmt.Sprintf("mirrors %s, checked %s ago", st.Registry, time.Since(st.CheckedAt).Round(time.Second))
+			}
+			checks = append(checks, diagnose.OK(name, detail))
+			continue
+		}
+
+		detail := fmt.Sprintf("mirrors %s: %s", st.Registry, st.Error)
+		remediation := "Check that the mirror is up and reachable from the agent; pulls try it after the healthy mirrors"
+		if down[st.Registry] == total[st.Registry] && !a.mirrors.Upstream() {
+			checks = append(checks, diagnose.Fail(name, detail,
+				"Every mirror of "+st.Registry+" is down and no_upstream is set, so its images cannot be pulled; bring a mirror back"))
+			continue
+		}
+		checks = append(checks, diagnose.Warn(name, detail, remediation))
+	}
+	return checks
+}
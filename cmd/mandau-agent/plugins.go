@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DescribePlugin reports a running plugin and the host access it is
+// confined to
+func (a *Agent) DescribePlugin(ctx context.Context, req *agentv1.DescribePluginRequest) (*agentv1.PluginDescription, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	desc := &agentv1.PluginDescription{Name: req.Name, AgentId: a.config.AgentID}
+	var (
+		found plugin.Plugin
+		perms plugin.Permissions
+	)
+	for _, p := range a.plugins.ListAll() {
+		if p.Name() == req.Name {
+			found = p
+			perms, desc.Sandboxed = a.plugins.Permissions(req.Name)
+			desc.Source = "builtin"
+		}
+	}
+	if found == nil {
+		p, granted, ok := a.services.Plugin(req.Name)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "plugin %s is not running on this agent", req.Name)
+		}
+		found, perms, desc.Source = p, granted, "service"
+		_, desc.Sandboxed = p.(plugin.SandboxedPlugin)
+	}
+
+	if inventory, err := a.installer.Inventory(); err == nil {
+		for _, installed := range inventory {
+			if installed.Name == req.Name {
+				desc.Source = "installed"
+			}
+		}
+	}
+
+	desc.Version = found.Version()
+	for _, c := range found.Capabilities() {
+		desc.Capabilities = append(desc.Capabilities, string(c))
+	}
+	desc.Permissions = &agentv1.PluginPermissions{
+		Exec:    perms.Exec,
+		Write:   perms.Write,
+		Network: perms.Network,
+	}
+	return desc, nil
+}
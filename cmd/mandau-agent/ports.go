@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/ports"
+	"github.com/moby/moby/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListListeningPorts lists the sockets listening on the host with the
+// processes and containers owning them. Without Docker the containers are
+// left out rather than failing the call.
+func (a *Agent) ListListeningPorts(ctx context.Context, req *agentv1.ListListeningPortsRequest) (*agentv1.ListListeningPortsResponse, error) {
+	if req.Port < 0 || req.Port > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", req.Port)
+	}
+
+	listeners, err := ports.List("/proc", int(req.Port))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list ports: %v", err)
+	}
+	if containers, err := a.portContainers(ctx); err != nil {
+		fmt.Printf("Warning: naming port owners: %v\n", err)
+	} else {
+		listeners = ports.Attribute(listeners, containers, int(req.Port))
+	}
+
+	resp := &agentv1.ListListeningPortsResponse{}
+	for _, l := range listeners {
+		resp.Ports = append(resp.Ports, &agentv1.ListeningPort{
+			Proto:       l.Proto,
+			Address:     l.Address,
+			Port:        int32(l.Port),
+			Pid:         int32(l.PID),
+			Process:     l.Process,
+			ContainerId: l.ContainerID,
+			Container:   l.Container,
+			Connections: int32(l.Connections),
+		})
+	}
+	return resp, nil
+}
+
+// portContainers lists the running containers and the ports they publish
+func (a *Agent) portContainers(ctx context.Context) ([]ports.Container, error) {
+	result, err := a.docker.ContainerList(ctx, client.ContainerListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]ports.Container, 0, len(result.Items))
+	for _, c := range result.Items {
+		container := ports.Container{ID: c.ID[:12]}
+		if len(c.Names) > 0 {
+			container.Name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		for _, p := range c.Ports {
+			if p.PublicPort == 0 {
+				continue
+			}
+			published := ports.Published{Proto: p.Type, Port: int(p.PublicPort)}
+			if p.IP.IsValid() {
+				published.Address = p.IP.String()
+			}
+			container.Published = append(container.Published, published)
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
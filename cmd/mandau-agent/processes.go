@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/ports"
+	"github.com/bhangun/mandau/pkg/agent/procs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cpuSample is how long CPU use is measured over when listing processes
+const cpuSample = time.Second
+
+// defaultProtected are the processes signals are refused to when
+// security.protected_processes is unset: killing them would cut the host
+// off from Mandau or from its operators
+var defaultProtected = []string{"mandau-agent", "sshd", "dockerd", "containerd", "systemd", "init"}
+
+// protectedProcesses reads security.protected_processes
+func protectedProcesses(names []string) []string {
+	if names == nil {
+		return defaultProtected
+	}
+	return names
+}
+
+// ListProcesses lists the processes of the host with their CPU and memory
+// use, optionally only those matching a name or listening on a port
+func (a *Agent) ListProcesses(ctx context.Context, req *agentv1.ListProcessesRequest) (*agentv1.ListProcessesResponse, error) {
+	if req.Port < 0 || req.Port > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", req.Port)
+	}
+	if req.Limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit %d", req.Limit)
+	}
+
+	var listening map[int]bool
+	if req.Port != 0 {
+		listeners, err := ports.List("/proc", int(req.Port))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list ports: %v", err)
+		}
+		listening = make(map[int]bool, len(listeners))
+		for _, l := range listeners {
+			listening[l.PID] = true
+		}
+	}
+
+	all, err := procs.List("/proc", cpuSample)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list processes: %v", err)
+	}
+	var processes []procs.Process
+	for _, p := range all {
+		if listening != nil && !listening[p.PID] {
+			continue
+		}
+		if req.Name != "" && !strings.Contains(p.Name, req.Name) && !strings.Contains(p.Command, req.Name) {
+			continue
+		}
+		processes = append(processes, p)
+	}
+	if err := procs.Sort(processes, req.Sort); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Limit > 0 && len(processes) > int(req.Limit) {
+		processes = processes[:req.Limit]
+	}
+
+	// Without Docker the containers stay unnamed rather than failing the call
+	names := make(map[string]string)
+	if containers, err := a.portContainers(ctx); err != nil {
+		fmt.Printf("Warning: naming process containers: %v\n", err)
+	} else {
+		for _, c := range containers {
+			names[c.ID] = c.Name
+		}
+	}
+
+	resp := &agentv1.ListProcessesResponse{}
+	for _, p := range processes {
+		resp.Processes = append(resp.Processes, &agentv1.HostProcess{
+			Pid:           int32(p.PID),
+			Ppid:          int32(p.PPID),
+			User:          p.User,
+			Name:          p.Name,
+			Command:       p.Command,
+			State:         p.State,
+			CpuPercent:    p.CPU,
+			MemoryBytes:   p.Memory,
+			MemoryPercent: p.MemoryShare,
+			ContainerId:   p.ContainerID,
+			Container:     names[p.ContainerID],
+		})
+	}
+	return resp, nil
+}
+
+// SignalProcess sends a signal to a process. Init, kernel threads, the
+// agent itself and the protected processes are refused, whatever the
+// caller's permissions.
+func (a *Agent) SignalProcess(ctx context.Context, req *agentv1.SignalProcessRequest) (*agentv1.SignalProcessResponse, error) {
+	sig, err := procs.ParseSignal(req.Signal)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Pid <= 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "refusing to signal pid %d", req.Pid)
+	}
+	if int(req.Pid) == os.Getpid() {
+		return nil, status.Error(codes.PermissionDenied, "refusing to signal the agent itself")
+	}
+
+	p, err := procs.Get("/proc", int(req.Pid))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, status.Errorf(codes.NotFound, "no process %d", req.Pid)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	if p.KernelThread() {
+		return nil, status.Errorf(codes.PermissionDenied, "refusing to signal kernel thread %s", p.Name)
+	}
+	for _, name := range a.protected {
+		if p.Name == name {
+			return nil, status.Errorf(codes.PermissionDenied,
+				"%s (%d) is protected by security.protected_processes", p.Name, req.Pid)
+		}
+	}
+
+	if err := syscall.Kill(int(req.Pid), sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil, status.Errorf(codes.NotFound, "no process %d", req.Pid)
+		}
+		return nil, status.Errorf(codes.Internal, "signal %d: %v", req.Pid, err)
+	}
+	return &agentv1.SignalProcessResponse{
+		Status: fmt.Sprintf("sent %s to %s (%d)", procs.SignalName(sig), p.Name, req.Pid),
+		Name:   p.Name,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// rebootDelay lets the last event reach the caller before systemd
+	// takes the agent down
+	rebootDelay = 2 * time.Second
+	// bootIDFile changes on every boot, telling a reboot from a restart of
+	// the agent alone
+	bootIDFile = "/proc/sys/kernel/random/boot_id"
+	// rebootingMessage is the last event before the host goes down
+	rebootingMessage = "Rebooting host"
+)
+
+// RebootHost reboots the host through systemd as one host.reboot
+// operation. With drain, stack changes are refused and the running stacks
+// stopped first; resumeReboot starts them again once the agent is back.
+func (a *Agent) RebootHost(req *agentv1.RebootHostRequest, stream agentv1.HostPowerService_RebootHostServer) error {
+	a.rebootMu.Lock()
+	running := a.opMgr.ListOperations(func(op *operation.Operation) bool {
+		return op.Type == operation.OperationTypeHostReboot &&
+			(op.State == operation.OperationStatePending || op.State == operation.OperationStateRunning)
+	})
+	if len(running) > 0 {
+		a.rebootMu.Unlock()
+		return status.Errorf(codes.FailedPrecondition, "a reboot is already in progress: operation %s", running[0].ID)
+	}
+	opID := a.opMgr.CreateOperation(stream.Context(), operation.OperationTypeHostReboot, map[string]string{
+		"drain":  fmt.Sprint(req.Drain),
+		"reason": req.Reason,
+	})
+	a.rebootMu.Unlock()
+
+	events := a.opMgr.Subscribe(opID)
+	defer a.opMgr.Unsubscribe(opID, events)
+	go a.executeReboot(opID, req)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			errorMsg := ""
+			if event.Error != nil {
+				errorMsg = event.Error.Error()
+			}
+			if err := stream.Send(&agentv1.OperationEvent{
+				OperationId: event.OperationID,
+				State:       convertOperationState(event.State),
+				Timestamp:   timestamppb.Now(),
+				Message:     event.Message,
+				Progress:    int32(event.Progress),
+				Error:       errorMsg,
+			}); err != nil {
+				return err
+			}
+			if event.Message == rebootingMessage ||
+				event.State == operation.OperationStateCompleted || event.State == operation.OperationStateFailed {
+				return nil
+			}
+		}
+	}
+}
+
+func (a *Agent) executeReboot(opID string, req *agentv1.RebootHostRequest) {
+	ctx := context.Background()
+	a.opMgr.SetState(opID, operation.OperationStateRunning)
+	if bootID, err := os.ReadFile(bootIDFile); err == nil {
+		a.opMgr.SetMetadata(opID, "boot_id", strings.TrimSpace(string(bootID)))
+	}
+
+	var stopped []string
+	drained := req.Drain && a.instructions.drainError() == nil
+	if req.Drain {
+		a.instructions.setDrain(true, "host reboot")
+		stacks, err := a.stackMgr.ListStacks(ctx)
+		if err != nil {
+			a.abortReboot(opID, nil, drained, fmt.Errorf("list stacks: %w", err))
+			return
+		}
+		for _, s := range stacks {
+			if s.State != stack.StateRunning && s.State != stack.StatePartial {
+				continue
+			}
+			a.opMgr.EmitEvent(opID, "Stopping stack "+s.Name)
+			if err := a.stackMgr.StopStack(ctx, s.Name); err != nil {
+				a.abortReboot(opID, stopped, drained, fmt.Errorf("stop stack %s: %w", s.Name, err))
+				return
+			}
+			stopped = append(stopped, s.Name)
+			a.opMgr.SetMetadata(opID, "stacks", strings.Join(stopped, ","))
+		}
+	}
+
+	fmt.Printf("Rebooting host (operation %s): %s\n", opID, req.Reason)
+	a.opMgr.SetMetadata(opID, "phase", "rebooting")
+	a.opMgr.EmitEvent(opID, rebootingMessage)
+	time.Sleep(rebootDelay)
+
+	if out, err := exec.Command("systemctl", "reboot").CombinedOutput(); err != nil {
+		a.abortReboot(opID, stopped, drained, fmt.Errorf("systemctl reboot: %v: %s", err, strings.TrimSpace(string(out))))
+	}
+}
+
+// abortReboot fails a reboot that did not happen, starting the stacks
+// stopped for it
+func (a *Agent) abortReboot(opID string, stopped []string, drained bool, cause error) {
+	if err := a.startStacks(opID, stopped); err != nil {
+		cause = errors.Join(cause, err)
+	}
+	if drained {
+		a.instructions.setDrain(false, "")
+	}
+	a.opMgr.SetError(opID, cause)
+}
+
+// resumeReboot finishes the reboots the restart interrupted, now that the
+// agent has registered with the core again. The stacks stopped for them
+// are started also when the agent went down before rebooting the host.
+func (a *Agent) resumeReboot(interrupted []*operation.Operation) {
+	bootID, _ := os.ReadFile(bootIDFile)
+	for _, op := range interrupted {
+		if op.Type != operation.OperationTypeHostReboot {
+			continue
+		}
+		if err := a.opMgr.Reopen(op.ID); err != nil {
+			fmt.Printf("Resume reboot %s: %v\n", op.ID, err)
+			continue
+		}
+		a.opMgr.EmitEvent(op.ID, "Agent registered with the core after the restart")
+
+		var stacks []string
+		if names := op.Metadata["stacks"]; names != "" {
+			stacks = strings.Split(names, ",")
+		}
+		err := a.startStacks(op.ID, stacks)
+		err = errors.Join(err, a.verifySysctls(requestid.With(context.Background(), op.RequestID), op.ID))
+		switch {
+		case op.Metadata["phase"] != "rebooting":
+			err = errors.Join(errors.New("the agent stopped before rebooting the host"), err)
+		case op.Metadata["boot_id"] != "" && op.Metadata["boot_id"] == strings.TrimSpace(string(bootID)):
+			err = errors.Join(errors.New("the agent restarted but the host did not reboot"), err)
+		}
+		if err != nil {
+			a.opMgr.SetError(op.ID, err)
+			continue
+		}
+		a.opMgr.EmitEvent(op.ID, "Reboot complete")
+		a.opMgr.SetCompleted(op.ID)
+	}
+}
+
+// verifySysctls checks that the kernel parameters Mandau persisted were
+// set again at boot, where the host environment plugin is enabled. ctx
+// carries the request ID of the reboot.
+func (a *Agent) verifySysctls(ctx context.Context, opID string) error {
+	env := a.services.Environment()
+	if env == nil {
+		return nil
+	}
+	a.opMgr.EmitEvent(opID, "Verifying persisted sysctls")
+	checks, err := env.VerifySysctls(ctx)
+	if err != nil {
+		return fmt.Errorf("verify sysctls: %w", err)
+	}
+	var failed []string
+	for _, c := range checks {
+		if !c.OK {
+			failed = append(failed, fmt.Sprintf("%s is %q, persisted %q in %s", c.Key, c.Runtime, c.Persisted, c.File))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("sysctls not set at boot: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// startStacks starts the stacks stopped for a reboot, going on past
+// failures
+func (a *Agent) startStacks(opID string, names []string) error {
+	var failed []string
+	for _, name := range names {
+		a.opMgr.EmitEvent(opID, "Starting stack "+name)
+		if err := a.stackMgr.StartStack(context.Background(), name); err != nil {
+			fmt.Printf("Reboot %s: start stack %s: %v\n", opID, name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("stacks not started: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
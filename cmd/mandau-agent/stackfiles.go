@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func stackFilesFromProto(files []*agentv1.StackFile) []stack.File {
+	out := make([]stack.File, 0, len(files))
+	for _, f := range files {
+		out = append(out, stack.File{Path: f.Path, SHA256: f.Sha256, Size: f.Size, Mode: os.FileMode(f.Mode)})
+	}
+	return out
+}
+
+func contentsFromProto(files []*agentv1.StackContent) []stack.Content {
+	out := make([]stack.Content, 0, len(files))
+	for _, f := range files {
+		out = append(out, stack.Content{Path: f.Path, Data: f.Content, Mode: os.FileMode(f.Mode)})
+	}
+	return out
+}
+
+func contentsToProto(files []stack.Content) []*agentv1.StackContent {
+	out := make([]*agentv1.StackContent, 0, len(files))
+	for _, f := range files {
+		out = append(out, &agentv1.StackContent{Path: f.Path, Content: f.Data, Mode: uint32(f.Mode)})
+	}
+	return out
+}
+
+// stackFilesError maps stack file errors to statuses
+func stackFilesError(err error) error {
+	switch {
+	case errors.Is(err, stack.ErrInvalidFile), errors.Is(err, stack.ErrInvalidStackName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, stack.ErrNamespaceMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// DiffStackFiles hashes the listed files the stack holds or has staged and
+// returns those that differ
+func (a *Agent) DiffStackFiles(ctx context.Context, req *agentv1.DiffStackFilesRequest) (*agentv1.DiffStackFilesResponse, error) {
+	diff, err := a.stackMgr.DiffFiles(req.StackName, req.Namespace, stackFilesFromProto(req.Files))
+	if err != nil {
+		return nil, stackFilesError(err)
+	}
+	return &agentv1.DiffStackFilesResponse{
+		Changed:   diff.Changed,
+		Removed:   diff.Removed,
+		Unchanged: int32(diff.Unchanged),
+	}, nil
+}
+
+// UploadStackFiles stages files for the stack's next apply as they arrive,
+// paced to transfers.max_rate
+func (a *Agent) UploadStackFiles(stream agentv1.StackService_UploadStackFilesServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+
+	r := &stackFileReader{ctx: stream.Context(), stream: stream, limit: a.transfers.limit}
+	if first.Path != "" {
+		r.next = first
+	}
+	resp := &agentv1.UploadStackFilesResponse{}
+	for r.nextFile() {
+		n, err := a.stackMgr.StageFile(first.StackName, first.Namespace, r.path, r)
+		if r.err != nil {
+			return r.err
+		}
+		if err != nil {
+			return stackFilesError(err)
+		}
+		resp.Files++
+		resp.Bytes += n
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// stackFileReader reads the file an UploadStackFiles stream is at, up to
+// the chunk starting the next one
+type stackFileReader struct {
+	ctx    context.Context
+	stream agentv1.StackService_UploadStackFilesServer
+	limit  *transfer.Limiter
+	next   *agentv1.StackFileChunk // First chunk of the next file
+	path   string
+	data   []byte
+	done   bool  // The stream ended
+	err    error // The stream failed
+}
+
+// nextFile moves to the next file, reporting whether there is one
+func (r *stackFileReader) nextFile() bool {
+	if r.next == nil {
+		return false
+	}
+	r.path, r.data, r.next = r.next.Path, r.next.Data, nil
+	return true
+}
+
+func (r *stackFileReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.next != nil || r.done {
+			return 0, io.EOF
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		chunk, err := r.stream.Recv()
+		if err == io.EOF {
+			r.done = true
+			continue
+		}
+		if err == nil {
+			err = r.limit.Wait(r.ctx, len(chunk.Data))
+		}
+		if err != nil {
+			r.err = err
+			continue
+		}
+		if chunk.Path != "" {
+			r.next = chunk
+			continue
+		}
+		r.data = chunk.Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/agent/stackgit"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/metadata"
+)
+
+// stackStorage opens the backend stacks.storage names for the stack root;
+// plain files need none
+func stackStorage(backend, root string) (stack.Storage, error) {
+	switch backend {
+	case "", "files":
+		return nil, nil
+	case "git":
+		repo, err := stackgit.Open(root)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Stack changes are committed to the git repository in %s\n", root)
+		return repo, nil
+	}
+	return nil, fmt.Errorf("stacks.storage: unknown backend %q, want files or git", backend)
+}
+
+// withAuthor names who stack changes made for a call are recorded as made
+// for: the requester the core forwards, or else the caller
+func withAuthor(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if by := md.Get(transport.RequesterHeader); len(by) > 0 && by[0] != "" {
+			return stack.WithAuthor(ctx, by[0])
+		}
+	}
+	if identity := plugin.IdentityFromContext(ctx); identity != nil {
+		return stack.WithAuthor(ctx, identity.UserID)
+	}
+	return ctx
+}
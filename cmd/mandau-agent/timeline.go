@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/agent/timeline"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/moby/moby/api/types/events"
+	"github.com/moby/moby/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// certScanInterval is how often the ACME certificate directory is read
+	// for renewals
+	certScanInterval = 10 * time.Minute
+	// defaultEventLimit is how many events GetStackEvents returns when the
+	// request sets no limit
+	defaultEventLimit = 100
+)
+
+// openTimeline opens the stack timelines under stacks.timeline_dir
+func openTimeline(cfg config.StacksConfig, stackRoot string) (*timeline.Timeline, error) {
+	dir := cfg.TimelineDir
+	if dir == "" {
+		dir = filepath.Clean(stackRoot) + ".timeline"
+	}
+	tl, err := timeline.Open(dir, cfg.TimelineEvents)
+	if err != nil {
+		return nil, fmt.Errorf("stack timeline: %w", err)
+	}
+	return tl, nil
+}
+
+// containerState is what the events of one container have shown so far
+type containerState struct {
+	stopping  bool // Killed or stopped on purpose, so its exit is no crash
+	crashed   bool
+	unhealthy bool
+}
+
+// watchStackEvents records crashes, restarts and health changes of stack
+// containers in their timelines until the agent stops, resubscribing to
+// Docker events from the last one seen when the stream breaks
+func (a *Agent) watchStackEvents() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stop
+		cancel()
+	}()
+
+	states := make(map[string]*containerState)
+	var since string
+	for ctx.Err() == nil {
+		result := a.docker.Events(ctx, client.EventsListOptions{
+			Since:   since,
+			Filters: client.Filters{}.Add("type", string(events.ContainerEventType)).Add("label", "com.docker.compose.project"),
+		})
+	stream:
+		for {
+			select {
+			case msg := <-result.Messages:
+				since = strconv.FormatInt(msg.Time, 10)
+				a.recordContainerEvent(states, msg)
+			case err := <-result.Err:
+				if ctx.Err() == nil && err != nil {
+					fmt.Printf("Warning: stack timeline: docker events: %v\n", err)
+				}
+				break stream
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// recordContainerEvent adds what a container event means for its stack to
+// the stack's timeline. Stops, kills and the exits they cause are expected
+// during applies and removals and are left out; containers created outside
+// the agent's operations are warned about.
+func (a *Agent) recordContainerEvent(states map[string]*containerState, msg events.Message) {
+	attrs := msg.Actor.Attributes
+	stackName := attrs["com.docker.compose.project"]
+	if stack.CheckName(stackName) != nil {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(a.config.StackRoot, stackName)); err != nil {
+		// Not a stack of this agent
+		return
+	}
+
+	state := states[msg.Actor.ID]
+	if state == nil {
+		state = &containerState{}
+		states[msg.Actor.ID] = state
+	}
+
+	name := attrs["name"]
+	e := timeline.Event{Service: attrs["com.docker.compose.service"]}
+	if msg.TimeNano != 0 {
+		e.Time = time.Unix(0, msg.TimeNano)
+	}
+	switch msg.Action {
+	case events.ActionKill, events.ActionStop:
+		state.stopping = true
+		return
+	case events.ActionDie:
+		if state.stopping {
+			return
+		}
+		code := attrs["exitCode"]
+		if code == "0" {
+			return
+		}
+		state.crashed = true
+		e.Kind, e.Summary, e.Failed = timeline.KindCrash, fmt.Sprintf("%s exited with code %s", name, code), true
+	case events.ActionOOM:
+		e.Kind, e.Summary, e.Failed = timeline.KindOOM, name+" ran out of memory", true
+	case events.ActionRestart:
+		state.stopping, state.crashed = false, false
+		e.Kind, e.Summary = timeline.KindRestart, name+" restarted"
+	case events.ActionStart:
+		crashed := state.crashed
+		state.stopping, state.crashed = false, false
+		if !crashed {
+			return
+		}
+		e.Kind, e.Summary = timeline.KindRestart, name+" restarted by its restart policy"
+	case events.ActionHealthStatusUnhealthy:
+		if state.unhealthy {
+			return
+		}
+		state.unhealthy = true
+		e.Kind, e.Summary, e.Failed = timeline.KindUnhealthy, name+" became unhealthy", true
+	case events.ActionHealthStatusHealthy:
+		if !state.unhealthy {
+			return
+		}
+		state.unhealthy = false
+		e.Kind, e.Summary = timeline.KindHealthy, name+" is healthy again"
+	case events.ActionCreate:
+		// The agent's compose runs hold the stack's lock; containers created
+		// without it come from compose run by hand or another tool
+		at := e.Time
+		if at.IsZero() {
+			at = time.Now()
+		}
+		if a.stackMgr.ChangedByAgent(stackName, at) {
+			return
+		}
+		fmt.Printf("Warning: container %s of stack %s was created outside the agent; its stored definition may no longer match what runs\n", name, stackName)
+		e.Kind, e.Summary = timeline.KindExternal, name+" created outside the agent"
+	case events.ActionDestroy:
+		delete(states, msg.Actor.ID)
+		return
+	default:
+		return
+	}
+
+	if err := a.timeline.Record(stackName, e); err != nil {
+		fmt.Printf("Warning: stack timeline: %v\n", err)
+	}
+}
+
+// watchCertificates records renewals of the ACME plugin's certificates in
+// the timelines of the stacks whose domains label they cover
+func (a *Agent) watchCertificates(dir string) {
+	known, err := timeline.ScanCertificates(dir)
+	if err != nil {
+		fmt.Printf("Warning: stack timeline: read certificates: %v\n", err)
+	}
+
+	ticker := time.NewTicker(certScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-a.stop:
+			return
+		}
+
+		certs, err := timeline.ScanCertificates(dir)
+		if err != nil {
+			fmt.Printf("Warning: stack timeline: read certificates: %v\n", err)
+			continue
+		}
+		if renewed := timeline.Renewed(known, certs); len(renewed) > 0 {
+			a.recordRenewals(renewed)
+		}
+		known = certs
+	}
+}
+
+func (a *Agent) recordRenewals(renewed []timeline.Certificate) {
+	stacks, err := a.stackMgr.ListStacks(context.Background())
+	if err != nil {
+		fmt.Printf("Warning: stack timeline: %v\n", err)
+		return
+	}
+	for _, s := range stacks {
+		domains := timeline.StackDomains(s.Labels)
+		for _, cert := range renewed {
+			var covered []string
+			for _, d := range domains {
+				if cert.Covers(d) {
+					covered = append(covered, d)
+				}
+			}
+			if len(covered) == 0 {
+				continue
+			}
+			err := a.timeline.Record(s.Name, timeline.Event{
+				Kind:    timeline.KindCertificate,
+				Summary: fmt.Sprintf("Certificate %s renewed, valid until %s", cert.Name, cert.NotAfter.UTC().Format(time.DateOnly)),
+				Details: covered,
+			})
+			if err != nil {
+				fmt.Printf("Warning: stack timeline: %v\n", err)
+			}
+		}
+	}
+}
+
+// GetStackEvents returns a stack's timeline. Removed stacks are checked
+// against the namespace their last apply or removal recorded.
+func (a *Agent) GetStackEvents(ctx context.Context, req *agentv1.GetStackEventsRequest) (*agentv1.GetStackEventsResponse, error) {
+	filter := timeline.Filter{Kinds: req.Kinds, Limit: int(req.Limit)}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultEventLimit
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+
+	found, err := a.timeline.List(req.StackName, filter)
+	if errors.Is(err, timeline.ErrInvalidStack) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read stack timeline: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(a.config.StackRoot, req.StackName)); err == nil {
+		if err := a.requireStackNamespace(req.StackName, req.Namespace); err != nil {
+			return nil, err
+		}
+	} else {
+		ns, err := a.timeline.Namespace(req.StackName)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "read stack timeline: %v", err)
+		}
+		if stack.NormalizeNamespace(ns) != stack.NormalizeNamespace(req.Namespace) {
+			return nil, status.Errorf(codes.NotFound, "stack not found: %s", req.StackName)
+		}
+	}
+
+	resp := &agentv1.GetStackEventsResponse{Events: make([]*agentv1.StackEvent, len(found))}
+	for i, e := range found {
+		resp.Events[i] = &agentv1.StackEvent{
+			Time:        timestamppb.New(e.Time),
+			Kind:        e.Kind,
+			Service:     e.Service,
+			Summary:     e.Summary,
+			Details:     e.Details,
+			Failed:      e.Failed,
+			OperationId: e.Operation,
+		}
+	}
+	return resp, nil
+}
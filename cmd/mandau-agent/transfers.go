@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/stack"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultTransferExpiry is how long uploads are kept untouched unless
+	// transfers.expire_after says otherwise
+	defaultTransferExpiry = 24 * time.Hour
+	// defaultBackupDir is where database backups are written by default
+	defaultBackupDir = "/var/backups/mandau"
+)
+
+// transfers is what chunked transfers need: partial uploads, the bandwidth
+// every transfer shares and where backups may be read
+type transfers struct {
+	store      *transfer.Store
+	limit      *transfer.Limiter
+	expiry     time.Duration
+	backupDirs []string
+}
+
+// openTransfers opens the upload store of transfers.dir
+func openTransfers(cfg config.TransfersConfig, stackRoot string) (*transfers, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Clean(stackRoot) + ".transfers"
+	}
+	store, err := transfer.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("transfers: %w", err)
+	}
+	rate, err := transfer.ParseRate(cfg.MaxRate)
+	if err != nil {
+		return nil, fmt.Errorf("transfers.max_rate: %w", err)
+	}
+
+	t := &transfers{
+		store:      store,
+		limit:      transfer.NewLimiter(rate),
+		expiry:     defaultTransferExpiry,
+		backupDirs: cfg.BackupDirs,
+	}
+	if cfg.ExpireAfter != "" {
+		d, err := time.ParseDuration(cfg.ExpireAfter)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("transfers.expire_after: invalid duration %q", cfg.ExpireAfter)
+		}
+		t.expiry = d
+	}
+	if len(t.backupDirs) == 0 {
+		t.backupDirs = []string{defaultBackupDir}
+	}
+	return t, nil
+}
+
+// expireTransfers removes abandoned uploads until the agent stops
+func (a *Agent) expireTransfers() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if n, err := a.transfers.store.Expire(a.transfers.expiry); err != nil {
+			fmt.Printf("Warning: expire uploads: %v\n", err)
+		} else if n > 0 {
+			fmt.Printf("Removed %d upload(s) untouched for %s\n", n, a.transfers.expiry)
+		}
+
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// transferTarget converts a target for the upload store
+func transferTarget(t *agentv1.TransferTarget) transfer.Target {
+	return transfer.Target{
+		Kind:      strings.ToLower(strings.TrimPrefix(t.GetKind().String(), "TRANSFER_KIND_")),
+		Stack:     t.GetStackName(),
+		Namespace: stack.NormalizeNamespace(t.GetNamespace()),
+		Path:      t.GetPath(),
+	}
+}
+
+func transferStatus(u *transfer.Upload) *agentv1.TransferStatus {
+	kind := agentv1.TransferKind_value["TRANSFER_KIND_"+strings.ToUpper(u.Target.Kind)]
+	return &agentv1.TransferStatus{
+		TransferId: u.ID,
+		Target: &agentv1.TransferTarget{
+			Kind:      agentv1.TransferKind(kind),
+			StackName: u.Target.Stack,
+			Namespace: u.Target.Namespace,
+			Path:      u.Target.Path,
+		},
+		Size:      u.Size,
+		Received:  u.Received,
+		Delivered: u.Delivered,
+		Images:    u.Images,
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+}
+
+// transferError maps upload store errors to statuses
+func transferError(err error) error {
+	switch {
+	case errors.Is(err, transfer.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, transfer.ErrBusy):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, transfer.ErrOffset), errors.Is(err, transfer.ErrMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, transfer.ErrDigest):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, transfer.ErrTooLarge), errors.Is(err, stack.ErrInvalidStackName), errors.Is(err, stack.ErrInvalidFile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, stack.ErrNamespaceMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// Upload writes a payload to the upload store as it arrives, paced to
+// transfers.max_rate, and delivers it once complete
+func (a *Agent) Upload(stream agentv1.TransferService_UploadServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkTarget(first.Target); err != nil {
+		return err
+	}
+
+	w, err := a.transfers.store.Begin(transfer.Upload{
+		ID:     first.TransferId,
+		Target: transferTarget(first.Target),
+		Size:   first.Size,
+		SHA256: first.Sha256,
+	}, first.Offset)
+	if err != nil {
+		if errors.Is(err, transfer.ErrBusy) || errors.Is(err, transfer.ErrOffset) || errors.Is(err, transfer.ErrMismatch) {
+			return transferError(err)
+		}
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer w.Release()
+
+	if err := a.receive(ctx, stream, first, w); err != nil {
+		// What arrived is kept for the upload to resume from
+		w.Close()
+		return err
+	}
+	up, err := a.complete(ctx, w)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(transferStatus(up))
+}
+
+// checkTarget rejects targets the agent cannot deliver to
+func checkTarget(t *agentv1.TransferTarget) error {
+	switch t.GetKind() {
+	case agentv1.TransferKind_TRANSFER_KIND_FILE:
+		if t.Path == "" {
+			return status.Error(codes.InvalidArgument, "file transfers need a path")
+		}
+		if err := stack.CheckName(t.StackName); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	case agentv1.TransferKind_TRANSFER_KIND_IMAGE_BUNDLE:
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown transfer kind %s", t.GetKind())
+	}
+	return nil
+}
+
+// complete records what w received and, once all of the payload is there,
+// verifies and delivers it
+func (a *Agent) complete(ctx context.Context, w *transfer.Writer) (*transfer.Upload, error) {
+	up, err := w.Close()
+	if err != nil {
+		return nil, transferError(err)
+	}
+	if !up.Complete() || up.Delivered {
+		return up, nil
+	}
+
+	path, err := w.Verify()
+	if err != nil {
+		return nil, transferError(err)
+	}
+	images, err := a.deliver(ctx, up.Target, path)
+	if err != nil {
+		// The data stays, so a resumed transfer retries the delivery
+		return nil, err
+	}
+	if err := w.Delivered(images); err != nil {
+		return nil, transferError(err)
+	}
+	return up, nil
+}
+
+// Fetch reads an artifact from the core's artifact store by digest, from
+// what an earlier fetch or upload of it left, paced to transfers.max_rate,
+// and delivers it
+func (a *Agent) Fetch(ctx context.Context, req *agentv1.FetchRequest) (*agentv1.TransferStatus, error) {
+	if err := checkTarget(req.Target); err != nil {
+		return nil, err
+	}
+	if a.serverConn == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the agent is not connected to a core")
+	}
+
+	target := transferTarget(req.Target)
+	w, err := a.transfers.store.Resume(transfer.Upload{
+		ID:     transfer.ID(target.Kind, target.Stack, target.Namespace, target.Path, req.Digest),
+		Target: target,
+		Size:   req.Size,
+		SHA256: req.Digest,
+	})
+	if err != nil {
+		if errors.Is(err, transfer.ErrBusy) || errors.Is(err, transfer.ErrMismatch) {
+			return nil, transferError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer w.Release()
+
+	if err := a.fetchArtifact(ctx, req.Digest, w); err != nil {
+		// What arrived is kept for the fetch to resume from
+		w.Close()
+		return nil, err
+	}
+	up, err := a.complete(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	return transferStatus(up), nil
+}
+
+// fetchArtifact writes the rest of an artifact to w, a chunk at a time
+func (a *Agent) fetchArtifact(ctx context.Context, digest string, w *transfer.Writer) error {
+	artifacts := agentv1.NewArtifactServiceClient(a.serverConn)
+	for up := w.Upload(); !up.Delivered && !up.Complete(); {
+		chunk, err := artifacts.FetchArtifact(ctx, &agentv1.FetchArtifactRequest{
+			AgentId: a.config.AgentID,
+			Digest:  digest,
+			Offset:  up.Received,
+		})
+		if err != nil {
+			return err
+		}
+		if chunk.Size != up.Size {
+			return status.Errorf(codes.DataLoss, "the core holds %d bytes of artifact %s, expected %d", chunk.Size, digest, up.Size)
+		}
+		if len(chunk.Data) == 0 {
+			return status.Errorf(codes.DataLoss, "the core sent no data of artifact %s at %d", digest, up.Received)
+		}
+		if err := a.transfers.limit.Wait(ctx, len(chunk.Data)); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return transferError(err)
+		}
+	}
+	return nil
+}
+
+// receive writes the chunks of first and the requests after it
+func (a *Agent) receive(ctx context.Context, stream agentv1.TransferService_UploadServer, first *agentv1.UploadRequest, w *transfer.Writer) error {
+	for req := first; ; {
+		if len(req.Chunk) > 0 {
+			if err := a.transfers.limit.Wait(ctx, len(req.Chunk)); err != nil {
+				return status.FromContextError(err).Err()
+			}
+			if _, err := w.Write(req.Chunk); err != nil {
+				return transferError(err)
+			}
+		}
+
+		var err error
+		req, err = stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// deliver hands a complete payload to its target and returns the images
+// it loaded
+func (a *Agent) deliver(ctx context.Context, target transfer.Target, path string) ([]string, error) {
+	if target.Kind == "file" {
+		if err := a.stackMgr.PlaceFile(target.Stack, target.Namespace, target.Path, path); err != nil {
+			if errors.Is(err, stack.ErrNamespaceMismatch) || errors.Is(err, stack.ErrInvalidStackName) || errors.Is(err, stack.ErrInvalidFile) {
+				return nil, transferError(err)
+			}
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, transferError(err)
+	}
+	defer f.Close()
+	images, err := a.stackMgr.LoadImages(ctx, f)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(images) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "the bundle holds no images")
+	}
+	return images, nil
+}
+
+// GetTransfer returns how much of an upload the agent holds. Uploads to
+// another target are not found.
+func (a *Agent) GetTransfer(ctx context.Context, req *agentv1.GetTransferRequest) (*agentv1.TransferStatus, error) {
+	up, err := a.transfers.store.Get(req.TransferId)
+	if err != nil {
+		if errors.Is(err, transfer.ErrNotFound) {
+			return nil, transferError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if up.Target != transferTarget(req.Target) {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", transfer.ErrNotFound, req.TransferId)
+	}
+	return transferStatus(up), nil
+}
+
+// ListBackups lists the files under the backup directories
+func (a *Agent) ListBackups(ctx context.Context, req *agentv1.ListBackupsRequest) (*agentv1.ListBackupsResponse, error) {
+	resp := &agentv1.ListBackupsResponse{}
+	for _, dir := range a.transfers.backupDirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			resp.Backups = append(resp.Backups, &agentv1.FileInfo{
+				Name:     d.Name(),
+				Path:     path,
+				Size:     info.Size(),
+				Modified: timestamppb.New(info.ModTime()),
+				Mode:     uint32(info.Mode().Perm()),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list backups: %v", err)
+		}
+	}
+	sort.Slice(resp.Backups, func(i, j int) bool { return resp.Backups[i].Path < resp.Backups[j].Path })
+	return resp, nil
+}
+
+// DownloadBackup streams a backup from offset, paced to transfers.max_rate
+func (a *Agent) DownloadBackup(req *agentv1.DownloadBackupRequest, stream agentv1.TransferService_DownloadBackupServer) error {
+	path, err := a.backupPath(req.Path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return status.Errorf(codes.NotFound, "backup not found: %s", req.Path)
+		}
+		return status.Errorf(codes.Internal, "open backup: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return status.Errorf(codes.Internal, "open backup: %v", err)
+	}
+	if req.Offset < 0 || req.Offset > info.Size() {
+		return status.Errorf(codes.OutOfRange, "offset %d is outside %s, which has %d bytes", req.Offset, req.Path, info.Size())
+	}
+	if _, err := f.Seek(req.Offset, io.SeekStart); err != nil {
+		return status.Errorf(codes.Internal, "seek backup: %v", err)
+	}
+
+	buf := make([]byte, transfer.ChunkSize)
+	offset := req.Offset
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := a.transfers.limit.Wait(stream.Context(), n); err != nil {
+				return status.FromContextError(err).Err()
+			}
+			if err := stream.Send(&agentv1.DownloadChunk{Offset: offset, Data: buf[:n], Size: info.Size()}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "read backup: %v", err)
+		}
+	}
+}
+
+// backupPath resolves path, refusing anything outside the backup
+// directories, symbolic links included
+func (a *Agent) backupPath(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", status.Errorf(codes.NotFound, "backup not found: %s", path)
+		}
+		return "", status.Errorf(codes.InvalidArgument, "backup %s: %v", path, err)
+	}
+	for _, dir := range a.transfers.backupDirs {
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, resolved); err == nil && filepath.IsLocal(rel) {
+			return resolved, nil
+		}
+	}
+	return "", status.Errorf(codes.PermissionDenied, "%s is not in a backup directory (transfers.backup_dirs)", path)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	adminCmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Look into the core's runtime when debugging it",
+		Long:  "Look into the core's runtime when debugging it. Needs admin on core:admin, which only the admin role has by default.",
+	}
+
+	profileCmd := &cobra.Command{
+		Use:   "profile <cpu|goroutine|heap|allocs|threadcreate|block|mutex>",
+		Short: "Write a pprof profile of the core",
+		Long: "Write a pprof profile of the core, for go tool pprof. A cpu profile samples for --duration " +
+			"first; the others are taken at once.",
+		Args: cobra.ExactArgs(1),
+		RunE: adminProfile,
+	}
+	profileCmd.Flags().StringP("output", "o", "", "File to write the profile to (default: <name>.pprof, or stdout with --debug)")
+	profileCmd.Flags().Duration("duration", 30*time.Second, "How long to sample a cpu profile, up to 5m")
+	profileCmd.Flags().Int("debug", 0, "Write a profile other than cpu as text: 1 for counts, 2 for goroutine stacks")
+
+	connectionsCmd := &cobra.Command{
+		Use:   "connections",
+		Short: "List the core's channel to each agent",
+		Args:  cobra.NoArgs,
+		RunE:  adminConnections,
+	}
+
+	registryCmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Show the core's records of agents and its loaded plugins",
+		Args:  cobra.NoArgs,
+		RunE:  adminRegistry,
+	}
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Print the configuration the core runs with, secrets masked",
+		Args:  cobra.NoArgs,
+		RunE:  adminConfig,
+	}
+
+	adminCmd.AddCommand(profileCmd, connectionsCmd, registryCmd, configCmd)
+	rootCmd.AddCommand(adminCmd)
+}
+
+func (c *CLI) adminProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	output, _ := cmd.Flags().GetString("output")
+	duration, _ := cmd.Flags().GetDuration("duration")
+	debug, _ := cmd.Flags().GetInt("debug")
+
+	req := &v1.GetProfileRequest{Name: name, Debug: int32(debug)}
+	if name == "cpu" {
+		req.Duration = durationpb.New(duration)
+		fmt.Fprintf(os.Stderr, "Sampling the core's CPU for %s...\n", duration)
+	}
+	stream, err := v1.NewAdminServiceClient(c.conn).GetProfile(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output == "" && (debug == 0 || name == "cpu") {
+		output = name + ".pprof"
+	}
+	if output != "" && output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+	if w != os.Stdout {
+		fmt.Fprintf(os.Stderr, "✓ Wrote the %s profile to %s\n", name, output)
+	}
+	return nil
+}
+
+func adminProfile(cmd *cobra.Command, args []string) error {
+	return cli.adminProfile(cmd, args)
+}
+
+func (c *CLI) adminConnections(cmd *cobra.Command, args []string) error {
+	resp, err := v1.NewAdminServiceClient(c.conn).ListConnections(context.Background(), &v1.ListConnectionsRequest{})
+	if err != nil {
+		return err
+	}
+	if len(resp.Connections) == 0 {
+		fmt.Println("No agents connected")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tADDRESS\tSTATUS\tCHANNEL\tCIRCUIT\tLAST SEEN\tCLOCK SKEW\tCERTIFICATE\tEXPIRES")
+	for _, ch := range resp.Connections {
+		address, channel := ch.Address, ch.ChannelState
+		if ch.DialOutOnly {
+			address, channel = "(dial-out)", "-"
+		}
+		skew, expires := "-", "-"
+		if ch.ClockSkew != nil {
+			skew = ch.ClockSkew.AsDuration().String()
+		}
+		if ch.CertificateExpires != nil {
+			expires = ch.CertificateExpires.AsTime().Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ch.AgentId, orDash(address), ch.Status, orDash(channel),
+			orDash(ch.Circuit.GetState()), ch.LastSeen.AsTime().Local().Format(time.RFC3339), skew,
+			orDash(ch.CertificateSubject), expires)
+	}
+	return w.Flush()
+}
+
+func adminConnections(cmd *cobra.Command, args []string) error {
+	return cli.adminConnections(cmd, args)
+}
+
+func (c *CLI) adminRegistry(cmd *cobra.Command, args []string) error {
+	registry, err := v1.NewAdminServiceClient(c.conn).GetRegistry(context.Background(), &v1.GetRegistryRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Agents (%d):\n", len(registry.Agents))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ID\tHOSTNAME\tSTATUS\tLABELS\tOPERATOR LABELS\tREMOVED\tSTACKS\tPLUGINS\tMAINTENANCE")
+	for _, a := range registry.Agents {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%t\n", a.AgentId, a.Hostname, a.Status,
+			orDash(formatLabels(a.Labels)), orDash(formatLabels(a.OperatorLabels)), orDash(strings.Join(a.RemovedLabels, ",")),
+			len(a.Stacks), orDash(strings.Join(a.Plugins, ",")), a.InMaintenance)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nPlugins (%d):\n", len(registry.Plugins))
+	for _, p := range registry.Plugins {
+		fmt.Printf("  %s %s (%s)\n", p.Name, p.Version, strings.Join(p.Capabilities, ", "))
+	}
+	return nil
+}
+
+func adminRegistry(cmd *cobra.Command, args []string) error {
+	return cli.adminRegistry(cmd, args)
+}
+
+func (c *CLI) adminConfig(cmd *cobra.Command, args []string) error {
+	dump, err := v1.NewAdminServiceClient(c.conn).GetConfig(context.Background(), &v1.GetConfigRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Print(dump.Yaml)
+	return nil
+}
+
+func adminConfig(cmd *cobra.Command, args []string) error {
+	return cli.adminConfig(cmd, args)
+}
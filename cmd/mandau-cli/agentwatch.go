@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func newAgentWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Follow agents as they join, change and leave",
+		Long: "List the agents matching the filters, then print a line each time one registers, " +
+			"changes status, labels, groups, maintenance or circuit, or stops matching. Runs until interrupted.",
+		Args: cobra.NoArgs,
+		RunE: cli.watchAgents,
+	}
+	cmd.Flags().StringSlice("label", nil, "Filter by label (key=value, repeatable)")
+	cmd.Flags().String("group", "", "Only watch members of this agent group")
+	return cmd
+}
+
+func (c *CLI) watchAgents(cmd *cobra.Command, args []string) error {
+	selector, err := labelFlag(cmd, "label")
+	if err != nil {
+		return err
+	}
+	group, _ := cmd.Flags().GetString("group")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stream, err := c.coreClient.WatchAgents(ctx, &v1.WatchAgentsRequest{Labels: selector, Group: group})
+	if err != nil {
+		return err
+	}
+	for {
+		delta, err := stream.Recv()
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(agentDeltaLine(delta))
+	}
+}
+
+// agentDeltaLine describes one change to the watched agents
+func agentDeltaLine(d *v1.AgentDelta) string {
+	at := d.Time.AsTime().Local().Format(time.TimeOnly)
+	kind := strings.ToLower(strings.TrimPrefix(d.Kind.String(), "AGENT_DELTA_KIND_"))
+	if d.Kind == v1.AgentDeltaKind_AGENT_DELTA_KIND_SYNCED {
+		return fmt.Sprintf("%s %-8s watching for changes", at, kind)
+	}
+
+	agent := d.Agent
+	status := agent.Status
+	if agent.Maintenance != nil {
+		status += " (maint)"
+	}
+	line := fmt.Sprintf("%s %-8s %-20s %-16s circuit %s", at, kind, agent.Id, status, orDash(agent.Circuit.GetState()))
+	if len(agent.Labels) > 0 {
+		line += " labels " + formatLabels(agent.Labels)
+	}
+	if len(agent.Groups) > 0 {
+		line += " groups " + strings.Join(agent.Groups, ",")
+	}
+	return line
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAgentDeltaLine(t *testing.T) {
+	now := timestamppb.New(time.Now())
+
+	line := agentDeltaLine(&v1.AgentDelta{
+		Kind: v1.AgentDeltaKind_AGENT_DELTA_KIND_UPDATED,
+		Time: now,
+		Agent: &v1.Agent{
+			Id:          "web-1",
+			Status:      "offline",
+			Labels:      map[string]string{"zone": "a", "role": "web"},
+			Groups:      []string{"frontend"},
+			Maintenance: &v1.Maintenance{Reason: "kernel upgrade"},
+		},
+	})
+	for _, want := range []string{"updated", "web-1", "offline (maint)", "circuit -", "labels role=web,zone=a", "groups frontend"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("%q does not contain %q", line, want)
+		}
+	}
+
+	if line := agentDeltaLine(&v1.AgentDelta{Kind: v1.AgentDeltaKind_AGENT_DELTA_KIND_SYNCED, Time: now}); !strings.Contains(line, "synced") {
+		t.Errorf("synced delta printed as %q", line)
+	}
+}
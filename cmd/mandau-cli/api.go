@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+
+	apiCmd.AddCommand(&cobra.Command{
+		Use:   "describe [symbol]",
+		Short: "Describe the server API",
+		Long: "List services and methods exposed by the server via gRPC reflection, " +
+			"or describe a single service, method or message. Requires server.reflection: true.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: describeAPI,
+	})
+}
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Inspect the server API",
+	Long:  "Commands to inspect the gRPC API exposed by the server",
+}
+
+func (c *CLI) describeAPI(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := rpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("open reflection stream: %w", err)
+	}
+	defer stream.CloseSend()
+
+	resp, err := reflectionCall(stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return fmt.Errorf("list services (is server reflection enabled?): %w", err)
+	}
+
+	var services []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		if !strings.HasPrefix(svc.Name, "grpc.reflection.") {
+			services = append(services, svc.Name)
+		}
+	}
+	sort.Strings(services)
+
+	// Fetch the file descriptors behind every service; the server sends
+	// transitive dependencies along with them
+	fdset := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, svc := range services {
+		resp, err := reflectionCall(stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: svc},
+		})
+		if err != nil {
+			return fmt.Errorf("describe %s: %w", svc, err)
+		}
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return fmt.Errorf("decode descriptor: %w", err)
+			}
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				fdset.File = append(fdset.File, fd)
+			}
+		}
+	}
+
+	files, err := protodesc.NewFiles(fdset)
+	if err != nil {
+		return fmt.Errorf("build descriptors: %w", err)
+	}
+
+	if len(args) == 0 {
+		for _, svc := range services {
+			desc, err := files.FindDescriptorByName(protoreflect.FullName(svc))
+			if err != nil {
+				fmt.Printf("service %s\n\n", svc)
+				continue
+			}
+			printService(desc.(protoreflect.ServiceDescriptor))
+		}
+		return nil
+	}
+
+	return describeSymbol(files, args[0])
+}
+
+func describeAPI(cmd *cobra.Command, args []string) error {
+	return cli.describeAPI(cmd, args)
+}
+
+func reflectionCall(stream rpb.ServerReflection_ServerReflectionInfoClient, req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("%s", e.ErrorMessage)
+	}
+	return resp, nil
+}
+
+// describeSymbol prints a service, method (pkg.Service.Method or
+// pkg.Service/Method) or message
+func describeSymbol(files *protoregistry.Files, symbol string) error {
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(symbol, "/"), "/", "."))
+
+	desc, err := files.FindDescriptorByName(name)
+	if err != nil {
+		return fmt.Errorf("symbol %s not found", symbol)
+	}
+
+	switch d := desc.(type) {
+	case protoreflect.ServiceDescriptor:
+		printService(d)
+	case protoreflect.MethodDescriptor:
+		fmt.Println(methodSignature(d))
+		fmt.Println()
+		printMessage(d.Input())
+		printMessage(d.Output())
+	case protoreflect.MessageDescriptor:
+		printMessage(d)
+	case protoreflect.EnumDescriptor:
+		printEnum(d)
+	default:
+		fmt.Printf("%s\n", desc.FullName())
+	}
+	return nil
+}
+
+func printService(svc protoreflect.ServiceDescriptor) {
+	fmt.Printf("service %s {\n", svc.FullName())
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		fmt.Printf("  %s\n", methodSignature(methods.Get(i)))
+	}
+	fmt.Printf("}\n\n")
+}
+
+func methodSignature(m protoreflect.MethodDescriptor) string {
+	in, out := string(m.Input().FullName()), string(m.Output().FullName())
+	if m.IsStreamingClient() {
+		in = "stream " + in
+	}
+	if m.IsStreamingServer() {
+		out = "stream " + out
+	}
+	return fmt.Sprintf("rpc %s(%s) returns (%s);", m.Name(), in, out)
+}
+
+func printMessage(msg protoreflect.MessageDescriptor) {
+	fmt.Printf("message %s {\n", msg.FullName())
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		fmt.Printf("  %s %s = %d;\n", fieldType(f), f.Name(), f.Number())
+	}
+	fmt.Printf("}\n\n")
+}
+
+func printEnum(e protoreflect.EnumDescriptor) {
+	fmt.Printf("enum %s {\n", e.FullName())
+	values := e.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		fmt.Printf("  %s = %d;\n", v.Name(), v.Number())
+	}
+	fmt.Printf("}\n\n")
+}
+
+func fieldType(f protoreflect.FieldDescriptor) string {
+	if f.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", kindName(f.MapKey()), kindName(f.MapValue()))
+	}
+	name := kindName(f)
+	if f.IsList() {
+		return "repeated " + name
+	}
+	if f.HasOptionalKeyword() {
+		return "optional " + name
+	}
+	return name
+}
+
+func kindName(f protoreflect.FieldDescriptor) string {
+	switch f.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(f.Message().FullName())
+	case protoreflect.EnumKind:
+		return string(f.Enum().FullName())
+	default:
+		return f.Kind().String()
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/application"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	appCmd := &cobra.Command{
+		Use:   "app",
+		Short: "Multi-stack applications",
+		Long: "An application manifest groups stacks across agents and declares which stacks " +
+			"depend on which. Stacks are applied in dependency order, each wave waiting until " +
+			"the one before it is running and healthy.",
+	}
+
+	appCmd.AddCommand(&cobra.Command{
+		Use:   "plan [manifest]",
+		Short: "Show the order an application's stacks are applied in",
+		Args:  cobra.ExactArgs(1),
+		RunE:  planApp,
+		// Planning only reads the manifest
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}, &cobra.Command{
+		Use:   "apply [manifest]",
+		Short: "Apply an application's stacks in dependency order",
+		Args:  cobra.ExactArgs(1),
+		RunE:  applyApp,
+	}, &cobra.Command{
+		Use:   "status [manifest]",
+		Short: "Show the health of an application and its stacks",
+		Args:  cobra.ExactArgs(1),
+		RunE:  appStatus,
+	})
+
+	rootCmd.AddCommand(appCmd)
+}
+
+func (c *CLI) planApp(cmd *cobra.Command, args []string) error {
+	m, err := application.Load(args[0])
+	if err != nil {
+		return err
+	}
+	waves, err := m.Order()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WAVE\tSTACK\tAGENT\tDEPENDS ON")
+	for i, wave := range waves {
+		for _, name := range wave {
+			spec, _ := m.Stack(name)
+			deps := strings.Join(spec.DependsOn, ",")
+			if deps == "" {
+				deps = "-"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, name, spec.Agent, deps)
+		}
+	}
+	return w.Flush()
+}
+
+func (c *CLI) applyApp(cmd *cobra.Command, args []string) error {
+	m, err := application.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	o := application.NewOrchestrator(v1.NewStackServiceClient(c.conn), c.namespace)
+	o.OnChange = func(st application.StackStatus) {
+		line := fmt.Sprintf("[wave %d] %s on %s: %s", st.Wave, st.Name, st.Agent, st.State)
+		if st.Detail != "" {
+			line += " (" + st.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+
+	status, err := o.Apply(context.Background(), m)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Application %s is %s\n", status.Name, status.State)
+	return nil
+}
+
+func (c *CLI) appStatus(cmd *cobra.Command, args []string) error {
+	m, err := application.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	o := application.NewOrchestrator(v1.NewStackServiceClient(c.conn), c.namespace)
+	status, err := o.Status(context.Background(), m)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Application %s: %s\n\n", status.Name, status.State)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WAVE\tSTACK\tAGENT\tSTATE\tDETAIL")
+	for _, st := range status.Stacks {
+		detail := st.Detail
+		if detail == "" {
+			detail = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", st.Wave, st.Name, st.Agent, st.State, detail)
+	}
+	return w.Flush()
+}
+
+func planApp(cmd *cobra.Command, args []string) error {
+	return cli.planApp(cmd, args)
+}
+
+func applyApp(cmd *cobra.Command, args []string) error {
+	return cli.applyApp(cmd, args)
+}
+
+func appStatus(cmd *cobra.Command, args []string) error {
+	return cli.appStatus(cmd, args)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List approval requests",
+		Args:  cobra.NoArgs,
+		RunE:  listApprovals,
+	}
+	listCmd.Flags().String("state", "pending", "Filter by state (pending, approved, rejected, used, expired, all)")
+	approvalsCmd.AddCommand(listCmd)
+
+	approveCmd := &cobra.Command{
+		Use:   "approve [approval-id]",
+		Short: "Approve a pending request",
+		Args:  cobra.ExactArgs(1),
+		RunE:  approveApproval,
+	}
+	approveCmd.Flags().String("comment", "", "Review comment")
+	approvalsCmd.AddCommand(approveCmd)
+
+	rejectCmd := &cobra.Command{
+		Use:   "reject [approval-id]",
+		Short: "Reject a pending request",
+		Args:  cobra.ExactArgs(1),
+		RunE:  rejectApproval,
+	}
+	rejectCmd.Flags().String("comment", "", "Review comment")
+	approvalsCmd.AddCommand(rejectCmd)
+}
+
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Review operations awaiting approval",
+	Long:  "Commands to list, approve and reject operations that policy requires a second identity to sign off",
+}
+
+func (c *CLI) listApprovals(cmd *cobra.Command, args []string) error {
+	stateName, _ := cmd.Flags().GetString("state")
+
+	state := v1.ApprovalState_APPROVAL_STATE_UNKNOWN
+	if stateName != "all" {
+		value, ok := v1.ApprovalState_value["APPROVAL_STATE_"+strings.ToUpper(stateName)]
+		if !ok {
+			return fmt.Errorf("invalid state %q", stateName)
+		}
+		state = v1.ApprovalState(value)
+	}
+
+	resp, err := c.coreClient.ListApprovals(context.Background(), &v1.ListApprovalsRequest{
+		State: state,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-36s %-10s %-20s %-20s %-8s %-25s %-12s %s\n", "ID", "STATE", "REQUESTED BY", "TARGET", "ACTION", "RESOURCE", "DIGEST", "EXPIRES")
+	for _, a := range resp.Approvals {
+		fmt.Printf("%-36s %-10s %-20s %-20s %-8s %-25s %-12s %s\n",
+			a.Id,
+			strings.ToLower(strings.TrimPrefix(a.State.String(), "APPROVAL_STATE_")),
+			a.RequestedBy,
+			approvalTarget(a),
+			a.Action,
+			a.Resource,
+			shortDigest(a.Digest),
+			a.ExpiresAt.AsTime().Format("2006-01-02 15:04:05"),
+		)
+	}
+
+	return nil
+}
+
+func listApprovals(cmd *cobra.Command, args []string) error {
+	return cli.listApprovals(cmd, args)
+}
+
+func (c *CLI) reviewApproval(cmd *cobra.Command, id string, approve bool) error {
+	comment, _ := cmd.Flags().GetString("comment")
+
+	approval, err := c.coreClient.ReviewApproval(context.Background(), &v1.ReviewApprovalRequest{
+		Id:      id,
+		Approve: approve,
+		Comment: comment,
+	})
+	if err != nil {
+		return err
+	}
+
+	if approve {
+		fmt.Printf("✓ Approved %s %s on %s for %s (digest %s)\n",
+			approval.Action, approval.Resource, approvalTarget(approval), approval.RequestedBy, shortDigest(approval.Digest))
+	} else {
+		fmt.Printf("✓ Rejected %s %s on %s for %s\n", approval.Action, approval.Resource, approvalTarget(approval), approval.RequestedBy)
+	}
+	return nil
+}
+
+// approvalTarget names the agent, or the group and its members, an approval covers
+func approvalTarget(a *v1.Approval) string {
+	if a.Group != "" {
+		return fmt.Sprintf("group:%s (%d)", a.Group, len(a.Agents))
+	}
+	return a.AgentId
+}
+
+// shortDigest abbreviates a request digest for display
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func approveApproval(cmd *cobra.Command, args []string) error {
+	return cli.reviewApproval(cmd, args[0], true)
+}
+
+func rejectApproval(cmd *cobra.Command, args []string) error {
+	return cli.reviewApproval(cmd, args[0], false)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	artifactCmd := &cobra.Command{
+		Use:   "artifact",
+		Short: "Content stored on the core by digest, uploaded once and fetched by agents",
+	}
+
+	pushCmd := &cobra.Command{
+		Use:   "push <file>",
+		Short: "Store a file on the core",
+		Long: "Store a file on the core under its SHA-256 digest, printed once stored. Content the core " +
+			"already holds is not sent again. Agents fetch it with mandau transfer fetch. " +
+			"Needs write on artifact:*.",
+		Args: cobra.ExactArgs(1),
+		RunE: pushArtifact,
+	}
+	pushCmd.Flags().String("kind", "file", "Kind of content: compose, template, script, image-bundle or file")
+	pushCmd.Flags().String("name", "", "Name to record (default: the file's name)")
+	pushCmd.Flags().Bool("pin", false, "Never collect the artifact as garbage")
+	pushCmd.Flags().String("bandwidth", "", "Bytes per second to send at most, e.g. 10M")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the artifacts stored on the core",
+		Args:  cobra.NoArgs,
+		RunE:  listArtifacts,
+	}
+	listCmd.Flags().String("kind", "", "Only list artifacts of this kind")
+
+	rmCmd := &cobra.Command{
+		Use:   "rm <digest>",
+		Short: "Remove an artifact, pinned or not",
+		Args:  cobra.ExactArgs(1),
+		RunE:  removeArtifact,
+	}
+
+	gcCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove the unpinned artifacts unused within the core's artifacts.retention",
+		Long: "Remove the unpinned artifacts neither pushed nor fetched within artifacts.retention now, " +
+			"rather than at the core's next collection.",
+		Args: cobra.NoArgs,
+		RunE: collectArtifacts,
+	}
+	gcCmd.Flags().Bool("dry-run", false, "List what would be removed")
+
+	artifactCmd.AddCommand(pushCmd, listCmd, rmCmd, gcCmd)
+	rootCmd.AddCommand(artifactCmd)
+}
+
+func (c *CLI) pushArtifactCmd(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	name, _ := cmd.Flags().GetString("name")
+	pin, _ := cmd.Flags().GetBool("pin")
+	limit, err := bandwidthFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	a, err := c.pushArtifact(context.Background(), args[0], kind, name, pin, limit)
+	if err != nil {
+		return err
+	}
+	fmt.Println(a.Digest)
+	return nil
+}
+
+func pushArtifact(cmd *cobra.Command, args []string) error {
+	return cli.pushArtifactCmd(cmd, args)
+}
+
+// pushArtifact stores file on the core unless it holds it already, and
+// returns its record
+func (c *CLI) pushArtifact(ctx context.Context, file, kind, name string, pin bool, limit *transfer.Limiter) (*v1.Artifact, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	digest, err := transfer.Digest(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+	if name == "" {
+		name = filepath.Base(file)
+	}
+
+	artifacts := v1.NewArtifactServiceClient(c.conn)
+	stored, err := artifacts.GetArtifact(ctx, &v1.GetArtifactRequest{Digest: digest})
+	switch {
+	case err == nil && (stored.Pinned || !pin):
+		fmt.Fprintf(os.Stderr, "✓ The core already holds %s\n", file)
+		return stored, nil
+	case err != nil && status.Code(err) != codes.NotFound:
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Pushing %s (%s) to the core...\n", file, quota.FormatMemory(info.Size()))
+	stream, err := artifacts.PutArtifact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&v1.PutArtifactRequest{Digest: digest, Kind: kind, Name: name, Size: info.Size(), Pin: pin}); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// The core answers with headers once it wants the payload; a refusal
+	// ends the call before the payload is sent
+	if md, _ := stream.Header(); md != nil {
+		buf := make([]byte, transfer.ChunkSize)
+		for {
+			n, readErr := f.Read(buf)
+			if n > 0 {
+				if err := limit.Wait(ctx, n); err != nil {
+					return nil, err
+				}
+				// EOF means the call ended early; CloseAndRecv says why
+				if err := stream.Send(&v1.PutArtifactRequest{Chunk: buf[:n]}); err == io.EOF {
+					break
+				} else if err != nil {
+					return nil, err
+				}
+			}
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			if readErr != nil {
+				return nil, readErr
+			}
+		}
+	}
+
+	a, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("push %s: %w", file, err)
+	}
+	return a, nil
+}
+
+func (c *CLI) listArtifacts(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	resp, err := v1.NewArtifactServiceClient(c.conn).ListArtifacts(context.Background(), &v1.ListArtifactsRequest{Kind: kind})
+	if err != nil {
+		return err
+	}
+	if len(resp.Artifacts) == 0 {
+		fmt.Println("No artifacts found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DIGEST\tKIND\tSIZE\tNAMES\tPUSHES\tFETCHES\tLAST USED\tPINNED")
+	for _, a := range resp.Artifacts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%t\n", a.Digest, a.Kind, quota.FormatMemory(a.Size),
+			strings.Join(a.Names, ","), a.Pushes, a.Fetches, a.UsedAt.AsTime().Local().Format(time.RFC3339), a.Pinned)
+	}
+	return w.Flush()
+}
+
+func listArtifacts(cmd *cobra.Command, args []string) error {
+	return cli.listArtifacts(cmd, args)
+}
+
+func (c *CLI) removeArtifact(cmd *cobra.Command, args []string) error {
+	a, err := v1.NewArtifactServiceClient(c.conn).DeleteArtifact(context.Background(), &v1.DeleteArtifactRequest{Digest: args[0]})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Removed %s %s (%s)\n", a.Kind, a.Digest, quota.FormatMemory(a.Size))
+	return nil
+}
+
+func removeArtifact(cmd *cobra.Command, args []string) error {
+	return cli.removeArtifact(cmd, args)
+}
+
+func (c *CLI) collectArtifacts(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	resp, err := v1.NewArtifactServiceClient(c.conn).CollectArtifactGarbage(context.Background(), &v1.CollectArtifactGarbageRequest{DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	for _, a := range resp.Removed {
+		fmt.Printf("%s %s %s (%s, last used %s)\n", verb, a.Kind, a.Digest, quota.FormatMemory(a.Size),
+			a.UsedAt.AsTime().Local().Format(time.RFC3339))
+	}
+	fmt.Printf("%s %d artifact(s), %s\n", verb, len(resp.Removed), quota.FormatMemory(resp.Freed))
+	return nil
+}
+
+func collectArtifacts(cmd *cobra.Command, args []string) error {
+	return cli.collectArtifacts(cmd, args)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	rootCmd.AddCommand(breakGlassCmd)
+
+	grantCmd := &cobra.Command{
+		Use:   "grant",
+		Short: "Grant temporary elevated roles",
+		Long:  "Grant roles to an identity for a limited time. A reason is mandatory and every grant is audited.",
+		Args:  cobra.NoArgs,
+		RunE:  grantBreakGlass,
+	}
+	grantCmd.Flags().StringSlice("role", nil, "Role to grant (repeatable)")
+	grantCmd.Flags().String("reason", "", "Why elevated access is needed (required)")
+	grantCmd.Flags().Duration("ttl", 0, "How long the grant lasts, e.g. 30m (required)")
+	grantCmd.Flags().String("user", "", "Identity to elevate (defaults to yourself)")
+	grantCmd.MarkFlagRequired("role")
+	grantCmd.MarkFlagRequired("reason")
+	grantCmd.MarkFlagRequired("ttl")
+	breakGlassCmd.AddCommand(grantCmd)
+
+	breakGlassCmd.AddCommand(&cobra.Command{
+		Use:   "revoke [grant-id]",
+		Short: "Revoke a break-glass grant",
+		Args:  cobra.ExactArgs(1),
+		RunE:  revokeBreakGlass,
+	})
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List break-glass grants",
+		Args:  cobra.NoArgs,
+		RunE:  listBreakGlass,
+	}
+	listCmd.Flags().Bool("active", false, "Only show active grants")
+	breakGlassCmd.AddCommand(listCmd)
+}
+
+var breakGlassCmd = &cobra.Command{
+	Use:   "breakglass",
+	Short: "Time-limited elevated access",
+	Long:  "Commands to grant, revoke and list temporary role elevations",
+}
+
+func (c *CLI) grantBreakGlass(cmd *cobra.Command, args []string) error {
+	roles, _ := cmd.Flags().GetStringSlice("role")
+	reason, _ := cmd.Flags().GetString("reason")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	user, _ := cmd.Flags().GetString("user")
+
+	grant, err := c.coreClient.GrantBreakGlass(context.Background(), &v1.GrantBreakGlassRequest{
+		UserId: user,
+		Roles:  roles,
+		Reason: reason,
+		Ttl:    durationpb.New(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Granted %s to %s until %s (grant %s)\n",
+		strings.Join(grant.Roles, ","),
+		grant.UserId,
+		grant.ExpiresAt.AsTime().Format("2006-01-02 15:04:05"),
+		grant.Id,
+	)
+	return nil
+}
+
+func grantBreakGlass(cmd *cobra.Command, args []string) error {
+	return cli.grantBreakGlass(cmd, args)
+}
+
+func (c *CLI) revokeBreakGlass(cmd *cobra.Command, args []string) error {
+	grant, err := c.coreClient.RevokeBreakGlass(context.Background(), &v1.RevokeBreakGlassRequest{
+		Id: args[0],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Revoked grant %s for %s\n", grant.Id, grant.UserId)
+	return nil
+}
+
+func revokeBreakGlass(cmd *cobra.Command, args []string) error {
+	return cli.revokeBreakGlass(cmd, args)
+}
+
+func (c *CLI) listBreakGlass(cmd *cobra.Command, args []string) error {
+	active, _ := cmd.Flags().GetBool("active")
+
+	resp, err := c.coreClient.ListBreakGlassGrants(context.Background(), &v1.ListBreakGlassGrantsRequest{
+		ActiveOnly: active,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-36s %-20s %-15s %-8s %-20s %s\n", "ID", "USER", "ROLES", "ACTIVE", "EXPIRES", "REASON")
+	for _, g := range resp.Grants {
+		fmt.Printf("%-36s %-20s %-15s %-8t %-20s %s\n",
+			g.Id,
+			g.UserId,
+			strings.Join(g.Roles, ","),
+			g.Active,
+			g.ExpiresAt.AsTime().Format("2006-01-02 15:04:05"),
+			g.Reason,
+		)
+	}
+
+	return nil
+}
+
+func listBreakGlass(cmd *cobra.Command, args []string) error {
+	return cli.listBreakGlass(cmd, args)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	complianceCmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "Check the fleet against patch policy",
+	}
+
+	packagesCmd := &cobra.Command{
+		Use:   "packages",
+		Short: "Show pending security updates and reboots on every agent",
+		Long: "Ask every agent serving the host environment for the updates its package manager has " +
+			"pending, as of its last package index refresh, and whether it needs a reboot because a " +
+			"package asks for one or a newer kernel than the running one is installed.",
+		Args: cobra.NoArgs,
+		RunE: compliancePackages,
+	}
+	packagesCmd.Flags().String("group", "", "Only check the agents in the group")
+	packagesCmd.Flags().Bool("packages", false, "List the security updates of each agent")
+
+	complianceCmd.AddCommand(packagesCmd)
+	rootCmd.AddCommand(complianceCmd)
+}
+
+func (c *CLI) compliancePackages(cmd *cobra.Command, args []string) error {
+	group, _ := cmd.Flags().GetString("group")
+	list, _ := cmd.Flags().GetBool("packages")
+
+	resp, err := c.coreClient.GetPatchCompliance(context.Background(), &v1.GetPatchComplianceRequest{Group: group})
+	if err != nil {
+		return err
+	}
+	warnMissing(resp.AgentErrors)
+	if len(resp.Agents) == 0 {
+		return fmt.Errorf("no agent reported its patch status; enable the host-environment plugin on them")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tPKG MGR\tSECURITY\tUPDATES\tRUNNING KERNEL\tINSTALLED KERNEL\tREBOOT")
+	compliant := 0
+	for _, a := range resp.Agents {
+		st := a.Status
+		reboot := "no"
+		if st.RebootRequired {
+			reboot = "REQUIRED"
+		}
+		if len(st.SecurityUpdates) == 0 && !st.RebootRequired {
+			compliant++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n", a.AgentId, st.PackageManager,
+			len(st.SecurityUpdates), st.Updates, orDash(st.RunningKernel), orDash(st.InstalledKernel), reboot)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if list {
+		for _, a := range resp.Agents {
+			st := a.Status
+			if len(st.SecurityUpdates) == 0 && len(st.RebootPackages) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s:\n", a.AgentId)
+			for _, u := range st.SecurityUpdates {
+				fmt.Printf("  %-40s %s -> %s\n", u.Name, orDash(u.CurrentVersion), u.AvailableVersion)
+			}
+			for _, pkg := range st.RebootPackages {
+				fmt.Printf("  %-40s needs a reboot\n", pkg)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d of %d agent(s) have no pending security updates or reboots\n", compliant, len(resp.Agents))
+	return nil
+}
+
+func compliancePackages(cmd *cobra.Command, args []string) error {
+	return cli.compliancePackages(cmd, args)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/diagnose"
+	"github.com/bhangun/mandau/pkg/transport"
+	"github.com/spf13/cobra"
+)
+
+// coreServerName is the name the CLI expects in the core certificate
+const coreServerName = "mandau-core"
+
+func init() {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "doctor [agent-id]",
+		Short: "Diagnose the CLI, core and agent setup",
+		Long: "Check certificates, core and agent connectivity, Docker and compose on the agent, " +
+			"plugins, registry mirrors, clock skew and disk space, with a remediation hint for every problem.",
+		Args: cobra.MaximumNArgs(1),
+		// Connect inside the command so connection problems are diagnosed
+		// instead of aborting it
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              doctor,
+	})
+}
+
+func (c *CLI) doctor(cmd *cobra.Command, args []string) error {
+	connectErr := c.connect(cmd)
+
+	checks := []*v1.DiagnosticCheck{}
+	local := func(list ...diagnose.Check) {
+		checks = append(checks, diagnose.ToProto("cli", list)...)
+	}
+
+	local(
+		diagnose.CertFile("client certificate", c.endpoint.tls.CertPath),
+		diagnose.CertFile("CA certificate", c.endpoint.tls.CAPath),
+	)
+
+	if connectErr != nil {
+		local(diagnose.Fail("client setup", connectErr.Error(),
+			"Set --cert/--key/--ca or MANDAU_CERT/MANDAU_KEY/MANDAU_CA to valid files"))
+		return printChecks(checks)
+	}
+
+	local(c.checkCoreTLS())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := c.coreClient.Diagnose(ctx, &v1.DiagnoseRequest{})
+	rtt := time.Since(start)
+	if err != nil {
+		local(diagnose.Fail("core diagnostics", err.Error(),
+			"Check the core logs; older cores do not support diagnostics"))
+		return printChecks(checks)
+	}
+	local(diagnose.ClockSkew("clock skew (cli↔core)", resp.Time.AsTime().Sub(start.Add(rtt/2))))
+	checks = append(checks, resp.Checks...)
+
+	if len(args) > 0 {
+		resp, err := c.coreClient.Diagnose(ctx, &v1.DiagnoseRequest{AgentId: args[0]})
+		if err != nil {
+			local(diagnose.Fail("agent diagnostics", err.Error(),
+				"Check the agent ID with \"mandau agent list\""))
+		} else {
+			checks = append(checks, resp.Checks...)
+		}
+	}
+
+	return printChecks(checks)
+}
+
+func doctor(cmd *cobra.Command, args []string) error {
+	return cli.doctor(cmd, args)
+}
+
+// checkCoreTLS performs a TLS handshake with the core to verify reachability
+// and the server certificate's validity and SANs
+func (c *CLI) checkCoreTLS() diagnose.Check {
+	const name = "core TLS"
+
+	cert, err := tls.LoadX509KeyPair(c.endpoint.tls.CertPath, c.endpoint.tls.KeyPath)
+	if err != nil {
+		return diagnose.Fail(name, fmt.Sprintf("load client key pair: %v", err),
+			"Make sure the client certificate and key belong together")
+	}
+	pool, err := transport.CertPool(transport.CAFiles(c.endpoint.tls)...)
+	if err != nil {
+		return diagnose.Fail(name, err.Error(), "Set --ca or MANDAU_CA to the cluster CA")
+	}
+	serverName := c.endpoint.tls.ServerName
+	if serverName == "" {
+		serverName = coreServerName
+	}
+
+	// The core's local unix socket speaks the same TLS
+	network, addr := "tcp", c.endpoint.server
+	if path, ok := strings.CutPrefix(addr, "unix://"); ok {
+		network, addr = "unix", path
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, network, addr, &tls.Config{
+		Certificates:     []tls.Certificate{cert},
+		RootCAs:          pool,
+		ServerName:       serverName,
+		MinVersion:       tls.VersionTLS13,
+		VerifyConnection: transport.PinCAs(c.endpoint.tls.PinnedCAs),
+	})
+	if err != nil {
+		return diagnose.TLSFailure(name, "core", c.endpoint.server, serverName, err)
+	}
+	defer conn.Close()
+
+	peer := conn.ConnectionState().PeerCertificates[0]
+	check := diagnose.Certificate(name, peer, time.Now())
+	check.Detail = c.endpoint.server + ": " + check.Detail
+	return check
+}
+
+func printChecks(checks []*v1.DiagnosticCheck) error {
+	failed := 0
+	for _, check := range checks {
+		mark := "✓"
+		switch check.Status {
+		case v1.CheckStatus_CHECK_STATUS_WARN:
+			mark = "!"
+		case v1.CheckStatus_CHECK_STATUS_FAIL:
+			mark = "✗"
+			failed++
+		}
+
+		fmt.Printf("%s [%s] %s: %s\n", mark, check.Source, check.Name, check.Detail)
+		if check.Remediation != "" && check.Status != v1.CheckStatus_CHECK_STATUS_OK {
+			fmt.Printf("    → %s\n", check.Remediation)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	driftCmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Find host configuration changed outside Mandau",
+	}
+
+	reportCmd := &cobra.Command{
+		Use:   "report [agent-id]",
+		Short: "Report managed host artifacts that drifted",
+		Long: "Compare the vhosts, units, cron files and zone files the host service plugins wrote " +
+			"with what they rendered, and the ports deployments opened with the firewall rules. " +
+			"Without an agent ID or --group every agent serving drift reports is scanned.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: driftReport,
+	}
+	reportCmd.Flags().String("group", "", "Scan every agent in the group")
+
+	driftCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(driftCmd)
+}
+
+func (c *CLI) driftReport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var agents []string
+	if group, _ := cmd.Flags().GetString("group"); group != "" || len(args) > 0 {
+		var err error
+		if agents, _, err = c.targetAgents(ctx, cmd, args, 0); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if agents, err = c.driftAgents(ctx); err != nil {
+			return err
+		}
+		if len(agents) == 0 {
+			return fmt.Errorf("no agent serves drift reports; enable a host service plugin on one")
+		}
+	}
+
+	driftClient := v1.NewDriftServiceClient(c.conn)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tKIND\tNAME\tSTATE\tDETAIL")
+	found := 0
+	for _, agentID := range agents {
+		report, err := driftClient.GetDriftReport(ctx, &v1.GetDriftReportRequest{AgentId: agentID})
+		if err != nil {
+			w.Flush()
+			return hostError(err, agentID, "nginx, systemd, firewall, cron or dns")
+		}
+		for _, d := range report.Drift {
+			found++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", agentID, d.Kind, d.Name, d.State, orDash(d.Detail))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if found == 0 {
+		fmt.Println("\nNo drift")
+		return nil
+	}
+	fmt.Printf("\n%d drifted\n", found)
+	return nil
+}
+
+func driftReport(cmd *cobra.Command, args []string) error {
+	return cli.driftReport(cmd, args)
+}
+
+// driftAgents lists the agents that serve drift reports
+func (c *CLI) driftAgents(ctx context.Context) ([]string, error) {
+	resp, err := c.coreClient.ListAgents(ctx, &v1.ListAgentsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, agent := range resp.Agents {
+		if capability.Has(agent.Capabilities, capability.Drift) {
+			ids = append(ids, agent.Id)
+		}
+	}
+	return ids, nil
+}
+
+// orDash shows an empty table cell as "-"
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
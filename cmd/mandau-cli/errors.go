@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes scripts can branch on. Errors that did not come from the core
+// or an agent exit with exitError.
+const (
+	exitError       = 1
+	exitInvalid     = 2 // The request was malformed
+	exitNotFound    = 3 // The agent, stack or other resource does not exist
+	exitDenied      = 4 // Not authenticated, or not allowed
+	exitApproval    = 5 // Waiting for a second sign-off
+	exitBlocked     = 6 // Refused by policy or state: freeze, maintenance, quota, capability
+	exitUnavailable = 7 // The agent or core could not be reached; retrying may help
+	exitConflict    = 8 // The resource exists, or the idempotency key was reused
+)
+
+// exitCode returns the exit code for err
+func exitCode(err error) int {
+	if _, ok := status.FromError(err); !ok {
+		return exitError
+	}
+
+	switch transport.Detail(err).Code {
+	case v1.ErrorCode_ERROR_CODE_INVALID_ARGUMENT:
+		return exitInvalid
+	case v1.ErrorCode_ERROR_CODE_NOT_FOUND, v1.ErrorCode_ERROR_CODE_AGENT_NOT_FOUND:
+		return exitNotFound
+	case v1.ErrorCode_ERROR_CODE_UNAUTHENTICATED, v1.ErrorCode_ERROR_CODE_PERMISSION_DENIED:
+		return exitDenied
+	case v1.ErrorCode_ERROR_CODE_APPROVAL_REQUIRED:
+		return exitApproval
+	case v1.ErrorCode_ERROR_CODE_FAILED_PRECONDITION, v1.ErrorCode_ERROR_CODE_FROZEN,
+		v1.ErrorCode_ERROR_CODE_MAINTENANCE, v1.ErrorCode_ERROR_CODE_QUOTA_EXCEEDED,
+		v1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING, v1.ErrorCode_ERROR_CODE_AGENT_DIAL_OUT_ONLY:
+		return exitBlocked
+	case v1.ErrorCode_ERROR_CODE_UNAVAILABLE, v1.ErrorCode_ERROR_CODE_TIMEOUT,
+		v1.ErrorCode_ERROR_CODE_AGENT_OFFLINE, v1.ErrorCode_ERROR_CODE_AGENT_UNHEALTHY:
+		return exitUnavailable
+	case v1.ErrorCode_ERROR_CODE_ALREADY_EXISTS:
+		return exitConflict
+	default:
+		return exitError
+	}
+}
+
+// printError writes err for a person: the message without gRPC framing,
+// then the error code, what to do about it and the request ID of the
+// command, which finds its log lines, operations and audit entries
+func printError(w io.Writer, err error, requestID string) {
+	st, ok := status.FromError(err)
+	if !ok {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	// Wrapped statuses print as "context: rpc error: code = X desc = message"
+	msg := strings.Replace(st.Message(), fmt.Sprintf("rpc error: code = %s desc = ", st.Code()), "", 1)
+	fmt.Fprintf(w, "Error: %s\n", msg)
+
+	detail := transport.Detail(err)
+	fmt.Fprintf(w, "  Code: %s\n", strings.TrimPrefix(detail.Code.String(), "ERROR_CODE_"))
+	if detail.Hint != "" {
+		fmt.Fprintf(w, "  Hint: %s\n", detail.Hint)
+	}
+	if detail.Retryable {
+		fmt.Fprintln(w, "  The same command may succeed if retried later")
+	}
+	if requestID != "" {
+		fmt.Fprintf(w, "  Request ID: %s (quote it when asking for support)\n", requestID)
+	}
+}
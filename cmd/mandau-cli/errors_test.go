@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExitCode(t *testing.T) {
+	approval := transport.Error(codes.FailedPrecondition, &v1.ErrorDetail{
+		Code: v1.ErrorCode_ERROR_CODE_APPROVAL_REQUIRED,
+	}, "requires approval")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"local", fmt.Errorf("read compose file: no such file"), exitError},
+		{"detail", approval, exitApproval},
+		{"wrapped detail", fmt.Errorf("agent web-1: %w", approval), exitApproval},
+		{"status code", status.Error(codes.PermissionDenied, "denied"), exitDenied},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), exitUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	err := fmt.Errorf("agent web-1: %w", transport.Error(codes.Unavailable, &v1.ErrorDetail{
+		Code:      v1.ErrorCode_ERROR_CODE_AGENT_OFFLINE,
+		Retryable: true,
+		Hint:      "check that the agent is running",
+	}, "agent offline: web-1"))
+
+	var buf bytes.Buffer
+	printError(&buf, err, "3f2a9c41d07e5b86")
+	want := "Error: agent web-1: agent offline: web-1\n" +
+		"  Code: AGENT_OFFLINE\n" +
+		"  Hint: check that the agent is running\n" +
+		"  The same command may succeed if retried later\n" +
+		"  Request ID: 3f2a9c41d07e5b86 (quote it when asking for support)\n"
+	if buf.String() != want {
+		t.Errorf("printError() wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *CLI) stackEvents(cmd *cobra.Command, args []string) error {
+	since, _ := cmd.Flags().GetDuration("since")
+	kinds, _ := cmd.Flags().GetStringSlice("kind")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	// A lone argument is the stack name; the core finds its agent
+	agentID, stackName := "", args[0]
+	if len(args) == 2 {
+		agentID, stackName = args[0], args[1]
+	}
+
+	req := &v1.GetStackEventsRequest{
+		AgentId:   agentID,
+		StackName: stackName,
+		Namespace: c.namespace,
+		Kinds:     kinds,
+		Limit:     int32(limit),
+	}
+	if since > 0 {
+		req.Since = timestamppb.New(time.Now().Add(-since))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := v1.NewStackServiceClient(c.conn).GetStackEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+	if len(resp.Events) == 0 {
+		fmt.Printf("No events for stack %s\n", stackName)
+		return nil
+	}
+	return printStackEvents(os.Stdout, resp.Events)
+}
+
+// printStackEvents writes events one per row, oldest first, with the
+// details of each indented below it and failures marked
+func printStackEvents(out io.Writer, events []*v1.StackEvent) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tKIND\tSERVICE\tSUMMARY")
+	for _, e := range events {
+		kind := e.Kind
+		if e.Failed {
+			kind += " ✗"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Time.AsTime().Local().Format(time.DateTime), kind, orDash(e.Service), e.Summary)
+		for _, d := range e.Details {
+			fmt.Fprintf(w, "\t\t\t  %s\n", d)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestPrintStackEvents(t *testing.T) {
+	now := timestamppb.New(time.Now())
+	var b strings.Builder
+	err := printStackEvents(&b, []*v1.StackEvent{
+		{Time: now, Kind: "apply", Summary: "Applied 1 changes", Details: []string{"api: image: api:1 → api:2"}},
+		{Time: now, Kind: "crash", Service: "api", Summary: "shop-api-1 exited with code 1", Failed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines:\n%s", len(lines), b.String())
+	}
+	for i, want := range []string{"SUMMARY", "apply", "api: image: api:1 → api:2", "crash ✗"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[1], " - ") {
+		t.Errorf("event without a service: %q", lines[1])
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// stackDocument is a stack export as written by "mandau stack export"
+type stackDocument struct {
+	Name       string            `yaml:"name"`
+	Namespace  string            `yaml:"namespace"`
+	Agent      string            `yaml:"agent"`
+	ExportedAt time.Time         `yaml:"exported_at"`
+	Redacted   bool              `yaml:"redacted"` // Secret values are masked
+	State      string            `yaml:"state"`
+	Owner      *stackOwnerDoc    `yaml:"owner,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+	Containers []containerDoc    `yaml:"containers,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty"`
+	Compose    string            `yaml:"compose,omitempty"`
+	Overrides  []fileDoc         `yaml:"overrides,omitempty"` // Merged over compose in order
+	Files      []fileDoc         `yaml:"files,omitempty"`     // configs/, secrets/ and dotenv variants
+}
+
+// fileDoc is a file stored with a stack's compose file
+type fileDoc struct {
+	Path    string `yaml:"path"`
+	Mode    uint32 `yaml:"mode,omitempty"`
+	Content string `yaml:"content,omitempty"`
+}
+
+type stackOwnerDoc struct {
+	Team   string `yaml:"team,omitempty"`
+	Owner  string `yaml:"owner,omitempty"`
+	Ticket string `yaml:"ticket,omitempty"`
+}
+
+type containerDoc struct {
+	Name   string `yaml:"name"`
+	Image  string `yaml:"image"`
+	State  string `yaml:"state"`
+	Status string `yaml:"status,omitempty"`
+}
+
+func newStackDocument(e *v1.StackExport) *stackDocument {
+	doc := &stackDocument{
+		Name:       e.Name,
+		Namespace:  e.Namespace,
+		Agent:      e.AgentId,
+		ExportedAt: e.ExportedAt.AsTime().UTC(),
+		Redacted:   e.Redacted,
+		State:      strings.ToLower(strings.TrimPrefix(e.State.String(), "STACK_STATE_")),
+		Labels:     e.Labels,
+		Env:        e.EnvVars,
+		Compose:    e.ComposeContent,
+	}
+	if o := e.Owner; o != nil {
+		doc.Owner = &stackOwnerDoc{Team: o.Team, Owner: o.Owner, Ticket: o.Ticket}
+	}
+	for _, f := range e.ComposeOverrides {
+		doc.Overrides = append(doc.Overrides, fileDoc{Path: f.Path, Content: string(f.Content)})
+	}
+	for _, f := range e.ProjectFiles {
+		doc.Files = append(doc.Files, fileDoc{Path: f.Path, Mode: f.Mode, Content: string(f.Content)})
+	}
+	for _, ctr := range e.Containers {
+		doc.Containers = append(doc.Containers, containerDoc{
+			Name:   ctr.Name,
+			Image:  ctr.Image,
+			State:  ctr.State,
+			Status: ctr.Status,
+		})
+	}
+	return doc
+}
+
+// writeStackArchive writes doc as a gzipped tarball holding compose.yaml,
+// .env, the compose overrides and project files, and stack.yaml with
+// everything else, under a directory named after the stack. Secret files
+// left out of a redacted export are left out of the archive.
+func writeStackArchive(w io.Writer, doc *stackDocument) error {
+	meta := *doc
+	meta.Compose, meta.Env = "", nil
+	// stack.yaml keeps the paths, and so the order overrides merge in
+	meta.Overrides, meta.Files = nil, nil
+	for _, f := range doc.Overrides {
+		meta.Overrides = append(meta.Overrides, fileDoc{Path: f.Path})
+	}
+	for _, f := range doc.Files {
+		meta.Files = append(meta.Files, fileDoc{Path: f.Path, Mode: f.Mode})
+	}
+	metaData, err := yaml.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"compose.yaml": doc.Compose,
+		"stack.yaml":   string(metaData),
+	}
+	if len(doc.Env) > 0 {
+		files[".env"] = envFile(doc.Env)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	writeFile := func(name, data string, mode int64) error {
+		hdr := &tar.Header{
+			Name:    doc.Name + "/" + name,
+			Mode:    mode,
+			Size:    int64(len(data)),
+			ModTime: doc.ExportedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := io.WriteString(tw, data)
+		return err
+	}
+	for _, name := range []string{"compose.yaml", ".env", "stack.yaml"} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := writeFile(name, data, 0o600); err != nil {
+			return err
+		}
+	}
+	for _, f := range doc.Overrides {
+		if err := writeFile(f.Path, f.Content, 0o600); err != nil {
+			return err
+		}
+	}
+	for _, f := range doc.Files {
+		if doc.Redacted && secretFile(f.Path) {
+			continue
+		}
+		mode := int64(f.Mode)
+		if mode == 0 {
+			mode = 0o600
+		}
+		if err := writeFile(f.Path, f.Content, mode); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// secretFile reports whether a project file holds secrets, as the agent
+// decides which to leave out of redacted exports
+func secretFile(path string) bool {
+	return strings.HasPrefix(path, "secrets/") || strings.HasPrefix(path, ".env.")
+}
+
+// envFile renders env as a .env file, sorted by name
+func envFile(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, env[k])
+	}
+	return b.String()
+}
+
+func (c *CLI) exportStack(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	reveal, _ := cmd.Flags().GetBool("reveal-secrets")
+	if format != "yaml" && format != "tar" {
+		return fmt.Errorf("unknown format %q: want yaml or tar", format)
+	}
+	if format == "tar" && output == "" {
+		return fmt.Errorf("--format tar needs --output")
+	}
+
+	// A lone argument is the stack name; the core finds its agent
+	agentID, stackName := "", args[0]
+	if len(args) == 2 {
+		agentID, stackName = args[0], args[1]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	export, err := v1.NewStackServiceClient(c.conn).ExportStack(ctx, &v1.ExportStackRequest{
+		AgentId:       agentID,
+		StackName:     stackName,
+		Namespace:     c.namespace,
+		RevealSecrets: reveal,
+	})
+	if err != nil {
+		return err
+	}
+	doc := newStackDocument(export)
+
+	out := io.Writer(os.Stdout)
+	if output != "" {
+		// Exports may hold secrets, so they are kept private
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("create export: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if format == "tar" {
+		err = writeStackArchive(out, doc)
+	} else {
+		err = yaml.NewEncoder(out).Encode(doc)
+	}
+	if err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "✓ Stack %s exported to %s\n", stackName, output)
+	}
+	if doc.Redacted {
+		fmt.Fprintln(os.Stderr, "note: secret values are masked; use --reveal-secrets to keep them")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteStackArchive(t *testing.T) {
+	doc := &stackDocument{
+		Name:       "web",
+		Namespace:  "default",
+		ExportedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Env:        map[string]string{"B": "2", "A": "1"},
+		Compose:    "services:\n  web:\n    image: nginx\n",
+		Overrides:  []fileDoc{{Path: "compose.prod.yaml", Content: "services: {}\n"}},
+		Files: []fileDoc{
+			{Path: "configs/nginx.conf", Mode: 0o644, Content: "events {}\n"},
+			{Path: "secrets/db_password"},
+		},
+		Redacted: true,
+	}
+
+	var buf bytes.Buffer
+	if err := writeStackArchive(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(data)
+	}
+
+	if files["web/compose.yaml"] != doc.Compose {
+		t.Errorf("compose.yaml = %q", files["web/compose.yaml"])
+	}
+	if files["web/.env"] != "A=1\nB=2\n" {
+		t.Errorf(".env = %q, want sorted variables", files["web/.env"])
+	}
+	if meta := files["web/stack.yaml"]; !strings.Contains(meta, "name: web") || strings.Contains(meta, "compose:") ||
+		!strings.Contains(meta, "path: compose.prod.yaml") || strings.Contains(meta, "content:") {
+		t.Errorf("stack.yaml = %q, want the metadata and file paths without the files", meta)
+	}
+	if files["web/compose.prod.yaml"] != "services: {}\n" || files["web/configs/nginx.conf"] != "events {}\n" {
+		t.Errorf("archive = %v, want the override and project files", files)
+	}
+	if _, ok := files["web/secrets/db_password"]; ok {
+		t.Error("redacted archive holds a secret file")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// maxSummary caps the output line shown per agent in the fleet run table
+const maxSummary = 60
+
+func init() {
+	fleetCmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Run allowlisted commands across agents",
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run [command]",
+		Short: "Run an allowlisted command on the agents matching a selector",
+		Long: "Run a command from the commands list of the agent config on every agent matching " +
+			"--label, --group and --agent, or on all agents with --all, a few at a time. Each agent " +
+			"runs it under the shorter of --timeout and the command's own timeout. The report lists " +
+			"the exit code of each agent and the last line of its output; --output prints the end " +
+			"of the output in full. Exits non-zero when the command fails or cannot be run on any " +
+			"selected agent.",
+		Args: cobra.ExactArgs(1),
+		RunE: fleetRun,
+	}
+	runCmd.Flags().StringSlice("label", nil, "Only agents with this label, key=value (repeatable)")
+	runCmd.Flags().String("group", "", "Only agents in this group")
+	runCmd.Flags().StringSlice("agent", nil, "Only this agent (repeatable)")
+	runCmd.Flags().Bool("all", false, "Run on every agent")
+	runCmd.Flags().Int("parallel", 0, "Agents running it at once (default and at most the core's fan_out.parallelism)")
+	runCmd.Flags().Duration("timeout", time.Minute, "Time each agent may take")
+	runCmd.Flags().Bool("output", false, "Print the output of each agent")
+
+	commandsCmd := &cobra.Command{
+		Use:   "commands [agent]",
+		Short: "List the commands an agent allows",
+		Args:  cobra.ExactArgs(1),
+		RunE:  fleetCommands,
+	}
+
+	fleetCmd.AddCommand(runCmd, commandsCmd)
+	rootCmd.AddCommand(fleetCmd)
+}
+
+func (c *CLI) fleetRun(cmd *cobra.Command, args []string) error {
+	labels, err := labelFlag(cmd, "label")
+	if err != nil {
+		return err
+	}
+	group, _ := cmd.Flags().GetString("group")
+	agents, _ := cmd.Flags().GetStringSlice("agent")
+	all, _ := cmd.Flags().GetBool("all")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	output, _ := cmd.Flags().GetBool("output")
+
+	report, err := c.coreClient.RunFleetCommand(context.Background(), &v1.RunFleetCommandRequest{
+		Command:     args[0],
+		Labels:      labels,
+		Group:       group,
+		AgentIds:    agents,
+		All:         all,
+		Parallelism: int32(parallel),
+		Timeout:     durationpb.New(timeout),
+	})
+	if err != nil {
+		return err
+	}
+	warnMissing(report.AgentErrors)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tEXIT\tDURATION\tOUTPUT")
+	failed := len(report.AgentErrors)
+	for _, r := range report.Results {
+		exit := fmt.Sprint(r.ExitCode)
+		if r.Error != "" {
+			exit = "-"
+		}
+		if r.ExitCode != 0 {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.AgentId, exit, formatElapsed(r.Duration.AsDuration()), orDash(commandSummary(r)))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if output {
+		for _, r := range report.Results {
+			fmt.Printf("\n==> %s <==\n", r.AgentId)
+			if r.Truncated {
+				fmt.Println("[earlier output cut]")
+			}
+			os.Stdout.Write(r.Stdout)
+			if len(r.Stderr) > 0 {
+				fmt.Println("--- stderr")
+				os.Stdout.Write(r.Stderr)
+			}
+		}
+	}
+
+	total := len(report.Results) + len(report.AgentErrors)
+	fmt.Printf("\n%d of %d agent(s) ran %s successfully in %s\n", total-failed, total, report.Command,
+		formatElapsed(report.Duration.AsDuration()))
+	if failed > 0 {
+		return fmt.Errorf("%s failed or could not be run on %d of %d agent(s)", report.Command, failed, total)
+	}
+	return nil
+}
+
+// commandSummary is the error of a run that did not complete, else the
+// last line it printed: to stdout when it succeeded, to stderr when not
+func commandSummary(r *v1.CommandResult) string {
+	line := r.Error
+	if line == "" {
+		outputs := [][]byte{r.Stdout, r.Stderr}
+		if r.ExitCode != 0 {
+			outputs = [][]byte{r.Stderr, r.Stdout}
+		}
+		for _, out := range outputs {
+			if text := strings.TrimSpace(string(out)); text != "" {
+				line = text[strings.LastIndex(text, "\n")+1:]
+				break
+			}
+		}
+	}
+	if runes := []rune(line); len(runes) > maxSummary {
+		line = string(runes[:maxSummary-3]) + "..."
+	}
+	return line
+}
+
+func (c *CLI) fleetCommands(cmd *cobra.Command, args []string) error {
+	resp, err := v1.NewCommandServiceClient(c.conn).ListCommands(context.Background(), &v1.ListCommandsRequest{AgentId: args[0]})
+	if err != nil {
+		return err
+	}
+	if len(resp.Commands) == 0 {
+		fmt.Println("No commands allowed")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTIMEOUT\tDESCRIPTION")
+	for _, command := range resp.Commands {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", command.Name, command.Timeout.AsDuration(), orDash(command.Description))
+	}
+	return w.Flush()
+}
+
+func fleetRun(cmd *cobra.Command, args []string) error {
+	return cli.fleetRun(cmd, args)
+}
+
+func fleetCommands(cmd *cobra.Command, args []string) error {
+	return cli.fleetCommands(cmd, args)
+}
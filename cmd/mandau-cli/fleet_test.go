@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestCommandSummary(t *testing.T) {
+	tests := []struct {
+		result *v1.CommandResult
+		want   string
+	}{
+		{&v1.CommandResult{Stdout: []byte("Filesystem Size\n/dev/sda1 40G\n\n"), Stderr: []byte("warning\n")}, "/dev/sda1 40G"},
+		{&v1.CommandResult{ExitCode: 1, Stdout: []byte("checking\n"), Stderr: []byte("nginx: configuration test failed\n")}, "nginx: configuration test failed"},
+		{&v1.CommandResult{ExitCode: 1, Stdout: []byte("checking\n")}, "checking"},
+		{&v1.CommandResult{ExitCode: -1, Stdout: []byte("started\n"), Error: "timed out after 1m0s"}, "timed out after 1m0s"},
+		{&v1.CommandResult{}, ""},
+	}
+	for _, tt := range tests {
+		if got := commandSummary(tt.result); got != tt.want {
+			t.Errorf("commandSummary(%v) = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+
+	long := commandSummary(&v1.CommandResult{Stdout: []byte(strings.Repeat("é", 100))})
+	if n := len([]rune(long)); n != maxSummary || !strings.HasSuffix(long, "...") {
+		t.Errorf("long line cut to %d runes: %q", n, long)
+	}
+}
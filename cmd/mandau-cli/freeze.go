@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	freezeCmd := &cobra.Command{
+		Use:       "freeze on|off",
+		Short:     "Freeze or unfreeze changes across the cluster",
+		Long:      "While frozen, the core rejects stack applies and removals, label, maintenance and group changes, and queued instructions from everyone without break-glass access",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"on", "off"},
+		RunE:      setFreeze,
+	}
+	freezeCmd.Flags().String("reason", "", "Why changes are frozen (required to freeze)")
+
+	rootCmd.AddCommand(freezeCmd)
+}
+
+func (c *CLI) setFreeze(cmd *cobra.Command, args []string) error {
+	var frozen bool
+	switch args[0] {
+	case "on":
+		frozen = true
+	case "off":
+	default:
+		return fmt.Errorf("expected on or off, got %q", args[0])
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+	if frozen && reason == "" {
+		return fmt.Errorf("--reason is required to freeze the cluster")
+	}
+
+	state, err := c.coreClient.SetFreeze(context.Background(), &v1.SetFreezeRequest{
+		Frozen: frozen,
+		Reason: reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if state.Frozen {
+		fmt.Printf("✓ Cluster frozen: %s\n", state.Reason)
+	} else {
+		fmt.Println("✓ Cluster unfrozen")
+	}
+	return nil
+}
+
+func setFreeze(cmd *cobra.Command, args []string) error {
+	return cli.setFreeze(cmd, args)
+}
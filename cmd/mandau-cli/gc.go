@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// orphanKinds names orphan kinds in gc output
+var orphanKinds = map[v1.OrphanKind]string{
+	v1.OrphanKind_ORPHAN_KIND_STACK_DIR:  "stack-dir",
+	v1.OrphanKind_ORPHAN_KIND_CONTAINERS: "containers",
+	v1.OrphanKind_ORPHAN_KIND_STALE_FILE: "stale-file",
+}
+
+func (c *CLI) collectStackGarbage(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	apply, _ := cmd.Flags().GetBool("apply")
+
+	agents, _, err := c.targetAgents(ctx, cmd, args, 0)
+	if err != nil {
+		return err
+	}
+
+	stackClient := v1.NewStackServiceClient(c.conn)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tKIND\tSTACK\tTARGET\tDETAIL\tRESULT")
+	found, removed := 0, 0
+	for _, agentID := range agents {
+		resp, err := stackClient.CollectStackGarbage(ctx, &v1.CollectStackGarbageRequest{
+			AgentId: agentID,
+			Apply:   apply,
+		})
+		if err != nil {
+			w.Flush()
+			return fmt.Errorf("agent %s: %w", agentID, err)
+		}
+
+		for _, o := range resp.Orphans {
+			found++
+			if o.Removed {
+				removed++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", agentID, orphanKinds[o.Kind], o.StackName,
+				orphanTarget(o), o.Detail, orphanResult(o, resp.Applied))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case found == 0:
+		fmt.Println("\nNothing to collect")
+	case apply:
+		fmt.Printf("\nRemoved %d of %d\n", removed, found)
+	default:
+		fmt.Printf("\nFound %d; run again with --apply to remove them\n", found)
+	}
+
+	if apply && removed < found {
+		return fmt.Errorf("%d could not be removed", found-removed)
+	}
+	return nil
+}
+
+// orphanTarget is the file, directory or containers an orphan is
+func orphanTarget(o *v1.StackOrphan) string {
+	if len(o.ContainerIds) > 0 {
+		return strings.Join(o.ContainerIds, ",")
+	}
+	return o.Path
+}
+
+func orphanResult(o *v1.StackOrphan, applied bool) string {
+	switch {
+	case !applied:
+		return "-"
+	case o.Removed:
+		return "removed"
+	}
+	return "failed: " + o.Error
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestOrphanColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		orphan  *v1.StackOrphan
+		applied bool
+		target  string
+		result  string
+	}{
+		{"reported file", &v1.StackOrphan{Path: "/stacks/web/.env.bak"}, false, "/stacks/web/.env.bak", "-"},
+		{"removed containers", &v1.StackOrphan{ContainerIds: []string{"abc", "def"}, Removed: true}, true, "abc,def", "removed"},
+		{"failed directory", &v1.StackOrphan{Path: "/stacks/old", Error: "permission denied"}, true, "/stacks/old", "failed: permission denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orphanTarget(tt.orphan); got != tt.target {
+				t.Errorf("target = %q, want %q", got, tt.target)
+			}
+			if got := orphanResult(tt.orphan, tt.applied); got != tt.result {
+				t.Errorf("result = %q, want %q", got, tt.result)
+			}
+		})
+	}
+}
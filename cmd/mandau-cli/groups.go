@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create agent group",
+		Long:  "Create a named group of agents selected by agent ID and/or labels",
+		Args:  cobra.ExactArgs(1),
+		RunE:  createGroup,
+	}
+	createCmd.Flags().String("description", "", "Group description")
+	createCmd.Flags().StringSlice("agent", nil, "Member agent ID (repeatable)")
+	createCmd.Flags().StringSlice("selector", nil, "Label selector key=value (repeatable)")
+	groupCmd.AddCommand(createCmd)
+
+	groupCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List agent groups",
+		Args:  cobra.NoArgs,
+		RunE:  listGroups,
+	})
+
+	groupCmd.AddCommand(&cobra.Command{
+		Use:   "show [name]",
+		Short: "Show agent group and its members",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showGroup,
+	})
+
+	updateCmd := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Update agent group",
+		Long:  "Change the description, members or label selector of an agent group",
+		Args:  cobra.ExactArgs(1),
+		RunE:  updateGroup,
+	}
+	updateCmd.Flags().String("description", "", "Group description")
+	updateCmd.Flags().StringSlice("add-agent", nil, "Agent ID to add (repeatable)")
+	updateCmd.Flags().StringSlice("remove-agent", nil, "Agent ID to remove (repeatable)")
+	updateCmd.Flags().StringSlice("selector", nil, "Label selector key=value to merge (repeatable)")
+	updateCmd.Flags().Bool("replace-selector", false, "Replace the selector instead of merging")
+	groupCmd.AddCommand(updateCmd)
+
+	groupCmd.AddCommand(&cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete agent group",
+		Args:  cobra.ExactArgs(1),
+		RunE:  deleteGroup,
+	})
+}
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage agent groups",
+	Long:  "Commands to manage named agent groups used for targeting and RBAC",
+}
+
+func (c *CLI) createGroup(cmd *cobra.Command, args []string) error {
+	description, _ := cmd.Flags().GetString("description")
+	agents, _ := cmd.Flags().GetStringSlice("agent")
+	selector, err := labelFlag(cmd, "selector")
+	if err != nil {
+		return err
+	}
+
+	if len(agents) == 0 && len(selector) == 0 {
+		return fmt.Errorf("a group needs at least one --agent or --selector")
+	}
+
+	group, err := c.coreClient.CreateAgentGroup(context.Background(), &v1.CreateAgentGroupRequest{
+		Group: &v1.AgentGroup{
+			Name:        args[0],
+			Description: description,
+			Agents:      agents,
+			Selector:    selector,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Group %s created\n", group.Name)
+	return nil
+}
+
+func createGroup(cmd *cobra.Command, args []string) error {
+	return cli.createGroup(cmd, args)
+}
+
+func (c *CLI) listGroups(cmd *cobra.Command, args []string) error {
+	resp, err := c.coreClient.ListAgentGroups(context.Background(), &v1.ListAgentGroupsRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %-30s %-30s %s\n", "NAME", "AGENTS", "SELECTOR", "DESCRIPTION")
+	for _, group := range resp.Groups {
+		fmt.Printf("%-20s %-30s %-30s %s\n",
+			group.Name,
+			strings.Join(group.Agents, ","),
+			formatLabels(group.Selector),
+			group.Description,
+		)
+	}
+
+	return nil
+}
+
+func listGroups(cmd *cobra.Command, args []string) error {
+	return cli.listGroups(cmd, args)
+}
+
+func (c *CLI) showGroup(cmd *cobra.Command, args []string) error {
+	resp, err := c.coreClient.GetAgentGroup(context.Background(), &v1.GetAgentGroupRequest{
+		Name: args[0],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name:        %s\n", resp.Group.Name)
+	fmt.Printf("Description: %s\n", resp.Group.Description)
+	fmt.Printf("Agents:      %s\n", strings.Join(resp.Group.Agents, ","))
+	fmt.Printf("Selector:    %s\n", formatLabels(resp.Group.Selector))
+	fmt.Printf("\nMembers:\n")
+	fmt.Printf("%-20s %-30s %-10s\n", "ID", "HOSTNAME", "STATUS")
+	for _, agent := range resp.Members {
+		fmt.Printf("%-20s %-30s %-10s\n", agent.Id, agent.Hostname, agent.Status)
+	}
+
+	return nil
+}
+
+func showGroup(cmd *cobra.Command, args []string) error {
+	return cli.showGroup(cmd, args)
+}
+
+func (c *CLI) updateGroup(cmd *cobra.Command, args []string) error {
+	addAgents, _ := cmd.Flags().GetStringSlice("add-agent")
+	removeAgents, _ := cmd.Flags().GetStringSlice("remove-agent")
+	replaceSelector, _ := cmd.Flags().GetBool("replace-selector")
+	selector, err := labelFlag(cmd, "selector")
+	if err != nil {
+		return err
+	}
+
+	req := &v1.UpdateAgentGroupRequest{
+		Name:            args[0],
+		AddAgents:       addAgents,
+		RemoveAgents:    removeAgents,
+		Selector:        selector,
+		ReplaceSelector: replaceSelector,
+	}
+	if cmd.Flags().Changed("description") {
+		description, _ := cmd.Flags().GetString("description")
+		req.Description = &description
+	}
+
+	group, err := c.coreClient.UpdateAgentGroup(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Group %s updated\n", group.Name)
+	return nil
+}
+
+func updateGroup(cmd *cobra.Command, args []string) error {
+	return cli.updateGroup(cmd, args)
+}
+
+func (c *CLI) deleteGroup(cmd *cobra.Command, args []string) error {
+	_, err := c.coreClient.DeleteAgentGroup(context.Background(), &v1.DeleteAgentGroupRequest{
+		Name: args[0],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Group %s deleted\n", args[0])
+	return nil
+}
+
+func deleteGroup(cmd *cobra.Command, args []string) error {
+	return cli.deleteGroup(cmd, args)
+}
+
+// groupAgents resolves the IDs of all agents in the named group
+func (c *CLI) groupAgents(ctx context.Context, group string) ([]string, error) {
+	resp, err := c.coreClient.ListAgents(ctx, &v1.ListAgentsRequest{Group: group})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Agents) == 0 {
+		return nil, fmt.Errorf("group %s has no agents", group)
+	}
+
+	ids := make([]string, 0, len(resp.Agents))
+	for _, agent := range resp.Agents {
+		ids = append(ids, agent.Id)
+	}
+	return ids, nil
+}
+
+// formatLabels renders a label map as comma separated key=value pairs
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/transport"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	hostCmd := &cobra.Command{
+		Use:   "host",
+		Short: "Inspect agent hosts",
+	}
+
+	portsCmd := &cobra.Command{
+		Use:   "ports [agent]",
+		Short: "List listening ports and the processes and containers owning them",
+		Args:  cobra.ExactArgs(1),
+		RunE:  hostPorts,
+	}
+	portsCmd.Flags().Int("port", 0, "Only show this port")
+
+	psCmd := &cobra.Command{
+		Use:   "ps [agent]",
+		Short: "List processes with their CPU and memory use",
+		Long: "List the processes of the agent host, with CPU use measured over a second. " +
+			"--port shows the processes listening on a port.",
+		Args: cobra.ExactArgs(1),
+		RunE: hostPs,
+	}
+	psCmd.Flags().String("sort", "pid", "Sort by cpu, mem, pid or name")
+	psCmd.Flags().String("name", "", "Only show processes whose name or command contains this")
+	psCmd.Flags().Int("port", 0, "Only show processes listening on this port")
+	psCmd.Flags().Int("limit", 0, "Show at most this many processes")
+
+	killCmd := &cobra.Command{
+		Use:   "kill [agent] [pid]",
+		Short: "Send a signal to a process",
+		Long: "Send a signal to a process on the agent host. The agent refuses init, kernel threads, " +
+			"itself and the processes in security.protected_processes.",
+		Args: cobra.ExactArgs(2),
+		RunE: hostKill,
+	}
+	killCmd.Flags().String("signal", "TERM", "Signal to send: HUP, INT, QUIT, KILL, USR1, USR2, TERM, CONT or STOP")
+
+	logsCmd := &cobra.Command{
+		Use:   "logs [agent] [path]",
+		Short: "Show or follow a host log file",
+		Long: "Print the last lines of a log file on the agent host, such as /var/log/nginx/error.log. " +
+			"Only files allowed by logs.files in the agent config can be read. Without a path the " +
+			"allowed files are listed.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: hostLogs,
+	}
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing lines as they are written")
+	logsCmd.Flags().IntP("lines", "n", 100, "Last lines to print first; -1 prints the whole file")
+	logsCmd.Flags().String("grep", "", "Only print lines matching this regular expression")
+	logsCmd.Flags().Duration("since", 0, "Only print lines stamped within this long ago, e.g. 30m")
+
+	rebootCmd := &cobra.Command{
+		Use:   "reboot [agent]",
+		Short: "Reboot the agent host",
+		Long: "Reboot the agent host through systemd and wait for the agent to register again. " +
+			"--drain refuses stack changes and stops the running stacks first; the agent starts " +
+			"them again once it is back. The whole sequence is one host.reboot operation.",
+		Args: cobra.ExactArgs(1),
+		RunE: hostReboot,
+	}
+	rebootCmd.Flags().Bool("drain", false, "Stop the running stacks before rebooting and start them after")
+	rebootCmd.Flags().String("reason", "", "Reason recorded with the operation")
+	rebootCmd.Flags().Duration("timeout", 15*time.Minute, "How long to wait for the agent to come back")
+
+	shippingCmd := &cobra.Command{
+		Use:   "log-shipping [agent]",
+		Short: "Show how an agent ships logs",
+		Long: "Show the sink logs.ship in the agent config sends to, how many entries were shipped, " +
+			"dropped or are waiting, the last error and the containers and journal followed.",
+		Args: cobra.ExactArgs(1),
+		RunE: hostLogShipping,
+	}
+
+	hostCmd.AddCommand(portsCmd, psCmd, killCmd, logsCmd, rebootCmd, shippingCmd)
+	rootCmd.AddCommand(hostCmd)
+}
+
+func (c *CLI) hostPorts(cmd *cobra.Command, args []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+
+	client := v1.NewPortServiceClient(c.conn)
+	resp, err := client.ListListeningPorts(context.Background(), &v1.ListListeningPortsRequest{AgentId: args[0], Port: int32(port)})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("agent %s cannot list ports: it has no procfs, or predates the port inventory", args[0])
+	}
+	if err != nil {
+		return hostError(err, args[0], "")
+	}
+	if len(resp.Ports) == 0 {
+		if port != 0 {
+			fmt.Printf("Nothing listens on port %d\n", port)
+		} else {
+			fmt.Println("No listening ports")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROTO\tADDRESS\tPORT\tPID\tPROCESS\tCONTAINER\tCONNECTIONS")
+	for _, p := range resp.Ports {
+		pid := "-"
+		if p.Pid > 0 {
+			pid = strconv.Itoa(int(p.Pid))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%d\n", p.Proto, orDash(p.Address), p.Port, pid,
+			orDash(p.Process), orDash(p.Container), p.Connections)
+	}
+	return w.Flush()
+}
+
+func hostPorts(cmd *cobra.Command, args []string) error {
+	return cli.hostPorts(cmd, args)
+}
+
+func (c *CLI) hostPs(cmd *cobra.Command, args []string) error {
+	sortBy, _ := cmd.Flags().GetString("sort")
+	name, _ := cmd.Flags().GetString("name")
+	port, _ := cmd.Flags().GetInt("port")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+
+	client := v1.NewProcessServiceClient(c.conn)
+	resp, err := client.ListProcesses(context.Background(), &v1.ListProcessesRequest{
+		AgentId: args[0],
+		Name:    name,
+		Port:    int32(port),
+		Sort:    sortBy,
+		Limit:   int32(limit),
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("agent %s cannot list processes: it has no procfs, or predates the process inventory", args[0])
+	}
+	if err != nil {
+		return hostError(err, args[0], "")
+	}
+	if len(resp.Processes) == 0 {
+		fmt.Println("No matching processes")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tUSER\tCPU%\tMEM%\tRSS\tSTATE\tCONTAINER\tCOMMAND")
+	for _, p := range resp.Processes {
+		command := p.Command
+		if command == "" {
+			command = "[" + p.Name + "]"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%.1f\t%.1f\t%s\t%s\t%s\t%s\n", p.Pid, p.User, p.CpuPercent, p.MemoryPercent,
+			formatSize(p.MemoryBytes), p.State, orDash(p.Container), command)
+	}
+	return w.Flush()
+}
+
+func hostPs(cmd *cobra.Command, args []string) error {
+	return cli.hostPs(cmd, args)
+}
+
+func (c *CLI) hostKill(cmd *cobra.Command, args []string) error {
+	pid, err := strconv.Atoi(args[1])
+	if err != nil || pid <= 0 {
+		return fmt.Errorf("invalid pid %q", args[1])
+	}
+	signal, _ := cmd.Flags().GetString("signal")
+
+	client := v1.NewProcessServiceClient(c.conn)
+	resp, err := client.SignalProcess(context.Background(), &v1.SignalProcessRequest{
+		AgentId: args[0],
+		Pid:     int32(pid),
+		Signal:  signal,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("agent %s cannot signal processes: it has no procfs, or predates the process inventory", args[0])
+	}
+	if err != nil {
+		return hostError(err, args[0], "")
+	}
+
+	fmt.Printf("✓ %s on %s\n", resp.Status, args[0])
+	return nil
+}
+
+func hostKill(cmd *cobra.Command, args []string) error {
+	return cli.hostKill(cmd, args)
+}
+
+// rebootPollInterval is how often hostReboot asks for the operation while
+// the host is down
+const rebootPollInterval = 5 * time.Second
+
+func (c *CLI) hostReboot(cmd *cobra.Command, args []string) error {
+	agentID := args[0]
+	drain, _ := cmd.Flags().GetBool("drain")
+	reason, _ := cmd.Flags().GetString("reason")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	ctx := context.Background()
+	stream, err := v1.NewHostPowerServiceClient(c.conn).RebootHost(ctx, &v1.RebootHostRequest{
+		AgentId: agentID,
+		Drain:   drain,
+		Reason:  reason,
+	})
+	if err != nil {
+		return hostError(err, agentID, "")
+	}
+
+	p := newProgress(os.Stdout, c.usePlain(), fmt.Sprintf("Rebooting agent %s...", agentID))
+	var last *v1.OperationEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if status.Code(err) == codes.Unimplemented {
+			return p.Done("", fmt.Errorf("agent %s cannot reboot its host: it was not booted by systemd, or predates host reboots", agentID))
+		}
+		if err != nil {
+			return p.Done("", hostError(err, agentID, ""))
+		}
+		p.Event(event)
+		last = event
+	}
+	if last == nil {
+		return p.Done("", fmt.Errorf("agent %s closed the stream before starting the reboot", agentID))
+	}
+	if last.State == v1.OperationState_OPERATION_STATE_FAILED {
+		return p.Done("", nil)
+	}
+
+	// The agent goes down with the host; the operation it resumes once back
+	// says how the reboot went
+	p.Event(&v1.OperationEvent{
+		OperationId: last.OperationId,
+		State:       v1.OperationState_OPERATION_STATE_RUNNING,
+		Message:     fmt.Sprintf("Waiting for agent %s to come back", agentID),
+	})
+	ops := v1.NewOperationsServiceClient(c.conn)
+	seen := last.Message
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(rebootPollInterval)
+		op, err := ops.GetOperation(ctx, &v1.GetOperationRequest{AgentId: agentID, OperationId: last.OperationId})
+		if err != nil {
+			continue // The agent is still down
+		}
+		if op.Message != seen || op.Error != "" {
+			seen = op.Message
+			p.Event(&v1.OperationEvent{OperationId: op.Id, State: op.State, Message: op.Message, Error: op.Error})
+		}
+		switch op.State {
+		case v1.OperationState_OPERATION_STATE_COMPLETED:
+			return p.Done(fmt.Sprintf("Agent %s rebooted", agentID), nil)
+		case v1.OperationState_OPERATION_STATE_FAILED, v1.OperationState_OPERATION_STATE_CANCELLED:
+			if op.Error == "" {
+				return p.Done("", fmt.Errorf("reboot operation %s failed", op.Id))
+			}
+			return p.Done("", nil)
+		}
+	}
+	return p.Done("", fmt.Errorf("agent %s did not finish rebooting within %s; see operation %s in mandau ops list %s",
+		agentID, timeout, last.OperationId, agentID))
+}
+
+func hostReboot(cmd *cobra.Command, args []string) error {
+	return cli.hostReboot(cmd, args)
+}
+
+func (c *CLI) hostLogs(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostLogServiceClient(c.conn)
+	if len(args) == 1 {
+		return c.listLogFiles(client, args[0])
+	}
+
+	follow, _ := cmd.Flags().GetBool("follow")
+	lines, _ := cmd.Flags().GetInt("lines")
+	grep, _ := cmd.Flags().GetString("grep")
+	since, _ := cmd.Flags().GetDuration("since")
+	if lines < -1 || lines == 0 {
+		return fmt.Errorf("invalid --lines %d", lines)
+	}
+	if _, err := regexp.Compile(grep); err != nil {
+		return fmt.Errorf("invalid --grep: %w", err)
+	}
+
+	req := &v1.TailLogFileRequest{
+		AgentId: args[0],
+		Path:    args[1],
+		Lines:   int32(lines),
+		Follow:  follow,
+		Grep:    grep,
+	}
+	if since > 0 {
+		req.Since = timestamppb.New(time.Now().Add(-since))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stream, err := client.TailLogFile(ctx, req)
+	if err != nil {
+		return c.hostLogsError(err, args[0])
+	}
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return c.hostLogsError(err, args[0])
+		}
+		fmt.Println(string(entry.Content))
+	}
+}
+
+func hostLogs(cmd *cobra.Command, args []string) error {
+	return cli.hostLogs(cmd, args)
+}
+
+// listLogFiles prints the log files an agent allows
+func (c *CLI) listLogFiles(client v1.HostLogServiceClient, agentID string) error {
+	resp, err := client.ListLogFiles(context.Background(), &v1.ListLogFilesRequest{AgentId: agentID})
+	if err != nil {
+		return c.hostLogsError(err, agentID)
+	}
+	if len(resp.Files) == 0 {
+		fmt.Println("No log files match logs.files")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSIZE\tMODIFIED")
+	for _, f := range resp.Files {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.Path, formatSize(uint64(f.Size)),
+			f.Modified.AsTime().Local().Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func (c *CLI) hostLogsError(err error, agentID string) error {
+	detail := transport.Detail(err)
+	if status.Code(err) == codes.Unimplemented || (detail != nil && detail.Code == v1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING) {
+		return fmt.Errorf("agent %s serves no log files: set logs.files in its config", agentID)
+	}
+	return hostError(err, agentID, "")
+}
+
+func (c *CLI) hostLogShipping(cmd *cobra.Command, args []string) error {
+	resp, err := v1.NewLogShipServiceClient(c.conn).GetLogShipping(context.Background(), &v1.GetLogShippingRequest{AgentId: args[0]})
+	if err != nil {
+		detail := transport.Detail(err)
+		if status.Code(err) == codes.Unimplemented || (detail != nil && detail.Code == v1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING) {
+			return fmt.Errorf("agent %s ships no logs: set logs.ship in its config", args[0])
+		}
+		return hostError(err, args[0], "")
+	}
+
+	fmt.Printf("Sink:     %s\n", resp.Sink)
+	fmt.Printf("Shipped:  %d\n", resp.Shipped)
+	fmt.Printf("Pending:  %d\n", resp.Pending)
+	fmt.Printf("Dropped:  %d\n", resp.Dropped)
+	if resp.LastShipped != nil {
+		fmt.Printf("Last:     %s\n", resp.LastShipped.AsTime().Local().Format("2006-01-02 15:04:05"))
+	}
+	if resp.LastError != "" {
+		fmt.Printf("Error:    %s (%s)\n", resp.LastError, resp.LastErrorAt.AsTime().Local().Format("2006-01-02 15:04:05"))
+	}
+	if len(resp.Streams) == 0 {
+		fmt.Println("\nNo containers followed")
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STACK\tSERVICE\tSOURCE\tPOSITION")
+	for _, s := range resp.Streams {
+		source := s.Source
+		if len(source) > 12 && s.Stack != "" {
+			source = source[:12]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", orDash(s.Stack), orDash(s.Service), source, orDash(s.Position))
+	}
+	return w.Flush()
+}
+
+func hostLogShipping(cmd *cobra.Command, args []string) error {
+	return cli.hostLogShipping(cmd, args)
+}
+
+// formatSize shows a size in binary units, e.g. 12.5M
+func formatSize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	value, suffix := float64(bytes)/unit, "K"
+	for _, s := range []string{"M", "G", "T"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, s
+	}
+	return fmt.Sprintf("%.1f%s", value, suffix)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func init() {
+	rootCmd.PersistentFlags().String("as", "", "Act as this user, to troubleshoot their permissions; needs the impersonate permission on user:<id> (MANDAU_AS)")
+}
+
+// withImpersonation asks the core to run the call as c.as, if set
+func (c *CLI) withImpersonation(ctx context.Context) context.Context {
+	if c.as == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, transport.ImpersonateHeader, c.as)
+}
+
+func (c *CLI) impersonateUnary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(c.withImpersonation(ctx), method, req, reply, cc, opts...)
+}
+
+func (c *CLI) impersonateStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(c.withImpersonation(ctx), desc, cc, method, opts...)
+}
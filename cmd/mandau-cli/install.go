@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/transport"
+	"github.com/bhangun/mandau/plugins/services/systemd"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultEnrollPort is where the core serves enrollment unless told otherwise
+const defaultEnrollPort = "8445"
+
+func newAgentInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install [core-addr]",
+		Short: "Enroll this host with the core and install the agent",
+		Long: "Run on a new host: generate the agent key, enroll with the core using a join token, " +
+			"write the agent configuration and certificates, then install and start the systemd unit. " +
+			"The core's CA is pinned with --ca-hash (logged by the core at startup) or --ca.",
+		Args: cobra.ExactArgs(1),
+		// There are no client credentials to connect with yet
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              cli.installAgent,
+	}
+	cmd.Flags().String("token", "", "Enrollment token (MANDAU_ENROLL_TOKEN)")
+	cmd.Flags().String("ca-hash", "", "Expected CA hash, sha256:<hex>")
+	cmd.Flags().String("enroll-addr", "", "Enrollment address (default <core host>:"+defaultEnrollPort+")")
+	cmd.Flags().String("id", "", "Agent ID (default agent-<hostname>)")
+	cmd.Flags().String("dir", "/etc/mandau", "Directory for the agent configuration and certificates")
+	cmd.Flags().String("stack-root", "/var/lib/mandau/stacks", "Stack root directory")
+	cmd.Flags().String("listen", ":8444", "Agent listen address")
+	cmd.Flags().String("agent-binary", "/usr/local/bin/mandau-agent", "Installed agent binary")
+	cmd.Flags().StringSlice("label", nil, "Extra agent label (key=value, repeatable)")
+	cmd.Flags().Bool("no-service", false, "Only enroll and write files; do not install the systemd unit")
+	return cmd
+}
+
+func (c *CLI) installAgent(cmd *cobra.Command, args []string) error {
+	coreAddr := args[0]
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("MANDAU_ENROLL_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("--token is required")
+	}
+	caHash, _ := cmd.Flags().GetString("ca-hash")
+	caFile, _ := cmd.Flags().GetString("ca")
+	if caHash == "" && caFile == "" {
+		return fmt.Errorf("pin the core CA with --ca-hash or --ca")
+	}
+	enrollAddr, _ := cmd.Flags().GetString("enroll-addr")
+	if enrollAddr == "" {
+		host, _, err := net.SplitHostPort(coreAddr)
+		if err != nil {
+			return fmt.Errorf("core address: %w", err)
+		}
+		enrollAddr = net.JoinHostPort(host, defaultEnrollPort)
+	}
+	labels, err := labelFlag(cmd, "label")
+	if err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("hostname: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ca, err := fetchPinnedCA(ctx, enrollAddr, caHash, caFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Core CA verified (%s)\n", transport.CAHash(ca))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	if err != nil {
+		return fmt.Errorf("create csr: %w", err)
+	}
+
+	id, _ := cmd.Flags().GetString("id")
+	resp, err := enroll(ctx, enrollAddr, ca, &v1.EnrollRequest{
+		Token:    token,
+		CsrPem:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}),
+		Hostname: hostname,
+		AgentId:  id,
+	})
+	if err != nil {
+		return fmt.Errorf("enroll: %w", err)
+	}
+	fmt.Printf("✓ Enrolled as %s, certificate valid until %s\n",
+		resp.AgentId, resp.ExpiresAt.AsTime().Format(time.RFC3339))
+
+	dir, _ := cmd.Flags().GetString("dir")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	certs := filepath.Join(dir, "certs")
+	keyPath := filepath.Join(certs, "agent.key")
+	certPath := filepath.Join(certs, "agent.crt")
+	caPath := filepath.Join(certs, "ca.crt")
+	cfgPath := filepath.Join(dir, "agent.yaml")
+
+	cfg := config.CreateDefaultAgentConfig()
+	cfg.Agent.ID = resp.AgentId
+	cfg.Agent.Hostname = hostname
+	cfg.Agent.Labels = resp.Labels
+	for k, v := range labels {
+		if cfg.Agent.Labels == nil {
+			cfg.Agent.Labels = map[string]string{}
+		}
+		cfg.Agent.Labels[k] = v
+	}
+	cfg.Server.ListenAddr, _ = cmd.Flags().GetString("listen")
+	cfg.Stacks.RootDir, _ = cmd.Flags().GetString("stack-root")
+	cfg.ServerConnection.CoreAddr = coreAddr
+	for _, tlsCfg := range []*config.TLSConfig{&cfg.Server.TLS, &cfg.ServerConnection.TLS} {
+		tlsCfg.CertPath = certPath
+		tlsCfg.KeyPath = keyPath
+		tlsCfg.CAPath = caPath
+	}
+	cfgData, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		path string
+		data []byte
+		mode os.FileMode
+	}{
+		{keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600},
+		{certPath, resp.CertificatePem, 0644},
+		{caPath, resp.CaPem, 0644},
+		{cfgPath, cfgData, 0600},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.path, f.data, f.mode); err != nil {
+			return fmt.Errorf("write %s: %w", f.path, err)
+		}
+	}
+	fmt.Printf("✓ Wrote configuration to %s\n", cfgPath)
+
+	if noService, _ := cmd.Flags().GetBool("no-service"); noService {
+		return nil
+	}
+
+	binary, _ := cmd.Flags().GetString("agent-binary")
+	if _, err := os.Stat(binary); err != nil {
+		return fmt.Errorf("agent binary: %w", err)
+	}
+	sd := systemd.New()
+	if err := sd.Init(ctx, nil); err != nil {
+		return err
+	}
+	opts := systemd.AgentUnitOptions{
+		Command:     []string{binary, "--config", cfgPath, "--id", resp.AgentId},
+		WatchdogSec: 60,
+		WritePaths:  []string{cfg.Stacks.RootDir, filepath.Clean(cfg.Stacks.RootDir) + ".operations", "/var/lib/mandau"},
+	}
+	if err := sd.InstallAgent(ctx, opts); err != nil {
+		return fmt.Errorf("install service: %w", err)
+	}
+	if err := sd.StartService(ctx, systemd.AgentUnit(opts).Name); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+	fmt.Println("✓ Installed and started mandau-agent.service")
+	return nil
+}
+
+// fetchPinnedCA asks the enrollment listener for the core CA, accepts it
+// only if it matches the pin, and checks the listener's certificate chains
+// to it. Nothing secret is sent before this succeeds.
+func fetchPinnedCA(ctx context.Context, addr, caHash, caFile string) (*x509.Certificate, error) {
+	var serverCerts []*x509.Certificate
+	creds := credentials.NewTLS(&tls.Config{
+		// Verified below, against the pinned CA
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			serverCerts = state.PeerCertificates
+			return nil
+		},
+	})
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := v1.NewEnrollmentServiceClient(conn).GetEnrollmentCA(ctx, &v1.GetEnrollmentCARequest{})
+	if err != nil {
+		return nil, fmt.Errorf("fetch core CA: %w", err)
+	}
+	block, _ := pem.Decode(resp.CaPem)
+	if block == nil {
+		return nil, fmt.Errorf("core sent no CA certificate")
+	}
+	ca, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse core CA: %w", err)
+	}
+
+	if caHash != "" && !transport.MatchCAHash(ca, caHash) {
+		return nil, fmt.Errorf("core CA %s does not match --ca-hash", transport.CAHash(ca))
+	}
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA: %w", err)
+		}
+		if pinned, _ := pem.Decode(data); pinned == nil || string(pinned.Bytes) != string(ca.Raw) {
+			return nil, fmt.Errorf("core CA does not match %s", caFile)
+		}
+	}
+
+	if len(serverCerts) == 0 {
+		return nil, errors.New("core presented no certificate")
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	intermediates := x509.NewCertPool()
+	for _, cert := range serverCerts[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := serverCerts[0].Verify(x509.VerifyOptions{
+		DNSName:       coreServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	}); err != nil {
+		return nil, fmt.Errorf("core certificate is not issued by the pinned CA: %w", err)
+	}
+	return ca, nil
+}
+
+func enroll(ctx context.Context, addr string, ca *x509.Certificate, req *v1.EnrollRequest) (*v1.EnrollResponse, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	creds := credentials.NewTLS(&tls.Config{
+		RootCAs:    roots,
+		ServerName: coreServerName,
+		MinVersion: tls.VersionTLS13,
+	})
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return v1.NewEnrollmentServiceClient(conn).Enroll(ctx, req)
+}
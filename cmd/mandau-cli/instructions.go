@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// newAgentInstructionCmds returns the agent commands that queue work for
+// delivery on heartbeats
+func newAgentInstructionCmds() []*cobra.Command {
+	drainCmd := &cobra.Command{
+		Use:       "drain on|off [agent-id]",
+		Short:     "Stop or resume accepting stack changes on an agent",
+		Long:      "Queue a drain instruction, delivered on the agent's next heartbeat. A draining agent refuses stack applies and removals until drained off.",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"on", "off"},
+		RunE:      cli.drainAgent,
+	}
+	drainCmd.Flags().String("reason", "", "Reason for draining")
+
+	refreshCmd := &cobra.Command{
+		Use:   "refresh [agent-id]",
+		Short: "Ask an agent to reload its configuration",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cli.refreshAgent,
+	}
+	refreshCmd.Flags().String("version", "", "Configuration version the agent should fetch")
+
+	instructionsCmd := &cobra.Command{
+		Use:   "instructions [agent-id]",
+		Short: "List instructions an agent has not yet reported on",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cli.listAgentInstructions,
+	}
+
+	return []*cobra.Command{drainCmd, refreshCmd, instructionsCmd}
+}
+
+func (c *CLI) drainAgent(cmd *cobra.Command, args []string) error {
+	mode, agentID := args[0], args[1]
+	if mode != "on" && mode != "off" {
+		return fmt.Errorf("invalid mode %q, expected on or off", mode)
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+
+	return c.queueInstruction(agentID, &v1.AgentInstruction{
+		Kind: &v1.AgentInstruction_Drain{Drain: &v1.DrainInstruction{Enabled: mode == "on", Reason: reason}},
+	})
+}
+
+func (c *CLI) refreshAgent(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+
+	return c.queueInstruction(args[0], &v1.AgentInstruction{
+		Kind: &v1.AgentInstruction_Config{Config: &v1.ConfigInstruction{Version: version}},
+	})
+}
+
+func (c *CLI) queueInstruction(agentID string, inst *v1.AgentInstruction) error {
+	queued, err := c.coreClient.QueueAgentInstruction(context.Background(), &v1.QueueAgentInstructionRequest{
+		AgentId:     agentID,
+		Instruction: inst,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Queued %s for agent %s; it runs after the agent's next heartbeat\n", instructionSummary(queued), agentID)
+	return nil
+}
+
+func (c *CLI) listAgentInstructions(cmd *cobra.Command, args []string) error {
+	resp, err := c.coreClient.ListAgentInstructions(context.Background(), &v1.ListAgentInstructionsRequest{AgentId: args[0]})
+	if err != nil {
+		return err
+	}
+	if len(resp.Pending) == 0 {
+		fmt.Println("No pending instructions")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tINSTRUCTION\tQUEUED\tBY")
+	for _, inst := range resp.Pending {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", inst.Id, instructionSummary(inst),
+			inst.CreatedAt.AsTime().Local().Format("2006-01-02 15:04:05"), inst.RequestedBy)
+	}
+	return w.Flush()
+}
+
+// instructionSummary describes an instruction in a few words
+func instructionSummary(inst *v1.AgentInstruction) string {
+	switch kind := inst.Kind.(type) {
+	case *v1.AgentInstruction_Config:
+		return "config refresh"
+	case *v1.AgentInstruction_Drain:
+		if kind.Drain.Enabled {
+			return "drain on"
+		}
+		return "drain off"
+	case *v1.AgentInstruction_ApplyStack:
+		return "apply " + kind.ApplyStack.StackName
+	case *v1.AgentInstruction_RemoveStack:
+		return "remove " + kind.RemoveStack.StackId
+	}
+	return "instruction"
+}
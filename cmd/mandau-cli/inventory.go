@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	inventoryCmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Machine-readable inventory of the fleet",
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export what every agent runs, for CMDB ingestion",
+		Long: "Export the agents with their host facts, stacks and the images of their containers with " +
+			"registry digests, nginx virtual hosts, DNS zones and certificates with their expiry. " +
+			"Parts an agent does not serve or you may not read are left out. JSON nests everything " +
+			"under its agent; CSV has one row per item, told apart by the kind column.",
+		Args: cobra.NoArgs,
+		RunE: exportInventory,
+	}
+	exportCmd.Flags().String("format", "json", "Output format: json or csv")
+	exportCmd.Flags().StringP("output", "o", "", "Write to file instead of stdout")
+	exportCmd.Flags().String("group", "", "Only agents in this group")
+	exportCmd.Flags().StringSlice("label", nil, "Only agents with this label, key=value (repeatable)")
+
+	inventoryCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+func (c *CLI) exportInventory(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	group, _ := cmd.Flags().GetString("group")
+	labels, err := labelFlag(cmd, "label")
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown inventory format %q (want json or csv)", format)
+	}
+
+	resp, err := c.coreClient.GetInventory(context.Background(), &v1.GetInventoryRequest{Group: group, Labels: labels})
+	if err != nil {
+		return err
+	}
+	warnMissing(resp.AgentErrors)
+	inv := toInventory(resp)
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := inv.Write(w, format); err != nil {
+		return err
+	}
+
+	if output != "" {
+		fmt.Printf("✓ Inventory of %d agent(s) written to %s\n", len(inv.Agents), output)
+	}
+	return nil
+}
+
+func exportInventory(cmd *cobra.Command, args []string) error {
+	return cli.exportInventory(cmd, args)
+}
+
+// toInventory converts the server inventory for writing
+func toInventory(resp *v1.Inventory) *inventory.Inventory {
+	inv := &inventory.Inventory{
+		GeneratedAt: resp.GeneratedAt.AsTime(),
+		Agents:      make([]inventory.Agent, 0, len(resp.Agents)),
+	}
+	if len(resp.AgentErrors) > 0 {
+		inv.Errors = resp.AgentErrors
+	}
+
+	for _, a := range resp.Agents {
+		agent := inventory.Agent{
+			ID:           a.Agent.GetId(),
+			Hostname:     a.Agent.GetHostname(),
+			Status:       a.Agent.GetStatus(),
+			Labels:       a.Agent.GetLabels(),
+			Groups:       a.Agent.GetGroups(),
+			Capabilities: a.Agent.GetCapabilities(),
+			LastSeen:     a.Agent.GetLastSeen().AsTime(),
+			DNSZones:     a.DnsZones,
+			Errors:       a.Errors,
+		}
+		if h := a.Host; h != nil {
+			agent.Host = &inventory.Host{
+				Hostname:     h.Hostname,
+				OS:           h.Os,
+				Kernel:       h.Kernel,
+				Architecture: h.Architecture,
+				CPUCores:     int(h.CpuCores),
+				MemoryMB:     h.MemoryMb,
+				DiskGB:       h.DiskGb,
+			}
+		}
+		for _, s := range a.Stacks {
+			stack := inventory.Stack{
+				Name:       s.Name,
+				Namespace:  s.Namespace,
+				State:      s.State.String(),
+				Team:       s.GetOwner().GetTeam(),
+				Owner:      s.GetOwner().GetOwner(),
+				Labels:     s.Labels,
+				Containers: make([]inventory.Container, 0, len(s.Containers)),
+			}
+			for _, c := range s.Containers {
+				stack.Containers = append(stack.Containers, inventory.Container{
+					Name:    c.Name,
+					State:   c.State,
+					Image:   c.Image,
+					ImageID: c.ImageId,
+					Digests: c.ImageDigests,
+				})
+			}
+			agent.Stacks = append(agent.Stacks, stack)
+		}
+		for _, v := range a.VirtualHosts {
+			agent.VirtualHosts = append(agent.VirtualHosts, inventory.VirtualHost{Name: v.Name, Enabled: v.Enabled})
+		}
+		for _, cert := range a.Certificates {
+			agent.Certificates = append(agent.Certificates, inventory.Certificate{
+				Domain:    cert.Domain,
+				Issuer:    cert.Issuer,
+				ExpiresAt: cert.ExpiresAt,
+			})
+		}
+		inv.Agents = append(inv.Agents, agent)
+	}
+	return inv
+}
@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	v1 "github.com/bhangun/mandau/api/v1"
 	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/transport"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
@@ -41,6 +46,17 @@ type CLI struct {
 	agentClient v1.AgentServiceClient
 	conn        *grpc.ClientConn
 	config      *config.CoreConfig // For CLI, we can reuse the core config structure
+	namespace   string             // Namespace of stack commands; empty is "default"
+	endpoint    endpoint           // Resolved connection settings, for diagnostics
+	requestID   string             // Sent with every call; empty until connected
+	reads       *readCache         // Listings kept for --cached
+	as          string             // User the core runs calls as, with --as
+}
+
+// endpoint records where and how the CLI connects
+type endpoint struct {
+	server string
+	tls    config.TLSConfig // Resolved paths, with trust settings from the config file
 }
 
 func main() {
@@ -50,6 +66,8 @@ func main() {
 	rootCmd.PersistentFlags().String("cert", "", "Client certificate")
 	rootCmd.PersistentFlags().String("key", "", "Client key")
 	rootCmd.PersistentFlags().String("ca", "", "CA certificate")
+	rootCmd.PersistentFlags().StringP("namespace", "n", "", "Namespace for stack commands (default \"default\", MANDAU_NAMESPACE)")
+	rootCmd.PersistentFlags().String("compression", "", "Call compression: gzip (default), zstd or none (MANDAU_COMPRESSION)")
 
 	// Agent commands
 	agentCmd := &cobra.Command{
@@ -57,11 +75,36 @@ func main() {
 		Short: "Agent management",
 	}
 
-	agentCmd.AddCommand(&cobra.Command{
+	agentListCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all agents",
 		RunE:  cli.listAgents,
-	})
+	}
+	agentListCmd.Flags().StringSlice("label", nil, "Filter by label (key=value, repeatable)")
+	agentListCmd.Flags().String("group", "", "Only list members of this agent group")
+	agentListCmd.Flags().Bool("cached", false, "Show the last listing fetched with these filters instead of asking the core")
+
+	agentLabelCmd := &cobra.Command{
+		Use:   "label [agent-id] [key=value...]",
+		Short: "Set or remove agent labels",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  cli.labelAgent,
+	}
+	agentLabelCmd.Flags().StringSlice("remove", nil, "Label keys to remove")
+
+	agentMaintenanceCmd := &cobra.Command{
+		Use:       "maintenance on|off [agent-id]",
+		Short:     "Toggle agent maintenance mode",
+		Long:      "Mark an agent as under planned downtime: offline alerts are suppressed and non-emergency deploys are rejected until the window expires",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"on", "off"},
+		RunE:      cli.setAgentMaintenance,
+	}
+	agentMaintenanceCmd.Flags().Duration("duration", time.Hour, "How long the maintenance window lasts")
+	agentMaintenanceCmd.Flags().String("reason", "", "Reason for the maintenance window")
+
+	agentCmd.AddCommand(agentListCmd, agentLabelCmd, agentMaintenanceCmd, newAgentInstallCmd(), newAgentPingCmd(), newAgentWatchCmd())
+	agentCmd.AddCommand(newAgentInstructionCmds()...)
 
 	// Stack commands
 	stackCmd := &cobra.Command{
@@ -69,31 +112,126 @@ func main() {
 		Short: "Stack management",
 	}
 
-	stackCmd.AddCommand(&cobra.Command{
+	stackListCmd := &cobra.Command{
 		Use:   "list [agent-id]",
-		Short: "List stacks on agent",
-		Args:  cobra.ExactArgs(1),
+		Short: "List stacks on an agent, a group or every agent",
+		Args:  cobra.MaximumNArgs(1),
 		RunE:  cli.listStacks,
-	})
+	}
+	stackListCmd.Flags().String("group", "", "Target every agent in this group")
+	stackListCmd.Flags().StringSlice("label", nil, "Filter by stack label (key=value, repeatable)")
+	stackListCmd.Flags().StringP("output", "o", "", "Output format: wide adds disk usage (slower)")
+	stackListCmd.Flags().Bool("cached", false, "Show the last listing fetched with these arguments instead of asking the core")
 
-	stackCmd.AddCommand(&cobra.Command{
+	stackApplyCmd := &cobra.Command{
 		Use:   "apply [agent-id] [stack-name] [compose-file]",
 		Short: "Apply stack to agent",
-		Args:  cobra.ExactArgs(3),
+		Args:  cobra.RangeArgs(2, 3),
 		RunE:  cli.applyStack,
-	})
+	}
+	stackApplyCmd.Flags().String("group", "", "Target every agent in this group")
+	stackApplyCmd.Flags().Bool("emergency", false, "Deploy even if the agent is in maintenance (needs the emergency permission)")
+	stackApplyCmd.Flags().String("approval-id", "", "Approved request ID when policy requires approval")
+	stackApplyCmd.Flags().String("idempotency-key", "", "Key that makes a retried apply return the operation of the first attempt")
+	stackApplyCmd.Flags().StringSlice("label", nil, "Stack label (key=value, repeatable); replaces stored labels")
+	stackApplyCmd.Flags().String("team", "", "Owning team")
+	stackApplyCmd.Flags().String("owner", "", "Owning person or contact")
+	stackApplyCmd.Flags().String("ticket", "", "Change ticket reference")
+	stackApplyCmd.Flags().Bool("queue", false, "If the agent is offline, queue the apply for when it comes back")
+	stackApplyCmd.Flags().Duration("queue-ttl", 0, "Drop the queued apply if the agent is not back by then (default set by the core)")
+	stackApplyCmd.Flags().Bool("no-wait", false, "Finish when compose up returns instead of waiting for services to be running and healthy")
+	stackApplyCmd.Flags().Duration("ready-timeout", 0, "How long to wait for services to be ready (default set by the agent)")
+	stackApplyCmd.Flags().String("pre-apply-hook", "", "Shell script run on the agent before compose up; setting a hook replaces both stored hooks")
+	stackApplyCmd.Flags().String("post-apply-hook", "", "Shell script run on the agent once services are ready, e.g. migrations")
+	stackApplyCmd.Flags().Bool("clear-hooks", false, "Remove the stack's stored hooks")
+	stackApplyCmd.Flags().String("images", "", "Image bundle written by docker save to load on the agent before compose up, for agents without registry access; sent through the core's artifact store when it has one")
+	stackApplyCmd.Flags().String("bandwidth", "", "Bytes per second to send the image bundle and stack files at most, e.g. 10M")
+	stackApplyCmd.Flags().StringArray("override", nil, "Compose file merged over the stack's, e.g. compose.prod.yaml (repeatable, merged in order)")
+	stackApplyCmd.Flags().String("project-files", "", "Directory whose configs/ and secrets/ files and dotenv variants such as .env.prod are applied with the compose file, replacing those stored")
+	stackApplyCmd.Flags().String("files", "", "Directory of files the compose file uses, e.g. configs; only files the agent lacks are sent, and files an earlier apply sent but the directory no longer has are removed")
+
+	stackRemoveCmd := &cobra.Command{
+		Use:   "remove [agent-id] [stack-name]",
+		Short: "Remove stack from agent",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  cli.removeStack,
+	}
+	stackRemoveCmd.Flags().String("group", "", "Target every agent in this group")
+	stackRemoveCmd.Flags().Bool("emergency", false, "Remove even if the agent is in maintenance (needs the emergency permission)")
+	stackRemoveCmd.Flags().String("approval-id", "", "Approved request ID when policy requires approval")
+	stackRemoveCmd.Flags().String("idempotency-key", "", "Key that makes a retried removal return the operation of the first attempt")
+	stackRemoveCmd.Flags().Bool("queue", false, "If the agent is offline, queue the removal for when it comes back")
+	stackRemoveCmd.Flags().Duration("queue-ttl", 0, "Drop the queued removal if the agent is not back by then (default set by the core)")
+
+	stackCmd.AddCommand(stackListCmd, stackApplyCmd, stackRemoveCmd)
 
-	stackCmd.AddCommand(&cobra.Command{
+	stackLogsCmd := &cobra.Command{
 		Use:   "logs [agent-id] [stack-name]",
-		Short: "Stream stack logs",
-		Args:  cobra.ExactArgs(2),
-		RunE:  cli.stackLogs,
-	})
+		Short: "Stream or search stack logs",
+		Long: "Follow the logs of a stack's containers, or with --since, --until, --grep or --tail print " +
+			"the entries matching and exit; -f follows after them. Agents with logs.index enabled " +
+			"search the logs they keep, which outlive container restarts; others search what Docker " +
+			"still has for the current containers.",
+		Args: cobra.ExactArgs(2),
+		RunE: cli.stackLogs,
+	}
+	stackLogsCmd.Flags().BoolP("follow", "f", false, "Keep printing entries as they are written (the default without search flags)")
+	stackLogsCmd.Flags().Duration("since", 0, "Only entries within this long ago, e.g. 2h")
+	stackLogsCmd.Flags().Duration("until", 0, "Only entries older than this, e.g. 30m")
+	stackLogsCmd.Flags().String("grep", "", "Only entries matching this regular expression")
+	stackLogsCmd.Flags().IntP("tail", "n", 0, "Only the last this many entries (default 1000 without --since)")
+	stackCmd.AddCommand(stackLogsCmd)
+
+	stackExportCmd := &cobra.Command{
+		Use:   "export [agent-id] stack-name",
+		Short: "Export a stack's compose file, .env, labels and state",
+		Long: "Write what is stored for a stack and how it runs as one YAML document, or as a " +
+			"tarball of compose.yaml, .env and stack.yaml, for backup, review or applying it on " +
+			"another agent. Secret values are masked unless --reveal-secrets is given, which needs " +
+			"write permission on the stack. Without an agent ID the core finds the agent running it.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: cli.exportStack,
+	}
+	stackExportCmd.Flags().String("format", "yaml", "Output format: yaml or tar (gzipped)")
+	stackExportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	stackExportCmd.Flags().Bool("reveal-secrets", false, "Keep secret values instead of masking them")
+	stackCmd.AddCommand(stackExportCmd)
+
+	stackEventsCmd := &cobra.Command{
+		Use:   "events [agent-id] stack-name",
+		Short: "Show what happened to a stack",
+		Long: "Print a stack's timeline, oldest first: applies with what they changed, scaling, " +
+			"removals, crashes, restarts, health changes and renewals of certificates for the " +
+			"domains in its \"domains\" label. Timelines outlive removed stacks, whose agent must " +
+			"be given. Without an agent ID the core finds the agent running the stack.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: cli.stackEvents,
+	}
+	stackEventsCmd.Flags().Duration("since", 0, "Only events within this long ago, e.g. 24h")
+	stackEventsCmd.Flags().StringSlice("kind", nil, "Only these kinds: apply, remove, scale, crash, oom, restart, unhealthy, healthy, certificate, external")
+	stackEventsCmd.Flags().IntP("limit", "n", 0, "Only the last this many events (default 100)")
+	stackCmd.AddCommand(stackEventsCmd)
+
+	stackGCCmd := &cobra.Command{
+		Use:   "gc [agent-id]",
+		Short: "Find, and with --apply remove, what stacks left behind on an agent",
+		Long: "Report stack directories without containers, containers of stacks whose directory " +
+			"is gone and stale backup or .env files. Stacks with an operation under way and anything " +
+			"changed within the last hour are left alone. --apply removes what is found and needs " +
+			"delete permission on all of the agent's stacks.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: cli.collectStackGarbage,
+	}
+	stackGCCmd.Flags().String("group", "", "Target every agent in this group")
+	stackGCCmd.Flags().Bool("apply", false, "Remove what is found instead of only reporting it")
+	stackCmd.AddCommand(stackGCCmd)
 
 	rootCmd.AddCommand(agentCmd, stackCmd)
 
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		printError(os.Stderr, err, cli.requestID)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -164,6 +302,11 @@ func (c *CLI) connect(cmd *cobra.Command) error {
 		return err
 	}
 
+	c.namespace, err = c.getFlagOrEnv(cmd, "namespace", "MANDAU_NAMESPACE", "")
+	if err != nil {
+		return err
+	}
+
 	// If config was loaded, use values from config as defaults if not provided via CLI/env
 	if c.config != nil {
 		// Only use config values if command-line flags/environment variables were not explicitly set
@@ -190,36 +333,57 @@ func (c *CLI) connect(cmd *cobra.Command) error {
 		}
 	}
 
+	tlsCfg := config.TLSConfig{}
+	if c.config != nil {
+		tlsCfg = c.config.Server.TLS
+	}
+	tlsCfg.CertPath, tlsCfg.KeyPath, tlsCfg.CAPath = certFile, keyFile, caFile
+	c.endpoint = endpoint{server: serverAddr, tls: tlsCfg}
+
 	if certFile == "" || keyFile == "" {
 		return fmt.Errorf("client certificate required (MANDAU_CERT, MANDAU_KEY)")
 	}
 
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	tlsConfig, err := transport.ClientConfig(tlsCfg, coreServerName)
 	if err != nil {
-		return fmt.Errorf("load cert: %w", err)
+		return err
 	}
 
-	// Load CA certificate to verify server certificate
-	caCert, err := ioutil.ReadFile(caFile)
+	creds := credentials.NewTLS(tlsConfig)
+
+	compression, err := c.getFlagOrEnv(cmd, "compression", "MANDAU_COMPRESSION", "")
 	if err != nil {
-		return fmt.Errorf("load CA cert: %w", err)
+		return err
+	}
+	compress, err := transport.CompressionOption(compression)
+	if err != nil {
+		return err
+	}
+
+	reason, err := c.getFlagOrEnv(cmd, "reason", "MANDAU_REASON", "")
+	if err != nil {
+		return err
+	}
+	mfaToken, err := c.getFlagOrEnv(cmd, "mfa-token", "MANDAU_MFA_TOKEN", "")
+	if err != nil {
+		return err
 	}
+	obligations := newObligations(reason, mfaToken)
 
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return fmt.Errorf("parse CA cert")
+	if err := c.initRequestID(cmd); err != nil {
+		return err
 	}
 
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool,
-		ServerName:   "mandau-core", // Use the server name from the certificate
-		MinVersion:   tls.VersionTLS13,
+	if c.as, err = c.getFlagOrEnv(cmd, "as", "MANDAU_AS", ""); err != nil {
+		return err
 	}
 
-	creds := credentials.NewTLS(tlsConfig)
+	cached, _ := cmd.Flags().GetBool("cached")
+	c.reads = newReadCache(serverAddr, certFile, c.as, cached)
 
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(creds), compress,
+		grpc.WithChainUnaryInterceptor(c.reads.unary, c.impersonateUnary, obligations.unary, c.requestIDUnary),
+		grpc.WithChainStreamInterceptor(c.impersonateStream, obligations.stream, c.requestIDStream))
 	if err != nil {
 		return fmt.Errorf("dial: %w", err)
 	}
@@ -257,118 +421,554 @@ func (c *CLI) getFlagOrEnv(cmd *cobra.Command, flagName, envName, defaultValue s
 func (c *CLI) listAgents(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	resp, err := c.coreClient.ListAgents(ctx, &v1.ListAgentsRequest{})
+	selector, err := labelFlag(cmd, "label")
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%-20s %-30s %-10s %-20s\n", "ID", "HOSTNAME", "STATUS", "LAST SEEN")
+	group, _ := cmd.Flags().GetString("group")
+
+	resp, err := c.coreClient.ListAgents(ctx, &v1.ListAgentsRequest{
+		Labels: selector,
+		Group:  group,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %-30s %-16s %-20s %-10s %s\n", "ID", "HOSTNAME", "STATUS", "LAST SEEN", "CIRCUIT", "GROUPS")
 	for _, agent := range resp.Agents {
-		fmt.Printf("%-20s %-30s %-10s %-20s\n",
+		status := agent.Status
+		if agent.Maintenance != nil {
+			status += " (maint)"
+		}
+		fmt.Printf("%-20s %-30s %-16s %-20s %-10s %s\n",
 			agent.Id,
 			agent.Hostname,
-			agent.Status,
+			status,
 			agent.LastSeen.AsTime().Format("2006-01-02 15:04:05"),
+			orDash(agent.Circuit.GetState()),
+			strings.Join(agent.Groups, ","),
 		)
 	}
 
+	// Host service plugins enabled on an agent whose host cannot run them
+	for _, agent := range resp.Agents {
+		for _, p := range agent.UnavailablePlugins {
+			fmt.Printf("%s: %s unavailable: %s\n", agent.Id, p.Plugin, p.Reason)
+		}
+	}
+	c.reads.annotate(os.Stderr)
+
 	return nil
 }
 
-func (c *CLI) listStacks(cmd *cobra.Command, args []string) error {
+func (c *CLI) labelAgent(cmd *cobra.Command, args []string) error {
 	agentID := args[0]
 	ctx := context.Background()
 
-	stackClient := v1.NewStackServiceClient(c.conn)
+	set, err := parseLabels(args[1:])
+	if err != nil {
+		return err
+	}
+
+	remove, err := cmd.Flags().GetStringSlice("remove")
+	if err != nil {
+		return err
+	}
 
-	resp, err := stackClient.ListStacks(ctx, &v1.ListStacksRequest{
+	if len(set) == 0 && len(remove) == 0 {
+		return fmt.Errorf("nothing to do: pass key=value pairs or --remove")
+	}
+
+	resp, err := c.coreClient.UpdateAgentLabels(ctx, &v1.UpdateAgentLabelsRequest{
 		AgentId: agentID,
+		Set:     set,
+		Remove:  remove,
 	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%-20s %-15s %-10s %s\n", "NAME", "STATE", "CONTAINERS", "PATH")
-	for _, stack := range resp.Stacks {
-		fmt.Printf("%-20s %-15s %-10d %s\n",
-			stack.Name,
-			stack.State.String(),
-			len(stack.Containers),
-			stack.Path,
-		)
+	fmt.Printf("Labels for agent %s:\n", resp.Agent.Id)
+	for k, v := range resp.Agent.Labels {
+		fmt.Printf("  %s=%s\n", k, v)
+	}
+
+	return nil
+}
+
+func (c *CLI) setAgentMaintenance(cmd *cobra.Command, args []string) error {
+	mode := args[0]
+	agentID := args[1]
+
+	if mode != "on" && mode != "off" {
+		return fmt.Errorf("invalid mode %q, expected on or off", mode)
+	}
+
+	duration, _ := cmd.Flags().GetDuration("duration")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	resp, err := c.coreClient.SetAgentMaintenance(context.Background(), &v1.SetAgentMaintenanceRequest{
+		AgentId:  agentID,
+		Enabled:  mode == "on",
+		Duration: durationpb.New(duration),
+		Reason:   reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if m := resp.Agent.Maintenance; m != nil {
+		fmt.Printf("✓ Agent %s in maintenance until %s\n", agentID, m.Until.AsTime().Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Printf("✓ Agent %s out of maintenance\n", agentID)
+	}
+	return nil
+}
+
+// labelFlag parses a key=value string slice flag into a label map
+func labelFlag(cmd *cobra.Command, name string) (map[string]string, error) {
+	values, err := cmd.Flags().GetStringSlice(name)
+	if err != nil {
+		return nil, fmt.Errorf("get flag %s: %w", name, err)
+	}
+	return parseLabels(values)
+}
+
+// parseLabels converts key=value pairs into a label map
+func parseLabels(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// targetAgents resolves the agents a command acts on: every member of the
+// --group flag when set, otherwise the leading agent-id argument. The
+// remaining positional arguments are returned and must number want.
+func (c *CLI) targetAgents(ctx context.Context, cmd *cobra.Command, args []string, want int) ([]string, []string, error) {
+	group, _ := cmd.Flags().GetString("group")
+	if group != "" {
+		if len(args) != want {
+			return nil, nil, fmt.Errorf("expected %d arguments with --group, got %d", want, len(args))
+		}
+		agents, err := c.groupAgents(ctx, group)
+		return agents, args, err
+	}
+
+	if len(args) != want+1 {
+		return nil, nil, fmt.Errorf("expected agent-id and %d more arguments, or --group", want)
+	}
+	return args[:1], args[1:], nil
+}
+
+func (c *CLI) listStacks(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Without an agent or group every agent is listed by the core at once
+	agents := []string{""}
+	if group, _ := cmd.Flags().GetString("group"); group != "" || len(args) > 0 {
+		var err error
+		if agents, _, err = c.targetAgents(ctx, cmd, args, 0); err != nil {
+			return err
+		}
+	}
+
+	selector, err := labelFlag(cmd, "label")
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != "" && output != "wide" {
+		return fmt.Errorf("unknown output %q: want wide", output)
+	}
+	wide := output == "wide"
+
+	stackClient := v1.NewStackServiceClient(c.conn)
+
+	if wide {
+		fmt.Printf("%-20s %-15s %-20s %-15s %-10s %-15s %-22s %-12s %-12s %s\n", "AGENT", "NAMESPACE", "NAME", "STATE", "CONTAINERS", "TEAM", "DISK", "VOLUMES", "IMAGES", "LABELS")
+	} else {
+		fmt.Printf("%-20s %-15s %-20s %-15s %-10s %-15s %s\n", "AGENT", "NAMESPACE", "NAME", "STATE", "CONTAINERS", "TEAM", "LABELS")
+	}
+	missing := make(map[string]string)
+	for _, agentID := range agents {
+		resp, err := stackClient.ListStacks(ctx, &v1.ListStacksRequest{
+			AgentId:   agentID,
+			Labels:    selector,
+			Namespace: c.namespace,
+			DiskUsage: wide,
+		})
+		if err != nil {
+			return fmt.Errorf("agent %s: %w", agentID, err)
+		}
+		for id, msg := range resp.AgentErrors {
+			missing[id] = msg
+		}
+
+		for _, stack := range resp.Stacks {
+			team := stack.GetOwner().GetTeam()
+			if team == "" {
+				team = "-"
+			}
+			owner := agentID
+			if stack.AgentId != "" {
+				owner = stack.AgentId
+			}
+			if wide {
+				disk, volumes, images := formatDiskUsage(stack.DiskUsage)
+				fmt.Printf("%-20s %-15s %-20s %-15s %-10d %-15s %-22s %-12s %-12s %s\n",
+					owner,
+					stack.Namespace,
+					stack.Name,
+					stack.State.String(),
+					len(stack.Containers),
+					team,
+					disk,
+					volumes,
+					images,
+					formatLabels(stack.Labels),
+				)
+				continue
+			}
+			fmt.Printf("%-20s %-15s %-20s %-15s %-10d %-15s %s\n",
+				owner,
+				stack.Namespace,
+				stack.Name,
+				stack.State.String(),
+				len(stack.Containers),
+				team,
+				formatLabels(stack.Labels),
+			)
+		}
 	}
+	warnMissing(missing)
+	c.reads.annotate(os.Stderr)
 
 	return nil
 }
 
+// formatDiskUsage renders a stack's disk usage for wide listings: the
+// usage counted against the agent's limit, then volumes and images alone
+func formatDiskUsage(u *v1.StackDiskUsage) (disk, volumes, images string) {
+	if u == nil {
+		return "-", "-", "-"
+	}
+	total := u.DirectoryBytes + u.VolumeBytes + u.ContainerBytes
+	return usageOf(quota.FormatMemory(total), quota.FormatMemory(u.LimitBytes), u.LimitBytes == 0),
+		quota.FormatMemory(u.VolumeBytes),
+		quota.FormatMemory(u.ImageBytes)
+}
+
 func (c *CLI) applyStack(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	stackName := args[1]
-	composeFile := args[2]
+	ctx := context.Background()
+
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 2)
+	if err != nil {
+		return err
+	}
+	stackName := rest[0]
+	composeFile := rest[1]
 
 	content, err := os.ReadFile(composeFile)
 	if err != nil {
 		return fmt.Errorf("read compose file: %w", err)
 	}
 
-	ctx := context.Background()
-	stackClient := v1.NewStackServiceClient(c.conn)
+	emergency, _ := cmd.Flags().GetBool("emergency")
+	approvalID, _ := cmd.Flags().GetString("approval-id")
+	idempotencyKey, _ := cmd.Flags().GetString("idempotency-key")
+	group, _ := cmd.Flags().GetString("group")
+	queue, queueTTL := queueFlags(cmd)
+	noWait, _ := cmd.Flags().GetBool("no-wait")
+	var readyTimeout *durationpb.Duration
+	if d, _ := cmd.Flags().GetDuration("ready-timeout"); d > 0 {
+		readyTimeout = durationpb.New(d)
+	}
 
-	stream, err := stackClient.ApplyStack(ctx, &v1.ApplyStackRequest{
-		AgentId:        agentID,
-		StackName:      stackName,
-		ComposeContent: string(content),
-	})
+	labels, err := labelFlag(cmd, "label")
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Applying stack %s to agent %s...\n", stackName, agentID)
+	hooks, err := hookFlags(cmd)
+	if err != nil {
+		return err
+	}
 
+	images, _ := cmd.Flags().GetString("images")
+	if images != "" && queue {
+		return fmt.Errorf("--images cannot be combined with --queue: bundles are only sent to online agents")
+	}
+	overrideFiles, _ := cmd.Flags().GetStringArray("override")
+	overrides, err := composeOverrides(overrideFiles)
+	if err != nil {
+		return err
+	}
+	var project *v1.StackContents
+	if dir, _ := cmd.Flags().GetString("project-files"); dir != "" {
+		files, err := projectFiles(dir)
+		if err != nil {
+			return err
+		}
+		project = &v1.StackContents{Files: files}
+	}
+
+	filesDir, _ := cmd.Flags().GetString("files")
+	if filesDir != "" && queue {
+		return fmt.Errorf("--files cannot be combined with --queue: files are only sent to online agents")
+	}
+	var files []*v1.StackFile
+	if filesDir != "" {
+		if files, err = stackFileManifest(filesDir, composeFile); err != nil {
+			return err
+		}
+	}
+	limit, err := bandwidthFlag(cmd)
+	if err != nil {
+		return err
+	}
+	// With an artifact store the bundle goes to the core once and each
+	// agent fetches it; without one it is uploaded to each agent
+	var bundle string
+	if images != "" {
+		a, err := c.pushArtifact(ctx, images, "image-bundle", "", false, limit)
+		switch {
+		case status.Code(err) == codes.FailedPrecondition:
+		case err != nil:
+			return err
+		default:
+			bundle = a.Digest
+		}
+	}
+
+	// Only send ownership when a flag was given so re-applies keep it
+	var owner *v1.StackOwner
+	if cmd.Flags().Changed("team") || cmd.Flags().Changed("owner") || cmd.Flags().Changed("ticket") {
+		owner = &v1.StackOwner{}
+		owner.Team, _ = cmd.Flags().GetString("team")
+		owner.Owner, _ = cmd.Flags().GetString("owner")
+		owner.Ticket, _ = cmd.Flags().GetString("ticket")
+	}
+
+	for _, agentID := range agents {
+		req := &v1.ApplyStackRequest{
+			AgentId:        agentID,
+			StackName:      stackName,
+			ComposeContent: string(content),
+			Emergency:      emergency,
+			ApprovalId:     approvalID,
+			Labels:         labels,
+			Owner:          owner,
+			Namespace:      c.namespace,
+			Group:          group,
+			Queue:          queue,
+			QueueTtl:       queueTTL,
+			IdempotencyKey: idempotencyKey,
+			NoWait:         noWait,
+			ReadyTimeout:   readyTimeout,
+			Hooks:          hooks,
+
+			ComposeOverrides: overrides,
+			ProjectFiles:     project,
+		}
+		if images != "" {
+			target := &v1.TransferTarget{Kind: v1.TransferKind_TRANSFER_KIND_IMAGE_BUNDLE, StackName: stackName, Namespace: c.namespace}
+			var st *v1.TransferStatus
+			if bundle != "" {
+				st, err = c.fetch(ctx, agentID, target, bundle, emergency)
+			} else {
+				st, err = c.upload(ctx, agentID, target, images, limit, emergency)
+			}
+			if err != nil {
+				return fmt.Errorf("agent %s: %w", agentID, err)
+			}
+			fmt.Printf("✓ Loaded %d image(s): %s\n", len(st.Images), strings.Join(st.Images, ", "))
+		}
+		if filesDir != "" {
+			if err := c.syncStackFiles(ctx, agentID, stackName, filesDir, files, limit, emergency); err != nil {
+				return fmt.Errorf("agent %s: %w", agentID, err)
+			}
+			req.Files = &v1.StackFiles{Files: files}
+		}
+		if err := c.applyStackToAgent(ctx, req); err != nil {
+			return fmt.Errorf("agent %s: %w", agentID, err)
+		}
+	}
+
+	return nil
+}
+
+// hookFlags reads the hook scripts named by --pre-apply-hook and
+// --post-apply-hook. Without either, or --clear-hooks, the stored hooks are
+// kept.
+func hookFlags(cmd *cobra.Command) (*v1.StackHooks, error) {
+	clearHooks, _ := cmd.Flags().GetBool("clear-hooks")
+	pre, _ := cmd.Flags().GetString("pre-apply-hook")
+	post, _ := cmd.Flags().GetString("post-apply-hook")
+	if clearHooks && (pre != "" || post != "") {
+		return nil, fmt.Errorf("--clear-hooks cannot be combined with hook scripts")
+	}
+	if !clearHooks && pre == "" && post == "" {
+		return nil, nil
+	}
+
+	hooks := &v1.StackHooks{}
+	for _, h := range []struct {
+		file   string
+		script *string
+	}{{pre, &hooks.PreApply}, {post, &hooks.PostApply}} {
+		if h.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(h.file)
+		if err != nil {
+			return nil, fmt.Errorf("read hook: %w", err)
+		}
+		*h.script = string(data)
+	}
+	return hooks, nil
+}
+
+func (c *CLI) applyStackToAgent(ctx context.Context, req *v1.ApplyStackRequest) error {
+	stackClient := v1.NewStackServiceClient(c.conn)
+
+	stream, err := stackClient.ApplyStack(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	p := newProgress(os.Stdout, c.usePlain(), fmt.Sprintf("Applying stack %s to agent %s...", req.StackName, req.AgentId))
 	for {
 		event, err := stream.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("stream error: %w", err)
+			return p.Done("", fmt.Errorf("stream error: %w", err))
+		}
+		p.Event(event)
+	}
+
+	if p.Queued() {
+		return p.Done("Stack apply queued", nil)
+	}
+	return p.Done("Stack applied successfully", nil)
+}
+
+func (c *CLI) removeStack(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 1)
+	if err != nil {
+		return err
+	}
+	stackName := rest[0]
+
+	emergency, _ := cmd.Flags().GetBool("emergency")
+	approvalID, _ := cmd.Flags().GetString("approval-id")
+	idempotencyKey, _ := cmd.Flags().GetString("idempotency-key")
+	group, _ := cmd.Flags().GetString("group")
+	queue, queueTTL := queueFlags(cmd)
+
+	stackClient := v1.NewStackServiceClient(c.conn)
+	for _, agentID := range agents {
+		stream, err := stackClient.RemoveStack(ctx, &v1.RemoveStackRequest{
+			AgentId:    agentID,
+			StackId:    stackName,
+			Emergency:  emergency,
+			ApprovalId: approvalID,
+			Namespace:  c.namespace,
+			Group:      group,
+			Queue:      queue,
+			QueueTtl:   queueTTL,
+
+			IdempotencyKey: idempotencyKey,
+		})
+		if err != nil {
+			return fmt.Errorf("agent %s: %w", agentID, err)
 		}
 
-		if event.Message != "" {
-			fmt.Printf("  → %s\n", event.Message)
+		p := newProgress(os.Stdout, c.usePlain(), fmt.Sprintf("Removing stack %s from agent %s...", stackName, agentID))
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return p.Done("", fmt.Errorf("agent %s: stream error: %w", agentID, err))
+			}
+			p.Event(event)
 		}
-		if event.Progress > 0 {
-			fmt.Printf("  [%d%%]\n", event.Progress)
+		success := "Stack removed successfully"
+		if p.Queued() {
+			success = "Stack removal queued"
 		}
-		if event.Error != "" {
-			fmt.Printf("  ✗ Error: %s\n", event.Error)
+		if err := p.Done(success, nil); err != nil {
+			return fmt.Errorf("agent %s: %w", agentID, err)
 		}
 	}
 
-	fmt.Println("✓ Stack applied successfully")
 	return nil
 }
 
 func (c *CLI) stackLogs(cmd *cobra.Command, args []string) error {
 	agentID := args[0]
 	stackName := args[1]
+	follow, _ := cmd.Flags().GetBool("follow")
+	since, _ := cmd.Flags().GetDuration("since")
+	until, _ := cmd.Flags().GetDuration("until")
+	grep, _ := cmd.Flags().GetString("grep")
+	tail, _ := cmd.Flags().GetInt("tail")
+	if since < 0 || until < 0 || tail < 0 {
+		return fmt.Errorf("--since, --until and --tail cannot be negative")
+	}
+	if until > 0 && follow {
+		return fmt.Errorf("--until cannot be combined with --follow")
+	}
+	if !cmd.Flags().Changed("follow") {
+		follow = since == 0 && until == 0 && grep == "" && tail == 0
+	}
+
+	req := &v1.GetStackLogsRequest{
+		AgentId:   agentID,
+		StackName: stackName,
+		Follow:    follow,
+		Namespace: c.namespace,
+		Grep:      grep,
+		Tail:      int32(tail),
+	}
+	now := time.Now()
+	if since > 0 {
+		req.Since = timestamppb.New(now.Add(-since))
+	}
+	if until > 0 {
+		req.Until = timestamppb.New(now.Add(-until))
+	}
 
 	ctx := context.Background()
 	stackClient := v1.NewStackServiceClient(c.conn)
 
-	stream, err := stackClient.GetStackLogs(ctx, &v1.GetStackLogsRequest{
-		AgentId:   agentID,
-		StackName: stackName,
-		Follow:    true,
-	})
+	stream, err := stackClient.GetStackLogsBatched(ctx, req)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Streaming logs for stack %s...\n", stackName)
+	layout := "2006-01-02 15:04:05"
+	if follow {
+		layout = "15:04:05"
+		fmt.Printf("Streaming logs for stack %s...\n", stackName)
+	}
 
 	for {
-		entry, err := stream.Recv()
+		batch, err := stream.Recv()
 		if err == io.EOF {
 			break
 		}
@@ -376,8 +976,10 @@ func (c *CLI) stackLogs(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("stream error: %w", err)
 		}
 
-		timestamp := entry.Timestamp.AsTime().Format("15:04:05")
-		fmt.Printf("[%s] [%s] %s\n", timestamp, entry.ServiceName, string(entry.Content))
+		for _, entry := range batch.Entries {
+			timestamp := entry.Timestamp.AsTime().Local().Format(layout)
+			fmt.Printf("[%s] [%s] %s\n", timestamp, entry.ServiceName, string(entry.Content))
+		}
 	}
 
 	return nil
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/marketplace"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	indexCmd := &cobra.Command{
+		Use:               "index",
+		Short:             "Create signing keys and sign plugin indexes",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	keygenCmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create a key pair for signing plugin indexes",
+		Long:  "Writes the private key to --out and prints the public key, which agents list in plugins.marketplace.trusted_keys",
+		Args:  cobra.NoArgs,
+		RunE:  generateIndexKey,
+	}
+	keygenCmd.Flags().String("out", "plugin-index.key", "File to write the private key to")
+
+	signCmd := &cobra.Command{
+		Use:   "sign <index.yaml>",
+		Short: "Sign a plugin index for the core to host",
+		Args:  cobra.ExactArgs(1),
+		RunE:  signIndex,
+	}
+	signCmd.Flags().String("key", "plugin-index.key", "Private key from \"mandau plugins index keygen\"")
+	signCmd.Flags().StringP("output", "o", "", "File to write the signed index to (default stdout)")
+
+	indexCmd.AddCommand(keygenCmd, signCmd)
+
+	installCmd := &cobra.Command{
+		Use:   "install <agent-id> <name>[@version]",
+		Short: "Install a plugin from the core's index on an agent",
+		Long:  "The agent downloads the plugin, checks it against the index and loads it. The index must be signed by a key the agent trusts.",
+		Args:  cobra.ExactArgs(2),
+		RunE:  installPlugin,
+	}
+
+	pluginsCmd.AddCommand(indexCmd, installCmd,
+		&cobra.Command{
+			Use:   "search [text]",
+			Short: "List the plugins in the core's index",
+			Args:  cobra.MaximumNArgs(1),
+			RunE:  searchPlugins,
+		},
+		&cobra.Command{
+			Use:   "installed [agent-id]",
+			Short: "List the plugins installed on agents",
+			Args:  cobra.MaximumNArgs(1),
+			RunE:  listInstalledPlugins,
+		},
+		&cobra.Command{
+			Use:   "describe <agent-id> <name>",
+			Short: "Show a plugin running on an agent and the host access it is allowed",
+			Args:  cobra.ExactArgs(2),
+			RunE:  describePlugin,
+		},
+	)
+}
+
+func generateIndexKey(cmd *cobra.Command, args []string) error {
+	out, _ := cmd.Flags().GetString("out")
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+
+	pub, priv, err := marketplace.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, []byte(priv+"\n"), 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Private key written to %s\n", out)
+	fmt.Printf("Public key: %s\n", pub)
+	return nil
+}
+
+func signIndex(cmd *cobra.Command, args []string) error {
+	keyFile, _ := cmd.Flags().GetString("key")
+	output, _ := cmd.Flags().GetString("output")
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var index marketplace.Index
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return fmt.Errorf("parse %s: %w", args[0], err)
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+
+	signed, err := marketplace.Sign(&index, string(key))
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Println(string(signed))
+		return nil
+	}
+	if err := os.WriteFile(output, append(signed, '\n'), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Signed index of %d plugin(s) written to %s\n", len(index.Plugins), output)
+	return nil
+}
+
+func (c *CLI) searchPlugins(cmd *cobra.Command, args []string) error {
+	index, err := c.coreClient.GetPluginIndex(context.Background(), &v1.GetPluginIndexRequest{})
+	if err != nil {
+		return err
+	}
+
+	var filter string
+	if len(args) > 0 {
+		filter = strings.ToLower(args[0])
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLATEST\tVERSIONS\tPLATFORMS\tDESCRIPTION")
+	for _, p := range index.Plugins {
+		if filter != "" && !strings.Contains(strings.ToLower(p.Name+" "+p.Description), filter) {
+			continue
+		}
+		latest := "-"
+		if len(p.Versions) > 0 {
+			latest = p.Versions[0]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", p.Name, latest, len(p.Versions),
+			strings.Join(p.Platforms, ","), p.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nIndex signed with key %s", index.KeyId)
+	if index.Published != nil {
+		fmt.Printf(", published %s", index.Published.AsTime().Local().Format(statusTimeFormat))
+	}
+	fmt.Println()
+	return nil
+}
+
+func searchPlugins(cmd *cobra.Command, args []string) error {
+	return cli.searchPlugins(cmd, args)
+}
+
+func (c *CLI) installPlugin(cmd *cobra.Command, args []string) error {
+	name, version, _ := strings.Cut(args[1], "@")
+
+	installed, err := c.coreClient.InstallPlugin(context.Background(), &v1.InstallPluginRequest{
+		AgentId: args[0],
+		Name:    name,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Installed %s %s on %s (sha256 %s)\n", installed.Name, installed.Version, args[0], installed.Sha256)
+	if !installed.Loaded {
+		fmt.Println("  An earlier version is running; restart the agent to load this one")
+	}
+	return nil
+}
+
+func installPlugin(cmd *cobra.Command, args []string) error {
+	return cli.installPlugin(cmd, args)
+}
+
+func (c *CLI) listInstalledPlugins(cmd *cobra.Command, args []string) error {
+	req := &v1.ListInstalledPluginsRequest{}
+	if len(args) > 0 {
+		req.AgentId = args[0]
+	}
+	resp, err := c.coreClient.ListInstalledPlugins(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tNAME\tVERSION\tSTATE\tINSTALLED")
+	for _, p := range resp.Plugins {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.AgentId, p.Name, p.Version, installedState(p),
+			p.InstalledAt.AsTime().Local().Format(statusTimeFormat))
+	}
+	return w.Flush()
+}
+
+func listInstalledPlugins(cmd *cobra.Command, args []string) error {
+	return cli.listInstalledPlugins(cmd, args)
+}
+
+func (c *CLI) describePlugin(cmd *cobra.Command, args []string) error {
+	desc, err := c.coreClient.DescribePlugin(context.Background(), &v1.DescribePluginRequest{
+		AgentId: args[0],
+		Name:    args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name:         %s\n", desc.Name)
+	fmt.Printf("Version:      %s\n", desc.Version)
+	fmt.Printf("Agent:        %s\n", desc.AgentId)
+	fmt.Printf("Source:       %s\n", desc.Source)
+	fmt.Printf("Capabilities: %s\n", orNone(strings.Join(desc.Capabilities, ", ")))
+	if !desc.Sandboxed {
+		fmt.Println("Permissions:  not sandboxed; the plugin has the agent's access")
+		return nil
+	}
+	perms := desc.Permissions
+	fmt.Println("Permissions:")
+	fmt.Printf("  Exec:    %s\n", orNone(strings.Join(perms.GetExec(), ", ")))
+	fmt.Printf("  Write:   %s\n", orNone(strings.Join(perms.GetWrite(), ", ")))
+	fmt.Printf("  Network: %s\n", orNone(strings.Join(perms.GetNetwork(), ", ")))
+	return nil
+}
+
+func describePlugin(cmd *cobra.Command, args []string) error {
+	return cli.describePlugin(cmd, args)
+}
+
+// installedState tells whether an installed plugin is running
+func installedState(p *v1.InstalledPlugin) string {
+	switch {
+	case !p.Enabled:
+		return "disabled"
+	case p.Loaded:
+		return "loaded"
+	default:
+		return "not loaded"
+	}
+}
+
+// orNone shows an empty list as "none"
+func orNone(list string) string {
+	if list == "" {
+		return "none"
+	}
+	return list
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bhangun/mandau/pkg/obligation"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func init() {
+	rootCmd.PersistentFlags().String("reason", "", "Reason recorded with the operation, for policies requiring one (MANDAU_REASON)")
+	rootCmd.PersistentFlags().String("mfa-token", "", "Second-factor token, for policies requiring MFA (MANDAU_MFA_TOKEN)")
+}
+
+// obligations sends a reason and MFA token with every call and, on a
+// terminal, prompts for them when the core refuses a call for lacking one
+type obligations struct {
+	mu          sync.Mutex
+	reason      string
+	mfaToken    string
+	interactive bool
+	in          *bufio.Reader
+	out         io.Writer
+}
+
+func newObligations(reason, mfaToken string) *obligations {
+	info, err := os.Stdin.Stat()
+	return &obligations{
+		reason:      reason,
+		mfaToken:    mfaToken,
+		interactive: err == nil && info.Mode()&os.ModeCharDevice != 0,
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stderr,
+	}
+}
+
+// outgoing attaches what the caller has supplied so far to ctx
+func (o *obligations) outgoing(ctx context.Context) context.Context {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.reason != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, obligation.ReasonHeader, o.reason)
+	}
+	if o.mfaToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, obligation.MFAHeader, o.mfaToken)
+	}
+	return ctx
+}
+
+// satisfy asks the user for what err reports missing and reports whether
+// the call is worth retrying
+func (o *obligations) satisfy(err error) bool {
+	unmet := obligation.Unmet(err)
+	if len(unmet) == 0 {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.interactive {
+		fmt.Fprintln(o.out, "Hint: pass --reason and --mfa-token to satisfy the policy")
+		return false
+	}
+
+	for _, name := range unmet {
+		switch name {
+		case plugin.ObligationReason:
+			o.reason = o.ask("Reason for this operation: ")
+			if o.reason == "" {
+				return false
+			}
+		case plugin.ObligationMFA:
+			o.mfaToken = o.ask("MFA token: ")
+			if o.mfaToken == "" {
+				return false
+			}
+		default:
+			// Recording is configured on the core, not by the caller
+			return false
+		}
+	}
+	return true
+}
+
+func (o *obligations) ask(prompt string) string {
+	fmt.Fprint(o.out, prompt)
+	line, _ := o.in.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// unary retries a call once the user satisfied its obligations
+func (o *obligations) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(o.outgoing(ctx), method, req, reply, cc, opts...)
+	if o.satisfy(err) {
+		err = invoker(o.outgoing(ctx), method, req, reply, cc, opts...)
+	}
+	return err
+}
+
+// stream opens streams that replay their request once the user satisfied
+// the obligations the core refused them for
+func (o *obligations) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	open := func() (grpc.ClientStream, error) {
+		return streamer(o.outgoing(ctx), desc, cc, method, opts...)
+	}
+	cs, err := open()
+	if err != nil {
+		return nil, err
+	}
+	return &obligationStream{ClientStream: cs, obligations: o, open: open}, nil
+}
+
+// obligationStream records what is sent until the first response, so a
+// stream the core refused before answering can be opened again
+type obligationStream struct {
+	grpc.ClientStream
+	obligations *obligations
+	open        func() (grpc.ClientStream, error)
+	sent        []interface{}
+	closed      bool
+	answered    bool
+}
+
+func (s *obligationStream) SendMsg(m interface{}) error {
+	if !s.answered {
+		s.sent = append(s.sent, m)
+	}
+	err := s.ClientStream.SendMsg(m)
+	if err == io.EOF && !s.answered {
+		// The stream ended early; RecvMsg reports why
+		return nil
+	}
+	return err
+}
+
+func (s *obligationStream) CloseSend() error {
+	s.closed = true
+	return s.ClientStream.CloseSend()
+}
+
+func (s *obligationStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.answered = true
+		return nil
+	}
+	if s.answered || !s.obligations.satisfy(err) {
+		return err
+	}
+
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	return s.ClientStream.RecvMsg(m)
+}
+
+// Header waits for the core to accept the stream. Callers sending a lot
+// before the first response, like image bundles, wait for it first so that
+// what they send is not kept for a replay. A stream the core refused is
+// reopened as in RecvMsg.
+func (s *obligationStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if md == nil && err == nil && !s.answered {
+		// The stream ended without headers; its status says why
+		if err := s.ClientStream.RecvMsg(&emptypb.Empty{}); s.obligations.satisfy(err) {
+			if err := s.reopen(); err != nil {
+				return nil, err
+			}
+			md, err = s.ClientStream.Header()
+		}
+	}
+	if md != nil {
+		s.answered = true
+		s.sent = nil
+	}
+	return md, err
+}
+
+// reopen opens the stream again and replays what was sent on it
+func (s *obligationStream) reopen() error {
+	cs, err := s.open()
+	if err != nil {
+		return err
+	}
+	s.ClientStream = cs
+	s.answered = true
+	for _, sent := range s.sent {
+		if err := cs.SendMsg(sent); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if s.closed {
+		return cs.CloseSend()
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	rootCmd.AddCommand(opsCmd)
+
+	pendingCmd := &cobra.Command{
+		Use:   "pending",
+		Short: "List operations queued for agents",
+		Args:  cobra.NoArgs,
+		RunE:  listPendingOps,
+	}
+	pendingCmd.Flags().String("agent", "", "Only show operations queued for this agent")
+	opsCmd.AddCommand(pendingCmd)
+
+	listCmd := &cobra.Command{
+		Use:   "list [agent-id]",
+		Short: "List the operations an agent has run",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listOps,
+	}
+	listCmd.Flags().String("key", "", "Only show the operation started with this idempotency key")
+	opsCmd.AddCommand(listCmd)
+
+	opsCmd.AddCommand(&cobra.Command{
+		Use:   "cancel [agent-id] [operation-id]",
+		Short: "Cancel a queued operation the agent has not received yet",
+		Args:  cobra.ExactArgs(2),
+		RunE:  cancelPendingOp,
+	})
+}
+
+var opsCmd = &cobra.Command{
+	Use:   "ops",
+	Short: "Manage operations",
+	Long:  "Commands for the operations agents run and those the core holds for offline and dial-out agents until their next heartbeat",
+}
+
+// queueFlags reads the --queue and --queue-ttl flags of a stack change
+func queueFlags(cmd *cobra.Command) (bool, *durationpb.Duration) {
+	queue, _ := cmd.Flags().GetBool("queue")
+	ttl, _ := cmd.Flags().GetDuration("queue-ttl")
+	if !queue || ttl == 0 {
+		return queue, nil
+	}
+	return true, durationpb.New(ttl)
+}
+
+func (c *CLI) listPendingOps(cmd *cobra.Command, args []string) error {
+	agentID, _ := cmd.Flags().GetString("agent")
+
+	resp, err := c.coreClient.ListAgentInstructions(context.Background(), &v1.ListAgentInstructionsRequest{AgentId: agentID})
+	if err != nil {
+		return err
+	}
+	if len(resp.Pending) == 0 {
+		fmt.Println("No pending operations")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAGENT\tOPERATION\tQUEUED\tEXPIRES\tBY")
+	for _, inst := range resp.Pending {
+		expires := "never"
+		if inst.ExpiresAt != nil {
+			expires = inst.ExpiresAt.AsTime().Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", inst.Id, inst.AgentId, instructionSummary(inst),
+			inst.CreatedAt.AsTime().Local().Format("2006-01-02 15:04:05"), expires, inst.RequestedBy)
+	}
+	return w.Flush()
+}
+
+func listPendingOps(cmd *cobra.Command, args []string) error {
+	return cli.listPendingOps(cmd, args)
+}
+
+func (c *CLI) listOps(cmd *cobra.Command, args []string) error {
+	key, _ := cmd.Flags().GetString("key")
+
+	resp, err := v1.NewOperationsServiceClient(c.conn).ListOperations(context.Background(), &v1.ListOperationsRequest{
+		AgentId:        args[0],
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Operations) == 0 {
+		fmt.Println("No operations")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tSTATE\tSTACK\tKEY\tREQUEST\tCREATED")
+	for _, op := range resp.Operations {
+		state := strings.ToLower(strings.TrimPrefix(op.State.String(), "OPERATION_STATE_"))
+		if op.Error != "" {
+			state += ": " + op.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", op.Id, op.Type, state, op.Metadata["stack"],
+			op.IdempotencyKey, orDash(op.RequestId), op.CreatedAt.AsTime().Local().Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func listOps(cmd *cobra.Command, args []string) error {
+	return cli.listOps(cmd, args)
+}
+
+func (c *CLI) cancelPendingOp(cmd *cobra.Command, args []string) error {
+	inst, err := c.coreClient.CancelAgentInstruction(context.Background(), &v1.CancelAgentInstructionRequest{
+		AgentId:       args[0],
+		InstructionId: args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Cancelled %s for agent %s\n", instructionSummary(inst), inst.AgentId)
+	return nil
+}
+
+func cancelPendingOp(cmd *cobra.Command, args []string) error {
+	return cli.cancelPendingOp(cmd, args)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func newAgentPingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ping [agent-id]",
+		Short: "Diagnose the connection between the core and an agent",
+		Long: "Measure round trips from the core to an agent, check the certificates both present " +
+			"(expiry and the name they are verified against), have the agent dial the core back and " +
+			"report the state of the core's channel to it, with a remediation for every problem.",
+		Args: cobra.ExactArgs(1),
+		RunE: cli.pingAgent,
+	}
+	cmd.Flags().IntP("count", "c", 5, "Round trips to measure (at most 100)")
+	return cmd
+}
+
+func (c *CLI) pingAgent(cmd *cobra.Command, args []string) error {
+	count, _ := cmd.Flags().GetInt("count")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute+time.Duration(count)*5*time.Second)
+	defer cancel()
+
+	report, err := c.coreClient.DiagnoseAgent(ctx, &v1.DiagnoseAgentRequest{AgentId: args[0], Pings: int32(count)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(pingSummary(report))
+	fmt.Println()
+	return printChecks(report.Checks)
+}
+
+// pingSummary is the headline of a connection report: where the agent is,
+// how its channel stands and how long round trips take
+func pingSummary(r *v1.AgentConnectionReport) string {
+	address := orDash(r.Address)
+	if r.DialOutOnly {
+		address = "dial-out only"
+	}
+	s := fmt.Sprintf("PING %s (%s): %s, last heartbeat %s ago\n", r.AgentId, address, r.Status,
+		time.Since(r.LastHeartbeat.AsTime()).Round(time.Second))
+	if r.ChannelState != "" {
+		s += fmt.Sprintf("channel %s\n", r.ChannelState)
+	}
+	if rt := r.RoundTrips; rt != nil {
+		s += fmt.Sprintf("%d sent, %d failed", rt.Sent, rt.Failed)
+		if rt.Avg != nil {
+			s += fmt.Sprintf(", round trip min/avg/max = %s/%s/%s",
+				rt.Min.AsDuration().Round(time.Microsecond), rt.Avg.AsDuration().Round(time.Microsecond), rt.Max.AsDuration().Round(time.Microsecond))
+		}
+		s += "\n"
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/hostprofile"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Standardize hosts on a golden host profile",
+		Long: "A host profile lists the packages, kernel parameters, users, firewall baseline, Docker " +
+			"packages and agent labels a kind of host should have. Applying it changes only what " +
+			"differs, so it can be applied again at any time; diff shows what differs without " +
+			"changing anything. Agents need the host-environment plugin, and the firewall plugin " +
+			"for profiles with a firewall section.",
+	}
+
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "check [profile]",
+		Short: "Validate a profile and show what it sets",
+		Args:  cobra.ExactArgs(1),
+		RunE:  checkProfile,
+		// Checking only reads the profile
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	})
+
+	diffCmd := &cobra.Command{
+		Use:   "diff [agent-id] [profile]",
+		Short: "Show how hosts differ from a profile",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  diffProfile,
+	}
+	diffCmd.Flags().String("group", "", "Compare every agent in the group")
+	diffCmd.Flags().Bool("all", false, "Also list the checks that pass")
+
+	applyCmd := &cobra.Command{
+		Use:   "apply [agent-id] [profile]",
+		Short: "Converge hosts on a profile",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  applyProfile,
+	}
+	applyCmd.Flags().String("group", "", "Apply to every agent in the group, one at a time")
+	applyCmd.Flags().Bool("force", false, "Replace sysctl.d and authorized_keys files edited outside Mandau")
+
+	profileCmd.AddCommand(diffCmd, applyCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+func (c *CLI) checkProfile(cmd *cobra.Command, args []string) error {
+	p, err := hostprofile.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Profile %s is valid\n", p.Name)
+	fmt.Printf("  Packages:       %d\n", len(p.Packages))
+	fmt.Printf("  Sysctls:        %d\n", len(p.Sysctls))
+	fmt.Printf("  Users:          %d\n", len(p.Users))
+	if p.Firewall != nil {
+		fmt.Printf("  Firewall rules: %d (rule set profile-%s)\n", len(p.Firewall.Rules), p.Name)
+	}
+	if p.Docker != nil {
+		fmt.Printf("  Docker:         %d package(s)\n", len(p.Docker.Packages))
+	}
+	fmt.Printf("  Agent labels:   %d\n", len(p.Agent.Labels))
+	return nil
+}
+
+func (c *CLI) diffProfile(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 1)
+	if err != nil {
+		return err
+	}
+	p, err := hostprofile.Load(rest[0])
+	if err != nil {
+		return err
+	}
+	all, _ := cmd.Flags().GetBool("all")
+
+	conv := c.converger()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tKIND\tNAME\tWANT\tHAVE\tSTATE")
+	compliant := 0
+	for _, agentID := range agents {
+		report, err := conv.Diff(ctx, p, agentID)
+		if err != nil {
+			w.Flush()
+			return hostError(err, agentID, profilePlugins)
+		}
+		if report.Compliant() {
+			compliant++
+		}
+		for _, check := range report.Checks {
+			if check.OK && !all {
+				continue
+			}
+			state := "DRIFT"
+			if check.OK {
+				state = "ok"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", agentID, check.Kind, check.Name, orDash(check.Want), orDash(check.Have), state)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d of %d agent(s) comply with profile %s\n", compliant, len(agents), p.Name)
+	return nil
+}
+
+func (c *CLI) applyProfile(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 1)
+	if err != nil {
+		return err
+	}
+	p, err := hostprofile.Load(rest[0])
+	if err != nil {
+		return err
+	}
+
+	conv := c.converger()
+	conv.Force, _ = cmd.Flags().GetBool("force")
+	conv.OnChange = func(agentID string, check hostprofile.Check) {
+		fmt.Printf("  → %s: %s %s (%s, want %s)\n", agentID, check.Kind, check.Name, orDash(check.Have), orDash(check.Want))
+	}
+
+	failed := 0
+	for _, agentID := range agents {
+		fmt.Printf("Applying profile %s to agent %s...\n", p.Name, agentID)
+		report, err := conv.Apply(ctx, p, agentID)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("✗ %s: %v\n", agentID, hostError(err, agentID, profilePlugins))
+		case !report.Compliant():
+			failed++
+			fmt.Printf("✗ %s still differs from the profile in %d item(s); see mandau profile diff\n", agentID, len(report.Drift()))
+		case len(report.Changed) == 0:
+			fmt.Printf("✓ %s already complies\n", agentID)
+		default:
+			fmt.Printf("✓ %s complies after %d change(s)\n", agentID, len(report.Changed))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d agent(s) do not comply with profile %s", failed, len(agents), p.Name)
+	}
+	return nil
+}
+
+// profilePlugins are the agent plugins a profile is applied through
+const profilePlugins = "host-environment or firewall"
+
+// converger returns a profile converger calling through the core
+func (c *CLI) converger() *hostprofile.Converger {
+	return hostprofile.NewConverger(v1.NewHostEnvironmentServiceClient(c.conn), v1.NewFirewallServiceClient(c.conn), c.coreClient)
+}
+
+func checkProfile(cmd *cobra.Command, args []string) error {
+	return cli.checkProfile(cmd, args)
+}
+
+func diffProfile(cmd *cobra.Command, args []string) error {
+	return cli.diffProfile(cmd, args)
+}
+
+func applyProfile(cmd *cobra.Command, args []string) error {
+	return cli.applyProfile(cmd, args)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+)
+
+func init() {
+	rootCmd.PersistentFlags().Bool("plain", false, "Plain progress output without colors or spinners (MANDAU_PLAIN)")
+}
+
+const (
+	ansiReset     = "\033[0m"
+	ansiRed       = "\033[31m"
+	ansiGreen     = "\033[32m"
+	ansiDim       = "\033[2m"
+	ansiClearLine = "\r\033[K"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progress renders the events of a streaming operation as named steps. On a
+// terminal the current step shows a spinner, percentage and elapsed time;
+// in plain mode every step is one line, suitable for CI logs.
+type progress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	plain bool
+	now   func() time.Time
+
+	start     time.Time
+	step      string
+	stepStart time.Time
+	percent   int32
+	frame     int
+	failed    bool
+	state     v1.OperationState
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// usePlain reports whether progress should be rendered without terminal
+// control sequences: when requested, or when stdout is not a terminal
+func (c *CLI) usePlain() bool {
+	if rootCmd.PersistentFlags().Changed("plain") {
+		plain, _ := rootCmd.PersistentFlags().GetBool("plain")
+		return plain
+	}
+	if os.Getenv("MANDAU_PLAIN") != "" || os.Getenv("NO_COLOR") != "" || os.Getenv("CI") != "" {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	return err != nil || info.Mode()&os.ModeCharDevice == 0
+}
+
+// newProgress prints title and starts rendering an operation
+func newProgress(out io.Writer, plain bool, title string) *progress {
+	p := &progress{out: out, plain: plain, now: time.Now, stop: make(chan struct{})}
+	p.start = p.now()
+	fmt.Fprintln(out, title)
+
+	if !plain {
+		p.wg.Add(1)
+		go p.spin()
+	}
+	return p
+}
+
+func (p *progress) spin() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame++
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the current step line. Callers hold mu.
+func (p *progress) draw() {
+	if p.plain || p.step == "" {
+		return
+	}
+	line := fmt.Sprintf("%s %s", spinnerFrames[p.frame%len(spinnerFrames)], p.step)
+	if p.percent > 0 {
+		line += fmt.Sprintf(" [%d%%]", p.percent)
+	}
+	fmt.Fprintf(p.out, "%s  %s %s%s%s", ansiClearLine, line, ansiDim, formatElapsed(p.now().Sub(p.stepStart)), ansiReset)
+}
+
+// Event renders one operation event: a message starts a new step, progress
+// updates the percentage and an error fails the current step
+func (p *progress) Event(event *v1.OperationEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state = event.State
+	if event.Message != "" && event.Message != p.step {
+		p.finishStep()
+		p.step = event.Message
+		p.stepStart = p.now()
+		p.percent = 0
+		if p.plain {
+			fmt.Fprintf(p.out, "  → %s\n", p.step)
+		}
+	}
+
+	if event.Progress > 0 && event.Progress != p.percent {
+		p.percent = event.Progress
+		if p.plain {
+			fmt.Fprintf(p.out, "    [%d%%]\n", p.percent)
+		}
+	}
+
+	if event.Error != "" {
+		p.failed = true
+		p.clearLine()
+		fmt.Fprintf(p.out, "  %s %s\n", p.color(ansiRed, "✗"), p.color(ansiRed, "Error: "+event.Error))
+	}
+
+	p.draw()
+}
+
+// finishStep marks the current step done with its duration. Callers hold mu.
+func (p *progress) finishStep() {
+	if p.step == "" || p.plain {
+		return
+	}
+	p.clearLine()
+	fmt.Fprintf(p.out, "  %s %s %s\n", p.color(ansiGreen, "✓"), p.step,
+		p.color(ansiDim, formatElapsed(p.now().Sub(p.stepStart))))
+}
+
+func (p *progress) clearLine() {
+	if !p.plain {
+		fmt.Fprint(p.out, ansiClearLine)
+	}
+}
+
+func (p *progress) color(code, s string) string {
+	if p.plain {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// Queued reports whether the stream ended with the operation still pending,
+// as it does when the core queues it for an unreachable agent
+func (p *progress) Queued() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == v1.OperationState_OPERATION_STATE_PENDING
+}
+
+// Done stops rendering and prints the outcome: success if err is nil and no
+// event reported an error
+func (p *progress) Done(success string, err error) error {
+	close(p.stop)
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := formatElapsed(p.now().Sub(p.start))
+	if err != nil || p.failed {
+		p.clearLine()
+		if err == nil {
+			err = fmt.Errorf("operation reported errors")
+		}
+		fmt.Fprintf(p.out, "%s (%s)\n", p.color(ansiRed, "✗ "+err.Error()), elapsed)
+		return err
+	}
+
+	p.finishStep()
+	fmt.Fprintf(p.out, "✓ %s (%s)\n", success, elapsed)
+	return nil
+}
+
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
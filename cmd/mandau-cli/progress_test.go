@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestProgressPlain(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []*v1.OperationEvent
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "steps and progress",
+			events: []*v1.OperationEvent{
+				{Message: "Pulling images"},
+				{Message: "Pulling images", Progress: 50},
+				{Message: "Starting containers", Progress: 100},
+			},
+			want: "Applying...\n  → Pulling images\n    [50%]\n  → Starting containers\n    [100%]\n✓ done (0.0s)\n",
+		},
+		{
+			name:    "error event fails the operation",
+			events:  []*v1.OperationEvent{{Message: "Starting"}, {Error: "port in use"}},
+			want:    "Applying...\n  → Starting\n  ✗ Error: port in use\n✗ operation reported errors (0.0s)\n",
+			wantErr: true,
+		},
+		{
+			name:    "stream error",
+			err:     errors.New("stream error: EOF"),
+			want:    "Applying...\n✗ stream error: EOF (0.0s)\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := newProgress(&out, true, "Applying...")
+			p.now = func() time.Time { return p.start }
+			for _, event := range tt.events {
+				p.Event(event)
+			}
+			err := p.Done("done", tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Done() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+
+	quotaCmd.AddCommand(&cobra.Command{
+		Use:   "show [agent-id]",
+		Short: "Show quota usage versus limits",
+		Long: "Show stacks and containers per agent and reservations per namespace " +
+			"against the configured quotas. --namespace limits the namespace table.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: showQuota,
+	})
+}
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Resource quotas",
+	Long:  "Commands to inspect agent and namespace quotas",
+}
+
+func (c *CLI) showQuota(cmd *cobra.Command, args []string) error {
+	req := &v1.GetQuotaUsageRequest{Namespace: c.namespace}
+	if len(args) > 0 {
+		req.AgentId = args[0]
+	}
+
+	usage, err := c.coreClient.GetQuotaUsage(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %-15s %s\n", "AGENT", "STACKS", "CONTAINERS")
+	for _, a := range usage.Agents {
+		fmt.Printf("%-20s %-15s %s\n",
+			a.AgentId,
+			usageOf(strconv.Itoa(int(a.Stacks)), strconv.Itoa(int(a.MaxStacks)), a.MaxStacks == 0),
+			usageOf(strconv.Itoa(int(a.Containers)), strconv.Itoa(int(a.MaxContainers)), a.MaxContainers == 0),
+		)
+	}
+
+	fmt.Println()
+	fmt.Printf("%-20s %-15s %-20s %s\n", "NAMESPACE", "STACKS", "CPUS", "MEMORY")
+	for _, ns := range usage.Namespaces {
+		fmt.Printf("%-20s %-15s %-20s %s\n",
+			ns.Namespace,
+			usageOf(strconv.Itoa(int(ns.Stacks)), strconv.Itoa(int(ns.MaxStacks)), ns.MaxStacks == 0),
+			usageOf(fmt.Sprintf("%.2f", ns.Cpus), fmt.Sprintf("%.2f", ns.MaxCpus), ns.MaxCpus == 0),
+			usageOf(quota.FormatMemory(ns.MemoryBytes), quota.FormatMemory(ns.MaxMemoryBytes), ns.MaxMemoryBytes == 0),
+		)
+	}
+
+	return nil
+}
+
+func showQuota(cmd *cobra.Command, args []string) error {
+	return cli.showQuota(cmd, args)
+}
+
+// usageOf renders "used/limit", or "used/-" when there is no limit
+func usageOf(used, limit string, unlimited bool) string {
+	if unlimited {
+		limit = "-"
+	}
+	return used + "/" + limit
+}
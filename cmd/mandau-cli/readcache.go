@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// cachedReads are the calls whose responses are kept for --cached
+var cachedReads = map[string]bool{
+	v1.CoreService_ListAgents_FullMethodName:  true,
+	v1.StackService_ListStacks_FullMethodName: true,
+}
+
+// readCache keeps the last response of each cached read, per core, client
+// certificate, impersonated user and request, so listings can be shown while the core is
+// down. With offline set, cached reads are answered from the cache alone.
+type readCache struct {
+	dir     string // Empty when there is nowhere to keep responses
+	scope   string // Core address, client certificate and impersonated user
+	offline bool
+	oldest  time.Time // Of the cached responses used
+}
+
+// cachedRead is a response as stored
+type cachedRead struct {
+	Method   string          `json:"method"`
+	SavedAt  time.Time       `json:"saved_at"`
+	Response json.RawMessage `json:"response"`
+}
+
+// newReadCache returns the cache for responses of server to the holder of
+// certFile acting as user, kept under MANDAU_CACHE_DIR or the user cache
+// directory
+func newReadCache(server, certFile, as string, offline bool) *readCache {
+	dir := os.Getenv("MANDAU_CACHE_DIR")
+	if dir == "" {
+		if base, err := os.UserCacheDir(); err == nil {
+			dir = filepath.Join(base, "mandau")
+		}
+	}
+	if dir != "" {
+		dir = filepath.Join(dir, "reads")
+	}
+	return &readCache{dir: dir, scope: server + "\x00" + certFile + "\x00" + as, offline: offline}
+}
+
+// unary keeps the responses of cached reads, or answers them from the cache
+// when offline
+func (r *readCache) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !cachedReads[method] {
+		if r.offline {
+			return fmt.Errorf("%s needs the core; only listings are served with --cached", method)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	reqMsg, replyMsg := req.(proto.Message), reply.(proto.Message)
+	if r.offline {
+		savedAt, err := r.load(method, reqMsg, replyMsg)
+		if err != nil {
+			return err
+		}
+		if r.oldest.IsZero() || savedAt.Before(r.oldest) {
+			r.oldest = savedAt
+		}
+		return nil
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err == nil {
+		if err := r.save(method, reqMsg, replyMsg); err != nil {
+			fmt.Fprintf(os.Stderr, "! could not cache the listing: %v\n", err)
+		}
+		return nil
+	}
+	if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded {
+		if read, rerr := r.read(method, reqMsg); rerr == nil {
+			fmt.Fprintf(os.Stderr, "! the core is unreachable; --cached shows this listing as of %s ago\n", staleness(read.SavedAt))
+		}
+	}
+	return err
+}
+
+// annotate says how old the cached responses used are, if any were
+func (r *readCache) annotate(w io.Writer) {
+	if r.oldest.IsZero() {
+		return
+	}
+	fmt.Fprintf(w, "! cached listing from %s (%s old); the core was not asked\n",
+		r.oldest.Local().Format(time.DateTime), staleness(r.oldest))
+}
+
+func (r *readCache) path(method string, req proto.Message) (string, error) {
+	if r.dir == "" {
+		return "", fmt.Errorf("no cache directory; set MANDAU_CACHE_DIR")
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(r.scope), []byte(method), data} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return filepath.Join(r.dir, hex.EncodeToString(h.Sum(nil))+".json"), nil
+}
+
+func (r *readCache) read(method string, req proto.Message) (*cachedRead, error) {
+	path, err := r.path(method, req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var read cachedRead
+	if err := json.Unmarshal(data, &read); err != nil {
+		return nil, fmt.Errorf("read cached listing: %w", err)
+	}
+	return &read, nil
+}
+
+// load fills reply with the cached response to req and returns when it was
+// saved
+func (r *readCache) load(method string, req, reply proto.Message) (time.Time, error) {
+	read, err := r.read(method, req)
+	if os.IsNotExist(err) {
+		return time.Time{}, fmt.Errorf("no cached listing for this request; run it once without --cached while the core is up")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := protojson.Unmarshal(read.Response, reply); err != nil {
+		return time.Time{}, fmt.Errorf("read cached listing: %w", err)
+	}
+	return read.SavedAt, nil
+}
+
+// save keeps reply as the response to req, replacing the file whole so
+// concurrent commands never read half of it
+func (r *readCache) save(method string, req, reply proto.Message) error {
+	path, err := r.path(method, req)
+	if err != nil {
+		return err
+	}
+	response, err := protojson.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedRead{Method: method, SavedAt: time.Now(), Response: response})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(r.dir, ".read-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// staleness is how long ago t was, to the second
+func staleness(t time.Time) string {
+	return time.Since(t).Round(time.Second).String()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReadCache(t *testing.T) {
+	t.Setenv("MANDAU_CACHE_DIR", t.TempDir())
+	method := v1.CoreService_ListAgents_FullMethodName
+	req := &v1.ListAgentsRequest{Group: "web"}
+	listed := &v1.ListAgentsResponse{Agents: []*v1.Agent{{Id: "web-1", Status: "online"}}}
+
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		proto.Merge(reply.(proto.Message), listed)
+		return nil
+	}
+
+	online := newReadCache("core:8443", "client.crt", "", false)
+	if err := online.unary(context.Background(), method, req, &v1.ListAgentsResponse{}, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+
+	offline := newReadCache("core:8443", "client.crt", "", true)
+	reply := &v1.ListAgentsResponse{}
+	if err := offline.unary(context.Background(), method, req, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("core called %d times, want once", calls)
+	}
+	if !proto.Equal(reply, listed) {
+		t.Errorf("cached listing = %v, want %v", reply, listed)
+	}
+	var note strings.Builder
+	offline.annotate(&note)
+	if !strings.Contains(note.String(), "cached listing from") {
+		t.Errorf("annotation = %q", note.String())
+	}
+
+	// Other filters, cores and certificates were never listed
+	for _, c := range []struct {
+		cache *readCache
+		req   *v1.ListAgentsRequest
+	}{
+		{offline, &v1.ListAgentsRequest{Group: "db"}},
+		{newReadCache("other:8443", "client.crt", "", true), req},
+		{newReadCache("core:8443", "admin.crt", "", true), req},
+		{newReadCache("core:8443", "client.crt", "bob", true), req},
+	} {
+		if err := c.cache.unary(context.Background(), method, c.req, &v1.ListAgentsResponse{}, nil, invoker); err == nil {
+			t.Errorf("%s %v served from another listing", c.cache.scope, c.req)
+		}
+	}
+
+	// Calls that change things are not answered offline
+	if err := offline.unary(context.Background(), v1.CoreService_SetFreeze_FullMethodName, &v1.SetFreezeRequest{}, &v1.FreezeState{}, nil, invoker); err == nil {
+		t.Error("SetFreeze answered offline")
+	}
+
+	// A failed call leaves the cached listing in place
+	down := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "connection refused")
+	}
+	if err := online.unary(context.Background(), method, req, &v1.ListAgentsResponse{}, nil, down); status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v, want the core's error", err)
+	}
+	if err := offline.unary(context.Background(), method, req, &v1.ListAgentsResponse{}, nil, invoker); err != nil {
+		t.Errorf("listing gone after a failed call: %v", err)
+	}
+}
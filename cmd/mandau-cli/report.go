@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show resource totals per namespace and team",
+		Args:  cobra.NoArgs,
+		RunE:  showReport,
+	}
+	showCmd.Flags().Bool("refresh", false, "Take a new snapshot instead of the latest periodic one")
+	reportCmd.AddCommand(showCmd)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a per-stack resource report",
+		Args:  cobra.NoArgs,
+		RunE:  exportReport,
+	}
+	exportCmd.Flags().Bool("refresh", false, "Take a new snapshot instead of the latest periodic one")
+	exportCmd.Flags().String("format", "csv", "Output format: csv or json")
+	exportCmd.Flags().StringP("output", "o", "", "Write to file instead of stdout")
+	reportCmd.AddCommand(exportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Resource reports for chargeback",
+	Long:  "Commands to view and export stack resource reservations attributed to namespaces, teams and labels",
+}
+
+// fetchReport converts the server report into a snapshot with totals
+func (c *CLI) fetchReport(cmd *cobra.Command) (*report.Snapshot, error) {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	resp, err := c.coreClient.GetResourceReport(context.Background(), &v1.GetResourceReportRequest{
+		Refresh: refresh,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]report.StackRow, len(resp.Stacks))
+	for i, s := range resp.Stacks {
+		rows[i] = report.StackRow{
+			AgentID:     s.AgentId,
+			Namespace:   s.Namespace,
+			Stack:       s.Stack,
+			State:       s.State.String(),
+			Team:        s.GetOwner().GetTeam(),
+			Owner:       s.GetOwner().GetOwner(),
+			Labels:      s.Labels,
+			Containers:  int(s.Containers),
+			Running:     int(s.RunningContainers),
+			CPUs:        s.Cpus,
+			MemoryBytes: s.MemoryBytes,
+		}
+	}
+
+	snap := report.New(resp.GeneratedAt.AsTime(), rows)
+	if len(resp.AgentErrors) > 0 {
+		snap.Errors = resp.AgentErrors
+	}
+	return snap, nil
+}
+
+// warnMissing tells the user which agents are absent from a result, and why
+func warnMissing(missing map[string]string) {
+	ids := make([]string, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(os.Stderr, "! agent %s not included: %s\n", id, missing[id])
+	}
+}
+
+func (c *CLI) showReport(cmd *cobra.Command, args []string) error {
+	snap, err := c.fetchReport(cmd)
+	if err != nil {
+		return err
+	}
+
+	warnMissing(snap.Errors)
+	fmt.Printf("Snapshot taken %s\n\n", snap.GeneratedAt.Local().Format("2006-01-02 15:04:05"))
+	printTotals("NAMESPACE", snap.Namespaces)
+	fmt.Println()
+	printTotals("TEAM", snap.Teams)
+	return nil
+}
+
+func showReport(cmd *cobra.Command, args []string) error {
+	return cli.showReport(cmd, args)
+}
+
+func printTotals(heading string, totals []report.Total) {
+	fmt.Printf("%-20s %-8s %-12s %-10s %s\n", heading, "STACKS", "CONTAINERS", "CPUS", "MEMORY")
+	for _, t := range totals {
+		fmt.Printf("%-20s %-8d %-12s %-10.2f %s\n",
+			t.Key,
+			t.Stacks,
+			fmt.Sprintf("%d/%d", t.Running, t.Containers),
+			t.CPUs,
+			quota.FormatMemory(t.MemoryBytes),
+		)
+	}
+}
+
+func (c *CLI) exportReport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	snap, err := c.fetchReport(cmd)
+	if err != nil {
+		return err
+	}
+	warnMissing(snap.Errors)
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := snap.Write(w, format); err != nil {
+		return err
+	}
+
+	if output != "" {
+		fmt.Printf("✓ Report written to %s\n", output)
+	}
+	return nil
+}
+
+func exportReport(cmd *cobra.Command, args []string) error {
+	return cli.exportReport(cmd, args)
+}
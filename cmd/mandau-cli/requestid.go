@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+func init() {
+	rootCmd.PersistentFlags().String("request-id", "", "Correlation ID sent with every call of the command; random by default (MANDAU_REQUEST_ID)")
+}
+
+// initRequestID settles the request ID the command's calls carry: the one
+// passed in, for instance by a script that already has one, or a new one
+func (c *CLI) initRequestID(cmd *cobra.Command) error {
+	id, err := c.getFlagOrEnv(cmd, "request-id", "MANDAU_REQUEST_ID", "")
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		id = requestid.New()
+	} else if !requestid.Valid(id) {
+		return fmt.Errorf("invalid request ID %q: use up to 128 letters, digits, '.', '-', '_' and ':'", id)
+	}
+	c.requestID = id
+	return nil
+}
+
+func (c *CLI) requestIDUnary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return requestid.UnaryClientInterceptor(requestid.With(ctx, c.requestID), method, req, reply, cc, invoker, opts...)
+}
+
+func (c *CLI) requestIDStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return requestid.StreamClientInterceptor(requestid.With(ctx, c.requestID), desc, cc, method, streamer, opts...)
+}
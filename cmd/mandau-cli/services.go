@@ -1,9 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
-
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -15,12 +27,14 @@ func init() {
 		Short: "Nginx management",
 	}
 
-	nginxCmd.AddCommand(&cobra.Command{
+	createProxyCmd := &cobra.Command{
 		Use:   "create-proxy [agent] [domain] [upstream] [port]",
-		Short: "Create reverse proxy",
+		Short: "Create reverse proxy listening on port",
 		Args:  cobra.ExactArgs(4),
 		RunE:  createReverseProxy,
-	})
+	}
+	createProxyCmd.Flags().String("snippet", "", "File of extra directives for the end of the server block")
+	nginxCmd.AddCommand(forceFlag(dryRunFlag(createProxyCmd)))
 
 	nginxCmd.AddCommand(&cobra.Command{
 		Use:   "list [agent]",
@@ -29,12 +43,50 @@ func init() {
 		RunE:  listVirtualHosts,
 	})
 
+	nginxCmd.AddCommand(&cobra.Command{
+		Use:   "enable [agent] [server-name]",
+		Short: "Enable a virtual host",
+		Args:  cobra.ExactArgs(2),
+		RunE:  enableVirtualHost,
+	})
+
+	nginxCmd.AddCommand(&cobra.Command{
+		Use:   "disable [agent] [server-name]",
+		Short: "Disable a virtual host",
+		Args:  cobra.ExactArgs(2),
+		RunE:  disableVirtualHost,
+	})
+
+	nginxCmd.AddCommand(forceFlag(dryRunFlag(&cobra.Command{
+		Use:   "delete [agent] [server-name]",
+		Short: "Delete a virtual host",
+		Args:  cobra.ExactArgs(2),
+		RunE:  deleteVirtualHost,
+	})))
+
 	// Systemd commands
 	systemdCmd := &cobra.Command{
 		Use:   "systemd",
 		Short: "Systemd service management",
 	}
 
+	createServiceCmd := &cobra.Command{
+		Use:   "create [agent] [name] [exec-start]",
+		Short: "Create a service unit",
+		Args:  cobra.ExactArgs(3),
+		RunE:  createService,
+	}
+	createServiceCmd.Flags().String("description", "", "Unit description")
+	createServiceCmd.Flags().String("user", "", "User to run as")
+	createServiceCmd.Flags().String("working-dir", "", "Working directory")
+	createServiceCmd.Flags().String("restart", "", "Restart policy, e.g. on-failure")
+	createServiceCmd.Flags().StringToString("env", nil, "Environment variable KEY=VALUE (repeatable)")
+	createServiceCmd.Flags().String("unit-snippet", "", "File of extra lines for the end of the [Unit] section")
+	createServiceCmd.Flags().String("service-snippet", "", "File of extra lines for the end of the [Service] section")
+	createServiceCmd.Flags().String("install-snippet", "", "File of extra lines for the end of the [Install] section")
+	createServiceCmd.Flags().Bool("template", false, "Create the template unit name@.service; each instance reads its own environment file")
+	systemdCmd.AddCommand(forceFlag(dryRunFlag(createServiceCmd)))
+
 	systemdCmd.AddCommand(&cobra.Command{
 		Use:   "start [agent] [service]",
 		Short: "Start service",
@@ -56,6 +108,20 @@ func init() {
 		RunE:  restartService,
 	})
 
+	systemdCmd.AddCommand(&cobra.Command{
+		Use:   "enable [agent] [service]",
+		Short: "Start service at boot",
+		Args:  cobra.ExactArgs(2),
+		RunE:  enableService,
+	})
+
+	systemdCmd.AddCommand(&cobra.Command{
+		Use:   "disable [agent] [service]",
+		Short: "Stop starting service at boot",
+		Args:  cobra.ExactArgs(2),
+		RunE:  disableService,
+	})
+
 	systemdCmd.AddCommand(&cobra.Command{
 		Use:   "status [agent] [service]",
 		Short: "Get service status",
@@ -63,6 +129,41 @@ func init() {
 		RunE:  getServiceStatus,
 	})
 
+	systemdCmd.AddCommand(&cobra.Command{
+		Use:   "list [agent]",
+		Short: "List services managed by Mandau",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listServices,
+	})
+
+	systemdCmd.AddCommand(&cobra.Command{
+		Use:   "instances [agent] [template]",
+		Short: "List the instances of a template unit",
+		Args:  cobra.ExactArgs(2),
+		RunE:  listInstances,
+	})
+
+	instanceEnvCmd := &cobra.Command{
+		Use:   "instance-env [agent] [template] [instance] [KEY=VALUE...]",
+		Short: "Replace the environment file of a template instance",
+		Long: "Replace the environment file of one instance of a template unit, such as the " +
+			"queue a worker reads. Without variables the file is deleted. The instance reads " +
+			"the file when it next starts; --restart restarts it now.",
+		Args: cobra.MinimumNArgs(3),
+		RunE: setInstanceEnv,
+	}
+	instanceEnvCmd.Flags().Bool("restart", false, "Restart the instance so it reads the file")
+	systemdCmd.AddCommand(forceFlag(instanceEnvCmd))
+
+	systemdCmd.AddCommand(&cobra.Command{
+		Use:   "scale [agent] [template] [count]",
+		Short: "Run count numbered instances of a template unit",
+		Long: "Start and enable instances 1 to count of a template unit, and stop and disable " +
+			"the numbered instances above count. Instances with other names are left alone.",
+		Args: cobra.ExactArgs(3),
+		RunE: scaleInstances,
+	})
+
 	// SSL commands (using ACME plugin)
 	sslCmd := &cobra.Command{
 		Use:   "ssl",
@@ -71,8 +172,8 @@ func init() {
 
 	sslCmd.AddCommand(&cobra.Command{
 		Use:   "obtain [agent] [domain] [email]",
-		Short: "Obtain SSL certificate",
-		Args:  cobra.ExactArgs(3),
+		Short: "Obtain SSL certificate (email defaults to the agent's ACME config)",
+		Args:  cobra.RangeArgs(2, 3),
 		RunE:  obtainCertificate,
 	})
 
@@ -90,6 +191,13 @@ func init() {
 		RunE:  renewAllCertificates,
 	})
 
+	sslCmd.AddCommand(&cobra.Command{
+		Use:   "revoke [agent] [domain]",
+		Short: "Revoke SSL certificate",
+		Args:  cobra.ExactArgs(2),
+		RunE:  revokeCertificate,
+	})
+
 	sslCmd.AddCommand(&cobra.Command{
 		Use:   "list [agent]",
 		Short: "List SSL certificates",
@@ -103,18 +211,43 @@ func init() {
 		Short: "Firewall management",
 	}
 
-	firewallCmd.AddCommand(&cobra.Command{
+	firewallCmd.AddCommand(dryRunFlag(&cobra.Command{
 		Use:   "allow-port [agent] [port] [protocol]",
 		Short: "Allow a port through firewall",
 		Args:  cobra.ExactArgs(3),
 		RunE:  allowPort,
-	})
+	}))
 
-	firewallCmd.AddCommand(&cobra.Command{
+	firewallCmd.AddCommand(dryRunFlag(&cobra.Command{
 		Use:   "deny-port [agent] [port] [protocol]",
 		Short: "Deny a port through firewall",
 		Args:  cobra.ExactArgs(3),
 		RunE:  denyPort,
+	}))
+
+	allowFromCmd := dryRunFlag(&cobra.Command{
+		Use:   "allow-from [agent] [cidr] [port] [protocol]",
+		Short: "Allow a port only from an IPv4 or IPv6 address or network",
+		Args:  cobra.ExactArgs(4),
+		RunE:  allowFrom,
+	})
+	allowFromCmd.Flags().String("set", "", "Rule set to add the rule to, removed together with remove-set")
+	allowFromCmd.Flags().String("limit", "", "Accept new connections only up to a rate, e.g. 10/minute (ufw uses its own limit)")
+	allowFromCmd.Flags().Int("burst", 0, "Connections accepted over the rate at once (default 5)")
+	firewallCmd.AddCommand(allowFromCmd)
+
+	firewallCmd.AddCommand(dryRunFlag(&cobra.Command{
+		Use:   "remove-set [agent] [name]",
+		Short: "Delete every rule of a rule set",
+		Args:  cobra.ExactArgs(2),
+		RunE:  removeFirewallRuleSet,
+	}))
+
+	firewallCmd.AddCommand(&cobra.Command{
+		Use:   "delete-rule [agent] [number]",
+		Short: "Delete a firewall rule by its number in list",
+		Args:  cobra.ExactArgs(2),
+		RunE:  deleteFirewallRule,
 	})
 
 	firewallCmd.AddCommand(&cobra.Command{
@@ -131,25 +264,35 @@ func init() {
 		RunE:  enableFirewall,
 	})
 
+	firewallCmd.AddCommand(&cobra.Command{
+		Use:   "disable [agent]",
+		Short: "Disable firewall",
+		Args:  cobra.ExactArgs(1),
+		RunE:  disableFirewall,
+	})
+
 	// Cron commands
 	cronCmd := &cobra.Command{
 		Use:   "cron",
 		Short: "Cron job management",
 	}
 
-	cronCmd.AddCommand(&cobra.Command{
+	addCronCmd := &cobra.Command{
 		Use:   "add [agent] [name] [schedule] [command]",
 		Short: "Add a cron job",
 		Args:  cobra.ExactArgs(4),
 		RunE:  addCronJob,
-	})
+	}
+	forceFlag(dryRunFlag(addCronCmd))
+	addCronCmd.Flags().String("user", "", "User to run the job as (default: the agent's cron user)")
+	cronCmd.AddCommand(addCronCmd)
 
-	cronCmd.AddCommand(&cobra.Command{
+	cronCmd.AddCommand(forceFlag(dryRunFlag(&cobra.Command{
 		Use:   "remove [agent] [name]",
 		Short: "Remove a cron job",
 		Args:  cobra.ExactArgs(2),
 		RunE:  removeCronJob,
-	})
+	})))
 
 	cronCmd.AddCommand(&cobra.Command{
 		Use:   "list [agent]",
@@ -192,37 +335,146 @@ func init() {
 		RunE:  updatePackages,
 	})
 
+	envCmd.AddCommand(&cobra.Command{
+		Use:   "packages [agent]",
+		Short: "List installed packages",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listPackages,
+	})
+
+	sysctlCmd := &cobra.Command{
+		Use:   "sysctl [agent] [key] [value]",
+		Short: "Show a kernel parameter, or set it when a value is given",
+		Args:  cobra.RangeArgs(2, 3),
+		RunE:  sysctl,
+	}
+	sysctlCmd.Flags().Bool("persist", false, "Also write the value to /etc/sysctl.d so it survives reboots")
+	sysctlCmd.Flags().Bool("force", false, "Replace a sysctl.d file edited outside Mandau")
+	envCmd.AddCommand(sysctlCmd)
+
+	timeSyncCmd := &cobra.Command{
+		Use:   "time [agent]",
+		Short: "Show time sync, or point chrony at the servers given",
+		Args:  cobra.ExactArgs(1),
+		RunE:  timeSync,
+	}
+	timeSyncCmd.Flags().StringSlice("server", nil, "NTP server or pool to sync with (repeatable)")
+	timeSyncCmd.Flags().Bool("step", false, "Step the clock now instead of slewing it")
+	timeSyncCmd.Flags().Bool("force", false, "Replace a sources file edited outside Mandau")
+	envCmd.AddCommand(timeSyncCmd)
+
+	sysctlProfileCmd := &cobra.Command{
+		Use:   "sysctl-profile [agent] [name]",
+		Short: "List the sysctl profiles, or persist and load the one named",
+		Long: "Sysctl profiles are built-in sets of kernel parameters for a workload, such as " +
+			"high-network. Applying one writes it to /etc/sysctl.d, so it is set again at boot, and " +
+			"loads it. Parameters set with sysctl --persist override those of profiles.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: sysctlProfile,
+	}
+	sysctlProfileCmd.Flags().Bool("remove", false, "Delete the profile's file; the running values stay until the next boot")
+	sysctlProfileCmd.Flags().Bool("force", false, "Replace or delete a sysctl.d file edited outside Mandau")
+	envCmd.AddCommand(sysctlProfileCmd)
+
+	envCmd.AddCommand(&cobra.Command{
+		Use:   "sysctl-verify [agent]",
+		Short: "Compare the sysctls Mandau persisted with the running values",
+		Long: "Compare each kernel parameter Mandau wrote to /etc/sysctl.d with its running value, " +
+			"as after a reboot. Host reboots run the same check once the agent is back.",
+		Args: cobra.ExactArgs(1),
+		RunE: sysctlVerify,
+	})
+
+	limitsCmd := &cobra.Command{
+		Use:   "limits [agent] [name] [domain:type:item=value...]",
+		Short: "List resource limits, or set the limits file named",
+		Long: "Without a name, list the limits of limits.conf and limits.d. With a name, replace " +
+			"/etc/security/limits.d/60-mandau-<name>.conf with the limits given, such as " +
+			"'*:soft:nofile=65536' or '@dev:-:nproc=4096'; without limits the file is deleted. " +
+			"pam_limits applies them to login sessions; services take theirs from their units.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: limits,
+	}
+	limitsCmd.Flags().Bool("force", false, "Replace or delete a limits.d file edited outside Mandau")
+	envCmd.AddCommand(limitsCmd)
+
+	dockerDaemonCmd := &cobra.Command{
+		Use:   "docker",
+		Short: "Manage the Docker daemon's daemon.json",
+	}
+	dockerDaemonCmd.AddCommand(&cobra.Command{
+		Use:   "show [agent]",
+		Short: "Show daemon.json and the drivers Docker runs with",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showDockerDaemon,
+	})
+	dockerApplyCmd := &cobra.Command{
+		Use:   "apply [agent-id] [file]",
+		Short: "Set the log driver, registry mirrors, storage driver and address pools of daemon.json",
+		Long: "Set the log-driver, log-opts, registry-mirrors, storage-driver, storage-opts and " +
+			"default-address-pools of daemon.json to those in a YAML or JSON file using the same " +
+			"keys. Those left out are removed; other keys of daemon.json are kept. With --restart, " +
+			"Docker is restarted to take the settings up, and if it does not come back healthy the " +
+			"previous daemon.json is restored. Containers without a restart policy stay stopped " +
+			"unless live-restore is enabled. With --group, agents are changed one at a time and the " +
+			"first failure stops the rollout.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: applyDockerDaemon,
+	}
+	dockerApplyCmd.Flags().String("group", "", "Apply to every agent in the group, one at a time")
+	dockerApplyCmd.Flags().Bool("restart", false, "Restart Docker, rolling back if it does not come back healthy")
+	dockerDaemonCmd.AddCommand(dryRunFlag(dockerApplyCmd))
+	envCmd.AddCommand(dockerDaemonCmd)
+
 	// DNS commands
 	dnsCmd := &cobra.Command{
 		Use:   "dns",
 		Short: "DNS management",
 	}
 
-	dnsCmd.AddCommand(&cobra.Command{
+	createZoneCmd := &cobra.Command{
 		Use:   "create-zone [agent] [domain]",
 		Short: "Create a DNS zone",
 		Args:  cobra.ExactArgs(2),
 		RunE:  createDNSZone,
-	})
+	}
+	forceFlag(dryRunFlag(createZoneCmd))
+	createZoneCmd.Flags().StringSlice("ns", nil, "Name server (repeatable; default ns1.<domain>)")
+	createZoneCmd.Flags().String("admin", "", "SOA contact (default hostmaster.<domain>)")
+	createZoneCmd.Flags().Int32("ttl", 0, "Default TTL in seconds (default 3600)")
+	dnsCmd.AddCommand(createZoneCmd)
 
-	dnsCmd.AddCommand(&cobra.Command{
+	addACmd := &cobra.Command{
 		Use:   "add-a [agent] [domain] [name] [ip]",
 		Short: "Add an A record",
 		Args:  cobra.ExactArgs(4),
 		RunE:  addARecord,
-	})
+	}
+	forceFlag(dryRunFlag(addACmd))
+	addACmd.Flags().Int32("ttl", 0, "TTL in seconds (default 3600)")
+	dnsCmd.AddCommand(addACmd)
 
-	dnsCmd.AddCommand(&cobra.Command{
+	addCNAMECmd := &cobra.Command{
 		Use:   "add-cname [agent] [domain] [name] [target]",
 		Short: "Add a CNAME record",
 		Args:  cobra.ExactArgs(4),
 		RunE:  addCNAMERecord,
+	}
+	forceFlag(dryRunFlag(addCNAMECmd))
+	addCNAMECmd.Flags().Int32("ttl", 0, "TTL in seconds (default 3600)")
+	dnsCmd.AddCommand(addCNAMECmd)
+
+	dnsCmd.AddCommand(&cobra.Command{
+		Use:   "zones [agent]",
+		Short: "List the DNS zones of an agent",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listDNSZones,
 	})
 
 	// Deploy command
 	deployCmd := &cobra.Command{
 		Use:   "deploy",
-		Short: "Deploy web services",
+		Short: "Deploy web services, static sites, databases and workers",
 	}
 
 	deployCmd.AddCommand(&cobra.Command{
@@ -232,6 +484,56 @@ func init() {
 		RunE:  deployWebService,
 	})
 
+	staticCmd := &cobra.Command{
+		Use:   "static [agent] [name] [domain] [root]",
+		Short: "Deploy a static site served by nginx",
+		Args:  cobra.ExactArgs(4),
+		RunE:  deployStaticSite,
+	}
+	staticCmd.Flags().StringSlice("index", nil, "Index file (repeatable; default index.html)")
+	staticCmd.Flags().Bool("ssl", false, "Obtain a certificate and serve over HTTPS")
+
+	databaseCmd := &cobra.Command{
+		Use:   "database [agent] [name] [engine]",
+		Short: "Deploy a database container (postgres, mysql or mariadb)",
+		Args:  cobra.ExactArgs(3),
+		RunE:  deployDatabase,
+	}
+	databaseCmd.Flags().String("version", "", "Image tag (default latest)")
+	databaseCmd.Flags().Int32("port", 0, "Host port bound to 127.0.0.1 (default: engine port)")
+	databaseCmd.Flags().String("data-dir", "", "Data directory on the host")
+	databaseCmd.Flags().String("password", "", "Root password (MANDAU_DB_PASSWORD)")
+	databaseCmd.Flags().String("backup-schedule", "", "Cron schedule for backups; none when empty")
+	databaseCmd.Flags().String("backup-dir", "", "Backup directory on the host")
+	databaseCmd.Flags().Int32("backup-retention", 0, "Days to keep backups")
+
+	workerCmd := &cobra.Command{
+		Use:   "worker [agent] [name] [command]",
+		Short: "Deploy a background worker run by systemd",
+		Args:  cobra.ExactArgs(3),
+		RunE:  deployWorker,
+	}
+	workerCmd.Flags().String("description", "", "Unit description")
+	workerCmd.Flags().String("working-dir", "", "Working directory")
+	workerCmd.Flags().String("user", "", "User to run as")
+	workerCmd.Flags().StringToString("env", nil, "Environment variable KEY=VALUE (repeatable)")
+
+	deployCmd.AddCommand(staticCmd, databaseCmd, workerCmd)
+
+	deployCmd.AddCommand(&cobra.Command{
+		Use:   "list [agent]",
+		Short: "List deployments and what each created",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listDeployments,
+	})
+
+	deployCmd.AddCommand(forceFlag(&cobra.Command{
+		Use:   "remove [agent] [name]",
+		Short: "Remove a deployment and everything it created",
+		Args:  cobra.ExactArgs(2),
+		RunE:  removeDeployment,
+	}))
+
 	servicesCmd.AddCommand(nginxCmd, systemdCmd, sslCmd, firewallCmd, cronCmd, envCmd, dnsCmd, deployCmd)
 }
 
@@ -240,16 +542,100 @@ var servicesCmd = &cobra.Command{
 	Short: "Manage host services",
 }
 
+// hostError turns an error from a host service call into one worth
+// reading. An agent without the plugin enabled does not serve its service
+// at all, which gRPC reports as unimplemented.
+func hostError(err error, agentID, plugin string) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Unimplemented:
+		return fmt.Errorf("agent %s does not serve this; enable the %s plugin in its config", agentID, plugin)
+	case codes.FailedPrecondition, codes.NotFound, codes.InvalidArgument, codes.PermissionDenied, codes.AlreadyExists, codes.Aborted:
+		return fmt.Errorf("%s", st.Message())
+	}
+	return err
+}
+
+// dryRunFlag lets a command diff its change on the agent instead of
+// making it
+func dryRunFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool("dry-run", false, "Show the diff of what would change without changing it")
+	return cmd
+}
+
+// forceFlag lets a command replace or remove files that were edited
+// outside Mandau since it wrote them, which the agent otherwise refuses
+func forceFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool("force", false, "Replace or remove files edited outside Mandau")
+	return cmd
+}
+
+// printDiff shows the change a dry run would have made
+func printDiff(diff string) {
+	if diff == "" {
+		fmt.Println("No changes")
+		return
+	}
+	fmt.Print(diff)
+}
+
+// readSnippet reads the file named by a snippet flag, or "" when unset
+func readSnippet(cmd *cobra.Command, flag string) (string, error) {
+	path, _ := cmd.Flags().GetString(flag)
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("--%s: %w", flag, err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// parsePort parses a port argument
+func parsePort(arg string) (int32, error) {
+	port, err := strconv.Atoi(arg)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", arg)
+	}
+	return int32(port), nil
+}
+
 func (c *CLI) createReverseProxy(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	upstream := args[2]
-	port := args[3]
+	port, err := parsePort(args[3])
+	if err != nil {
+		return err
+	}
+
+	snippet, err := readSnippet(cmd, "snippet")
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewNginxServiceClient(c.conn)
+	resp, err := client.CreateReverseProxy(context.Background(), &v1.CreateReverseProxyRequest{
+		AgentId:  args[0],
+		Domain:   args[1],
+		Upstream: args[2],
+		Port:     port,
+		DryRun:   dryRun,
+		Force:    force,
+		Snippet:  snippet,
+	})
+	if err != nil {
+		return hostError(err, args[0], "nginx-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
 
-	// Call the agent service to create the reverse proxy via nginx plugin
-	// This would require an API endpoint in the agent service
-	fmt.Printf("Creating reverse proxy on agent %s for %s -> %s (port %s)\n", agentID, domain, upstream, port)
-	fmt.Println("Note: This would call the nginx plugin in the actual implementation")
+	fmt.Printf("✓ Reverse proxy %s -> %s created on port %d (enable it with 'services nginx enable')\n", args[1], args[2], port)
 	return nil
 }
 
@@ -258,21 +644,157 @@ func createReverseProxy(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) listVirtualHosts(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Listing virtual hosts on agent %s\n", agentID)
-	fmt.Println("Note: This would call the nginx plugin in the actual implementation")
-	return nil
+	client := v1.NewNginxServiceClient(c.conn)
+	resp, err := client.ListVirtualHosts(context.Background(), &v1.ListVirtualHostsRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "nginx-manager")
+	}
+	if len(resp.Vhosts) == 0 {
+		fmt.Println("No virtual hosts")
+		return nil
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range resp.Enabled {
+		enabled[name] = true
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER NAME\tENABLED")
+	for _, name := range resp.Vhosts {
+		fmt.Fprintf(w, "%s\t%v\n", name, enabled[name])
+	}
+	return w.Flush()
 }
 
 func listVirtualHosts(cmd *cobra.Command, args []string) error {
 	return cli.listVirtualHosts(cmd, args)
 }
 
+func (c *CLI) enableVirtualHost(cmd *cobra.Command, args []string) error {
+	client := v1.NewNginxServiceClient(c.conn)
+	if _, err := client.EnableVirtualHost(context.Background(), &v1.EnableVirtualHostRequest{
+		AgentId:    args[0],
+		ServerName: args[1],
+	}); err != nil {
+		return hostError(err, args[0], "nginx-manager")
+	}
+
+	fmt.Printf("✓ Virtual host %s enabled\n", args[1])
+	return nil
+}
+
+func enableVirtualHost(cmd *cobra.Command, args []string) error {
+	return cli.enableVirtualHost(cmd, args)
+}
+
+func (c *CLI) disableVirtualHost(cmd *cobra.Command, args []string) error {
+	client := v1.NewNginxServiceClient(c.conn)
+	if _, err := client.DisableVirtualHost(context.Background(), &v1.DisableVirtualHostRequest{
+		AgentId:    args[0],
+		ServerName: args[1],
+	}); err != nil {
+		return hostError(err, args[0], "nginx-manager")
+	}
+
+	fmt.Printf("✓ Virtual host %s disabled\n", args[1])
+	return nil
+}
+
+func disableVirtualHost(cmd *cobra.Command, args []string) error {
+	return cli.disableVirtualHost(cmd, args)
+}
+
+func (c *CLI) deleteVirtualHost(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewNginxServiceClient(c.conn)
+	resp, err := client.DeleteVirtualHost(context.Background(), &v1.DeleteVirtualHostRequest{
+		AgentId:    args[0],
+		ServerName: args[1],
+		DryRun:     dryRun,
+		Force:      force,
+	})
+	if err != nil {
+		return hostError(err, args[0], "nginx-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Virtual host %s deleted\n", args[1])
+	return nil
+}
+
+func deleteVirtualHost(cmd *cobra.Command, args []string) error {
+	return cli.deleteVirtualHost(cmd, args)
+}
+
+func (c *CLI) createService(cmd *cobra.Command, args []string) error {
+	description, _ := cmd.Flags().GetString("description")
+	user, _ := cmd.Flags().GetString("user")
+	workingDir, _ := cmd.Flags().GetString("working-dir")
+	restart, _ := cmd.Flags().GetString("restart")
+	env, _ := cmd.Flags().GetStringToString("env")
+	template, _ := cmd.Flags().GetBool("template")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	var snippets [3]string
+	for i, flag := range []string{"unit-snippet", "service-snippet", "install-snippet"} {
+		snippet, err := readSnippet(cmd, flag)
+		if err != nil {
+			return err
+		}
+		snippets[i] = snippet
+	}
+
+	client := v1.NewSystemdServiceClient(c.conn)
+	resp, err := client.CreateService(context.Background(), &v1.CreateServiceRequest{
+		AgentId:        args[0],
+		Name:           args[1],
+		Description:    description,
+		User:           user,
+		WorkingDir:     workingDir,
+		ExecStart:      args[2],
+		Environment:    env,
+		Restart:        restart,
+		Template:       template,
+		DryRun:         dryRun,
+		Force:          force,
+		UnitSnippet:    snippets[0],
+		ServiceSnippet: snippets[1],
+		InstallSnippet: snippets[2],
+	})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	if template {
+		fmt.Printf("✓ Template %s@.service created (run instances with 'services systemd scale')\n", args[1])
+		return nil
+	}
+	fmt.Printf("✓ Service %s created (start it with 'services systemd start')\n", args[1])
+	return nil
+}
+
+func createService(cmd *cobra.Command, args []string) error {
+	return cli.createService(cmd, args)
+}
+
 func (c *CLI) startService(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	service := args[1]
-	fmt.Printf("Starting service %s on agent %s\n", service, agentID)
-	fmt.Println("Note: This would call the systemd plugin in the actual implementation")
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.StartService(context.Background(), &v1.StartServiceRequest{AgentId: args[0], Name: args[1]}); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("✓ Service %s started\n", args[1])
 	return nil
 }
 
@@ -281,10 +803,12 @@ func startService(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) stopService(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	service := args[1]
-	fmt.Printf("Stopping service %s on agent %s\n", service, agentID)
-	fmt.Println("Note: This would call the systemd plugin in the actual implementation")
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.StopService(context.Background(), &v1.StopServiceRequest{AgentId: args[0], Name: args[1]}); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("✓ Service %s stopped\n", args[1])
 	return nil
 }
 
@@ -293,10 +817,12 @@ func stopService(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) restartService(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	service := args[1]
-	fmt.Printf("Restarting service %s on agent %s\n", service, agentID)
-	fmt.Println("Note: This would call the systemd plugin in the actual implementation")
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.RestartService(context.Background(), &v1.RestartServiceRequest{AgentId: args[0], Name: args[1]}); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("✓ Service %s restarted\n", args[1])
 	return nil
 }
 
@@ -304,11 +830,42 @@ func restartService(cmd *cobra.Command, args []string) error {
 	return cli.restartService(cmd, args)
 }
 
+func (c *CLI) enableService(cmd *cobra.Command, args []string) error {
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.EnableService(context.Background(), &v1.EnableServiceRequest{AgentId: args[0], Name: args[1]}); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("✓ Service %s enabled\n", args[1])
+	return nil
+}
+
+func enableService(cmd *cobra.Command, args []string) error {
+	return cli.enableService(cmd, args)
+}
+
+func (c *CLI) disableService(cmd *cobra.Command, args []string) error {
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.DisableService(context.Background(), &v1.DisableServiceRequest{AgentId: args[0], Name: args[1]}); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("✓ Service %s disabled\n", args[1])
+	return nil
+}
+
+func disableService(cmd *cobra.Command, args []string) error {
+	return cli.disableService(cmd, args)
+}
+
 func (c *CLI) getServiceStatus(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	service := args[1]
-	fmt.Printf("Status for service %s on agent %s\n", service, agentID)
-	fmt.Println("Note: This would call the systemd plugin in the actual implementation")
+	client := v1.NewSystemdServiceClient(c.conn)
+	resp, err := client.GetServiceStatus(context.Background(), &v1.GetServiceStatusRequest{AgentId: args[0], Name: args[1]})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	fmt.Printf("%s: %s\n", args[1], strings.TrimSpace(resp.Status))
 	return nil
 }
 
@@ -316,12 +873,141 @@ func getServiceStatus(cmd *cobra.Command, args []string) error {
 	return cli.getServiceStatus(cmd, args)
 }
 
+func (c *CLI) listServices(cmd *cobra.Command, args []string) error {
+	client := v1.NewSystemdServiceClient(c.conn)
+	resp, err := client.ListServices(context.Background(), &v1.ListServicesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+	if len(resp.Services) == 0 {
+		fmt.Println("No services managed by Mandau")
+		return nil
+	}
+
+	for _, name := range resp.Services {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func listServices(cmd *cobra.Command, args []string) error {
+	return cli.listServices(cmd, args)
+}
+
+func (c *CLI) listInstances(cmd *cobra.Command, args []string) error {
+	client := v1.NewSystemdServiceClient(c.conn)
+	resp, err := client.ListInstances(context.Background(), &v1.ListInstancesRequest{AgentId: args[0], Template: args[1]})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+	if len(resp.Instances) == 0 {
+		fmt.Printf("No instances of %s@.service\n", args[1])
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tSTATE\tENABLED\tENVIRONMENT")
+	for _, i := range resp.Instances {
+		enabled := "no"
+		if i.Enabled {
+			enabled = "yes"
+		}
+		keys := make([]string, 0, len(i.Environment))
+		for key := range i.Environment {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Name, i.State, enabled, orDash(strings.Join(keys, ",")))
+	}
+	return w.Flush()
+}
+
+func listInstances(cmd *cobra.Command, args []string) error {
+	return cli.listInstances(cmd, args)
+}
+
+func (c *CLI) setInstanceEnv(cmd *cobra.Command, args []string) error {
+	req := &v1.SetInstanceEnvironmentRequest{
+		AgentId:     args[0],
+		Template:    args[1],
+		Instance:    args[2],
+		Environment: make(map[string]string),
+	}
+	req.Restart, _ = cmd.Flags().GetBool("restart")
+	req.Force, _ = cmd.Flags().GetBool("force")
+	for _, arg := range args[3:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid variable %q: want KEY=VALUE", arg)
+		}
+		req.Environment[key] = value
+	}
+
+	client := v1.NewSystemdServiceClient(c.conn)
+	if _, err := client.SetInstanceEnvironment(context.Background(), req); err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	name := args[1] + "@" + args[2]
+	switch {
+	case len(req.Environment) == 0:
+		fmt.Printf("✓ Environment file of %s removed\n", name)
+	case req.Restart:
+		fmt.Printf("✓ %d variable(s) set for %s and the instance restarted\n", len(req.Environment), name)
+	default:
+		fmt.Printf("✓ %d variable(s) set for %s; they apply when it next starts\n", len(req.Environment), name)
+	}
+	return nil
+}
+
+func setInstanceEnv(cmd *cobra.Command, args []string) error {
+	return cli.setInstanceEnv(cmd, args)
+}
+
+func (c *CLI) scaleInstances(cmd *cobra.Command, args []string) error {
+	count, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid instance count %q", args[2])
+	}
+
+	client := v1.NewSystemdServiceClient(c.conn)
+	resp, err := client.ScaleInstances(context.Background(), &v1.ScaleInstancesRequest{
+		AgentId:  args[0],
+		Template: args[1],
+		Count:    int32(count),
+	})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+
+	for _, name := range resp.Stopped {
+		fmt.Printf("  → %s stopped\n", name)
+	}
+	fmt.Printf("✓ %d instance(s) of %s running\n", len(resp.Started), args[1])
+	return nil
+}
+
+func scaleInstances(cmd *cobra.Command, args []string) error {
+	return cli.scaleInstances(cmd, args)
+}
+
 func (c *CLI) obtainCertificate(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	email := args[2]
-	fmt.Printf("Obtaining certificate for %s on agent %s (email: %s)\n", domain, agentID, email)
-	fmt.Println("Note: This would call the ACME plugin in the actual implementation")
+	req := &v1.ObtainCertificateRequest{AgentId: args[0], Domain: args[1]}
+	if len(args) > 2 {
+		req.Email = args[2]
+	}
+
+	client := v1.NewACMEServiceClient(c.conn)
+	resp, err := client.ObtainCertificate(context.Background(), req)
+	if err != nil {
+		return hostError(err, args[0], "acme-manager")
+	}
+
+	fmt.Printf("✓ Certificate for %s obtained\n", args[1])
+	if cert := resp.Certificate; cert != nil {
+		fmt.Printf("  Certificate: %s\n", cert.CertPath)
+		fmt.Printf("  Key:         %s\n", cert.KeyPath)
+	}
 	return nil
 }
 
@@ -330,10 +1016,12 @@ func obtainCertificate(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) renewCertificate(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	fmt.Printf("Renewing certificate for %s on agent %s\n", domain, agentID)
-	fmt.Println("Note: This would call the ACME plugin in the actual implementation")
+	client := v1.NewACMEServiceClient(c.conn)
+	if _, err := client.RenewCertificate(context.Background(), &v1.RenewCertificateRequest{AgentId: args[0], Domain: args[1]}); err != nil {
+		return hostError(err, args[0], "acme-manager")
+	}
+
+	fmt.Printf("✓ Certificate for %s renewed\n", args[1])
 	return nil
 }
 
@@ -342,9 +1030,12 @@ func renewCertificate(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) renewAllCertificates(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Renewing all certificates on agent %s\n", agentID)
-	fmt.Println("Note: This would call the ACME plugin in the actual implementation")
+	client := v1.NewACMEServiceClient(c.conn)
+	if _, err := client.RenewAll(context.Background(), &v1.RenewAllCertificatesRequest{AgentId: args[0]}); err != nil {
+		return hostError(err, args[0], "acme-manager")
+	}
+
+	fmt.Println("✓ Certificates due for renewal renewed")
 	return nil
 }
 
@@ -352,23 +1043,62 @@ func renewAllCertificates(cmd *cobra.Command, args []string) error {
 	return cli.renewAllCertificates(cmd, args)
 }
 
-func (c *CLI) listCertificates(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Listing certificates on agent %s\n", agentID)
-	fmt.Println("Note: This would call the ACME plugin in the actual implementation")
+func (c *CLI) revokeCertificate(cmd *cobra.Command, args []string) error {
+	client := v1.NewACMEServiceClient(c.conn)
+	if _, err := client.RevokeCertificate(context.Background(), &v1.RevokeCertificateRequest{AgentId: args[0], Domain: args[1]}); err != nil {
+		return hostError(err, args[0], "acme-manager")
+	}
+
+	fmt.Printf("✓ Certificate for %s revoked\n", args[1])
 	return nil
 }
 
+func revokeCertificate(cmd *cobra.Command, args []string) error {
+	return cli.revokeCertificate(cmd, args)
+}
+
+func (c *CLI) listCertificates(cmd *cobra.Command, args []string) error {
+	client := v1.NewACMEServiceClient(c.conn)
+	resp, err := client.ListCertificates(context.Background(), &v1.ListCertificatesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "acme-manager")
+	}
+	if len(resp.Certificates) == 0 {
+		fmt.Println("No certificates")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tEXPIRES\tCERTIFICATE")
+	for _, cert := range resp.Certificates {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", cert.Domain, cert.ExpiresAt, cert.CertPath)
+	}
+	return w.Flush()
+}
+
 func listCertificates(cmd *cobra.Command, args []string) error {
 	return cli.listCertificates(cmd, args)
 }
 
 func (c *CLI) allowPort(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	port := args[1]
-	protocol := args[2]
-	fmt.Printf("Allowing port %s (%s) on agent %s\n", port, protocol, agentID)
-	fmt.Println("Note: This would call the firewall plugin in the actual implementation")
+	port, err := parsePort(args[1])
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	client := v1.NewFirewallServiceClient(c.conn)
+	resp, err := client.AllowPort(context.Background(), &v1.AllowPortRequest{AgentId: args[0], Port: port, Proto: args[2], DryRun: dryRun})
+	if err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Port %d/%s allowed\n", port, args[2])
 	return nil
 }
 
@@ -377,11 +1107,24 @@ func allowPort(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) denyPort(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	port := args[1]
-	protocol := args[2]
-	fmt.Printf("Denying port %s (%s) on agent %s\n", port, protocol, agentID)
-	fmt.Println("Note: This would call the firewall plugin in the actual implementation")
+	port, err := parsePort(args[1])
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	client := v1.NewFirewallServiceClient(c.conn)
+	resp, err := client.DenyPort(context.Background(), &v1.DenyPortRequest{AgentId: args[0], Port: port, Proto: args[2], DryRun: dryRun})
+	if err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Port %d/%s denied\n", port, args[2])
 	return nil
 }
 
@@ -389,10 +1132,99 @@ func denyPort(cmd *cobra.Command, args []string) error {
 	return cli.denyPort(cmd, args)
 }
 
+func (c *CLI) allowFrom(cmd *cobra.Command, args []string) error {
+	port, err := parsePort(args[2])
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	set, _ := cmd.Flags().GetString("set")
+	limit, _ := cmd.Flags().GetString("limit")
+	burst, _ := cmd.Flags().GetInt("burst")
+
+	client := v1.NewFirewallServiceClient(c.conn)
+	resp, err := client.AddRule(context.Background(), &v1.AddFirewallRuleRequest{
+		AgentId:   args[0],
+		Action:    "allow",
+		Proto:     args[3],
+		FromIp:    args[1],
+		ToPort:    port,
+		RuleSet:   set,
+		RateLimit: limit,
+		RateBurst: int32(burst),
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Port %d/%s allowed from %s\n", port, args[3], args[1])
+	return nil
+}
+
+func allowFrom(cmd *cobra.Command, args []string) error {
+	return cli.allowFrom(cmd, args)
+}
+
+func (c *CLI) removeFirewallRuleSet(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	client := v1.NewFirewallServiceClient(c.conn)
+	resp, err := client.RemoveRuleSet(context.Background(), &v1.RemoveFirewallRuleSetRequest{AgentId: args[0], Name: args[1], DryRun: dryRun})
+	if err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Rule set %s removed (%d rules)\n", args[1], resp.Removed)
+	return nil
+}
+
+func removeFirewallRuleSet(cmd *cobra.Command, args []string) error {
+	return cli.removeFirewallRuleSet(cmd, args)
+}
+
+func (c *CLI) deleteFirewallRule(cmd *cobra.Command, args []string) error {
+	number, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid rule number %q", args[1])
+	}
+
+	client := v1.NewFirewallServiceClient(c.conn)
+	if _, err := client.DeleteRule(context.Background(), &v1.DeleteFirewallRuleRequest{AgentId: args[0], RuleNumber: int32(number)}); err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+
+	fmt.Printf("✓ Rule %d deleted\n", number)
+	return nil
+}
+
+func deleteFirewallRule(cmd *cobra.Command, args []string) error {
+	return cli.deleteFirewallRule(cmd, args)
+}
+
 func (c *CLI) listFirewallRules(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Listing firewall rules on agent %s\n", agentID)
-	fmt.Println("Note: This would call the firewall plugin in the actual implementation")
+	client := v1.NewFirewallServiceClient(c.conn)
+	resp, err := client.ListRules(context.Background(), &v1.ListFirewallRulesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+	if len(resp.Rules) == 0 {
+		fmt.Println("No firewall rules")
+		return nil
+	}
+
+	for _, rule := range resp.Rules {
+		fmt.Println(rule)
+	}
 	return nil
 }
 
@@ -401,9 +1233,12 @@ func listFirewallRules(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) enableFirewall(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Enabling firewall on agent %s\n", agentID)
-	fmt.Println("Note: This would call the firewall plugin in the actual implementation")
+	client := v1.NewFirewallServiceClient(c.conn)
+	if _, err := client.Enable(context.Background(), &v1.EnableFirewallRequest{AgentId: args[0]}); err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+
+	fmt.Printf("✓ Firewall enabled on agent %s\n", args[0])
 	return nil
 }
 
@@ -411,13 +1246,46 @@ func enableFirewall(cmd *cobra.Command, args []string) error {
 	return cli.enableFirewall(cmd, args)
 }
 
+func (c *CLI) disableFirewall(cmd *cobra.Command, args []string) error {
+	client := v1.NewFirewallServiceClient(c.conn)
+	if _, err := client.Disable(context.Background(), &v1.DisableFirewallRequest{AgentId: args[0]}); err != nil {
+		return hostError(err, args[0], "firewall-manager")
+	}
+
+	fmt.Printf("✓ Firewall disabled on agent %s\n", args[0])
+	return nil
+}
+
+func disableFirewall(cmd *cobra.Command, args []string) error {
+	return cli.disableFirewall(cmd, args)
+}
+
 func (c *CLI) addCronJob(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	name := args[1]
-	schedule := args[2]
-	command := args[3]
-	fmt.Printf("Adding cron job '%s' with schedule '%s' and command '%s' on agent %s\n", name, schedule, command, agentID)
-	fmt.Println("Note: This would call the cron plugin in the actual implementation")
+	user, _ := cmd.Flags().GetString("user")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewCronServiceClient(c.conn)
+	resp, err := client.AddCronJob(context.Background(), &v1.AddCronJobRequest{
+		AgentId: args[0],
+		Job: &v1.CronJob{
+			Name:     args[1],
+			Schedule: args[2],
+			Command:  args[3],
+			User:     user,
+		},
+		DryRun: dryRun,
+		Force:  force,
+	})
+	if err != nil {
+		return hostError(err, args[0], "cron-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Cron job %s added (%s)\n", args[1], args[2])
 	return nil
 }
 
@@ -426,10 +1294,20 @@ func addCronJob(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) removeCronJob(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	name := args[1]
-	fmt.Printf("Removing cron job '%s' on agent %s\n", name, agentID)
-	fmt.Println("Note: This would call the cron plugin in the actual implementation")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewCronServiceClient(c.conn)
+	resp, err := client.RemoveCronJob(context.Background(), &v1.RemoveCronJobRequest{AgentId: args[0], Name: args[1], DryRun: dryRun, Force: force})
+	if err != nil {
+		return hostError(err, args[0], "cron-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Cron job %s removed\n", args[1])
 	return nil
 }
 
@@ -438,10 +1316,22 @@ func removeCronJob(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) listCronJobs(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Listing cron jobs on agent %s\n", agentID)
-	fmt.Println("Note: This would call the cron plugin in the actual implementation")
-	return nil
+	client := v1.NewCronServiceClient(c.conn)
+	resp, err := client.ListCronJobs(context.Background(), &v1.ListCronJobsRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "cron-manager")
+	}
+	if len(resp.Jobs) == 0 {
+		fmt.Println("No cron jobs")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tUSER\tCOMMAND")
+	for _, job := range resp.Jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.Name, job.Schedule, job.User, job.Command)
+	}
+	return w.Flush()
 }
 
 func listCronJobs(cmd *cobra.Command, args []string) error {
@@ -449,9 +1339,20 @@ func listCronJobs(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) getHostInfo(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Getting host information on agent %s\n", agentID)
-	fmt.Println("Note: This would call the environment plugin in the actual implementation")
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	info, err := client.GetHostInfo(context.Background(), &v1.GetHostInfoRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	fmt.Printf("Hostname:     %s\n", info.Hostname)
+	fmt.Printf("OS:           %s\n", info.Os)
+	fmt.Printf("Kernel:       %s\n", info.Kernel)
+	fmt.Printf("Architecture: %s\n", info.Architecture)
+	fmt.Printf("CPU cores:    %d\n", info.CpuCores)
+	fmt.Printf("Memory:       %d MB\n", info.MemoryMb)
+	fmt.Printf("Disk:         %d GB\n", info.DiskGb)
+	fmt.Printf("Uptime:       %s\n", info.Uptime)
 	return nil
 }
 
@@ -460,10 +1361,12 @@ func getHostInfo(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) installPackage(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	package_name := args[1]
-	fmt.Printf("Installing package %s on agent %s\n", package_name, agentID)
-	fmt.Println("Note: This would call the environment plugin in the actual implementation")
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	if _, err := client.InstallPackage(context.Background(), &v1.InstallPackageRequest{AgentId: args[0], PackageName: args[1]}); err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	fmt.Printf("✓ Package %s installed\n", args[1])
 	return nil
 }
 
@@ -472,10 +1375,12 @@ func installPackage(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) removePackage(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	package_name := args[1]
-	fmt.Printf("Removing package %s on agent %s\n", package_name, agentID)
-	fmt.Println("Note: This would call the environment plugin in the actual implementation")
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	if _, err := client.RemovePackage(context.Background(), &v1.RemovePackageRequest{AgentId: args[0], PackageName: args[1]}); err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	fmt.Printf("✓ Package %s removed\n", args[1])
 	return nil
 }
 
@@ -484,9 +1389,12 @@ func removePackage(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) updatePackages(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	fmt.Printf("Updating packages on agent %s\n", agentID)
-	fmt.Println("Note: This would call the environment plugin in the actual implementation")
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	if _, err := client.UpdatePackages(context.Background(), &v1.UpdatePackagesRequest{AgentId: args[0]}); err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	fmt.Printf("✓ Packages updated on agent %s\n", args[0])
 	return nil
 }
 
@@ -494,11 +1402,355 @@ func updatePackages(cmd *cobra.Command, args []string) error {
 	return cli.updatePackages(cmd, args)
 }
 
+func (c *CLI) listPackages(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	resp, err := client.ListPackages(context.Background(), &v1.ListPackagesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	for _, pkg := range resp.Packages {
+		fmt.Println(pkg)
+	}
+	return nil
+}
+
+func listPackages(cmd *cobra.Command, args []string) error {
+	return cli.listPackages(cmd, args)
+}
+
+func (c *CLI) sysctl(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+
+	if len(args) == 3 {
+		persist, _ := cmd.Flags().GetBool("persist")
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := client.SetSysctl(context.Background(), &v1.SetSysctlRequest{
+			AgentId: args[0],
+			Key:     args[1],
+			Value:   args[2],
+			Persist: persist,
+			Force:   force,
+		}); err != nil {
+			return hostError(err, args[0], "host-environment")
+		}
+		fmt.Printf("✓ %s = %s\n", args[1], args[2])
+		return nil
+	}
+
+	resp, err := client.GetSysctl(context.Background(), &v1.GetSysctlRequest{AgentId: args[0], Key: args[1]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+	fmt.Printf("%s = %s\n", args[1], resp.Value)
+	return nil
+}
+
+func sysctl(cmd *cobra.Command, args []string) error {
+	return cli.sysctl(cmd, args)
+}
+
+func (c *CLI) timeSync(cmd *cobra.Command, args []string) error {
+	servers, _ := cmd.Flags().GetStringSlice("server")
+	step, _ := cmd.Flags().GetBool("step")
+	force, _ := cmd.Flags().GetBool("force")
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+
+	if len(servers) > 0 {
+		_, err := client.ConfigureTimeSync(context.Background(), &v1.ConfigureTimeSyncRequest{
+			AgentId: args[0],
+			Servers: servers,
+			Step:    step,
+			Force:   force,
+		})
+		if err != nil {
+			return hostError(err, args[0], "host-environment")
+		}
+		fmt.Printf("✓ Agent %s syncs time with %s\n", args[0], strings.Join(servers, ", "))
+		return nil
+	}
+
+	resp, err := client.GetTimeSync(context.Background(), &v1.GetTimeSyncRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+	if resp.Daemon == "" {
+		fmt.Println("No time sync daemon runs; set servers with --server to install chrony")
+		return nil
+	}
+	fmt.Printf("Daemon:       %s\n", resp.Daemon)
+	fmt.Printf("Synchronized: %t\n", resp.Synchronized)
+	if resp.Stratum > 0 {
+		fmt.Printf("Stratum:      %d\n", resp.Stratum)
+		fmt.Printf("Offset:       %s\n", time.Duration(resp.OffsetSeconds*float64(time.Second)).Round(time.Microsecond))
+	}
+	fmt.Printf("Servers:      %s\n", orDash(strings.Join(resp.Servers, ", ")))
+	return nil
+}
+
+func timeSync(cmd *cobra.Command, args []string) error {
+	return cli.timeSync(cmd, args)
+}
+
+func (c *CLI) sysctlProfile(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+
+	if len(args) == 2 {
+		remove, _ := cmd.Flags().GetBool("remove")
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := client.ApplySysctlProfile(context.Background(), &v1.ApplySysctlProfileRequest{
+			AgentId: args[0],
+			Name:    args[1],
+			Remove:  remove,
+			Force:   force,
+		}); err != nil {
+			return hostError(err, args[0], "host-environment")
+		}
+		if remove {
+			fmt.Printf("✓ Sysctl profile %s removed; its values stay until the next boot\n", args[1])
+		} else {
+			fmt.Printf("✓ Sysctl profile %s persisted and loaded\n", args[1])
+		}
+		return nil
+	}
+
+	resp, err := client.ListSysctlProfiles(context.Background(), &v1.ListSysctlProfilesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tAPPLIED\tSETTINGS\tDESCRIPTION")
+	for _, p := range resp.Profiles {
+		applied := "no"
+		if p.Applied {
+			applied = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", p.Name, applied, len(p.Settings), p.Description)
+	}
+	return w.Flush()
+}
+
+func sysctlProfile(cmd *cobra.Command, args []string) error {
+	return cli.sysctlProfile(cmd, args)
+}
+
+func (c *CLI) sysctlVerify(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	resp, err := client.VerifySysctls(context.Background(), &v1.VerifySysctlsRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+	if len(resp.Checks) == 0 {
+		fmt.Println("No sysctls persisted by Mandau")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tPERSISTED\tRUNNING\tFILE\tSTATE")
+	failed := 0
+	for _, check := range resp.Checks {
+		state := "ok"
+		if !check.Ok {
+			state = "MISMATCH"
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", check.Key, check.Persisted, orDash(check.Runtime), check.File, state)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d persisted sysctl(s) differ from the running values", failed, len(resp.Checks))
+	}
+	return nil
+}
+
+func sysctlVerify(cmd *cobra.Command, args []string) error {
+	return cli.sysctlVerify(cmd, args)
+}
+
+func (c *CLI) limits(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+
+	if len(args) >= 2 {
+		req := &v1.SetLimitsRequest{AgentId: args[0], Name: args[1]}
+		req.Force, _ = cmd.Flags().GetBool("force")
+		for _, arg := range args[2:] {
+			limit, err := parseLimit(arg)
+			if err != nil {
+				return err
+			}
+			req.Limits = append(req.Limits, limit)
+		}
+		if _, err := client.SetLimits(context.Background(), req); err != nil {
+			return hostError(err, args[0], "host-environment")
+		}
+		if len(req.Limits) == 0 {
+			fmt.Printf("✓ Limits %s removed\n", args[1])
+		} else {
+			fmt.Printf("✓ %d limit(s) set as %s\n", len(req.Limits), args[1])
+		}
+		return nil
+	}
+
+	resp, err := client.GetLimits(context.Background(), &v1.GetLimitsRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tTYPE\tITEM\tVALUE\tFILE")
+	for _, l := range resp.Limits {
+		file := l.File
+		if l.Managed {
+			file += " (Mandau)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Domain, l.Type, l.Item, l.Value, file)
+	}
+	return w.Flush()
+}
+
+func limits(cmd *cobra.Command, args []string) error {
+	return cli.limits(cmd, args)
+}
+
+func (c *CLI) showDockerDaemon(cmd *cobra.Command, args []string) error {
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	resp, err := client.GetDockerDaemon(context.Background(), &v1.GetDockerDaemonRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "host-environment")
+	}
+
+	if resp.Running {
+		fmt.Printf("Docker runs with log driver %s and storage driver %s\n", resp.RunningLogDriver, resp.RunningStorageDriver)
+	} else {
+		fmt.Println("Docker is not answering")
+	}
+	if resp.Content == "" {
+		fmt.Println("No /etc/docker/daemon.json")
+		return nil
+	}
+	fmt.Printf("\n/etc/docker/daemon.json:\n%s", resp.Content)
+	return nil
+}
+
+func showDockerDaemon(cmd *cobra.Command, args []string) error {
+	return cli.showDockerDaemon(cmd, args)
+}
+
+// dockerDaemonFile is the daemon.json settings applyDockerDaemon reads,
+// under daemon.json's own keys
+type dockerDaemonFile struct {
+	LogDriver           string            `yaml:"log-driver"`
+	LogOpts             map[string]string `yaml:"log-opts"`
+	RegistryMirrors     []string          `yaml:"registry-mirrors"`
+	StorageDriver       string            `yaml:"storage-driver"`
+	StorageOpts         []string          `yaml:"storage-opts"`
+	DefaultAddressPools []struct {
+		Base string `yaml:"base"`
+		Size int32  `yaml:"size"`
+	} `yaml:"default-address-pools"`
+}
+
+func (c *CLI) applyDockerDaemon(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(rest[0])
+	if err != nil {
+		return err
+	}
+	var file dockerDaemonFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse %s: %w", rest[0], err)
+	}
+
+	settings := &v1.DockerDaemonSettings{
+		LogDriver:       file.LogDriver,
+		LogOpts:         file.LogOpts,
+		RegistryMirrors: file.RegistryMirrors,
+		StorageDriver:   file.StorageDriver,
+		StorageOpts:     file.StorageOpts,
+	}
+	for _, pool := range file.DefaultAddressPools {
+		settings.DefaultAddressPools = append(settings.DefaultAddressPools, &v1.DockerAddressPool{Base: pool.Base, Size: pool.Size})
+	}
+	restart, _ := cmd.Flags().GetBool("restart")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	client := v1.NewHostEnvironmentServiceClient(c.conn)
+	for _, agentID := range agents {
+		if restart && !dryRun {
+			fmt.Printf("Applying daemon.json to agent %s and restarting Docker...\n", agentID)
+		}
+		resp, err := client.SetDockerDaemon(ctx, &v1.SetDockerDaemonRequest{
+			AgentId:  agentID,
+			Settings: settings,
+			Restart:  restart,
+			DryRun:   dryRun,
+		})
+		if err != nil {
+			return hostError(err, agentID, "host-environment")
+		}
+
+		switch {
+		case dryRun:
+			if len(agents) > 1 {
+				fmt.Printf("%s:\n", agentID)
+			}
+			printDiff(resp.Diff)
+		case !resp.Changed:
+			fmt.Printf("✓ %s: daemon.json already up to date\n", agentID)
+		case resp.Restarted:
+			fmt.Printf("✓ %s: daemon.json updated and Docker restarted healthy\n", agentID)
+		default:
+			fmt.Printf("✓ %s: daemon.json updated; Docker takes it up when restarted (--restart)\n", agentID)
+		}
+	}
+	return nil
+}
+
+func applyDockerDaemon(cmd *cobra.Command, args []string) error {
+	return cli.applyDockerDaemon(cmd, args)
+}
+
+// parseLimit reads a limit written as domain:type:item=value
+func parseLimit(s string) (*v1.Limit, error) {
+	spec, value, ok := strings.Cut(s, "=")
+	parts := strings.Split(spec, ":")
+	if !ok || len(parts) != 3 || value == "" {
+		return nil, fmt.Errorf("invalid limit %q: want domain:type:item=value, such as '*:soft:nofile=65536'", s)
+	}
+	return &v1.Limit{Domain: parts[0], Type: parts[1], Item: parts[2], Value: value}, nil
+}
+
 func (c *CLI) createDNSZone(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	fmt.Printf("Creating DNS zone for %s on agent %s\n", domain, agentID)
-	fmt.Println("Note: This would call the DNS plugin in the actual implementation")
+	nameservers, _ := cmd.Flags().GetStringSlice("ns")
+	admin, _ := cmd.Flags().GetString("admin")
+	ttl, _ := cmd.Flags().GetInt32("ttl")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewDNSServiceClient(c.conn)
+	resp, err := client.CreateZone(context.Background(), &v1.CreateZoneRequest{
+		AgentId:     args[0],
+		Domain:      args[1],
+		Ttl:         ttl,
+		Nameservers: nameservers,
+		Admin:       admin,
+		DryRun:      dryRun,
+		Force:       force,
+	})
+	if err != nil {
+		return hostError(err, args[0], "dns-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ Zone %s created\n", args[1])
 	return nil
 }
 
@@ -507,12 +1759,29 @@ func createDNSZone(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) addARecord(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	name := args[2]
-	ip := args[3]
-	fmt.Printf("Adding A record %s -> %s for domain %s on agent %s\n", name, ip, domain, agentID)
-	fmt.Println("Note: This would call the DNS plugin in the actual implementation")
+	ttl, _ := cmd.Flags().GetInt32("ttl")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewDNSServiceClient(c.conn)
+	resp, err := client.AddARecord(context.Background(), &v1.AddARecordRequest{
+		AgentId: args[0],
+		Domain:  args[1],
+		Name:    args[2],
+		Ip:      args[3],
+		Ttl:     ttl,
+		DryRun:  dryRun,
+		Force:   force,
+	})
+	if err != nil {
+		return hostError(err, args[0], "dns-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ %s.%s A %s added\n", args[2], args[1], args[3])
 	return nil
 }
 
@@ -521,12 +1790,29 @@ func addARecord(cmd *cobra.Command, args []string) error {
 }
 
 func (c *CLI) addCNAMERecord(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	domain := args[1]
-	name := args[2]
-	target := args[3]
-	fmt.Printf("Adding CNAME record %s -> %s for domain %s on agent %s\n", name, target, domain, agentID)
-	fmt.Println("Note: This would call the DNS plugin in the actual implementation")
+	ttl, _ := cmd.Flags().GetInt32("ttl")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewDNSServiceClient(c.conn)
+	resp, err := client.AddCNAMERecord(context.Background(), &v1.AddCNAMERecordRequest{
+		AgentId: args[0],
+		Domain:  args[1],
+		Name:    args[2],
+		Target:  args[3],
+		Ttl:     ttl,
+		DryRun:  dryRun,
+		Force:   force,
+	})
+	if err != nil {
+		return hostError(err, args[0], "dns-manager")
+	}
+	if dryRun {
+		printDiff(resp.Diff)
+		return nil
+	}
+
+	fmt.Printf("✓ %s.%s CNAME %s added\n", args[2], args[1], args[3])
 	return nil
 }
 
@@ -534,15 +1820,229 @@ func addCNAMERecord(cmd *cobra.Command, args []string) error {
 	return cli.addCNAMERecord(cmd, args)
 }
 
+func (c *CLI) listDNSZones(cmd *cobra.Command, args []string) error {
+	client := v1.NewDNSServiceClient(c.conn)
+	resp, err := client.ListZones(context.Background(), &v1.ListZonesRequest{AgentId: args[0]})
+	if err != nil {
+		return hostError(err, args[0], "dns-manager")
+	}
+	if len(resp.Zones) == 0 {
+		fmt.Println("No zones")
+		return nil
+	}
+	for _, zone := range resp.Zones {
+		fmt.Println(zone)
+	}
+	return nil
+}
+
+func listDNSZones(cmd *cobra.Command, args []string) error {
+	return cli.listDNSZones(cmd, args)
+}
+
+// webServiceFile is the YAML file describing a web service deployment
+type webServiceFile struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Domain      string            `yaml:"domain"`
+	Port        int32             `yaml:"port"` // Port the service listens on locally
+	Command     string            `yaml:"command"`
+	WorkingDir  string            `yaml:"working_dir"`
+	User        string            `yaml:"user"`
+	SSL         bool              `yaml:"ssl"`
+	Environment map[string]string `yaml:"environment"`
+}
+
 func (c *CLI) deployWebService(cmd *cobra.Command, args []string) error {
-	agentID := args[0]
-	configFile := args[1]
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return err
+	}
+	var svc webServiceFile
+	if err := yaml.Unmarshal(data, &svc); err != nil {
+		return fmt.Errorf("parse %s: %w", args[1], err)
+	}
 
-	fmt.Printf("Deploying web service from %s to agent %s\n", configFile, agentID)
-	fmt.Println("Note: This would call the nginx/systemd/ssl plugins in the actual implementation")
-	return nil
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	stream, err := client.DeployWebService(context.Background(), &v1.DeployWebServiceRequest{
+		AgentId:     args[0],
+		Name:        svc.Name,
+		Description: svc.Description,
+		Domain:      svc.Domain,
+		Port:        svc.Port,
+		Command:     svc.Command,
+		WorkingDir:  svc.WorkingDir,
+		User:        svc.User,
+		Ssl:         svc.SSL,
+		Environment: svc.Environment,
+	})
+	if err != nil {
+		return hostError(err, args[0], "systemd-manager")
+	}
+	return c.followDeployment(stream, fmt.Sprintf("Deploying web service %s to agent %s...", svc.Name, args[0]), "Web service deployed")
 }
 
 func deployWebService(cmd *cobra.Command, args []string) error {
 	return cli.deployWebService(cmd, args)
 }
+
+func (c *CLI) deployStaticSite(cmd *cobra.Command, args []string) error {
+	index, _ := cmd.Flags().GetStringSlice("index")
+	ssl, _ := cmd.Flags().GetBool("ssl")
+
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	stream, err := client.DeployStaticSite(context.Background(), &v1.DeployStaticSiteRequest{
+		AgentId: args[0],
+		Name:    args[1],
+		Domain:  args[2],
+		Root:    args[3],
+		Index:   index,
+		Ssl:     ssl,
+	})
+	if err != nil {
+		return err
+	}
+	return c.followDeployment(stream, fmt.Sprintf("Deploying static site %s to agent %s...", args[1], args[0]), "Static site deployed")
+}
+
+func deployStaticSite(cmd *cobra.Command, args []string) error {
+	return cli.deployStaticSite(cmd, args)
+}
+
+func (c *CLI) deployDatabase(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+	port, _ := cmd.Flags().GetInt32("port")
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	password, _ := cmd.Flags().GetString("password")
+	if password == "" {
+		password = os.Getenv("MANDAU_DB_PASSWORD")
+	}
+	schedule, _ := cmd.Flags().GetString("backup-schedule")
+	backupDir, _ := cmd.Flags().GetString("backup-dir")
+	retention, _ := cmd.Flags().GetInt32("backup-retention")
+
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	stream, err := client.DeployDatabase(context.Background(), &v1.DeployDatabaseRequest{
+		AgentId:             args[0],
+		Name:                args[1],
+		Engine:              args[2],
+		Version:             version,
+		Port:                port,
+		DataDir:             dataDir,
+		Password:            password,
+		BackupSchedule:      schedule,
+		BackupDir:           backupDir,
+		BackupRetentionDays: retention,
+	})
+	if err != nil {
+		return err
+	}
+	return c.followDeployment(stream, fmt.Sprintf("Deploying %s database %s to agent %s...", args[2], args[1], args[0]), "Database deployed")
+}
+
+func deployDatabase(cmd *cobra.Command, args []string) error {
+	return cli.deployDatabase(cmd, args)
+}
+
+func (c *CLI) deployWorker(cmd *cobra.Command, args []string) error {
+	description, _ := cmd.Flags().GetString("description")
+	workingDir, _ := cmd.Flags().GetString("working-dir")
+	user, _ := cmd.Flags().GetString("user")
+	env, _ := cmd.Flags().GetStringToString("env")
+
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	stream, err := client.DeployWorker(context.Background(), &v1.DeployWorkerRequest{
+		AgentId:     args[0],
+		Name:        args[1],
+		Description: description,
+		Command:     args[2],
+		WorkingDir:  workingDir,
+		User:        user,
+		Environment: env,
+	})
+	if err != nil {
+		return err
+	}
+	return c.followDeployment(stream, fmt.Sprintf("Deploying worker %s to agent %s...", args[1], args[0]), "Worker deployed")
+}
+
+func deployWorker(cmd *cobra.Command, args []string) error {
+	return cli.deployWorker(cmd, args)
+}
+
+func (c *CLI) listDeployments(cmd *cobra.Command, args []string) error {
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	resp, err := client.ListDeployedServices(context.Background(), &v1.ListDeployedServicesRequest{AgentId: args[0]})
+	if err != nil {
+		return err
+	}
+	if len(resp.Services) == 0 {
+		fmt.Println("No deployments")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tDOMAIN\tSTATE\tDEPLOYED\tRESOURCES")
+	for _, svc := range resp.Services {
+		var resources []string
+		for _, r := range svc.Resources {
+			resources = append(resources, r.Kind+":"+r.Name)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, svc.Kind, svc.Domain, svc.State,
+			svc.DeployedAt.AsTime().Local().Format("2006-01-02 15:04:05"), strings.Join(resources, ", "))
+	}
+	return w.Flush()
+}
+
+func listDeployments(cmd *cobra.Command, args []string) error {
+	return cli.listDeployments(cmd, args)
+}
+
+func (c *CLI) removeDeployment(cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool("force")
+
+	client := v1.NewServiceDeploymentServiceClient(c.conn)
+	stream, err := client.RemoveWebService(context.Background(), &v1.RemoveWebServiceRequest{
+		AgentId: args[0],
+		Name:    args[1],
+		Force:   force,
+	})
+	if err != nil {
+		return err
+	}
+	return c.followDeployment(stream, fmt.Sprintf("Removing %s from agent %s...", args[1], args[0]), "Deployment removed")
+}
+
+func removeDeployment(cmd *cobra.Command, args []string) error {
+	return cli.removeDeployment(cmd, args)
+}
+
+// followDeployment renders the step events of a deployment or removal until
+// the stream ends. A failed step is reported along with each step rolled
+// back after it.
+func (c *CLI) followDeployment(stream interface {
+	Recv() (*v1.ServiceOperationEvent, error)
+}, title, success string) error {
+	p := newProgress(os.Stdout, c.usePlain(), title)
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return p.Done("", err)
+		}
+
+		op := &v1.OperationEvent{OperationId: event.OperationId, Progress: event.Progress}
+		switch event.State {
+		case "RUNNING":
+			op.Message = event.Message
+		case "FAILED":
+			op.Error = event.Error
+		case "ROLLING_BACK":
+			op.Message = "Roll back " + event.Message
+		}
+		p.Event(op)
+	}
+	return p.Done(success, nil)
+}
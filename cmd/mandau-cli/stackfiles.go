@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/transfer"
+)
+
+// appliedFiles are written by applies on the agent, as are hooks, so
+// --files skips them at the top of the directory
+var appliedFiles = map[string]bool{
+	"compose.yaml": true, ".env": true, ".mandau.yaml": true, ".mandau.lock": true,
+	"compose.yaml.prev": true, ".env.prev": true, ".mandau.yaml.prev": true,
+}
+
+// stackFileManifest hashes the regular files under dir, except .git,
+// those applies write and the compose file itself
+func stackFileManifest(dir, composeFile string) ([]*v1.StackFile, error) {
+	compose, err := filepath.Abs(composeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*v1.StackFile
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || rel == "hooks" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if abs, _ := filepath.Abs(path); !d.Type().IsRegular() || appliedFiles[rel] || abs == compose {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		digest, err := transfer.Digest(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		files = append(files, &v1.StackFile{
+			Path:   filepath.ToSlash(rel),
+			Sha256: digest,
+			Size:   info.Size(),
+			Mode:   uint32(info.Mode().Perm()),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read stack files: %w", err)
+	}
+	return files, nil
+}
+
+// syncStackFiles uploads the files of dir an agent lacks to stage them
+// for the apply listing files
+func (c *CLI) syncStackFiles(ctx context.Context, agentID, stackName, dir string, files []*v1.StackFile, limit *transfer.Limiter, emergency bool) error {
+	stackClient := v1.NewStackServiceClient(c.conn)
+	diff, err := stackClient.DiffStackFiles(ctx, &v1.DiffStackFilesRequest{
+		AgentId:   agentID,
+		StackName: stackName,
+		Namespace: c.namespace,
+		Files:     files,
+	})
+	if err != nil {
+		return fmt.Errorf("compare stack files: %w", err)
+	}
+	fmt.Printf("Stack files: %d unchanged, %d to upload, %d to remove\n", diff.Unchanged, len(diff.Changed), len(diff.Removed))
+	if len(diff.Changed) == 0 {
+		return nil
+	}
+
+	stream, err := stackClient.UploadStackFiles(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&v1.StackFileChunk{
+		AgentId:   agentID,
+		StackName: stackName,
+		Namespace: c.namespace,
+		Emergency: emergency,
+	}); err != nil && err != io.EOF {
+		return err
+	}
+
+	// The core answers with headers once it wants the files; a refusal
+	// ends the call before they are sent
+	if md, _ := stream.Header(); md != nil {
+		if err := sendStackFiles(ctx, stream.Send, dir, diff.Changed, limit); err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("upload stack files: %w", err)
+	}
+	fmt.Printf("✓ Uploaded %d file(s), %s\n", resp.Files, quota.FormatMemory(resp.Bytes))
+	return nil
+}
+
+// sendStackFiles sends the files at paths under dir chunk by chunk. io.EOF
+// means the call ended early.
+func sendStackFiles(ctx context.Context, send func(*v1.StackFileChunk) error, dir string, paths []string, limit *transfer.Limiter) error {
+	buf := make([]byte, transfer.ChunkSize)
+	for _, rel := range paths {
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		// The first chunk names the file, even an empty one
+		chunk := &v1.StackFileChunk{Path: rel}
+		for {
+			n, readErr := f.Read(buf)
+			if n > 0 || chunk.Path != "" {
+				if err := limit.Wait(ctx, n); err != nil {
+					f.Close()
+					return err
+				}
+				chunk.Data = buf[:n]
+				if err := send(chunk); err != nil {
+					f.Close()
+					return err
+				}
+				chunk = &v1.StackFileChunk{}
+			}
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			if readErr != nil {
+				f.Close()
+				return readErr
+			}
+		}
+		f.Close()
+	}
+	return nil
+}
+
+// composeOverrides reads the compose files merged over the stack's, which
+// the agent stores under their base names
+func composeOverrides(files []string) ([]*v1.StackContent, error) {
+	var overrides []*v1.StackContent
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read compose override: %w", err)
+		}
+		overrides = append(overrides, &v1.StackContent{Path: filepath.Base(file), Content: data})
+	}
+	return overrides, nil
+}
+
+// projectFiles reads the files under the configs/ and secrets/ directories
+// of dir and its dotenv variants such as .env.prod, except the .env.prev
+// applies keep
+func projectFiles(dir string) ([]*v1.StackContent, error) {
+	var files []*v1.StackContent
+	add := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, &v1.StackContent{Path: filepath.ToSlash(rel), Content: data, Mode: uint32(info.Mode().Perm())})
+		return nil
+	}
+
+	variants, err := filepath.Glob(filepath.Join(dir, ".env.*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range variants {
+		if appliedFiles[filepath.Base(path)] {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(path, fs.FileInfoToDirEntry(info), nil); err != nil {
+			return nil, fmt.Errorf("read project files: %w", err)
+		}
+	}
+	for _, sub := range []string{"configs", "secrets"} {
+		err := filepath.WalkDir(filepath.Join(dir, sub), add)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("read project files: %w", err)
+		}
+	}
+	return files, nil
+}
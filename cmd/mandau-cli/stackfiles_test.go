@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestStackFileManifest(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"compose.yaml":        "services: {}",
+		".env":                "A=1",
+		"nginx/nginx.conf":    "events {}",
+		"scripts/init.sh":     "#!/bin/sh",
+		".git/HEAD":           "ref: refs/heads/main",
+		"prod.compose.yaml":   "services: {}",
+		"nginx/conf.d/.keep":  "",
+		"nginx/compose.yaml":  "not the stack's",
+		"scripts/.env.sample": "A=",
+		"hooks/pre_apply":     "exit 1",
+	} {
+		file := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Chmod(filepath.Join(dir, "scripts/init.sh"), 0700)
+
+	files, err := stackFileManifest(dir, filepath.Join(dir, "prod.compose.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	want := "nginx/compose.yaml,nginx/conf.d/.keep,nginx/nginx.conf,scripts/.env.sample,scripts/init.sh"
+	if got := strings.Join(paths, ","); got != want {
+		t.Errorf("paths = %s, want %s", got, want)
+	}
+	if f := files[1]; f.Size != 0 || f.Sha256 != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("empty file = %v", f)
+	}
+	if f := files[4]; f.Mode != 0700 || f.Size != 9 {
+		t.Errorf("script = %v, want mode 0700 and 9 bytes", f)
+	}
+
+	// Each file starts with a chunk naming it, even when empty
+	var chunks []*v1.StackFileChunk
+	err = sendStackFiles(context.Background(), func(c *v1.StackFileChunk) error {
+		chunks = append(chunks, &v1.StackFileChunk{Path: c.Path, Data: append([]byte(nil), c.Data...)})
+		return nil
+	}, dir, []string{"nginx/conf.d/.keep", "nginx/nginx.conf"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 || chunks[0].Path != "nginx/conf.d/.keep" || len(chunks[0].Data) != 0 ||
+		chunks[1].Path != "nginx/nginx.conf" || string(chunks[1].Data) != "events {}" {
+		t.Errorf("chunks = %v", chunks)
+	}
+}
+
+func TestProjectFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"compose.yaml", ".env", ".env.prod", "configs/nginx/nginx.conf", "secrets/db_password", "scripts/init.sh"} {
+		file := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(path), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := projectFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		if string(f.Content) != f.Path || f.Mode != 0600 {
+			t.Errorf("%s = %q with mode %o", f.Path, f.Content, f.Mode)
+		}
+		paths = append(paths, f.Path)
+	}
+	if got, want := strings.Join(paths, ","), ".env.prod,configs/nginx/nginx.conf,secrets/db_password"; got != want {
+		t.Errorf("paths = %s, want %s", got, want)
+	}
+
+	// Neither directory is required
+	if files, err := projectFiles(t.TempDir()); err != nil || len(files) != 0 {
+		t.Errorf("empty directory: %v, %v", files, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show an overview of the cluster",
+		Long:  "Shows the core version and uptime, agents by status, changes in flight and recently failed, queued instructions, certificates about to expire and the freeze and maintenance flags",
+		Args:  cobra.NoArgs,
+		RunE:  showStatus,
+	})
+}
+
+const statusTimeFormat = "2006-01-02 15:04:05"
+
+func (c *CLI) showStatus(cmd *cobra.Command, args []string) error {
+	st, err := c.coreClient.GetClusterStatus(context.Background(), &v1.GetClusterStatusRequest{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	fmt.Printf("Core:         %s (version %s, up %s)\n",
+		c.endpoint.server, st.Version, formatElapsed(now.Sub(st.StartedAt.AsTime())))
+
+	if st.Freeze.GetFrozen() {
+		fmt.Printf("Freeze:       FROZEN since %s by %s: %s\n",
+			st.Freeze.SetAt.AsTime().Local().Format(statusTimeFormat), st.Freeze.SetBy, st.Freeze.Reason)
+	} else {
+		fmt.Println("Freeze:       off")
+	}
+
+	total := 0
+	for _, n := range st.Agents {
+		total += int(n)
+	}
+	statuses := make([]string, 0, len(st.Agents))
+	for s := range st.Agents {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	counts := make([]string, len(statuses))
+	for i, s := range statuses {
+		counts[i] = fmt.Sprintf("%d %s", st.Agents[s], s)
+	}
+	fmt.Printf("Agents:       %d total", total)
+	if len(counts) > 0 {
+		fmt.Printf(" (%s)", strings.Join(counts, ", "))
+	}
+	fmt.Println()
+
+	if len(st.Maintenance) > 0 {
+		fmt.Printf("Maintenance:  %s\n", strings.Join(st.Maintenance, ", "))
+	} else {
+		fmt.Println("Maintenance:  none")
+	}
+	fmt.Printf("Queued:       %d instruction(s) for offline agents\n", st.QueuedInstructions)
+
+	fmt.Printf("\nIn progress (%d):\n", len(st.Running))
+	for _, op := range st.Running {
+		fmt.Printf("  %-20s %-13s %-20s by %s, %s\n",
+			op.AgentId, op.Kind, op.Stack, op.RequestedBy, formatElapsed(now.Sub(op.StartedAt.AsTime())))
+	}
+
+	fmt.Printf("\nRecent failures (%d):\n", len(st.Failures))
+	for _, op := range st.Failures {
+		failure := op.Error
+		if op.RequestId != "" {
+			failure += " (request " + op.RequestId + ")"
+		}
+		fmt.Printf("  %s %-20s %-13s %-20s %s\n",
+			op.FinishedAt.AsTime().Local().Format(statusTimeFormat), op.AgentId, op.Kind, op.Stack, failure)
+	}
+
+	fmt.Printf("\nClock skew (%d):\n", len(st.ClockSkew))
+	for _, skew := range st.ClockSkew {
+		fmt.Printf("  %-20s %s off the core's clock\n", skew.AgentId, skew.Skew.AsDuration().Round(time.Millisecond))
+	}
+
+	fmt.Printf("\nOpen circuits (%d):\n", len(st.OpenCircuits))
+	for _, circuit := range st.OpenCircuits {
+		fmt.Printf("  %-20s %-9s since %s, %d failed calls: %s\n", circuit.AgentId, circuit.State,
+			circuit.Since.AsTime().Local().Format(statusTimeFormat), circuit.Failures, circuit.LastError)
+	}
+
+	fmt.Printf("\nExpiring certificates (%d):\n", len(st.ExpiringCertificates))
+	for _, cert := range st.ExpiringCertificates {
+		state := "expires"
+		if cert.NotAfter.AsTime().Before(now) {
+			state = "EXPIRED"
+		}
+		fmt.Printf("  %-20s %-30s %s %s\n",
+			cert.Name, cert.Subject, state, cert.NotAfter.AsTime().Local().Format(statusTimeFormat))
+	}
+	return nil
+}
+
+func showStatus(cmd *cobra.Command, args []string) error {
+	return cli.showStatus(cmd, args)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/support"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// supportLogLimit caps the log bytes collected per stack
+const supportLogLimit = 1 << 20
+
+func init() {
+	supportCmd := &cobra.Command{
+		Use:   "support-bundle [agent-id]",
+		Short: "Collect a redacted support bundle for bug reports",
+		Long: "Collect the CLI configuration, versions, core and agent diagnostics, agents, stacks, " +
+			"recent stack logs and approval history into a tarball. Secrets, tokens and private keys " +
+			"are redacted. Without an agent ID every agent is included.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: supportBundle,
+	}
+	supportCmd.Flags().StringP("output", "o", "", "Bundle file (default mandau-support-<time>.tar.gz)")
+	supportCmd.Flags().Bool("no-logs", false, "Do not collect stack logs")
+	rootCmd.AddCommand(supportCmd)
+}
+
+// supportManifest describes a bundle and records what could not be collected
+type supportManifest struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	CLIVersion  string            `json:"cli_version"`
+	GoVersion   string            `json:"go_version"`
+	Platform    string            `json:"platform"`
+	Server      string            `json:"server"`
+	Agents      []string          `json:"agents"`
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+func (c *CLI) supportBundle(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	noLogs, _ := cmd.Flags().GetBool("no-logs")
+
+	now := time.Now().UTC()
+	name := "mandau-support-" + now.Format("20060102-150405")
+	if output == "" {
+		output = name + ".tar.gz"
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	defer f.Close()
+
+	bundle := support.New(f, name, now)
+	manifest := &supportManifest{
+		GeneratedAt: now,
+		CLIVersion:  version,
+		GoVersion:   runtime.Version(),
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		Server:      c.endpoint.server,
+		Errors:      map[string]string{},
+	}
+
+	// add writes one file, recording collection failures in the manifest
+	// instead of aborting the bundle
+	var writeErr error
+	add := func(file string, content supportFile) {
+		if content.err != nil {
+			manifest.Errors[file] = content.err.Error()
+			return
+		}
+		if writeErr == nil {
+			writeErr = bundle.Add(file, content.data)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	add("cli/endpoint.json", marshalJSON(map[string]string{
+		"server":    c.endpoint.server,
+		"cert_file": c.endpoint.tls.CertPath,
+		"ca_file":   c.endpoint.tls.CAPath,
+		"namespace": c.namespace,
+	}))
+	if c.config != nil {
+		data, err := yaml.Marshal(c.config)
+		add("cli/config.yaml", supportFile{data, err})
+	}
+
+	diag, err := c.coreClient.Diagnose(ctx, &v1.DiagnoseRequest{})
+	add("core/diagnose.json", marshalProto(diag, err))
+
+	approvals, err := c.coreClient.ListApprovals(ctx, &v1.ListApprovalsRequest{})
+	add("core/approvals.json", marshalProto(approvals, err))
+
+	agents, err := c.coreClient.ListAgents(ctx, &v1.ListAgentsRequest{})
+	add("core/agents.json", marshalProto(agents, err))
+
+	if len(args) > 0 {
+		manifest.Agents = args[:1]
+	} else if agents != nil {
+		for _, agent := range agents.Agents {
+			manifest.Agents = append(manifest.Agents, agent.Id)
+		}
+	}
+
+	stackClient := v1.NewStackServiceClient(c.conn)
+	for _, agentID := range manifest.Agents {
+		dir := "agents/" + agentID + "/"
+
+		diag, err := c.coreClient.Diagnose(ctx, &v1.DiagnoseRequest{AgentId: agentID})
+		add(dir+"diagnose.json", marshalProto(diag, err))
+
+		stacks, err := stackClient.ListStacks(ctx, &v1.ListStacksRequest{AgentId: agentID})
+		add(dir+"stacks.json", marshalProto(stacks, err))
+
+		if noLogs || stacks == nil {
+			continue
+		}
+		for _, stack := range stacks.Stacks {
+			file := fmt.Sprintf("%slogs/%s/%s.log", dir, normalizeSupportNamespace(stack.Namespace), stack.Name)
+			data, err := c.collectStackLogs(ctx, stackClient, agentID, stack)
+			add(file, supportFile{data, err})
+		}
+	}
+
+	add("manifest.json", marshalJSON(manifest))
+	if writeErr != nil {
+		return fmt.Errorf("write bundle: %w", writeErr)
+	}
+	if err := bundle.Close(); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	fmt.Printf("✓ Support bundle written to %s\n", output)
+	if len(manifest.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %d item(s) could not be collected; see manifest.json\n", len(manifest.Errors))
+	}
+	return nil
+}
+
+func supportBundle(cmd *cobra.Command, args []string) error {
+	return cli.supportBundle(cmd, args)
+}
+
+// collectStackLogs reads the recent logs of a stack without following them,
+// keeping at most supportLogLimit bytes
+func (c *CLI) collectStackLogs(ctx context.Context, client v1.StackServiceClient, agentID string, stack *v1.Stack) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	stream, err := client.GetStackLogs(ctx, &v1.GetStackLogsRequest{
+		AgentId:   agentID,
+		StackName: stack.Name,
+		Namespace: stack.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for b.Len() < supportLogLimit {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s [%s] [%s] %s\n",
+			entry.Timestamp.AsTime().Format(time.RFC3339), entry.ServiceName, entry.Stream,
+			strings.TrimRight(string(entry.Content), "\n"))
+	}
+	return []byte(b.String()), nil
+}
+
+func normalizeSupportNamespace(ns string) string {
+	if ns == "" {
+		return "default"
+	}
+	return ns
+}
+
+// supportFile is a bundle file's content or the error collecting it
+type supportFile struct {
+	data []byte
+	err  error
+}
+
+func marshalJSON(v any) supportFile {
+	data, err := json.MarshalIndent(v, "", "  ")
+	return supportFile{data, err}
+}
+
+func marshalProto(m proto.Message, err error) supportFile {
+	if err != nil {
+		return supportFile{err: err}
+	}
+	data, err := protojson.MarshalOptions{Multiline: true}.Marshal(m)
+	return supportFile{data, err}
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// transferAttempts is how often a transfer is tried before giving up
+	transferAttempts = 5
+	// transferBackoff is the wait before the second attempt; later waits
+	// grow with each attempt
+	transferBackoff = 2 * time.Second
+)
+
+func init() {
+	transferCmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Resumable, bandwidth-limited uploads to and downloads from agents",
+	}
+
+	uploadCmd := &cobra.Command{
+		Use:   "upload <agent-id> <stack-name> <file>",
+		Short: "Upload a file into a stack's directory",
+		Long: "Upload a file into the directory of a stack, at --path or under its own name. The file is sent " +
+			"in chunks; when the link drops the upload resumes where the agent stopped receiving, also when " +
+			"the command is run again. The agent checks the file's digest before placing it. " +
+			"Needs write on the stack.",
+		Args: cobra.ExactArgs(3),
+		RunE: uploadFile,
+	}
+	uploadCmd.Flags().String("path", "", "Path in the stack directory (default: the file's name)")
+	uploadCmd.Flags().String("bandwidth", "", "Bytes per second to send at most, e.g. 10M")
+	uploadCmd.Flags().Bool("emergency", false, "Upload even if the agent is in maintenance (needs the emergency permission)")
+
+	backupsCmd := &cobra.Command{
+		Use:   "backups <agent-id>",
+		Short: "List the backups an agent holds",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listBackups,
+	}
+
+	downloadCmd := &cobra.Command{
+		Use:   "download <agent-id> <backup-path>",
+		Short: "Download a backup from an agent",
+		Long: "Download a backup listed by mandau transfer backups. Data is written to <output>.part and " +
+			"renamed once complete; running the command again resumes from the end of the .part file. " +
+			"Needs read on host:backup.",
+		Args: cobra.ExactArgs(2),
+		RunE: downloadBackup,
+	}
+	downloadCmd.Flags().StringP("output", "o", "", "File to write (default: the backup's name)")
+	downloadCmd.Flags().String("bandwidth", "", "Bytes per second to receive at most, e.g. 10M")
+
+	fetchCmd := &cobra.Command{
+		Use:   "fetch [agent-id] <stack-name> <digest>",
+		Short: "Have agents fetch an artifact from the core into a stack",
+		Long: "Have an agent, or every agent in --group, fetch an artifact pushed with mandau artifact push " +
+			"from the core by its digest. Image bundles are loaded into Docker; other artifacts are placed " +
+			"in the stack's directory at --path or under the name they were pushed with. An interrupted " +
+			"fetch resumes where the agent stopped receiving. Needs write on the stack.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: fetchArtifact,
+	}
+	fetchCmd.Flags().String("path", "", "Path in the stack directory (default: the artifact's name)")
+	fetchCmd.Flags().String("group", "", "Target every agent in this group")
+	fetchCmd.Flags().Bool("emergency", false, "Fetch even if the agent is in maintenance (needs the emergency permission)")
+
+	transferCmd.AddCommand(uploadCmd, backupsCmd, downloadCmd, fetchCmd)
+	rootCmd.AddCommand(transferCmd)
+}
+
+// bandwidthFlag reads --bandwidth into a limiter, nil when unset
+func bandwidthFlag(cmd *cobra.Command) (*transfer.Limiter, error) {
+	value, _ := cmd.Flags().GetString("bandwidth")
+	rate, err := transfer.ParseRate(value)
+	if err != nil {
+		return nil, fmt.Errorf("--bandwidth: %w", err)
+	}
+	return transfer.NewLimiter(rate), nil
+}
+
+func (c *CLI) uploadFile(cmd *cobra.Command, args []string) error {
+	agentID, stackName, file := args[0], args[1], args[2]
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
+		path = filepath.Base(file)
+	}
+	emergency, _ := cmd.Flags().GetBool("emergency")
+	limit, err := bandwidthFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	target := &v1.TransferTarget{
+		Kind:      v1.TransferKind_TRANSFER_KIND_FILE,
+		StackName: stackName,
+		Namespace: c.namespace,
+		Path:      path,
+	}
+	if _, err := c.upload(context.Background(), agentID, target, file, limit, emergency); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Uploaded %s to %s/%s on agent %s\n", file, stackName, path, agentID)
+	return nil
+}
+
+func uploadFile(cmd *cobra.Command, args []string) error {
+	return cli.uploadFile(cmd, args)
+}
+
+// retryableTransfer reports whether a transfer that failed with err is
+// worth resuming: the link or agent dropped, another call still held the
+// upload, or the payload arrived damaged and starts over
+func retryableTransfer(err error) bool {
+	switch status.Code(err) {
+	case codes.Aborted, codes.DataLoss:
+		return true
+	}
+	return exitCode(err) == exitUnavailable
+}
+
+// upload sends file to target on an agent, resuming from what the agent
+// holds of it, and retries when the transfer is interrupted
+func (c *CLI) upload(ctx context.Context, agentID string, target *v1.TransferTarget, file string, limit *transfer.Limiter, emergency bool) (*v1.TransferStatus, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	digest, err := transfer.Digest(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+	kind := strings.ToLower(strings.TrimPrefix(target.Kind.String(), "TRANSFER_KIND_"))
+	req := &v1.UploadRequest{
+		AgentId:    agentID,
+		TransferId: transfer.ID(kind, target.StackName, target.Namespace, target.Path, digest),
+		Target:     target,
+		Size:       info.Size(),
+		Sha256:     digest,
+		Emergency:  emergency,
+	}
+
+	fmt.Printf("Uploading %s (%s) to agent %s...\n", file, quota.FormatMemory(info.Size()), agentID)
+	transfers := v1.NewTransferServiceClient(c.conn)
+	for attempt := 1; ; attempt++ {
+		st, err := c.uploadFrom(ctx, transfers, f, req, limit)
+		if err == nil {
+			return st, nil
+		}
+		if attempt == transferAttempts || !retryableTransfer(err) {
+			return nil, fmt.Errorf("upload %s: %w", file, err)
+		}
+		wait := time.Duration(attempt) * transferBackoff
+		fmt.Printf("Upload interrupted (%v), resuming in %s...\n", status.Convert(err).Message(), wait)
+		time.Sleep(wait)
+	}
+}
+
+// uploadFrom sends what the agent lacks of f in one call
+func (c *CLI) uploadFrom(ctx context.Context, transfers v1.TransferServiceClient, f *os.File, req *v1.UploadRequest, limit *transfer.Limiter) (*v1.TransferStatus, error) {
+	st, err := transfers.GetTransfer(ctx, &v1.GetTransferRequest{AgentId: req.AgentId, TransferId: req.TransferId, Target: req.Target})
+	switch {
+	case status.Code(err) == codes.NotFound:
+		req.Offset = 0
+	case err != nil:
+		return nil, err
+	case st.Delivered:
+		return st, nil
+	default:
+		req.Offset = st.Received
+		if st.Received > 0 {
+			fmt.Printf("Resuming at %s of %s\n", quota.FormatMemory(st.Received), quota.FormatMemory(st.Size))
+		}
+	}
+	if _, err := f.Seek(req.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	stream, err := transfers.Upload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(req); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// The core answers with headers once it accepted the upload; a refusal
+	// ends the call before the payload is sent
+	if md, _ := stream.Header(); md != nil {
+		buf := make([]byte, transfer.ChunkSize)
+		for {
+			n, readErr := f.Read(buf)
+			if n > 0 {
+				if err := limit.Wait(ctx, n); err != nil {
+					return nil, err
+				}
+				// EOF means the call ended early; CloseAndRecv says why
+				if err := stream.Send(&v1.UploadRequest{Chunk: buf[:n]}); err == io.EOF {
+					break
+				} else if err != nil {
+					return nil, err
+				}
+			}
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			if readErr != nil {
+				return nil, readErr
+			}
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func (c *CLI) fetchArtifact(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	agents, rest, err := c.targetAgents(ctx, cmd, args, 2)
+	if err != nil {
+		return err
+	}
+	stackName, digest := rest[0], rest[1]
+	path, _ := cmd.Flags().GetString("path")
+	emergency, _ := cmd.Flags().GetBool("emergency")
+
+	a, err := v1.NewArtifactServiceClient(c.conn).GetArtifact(ctx, &v1.GetArtifactRequest{Digest: digest})
+	if err != nil {
+		return err
+	}
+	target := &v1.TransferTarget{Kind: v1.TransferKind_TRANSFER_KIND_FILE, StackName: stackName, Namespace: c.namespace, Path: path}
+	if a.Kind == "image-bundle" {
+		target.Kind = v1.TransferKind_TRANSFER_KIND_IMAGE_BUNDLE
+		target.Path = ""
+	} else if target.Path == "" {
+		if len(a.Names) == 0 {
+			return fmt.Errorf("artifact %s has no name; set --path", digest)
+		}
+		target.Path = a.Names[0]
+	}
+
+	for _, agentID := range agents {
+		st, err := c.fetch(ctx, agentID, target, digest, emergency)
+		if err != nil {
+			return fmt.Errorf("agent %s: %w", agentID, err)
+		}
+		if len(st.Images) > 0 {
+			fmt.Printf("✓ Agent %s loaded %d image(s): %s\n", agentID, len(st.Images), strings.Join(st.Images, ", "))
+		} else {
+			fmt.Printf("✓ Agent %s placed %s in %s/%s\n", agentID, digest, stackName, target.Path)
+		}
+	}
+	return nil
+}
+
+func fetchArtifact(cmd *cobra.Command, args []string) error {
+	return cli.fetchArtifact(cmd, args)
+}
+
+// fetch has an agent fetch an artifact from the core into target, and
+// retries when the fetch is interrupted; the agent resumes it
+func (c *CLI) fetch(ctx context.Context, agentID string, target *v1.TransferTarget, digest string, emergency bool) (*v1.TransferStatus, error) {
+	transfers := v1.NewTransferServiceClient(c.conn)
+	req := &v1.FetchRequest{AgentId: agentID, Target: target, Digest: digest, Emergency: emergency}
+
+	fmt.Printf("Agent %s is fetching %s from the core...\n", agentID, digest)
+	for attempt := 1; ; attempt++ {
+		st, err := transfers.Fetch(ctx, req)
+		if err == nil {
+			return st, nil
+		}
+		if attempt == transferAttempts || !retryableTransfer(err) {
+			return nil, fmt.Errorf("fetch %s: %w", digest, err)
+		}
+		wait := time.Duration(attempt) * transferBackoff
+		fmt.Printf("Fetch interrupted (%v), resuming in %s...\n", status.Convert(err).Message(), wait)
+		time.Sleep(wait)
+	}
+}
+
+func (c *CLI) listBackups(cmd *cobra.Command, args []string) error {
+	resp, err := v1.NewTransferServiceClient(c.conn).ListBackups(context.Background(), &v1.ListBackupsRequest{AgentId: args[0]})
+	if err != nil {
+		return err
+	}
+	if len(resp.Backups) == 0 {
+		fmt.Println("No backups found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSIZE\tMODIFIED")
+	for _, b := range resp.Backups {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", b.Path, quota.FormatMemory(b.Size), b.Modified.AsTime().Local().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func listBackups(cmd *cobra.Command, args []string) error {
+	return cli.listBackups(cmd, args)
+}
+
+func (c *CLI) downloadBackup(cmd *cobra.Command, args []string) error {
+	agentID, path := args[0], args[1]
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = filepath.Base(path)
+	}
+	limit, err := bandwidthFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	part := output + ".part"
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", part, err)
+	}
+	defer f.Close()
+
+	ctx := context.Background()
+	for attempt := 1; ; attempt++ {
+		err = c.downloadTo(ctx, f, &v1.DownloadBackupRequest{AgentId: agentID, Path: path}, limit)
+		if err == nil {
+			break
+		}
+		if attempt == transferAttempts || !retryableTransfer(err) {
+			return fmt.Errorf("download %s: %w", path, err)
+		}
+		wait := time.Duration(attempt) * transferBackoff
+		fmt.Printf("Download interrupted (%v), resuming in %s...\n", status.Convert(err).Message(), wait)
+		time.Sleep(wait)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(part, output); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Downloaded %s to %s\n", path, output)
+	return nil
+}
+
+func downloadBackup(cmd *cobra.Command, args []string) error {
+	return cli.downloadBackup(cmd, args)
+}
+
+// downloadTo appends what f lacks of a backup in one call
+func (c *CLI) downloadTo(ctx context.Context, f *os.File, req *v1.DownloadBackupRequest, limit *transfer.Limiter) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	req.Offset = info.Size()
+
+	stream, err := v1.NewTransferServiceClient(c.conn).DownloadBackup(ctx, req)
+	if err != nil {
+		return err
+	}
+	offset := req.Offset
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if offset == req.Offset {
+			if offset > 0 {
+				fmt.Printf("Resuming at %s of %s\n", quota.FormatMemory(offset), quota.FormatMemory(chunk.Size))
+			} else {
+				fmt.Printf("Downloading %s (%s) from agent %s...\n", req.Path, quota.FormatMemory(chunk.Size), req.AgentId)
+			}
+		}
+		if chunk.Offset != offset {
+			return fmt.Errorf("agent sent data at %d, expected %d", chunk.Offset, offset)
+		}
+		if _, err := f.Write(chunk.Data); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name(), err)
+		}
+		offset += int64(len(chunk.Data))
+		if err := limit.Wait(ctx, len(chunk.Data)); err != nil {
+			return err
+		}
+	}
+}
@@ -86,6 +86,7 @@ func main() {
 	coreConfig.CAPath = cfg.Server.TLS.CAPath
 	coreConfig.PluginDir = cfg.PluginDir
 	coreConfig.FullConfig = cfg
+	coreConfig.Version = version
 
 	// Override with command-line flags if provided
 	if *listenAddr != "" {
@@ -0,0 +1,160 @@
+// Package command runs the commands an agent's config allows by name, for
+// mandau fleet run, keeping their exit code and a sample of their output.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+const (
+	// DefaultTimeout bounds commands configured without a timeout
+	DefaultTimeout = time.Minute
+	// SampleSize is how much of the end of stdout and of stderr is kept
+	SampleSize = 4096
+	// waitDelay is how long output is still read after a timed out command
+	// is killed, in case it left children holding its pipes
+	waitDelay = time.Second
+)
+
+// ErrNotAllowed is returned for commands the agent config does not list
+var ErrNotAllowed = errors.New("not in commands")
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Command is one entry of the commands allowlist
+type Command struct {
+	Name        string
+	Description string
+	Argv        []string
+	Timeout     time.Duration
+}
+
+// Allowlist holds the commands that may be run, by name
+type Allowlist map[string]Command
+
+// New reads the commands entries of the agent config. Scripts run with
+// /bin/sh -c.
+func New(cfg config.CommandConfig) (Command, error) {
+	if !validName.MatchString(cfg.Name) {
+		return Command{}, fmt.Errorf("invalid name %q: use letters, digits, '.', '_' and '-'", cfg.Name)
+	}
+	cmd := Command{Name: cfg.Name, Description: cfg.Description, Timeout: DefaultTimeout}
+	switch {
+	case len(cfg.Command) > 0 && cfg.Script != "":
+		return Command{}, fmt.Errorf("%s: set command or script, not both", cfg.Name)
+	case len(cfg.Command) > 0:
+		cmd.Argv = cfg.Command
+	case cfg.Script != "":
+		cmd.Argv = []string{"/bin/sh", "-c", cfg.Script}
+	default:
+		return Command{}, fmt.Errorf("%s: set command or script", cfg.Name)
+	}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || d <= 0 {
+			return Command{}, fmt.Errorf("%s: invalid timeout %q", cfg.Name, cfg.Timeout)
+		}
+		cmd.Timeout = d
+	}
+	return cmd, nil
+}
+
+// Get returns the named command
+func (l Allowlist) Get(name string) (Command, error) {
+	cmd, ok := l[name]
+	if !ok {
+		return Command{}, fmt.Errorf("%s: %w", name, ErrNotAllowed)
+	}
+	return cmd, nil
+}
+
+// Commands lists the allowed commands by name
+func (l Allowlist) Commands() []Command {
+	cmds := make([]Command, 0, len(l))
+	for _, cmd := range l {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+// Result is how a run of a command ended
+type Result struct {
+	ExitCode  int // -1 when it did not exit by itself
+	Stdout    []byte
+	Stderr    []byte
+	Truncated bool // Output was longer than SampleSize and only its end kept
+	Duration  time.Duration
+	Err       error // Why it did not run to completion: not started or timed out
+}
+
+// Run runs cmd for at most its own timeout, or the shortest of limits
+// when that is shorter; limits that are not positive are ignored. A timed
+// out command is killed with the processes it started.
+func (c Command) Run(ctx context.Context, limits ...time.Duration) Result {
+	timeout := c.Timeout
+	for _, limit := range limits {
+		if limit > 0 && limit < timeout {
+			timeout = limit
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr tail
+	cmd := exec.CommandContext(ctx, c.Argv[0], c.Argv[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.WaitDelay = waitDelay
+	killGroup(cmd)
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{
+		ExitCode:  -1,
+		Stdout:    stdout.bytes(),
+		Stderr:    stderr.bytes(),
+		Truncated: stdout.cut || stderr.cut,
+		Duration:  time.Since(start),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case ctx.Err() != nil:
+		result.Err = ctx.Err()
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.Err = err
+	default:
+		result.ExitCode = 0
+	}
+	return result
+}
+
+// tail keeps the last SampleSize bytes written to it
+type tail struct {
+	buf []byte
+	cut bool
+}
+
+func (t *tail) Write(p []byte) (int, error) {
+	n := len(p)
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - SampleSize; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+		t.cut = true
+	}
+	return n, nil
+}
+
+func (t *tail) bytes() []byte { return t.buf }
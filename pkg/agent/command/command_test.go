@@ -0,0 +1,80 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	cmd, err := New(config.CommandConfig{Name: "disk-usage", Command: []string{"df", "-h"}})
+	if err != nil || cmd.Timeout != DefaultTimeout || len(cmd.Argv) != 2 {
+		t.Errorf("command: %+v, %v", cmd, err)
+	}
+	cmd, err = New(config.CommandConfig{Name: "uptime", Script: "uptime | cut -d, -f1", Timeout: "5s"})
+	if err != nil || cmd.Timeout != 5*time.Second || cmd.Argv[0] != "/bin/sh" || cmd.Argv[2] != "uptime | cut -d, -f1" {
+		t.Errorf("script: %+v, %v", cmd, err)
+	}
+
+	for _, bad := range []config.CommandConfig{
+		{Name: "", Command: []string{"true"}},
+		{Name: "../x", Command: []string{"true"}},
+		{Name: "both", Command: []string{"true"}, Script: "true"},
+		{Name: "neither"},
+		{Name: "slow", Command: []string{"true"}, Timeout: "forever"},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("New(%+v) succeeded", bad)
+		}
+	}
+
+	l := Allowlist{"uptime": cmd}
+	if _, err := l.Get("reboot"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Get(reboot) error = %v, want ErrNotAllowed", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	script := func(s string, timeout time.Duration) Command {
+		return Command{Name: "test", Argv: []string{"/bin/sh", "-c", s}, Timeout: timeout}
+	}
+
+	r := script("echo out; echo err >&2; exit 3", time.Minute).Run(ctx, 0)
+	if r.Err != nil || r.ExitCode != 3 || string(r.Stdout) != "out\n" || string(r.Stderr) != "err\n" || r.Truncated {
+		t.Errorf("exit 3: %+v", r)
+	}
+
+	// Only the end of long output is kept
+	r = script("seq 1 5000", time.Minute).Run(ctx, 0)
+	if r.ExitCode != 0 || !r.Truncated || len(r.Stdout) != SampleSize || !bytes.HasSuffix(r.Stdout, []byte("\n4999\n5000\n")) {
+		t.Errorf("long output: exit %d, truncated %v, %d bytes ending %q", r.ExitCode, r.Truncated, len(r.Stdout), r.Stdout[len(r.Stdout)-10:])
+	}
+
+	// The shorter of the command's and the caller's timeout applies
+	r = script("echo started; sleep 10", 100*time.Millisecond).Run(ctx, time.Minute)
+	if r.ExitCode != -1 || r.Err == nil || !strings.Contains(r.Err.Error(), "timed out after 100ms") || string(r.Stdout) != "started\n" {
+		t.Errorf("command timeout: %+v", r)
+	}
+	r = script("sleep 10", time.Minute).Run(ctx, 50*time.Millisecond)
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "timed out after 50ms") || r.Duration > 5*time.Second {
+		t.Errorf("caller timeout: %+v", r)
+	}
+
+	// Of several limits, such as the caller's and their role's, the
+	// shortest applies
+	r = script("sleep 10", time.Minute).Run(ctx, time.Minute, 50*time.Millisecond, 0)
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "timed out after 50ms") {
+		t.Errorf("shortest limit: %+v", r)
+	}
+
+	r = Command{Name: "missing", Argv: []string{"/nonexistent/mandau-test"}, Timeout: time.Minute}.Run(ctx, 0)
+	if r.Err == nil || r.ExitCode != -1 {
+		t.Errorf("missing program: %+v", r)
+	}
+}
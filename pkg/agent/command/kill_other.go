@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd)
+
+package command
+
+import "os/exec"
+
+// killGroup leaves cmd as it is; cancelling it kills only the command
+// itself, as there are no process groups here
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroup runs cmd in a process group of its own and makes cancelling it
+// kill the whole group, so children of a script do not outlive it
+func killGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
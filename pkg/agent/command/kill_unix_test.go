@@ -0,0 +1,53 @@
+//go:build linux || darwin || freebsd
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// running reports whether pid is a live process; zombies waiting for a
+// parent that does not reap them count as gone
+func running(pid int) bool {
+	if errors.Is(syscall.Kill(pid, 0), syscall.ESRCH) {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return !os.IsNotExist(err) && !errors.Is(err, syscall.ESRCH)
+	}
+	end := strings.LastIndexByte(string(stat), ')')
+	return end < 0 || !strings.HasPrefix(string(stat[end+1:]), " Z")
+}
+
+func TestRunKillsChildren(t *testing.T) {
+	cmd := Command{Name: "test", Argv: []string{"/bin/sh", "-c", "sleep 30 & echo $!; wait"}, Timeout: time.Minute}
+	r := cmd.Run(context.Background(), 200*time.Millisecond)
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "timed out") {
+		t.Fatalf("result: %+v, want a timeout", r)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(r.Stdout)))
+	if err != nil {
+		t.Fatalf("stdout = %q, want the child's PID", r.Stdout)
+	}
+	if r.Duration > 5*time.Second {
+		t.Errorf("took %s; output was held open past the timeout", r.Duration)
+	}
+
+	// Killing is immediate, but the child may take a moment to be reaped
+	deadline := time.Now().Add(5 * time.Second)
+	for running(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child %d still running after the command timed out", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
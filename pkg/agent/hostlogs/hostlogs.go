@@ -0,0 +1,365 @@
+// Package hostlogs reads and follows the log files of the host an agent
+// runs on, limited to an allowlist of paths operators configure.
+package hostlogs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ErrNotAllowed is returned for files outside the allowlist
+var ErrNotAllowed = errors.New("not in logs.files")
+
+const (
+	// DefaultLines is how many lines are shown before following, as tail
+	DefaultLines = 100
+	// maxLine caps a line; longer ones are cut
+	maxLine = 64 * 1024
+	// pollInterval is how often a followed file is checked for growth
+	pollInterval = 500 * time.Millisecond
+)
+
+// Allowlist holds the files that may be read, as absolute paths or
+// filepath.Match patterns such as /var/log/nginx/*.log
+type Allowlist []string
+
+// Resolve checks that path is allowed and returns it cleaned. Symbolic
+// links must resolve to an allowed file too, so a link under an allowed
+// directory cannot expose another file.
+func (l Allowlist) Resolve(path string) (string, error) {
+	path, _, err := l.resolve(path)
+	return path, err
+}
+
+// resolve is Resolve, also returning the file path links to
+func (l Allowlist) resolve(path string) (string, string, error) {
+	if !filepath.IsAbs(path) {
+		return "", "", fmt.Errorf("%s: path must be absolute", path)
+	}
+	path = filepath.Clean(path)
+	if !l.match(path) {
+		return "", "", fmt.Errorf("%s: %w", path, ErrNotAllowed)
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", "", err
+	}
+	if real != path && !l.match(real) {
+		return "", "", fmt.Errorf("%s links to %s: %w", path, real, ErrNotAllowed)
+	}
+	return path, real, nil
+}
+
+// open opens path if Resolve accepts it. The file opened must be the one
+// checked, so a link swapped in between is refused rather than followed.
+func (l Allowlist) open(path string) (*os.File, os.FileInfo, error) {
+	path, real, err := l.resolve(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if checked, err := os.Lstat(real); err != nil || !os.SameFile(info, checked) {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s changed while being opened: %w", path, ErrNotAllowed)
+	}
+	return f, info, nil
+}
+
+func (l Allowlist) match(path string) bool {
+	for _, pattern := range l {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Files lists the existing regular files the allowlist matches and Resolve
+// accepts
+func (l Allowlist) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range l {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if _, err := l.Resolve(path); err != nil || seen[path] {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Options select what Tail sends
+type Options struct {
+	Lines  int            // Last lines to start from; 0 is DefaultLines, negative the whole file
+	Since  time.Time      // Only lines stamped at or after, read from the start of the file
+	Grep   *regexp.Regexp // Only matching lines
+	Follow bool           // Keep sending lines appended to the file
+}
+
+// Line is one line of a log file. Time is its timestamp when it has one
+// Tail recognizes, else that of the line before it; zero when no line has
+// had one yet.
+type Line struct {
+	Time time.Time
+	Text string
+}
+
+// Tail sends the lines of an allowed file selected by opts. Following, it
+// returns when ctx is done, reopening the file when it is rotated or
+// truncated. Every reopen is checked against the allowlist as the first
+// open is, so a rotated log replaced by a link to another file stops the
+// tail with ErrNotAllowed instead of streaming that file.
+func (l Allowlist) Tail(ctx context.Context, path string, opts Options, send func(Line) error) error {
+	f, info, err := l.open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+
+	var offset int64
+	if opts.Since.IsZero() && opts.Lines >= 0 {
+		lines := opts.Lines
+		if lines == 0 {
+			lines = DefaultLines
+		}
+		if offset, err = lastLines(f, info.Size(), lines); err != nil {
+			return err
+		}
+	}
+
+	t := &tailer{opts: opts, send: send, now: time.Now()}
+	if offset, err = t.read(f, offset); err != nil {
+		return err
+	}
+	if !opts.Follow {
+		return t.flush()
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(path)
+		if err != nil {
+			continue // Rotated away; wait for the new file
+		}
+		if !os.SameFile(info, current) || current.Size() < offset {
+			// Finish the rotated file, then start the new one from the top
+			if !os.SameFile(info, current) {
+				if _, err := t.read(f, offset); err != nil {
+					return err
+				}
+			}
+			next, nextInfo, err := l.open(path)
+			if errors.Is(err, ErrNotAllowed) {
+				if err := t.flush(); err != nil {
+					return err
+				}
+				return err
+			}
+			if err != nil {
+				continue
+			}
+			if !nextInfo.Mode().IsRegular() {
+				next.Close()
+				return fmt.Errorf("%s is not a regular file", path)
+			}
+			if err := t.flush(); err != nil {
+				next.Close()
+				return err
+			}
+			f.Close()
+			f, info, offset = next, nextInfo, 0
+		}
+		if offset, err = t.read(f, offset); err != nil {
+			return err
+		}
+	}
+}
+
+// tailer filters and sends the lines read
+type tailer struct {
+	opts    Options
+	send    func(Line) error
+	now     time.Time // For timestamps without a year
+	last    time.Time
+	partial []byte // Unterminated last line, held until it is complete
+}
+
+// read sends the complete lines from offset and returns the offset after
+// them
+func (t *tailer) read(f *os.File, offset int64) (int64, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	r := bufio.NewReaderSize(f, maxLine)
+	for {
+		chunk, err := r.ReadSlice('\n')
+		offset += int64(len(chunk))
+		if err == bufio.ErrBufferFull {
+			if len(t.partial) < maxLine {
+				t.partial = append(t.partial, chunk...)
+			}
+			continue
+		}
+		if err == io.EOF {
+			t.partial = append(t.partial, chunk...)
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		t.partial = append(t.partial, chunk...)
+		if err := t.flush(); err != nil {
+			return offset, err
+		}
+	}
+}
+
+// flush sends the line held, also when it is not terminated because the
+// file ends or is replaced
+func (t *tailer) flush() error {
+	if len(t.partial) == 0 {
+		return nil
+	}
+	line := bytes.TrimRight(t.partial, "\r\n")
+	t.partial = nil
+	if len(line) > maxLine {
+		line = line[:maxLine]
+	}
+	return t.line(string(line))
+}
+
+func (t *tailer) line(text string) error {
+	if ts, ok := ParseTime(text, t.now); ok {
+		t.last = ts
+	}
+	if !t.opts.Since.IsZero() && (t.last.IsZero() || t.last.Before(t.opts.Since)) {
+		return nil
+	}
+	if t.opts.Grep != nil && !t.opts.Grep.MatchString(text) {
+		return nil
+	}
+	return t.send(Line{Time: t.last, Text: text})
+}
+
+// lastLines returns the offset of the last n lines of a file of size bytes
+func lastLines(f *os.File, size int64, n int) (int64, error) {
+	const block = 8192
+	buf := make([]byte, block)
+	end := size
+	found := 0
+	for end > 0 {
+		start := end - block
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue // The newline ending the file ends the last line
+			}
+			found++
+			if found == n {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// timeFormats are the timestamps log lines commonly carry and their layouts
+var timeFormats = []struct {
+	pattern *regexp.Regexp
+	layout  string
+}{
+	// Application logs and journalctl -o short-iso
+	{regexp.MustCompile(`^\d{4}-\d\d-\d\d[T ]\d\d:\d\d:\d\d(\.\d+)?(Z|[+-]\d\d:?\d\d)?`), ""},
+	// nginx error log
+	{regexp.MustCompile(`^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d`), "2006/01/02 15:04:05"},
+	// syslog
+	{regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d`), "Jan _2 15:04:05"},
+	// nginx and Apache access logs, after the client fields
+	{regexp.MustCompile(`\[\d\d/[A-Z][a-z]{2}/\d{4}:\d\d:\d\d:\d\d [+-]\d{4}\]`), "[02/Jan/2006:15:04:05 -0700]"},
+}
+
+// isoLayouts are tried in turn for the first format
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ParseTime reads the timestamp of a log line. Timestamps without a zone
+// are local. Syslog ones have no year and are taken to be in the year up to
+// now.
+func ParseTime(line string, now time.Time) (time.Time, bool) {
+	for i, f := range timeFormats {
+		match := f.pattern.FindString(line)
+		if match == "" {
+			continue
+		}
+		if i == 0 {
+			for _, layout := range isoLayouts {
+				if ts, err := time.ParseInLocation(layout, match, time.Local); err == nil {
+					return ts, true
+				}
+			}
+			continue
+		}
+		ts, err := time.ParseInLocation(f.layout, match, time.Local)
+		if err != nil {
+			continue
+		}
+		if ts.Year() == 0 {
+			ts = ts.AddDate(now.Year(), 0, 0)
+			if ts.After(now.Add(24 * time.Hour)) {
+				ts = ts.AddDate(-1, 0, 0) // Logged in December, read in January
+			}
+		}
+		return ts, true
+	}
+	return time.Time{}, false
+}
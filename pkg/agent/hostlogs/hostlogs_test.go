@@ -0,0 +1,206 @@
+package hostlogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "log")
+	os.MkdirAll(logs, 0755)
+	os.WriteFile(filepath.Join(logs, "app.log"), []byte("hello\n"), 0644)
+	secret := filepath.Join(dir, "shadow")
+	os.WriteFile(secret, []byte("root:x\n"), 0600)
+	if err := os.Symlink(secret, filepath.Join(logs, "evil.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	allow := Allowlist{filepath.Join(logs, "*.log")}
+	if got, err := allow.Resolve(filepath.Join(logs, "..", "log", "app.log")); err != nil || got != filepath.Join(logs, "app.log") {
+		t.Errorf("Resolve(app.log) = %q, %v", got, err)
+	}
+	for _, path := range []string{secret, filepath.Join(logs, "evil.log")} {
+		if _, err := allow.Resolve(path); !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Resolve(%s) = %v, want ErrNotAllowed", path, err)
+		}
+	}
+	if _, err := allow.Resolve("log/app.log"); err == nil {
+		t.Error("Resolve(relative): expected an error")
+	}
+
+	if files := allow.Files(); len(files) != 1 || files[0] != filepath.Join(logs, "app.log") {
+		t.Errorf("Files() = %v, want app.log", files)
+	}
+}
+
+// collect tails path and returns the lines sent
+func collect(t *testing.T, path string, opts Options) []string {
+	t.Helper()
+	var lines []string
+	err := Allowlist{path}.Tail(context.Background(), path, opts, func(l Line) error {
+		lines = append(lines, l.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var b strings.Builder
+	for i := 1; i <= 150; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	b.WriteString("unterminated")
+	os.WriteFile(path, []byte(b.String()), 0644)
+
+	lines := collect(t, path, Options{})
+	if len(lines) != DefaultLines || lines[0] != "line 52" || lines[99] != "unterminated" {
+		t.Errorf("default tail: %d lines, %q ... %q", len(lines), lines[0], lines[len(lines)-1])
+	}
+	if lines := collect(t, path, Options{Lines: -1}); len(lines) != 151 {
+		t.Errorf("whole file: %d lines, want 151", len(lines))
+	}
+	lines = collect(t, path, Options{Lines: 3, Grep: regexp.MustCompile(`^line 1\d\d$`)})
+	if strings.Join(lines, ",") != "line 149,line 150" {
+		t.Errorf("grep: %v", lines)
+	}
+}
+
+func TestTailSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "error.log")
+	os.WriteFile(path, []byte(
+		"2026/03/01 10:00:00 [error] 1#1: old\n"+
+			"2026/03/01 12:00:00 [error] 1#1: upstream timed out\n"+
+			"  continued\n"+
+			"2026/03/01 13:00:00 [warn] 1#1: slow\n"), 0644)
+
+	since := time.Date(2026, 3, 1, 11, 0, 0, 0, time.Local)
+	lines := collect(t, path, Options{Since: since})
+	want := []string{"2026/03/01 12:00:00 [error] 1#1: upstream timed out", "  continued", "2026/03/01 13:00:00 [warn] 1#1: slow"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("since: %q", lines)
+	}
+}
+
+func TestTailFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("before\n"), 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Allowlist{path}.Tail(ctx, path, Options{Follow: true}, func(l Line) error {
+			lines <- l.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a line")
+			return ""
+		}
+	}
+
+	if line := next(); line != "before" {
+		t.Fatalf("got %q, want before", line)
+	}
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\n")
+	f.Close()
+	if line := next(); line != "appended" {
+		t.Errorf("got %q, want appended", line)
+	}
+
+	// Rotation: the old file is moved away and a new one created
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("rotated\n"), 0644)
+	if line := next(); line != "rotated" {
+		t.Errorf("got %q, want rotated", line)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTailFollowRotatedIntoSymlink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	secret := filepath.Join(dir, "secret")
+	os.WriteFile(path, []byte("before\n"), 0644)
+	os.WriteFile(secret, []byte("secret\n"), 0600)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Allowlist{filepath.Join(dir, "*.log")}.Tail(ctx, path, Options{Follow: true}, func(l Line) error {
+			lines <- l.Text
+			return nil
+		})
+	}()
+	if line := <-lines; line != "before" {
+		t.Fatalf("got %q, want before", line)
+	}
+
+	// The rotated log is replaced by a link to a file outside the allowlist
+	os.Rename(path, path+".1")
+	if err := os.Symlink(secret, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Tail = %v, want ErrNotAllowed", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Tail kept following the link")
+	}
+	close(lines)
+	for line := range lines {
+		t.Errorf("sent %q from the linked file", line)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		{"2026-01-05T10:11:12Z level=info", time.Date(2026, 1, 5, 10, 11, 12, 0, time.UTC)},
+		{"2026-01-05 10:11:12.500 INFO started", time.Date(2026, 1, 5, 10, 11, 12, 500e6, time.Local)},
+		{"2026/01/05 10:11:12 [error] 7#7: *1 connect() failed", time.Date(2026, 1, 5, 10, 11, 12, 0, time.Local)},
+		{"Jan  5 10:11:12 web sshd[42]: Accepted publickey", time.Date(2026, 1, 5, 10, 11, 12, 0, time.Local)},
+		{"Dec 31 23:59:59 web cron[1]: job", time.Date(2025, 12, 31, 23, 59, 59, 0, time.Local)},
+		{`10.0.0.1 - - [05/Jan/2026:10:11:12 +0000] "GET / HTTP/1.1" 200`, time.Date(2026, 1, 5, 10, 11, 12, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.line, now)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.line, got, ok, tt.want)
+		}
+	}
+	if _, ok := ParseTime("  at com.example.Main", now); ok {
+		t.Error("ParseTime(stack frame): expected no timestamp")
+	}
+}
@@ -0,0 +1,519 @@
+// Package logindex keeps the logs of an agent's stacks on disk so they can
+// be searched by time and content after their containers restart or are
+// gone, without a central log store. Each stack has a ring of segment files
+// bounded in size and age: when a new segment would exceed the bound the
+// oldest is removed.
+package logindex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DefaultDir holds the segments, one directory per stack
+	DefaultDir = "/var/lib/mandau/logindex"
+	// DefaultMaxSize bounds the segments of one stack
+	DefaultMaxSize = 256 << 20
+	// DefaultRetention is how long entries are kept
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// segmentsPerStack is how many segments the size bound is split into,
+	// so removing the oldest frees an eighth of the ring
+	segmentsPerStack = 8
+	minSegmentSize   = 1 << 20
+	currentSegment   = "current.log"
+	positionsFile    = "positions.json"
+)
+
+// ErrInvalidStack is returned for stack names that are not one path element
+var ErrInvalidStack = errors.New("invalid stack name")
+
+// Query selects entries. Zero fields select everything.
+type Query struct {
+	Since time.Time      // At or after
+	Until time.Time      // Before
+	Grep  *regexp.Regexp // Content must match
+	Tail  int            // Only the last this many matches
+}
+
+func (q Query) matches(r *record) bool {
+	if !q.Since.IsZero() && r.Time < q.Since.UnixNano() {
+		return false
+	}
+	if !q.Until.IsZero() && r.Time >= q.Until.UnixNano() {
+		return false
+	}
+	return q.Grep == nil || q.Grep.MatchString(r.Content)
+}
+
+// overlaps reports whether entries between first and last may match
+func (q Query) overlaps(first, last int64) bool {
+	if !q.Since.IsZero() && last < q.Since.UnixNano() {
+		return false
+	}
+	return q.Until.IsZero() || first < q.Until.UnixNano()
+}
+
+// record is an entry as segments store it, one JSON object per line
+type record struct {
+	Time      int64  `json:"t"`
+	Stream    string `json:"s,omitempty"`
+	Service   string `json:"v,omitempty"`
+	Container string `json:"c,omitempty"`
+	Content   string `json:"m"`
+}
+
+func (r *record) entry() *agentv1.LogEntry {
+	return &agentv1.LogEntry{
+		Timestamp:   timestamppb.New(time.Unix(0, r.Time)),
+		Stream:      r.Stream,
+		Content:     []byte(r.Content),
+		ContainerId: r.Container,
+		ServiceName: r.Service,
+	}
+}
+
+// Index holds the log rings of every stack under a directory
+type Index struct {
+	dir         string
+	maxSize     int64
+	segmentSize int64
+	retention   time.Duration
+
+	mu     sync.Mutex
+	stacks map[string]*stackLog
+}
+
+// Open returns the index under dir, keeping at most maxSize bytes per stack
+// for at most retention
+func Open(dir string, maxSize int64, retention time.Duration) (*Index, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Index{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: max(maxSize/segmentsPerStack, minSegmentSize),
+		retention:   retention,
+		stacks:      make(map[string]*stackLog),
+	}, nil
+}
+
+// stack returns the ring of a stack, opening it on first use. Unless create
+// is set a stack with nothing indexed returns nil.
+func (ix *Index) stack(name string, create bool) (*stackLog, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStack, name)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if s, ok := ix.stacks[name]; ok {
+		return s, nil
+	}
+	dir := filepath.Join(ix.dir, name)
+	if !create {
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	s, err := openStackLog(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open log index of %s: %w", name, err)
+	}
+	ix.stacks[name] = s
+	return s, nil
+}
+
+// Add indexes an entry of a stack's container. Content should already be
+// redacted: it is kept on disk.
+func (ix *Index) Add(stack string, e *agentv1.LogEntry) error {
+	s, err := ix.stack(stack, true)
+	if err != nil {
+		return err
+	}
+	r := &record{
+		Time:      e.Timestamp.AsTime().UnixNano(),
+		Stream:    e.Stream,
+		Service:   e.ServiceName,
+		Container: e.ContainerId,
+		Content:   string(e.Content),
+	}
+	return s.add(r, ix.segmentSize, ix.maxSize, ix.retention)
+}
+
+// Last returns the time of the last entry indexed from a container, zero
+// when none was
+func (ix *Index) Last(stack, container string) time.Time {
+	s, err := ix.stack(stack, false)
+	if err != nil || s == nil {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.positions[container]; ok {
+		return time.Unix(0, t)
+	}
+	return time.Time{}
+}
+
+// Search passes the entries of a stack matching q to emit, oldest segment
+// first. Stacks with nothing indexed have no entries.
+func (ix *Index) Search(stack string, q Query, emit func(*agentv1.LogEntry) error) error {
+	s, err := ix.stack(stack, false)
+	if err != nil || s == nil {
+		return err
+	}
+	reads, err := s.snapshot(q)
+	if err != nil {
+		return err
+	}
+
+	// With a tail only the last matches are kept, in a ring
+	var (
+		tail []*record
+		next int
+	)
+	for _, rd := range reads {
+		err := rd.scan(func(r *record) error {
+			if !q.matches(r) {
+				return nil
+			}
+			if q.Tail <= 0 {
+				return emit(r.entry())
+			}
+			if len(tail) < q.Tail {
+				tail = append(tail, r)
+			} else {
+				tail[next] = r
+				next = (next + 1) % q.Tail
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for i := range tail {
+		if err := emit(tail[(next+i)%len(tail)].entry()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes buffered entries to disk
+func (ix *Index) Flush() error {
+	ix.mu.Lock()
+	stacks := make([]*stackLog, 0, len(ix.stacks))
+	for _, s := range ix.stacks {
+		stacks = append(stacks, s)
+	}
+	ix.mu.Unlock()
+
+	var errs []error
+	for _, s := range stacks {
+		s.mu.Lock()
+		errs = append(errs, s.w.Flush())
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Prune removes the segments older than the retention of every stack, and
+// the directories of stacks left with nothing, such as removed ones
+func (ix *Index) Prune() error {
+	dirs, err := os.ReadDir(ix.dir)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-ix.retention).UnixNano()
+	var errs []error
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		s, err := ix.stack(d.Name(), false)
+		if err != nil || s == nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.mu.Lock()
+		if s.cur.size > 0 && s.cur.last < cutoff {
+			errs = append(errs, s.rotate())
+		}
+		s.expire(cutoff)
+		empty := len(s.closed) == 0 && s.cur.size == 0
+		s.mu.Unlock()
+		if !empty {
+			continue
+		}
+
+		ix.mu.Lock()
+		delete(ix.stacks, d.Name())
+		ix.mu.Unlock()
+		s.mu.Lock()
+		errs = append(errs, s.file.Close(), os.RemoveAll(s.dir))
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Close writes what is buffered and closes the segments
+func (ix *Index) Close() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	var errs []error
+	for name, s := range ix.stacks {
+		s.mu.Lock()
+		errs = append(errs, s.w.Flush(), s.savePositions(), s.file.Close())
+		s.mu.Unlock()
+		delete(ix.stacks, name)
+	}
+	return errors.Join(errs...)
+}
+
+// segment is a file of entries. Closed segments are named
+// <seq>-<first>-<last>.log after their creation and entry times.
+type segment struct {
+	path        string
+	seq         int64
+	first, last int64 // Entry times in Unix nanoseconds
+	size        int64
+}
+
+// stackLog is the ring of one stack: closed segments, oldest first, and the
+// current one entries are appended to
+type stackLog struct {
+	dir string
+
+	mu        sync.Mutex
+	closed    []segment
+	cur       segment
+	file      *os.File
+	w         *bufio.Writer
+	positions map[string]int64 // Last entry time per container
+}
+
+func openStackLog(dir string) (*stackLog, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &stackLog{dir: dir, positions: make(map[string]int64)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		var seg segment
+		if _, err := fmt.Sscanf(e.Name(), "%d-%d-%d.log", &seg.seq, &seg.first, &seg.last); err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		seg.path, seg.size = filepath.Join(dir, e.Name()), info.Size()
+		s.closed = append(s.closed, seg)
+	}
+	sort.Slice(s.closed, func(i, j int) bool { return s.closed[i].seq < s.closed[j].seq })
+
+	if data, err := os.ReadFile(filepath.Join(dir, positionsFile)); err == nil {
+		json.Unmarshal(data, &s.positions)
+	}
+
+	// Positions are saved on rotation; the current segment has the rest
+	s.cur = segment{path: filepath.Join(dir, currentSegment), seq: time.Now().UnixNano()}
+	cur := &reader{seg: s.cur, limit: -1}
+	if err := cur.scan(s.track); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if s.file, err = os.OpenFile(s.cur.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return nil, err
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		s.file.Close()
+		return nil, err
+	}
+	s.cur.size = info.Size()
+	s.w = bufio.NewWriter(s.file)
+	return s, nil
+}
+
+// track notes an entry in the current segment's times and the positions
+func (s *stackLog) track(r *record) error {
+	if s.cur.first == 0 || r.Time < s.cur.first {
+		s.cur.first = r.Time
+	}
+	s.cur.last = max(s.cur.last, r.Time)
+	if r.Container != "" && r.Time > s.positions[r.Container] {
+		s.positions[r.Container] = r.Time
+	}
+	return nil
+}
+
+func (s *stackLog) add(r *record, segmentSize, maxSize int64, retention time.Duration) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur.size > 0 && s.cur.size+int64(len(line)) > segmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		s.expire(time.Now().Add(-retention).UnixNano())
+		s.bound(maxSize - segmentSize)
+	}
+	if _, err := s.w.Write(line); err != nil {
+		return err
+	}
+	s.cur.size += int64(len(line))
+	return s.track(r)
+}
+
+// rotate closes the current segment and starts a new one
+func (s *stackLog) rotate() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	seg := s.cur
+	seg.path = filepath.Join(s.dir, fmt.Sprintf("%d-%d-%d.log", seg.seq, seg.first, seg.last))
+	if err := os.Rename(s.cur.path, seg.path); err != nil {
+		return err
+	}
+	s.closed = append(s.closed, seg)
+
+	file, err := os.OpenFile(s.cur.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	s.file, s.w = file, bufio.NewWriter(file)
+	s.cur = segment{path: s.cur.path, seq: max(time.Now().UnixNano(), seg.seq+1)}
+	return s.savePositions()
+}
+
+// expire removes the closed segments whose entries are all before cutoff,
+// and the positions of containers not seen since
+func (s *stackLog) expire(cutoff int64) {
+	for len(s.closed) > 0 && s.closed[0].last < cutoff {
+		os.Remove(s.closed[0].path)
+		s.closed = s.closed[1:]
+	}
+	for c, t := range s.positions {
+		if t < cutoff {
+			delete(s.positions, c)
+		}
+	}
+}
+
+// bound removes the oldest closed segments until they fit in size
+func (s *stackLog) bound(size int64) {
+	var total int64
+	for _, seg := range s.closed {
+		total += seg.size
+	}
+	for len(s.closed) > 0 && total > size {
+		os.Remove(s.closed[0].path)
+		total -= s.closed[0].size
+		s.closed = s.closed[1:]
+	}
+}
+
+func (s *stackLog) savePositions() error {
+	data, err := json.Marshal(s.positions)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, positionsFile)
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// snapshot returns readers of the segments that may hold entries matching
+// q, after writing out what is buffered
+func (s *stackLog) snapshot(q Query) ([]*reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil {
+		return nil, err
+	}
+	var reads []*reader
+	for _, seg := range s.closed {
+		if q.overlaps(seg.first, seg.last) {
+			reads = append(reads, &reader{seg: seg, limit: -1})
+		}
+	}
+	if s.cur.size > 0 && q.overlaps(s.cur.first, s.cur.last) {
+		// Lines appended while reading are left for the next search
+		reads = append(reads, &reader{seg: s.cur, limit: s.cur.size})
+	}
+	return reads, nil
+}
+
+// reader reads the records of a segment, up to limit bytes when not -1
+type reader struct {
+	seg   segment
+	limit int64
+}
+
+func (rd *reader) scan(fn func(*record) error) error {
+	f, err := os.Open(rd.seg.path)
+	if errors.Is(err, fs.ErrNotExist) && rd.limit < 0 {
+		// Removed from the ring since the snapshot
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var in io.Reader = f
+	if rd.limit >= 0 {
+		in = io.LimitReader(f, rd.limit)
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var r record
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			// A line cut short by a crash
+			continue
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
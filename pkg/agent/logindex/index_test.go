@@ -0,0 +1,143 @@
+package logindex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func entry(t time.Time, container, content string) *agentv1.LogEntry {
+	return &agentv1.LogEntry{
+		Timestamp:   timestamppb.New(t),
+		Stream:      "stdout",
+		Content:     []byte(content),
+		ContainerId: container,
+		ServiceName: "api",
+	}
+}
+
+func search(t *testing.T, ix *Index, stack string, q Query) []string {
+	t.Helper()
+	var got []string
+	err := ix.Search(stack, q, func(e *agentv1.LogEntry) error {
+		got = append(got, string(e.Content))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestSearch(t *testing.T) {
+	ix, err := Open(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, line := range []string{"starting", "error: db down", "retrying", "error: db down again", "ready"} {
+		if err := ix.Add("shop", entry(now.Add(time.Duration(i-5)*time.Hour), "c1", line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"starting", "error: db down", "retrying", "error: db down again", "ready"}},
+		{"grep", Query{Grep: regexp.MustCompile(`^error`)}, []string{"error: db down", "error: db down again"}},
+		{"since", Query{Since: now.Add(-2*time.Hour - time.Minute)}, []string{"error: db down again", "ready"}},
+		{"until", Query{Until: now.Add(-3 * time.Hour)}, []string{"starting", "error: db down"}},
+		{"tail", Query{Tail: 2}, []string{"error: db down again", "ready"}},
+		{"grep and tail", Query{Grep: regexp.MustCompile(`error`), Tail: 1}, []string{"error: db down again"}},
+	} {
+		if got := search(t, ix, "shop", tc.query); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if got := search(t, ix, "other", Query{}); len(got) != 0 {
+		t.Errorf("stack with nothing indexed: got %q", got)
+	}
+	if err := ix.Search("../shop", Query{}, nil); err == nil {
+		t.Error("stack name with a path: no error")
+	}
+}
+
+func TestRingIsBoundedAndSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	ix, err := Open(dir, 2*minSegmentSize, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each line is about 1 KiB, so 5000 of them rotate several segments
+	now := time.Now()
+	pad := strings.Repeat("x", 1000)
+	for i := range 5000 {
+		if err := ix.Add("shop", entry(now.Add(time.Duration(i-5000)*time.Millisecond), "c1", fmt.Sprintf("%d %s", i, pad))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var size int64
+	files, _ := filepath.Glob(filepath.Join(dir, "shop", "*.log"))
+	for _, f := range files {
+		info, _ := os.Stat(f)
+		size += info.Size()
+	}
+	if size > 2*minSegmentSize+64*1024 {
+		t.Errorf("ring holds %d bytes in %d segments, want at most about %d", size, len(files), 2*minSegmentSize)
+	}
+	got := search(t, ix, "shop", Query{})
+	if len(got) == 0 || !strings.HasPrefix(got[len(got)-1], "4999 ") || strings.HasPrefix(got[0], "0 ") {
+		t.Fatalf("ring holds %d entries, want the newest without the oldest", len(got))
+	}
+	if err := ix.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Open(dir, 2*minSegmentSize, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := again.Last("shop", "c1"); !last.Equal(now.Add(-time.Millisecond)) {
+		t.Errorf("last = %s, want %s", last, now.Add(-time.Millisecond))
+	}
+	if n := len(search(t, again, "shop", Query{})); n != len(got) {
+		t.Errorf("reopened index holds %d entries, want %d", n, len(got))
+	}
+}
+
+func TestPruneRemovesExpiredStacks(t *testing.T) {
+	dir := t.TempDir()
+	ix, err := Open(dir, 0, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	ix.Add("old", entry(now.Add(-2*time.Hour), "c1", "gone"))
+	ix.Add("shop", entry(now.Add(-2*time.Hour), "c2", "expired"))
+	ix.Add("shop", entry(now, "c2", "kept"))
+
+	if err := ix.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Errorf("expired stack still on disk: %v", err)
+	}
+	if !ix.Last("old", "c1").IsZero() {
+		t.Error("expired stack still has a position")
+	}
+	if got := search(t, ix, "shop", Query{}); len(got) != 2 {
+		t.Errorf("current segment with a recent entry: got %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Container identifies a container whose logs are read
+type Container struct {
+	ID      string
+	Service string
+	Since   time.Time // Only lines from then on, when set
+}
+
+// DockerSource reads the logs of containers from Docker, one stream per
+// container. With follow it keeps reading until ctx is cancelled; otherwise
+// it returns the last tail lines of each container.
+func DockerSource(docker *client.Client, containers []Container, follow bool, tail string) Source {
+	return func(ctx context.Context, emit func(*agentv1.LogEntry)) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var (
+			wg       sync.WaitGroup
+			errOnce  sync.Once
+			firstErr error
+		)
+		for _, c := range containers {
+			wg.Add(1)
+			go func(c Container) {
+				defer wg.Done()
+				if err := readContainer(ctx, docker, c, follow, tail, emit); err != nil && ctx.Err() == nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		return firstErr
+	}
+}
+
+func readContainer(ctx context.Context, docker *client.Client, c Container, follow bool, tail string, emit func(*agentv1.LogEntry)) error {
+	opts := client.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     follow,
+		Tail:       tail,
+	}
+	if !c.Since.IsZero() {
+		opts.Since = c.Since.Format(time.RFC3339Nano)
+	}
+	rc, err := docker.ContainerLogs(ctx, c.ID, opts)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	stdout := &lineWriter{container: c, stream: "stdout", emit: emit}
+	stderr := &lineWriter{container: c, stream: "stderr", emit: emit}
+	_, err = stdcopy.StdCopy(stdout, stderr, rc)
+	stdout.flush()
+	stderr.flush()
+	return err
+}
+
+// lineWriter turns Docker's timestamped log output into one entry per line
+type lineWriter struct {
+	container Container
+	stream    string
+	emit      func(*agentv1.LogEntry)
+	partial   []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.line(w.partial)
+		w.partial = nil
+	}
+}
+
+func (w *lineWriter) line(line []byte) {
+	ts, content := ParseTimestamp(string(line))
+	w.emit(&agentv1.LogEntry{
+		Timestamp:   timestamppb.New(ts),
+		Stream:      w.stream,
+		Content:     []byte(content),
+		ContainerId: w.container.ID,
+		ServiceName: w.container.Service,
+	})
+}
+
+// ParseTimestamp splits the RFC 3339 timestamp Docker prefixes to log lines
+// from the content. Lines without one are stamped with the current time.
+func ParseTimestamp(line string) (time.Time, string) {
+	if prefix, rest, ok := strings.Cut(line, " "); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			return ts, rest
+		}
+	}
+	return time.Now(), line
+}
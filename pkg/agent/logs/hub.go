@@ -0,0 +1,203 @@
+// Package logs reads container logs on the agent. Followers of the same
+// stack share one Docker log stream through a Hub, which fans entries out to
+// each subscriber through its own buffer.
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+// Source produces log entries until ctx is cancelled or the logs end. Emit
+// may be called from several goroutines.
+type Source func(ctx context.Context, emit func(*agentv1.LogEntry)) error
+
+// Policy decides what happens when a subscriber's buffer is full
+type Policy int
+
+const (
+	// DropOldest discards the subscriber's oldest buffered entry so a slow
+	// viewer never holds up the others. Dropped entries are counted.
+	DropOldest Policy = iota
+	// Block waits for the subscriber, slowing the shared stream for every
+	// follower of the stack
+	Block
+)
+
+// DefaultBuffer is the per-subscriber buffer used when the hub is created
+// with a non-positive size
+const DefaultBuffer = 1024
+
+// Hub shares one source per key between subscribers
+type Hub struct {
+	mu     sync.Mutex
+	feeds  map[string]*feed
+	buffer int
+}
+
+// feed is one running source and its subscribers
+type feed struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	cancel context.CancelFunc
+}
+
+// Subscription receives the entries of one feed
+type Subscription struct {
+	hub     *Hub
+	key     string
+	feed    *feed
+	policy  Policy
+	entries chan *agentv1.LogEntry
+	done    chan struct{} // Closed on Close
+	closed  sync.Once
+	dropped atomic.Int64
+	err     error // Source error, set before entries is closed
+}
+
+// NewHub returns a hub giving each subscriber buffer entries of slack
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{feeds: make(map[string]*feed), buffer: buffer}
+}
+
+// Subscribe follows key, starting source if nobody follows it yet. The
+// source runs until it ends or the last subscriber closes.
+func (h *Hub) Subscribe(key string, policy Policy, source Source) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, ok := h.feeds[key]
+	sub := &Subscription{
+		hub:     h,
+		key:     key,
+		policy:  policy,
+		entries: make(chan *agentv1.LogEntry, h.buffer),
+		done:    make(chan struct{}),
+	}
+
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		f = &feed{subs: make(map[*Subscription]struct{}), cancel: cancel}
+		h.feeds[key] = f
+		go h.run(ctx, key, f, source)
+	}
+
+	sub.feed = f
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+
+	return sub
+}
+
+// run reads the source and closes every remaining subscription when it ends
+func (h *Hub) run(ctx context.Context, key string, f *feed, source Source) {
+	err := source(ctx, f.publish)
+
+	h.mu.Lock()
+	if h.feeds[key] == f {
+		delete(h.feeds, key)
+	}
+	h.mu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subs {
+		sub.err = err
+		close(sub.entries)
+		delete(f.subs, sub)
+	}
+}
+
+// publish hands an entry to every subscriber according to its policy
+func (f *feed) publish(entry *agentv1.LogEntry) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for sub := range f.subs {
+		sub.deliver(entry)
+	}
+}
+
+func (s *Subscription) deliver(entry *agentv1.LogEntry) {
+	if s.policy == Block {
+		select {
+		case s.entries <- entry:
+		case <-s.done:
+		}
+		return
+	}
+
+	for {
+		select {
+		case s.entries <- entry:
+			return
+		default:
+		}
+		// Make room by discarding the oldest entry; another goroutine
+		// may win the slot, so retry
+		select {
+		case <-s.entries:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// Entries delivers log entries. It is closed when the source ends; Err then
+// reports why.
+func (s *Subscription) Entries() <-chan *agentv1.LogEntry {
+	return s.entries
+}
+
+// Err returns the source error once Entries is closed
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Dropped counts entries discarded because the subscriber fell behind
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes. The source stops when its last subscriber leaves.
+func (s *Subscription) Close() {
+	s.closed.Do(func() {
+		close(s.done)
+
+		h := s.hub
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		s.feed.mu.Lock()
+		_, subscribed := s.feed.subs[s]
+		delete(s.feed.subs, s)
+		remaining := len(s.feed.subs)
+		s.feed.mu.Unlock()
+
+		if subscribed && remaining == 0 && h.feeds[s.key] == s.feed {
+			delete(h.feeds, s.key)
+			s.feed.cancel()
+		}
+	})
+}
+
+// ParsePolicy parses a configured slow-subscriber policy: "drop" (default)
+// or "block"
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "", "drop":
+		return DropOldest, nil
+	case "block":
+		return Block, nil
+	default:
+		return DropOldest, fmt.Errorf("unknown log policy %q (want drop or block)", name)
+	}
+}
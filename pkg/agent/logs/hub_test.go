@@ -0,0 +1,144 @@
+package logs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+// chanSource emits entries sent on in until ctx is cancelled, counting how
+// often it was started
+func chanSource(in <-chan *agentv1.LogEntry, starts *atomic.Int32, stopped chan<- struct{}) Source {
+	return func(ctx context.Context, emit func(*agentv1.LogEntry)) error {
+		starts.Add(1)
+		defer close(stopped)
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case e := <-in:
+				emit(e)
+			}
+		}
+	}
+}
+
+func recv(t *testing.T, sub *Subscription) *agentv1.LogEntry {
+	t.Helper()
+	select {
+	case e := <-sub.Entries():
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no entry received")
+		return nil
+	}
+}
+
+func TestHubSharesSource(t *testing.T) {
+	hub := NewHub(4)
+	in := make(chan *agentv1.LogEntry)
+	stopped := make(chan struct{})
+	var starts atomic.Int32
+	source := chanSource(in, &starts, stopped)
+
+	a := hub.Subscribe("web", DropOldest, source)
+	b := hub.Subscribe("web", DropOldest, source)
+
+	in <- &agentv1.LogEntry{Content: []byte("hello")}
+	if got := string(recv(t, a).Content); got != "hello" {
+		t.Errorf("a got %q", got)
+	}
+	if got := string(recv(t, b).Content); got != "hello" {
+		t.Errorf("b got %q", got)
+	}
+	if n := starts.Load(); n != 1 {
+		t.Errorf("source started %d times, want 1", n)
+	}
+
+	a.Close()
+	select {
+	case <-stopped:
+		t.Fatal("source stopped while b still follows")
+	default:
+	}
+
+	b.Close()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("source not stopped after the last subscriber left")
+	}
+}
+
+func TestHubDropOldest(t *testing.T) {
+	hub := NewHub(2)
+	in := make(chan *agentv1.LogEntry)
+	stopped := make(chan struct{})
+	var starts atomic.Int32
+
+	sub := hub.Subscribe("web", DropOldest, chanSource(in, &starts, stopped))
+	defer sub.Close()
+
+	for _, c := range []string{"1", "2", "3", "4"} {
+		in <- &agentv1.LogEntry{Content: []byte(c)}
+	}
+	// The source delivers synchronously, but give the last send time to land
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.Dropped() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := sub.Dropped(); got != 2 {
+		t.Errorf("Dropped() = %d, want 2", got)
+	}
+	if got := string(recv(t, sub).Content); got != "3" {
+		t.Errorf("first kept entry = %q, want 3", got)
+	}
+}
+
+func TestHubSourceEnd(t *testing.T) {
+	hub := NewHub(4)
+	sub := hub.Subscribe("web", Block, func(ctx context.Context, emit func(*agentv1.LogEntry)) error {
+		emit(&agentv1.LogEntry{Content: []byte("last")})
+		return context.DeadlineExceeded
+	})
+	defer sub.Close()
+
+	if got := string(recv(t, sub).Content); got != "last" {
+		t.Errorf("got %q", got)
+	}
+	if _, ok := <-sub.Entries(); ok {
+		t.Fatal("entries not closed after the source ended")
+	}
+	if sub.Err() != context.DeadlineExceeded {
+		t.Errorf("Err() = %v", sub.Err())
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, content := ParseTimestamp("2024-05-01T10:00:00.123456789Z GET / 200")
+	if content != "GET / 200" {
+		t.Errorf("content = %q", content)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC); !ts.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", ts, want)
+	}
+
+	if _, content := ParseTimestamp("no timestamp here"); content != "no timestamp here" {
+		t.Errorf("content = %q", content)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for name, want := range map[string]Policy{"": DropOldest, "drop": DropOldest, "block": Block} {
+		if got, err := ParsePolicy(name); err != nil || got != want {
+			t.Errorf("ParsePolicy(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParsePolicy("slow"); err == nil {
+		t.Error("ParsePolicy(slow) should fail")
+	}
+}
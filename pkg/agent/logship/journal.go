@@ -0,0 +1,98 @@
+package logship
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// JournalSource is the position key of the journal
+const JournalSource = "journal"
+
+// FollowJournal ships the journal of units with journalctl until ctx is
+// cancelled, resuming after the last cursor shipped, or from now the first
+// time
+func (s *Shipper) FollowJournal(ctx context.Context, units []string) error {
+	args := []string{"--follow", "--output=json", "--no-pager"}
+	for _, unit := range units {
+		args = append(args, "--unit="+unit)
+	}
+	if cursor := s.Position(JournalSource); cursor != "" {
+		args = append(args, "--after-cursor="+cursor)
+	} else {
+		args = append(args, "--lines=0")
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start journalctl: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if e, ok := parseJournal(scanner.Bytes()); ok {
+			s.Add(e)
+		}
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	return fmt.Errorf("journalctl ended: %v", err)
+}
+
+// parseJournal reads one journalctl --output=json record
+func parseJournal(line []byte) (Entry, bool) {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(line, &record); err != nil {
+		return Entry{}, false
+	}
+	field := func(name string) string {
+		var s string
+		json.Unmarshal(record[name], &s)
+		return s
+	}
+
+	var message string
+	if err := json.Unmarshal(record["MESSAGE"], &message); err != nil {
+		// Messages that are not valid UTF-8 come as arrays of bytes
+		var bytes []int
+		if json.Unmarshal(record["MESSAGE"], &bytes) != nil {
+			return Entry{}, false
+		}
+		raw := make([]byte, len(bytes))
+		for i, b := range bytes {
+			raw[i] = byte(b)
+		}
+		message = string(raw)
+	}
+
+	ts := time.Now()
+	if usec, err := strconv.ParseInt(field("__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		ts = time.UnixMicro(usec)
+	}
+	unit := field("_SYSTEMD_UNIT")
+	if unit == "" {
+		unit = field("SYSLOG_IDENTIFIER")
+	}
+
+	return Entry{
+		Time:     ts,
+		Line:     message,
+		Labels:   map[string]string{LabelSource: "journal", LabelUnit: unit},
+		Source:   JournalSource,
+		Position: field("__CURSOR"),
+	}, true
+}
@@ -0,0 +1,267 @@
+package logship
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+var (
+	t0      = time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	entries = []Entry{
+		{Time: t0, Line: "GET /", Labels: map[string]string{"agent": "web-1", "stack": "shop", "service": "api"}, Container: "c1"},
+		{Time: t0.Add(time.Second), Line: "GET /cart", Labels: map[string]string{"agent": "web-1", "stack": "shop", "service": "api"}, Container: "c1"},
+		{Time: t0.Add(2 * time.Second), Line: "ready", Labels: map[string]string{"agent": "web-1", "stack": "shop", "service": "db"}, Container: "c2"},
+	}
+)
+
+// recorder is a sink endpoint keeping the requests it gets
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	reply    string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+	io.WriteString(w, r.reply)
+}
+
+func TestLokiSink(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	sink, err := NewSink(config.LogShipConfig{Sink: "loki", URL: srv.URL + "/", Username: "tenant"}, "web-1", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	req := rec.requests[0]
+	if req.URL.Path != "/loki/api/v1/push" {
+		t.Errorf("path = %s", req.URL.Path)
+	}
+	if user, pass, _ := req.BasicAuth(); user != "tenant" || pass != "pw" {
+		t.Errorf("basic auth = %s:%s", user, pass)
+	}
+	var push struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.Unmarshal(rec.bodies[0], &push); err != nil {
+		t.Fatal(err)
+	}
+	if len(push.Streams) != 2 || len(push.Streams[0].Values) != 2 || push.Streams[1].Stream["service"] != "db" {
+		t.Fatalf("streams = %+v, want api with 2 lines and db", push.Streams)
+	}
+	if v := push.Streams[0].Values[1]; v[0] != "1792143001000000000" || v[1] != "GET /cart" {
+		t.Errorf("value = %v", v)
+	}
+}
+
+func TestElasticSinkReportsRejectedEntries(t *testing.T) {
+	rec := &recorder{reply: `{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	sink, err := NewSink(config.LogShipConfig{Sink: "elasticsearch", URL: srv.URL, Index: "logs-mandau"}, "web-1", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Send(context.Background(), entries[:2])
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 entries rejected, first: mapper_parsing_exception") {
+		t.Errorf("error = %v", err)
+	}
+
+	req := rec.requests[0]
+	if req.URL.Path != "/_bulk" || req.Header.Get("Authorization") != "ApiKey key" {
+		t.Errorf("request = %s with %q", req.URL.Path, req.Header.Get("Authorization"))
+	}
+	lines := strings.Split(strings.TrimSpace(string(rec.bodies[0])), "\n")
+	if len(lines) != 4 || lines[0] != `{"create":{"_index":"logs-mandau"}}` {
+		t.Fatalf("bulk body = %q", lines)
+	}
+	var doc map[string]string
+	if err := json.Unmarshal([]byte(lines[1]), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["message"] != "GET /" || doc["@timestamp"] != "2026-10-16T09:30:00Z" || doc["container"] != "c1" || doc["stack"] != "shop" {
+		t.Errorf("document = %v", doc)
+	}
+}
+
+func TestS3Sink(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	sink, err := NewSink(config.LogShipConfig{Sink: "s3", URL: srv.URL, Bucket: "logs", Region: "eu-west-1", Username: "AKID"}, "web-1", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.(*s3Sink).now = func() time.Time { return t0.Add(time.Minute) }
+	if err := sink.Send(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	req := rec.requests[0]
+	if req.Method != http.MethodPut || !strings.HasPrefix(req.URL.Path, "/logs/logs/web-1/2026/10/16/093000-") {
+		t.Errorf("request = %s %s", req.Method, req.URL.Path)
+	}
+	if auth := req.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("authorization = %s", auth)
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(string(rec.bodies[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(gz)
+	var n int
+	for scanner.Scan() {
+		n++
+	}
+	if n != len(entries) {
+		t.Errorf("object holds %d lines, want %d", n, len(entries))
+	}
+}
+
+func TestNewSinkRejectsBadConfig(t *testing.T) {
+	for name, cfg := range map[string]config.LogShipConfig{
+		"sink":       {Sink: "splunk", URL: "http://x"},
+		"url":        {Sink: "loki", URL: "loki:3100"},
+		"s3 bucket":  {Sink: "s3", Username: "AKID"},
+		"s3 secrets": {Sink: "s3", Bucket: "logs"},
+	} {
+		if _, err := NewSink(cfg, "web-1", ""); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+// flakySink fails its first sends
+type flakySink struct {
+	mu    sync.Mutex
+	fails int
+	got   []Entry
+	sent  chan struct{}
+}
+
+func (s *flakySink) Send(ctx context.Context, entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("connection refused")
+	}
+	s.got = append(s.got, entries...)
+	s.sent <- struct{}{}
+	return nil
+}
+
+func TestShipperRetriesAndAdvancesPositions(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(config.LogShipConfig{Sink: "loki", URL: "http://loki", FlushInterval: "10ms", StateDir: dir, Labels: map[string]string{"env": "prod"}}, "web-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &flakySink{fails: 2, sent: make(chan struct{}, 1)}
+	s.sink = sink
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	s.Add(Entry{Time: t0, Line: "one", Source: "c1", Position: "2026-10-16T09:30:00Z"})
+	s.Add(Entry{Time: t0, Line: "two", Source: "c1", Position: "2026-10-16T09:30:01Z"})
+	select {
+	case <-sink.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("nothing shipped: %+v", s.Status())
+	}
+	cancel()
+	<-done
+
+	status := s.Status()
+	if status.Shipped != 2 || status.LastError != "" || status.Pending != 0 {
+		t.Errorf("status = %+v", status)
+	}
+	if got := sink.got[0].Labels; got["agent"] != "web-1" || got["env"] != "prod" {
+		t.Errorf("labels = %v", got)
+	}
+
+	// A new shipper resumes where the last one shipped
+	again, err := New(config.LogShipConfig{Sink: "loki", URL: "http://loki", StateDir: dir}, "web-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := again.Position("c1"); pos != "2026-10-16T09:30:01Z" {
+		t.Errorf("position = %q", pos)
+	}
+}
+
+func TestShipperDropsWhenFull(t *testing.T) {
+	s, err := New(config.LogShipConfig{Sink: "loki", URL: "http://loki", Buffer: 2, StateDir: t.TempDir()}, "web-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		s.Add(Entry{Time: t0, Line: "x"})
+	}
+	if status := s.Status(); status.Dropped != 3 || status.Pending != 2 {
+		t.Errorf("status = %+v, want 3 dropped and 2 pending", status)
+	}
+}
+
+func TestShips(t *testing.T) {
+	all, _ := New(config.LogShipConfig{Sink: "loki", URL: "http://loki", StateDir: t.TempDir()}, "web-1", "")
+	labelled, _ := New(config.LogShipConfig{Sink: "loki", URL: "http://loki", Stacks: "labelled", StateDir: t.TempDir()}, "web-1", "")
+
+	for _, tc := range []struct {
+		shipper *Shipper
+		labels  map[string]string
+		want    bool
+	}{
+		{all, nil, true},
+		{all, map[string]string{StackLabel: "false"}, false},
+		{labelled, nil, false},
+		{labelled, map[string]string{StackLabel: "true"}, true},
+	} {
+		if got := tc.shipper.Ships(tc.labels); got != tc.want {
+			t.Errorf("stacks %s, labels %v: ships = %v", tc.shipper.stacks, tc.labels, got)
+		}
+	}
+}
+
+func TestParseJournal(t *testing.T) {
+	e, ok := parseJournal([]byte(`{"__CURSOR":"s=abc;i=1f","__REALTIME_TIMESTAMP":"1792143000000000","_SYSTEMD_UNIT":"nginx.service","MESSAGE":"started"}`))
+	if !ok || e.Line != "started" || !e.Time.Equal(t0) || e.Labels[LabelUnit] != "nginx.service" || e.Position != "s=abc;i=1f" || e.Source != JournalSource {
+		t.Errorf("entry = %+v", e)
+	}
+
+	e, ok = parseJournal([]byte(`{"__CURSOR":"s=abc;i=20","SYSLOG_IDENTIFIER":"kernel","MESSAGE":[104,105,255]}`))
+	if !ok || e.Line != "hi\xff" || e.Labels[LabelUnit] != "kernel" {
+		t.Errorf("binary entry = %+v", e)
+	}
+}
@@ -0,0 +1,89 @@
+package logship
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// positionsFile holds the read positions in the state directory
+const positionsFile = "positions.json"
+
+// positionTTL is how long the position of a source that ships nothing is
+// kept, so removed containers do not pile up
+const positionTTL = 7 * 24 * time.Hour
+
+// position is where reading a source resumes
+type position struct {
+	Value   string    `json:"value"`
+	Updated time.Time `json:"updated"`
+}
+
+// positions are the read positions of every source, kept in a file
+type positions struct {
+	path string
+
+	mu sync.Mutex
+	m  map[string]position
+}
+
+func loadPositions(dir string) (*positions, error) {
+	p := &positions{path: filepath.Join(dir, positionsFile), m: make(map[string]position)}
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &p.m); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *positions) get(source string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.m[source].Value
+}
+
+// advance moves each source to the last position in batch
+func (p *positions) advance(batch []Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, e := range batch {
+		if e.Source != "" && e.Position != "" {
+			p.m[e.Source] = position{Value: e.Position, Updated: now}
+		}
+	}
+}
+
+// save writes the positions, forgetting those not updated for positionTTL
+func (p *positions) save() error {
+	p.mu.Lock()
+	for source, pos := range p.m {
+		if time.Since(pos.Updated) > positionTTL {
+			delete(p.m, source)
+		}
+	}
+	data, err := json.Marshal(p.m)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
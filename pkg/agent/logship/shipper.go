@@ -0,0 +1,286 @@
+// Package logship forwards the logs of an agent's containers and systemd
+// units to a central log store, Loki, Elasticsearch or S3, in batches. Read
+// positions advance only once a batch is stored, so a restarted agent
+// resumes from what was shipped rather than from what was read.
+package logship
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/redact"
+)
+
+const (
+	// DefaultBatchSize is how many entries go in one request
+	DefaultBatchSize = 500
+	// DefaultFlushInterval is the longest an entry waits to be sent
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultBuffer is how many entries are held while the sink is down
+	DefaultBuffer = 10000
+	// DefaultStateDir keeps the read positions
+	DefaultStateDir = "/var/lib/mandau/logship"
+	// maxBackoff caps the wait between retries of a failed batch
+	maxBackoff = 5 * time.Minute
+)
+
+// Label names entries carry besides those configured
+const (
+	LabelAgent   = "agent"
+	LabelStack   = "stack"
+	LabelService = "service"
+	LabelStream  = "stream" // stdout or stderr
+	LabelSource  = "source" // container or journal
+	LabelUnit    = "unit"
+)
+
+// StackLabel on a stack opts it in (true) or out (false) of shipping
+const StackLabel = "logs.ship"
+
+// Entry is one log line
+type Entry struct {
+	Time      time.Time
+	Line      string
+	Labels    map[string]string
+	Container string // ID of the container the line came from, if any
+	// Position is where reading resumes once the entry is stored: the
+	// container log timestamp or journal cursor, under the key Source
+	Source   string
+	Position string
+}
+
+// Sink stores batches of entries
+type Sink interface {
+	Send(ctx context.Context, entries []Entry) error
+}
+
+// Status reports how shipping is going
+type Status struct {
+	Sink        string
+	Shipped     int64
+	Dropped     int64 // Lost because the buffer was full
+	Pending     int   // Read but not yet stored
+	LastShipped time.Time
+	LastError   string // Of the last failed batch, cleared by a stored one
+	LastErrorAt time.Time
+}
+
+// labelName is what Loki accepts as a label name
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Shipper batches entries to a sink
+type Shipper struct {
+	sink      Sink
+	labels    map[string]string // Added to every entry
+	stacks    string            // all or labelled
+	batchSize int
+	interval  time.Duration
+	entries   chan Entry
+	positions *positions
+
+	mu       sync.Mutex
+	status   Status
+	redactor *redact.Redactor // Masks secrets in lines before they leave the host
+}
+
+// New returns a shipper for cfg, labelling entries with agentID. Secret is
+// the password, token or S3 secret key, read by the caller from
+// cfg.PasswordFile or the secrets plugin.
+func New(cfg config.LogShipConfig, agentID, secret string) (*Shipper, error) {
+	sink, err := NewSink(cfg, agentID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Shipper{
+		sink:      sink,
+		labels:    map[string]string{LabelAgent: agentID},
+		stacks:    cfg.Stacks,
+		batchSize: cfg.BatchSize,
+		interval:  DefaultFlushInterval,
+		status:    Status{Sink: cfg.Sink},
+	}
+	switch s.stacks {
+	case "":
+		s.stacks = "all"
+	case "all", "labelled":
+	default:
+		return nil, fmt.Errorf("logs.ship.stacks: want all or labelled, got %q", cfg.Stacks)
+	}
+	for name, value := range cfg.Labels {
+		if !labelName.MatchString(name) {
+			return nil, fmt.Errorf("logs.ship.labels: invalid label name %q", name)
+		}
+		s.labels[name] = value
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval != "" {
+		d, err := time.ParseDuration(cfg.FlushInterval)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("logs.ship.flush_interval: invalid duration %q", cfg.FlushInterval)
+		}
+		s.interval = d
+	}
+	buffer := cfg.Buffer
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	s.entries = make(chan Entry, buffer)
+
+	stateDir := cfg.StateDir
+	if stateDir == "" {
+		stateDir = DefaultStateDir
+	}
+	if s.positions, err = loadPositions(stateDir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetRedactor masks secrets in the lines shipped from now on
+func (s *Shipper) SetRedactor(r *redact.Redactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redactor = r
+}
+
+// Ships reports whether the logs of a stack with labels are shipped
+func (s *Shipper) Ships(labels map[string]string) bool {
+	switch labels[StackLabel] {
+	case "false":
+		return false
+	case "true":
+		return true
+	}
+	return s.stacks == "all"
+}
+
+// Position returns where reading source resumes, empty when it was never
+// shipped
+func (s *Shipper) Position(source string) string {
+	return s.positions.get(source)
+}
+
+// Add queues an entry, labelled with the shipper's labels. When the buffer
+// is full the entry is dropped and counted rather than holding up the
+// reader.
+func (s *Shipper) Add(e Entry) {
+	labels := make(map[string]string, len(s.labels)+len(e.Labels))
+	for k, v := range s.labels {
+		labels[k] = v
+	}
+	for k, v := range e.Labels {
+		labels[k] = v
+	}
+	e.Labels = labels
+
+	s.mu.Lock()
+	if s.redactor != nil {
+		e.Line = s.redactor.String(e.Line)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.entries <- e:
+	default:
+		s.mu.Lock()
+		s.status.Dropped++
+		s.mu.Unlock()
+	}
+}
+
+// Status returns how shipping is going
+func (s *Shipper) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Pending += len(s.entries)
+	return status
+}
+
+// Run sends batches until ctx is cancelled, then tries once more to send
+// what it holds. A batch that fails is retried with growing pauses while
+// new entries wait in the buffer.
+func (s *Shipper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	var (
+		batch   = make([]Entry, 0, s.batchSize)
+		backoff time.Duration
+		retryAt time.Time
+	)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 || time.Now().Before(retryAt) {
+			return
+		}
+		if err := s.send(ctx, batch); err != nil {
+			backoff = min(max(2*backoff, s.interval), maxBackoff)
+			retryAt = time.Now().Add(backoff)
+			return
+		}
+		batch = batch[:0]
+		backoff, retryAt = 0, time.Time{}
+	}
+
+	for {
+		// A full batch waits for the sink before more entries are taken
+		in := s.entries
+		if len(batch) >= s.batchSize {
+			in = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			retryAt = time.Time{}
+			flush(ctx)
+			cancel()
+			return
+		case e := <-in:
+			batch = append(batch, e)
+			s.setPending(len(batch))
+			if len(batch) >= s.batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
+
+// send stores a batch and advances the read positions past it
+func (s *Shipper) send(ctx context.Context, batch []Entry) error {
+	err := s.sink.Send(ctx, batch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.status.LastErrorAt = time.Now()
+		return err
+	}
+	s.status.Shipped += int64(len(batch))
+	s.status.Pending = 0
+	s.status.LastShipped = time.Now()
+	s.status.LastError = ""
+
+	s.positions.advance(batch)
+	if err := s.positions.save(); err != nil {
+		s.status.LastError = fmt.Sprintf("save read positions: %v", err)
+		s.status.LastErrorAt = time.Now()
+	}
+	return nil
+}
+
+func (s *Shipper) setPending(n int) {
+	s.mu.Lock()
+	s.status.Pending = n
+	s.mu.Unlock()
+}
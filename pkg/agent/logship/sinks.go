@@ -0,0 +1,357 @@
+package logship
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// requestTimeout bounds one request to a sink
+const requestTimeout = 30 * time.Second
+
+// NewSink returns the sink cfg names
+func NewSink(cfg config.LogShipConfig, agentID, secret string) (Sink, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	auth := httpAuth{user: cfg.Username, secret: secret}
+
+	switch cfg.Sink {
+	case "loki":
+		base, err := sinkURL(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		return &lokiSink{client: client, url: base + "/loki/api/v1/push", auth: auth}, nil
+	case "elasticsearch":
+		base, err := sinkURL(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		index := cfg.Index
+		if index == "" {
+			index = "mandau-logs"
+		}
+		return &elasticSink{client: client, url: base + "/_bulk", index: index, auth: auth}, nil
+	case "s3":
+		if cfg.Bucket == "" {
+			return nil, fmt.Errorf("logs.ship.bucket is required for s3")
+		}
+		if cfg.Username == "" || secret == "" {
+			return nil, fmt.Errorf("logs.ship for s3 needs username (the access key ID) and its secret key")
+		}
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := cfg.URL
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+		base, err := sinkURL(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		prefix := cfg.Prefix
+		if prefix == "" {
+			prefix = "logs/"
+		}
+		return &s3Sink{
+			client:    client,
+			url:       base + "/" + url.PathEscape(cfg.Bucket),
+			prefix:    prefix + url.PathEscape(agentID) + "/",
+			region:    region,
+			accessKey: cfg.Username,
+			secretKey: secret,
+		}, nil
+	}
+	return nil, fmt.Errorf("logs.ship.sink: want loki, elasticsearch or s3, got %q", cfg.Sink)
+}
+
+// sinkURL checks a sink's base URL and trims its trailing slash
+func sinkURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("logs.ship.url: want an http or https URL, got %q", raw)
+	}
+	return strings.TrimSuffix(raw, "/"), nil
+}
+
+// httpAuth is basic auth with a user, otherwise a token sent under scheme
+type httpAuth struct {
+	user   string
+	secret string
+}
+
+func (a httpAuth) apply(req *http.Request, scheme string) {
+	switch {
+	case a.user != "":
+		req.SetBasicAuth(a.user, a.secret)
+	case a.secret != "":
+		req.Header.Set("Authorization", scheme+" "+a.secret)
+	}
+}
+
+// post sends body and fails on a status other than 2xx
+func post(ctx context.Context, client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, bytes.TrimSpace(body[:min(len(body), 512)]))
+	}
+	return body, nil
+}
+
+// lokiSink pushes to Loki, one stream per label set
+type lokiSink struct {
+	client *http.Client
+	url    string
+	auth   httpAuth
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) Send(ctx context.Context, entries []Entry) error {
+	streams := make(map[string]*lokiStream)
+	var order []string
+	for _, e := range entries {
+		key := labelKey(e.Labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: e.Labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), e.Line})
+	}
+
+	push := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		push.Streams = append(push.Streams, streams[key])
+	}
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.auth.apply(req, "Bearer")
+	_, err = post(ctx, s.client, req)
+	return err
+}
+
+// labelKey identifies a label set
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%q,", k, labels[k])
+	}
+	return b.String()
+}
+
+// document is an entry as Elasticsearch and S3 store it
+func document(e Entry) map[string]string {
+	doc := make(map[string]string, len(e.Labels)+3)
+	for k, v := range e.Labels {
+		doc[k] = v
+	}
+	doc["@timestamp"] = e.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = e.Line
+	if e.Container != "" {
+		doc["container"] = e.Container
+	}
+	return doc
+}
+
+// ndjson writes one document per line, each preceded by action when set
+func ndjson(entries []Entry, action []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if action != nil {
+			buf.Write(action)
+			buf.WriteByte('\n')
+		}
+		line, err := json.Marshal(document(e))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// elasticSink indexes entries with the bulk API. It creates rather than
+// indexes documents, so the index may be a data stream.
+type elasticSink struct {
+	client *http.Client
+	url    string
+	index  string
+	auth   httpAuth
+}
+
+func (s *elasticSink) Send(ctx context.Context, entries []Entry) error {
+	action, err := json.Marshal(map[string]map[string]string{"create": {"_index": s.index}})
+	if err != nil {
+		return err
+	}
+	body, err := ndjson(entries, action)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	s.auth.apply(req, "ApiKey")
+	respBody, err := post(ctx, s.client, req)
+	if err != nil {
+		return err
+	}
+
+	// The bulk API answers 200 when single documents fail
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("parse bulk response: %w", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+	failed := 0
+	var first string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				failed++
+				if first == "" {
+					first = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d entries rejected, first: %s", failed, len(entries), first)
+}
+
+// s3Sink writes each batch as a gzipped NDJSON object under
+// prefix/agent/YYYY/MM/DD/
+type s3Sink struct {
+	client    *http.Client
+	url       string // Endpoint and bucket, path style
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	now       func() time.Time // For tests
+}
+
+func (s *s3Sink) Send(ctx context.Context, entries []Entry) error {
+	data, err := ndjson(entries, nil)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	if _, err := gz.Write(data); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+	first := entries[0].Time.UTC()
+	key := fmt.Sprintf("%s%s/%s-%d.ndjson.gz", s.prefix, first.Format("2006/01/02"), first.Format("150405"), now.UnixNano())
+
+	req, err := http.NewRequest(http.MethodPut, s.url+"/"+key, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+	signV4(req, body.Bytes(), s.accessKey, s.secretKey, s.region, now)
+	_, err = post(ctx, s.client, req)
+	return err
+}
+
+// signV4 signs an S3 request with AWS Signature Version 4
+func signV4(req *http.Request, body []byte, accessKey, secretKey, region string, now time.Time) {
+	payload := sha256Hex(body)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+
+	const signed = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payload + "\nx-amz-date:" + amzDate + "\n",
+		signed,
+		payload,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signed, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,209 @@
+// Package mac detects whether SELinux or AppArmor enforce a policy on the
+// host and works out the labels and policy changes host services need
+// under it: web roots nginx can read, upstreams it can connect to, ports it
+// can bind and unit binaries systemd can run.
+package mac
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Modules a Fix is for
+const (
+	SELinux  = "SELinux"
+	AppArmor = "AppArmor"
+)
+
+// nginxProfile is where distributions keep the AppArmor profile of nginx
+const nginxProfile = "/etc/apparmor.d/usr.sbin.nginx"
+
+// httpPorts are labeled http_port_t by the SELinux reference policy, so
+// nginx may bind them without a change
+var httpPorts = map[int]bool{80: true, 81: true, 443: true, 488: true, 8008: true, 8009: true, 8443: true, 9000: true}
+
+// Status is the mandatory access control the host enforces
+type Status struct {
+	SELinux       bool // Enforcing; a permissive policy only logs denials
+	NginxConfined bool // AppArmor enforces a profile on nginx
+}
+
+// Detect reads the enforcement state of the host from /sys
+func Detect() Status {
+	return detect("/")
+}
+
+func detect(root string) Status {
+	var s Status
+	if data, err := os.ReadFile(filepath.Join(root, "sys/fs/selinux/enforce")); err == nil {
+		s.SELinux = strings.TrimSpace(string(data)) == "1"
+	}
+
+	f, err := os.Open(filepath.Join(root, "sys/kernel/security/apparmor/profiles"))
+	if err != nil {
+		return s
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Each line is "<profile> (<mode>)"
+		name, mode, ok := strings.Cut(scanner.Text(), " (")
+		if ok && mode == "enforce)" && (name == "nginx" || name == "/usr/sbin/nginx") {
+			s.NginxConfined = true
+		}
+	}
+	return s
+}
+
+// Enforcing reports whether either module enforces a policy that matters
+// to host services
+func (s Status) Enforcing() bool {
+	return s.SELinux || s.NginxConfined
+}
+
+func (s Status) String() string {
+	var parts []string
+	if s.SELinux {
+		parts = append(parts, "SELinux enforcing")
+	}
+	if s.NginxConfined {
+		parts = append(parts, "AppArmor confining nginx")
+	}
+	if len(parts) == 0 {
+		return "no SELinux or AppArmor enforcement"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fix is a change the policy needs before a host service works. Apply
+// holds the commands making it; when there are none it is made by hand as
+// Manual describes. Undo reverts the commands and is empty for changes
+// shared with other services.
+type Fix struct {
+	Module string
+	Reason string // What the change allows, e.g. "let nginx read /srv/site"
+	Apply  [][]string
+	Undo   [][]string
+	Manual string
+}
+
+// Guidance tells an operator how to make the change
+func (f Fix) Guidance() string {
+	if len(f.Apply) == 0 {
+		return f.Module + ": to " + f.Reason + ", " + f.Manual
+	}
+	cmds := make([]string, len(f.Apply))
+	for i, cmd := range f.Apply {
+		cmds[i] = shellJoin(cmd)
+	}
+	return f.Module + ": to " + f.Reason + ", run " + strings.Join(cmds, " && ")
+}
+
+// WebRoot lets nginx serve the files under dir
+func (s Status) WebRoot(dir string) []Fix {
+	var fixes []Fix
+	if s.SELinux {
+		spec := dir + "(/.*)?"
+		fixes = append(fixes, Fix{
+			Module: SELinux,
+			Reason: "let nginx read " + dir,
+			Apply: [][]string{
+				{"semanage", "fcontext", "-a", "-t", "httpd_sys_content_t", spec},
+				{"restorecon", "-R", dir},
+			},
+			Undo: [][]string{
+				{"semanage", "fcontext", "-d", spec},
+				{"restorecon", "-R", dir},
+			},
+		})
+	}
+	if s.NginxConfined {
+		fixes = append(fixes, Fix{
+			Module: AppArmor,
+			Reason: "let nginx read " + dir,
+			Manual: "add \"" + dir + "/** r,\" to " + localProfile(nginxProfile) +
+				" and run apparmor_parser -r " + nginxProfile,
+		})
+	}
+	return fixes
+}
+
+// ProxyUpstream lets nginx connect to the services it proxies. The boolean
+// is shared by every site, so it is not undone.
+func (s Status) ProxyUpstream() []Fix {
+	if !s.SELinux {
+		return nil
+	}
+	return []Fix{{
+		Module: SELinux,
+		Reason: "let nginx connect to its upstreams",
+		Apply:  [][]string{{"setsebool", "-P", "httpd_can_network_connect", "1"}},
+	}}
+}
+
+// ListenPort lets nginx bind a TCP port
+func (s Status) ListenPort(port int) []Fix {
+	if !s.SELinux || httpPorts[port] {
+		return nil
+	}
+	p := strconv.Itoa(port)
+	return []Fix{{
+		Module: SELinux,
+		Reason: "let nginx listen on port " + p,
+		Apply:  [][]string{{"semanage", "port", "-a", "-t", "http_port_t", "-p", "tcp", p}},
+		Undo:   [][]string{{"semanage", "port", "-d", "-t", "http_port_t", "-p", "tcp", p}},
+	}}
+}
+
+// UnitExec lets systemd run the binary a unit starts. Binaries under home
+// and temporary directories carry labels init may not execute; the rest of
+// the file system is left to the policy.
+func (s Status) UnitExec(command string) []Fix {
+	fields := strings.Fields(command)
+	if !s.SELinux || len(fields) == 0 || !filepath.IsAbs(fields[0]) {
+		return nil
+	}
+	path := filepath.Clean(fields[0])
+	confined := false
+	for _, dir := range []string{"/home/", "/root/", "/tmp/", "/var/tmp/"} {
+		if strings.HasPrefix(path, dir) {
+			confined = true
+		}
+	}
+	if !confined {
+		return nil
+	}
+	return []Fix{{
+		Module: SELinux,
+		Reason: "let systemd run " + path,
+		Apply: [][]string{
+			{"semanage", "fcontext", "-a", "-t", "bin_t", path},
+			{"restorecon", path},
+		},
+		Undo: [][]string{
+			{"semanage", "fcontext", "-d", path},
+			{"restorecon", path},
+		},
+	}}
+}
+
+// localProfile is the include distributions leave for local additions to
+// profile
+func localProfile(profile string) string {
+	return filepath.Join(filepath.Dir(profile), "local", filepath.Base(profile))
+}
+
+// shellJoin quotes the arguments of cmd that a shell would split or expand
+func shellJoin(cmd []string) string {
+	quoted := make([]string, len(cmd))
+	for i, arg := range cmd {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`*?()[]{}|&;<>~#!") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,71 @@
+package mac
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	if s := detect(t.TempDir()); s.Enforcing() {
+		t.Errorf("bare host: %v, want no enforcement", s)
+	}
+
+	root := t.TempDir()
+	writeFile(t, root, "sys/fs/selinux/enforce", "0\n")
+	writeFile(t, root, "sys/kernel/security/apparmor/profiles", "/usr/sbin/nginx (complain)\ndocker-default (enforce)\n")
+	if s := detect(root); s.Enforcing() {
+		t.Errorf("permissive host: %v, want no enforcement", s)
+	}
+
+	writeFile(t, root, "sys/fs/selinux/enforce", "1\n")
+	writeFile(t, root, "sys/kernel/security/apparmor/profiles", "/usr/sbin/nginx (enforce)\n")
+	if s := detect(root); !s.SELinux || !s.NginxConfined {
+		t.Errorf("enforcing host: %+v, want SELinux and nginx confined", s)
+	}
+}
+
+func TestFixes(t *testing.T) {
+	none := Status{}
+	if fixes := append(none.WebRoot("/srv/site"), none.UnitExec("/home/app/run")...); len(fixes) != 0 {
+		t.Errorf("unconfined host: fixes = %v, want none", fixes)
+	}
+
+	s := Status{SELinux: true, NginxConfined: true}
+	root := s.WebRoot("/srv/my site")
+	if len(root) != 2 {
+		t.Fatalf("WebRoot() = %v, want SELinux and AppArmor fixes", root)
+	}
+	want := "SELinux: to let nginx read /srv/my site, run semanage fcontext -a -t httpd_sys_content_t '/srv/my site(/.*)?' && restorecon -R '/srv/my site'"
+	if got := root[0].Guidance(); got != want {
+		t.Errorf("Guidance() = %q\nwant %q", got, want)
+	}
+	if got := root[1].Guidance(); !strings.Contains(got, "/etc/apparmor.d/local/usr.sbin.nginx") {
+		t.Errorf("AppArmor guidance = %q, want the local nginx profile", got)
+	}
+
+	if fixes := s.ListenPort(443); len(fixes) != 0 {
+		t.Errorf("ListenPort(443) = %v, want none", fixes)
+	}
+	if fixes := s.ListenPort(8081); len(fixes) != 1 || len(fixes[0].Undo) != 1 {
+		t.Errorf("ListenPort(8081) = %v, want a port label with undo", fixes)
+	}
+	if fixes := s.UnitExec("/usr/bin/app --serve"); len(fixes) != 0 {
+		t.Errorf("UnitExec(/usr/bin) = %v, want none", fixes)
+	}
+	if fixes := s.UnitExec("/home/app/bin/server --port 8080"); len(fixes) != 1 || fixes[0].Apply[0][5] != "/home/app/bin/server" {
+		t.Errorf("UnitExec(/home) = %v, want the binary labeled bin_t", fixes)
+	}
+}
@@ -0,0 +1,321 @@
+// Package mirror pulls images through mirrors of their registry, for hosts
+// that cannot or should not reach public registries. Mirrors of an image's
+// registry are tried in the configured order, those failing their health
+// check last, then the registry itself unless upstream pulls are off.
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/distribution/reference"
+	"github.com/moby/moby/client"
+)
+
+const (
+	// DefaultRegistry is the registry mirrors stand in for unless they name one
+	DefaultRegistry = "docker.io"
+	// DefaultHealthInterval is the time between health checks of mirrors
+	DefaultHealthInterval = 30 * time.Second
+	// healthTimeout bounds one health check
+	healthTimeout = 5 * time.Second
+)
+
+// ErrNoSource is returned for images no mirror or registry may serve
+var ErrNoSource = errors.New("no registry to pull from")
+
+// Docker is the part of the Docker client pulls need
+type Docker interface {
+	ImagePull(ctx context.Context, ref string, options client.ImagePullOptions) (client.ImagePullResponse, error)
+	ImageTag(ctx context.Context, options client.ImageTagOptions) (client.ImageTagResult, error)
+}
+
+// Mirror serves the repositories of Registry from Host, under Prefix
+type Mirror struct {
+	Registry string
+	Host     string
+	Prefix   string
+	url      string // Registry API root health checks request
+}
+
+func (m Mirror) String() string {
+	return path.Join(m.Host, m.Prefix)
+}
+
+// Health is the outcome of a mirror's latest health check. Mirrors not yet
+// checked count as healthy.
+type Health struct {
+	Healthy   bool
+	CheckedAt time.Time
+	Error     string
+}
+
+// Set is the mirrors of an agent. A nil Set pulls every image from its
+// registry.
+type Set struct {
+	mirrors  []Mirror
+	upstream bool
+	interval time.Duration
+	client   *http.Client
+
+	mu     sync.RWMutex
+	health []Health // By index in mirrors
+}
+
+// New reads the mirrors of cfg; it returns nil when there are none
+func New(cfg config.RegistryMirrorsConfig) (*Set, error) {
+	if len(cfg.Mirrors) == 0 {
+		if cfg.NoUpstream {
+			return nil, errors.New("no_upstream is set but no mirrors are configured")
+		}
+		return nil, nil
+	}
+
+	s := &Set{
+		upstream: !cfg.NoUpstream,
+		interval: DefaultHealthInterval,
+		client:   &http.Client{Timeout: healthTimeout},
+	}
+	if cfg.HealthInterval != "" {
+		d, err := time.ParseDuration(cfg.HealthInterval)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid health_interval %q", cfg.HealthInterval)
+		}
+		s.interval = d
+	}
+
+	for _, mc := range cfg.Mirrors {
+		m, err := parseMirror(mc)
+		if err != nil {
+			return nil, err
+		}
+		s.mirrors = append(s.mirrors, m)
+		s.health = append(s.health, Health{Healthy: true})
+	}
+	return s, nil
+}
+
+func parseMirror(cfg config.RegistryMirrorConfig) (Mirror, error) {
+	raw := cfg.URL
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return Mirror{}, fmt.Errorf("invalid mirror url %q: want host[:port][/path]", cfg.URL)
+	}
+
+	registry := normalizeRegistry(cfg.Registry)
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	return Mirror{
+		Registry: registry,
+		Host:     u.Host,
+		Prefix:   strings.Trim(u.Path, "/"),
+		url:      u.Scheme + "://" + u.Host + "/v2/",
+	}, nil
+}
+
+// normalizeRegistry maps the names Docker Hub goes by to docker.io
+func normalizeRegistry(registry string) string {
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io":
+		return DefaultRegistry
+	}
+	return registry
+}
+
+// Source is where an image may be pulled from: Ref on Mirror, or the image
+// itself from its registry when Mirror is empty
+type Source struct {
+	Ref    string
+	Mirror string
+}
+
+// Sources lists where to pull image from, in the order to try them: the
+// healthy mirrors of its registry, its registry unless upstream pulls are
+// off, then the unhealthy mirrors. Images pinned by digest come from their
+// registry alone, since Docker finds them locally by that name only.
+func (s *Set) Sources(image string) ([]Source, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("parse image %s: %w", image, err)
+	}
+	upstream := Source{Ref: image}
+	if s == nil {
+		return []Source{upstream}, nil
+	}
+	if _, ok := named.(reference.Digested); ok {
+		if !s.upstream {
+			return nil, fmt.Errorf("%w: %s is pinned by digest, which mirrors cannot serve under its name, and no_upstream is set", ErrNoSource, image)
+		}
+		return []Source{upstream}, nil
+	}
+
+	tagged := reference.TagNameOnly(named).(reference.Tagged)
+	registry := reference.Domain(named)
+	repo := reference.Path(named)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var healthy, unhealthy []Source
+	for i, m := range s.mirrors {
+		if m.Registry != registry {
+			continue
+		}
+		src := Source{Ref: path.Join(m.Host, m.Prefix, repo) + ":" + tagged.Tag(), Mirror: m.String()}
+		if s.health[i].Healthy {
+			healthy = append(healthy, src)
+		} else {
+			unhealthy = append(unhealthy, src)
+		}
+	}
+
+	sources := healthy
+	if s.upstream {
+		sources = append(sources, upstream)
+	}
+	sources = append(sources, unhealthy...)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: no mirror of %s is configured and no_upstream is set", ErrNoSource, registry)
+	}
+	return sources, nil
+}
+
+// Pull pulls image from the first of its sources that has it. An image
+// pulled from a mirror is tagged with its own name, so that compose finds
+// it. event is told of each source that fails. It returns the source used.
+func (s *Set) Pull(ctx context.Context, docker Docker, image string, event func(string)) (Source, error) {
+	sources, err := s.Sources(image)
+	if err != nil {
+		return Source{}, err
+	}
+
+	var errs []error
+	for _, src := range sources {
+		err := pull(ctx, docker, src.Ref)
+		if err == nil && src.Mirror != "" {
+			_, err = docker.ImageTag(ctx, client.ImageTagOptions{Source: src.Ref, Target: image})
+		}
+		if err == nil {
+			return src, nil
+		}
+
+		from := src.Mirror
+		if from == "" {
+			from = "its registry"
+		}
+		err = fmt.Errorf("from %s: %w", from, err)
+		errs = append(errs, err)
+		if event != nil {
+			event(fmt.Sprintf("Pulling %s %v", image, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return Source{}, fmt.Errorf("pull %s: %w", image, errors.Join(errs...))
+}
+
+// pull waits for the pull to finish; errors Docker reports midway, such as
+// a missing manifest, arrive in the progress stream
+func pull(ctx context.Context, docker Docker, ref string) error {
+	resp, err := docker.ImagePull(ctx, ref, client.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+	return resp.Wait(ctx)
+}
+
+// Upstream reports whether images may be pulled from their registry
+func (s *Set) Upstream() bool {
+	return s == nil || s.upstream
+}
+
+// Status is a mirror and its health
+type Status struct {
+	Mirror
+	Health
+}
+
+// Status returns every mirror with its health, in the configured order
+func (s *Set) Status() []Status {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := make([]Status, len(s.mirrors))
+	for i, m := range s.mirrors {
+		status[i] = Status{Mirror: m, Health: s.health[i]}
+	}
+	return status
+}
+
+// Check requests the registry API root of every mirror. Any answer below
+// 500, including the 401 of registries that need a login, is healthy.
+func (s *Set) Check(ctx context.Context) {
+	for i, m := range s.mirrors {
+		h := Health{Healthy: true, CheckedAt: time.Now()}
+		if err := s.check(ctx, m); err != nil {
+			h.Healthy, h.Error = false, err.Error()
+		}
+		s.mu.Lock()
+		s.health[i] = h
+		s.mu.Unlock()
+	}
+}
+
+func (s *Set) check(ctx context.Context, m Mirror) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s answered %s", m.url, resp.Status)
+	}
+	return nil
+}
+
+// Run checks the mirrors now and every health interval until ctx is
+// cancelled. changed is called for mirrors whose health changed.
+func (s *Set) Run(ctx context.Context, changed func(Status)) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		before := s.Status()
+		s.Check(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		for i, st := range s.Status() {
+			if st.Healthy != before[i].Healthy {
+				changed(st)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
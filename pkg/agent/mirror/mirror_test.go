@@ -0,0 +1,183 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/client"
+)
+
+func refs(sources []Source) string {
+	var out []string
+	for _, src := range sources {
+		out = append(out, src.Ref)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestSources(t *testing.T) {
+	s, err := New(config.RegistryMirrorsConfig{Mirrors: []config.RegistryMirrorConfig{
+		{URL: "mirror-a.internal:5000"},
+		{URL: "https://harbor.internal/hub/", Registry: "index.docker.io"},
+		{URL: "harbor.internal/ghcr", Registry: "ghcr.io"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := s.Sources("nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := refs(sources); got != "mirror-a.internal:5000/library/nginx:latest harbor.internal/hub/library/nginx:latest nginx" {
+		t.Errorf("nginx sources = %s", got)
+	}
+	if sources[1].Mirror != "harbor.internal/hub" || sources[2].Mirror != "" {
+		t.Errorf("nginx mirrors = %+v", sources)
+	}
+
+	// Unhealthy mirrors are tried after the registry itself
+	s.health[0] = Health{Error: "connection refused"}
+	sources, _ = s.Sources("ghcr.io/acme/api:1.2")
+	if got := refs(sources); got != "harbor.internal/ghcr/acme/api:1.2 ghcr.io/acme/api:1.2" {
+		t.Errorf("ghcr sources = %s", got)
+	}
+	sources, _ = s.Sources("nginx:1.27")
+	if got := refs(sources); got != "harbor.internal/hub/library/nginx:1.27 nginx:1.27 mirror-a.internal:5000/library/nginx:1.27" {
+		t.Errorf("sources with an unhealthy mirror = %s", got)
+	}
+
+	// Digests are only served under the image's own name
+	digest := "nginx@sha256:" + strings.Repeat("a", 64)
+	if sources, _ := s.Sources(digest); refs(sources) != digest {
+		t.Errorf("digest sources = %s", refs(sources))
+	}
+
+	s.upstream = false
+	if _, err := s.Sources("quay.io/acme/api:1"); !errors.Is(err, ErrNoSource) {
+		t.Errorf("unmirrored registry without upstream: error = %v", err)
+	}
+	if _, err := s.Sources(digest); !errors.Is(err, ErrNoSource) {
+		t.Errorf("digest without upstream: error = %v", err)
+	}
+
+	var none *Set
+	if sources, _ := none.Sources("nginx"); refs(sources) != "nginx" {
+		t.Errorf("nil set sources = %s", refs(sources))
+	}
+
+	for _, bad := range []config.RegistryMirrorsConfig{
+		{NoUpstream: true},
+		{Mirrors: []config.RegistryMirrorConfig{{URL: "ftp://mirror"}}},
+		{Mirrors: []config.RegistryMirrorConfig{{URL: "mirror"}}, HealthInterval: "often"},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("New(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	status := func(code int) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v2/" {
+				t.Errorf("health check requested %s", r.URL.Path)
+			}
+			w.WriteHeader(code)
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	s, err := New(config.RegistryMirrorsConfig{Mirrors: []config.RegistryMirrorConfig{
+		{URL: status(http.StatusOK)},
+		{URL: status(http.StatusUnauthorized) + "/hub"},
+		{URL: status(http.StatusServiceUnavailable)},
+		{URL: down.URL},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Check(context.Background())
+
+	for i, want := range []bool{true, true, false, false} {
+		st := s.Status()[i]
+		if st.Healthy != want || st.CheckedAt.IsZero() || (st.Error == "") == !want {
+			t.Errorf("mirror %d: %+v, want healthy %v", i, st, want)
+		}
+	}
+}
+
+// fakeDocker has the images in have, under any name
+type fakeDocker struct {
+	have   map[string]bool
+	pulled []string
+	tags   map[string]string
+}
+
+type pullResponse struct {
+	io.ReadCloser
+	err error
+}
+
+func (r pullResponse) JSONMessages(ctx context.Context) iter.Seq2[jsonstream.Message, error] {
+	return func(func(jsonstream.Message, error) bool) {}
+}
+
+func (r pullResponse) Wait(ctx context.Context) error { return r.err }
+
+func (d *fakeDocker) ImagePull(ctx context.Context, ref string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
+	d.pulled = append(d.pulled, ref)
+	resp := pullResponse{ReadCloser: io.NopCloser(strings.NewReader(""))}
+	if !d.have[ref] {
+		// Registries report missing manifests in the progress stream
+		resp.err = errors.New("manifest unknown")
+	}
+	return resp, nil
+}
+
+func (d *fakeDocker) ImageTag(ctx context.Context, options client.ImageTagOptions) (client.ImageTagResult, error) {
+	d.tags[options.Target] = options.Source
+	return client.ImageTagResult{}, nil
+}
+
+func TestPull(t *testing.T) {
+	s, err := New(config.RegistryMirrorsConfig{Mirrors: []config.RegistryMirrorConfig{
+		{URL: "mirror-a.internal"},
+		{URL: "mirror-b.internal"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	docker := &fakeDocker{have: map[string]bool{"mirror-b.internal/library/redis:7": true}, tags: map[string]string{}}
+	var events []string
+	src, err := s.Pull(ctx, docker, "redis:7", func(msg string) { events = append(events, msg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Mirror != "mirror-b.internal" || docker.tags["redis:7"] != "mirror-b.internal/library/redis:7" {
+		t.Errorf("pulled from %+v, tags %v: want mirror-b tagged as redis:7", src, docker.tags)
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "mirror-a.internal: manifest unknown") {
+		t.Errorf("events = %q, want mirror-a's failure", events)
+	}
+
+	// Without any source having it, every failure is reported
+	docker = &fakeDocker{have: map[string]bool{}, tags: map[string]string{}}
+	_, err = s.Pull(ctx, docker, "redis:8", nil)
+	if err == nil || len(docker.pulled) != 3 || !strings.Contains(err.Error(), "from its registry: manifest unknown") {
+		t.Errorf("pulled %v: error = %v", docker.pulled, err)
+	}
+}
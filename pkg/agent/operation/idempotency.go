@@ -0,0 +1,58 @@
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrKeyReused is returned when an idempotency key comes back with a
+// different request than the one it started an operation for
+var ErrKeyReused = errors.New("idempotency key was used for a different request")
+
+// Idempotency is the idempotency key a request carried and a fingerprint of
+// the request, so a retry can be told from a key reused by mistake
+type Idempotency struct {
+	Key         string
+	Fingerprint string
+}
+
+// admissionFields decide how a request reaches the agent rather than what it
+// does; a retry may change them, for instance with a fresh approval
+var admissionFields = map[protoreflect.Name]bool{
+	"idempotency_key": true,
+	"agent_id":        true,
+	"approval_id":     true,
+	"emergency":       true,
+	"group":           true,
+	"queue":           true,
+	"queue_ttl":       true,
+}
+
+// NewIdempotency returns the idempotency of req sent with key. Without a key
+// there is nothing to dedupe and the zero Idempotency is returned.
+func NewIdempotency(key string, req proto.Message) Idempotency {
+	if key == "" {
+		return Idempotency{}
+	}
+
+	msg := proto.Clone(req).ProtoReflect()
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if admissionFields[fd.Name()] {
+			msg.Clear(fd)
+		}
+		return true
+	})
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg.Interface())
+	if err != nil {
+		// Unreachable for generated messages; an empty fingerprint only
+		// matches other requests that failed the same way
+		data = nil
+	}
+	sum := sha256.Sum256(data)
+	return Idempotency{Key: key, Fingerprint: hex.EncodeToString(sum[:])}
+}
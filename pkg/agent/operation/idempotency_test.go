@@ -0,0 +1,57 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestNewIdempotency(t *testing.T) {
+	req := &agentv1.ApplyStackRequest{StackName: "web", ComposeContent: "services: {}", ApprovalId: "a1"}
+
+	// Retries may come through a fresh approval
+	retry := &agentv1.ApplyStackRequest{StackName: "web", ComposeContent: "services: {}", ApprovalId: "a2", IdempotencyKey: "k1"}
+	if NewIdempotency("k1", req) != NewIdempotency("k1", retry) {
+		t.Error("retry fingerprint differs from the first request's")
+	}
+
+	changed := &agentv1.ApplyStackRequest{StackName: "web", ComposeContent: "services: {api: {}}"}
+	if NewIdempotency("k1", req).Fingerprint == NewIdempotency("k1", changed).Fingerprint {
+		t.Error("different content has the same fingerprint")
+	}
+
+	if idem := NewIdempotency("", req); idem != (Idempotency{}) {
+		t.Errorf("NewIdempotency() without a key = %+v, want zero", idem)
+	}
+}
+
+func TestFindIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	m, _, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &agentv1.RemoveStackRequest{StackId: "web"}
+	idem := NewIdempotency("k1", req)
+	if id, err := m.FindIdempotent(idem); id != "" || err != nil {
+		t.Fatalf("FindIdempotent() of a new key = %q, %v", id, err)
+	}
+	opID := m.CreateIdempotentOperation(context.Background(), OperationTypeStackRemove, nil, idem)
+
+	// Keys survive restarts with the operation records
+	m, _, err = NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := m.FindIdempotent(idem); id != opID || err != nil {
+		t.Errorf("FindIdempotent() of a retry = %q, %v; want %s", id, err, opID)
+	}
+
+	other := NewIdempotency("k1", &agentv1.RemoveStackRequest{StackId: "db"})
+	if _, err := m.FindIdempotent(other); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("FindIdempotent() of a reused key: %v, want ErrKeyReused", err)
+	}
+}
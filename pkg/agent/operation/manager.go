@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/requestid"
 	"github.com/google/uuid"
 )
 
@@ -13,6 +15,9 @@ type Manager struct {
 	mu         sync.RWMutex
 	operations map[string]*Operation
 	listeners  map[string][]chan Event
+	keys       map[string]string // Idempotency key to the operation it started
+	dir        string            // Where operation records are kept; empty keeps them in memory
+	redactor   *redact.Redactor  // Masks secrets in event messages and errors
 }
 
 type Operation struct {
@@ -23,7 +28,13 @@ type Operation struct {
 	CompletedAt *time.Time
 	Error       error
 	Progress    int
+	Message     string // Last event message
 	Metadata    map[string]string
+	// IdempotencyKey is the key of the request that started the operation
+	IdempotencyKey string
+	// RequestID correlates the operation with the command that started it
+	RequestID   string
+	fingerprint string
 	cancelFunc  context.CancelFunc
 }
 
@@ -35,6 +46,7 @@ const (
 	OperationTypeImagePull   OperationType = "image.pull"
 	OperationTypeExec        OperationType = "container.exec"
 	OperationTypeBackup      OperationType = "backup"
+	OperationTypeHostReboot  OperationType = "host.reboot"
 )
 
 type OperationState int
@@ -60,9 +72,19 @@ func NewManager() *Manager {
 	return &Manager{
 		operations: make(map[string]*Operation),
 		listeners:  make(map[string][]chan Event),
+		keys:       make(map[string]string),
+		redactor:   redact.Default(),
 	}
 }
 
+// SetRedactor replaces the redactor applied to event messages and errors
+// before they are stored or sent
+func (m *Manager) SetRedactor(r *redact.Redactor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redactor = r
+}
+
 // emitEventLocked sends an event to all listeners for the operation
 // Must be called with mu locked
 func (m *Manager) emitEventLocked(event Event) {
@@ -77,8 +99,14 @@ func (m *Manager) emitEventLocked(event Event) {
 	}
 }
 
-// CreateOperation creates a new operation
-func (m *Manager) CreateOperation(opType OperationType, metadata map[string]string) string {
+// CreateOperation creates a new operation for the request in ctx
+func (m *Manager) CreateOperation(ctx context.Context, opType OperationType, metadata map[string]string) string {
+	return m.CreateIdempotentOperation(ctx, opType, metadata, Idempotency{})
+}
+
+// CreateIdempotentOperation creates an operation for a request sent with an
+// idempotency key, so FindIdempotent returns it to retries of the request
+func (m *Manager) CreateIdempotentOperation(ctx context.Context, opType OperationType, metadata map[string]string, idem Idempotency) string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -93,13 +121,42 @@ func (m *Manager) CreateOperation(opType OperationType, metadata map[string]stri
 		CreatedAt:  time.Now(),
 		Metadata:   metadata,
 		cancelFunc: cancel,
+
+		IdempotencyKey: idem.Key,
+		RequestID:      requestid.From(ctx),
+		fingerprint:    idem.Fingerprint,
 	}
 
 	m.operations[opID] = op
+	if idem.Key != "" {
+		m.keys[idem.Key] = opID
+	}
+	m.persist(op)
 
 	return opID
 }
 
+// FindIdempotent returns the ID of the operation a request with idem's key
+// started, or "" when the key is new. A key that started an operation for a
+// different request is refused with ErrKeyReused.
+func (m *Manager) FindIdempotent(idem Idempotency) (string, error) {
+	if idem.Key == "" {
+		return "", nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	op, exists := m.operations[m.keys[idem.Key]]
+	if !exists {
+		return "", nil
+	}
+	if op.fingerprint != idem.Fingerprint {
+		return "", fmt.Errorf("%w: %s started operation %s", ErrKeyReused, idem.Key, op.ID)
+	}
+	return op.ID, nil
+}
+
 // GetOperation retrieves operation by ID
 func (m *Manager) GetOperation(opID string) (*Operation, error) {
 	m.mu.RLock()
@@ -139,6 +196,7 @@ func (m *Manager) SetState(opID string, state OperationState) {
 	}
 
 	op.State = state
+	m.persist(op)
 
 	m.emitEventLocked(Event{
 		OperationID: opID,
@@ -177,6 +235,10 @@ func (m *Manager) EmitEvent(opID string, message string) {
 		return
 	}
 
+	message = m.redactor.String(message)
+	op.Message = message
+	m.persist(op)
+
 	m.emitEventLocked(Event{
 		OperationID: opID,
 		State:       op.State,
@@ -185,6 +247,53 @@ func (m *Manager) EmitEvent(opID string, message string) {
 	})
 }
 
+// SetMetadata records key on an operation. The metadata map is replaced,
+// not changed, so readers holding the old one are unaffected.
+func (m *Manager) SetMetadata(opID, key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, exists := m.operations[opID]
+	if !exists {
+		return
+	}
+
+	metadata := make(map[string]string, len(op.Metadata)+1)
+	for k, v := range op.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	op.Metadata = metadata
+	m.persist(op)
+}
+
+// Reopen sets an operation interrupted by an agent restart running again,
+// for operations meant to span one, such as a host reboot
+func (m *Manager) Reopen(opID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, exists := m.operations[opID]
+	if !exists {
+		return fmt.Errorf("operation not found: %s", opID)
+	}
+	if op.Error != ErrInterrupted {
+		return fmt.Errorf("operation %s was not interrupted", opID)
+	}
+
+	op.State = OperationStateRunning
+	op.Error = nil
+	op.CompletedAt = nil
+	m.persist(op)
+
+	m.emitEventLocked(Event{
+		OperationID: opID,
+		State:       OperationStateRunning,
+		Timestamp:   time.Now(),
+	})
+	return nil
+}
+
 // SetError marks operation as failed
 func (m *Manager) SetError(opID string, err error) {
 	m.mu.Lock()
@@ -195,10 +304,12 @@ func (m *Manager) SetError(opID string, err error) {
 		return
 	}
 
+	err = m.redactor.Error(err)
 	op.State = OperationStateFailed
 	op.Error = err
 	now := time.Now()
 	op.CompletedAt = &now
+	m.persist(op)
 
 	m.emitEventLocked(Event{
 		OperationID: opID,
@@ -222,6 +333,7 @@ func (m *Manager) SetCompleted(opID string) {
 	op.Progress = 100
 	now := time.Now()
 	op.CompletedAt = &now
+	m.persist(op)
 
 	m.emitEventLocked(Event{
 		OperationID: opID,
@@ -249,6 +361,7 @@ func (m *Manager) Cancel(opID string) error {
 	op.State = OperationStateCancelled
 	now := time.Now()
 	op.CompletedAt = &now
+	m.persist(op)
 
 	m.emitEventLocked(Event{
 		OperationID: opID,
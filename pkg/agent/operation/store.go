@@ -0,0 +1,187 @@
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// recordRetention is how long finished operations stay on disk
+const recordRetention = 7 * 24 * time.Hour
+
+// ErrInterrupted is the error of operations cut short by an agent restart
+var ErrInterrupted = errors.New("interrupted by agent restart")
+
+// record is the on-disk form of an operation
+type record struct {
+	ID          string            `json:"id"`
+	Type        OperationType     `json:"type"`
+	State       OperationState    `json:"state"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Progress    int               `json:"progress"`
+	Message     string            `json:"message,omitempty"` // Last event message
+	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Fingerprint    string `json:"fingerprint,omitempty"` // Of the request sent with the key
+	RequestID      string `json:"request_id,omitempty"`
+}
+
+// NewPersistentManager returns a manager that records every operation under
+// dir so it survives restarts. Operations that were pending or running when
+// the agent stopped are marked failed with ErrInterrupted and returned, so
+// the caller can report and reconcile them.
+func NewPersistentManager(dir string) (*Manager, []*Operation, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, fmt.Errorf("create operations dir: %w", err)
+	}
+
+	m := NewManager()
+	m.dir = dir
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read operations dir: %w", err)
+	}
+
+	now := time.Now()
+	var interrupted []*Operation
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		op, err := readRecord(path)
+		if err != nil {
+			log.Printf("Skipping operation record %s: %v", path, err)
+			continue
+		}
+
+		if op.CompletedAt != nil && now.Sub(*op.CompletedAt) > recordRetention {
+			os.Remove(path)
+			continue
+		}
+
+		if op.State == OperationStatePending || op.State == OperationStateRunning {
+			op.State = OperationStateFailed
+			op.Error = ErrInterrupted
+			op.CompletedAt = &now
+			interrupted = append(interrupted, op)
+		}
+
+		m.operations[op.ID] = op
+		if op.IdempotencyKey != "" {
+			m.keys[op.IdempotencyKey] = op.ID
+		}
+		if err := m.writeRecord(op); err != nil {
+			log.Printf("Update operation record %s: %v", path, err)
+		}
+	}
+
+	return m, interrupted, nil
+}
+
+func readRecord(path string) (*Operation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	if r.ID == "" {
+		return nil, fmt.Errorf("missing id")
+	}
+
+	op := &Operation{
+		ID:          r.ID,
+		Type:        r.Type,
+		State:       r.State,
+		CreatedAt:   r.CreatedAt,
+		CompletedAt: r.CompletedAt,
+		Progress:    r.Progress,
+		Message:     r.Message,
+		Metadata:    r.Metadata,
+		cancelFunc:  func() {},
+
+		IdempotencyKey: r.IdempotencyKey,
+		RequestID:      r.RequestID,
+		fingerprint:    r.Fingerprint,
+	}
+	if r.Error != "" {
+		op.Error = errors.New(r.Error)
+		if r.Error == ErrInterrupted.Error() {
+			op.Error = ErrInterrupted
+		}
+	}
+	return op, nil
+}
+
+// writeRecord writes op's record when the manager is persistent. Callers
+// hold mu.
+func (m *Manager) writeRecord(op *Operation) error {
+	if m.dir == "" {
+		return nil
+	}
+
+	r := record{
+		ID:          op.ID,
+		Type:        op.Type,
+		State:       op.State,
+		CreatedAt:   op.CreatedAt,
+		CompletedAt: op.CompletedAt,
+		Progress:    op.Progress,
+		Message:     op.Message,
+		Metadata:    op.Metadata,
+
+		IdempotencyKey: op.IdempotencyKey,
+		Fingerprint:    op.fingerprint,
+		RequestID:      op.RequestID,
+	}
+	if op.Error != nil {
+		r.Error = op.Error.Error()
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so a crash never leaves a torn record
+	path := filepath.Join(m.dir, op.ID+".json")
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// persist logs instead of failing: losing a record must not fail the
+// operation it describes. Callers hold mu.
+func (m *Manager) persist(op *Operation) {
+	if err := m.writeRecord(op); err != nil {
+		log.Printf("Persist operation %s: %v", op.ID, err)
+	}
+}
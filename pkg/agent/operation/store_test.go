@@ -0,0 +1,156 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/requestid"
+)
+
+func TestPersistentManagerMarksInterrupted(t *testing.T) {
+	dir := t.TempDir()
+
+	m, interrupted, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interrupted) != 0 {
+		t.Fatalf("fresh store reported %d interrupted operations", len(interrupted))
+	}
+
+	running := m.CreateOperation(requestid.With(context.Background(), "req-1"), OperationTypeStackApply, map[string]string{"stack": "web"})
+	m.SetState(running, OperationStateRunning)
+	m.EmitEvent(running, "Creating/updating services...")
+
+	done := m.CreateOperation(context.Background(), OperationTypeStackRemove, map[string]string{"stack": "db"})
+	m.SetCompleted(done)
+
+	// Simulate a restart
+	m, interrupted, err = NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(interrupted) != 1 || interrupted[0].ID != running {
+		t.Fatalf("interrupted = %v, want only %s", interrupted, running)
+	}
+	op := interrupted[0]
+	if op.State != OperationStateFailed || !errors.Is(op.Error, ErrInterrupted) {
+		t.Errorf("interrupted op state = %v, error = %v", op.State, op.Error)
+	}
+	if op.Message != "Creating/updating services..." || op.Metadata["stack"] != "web" || op.RequestID != "req-1" {
+		t.Errorf("interrupted op lost its record: %+v", op)
+	}
+
+	got, err := m.GetOperation(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != OperationStateCompleted {
+		t.Errorf("completed op state = %v", got.State)
+	}
+
+	// The failure is recorded, so a second restart reports nothing new
+	if _, interrupted, err = NewPersistentManager(dir); err != nil || len(interrupted) != 0 {
+		t.Errorf("second restart: interrupted = %v, err = %v", interrupted, err)
+	}
+}
+
+func TestReopenAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	m, _, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reboot := m.CreateOperation(context.Background(), OperationTypeHostReboot, nil)
+	m.SetState(reboot, OperationStateRunning)
+	m.SetMetadata(reboot, "stacks", "web,db")
+	if err := m.Reopen(reboot); err == nil {
+		t.Error("Reopen of a running operation: expected an error")
+	}
+
+	m, interrupted, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interrupted) != 1 || interrupted[0].Metadata["stacks"] != "web,db" {
+		t.Fatalf("interrupted = %v, want the reboot with its stacks", interrupted)
+	}
+	if err := m.Reopen(reboot); err != nil {
+		t.Fatal(err)
+	}
+	m.SetCompleted(reboot)
+
+	if _, interrupted, err = NewPersistentManager(dir); err != nil || len(interrupted) != 0 {
+		t.Fatalf("after completing: interrupted = %v, err = %v", interrupted, err)
+	}
+	got, _ := m.GetOperation(reboot)
+	if got.State != OperationStateCompleted || got.Error != nil {
+		t.Errorf("reopened op state = %v, error = %v", got.State, got.Error)
+	}
+}
+
+func TestPersistentManagerPrunesOldRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	m, _, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := m.CreateOperation(context.Background(), OperationTypeStackApply, nil)
+	op, _ := m.GetOperation(id)
+	old := time.Now().Add(-recordRetention - time.Hour)
+	op.State = OperationStateCompleted
+	op.CompletedAt = &old
+	if err := m.writeRecord(op); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600)
+
+	m, _, err = NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetOperation(id); err == nil {
+		t.Error("expired operation was loaded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); !os.IsNotExist(err) {
+		t.Errorf("expired record not removed: %v", err)
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	dir := t.TempDir()
+	m, _, err := NewPersistentManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opID := m.CreateOperation(context.Background(), OperationTypeStackApply, map[string]string{"stack": "web"})
+	events := m.Subscribe(opID)
+	defer m.Unsubscribe(opID, events)
+	<-events // The current state
+
+	m.EmitEvent(opID, "Starting with DB_PASSWORD=hunter2")
+	if event := <-events; event.Message != "Starting with DB_PASSWORD=******" {
+		t.Errorf("event message = %q", event.Message)
+	}
+	m.SetError(opID, errors.New("compose up: output: API_TOKEN=abc is invalid"))
+	if event := <-events; event.Error.Error() != "compose up: output: API_TOKEN=****** is invalid" {
+		t.Errorf("event error = %v", event.Error)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, opID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Contains(s, "hunter2") || strings.Contains(s, "abc") {
+		t.Errorf("record keeps a secret: %s", s)
+	}
+}
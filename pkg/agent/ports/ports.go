@@ -0,0 +1,269 @@
+// Package ports inventories the sockets listening on the host from procfs,
+// along with the processes and containers owning them and the connections
+// established to them.
+package ports
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TCP states in /proc/net/tcp; UDP sockets bound without a peer show as 07
+const (
+	stateEstablished = "01"
+	stateListen      = "0A"
+	stateUnconnected = "07"
+)
+
+// Listener is a socket accepting connections or datagrams
+type Listener struct {
+	Proto       string // tcp, tcp6, udp or udp6
+	Address     string
+	Port        int
+	PID         int // 0 when no process holding it is visible
+	Process     string
+	ContainerID string // Short ID, when the process runs in a container
+	Container   string // Name, once attributed
+	Connections int    // Established TCP connections to the port
+}
+
+// Container is a running container, for naming the owners of ports
+type Container struct {
+	ID        string // Short ID
+	Name      string
+	Published []Published
+}
+
+// Published is a container port published on the host
+type Published struct {
+	Proto   string // tcp or udp
+	Address string
+	Port    int
+}
+
+// containerID finds a container ID in a cgroup path, as docker and
+// containerd name them
+var containerID = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// List reads the listening sockets from the procfs mounted at proc. port
+// limits the list to one port when not zero.
+func List(proc string, port int) ([]Listener, error) {
+	var listeners []Listener
+	var inodes []uint64              // Of each listener
+	established := make(map[int]int) // TCP port to connections
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		sockets, err := readSockets(filepath.Join(proc, "net", proto))
+		if os.IsNotExist(err) {
+			continue // No IPv6 on the host
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range sockets {
+			tcp := strings.HasPrefix(proto, "tcp")
+			switch {
+			case tcp && s.state == stateEstablished:
+				established[s.port]++
+				continue
+			case tcp && s.state != stateListen:
+				continue
+			case !tcp && (s.state != stateUnconnected || s.remotePort != 0):
+				continue
+			}
+			if port != 0 && s.port != port {
+				continue
+			}
+			listeners = append(listeners, Listener{Proto: proto, Address: s.address, Port: s.port})
+			inodes = append(inodes, s.inode)
+		}
+	}
+
+	owners, err := socketOwners(proc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range listeners {
+		l := &listeners[i]
+		l.PID = owners[inodes[i]]
+		if strings.HasPrefix(l.Proto, "tcp") {
+			l.Connections = established[l.Port]
+		}
+		if l.PID == 0 {
+			continue
+		}
+		pidDir := filepath.Join(proc, strconv.Itoa(l.PID))
+		if comm, err := os.ReadFile(filepath.Join(pidDir, "comm")); err == nil {
+			l.Process = strings.TrimSpace(string(comm))
+		}
+		if cgroup, err := os.ReadFile(filepath.Join(pidDir, "cgroup")); err == nil {
+			if id := containerID.Find(cgroup); id != nil {
+				l.ContainerID = string(id[:12])
+			}
+		}
+	}
+
+	sortListeners(listeners)
+	return listeners, nil
+}
+
+// Attribute names the containers owning listeners, either running the
+// process or publishing the port through docker-proxy. Published ports no
+// socket listens on, as with the userland proxy turned off, are added.
+func Attribute(listeners []Listener, containers []Container, port int) []Listener {
+	names := make(map[string]string, len(containers))
+	published := make(map[string]string) // "<proto>/<port>" to container name
+	for _, c := range containers {
+		names[c.ID] = c.Name
+		for _, p := range c.Published {
+			published[p.Proto+"/"+strconv.Itoa(p.Port)] = c.Name
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range listeners {
+		l := &listeners[i]
+		key := strings.TrimSuffix(l.Proto, "6") + "/" + strconv.Itoa(l.Port)
+		seen[key] = true
+		if name, ok := names[l.ContainerID]; ok {
+			l.Container = name
+		} else if name, ok := published[key]; ok {
+			l.Container = name
+		}
+	}
+
+	for _, c := range containers {
+		for _, p := range c.Published {
+			key := p.Proto + "/" + strconv.Itoa(p.Port)
+			if seen[key] || (port != 0 && p.Port != port) {
+				continue
+			}
+			seen[key] = true
+			listeners = append(listeners, Listener{Proto: p.Proto, Address: p.Address, Port: p.Port, ContainerID: c.ID, Container: c.Name})
+		}
+	}
+
+	sortListeners(listeners)
+	return listeners
+}
+
+func sortListeners(listeners []Listener) {
+	sort.Slice(listeners, func(i, j int) bool {
+		a, b := listeners[i], listeners[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Proto != b.Proto {
+			return a.Proto < b.Proto
+		}
+		return a.Address < b.Address
+	})
+}
+
+// socket is one line of /proc/net/{tcp,udp}[6]
+type socket struct {
+	address    string
+	port       int
+	remotePort int
+	state      string
+	inode      uint64
+}
+
+func readSockets(path string) ([]socket, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sockets []socket
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// "0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000 0 0 12345 ..."
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		address, port, err := parseAddress(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		_, remotePort, err := parseAddress(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: inode %q", path, fields[9])
+		}
+		sockets = append(sockets, socket{address: address, port: port, remotePort: remotePort, state: fields[3], inode: inode})
+	}
+	return sockets, scanner.Err()
+}
+
+// parseAddress parses "<hex address>:<hex port>". The address is stored as
+// 32-bit words in host byte order, which is little-endian wherever Mandau
+// runs.
+func parseAddress(s string) (string, int, error) {
+	addr, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("address %q", s)
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("port %q", port)
+	}
+	raw, err := hex.DecodeString(addr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("address %q", addr)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return net.IP(raw).String(), int(p), nil
+}
+
+// socketOwners maps socket inodes to a process holding them. Processes
+// that exit or deny access while being read are skipped.
+func socketOwners(proc string) (map[uint64]int, error) {
+	entries, err := os.ReadDir(proc)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[uint64]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(proc, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, ok := owners[inode]; !ok {
+				owners[inode] = pid
+			}
+		}
+	}
+	return owners, nil
+}
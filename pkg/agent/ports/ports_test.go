@@ -0,0 +1,103 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const tcpHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+// fakeProc builds a procfs with nginx listening on 0.0.0.0:80 with one
+// connection, a container process on 127.0.0.1:8080 and dnsmasq on udp6
+// [::]:53
+func fakeProc(t *testing.T) string {
+	t.Helper()
+	proc := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(proc, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	socket := func(pid, fd, inode string) {
+		dir := filepath.Join(proc, pid, "fd")
+		os.MkdirAll(dir, 0755)
+		if err := os.Symlink("socket:["+inode+"]", filepath.Join(dir, fd)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("net/tcp", tcpHeader+
+		"   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 100 0 0 10 0\n"+
+		"   1: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0 100 0 0 10 0\n"+
+		"   2: 0A00000A:0050 0B00000A:D431 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0 100 0 0 10 0\n")
+	write("net/udp6", tcpHeader+
+		"   0: 00000000000000000000000000000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1004 2 0\n")
+	write("10/comm", "nginx\n")
+	write("10/cgroup", "0::/system.slice/nginx.service\n")
+	socket("10", "6", "1001")
+	write("20/comm", "node\n")
+	write("20/cgroup", "0::/system.slice/docker-3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e.scope\n")
+	socket("20", "3", "1002")
+	write("30/comm", "dnsmasq\n")
+	socket("30", "4", "1004")
+	return proc
+}
+
+func TestList(t *testing.T) {
+	listeners, err := List(fakeProc(t), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Listener{
+		{Proto: "udp6", Address: "::", Port: 53, PID: 30, Process: "dnsmasq"},
+		{Proto: "tcp", Address: "0.0.0.0", Port: 80, PID: 10, Process: "nginx", Connections: 1},
+		{Proto: "tcp", Address: "127.0.0.1", Port: 8080, PID: 20, Process: "node", ContainerID: "3f4e5d6c7b8a"},
+	}
+	if len(listeners) != len(want) {
+		t.Fatalf("List() = %+v, want %+v", listeners, want)
+	}
+	for i := range want {
+		if listeners[i] != want[i] {
+			t.Errorf("listener %d = %+v, want %+v", i, listeners[i], want[i])
+		}
+	}
+
+	only, err := List(fakeProc(t), 8080)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(only) != 1 || only[0].Process != "node" {
+		t.Errorf("List(8080) = %+v, want node alone", only)
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	listeners := []Listener{
+		{Proto: "tcp", Address: "0.0.0.0", Port: 80, Process: "docker-proxy", PID: 5},
+		{Proto: "tcp", Address: "127.0.0.1", Port: 8080, Process: "node", PID: 20, ContainerID: "3f4e5d6c7b8a"},
+	}
+	containers := []Container{
+		{ID: "aaaaaaaaaaaa", Name: "web", Published: []Published{{Proto: "tcp", Port: 80}, {Proto: "tcp", Address: "0.0.0.0", Port: 5432}}},
+		{ID: "3f4e5d6c7b8a", Name: "api"},
+	}
+
+	got := Attribute(listeners, containers, 0)
+	if len(got) != 3 {
+		t.Fatalf("Attribute() = %+v, want the published port added", got)
+	}
+	if got[0].Container != "web" || got[2].Container != "api" {
+		t.Errorf("owners = %q, %q, want web and api", got[0].Container, got[2].Container)
+	}
+	if got[1].Port != 5432 || got[1].Container != "web" || got[1].PID != 0 {
+		t.Errorf("published without a socket = %+v, want web's 5432", got[1])
+	}
+
+	if got := Attribute(nil, containers, 80); len(got) != 1 {
+		t.Errorf("Attribute(port 80) = %+v, want one listener", got)
+	}
+}
@@ -0,0 +1,295 @@
+// Package procs lists the processes of the host from procfs with their CPU
+// and memory use, and names the signals operators may send them.
+package procs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat. It
+// is 100 on every architecture Linux exposes to user space.
+const clockTicks = 100
+
+// Process is one process of the host
+type Process struct {
+	PID         int
+	PPID        int
+	User        string
+	Name        string
+	Command     string  // Full command line; empty for kernel threads
+	State       string  // R, S, D, Z, T...
+	CPU         float64 // Percent of one CPU over the sampling interval
+	Memory      uint64  // Resident bytes
+	MemoryShare float64 // Percent of the host's memory
+	ContainerID string  // Short ID, when it runs in a container
+}
+
+// KernelThread reports whether p is a kernel thread, which has no command
+// line and descends from kthreadd
+func (p Process) KernelThread() bool {
+	return p.Command == "" && (p.PID == 2 || p.PPID == 2)
+}
+
+// containerID finds a container ID in a cgroup path, as docker and
+// containerd name them
+var containerID = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// ContainerID returns the short ID of the container a /proc/<pid>/cgroup
+// places a process in, or ""
+func ContainerID(cgroup []byte) string {
+	if id := containerID.Find(cgroup); id != nil {
+		return string(id[:12])
+	}
+	return ""
+}
+
+// List reads the processes from the procfs mounted at proc. CPU use is
+// measured over interval, as top does, rather than averaged over each
+// process's life.
+func List(proc string, interval time.Duration) ([]Process, error) {
+	before, err := cpuTimes(proc)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(interval)
+
+	entries, err := os.ReadDir(proc)
+	if err != nil {
+		return nil, err
+	}
+	total := memTotal(proc)
+	users := make(map[string]string)
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes exiting while they are read are left out
+		p, ticks, err := readProcess(filepath.Join(proc, entry.Name()), pid)
+		if err != nil {
+			continue
+		}
+		if start, ok := before[pid]; ok && interval > 0 && ticks >= start {
+			p.CPU = float64(ticks-start) / clockTicks / interval.Seconds() * 100
+		}
+		if total > 0 {
+			p.MemoryShare = float64(p.Memory) / float64(total) * 100
+		}
+		p.User = userName(users, p.User)
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+// Sort orders processes by cpu or mem, highest first, or by pid or name
+func Sort(processes []Process, by string) error {
+	var less func(a, b Process) bool
+	switch by {
+	case "", "pid":
+		less = func(a, b Process) bool { return a.PID < b.PID }
+	case "cpu":
+		less = func(a, b Process) bool { return a.CPU > b.CPU }
+	case "mem":
+		less = func(a, b Process) bool { return a.Memory > b.Memory }
+	case "name":
+		less = func(a, b Process) bool { return a.Name < b.Name }
+	default:
+		return fmt.Errorf("unknown sort %q: use cpu, mem, pid or name", by)
+	}
+	sort.SliceStable(processes, func(i, j int) bool { return less(processes[i], processes[j]) })
+	return nil
+}
+
+// signals are the signals operators may send, by name
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
+
+// ParseSignal parses a signal name, with or without SIG, or its number.
+// Empty means TERM.
+func ParseSignal(name string) (syscall.Signal, error) {
+	if name == "" {
+		return syscall.SIGTERM, nil
+	}
+	name = strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	if sig, ok := signals[name]; ok {
+		return sig, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, sig := range signals {
+			if int(sig) == n {
+				return sig, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown signal %q: use HUP, INT, QUIT, KILL, USR1, USR2, TERM, CONT or STOP", name)
+}
+
+// SignalName is the name of sig without SIG, e.g. TERM
+func SignalName(sig syscall.Signal) string {
+	for name, s := range signals {
+		if s == sig {
+			return name
+		}
+	}
+	return strconv.Itoa(int(sig))
+}
+
+// Get reads one process, without CPU use
+func Get(proc string, pid int) (Process, error) {
+	p, _, err := readProcess(filepath.Join(proc, strconv.Itoa(pid)), pid)
+	if err != nil {
+		return Process{}, fmt.Errorf("process %d: %w", pid, err)
+	}
+	p.User = userName(map[string]string{}, p.User)
+	return p, nil
+}
+
+// readProcess reads a process and its CPU time in clock ticks. User holds
+// the UID until it is resolved.
+func readProcess(dir string, pid int) (Process, uint64, error) {
+	p := Process{PID: pid}
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return p, 0, err
+	}
+	ticks, err := parseStat(stat, &p)
+	if err != nil {
+		return p, 0, err
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		p.Command = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	}
+	if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+		p.ContainerID = ContainerID(cgroup)
+	}
+
+	status, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return p, ticks, nil
+	}
+	defer status.Close()
+	scanner := bufio.NewScanner(status)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			p.User = fields[0]
+		case "VmRSS":
+			// "VmRSS:	  123456 kB"
+			if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+				p.Memory = kb * 1024
+			}
+		}
+	}
+	return p, ticks, nil
+}
+
+// parseStat reads the name, state, parent and CPU time from
+// /proc/<pid>/stat. The name is in parentheses and may itself hold spaces
+// and parentheses, so the fields are counted from the last ')'.
+func parseStat(stat []byte, p *Process) (uint64, error) {
+	open := bytes.IndexByte(stat, '(')
+	end := bytes.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return 0, fmt.Errorf("malformed stat")
+	}
+	p.Name = string(stat[open+1 : end])
+
+	// state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt
+	// cmajflt utime stime ...
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed stat")
+	}
+	p.State = fields[0]
+	p.PPID, _ = strconv.Atoi(fields[1])
+	utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("malformed stat")
+	}
+	return utime + stime, nil
+}
+
+// cpuTimes samples the CPU time of every process
+func cpuTimes(proc string) (map[int]uint64, error) {
+	entries, err := os.ReadDir(proc)
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[int]uint64, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join(proc, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		var p Process
+		if ticks, err := parseStat(stat, &p); err == nil {
+			times[pid] = ticks
+		}
+	}
+	return times, nil
+}
+
+// memTotal is the host's memory in bytes, or 0 when unknown
+func memTotal(proc string) uint64 {
+	data, err := os.ReadFile(filepath.Join(proc, "meminfo"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "MemTotal:"); ok {
+			fields := strings.Fields(value)
+			if len(fields) > 0 {
+				kb, _ := strconv.ParseUint(fields[0], 10, 64)
+				return kb * 1024
+			}
+		}
+	}
+	return 0
+}
+
+// userName resolves a UID, caching the names already looked up. UIDs
+// without a name stay numeric.
+func userName(cache map[string]string, uid string) string {
+	if name, ok := cache[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	cache[uid] = name
+	return name
+}
@@ -0,0 +1,127 @@
+package procs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// fakeProc builds a procfs with init, a kthreadd child, nginx and a node
+// process in a container
+func fakeProc(t *testing.T) string {
+	t.Helper()
+	proc := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(proc, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("meminfo", "MemTotal:        1000000 kB\nMemFree:          500000 kB\n")
+	write("1/stat", "1 (systemd) S 0 1 1 0 -1 4194560 100 0 0 0 50 20 0 0 20 0 1 0 1 0 0\n")
+	write("1/cmdline", "/sbin/init\x00splash\x00")
+	write("1/status", "Name:\tsystemd\nUid:\t0\t0\t0\t0\nVmRSS:\t   10000 kB\n")
+	write("3/stat", "3 (rcu_gp) I 2 0 0 0 -1 69238880 0 0 0 0 0 0 0 0 0 -20 1 0 1 0 0\n")
+	write("3/status", "Name:\trcu_gp\nUid:\t0\t0\t0\t0\n")
+	// A name with spaces and parentheses
+	write("10/stat", "10 (nginx: (worker)) R 1 10 10 0 -1 4194560 10 0 0 0 300 100 0 0 20 0 1 0 5 0 0\n")
+	write("10/cmdline", "nginx: worker process\x00")
+	write("10/status", "Name:\tnginx\nUid:\t65534\t65534\t65534\t65534\nVmRSS:\t  250000 kB\n")
+	write("20/stat", "20 (node) S 1 20 20 0 -1 4194560 10 0 0 0 10 5 0 0 20 0 1 0 9 0 0\n")
+	write("20/cmdline", "node\x00server.js\x00")
+	write("20/status", "Name:\tnode\nUid:\t1000\t1000\t1000\t1000\nVmRSS:\t  50000 kB\n")
+	write("20/cgroup", "0::/system.slice/docker-3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e.scope\n")
+	return proc
+}
+
+func TestList(t *testing.T) {
+	processes, err := List(fakeProc(t), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Sort(processes, "pid"); err != nil {
+		t.Fatal(err)
+	}
+	if len(processes) != 4 {
+		t.Fatalf("got %d processes, want 4", len(processes))
+	}
+
+	systemd, kthread, nginx, node := processes[0], processes[1], processes[2], processes[3]
+	if systemd.Name != "systemd" || systemd.Command != "/sbin/init splash" || systemd.State != "S" || systemd.User != "root" {
+		t.Errorf("systemd = %+v", systemd)
+	}
+	if !kthread.KernelThread() || nginx.KernelThread() {
+		t.Errorf("kernel threads: rcu_gp %v, nginx %v", kthread.KernelThread(), nginx.KernelThread())
+	}
+	if nginx.Name != "nginx: (worker)" || nginx.PPID != 1 || nginx.State != "R" {
+		t.Errorf("nginx = %+v", nginx)
+	}
+	if nginx.Memory != 250000*1024 || nginx.MemoryShare != 25 {
+		t.Errorf("nginx memory = %d bytes, %.1f%%", nginx.Memory, nginx.MemoryShare)
+	}
+	if node.ContainerID != "3f4e5d6c7b8a" || node.Command != "node server.js" {
+		t.Errorf("node = %+v", node)
+	}
+}
+
+func TestSort(t *testing.T) {
+	processes := []Process{
+		{PID: 3, Name: "b", CPU: 5, Memory: 100},
+		{PID: 1, Name: "c", CPU: 50, Memory: 10},
+		{PID: 2, Name: "a", CPU: 0.5, Memory: 1000},
+	}
+	tests := []struct {
+		by   string
+		want []int
+	}{
+		{"cpu", []int{1, 3, 2}},
+		{"mem", []int{2, 3, 1}},
+		{"pid", []int{1, 2, 3}},
+		{"name", []int{2, 3, 1}},
+	}
+	for _, tt := range tests {
+		if err := Sort(processes, tt.by); err != nil {
+			t.Fatal(err)
+		}
+		for i, pid := range tt.want {
+			if processes[i].PID != pid {
+				t.Errorf("sort %s: got pid %d at %d, want %d", tt.by, processes[i].PID, i, pid)
+			}
+		}
+	}
+	if err := Sort(processes, "io"); err == nil {
+		t.Error("sort io: expected an error")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		name string
+		want syscall.Signal
+	}{
+		{"", syscall.SIGTERM},
+		{"KILL", syscall.SIGKILL},
+		{"sighup", syscall.SIGHUP},
+		{"usr1", syscall.SIGUSR1},
+		{"9", syscall.SIGKILL},
+	}
+	for _, tt := range tests {
+		got, err := ParseSignal(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSignal(%q) = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+		if name := SignalName(got); tt.name == "usr1" && name != "USR1" {
+			t.Errorf("SignalName(%v) = %q, want USR1", got, name)
+		}
+	}
+	for _, name := range []string{"SEGV", "11", "bogus"} {
+		if _, err := ParseSignal(name); err == nil {
+			t.Errorf("ParseSignal(%q): expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package seal encrypts stack files at rest with AES-256-GCM. Sealed files
+// start with a header naming the format, so files written before encryption
+// was turned on are still read as they are.
+package seal
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// KeySize is the length of a sealing key in bytes
+const KeySize = 32
+
+// header starts every sealed file
+var header = []byte("MANDAU-SEALED-V1\n")
+
+// ErrNoKey is returned when a sealed file is read without a key
+var ErrNoKey = errors.New("file is encrypted and no stack encryption key is configured")
+
+// Sealer encrypts and decrypts file contents. A nil Sealer stores contents
+// as they are and only reads unsealed files.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// New returns a sealer using key, which must be KeySize bytes
+func New(key []byte) (*Sealer, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("stack encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// FromFile returns a sealer using the key in path: KeySize raw bytes, or
+// their hex or base64 encoding
+func FromFile(path string) (*Sealer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read stack encryption key: %w", err)
+	}
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("stack encryption key %s: %w", path, err)
+	}
+	return New(key)
+}
+
+// FromSecrets returns a sealer using the key the secrets plugin stores
+// under name, encoded as for FromFile
+func FromSecrets(ctx context.Context, secrets plugin.SecretsPlugin, name string) (*Sealer, error) {
+	data, err := secrets.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("get stack encryption key %s: %w", name, err)
+	}
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("stack encryption key %s: %w", name, err)
+	}
+	return New(key)
+}
+
+func decodeKey(data []byte) ([]byte, error) {
+	if len(data) == KeySize {
+		return data, nil
+	}
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("want %d bytes, raw, hex or base64", KeySize)
+}
+
+// Seal encrypts plaintext. The nil Sealer returns plaintext.
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	if s == nil {
+		return plaintext, nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+s.aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	// The header is authenticated too, so it cannot be swapped
+	return s.aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Open decrypts data written by Seal. Unsealed data is returned as it is.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if s == nil {
+		return nil, ErrNoKey
+	}
+
+	data = data[len(header):]
+	if len(data) < s.aead.NonceSize() {
+		return nil, errors.New("sealed file is truncated")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, errors.New("decrypt sealed file: wrong key or corrupted file")
+	}
+	return plaintext, nil
+}
+
+// Enabled reports whether s encrypts what it writes
+func (s *Sealer) Enabled() bool {
+	return s != nil
+}
+
+// IsSealed reports whether data was written by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// WriteFile seals data and writes it to path, readable by the owner only.
+// The mode of an existing file is tightened as well.
+func (s *Sealer) WriteFile(path string, data []byte) error {
+	sealed, err := s.Seal(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// ReadFile reads path and opens its contents
+func (s *Sealer) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, nil
+}
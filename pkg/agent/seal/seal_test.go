@@ -0,0 +1,102 @@
+package seal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	s, err := New(testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("services:\n  web:\n    image: nginx\n")
+
+	sealed, err := s.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("nginx")) {
+		t.Fatalf("sealed = %q, want an encrypted file", sealed)
+	}
+	got, err := s.Open(sealed)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open = %q, %v; want the plaintext", got, err)
+	}
+
+	other, _ := New(testKey(2))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("another key opened the file")
+	}
+	var none *Sealer
+	if _, err := none.Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("open without a key: %v, want ErrNoKey", err)
+	}
+}
+
+func TestUnsealedPassThrough(t *testing.T) {
+	s, _ := New(testKey(1))
+	plaintext := []byte("FOO=bar\n")
+	for _, sealer := range []*Sealer{s, nil} {
+		got, err := sealer.Open(plaintext)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("Open of a plain file = %q, %v; want it unchanged", got, err)
+		}
+	}
+
+	var none *Sealer
+	if got, _ := none.Seal(plaintext); !bytes.Equal(got, plaintext) {
+		t.Errorf("nil sealer wrote %q, want the plaintext", got)
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"raw": testKey(3),
+		"hex": []byte(hex.EncodeToString(testKey(3)) + "\n"),
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, data, 0600)
+		if _, err := FromFile(path); err != nil {
+			t.Errorf("%s key: %v", name, err)
+		}
+	}
+
+	short := filepath.Join(dir, "short")
+	os.WriteFile(short, []byte("too short"), 0600)
+	if _, err := FromFile(short); err == nil {
+		t.Error("short key accepted")
+	}
+}
+
+func TestWriteFileTightensMode(t *testing.T) {
+	s, _ := New(testKey(1))
+	path := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteFile(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	got, err := s.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("ReadFile = %q, %v; want new", got, err)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deploy runs recipe and records what it creates in the recipe's manifest.
+// A name or domain already deployed is refused before anything changes.
+// When a step fails the deployment is rolled back; whatever the rollback
+// could not undo stays in a failed manifest for Remove to clean up.
+func (m *ServiceManager) Deploy(ctx context.Context, recipe Recipe, progress func(StepEvent)) error {
+	mf := recipe.Manifest()
+	steps, err := recipe.Steps(m)
+	if err != nil {
+		return err
+	}
+
+	release, err := m.manifests.reserve(mf.Name, mf.Domain, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	mf.State = DeploymentDeploying
+	mf.DeployedAt = time.Now()
+	if err := m.manifests.save(mf); err != nil {
+		return fmt.Errorf("record manifest: %w", err)
+	}
+
+	if err := runSteps(ctx, m.manifests.track(mf, steps), progress); err != nil {
+		// Certificates are kept on rollback by design; they do not make
+		// the deployment worth keeping
+		var left bool
+		for _, r := range mf.Resources {
+			left = left || r.Kind != ResourceCertificate
+		}
+		if !left {
+			m.manifests.delete(mf.Name)
+			return err
+		}
+
+		mf.State = DeploymentFailed
+		m.manifests.save(mf)
+		return err
+	}
+
+	mf.State = DeploymentDeployed
+	if err := m.manifests.save(mf); err != nil {
+		return fmt.Errorf("record manifest: %w", err)
+	}
+	return nil
+}
+
+// Deployments returns the manifests of the recorded deployments
+func (m *ServiceManager) Deployments() ([]*Manifest, error) {
+	return m.manifests.all()
+}
+
+// Remove tears down everything the manifest of name records, newest first.
+// A resource that fails to go is reported and kept in the manifest, and the
+// others are still removed, so Remove can be retried. Files edited since
+// the deployment wrote them are kept unless force is set. Database data
+// and backup directories are left on the host.
+func (m *ServiceManager) Remove(ctx context.Context, name string, force bool, progress func(StepEvent)) error {
+	report := func(e StepEvent) {
+		if progress != nil {
+			progress(e)
+		}
+	}
+
+	release, err := m.manifests.reserve(name, "", false)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	mf, err := m.manifests.get(name)
+	if err != nil {
+		return err
+	}
+
+	resources := append([]Resource(nil), mf.Resources...)
+	var failed []string
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+		step := "remove " + r.Kind + " " + r.Name
+		done := (len(resources) - 1 - i) * 100 / len(resources)
+
+		report(StepEvent{Step: step, State: StepRunning, Progress: done})
+		err := ctx.Err()
+		if err == nil {
+			err = m.teardown(ctx, r, name, force)
+		}
+		if err != nil {
+			report(StepEvent{Step: step, State: StepFailed, Progress: done, Error: err.Error()})
+			failed = append(failed, fmt.Sprintf("%s %s: %v", r.Kind, r.Name, err))
+			continue
+		}
+
+		mf.remove(r)
+		if err := m.manifests.save(mf); err != nil {
+			return fmt.Errorf("record manifest: %w", err)
+		}
+		report(StepEvent{Step: step, State: StepCompleted, Progress: (len(resources) - i) * 100 / len(resources)})
+	}
+
+	if len(failed) > 0 {
+		mf.State = DeploymentFailed
+		m.manifests.save(mf)
+		return fmt.Errorf("remove %s incomplete: %s", name, strings.Join(failed, "; "))
+	}
+	return m.manifests.delete(name)
+}
+
+// teardown removes one resource of the deployment name. Resources already
+// gone count as removed.
+func (m *ServiceManager) teardown(ctx context.Context, r Resource, name string, force bool) error {
+	switch r.Kind {
+	case ResourceUnit:
+		if err := m.requirePlugins("removing a unit", PluginSystemd); err != nil {
+			return err
+		}
+		return m.systemd.RemoveService(ctx, r.Name, force)
+
+	case ResourceVhost:
+		if err := m.requirePlugins("removing a virtual host", PluginNginx); err != nil {
+			return err
+		}
+		if err := m.nginx.DeleteVirtualHost(ctx, r.Name, force); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+
+	case ResourcePort:
+		return m.releasePort(ctx, r, name)
+
+	case ResourceCertificate:
+		if err := m.requirePlugins("removing a certificate", PluginACME); err != nil {
+			return err
+		}
+		return m.acme.DeleteCertificate(ctx, r.Name)
+
+	case ResourceCron:
+		if err := m.requirePlugins("removing a cron job", PluginCron); err != nil {
+			return err
+		}
+		return m.cron.RemoveCronJob(ctx, r.Name, force)
+	}
+	return fmt.Errorf("unknown resource kind %q", r.Kind)
+}
+
+// releasePort closes a port the deployment name opened, unless another
+// deployment still needs it. In that case the other deployment takes over
+// closing it once it is removed in turn.
+func (m *ServiceManager) releasePort(ctx context.Context, r Resource, name string) error {
+	if !r.Owned {
+		return nil
+	}
+
+	manifests, err := m.manifests.all()
+	if err != nil {
+		return err
+	}
+	for _, other := range manifests {
+		if other.Name == name || !other.uses(ResourcePort, r.Name) {
+			continue
+		}
+		for i := range other.Resources {
+			if other.Resources[i].Kind == ResourcePort && other.Resources[i].Name == r.Name {
+				other.Resources[i].Owned = true
+			}
+		}
+		return m.manifests.save(other)
+	}
+
+	if err := m.requirePlugins("closing a port", PluginFirewall); err != nil {
+		return err
+	}
+	port, proto, _ := strings.Cut(r.Name, "/")
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", r.Name)
+	}
+	return m.firewall.RevokePort(ctx, n, proto)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testRecipe deploys fixed steps
+type testRecipe struct {
+	manifest Manifest
+	steps    []Step
+}
+
+func (r *testRecipe) Manifest() *Manifest                     { mf := r.manifest; return &mf }
+func (r *testRecipe) Steps(m *ServiceManager) ([]Step, error) { return r.steps, nil }
+
+func testManager(t *testing.T) *ServiceManager {
+	t.Helper()
+	store, err := newManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ServiceManager{manifests: store}
+}
+
+func portStep(name string, owned bool) Step {
+	return Step{
+		Name:    "open " + name,
+		Run:     func(ctx context.Context) error { return nil },
+		Creates: func() *Resource { return &Resource{Kind: ResourcePort, Name: name, Owned: owned} },
+	}
+}
+
+func TestDeployRecordsManifest(t *testing.T) {
+	m := testManager(t)
+
+	err := m.Deploy(context.Background(), &testRecipe{
+		manifest: Manifest{Name: "api", Kind: "web service", Domain: "api.example.com"},
+		steps:    []Step{portStep("80/tcp", true), {Name: "check", Run: func(ctx context.Context) error { return nil }}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := m.Deployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 1 || manifests[0].State != DeploymentDeployed || len(manifests[0].Resources) != 1 {
+		t.Fatalf("manifests = %+v, want api deployed with one port", manifests)
+	}
+
+	// Neither the name nor the domain can be deployed twice
+	for _, mf := range []Manifest{{Name: "api"}, {Name: "api2", Domain: "api.example.com"}} {
+		err := m.Deploy(context.Background(), &testRecipe{manifest: mf}, nil)
+		if !errors.Is(err, errDeploymentExists) {
+			t.Errorf("Deploy(%s) error = %v, want already deployed", mf.Name, err)
+		}
+	}
+}
+
+func TestDeployKeepsWhatRollbackLeft(t *testing.T) {
+	m := testManager(t)
+	fail := Step{Name: "fail", Run: func(ctx context.Context) error { return errors.New("boom") }}
+
+	// A complete rollback forgets the deployment
+	undone := portStep("80/tcp", true)
+	undone.Undo = func(ctx context.Context) error { return nil }
+	if err := m.Deploy(context.Background(), &testRecipe{
+		manifest: Manifest{Name: "clean"},
+		steps:    []Step{undone, fail},
+	}, nil); err == nil {
+		t.Fatal("Deploy() succeeded, want the failed step's error")
+	}
+	if _, err := m.manifests.get("clean"); !errors.Is(err, errNoDeployment) {
+		t.Errorf("manifest of a rolled back deployment: %v, want none", err)
+	}
+
+	// A failed undo leaves the resource recorded for removal
+	stuck := portStep("443/tcp", true)
+	stuck.Undo = func(ctx context.Context) error { return errors.New("busy") }
+	m.Deploy(context.Background(), &testRecipe{
+		manifest: Manifest{Name: "stuck"},
+		steps:    []Step{stuck, fail},
+	}, nil)
+	mf, err := m.manifests.get("stuck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mf.State != DeploymentFailed || !mf.uses(ResourcePort, "443/tcp") {
+		t.Errorf("manifest = %+v, want failed with the port left", mf)
+	}
+}
+
+func TestRemoveHandsSharedPortToOtherDeployment(t *testing.T) {
+	m := testManager(t)
+	for _, d := range []struct {
+		name  string
+		owned bool
+	}{{"first", true}, {"second", false}} {
+		if err := m.Deploy(context.Background(), &testRecipe{
+			manifest: Manifest{Name: d.name},
+			steps:    []Step{portStep("80/tcp", d.owned)},
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// No firewall plugin is enabled: closing the port would fail
+	if err := m.Remove(context.Background(), "first", false, nil); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := m.manifests.get("first"); !errors.Is(err, errNoDeployment) {
+		t.Errorf("first still recorded: %v", err)
+	}
+
+	second, err := m.manifests.get("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Resources[0].Owned {
+		t.Error("second deployment did not take over closing the port")
+	}
+
+	if err := m.Remove(context.Background(), "second", false, nil); err == nil {
+		t.Error("Remove(second) succeeded without a firewall, want the port kept recorded")
+	}
+	if err := m.Remove(context.Background(), "missing", false, nil); !errors.Is(err, errNoDeployment) {
+		t.Errorf("Remove(missing) error = %v, want no such deployment", err)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drift states
+const (
+	DriftModified = "modified" // Edited since Mandau wrote it
+	DriftMissing  = "missing"  // Removed, or a rule no longer in the firewall
+)
+
+// DriftZone is the kind of drifted DNS zone files. The other kinds are the
+// resource kinds of what drifted.
+const DriftZone = "zone"
+
+// DefaultDriftInterval is how often the agent scans for drift unless
+// configured otherwise
+const DefaultDriftInterval = time.Hour
+
+// Drift is a host artifact Mandau manages that no longer matches what it
+// rendered
+type Drift struct {
+	Kind   string // vhost, unit, cron, zone or port
+	Name   string // File path, or "<port>/<proto>" for firewall rules
+	State  string
+	Detail string // For ports, the deployments that opened them
+}
+
+// driftKinds are the kinds of the files each plugin writes
+var driftKinds = map[string]string{
+	PluginNginx:   ResourceVhost,
+	PluginSystemd: ResourceUnit,
+	PluginCron:    ResourceCron,
+	PluginDNS:     DriftZone,
+}
+
+// tracksDrift reports whether a plugin whose changes can drift is enabled
+func (m *ServiceManager) tracksDrift() bool {
+	return m.nginx != nil || m.systemd != nil || m.firewall != nil || m.cron != nil || m.dns != nil
+}
+
+// ScanDrift compares the files the plugins wrote with the checksums
+// recorded when they were written, and the ports deployments opened with
+// the firewall's rules
+func (m *ServiceManager) ScanDrift(ctx context.Context) ([]Drift, error) {
+	var drift []Drift
+	for _, f := range m.managed.Drift() {
+		d := Drift{Kind: m.fileKind(f.Path), Name: f.Path, State: DriftModified}
+		if f.Missing {
+			d.State = DriftMissing
+		}
+		drift = append(drift, d)
+	}
+
+	if m.firewall == nil || m.manifests == nil {
+		return drift, nil
+	}
+	manifests, err := m.manifests.all()
+	if err != nil {
+		return nil, err
+	}
+	ports := make(map[string][]string) // "<port>/<proto>" to the deployments needing it
+	for _, mf := range manifests {
+		for _, r := range mf.Resources {
+			if r.Kind == ResourcePort {
+				ports[r.Name] = append(ports[r.Name], mf.Name)
+			}
+		}
+	}
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		port, proto, _ := strings.Cut(name, "/")
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			continue
+		}
+		allowed, err := m.firewall.PortAllowed(ctx, n, proto)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			drift = append(drift, Drift{
+				Kind:   ResourcePort,
+				Name:   name,
+				State:  DriftMissing,
+				Detail: "opened by " + strings.Join(ports[name], ", "),
+			})
+		}
+	}
+	return drift, nil
+}
+
+// fileKind names what a managed file is by the plugin allowed to write it
+func (m *ServiceManager) fileKind(path string) string {
+	for name, kind := range driftKinds {
+		if sandbox := m.sandboxes[name]; sandbox != nil && sandbox.CanWrite(path) {
+			return kind
+		}
+	}
+	return "file"
+}
+
+// WatchDrift scans for drift every interval until ctx is done. report is
+// called for each artifact when it starts drifting or its state changes,
+// and with resolved set once it matches again.
+func (m *ServiceManager) WatchDrift(ctx context.Context, interval time.Duration, report func(d Drift, resolved bool)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := make(map[string]Drift)
+	for {
+		drift, err := m.ScanDrift(ctx)
+		if err != nil {
+			log.Printf("drift scan: %v", err)
+		} else {
+			current := make(map[string]Drift, len(drift))
+			for _, d := range drift {
+				key := d.Kind + " " + d.Name
+				current[key] = d
+				if was, ok := known[key]; !ok || was.State != d.State {
+					report(d, false)
+				}
+			}
+			for key, d := range known {
+				if _, ok := current[key]; !ok {
+					report(d, true)
+				}
+			}
+			known = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
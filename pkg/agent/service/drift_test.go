@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+func driftManager(t *testing.T) (*ServiceManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	managed, err := plugin.NewManagedFiles(filepath.Join(dir, "managed-files.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites := filepath.Join(dir, "sites")
+	os.MkdirAll(sites, 0755)
+	sandbox := plugin.NewSandbox(PluginNginx, plugin.Permissions{Write: []string{sites}})
+	sandbox.SetManaged(managed)
+
+	m := testManager(t)
+	m.managed = managed
+	m.sandboxes = map[string]*plugin.Sandbox{PluginNginx: sandbox}
+	return m, sites
+}
+
+func TestScanDrift(t *testing.T) {
+	m, sites := driftManager(t)
+	edited := filepath.Join(sites, "edited.conf")
+	removed := filepath.Join(sites, "removed.conf")
+	kept := filepath.Join(sites, "kept.conf")
+	for _, name := range []string{edited, removed, kept} {
+		if err := m.sandboxes[PluginNginx].WriteManaged(name, []byte("# Managed by Mandau\n"), 0644, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(edited, []byte("# Managed by Mandau\nhand edit\n"), 0644)
+	os.Remove(removed)
+
+	drift, err := m.ScanDrift(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{Kind: ResourceVhost, Name: edited, State: DriftModified},
+		{Kind: ResourceVhost, Name: removed, State: DriftMissing},
+	}
+	if len(drift) != len(want) {
+		t.Fatalf("ScanDrift() = %+v, want %+v", drift, want)
+	}
+	for i := range want {
+		if drift[i] != want[i] {
+			t.Errorf("drift[%d] = %+v, want %+v", i, drift[i], want[i])
+		}
+	}
+}
+
+func TestWatchDriftReportsChanges(t *testing.T) {
+	m, sites := driftManager(t)
+	site := filepath.Join(sites, "site.conf")
+	if err := m.sandboxes[PluginNginx].WriteManaged(site, []byte("# Managed by Mandau\n"), 0644, false); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(site, []byte("hand edit\n"), 0644)
+
+	type event struct {
+		state    string
+		resolved bool
+	}
+	events := make(chan event, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.WatchDrift(ctx, 10*time.Millisecond, func(d Drift, resolved bool) {
+			events <- event{d.State, resolved}
+		})
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	next := func() event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no drift reported")
+		}
+		return event{}
+	}
+
+	if e := next(); e != (event{DriftModified, false}) {
+		t.Errorf("first report = %+v, want modified", e)
+	}
+	os.Remove(site)
+	if e := next(); e != (event{DriftMissing, false}) {
+		t.Errorf("after removal = %+v, want missing", e)
+	}
+	m.sandboxes[PluginNginx].WriteManaged(site, []byte("# Managed by Mandau\n"), 0644, true)
+	if e := next(); e != (event{DriftMissing, true}) {
+		t.Errorf("after rewrite = %+v, want resolved", e)
+	}
+}
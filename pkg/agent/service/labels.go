@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/agent/mac"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// How deployments handle the SELinux or AppArmor policy of the host
+const (
+	LabelsAdvise = "advise" // Add the changes the policy needs to the errors of steps it may deny
+	LabelsApply  = "apply"  // Make the changes before the steps, undoing them on rollback
+	LabelsOff    = "off"
+)
+
+// labelerName is the sandbox the label changes are made and audited under
+const labelerName = "security-labels"
+
+// newLabeler returns the sandbox label changes run in
+func newLabeler(auditor *plugin.HostAuditor) *plugin.Sandbox {
+	labeler := plugin.NewSandbox(labelerName, plugin.Permissions{
+		Exec: []string{"semanage", "restorecon", "setsebool"},
+	})
+	labeler.SetAuditor(auditor)
+	return labeler
+}
+
+// SetSecurityLabels sets how deployments handle the SELinux or AppArmor
+// policy of the host: advise (the default), apply or off
+func (m *ServiceManager) SetSecurityLabels(mode string) error {
+	switch mode {
+	case "":
+		mode = LabelsAdvise
+	case LabelsAdvise, LabelsApply, LabelsOff:
+	default:
+		return fmt.Errorf("unknown security labels mode %q: use advise, apply or off", mode)
+	}
+	m.labels = mode
+	return nil
+}
+
+// Confinement returns the mandatory access control the host enforces
+func (m *ServiceManager) Confinement() mac.Status {
+	return m.mac
+}
+
+// confineSteps prepares steps for the policy of the host. When labels are
+// applied, each fix with commands runs as a step before them. Any other
+// fix is added to the errors of the steps, since the policy may be why
+// they failed. Labels stay when the deployment is removed; like the
+// certificate, they are harmless.
+func (m *ServiceManager) confineSteps(fixes []mac.Fix, steps []Step) []Step {
+	if m.labels == LabelsOff || len(fixes) == 0 {
+		return steps
+	}
+
+	var confined []Step
+	var advice []string
+	for _, fix := range fixes {
+		if m.labels == LabelsApply && len(fix.Apply) > 0 {
+			confined = append(confined, m.fixStep(fix))
+		} else {
+			advice = append(advice, fix.Guidance())
+		}
+	}
+	for _, step := range steps {
+		if len(advice) > 0 {
+			step.Run = advise(step.Run, advice)
+		}
+		confined = append(confined, step)
+	}
+	return confined
+}
+
+// advise adds the policy changes to the error of run
+func advise(run func(ctx context.Context) error, advice []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		err := run(ctx)
+		if err == nil {
+			return nil
+		}
+		return fmt.Errorf("%w; if the policy denied it: %s", err, strings.Join(advice, "; "))
+	}
+}
+
+// fixStep makes the changes of fix. A file context or port the policy
+// already defines is left as it is and not removed on rollback.
+func (m *ServiceManager) fixStep(fix mac.Fix) Step {
+	var defined bool
+	step := Step{
+		Name: fix.Module + ": " + fix.Reason,
+		Run: func(ctx context.Context) error {
+			for _, cmd := range fix.Apply {
+				output, err := m.labeler.CommandContext(ctx, cmd[0], cmd[1:]...).CombinedOutput()
+				if err == nil {
+					continue
+				}
+				if strings.Contains(string(output), "already defined") {
+					defined = true
+					continue
+				}
+				if len(output) == 0 {
+					return fmt.Errorf("%s: %w", cmd[0], err)
+				}
+				return fmt.Errorf("%s: %s", cmd[0], strings.TrimSpace(string(output)))
+			}
+			return nil
+		},
+	}
+	if len(fix.Undo) > 0 {
+		step.Undo = func(ctx context.Context) error {
+			if defined {
+				return nil
+			}
+			for _, cmd := range fix.Undo {
+				if output, err := m.labeler.CommandContext(ctx, cmd[0], cmd[1:]...).CombinedOutput(); err != nil {
+					return fmt.Errorf("%s: %s", cmd[0], strings.TrimSpace(string(output)))
+				}
+			}
+			return nil
+		}
+	}
+	return step
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/agent/mac"
+)
+
+func TestConfineSteps(t *testing.T) {
+	m := testManager(t)
+	m.mac = mac.Status{SELinux: true, NginxConfined: true}
+	m.labeler = newLabeler(nil)
+	fixes := m.mac.WebRoot("/srv/site")
+	fail := Step{Name: "create nginx config", Run: func(ctx context.Context) error { return errors.New("permission denied") }}
+
+	m.SetSecurityLabels(LabelsOff)
+	if steps := m.confineSteps(fixes, []Step{fail}); len(steps) != 1 || steps[0].Run(context.Background()).Error() != "permission denied" {
+		t.Error("off: steps changed")
+	}
+
+	m.SetSecurityLabels("")
+	steps := m.confineSteps(fixes, []Step{fail})
+	if len(steps) != 1 {
+		t.Fatalf("advise: %d steps, want the step alone", len(steps))
+	}
+	err := steps[0].Run(context.Background())
+	if !strings.Contains(err.Error(), "semanage fcontext") || !strings.Contains(err.Error(), "apparmor_parser") {
+		t.Errorf("advise: error = %v, want both remediations", err)
+	}
+
+	// Applying runs the SELinux fix as its own step; AppArmor stays advice
+	m.SetSecurityLabels(LabelsApply)
+	steps = m.confineSteps(fixes, []Step{fail})
+	if len(steps) != 2 || steps[0].Name != "SELinux: let nginx read /srv/site" || steps[0].Undo == nil {
+		t.Fatalf("apply: steps = %+v, want the label step first", steps)
+	}
+	if err := steps[1].Run(context.Background()); strings.Contains(err.Error(), "semanage") || !strings.Contains(err.Error(), "apparmor_parser") {
+		t.Errorf("apply: error = %v, want AppArmor remediation only", err)
+	}
+
+	if err := m.SetSecurityLabels("enforce"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/mac"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/config"
 	"github.com/bhangun/mandau/pkg/plugin"
 
 	"github.com/bhangun/mandau/plugins/host/cron"
@@ -24,129 +28,176 @@ type ServiceManager struct {
 	cron        *cron.CronPlugin
 	acme        *acme.ACMEPlugin
 	dns         *dns.DNSPlugin
+
+	enabled   map[string]plugin.Plugin
+	sandboxes map[string]*plugin.Sandbox // Each plugin is confined to what it declares
+	managed   *plugin.ManagedFiles       // What the plugins wrote, for drift scans
+
+	mac     mac.Status      // SELinux or AppArmor enforcement, detected once
+	labels  string          // How deployments handle it
+	labeler *plugin.Sandbox // Runs the label changes
+
+	manifests *manifestStore
+
+	availability []*v1.PluginAvailability // Of the enabled plugins, probed at startup
 }
 
-func NewServiceManager(ctx context.Context) (*ServiceManager, error) {
+// Plugin names enabling each host service in the agent config
+const (
+	PluginNginx       = "nginx-manager"
+	PluginSystemd     = "systemd-manager"
+	PluginFirewall    = "firewall-manager"
+	PluginEnvironment = "host-environment"
+	PluginCron        = "cron-manager"
+	PluginACME        = "acme-manager"
+	PluginDNS         = "dns-manager"
+)
+
+// IsServicePlugin reports whether name is one of the host service plugins
+// managed here rather than by the plugin registry
+func IsServicePlugin(name string) bool {
+	switch name {
+	case PluginNginx, PluginSystemd, PluginFirewall, PluginEnvironment, PluginCron, PluginACME, PluginDNS:
+		return true
+	}
+	return false
+}
+
+// DefaultManifestDir holds deployment manifests unless configured otherwise
+const DefaultManifestDir = "/var/lib/mandau/deployments"
+
+// NewServiceManager initializes the host service plugins enabled in cfg.
+// Disabled plugins, and enabled ones this host cannot run, stay nil and
+// their services are not served. Deployment
+// manifests are kept under manifestDir once systemd is enabled. The host
+// changes the plugins make are recorded with auditor, and the files they
+// generate guarded with managed.
+func NewServiceManager(ctx context.Context, cfg config.PluginConfig, manifestDir string, auditor *plugin.HostAuditor, managed *plugin.ManagedFiles) (*ServiceManager, error) {
 	mgr := &ServiceManager{
-		nginx:       nginx.New(),
-		systemd:     systemd.New(),
-		firewall:    firewall.New(),
-		environment: environment.New(),
-		cron:        cron.New(),
-		acme:        acme.New(),
-		dns:         dns.New(),
+		enabled:   make(map[string]plugin.Plugin),
+		sandboxes: make(map[string]*plugin.Sandbox),
+		managed:   managed,
+		mac:       mac.Detect(),
+		labels:    LabelsAdvise,
+		labeler:   newLabeler(auditor),
 	}
 
-	// Initialize all plugins
 	plugins := []struct {
-		name   string
-		plugin plugin.Plugin
-		config map[string]interface{}
+		name     string
+		plugin   plugin.Plugin
+		defaults map[string]interface{}
 	}{
-		{"nginx", mgr.nginx, map[string]interface{}{}},
-		{"systemd", mgr.systemd, map[string]interface{}{}},
-		{"firewall", mgr.firewall, map[string]interface{}{"backend": "ufw"}},
-		{"environment", mgr.environment, map[string]interface{}{}},
-		{"cron", mgr.cron, map[string]interface{}{}},
-		{"acme", mgr.acme, map[string]interface{}{"production": false}},
-		{"dns", mgr.dns, map[string]interface{}{}},
+		{PluginNginx, nginx.New(), nil},
+		{PluginSystemd, systemd.New(), nil},
+		{PluginFirewall, firewall.New(), nil},
+		{PluginEnvironment, environment.New(), nil},
+		{PluginCron, cron.New(), nil},
+		{PluginACME, acme.New(), map[string]interface{}{"production": false}},
+		{PluginDNS, dns.New(), nil},
 	}
 
 	for _, p := range plugins {
-		if err := p.plugin.Init(ctx, p.config); err != nil {
+		if !cfg.Enabled[p.name] {
+			continue
+		}
+
+		settings := make(map[string]interface{})
+		for k, v := range p.defaults {
+			settings[k] = v
+		}
+		for k, v := range cfg.Configs[p.name] {
+			settings[k] = v
+		}
+		availability := &v1.PluginAvailability{Plugin: p.name, Capability: pluginCapabilities[p.name], Available: true}
+		mgr.availability = append(mgr.availability, availability)
+		if reason := hostPlatform.unavailable(p.name, settings); reason != "" {
+			availability.Available, availability.Reason = false, reason
+			continue
+		}
+		if err := p.plugin.Init(ctx, settings); err != nil {
+			mgr.Shutdown(ctx)
 			return nil, fmt.Errorf("init %s: %w", p.name, err)
 		}
+		mgr.enabled[p.name] = p.plugin
+		if sandbox := plugin.Confine(p.plugin, nil, auditor); sandbox != nil {
+			sandbox.SetManaged(managed)
+			mgr.sandboxes[p.name] = sandbox
+		}
+
+		switch plug := p.plugin.(type) {
+		case *nginx.NginxPlugin:
+			mgr.nginx = plug
+		case *systemd.SystemdPlugin:
+			mgr.systemd = plug
+		case *firewall.FirewallPlugin:
+			mgr.firewall = plug
+		case *environment.EnvironmentPlugin:
+			mgr.environment = plug
+		case *cron.CronPlugin:
+			mgr.cron = plug
+		case *acme.ACMEPlugin:
+			mgr.acme = plug
+		case *dns.DNSPlugin:
+			mgr.dns = plug
+		}
+	}
+
+	// Deployments are served along with systemd
+	if mgr.systemd != nil {
+		manifests, err := newManifestStore(manifestDir)
+		if err != nil {
+			mgr.Shutdown(ctx)
+			return nil, err
+		}
+		mgr.manifests = manifests
 	}
 
 	return mgr, nil
 }
 
-// DeployWebService deploys a complete web service with nginx, systemd, firewall, and SSL
-func (m *ServiceManager) DeployWebService(ctx context.Context, config *WebServiceConfig) error {
-	// 1. Create systemd service
-	service := &systemd.ServiceUnit{
-		Name:        config.Name,
-		Description: config.Description,
-		User:        config.User,
-		WorkingDir:  config.WorkingDir,
-		ExecStart:   config.Command,
-		Restart:     "always",
-		RestartSec:  10,
-		Environment: config.Environment,
+// Plugin returns an enabled host service plugin and the permissions it is
+// confined to
+func (m *ServiceManager) Plugin(name string) (plugin.Plugin, plugin.Permissions, bool) {
+	p, ok := m.enabled[name]
+	if !ok {
+		return nil, plugin.Permissions{}, false
 	}
+	return p, m.sandboxes[name].Permissions(), true
+}
 
-	if err := m.systemd.CreateService(service); err != nil {
-		return fmt.Errorf("create service: %w", err)
+// Capabilities lists the host services the enabled plugins let the agent
+// serve. Every deployment recipe needs systemd; each checks the other
+// plugins it uses when it runs.
+func (m *ServiceManager) Capabilities() []string {
+	var caps []string
+	if m.nginx != nil {
+		caps = append(caps, capability.Nginx)
 	}
-
-	if err := m.systemd.EnableService(config.Name); err != nil {
-		return fmt.Errorf("enable service: %w", err)
+	if m.systemd != nil {
+		caps = append(caps, capability.Systemd)
 	}
-
-	if err := m.systemd.StartService(config.Name); err != nil {
-		return fmt.Errorf("start service: %w", err)
+	if m.firewall != nil {
+		caps = append(caps, capability.Firewall)
 	}
-
-	// 2. Configure nginx reverse proxy
-	if err := m.nginx.CreateReverseProxy(
-		config.Domain,
-		fmt.Sprintf("http://127.0.0.1:%d", config.Port),
-		80,
-	); err != nil {
-		return fmt.Errorf("create nginx config: %w", err)
+	if m.acme != nil {
+		caps = append(caps, capability.ACME)
 	}
-
-	if err := m.nginx.EnableVirtualHost(config.Domain); err != nil {
-		return fmt.Errorf("enable nginx vhost: %w", err)
+	if m.environment != nil {
+		caps = append(caps, capability.Host)
 	}
-
-	// 3. Open firewall ports
-	if err := m.firewall.AllowPort(80, "tcp"); err != nil {
-		return fmt.Errorf("open firewall port 80: %w", err)
+	if m.cron != nil {
+		caps = append(caps, capability.Cron)
 	}
-
-	if err := m.firewall.AllowPort(443, "tcp"); err != nil {
-		return fmt.Errorf("open firewall port 443: %w", err)
+	if m.dns != nil {
+		caps = append(caps, capability.DNS)
 	}
-
-	// 4. Obtain SSL certificate
-	if config.SSL {
-		cert, err := m.acme.ObtainCertificate(config.Domain)
-		if err != nil {
-			return fmt.Errorf("obtain certificate: %w", err)
-		}
-
-		// Update nginx config with SSL
-		vhost := &nginx.VirtualHost{
-			ServerName: config.Domain,
-			Listen:     443,
-			ProxyPass:  fmt.Sprintf("http://127.0.0.1:%d", config.Port),
-			SSL: &nginx.SSLConfig{
-				Certificate:    cert.CertPath,
-				CertificateKey: cert.KeyPath,
-				Protocols:      []string{"TLSv1.2", "TLSv1.3"},
-			},
-		}
-
-		if err := m.nginx.CreateVirtualHost(vhost); err != nil {
-			return fmt.Errorf("create SSL vhost: %w", err)
-		}
+	if m.systemd != nil {
+		caps = append(caps, capability.Deploy)
 	}
-
-	// 5. Add automatic renewal cron job
-	if config.SSL {
-		cronJob := &cron.CronJob{
-			Name:     config.Name + "-cert-renewal",
-			Schedule: "0 0 * * *", // Daily at midnight
-			Command:  "certbot renew && nginx -s reload",
-		}
-
-		if err := m.cron.AddCronJob(cronJob); err != nil {
-			return fmt.Errorf("add cron job: %w", err)
-		}
+	if m.tracksDrift() {
+		caps = append(caps, capability.Drift)
 	}
-
-	return nil
+	return caps
 }
 
 // Nginx returns the nginx plugin
@@ -184,28 +235,29 @@ func (m *ServiceManager) DNS() *dns.DNSPlugin {
 	return m.dns
 }
 
-type WebServiceConfig struct {
-	Name        string
-	Description string
-	Domain      string
-	Port        int
-	Command     string
-	WorkingDir  string
-	User        string
-	SSL         bool
-	Environment map[string]string
-}
-
-// Shutdown gracefully shuts down all service plugins
+// Shutdown gracefully shuts down the enabled service plugins
 func (m *ServiceManager) Shutdown(ctx context.Context) error {
-	plugins := []plugin.Plugin{
-		m.nginx,
-		m.systemd,
-		m.firewall,
-		m.environment,
-		m.cron,
-		m.acme,
-		m.dns,
+	var plugins []plugin.Plugin
+	if m.nginx != nil {
+		plugins = append(plugins, m.nginx)
+	}
+	if m.systemd != nil {
+		plugins = append(plugins, m.systemd)
+	}
+	if m.firewall != nil {
+		plugins = append(plugins, m.firewall)
+	}
+	if m.environment != nil {
+		plugins = append(plugins, m.environment)
+	}
+	if m.cron != nil {
+		plugins = append(plugins, m.cron)
+	}
+	if m.acme != nil {
+		plugins = append(plugins, m.acme)
+	}
+	if m.dns != nil {
+		plugins = append(plugins, m.dns)
 	}
 
 	for _, p := range plugins {
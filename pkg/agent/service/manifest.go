@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resource kinds recorded in deployment manifests
+const (
+	ResourceUnit        = "unit"
+	ResourceVhost       = "vhost"
+	ResourcePort        = "port" // Named "<port>/<proto>"
+	ResourceCertificate = "certificate"
+	ResourceCron        = "cron"
+)
+
+// Deployment states recorded in manifests
+const (
+	DeploymentDeploying = "DEPLOYING"
+	DeploymentDeployed  = "DEPLOYED"
+	DeploymentFailed    = "FAILED" // A rollback or removal left resources behind
+)
+
+var (
+	errDeploymentExists = errors.New("already deployed")
+	errDeploymentBusy   = errors.New("deployment in progress")
+	errNoDeployment     = errors.New("no such deployment")
+)
+
+// Resource is something a deployment created on the host
+type Resource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Owned marks a port the deployment opened rather than found open.
+	// Only owned ports are closed when no deployment needs them any more.
+	Owned bool `json:"owned,omitempty"`
+}
+
+// Manifest records a deployment and everything it created, so it can be
+// listed and torn down later
+type Manifest struct {
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"` // web service, static site, database or worker
+	Domain     string     `json:"domain,omitempty"`
+	State      string     `json:"state"`
+	DeployedAt time.Time  `json:"deployed_at"`
+	Resources  []Resource `json:"resources"`
+}
+
+func (mf *Manifest) remove(r Resource) {
+	for i, have := range mf.Resources {
+		if have.Kind == r.Kind && have.Name == r.Name {
+			mf.Resources = append(mf.Resources[:i], mf.Resources[i+1:]...)
+			return
+		}
+	}
+}
+
+func (mf *Manifest) uses(kind, name string) bool {
+	for _, r := range mf.Resources {
+		if r.Kind == kind && r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestStore keeps one manifest file per deployment under dir. A name
+// is reserved while it is being deployed or removed so two operations
+// never race on one deployment.
+type manifestStore struct {
+	mu   sync.Mutex
+	dir  string
+	busy map[string]bool
+}
+
+func newManifestStore(dir string) (*manifestStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create manifest dir: %w", err)
+	}
+	return &manifestStore{dir: dir, busy: make(map[string]bool)}, nil
+}
+
+// reserve claims name for an operation. With fresh set, the name and
+// domain must not belong to a recorded deployment yet; otherwise the
+// deployment must exist.
+func (s *manifestStore) reserve(name, domain string, fresh bool) (release func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.busy[name] {
+		return nil, fmt.Errorf("%s: %w", name, errDeploymentBusy)
+	}
+
+	manifests, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	for _, mf := range manifests {
+		if mf.Name == name {
+			exists = true
+		} else if fresh && domain != "" && mf.Domain == domain {
+			return nil, fmt.Errorf("domain %s is served by %s: %w", domain, mf.Name, errDeploymentExists)
+		}
+	}
+	if fresh && exists {
+		return nil, fmt.Errorf("%s: %w; remove it first", name, errDeploymentExists)
+	}
+	if !fresh && !exists {
+		return nil, fmt.Errorf("%s: %w", name, errNoDeployment)
+	}
+
+	s.busy[name] = true
+	return func() {
+		s.mu.Lock()
+		delete(s.busy, name)
+		s.mu.Unlock()
+	}, nil
+}
+
+// all returns the recorded deployments sorted by name
+func (s *manifestStore) all() ([]*Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// list reads every manifest. Callers hold mu.
+func (s *manifestStore) list() ([]*Manifest, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest dir: %w", err)
+	}
+
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Skipping deployment manifest %s: %v", path, err)
+			continue
+		}
+		var mf Manifest
+		if err := json.Unmarshal(data, &mf); err != nil || mf.Name == "" {
+			log.Printf("Skipping deployment manifest %s: invalid", path)
+			continue
+		}
+		manifests = append(manifests, &mf)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, nil
+}
+
+// get returns the manifest of name
+func (s *manifestStore) get(name string) (*Manifest, error) {
+	data, err := os.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", name, errNoDeployment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var mf Manifest
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", name, err)
+	}
+	return &mf, nil
+}
+
+// save writes mf, replacing its previous manifest
+func (s *manifestStore) save(mf *Manifest) error {
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so a crash never leaves a torn manifest
+	path := s.path(mf.Name)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *manifestStore) delete(name string) error {
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *manifestStore) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// track wraps steps so mf lists each resource as soon as it is created and
+// drops it once undone. The manifest on disk stays current, so resources
+// left by an agent that stopped mid-deployment can still be removed.
+func (s *manifestStore) track(mf *Manifest, steps []Step) []Step {
+	tracked := make([]Step, len(steps))
+	for i, step := range steps {
+		tracked[i] = step
+		if step.Creates == nil {
+			continue
+		}
+
+		run, undo, creates := step.Run, step.Undo, step.Creates
+		var created *Resource
+		tracked[i].Run = func(ctx context.Context) error {
+			if err := run(ctx); err != nil {
+				return err
+			}
+			if created = creates(); created == nil {
+				return nil
+			}
+			mf.Resources = append(mf.Resources, *created)
+			if err := s.save(mf); err != nil {
+				// Untracked resources could never be removed
+				mf.remove(*created)
+				if undo != nil {
+					undo(ctx)
+				}
+				return fmt.Errorf("record manifest: %w", err)
+			}
+			return nil
+		}
+		if undo == nil {
+			continue
+		}
+		tracked[i].Undo = func(ctx context.Context) error {
+			if err := undo(ctx); err != nil {
+				return err
+			}
+			if created == nil {
+				return nil
+			}
+			mf.remove(*created)
+			return s.save(mf)
+		}
+	}
+	return tracked
+}
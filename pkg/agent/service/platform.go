@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// pluginCapabilities maps each host service plugin to the agent capability
+// it provides
+var pluginCapabilities = map[string]string{
+	PluginNginx:       capability.Nginx,
+	PluginSystemd:     capability.Systemd,
+	PluginFirewall:    capability.Firewall,
+	PluginEnvironment: capability.Host,
+	PluginCron:        capability.Cron,
+	PluginACME:        capability.ACME,
+	PluginDNS:         capability.DNS,
+}
+
+// platform is what the host service plugins need from the host, probed
+// once at startup
+type platform struct {
+	goos     string
+	lookPath func(file string) (string, error)
+	stat     func(name string) (os.FileInfo, error)
+}
+
+var hostPlatform = platform{goos: runtime.GOOS, lookPath: exec.LookPath, stat: os.Stat}
+
+// unavailable returns why the named plugin, configured with settings, would
+// fail every call on this host, or "" when it can run. Plugins without
+// host requirements, and settings Init rejects anyway, pass.
+func (p platform) unavailable(name string, settings map[string]interface{}) string {
+	switch name {
+	case PluginSystemd:
+		if p.goos != "linux" {
+			return fmt.Sprintf("systemd runs only on Linux, not %s", p.goos)
+		}
+		if _, err := p.stat("/run/systemd/system"); err != nil {
+			return "systemd is not the init system"
+		}
+		if !p.has("systemctl") {
+			return "systemctl not found"
+		}
+	case PluginFirewall:
+		if p.goos != "linux" {
+			return fmt.Sprintf("ufw, iptables and nftables run only on Linux, not %s", p.goos)
+		}
+		switch backend := plugin.GetStringConfig(settings, "backend"); backend {
+		case "":
+			if !p.has("ufw") && !p.has("iptables") && !p.has("nft") {
+				return "none of ufw, iptables or nft found"
+			}
+		case "ufw", "iptables":
+			if !p.has(backend) {
+				return backend + " not found"
+			}
+		case "nftables":
+			if !p.has("nft") {
+				return "nft not found"
+			}
+		}
+	case PluginCron:
+		if _, err := p.stat("/etc/cron.d"); err != nil {
+			return "/etc/cron.d not found: no cron daemon that reads it is installed"
+		}
+	case PluginDNS:
+		if !p.has("rndc") {
+			return "rndc not found: BIND is not installed"
+		}
+	}
+	return ""
+}
+
+func (p platform) has(command string) bool {
+	_, err := p.lookPath(command)
+	return err == nil
+}
+
+// Availability lists the host service plugins enabled in the agent config
+// and whether this host can run them. Unavailable plugins are left
+// uninitialized and their services are not served.
+func (m *ServiceManager) Availability() []*v1.PluginAvailability {
+	return m.availability
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// fakePlatform is a host running goos with only the given commands and
+// paths
+func fakePlatform(goos string, present ...string) platform {
+	has := make(map[string]bool)
+	for _, p := range present {
+		has[p] = true
+	}
+	return platform{
+		goos: goos,
+		lookPath: func(file string) (string, error) {
+			if has[file] {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("executable file not found in $PATH")
+		},
+		stat: func(name string) (os.FileInfo, error) {
+			if has[name] {
+				return nil, nil
+			}
+			return nil, fs.ErrNotExist
+		},
+	}
+}
+
+func TestPlatformUnavailable(t *testing.T) {
+	debian := fakePlatform("linux", "/run/systemd/system", "systemctl", "nft", "/etc/cron.d", "rndc")
+	alpine := fakePlatform("linux", "iptables")
+	mac := fakePlatform("darwin", "systemctl", "ufw", "/etc/cron.d")
+
+	tests := []struct {
+		host     platform
+		plugin   string
+		settings map[string]interface{}
+		want     string
+	}{
+		{debian, PluginSystemd, nil, ""},
+		{debian, PluginFirewall, nil, ""},
+		{debian, PluginFirewall, map[string]interface{}{"backend": "nftables"}, ""},
+		{debian, PluginFirewall, map[string]interface{}{"backend": "ufw"}, "ufw not found"},
+		{debian, PluginCron, nil, ""},
+		{debian, PluginDNS, nil, ""},
+		{alpine, PluginSystemd, nil, "systemd is not the init system"},
+		{alpine, PluginFirewall, nil, ""},
+		{alpine, PluginCron, nil, "/etc/cron.d not found: no cron daemon that reads it is installed"},
+		{alpine, PluginDNS, nil, "rndc not found: BIND is not installed"},
+		{mac, PluginSystemd, nil, "systemd runs only on Linux, not darwin"},
+		{mac, PluginFirewall, nil, "ufw, iptables and nftables run only on Linux, not darwin"},
+		{mac, PluginCron, nil, ""},
+		{mac, PluginNginx, nil, ""},
+		{mac, PluginEnvironment, nil, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.host.unavailable(tt.plugin, tt.settings); got != tt.want {
+			t.Errorf("%s on %s %v: %q, want %q", tt.plugin, tt.host.goos, tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestServiceManagerSkipsUnavailablePlugins(t *testing.T) {
+	saved := hostPlatform
+	defer func() { hostPlatform = saved }()
+	hostPlatform = fakePlatform("darwin", "/etc/cron.d")
+
+	cfg := config.PluginConfig{Enabled: map[string]bool{PluginSystemd: true, PluginCron: true}}
+	m, err := NewServiceManager(context.Background(), cfg, t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Shutdown(context.Background())
+
+	if m.Systemd() != nil || m.manifests != nil {
+		t.Error("systemd initialized on darwin")
+	}
+	if caps := m.Capabilities(); len(caps) != 2 || caps[0] != "cron" || caps[1] != "drift" {
+		t.Errorf("capabilities = %v, want cron and drift", caps)
+	}
+	got := make(map[string]string)
+	for _, a := range m.Availability() {
+		got[a.Capability] = a.Reason
+		if a.Available != (a.Reason == "") {
+			t.Errorf("%s: available %v with reason %q", a.Plugin, a.Available, a.Reason)
+		}
+	}
+	if len(got) != 2 || got["cron"] != "" || got["systemd"] != "systemd runs only on Linux, not darwin" {
+		t.Errorf("availability = %v", m.Availability())
+	}
+}
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bhangun/mandau/plugins/host/cron"
+	"github.com/bhangun/mandau/plugins/security/acme"
+	"github.com/bhangun/mandau/plugins/services/nginx"
+	"github.com/bhangun/mandau/plugins/services/systemd"
+)
+
+// Recipe is a kind of deployment. Manifest names the deployment; Steps
+// checks the recipe against the enabled plugins and lists its changes, each
+// with its undo.
+type Recipe interface {
+	Manifest() *Manifest
+	Steps(m *ServiceManager) ([]Step, error)
+}
+
+// validName keeps recipe names usable as unit, container and file names
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func checkName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// requirePlugins fails unless every named plugin is enabled
+func (m *ServiceManager) requirePlugins(what string, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		var enabled bool
+		switch name {
+		case PluginNginx:
+			enabled = m.nginx != nil
+		case PluginSystemd:
+			enabled = m.systemd != nil
+		case PluginFirewall:
+			enabled = m.firewall != nil
+		case PluginCron:
+			enabled = m.cron != nil
+		case PluginACME:
+			enabled = m.acme != nil
+		}
+		if !enabled {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s needs the %s plugin(s) enabled", what, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// WebServiceConfig deploys a command behind an nginx reverse proxy
+type WebServiceConfig struct {
+	Name        string
+	Description string
+	Domain      string
+	Port        int
+	Command     string
+	WorkingDir  string
+	User        string
+	SSL         bool
+	Environment map[string]string
+}
+
+func (config *WebServiceConfig) Manifest() *Manifest {
+	return &Manifest{Name: config.Name, Kind: "web service", Domain: config.Domain}
+}
+
+func (config *WebServiceConfig) Steps(m *ServiceManager) ([]Step, error) {
+	if err := checkName(config.Name); err != nil {
+		return nil, err
+	}
+	if err := m.requirePlugins("a web service", PluginSystemd, PluginNginx, PluginFirewall); err != nil {
+		return nil, err
+	}
+	if config.SSL {
+		if err := m.requirePlugins("SSL", PluginACME, PluginCron); err != nil {
+			return nil, err
+		}
+	}
+
+	upstream := fmt.Sprintf("http://127.0.0.1:%d", config.Port)
+	plain := func() *nginx.VirtualHost {
+		return nginx.ReverseProxy(config.Domain, upstream, 80)
+	}
+
+	// 1. Create systemd service
+	steps := m.unitSteps(&systemd.ServiceUnit{
+		Name:        config.Name,
+		Description: config.Description,
+		User:        config.User,
+		WorkingDir:  config.WorkingDir,
+		ExecStart:   config.Command,
+		Restart:     "always",
+		RestartSec:  10,
+		Environment: config.Environment,
+	})
+
+	// 2. Configure nginx reverse proxy, 3. open firewall ports
+	steps = append(steps, m.vhostSteps(plain())...)
+	steps = append(steps, m.openPortStep(80), m.openPortStep(443))
+
+	// 4. Obtain SSL certificate, 5. add automatic renewal
+	if config.SSL {
+		steps = append(steps, m.sslSteps(config.Name, config.Domain, plain, func(cert *acme.Certificate) *nginx.VirtualHost {
+			return &nginx.VirtualHost{
+				ServerName: config.Domain,
+				Listen:     443,
+				ProxyPass:  upstream,
+				SSL:        sslConfig(cert),
+			}
+		})...)
+	}
+	return steps, nil
+}
+
+// StaticSiteConfig serves a directory with nginx
+type StaticSiteConfig struct {
+	Name   string
+	Domain string
+	Root   string
+	Index  []string
+	SSL    bool
+}
+
+func (config *StaticSiteConfig) Manifest() *Manifest {
+	return &Manifest{Name: config.Name, Kind: "static site", Domain: config.Domain}
+}
+
+func (config *StaticSiteConfig) Steps(m *ServiceManager) ([]Step, error) {
+	if err := checkName(config.Name); err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(config.Root) {
+		return nil, fmt.Errorf("site root must be an absolute path, got %q", config.Root)
+	}
+	if err := m.requirePlugins("a static site", PluginNginx, PluginFirewall); err != nil {
+		return nil, err
+	}
+	if config.SSL {
+		if err := m.requirePlugins("SSL", PluginACME, PluginCron); err != nil {
+			return nil, err
+		}
+	}
+
+	index := config.Index
+	if len(index) == 0 {
+		index = []string{"index.html"}
+	}
+	site := func(listen int) *nginx.VirtualHost {
+		return &nginx.VirtualHost{
+			ServerName: config.Domain,
+			Listen:     listen,
+			Root:       config.Root,
+			Index:      index,
+			Locations:  []nginx.Location{{Path: "/", TryFiles: []string{"$uri", "$uri/", "=404"}}},
+		}
+	}
+	plain := func() *nginx.VirtualHost { return site(80) }
+
+	steps := m.vhostSteps(plain())
+	steps = append(steps, m.openPortStep(80), m.openPortStep(443))
+
+	if config.SSL {
+		steps = append(steps, m.sslSteps(config.Name, config.Domain, plain, func(cert *acme.Certificate) *nginx.VirtualHost {
+			vhost := site(443)
+			vhost.SSL = sslConfig(cert)
+			return vhost
+		})...)
+	}
+	return steps, nil
+}
+
+// DatabaseConfig runs a database container under systemd, bound to
+// localhost, with optional scheduled dumps
+type DatabaseConfig struct {
+	Name           string
+	Engine         string // postgres, mysql or mariadb
+	Version        string
+	Port           int
+	DataDir        string
+	Password       string
+	BackupSchedule string // Cron schedule; empty disables backups
+	BackupDir      string
+	RetentionDays  int
+}
+
+// databaseEngine describes how to run and dump one database engine
+type databaseEngine struct {
+	image       string
+	port        int
+	dataPath    string // Inside the container
+	passwordEnv string
+	dump        string // Run inside the container, writes SQL to stdout
+}
+
+var databaseEngines = map[string]databaseEngine{
+	"postgres": {
+		image:       "postgres",
+		port:        5432,
+		dataPath:    "/var/lib/postgresql/data",
+		passwordEnv: "POSTGRES_PASSWORD",
+		dump:        "pg_dumpall -U postgres",
+	},
+	"mysql": {
+		image:       "mysql",
+		port:        3306,
+		dataPath:    "/var/lib/mysql",
+		passwordEnv: "MYSQL_ROOT_PASSWORD",
+		dump:        `sh -c 'exec mysqldump --all-databases -uroot -p"$MYSQL_ROOT_PASSWORD"'`,
+	},
+	"mariadb": {
+		image:       "mariadb",
+		port:        3306,
+		dataPath:    "/var/lib/mysql",
+		passwordEnv: "MARIADB_ROOT_PASSWORD",
+		dump:        `sh -c 'exec mariadb-dump --all-databases -uroot -p"$MARIADB_ROOT_PASSWORD"'`,
+	},
+}
+
+func (config *DatabaseConfig) Manifest() *Manifest {
+	return &Manifest{Name: config.Name, Kind: "database"}
+}
+
+func (config *DatabaseConfig) Steps(m *ServiceManager) ([]Step, error) {
+	if err := checkName(config.Name); err != nil {
+		return nil, err
+	}
+	engine, ok := databaseEngines[config.Engine]
+	if !ok {
+		return nil, fmt.Errorf("unknown database engine %q: use postgres, mysql or mariadb", config.Engine)
+	}
+	if config.Password == "" {
+		return nil, fmt.Errorf("a database password is required")
+	}
+	if err := m.requirePlugins("a database", PluginSystemd); err != nil {
+		return nil, err
+	}
+	if config.BackupSchedule != "" {
+		if err := m.requirePlugins("database backups", PluginCron); err != nil {
+			return nil, err
+		}
+	}
+
+	version := config.Version
+	if version == "" {
+		version = "latest"
+	}
+	port := config.Port
+	if port == 0 {
+		port = engine.port
+	}
+	dataDir := config.DataDir
+	if dataDir == "" {
+		dataDir = filepath.Join("/var/lib/mandau/databases", config.Name)
+	}
+	container := "mandau-db-" + config.Name
+	volume := dataDir + ":" + engine.dataPath
+	if m.mac.SELinux && m.labels == LabelsApply {
+		// Docker labels the directory for the container alone
+		volume += ":Z"
+	}
+
+	// The password goes in the unit environment rather than the command
+	// line, so it does not show in process listings
+	steps := m.unitSteps(&systemd.ServiceUnit{
+		Name:        container,
+		Description: fmt.Sprintf("Mandau %s database %s", config.Engine, config.Name),
+		After:       []string{"docker.service"},
+		Requires:    []string{"docker.service"},
+		ExecStart: fmt.Sprintf("/usr/bin/docker run --rm --name %s -p 127.0.0.1:%d:%d -v %s -e %s %s:%s",
+			container, port, engine.port, volume, engine.passwordEnv, engine.image, version),
+		ExecStop:    "/usr/bin/docker stop " + container,
+		Restart:     "always",
+		RestartSec:  10,
+		Environment: map[string]string{engine.passwordEnv: config.Password},
+		// A container left over from a crash would block the name
+		CustomService: "ExecStartPre=-/usr/bin/docker rm -f " + container,
+	})
+
+	if config.BackupSchedule == "" {
+		return steps, nil
+	}
+
+	backupDir := config.BackupDir
+	if backupDir == "" {
+		backupDir = filepath.Join("/var/backups/mandau", config.Name)
+	}
+	retention := config.RetentionDays
+	if retention <= 0 {
+		retention = 7
+	}
+
+	// cron treats % as a newline, so the date format is escaped
+	backup := fmt.Sprintf(`mkdir -p %s && docker exec %s %s | gzip > %s/%s-$(date +\%%Y\%%m\%%d\%%H\%%M).sql.gz && find %s -name '%s-*.sql.gz' -mtime +%d -delete`,
+		backupDir, container, engine.dump, backupDir, config.Name, backupDir, config.Name, retention)
+
+	return append(steps, m.cronStep(&cron.CronJob{
+		Name:     config.Name + "-db-backup",
+		Schedule: config.BackupSchedule,
+		Command:  backup,
+	})), nil
+}
+
+// WorkerConfig runs a background command under systemd
+type WorkerConfig struct {
+	Name        string
+	Description string
+	Command     string
+	WorkingDir  string
+	User        string
+	Environment map[string]string
+}
+
+func (config *WorkerConfig) Manifest() *Manifest {
+	return &Manifest{Name: config.Name, Kind: "worker"}
+}
+
+func (config *WorkerConfig) Steps(m *ServiceManager) ([]Step, error) {
+	if err := checkName(config.Name); err != nil {
+		return nil, err
+	}
+	if config.Command == "" {
+		return nil, fmt.Errorf("a worker command is required")
+	}
+	if err := m.requirePlugins("a worker", PluginSystemd); err != nil {
+		return nil, err
+	}
+
+	return m.unitSteps(&systemd.ServiceUnit{
+		Name:        config.Name,
+		Description: config.Description,
+		User:        config.User,
+		WorkingDir:  config.WorkingDir,
+		ExecStart:   config.Command,
+		Restart:     "always",
+		RestartSec:  10,
+		Environment: config.Environment,
+	}), nil
+}
+
+// unitSteps creates, enables and starts a systemd unit
+func (m *ServiceManager) unitSteps(unit *systemd.ServiceUnit) []Step {
+	return m.confineSteps(m.mac.UnitExec(unit.ExecStart), []Step{
+		{
+			Name: "create systemd unit " + unit.Name,
+			Run: func(ctx context.Context) error {
+				return m.systemd.CreateService(ctx, unit, false)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.systemd.RemoveService(ctx, unit.Name, false)
+			},
+			Creates: func() *Resource {
+				return &Resource{Kind: ResourceUnit, Name: unit.Name}
+			},
+		},
+		{
+			Name: "enable service " + unit.Name,
+			Run: func(ctx context.Context) error {
+				return m.systemd.EnableService(ctx, unit.Name)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.systemd.DisableService(ctx, unit.Name)
+			},
+		},
+		{
+			Name: "start service " + unit.Name,
+			Run: func(ctx context.Context) error {
+				return m.systemd.StartService(ctx, unit.Name)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.systemd.StopService(ctx, unit.Name)
+			},
+		},
+	})
+}
+
+// vhostSteps writes and enables an nginx virtual host
+func (m *ServiceManager) vhostSteps(vhost *nginx.VirtualHost) []Step {
+	fixes := m.mac.ListenPort(vhost.Listen)
+	if vhost.Root != "" {
+		fixes = append(fixes, m.mac.WebRoot(vhost.Root)...)
+	}
+	if vhost.ProxyPass != "" {
+		fixes = append(fixes, m.mac.ProxyUpstream()...)
+	}
+
+	return m.confineSteps(fixes, []Step{
+		{
+			Name: "create nginx config " + vhost.ServerName,
+			Run: func(ctx context.Context) error {
+				return m.nginx.CreateVirtualHost(ctx, vhost, false)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.nginx.DeleteVirtualHost(ctx, vhost.ServerName, false)
+			},
+			Creates: func() *Resource {
+				return &Resource{Kind: ResourceVhost, Name: vhost.ServerName}
+			},
+		},
+		{
+			Name: "enable nginx vhost " + vhost.ServerName,
+			Run: func(ctx context.Context) error {
+				return m.nginx.EnableVirtualHost(ctx, vhost.ServerName)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.nginx.DisableVirtualHost(ctx, vhost.ServerName)
+			},
+		},
+	})
+}
+
+// sslSteps obtains a certificate for domain, switches its virtual host to
+// the one secure returns and schedules renewal. The certificate is kept on
+// rollback; it is harmless and reissuing it counts against the CA's rate
+// limits. Rolling back the switch restores the host plain returns.
+func (m *ServiceManager) sslSteps(name, domain string, plain func() *nginx.VirtualHost, secure func(*acme.Certificate) *nginx.VirtualHost) []Step {
+	var cert *acme.Certificate
+	return []Step{
+		{
+			Name: "obtain certificate " + domain,
+			Run: func(ctx context.Context) error {
+				var err error
+				cert, err = m.acme.ObtainCertificate(ctx, domain)
+				return err
+			},
+			Creates: func() *Resource {
+				return &Resource{Kind: ResourceCertificate, Name: domain}
+			},
+		},
+		{
+			Name: "create SSL vhost " + domain,
+			Run: func(ctx context.Context) error {
+				return m.nginx.CreateVirtualHost(ctx, secure(cert), false)
+			},
+			Undo: func(ctx context.Context) error {
+				return m.nginx.CreateVirtualHost(ctx, plain(), false)
+			},
+		},
+		m.cronStep(&cron.CronJob{
+			Name:     name + "-cert-renewal",
+			Schedule: "0 0 * * *", // Daily at midnight
+			Command:  "certbot renew && nginx -s reload",
+		}),
+	}
+}
+
+// cronStep installs a cron job
+func (m *ServiceManager) cronStep(job *cron.CronJob) Step {
+	return Step{
+		Name: "add cron job " + job.Name,
+		Run: func(ctx context.Context) error {
+			return m.cron.AddCronJob(ctx, job, false)
+		},
+		Undo: func(ctx context.Context) error {
+			return m.cron.RemoveCronJob(ctx, job.Name, false)
+		},
+		Creates: func() *Resource {
+			return &Resource{Kind: ResourceCron, Name: job.Name}
+		},
+	}
+}
+
+// openPortStep allows a TCP port, closing it again on rollback only when
+// the deployment opened it. The port is recorded either way, so removal
+// knows which deployments still need it.
+func (m *ServiceManager) openPortStep(port int) Step {
+	var opened bool
+	return Step{
+		Name: fmt.Sprintf("open firewall port %d", port),
+		Run: func(ctx context.Context) error {
+			allowed, err := m.firewall.PortAllowed(ctx, port, "tcp")
+			if err != nil {
+				return err
+			}
+			if allowed {
+				return nil
+			}
+			if err := m.firewall.AllowPort(ctx, port, "tcp"); err != nil {
+				return err
+			}
+			opened = true
+			return nil
+		},
+		Undo: func(ctx context.Context) error {
+			if !opened {
+				return nil
+			}
+			return m.firewall.RevokePort(ctx, port, "tcp")
+		},
+		Creates: func() *Resource {
+			return &Resource{Kind: ResourcePort, Name: fmt.Sprintf("%d/tcp", port), Owned: opened}
+		},
+	}
+}
+
+func sslConfig(cert *acme.Certificate) *nginx.SSLConfig {
+	return &nginx.SSLConfig{
+		Certificate:    cert.CertPath,
+		CertificateKey: cert.KeyPath,
+		Protocols:      []string{"TLSv1.2", "TLSv1.3"},
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bhangun/mandau/plugins/host/cron"
+	"github.com/bhangun/mandau/plugins/services/systemd"
+)
+
+func stepNames(steps []Step) []string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestDatabaseRecipeSchedulesBackups(t *testing.T) {
+	m := &ServiceManager{systemd: systemd.New(), cron: cron.New()}
+
+	steps, err := (&DatabaseConfig{
+		Name:           "orders",
+		Engine:         "postgres",
+		Password:       "secret",
+		BackupSchedule: "0 3 * * *",
+	}).Steps(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := stepNames(steps)
+	if names[0] != "create systemd unit mandau-db-orders" {
+		t.Errorf("first step = %q, want the database unit", names[0])
+	}
+	if last := names[len(names)-1]; last != "add cron job orders-db-backup" {
+		t.Errorf("last step = %q, want the backup job", last)
+	}
+}
+
+func TestRecipesRequirePlugins(t *testing.T) {
+	m := &ServiceManager{systemd: systemd.New()}
+
+	_, err := (&DatabaseConfig{Name: "orders", Engine: "postgres", Password: "secret", BackupSchedule: "@daily"}).Steps(m)
+	if err == nil || !strings.Contains(err.Error(), PluginCron) {
+		t.Errorf("database with backups error = %v, want cron plugin required", err)
+	}
+
+	_, err = (&StaticSiteConfig{Name: "docs", Domain: "docs.example.com", Root: "/srv/docs"}).Steps(m)
+	if err == nil || !strings.Contains(err.Error(), PluginNginx) {
+		t.Errorf("static site error = %v, want nginx plugin required", err)
+	}
+
+	if _, err := (&WorkerConfig{Name: "mailer", Command: "/usr/bin/mailer"}).Steps(m); err != nil {
+		t.Errorf("worker error = %v, want systemd alone to suffice", err)
+	}
+}
+
+func TestRecipesRejectBadInput(t *testing.T) {
+	m := &ServiceManager{systemd: systemd.New()}
+
+	for name, recipe := range map[string]Recipe{
+		"unsafe name":    &WorkerConfig{Name: "../etc", Command: "true"},
+		"unknown engine": &DatabaseConfig{Name: "db", Engine: "oracle", Password: "x"},
+		"no password":    &DatabaseConfig{Name: "db", Engine: "mysql"},
+		"relative root":  &StaticSiteConfig{Name: "docs", Root: "srv/docs"},
+	} {
+		if _, err := recipe.Steps(m); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
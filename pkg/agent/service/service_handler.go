@@ -2,15 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	v1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/plugins/host/cron"
+	"github.com/bhangun/mandau/plugins/host/environment"
+	"github.com/bhangun/mandau/plugins/services/dns"
 	"github.com/bhangun/mandau/plugins/services/firewall"
 	"github.com/bhangun/mandau/plugins/services/nginx"
 	"github.com/bhangun/mandau/plugins/services/systemd"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ServicesHandler struct {
@@ -19,7 +34,10 @@ type ServicesHandler struct {
 	v1.UnimplementedFirewallServiceServer
 	v1.UnimplementedACMEServiceServer
 	v1.UnimplementedHostEnvironmentServiceServer
+	v1.UnimplementedCronServiceServer
+	v1.UnimplementedDNSServiceServer
 	v1.UnimplementedServiceDeploymentServiceServer
+	v1.UnimplementedDriftServiceServer
 
 	serviceMgr *ServiceManager
 }
@@ -30,14 +48,48 @@ func NewServicesHandler(serviceMgr *ServiceManager) *ServicesHandler {
 	}
 }
 
+// Register serves the host services whose plugins are enabled; the others
+// stay unknown to callers
+func (h *ServicesHandler) Register(server grpc.ServiceRegistrar) {
+	m := h.serviceMgr
+	if m.nginx != nil {
+		v1.RegisterNginxServiceServer(server, h)
+	}
+	if m.systemd != nil {
+		v1.RegisterSystemdServiceServer(server, h)
+	}
+	if m.firewall != nil {
+		v1.RegisterFirewallServiceServer(server, h)
+	}
+	if m.acme != nil {
+		v1.RegisterACMEServiceServer(server, h)
+	}
+	if m.environment != nil {
+		v1.RegisterHostEnvironmentServiceServer(server, h)
+	}
+	if m.cron != nil {
+		v1.RegisterCronServiceServer(server, h)
+	}
+	if m.dns != nil {
+		v1.RegisterDNSServiceServer(server, h)
+	}
+	if m.systemd != nil {
+		v1.RegisterServiceDeploymentServiceServer(server, h)
+	}
+	if m.tracksDrift() {
+		v1.RegisterDriftServiceServer(server, h)
+	}
+}
+
 // Nginx Service Handlers
 func (h *ServicesHandler) CreateVirtualHost(ctx context.Context, req *v1.CreateVirtualHostRequest) (*v1.CreateVirtualHostResponse, error) {
 	vhost := &nginx.VirtualHost{
-		ServerName: req.ServerName,
-		Listen:     int(req.Listen),
-		Root:       req.Root,
-		Index:      req.Index,
-		ProxyPass:  req.ProxyPass,
+		ServerName:   req.ServerName,
+		Listen:       int(req.Listen),
+		Root:         req.Root,
+		Index:        req.Index,
+		ProxyPass:    req.ProxyPass,
+		CustomConfig: req.Snippet,
 	}
 
 	// Convert locations
@@ -61,8 +113,16 @@ func (h *ServicesHandler) CreateVirtualHost(ctx context.Context, req *v1.CreateV
 		}
 	}
 
-	if err := h.serviceMgr.nginx.CreateVirtualHost(vhost); err != nil {
-		return nil, status.Errorf(codes.Internal, "create vhost: %v", err)
+	if req.DryRun {
+		diff, err := h.serviceMgr.Nginx().PlanVirtualHost(vhost)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan vhost: %v", err)
+		}
+		return &v1.CreateVirtualHostResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.nginx.CreateVirtualHost(ctx, vhost, req.Force); err != nil {
+		return nil, changeStatus("create vhost", err)
 	}
 
 	return &v1.CreateVirtualHostResponse{
@@ -71,14 +131,19 @@ func (h *ServicesHandler) CreateVirtualHost(ctx context.Context, req *v1.CreateV
 }
 
 func (h *ServicesHandler) CreateReverseProxy(ctx context.Context, req *v1.CreateReverseProxyRequest) (*v1.CreateReverseProxyResponse, error) {
-	err := h.serviceMgr.Nginx().CreateReverseProxy(
-		req.Domain,
-		req.Upstream,
-		int(req.Port),
-	)
+	vhost := nginx.ReverseProxy(req.Domain, req.Upstream, int(req.Port))
+	vhost.CustomConfig = req.Snippet
 
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "create reverse proxy: %v", err)
+	if req.DryRun {
+		diff, err := h.serviceMgr.Nginx().PlanVirtualHost(vhost)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan reverse proxy: %v", err)
+		}
+		return &v1.CreateReverseProxyResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Nginx().CreateVirtualHost(ctx, vhost, req.Force); err != nil {
+		return nil, changeStatus("create reverse proxy", err)
 	}
 
 	return &v1.CreateReverseProxyResponse{
@@ -86,6 +151,104 @@ func (h *ServicesHandler) CreateReverseProxy(ctx context.Context, req *v1.Create
 	}, nil
 }
 
+func (h *ServicesHandler) EnableVirtualHost(ctx context.Context, req *v1.EnableVirtualHostRequest) (*v1.EnableVirtualHostResponse, error) {
+	if err := checkName(req.ServerName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := h.serviceMgr.Nginx().EnableVirtualHost(ctx, req.ServerName); err != nil {
+		return nil, status.Errorf(codes.Internal, "enable vhost: %v", err)
+	}
+
+	return &v1.EnableVirtualHostResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) DisableVirtualHost(ctx context.Context, req *v1.DisableVirtualHostRequest) (*v1.DisableVirtualHostResponse, error) {
+	if err := checkName(req.ServerName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := h.serviceMgr.Nginx().DisableVirtualHost(ctx, req.ServerName); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "vhost %s is not enabled", req.ServerName)
+		}
+		return nil, status.Errorf(codes.Internal, "disable vhost: %v", err)
+	}
+
+	return &v1.DisableVirtualHostResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) DeleteVirtualHost(ctx context.Context, req *v1.DeleteVirtualHostRequest) (*v1.DeleteVirtualHostResponse, error) {
+	if err := checkName(req.ServerName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.DryRun {
+		diff, err := h.serviceMgr.Nginx().PlanDeleteVirtualHost(req.ServerName)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "vhost %s not found", req.ServerName)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan vhost deletion: %v", err)
+		}
+		return &v1.DeleteVirtualHostResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Nginx().DeleteVirtualHost(ctx, req.ServerName, req.Force); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "vhost %s not found", req.ServerName)
+		}
+		return nil, changeStatus("delete vhost", err)
+	}
+
+	return &v1.DeleteVirtualHostResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListVirtualHosts(ctx context.Context, req *v1.ListVirtualHostsRequest) (*v1.ListVirtualHostsResponse, error) {
+	vhosts, err := h.serviceMgr.Nginx().ListVirtualHosts()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list vhosts: %v", err)
+	}
+
+	resp := &v1.ListVirtualHostsResponse{}
+	for name, enabled := range vhosts {
+		resp.Vhosts = append(resp.Vhosts, name)
+		if enabled {
+			resp.Enabled = append(resp.Enabled, name)
+		}
+	}
+	sort.Strings(resp.Vhosts)
+	sort.Strings(resp.Enabled)
+	return resp, nil
+}
+
+func (h *ServicesHandler) CreateLoadBalancer(ctx context.Context, req *v1.CreateLoadBalancerRequest) (*v1.CreateLoadBalancerResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(req.Backends) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "a load balancer needs at least one backend")
+	}
+	if req.DryRun {
+		diff, err := h.serviceMgr.Nginx().PlanLoadBalancer(req.Name, req.Backends, req.Algorithm)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan load balancer: %v", err)
+		}
+		return &v1.CreateLoadBalancerResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Nginx().CreateLoadBalancer(ctx, req.Name, req.Backends, req.Algorithm, req.Force); err != nil {
+		return nil, changeStatus("create load balancer", err)
+	}
+
+	return &v1.CreateLoadBalancerResponse{
+		Status: "success",
+	}, nil
+}
+
 // Systemd Service Handlers
 func (h *ServicesHandler) CreateService(ctx context.Context, req *v1.CreateServiceRequest) (*v1.CreateServiceResponse, error) {
 	service := &systemd.ServiceUnit{
@@ -105,10 +268,30 @@ func (h *ServicesHandler) CreateService(ctx context.Context, req *v1.CreateServi
 		MemoryLimit:   req.MemoryLimit,
 		PrivateTmp:    req.PrivateTmp,
 		ProtectSystem: req.ProtectSystem,
+		CustomUnit:    req.UnitSnippet,
+		CustomService: req.ServiceSnippet,
+		CustomInstall: req.InstallSnippet,
+	}
+
+	if req.Template && !unitTemplate.MatchString(req.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid template name %q", req.Name)
+	}
+
+	plan, create := h.serviceMgr.Systemd().PlanService, h.serviceMgr.Systemd().CreateService
+	if req.Template {
+		plan, create = h.serviceMgr.Systemd().PlanTemplate, h.serviceMgr.Systemd().CreateTemplate
+	}
+
+	if req.DryRun {
+		diff, err := plan(service)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan service: %v", err)
+		}
+		return &v1.CreateServiceResponse{Status: dryRunStatus, Diff: diff}, nil
 	}
 
-	if err := h.serviceMgr.systemd.CreateService(service); err != nil {
-		return nil, status.Errorf(codes.Internal, "create service: %v", err)
+	if err := create(ctx, service, req.Force); err != nil {
+		return nil, changeStatus("create service", err)
 	}
 
 	return &v1.CreateServiceResponse{
@@ -117,7 +300,7 @@ func (h *ServicesHandler) CreateService(ctx context.Context, req *v1.CreateServi
 }
 
 func (h *ServicesHandler) StartService(ctx context.Context, req *v1.StartServiceRequest) (*v1.StartServiceResponse, error) {
-	if err := h.serviceMgr.Systemd().StartService(req.Name); err != nil {
+	if err := h.serviceMgr.Systemd().StartService(ctx, req.Name); err != nil {
 		return nil, status.Errorf(codes.Internal, "start service: %v", err)
 	}
 
@@ -126,8 +309,48 @@ func (h *ServicesHandler) StartService(ctx context.Context, req *v1.StartService
 	}, nil
 }
 
+func (h *ServicesHandler) StopService(ctx context.Context, req *v1.StopServiceRequest) (*v1.StopServiceResponse, error) {
+	if err := h.serviceMgr.Systemd().StopService(ctx, req.Name); err != nil {
+		return nil, status.Errorf(codes.Internal, "stop service: %v", err)
+	}
+
+	return &v1.StopServiceResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) RestartService(ctx context.Context, req *v1.RestartServiceRequest) (*v1.RestartServiceResponse, error) {
+	if err := h.serviceMgr.Systemd().RestartService(ctx, req.Name); err != nil {
+		return nil, status.Errorf(codes.Internal, "restart service: %v", err)
+	}
+
+	return &v1.RestartServiceResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) EnableService(ctx context.Context, req *v1.EnableServiceRequest) (*v1.EnableServiceResponse, error) {
+	if err := h.serviceMgr.Systemd().EnableService(ctx, req.Name); err != nil {
+		return nil, status.Errorf(codes.Internal, "enable service: %v", err)
+	}
+
+	return &v1.EnableServiceResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) DisableService(ctx context.Context, req *v1.DisableServiceRequest) (*v1.DisableServiceResponse, error) {
+	if err := h.serviceMgr.Systemd().DisableService(ctx, req.Name); err != nil {
+		return nil, status.Errorf(codes.Internal, "disable service: %v", err)
+	}
+
+	return &v1.DisableServiceResponse{
+		Status: "success",
+	}, nil
+}
+
 func (h *ServicesHandler) GetServiceStatus(ctx context.Context, req *v1.GetServiceStatusRequest) (*v1.GetServiceStatusResponse, error) {
-	svcStatus, err := h.serviceMgr.Systemd().GetServiceStatus(req.Name)
+	svcStatus, err := h.serviceMgr.Systemd().GetServiceStatus(ctx, req.Name)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "get status: %v", err)
 	}
@@ -137,6 +360,96 @@ func (h *ServicesHandler) GetServiceStatus(ctx context.Context, req *v1.GetServi
 	}, nil
 }
 
+func (h *ServicesHandler) ListServices(ctx context.Context, req *v1.ListServicesRequest) (*v1.ListServicesResponse, error) {
+	services, err := h.serviceMgr.Systemd().ListServices()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list services: %v", err)
+	}
+
+	return &v1.ListServicesResponse{
+		Services: services,
+	}, nil
+}
+
+var (
+	// unitTemplate keeps a template name, and so the directory of its
+	// environment files, to one path element
+	unitTemplate = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]*$`)
+	unitInstance = regexp.MustCompile(`^[A-Za-z0-9_:-][A-Za-z0-9_.:-]*$`)
+	envKey       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// maxInstances bounds ScaleInstances, so a typo does not start thousands
+const maxInstances = 256
+
+func (h *ServicesHandler) ListInstances(ctx context.Context, req *v1.ListInstancesRequest) (*v1.ListInstancesResponse, error) {
+	if !unitTemplate.MatchString(req.Template) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid template name %q", req.Template)
+	}
+	instances, err := h.serviceMgr.Systemd().ListInstances(ctx, req.Template)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list instances: %v", err)
+	}
+
+	resp := &v1.ListInstancesResponse{}
+	for _, i := range instances {
+		resp.Instances = append(resp.Instances, &v1.ServiceInstance{
+			Name:        i.Name,
+			Instance:    i.Instance,
+			State:       i.State,
+			Enabled:     i.Enabled,
+			Environment: i.Environment,
+		})
+	}
+	return resp, nil
+}
+
+func (h *ServicesHandler) SetInstanceEnvironment(ctx context.Context, req *v1.SetInstanceEnvironmentRequest) (*v1.SetInstanceEnvironmentResponse, error) {
+	if !unitTemplate.MatchString(req.Template) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid template name %q", req.Template)
+	}
+	if !unitInstance.MatchString(req.Instance) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid instance name %q", req.Instance)
+	}
+	for key, value := range req.Environment {
+		if !envKey.MatchString(key) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid environment variable name %q", key)
+		}
+		if strings.ContainsAny(value, "\n\r\x00") {
+			return nil, status.Errorf(codes.InvalidArgument, "environment variable %s spans lines", key)
+		}
+	}
+
+	systemd := h.serviceMgr.Systemd()
+	if err := systemd.SetInstanceEnvironment(ctx, req.Template, req.Instance, req.Environment, req.Force); err != nil {
+		if len(req.Environment) == 0 && errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "%s@%s has no environment file", req.Template, req.Instance)
+		}
+		return nil, changeStatus("set instance environment", err)
+	}
+	if req.Restart {
+		if err := systemd.RestartService(ctx, req.Template+"@"+req.Instance); err != nil {
+			return nil, status.Errorf(codes.Internal, "restart instance: %v", err)
+		}
+	}
+	return &v1.SetInstanceEnvironmentResponse{Status: "success"}, nil
+}
+
+func (h *ServicesHandler) ScaleInstances(ctx context.Context, req *v1.ScaleInstancesRequest) (*v1.ScaleInstancesResponse, error) {
+	if !unitTemplate.MatchString(req.Template) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid template name %q", req.Template)
+	}
+	if req.Count < 0 || req.Count > maxInstances {
+		return nil, status.Errorf(codes.InvalidArgument, "instance count %d is not between 0 and %d", req.Count, maxInstances)
+	}
+
+	started, stopped, err := h.serviceMgr.Systemd().ScaleInstances(ctx, req.Template, int(req.Count))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "scale instances: %v", err)
+	}
+	return &v1.ScaleInstancesResponse{Status: "success", Started: started, Stopped: stopped}, nil
+}
+
 // Firewall Handlers
 func (h *ServicesHandler) AddRule(ctx context.Context, req *v1.AddFirewallRuleRequest) (*v1.AddFirewallRuleResponse, error) {
 	rule := &firewall.FirewallRule{
@@ -147,9 +460,28 @@ func (h *ServicesHandler) AddRule(ctx context.Context, req *v1.AddFirewallRuleRe
 		ToIP:     req.ToIp,
 		ToPort:   int(req.ToPort),
 		Comment:  req.Comment,
+		Set:      req.RuleSet,
+	}
+	if req.RateLimit != "" {
+		limit, err := firewall.ParseRateLimit(req.RateLimit, int(req.RateBurst))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		rule.Limit = limit
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.DryRun {
+		diff, err := h.serviceMgr.Firewall().PlanRule(ctx, rule)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan rule: %v", err)
+		}
+		return &v1.AddFirewallRuleResponse{Status: dryRunStatus, Diff: diff}, nil
 	}
 
-	if err := h.serviceMgr.firewall.AddRule(rule); err != nil {
+	if err := h.serviceMgr.firewall.AddRule(ctx, rule); err != nil {
 		return nil, status.Errorf(codes.Internal, "add rule: %v", err)
 	}
 
@@ -159,7 +491,15 @@ func (h *ServicesHandler) AddRule(ctx context.Context, req *v1.AddFirewallRuleRe
 }
 
 func (h *ServicesHandler) AllowPort(ctx context.Context, req *v1.AllowPortRequest) (*v1.AllowPortResponse, error) {
-	if err := h.serviceMgr.Firewall().AllowPort(int(req.Port), req.Proto); err != nil {
+	if req.DryRun {
+		diff, err := h.serviceMgr.Firewall().PlanAllowPort(ctx, int(req.Port), req.Proto)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan rule: %v", err)
+		}
+		return &v1.AllowPortResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Firewall().AllowPort(ctx, int(req.Port), req.Proto); err != nil {
 		return nil, status.Errorf(codes.Internal, "allow port: %v", err)
 	}
 
@@ -168,9 +508,94 @@ func (h *ServicesHandler) AllowPort(ctx context.Context, req *v1.AllowPortReques
 	}, nil
 }
 
+func (h *ServicesHandler) DenyPort(ctx context.Context, req *v1.DenyPortRequest) (*v1.DenyPortResponse, error) {
+	if req.DryRun {
+		diff, err := h.serviceMgr.Firewall().PlanDenyPort(ctx, int(req.Port), req.Proto)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan rule: %v", err)
+		}
+		return &v1.DenyPortResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Firewall().DenyPort(ctx, int(req.Port), req.Proto); err != nil {
+		return nil, status.Errorf(codes.Internal, "deny port: %v", err)
+	}
+
+	return &v1.DenyPortResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) DeleteRule(ctx context.Context, req *v1.DeleteFirewallRuleRequest) (*v1.DeleteFirewallRuleResponse, error) {
+	if req.RuleNumber < 1 {
+		return nil, status.Error(codes.InvalidArgument, "rule numbers start at 1")
+	}
+	if err := h.serviceMgr.Firewall().DeleteRule(ctx, int(req.RuleNumber)); err != nil {
+		return nil, status.Errorf(codes.Internal, "delete rule: %v", err)
+	}
+
+	return &v1.DeleteFirewallRuleResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListRules(ctx context.Context, req *v1.ListFirewallRulesRequest) (*v1.ListFirewallRulesResponse, error) {
+	rules, err := h.serviceMgr.Firewall().ListRules(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list rules: %v", err)
+	}
+
+	return &v1.ListFirewallRulesResponse{
+		Rules: rules,
+	}, nil
+}
+
+func (h *ServicesHandler) Enable(ctx context.Context, req *v1.EnableFirewallRequest) (*v1.EnableFirewallResponse, error) {
+	if err := h.serviceMgr.Firewall().Enable(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "enable firewall: %v", err)
+	}
+
+	return &v1.EnableFirewallResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) Disable(ctx context.Context, req *v1.DisableFirewallRequest) (*v1.DisableFirewallResponse, error) {
+	if err := h.serviceMgr.Firewall().Disable(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "disable firewall: %v", err)
+	}
+
+	return &v1.DisableFirewallResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) RemoveRuleSet(ctx context.Context, req *v1.RemoveFirewallRuleSetRequest) (*v1.RemoveFirewallRuleSetResponse, error) {
+	if req.DryRun {
+		diff, err := h.serviceMgr.Firewall().PlanRemoveRuleSet(ctx, req.Name)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan rule set: %v", err)
+		}
+		return &v1.RemoveFirewallRuleSetResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	removed, err := h.serviceMgr.Firewall().RemoveRuleSet(ctx, req.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "remove rule set: %v", err)
+	}
+	if removed == 0 {
+		return nil, status.Errorf(codes.NotFound, "no rules in set %s", req.Name)
+	}
+
+	return &v1.RemoveFirewallRuleSetResponse{
+		Status:  "success",
+		Removed: int32(removed),
+	}, nil
+}
+
 // ACME Handlers
 func (h *ServicesHandler) ObtainCertificate(ctx context.Context, req *v1.ObtainCertificateRequest) (*v1.ObtainCertificateResponse, error) {
-	cert, err := h.serviceMgr.ACME().ObtainCertificate(req.Domain)
+	cert, err := h.serviceMgr.ACME().ObtainCertificateFor(ctx, req.Domain, req.Email)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "obtain certificate: %v", err)
 	}
@@ -185,8 +610,18 @@ func (h *ServicesHandler) ObtainCertificate(ctx context.Context, req *v1.ObtainC
 	}, nil
 }
 
+func (h *ServicesHandler) RenewCertificate(ctx context.Context, req *v1.RenewCertificateRequest) (*v1.RenewCertificateResponse, error) {
+	if err := h.serviceMgr.ACME().RenewCertificate(ctx, req.Domain); err != nil {
+		return nil, status.Errorf(codes.Internal, "renew certificate: %v", err)
+	}
+
+	return &v1.RenewCertificateResponse{
+		Status: "success",
+	}, nil
+}
+
 func (h *ServicesHandler) RenewAll(ctx context.Context, req *v1.RenewAllCertificatesRequest) (*v1.RenewAllCertificatesResponse, error) {
-	if err := h.serviceMgr.ACME().RenewAllCertificates(); err != nil {
+	if err := h.serviceMgr.ACME().RenewAllCertificates(ctx); err != nil {
 		return nil, status.Errorf(codes.Internal, "renew all: %v", err)
 	}
 
@@ -195,9 +630,39 @@ func (h *ServicesHandler) RenewAll(ctx context.Context, req *v1.RenewAllCertific
 	}, nil
 }
 
+func (h *ServicesHandler) RevokeCertificate(ctx context.Context, req *v1.RevokeCertificateRequest) (*v1.RevokeCertificateResponse, error) {
+	if err := h.serviceMgr.ACME().RevokeCertificate(ctx, req.Domain); err != nil {
+		return nil, status.Errorf(codes.Internal, "revoke certificate: %v", err)
+	}
+
+	return &v1.RevokeCertificateResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListCertificates(ctx context.Context, req *v1.ListCertificatesRequest) (*v1.ListCertificatesResponse, error) {
+	certs, err := h.serviceMgr.ACME().ListCertificates(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list certificates: %v", err)
+	}
+
+	resp := &v1.ListCertificatesResponse{}
+	for _, cert := range certs {
+		resp.Certificates = append(resp.Certificates, &v1.Certificate{
+			Domain:    cert.Domain,
+			CertPath:  cert.CertPath,
+			KeyPath:   cert.KeyPath,
+			ExpiresAt: cert.ExpiresAt,
+			IssuedAt:  cert.IssuedAt,
+			Issuer:    cert.Issuer,
+		})
+	}
+	return resp, nil
+}
+
 // Host Environment Handlers
 func (h *ServicesHandler) GetHostInfo(ctx context.Context, req *v1.GetHostInfoRequest) (*v1.GetHostInfoResponse, error) {
-	info, err := h.serviceMgr.Environment().GetHostInfo()
+	info, err := h.serviceMgr.Environment().GetHostInfo(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "get host info: %v", err)
 	}
@@ -215,7 +680,7 @@ func (h *ServicesHandler) GetHostInfo(ctx context.Context, req *v1.GetHostInfoRe
 }
 
 func (h *ServicesHandler) InstallPackage(ctx context.Context, req *v1.InstallPackageRequest) (*v1.InstallPackageResponse, error) {
-	if err := h.serviceMgr.Environment().InstallPackage(req.PackageName); err != nil {
+	if err := h.serviceMgr.Environment().InstallPackage(ctx, req.PackageName); err != nil {
 		return nil, status.Errorf(codes.Internal, "install package: %v", err)
 	}
 
@@ -224,44 +689,842 @@ func (h *ServicesHandler) InstallPackage(ctx context.Context, req *v1.InstallPac
 	}, nil
 }
 
-// Complete Service Deployment Handler
-func (h *ServicesHandler) DeployWebService(req *v1.DeployWebServiceRequest, stream v1.ServiceDeploymentService_DeployWebServiceServer) error {
-	ctx := stream.Context()
+func (h *ServicesHandler) RemovePackage(ctx context.Context, req *v1.RemovePackageRequest) (*v1.RemovePackageResponse, error) {
+	if err := h.serviceMgr.Environment().RemovePackage(ctx, req.PackageName); err != nil {
+		return nil, status.Errorf(codes.Internal, "remove package: %v", err)
+	}
 
-	// Send initial event
-	stream.Send(&v1.ServiceOperationEvent{
-		OperationId: generateOperationID(),
-		State:       "RUNNING",
-		Message:     "Starting web service deployment",
-	})
+	return &v1.RemovePackageResponse{
+		Status: "success",
+	}, nil
+}
 
-	config := &WebServiceConfig{
-		Name:        req.Name,
-		Description: req.Description,
-		Domain:      req.Domain,
-		Port:        int(req.Port),
-		Command:     req.Command,
-		WorkingDir:  req.WorkingDir,
-		User:        req.User,
-		SSL:         req.Ssl,
-		Environment: req.Environment,
+func (h *ServicesHandler) UpdatePackages(ctx context.Context, req *v1.UpdatePackagesRequest) (*v1.UpdatePackagesResponse, error) {
+	if err := h.serviceMgr.Environment().UpdatePackages(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "update packages: %v", err)
 	}
 
-	// Stream progress updates
-	if err := h.serviceMgr.DeployWebService(ctx, config); err != nil {
-		stream.Send(&v1.ServiceOperationEvent{
-			State: "FAILED",
-			Error: err.Error(),
-		})
-		return status.Errorf(codes.Internal, "deploy failed: %v", err)
+	return &v1.UpdatePackagesResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListPackages(ctx context.Context, req *v1.ListPackagesRequest) (*v1.ListPackagesResponse, error) {
+	packages, err := h.serviceMgr.Environment().ListPackages(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list packages: %v", err)
 	}
 
-	stream.Send(&v1.ServiceOperationEvent{
-		State:   "COMPLETED",
-		Message: "Web service deployed successfully",
-	})
+	resp := &v1.ListPackagesResponse{}
+	for _, pkg := range packages {
+		resp.Packages = append(resp.Packages, pkg.Name+" "+pkg.Version)
+	}
+	return resp, nil
+}
 
-	return nil
+// sysctlKey matches a dotted kernel parameter, which also names the file
+// it persists to
+var sysctlKey = regexp.MustCompile(`^[a-z0-9_]+(\.[A-Za-z0-9_-]+)+$`)
+
+func (h *ServicesHandler) SetSysctl(ctx context.Context, req *v1.SetSysctlRequest) (*v1.SetSysctlResponse, error) {
+	if !sysctlKey.MatchString(req.Key) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid sysctl key %q: use the dotted form, such as net.ipv4.ip_forward", req.Key)
+	}
+	if strings.ContainsAny(req.Value, "\r\n") {
+		return nil, status.Error(codes.InvalidArgument, "sysctl values are one line")
+	}
+	if err := h.serviceMgr.Environment().SetSysctl(ctx, req.Key, req.Value); err != nil {
+		return nil, status.Errorf(codes.Internal, "set sysctl: %v", err)
+	}
+	if req.Persist {
+		if err := h.serviceMgr.Environment().PersistSysctl(ctx, req.Key, req.Value, req.Force); err != nil {
+			return nil, changeStatus("persist sysctl", err)
+		}
+	}
+
+	return &v1.SetSysctlResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) GetSysctl(ctx context.Context, req *v1.GetSysctlRequest) (*v1.GetSysctlResponse, error) {
+	value, err := h.serviceMgr.Environment().GetSysctl(ctx, req.Key)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "get sysctl %s: %v", req.Key, err)
+	}
+
+	return &v1.GetSysctlResponse{
+		Value: value,
+	}, nil
+}
+
+// timeServer matches an NTP server or pool name or address, keeping it to
+// one directive in chrony's sources file
+var timeServer = regexp.MustCompile(`^[A-Za-z0-9.:-]+$`)
+
+func (h *ServicesHandler) GetTimeSync(ctx context.Context, req *v1.GetTimeSyncRequest) (*v1.GetTimeSyncResponse, error) {
+	ts, err := h.serviceMgr.Environment().GetTimeSync(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get time sync: %v", err)
+	}
+
+	return &v1.GetTimeSyncResponse{
+		Daemon:        ts.Daemon,
+		Synchronized:  ts.Synchronized,
+		OffsetSeconds: ts.OffsetSeconds,
+		Stratum:       int32(ts.Stratum),
+		Servers:       ts.Servers,
+	}, nil
+}
+
+func (h *ServicesHandler) ConfigureTimeSync(ctx context.Context, req *v1.ConfigureTimeSyncRequest) (*v1.ConfigureTimeSyncResponse, error) {
+	if len(req.Servers) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one server is required")
+	}
+	for _, server := range req.Servers {
+		if !timeServer.MatchString(server) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid server %q", server)
+		}
+	}
+	if err := h.serviceMgr.Environment().ConfigureTimeSync(ctx, req.Servers, req.Step, req.Force); err != nil {
+		return nil, changeStatus("configure time sync", err)
+	}
+
+	return &v1.ConfigureTimeSyncResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) GetPatchStatus(ctx context.Context, req *v1.GetPatchStatusRequest) (*v1.PatchStatus, error) {
+	ps, err := h.serviceMgr.Environment().GetPatchStatus(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get patch status: %v", err)
+	}
+
+	resp := &v1.PatchStatus{
+		PackageManager:  ps.PackageManager,
+		Updates:         int32(ps.Updates),
+		RebootRequired:  ps.RebootRequired,
+		RebootPackages:  ps.RebootPackages,
+		RunningKernel:   ps.RunningKernel,
+		InstalledKernel: ps.InstalledKernel,
+		CheckedAt:       timestamppb.Now(),
+	}
+	for _, u := range ps.SecurityUpdates {
+		resp.SecurityUpdates = append(resp.SecurityUpdates, &v1.PackageUpdate{
+			Name:             u.Name,
+			CurrentVersion:   u.Current,
+			AvailableVersion: u.Available,
+		})
+	}
+	return resp, nil
+}
+
+// userName matches the names useradd accepts by default, which also keeps
+// user and group names to one argument
+var userName = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+func (h *ServicesHandler) GetUser(ctx context.Context, req *v1.GetUserRequest) (*v1.GetUserResponse, error) {
+	if !userName.MatchString(req.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name %q", req.Name)
+	}
+	user, err := h.serviceMgr.Environment().GetUser(ctx, req.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get user: %v", err)
+	}
+
+	return &v1.GetUserResponse{
+		Exists:         user.Exists,
+		Shell:          user.Shell,
+		Home:           user.Home,
+		Groups:         user.Groups,
+		AuthorizedKeys: user.AuthorizedKeys,
+	}, nil
+}
+
+func (h *ServicesHandler) EnsureUser(ctx context.Context, req *v1.EnsureUserRequest) (*v1.EnsureUserResponse, error) {
+	if !userName.MatchString(req.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name %q", req.Name)
+	}
+	for _, group := range req.Groups {
+		if !userName.MatchString(group) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid group name %q", group)
+		}
+	}
+	if req.Shell != "" && (!strings.HasPrefix(req.Shell, "/") || strings.ContainsAny(req.Shell, " \t\r\n")) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid shell %q: want an absolute path", req.Shell)
+	}
+	for _, key := range req.AuthorizedKeys {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "\r\n") {
+			return nil, status.Error(codes.InvalidArgument, "authorized keys are one non-empty line each")
+		}
+	}
+	if err := h.serviceMgr.Environment().EnsureUser(ctx, req.Name, req.Shell, req.Groups, req.AuthorizedKeys, req.Force); err != nil {
+		return nil, changeStatus("ensure user", err)
+	}
+
+	return &v1.EnsureUserResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListSysctlProfiles(ctx context.Context, req *v1.ListSysctlProfilesRequest) (*v1.ListSysctlProfilesResponse, error) {
+	applied := make(map[string]bool)
+	for _, name := range h.serviceMgr.Environment().AppliedSysctlProfiles() {
+		applied[name] = true
+	}
+
+	resp := &v1.ListSysctlProfilesResponse{}
+	for _, p := range environment.SysctlProfiles {
+		resp.Profiles = append(resp.Profiles, &v1.SysctlProfile{
+			Name:        p.Name,
+			Description: p.Description,
+			Settings:    p.Settings,
+			Applied:     applied[p.Name],
+		})
+	}
+	return resp, nil
+}
+
+func (h *ServicesHandler) ApplySysctlProfile(ctx context.Context, req *v1.ApplySysctlProfileRequest) (*v1.ApplySysctlProfileResponse, error) {
+	known := false
+	for _, p := range environment.SysctlProfiles {
+		known = known || p.Name == req.Name
+	}
+	if !known {
+		return nil, status.Errorf(codes.NotFound, "unknown sysctl profile %q", req.Name)
+	}
+
+	if req.Remove {
+		if err := h.serviceMgr.Environment().RemoveSysctlProfile(ctx, req.Name, req.Force); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, status.Errorf(codes.NotFound, "sysctl profile %s is not applied", req.Name)
+			}
+			return nil, changeStatus("remove sysctl profile", err)
+		}
+		return &v1.ApplySysctlProfileResponse{Status: "success"}, nil
+	}
+	if err := h.serviceMgr.Environment().ApplySysctlProfile(ctx, req.Name, req.Force); err != nil {
+		return nil, changeStatus("apply sysctl profile", err)
+	}
+
+	return &v1.ApplySysctlProfileResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) VerifySysctls(ctx context.Context, req *v1.VerifySysctlsRequest) (*v1.VerifySysctlsResponse, error) {
+	checks, err := h.serviceMgr.Environment().VerifySysctls(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "verify sysctls: %v", err)
+	}
+
+	resp := &v1.VerifySysctlsResponse{}
+	for _, c := range checks {
+		resp.Checks = append(resp.Checks, &v1.SysctlCheck{
+			Key:       c.Key,
+			Persisted: c.Persisted,
+			Runtime:   c.Runtime,
+			File:      c.File,
+			Ok:        c.OK,
+		})
+	}
+	return resp, nil
+}
+
+func (h *ServicesHandler) GetLimits(ctx context.Context, req *v1.GetLimitsRequest) (*v1.GetLimitsResponse, error) {
+	limits, err := h.serviceMgr.Environment().GetLimits()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get limits: %v", err)
+	}
+
+	resp := &v1.GetLimitsResponse{}
+	for _, l := range limits {
+		resp.Limits = append(resp.Limits, &v1.Limit{
+			Domain:  l.Domain,
+			Type:    l.Type,
+			Item:    l.Item,
+			Value:   l.Value,
+			File:    l.File,
+			Managed: l.Managed,
+		})
+	}
+	return resp, nil
+}
+
+var (
+	// limitsName keeps a limits.d file name to one path element
+	limitsName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	// limitDomain matches *, a user or an @group
+	limitDomain = regexp.MustCompile(`^(\*|@?[a-z_][a-z0-9_-]{0,31})$`)
+	limitValue  = regexp.MustCompile(`^(-?[0-9]+|unlimited|infinity)$`)
+	// limitItems are the items pam_limits knows
+	limitItems = map[string]bool{
+		"core": true, "data": true, "fsize": true, "memlock": true, "nofile": true, "rss": true,
+		"stack": true, "cpu": true, "nproc": true, "as": true, "maxlogins": true, "maxsyslogins": true,
+		"priority": true, "locks": true, "sigpending": true, "msgqueue": true, "nice": true, "rtprio": true,
+	}
+)
+
+func (h *ServicesHandler) SetLimits(ctx context.Context, req *v1.SetLimitsRequest) (*v1.SetLimitsResponse, error) {
+	if !limitsName.MatchString(req.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limits name %q", req.Name)
+	}
+	limits := make([]environment.Limit, 0, len(req.Limits))
+	for _, l := range req.Limits {
+		switch {
+		case !limitDomain.MatchString(l.Domain):
+			return nil, status.Errorf(codes.InvalidArgument, "invalid limit domain %q: want *, a user or @group", l.Domain)
+		case l.Type != "soft" && l.Type != "hard" && l.Type != "-":
+			return nil, status.Errorf(codes.InvalidArgument, "invalid limit type %q: use soft, hard or -", l.Type)
+		case !limitItems[l.Item]:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown limit item %q", l.Item)
+		case !limitValue.MatchString(l.Value):
+			return nil, status.Errorf(codes.InvalidArgument, "invalid limit value %q", l.Value)
+		}
+		limits = append(limits, environment.Limit{Domain: l.Domain, Type: l.Type, Item: l.Item, Value: l.Value})
+	}
+
+	if err := h.serviceMgr.Environment().SetLimits(ctx, req.Name, limits, req.Force); err != nil {
+		if len(limits) == 0 && errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "no limits named %s", req.Name)
+		}
+		return nil, changeStatus("set limits", err)
+	}
+
+	return &v1.SetLimitsResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) GetDockerDaemon(ctx context.Context, req *v1.GetDockerDaemonRequest) (*v1.GetDockerDaemonResponse, error) {
+	state, err := h.serviceMgr.Environment().GetDockerDaemon(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get docker daemon: %v", err)
+	}
+
+	settings := &v1.DockerDaemonSettings{
+		LogDriver:       state.Settings.LogDriver,
+		LogOpts:         state.Settings.LogOpts,
+		RegistryMirrors: state.Settings.RegistryMirrors,
+		StorageDriver:   state.Settings.StorageDriver,
+		StorageOpts:     state.Settings.StorageOpts,
+	}
+	for _, pool := range state.Settings.DefaultAddressPools {
+		settings.DefaultAddressPools = append(settings.DefaultAddressPools, &v1.DockerAddressPool{Base: pool.Base, Size: int32(pool.Size)})
+	}
+	return &v1.GetDockerDaemonResponse{
+		Settings:             settings,
+		Content:              state.Content,
+		Running:              state.Running,
+		RunningLogDriver:     state.LogDriver,
+		RunningStorageDriver: state.StorageDriver,
+	}, nil
+}
+
+var (
+	dockerDriver    = regexp.MustCompile(`^[a-z0-9][a-z0-9._/:-]*$`)
+	dockerOptionKey = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+)
+
+// checkDockerDaemon validates settings before they reach daemon.json,
+// where a mistake keeps Docker from starting
+func checkDockerDaemon(s *v1.DockerDaemonSettings) error {
+	if s.LogDriver != "" && !dockerDriver.MatchString(s.LogDriver) {
+		return fmt.Errorf("invalid log driver %q", s.LogDriver)
+	}
+	if len(s.LogOpts) > 0 && s.LogDriver == "" {
+		return fmt.Errorf("log options need a log driver")
+	}
+	for key := range s.LogOpts {
+		if !dockerOptionKey.MatchString(key) {
+			return fmt.Errorf("invalid log option %q", key)
+		}
+	}
+	for _, mirror := range s.RegistryMirrors {
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid registry mirror %q: want an http or https URL", mirror)
+		}
+	}
+	if s.StorageDriver != "" && !dockerDriver.MatchString(s.StorageDriver) {
+		return fmt.Errorf("invalid storage driver %q", s.StorageDriver)
+	}
+	if len(s.StorageOpts) > 0 && s.StorageDriver == "" {
+		return fmt.Errorf("storage options need a storage driver")
+	}
+	for _, opt := range s.StorageOpts {
+		if key, _, ok := strings.Cut(opt, "="); !ok || !dockerOptionKey.MatchString(key) {
+			return fmt.Errorf("invalid storage option %q: want key=value", opt)
+		}
+	}
+
+	var pools []netip.Prefix
+	for _, pool := range s.DefaultAddressPools {
+		base, err := netip.ParsePrefix(pool.Base)
+		if err != nil || base != base.Masked() {
+			return fmt.Errorf("invalid address pool base %q: want a network such as 10.200.0.0/16", pool.Base)
+		}
+		if int(pool.Size) < base.Bits() || int(pool.Size) > base.Addr().BitLen() {
+			return fmt.Errorf("invalid size %d for address pool %s: want %d to %d", pool.Size, pool.Base, base.Bits(), base.Addr().BitLen())
+		}
+		for _, other := range pools {
+			if other.Overlaps(base) {
+				return fmt.Errorf("address pools %s and %s overlap", other, base)
+			}
+		}
+		pools = append(pools, base)
+	}
+	return nil
+}
+
+func (h *ServicesHandler) SetDockerDaemon(ctx context.Context, req *v1.SetDockerDaemonRequest) (*v1.SetDockerDaemonResponse, error) {
+	s := req.Settings
+	if s == nil {
+		s = &v1.DockerDaemonSettings{}
+	}
+	if err := checkDockerDaemon(s); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	settings := environment.DockerDaemon{
+		LogDriver:       s.LogDriver,
+		LogOpts:         s.LogOpts,
+		RegistryMirrors: s.RegistryMirrors,
+		StorageDriver:   s.StorageDriver,
+		StorageOpts:     s.StorageOpts,
+	}
+	for _, pool := range s.DefaultAddressPools {
+		settings.DefaultAddressPools = append(settings.DefaultAddressPools, environment.AddressPool{Base: pool.Base, Size: int(pool.Size)})
+	}
+
+	change, err := h.serviceMgr.Environment().ApplyDockerDaemon(ctx, settings, req.Restart, req.DryRun)
+	if errors.Is(err, environment.ErrDockerUnhealthy) {
+		return nil, status.Errorf(codes.Aborted, "set docker daemon: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "set docker daemon: %v", err)
+	}
+	if req.DryRun {
+		return &v1.SetDockerDaemonResponse{Status: dryRunStatus, Changed: change.Changed, Diff: change.Diff}, nil
+	}
+	return &v1.SetDockerDaemonResponse{
+		Status:    "success",
+		Changed:   change.Changed,
+		Restarted: change.Restarted,
+	}, nil
+}
+
+// Cron Handlers
+func (h *ServicesHandler) AddCronJob(ctx context.Context, req *v1.AddCronJobRequest) (*v1.AddCronJobResponse, error) {
+	job := req.Job
+	if job == nil {
+		return nil, status.Error(codes.InvalidArgument, "job is required")
+	}
+	if err := checkName(job.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(strings.Fields(job.Schedule)) != 5 && !strings.HasPrefix(job.Schedule, "@") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schedule %q: use five cron fields or a macro such as @daily", job.Schedule)
+	}
+	if strings.ContainsAny(job.Command, "\n\r") {
+		return nil, status.Error(codes.InvalidArgument, "the command must be a single line")
+	}
+
+	cronJob := &cron.CronJob{
+		Name:     job.Name,
+		Schedule: job.Schedule,
+		Command:  job.Command,
+		User:     job.User,
+		Enabled:  true,
+	}
+	if req.DryRun {
+		diff, err := h.serviceMgr.Cron().PlanCronJob(cronJob)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan cron job: %v", err)
+		}
+		return &v1.AddCronJobResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Cron().AddCronJob(ctx, cronJob, req.Force); err != nil {
+		return nil, changeStatus("add cron job", err)
+	}
+
+	return &v1.AddCronJobResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) RemoveCronJob(ctx context.Context, req *v1.RemoveCronJobRequest) (*v1.RemoveCronJobResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.DryRun {
+		diff, err := h.serviceMgr.Cron().PlanRemoveCronJob(req.Name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "cron job %s not found", req.Name)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan cron job removal: %v", err)
+		}
+		return &v1.RemoveCronJobResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.Cron().RemoveCronJob(ctx, req.Name, req.Force); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "cron job %s not found", req.Name)
+		}
+		return nil, changeStatus("remove cron job", err)
+	}
+
+	return &v1.RemoveCronJobResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListCronJobs(ctx context.Context, req *v1.ListCronJobsRequest) (*v1.ListCronJobsResponse, error) {
+	jobs, err := h.serviceMgr.Cron().ListCronJobs()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list cron jobs: %v", err)
+	}
+
+	resp := &v1.ListCronJobsResponse{}
+	for _, job := range jobs {
+		resp.Jobs = append(resp.Jobs, &v1.CronJob{
+			Name:     job.Name,
+			Schedule: job.Schedule,
+			Command:  job.Command,
+			User:     job.User,
+		})
+	}
+	return resp, nil
+}
+
+// DNS Handlers
+func (h *ServicesHandler) CreateZone(ctx context.Context, req *v1.CreateZoneRequest) (*v1.CreateZoneResponse, error) {
+	if err := checkName(req.Domain); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ttl := int(req.Ttl)
+	if ttl <= 0 {
+		ttl = defaultDNSTTL
+	}
+	nameservers := req.Nameservers
+	if len(nameservers) == 0 {
+		nameservers = []string{"ns1." + req.Domain}
+	}
+	admin := req.Admin
+	if admin == "" {
+		admin = "hostmaster." + req.Domain
+	}
+	serial, _ := strconv.Atoi(time.Now().Format("20060102") + "01")
+
+	zone := &dns.DNSZone{
+		Domain: req.Domain,
+		TTL:    ttl,
+		SOA: dns.SOARecord{
+			Primary:    nameservers[0],
+			Admin:      strings.Replace(admin, "@", ".", 1),
+			Serial:     serial,
+			Refresh:    3600,
+			Retry:      600,
+			Expire:     604800,
+			MinimumTTL: 300,
+		},
+		NS: nameservers,
+	}
+	if req.DryRun {
+		diff, err := h.serviceMgr.DNS().PlanZone(zone)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "plan zone: %v", err)
+		}
+		return &v1.CreateZoneResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.DNS().CreateZone(ctx, zone, req.Force); err != nil {
+		return nil, changeStatus("create zone", err)
+	}
+
+	return &v1.CreateZoneResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) AddARecord(ctx context.Context, req *v1.AddARecordRequest) (*v1.AddARecordResponse, error) {
+	if err := checkName(req.Domain); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if ip := net.ParseIP(req.Ip); ip == nil || ip.To4() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid IPv4 address %q", req.Ip)
+	}
+	if err := checkRecordName(req.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.DryRun {
+		diff, err := h.serviceMgr.DNS().PlanARecord(req.Domain, req.Name, req.Ip, dnsTTL(req.Ttl))
+		if err != nil {
+			return nil, dnsRecordStatus(req.Domain, err)
+		}
+		return &v1.AddARecordResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.DNS().AddARecord(ctx, req.Domain, req.Name, req.Ip, dnsTTL(req.Ttl), req.Force); err != nil {
+		return nil, dnsRecordStatus(req.Domain, err)
+	}
+
+	return &v1.AddARecordResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) AddCNAMERecord(ctx context.Context, req *v1.AddCNAMERecordRequest) (*v1.AddCNAMERecordResponse, error) {
+	if err := checkName(req.Domain); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := checkRecordName(req.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := checkRecordName(req.Target); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.DryRun {
+		diff, err := h.serviceMgr.DNS().PlanCNAMERecord(req.Domain, req.Name, req.Target, dnsTTL(req.Ttl))
+		if err != nil {
+			return nil, dnsRecordStatus(req.Domain, err)
+		}
+		return &v1.AddCNAMERecordResponse{Status: dryRunStatus, Diff: diff}, nil
+	}
+
+	if err := h.serviceMgr.DNS().AddCNAMERecord(ctx, req.Domain, req.Name, req.Target, dnsTTL(req.Ttl), req.Force); err != nil {
+		return nil, dnsRecordStatus(req.Domain, err)
+	}
+
+	return &v1.AddCNAMERecordResponse{
+		Status: "success",
+	}, nil
+}
+
+func (h *ServicesHandler) ListZones(ctx context.Context, req *v1.ListZonesRequest) (*v1.ListZonesResponse, error) {
+	zones, err := h.serviceMgr.DNS().ListZones()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list zones: %v", err)
+	}
+	return &v1.ListZonesResponse{Zones: zones}, nil
+}
+
+// dryRunStatus is the status of a request that only diffed its change
+const dryRunStatus = "dry-run"
+
+// changeStatus maps a failed host change to its status. A file edited
+// outside Mandau is a precondition the caller can override with force.
+func changeStatus(op string, err error) error {
+	if errors.Is(err, plugin.ErrEdited) {
+		return status.Errorf(codes.FailedPrecondition, "%s: %v; force to replace it", op, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", op, err)
+}
+
+// defaultDNSTTL applies to zones and records created without a TTL
+const defaultDNSTTL = 3600
+
+func dnsTTL(ttl int32) int {
+	if ttl <= 0 {
+		return defaultDNSTTL
+	}
+	return int(ttl)
+}
+
+// checkRecordName keeps a record field from spilling into the rest of the
+// zone file
+func checkRecordName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\r\n;") {
+		return fmt.Errorf("invalid record name %q", name)
+	}
+	return nil
+}
+
+// dnsRecordStatus reports a record added to a zone that does not exist as
+// not found
+func dnsRecordStatus(domain string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return status.Errorf(codes.NotFound, "zone %s not found", domain)
+	}
+	return changeStatus("add record", err)
+}
+
+// Complete Service Deployment Handlers
+func (h *ServicesHandler) DeployWebService(req *v1.DeployWebServiceRequest, stream v1.ServiceDeploymentService_DeployWebServiceServer) error {
+	return h.deploy(stream, &WebServiceConfig{
+		Name:        req.Name,
+		Description: req.Description,
+		Domain:      req.Domain,
+		Port:        int(req.Port),
+		Command:     req.Command,
+		WorkingDir:  req.WorkingDir,
+		User:        req.User,
+		SSL:         req.Ssl,
+		Environment: req.Environment,
+	})
+}
+
+func (h *ServicesHandler) DeployStaticSite(req *v1.DeployStaticSiteRequest, stream v1.ServiceDeploymentService_DeployStaticSiteServer) error {
+	return h.deploy(stream, &StaticSiteConfig{
+		Name:   req.Name,
+		Domain: req.Domain,
+		Root:   req.Root,
+		Index:  req.Index,
+		SSL:    req.Ssl,
+	})
+}
+
+func (h *ServicesHandler) DeployDatabase(req *v1.DeployDatabaseRequest, stream v1.ServiceDeploymentService_DeployDatabaseServer) error {
+	return h.deploy(stream, &DatabaseConfig{
+		Name:           req.Name,
+		Engine:         req.Engine,
+		Version:        req.Version,
+		Port:           int(req.Port),
+		DataDir:        req.DataDir,
+		Password:       req.Password,
+		BackupSchedule: req.BackupSchedule,
+		BackupDir:      req.BackupDir,
+		RetentionDays:  int(req.BackupRetentionDays),
+	})
+}
+
+func (h *ServicesHandler) DeployWorker(req *v1.DeployWorkerRequest, stream v1.ServiceDeploymentService_DeployWorkerServer) error {
+	return h.deploy(stream, &WorkerConfig{
+		Name:        req.Name,
+		Description: req.Description,
+		Command:     req.Command,
+		WorkingDir:  req.WorkingDir,
+		User:        req.User,
+		Environment: req.Environment,
+	})
+}
+
+// deploy runs a recipe, streaming its steps as events
+func (h *ServicesHandler) deploy(stream grpc.ServerStreamingServer[v1.ServiceOperationEvent], recipe Recipe) error {
+	kind := recipe.Manifest().Kind
+	send := h.sender(stream)
+
+	// The first step event shows the recipe passed its checks
+	var started bool
+	err := h.serviceMgr.Deploy(stream.Context(), recipe, func(e StepEvent) {
+		if !started {
+			started = true
+			send(StepRunning, "Starting "+kind+" deployment", 0, "")
+		}
+		send(e.State, e.Step, e.Progress, e.Error)
+	})
+	if err != nil {
+		if !started {
+			return deploymentStatus("deploy "+kind, err)
+		}
+		send(StepFailed, capitalize(kind)+" deployment rolled back", 0, err.Error())
+		return status.Errorf(codes.Internal, "deploy failed: %v", err)
+	}
+
+	send(StepCompleted, capitalize(kind)+" deployed successfully", 100, "")
+	return nil
+}
+
+// RemoveWebService removes a deployment of any kind, streaming the removal
+// of each resource its manifest records
+func (h *ServicesHandler) RemoveWebService(req *v1.RemoveWebServiceRequest, stream v1.ServiceDeploymentService_RemoveWebServiceServer) error {
+	send := h.sender(stream)
+
+	var started bool
+	err := h.serviceMgr.Remove(stream.Context(), req.Name, req.Force, func(e StepEvent) {
+		started = true
+		send(e.State, e.Step, e.Progress, e.Error)
+	})
+	if err != nil {
+		if !started {
+			return deploymentStatus("remove", err)
+		}
+		return status.Errorf(codes.Internal, "remove failed: %v", err)
+	}
+
+	send(StepCompleted, req.Name+" removed", 100, "")
+	return nil
+}
+
+func (h *ServicesHandler) ListDeployedServices(ctx context.Context, req *v1.ListDeployedServicesRequest) (*v1.ListDeployedServicesResponse, error) {
+	manifests, err := h.serviceMgr.Deployments()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list deployments: %v", err)
+	}
+
+	resp := &v1.ListDeployedServicesResponse{}
+	for _, mf := range manifests {
+		svc := &v1.DeployedService{
+			Name:       mf.Name,
+			Kind:       mf.Kind,
+			Domain:     mf.Domain,
+			State:      mf.State,
+			DeployedAt: timestamppb.New(mf.DeployedAt),
+		}
+		for _, r := range mf.Resources {
+			svc.Resources = append(svc.Resources, &v1.DeployedResource{Kind: r.Kind, Name: r.Name})
+		}
+		resp.Services = append(resp.Services, svc)
+	}
+	return resp, nil
+}
+
+// GetDriftReport scans the managed host artifacts for drift
+func (h *ServicesHandler) GetDriftReport(ctx context.Context, req *v1.GetDriftReportRequest) (*v1.DriftReport, error) {
+	drift, err := h.serviceMgr.ScanDrift(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "scan drift: %v", err)
+	}
+
+	resp := &v1.DriftReport{ScannedAt: timestamppb.Now()}
+	for _, d := range drift {
+		resp.Drift = append(resp.Drift, &v1.HostDrift{
+			Kind:   d.Kind,
+			Name:   d.Name,
+			State:  d.State,
+			Detail: d.Detail,
+		})
+	}
+	return resp, nil
+}
+
+// sender returns a function streaming events under one operation ID
+func (h *ServicesHandler) sender(stream grpc.ServerStreamingServer[v1.ServiceOperationEvent]) func(state, message string, progress int, errMsg string) {
+	opID := generateOperationID()
+	return func(state, message string, progress int, errMsg string) {
+		stream.Send(&v1.ServiceOperationEvent{
+			OperationId: opID,
+			State:       state,
+			Timestamp:   timestamppb.Now(),
+			Message:     message,
+			Progress:    int32(progress),
+			Error:       errMsg,
+		})
+	}
+}
+
+// deploymentStatus maps an error raised before any step ran to its status
+func deploymentStatus(op string, err error) error {
+	switch {
+	case errors.Is(err, errDeploymentExists):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", op, err)
+	case errors.Is(err, errNoDeployment):
+		return status.Errorf(codes.NotFound, "%s: %v", op, err)
+	case errors.Is(err, errDeploymentBusy):
+		return status.Errorf(codes.Aborted, "%s: %v", op, err)
+	}
+	return status.Errorf(codes.FailedPrecondition, "%s: %v", op, err)
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
 // generateOperationID generates a unique operation ID
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Bad input is refused before any plugin is touched, so a handler without
+// plugins is enough here
+func TestHandlerRejectsInvalidInput(t *testing.T) {
+	h := NewServicesHandler(&ServiceManager{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"cron schedule", func() error {
+			_, err := h.AddCronJob(ctx, &v1.AddCronJobRequest{Job: &v1.CronJob{Name: "backup", Schedule: "0 3 * *", Command: "true"}})
+			return err
+		}},
+		{"multi-line cron command", func() error {
+			_, err := h.AddCronJob(ctx, &v1.AddCronJobRequest{Job: &v1.CronJob{Name: "backup", Schedule: "@daily", Command: "true\n* * * * * root evil"}})
+			return err
+		}},
+		{"cron job name", func() error {
+			_, err := h.RemoveCronJob(ctx, &v1.RemoveCronJobRequest{Name: "../passwd"})
+			return err
+		}},
+		{"A record address", func() error {
+			_, err := h.AddARecord(ctx, &v1.AddARecordRequest{Domain: "example.com", Name: "www", Ip: "::1"})
+			return err
+		}},
+		{"CNAME target", func() error {
+			_, err := h.AddCNAMERecord(ctx, &v1.AddCNAMERecordRequest{Domain: "example.com", Name: "www", Target: "a.example.com. ; x"})
+			return err
+		}},
+		{"firewall source", func() error {
+			_, err := h.AddRule(ctx, &v1.AddFirewallRuleRequest{Action: "allow", Proto: "tcp", FromIp: "10.0.0.0/33", ToPort: 22})
+			return err
+		}},
+		{"mixed address families", func() error {
+			_, err := h.AddRule(ctx, &v1.AddFirewallRuleRequest{Action: "allow", FromIp: "10.0.0.1", ToIp: "::1"})
+			return err
+		}},
+		{"rate limit", func() error {
+			_, err := h.AddRule(ctx, &v1.AddFirewallRuleRequest{Action: "allow", Proto: "tcp", ToPort: 22, RateLimit: "10/fortnight"})
+			return err
+		}},
+		{"rule set name", func() error {
+			_, err := h.AddRule(ctx, &v1.AddFirewallRuleRequest{Action: "allow", Proto: "tcp", ToPort: 22, RuleSet: "web app"})
+			return err
+		}},
+		{"no time servers", func() error {
+			_, err := h.ConfigureTimeSync(ctx, &v1.ConfigureTimeSyncRequest{})
+			return err
+		}},
+		{"time server directive", func() error {
+			_, err := h.ConfigureTimeSync(ctx, &v1.ConfigureTimeSyncRequest{Servers: []string{"pool.ntp.org iburst\nallow all"}})
+			return err
+		}},
+		{"sysctl key path", func() error {
+			_, err := h.SetSysctl(ctx, &v1.SetSysctlRequest{Key: "../../etc/passwd", Value: "1", Persist: true})
+			return err
+		}},
+		{"template name", func() error {
+			_, err := h.ListInstances(ctx, &v1.ListInstancesRequest{Template: "../worker"})
+			return err
+		}},
+		{"instance name", func() error {
+			_, err := h.SetInstanceEnvironment(ctx, &v1.SetInstanceEnvironmentRequest{Template: "worker", Instance: "1/../../x"})
+			return err
+		}},
+		{"multi-line instance environment", func() error {
+			_, err := h.SetInstanceEnvironment(ctx, &v1.SetInstanceEnvironmentRequest{Template: "worker", Instance: "1", Environment: map[string]string{"QUEUE": "a\nPATH=/tmp"}})
+			return err
+		}},
+		{"instance count", func() error {
+			_, err := h.ScaleInstances(ctx, &v1.ScaleInstancesRequest{Template: "worker", Count: 10000})
+			return err
+		}},
+		{"registry mirror", func() error {
+			_, err := h.SetDockerDaemon(ctx, &v1.SetDockerDaemonRequest{Settings: &v1.DockerDaemonSettings{RegistryMirrors: []string{"mirror.example.com"}}})
+			return err
+		}},
+		{"log options without a driver", func() error {
+			_, err := h.SetDockerDaemon(ctx, &v1.SetDockerDaemonRequest{Settings: &v1.DockerDaemonSettings{LogOpts: map[string]string{"max-size": "10m"}}})
+			return err
+		}},
+		{"address pool size", func() error {
+			_, err := h.SetDockerDaemon(ctx, &v1.SetDockerDaemonRequest{Settings: &v1.DockerDaemonSettings{
+				DefaultAddressPools: []*v1.DockerAddressPool{{Base: "10.200.0.0/16", Size: 8}},
+			}})
+			return err
+		}},
+		{"overlapping address pools", func() error {
+			_, err := h.SetDockerDaemon(ctx, &v1.SetDockerDaemonRequest{Settings: &v1.DockerDaemonSettings{
+				DefaultAddressPools: []*v1.DockerAddressPool{{Base: "10.200.0.0/16", Size: 24}, {Base: "10.200.128.0/17", Size: 24}},
+			}})
+			return err
+		}},
+		{"user name", func() error {
+			_, err := h.EnsureUser(ctx, &v1.EnsureUserRequest{Name: "deploy -o"})
+			return err
+		}},
+		{"limits file name", func() error {
+			_, err := h.SetLimits(ctx, &v1.SetLimitsRequest{Name: "../limits"})
+			return err
+		}},
+		{"limit item", func() error {
+			_, err := h.SetLimits(ctx, &v1.SetLimitsRequest{Name: "db", Limits: []*v1.Limit{{Domain: "postgres", Type: "soft", Item: "files", Value: "65536"}}})
+			return err
+		}},
+		{"limit value", func() error {
+			_, err := h.SetLimits(ctx, &v1.SetLimitsRequest{Name: "db", Limits: []*v1.Limit{{Domain: "*", Type: "-", Item: "nofile", Value: "65536\n* - nproc 1"}}})
+			return err
+		}},
+		{"authorized key lines", func() error {
+			_, err := h.EnsureUser(ctx, &v1.EnsureUserRequest{Name: "deploy", AuthorizedKeys: []string{"ssh-ed25519 AAAA\nssh-rsa BBBB"}})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		if code := status.Code(tt.call()); code != codes.InvalidArgument {
+			t.Errorf("%s: code = %v, want InvalidArgument", tt.name, code)
+		}
+	}
+}
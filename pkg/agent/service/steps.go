@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Step states reported while a deployment runs
+const (
+	StepRunning     = "RUNNING"
+	StepCompleted   = "COMPLETED"
+	StepFailed      = "FAILED"
+	StepRollingBack = "ROLLING_BACK"
+	StepRolledBack  = "ROLLED_BACK"
+)
+
+// Step is one change of a deployment. Undo compensates for Run and is nil
+// when the step leaves nothing behind to remove. Creates, when set, names
+// the resource a successful Run left for the deployment manifest; it may
+// return nil when Run found the resource already in place.
+type Step struct {
+	Name    string
+	Run     func(ctx context.Context) error
+	Undo    func(ctx context.Context) error
+	Creates func() *Resource
+}
+
+// StepEvent reports the progress of a step
+type StepEvent struct {
+	Step     string
+	State    string
+	Progress int // Percent of steps completed
+	Error    string
+}
+
+// runSteps runs steps in order. When one fails, the steps already completed
+// are undone in reverse order and the step's error is returned, along with
+// any undo that failed too. progress may be nil.
+func runSteps(ctx context.Context, steps []Step, progress func(StepEvent)) error {
+	report := func(e StepEvent) {
+		if progress != nil {
+			progress(e)
+		}
+	}
+
+	for i, step := range steps {
+		report(StepEvent{Step: step.Name, State: StepRunning, Progress: i * 100 / len(steps)})
+
+		err := ctx.Err()
+		if err == nil {
+			err = step.Run(ctx)
+		}
+		if err != nil {
+			report(StepEvent{Step: step.Name, State: StepFailed, Progress: i * 100 / len(steps), Error: err.Error()})
+			return rollback(steps[:i], report, fmt.Errorf("%s: %w", step.Name, err))
+		}
+
+		report(StepEvent{Step: step.Name, State: StepCompleted, Progress: (i + 1) * 100 / len(steps)})
+	}
+	return nil
+}
+
+// rollback undoes done in reverse order. Undo runs on a fresh context so a
+// cancelled deployment is still cleaned up.
+func rollback(done []Step, report func(StepEvent), cause error) error {
+	ctx := context.Background()
+
+	var failed []string
+	for i := len(done) - 1; i >= 0; i-- {
+		step := done[i]
+		if step.Undo == nil {
+			continue
+		}
+
+		report(StepEvent{Step: step.Name, State: StepRollingBack})
+		if err := step.Undo(ctx); err != nil {
+			report(StepEvent{Step: step.Name, State: StepFailed, Error: "rollback: " + err.Error()})
+			failed = append(failed, fmt.Sprintf("%s: %v", step.Name, err))
+			continue
+		}
+		report(StepEvent{Step: step.Name, State: StepRolledBack})
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w (rollback incomplete: %s)", cause, strings.Join(failed, "; "))
+	}
+	return cause
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunStepsRollsBackCompletedSteps(t *testing.T) {
+	var calls []string
+	step := func(name string, runErr, undoErr error, undo bool) Step {
+		s := Step{
+			Name: name,
+			Run: func(ctx context.Context) error {
+				calls = append(calls, "run "+name)
+				return runErr
+			},
+		}
+		if undo {
+			s.Undo = func(ctx context.Context) error {
+				calls = append(calls, "undo "+name)
+				return undoErr
+			}
+		}
+		return s
+	}
+
+	var states []string
+	err := runSteps(context.Background(), []Step{
+		step("unit", nil, nil, true),
+		step("cert", nil, nil, false),
+		step("vhost", nil, nil, true),
+		step("port", errors.New("ufw failed"), nil, true),
+		step("cron", nil, nil, true),
+	}, func(e StepEvent) {
+		states = append(states, e.Step+" "+e.State)
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "port: ufw failed") {
+		t.Fatalf("runSteps() error = %v, want the failed step's error", err)
+	}
+
+	want := []string{"run unit", "run cert", "run vhost", "run port", "undo vhost", "undo unit"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if last := states[len(states)-1]; last != "unit "+StepRolledBack {
+		t.Errorf("last event = %q, want unit rolled back", last)
+	}
+}
+
+func TestRunStepsReportsFailedRollback(t *testing.T) {
+	err := runSteps(context.Background(), []Step{
+		{
+			Name: "unit",
+			Run:  func(ctx context.Context) error { return nil },
+			Undo: func(ctx context.Context) error { return errors.New("busy") },
+		},
+		{
+			Name: "vhost",
+			Run:  func(ctx context.Context) error { return errors.New("nginx -t failed") },
+		},
+	}, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "rollback incomplete: unit: busy") {
+		t.Errorf("runSteps() error = %v, want the failed undo reported", err)
+	}
+}
+
+func TestRunStepsProgress(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+
+	var last StepEvent
+	err := runSteps(context.Background(), []Step{{Name: "a", Run: ok}, {Name: "b", Run: ok}}, func(e StepEvent) {
+		last = e
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.State != StepCompleted || last.Progress != 100 {
+		t.Errorf("last event = %+v, want completed at 100%%", last)
+	}
+}
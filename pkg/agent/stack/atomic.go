@@ -0,0 +1,212 @@
+package stack
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tempPrefix starts the names of files being written; a crash may leave
+// them behind
+const tempPrefix = ".mandau-write-"
+
+// previousSuffix marks the version of a stack definition file the last
+// write replaced
+const previousSuffix = ".prev"
+
+// definitionFiles are kept in their previous version when replaced
+var definitionFiles = map[string]bool{"compose.yaml": true, ".env": true, metadataFile: true}
+
+// writeTemp writes data to a temporary file next to path and flushes it to
+// disk. The caller renames it into place with commitFile or removes it.
+func writeTemp(path string, data []byte, mode os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), mode)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// commitFile renames tmp over path and flushes the directory entry. The
+// version of a definition file it replaces is kept as path.prev.
+func commitFile(tmp, path string) error {
+	if definitionFiles[filepath.Base(path)] {
+		if err := keepPrevious(path); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// writeFile replaces path with data so that it is never seen half-written
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	tmp, err := writeTemp(path, data, mode)
+	if err != nil {
+		return err
+	}
+	if err := commitFile(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeSealed is writeFile for what is sealed when encryption is on
+func (m *Manager) writeSealed(path string, data []byte) error {
+	sealed, err := m.sealer.Seal(data)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, sealed, 0600)
+}
+
+// keepPrevious links path, if it exists, as path.prev, or copies it where
+// links are not supported
+func keepPrevious(path string) error {
+	prev := path + previousSuffix
+	if err := os.Remove(prev); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("keep previous version: %w", err)
+	}
+	err := os.Link(path, prev)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("keep previous version: %w", err)
+	}
+	tmp, err := writeTemp(prev, data, 0600)
+	if err != nil {
+		return fmt.Errorf("keep previous version: %w", err)
+	}
+	if err := os.Rename(tmp, prev); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("keep previous version: %w", err)
+	}
+	return nil
+}
+
+// syncDir flushes the entries of dir, so renames in it survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// RecoverFiles removes what interrupted writes left in the stack
+// directories and restores definition files that cannot be read from their
+// previous version. It runs once at startup, before interrupted operations
+// are resumed.
+func (m *Manager) RecoverFiles() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries, err := os.ReadDir(m.stackRoot)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		stackPath := filepath.Join(m.stackRoot, entry.Name())
+		removeTemps(stackPath)
+		for name := range definitionFiles {
+			if restored, err := m.restorePrevious(stackPath, name); err != nil {
+				log.Printf("Cannot recover %s of stack %s: %v", name, entry.Name(), err)
+			} else if restored {
+				log.Printf("Restored %s of stack %s from its previous version", name, entry.Name())
+			}
+		}
+	}
+}
+
+// removeTemps removes the temporary files of writes cut short under
+// stackPath
+func removeTemps(stackPath string) {
+	filepath.WalkDir(stackPath, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasPrefix(d.Name(), tempPrefix) {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// restorePrevious puts back the previous version of the definition file
+// name when the current one cannot be read. Missing files are left alone,
+// as applies never remove them.
+func (m *Manager) restorePrevious(stackPath, name string) (bool, error) {
+	path := filepath.Join(stackPath, name)
+	prev := path + previousSuffix
+	if _, err := os.Stat(path); err != nil {
+		return false, nil
+	}
+	if _, err := os.Stat(prev); err != nil || m.readable(path) {
+		return false, nil
+	}
+	if !m.readable(prev) {
+		return false, fmt.Errorf("the previous version cannot be read either")
+	}
+
+	data, err := os.ReadFile(prev)
+	if err != nil {
+		return false, err
+	}
+	tmp, err := writeTemp(path, data, 0600)
+	if err != nil {
+		return false, err
+	}
+	// Renamed directly, so the good previous version is not replaced
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	return true, syncDir(stackPath)
+}
+
+// readable reports whether the definition file at path opens; the compose
+// file and metadata must parse as well
+func (m *Manager) readable(path string) bool {
+	base := strings.TrimSuffix(filepath.Base(path), previousSuffix)
+	if base == metadataFile {
+		data, err := os.ReadFile(path)
+		var md Metadata
+		return err == nil && yaml.Unmarshal(data, &md) == nil
+	}
+
+	data, err := m.sealer.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if base == "compose.yaml" {
+		var doc map[string]any
+		return yaml.Unmarshal(data, &doc) == nil && doc != nil
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempFiles returns the temporary files of writes left under dir
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var found []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasPrefix(info.Name(), tempPrefix) {
+			found = append(found, path)
+		}
+		return nil
+	})
+	return found
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	compose := filepath.Join(dir, "compose.yaml")
+
+	if err := writeFile(compose, []byte("services: {a: {}}\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, compose); got != "services: {a: {}}\n" {
+		t.Errorf("compose.yaml = %q", got)
+	}
+	if info, _ := os.Stat(compose); info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+	if _, err := os.Stat(compose + previousSuffix); !os.IsNotExist(err) {
+		t.Errorf("first write kept a previous version: %v", err)
+	}
+
+	// Replacing a definition file keeps what it replaced
+	if err := writeFile(compose, []byte("services: {b: {}}\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, compose); got != "services: {b: {}}\n" {
+		t.Errorf("compose.yaml = %q", got)
+	}
+	if got := readString(t, compose+previousSuffix); got != "services: {a: {}}\n" {
+		t.Errorf("compose.yaml.prev = %q, want the first version", got)
+	}
+	if err := writeFile(compose, []byte("services: {c: {}}\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, compose+previousSuffix); got != "services: {b: {}}\n" {
+		t.Errorf("compose.yaml.prev = %q, want the second version", got)
+	}
+
+	// Other files are replaced without one
+	other := filepath.Join(dir, "app.conf")
+	for _, data := range []string{"one", "two"} {
+		if err := writeFile(other, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readString(t, other); got != "two" {
+		t.Errorf("app.conf = %q", got)
+	}
+	if _, err := os.Stat(other + previousSuffix); !os.IsNotExist(err) {
+		t.Errorf("app.conf kept a previous version: %v", err)
+	}
+
+	// A write that cannot be put in place leaves no temporary file
+	if err := os.Mkdir(filepath.Join(dir, "taken"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "taken", "x"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dir, "taken"), []byte("data"), 0600); err == nil {
+		t.Error("writeFile over a directory succeeded")
+	}
+	if temps := tempFiles(t, dir); len(temps) != 0 {
+		t.Errorf("temporary files left: %v", temps)
+	}
+}
+
+func TestRecoverFiles(t *testing.T) {
+	const good = "services:\n  web:\n    image: nginx\n"
+	tests := []struct {
+		name    string
+		files   map[string]string // Under the stack directory
+		want    map[string]string
+		missing []string
+	}{
+		{
+			name: "interrupted write",
+			files: map[string]string{
+				"compose.yaml":             good,
+				tempPrefix + "1":           "services:\n  we",
+				"conf/" + tempPrefix + "2": "half",
+				"conf/app.conf":            "kept",
+			},
+			want:    map[string]string{"compose.yaml": good, "conf/app.conf": "kept"},
+			missing: []string{tempPrefix + "1", "conf/" + tempPrefix + "2"},
+		},
+		{
+			name:  "truncated compose file",
+			files: map[string]string{"compose.yaml": "", "compose.yaml.prev": good},
+			want:  map[string]string{"compose.yaml": good, "compose.yaml.prev": good},
+		},
+		{
+			name:  "unparsable compose file",
+			files: map[string]string{"compose.yaml": "services: [web", "compose.yaml.prev": good},
+			want:  map[string]string{"compose.yaml": good},
+		},
+		{
+			name:  "unparsable metadata",
+			files: map[string]string{metadataFile: "labels: [", metadataFile + ".prev": "namespace: team-a\n"},
+			want:  map[string]string{metadataFile: "namespace: team-a\n"},
+		},
+		{
+			name:  "leftover previous version",
+			files: map[string]string{"compose.yaml": good, "compose.yaml.prev": "services: {old: {}}\n"},
+			want:  map[string]string{"compose.yaml": good, "compose.yaml.prev": "services: {old: {}}\n"},
+		},
+		{
+			name:  "previous version unreadable too",
+			files: map[string]string{"compose.yaml": "", "compose.yaml.prev": "services: [web"},
+			want:  map[string]string{"compose.yaml": "", "compose.yaml.prev": "services: [web"},
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{"compose.yaml.prev": good},
+			want:    map[string]string{"compose.yaml.prev": good},
+			missing: []string{"compose.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			stackPath := filepath.Join(root, "web")
+			for name, data := range tt.files {
+				path := filepath.Join(stackPath, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			NewManager(root, nil, nil, nil, nil).RecoverFiles()
+
+			for name, want := range tt.want {
+				if got := readString(t, filepath.Join(stackPath, name)); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			for _, name := range tt.missing {
+				if _, err := os.Stat(filepath.Join(stackPath, name)); !os.IsNotExist(err) {
+					t.Errorf("%s exists after recovery", name)
+				}
+			}
+			if temps := tempFiles(t, root); len(temps) != 0 {
+				t.Errorf("temporary files left: %v", temps)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/moby/moby/client"
+)
+
+// ErrStackTooLarge is returned for applies to a stack that already uses
+// more disk than the agent allows a stack
+var ErrStackTooLarge = errors.New("stack exceeds its disk limit (stacks.max_size)")
+
+// DiskUsage is what a stack takes on the agent's disk
+type DiskUsage struct {
+	DirectoryBytes int64 // Compose, .env, hooks and metadata
+	VolumeBytes    int64 // Named volumes of the compose project
+	ContainerBytes int64 // Writable layers of its containers
+	// ImageBytes are the images its containers run. Images may be shared
+	// with other stacks, so they do not count towards the limit.
+	ImageBytes int64
+}
+
+// Total is the usage the stack size limit applies to
+func (u *DiskUsage) Total() int64 {
+	return u.DirectoryBytes + u.VolumeBytes + u.ContainerBytes
+}
+
+// SetMaxSize limits the disk a stack may use before further applies to it
+// are refused. Zero means no limit.
+func (m *Manager) SetMaxSize(bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxSize = bytes
+}
+
+// MaxSize returns the per-stack disk limit; zero means none
+func (m *Manager) MaxSize() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.maxSize
+}
+
+// DiskUsage measures every stack under the stack root, keyed by name.
+// Docker is asked once, which takes a while on hosts with many images.
+func (m *Manager) DiskUsage(ctx context.Context) (map[string]*DiskUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries, err := os.ReadDir(m.stackRoot)
+	if err != nil {
+		return nil, fmt.Errorf("read stack root: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return m.diskUsage(ctx, names)
+}
+
+// checkDiskLimit refuses applies to a stack already at its disk limit.
+// Callers hold the lock.
+func (m *Manager) checkDiskLimit(ctx context.Context, name string) error {
+	if m.maxSize <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(m.stackRoot, name)); os.IsNotExist(err) {
+		return nil
+	}
+
+	usage, err := m.diskUsage(ctx, []string{name})
+	if err != nil {
+		return fmt.Errorf("measure disk usage: %w", err)
+	}
+	if total := usage[name].Total(); total > m.maxSize {
+		return fmt.Errorf("%w: %s uses %s of %s", ErrStackTooLarge, name,
+			quota.FormatMemory(total), quota.FormatMemory(m.maxSize))
+	}
+	return nil
+}
+
+// diskUsage measures the named stacks. Volumes and containers belong to the
+// stack whose name is their compose project. Callers hold the lock.
+func (m *Manager) diskUsage(ctx context.Context, names []string) (map[string]*DiskUsage, error) {
+	usage := make(map[string]*DiskUsage, len(names))
+	for _, name := range names {
+		size, err := dirSize(filepath.Join(m.stackRoot, name))
+		if err != nil {
+			return nil, fmt.Errorf("measure stack %s: %w", name, err)
+		}
+		usage[name] = &DiskUsage{DirectoryBytes: size}
+	}
+
+	df, err := m.docker.DiskUsage(ctx, client.DiskUsageOptions{
+		Containers: true,
+		Images:     true,
+		Volumes:    true,
+		Verbose:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("docker disk usage: %w", err)
+	}
+
+	imageSizes := make(map[string]int64, len(df.Images.Items))
+	for _, img := range df.Images.Items {
+		imageSizes[img.ID] = img.Size
+	}
+
+	// Each image counts once per stack, however many containers run it
+	counted := make(map[string]bool)
+	for _, c := range df.Containers.Items {
+		project := c.Labels["com.docker.compose.project"]
+		u, ok := usage[project]
+		if !ok {
+			continue
+		}
+		u.ContainerBytes += c.SizeRw
+		if key := project + "/" + c.ImageID; !counted[key] {
+			counted[key] = true
+			u.ImageBytes += imageSizes[c.ImageID]
+		}
+	}
+
+	for _, v := range df.Volumes.Items {
+		u, ok := usage[v.Labels["com.docker.compose.project"]]
+		// Drivers other than local do not report a size
+		if !ok || v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		u.VolumeBytes += v.UsageData.Size
+	}
+
+	return usage, nil
+}
+
+// dirSize adds up the sizes of the files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
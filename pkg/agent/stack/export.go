@@ -0,0 +1,58 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Export is everything stored for a stack
+type Export struct {
+	Stack        *Stack
+	Compose      string
+	Env          map[string]string // The .env file; empty without one
+	Overrides    []Content
+	ProjectFiles []Content
+}
+
+// Export returns a stack with its compose files, project files and .env,
+// decrypted
+func (m *Manager) Export(ctx context.Context, name string) (*Export, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, err := m.loadStack(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	compose, err := m.sealer.ReadFile(composeFile(s.Path))
+	if err != nil {
+		return nil, fmt.Errorf("read compose file: %w", err)
+	}
+
+	pairs, err := m.readEnv(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, _ := strings.Cut(pair, "=")
+		env[k] = v
+	}
+
+	md, err := readMetadata(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := m.readOverrides(s.Path, md)
+	if err != nil {
+		return nil, err
+	}
+	files, err := readProjectFiles(s.Path, md)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{Stack: s, Compose: string(compose), Env: env, Overrides: overrides, ProjectFiles: files}, nil
+}
@@ -0,0 +1,91 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// managedFiles are written by applies alone, with the versions they
+// replaced, and the stack's lock file
+var managedFiles = map[string]bool{
+	"compose.yaml": true, ".env": true, metadataFile: true, lockFile: true,
+	"compose.yaml" + previousSuffix: true, ".env" + previousSuffix: true, metadataFile + previousSuffix: true,
+}
+
+// PlaceFile moves the file at src to rel in the directory of stack name,
+// which must exist in namespace ns, replacing what is there. rel may not
+// leave the directory or name the files applies write.
+func (m *Manager) PlaceFile(name, ns, rel, src string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	rel = filepath.Clean(rel)
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w %s/%s", ErrInvalidFile, name, rel)
+	}
+	if managedFiles[rel] {
+		return fmt.Errorf("%w: %s is written by stack applies", ErrInvalidFile, rel)
+	}
+
+	stackPath := filepath.Join(m.stackRoot, name)
+	if _, err := os.Stat(stackPath); os.IsNotExist(err) {
+		return fmt.Errorf("stack not found: %s", name)
+	}
+	if err := checkNamespace(stackPath, ns); err != nil {
+		return err
+	}
+	release, err := m.lockStack(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	dest := filepath.Join(stackPath, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	err = os.Rename(src, dest)
+	if errors.Is(err, syscall.EXDEV) {
+		// The transfer directory is on another filesystem
+		return copyFile(src, dest)
+	}
+	if err != nil {
+		return fmt.Errorf("place file: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dest through a temporary file, then removes src
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("place file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("place file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("place file: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return fmt.Errorf("place file: %w", err)
+	}
+	return os.Remove(src)
+}
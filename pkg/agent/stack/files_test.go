@@ -0,0 +1,50 @@
+package stack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlaceFileRejectsEscapingStackNames(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "stacks")
+	if err := os.MkdirAll(filepath.Join(root, "web"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(root, nil, nil, nil, nil)
+
+	for _, name := range []string{"..", "."} {
+		src := filepath.Join(dir, "upload-"+name)
+		if err := os.WriteFile(src, []byte("payload"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.PlaceFile(name, "", "app.conf", src); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("PlaceFile(%q) = %v, want ErrInvalidStackName", name, err)
+		}
+		if _, err := os.Stat(src); err != nil {
+			t.Errorf("PlaceFile(%q) moved the upload: %v", name, err)
+		}
+	}
+	for _, path := range []string{filepath.Join(dir, "app.conf"), filepath.Join(root, "app.conf")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was written", path)
+		}
+	}
+
+	// The stack's own directory still takes files
+	src := filepath.Join(dir, "upload")
+	if err := os.WriteFile(src, []byte("payload"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PlaceFile("web", "", "configs/app.conf", src); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "web", "configs", "app.conf")); err != nil || string(data) != "payload" {
+		t.Errorf("placed file = %q, %v", data, err)
+	}
+	if err := m.PlaceFile("web", "", "compose.yaml", src); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("placing compose.yaml = %v, want ErrInvalidFile", err)
+	}
+}
@@ -0,0 +1,288 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/moby/moby/client"
+)
+
+// gcMinAge keeps garbage collection away from stacks being written: stack
+// directories and files changed more recently are never reported
+const gcMinAge = time.Hour
+
+// OrphanKind is what garbage collection found
+type OrphanKind int
+
+const (
+	OrphanStackDir   OrphanKind = iota + 1 // Stack directory without containers
+	OrphanContainers                       // Containers of a stack with no directory
+	OrphanStaleFile                        // Backup or .env file left in a stack
+)
+
+// staleSuffixes mark the backup files editors and tools leave next to
+// compose and .env files
+var staleSuffixes = []string{".bak", ".backup", ".old", ".orig", ".rej", ".swp", ".tmp", "~"}
+
+// Orphan is something stacks left behind on the agent
+type Orphan struct {
+	Kind       OrphanKind
+	Stack      string
+	Path       string   // Directory or file; empty for containers
+	Containers []string // Container IDs
+	Detail     string
+	Removed    bool
+	Err        error // Why removing it failed
+}
+
+// composeContainer is a container labelled with a compose project
+type composeContainer struct {
+	ID         string
+	Project    string
+	WorkingDir string
+}
+
+// CollectGarbage looks for stack directories without containers, containers
+// of compose projects under the stack root whose directory is gone, and
+// stale backup or .env files. With apply set, what it finds is removed.
+// Stacks with an operation under way and anything changed within the last
+// hour are left alone.
+func (m *Manager) CollectGarbage(ctx context.Context, apply bool) ([]Orphan, error) {
+	if apply {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	} else {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	}
+
+	root, err := filepath.Abs(m.stackRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolve stack root: %w", err)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("read stack root: %w", err)
+	}
+	containers, err := m.composeContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	busy := m.busyStacks()
+
+	// A stack's containers carry its name as project, or its directory as
+	// working dir when compose was given the directory
+	inUse := make(map[string]bool)
+	for _, c := range containers {
+		inUse[c.Project] = true
+		inUse[c.WorkingDir] = true
+	}
+
+	var orphans []Orphan
+	now := time.Now()
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || busy[name] {
+			continue
+		}
+		stackPath := filepath.Join(root, name)
+
+		if !inUse[name] && !inUse[stackPath] {
+			changed, err := lastChange(stackPath)
+			if err != nil || now.Sub(changed) < gcMinAge {
+				continue
+			}
+			orphans = append(orphans, Orphan{
+				Kind:   OrphanStackDir,
+				Stack:  name,
+				Path:   stackPath,
+				Detail: "no containers since " + changed.Format(time.RFC3339),
+			})
+			continue
+		}
+
+		stale, err := staleFiles(name, stackPath, now)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, stale...)
+	}
+
+	// Containers are only claimed when compose ran them from a directory
+	// under the stack root, so projects started by hand are never touched
+	missing := make(map[string]*Orphan)
+	for _, c := range containers {
+		if busy[c.Project] || !underRoot(root, c.WorkingDir) {
+			continue
+		}
+		if _, err := os.Stat(c.WorkingDir); !os.IsNotExist(err) {
+			continue
+		}
+		o, ok := missing[c.WorkingDir]
+		if !ok {
+			o = &Orphan{Kind: OrphanContainers, Stack: c.Project}
+			missing[c.WorkingDir] = o
+		}
+		o.Containers = append(o.Containers, c.ID)
+	}
+	for dir, o := range missing {
+		o.Detail = fmt.Sprintf("%d container(s) of %s, which no longer exists", len(o.Containers), dir)
+		orphans = append(orphans, *o)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		a, b := orphans[i], orphans[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Stack != b.Stack {
+			return a.Stack < b.Stack
+		}
+		return a.Path < b.Path
+	})
+
+	if apply {
+		for i := range orphans {
+			m.removeOrphan(ctx, &orphans[i])
+		}
+	}
+	return orphans, nil
+}
+
+// composeContainers lists every container with a compose project label
+func (m *Manager) composeContainers(ctx context.Context) ([]composeContainer, error) {
+	containerFilters := client.Filters{}
+	containerFilters.Add("label", "com.docker.compose.project")
+
+	containerListResult, err := m.docker.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: containerFilters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]composeContainer, len(containerListResult.Items))
+	for i, c := range containerListResult.Items {
+		result[i] = composeContainer{
+			ID:         c.ID[:12],
+			Project:    c.Labels["com.docker.compose.project"],
+			WorkingDir: filepath.Clean(c.Labels["com.docker.compose.project.working_dir"]),
+		}
+	}
+	return result, nil
+}
+
+// busyStacks returns the stacks with a pending or running operation
+func (m *Manager) busyStacks() map[string]bool {
+	busy := make(map[string]bool)
+	ops := m.opMgr.ListOperations(func(op *operation.Operation) bool {
+		return op.State == operation.OperationStatePending || op.State == operation.OperationStateRunning
+	})
+	for _, op := range ops {
+		if name := op.Metadata["stack"]; name != "" {
+			busy[name] = true
+		}
+	}
+	return busy
+}
+
+// lastChange returns when anything in dir last changed
+func lastChange(dir string) (time.Time, error) {
+	var latest time.Time
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// staleFiles returns the backup files in a stack directory, what interrupted
+// writes left and its .env when no compose file is left to use it
+func staleFiles(name, stackPath string, now time.Time) ([]Orphan, error) {
+	entries, err := os.ReadDir(stackPath)
+	if err != nil {
+		return nil, fmt.Errorf("read stack %s: %w", name, err)
+	}
+
+	_, statErr := os.Stat(composeFile(stackPath))
+	noCompose := os.IsNotExist(statErr)
+
+	var orphans []Orphan
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		file := entry.Name()
+
+		var detail string
+		switch {
+		case file == ".env" && noCompose:
+			detail = ".env without a compose file"
+		case strings.HasPrefix(file, tempPrefix):
+			detail = "interrupted write"
+		case hasStaleSuffix(file):
+			detail = "backup file"
+		default:
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < gcMinAge {
+			continue
+		}
+		orphans = append(orphans, Orphan{
+			Kind:   OrphanStaleFile,
+			Stack:  name,
+			Path:   filepath.Join(stackPath, file),
+			Detail: detail,
+		})
+	}
+	return orphans, nil
+}
+
+func hasStaleSuffix(file string) bool {
+	for _, suffix := range staleSuffixes {
+		if strings.HasSuffix(file, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// underRoot reports whether dir is a stack directory under root
+func underRoot(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel)
+}
+
+// removeOrphan removes what o points at and records the outcome on it.
+// Callers hold the write lock.
+func (m *Manager) removeOrphan(ctx context.Context, o *Orphan) {
+	var err error
+	switch o.Kind {
+	case OrphanStackDir:
+		err = os.RemoveAll(o.Path)
+	case OrphanStaleFile:
+		err = os.Remove(o.Path)
+	case OrphanContainers:
+		err = m.execCommand(ctx, append([]string{"docker", "rm", "--force"}, o.Containers...), nil, nil)
+	}
+	o.Removed, o.Err = err == nil, err
+}
@@ -0,0 +1,185 @@
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Hook names, which are also the files hooks are stored in under
+// <stack>/hooks
+const (
+	HookPreApply  = "pre-apply"
+	HookPostApply = "post-apply"
+)
+
+// DefaultHookTimeout limits a hook when the agent config sets no timeout
+const DefaultHookTimeout = 10 * time.Minute
+
+// hookLineLimit is the longest output line streamed from a hook
+const hookLineLimit = 64 * 1024
+
+// ErrHooksDisabled is returned for applies carrying hooks on an agent that
+// does not run them
+var ErrHooksDisabled = errors.New("stack hooks are disabled on this agent (stacks.hooks.enabled)")
+
+// Hooks are shell scripts run around compose up. An empty script means none.
+type Hooks struct {
+	PreApply  string // Before compose up
+	PostApply string // Once services are ready
+}
+
+// HookConfig controls whether and how the agent runs stack hooks
+type HookConfig struct {
+	Enabled bool
+	Timeout time.Duration // Zero means DefaultHookTimeout
+	Shell   string        // Empty means /bin/sh
+}
+
+// SetHooks sets how stack hooks run. Until it is called they are disabled.
+func (m *Manager) SetHooks(cfg HookConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = cfg
+}
+
+func hookPath(stackPath, name string) string {
+	return filepath.Join(stackPath, "hooks", name)
+}
+
+// checkHooks rejects hooks the agent would not run. Callers hold the lock.
+func (m *Manager) checkHooks(hooks *Hooks) error {
+	if hooks != nil && !m.hooks.Enabled && (hooks.PreApply != "" || hooks.PostApply != "") {
+		return ErrHooksDisabled
+	}
+	return nil
+}
+
+// storeHooks replaces the stored hooks of a stack; nil keeps them. Hooks
+// are sealed like the compose file. Callers hold the write lock.
+func (m *Manager) storeHooks(stackPath string, hooks *Hooks) error {
+	if hooks == nil {
+		return nil
+	}
+
+	dir := filepath.Join(stackPath, "hooks")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create hooks dir: %w", err)
+	}
+	for name, script := range map[string]string{HookPreApply: hooks.PreApply, HookPostApply: hooks.PostApply} {
+		path := hookPath(stackPath, name)
+		if script == "" {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove %s hook: %w", name, err)
+			}
+			continue
+		}
+		if err := m.writeSealed(path, []byte(script)); err != nil {
+			return fmt.Errorf("write %s hook: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// runHook runs the stored hook name of a stack, if there is one, in the
+// stack directory with the stack's .env in its environment. Its output is
+// emitted on the operation line by line.
+func (m *Manager) runHook(ctx context.Context, opID, name, stackName, stackPath string) error {
+	script, err := m.sealer.ReadFile(hookPath(stackPath, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s hook: %w", name, err)
+	}
+
+	m.mu.RLock()
+	cfg := m.hooks
+	m.mu.RUnlock()
+	if !cfg.Enabled {
+		m.opMgr.EmitEvent(opID, fmt.Sprintf("Skipping %s hook: hooks are disabled on this agent", name))
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	shell := cfg.Shell
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	env, err := m.readEnv(stackPath)
+	if err != nil {
+		return err
+	}
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The script is read from stdin, so sealed hooks never touch the disk
+	// in plain text
+	cmd := exec.CommandContext(ctx, shell, "-s")
+	cmd.Dir = stackPath
+	cmd.Stdin = bytes.NewReader(script)
+	cmd.Env = append(append(os.Environ(), env...),
+		"MANDAU_STACK="+stackName,
+		"MANDAU_NAMESPACE="+md.Namespace,
+		"MANDAU_HOOK="+name,
+		"MANDAU_OPERATION_ID="+opID,
+		"COMPOSE_PROJECT_NAME="+stackName,
+	)
+	// Background processes left by the script must not hold the apply
+	cmd.WaitDelay = 5 * time.Second
+
+	pr, pw := io.Pipe()
+	cmd.Stdout, cmd.Stderr = pw, pw
+
+	m.opMgr.EmitEvent(opID, fmt.Sprintf("Running %s hook...", name))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 4096), hookLineLimit)
+		for scanner.Scan() {
+			m.opMgr.EmitEvent(opID, fmt.Sprintf("[%s] %s", name, scanner.Text()))
+		}
+		// Keep the pipe flowing after an overlong line
+		io.Copy(io.Discard, pr)
+	}()
+
+	err = cmd.Wait()
+	pw.Close()
+	<-done
+
+	// A script that exited on its own is judged by its exit code, even if
+	// processes it left behind delayed Wait
+	if state := cmd.ProcessState; state != nil && state.Exited() {
+		if code := state.ExitCode(); code != 0 {
+			return fmt.Errorf("%s hook exited with code %d", name, code)
+		}
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s hook timed out after %s", name, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+	return nil
+}
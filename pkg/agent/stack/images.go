@@ -0,0 +1,79 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/agent/mirror"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/client"
+)
+
+// SetMirrors pulls images through mirrors from now on; nil pulls them from
+// their registries. Applies then pull missing images before compose runs.
+func (m *Manager) SetMirrors(mirrors *mirror.Set) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mirrors = mirrors
+}
+
+// ImageDigests returns the registry digests of every local image, keyed by
+// image ID. Images that were never pulled or pushed have none.
+func (m *Manager) ImageDigests(ctx context.Context) (map[string][]string, error) {
+	images, err := m.docker.ImageList(ctx, client.ImageListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list images: %w", err)
+	}
+
+	digests := make(map[string][]string, len(images.Items))
+	for _, img := range images.Items {
+		digests[img.ID] = img.RepoDigests
+	}
+	return digests, nil
+}
+
+// LoadImages loads the images of a bundle written by docker save, which
+// may be compressed, and returns their names. Images saved without a name
+// are returned by ID.
+func (m *Manager) LoadImages(ctx context.Context, bundle io.Reader) ([]string, error) {
+	resp, err := m.docker.ImageLoad(ctx, bundle)
+	if err != nil {
+		return nil, fmt.Errorf("load images: %w", err)
+	}
+	defer resp.Close()
+
+	images, err := loadedImages(resp)
+	if err != nil {
+		return nil, fmt.Errorf("load images: %w", err)
+	}
+	return images, nil
+}
+
+// loadedImages reads the images Docker reports loading from its progress
+// stream, which also carries errors found midway through the bundle
+func loadedImages(r io.Reader) ([]string, error) {
+	var images []string
+	dec := json.NewDecoder(r)
+	for {
+		var msg jsonstream.Message
+		if err := dec.Decode(&msg); err == io.EOF {
+			return images, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if msg.Error != nil {
+			return nil, errors.New(msg.Error.Message)
+		}
+
+		line := strings.TrimSpace(msg.Stream)
+		if name, ok := strings.CutPrefix(line, "Loaded image: "); ok {
+			images = append(images, name)
+		} else if id, ok := strings.CutPrefix(line, "Loaded image ID: "); ok {
+			images = append(images, id)
+		}
+	}
+}
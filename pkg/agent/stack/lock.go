@@ -0,0 +1,141 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// lockFile is what the advisory lock on a stack's directory is taken on.
+// Scripts running compose by hand can share it with flock(1).
+const lockFile = ".mandau.lock"
+
+// ownChangeGrace is how long after an operation of this agent on a stack
+// ends its container changes are still put down to it, as Docker events
+// may arrive late
+const ownChangeGrace = 10 * time.Second
+
+var (
+	// ErrStackLocked is returned when another process holds the lock on
+	// a stack's directory
+	ErrStackLocked = errors.New("stack is locked by another process")
+	// ErrRootLocked is returned when another agent uses the stack root
+	ErrRootLocked = errors.New("stack root is in use by another agent")
+)
+
+// errWouldBlock is returned by tryLock for files another process has locked
+var errWouldBlock = errors.New("locked")
+
+// stackLocks are the locks this agent holds on stack directories, each
+// shared by all its operations on the stack
+type stackLocks struct {
+	mu       sync.Mutex
+	held     map[string]*heldLock
+	released map[string]time.Time // When the last operation on a stack ended
+}
+
+type heldLock struct {
+	f     *os.File
+	users int
+}
+
+// LockRoot takes the lock on the stack root for as long as the agent runs,
+// failing with ErrRootLocked if another agent holds it. The lock file is
+// next to the root, as the staging directory is.
+func (m *Manager) LockRoot() error {
+	path := filepath.Clean(m.stackRoot) + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("lock stack root: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("lock stack root: %w", err)
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		if errors.Is(err, errWouldBlock) {
+			return fmt.Errorf("%w: %s", ErrRootLocked, m.stackRoot)
+		}
+		return fmt.Errorf("lock stack root: %w", err)
+	}
+	m.rootLock = f
+	return nil
+}
+
+// lockStack takes the lock on the directory of stack name for an operation
+// of this agent, failing with ErrStackLocked if another process holds it.
+// Operations of this agent share the lock; it is released when the last of
+// them calls the returned func. Stacks without a directory are not locked.
+func (m *Manager) lockStack(name string) (func(), error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	m.locks.mu.Lock()
+	defer m.locks.mu.Unlock()
+
+	if l := m.locks.held[name]; l != nil {
+		l.users++
+		return m.unlocker(name), nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(m.stackRoot, name, lockFile), os.O_RDWR|os.O_CREATE, 0600)
+	if os.IsNotExist(err) {
+		return func() {}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock stack: %w", err)
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		if errors.Is(err, errWouldBlock) {
+			return nil, fmt.Errorf("%w: %s", ErrStackLocked, name)
+		}
+		return nil, fmt.Errorf("lock stack: %w", err)
+	}
+	if m.locks.held == nil {
+		m.locks.held = make(map[string]*heldLock)
+	}
+	m.locks.held[name] = &heldLock{f: f, users: 1}
+	return m.unlocker(name), nil
+}
+
+// unlocker returns the func one operation releases its share of the lock
+// on stack name with
+func (m *Manager) unlocker(name string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.locks.mu.Lock()
+			defer m.locks.mu.Unlock()
+
+			l := m.locks.held[name]
+			if l.users--; l.users > 0 {
+				return
+			}
+			unlock(l.f)
+			l.f.Close()
+			delete(m.locks.held, name)
+			if m.locks.released == nil {
+				m.locks.released = make(map[string]time.Time)
+			}
+			m.locks.released[name] = time.Now()
+		})
+	}
+}
+
+// ChangedByAgent reports whether a change to the containers of stack name
+// at t falls within an operation of this agent on it. Changes outside them
+// were made by someone running compose by hand or by another tool.
+func (m *Manager) ChangedByAgent(name string, t time.Time) bool {
+	m.locks.mu.Lock()
+	defer m.locks.mu.Unlock()
+
+	if m.locks.held[name] != nil {
+		return true
+	}
+	released, ok := m.locks.released[name]
+	return ok && t.Before(released.Add(ownChangeGrace))
+}
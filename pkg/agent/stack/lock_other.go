@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd)
+
+package stack
+
+import "os"
+
+// tryLock succeeds without locking, as there is no flock here; only the
+// agent's own operations are kept apart
+func tryLock(f *os.File) error {
+	return nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
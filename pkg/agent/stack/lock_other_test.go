@@ -0,0 +1,34 @@
+//go:build !(linux || darwin || freebsd)
+
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockWithoutFlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), lockFile)
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := tryLock(f); err != nil {
+			t.Errorf("tryLock %d: %v", i, err)
+		}
+	}
+
+	// The agent's own operations still share and release the lock
+	m, _ := newLockManager(t)
+	release, err := m.lockStack("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if l := m.locks.held["web"]; l != nil {
+		t.Errorf("lock still held: %+v", l)
+	}
+}
@@ -0,0 +1,101 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newLockManager returns a manager over a stack root holding stack web
+func newLockManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "stacks")
+	if err := os.MkdirAll(filepath.Join(root, "web"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(root, nil, nil, nil, nil), root
+}
+
+func TestLockStackShared(t *testing.T) {
+	m, root := newLockManager(t)
+
+	first, err := m.lockStack("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.lockStack("web")
+	if err != nil {
+		t.Fatalf("nested lock: %v", err)
+	}
+	if users := m.locks.held["web"].users; users != 2 {
+		t.Fatalf("users = %d, want 2", users)
+	}
+	if _, err := os.Stat(filepath.Join(root, "web", lockFile)); err != nil {
+		t.Errorf("lock file: %v", err)
+	}
+
+	// Releasing twice gives up one share only
+	first()
+	first()
+	if l := m.locks.held["web"]; l == nil || l.users != 1 {
+		t.Fatalf("after one release: %+v, want one user", l)
+	}
+	second()
+	if l := m.locks.held["web"]; l != nil {
+		t.Fatalf("after the last release: %+v, want unlocked", l)
+	}
+
+	// Stacks without a directory have nothing to lock
+	release, err := m.lockStack("missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if _, ok := m.locks.held["missing"]; ok {
+		t.Error("locked a stack without a directory")
+	}
+}
+
+func TestLockStackReleasedOnError(t *testing.T) {
+	m, _ := newLockManager(t)
+
+	// The upload is gone, so placing it fails with the lock taken
+	if err := m.PlaceFile("web", "", "app.conf", filepath.Join(t.TempDir(), "gone")); err == nil {
+		t.Fatal("PlaceFile of a missing upload succeeded")
+	}
+	if l := m.locks.held["web"]; l != nil {
+		t.Errorf("lock still held after a failed operation: %+v", l)
+	}
+	if _, ok := m.locks.released["web"]; !ok {
+		t.Error("release not recorded")
+	}
+}
+
+func TestChangedByAgent(t *testing.T) {
+	m, _ := newLockManager(t)
+	now := time.Now()
+
+	if m.ChangedByAgent("web", now) {
+		t.Error("change before any operation put down to the agent")
+	}
+
+	release, err := m.lockStack("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.ChangedByAgent("web", now.Add(time.Hour)) {
+		t.Error("change during an operation not put down to the agent")
+	}
+	release()
+
+	if !m.ChangedByAgent("web", time.Now()) {
+		t.Error("change just after an operation not put down to the agent")
+	}
+	if m.ChangedByAgent("web", time.Now().Add(2*ownChangeGrace)) {
+		t.Error("change after the grace period put down to the agent")
+	}
+	if m.ChangedByAgent("db", time.Now()) {
+		t.Error("change to another stack put down to the agent")
+	}
+}
@@ -0,0 +1,86 @@
+//go:build linux || darwin || freebsd
+
+package stack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// holdLock takes the lock on path as another process would, through its
+// own open file, and returns the file to close to release it
+func holdLock(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		t.Fatalf("lock %s: %v", path, err)
+	}
+	return f
+}
+
+func TestLockStackExclusive(t *testing.T) {
+	m, root := newLockManager(t)
+	path := filepath.Join(root, "web", lockFile)
+
+	// Held by another process: operations fail rather than wait
+	other := holdLock(t, path)
+	if _, err := m.lockStack("web"); !errors.Is(err, ErrStackLocked) {
+		t.Fatalf("lockStack = %v, want ErrStackLocked", err)
+	}
+	if err := m.PlaceFile("web", "", "app.conf", filepath.Join(t.TempDir(), "upload")); !errors.Is(err, ErrStackLocked) {
+		t.Errorf("PlaceFile = %v, want ErrStackLocked", err)
+	}
+	if l := m.locks.held["web"]; l != nil {
+		t.Errorf("failed lock left held: %+v", l)
+	}
+	other.Close()
+
+	// Held by the agent: other processes are kept out until the last
+	// operation releases it
+	first, err := m.lockStack("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.lockStack("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.OpenFile(path, os.O_RDWR, 0600)
+	defer f.Close()
+	first()
+	if err := tryLock(f); !errors.Is(err, errWouldBlock) {
+		t.Fatalf("lock taken by another process while shared: %v", err)
+	}
+	second()
+	if err := tryLock(f); err != nil {
+		t.Errorf("lock not released: %v", err)
+	}
+}
+
+func TestLockRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "stacks")
+	m := NewManager(root, nil, nil, nil, nil)
+	if err := m.LockRoot(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(root + ".lock"); err != nil {
+		t.Errorf("lock file: %v", err)
+	}
+
+	other := NewManager(root+string(filepath.Separator), nil, nil, nil, nil)
+	if err := other.LockRoot(); !errors.Is(err, ErrRootLocked) {
+		t.Fatalf("second LockRoot = %v, want ErrRootLocked", err)
+	}
+
+	m.rootLock.Close()
+	if err := other.LockRoot(); err != nil {
+		t.Errorf("LockRoot after the first agent stopped: %v", err)
+	}
+	other.rootLock.Close()
+}
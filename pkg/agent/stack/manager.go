@@ -1,16 +1,25 @@
 package stack
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/bhangun/mandau/pkg/agent/mirror"
 	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/bhangun/mandau/pkg/agent/seal"
+	"github.com/bhangun/mandau/pkg/agent/timeline"
+	"github.com/bhangun/mandau/pkg/quota"
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/requestid"
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/moby/moby/client"
@@ -23,6 +32,17 @@ type Manager struct {
 	docker    *client.Client
 	stacks    map[string]*Stack
 	opMgr     *operation.Manager
+	sealer    *seal.Sealer // Nil stores compose and .env files unencrypted
+	redactor  *redact.Redactor
+
+	readyTimeout time.Duration // Zero means DefaultReadyTimeout
+	hooks        HookConfig
+	maxSize      int64              // Per-stack disk limit in bytes; zero means none
+	timeline     *timeline.Timeline // Nil records no events
+	mirrors      *mirror.Set        // Nil pulls images from their registries
+	storage      Storage            // Nil keeps no history of the stack root
+	locks        stackLocks
+	rootLock     *os.File // Held for as long as the agent runs
 }
 
 type Stack struct {
@@ -34,7 +54,10 @@ type Stack struct {
 	Containers []ContainerInfo
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	Namespace  string
 	Labels     map[string]string
+	Owner      Owner
+	Resources  quota.Reservations
 }
 
 type StackState int
@@ -54,14 +77,20 @@ type ContainerInfo struct {
 	State   string
 	Status  string
 	Image   string
+	ImageID string
 }
 
-func NewManager(stackRoot string, docker *client.Client, opMgr *operation.Manager) *Manager {
+// NewManager manages the stacks under stackRoot. Compose and .env files are
+// encrypted with sealer; nil leaves them in plain text. Diffs mask the
+// values of variables redactor considers secret.
+func NewManager(stackRoot string, docker *client.Client, opMgr *operation.Manager, sealer *seal.Sealer, redactor *redact.Redactor) *Manager {
 	return &Manager{
 		stackRoot: stackRoot,
 		docker:    docker,
 		stacks:    make(map[string]*Stack),
 		opMgr:     opMgr,
+		sealer:    sealer,
+		redactor:  redactor,
 	}
 }
 
@@ -78,7 +107,8 @@ func (m *Manager) ListStacks(ctx context.Context) ([]*Stack, error) {
 	stacks := make([]*Stack, 0)
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// Dot directories are not stacks, e.g. the .git of stacks.storage
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -104,6 +134,9 @@ func (m *Manager) GetStack(ctx context.Context, name string) (*Stack, error) {
 }
 
 func (m *Manager) loadStack(ctx context.Context, name string) (*Stack, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
 	stackPath := filepath.Join(m.stackRoot, name)
 
 	// Check if stack directory exists
@@ -112,18 +145,22 @@ func (m *Manager) loadStack(ctx context.Context, name string) (*Stack, error) {
 	}
 
 	// Load compose file
-	composePath := filepath.Join(stackPath, "compose.yaml")
-	if _, err := os.Stat(composePath); os.IsNotExist(err) {
-		composePath = filepath.Join(stackPath, "docker-compose.yaml")
+	composeData, err := m.sealer.ReadFile(composeFile(stackPath))
+	if err != nil {
+		return nil, fmt.Errorf("read compose file: %w", err)
 	}
 
-	composeData, err := os.ReadFile(composePath)
+	md, err := readMetadata(stackPath)
 	if err != nil {
-		return nil, fmt.Errorf("read compose file: %w", err)
+		return nil, err
+	}
+	overrides, err := m.readOverrides(stackPath, md)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse compose file
-	project, err := m.parseCompose(ctx, name, composeData, stackPath)
+	project, err := m.parseCompose(ctx, name, composeData, stackPath, overrides...)
 	if err != nil {
 		return nil, fmt.Errorf("parse compose: %w", err)
 	}
@@ -134,6 +171,10 @@ func (m *Manager) loadStack(ctx context.Context, name string) (*Stack, error) {
 		return nil, fmt.Errorf("get containers: %w", err)
 	}
 
+	// compose-go accepted the file, so a reservation it cannot express in
+	// quota terms is reported as zero rather than hiding the stack
+	resources, _ := quota.FromCompose(composeData)
+
 	stack := &Stack{
 		ID:         name,
 		Name:       name,
@@ -141,7 +182,10 @@ func (m *Manager) loadStack(ctx context.Context, name string) (*Stack, error) {
 		Project:    project,
 		Containers: containers,
 		State:      m.determineState(containers),
-		Labels:     make(map[string]string),
+		Namespace:  NormalizeNamespace(md.Namespace),
+		Labels:     md.Labels,
+		Owner:      md.Owner,
+		Resources:  resources,
 		UpdatedAt:  time.Now(),
 	}
 
@@ -154,22 +198,41 @@ func (m *Manager) loadStack(ctx context.Context, name string) (*Stack, error) {
 	return stack, nil
 }
 
-func (m *Manager) parseCompose(ctx context.Context, name string, data []byte, workingDir string) (*types.Project, error) {
+// composeFile returns the compose file of the stack in stackPath
+func composeFile(stackPath string) string {
+	composePath := filepath.Join(stackPath, "compose.yaml")
+	if _, err := os.Stat(composePath); os.IsNotExist(err) {
+		composePath = filepath.Join(stackPath, "docker-compose.yaml")
+	}
+	return composePath
+}
+
+// parseCompose loads a compose file with the overrides merged over it in
+// order
+func (m *Manager) parseCompose(ctx context.Context, name string, data []byte, workingDir string, overrides ...Content) (*types.Project, error) {
 	// Parse YAML
 	var raw map[string]interface{}
 	if err := yaml.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
 
+	configFiles := []types.ConfigFile{
+		{
+			Content: data,
+		},
+	}
+	for _, o := range overrides {
+		configFiles = append(configFiles, types.ConfigFile{Filename: filepath.Join(workingDir, o.Path), Content: o.Data})
+	}
+
 	// Use compose-go loader
 	project, err := loader.LoadWithContext(ctx, types.ConfigDetails{
-		WorkingDir: workingDir,
-		ConfigFiles: []types.ConfigFile{
-			{
-				Content: data,
-			},
-		},
+		WorkingDir:  workingDir,
+		ConfigFiles: configFiles,
 		Environment: types.NewMapping(nil),
+	}, func(o *loader.Options) {
+		// Compose files rarely name their project; the stack does
+		o.SetProjectName(name, true)
 	})
 	if err != nil {
 		return nil, err
@@ -201,6 +264,7 @@ func (m *Manager) getStackContainers(ctx context.Context, stackName string) ([]C
 			State:   string(c.State),
 			Status:  c.Status,
 			Image:   c.Image,
+			ImageID: c.ImageID,
 		}
 	}
 
@@ -237,17 +301,85 @@ func (m *Manager) ApplyStack(ctx context.Context, req *ApplyStackRequest) (strin
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// A retry gets the operation the first request started
+	if opID, err := m.opMgr.FindIdempotent(req.Idempotency); err != nil || opID != "" {
+		return opID, err
+	}
+
+	if err := CheckName(req.StackName); err != nil {
+		return "", err
+	}
+	if err := m.checkHooks(req.Hooks); err != nil {
+		return "", err
+	}
+
+	if err := checkProject(req.Overrides, req.ProjectFiles); err != nil {
+		return "", err
+	}
+
+	if err := m.checkDiskLimit(ctx, req.StackName); err != nil {
+		return "", err
+	}
+
 	stackPath := filepath.Join(m.stackRoot, req.StackName)
 
-	// Create stack directory if doesn't exist
-	if err := os.MkdirAll(stackPath, 0755); err != nil {
+	// An existing stack may only be updated from its own namespace
+	if _, err := os.Stat(stackPath); err == nil {
+		if err := checkNamespace(stackPath, req.Namespace); err != nil {
+			return "", err
+		}
+	}
+
+	// Files are checked before anything is written, so an apply missing
+	// some leaves the stack as it was
+	var files *fileSync
+	if req.Files != nil {
+		var err error
+		if files, err = m.planFiles(stackPath, req.StackName, req.Files); err != nil {
+			return "", err
+		}
+	}
+
+	// Create stack directory if doesn't exist. Compose and .env files may
+	// hold credentials, so only the agent's user may read them.
+	if err := os.MkdirAll(stackPath, 0700); err != nil {
 		return "", fmt.Errorf("create stack dir: %w", err)
 	}
+	if err := os.Chmod(stackPath, 0700); err != nil {
+		return "", fmt.Errorf("restrict stack dir: %w", err)
+	}
 
-	// Write compose file
-	composePath := filepath.Join(stackPath, "compose.yaml")
-	if err := os.WriteFile(composePath, []byte(req.ComposeContent), 0644); err != nil {
-		return "", fmt.Errorf("write compose file: %w", err)
+	// The lock is held until the apply ends, so other processes do not
+	// touch the stack meanwhile
+	release, err := m.lockStack(req.StackName)
+	if err != nil {
+		return "", err
+	}
+	started := false
+	defer func() {
+		if !started {
+			release()
+		}
+	}()
+
+	if files != nil {
+		if err := files.apply(stackPath); err != nil {
+			return "", err
+		}
+		os.RemoveAll(m.stagePath(req.StackName))
+	}
+
+	// What changes is worked out while the old compose file is there
+	plan := m.planApply(ctx, req)
+
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return "", err
+	}
+
+	// The compose file is written with the files it uses
+	if err := m.writeProject(stackPath, req.ComposeContent, req.Overrides, req.ProjectFiles, md); err != nil {
+		return "", err
 	}
 
 	// Write env file if provided
@@ -257,92 +389,163 @@ func (m *Manager) ApplyStack(ctx context.Context, req *ApplyStackRequest) (strin
 		for k, v := range req.EnvVars {
 			envContent += fmt.Sprintf("%s=%s\n", k, v)
 		}
-		if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
+		if err := m.writeSealed(envPath, []byte(envContent)); err != nil {
 			return "", fmt.Errorf("write env file: %w", err)
 		}
 	}
 
+	if err := m.storeHooks(stackPath, req.Hooks); err != nil {
+		return "", err
+	}
+
+	// Labels and ownership are replaced only when the request carries them,
+	// so plain re-applies keep what is stored
+	md.Namespace = NormalizeNamespace(req.Namespace)
+	if req.Labels != nil {
+		md.Labels = req.Labels
+	}
+	if req.Owner != nil {
+		md.Owner = *req.Owner
+	}
+	if req.Files != nil {
+		md.Files = req.Files
+	}
+	if err := writeMetadata(stackPath, md); err != nil {
+		return "", err
+	}
+
 	// Create operation for async execution
-	opID := m.opMgr.CreateOperation(operation.OperationTypeStackApply, map[string]string{
+	opID := m.opMgr.CreateIdempotentOperation(ctx, operation.OperationTypeStackApply, map[string]string{
 		"stack": req.StackName,
-	})
+	}, req.Idempotency)
+	m.recordChange(ctx, m.storage, "Apply", req.StackName, md.Namespace, opID)
 
 	// Execute in background
-	go m.executeApply(context.Background(), opID, req, stackPath)
+	started = true
+	go func() {
+		defer release()
+		m.executeApply(context.Background(), opID, req, stackPath, plan)
+	}()
 
 	return opID, nil
 }
 
-func (m *Manager) executeApply(ctx context.Context, opID string, req *ApplyStackRequest, stackPath string) {
+func (m *Manager) executeApply(ctx context.Context, opID string, req *ApplyStackRequest, stackPath string, plan *applyPlan) {
+	fail := func(err error) {
+		m.opMgr.SetError(opID, err)
+		m.recordApply(req, opID, plan, err)
+	}
+
 	m.opMgr.SetState(opID, operation.OperationStateRunning)
 	m.opMgr.EmitEvent(opID, "Parsing compose file...")
 
-	// Parse project
+	// Parse project, with the overrides stored with the compose file
 	composeData := []byte(req.ComposeContent)
-	project, err := m.parseCompose(ctx, req.StackName, composeData, stackPath)
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		fail(err)
+		return
+	}
+	overrides, err := m.readOverrides(stackPath, md)
 	if err != nil {
-		m.opMgr.SetError(opID, fmt.Errorf("parse compose: %w", err))
+		fail(err)
+		return
+	}
+	project, err := m.parseCompose(ctx, req.StackName, composeData, stackPath, overrides...)
+	if err != nil {
+		fail(fmt.Errorf("parse compose: %w", err))
 		return
 	}
 
-	// Pull images if requested
-	if req.PullImages {
+	// Pull images if requested. With registry mirrors the missing ones are
+	// pulled too, or compose would pull them from their registries.
+	if req.PullImages || m.mirrors != nil {
 		m.opMgr.EmitEvent(opID, "Pulling images...")
-		if err := m.pullImages(ctx, project); err != nil {
-			m.opMgr.SetError(opID, fmt.Errorf("pull images: %w", err))
+		if err := m.pullImages(ctx, opID, project, req.PullImages); err != nil {
+			fail(fmt.Errorf("pull images: %w", err))
 			return
 		}
 	}
 
+	if err := m.runHook(ctx, opID, HookPreApply, req.StackName, stackPath); err != nil {
+		fail(err)
+		return
+	}
+
 	// Apply using docker compose
 	m.opMgr.EmitEvent(opID, "Creating/updating services...")
 
 	// Use docker compose CLI via exec (compose-go doesn't support full lifecycle)
 	// In production, this would use the compose API or reimplemented logic
-	// Use relative path from stack root directory
-	relativeComposePath := filepath.Join(req.StackName, "compose.yaml")
-	cmd := []string{"docker", "compose", "-f", relativeComposePath, "up", "-d"}
+	args := []string{"up", "-d"}
 
 	if req.ForceRecreate {
-		cmd = append(cmd, "--force-recreate")
+		args = append(args, "--force-recreate")
 	}
 
 	if len(req.Services) > 0 {
-		cmd = append(cmd, req.Services...)
+		args = append(args, req.Services...)
 	}
 
 	// Execute command (simplified - production would stream output)
-	if err := m.execCommand(ctx, cmd); err != nil {
-		m.opMgr.SetError(opID, fmt.Errorf("compose up: %w", err))
+	if err := m.compose(ctx, req.StackName, stackPath, args...); err != nil {
+		fail(fmt.Errorf("compose up: %w", err))
+		return
+	}
+
+	if !req.NoWait {
+		timeout := m.readyTimeoutFor(req)
+		m.opMgr.EmitEvent(opID, fmt.Sprintf("Waiting up to %s for services to be ready...", timeout))
+		if err := m.waitReady(ctx, opID, req.StackName, expectedServices(project, req.Services), timeout); err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	if err := m.runHook(ctx, opID, HookPostApply, req.StackName, stackPath); err != nil {
+		fail(fmt.Errorf("%w; the services were applied", err))
 		return
 	}
 
 	m.opMgr.EmitEvent(opID, "Stack applied successfully")
 	m.opMgr.SetCompleted(opID)
+	m.recordApply(req, opID, plan, nil)
 }
 
-func (m *Manager) pullImages(ctx context.Context, project *types.Project) error {
+// pullImages pulls the images of project, through the registry mirrors if
+// any; unless all is set, only those missing on the host
+func (m *Manager) pullImages(ctx context.Context, opID string, project *types.Project, all bool) error {
+	event := func(msg string) { m.opMgr.EmitEvent(opID, msg) }
 	for _, service := range project.Services {
 		if service.Image == "" {
 			continue
 		}
-		// Pull image using Docker SDK
-		// Simplified - production would stream progress
-		reader, err := m.docker.ImagePull(ctx, service.Image, client.ImagePullOptions{})
+		if !all {
+			if _, err := m.docker.ImageInspect(ctx, service.Image); err == nil {
+				continue
+			}
+		}
+		src, err := m.mirrors.Pull(ctx, m.docker, service.Image, event)
 		if err != nil {
 			return err
 		}
-		io.Copy(io.Discard, reader)
-		reader.Close()
+		if src.Mirror != "" {
+			event(fmt.Sprintf("Pulled %s from mirror %s", service.Image, src.Mirror))
+		}
 	}
 	return nil
 }
 
-// DiffStack compares current stack with new compose content
-func (m *Manager) DiffStack(ctx context.Context, stackName string, newContent string) (*DiffResult, error) {
+// DiffStack compares current stack with new compose content and its
+// overrides, and with files unless nil
+func (m *Manager) DiffStack(ctx context.Context, stackName string, newContent string, overrides, files []Content) (*DiffResult, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if err := checkProject(overrides, files); err != nil {
+		return nil, err
+	}
+
 	// Load current stack
 	current, err := m.loadStack(ctx, stackName)
 	if err != nil {
@@ -350,12 +553,17 @@ func (m *Manager) DiffStack(ctx context.Context, stackName string, newContent st
 	}
 
 	// Parse new compose
-	newProject, err := m.parseCompose(ctx, stackName, []byte(newContent), current.Path)
+	newProject, err := m.parseCompose(ctx, stackName, []byte(newContent), current.Path, overrides...)
 	if err != nil {
 		return nil, fmt.Errorf("parse new compose: %w", err)
 	}
 
-	return m.computeDiff(current.Project, newProject), nil
+	result := m.computeDiff(current.Project, newProject)
+	if result.Files, err = m.projectChanges(current.Path, overrides, files); err != nil {
+		return nil, err
+	}
+	result.HasChanges = result.HasChanges || len(result.Files) > 0
+	return result, nil
 }
 
 func (m *Manager) computeDiff(current, new *types.Project) *DiffResult {
@@ -421,27 +629,86 @@ func (m *Manager) compareServices(current, new types.ServiceConfig) []string {
 		changes = append(changes, "ports changed")
 	}
 
-	if len(current.Environment) != len(new.Environment) {
-		changes = append(changes, "environment changed")
+	if current.GetScale() != new.GetScale() {
+		changes = append(changes, fmt.Sprintf("replicas: %d → %d", current.GetScale(), new.GetScale()))
 	}
 
+	changes = append(changes, m.compareEnvironment(current.Environment, new.Environment)...)
+
 	// Compare other fields as needed
 
 	return changes
 }
 
+// compareEnvironment lists changed variables; values of secret ones are
+// masked
+func (m *Manager) compareEnvironment(current, new types.MappingWithEquals) []string {
+	value := func(env types.MappingWithEquals, name string) string {
+		if v := env[name]; v != nil {
+			return m.redactor.Value(name, *v)
+		}
+		return ""
+	}
+
+	names := make([]string, 0, len(current)+len(new))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range new {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		_, had := current[name]
+		_, has := new[name]
+		switch {
+		case !had:
+			changes = append(changes, fmt.Sprintf("environment %s: added", name))
+		case !has:
+			changes = append(changes, fmt.Sprintf("environment %s: removed", name))
+		default:
+			before, after := current[name], new[name]
+			if (before == nil) != (after == nil) || (before != nil && *before != *after) {
+				changes = append(changes, fmt.Sprintf("environment %s: %s → %s", name, value(current, name), value(new, name)))
+			}
+		}
+	}
+	return changes
+}
+
 // RemoveStack removes a stack and its containers
-func (m *Manager) RemoveStack(ctx context.Context, stackName string, removeVolumes bool) (string, error) {
+func (m *Manager) RemoveStack(ctx context.Context, stackName string, removeVolumes bool, idem operation.Idempotency) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if opID, err := m.opMgr.FindIdempotent(idem); err != nil || opID != "" {
+		return opID, err
+	}
+
+	if err := CheckName(stackName); err != nil {
+		return "", err
+	}
 	stackPath := filepath.Join(m.stackRoot, stackName)
+	release, err := m.lockStack(stackName)
+	if err != nil {
+		return "", err
+	}
 
-	opID := m.opMgr.CreateOperation(operation.OperationTypeStackRemove, map[string]string{
-		"stack": stackName,
-	})
+	opID := m.opMgr.CreateIdempotentOperation(ctx, operation.OperationTypeStackRemove, map[string]string{
+		"stack":          stackName,
+		"remove_volumes": strconv.FormatBool(removeVolumes),
+	}, idem)
 
-	go m.executeRemove(context.Background(), opID, stackName, stackPath, removeVolumes)
+	// The removal is recorded for the requester once the directory is gone
+	bg := requestid.With(WithAuthor(context.Background(), authorFrom(ctx)), requestid.From(ctx))
+	go func() {
+		defer release()
+		m.executeRemove(bg, opID, stackName, stackPath, removeVolumes)
+	}()
 
 	return opID, nil
 }
@@ -449,35 +716,130 @@ func (m *Manager) RemoveStack(ctx context.Context, stackName string, removeVolum
 func (m *Manager) executeRemove(ctx context.Context, opID, stackName, stackPath string, removeVolumes bool) {
 	m.opMgr.SetState(opID, operation.OperationStateRunning)
 	m.opMgr.EmitEvent(opID, "Stopping containers...")
+	ns := stackNamespace(stackPath)
 
 	// Execute docker compose down
-	relativeComposePath := filepath.Join(stackName, "compose.yaml")
-	cmd := []string{"docker", "compose", "-f", relativeComposePath, "down"}
+	args := []string{"down"}
 	if removeVolumes {
-		cmd = append(cmd, "--volumes")
+		args = append(args, "--volumes")
 	}
 
-	if err := m.execCommand(ctx, cmd); err != nil {
-		m.opMgr.SetError(opID, fmt.Errorf("compose down: %w", err))
+	if err := m.compose(ctx, stackName, stackPath, args...); err != nil {
+		err = fmt.Errorf("compose down: %w", err)
+		m.opMgr.SetError(opID, err)
+		m.recordRemove(stackName, ns, opID, err)
 		return
 	}
 
 	m.opMgr.EmitEvent(opID, "Removing stack directory...")
 	if err := os.RemoveAll(stackPath); err != nil {
-		m.opMgr.SetError(opID, fmt.Errorf("remove directory: %w", err))
+		err = fmt.Errorf("remove directory: %w", err)
+		m.opMgr.SetError(opID, err)
+		m.recordRemove(stackName, ns, opID, err)
 		return
 	}
 
+	m.recordChange(ctx, m.storage, "Remove", stackName, ns, opID)
 	m.opMgr.EmitEvent(opID, "Stack removed successfully")
 	m.opMgr.SetCompleted(opID)
+	m.recordRemove(stackName, ns, opID, nil)
+}
+
+// compose runs docker compose with args on a stack. Encrypted stacks never
+// reach the disk decrypted: compose reads the compose file from stdin and the
+// .env variables from its environment.
+func (m *Manager) compose(ctx context.Context, stackName, stackPath string, args ...string) error {
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return err
+	}
+
+	if !m.sealer.Enabled() {
+		// Use relative path from stack root directory
+		cmd := []string{"docker", "compose", "-f", filepath.Join(stackName, "compose.yaml")}
+		for _, name := range md.Overrides {
+			cmd = append(cmd, "-f", filepath.Join(stackName, name))
+		}
+		return m.execCommand(ctx, append(cmd, args...), nil, nil)
+	}
+
+	composeData, err := m.sealer.ReadFile(filepath.Join(stackPath, "compose.yaml"))
+	if err != nil {
+		return err
+	}
+	overrides, err := m.readOverrides(stackPath, md)
+	if err != nil {
+		return err
+	}
+	env, err := m.readEnv(stackPath)
+	if err != nil {
+		return err
+	}
+
+	cmd := []string{"docker", "compose",
+		"-p", stackName,
+		"--project-directory", stackPath,
+		"-f", "-",
+	}
+	// Overrides are passed through pipes, as the compose file is through
+	// stdin; the first extra file is descriptor 3
+	extra := make([][]byte, 0, len(overrides))
+	for i, o := range overrides {
+		cmd = append(cmd, "-f", fmt.Sprintf("/dev/fd/%d", 3+i))
+		extra = append(extra, o.Data)
+	}
+	// The .env on disk is encrypted; its variables are in env
+	cmd = append(cmd, "--env-file", os.DevNull)
+	return m.execCommand(ctx, append(cmd, args...), composeData, env, extra...)
 }
 
-func (m *Manager) execCommand(ctx context.Context, cmd []string) error {
+// readEnv returns the variables of a stack's .env file as KEY=VALUE pairs
+func (m *Manager) readEnv(stackPath string) ([]string, error) {
+	data, err := m.sealer.ReadFile(filepath.Join(stackPath, ".env"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+
+	var env []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || !strings.Contains(line, "=") {
+			continue
+		}
+		env = append(env, line)
+	}
+	return env, nil
+}
+
+// execCommand runs cmd with stdin and env, if not nil. The command reads
+// each of extra from a pipe, starting at descriptor 3.
+func (m *Manager) execCommand(ctx context.Context, cmd []string, stdin []byte, env []string, extra ...[]byte) error {
 	// Execute the command with proper context and error handling
 	command := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 
 	// Set working directory to the stack root directory so compose files can be found
 	command.Dir = m.stackRoot
+	if stdin != nil {
+		command.Stdin = bytes.NewReader(stdin)
+	}
+	if env != nil {
+		command.Env = append(os.Environ(), env...)
+	}
+	for _, data := range extra {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return fmt.Errorf("create pipe: %w", err)
+		}
+		defer r.Close()
+		command.ExtraFiles = append(command.ExtraFiles, r)
+		go func(data []byte) {
+			w.Write(data)
+			w.Close()
+		}(data)
+	}
 
 	// Execute the command
 	output, err := command.CombinedOutput()
@@ -495,10 +857,21 @@ type ApplyStackRequest struct {
 	ForceRecreate  bool
 	Services       []string
 	PullImages     bool
+	Labels         map[string]string // nil keeps the stored labels
+	Owner          *Owner            // nil keeps the stored ownership
+	Namespace      string
+	Idempotency    operation.Idempotency
+	NoWait         bool          // Complete when compose up returns
+	ReadyTimeout   time.Duration // Zero uses the manager's timeout
+	Hooks          *Hooks        // nil keeps the stored hooks
+	Files          []File        // nil leaves the stack's other files alone
+	Overrides      []Content     // Compose files merged over ComposeContent
+	ProjectFiles   []Content     // nil keeps the stored project files
 }
 
 type DiffResult struct {
 	Services   []ServiceDiff
+	Files      []FileChange // Compose overrides and project files
 	HasChanges bool
 }
 
@@ -0,0 +1,95 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// metadataFile holds labels and ownership next to the compose file. It is
+// outside the compose spec so docker compose ignores it.
+const metadataFile = ".mandau.yaml"
+
+// Owner records who is accountable for a stack
+type Owner struct {
+	Team   string `yaml:"team,omitempty"`
+	Owner  string `yaml:"owner,omitempty"`
+	Ticket string `yaml:"ticket,omitempty"`
+}
+
+// Metadata is the persisted, user-supplied description of a stack
+type Metadata struct {
+	Namespace    string            `yaml:"namespace,omitempty"`
+	Labels       map[string]string `yaml:"labels,omitempty"`
+	Owner        Owner             `yaml:"owner,omitempty"`
+	Files        []File            `yaml:"files,omitempty"`         // Synced by the last apply listing files
+	Overrides    []string          `yaml:"overrides,omitempty"`     // Compose overrides, in merge order
+	ProjectFiles []string          `yaml:"project_files,omitempty"` // configs/, secrets/ and dotenv variants
+}
+
+func readMetadata(stackPath string) (*Metadata, error) {
+	md := &Metadata{}
+
+	data, err := os.ReadFile(filepath.Join(stackPath, metadataFile))
+	if os.IsNotExist(err) {
+		md.Labels = make(map[string]string)
+		return md, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read metadata: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, md); err != nil {
+		return nil, fmt.Errorf("parse metadata: %w", err)
+	}
+	if md.Labels == nil {
+		md.Labels = make(map[string]string)
+	}
+	return md, nil
+}
+
+func writeMetadata(stackPath string, md *Metadata) error {
+	data, err := yaml.Marshal(md)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	if err := writeFile(filepath.Join(stackPath, metadataFile), data, 0600); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+	return nil
+}
+
+// Metadata returns the stored labels and ownership of a stack without
+// inspecting its containers. Unknown stacks have empty metadata.
+func (m *Manager) Metadata(name string) (*Metadata, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return readMetadata(filepath.Join(m.stackRoot, name))
+}
+
+// AsLabels flattens labels, namespace and ownership into one map for policy
+// and audit; they appear under the "namespace", "owner", "team" and "ticket"
+// keys
+func (md *Metadata) AsLabels() map[string]string {
+	labels := make(map[string]string, len(md.Labels)+4)
+	for k, v := range md.Labels {
+		labels[k] = v
+	}
+	labels["namespace"] = NormalizeNamespace(md.Namespace)
+	if md.Owner.Team != "" {
+		labels["team"] = md.Owner.Team
+	}
+	if md.Owner.Owner != "" {
+		labels["owner"] = md.Owner.Owner
+	}
+	if md.Owner.Ticket != "" {
+		labels["ticket"] = md.Owner.Ticket
+	}
+	return labels
+}
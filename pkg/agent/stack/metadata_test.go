@@ -0,0 +1,101 @@
+package stack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "stacks")
+	stackPath := filepath.Join(root, "web")
+	if err := os.MkdirAll(stackPath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(root, nil, nil, nil, nil)
+
+	// Stacks without metadata have empty labels and the default namespace
+	md, err := m.Metadata("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Labels == nil || len(md.Labels) != 0 || md.Owner != (Owner{}) {
+		t.Errorf("metadata without a file = %+v", md)
+	}
+	if got := md.AsLabels(); !reflect.DeepEqual(got, map[string]string{"namespace": DefaultNamespace}) {
+		t.Errorf("AsLabels() = %v", got)
+	}
+
+	want := &Metadata{
+		Namespace: "team-a",
+		Labels:    map[string]string{"env": "prod", "tier": "frontend"},
+		Owner:     Owner{Team: "payments", Owner: "alice", Ticket: "OPS-42"},
+	}
+	if err := writeMetadata(stackPath, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Metadata("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata() = %+v, want %+v", got, want)
+	}
+	if info, err := os.Stat(filepath.Join(stackPath, metadataFile)); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("metadata file: %v, %v", info, err)
+	}
+
+	// Policy and audit see labels, namespace and ownership as one map;
+	// a label cannot pose as the namespace
+	want.Labels["namespace"] = "team-b"
+	if err := writeMetadata(stackPath, want); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = m.Metadata("web")
+	labels := map[string]string{
+		"env": "prod", "tier": "frontend",
+		"namespace": "team-a", "team": "payments", "owner": "alice", "ticket": "OPS-42",
+	}
+	if flat := got.AsLabels(); !reflect.DeepEqual(flat, labels) {
+		t.Errorf("AsLabels() = %v, want %v", flat, labels)
+	}
+}
+
+func TestStacksCarryMetadata(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "stacks")
+	for name, md := range map[string]*Metadata{
+		"web":    {Namespace: "team-a", Labels: map[string]string{"env": "prod"}, Owner: Owner{Team: "payments"}},
+		"legacy": nil,
+	} {
+		stackPath := filepath.Join(root, name)
+		if err := os.MkdirAll(stackPath, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(stackPath, "compose.yaml"), []byte("services:\n  app:\n    image: nginx\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if md != nil {
+			if err := writeMetadata(stackPath, md); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	m := NewManager(root, containerDocker(t, map[string]string{}), nil, nil, nil)
+
+	stacks, err := m.ListStacks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*Stack)
+	for _, s := range stacks {
+		byName[s.Name] = s
+	}
+	if web := byName["web"]; web == nil || web.Namespace != "team-a" || web.Labels["env"] != "prod" || web.Owner.Team != "payments" {
+		t.Errorf("web = %+v", web)
+	}
+	if legacy := byName["legacy"]; legacy == nil || legacy.Namespace != DefaultNamespace || len(legacy.Labels) != 0 {
+		t.Errorf("legacy = %+v", legacy)
+	}
+}
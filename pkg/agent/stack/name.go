@@ -0,0 +1,23 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidStackName is returned for stack names that do not name a
+// directory of their own under the stack root
+var ErrInvalidStackName = errors.New("invalid stack name")
+
+// CheckName rejects stack names that would address anything but a
+// directory below the stack root: empty names, paths, "." and "..". Every
+// entry point taking a stack name calls it before joining the name to a
+// path.
+func CheckName(name string) error {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidStackName, name)
+	}
+	return nil
+}
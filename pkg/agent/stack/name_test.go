@@ -0,0 +1,50 @@
+package stack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"web", "web-1", "db.main", "..web"} {
+		if err := CheckName(name); err != nil {
+			t.Errorf("CheckName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "a/b", "../web", `a\b`, "/web"} {
+		if err := CheckName(name); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("CheckName(%q) = %v, want ErrInvalidStackName", name, err)
+		}
+	}
+}
+
+func TestSyncRejectsEscapingStackNames(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(filepath.Join(dir, "stacks"), nil, nil, nil, nil)
+	files := []File{{Path: "configs/app.conf", SHA256: strings.Repeat("0", 64), Size: 1}}
+
+	for _, name := range []string{".", ".."} {
+		if _, err := m.DiffFiles(name, "", files); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("DiffFiles(%q) = %v, want ErrInvalidStackName", name, err)
+		}
+		if _, err := m.DiffFiles(name, "", nil); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("DiffFiles(%q) without files = %v, want ErrInvalidStackName", name, err)
+		}
+		if _, err := m.StageFile(name, "", "configs/app.conf", strings.NewReader("x")); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("StageFile(%q) = %v, want ErrInvalidStackName", name, err)
+		}
+		if err := m.CheckNamespace(name, ""); !errors.Is(err, ErrInvalidStackName) {
+			t.Errorf("CheckNamespace(%q) = %v, want ErrInvalidStackName", name, err)
+		}
+	}
+
+	// Nothing was staged next to or above the stack root
+	for _, path := range []string{filepath.Join(dir, "configs"), filepath.Join(dir, "stacks.staging", "configs")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was written", path)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// DefaultNamespace owns stacks created without a namespace, including every
+// stack that predates namespaces
+const DefaultNamespace = "default"
+
+// ErrNamespaceMismatch is returned when a stack is addressed through a
+// namespace it does not belong to
+var ErrNamespaceMismatch = errors.New("stack belongs to another namespace")
+
+// NormalizeNamespace maps the empty namespace to DefaultNamespace
+func NormalizeNamespace(ns string) string {
+	if ns == "" {
+		return DefaultNamespace
+	}
+	return ns
+}
+
+// CheckNamespace verifies that stack name belongs to namespace ns. Stack
+// names are unique per agent, so a stack is never visible from two namespaces.
+func (m *Manager) CheckNamespace(name, ns string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return checkNamespace(filepath.Join(m.stackRoot, name), ns)
+}
+
+func checkNamespace(stackPath, ns string) error {
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return err
+	}
+	if NormalizeNamespace(md.Namespace) != NormalizeNamespace(ns) {
+		return fmt.Errorf("%w: %s", ErrNamespaceMismatch, filepath.Base(stackPath))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/agent/operation"
+)
+
+// newNamespaceManager returns a manager holding stack web in namespace
+// team-a and stack legacy, which predates namespaces and has no metadata
+func newNamespaceManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "stacks")
+	for _, name := range []string{"web", "legacy"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name, "compose.yaml"), []byte("services: {app: {}}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeMetadata(filepath.Join(root, "web"), &Metadata{Namespace: "team-a"}); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(root, nil, operation.NewManager(), nil, nil), root
+}
+
+func TestCheckNamespace(t *testing.T) {
+	m, _ := newNamespaceManager(t)
+
+	tests := []struct {
+		stack, namespace string
+		mismatch         bool
+	}{
+		{"web", "team-a", false},
+		{"web", "team-b", true},
+		{"web", "", true},
+		{"web", DefaultNamespace, true},
+		{"legacy", "", false},
+		{"legacy", DefaultNamespace, false},
+		{"legacy", "team-a", true},
+	}
+	for _, tt := range tests {
+		err := m.CheckNamespace(tt.stack, tt.namespace)
+		if got := errors.Is(err, ErrNamespaceMismatch); got != tt.mismatch || (err != nil && !got) {
+			t.Errorf("CheckNamespace(%s, %q) = %v, want mismatch %v", tt.stack, tt.namespace, err, tt.mismatch)
+		}
+	}
+
+	md, err := m.Metadata("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns := md.AsLabels()["namespace"]; ns != DefaultNamespace {
+		t.Errorf("namespace of a stack without metadata = %q, want %s", ns, DefaultNamespace)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	m, root := newNamespaceManager(t)
+	ctx := context.Background()
+
+	// Applying over another namespace's stack is refused before anything
+	// is written
+	_, err := m.ApplyStack(ctx, &ApplyStackRequest{StackName: "web", Namespace: "team-b", ComposeContent: "services: {evil: {}}\n"})
+	if !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("ApplyStack from team-b = %v, want ErrNamespaceMismatch", err)
+	}
+	_, err = m.ApplyStack(ctx, &ApplyStackRequest{StackName: "legacy", Namespace: "team-b", ComposeContent: "services: {evil: {}}\n"})
+	if !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("ApplyStack over the default namespace = %v, want ErrNamespaceMismatch", err)
+	}
+	for _, name := range []string{"web", "legacy"} {
+		if got := readString(t, filepath.Join(root, name, "compose.yaml")); got != "services: {app: {}}\n" {
+			t.Errorf("%s compose.yaml = %q", name, got)
+		}
+	}
+	md, err := m.Metadata("web")
+	if err != nil || md.Namespace != "team-a" {
+		t.Errorf("web metadata = %+v, %v; want it kept in team-a", md, err)
+	}
+
+	// Nor can files be placed in it
+	src := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(src, []byte("payload"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PlaceFile("web", "team-b", "app.conf", src); !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("PlaceFile from team-b = %v, want ErrNamespaceMismatch", err)
+	}
+	if err := m.PlaceFile("web", "team-a", "app.conf", src); err != nil {
+		t.Errorf("PlaceFile from team-a: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package stack
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Content is a file stored with a stack's compose file: a compose override
+// or a project file
+type Content struct {
+	Path string
+	Data []byte
+	Mode os.FileMode // Zero means 0644 under configs/ and 0600 elsewhere
+}
+
+// FileChange is how an apply changes one of a stack's stored files
+type FileChange struct {
+	Path   string
+	Action DiffAction // Create, update or delete
+}
+
+// checkOverride rejects compose overrides that are not YAML file names at
+// the top of the stack's directory
+func checkOverride(name string) error {
+	ext := filepath.Ext(name)
+	if name != filepath.Base(name) || managedFiles[name] || (ext != ".yaml" && ext != ".yml") || !filepath.IsLocal(name) {
+		return fmt.Errorf("%w: compose override %s must be a .yaml or .yml file name", ErrInvalidFile, name)
+	}
+	return nil
+}
+
+// checkProjectFile rejects project files that are neither under configs/
+// or secrets/ nor a dotenv variant such as .env.prod
+func checkProjectFile(rel string) error {
+	if rel != filepath.Clean(rel) || !filepath.IsLocal(rel) || managedFiles[rel] {
+		return fmt.Errorf("%w: %s", ErrInvalidFile, rel)
+	}
+	dir, _, nested := strings.Cut(rel, string(filepath.Separator))
+	if nested && (dir == "configs" || dir == "secrets") || !nested && strings.HasPrefix(rel, ".env.") {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is neither under configs/ or secrets/ nor a dotenv variant such as .env.prod", ErrInvalidFile, rel)
+}
+
+// checkProject validates compose overrides and project files, which may
+// not share paths
+func checkProject(overrides, files []Content) error {
+	seen := make(map[string]bool, len(overrides)+len(files))
+	for _, c := range overrides {
+		if err := checkOverride(c.Path); err != nil {
+			return err
+		}
+		if seen[c.Path] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidFile, c.Path)
+		}
+		seen[c.Path] = true
+	}
+	for _, c := range files {
+		if err := checkProjectFile(c.Path); err != nil {
+			return err
+		}
+		if seen[c.Path] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidFile, c.Path)
+		}
+		seen[c.Path] = true
+	}
+	return nil
+}
+
+// SecretFile reports whether a project file holds secrets: those under
+// secrets/ and dotenv variants
+func SecretFile(rel string) bool {
+	return strings.HasPrefix(rel, "secrets"+string(filepath.Separator)) || strings.HasPrefix(rel, ".env.")
+}
+
+func (c Content) mode() os.FileMode {
+	if c.Mode != 0 {
+		return c.Mode.Perm()
+	}
+	if strings.HasPrefix(c.Path, "configs"+string(filepath.Separator)) {
+		return 0644
+	}
+	return 0600
+}
+
+// readOverrides returns the stored compose overrides of the stack in
+// stackPath, in the order they are merged
+func (m *Manager) readOverrides(stackPath string, md *Metadata) ([]Content, error) {
+	var overrides []Content
+	for _, name := range md.Overrides {
+		data, err := m.sealer.ReadFile(filepath.Join(stackPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("read compose override: %w", err)
+		}
+		overrides = append(overrides, Content{Path: name, Data: data})
+	}
+	return overrides, nil
+}
+
+// readProjectFiles returns the stored project files of the stack in
+// stackPath
+func readProjectFiles(stackPath string, md *Metadata) ([]Content, error) {
+	var files []Content
+	for _, rel := range md.ProjectFiles {
+		path := filepath.Join(stackPath, rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read project file: %w", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("read project file: %w", err)
+		}
+		files = append(files, Content{Path: rel, Data: data, Mode: info.Mode().Perm()})
+	}
+	return files, nil
+}
+
+// diffContents compares stored files with the next ones by path and
+// content
+func diffContents(stored, next []Content) []FileChange {
+	old := make(map[string][]byte, len(stored))
+	for _, c := range stored {
+		old[c.Path] = c.Data
+	}
+
+	var changes []FileChange
+	for _, c := range next {
+		data, ok := old[c.Path]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: c.Path, Action: DiffActionCreate})
+		case !bytes.Equal(data, c.Data):
+			changes = append(changes, FileChange{Path: c.Path, Action: DiffActionUpdate})
+		}
+		delete(old, c.Path)
+	}
+	for path := range old {
+		changes = append(changes, FileChange{Path: path, Action: DiffActionDelete})
+	}
+	return changes
+}
+
+// projectChanges returns how overrides and, unless nil, files change what
+// is stored for the stack in stackPath, by path. Callers hold the lock.
+func (m *Manager) projectChanges(stackPath string, overrides, files []Content) ([]FileChange, error) {
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := m.readOverrides(stackPath, md)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffContents(stored, overrides)
+	if files != nil {
+		if stored, err = readProjectFiles(stackPath, md); err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffContents(stored, files)...)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// writeProject writes the compose file of the stack in stackPath, its
+// overrides and, unless files is nil, its project files, and records them
+// in md. Everything goes to temporary files flushed to disk first, so a
+// failed write leaves the stored project as it was; files the new project
+// lacks are removed once it is in place. Compose files are sealed; project
+// files are not, as Docker reads them.
+func (m *Manager) writeProject(stackPath, compose string, overrides, files []Content, md *Metadata) error {
+	type pending struct{ tmp, dest string }
+	var written []pending
+	fail := func(err error) error {
+		for _, p := range written {
+			os.Remove(p.tmp)
+		}
+		return err
+	}
+
+	write := func(rel string, data []byte, sealed bool, mode os.FileMode) error {
+		dest := filepath.Join(stackPath, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		if sealed {
+			var err error
+			if data, err = m.sealer.Seal(data); err != nil {
+				return fmt.Errorf("write %s: %w", rel, err)
+			}
+		}
+		tmp, err := writeTemp(dest, data, mode)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", rel, err)
+		}
+		written = append(written, pending{tmp: tmp, dest: dest})
+		return nil
+	}
+
+	if err := write("compose.yaml", []byte(compose), true, 0600); err != nil {
+		return fail(err)
+	}
+	for _, c := range overrides {
+		if err := write(c.Path, c.Data, true, 0600); err != nil {
+			return fail(err)
+		}
+	}
+	for _, c := range files {
+		if err := write(c.Path, c.Data, false, c.mode()); err != nil {
+			return fail(err)
+		}
+	}
+	for _, p := range written {
+		if err := commitFile(p.tmp, p.dest); err != nil {
+			return fail(fmt.Errorf("write %s: %w", p.dest, err))
+		}
+	}
+
+	// What the previous apply stored and this one does not goes
+	gone := diffContents(contentsAt(md.Overrides), overrides)
+	names := make([]string, 0, len(overrides))
+	for _, c := range overrides {
+		names = append(names, c.Path)
+	}
+	md.Overrides = names
+	if files != nil {
+		gone = append(gone, diffContents(contentsAt(md.ProjectFiles), files)...)
+		paths := make([]string, 0, len(files))
+		for _, c := range files {
+			paths = append(paths, c.Path)
+		}
+		md.ProjectFiles = paths
+	}
+	for _, change := range gone {
+		if change.Action != DiffActionDelete {
+			continue
+		}
+		if err := removeStackFile(stackPath, change.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contentsAt lists paths as contents without data
+func contentsAt(paths []string) []Content {
+	contents := make([]Content, 0, len(paths))
+	for _, path := range paths {
+		contents = append(contents, Content{Path: path})
+	}
+	return contents
+}
@@ -0,0 +1,36 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// StopStack stops a stack's containers without removing them, so
+// StartStack can bring it back as it was, as before a host reboot
+func (m *Manager) StopStack(ctx context.Context, name string) error {
+	release, err := m.lockStack(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := m.compose(ctx, name, filepath.Join(m.stackRoot, name), "stop"); err != nil {
+		return fmt.Errorf("compose stop: %w", err)
+	}
+	return nil
+}
+
+// StartStack starts the containers of a stack StopStack stopped
+func (m *Manager) StartStack(ctx context.Context, name string) error {
+	release, err := m.lockStack(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := m.compose(ctx, name, filepath.Join(m.stackRoot, name), "start"); err != nil {
+		return fmt.Errorf("compose start: %w", err)
+	}
+	return nil
+}
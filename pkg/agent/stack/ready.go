@@ -0,0 +1,136 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// DefaultReadyTimeout is how long an apply waits for its services to be
+// ready when neither the request nor the agent config sets a timeout
+const DefaultReadyTimeout = 5 * time.Minute
+
+// readyPollInterval is how often an apply looks at its services while
+// waiting for them
+var readyPollInterval = 2 * time.Second
+
+// SetReadyTimeout sets how long applies wait for their services to be ready
+// unless the request sets its own timeout. Zero means DefaultReadyTimeout.
+func (m *Manager) SetReadyTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readyTimeout = d
+}
+
+// readyTimeoutFor returns the timeout an apply waits with
+func (m *Manager) readyTimeoutFor(req *ApplyStackRequest) time.Duration {
+	if req.ReadyTimeout > 0 {
+		return req.ReadyTimeout
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.readyTimeout > 0 {
+		return m.readyTimeout
+	}
+	return DefaultReadyTimeout
+}
+
+// expectedServices returns the services an apply starts containers for:
+// the requested ones, or every service not scaled to zero
+func expectedServices(project *types.Project, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	var services []string
+	for _, svc := range project.Services {
+		if svc.Deploy != nil && svc.Deploy.Replicas != nil && *svc.Deploy.Replicas == 0 {
+			continue
+		}
+		services = append(services, svc.Name)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// serviceReadiness reports whether every container of a service is ready,
+// that is running and passing its healthcheck if it has one, or exited
+// successfully. The description says why not, or how it is ready.
+func serviceReadiness(containers []ContainerInfo) (bool, string) {
+	if len(containers) == 0 {
+		return false, "no container yet"
+	}
+
+	healthy, completed := false, false
+	for _, c := range containers {
+		switch {
+		case c.State == "exited" && strings.HasPrefix(c.Status, "Exited (0)"):
+			completed = true
+		case c.State != "running":
+			return false, fmt.Sprintf("container %s is %s", c.Name, c.State)
+		case strings.Contains(c.Status, "(health: starting)"):
+			return false, "waiting for healthcheck"
+		case strings.Contains(c.Status, "(unhealthy)"):
+			return false, "unhealthy"
+		case strings.Contains(c.Status, "(healthy)"):
+			healthy = true
+		}
+	}
+
+	switch {
+	case healthy:
+		return true, "healthy"
+	case completed:
+		return true, "completed"
+	}
+	return true, "running"
+}
+
+// waitReady waits until every one of services is ready, emitting a line on
+// the operation each time a service's readiness changes
+func (m *Manager) waitReady(ctx context.Context, opID, stackName string, services []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	reported := make(map[string]string, len(services))
+	var pending []string
+	for {
+		containers, err := m.getStackContainers(ctx, stackName)
+		if err == nil {
+			byService := make(map[string][]ContainerInfo)
+			for _, c := range containers {
+				byService[c.Service] = append(byService[c.Service], c)
+			}
+
+			pending = pending[:0]
+			for _, svc := range services {
+				ready, detail := serviceReadiness(byService[svc])
+				if reported[svc] != detail {
+					reported[svc] = detail
+					m.opMgr.EmitEvent(opID, fmt.Sprintf("Service %s: %s", svc, detail))
+				}
+				if !ready {
+					pending = append(pending, fmt.Sprintf("%s (%s)", svc, detail))
+				}
+			}
+			if len(pending) == 0 {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if len(pending) == 0 && err != nil {
+				return fmt.Errorf("check services: %w", err)
+			}
+			return fmt.Errorf("services not ready after %s: %s", timeout, strings.Join(pending, ", "))
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/moby/moby/client"
+)
+
+func TestReadyTimeoutFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  time.Duration // SetReadyTimeout
+		request time.Duration
+		want    time.Duration
+	}{
+		{name: "default", want: DefaultReadyTimeout},
+		{name: "agent config", config: 2 * time.Minute, want: 2 * time.Minute},
+		{name: "request", request: 30 * time.Second, want: 30 * time.Second},
+		{name: "request over agent config", config: 2 * time.Minute, request: 10 * time.Minute, want: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(t.TempDir(), nil, nil, nil, nil)
+			m.SetReadyTimeout(tt.config)
+			if got := m.readyTimeoutFor(&ApplyStackRequest{ReadyTimeout: tt.request}); got != tt.want {
+				t.Errorf("readyTimeoutFor = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedServices(t *testing.T) {
+	zero, two := 0, 2
+	project := &types.Project{Services: types.Services{
+		"web":    {Name: "web"},
+		"db":     {Name: "db", Deploy: &types.DeployConfig{Replicas: &two}},
+		"worker": {Name: "worker", Deploy: &types.DeployConfig{Replicas: &zero}},
+		"cache":  {Name: "cache", Deploy: &types.DeployConfig{}},
+	}}
+
+	if got, want := expectedServices(project, nil), []string{"cache", "db", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expectedServices = %v, want %v", got, want)
+	}
+	if got, want := expectedServices(project, []string{"worker"}), []string{"worker"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expectedServices(worker) = %v, want %v", got, want)
+	}
+}
+
+func TestServiceReadiness(t *testing.T) {
+	running := func(name, status string) ContainerInfo {
+		return ContainerInfo{Name: name, State: "running", Status: status}
+	}
+	tests := []struct {
+		name       string
+		containers []ContainerInfo
+		ready      bool
+		detail     string
+	}{
+		{name: "no container", ready: false, detail: "no container yet"},
+		{name: "running", containers: []ContainerInfo{running("web-1", "Up 3 seconds")}, ready: true, detail: "running"},
+		{name: "healthy", containers: []ContainerInfo{running("web-1", "Up 1 minute (healthy)")}, ready: true, detail: "healthy"},
+		{name: "health starting", containers: []ContainerInfo{running("web-1", "Up 2 seconds (health: starting)")}, ready: false, detail: "waiting for healthcheck"},
+		{name: "unhealthy", containers: []ContainerInfo{running("web-1", "Up 1 minute (unhealthy)")}, ready: false, detail: "unhealthy"},
+		{name: "completed", containers: []ContainerInfo{{Name: "migrate-1", State: "exited", Status: "Exited (0) 5 seconds ago"}}, ready: true, detail: "completed"},
+		{name: "exited with an error", containers: []ContainerInfo{{Name: "migrate-1", State: "exited", Status: "Exited (1) 5 seconds ago"}}, ready: false, detail: "container migrate-1 is exited"},
+		{name: "restarting", containers: []ContainerInfo{{Name: "web-1", State: "restarting", Status: "Restarting (1) 2 seconds ago"}}, ready: false, detail: "container web-1 is restarting"},
+		{
+			name:       "one replica not yet healthy",
+			containers: []ContainerInfo{running("web-1", "Up 1 minute (healthy)"), running("web-2", "Up 1 second (health: starting)")},
+			ready:      false,
+			detail:     "waiting for healthcheck",
+		},
+		{
+			name:       "all replicas healthy",
+			containers: []ContainerInfo{running("web-1", "Up 1 minute (healthy)"), running("web-2", "Up 1 minute (healthy)")},
+			ready:      true,
+			detail:     "healthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, detail := serviceReadiness(tt.containers)
+			if ready != tt.ready || detail != tt.detail {
+				t.Errorf("serviceReadiness = %v, %q; want %v, %q", ready, detail, tt.ready, tt.detail)
+			}
+		})
+	}
+}
+
+// containerDocker serves a Docker API listing, at each poll, the next of
+// polls' containers of stack web by service and status; the last repeats
+func containerDocker(t *testing.T, polls ...map[string]string) *client.Client {
+	t.Helper()
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_ping") {
+			w.Header().Set("Api-Version", client.MaxAPIVersion)
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/containers/json") {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		poll := polls[min(calls, len(polls)-1)]
+		calls++
+		mu.Unlock()
+
+		list := []map[string]any{}
+		for service, status := range poll {
+			state := "running"
+			if strings.HasPrefix(status, "Exited") {
+				state = "exited"
+			}
+			list = append(list, map[string]any{
+				"Id":     service + "0123456789abcdef",
+				"Names":  []string{"/web-" + service + "-1"},
+				"Labels": map[string]string{"com.docker.compose.project": "web", "com.docker.compose.service": service},
+				"State":  state,
+				"Status": status,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+
+	docker, err := client.New(client.WithHost("tcp://" + server.Listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { docker.Close() })
+	return docker
+}
+
+func TestWaitReady(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		polls   []map[string]string
+		timeout time.Duration
+		err     string // Empty when the services become ready
+		events  []string
+	}{
+		{
+			name: "healthcheck passes",
+			polls: []map[string]string{
+				{},
+				{"web": "Up 1 second (health: starting)", "migrate": "Up 1 second"},
+				{"web": "Up 2 seconds (healthy)", "migrate": "Exited (0) 1 second ago"},
+			},
+			timeout: 5 * time.Second,
+			events: []string{
+				"Service migrate: no container yet",
+				"Service web: no container yet",
+				"Service migrate: running",
+				"Service web: waiting for healthcheck",
+				"Service migrate: completed",
+				"Service web: healthy",
+			},
+		},
+		{
+			name:    "unhealthy",
+			polls:   []map[string]string{{"web": "Up 1 minute (unhealthy)", "migrate": "Exited (0) 1 second ago"}},
+			timeout: 100 * time.Millisecond,
+			err:     "services not ready after 100ms: web (unhealthy)",
+			events:  []string{"Service migrate: completed", "Service web: unhealthy"},
+		},
+		{
+			name:    "exited",
+			polls:   []map[string]string{{"web": "Up 1 minute", "migrate": "Exited (1) 1 second ago"}},
+			timeout: 100 * time.Millisecond,
+			err:     "services not ready after 100ms: migrate (container /web-migrate-1 is exited)",
+			events:  []string{"Service migrate: container /web-migrate-1 is exited", "Service web: running"},
+		},
+		{
+			name:    "health still starting",
+			polls:   []map[string]string{{"web": "Up 1 second (health: starting)", "migrate": "Exited (0) 1 second ago"}},
+			timeout: 50 * time.Millisecond,
+			err:     "services not ready after 50ms: web (waiting for healthcheck)",
+			events:  []string{"Service migrate: completed", "Service web: waiting for healthcheck"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := operation.NewManager()
+			m := NewManager(t.TempDir(), containerDocker(t, tt.polls...), ops, nil, nil)
+			opID := ops.CreateOperation(context.Background(), operation.OperationTypeStackApply, nil)
+			events := ops.Subscribe(opID)
+			<-events // The current state
+
+			began := time.Now()
+			err := m.waitReady(context.Background(), opID, "web", []string{"migrate", "web"}, tt.timeout)
+			if tt.err == "" && err != nil {
+				t.Fatalf("waitReady = %v", err)
+			}
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("waitReady = %v, want %q", err, tt.err)
+				}
+				if elapsed := time.Since(began); elapsed < tt.timeout {
+					t.Errorf("gave up after %s, before the %s timeout", elapsed, tt.timeout)
+				}
+			}
+
+			var got []string
+			for len(events) > 0 {
+				got = append(got, (<-events).Message)
+			}
+			if !reflect.DeepEqual(got, tt.events) {
+				t.Errorf("events = %q, want %q", got, tt.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package stack
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bhangun/mandau/pkg/agent/operation"
+)
+
+// ResumeInterrupted reports stack operations cut short by an agent restart.
+// With reconcile set, each affected stack is brought back in line with what
+// is stored for it: interrupted applies re-run compose up from the stored
+// compose file and interrupted removals finish removing the stack. The new
+// operations carry the interrupted operation's ID as "resumes".
+func (m *Manager) ResumeInterrupted(ops []*operation.Operation, reconcile bool) {
+	for _, op := range ops {
+		name := op.Metadata["stack"]
+		if name == "" {
+			continue
+		}
+
+		log.Printf("Operation %s (%s) on stack %s was interrupted by a restart (last step: %q)",
+			op.ID, op.Type, name, op.Message)
+		m.opMgr.EmitEvent(op.ID, "Interrupted by agent restart")
+
+		if !reconcile {
+			continue
+		}
+
+		switch op.Type {
+		case operation.OperationTypeStackApply:
+			m.resumeApply(op.ID, name)
+		case operation.OperationTypeStackRemove:
+			m.resumeRemove(op.ID, name, op.Metadata["remove_volumes"] == "true")
+		}
+	}
+}
+
+func (m *Manager) resumeApply(interruptedID, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stackPath := filepath.Join(m.stackRoot, name)
+	content, err := m.sealer.ReadFile(filepath.Join(stackPath, "compose.yaml"))
+	if err != nil {
+		log.Printf("Cannot reconcile stack %s: %v", name, err)
+		return
+	}
+	release, err := m.lockStack(name)
+	if err != nil {
+		log.Printf("Cannot reconcile stack %s: %v", name, err)
+		return
+	}
+
+	opID := m.opMgr.CreateOperation(context.Background(), operation.OperationTypeStackApply, map[string]string{
+		"stack":   name,
+		"resumes": interruptedID,
+	})
+	log.Printf("Reconciling stack %s to its stored compose file (operation %s)", name, opID)
+
+	// The stored .env is picked up by compose, so only the compose file is passed
+	go func() {
+		defer release()
+		m.executeApply(context.Background(), opID, &ApplyStackRequest{
+			StackName:      name,
+			ComposeContent: string(content),
+		}, stackPath, nil)
+	}()
+}
+
+func (m *Manager) resumeRemove(interruptedID, name string, removeVolumes bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stackPath := filepath.Join(m.stackRoot, name)
+	if _, err := os.Stat(stackPath); os.IsNotExist(err) {
+		// The removal got as far as deleting the directory
+		return
+	}
+	release, err := m.lockStack(name)
+	if err != nil {
+		log.Printf("Cannot finish removing stack %s: %v", name, err)
+		return
+	}
+
+	opID := m.opMgr.CreateOperation(context.Background(), operation.OperationTypeStackRemove, map[string]string{
+		"stack":          name,
+		"resumes":        interruptedID,
+		"remove_volumes": strconv.FormatBool(removeVolumes),
+	})
+	log.Printf("Finishing removal of stack %s (operation %s)", name, opID)
+
+	go func() {
+		defer release()
+		m.executeRemove(context.Background(), opID, name, stackPath, removeVolumes)
+	}()
+}
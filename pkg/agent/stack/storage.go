@@ -0,0 +1,62 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/requestid"
+)
+
+// Storage keeps the history of the stack root. Applies and removals record
+// each stack they change once its files are written; the files themselves
+// stay in the stack root either way.
+type Storage interface {
+	// Record keeps what changed in the directory of stack name, made for
+	// the requester by (empty for the agent itself) and described by
+	// message
+	Record(name, by, message string) error
+}
+
+// SetStorage sets where stack changes are recorded; nil keeps the stack
+// root as plain files without history
+func (m *Manager) SetStorage(s Storage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.storage = s
+}
+
+type authorKey struct{}
+
+// WithAuthor names who changes made with ctx are recorded as made for
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorKey{}, author)
+}
+
+// authorFrom returns the author WithAuthor set on ctx
+func authorFrom(ctx context.Context) string {
+	author, _ := ctx.Value(authorKey{}).(string)
+	return author
+}
+
+// recordChange records a change to stack name with the author and request
+// ID of ctx. Failing to is logged, as the change itself has been made.
+func (m *Manager) recordChange(ctx context.Context, storage Storage, verb, name, ns, opID string) {
+	if storage == nil {
+		return
+	}
+	lines := []string{fmt.Sprintf("%s %s", verb, name), ""}
+	if ns != "" {
+		lines = append(lines, "Namespace: "+ns)
+	}
+	if opID != "" {
+		lines = append(lines, "Operation: "+opID)
+	}
+	if id := requestid.From(ctx); id != "" {
+		lines = append(lines, "Request: "+id)
+	}
+	if err := storage.Record(name, authorFrom(ctx), strings.Join(lines, "\n")); err != nil {
+		log.Printf("Cannot record %s of stack %s: %v", strings.ToLower(verb), name, err)
+	}
+}
@@ -0,0 +1,260 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/transfer"
+)
+
+var (
+	// ErrFilesMissing is returned when an apply lists files that were
+	// neither staged nor are in the stack's directory already
+	ErrFilesMissing = errors.New("stack files were not uploaded")
+	// ErrInvalidFile is returned for stack file paths that leave the
+	// stack's directory or name what applies write themselves
+	ErrInvalidFile = errors.New("invalid stack file")
+)
+
+// File is a file next to a stack's compose file, by its hash
+type File struct {
+	Path   string      `yaml:"path"`
+	SHA256 string      `yaml:"sha256"`
+	Size   int64       `yaml:"size"`
+	Mode   os.FileMode `yaml:"mode,omitempty"`
+}
+
+// FileDiff is what an agent lacks of a set of stack files
+type FileDiff struct {
+	Changed   []string // Missing or different, in the order listed
+	Removed   []string // Synced by an earlier apply, no longer listed
+	Unchanged int
+}
+
+// stagePath is where files uploaded for the next apply of stack name wait
+func (m *Manager) stagePath(name string) string {
+	return filepath.Join(filepath.Clean(m.stackRoot)+".staging", name)
+}
+
+// checkNewNamespace is checkNamespace for stacks that may not exist yet
+func checkNewNamespace(stackPath, ns string) error {
+	if _, err := os.Stat(stackPath); err != nil {
+		return nil
+	}
+	return checkNamespace(stackPath, ns)
+}
+
+// checkFile rejects paths that leave the stack's directory or name what
+// applies write themselves
+func checkFile(name, rel string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	if rel != filepath.Clean(rel) || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w %s/%s", ErrInvalidFile, name, rel)
+	}
+	if managedFiles[rel] || rel == "hooks" || strings.HasPrefix(rel, "hooks"+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s is written by stack applies", ErrInvalidFile, rel)
+	}
+	return nil
+}
+
+// checkFiles validates a set of stack files and returns it by path
+func checkFiles(name string, files []File) (map[string]File, error) {
+	byPath := make(map[string]File, len(files))
+	for _, f := range files {
+		if err := checkFile(name, f.Path); err != nil {
+			return nil, err
+		}
+		if _, ok := byPath[f.Path]; ok {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidFile, f.Path)
+		}
+		byPath[f.Path] = f
+	}
+	return byPath, nil
+}
+
+// holds reports whether path has the content f describes
+func holds(path string, f File) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil || !info.Mode().IsRegular() || info.Size() != f.Size {
+		return false
+	}
+	digest, err := transfer.Digest(file)
+	return err == nil && digest == f.SHA256
+}
+
+// DiffFiles compares files with those in the directory of stack name, or
+// staged for its next apply, and returns what the agent lacks. The stack,
+// if it exists, must be in namespace ns.
+func (m *Manager) DiffFiles(name, ns string, files []File) (*FileDiff, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	byPath, err := checkFiles(name, files)
+	if err != nil {
+		return nil, err
+	}
+
+	stackPath := filepath.Join(m.stackRoot, name)
+	m.mu.RLock()
+	md, err := readMetadata(stackPath)
+	if err == nil {
+		err = checkNewNamespace(stackPath, ns)
+	}
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Hashing may take a while, so applies are not held up by it
+	diff := &FileDiff{}
+	for _, f := range files {
+		if holds(filepath.Join(stackPath, f.Path), f) || holds(filepath.Join(m.stagePath(name), f.Path), f) {
+			diff.Unchanged++
+			continue
+		}
+		diff.Changed = append(diff.Changed, f.Path)
+	}
+	for _, f := range md.Files {
+		if _, ok := byPath[f.Path]; !ok {
+			diff.Removed = append(diff.Removed, f.Path)
+		}
+	}
+	return diff, nil
+}
+
+// StageFile writes what r holds to rel among the files waiting for the
+// next apply of stack name, which, if it exists, must be in namespace ns.
+// It returns the bytes written.
+func (m *Manager) StageFile(name, ns, rel string, r io.Reader) (int64, error) {
+	if err := checkFile(name, rel); err != nil {
+		return 0, err
+	}
+	m.mu.RLock()
+	err := checkNewNamespace(filepath.Join(m.stackRoot, name), ns)
+	m.mu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+
+	dest := filepath.Join(m.stagePath(name), rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return 0, fmt.Errorf("create staging directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".stage-*")
+	if err != nil {
+		return 0, fmt.Errorf("stage file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, fmt.Errorf("stage %s: %w", rel, err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return n, fmt.Errorf("stage %s: %w", rel, err)
+	}
+	return n, nil
+}
+
+// fileSync is what bringing a stack's directory to a set of files takes
+type fileSync struct {
+	stage  string
+	place  []File   // Staged, moved into the directory
+	keep   []File   // Unchanged; only their mode is set
+	remove []string // Synced by an earlier apply, no longer listed
+}
+
+// planFiles works out how to bring the directory of stack name to files,
+// failing with ErrFilesMissing if any are neither staged nor unchanged.
+// Called with m.mu held.
+func (m *Manager) planFiles(stackPath, name string, files []File) (*fileSync, error) {
+	byPath, err := checkFiles(name, files)
+	if err != nil {
+		return nil, err
+	}
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sync := &fileSync{stage: m.stagePath(name)}
+	var missing []string
+	for _, f := range files {
+		switch {
+		case holds(filepath.Join(sync.stage, f.Path), f):
+			sync.place = append(sync.place, f)
+		case holds(filepath.Join(stackPath, f.Path), f):
+			sync.keep = append(sync.keep, f)
+		default:
+			missing = append(missing, f.Path)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrFilesMissing, strings.Join(missing, ", "))
+	}
+	for _, f := range md.Files {
+		if _, ok := byPath[f.Path]; !ok {
+			sync.remove = append(sync.remove, f.Path)
+		}
+	}
+	return sync, nil
+}
+
+// apply removes the files no longer listed from stackPath, moves the
+// staged ones into it and sets the mode of the unchanged ones
+func (s *fileSync) apply(stackPath string) error {
+	// Removed first, as a listed file may take the path of a directory
+	// they leave empty
+	for _, rel := range s.remove {
+		if err := removeStackFile(stackPath, rel); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.place {
+		dest := filepath.Join(stackPath, f.Path)
+		if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		if err := os.Rename(filepath.Join(s.stage, f.Path), dest); err != nil {
+			return fmt.Errorf("place %s: %w", f.Path, err)
+		}
+	}
+	for _, f := range append(s.place, s.keep...) {
+		if f.Mode == 0 {
+			continue
+		}
+		if err := os.Chmod(filepath.Join(stackPath, f.Path), f.Mode.Perm()); err != nil {
+			return fmt.Errorf("set mode of %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// removeStackFile removes rel from stackPath, and the directories that
+// leaves empty
+func removeStackFile(stackPath, rel string) error {
+	path := filepath.Join(stackPath, rel)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", rel, err)
+	}
+	// Remove fails on directories that are not empty
+	for dir := filepath.Dir(path); dir != stackPath; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
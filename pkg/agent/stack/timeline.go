@@ -0,0 +1,174 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/agent/timeline"
+)
+
+// SetTimeline records applies and removals in tl from now on. Until it is
+// called none are recorded.
+func (m *Manager) SetTimeline(tl *timeline.Timeline) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeline = tl
+}
+
+// applyPlan is what an apply changes, worked out before the compose file
+// is replaced
+type applyPlan struct {
+	changes []string // One line per change, as in stack diffs
+	scales  []scaleChange
+}
+
+type scaleChange struct {
+	service  string
+	from, to int
+}
+
+// planApply compares what req applies with what is stored for its stack,
+// for its timeline. Callers hold the lock.
+func (m *Manager) planApply(ctx context.Context, req *ApplyStackRequest) *applyPlan {
+	if m.timeline == nil {
+		return nil
+	}
+	name := req.StackName
+	next, err := m.parseCompose(ctx, name, []byte(req.ComposeContent), filepath.Join(m.stackRoot, name), req.Overrides...)
+	if err != nil {
+		// The apply fails on it and records why
+		return nil
+	}
+
+	plan := &applyPlan{}
+	// Changed files follow the services, for new stacks too
+	defer func() {
+		files, _ := m.projectChanges(filepath.Join(m.stackRoot, name), req.Overrides, req.ProjectFiles)
+		for _, f := range files {
+			plan.changes = append(plan.changes, "file "+f.Path+": "+fileAction(f.Action))
+		}
+	}()
+
+	current, err := m.loadStack(ctx, name)
+	if err != nil {
+		for _, svc := range next.Services {
+			plan.changes = append(plan.changes, svc.Name+": added")
+		}
+		sort.Strings(plan.changes)
+		return plan
+	}
+
+	diff := m.computeDiff(current.Project, next)
+	sort.Slice(diff.Services, func(i, j int) bool { return diff.Services[i].Name < diff.Services[j].Name })
+	for _, svc := range diff.Services {
+		switch svc.Action {
+		case DiffActionCreate:
+			plan.changes = append(plan.changes, svc.Name+": added")
+		case DiffActionDelete:
+			plan.changes = append(plan.changes, svc.Name+": removed")
+		default:
+			for _, change := range svc.Changes {
+				plan.changes = append(plan.changes, svc.Name+": "+change)
+			}
+		}
+	}
+
+	for _, svc := range next.Services {
+		old, ok := current.Project.Services[svc.Name]
+		if ok && old.GetScale() != svc.GetScale() {
+			plan.scales = append(plan.scales, scaleChange{service: svc.Name, from: old.GetScale(), to: svc.GetScale()})
+		}
+	}
+	sort.Slice(plan.scales, func(i, j int) bool { return plan.scales[i].service < plan.scales[j].service })
+	return plan
+}
+
+// fileAction names what a file change does, for timelines
+func fileAction(action DiffAction) string {
+	switch action {
+	case DiffActionCreate:
+		return "added"
+	case DiffActionDelete:
+		return "removed"
+	}
+	return "changed"
+}
+
+// recordApply adds an apply to the stack's timeline, with the services it
+// scaled when it succeeded
+func (m *Manager) recordApply(req *ApplyStackRequest, opID string, plan *applyPlan, applyErr error) {
+	m.mu.RLock()
+	tl := m.timeline
+	m.mu.RUnlock()
+	if tl == nil {
+		return
+	}
+	if plan == nil {
+		plan = &applyPlan{}
+	}
+
+	e := timeline.Event{
+		Kind:      timeline.KindApply,
+		Details:   plan.changes,
+		Operation: opID,
+		Namespace: stackNamespace(filepath.Join(m.stackRoot, req.StackName)),
+	}
+	switch {
+	case applyErr != nil:
+		e.Summary, e.Failed = "Apply failed: "+applyErr.Error(), true
+	case len(plan.changes) == 0:
+		e.Summary = "Applied without compose changes"
+	default:
+		e.Summary = fmt.Sprintf("Applied %d changes", len(plan.changes))
+	}
+	if len(req.Services) > 0 {
+		e.Summary += " to " + strings.Join(req.Services, ", ")
+	}
+	m.record(tl, req.StackName, e)
+
+	if applyErr != nil {
+		return
+	}
+	for _, s := range plan.scales {
+		m.record(tl, req.StackName, timeline.Event{
+			Kind:      timeline.KindScale,
+			Service:   s.service,
+			Summary:   fmt.Sprintf("Scaled from %d to %d replicas", s.from, s.to),
+			Operation: opID,
+		})
+	}
+}
+
+// recordRemove adds a removal to the stack's timeline. ns is the namespace
+// the stack was in, read before its directory went away.
+func (m *Manager) recordRemove(stackName, ns, opID string, removeErr error) {
+	m.mu.RLock()
+	tl := m.timeline
+	m.mu.RUnlock()
+
+	e := timeline.Event{Kind: timeline.KindRemove, Summary: "Removed", Operation: opID, Namespace: ns}
+	if removeErr != nil {
+		e.Summary, e.Failed = "Removal failed: "+removeErr.Error(), true
+	}
+	m.record(tl, stackName, e)
+}
+
+// stackNamespace is the namespace of the stack at stackPath, kept with its
+// applies and removals so its timeline can be checked after removal
+func stackNamespace(stackPath string) string {
+	md, err := readMetadata(stackPath)
+	if err != nil {
+		return DefaultNamespace
+	}
+	return NormalizeNamespace(md.Namespace)
+}
+
+func (m *Manager) record(tl *timeline.Timeline, stackName string, e timeline.Event) {
+	if err := tl.Record(stackName, e); err != nil {
+		log.Printf("Cannot record %s of stack %s in its timeline: %v", e.Kind, stackName, err)
+	}
+}
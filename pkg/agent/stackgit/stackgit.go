@@ -0,0 +1,140 @@
+// Package stackgit keeps the stack root in a git repository, committing
+// each stack an apply or removal changes with who asked for it as author,
+// so stack definitions have history, blame and revert through standard git
+// tooling run in the stack root.
+package stackgit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Committer is who commits are made by; their authors are the requesters
+const Committer = "mandau-agent"
+
+// excluded are never committed: what the agent writes for itself, and
+// project files holding secrets, which are not sealed
+var excluded = []string{
+	".mandau.lock",
+	".mandau-write-*",
+	"compose.yaml.prev",
+	".env.prev",
+	".mandau.yaml.prev",
+	"/*/secrets/",
+	"/*/.env.*",
+}
+
+// Repo is a stack root kept in git
+type Repo struct {
+	mu   sync.Mutex // git takes its own index lock; calls wait here instead
+	root string
+}
+
+// Open makes root a git repository unless it is one already. A new
+// repository starts with a commit of the stacks already in root.
+func Open(root string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("stack history: %w", err)
+	}
+	r := &Repo{root: root}
+
+	_, err := os.Stat(filepath.Join(root, ".git"))
+	fresh := os.IsNotExist(err)
+	if fresh {
+		if err := os.MkdirAll(root, 0700); err != nil {
+			return nil, fmt.Errorf("stack history: %w", err)
+		}
+		if _, err := r.git("init", "--quiet"); err != nil {
+			return nil, fmt.Errorf("stack history: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("stack history: %w", err)
+	}
+
+	exclude := filepath.Join(root, ".git", "info", "exclude")
+	if err := os.MkdirAll(filepath.Dir(exclude), 0700); err != nil {
+		return nil, fmt.Errorf("stack history: %w", err)
+	}
+	if err := os.WriteFile(exclude, []byte(strings.Join(excluded, "\n")+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("stack history: %w", err)
+	}
+
+	if fresh {
+		if _, err := r.git("add", "--all"); err != nil {
+			return nil, fmt.Errorf("stack history: %w", err)
+		}
+		if _, err := r.git("commit", "--quiet", "--allow-empty", "--author", author(""), "--message", "Start stack history"); err != nil {
+			return nil, fmt.Errorf("stack history: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// Record commits what changed in the directory of stack name, if anything,
+// authored by the requester by; empty means the agent itself
+func (r *Repo) Record(name, by, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.git("add", "--all", "--", name); err != nil {
+		if _, statErr := os.Stat(filepath.Join(r.root, name)); os.IsNotExist(statErr) {
+			// Neither on disk nor ever committed
+			return nil
+		}
+		return fmt.Errorf("record stack %s: %w", name, err)
+	}
+	// diff exits with 1 when there are changes
+	_, err := r.git("diff", "--cached", "--quiet", "--", name)
+	var exitErr *exec.ExitError
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return fmt.Errorf("record stack %s: %w", name, err)
+	}
+	if _, err := r.git("commit", "--quiet", "--author", author(by), "--message", message, "--", name); err != nil {
+		return fmt.Errorf("record stack %s: %w", name, err)
+	}
+	return nil
+}
+
+// author formats a requester as a git author, which needs an email; the
+// empty one stands for none
+func author(by string) string {
+	by = strings.Map(func(r rune) rune {
+		if r == '<' || r == '>' || r == '\n' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(by))
+	if by == "" {
+		by = Committer
+	}
+	return by + " <>"
+}
+
+// git runs git in the repository and returns its output
+func (r *Repo) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.root}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_COMMITTER_NAME="+Committer,
+		"GIT_COMMITTER_EMAIL=",
+		"GIT_CONFIG_NOSYSTEM=1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return out, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package stackgit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitLog(t *testing.T, root string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", root, "log", "--format=%an|%s"}, args...)...).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRecord(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("web/compose.yaml", "services: {}\n")
+
+	repo, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("web/compose.yaml", "services:\n  web:\n    image: nginx\n")
+	write("web/.mandau.lock", "")
+	write("web/compose.yaml.prev", "services: {}\n")
+	write("web/secrets/token", "s3cret")
+	write("web/configs/app.conf", "debug = false\n")
+	if err := repo.Record("web", "alice", "Apply web"); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing changed, so nothing is committed
+	if err := repo.Record("web", "bob", "Apply web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Record("api", "bob", "Remove api"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := gitLog(t, root), "alice|Apply web\n"+Committer+"|Start stack history"; got != want {
+		t.Fatalf("log = %q, want %q", got, want)
+	}
+	out, err := exec.Command("git", "-C", root, "ls-files").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(string(out)), []string{"web/compose.yaml", "web/configs/app.conf"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("committed %v, want %v", got, want)
+	}
+
+	if err := os.RemoveAll(filepath.Join(root, "web")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Record("web", "carol <carol@example.com>", "Remove web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitLog(t, root, "-1"); got != "carol carol@example.com|Remove web" {
+		t.Fatalf("last commit = %q", got)
+	}
+
+	// Reopening keeps the history
+	if _, err := Open(root); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(gitLog(t, root), "\n") + 1; got != 3 {
+		t.Fatalf("%d commits after reopening, want 3", got)
+	}
+}
@@ -0,0 +1,104 @@
+package timeline
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DomainsLabel on a stack lists the domains it serves, comma separated, so
+// renewals of their certificates appear in its timeline
+const DomainsLabel = "domains"
+
+// Certificate is a certificate in a certbot style live directory
+type Certificate struct {
+	Name     string // Its directory, certbot's certificate name
+	Domains  []string
+	NotAfter time.Time
+}
+
+// ScanCertificates reads the certificate of each directory under dir, such
+// as /etc/letsencrypt/live/<name>/cert.pem. A missing dir has none.
+func ScanCertificates(dir string) (map[string]Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make(map[string]Certificate)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), "cert.pem"))
+		if err != nil {
+			continue
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		domains := cert.DNSNames
+		if len(domains) == 0 && cert.Subject.CommonName != "" {
+			domains = []string{cert.Subject.CommonName}
+		}
+		certs[e.Name()] = Certificate{Name: e.Name(), Domains: domains, NotAfter: cert.NotAfter}
+	}
+	return certs, nil
+}
+
+// Renewed returns the certificates of after that expire later than they
+// did in before, by name. Certificates new in after were issued rather
+// than renewed and are left out.
+func Renewed(before, after map[string]Certificate) []Certificate {
+	var renewed []Certificate
+	for name, cert := range after {
+		if old, ok := before[name]; ok && cert.NotAfter.After(old.NotAfter) {
+			renewed = append(renewed, cert)
+		}
+	}
+	sort.Slice(renewed, func(i, j int) bool { return renewed[i].Name < renewed[j].Name })
+	return renewed
+}
+
+// Covers reports whether the certificate is valid for domain, through a
+// wildcard name too
+func (c Certificate) Covers(domain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, name := range c.Domains {
+		name = strings.ToLower(name)
+		if name == domain {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(name, "*."); ok {
+			if head, rest, found := strings.Cut(domain, "."); found && head != "" && rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StackDomains reads the domains a stack's labels list under DomainsLabel
+func StackDomains(labels map[string]string) []string {
+	var domains []string
+	for _, d := range strings.Split(labels[DomainsLabel], ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
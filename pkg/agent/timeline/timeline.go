@@ -0,0 +1,240 @@
+// Package timeline records what happened to each stack: applies with what
+// they changed, scaling, removals, crashes, restarts, health changes and
+// certificate renewals, so operators can see what changed before an
+// outage. Each stack's events are kept in a JSON lines file bounded in
+// length; a stack's timeline outlives its removal.
+package timeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEvents is how many events are kept per stack
+const DefaultMaxEvents = 1000
+
+// Event kinds
+const (
+	KindApply       = "apply"
+	KindRemove      = "remove"
+	KindScale       = "scale"
+	KindCrash       = "crash"
+	KindOOM         = "oom"
+	KindRestart     = "restart"
+	KindUnhealthy   = "unhealthy"
+	KindHealthy     = "healthy" // Recovered after being unhealthy
+	KindCertificate = "certificate"
+	KindExternal    = "external" // Containers created outside the agent
+)
+
+// ErrInvalidStack is returned for stack names that are not one path element
+var ErrInvalidStack = errors.New("invalid stack name")
+
+// Event is something that happened to a stack
+type Event struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Service   string    `json:"service,omitempty"`
+	Summary   string    `json:"summary"`
+	Details   []string  `json:"details,omitempty"` // Such as the changes an apply made
+	Failed    bool      `json:"failed,omitempty"`
+	Operation string    `json:"operation,omitempty"`
+	Namespace string    `json:"namespace,omitempty"` // Set on applies and removals
+}
+
+// Timeline keeps the events of every stack under a directory
+type Timeline struct {
+	dir       string
+	maxEvents int
+
+	mu     sync.Mutex
+	counts map[string]int // Lines in each stack's file, once known
+}
+
+// Open returns the timeline under dir, keeping at most maxEvents per stack
+func Open(dir string, maxEvents int) (*Timeline, error) {
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Timeline{dir: dir, maxEvents: maxEvents, counts: make(map[string]int)}, nil
+}
+
+func (t *Timeline) path(stack string) (string, error) {
+	if stack == "" || stack != filepath.Base(stack) || stack == "." || stack == ".." {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStack, stack)
+	}
+	return filepath.Join(t.dir, stack+".jsonl"), nil
+}
+
+// Record appends an event to a stack's timeline, stamped now when it has
+// no time. A nil timeline records nothing.
+func (t *Timeline) Record(stack string, e Event) error {
+	if t == nil {
+		return nil
+	}
+	path, err := t.path(stack)
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count, ok := t.counts[stack]
+	if !ok {
+		events, err := readEvents(path)
+		if err != nil {
+			return err
+		}
+		count = len(events)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	count++
+
+	// The file may grow to twice the bound before it is cut back, so it is
+	// not rewritten on every event
+	if count > 2*t.maxEvents {
+		if err := t.trim(path); err != nil {
+			return err
+		}
+		count = t.maxEvents
+	}
+	t.counts[stack] = count
+	return nil
+}
+
+// trim keeps the last maxEvents events of a file. Callers hold the lock.
+func (t *Timeline) trim(path string) error {
+	events, err := readEvents(path)
+	if err != nil {
+		return err
+	}
+	if len(events) > t.maxEvents {
+		events = events[len(events)-t.maxEvents:]
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Namespace returns the namespace a stack was last applied or removed in,
+// which outlives the stack, or "" when its timeline records none
+func (t *Timeline) Namespace(stack string) (string, error) {
+	events, err := t.List(stack, Filter{})
+	if err != nil {
+		return "", err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Namespace != "" {
+			return events[i].Namespace, nil
+		}
+	}
+	return "", nil
+}
+
+// Filter selects events. Zero fields select everything.
+type Filter struct {
+	Since time.Time
+	Kinds []string
+	Limit int // Only the last this many
+}
+
+// List returns a stack's events matching f, oldest first. A stack with no
+// timeline has no events.
+func (t *Timeline) List(stack string, f Filter) ([]Event, error) {
+	path, err := t.path(stack)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	events, err := readEvents(path)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make(map[string]bool, len(f.Kinds))
+	for _, k := range f.Kinds {
+		kinds[k] = true
+	}
+	matched := events[:0]
+	for _, e := range events {
+		if e.Time.Before(f.Since) || (len(kinds) > 0 && !kinds[e.Kind]) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		matched = matched[len(matched)-f.Limit:]
+	}
+	return matched, nil
+}
+
+// readEvents reads a timeline file, skipping lines cut short by a crash
+func readEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,166 @@
+package timeline
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndList(t *testing.T) {
+	tl, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for i, e := range []Event{
+		{Kind: KindApply, Summary: "Applied", Details: []string{"api: image: api:1 → api:2"}, Operation: "op-1"},
+		{Kind: KindCrash, Service: "api", Summary: "shop-api-1 exited with code 1"},
+		{Kind: KindRestart, Service: "api", Summary: "shop-api-1 restarted"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Minute)
+		if err := tl.Record("shop", e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{KindApply, KindCrash, KindRestart}},
+		{"since", Filter{Since: start.Add(time.Minute)}, []string{KindCrash, KindRestart}},
+		{"kinds", Filter{Kinds: []string{KindApply, KindRestart}}, []string{KindApply, KindRestart}},
+		{"limit", Filter{Limit: 1}, []string{KindRestart}},
+	} {
+		events, err := tl.List("shop", tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range events {
+			got = append(got, e.Kind)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	events, _ := tl.List("shop", Filter{Limit: 3})
+	if e := events[0]; e.Operation != "op-1" || len(e.Details) != 1 || !e.Time.Equal(start) {
+		t.Errorf("apply event = %+v", e)
+	}
+	if events, err := tl.List("other", Filter{}); err != nil || len(events) != 0 {
+		t.Errorf("stack without a timeline: %v, %v", events, err)
+	}
+	if err := tl.Record("../shop", Event{Kind: KindApply}); err == nil {
+		t.Error("stack name with a path: no error")
+	}
+	if ns, _ := tl.Namespace("shop"); ns != "" {
+		t.Errorf("namespace without applies = %q", ns)
+	}
+	tl.Record("shop", Event{Kind: KindRemove, Namespace: "team-a"})
+	tl.Record("shop", Event{Kind: KindCrash})
+	if ns, _ := tl.Namespace("shop"); ns != "team-a" {
+		t.Errorf("namespace = %q, want team-a", ns)
+	}
+	var none *Timeline
+	if err := none.Record("shop", Event{Kind: KindApply}); err != nil {
+		t.Errorf("nil timeline: %v", err)
+	}
+}
+
+func TestTimelineIsBounded(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := Open(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 45 {
+		if err := tl.Record("shop", Event{Kind: KindRestart, Summary: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reopened, the count is read from the file
+	tl, _ = Open(dir, 10)
+	tl.Record("shop", Event{Kind: KindRestart, Summary: "45"})
+	events, err := readEvents(filepath.Join(dir, "shop.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) > 20 || events[len(events)-1].Summary != "45" {
+		t.Errorf("file holds %d events ending with %q, want at most 20 ending with 45", len(events), events[len(events)-1].Summary)
+	}
+	listed, _ := tl.List("shop", Filter{Limit: 10})
+	if len(listed) != 10 || listed[0].Summary != "36" {
+		t.Errorf("last 10 events start with %q", listed[0].Summary)
+	}
+}
+
+func writeCert(t *testing.T, dir, name string, notAfter time.Time, domains ...string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, name), 0700)
+	if err := os.WriteFile(filepath.Join(dir, name, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenewedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(20 * 24 * time.Hour).Truncate(time.Second)
+	writeCert(t, dir, "example.com", expiry, "example.com", "*.example.com")
+	writeCert(t, dir, "other.org", expiry, "other.org")
+
+	before, err := ScanCertificates(dir)
+	if err != nil || len(before) != 2 {
+		t.Fatalf("scanned %v, %v", before, err)
+	}
+	writeCert(t, dir, "example.com", expiry.Add(60*24*time.Hour), "example.com", "*.example.com")
+	writeCert(t, dir, "new.net", expiry, "new.net")
+	after, _ := ScanCertificates(dir)
+
+	renewed := Renewed(before, after)
+	if len(renewed) != 1 || renewed[0].Name != "example.com" {
+		t.Fatalf("renewed = %+v, want example.com only", renewed)
+	}
+	for domain, want := range map[string]bool{
+		"example.com":     true,
+		"API.example.com": true,
+		"a.b.example.com": false,
+		"example.org":     false,
+	} {
+		if got := renewed[0].Covers(domain); got != want {
+			t.Errorf("covers %s = %v", domain, got)
+		}
+	}
+
+	if got := StackDomains(map[string]string{DomainsLabel: "shop.example.com, api.example.com,"}); strings.Join(got, " ") != "shop.example.com api.example.com" {
+		t.Errorf("stack domains = %q", got)
+	}
+}
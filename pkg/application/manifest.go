@@ -0,0 +1,193 @@
+// Package application deploys applications: groups of stacks spread across
+// agents whose dependencies decide the order they are applied in, such as a
+// database before the API that uses it and the API before its frontend.
+package application
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultHealthTimeout applies when a stack sets no health timeout
+const defaultHealthTimeout = 5 * time.Minute
+
+// Manifest describes an application
+type Manifest struct {
+	Name      string      `yaml:"name"`
+	Namespace string      `yaml:"namespace,omitempty"` // Empty uses the caller's namespace
+	Stacks    []StackSpec `yaml:"stacks"`
+}
+
+// StackSpec is one stack of an application and the agent it runs on
+type StackSpec struct {
+	Name      string            `yaml:"name"`
+	Agent     string            `yaml:"agent"`
+	Compose   string            `yaml:"compose"` // Path, relative to the manifest
+	DependsOn []string          `yaml:"depends_on,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+	Health    HealthGate        `yaml:"health,omitempty"`
+
+	content string
+}
+
+// HealthGate is what a stack must reach before its dependents are applied
+type HealthGate struct {
+	Timeout string `yaml:"timeout,omitempty"` // Default 5m
+	// Skip lets dependents start as soon as the apply completes
+	Skip bool `yaml:"skip,omitempty"`
+}
+
+// timeout returns the parsed health timeout; Validate has checked it
+func (h HealthGate) timeout() time.Duration {
+	if h.Timeout == "" {
+		return defaultHealthTimeout
+	}
+	d, _ := time.ParseDuration(h.Timeout)
+	return d
+}
+
+// Load reads and validates the manifest in path along with the compose
+// files it names
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range m.Stacks {
+		s := &m.Stacks[i]
+		file := s.Compose
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("stack %s: read compose file: %w", s.Name, err)
+		}
+		s.content = string(content)
+	}
+	return m, nil
+}
+
+// Parse decodes and validates a manifest. Compose files are not read.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that stacks are named once, run on an agent, depend on
+// stacks of the application and that the dependencies have no cycle
+func (m *Manifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("application has no name")
+	}
+	if len(m.Stacks) == 0 {
+		return fmt.Errorf("application %s has no stacks", m.Name)
+	}
+
+	seen := make(map[string]bool, len(m.Stacks))
+	for _, s := range m.Stacks {
+		switch {
+		case s.Name == "":
+			return fmt.Errorf("a stack has no name")
+		case seen[s.Name]:
+			return fmt.Errorf("stack %s is listed twice", s.Name)
+		case s.Agent == "":
+			return fmt.Errorf("stack %s has no agent", s.Name)
+		case s.Compose == "":
+			return fmt.Errorf("stack %s has no compose file", s.Name)
+		}
+		if s.Health.Timeout != "" {
+			if d, err := time.ParseDuration(s.Health.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("stack %s: invalid health timeout %q", s.Name, s.Health.Timeout)
+			}
+		}
+		seen[s.Name] = true
+	}
+	for _, s := range m.Stacks {
+		for _, dep := range s.DependsOn {
+			if !seen[dep] {
+				return fmt.Errorf("stack %s depends on unknown stack %s", s.Name, dep)
+			}
+		}
+	}
+
+	_, err := m.Order()
+	return err
+}
+
+// Order groups the stacks into waves: every stack depends only on stacks of
+// earlier waves, so a wave can be applied at once after the one before it
+// is healthy. Stacks within a wave are sorted by name.
+func (m *Manifest) Order() ([][]string, error) {
+	remaining := make(map[string][]string, len(m.Stacks)) // Stack to unmet dependencies
+	for _, s := range m.Stacks {
+		remaining[s.Name] = s.DependsOn
+	}
+
+	var waves [][]string
+	done := make(map[string]bool, len(m.Stacks))
+	for len(remaining) > 0 {
+		var wave []string
+		for name, deps := range remaining {
+			ready := true
+			for _, dep := range deps {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, name)
+			}
+		}
+		if len(wave) == 0 {
+			stuck := make([]string, 0, len(remaining))
+			for name := range remaining {
+				stuck = append(stuck, name)
+			}
+			sort.Strings(stuck)
+			return nil, fmt.Errorf("dependency cycle between stacks %v", stuck)
+		}
+
+		sort.Strings(wave)
+		for _, name := range wave {
+			done[name] = true
+			delete(remaining, name)
+		}
+		waves = append(waves, wave)
+	}
+	return waves, nil
+}
+
+// Stack returns the stack named name
+func (m *Manifest) Stack(name string) (*StackSpec, bool) {
+	for i := range m.Stacks {
+		if m.Stacks[i].Name == name {
+			return &m.Stacks[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetCompose sets the compose file content of a stack, for manifests that
+// were parsed rather than loaded
+func (s *StackSpec) SetCompose(content string) {
+	s.content = content
+}
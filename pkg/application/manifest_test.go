@@ -0,0 +1,92 @@
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const shop = `
+name: shop
+stacks:
+  - name: frontend
+    agent: web-1
+    compose: frontend.yaml
+    depends_on: [api]
+  - name: api
+    agent: app-1
+    compose: api.yaml
+    depends_on: [db, cache]
+  - name: cache
+    agent: db-1
+    compose: cache.yaml
+  - name: db
+    agent: db-1
+    compose: db.yaml
+    health:
+      timeout: 10m
+`
+
+func TestOrder(t *testing.T) {
+	m, err := Parse([]byte(shop))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waves, err := m.Order()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"cache", "db"}, {"api"}, {"frontend"}}
+	if !reflect.DeepEqual(waves, want) {
+		t.Errorf("Order = %v, want %v", waves, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		manifest string
+		want     string
+	}{
+		"cycle": {
+			"name: a\nstacks:\n  - {name: x, agent: a1, compose: x.yaml, depends_on: [y]}\n  - {name: y, agent: a1, compose: y.yaml, depends_on: [x]}\n",
+			"dependency cycle between stacks [x y]",
+		},
+		"unknown dependency": {
+			"name: a\nstacks:\n  - {name: x, agent: a1, compose: x.yaml, depends_on: [db]}\n",
+			"depends on unknown stack db",
+		},
+		"duplicate": {
+			"name: a\nstacks:\n  - {name: x, agent: a1, compose: x.yaml}\n  - {name: x, agent: a2, compose: x.yaml}\n",
+			"listed twice",
+		},
+		"no agent": {
+			"name: a\nstacks:\n  - {name: x, compose: x.yaml}\n",
+			"has no agent",
+		},
+		"bad timeout": {
+			"name: a\nstacks:\n  - {name: x, agent: a1, compose: x.yaml, health: {timeout: soon}}\n",
+			"invalid health timeout",
+		},
+	} {
+		if _, err := Parse([]byte(tc.manifest)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestLoadReadsComposeFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("name: a\nstacks:\n  - {name: x, agent: a1, compose: x/compose.yaml}\n"), 0600)
+	os.Mkdir(filepath.Join(dir, "x"), 0700)
+	os.WriteFile(filepath.Join(dir, "x", "compose.yaml"), []byte("services: {}\n"), 0600)
+
+	m, err := Load(filepath.Join(dir, "app.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Stacks[0].content; got != "services: {}\n" {
+		t.Errorf("compose = %q, want the file next to the manifest", got)
+	}
+}
@@ -0,0 +1,314 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+// defaultPollInterval is how often health gates look at a stack
+const defaultPollInterval = 2 * time.Second
+
+// State is where a stack, or a whole application, stands
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateApplying  State = "applying"
+	StateWaiting   State = "waiting" // Applied, waiting for the health gate
+	StateHealthy   State = "healthy"
+	StateUnhealthy State = "unhealthy"
+	StateMissing   State = "missing" // Not deployed
+	StateFailed    State = "failed"
+	StateSkipped   State = "skipped"  // Not applied because an earlier wave failed
+	StateUnknown   State = "unknown"  // The agent could not be asked
+	StateDegraded  State = "degraded" // Applications only: some stacks are not healthy
+)
+
+// StackStatus is the state of one stack of an application
+type StackStatus struct {
+	Name   string
+	Agent  string
+	Wave   int // Position in the apply order, from 1
+	State  State
+	Detail string
+}
+
+// Status is the state of an application and of its stacks in apply order
+type Status struct {
+	Name   string
+	State  State
+	Stacks []StackStatus
+}
+
+// summarize sets the application state from the stack states: healthy when
+// every stack is, missing when none is deployed, failed when an apply
+// failed and degraded otherwise
+func (s *Status) summarize() {
+	counts := make(map[State]int)
+	for _, st := range s.Stacks {
+		counts[st.State]++
+	}
+	switch {
+	case counts[StateHealthy] == len(s.Stacks):
+		s.State = StateHealthy
+	case counts[StateFailed] > 0:
+		s.State = StateFailed
+	case counts[StateMissing] == len(s.Stacks):
+		s.State = StateMissing
+	default:
+		s.State = StateDegraded
+	}
+}
+
+// Orchestrator applies applications through the core, one wave of stacks
+// at a time, and reports their status
+type Orchestrator struct {
+	stacks    agentv1.StackServiceClient
+	namespace string
+
+	// PollInterval is how often health gates look at a stack; zero means 2s
+	PollInterval time.Duration
+	// OnChange, when set, is called each time a stack changes state. Calls
+	// for stacks of one wave may come from several goroutines, one at a time.
+	OnChange func(StackStatus)
+
+	mu sync.Mutex
+}
+
+// NewOrchestrator returns an orchestrator calling stacks, using namespace
+// for manifests that do not name one
+func NewOrchestrator(stacks agentv1.StackServiceClient, namespace string) *Orchestrator {
+	return &Orchestrator{stacks: stacks, namespace: namespace}
+}
+
+// Apply applies the stacks of m wave by wave. A wave starts once every
+// stack of the wave before it is applied and healthy; when a stack fails,
+// later waves are skipped. The returned status is complete either way.
+func (o *Orchestrator) Apply(ctx context.Context, m *Manifest) (*Status, error) {
+	waves, err := m.Order()
+	if err != nil {
+		return nil, err
+	}
+	status := newStatus(m, waves)
+
+	next := 0
+	for _, wave := range waves {
+		stacks := status.Stacks[next : next+len(wave)]
+		next += len(wave)
+
+		var wg sync.WaitGroup
+		for i := range stacks {
+			spec, _ := m.Stack(stacks[i].Name)
+			wg.Add(1)
+			go func(st *StackStatus) {
+				defer wg.Done()
+				o.deploy(ctx, m, spec, st)
+			}(&stacks[i])
+		}
+		wg.Wait()
+
+		var failed []string
+		for _, st := range stacks {
+			if st.State == StateFailed {
+				failed = append(failed, st.Name)
+			}
+		}
+		if len(failed) > 0 {
+			for i := next; i < len(status.Stacks); i++ {
+				o.set(&status.Stacks[i], StateSkipped, "stack "+strings.Join(failed, ", ")+" failed")
+			}
+			status.summarize()
+			return status, fmt.Errorf("application %s: stack %s failed", m.Name, strings.Join(failed, ", "))
+		}
+	}
+
+	status.summarize()
+	return status, nil
+}
+
+// Status reports the state of every stack of m as it runs now
+func (o *Orchestrator) Status(ctx context.Context, m *Manifest) (*Status, error) {
+	waves, err := m.Order()
+	if err != nil {
+		return nil, err
+	}
+	status := newStatus(m, waves)
+
+	for i := range status.Stacks {
+		st := &status.Stacks[i]
+		spec, _ := m.Stack(st.Name)
+		state, detail, err := o.check(ctx, o.namespaceOf(m), spec)
+		if err != nil {
+			state, detail = StateUnknown, err.Error()
+		}
+		st.State, st.Detail = state, detail
+	}
+
+	status.summarize()
+	return status, nil
+}
+
+func newStatus(m *Manifest, waves [][]string) *Status {
+	status := &Status{Name: m.Name, State: StatePending}
+	for i, wave := range waves {
+		for _, name := range wave {
+			spec, _ := m.Stack(name)
+			status.Stacks = append(status.Stacks, StackStatus{
+				Name:  name,
+				Agent: spec.Agent,
+				Wave:  i + 1,
+				State: StatePending,
+			})
+		}
+	}
+	return status
+}
+
+func (o *Orchestrator) namespaceOf(m *Manifest) string {
+	if m.Namespace != "" {
+		return m.Namespace
+	}
+	return o.namespace
+}
+
+// set changes the state of st and reports it
+func (o *Orchestrator) set(st *StackStatus, state State, detail string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	st.State, st.Detail = state, detail
+	if o.OnChange != nil {
+		o.OnChange(*st)
+	}
+}
+
+// deploy applies one stack and waits for its health gate
+func (o *Orchestrator) deploy(ctx context.Context, m *Manifest, spec *StackSpec, st *StackStatus) {
+	ns := o.namespaceOf(m)
+
+	o.set(st, StateApplying, "")
+	if err := o.apply(ctx, ns, spec); err != nil {
+		o.set(st, StateFailed, err.Error())
+		return
+	}
+
+	if spec.Health.Skip {
+		o.set(st, StateHealthy, "health gate skipped")
+		return
+	}
+	o.set(st, StateWaiting, "")
+	if err := o.waitHealthy(ctx, ns, spec); err != nil {
+		o.set(st, StateFailed, err.Error())
+		return
+	}
+	o.set(st, StateHealthy, "")
+}
+
+// apply applies one stack and follows its operation to the end
+func (o *Orchestrator) apply(ctx context.Context, namespace string, spec *StackSpec) error {
+	stream, err := o.stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{
+		AgentId:        spec.Agent,
+		StackName:      spec.Name,
+		ComposeContent: spec.content,
+		Labels:         spec.Labels,
+		Namespace:      namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	var last *agentv1.OperationEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		last = event
+	}
+
+	if last == nil {
+		return fmt.Errorf("apply ended without a result")
+	}
+	switch last.State {
+	case agentv1.OperationState_OPERATION_STATE_FAILED, agentv1.OperationState_OPERATION_STATE_CANCELLED:
+		if last.Error != "" {
+			return fmt.Errorf("apply: %s", last.Error)
+		}
+		return fmt.Errorf("apply: %s", last.Message)
+	}
+	return nil
+}
+
+// waitHealthy polls a stack until it is healthy or its health timeout passes
+func (o *Orchestrator) waitHealthy(ctx context.Context, namespace string, spec *StackSpec) error {
+	timeout := spec.Health.timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		state, detail, err := o.check(ctx, namespace, spec)
+		if err != nil {
+			detail = err.Error()
+		} else if state == StateHealthy {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not healthy after %s: %s", timeout, detail)
+		case <-ticker.C:
+		}
+	}
+}
+
+// check looks up a stack on its agent and judges its health
+func (o *Orchestrator) check(ctx context.Context, namespace string, spec *StackSpec) (State, string, error) {
+	resp, err := o.stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: spec.Agent, Namespace: namespace})
+	if err != nil {
+		return "", "", err
+	}
+	if msg := resp.AgentErrors[spec.Agent]; msg != "" {
+		return "", "", fmt.Errorf("agent %s: %s", spec.Agent, msg)
+	}
+
+	for _, s := range resp.Stacks {
+		if s.Name == spec.Name {
+			state, detail := stackHealth(s)
+			return state, detail, nil
+		}
+	}
+	return StateMissing, "not deployed on " + spec.Agent, nil
+}
+
+// stackHealth judges a stack healthy when it has containers and all of them
+// run without a failing or pending health check
+func stackHealth(s *agentv1.Stack) (State, string) {
+	if len(s.Containers) == 0 {
+		return StateUnhealthy, "no containers"
+	}
+	for _, c := range s.Containers {
+		if c.State != "running" {
+			return StateUnhealthy, fmt.Sprintf("container %s is %s", c.Name, c.State)
+		}
+		if strings.Contains(c.Status, "(unhealthy)") || strings.Contains(c.Status, "(health: starting)") {
+			return StateUnhealthy, fmt.Sprintf("container %s: %s", c.Name, c.Status)
+		}
+	}
+	return StateHealthy, ""
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/testutil"
+)
+
+func testManifest(t *testing.T) *Manifest {
+	t.Helper()
+	m, err := Parse([]byte(`
+name: shop
+stacks:
+  - {name: db, agent: agent-1, compose: db.yaml}
+  - {name: api, agent: agent-2, compose: api.yaml, depends_on: [db]}
+  - {name: web, agent: agent-2, compose: web.yaml, depends_on: [api]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range m.Stacks {
+		m.Stacks[i].SetCompose("services:\n  " + m.Stacks[i].Name + ":\n    image: busybox\n")
+	}
+	return m
+}
+
+func TestApplyInOrder(t *testing.T) {
+	cluster := testutil.NewCluster(t, testutil.Options{Agents: []string{"agent-1", "agent-2"}})
+	o := NewOrchestrator(agentv1.NewStackServiceClient(cluster.Dial("alice")), "")
+	o.PollInterval = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	var applied []string
+	o.OnChange = func(st StackStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		if st.State == StateApplying {
+			applied = append(applied, st.Name)
+		}
+	}
+
+	status, err := o.Apply(context.Background(), testManifest(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != StateHealthy {
+		t.Errorf("application is %s, want healthy: %+v", status.State, status.Stacks)
+	}
+	if want := []string{"db", "api", "web"}; len(applied) != 3 || applied[0] != want[0] || applied[1] != want[1] || applied[2] != want[2] {
+		t.Errorf("applied %v, want %v", applied, want)
+	}
+	if _, ok := cluster.Agent("agent-2").Docker.Stack("api"); !ok {
+		t.Error("api does not run on agent-2")
+	}
+}
+
+func TestApplyStopsAfterFailure(t *testing.T) {
+	cluster := testutil.NewCluster(t, testutil.Options{Agents: []string{"agent-1", "agent-2"}})
+	cluster.Agent("agent-2").Docker.FailNext("api", errors.New("pull access denied"))
+	o := NewOrchestrator(agentv1.NewStackServiceClient(cluster.Dial("alice")), "")
+	o.PollInterval = 10 * time.Millisecond
+
+	status, err := o.Apply(context.Background(), testManifest(t))
+	if err == nil {
+		t.Fatal("apply succeeded, want the api failure")
+	}
+	want := map[string]State{"db": StateHealthy, "api": StateFailed, "web": StateSkipped}
+	for _, st := range status.Stacks {
+		if st.State != want[st.Name] {
+			t.Errorf("%s is %s, want %s", st.Name, st.State, want[st.Name])
+		}
+	}
+	if _, ok := cluster.Agent("agent-2").Docker.Stack("web"); ok {
+		t.Error("web was applied after api failed")
+	}
+
+	status, err = o.Status(context.Background(), testManifest(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != StateDegraded {
+		t.Errorf("application is %s, want degraded: %+v", status.State, status.Stacks)
+	}
+}
@@ -0,0 +1,311 @@
+// Package artifact keeps content on the core addressed by its SHA-256
+// digest, so content deployed to many agents is uploaded once and fetched
+// by each agent. Content stored twice is kept once; artifacts neither
+// pushed nor fetched for a while are collected as garbage unless pinned.
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for digests the store does not hold
+	ErrNotFound = errors.New("artifact not found")
+	// ErrDigest is returned when content does not have the digest it was
+	// stored under
+	ErrDigest = errors.New("content does not match its digest")
+	// ErrSize is returned when content is not as long as it was said to be
+	ErrSize = errors.New("content does not have its size")
+)
+
+// Kinds are the kinds of artifact the store takes
+var Kinds = []string{"compose", "template", "script", "image-bundle", "file"}
+
+var validDigest = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Artifact is what the store records of an artifact
+type Artifact struct {
+	Digest    string    `json:"digest"`
+	Kind      string    `json:"kind"`
+	Names     []string  `json:"names,omitempty"` // Every name it was pushed under
+	Size      int64     `json:"size"`
+	Pinned    bool      `json:"pinned,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UsedAt    time.Time `json:"used_at"` // Last pushed or fetched
+	Pushes    int64     `json:"pushes"`
+	Fetches   int64     `json:"fetches"`
+}
+
+// Store keeps artifacts in a directory: the content and a record of each,
+// under its digest
+type Store struct {
+	dir string
+	mu  sync.Mutex // Held while records are written
+}
+
+// Open opens the store in dir, creating it
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "tmp"), 0700); err != nil {
+		return nil, fmt.Errorf("create artifact dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) recordPath(digest string) string { return filepath.Join(s.dir, digest+".json") }
+func (s *Store) dataPath(digest string) string   { return filepath.Join(s.dir, digest+".data") }
+
+// ValidKind reports whether the store takes artifacts of kind
+func ValidKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidDigest reports whether digest is a hex SHA-256 digest as the store
+// keys artifacts by
+func ValidDigest(digest string) bool {
+	return validDigest.MatchString(digest)
+}
+
+// Get returns the artifact with digest
+func (s *Store) Get(digest string) (*Artifact, error) {
+	if !validDigest.MatchString(digest) {
+		return nil, fmt.Errorf("invalid digest %q: want 64 lower-case hex digits", digest)
+	}
+	data, err := os.ReadFile(s.recordPath(digest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var a Artifact
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("read artifact %s: %w", digest, err)
+	}
+	return &a, nil
+}
+
+func (s *Store) save(a *Artifact) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	tmp := s.recordPath(a.Digest) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.recordPath(a.Digest))
+}
+
+// Meta describes content being put
+type Meta struct {
+	Digest string
+	Kind   string
+	Name   string // Optional, e.g. the file name
+	Size   int64
+	Pin    bool
+}
+
+// Put stores the content r holds as meta describes. Content the store
+// already holds is read and dropped, and only its record is updated.
+func (s *Store) Put(meta Meta, r io.Reader) (*Artifact, error) {
+	if !validDigest.MatchString(meta.Digest) {
+		return nil, fmt.Errorf("invalid digest %q: want 64 lower-case hex digits", meta.Digest)
+	}
+	if !ValidKind(meta.Kind) {
+		return nil, fmt.Errorf("unknown artifact kind %q: want one of %s", meta.Kind, strings.Join(Kinds, ", "))
+	}
+	if meta.Size < 0 {
+		return nil, fmt.Errorf("invalid size %d", meta.Size)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, "tmp"), "put-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, meta.Size+1))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n != meta.Size {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrSize, n, meta.Size)
+	}
+	if digest := hex.EncodeToString(h.Sum(nil)); digest != meta.Digest {
+		return nil, fmt.Errorf("%w: got sha256 %s, want %s", ErrDigest, digest, meta.Digest)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	a, err := s.Get(meta.Digest)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		a = &Artifact{Digest: meta.Digest, Kind: meta.Kind, Size: meta.Size, CreatedAt: now}
+		if err := os.Rename(tmp.Name(), s.dataPath(meta.Digest)); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	if meta.Name != "" && !contains(a.Names, meta.Name) {
+		a.Names = append(a.Names, meta.Name)
+	}
+	a.Pinned = a.Pinned || meta.Pin
+	a.UsedAt = now
+	a.Pushes++
+	if err := s.save(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Open opens the content of the artifact with digest
+func (s *Store) Open(digest string) (*os.File, *Artifact, error) {
+	a, err := s.Get(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(s.dataPath(digest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, digest)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, a, nil
+}
+
+// Fetched records that an agent began fetching the artifact with digest
+func (s *Store) Fetched(digest string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.Get(digest)
+	if err != nil {
+		return err
+	}
+	a.UsedAt = time.Now()
+	a.Fetches++
+	return s.save(a)
+}
+
+// List returns the artifacts of kind, or of every kind when it is empty,
+// the most recently used first
+func (s *Store) List(kind string) ([]*Artifact, error) {
+	records, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []*Artifact
+	for _, record := range records {
+		a, err := s.Get(strings.TrimSuffix(filepath.Base(record), ".json"))
+		if err != nil {
+			continue
+		}
+		if kind == "" || a.Kind == kind {
+			artifacts = append(artifacts, a)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].UsedAt.After(artifacts[j].UsedAt) })
+	return artifacts, nil
+}
+
+// Delete removes the artifact with digest and returns what it was
+func (s *Store) Delete(digest string) (*Artifact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.Get(digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.remove(digest); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *Store) remove(digest string) error {
+	if err := os.Remove(s.recordPath(digest)); err != nil {
+		return err
+	}
+	if err := os.Remove(s.dataPath(digest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Collect removes the unpinned artifacts not used since before, and
+// content left behind by interrupted puts, and returns the artifacts. With
+// dryRun set nothing is removed.
+func (s *Store) Collect(before time.Time, dryRun bool) ([]*Artifact, error) {
+	artifacts, err := s.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []*Artifact
+	for _, a := range artifacts {
+		if a.Pinned || !a.UsedAt.Before(before) {
+			continue
+		}
+		if !dryRun {
+			// Pushed or fetched while the list was read
+			if current, err := s.Get(a.Digest); err != nil || !current.UsedAt.Before(before) {
+				continue
+			}
+			if err := s.remove(a.Digest); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, a)
+	}
+
+	if !dryRun {
+		tmps, _ := filepath.Glob(filepath.Join(s.dir, "tmp", "put-*"))
+		for _, tmp := range tmps {
+			if info, err := os.Stat(tmp); err == nil && info.ModTime().Before(before) {
+				os.Remove(tmp)
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,111 @@
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func digestOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestStoreDedupes(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "services:\n  web:\n    image: nginx\n"
+	meta := Meta{Digest: digestOf(content), Kind: "compose", Name: "shop.yaml", Size: int64(len(content))}
+
+	if _, err := s.Put(meta, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	meta.Name = "blog.yaml"
+	a, err := s.Put(meta, strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Pushes != 2 || len(a.Names) != 2 {
+		t.Errorf("pushed twice: %+v", a)
+	}
+	if list, _ := s.List(""); len(list) != 1 {
+		t.Errorf("stored %d artifacts, want 1", len(list))
+	}
+
+	f, _, err := s.Open(meta.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != content {
+		t.Errorf("content = %q", data)
+	}
+
+	bad := meta
+	bad.Digest = digestOf("something else")
+	if _, err := s.Put(bad, strings.NewReader(content)); !errors.Is(err, ErrDigest) {
+		t.Errorf("wrong digest: error = %v", err)
+	}
+	if _, err := s.Get(bad.Digest); !errors.Is(err, ErrNotFound) {
+		t.Errorf("content with the wrong digest was stored: %v", err)
+	}
+	short := meta
+	short.Size++
+	if _, err := s.Put(short, strings.NewReader(content)); !errors.Is(err, ErrSize) {
+		t.Errorf("short content: error = %v", err)
+	}
+	meta.Kind = "binary"
+	if _, err := s.Put(meta, strings.NewReader(content)); err == nil {
+		t.Error("unknown kind was stored")
+	}
+}
+
+func TestStoreCollects(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []struct {
+		content string
+		pin     bool
+	}{{"old", false}, {"pinned", true}} {
+		meta := Meta{Digest: digestOf(m.content), Kind: "script", Size: int64(len(m.content)), Pin: m.pin}
+		if _, err := s.Put(meta, strings.NewReader(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	recent := Meta{Digest: digestOf("recent"), Kind: "script", Size: 6}
+	if _, err := s.Put(recent, strings.NewReader("recent")); err != nil {
+		t.Fatal(err)
+	}
+	// Fetching keeps an artifact
+	if err := s.Fetched(digestOf("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, err := s.Collect(cutoff, false); err != nil || len(removed) != 0 {
+		t.Errorf("collected %d artifacts, %v", len(removed), err)
+	}
+	removed, err := s.Collect(time.Now().Add(time.Second), true)
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("dry run found %d artifacts, %v", len(removed), err)
+	}
+	if list, _ := s.List("script"); len(list) != 3 {
+		t.Errorf("dry run removed artifacts: %d left", len(list))
+	}
+	if _, err := s.Collect(time.Now().Add(time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := s.List(""); len(list) != 1 || !list[0].Pinned {
+		t.Errorf("left after collection: %v", list)
+	}
+}
@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// safeFields are request fields that identify what was changed and never
+// carry secrets. Anything else (contents, env values, passwords, tokens) is
+// left out of audit metadata unless a typed extractor below picks it.
+var safeFields = map[string]bool{
+	"agent_id":        true,
+	"stack_id":        true,
+	"stack_name":      true,
+	"namespace":       true,
+	"container_id":    true,
+	"operation_id":    true,
+	"path":            true,
+	"name":            true,
+	"server_name":     true,
+	"domain":          true,
+	"upstream":        true,
+	"proxy_pass":      true,
+	"root":            true,
+	"port":            true,
+	"listen":          true,
+	"proto":           true,
+	"action":          true,
+	"from_ip":         true,
+	"from_port":       true,
+	"to_ip":           true,
+	"to_port":         true,
+	"rule_number":     true,
+	"package_name":    true,
+	"key":             true, // sysctl key; the value is not recorded
+	"user":            true,
+	"group":           true,
+	"working_dir":     true,
+	"type":            true,
+	"restart":         true,
+	"production":      true,
+	"follow":          true,
+	"force_recreate":  true,
+	"pull_images":     true,
+	"emergency":       true,
+	"approval_id":     true,
+	"enabled":         true,
+	"hostname":        true,
+	"version":         true,
+	"mode":            true,
+	"algorithm":       true,
+	"reason":          true,
+	"comment":         true,
+	"schedule":        true,
+	"ip":              true,
+	"target":          true,
+	"engine":          true,
+	"idempotency_key": true,
+	"reveal_secrets":  true,
+	"no_wait":         true,
+	"apply":           true,
+	"dry_run":         true,
+	"force":           true,
+}
+
+// Metadata extracts sanitized, audit-worthy parameters from a request so
+// queries like "who changed stack X's image" can be answered. Secrets such
+// as compose env values, file contents and passwords are never included.
+func Metadata(req interface{}) map[string]string {
+	md := make(map[string]string)
+
+	// Calls the core forwards without a handler of its own
+	if f, ok := req.(*transport.Frame); ok {
+		req = f.Message
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return md
+	}
+
+	extractSafeFields(md, msg.ProtoReflect())
+
+	switch r := req.(type) {
+	case *agentv1.ApplyStackRequest:
+		if images := composeImages(r.ComposeContent); len(images) > 0 {
+			md["images"] = strings.Join(images, ",")
+		}
+		if len(r.Services) > 0 {
+			md["services"] = strings.Join(r.Services, ",")
+		}
+		if len(r.EnvVars) > 0 {
+			md["env_keys"] = strings.Join(sortedKeys(r.EnvVars), ",")
+		}
+		if len(r.Labels) > 0 {
+			md["labels"] = joinLabels(r.Labels)
+		}
+		if owner := r.Owner; owner != nil {
+			md["team"] = owner.Team
+			md["owner"] = owner.Owner
+			md["ticket"] = owner.Ticket
+		}
+		// Which hooks were set, not their scripts
+		if hooks := r.Hooks; hooks != nil {
+			var set []string
+			if hooks.PreApply != "" {
+				set = append(set, "pre_apply")
+			}
+			if hooks.PostApply != "" {
+				set = append(set, "post_apply")
+			}
+			md["hooks"] = strings.Join(set, ",")
+		}
+	case *agentv1.ListStacksRequest:
+		if len(r.Labels) > 0 {
+			md["labels"] = joinLabels(r.Labels)
+		}
+	case *agentv1.DiffStackRequest:
+		if images := composeImages(r.NewComposeContent); len(images) > 0 {
+			md["images"] = strings.Join(images, ",")
+		}
+	case *agentv1.WriteFileRequest:
+		md["size"] = strconv.Itoa(len(r.Content))
+	case *agentv1.ExecRequest:
+		if start := r.GetStart(); start != nil {
+			md["container_id"] = start.ContainerId
+			if len(start.Cmd) > 0 {
+				// Only the binary; arguments may contain credentials
+				md["command"] = start.Cmd[0]
+			}
+			md["tty"] = strconv.FormatBool(start.Tty)
+		}
+	case *agentv1.RegisterRequest:
+		if len(r.Capabilities) > 0 {
+			md["capabilities"] = strings.Join(r.Capabilities, ",")
+		}
+	case *agentv1.UpdateAgentLabelsRequest:
+		if len(r.Set) > 0 {
+			md["set_labels"] = strings.Join(sortedKeys(r.Set), ",")
+		}
+		if len(r.Remove) > 0 {
+			md["remove_labels"] = strings.Join(r.Remove, ",")
+		}
+	case *agentv1.CreateServiceRequest:
+		if len(r.Environment) > 0 {
+			md["env_keys"] = strings.Join(sortedKeys(r.Environment), ",")
+		}
+	case *agentv1.AddCronJobRequest:
+		if job := r.Job; job != nil {
+			extractSafeFields(md, job.ProtoReflect())
+			md["command"] = commandBinary(job.Command)
+		}
+	case *agentv1.DeployWebServiceRequest:
+		md["command"] = commandBinary(r.Command)
+		if len(r.Environment) > 0 {
+			md["env_keys"] = strings.Join(sortedKeys(r.Environment), ",")
+		}
+	case *agentv1.DeployWorkerRequest:
+		md["command"] = commandBinary(r.Command)
+		if len(r.Environment) > 0 {
+			md["env_keys"] = strings.Join(sortedKeys(r.Environment), ",")
+		}
+	}
+
+	return md
+}
+
+// extractSafeFields copies populated scalar fields listed in safeFields
+func extractSafeFields(md map[string]string, m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() || fd.IsMap() || !safeFields[string(fd.Name())] {
+			return true
+		}
+
+		switch fd.Kind() {
+		case protoreflect.StringKind:
+			md[string(fd.Name())] = v.String()
+		case protoreflect.BoolKind:
+			md[string(fd.Name())] = strconv.FormatBool(v.Bool())
+		case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind,
+			protoreflect.Sint64Kind, protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+			md[string(fd.Name())] = strconv.FormatInt(v.Int(), 10)
+		case protoreflect.Uint32Kind, protoreflect.Uint64Kind,
+			protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+			md[string(fd.Name())] = strconv.FormatUint(v.Uint(), 10)
+		}
+		return true
+	})
+}
+
+// commandBinary returns the first word of a command line; arguments may
+// contain credentials
+func commandBinary(command string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// composeImages returns the sorted service=image pairs of a compose file
+func composeImages(content string) []string {
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &compose); err != nil {
+		return nil
+	}
+
+	images := make([]string, 0, len(compose.Services))
+	for name, svc := range compose.Services {
+		if svc.Image != "" {
+			images = append(images, name+"="+svc.Image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// joinLabels renders labels as sorted key=value pairs; stack labels are
+// descriptive and safe to record in full
+func joinLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range sortedKeys(labels) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
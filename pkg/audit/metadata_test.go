@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/transport"
+)
+
+// secret is put in every field that must not be recorded
+const secret = "hunter2"
+
+const compose = `services:
+  web:
+    image: nginx:1.25
+    environment:
+      DB_PASSWORD: hunter2
+  db:
+    image: postgres:16
+  build-only:
+    build: .
+`
+
+func TestMetadata(t *testing.T) {
+	sysctl := &agentv1.SetSysctlRequest{AgentId: "agent-1", Key: "net.ipv4.ip_forward", Value: secret, Persist: true}
+
+	tests := []struct {
+		name string
+		req  interface{}
+		want map[string]string
+	}{
+		{name: "not a message", req: "apply web", want: map[string]string{}},
+		{
+			name: "apply stack",
+			req: &agentv1.ApplyStackRequest{
+				AgentId:        "agent-1",
+				StackName:      "web",
+				ComposeContent: compose,
+				EnvVars:        map[string]string{"DB_PASSWORD": secret, "API_KEY": secret},
+				Services:       []string{"web"},
+				Emergency:      true,
+				Labels:         map[string]string{"tier": "frontend", "app": "shop"},
+				Owner:          &agentv1.StackOwner{Team: "payments", Owner: "alice", Ticket: "OPS-1"},
+				Hooks:          &agentv1.StackHooks{PreApply: "curl -H 'Authorization: " + secret + "' https://ci"},
+			},
+			want: map[string]string{
+				"agent_id":   "agent-1",
+				"stack_name": "web",
+				"emergency":  "true",
+				"images":     "db=postgres:16,web=nginx:1.25",
+				"services":   "web",
+				"env_keys":   "API_KEY,DB_PASSWORD",
+				"labels":     "app=shop,tier=frontend",
+				"team":       "payments",
+				"owner":      "alice",
+				"ticket":     "OPS-1",
+				"hooks":      "pre_apply",
+			},
+		},
+		{
+			name: "apply stack with unreadable compose",
+			req:  &agentv1.ApplyStackRequest{StackName: "web", ComposeContent: "services: [" + secret},
+			want: map[string]string{"stack_name": "web"},
+		},
+		{
+			name: "write file",
+			req:  &agentv1.WriteFileRequest{StackName: "web", Path: ".env", Content: []byte("DB_PASSWORD=" + secret), Mode: 0600},
+			want: map[string]string{"stack_name": "web", "path": ".env", "mode": "384", "size": "19"},
+		},
+		{
+			name: "exec",
+			req: &agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Start{Start: &agentv1.ExecStart{
+				ContainerId: "db-1",
+				Cmd:         []string{"mysql", "-p" + secret},
+				Tty:         true,
+				Env:         map[string]string{"MYSQL_PWD": secret},
+				User:        "root",
+			}}},
+			want: map[string]string{"container_id": "db-1", "command": "mysql", "tty": "true"},
+		},
+		{
+			name: "exec input",
+			req:  &agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Stdin{Stdin: []byte(secret + "\n")}},
+			want: map[string]string{},
+		},
+		{
+			name: "sysctl",
+			req:  sysctl,
+			want: map[string]string{"agent_id": "agent-1", "key": "net.ipv4.ip_forward"},
+		},
+		{
+			name: "forwarded frame",
+			req:  &transport.Frame{Payload: []byte(secret), Message: sysctl},
+			want: map[string]string{"agent_id": "agent-1", "key": "net.ipv4.ip_forward"},
+		},
+		{
+			name: "frame not decoded",
+			req:  &transport.Frame{Payload: []byte(secret)},
+			want: map[string]string{},
+		},
+		{
+			name: "database",
+			req: &agentv1.DeployDatabaseRequest{
+				AgentId:        "agent-1",
+				Name:           "orders",
+				Engine:         "postgres",
+				Version:        "16",
+				Port:           5432,
+				DataDir:        "/srv/orders",
+				Password:       secret,
+				BackupSchedule: "0 3 * * *",
+			},
+			want: map[string]string{"agent_id": "agent-1", "name": "orders", "engine": "postgres", "version": "16", "port": "5432"},
+		},
+		{
+			name: "enroll",
+			req:  &agentv1.EnrollRequest{Token: secret, Hostname: "web-01", CsrPem: []byte(secret)},
+			want: map[string]string{"hostname": "web-01"},
+		},
+		{
+			name: "cron job",
+			req: &agentv1.AddCronJobRequest{AgentId: "agent-1", DryRun: true, Job: &agentv1.CronJob{
+				Name:     "backup",
+				Schedule: "0 3 * * *",
+				Command:  "pg_dump --password=" + secret + " orders",
+				User:     "postgres",
+			}},
+			want: map[string]string{
+				"agent_id": "agent-1",
+				"dry_run":  "true",
+				"name":     "backup",
+				"schedule": "0 3 * * *",
+				"command":  "pg_dump",
+				"user":     "postgres",
+			},
+		},
+		{
+			name: "web service",
+			req: &agentv1.DeployWebServiceRequest{
+				AgentId:     "agent-1",
+				Name:        "api",
+				Domain:      "api.example.com",
+				Port:        8080,
+				Command:     "node server.js --token " + secret,
+				Environment: map[string]string{"STRIPE_KEY": secret, "NODE_ENV": "production"},
+			},
+			want: map[string]string{
+				"agent_id": "agent-1",
+				"name":     "api",
+				"domain":   "api.example.com",
+				"port":     "8080",
+				"command":  "node",
+				"env_keys": "NODE_ENV,STRIPE_KEY",
+			},
+		},
+		{
+			name: "agent labels",
+			req: &agentv1.UpdateAgentLabelsRequest{
+				AgentId: "agent-1",
+				Set:     map[string]string{"zone": "eu-1", "env": "prod"},
+				Remove:  []string{"canary"},
+			},
+			want: map[string]string{"agent_id": "agent-1", "set_labels": "env,zone", "remove_labels": "canary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Metadata(tt.req)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Metadata = %v, want %v", got, tt.want)
+			}
+			for k, v := range got {
+				if strings.Contains(v, secret) {
+					t.Errorf("%s = %q records a secret", k, v)
+				}
+			}
+		})
+	}
+}
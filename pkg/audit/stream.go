@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Stream wraps a server stream to measure the traffic of a streaming RPC and
+// remember its first request message for metadata extraction
+type Stream struct {
+	grpc.ServerStream
+
+	first         atomic.Value
+	msgsSent      atomic.Int64
+	msgsReceived  atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// NewStream starts recording ss
+func NewStream(ss grpc.ServerStream) *Stream {
+	return &Stream{ServerStream: ss}
+}
+
+func (s *Stream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.msgsSent.Add(1)
+		s.bytesSent.Add(int64(messageSize(m)))
+	}
+	return err
+}
+
+func (s *Stream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.msgsReceived.Add(1)
+		s.bytesReceived.Add(int64(messageSize(m)))
+		if s.first.Load() == nil {
+			s.first.Store(m)
+		}
+	}
+	return err
+}
+
+// FirstRequest returns the first message received, or nil
+func (s *Stream) FirstRequest() interface{} {
+	return s.first.Load()
+}
+
+// Metadata returns the request metadata merged with the stream's traffic
+// counters
+func (s *Stream) Metadata() map[string]string {
+	md := Metadata(s.FirstRequest())
+	md["messages_sent"] = strconv.FormatInt(s.msgsSent.Load(), 10)
+	md["messages_received"] = strconv.FormatInt(s.msgsReceived.Load(), 10)
+	md["bytes_sent"] = strconv.FormatInt(s.bytesSent.Load(), 10)
+	md["bytes_received"] = strconv.FormatInt(s.bytesReceived.Load(), 10)
+	return md
+}
+
+func messageSize(m interface{}) int {
+	if f, ok := m.(*transport.Frame); ok {
+		return len(f.Payload)
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
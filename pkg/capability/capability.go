@@ -0,0 +1,89 @@
+package capability
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Capability names advertised by agents at registration. Only services the
+// agent actually serves are listed; plugin capabilities are not agent
+// capabilities and are never advertised.
+const (
+	Docker    = "docker"
+	Stack     = "stack"
+	Container = "container"
+	Logs      = "logs"
+	Exec      = "exec"
+	Files     = "files"
+	Ports     = "ports"     // Listening port inventory, where procfs is mounted
+	Processes = "processes" // Process inventory and signals, where procfs is mounted
+	LogFiles  = "logfiles"  // Host log files, when logs.files in the agent config allows any
+	Reboot    = "reboot"    // Host reboots, where systemd is the init system
+	LogShip   = "logship"   // Log shipping status, when logs.ship in the agent config names a sink
+	Timeline  = "timeline"  // Per-stack event timelines
+	Commands  = "commands"  // Allowlisted commands, when commands in the agent config lists any
+	Transfer  = "transfer"  // Resumable uploads and backup downloads
+
+	// Host services, served only when their plugin is enabled in the agent
+	// config
+	Nginx    = "nginx"
+	Systemd  = "systemd"
+	Firewall = "firewall"
+	ACME     = "acme"
+	Host     = "host"
+	Cron     = "cron"
+	DNS      = "dns"
+	Deploy   = "deploy"
+	Drift    = "drift" // Served along with nginx, systemd, firewall, cron or DNS
+)
+
+// Detect probes the host and returns the capabilities it can actually serve.
+// Docker-backed capabilities are always present because the agent refuses to
+// start without a reachable Docker daemon; stack deployment additionally
+// needs the compose plugin.
+func Detect() []string {
+	caps := []string{Docker, Container, Logs, Exec, Files, Transfer}
+
+	if composeAvailable() {
+		caps = append(caps, Stack)
+	}
+	if _, err := os.Stat("/proc/net/tcp"); err == nil {
+		caps = append(caps, Ports)
+	}
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		caps = append(caps, Processes)
+	}
+	if systemdBooted() {
+		caps = append(caps, Reboot)
+	}
+
+	return caps
+}
+
+// Has reports whether caps contains the named capability
+func Has(caps []string, name string) bool {
+	for _, c := range caps {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// systemdBooted reports whether systemd is the running init system, as
+// sd_booted does
+func systemdBooted() bool {
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return false
+	}
+	_, err := exec.LookPath("systemctl")
+	return err == nil
+}
+
+func composeAvailable() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "docker", "compose", "version").Run() == nil
+}
@@ -0,0 +1,245 @@
+// Package certidentity turns client certificates into identities. By
+// default the user ID is the subject CN; a mapping table can take the user
+// ID, attributes and roles from subject fields, SANs and custom
+// extensions, so PKIs that encode roles or teams in certificates work with
+// the RBAC plugin as they are.
+package certidentity
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// Mapper builds identities from certificates. A nil Mapper uses the CN.
+type Mapper struct {
+	userID     *rule
+	attributes []*rule
+	roles      []*rule
+}
+
+// rule is a compiled IdentityMapping
+type rule struct {
+	name  string
+	from  func(*x509.Certificate) []string
+	match *regexp.Regexp
+	table map[string]string
+}
+
+// New compiles a mapping table. An empty table returns a nil Mapper.
+func New(cfg config.IdentityMappingConfig) (*Mapper, error) {
+	if cfg.UserID == nil && len(cfg.Attributes) == 0 && len(cfg.Roles) == 0 {
+		return nil, nil
+	}
+
+	m := &Mapper{}
+	if cfg.UserID != nil {
+		r, err := compile(*cfg.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("identity.user_id: %w", err)
+		}
+		m.userID = r
+	}
+	for i, mapping := range cfg.Attributes {
+		if mapping.Name == "" {
+			return nil, fmt.Errorf("identity.attributes[%d]: name is required", i)
+		}
+		r, err := compile(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("identity.attributes[%d]: %w", i, err)
+		}
+		m.attributes = append(m.attributes, r)
+	}
+	for i, mapping := range cfg.Roles {
+		r, err := compile(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("identity.roles[%d]: %w", i, err)
+		}
+		m.roles = append(m.roles, r)
+	}
+	return m, nil
+}
+
+func compile(mapping config.IdentityMapping) (*rule, error) {
+	from, err := source(mapping.From)
+	if err != nil {
+		return nil, err
+	}
+	r := &rule{name: mapping.Name, from: from, table: mapping.Map}
+	if mapping.Match != "" {
+		if r.match, err = regexp.Compile(mapping.Match); err != nil {
+			return nil, fmt.Errorf("match: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// source returns what reads the values of from out of a certificate
+func source(from string) (func(*x509.Certificate) []string, error) {
+	switch from {
+	case "cn":
+		return func(c *x509.Certificate) []string { return nonEmpty(c.Subject.CommonName) }, nil
+	case "o":
+		return func(c *x509.Certificate) []string { return c.Subject.Organization }, nil
+	case "ou":
+		return func(c *x509.Certificate) []string { return c.Subject.OrganizationalUnit }, nil
+	case "dns_san":
+		return func(c *x509.Certificate) []string { return c.DNSNames }, nil
+	case "email_san":
+		return func(c *x509.Certificate) []string { return c.EmailAddresses }, nil
+	case "uri_san":
+		return func(c *x509.Certificate) []string {
+			values := make([]string, 0, len(c.URIs))
+			for _, u := range c.URIs {
+				values = append(values, u.String())
+			}
+			return values
+		}, nil
+	case "ip_san":
+		return func(c *x509.Certificate) []string {
+			values := make([]string, 0, len(c.IPAddresses))
+			for _, ip := range c.IPAddresses {
+				values = append(values, ip.String())
+			}
+			return values
+		}, nil
+	}
+
+	if oid, ok := strings.CutPrefix(from, "ext:"); ok {
+		id, err := parseOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		return func(c *x509.Certificate) []string { return extensionValues(c, id) }, nil
+	}
+	return nil, fmt.Errorf("unknown source %q: want cn, o, ou, dns_san, email_san, uri_san, ip_san or ext:<OID>", from)
+}
+
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
+
+// extensionValues decodes the extension id as a string or a sequence of
+// strings. Other encodings yield nothing.
+func extensionValues(c *x509.Certificate, id asn1.ObjectIdentifier) []string {
+	for _, ext := range c.Extensions {
+		if !ext.Id.Equal(id) {
+			continue
+		}
+		var value string
+		if rest, err := asn1.Unmarshal(ext.Value, &value); err == nil && len(rest) == 0 {
+			return nonEmpty(value)
+		}
+		var values []string
+		if rest, err := asn1.Unmarshal(ext.Value, &values); err == nil && len(rest) == 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// values returns what r takes from cert, in certificate order
+func (r *rule) values(cert *x509.Certificate) []string {
+	var values []string
+	for _, v := range r.from(cert) {
+		if r.match != nil {
+			m := r.match.FindStringSubmatch(v)
+			if m == nil {
+				continue
+			}
+			if len(m) > 1 {
+				v = m[1]
+			}
+		}
+		if r.table != nil {
+			mapped, ok := r.table[v]
+			if !ok {
+				continue
+			}
+			v = mapped
+		}
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Identity returns the identity cert stands for. Attributes taking several
+// values hold them comma-separated and sorted; roles are deduplicated. The
+// user ID is empty when user_id is mapped and cert has no such value.
+func (m *Mapper) Identity(cert *x509.Certificate) *plugin.Identity {
+	identity := &plugin.Identity{
+		UserID:     cert.Subject.CommonName,
+		Attributes: make(map[string]string),
+	}
+	if m == nil {
+		return identity
+	}
+
+	if m.userID != nil {
+		identity.UserID = ""
+		if values := m.userID.values(cert); len(values) > 0 {
+			identity.UserID = values[0]
+		}
+	}
+
+	collected := make(map[string][]string)
+	for _, r := range m.attributes {
+		collected[r.name] = append(collected[r.name], r.values(cert)...)
+	}
+	for name, values := range collected {
+		if len(values) > 0 {
+			identity.Attributes[name] = strings.Join(dedupe(values), ",")
+		}
+	}
+
+	var roles []string
+	for _, r := range m.roles {
+		roles = append(roles, r.values(cert)...)
+	}
+	identity.Roles = dedupe(roles)
+	return identity
+}
+
+// dedupe returns values sorted without repeats
+func dedupe(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	out := sorted[:1]
+	for _, v := range sorted[1:] {
+		if v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package certidentity
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestMapperIdentity(t *testing.T) {
+	teams, _ := asn1.Marshal([]string{"payments", "search"})
+	tier, _ := asn1.Marshal("gold")
+	spiffe, _ := url.Parse("spiffe://example.org/user/alice")
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "alice",
+			Organization:       []string{"Example"},
+			OrganizationalUnit: []string{"role-operator", "role-viewer", "ops"},
+		},
+		EmailAddresses: []string{"alice@example.org"},
+		URIs:           []*url.URL{spiffe},
+		Extensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55555, 1}, Value: teams},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55555, 2}, Value: tier},
+		},
+	}
+
+	// Without a mapping the CN names the caller
+	var none *Mapper
+	if id := none.Identity(cert); id.UserID != "alice" || len(id.Roles) != 0 {
+		t.Errorf("default identity = %+v", id)
+	}
+
+	m, err := New(config.IdentityMappingConfig{
+		UserID: &config.IdentityMapping{From: "email_san"},
+		Attributes: []config.IdentityMapping{
+			{Name: "team", From: "ext:1.3.6.1.4.1.55555.1"},
+			{Name: "tier", From: "ext:1.3.6.1.4.1.55555.2"},
+			{Name: "spiffe_path", From: "uri_san", Match: `^spiffe://example\.org(/.*)$`},
+			{Name: "org", From: "o"},
+		},
+		Roles: []config.IdentityMapping{
+			{From: "ou", Match: `^role-(.+)$`},
+			{From: "ou", Map: map[string]string{"ops": "operator", "dev": "developer"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := m.Identity(cert)
+	if id.UserID != "alice@example.org" {
+		t.Errorf("user ID = %q", id.UserID)
+	}
+	wantAttributes := map[string]string{
+		"team":        "payments,search",
+		"tier":        "gold",
+		"spiffe_path": "/user/alice",
+		"org":         "Example",
+	}
+	if !reflect.DeepEqual(id.Attributes, wantAttributes) {
+		t.Errorf("attributes = %v, want %v", id.Attributes, wantAttributes)
+	}
+	if want := []string{"operator", "viewer"}; !reflect.DeepEqual(id.Roles, want) {
+		t.Errorf("roles = %v, want %v", id.Roles, want)
+	}
+
+	// A mapped user ID the certificate lacks leaves the caller unnamed
+	if id := m.Identity(&x509.Certificate{Subject: pkix.Name{CommonName: "bob"}}); id.UserID != "" || len(id.Roles) != 0 {
+		t.Errorf("identity without the mapped fields = %+v", id)
+	}
+}
+
+func TestNewRejectsBadMappings(t *testing.T) {
+	for name, cfg := range map[string]config.IdentityMappingConfig{
+		"unknown source":     {Roles: []config.IdentityMapping{{From: "serial"}}},
+		"invalid OID":        {Roles: []config.IdentityMapping{{From: "ext:1.x"}}},
+		"invalid match":      {Roles: []config.IdentityMapping{{From: "ou", Match: "("}}},
+		"unnamed attribute":  {Attributes: []config.IdentityMapping{{From: "ou"}}},
+		"bad user ID source": {UserID: &config.IdentityMapping{From: "subject"}},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+
+	if m, err := New(config.IdentityMappingConfig{}); m != nil || err != nil {
+		t.Errorf("empty mapping = %v, %v; want nil", m, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package chaos injects faults into gRPC servers so that clients' retries and
+// the core's handling of failing agents can be exercised before production.
+// Faults are drawn per call: added latency, calls failing with Unavailable,
+// and streams dropped partway through.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dropAfter is how many messages a dropped stream sends before failing, so
+// that clients see a stream break after it started rather than a failed call
+const dropAfter = 1
+
+// Injector decides which calls to disturb
+type Injector struct {
+	methods        []string
+	latency        time.Duration
+	latencyPercent float64
+	errorPercent   float64
+	dropPercent    float64
+
+	mu   sync.Mutex
+	rand func() float64 // In [0, 100)
+}
+
+// New returns the injector cfg describes, or nil when chaos is disabled
+func New(cfg config.ChaosConfig) (*Injector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{
+		{"latency_percent", cfg.LatencyPercent},
+		{"error_percent", cfg.ErrorPercent},
+		{"drop_percent", cfg.DropPercent},
+	} {
+		if p.value < 0 || p.value > 100 {
+			return nil, fmt.Errorf("chaos %s must be between 0 and 100, got %v", p.name, p.value)
+		}
+	}
+
+	var latency time.Duration
+	if cfg.Latency != "" {
+		d, err := time.ParseDuration(cfg.Latency)
+		if err != nil {
+			return nil, fmt.Errorf("chaos: parse latency: %w", err)
+		}
+		latency = d
+	}
+	if cfg.LatencyPercent > 0 && latency <= 0 {
+		return nil, fmt.Errorf("chaos latency_percent needs a latency")
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return &Injector{
+		methods:        cfg.Methods,
+		latency:        latency,
+		latencyPercent: cfg.LatencyPercent,
+		errorPercent:   cfg.ErrorPercent,
+		dropPercent:    cfg.DropPercent,
+		rand:           func() float64 { return r.Float64() * 100 },
+	}, nil
+}
+
+// String summarizes the faults, for the warning servers log at startup
+func (in *Injector) String() string {
+	methods := "all methods"
+	if len(in.methods) > 0 {
+		methods = strings.Join(in.methods, ", ")
+	}
+	return fmt.Sprintf("latency %s on %v%%, unavailable on %v%%, dropped streams on %v%% of calls to %s",
+		in.latency, in.latencyPercent, in.errorPercent, in.dropPercent, methods)
+}
+
+// SetRand replaces the source of randomness; rand must return values in
+// [0, 100). Tests use it to pick the faults.
+func (in *Injector) SetRand(rand func() float64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rand = rand
+}
+
+// UnaryServerInterceptor delays and fails unary calls
+func (in *Injector) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !in.matches(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	if err := in.disturb(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor delays, fails and drops streams
+func (in *Injector) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !in.matches(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	if err := in.disturb(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	if in.roll(in.dropPercent) {
+		ss = &droppingStream{ServerStream: ss, method: info.FullMethod, left: dropAfter}
+	}
+	return handler(srv, ss)
+}
+
+// disturb sleeps for the latency and fails the call, each on its share of
+// calls
+func (in *Injector) disturb(ctx context.Context, method string) error {
+	if in.roll(in.latencyPercent) {
+		timer := time.NewTimer(in.latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if in.roll(in.errorPercent) {
+		return status.Errorf(codes.Unavailable, "chaos: injected failure of %s", method)
+	}
+	return nil
+}
+
+func (in *Injector) roll(percent float64) bool {
+	if percent <= 0 {
+		return false
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.rand() < percent
+}
+
+// matches reports whether faults apply to method. Patterns are full method
+// names, or "/pkg.Service/*" for every method of a service.
+func (in *Injector) matches(method string) bool {
+	if len(in.methods) == 0 {
+		return true
+	}
+	for _, pattern := range in.methods {
+		if pattern == method {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// droppingStream fails once it has sent its share of messages, as a stream
+// whose connection broke does
+type droppingStream struct {
+	grpc.ServerStream
+	method string
+	left   int
+}
+
+func (s *droppingStream) SendMsg(m interface{}) error {
+	if s.left <= 0 {
+		return status.Errorf(codes.Unavailable, "chaos: injected drop of %s", s.method)
+	}
+	s.left--
+	return s.ServerStream.SendMsg(m)
+}
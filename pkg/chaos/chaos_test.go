@@ -0,0 +1,125 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStream struct {
+	grpc.ServerStream
+	sent int
+}
+
+func (s *fakeStream) Context() context.Context  { return context.Background() }
+func (s *fakeStream) SendMsg(interface{}) error { s.sent++; return nil }
+
+// always makes every roll hit
+func always() float64 { return 0 }
+
+// never makes every roll miss
+func never() float64 { return 99.99 }
+
+func TestDisabled(t *testing.T) {
+	in, err := New(config.ChaosConfig{ErrorPercent: 100})
+	if err != nil || in != nil {
+		t.Fatalf("New = %v, %v; want nil without enabled", in, err)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, cfg := range []config.ChaosConfig{
+		{Enabled: true, ErrorPercent: 150},
+		{Enabled: true, DropPercent: -1},
+		{Enabled: true, Latency: "soon", LatencyPercent: 10},
+		{Enabled: true, LatencyPercent: 10},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+func TestUnaryFaults(t *testing.T) {
+	in, err := New(config.ChaosConfig{Enabled: true, ErrorPercent: 50, Methods: []string{"/mandau.agent.v1.StackService/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called++
+		return "ok", nil
+	}
+	call := func(method string) error {
+		_, err := in.UnaryServerInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	in.SetRand(always)
+	if err := call("/mandau.agent.v1.StackService/ListStacks"); status.Code(err) != codes.Unavailable {
+		t.Errorf("matching call: %v, want unavailable", err)
+	}
+	if err := call("/mandau.agent.v1.CoreService/ListAgents"); err != nil {
+		t.Errorf("other service: %v, want it untouched", err)
+	}
+
+	in.SetRand(never)
+	if err := call("/mandau.agent.v1.StackService/ListStacks"); err != nil {
+		t.Errorf("missed roll: %v, want success", err)
+	}
+	if called != 2 {
+		t.Errorf("handler ran %d times, want 2", called)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	in, err := New(config.ChaosConfig{Enabled: true, Latency: "50ms", LatencyPercent: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/mandau.agent.v1.StackService/ListStacks"}
+
+	start := time.Now()
+	if _, err := in.UnaryServerInterceptor(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("call took %s, want at least the latency", elapsed)
+	}
+
+	// Callers giving up end the delay
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := in.UnaryServerInterceptor(ctx, nil, info, handler); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expired call: %v, want deadline exceeded", err)
+	}
+}
+
+func TestDroppedStream(t *testing.T) {
+	in, err := New(config.ChaosConfig{Enabled: true, DropPercent: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := &fakeStream{}
+	info := &grpc.StreamServerInfo{FullMethod: "/mandau.agent.v1.StackService/ApplyStack"}
+	err = in.StreamServerInterceptor(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			if err := stream.SendMsg(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("stream: %v, want unavailable", err)
+	}
+	if ss.sent != dropAfter {
+		t.Errorf("sent %d messages before the drop, want %d", ss.sent, dropAfter)
+	}
+}
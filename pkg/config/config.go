@@ -9,10 +9,35 @@ import (
 
 // CoreConfig represents the configuration for the core server
 type CoreConfig struct {
-	Server           ServerConfig           `yaml:"server"`
-	Plugins          PluginConfig           `yaml:"plugins"`
-	AgentManagement  AgentManagementConfig  `yaml:"agent_management"`
-	PluginDir        string                 `yaml:"plugin_dir"`
+	Server          ServerConfig          `yaml:"server"`
+	Plugins         PluginConfig          `yaml:"plugins"`
+	AgentManagement AgentManagementConfig `yaml:"agent_management"`
+	PluginDir       string                `yaml:"plugin_dir"`
+	Groups          []AgentGroupConfig    `yaml:"groups,omitempty"`
+	GroupsFile      string                `yaml:"groups_file,omitempty"` // Stores groups managed through the API
+	FreezeFile      string                `yaml:"freeze_file,omitempty"` // Keeps the cluster freeze across restarts
+	Approvals       ApprovalConfig        `yaml:"approvals,omitempty"`
+	BreakGlass      BreakGlassConfig      `yaml:"break_glass,omitempty"`
+	Anomaly         AnomalyConfig         `yaml:"anomaly,omitempty"`
+	Quotas          QuotaConfig           `yaml:"quotas,omitempty"`
+	Reporting       ReportingConfig       `yaml:"reporting,omitempty"`
+	ReadCache       ReadCacheConfig       `yaml:"read_cache,omitempty"`
+	FanOut          FanOutConfig          `yaml:"fan_out,omitempty"`
+	CircuitBreaker  CircuitBreakerConfig  `yaml:"circuit_breaker,omitempty"`
+	Enrollment      EnrollmentConfig      `yaml:"enrollment,omitempty"`
+	OfflineQueue    OfflineQueueConfig    `yaml:"offline_queue,omitempty"`
+	AgentTLS        TLSConfig             `yaml:"agent_tls,omitempty"` // Connections to agents; defaults to server.tls
+	Proxy           ProxyConfig           `yaml:"proxy,omitempty"`
+	Chaos           ChaosConfig           `yaml:"chaos,omitempty"`
+	Redaction       RedactionConfig       `yaml:"redaction,omitempty"`
+	Artifacts       ArtifactsConfig       `yaml:"artifacts,omitempty"`
+}
+
+// ArtifactsConfig is the core's content-addressable artifact store
+type ArtifactsConfig struct {
+	Dir        string `yaml:"dir,omitempty"`         // Empty disables the store
+	Retention  string `yaml:"retention,omitempty"`   // Unpinned artifacts unused this long are collected, default "720h"
+	GCInterval string `yaml:"gc_interval,omitempty"` // How often garbage is collected, default "24h"
 }
 
 // AgentConfig represents the configuration for the agent
@@ -24,27 +49,79 @@ type AgentConfig struct {
 	Stacks           StacksConfig           `yaml:"stacks"`
 	Plugins          PluginConfig           `yaml:"plugins"`
 	Security         SecurityConfig         `yaml:"security"`
+	Logs             LogsConfig             `yaml:"logs,omitempty"`
+	Deployments      DeploymentsConfig      `yaml:"deployments,omitempty"`
+	Chaos            ChaosConfig            `yaml:"chaos,omitempty"`
+	Redaction        RedactionConfig        `yaml:"redaction,omitempty"`
+	Commands         []CommandConfig        `yaml:"commands,omitempty"` // What mandau fleet run may run
+	Transfers        TransfersConfig        `yaml:"transfers,omitempty"`
+}
+
+// TransfersConfig tunes the chunked transfers of file uploads, image
+// bundles and backup downloads
+type TransfersConfig struct {
+	Dir         string   `yaml:"dir,omitempty"`          // Partial uploads, default <stacks.root_dir>.transfers
+	MaxRate     string   `yaml:"max_rate,omitempty"`     // Bytes per second of all transfers together, e.g. "20M"; unlimited when empty
+	ExpireAfter string   `yaml:"expire_after,omitempty"` // Uploads untouched this long are removed, default 24h
+	BackupDirs  []string `yaml:"backup_dirs,omitempty"`  // Where backups may be downloaded from, default /var/backups/mandau
+}
+
+// CommandConfig is a command the agent runs on request, by name. Callers
+// cannot pass arguments; each variant needs its own entry.
+type CommandConfig struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Command     []string `yaml:"command,omitempty"` // Program and arguments, run without a shell
+	Script      string   `yaml:"script,omitempty"`  // Run with /bin/sh -c instead, when command is unset
+	Timeout     string   `yaml:"timeout,omitempty"` // Default 1m
 }
 
 // ServerConfig contains server-related configuration
 type ServerConfig struct {
-	ListenAddr string    `yaml:"listen_addr"`
-	TLS        TLSConfig `yaml:"tls"`
+	ListenAddr  string                `yaml:"listen_addr"`
+	ListenAddrs []string              `yaml:"listen_addrs,omitempty"` // Core: more addresses, e.g. "[::]:8443" next to "0.0.0.0:8443"
+	UnixSocket  string                `yaml:"unix_socket,omitempty"`  // Core: socket for local tools, same TLS as TCP
+	TLS         TLSConfig             `yaml:"tls"`
+	Reflection  bool                  `yaml:"reflection"`         // Expose gRPC server reflection
+	Identity    IdentityMappingConfig `yaml:"identity,omitempty"` // How callers' certificates become identities
+}
+
+// IdentityMappingConfig maps parts of a caller's certificate into its
+// identity, for PKIs that encode roles or teams in certificates. Without
+// it the user ID is the subject CN.
+type IdentityMappingConfig struct {
+	UserID     *IdentityMapping  `yaml:"user_id,omitempty"` // First value found; default the CN
+	Attributes []IdentityMapping `yaml:"attributes,omitempty"`
+	Roles      []IdentityMapping `yaml:"roles,omitempty"`
+}
+
+// IdentityMapping takes values from one part of a certificate
+type IdentityMapping struct {
+	// cn, o, ou, dns_san, email_san, uri_san, ip_san, or ext:<OID> for a
+	// string or sequence of strings in a custom extension
+	From  string            `yaml:"from"`
+	Name  string            `yaml:"name,omitempty"`  // Attribute set; attributes only
+	Match string            `yaml:"match,omitempty"` // Values must match; its first group, if any, is kept
+	Map   map[string]string `yaml:"map,omitempty"`   // Translates values; others are dropped when set
 }
 
 // ServerConnectionConfig contains connection configuration to the core server
 type ServerConnectionConfig struct {
-	CoreAddr string    `yaml:"core_addr"`
-	TLS      TLSConfig `yaml:"tls"`
+	CoreAddr    string    `yaml:"core_addr"`
+	TLS         TLSConfig `yaml:"tls"`
+	Compression string    `yaml:"compression,omitempty"`   // gzip (default), zstd or none
+	DialOutOnly bool      `yaml:"dial_out_only,omitempty"` // Open no listener; take work from heartbeat responses
 }
 
 // TLSConfig contains TLS-related configuration
 type TLSConfig struct {
-	CertPath   string `yaml:"cert_path"`
-	KeyPath    string `yaml:"key_path"`
-	CAPath     string `yaml:"ca_path"`
-	MinVersion string `yaml:"min_version"`
-	ServerName string `yaml:"server_name"`
+	CertPath   string   `yaml:"cert_path"` // May include intermediates after the certificate
+	KeyPath    string   `yaml:"key_path"`
+	CAPath     string   `yaml:"ca_path"`
+	CAPaths    []string `yaml:"ca_paths,omitempty"` // More trusted CA bundles, e.g. during CA rotation
+	MinVersion string   `yaml:"min_version"`
+	ServerName string   `yaml:"server_name"`          // Name the peer's certificate must be valid for
+	PinnedCAs  []string `yaml:"pinned_cas,omitempty"` // sha256:<hex> of CAs the peer must chain to
 }
 
 // AgentInfoConfig contains agent identification configuration
@@ -52,24 +129,131 @@ type AgentInfoConfig struct {
 	ID       string            `yaml:"id"`
 	Hostname string            `yaml:"hostname"`
 	Labels   map[string]string `yaml:"labels"`
+	PIDFile  string            `yaml:"pid_file,omitempty"` // Written at startup, removed on exit
 }
 
 // DockerConfig contains Docker-related configuration
 type DockerConfig struct {
-	Socket     string `yaml:"socket"`
-	APIVersion string `yaml:"api_version"`
+	Socket          string                `yaml:"socket"`
+	APIVersion      string                `yaml:"api_version"`
+	RegistryMirrors RegistryMirrorsConfig `yaml:"registry_mirrors,omitempty"` // Pull images through mirrors
+}
+
+// RegistryMirrorsConfig rewrites image pulls to mirrors of their registry,
+// for air-gapped and bandwidth-constrained hosts. Mirrors are tried in
+// order, those failing their health check last.
+type RegistryMirrorsConfig struct {
+	Mirrors        []RegistryMirrorConfig `yaml:"mirrors,omitempty"`
+	NoUpstream     bool                   `yaml:"no_upstream,omitempty"`     // Never pull from the registry itself
+	HealthInterval string                 `yaml:"health_interval,omitempty"` // Between health checks, default 30s
+}
+
+// RegistryMirrorConfig is one mirror of a registry
+type RegistryMirrorConfig struct {
+	Registry string `yaml:"registry,omitempty"` // Registry it mirrors, default docker.io
+	// Where images are pulled from instead: host[:port], with a path when the
+	// registry's repositories are under one, e.g. "mirror.internal:5000/hub".
+	// An http:// scheme makes health checks use plain HTTP.
+	URL string `yaml:"url"`
 }
 
 // StacksConfig contains stack-related configuration
 type StacksConfig struct {
-	RootDir                  string `yaml:"root_dir"`
-	MaxConcurrentOperations  int    `yaml:"max_concurrent_operations"`
+	RootDir                 string                `yaml:"root_dir"`
+	MaxConcurrentOperations int                   `yaml:"max_concurrent_operations"`
+	OperationsDir           string                `yaml:"operations_dir,omitempty"`        // Operation records, default <root_dir>.operations
+	ReconcileInterrupted    bool                  `yaml:"reconcile_interrupted,omitempty"` // Re-run operations cut short by a restart
+	Encryption              StackEncryptionConfig `yaml:"encryption,omitempty"`
+	ReadyTimeout            string                `yaml:"ready_timeout,omitempty"` // How long applies wait for healthy services, default 5m
+	Hooks                   StackHooksConfig      `yaml:"hooks,omitempty"`
+	MaxSize                 string                `yaml:"max_size,omitempty"`        // Disk per stack before applies are refused, e.g. "10G"
+	TimelineDir             string                `yaml:"timeline_dir,omitempty"`    // Per-stack event timelines, default <root_dir>.timeline
+	TimelineEvents          int                   `yaml:"timeline_events,omitempty"` // Events kept per stack, default 1000
+	Storage                 string                `yaml:"storage,omitempty"`         // "files" (default) or "git", committing each change
+}
+
+// StackHooksConfig lets applies run pre- and post-apply shell scripts on
+// the agent host. Hooks run as the agent's user, so they are off unless
+// enabled.
+type StackHooksConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Timeout string `yaml:"timeout,omitempty"` // Per hook, default 10m
+	Shell   string `yaml:"shell,omitempty"`   // Default /bin/sh
+}
+
+// StackEncryptionConfig encrypts compose and .env files at rest. The key is
+// 32 bytes, raw or hex or base64 encoded, read from KeyFile or from the
+// secrets plugin under SecretKey.
+type StackEncryptionConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	KeyFile   string `yaml:"key_file,omitempty"`
+	SecretKey string `yaml:"secret_key,omitempty"` // Name of the key in the secrets plugin
+}
+
+// LogsConfig tunes how followed stack logs are shared between viewers,
+// which host log files agents serve, where logs are shipped and whether
+// they are kept for searching
+type LogsConfig struct {
+	SubscriberBuffer int            `yaml:"subscriber_buffer"` // Entries buffered per viewer, default 1024
+	SlowPolicy       string         `yaml:"slow_policy"`       // drop (default) or block when a viewer falls behind
+	Files            []string       `yaml:"files,omitempty"`   // Host log files mandau host logs may read: paths or patterns such as /var/log/nginx/*.log
+	Ship             LogShipConfig  `yaml:"ship,omitempty"`
+	Index            LogIndexConfig `yaml:"index,omitempty"`
+}
+
+// LogIndexConfig keeps stack logs on the agent host, so mandau stack logs
+// can search them by time and content after containers restart
+type LogIndexConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Dir       string `yaml:"dir,omitempty"`       // Default /var/lib/mandau/logindex
+	MaxSize   string `yaml:"max_size,omitempty"`  // Kept per stack, e.g. 512M; default 256M
+	Retention string `yaml:"retention,omitempty"` // Entries older are removed, default 168h
+}
+
+// LogShipConfig forwards the logs of stack containers, and the journal of
+// the systemd units listed, to a central log store. Entries are labelled
+// with the agent, stack and service they come from.
+type LogShipConfig struct {
+	Sink          string            `yaml:"sink,omitempty"`           // loki, elasticsearch or s3; nothing is shipped when empty
+	URL           string            `yaml:"url,omitempty"`            // Loki or Elasticsearch base URL, or the S3 endpoint
+	Index         string            `yaml:"index,omitempty"`          // Elasticsearch index, default mandau-logs
+	Bucket        string            `yaml:"bucket,omitempty"`         // S3 bucket
+	Region        string            `yaml:"region,omitempty"`         // S3 region, default us-east-1
+	Prefix        string            `yaml:"prefix,omitempty"`         // S3 key prefix, default logs/
+	Username      string            `yaml:"username,omitempty"`       // Basic auth user, or the S3 access key ID
+	PasswordFile  string            `yaml:"password_file,omitempty"`  // Basic auth password, bearer token or S3 secret key
+	SecretKey     string            `yaml:"secret_key,omitempty"`     // The same from the secrets plugin, by name
+	Stacks        string            `yaml:"stacks,omitempty"`         // all (default) or labelled: only stacks labelled logs.ship=true. Stacks labelled logs.ship=false are never shipped
+	Journal       []string          `yaml:"journal,omitempty"`        // Systemd units whose journal is shipped too
+	Labels        map[string]string `yaml:"labels,omitempty"`         // Added to every entry, e.g. env: prod
+	BatchSize     int               `yaml:"batch_size,omitempty"`     // Entries per request, default 500
+	FlushInterval string            `yaml:"flush_interval,omitempty"` // Longest an entry waits to be sent, default 5s
+	Buffer        int               `yaml:"buffer,omitempty"`         // Entries held while the sink is unreachable, default 10000
+	StateDir      string            `yaml:"state_dir,omitempty"`      // Where read positions are kept, default /var/lib/mandau/logship
+}
+
+// DeploymentsConfig contains host service deployment configuration
+type DeploymentsConfig struct {
+	ManifestDir    string `yaml:"manifest_dir,omitempty"`    // What each deployment created, default /var/lib/mandau/deployments
+	SecurityLabels string `yaml:"security_labels,omitempty"` // SELinux and AppArmor changes deployments need: advise (default), apply or off
 }
 
 // PluginConfig contains plugin-related configuration
 type PluginConfig struct {
-	Enabled map[string]bool                `yaml:"enabled"`
-	Configs map[string]map[string]interface{} `yaml:"configs,omitempty"`
+	Enabled       map[string]bool                   `yaml:"enabled"`
+	Configs       map[string]map[string]interface{} `yaml:"configs,omitempty"`
+	Marketplace   MarketplaceConfig                 `yaml:"marketplace,omitempty"`
+	HostAudit     string                            `yaml:"host_audit,omitempty"`     // Agent: host changes of sandboxed plugins to audit: all (default), commands, files or off
+	ManagedFiles  string                            `yaml:"managed_files,omitempty"`  // Agent: checksums of the files host service plugins wrote, default /var/lib/mandau/managed-files.json
+	DriftInterval string                            `yaml:"drift_interval,omitempty"` // Agent: how often managed host artifacts are scanned for drift, default 1h; 0 disables
+}
+
+// MarketplaceConfig is the signed plugin index. The core hosts it; agents
+// install plugins from it only when it is signed by a key they trust.
+type MarketplaceConfig struct {
+	Index       string   `yaml:"index,omitempty"`        // Core: signed index file, from "mandau plugins index sign"
+	TrustedKeys []string `yaml:"trusted_keys,omitempty"` // Agent: base64 ed25519 public keys; none disables installs
+	InstallDir  string   `yaml:"install_dir,omitempty"`  // Agent: default /var/lib/mandau/plugins
 }
 
 // SecurityConfig contains security-related configuration
@@ -77,6 +261,9 @@ type SecurityConfig struct {
 	ExecTimeout         string `yaml:"exec_timeout"`
 	LogRetention        string `yaml:"log_retention"`
 	TerminalRecording   bool   `yaml:"terminal_recording"`
+	// Processes mandau host kill refuses to signal, by name; unset protects
+	// the agent, sshd, dockerd, containerd and init
+	ProtectedProcesses []string `yaml:"protected_processes,omitempty"`
 }
 
 // AgentManagementConfig contains agent management configuration
@@ -84,6 +271,162 @@ type AgentManagementConfig struct {
 	HeartbeatInterval string `yaml:"heartbeat_interval"`
 	OfflineTimeout    string `yaml:"offline_timeout"`
 	AutoDeregister    bool   `yaml:"auto_deregister"`
+	Compression       string `yaml:"compression,omitempty"`        // Calls to agents: gzip (default), zstd or none
+	IdentityFile      string `yaml:"identity_file,omitempty"`      // Keeps agent ID to certificate bindings across restarts
+	CertProfiles      string `yaml:"cert_profiles,omitempty"`      // permissive (default) or strict
+	MaxClockSkew      string `yaml:"max_clock_skew,omitempty"`     // Agent clock drift flagged and audited, default 2s
+	MaxHeartbeatRate  int    `yaml:"max_heartbeat_rate,omitempty"` // Heartbeats per second the fleet sends at most, default 100
+}
+
+// AgentGroupConfig declares an agent group loaded at core startup
+type AgentGroupConfig struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Agents      []string          `yaml:"agents"`
+	Selector    map[string]string `yaml:"selector"`
+}
+
+// ApprovalConfig lists actions that must be approved by a second identity
+type ApprovalConfig struct {
+	Rules []ApprovalRule `yaml:"rules"`
+	TTL   string         `yaml:"ttl"` // How long an approval request stays valid
+}
+
+// ApprovalRule matches an action on a resource, optionally limited to a group
+type ApprovalRule struct {
+	Action   string `yaml:"action"`   // e.g. "delete", "*"
+	Resource string `yaml:"resource"` // e.g. "stack:*"
+	Group    string `yaml:"group"`    // Only agents in this group
+}
+
+// BreakGlassConfig limits temporary role elevation
+type BreakGlassConfig struct {
+	MaxTTL string `yaml:"max_ttl"` // Longest grant allowed, default 4h
+}
+
+// EnrollmentConfig lets new agents obtain a certificate with a join token
+type EnrollmentConfig struct {
+	ListenAddr string            `yaml:"listen_addr"`        // Listener without client certificates; empty disables enrollment
+	CAKeyPath  string            `yaml:"ca_key_path"`        // Key of the CA at server.tls.ca_path, used to sign agent certificates
+	CertTTL    string            `yaml:"cert_ttl,omitempty"` // Validity of issued certificates, default 8760h
+	Tokens     []EnrollmentToken `yaml:"tokens"`
+}
+
+// EnrollmentToken admits agents until it expires or is used up
+type EnrollmentToken struct {
+	Token     string            `yaml:"token"`
+	Labels    map[string]string `yaml:"labels,omitempty"`     // Written to the enrolled agent's config
+	ExpiresAt string            `yaml:"expires_at,omitempty"` // RFC 3339; empty never expires
+	MaxUses   int               `yaml:"max_uses,omitempty"`   // 0 is unlimited
+}
+
+// OfflineQueueConfig controls agent instructions waiting for delivery
+type OfflineQueueConfig struct {
+	File       string `yaml:"file,omitempty"`        // Keeps pending instructions across core restarts
+	DefaultTTL string `yaml:"default_ttl,omitempty"` // Queued stack changes, default "24h"
+	MaxTTL     string `yaml:"max_ttl,omitempty"`     // Longest TTL a caller may ask for, default "168h"
+}
+
+// AnomalyConfig contains rules evaluated over the audit stream
+type AnomalyConfig struct {
+	Rules []AnomalyRule `yaml:"rules"`
+}
+
+// AnomalyRule raises a notification when an identity produces Threshold
+// matching audit entries within Window
+type AnomalyRule struct {
+	Name      string `yaml:"name"`
+	Action    string `yaml:"action"`    // Audit action pattern, trailing * allowed
+	Result    string `yaml:"result"`    // e.g. "denied", "error"; empty matches any
+	Threshold int    `yaml:"threshold"` // Default 1
+	Window    string `yaml:"window"`    // Default 1m
+	// BusinessHours such as "09:00-18:00", or "22:00-06:00" across midnight,
+	// limits the rule to entries outside those hours or on days not listed
+	// in BusinessDays
+	BusinessHours string   `yaml:"business_hours"`
+	BusinessDays  []string `yaml:"business_days"` // e.g. ["mon", "tue"], default Mon-Fri
+	Severity      string   `yaml:"severity"`      // Default "warning"
+}
+
+// QuotaConfig limits what may be deployed; zero values mean unlimited
+type QuotaConfig struct {
+	Agent      AgentQuota                `yaml:"agent"`      // Applies to every agent
+	Namespaces map[string]NamespaceQuota `yaml:"namespaces"` // "*" applies to unlisted namespaces
+}
+
+// AgentQuota limits stacks and declared containers on a single agent
+type AgentQuota struct {
+	MaxStacks     int `yaml:"max_stacks"`
+	MaxContainers int `yaml:"max_containers"`
+}
+
+// NamespaceQuota limits a namespace across all agents. CPU and memory are
+// the sum of compose deploy.resources.reservations times replicas.
+type NamespaceQuota struct {
+	MaxStacks int     `yaml:"max_stacks"`
+	MaxCPUs   float64 `yaml:"max_cpus"`
+	MaxMemory string  `yaml:"max_memory"` // e.g. "8G"
+}
+
+// ReportingConfig schedules resource snapshots for chargeback
+type ReportingConfig struct {
+	Interval string   `yaml:"interval"` // e.g. "1h"; empty disables periodic snapshots
+	Dir      string   `yaml:"dir"`      // Where snapshot files are written
+	Formats  []string `yaml:"formats"`  // "json", "csv"; default json
+}
+
+// ReadCacheConfig caches proxied agent reads in the core
+type ReadCacheConfig struct {
+	TTL string `yaml:"ttl"` // e.g. "2s" (default); "0s" disables caching
+}
+
+// FanOutConfig bounds queries the core sends to every agent at once
+type FanOutConfig struct {
+	Parallelism  int    `yaml:"parallelism"`   // Agents queried concurrently, default 16
+	AgentTimeout string `yaml:"agent_timeout"` // Per-agent deadline, default "10s"
+}
+
+// CircuitBreakerConfig stops the core from calling agents whose calls keep
+// failing and bounds its retries of failed reads
+type CircuitBreakerConfig struct {
+	Failures    int     `yaml:"failures,omitempty"`     // Consecutive failed calls that open an agent's circuit, default 5; -1 disables
+	Cooldown    string  `yaml:"cooldown,omitempty"`     // Wait before a probe call goes through an open circuit, default "30s"
+	MaxAttempts int     `yaml:"max_attempts,omitempty"` // Tries of a read that found the agent unavailable, default 3; 1 disables retries
+	RetryBudget float64 `yaml:"retry_budget,omitempty"` // Retries earned per successful call to an agent, default 0.1
+}
+
+// ProxyConfig lets the core forward agent services it has no handler for
+type ProxyConfig struct {
+	Routes []ProxyRoute `yaml:"routes,omitempty"`
+}
+
+// ProxyRoute forwards matching methods to the agent each call names.
+// Callers need "read" on Resource, or "write" when the methods change the
+// host.
+type ProxyRoute struct {
+	Method     string `yaml:"method"`               // "/pkg.Service/Method", or "/pkg.Service/*" for all its methods
+	Resource   string `yaml:"resource"`             // e.g. "host:backup"
+	Write      bool   `yaml:"write,omitempty"`      // Changes the host; refused while the cluster is frozen
+	Capability string `yaml:"capability,omitempty"` // Agent capability the methods need
+}
+
+// ChaosConfig injects faults into the calls a server handles, for testing
+// how clients and the core cope with them. Never enable it in production.
+type ChaosConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Methods        []string `yaml:"methods,omitempty"`         // Methods to disturb, "/pkg.Service/*" for a whole service; default all
+	Latency        string   `yaml:"latency,omitempty"`         // Delay added to slowed calls, e.g. "2s"
+	LatencyPercent float64  `yaml:"latency_percent,omitempty"` // Share of calls slowed down
+	ErrorPercent   float64  `yaml:"error_percent,omitempty"`   // Share of calls failed with Unavailable
+	DropPercent    float64  `yaml:"drop_percent,omitempty"`    // Share of streams broken after their first message
+}
+
+// RedactionConfig names the variables whose values are masked in operation
+// events, logs, diffs and audit records
+type RedactionConfig struct {
+	// Case-insensitive regular expressions matched against variable names;
+	// default PASSWORD, PASSWD, SECRET, TOKEN, KEY and CREDENTIAL
+	Patterns []string `yaml:"patterns,omitempty"`
 }
 
 // LoadCoreConfig loads the core server configuration from a YAML file
@@ -158,6 +501,10 @@ func CreateDefaultCoreConfig() *CoreConfig {
     permissions:
       - resource: "*"
         actions: ["read", "logs"]
+  - name: agent
+    permissions:
+      - resource: "agent:*"
+        actions: ["register", "heartbeat", "fetch_artifact"]
 users:
   - id: "admin@example.com"
     name: "Administrator"
@@ -166,8 +513,8 @@ users:
     name: "Operations Team"
     roles: ["operator"]
   - id: "mandau-agent"
-    name: "Agent User"
-    roles: ["admin"]  # Agent needs admin rights to manage stacks on its host
+    name: "Agent"
+    roles: ["agent"]  # Register, heartbeat and fetch artifacts only
   - id: "mandau-cli"
     name: "CLI User"
     roles: ["admin"]`,
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultCPUProfile = 30 * time.Second
+	maxCPUProfile     = 5 * time.Minute
+)
+
+// profiles are the runtime/pprof profiles GetProfile serves besides cpu
+var profiles = []string{"goroutine", "heap", "allocs", "threadcreate", "block", "mutex"}
+
+// authorizeAdmin checks that the caller may look into the core's runtime,
+// which needs the admin action on core:admin
+func (c *Core) authorizeAdmin(ctx context.Context) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	if err := auth.Authorize(ctx, identity, &plugin.Action{
+		Action:   "admin",
+		Resource: "core:admin",
+	}); err != nil {
+		return status.Errorf(codes.PermissionDenied, "%s may not use the admin API", identity.UserID)
+	}
+	return nil
+}
+
+// GetProfile streams a pprof profile of the core
+func (c *Core) GetProfile(req *agentv1.GetProfileRequest, stream agentv1.AdminService_GetProfileServer) error {
+	ctx := stream.Context()
+	if err := c.authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if req.Name == "cpu" {
+		duration := defaultCPUProfile
+		if req.Duration != nil {
+			duration = req.Duration.AsDuration()
+		}
+		if duration <= 0 || duration > maxCPUProfile {
+			return status.Errorf(codes.InvalidArgument, "cpu profiles last up to %s", maxCPUProfile)
+		}
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "cpu profile: %v", err)
+		}
+		timer := time.NewTimer(duration)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			pprof.StopCPUProfile()
+			return ctx.Err()
+		}
+		pprof.StopCPUProfile()
+	} else {
+		if !validProfile(req.Name) {
+			return status.Errorf(codes.InvalidArgument, "unknown profile %q, want cpu, %s", req.Name, strings.Join(profiles, ", "))
+		}
+		if err := pprof.Lookup(req.Name).WriteTo(&buf, int(req.Debug)); err != nil {
+			return status.Errorf(codes.Internal, "%s profile: %v", req.Name, err)
+		}
+	}
+
+	for data := buf.Bytes(); len(data) > 0; {
+		n := min(len(data), transfer.ChunkSize)
+		if err := stream.Send(&agentv1.ProfileChunk{Data: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func validProfile(name string) bool {
+	for _, p := range profiles {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ListConnections returns the core's channel to each agent it knows
+func (c *Core) ListConnections(ctx context.Context, req *agentv1.ListConnectionsRequest) (*agentv1.ListConnectionsResponse, error) {
+	if err := c.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &agentv1.ListConnectionsResponse{}
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		ch := &agentv1.AgentChannel{
+			AgentId:     agent.ID,
+			Address:     agent.Address,
+			Status:      string(agent.Status),
+			DialOutOnly: agent.DialOutOnly,
+			LastSeen:    timestamppb.New(agent.LastSeen),
+		}
+		if agent.Client != nil {
+			ch.ChannelState = agent.Client.GetState().String()
+		}
+		if cert := agent.Certificate; cert != nil {
+			ch.CertificateSubject = cert.Subject.String()
+			ch.CertificateExpires = timestamppb.New(cert.NotAfter)
+		}
+		if agent.ClockSkew != nil {
+			ch.ClockSkew = durationpb.New(*agent.ClockSkew)
+		}
+		agent.mu.RUnlock()
+
+		if c.breakers != nil {
+			ch.Circuit = c.breakers.state(ch.AgentId)
+		}
+		resp.Connections = append(resp.Connections, ch)
+	}
+	return resp, nil
+}
+
+// GetRegistry returns the core's records of its agents and the plugins it
+// loaded
+func (c *Core) GetRegistry(ctx context.Context, req *agentv1.GetRegistryRequest) (*agentv1.CoreRegistry, error) {
+	if err := c.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	registry := &agentv1.CoreRegistry{}
+	now := time.Now()
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		ra := &agentv1.RegistryAgent{
+			AgentId:        agent.ID,
+			Hostname:       agent.Hostname,
+			Status:         string(agent.Status),
+			Labels:         agent.Labels,
+			ReportedLabels: agent.ReportedLabels,
+			OperatorLabels: agent.OperatorLabels,
+			Capabilities:   append([]string(nil), agent.Capabilities...),
+			Stacks:         append([]string(nil), agent.Stacks...),
+			InMaintenance:  agent.inMaintenance(now),
+		}
+		for key := range agent.RemovedLabels {
+			ra.RemovedLabels = append(ra.RemovedLabels, key)
+		}
+		sort.Strings(ra.RemovedLabels)
+		for _, p := range agent.Plugins {
+			ra.Plugins = append(ra.Plugins, p.Name)
+		}
+		agent.mu.RUnlock()
+		registry.Agents = append(registry.Agents, ra)
+	}
+
+	for _, p := range c.plugins.ListAll() {
+		cp := &agentv1.CorePlugin{Name: p.Name(), Version: p.Version()}
+		for _, capability := range p.Capabilities() {
+			cp.Capabilities = append(cp.Capabilities, string(capability))
+		}
+		registry.Plugins = append(registry.Plugins, cp)
+	}
+	sort.Slice(registry.Plugins, func(i, j int) bool { return registry.Plugins[i].Name < registry.Plugins[j].Name })
+	return registry, nil
+}
+
+// GetConfig returns the core's configuration as YAML, with the values of
+// keys the redaction patterns match masked
+func (c *Core) GetConfig(ctx context.Context, req *agentv1.GetConfigRequest) (*agentv1.ConfigDump, error) {
+	if err := c.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if c.config == nil || c.config.FullConfig == nil {
+		return &agentv1.ConfigDump{}, nil
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(c.config.FullConfig); err != nil {
+		return nil, status.Errorf(codes.Internal, "encode config: %v", err)
+	}
+	redactor := c.redactor
+	if redactor == nil {
+		redactor = redact.Default()
+	}
+	maskSecrets(&doc, redactor)
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode config: %v", err)
+	}
+	return &agentv1.ConfigDump{Yaml: string(data)}, nil
+}
+
+// maskSecrets masks the scalar values of mapping keys redactor takes for
+// secrets, anywhere under node. Paths are kept: key_path names where a key
+// is, not the key.
+func maskSecrets(node *yaml.Node, redactor *redact.Redactor) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Value != "" && redactor.Secret(key.Value) && !isPath(key.Value) {
+				value.Value, value.Tag, value.Style = redact.Mask, "!!str", 0
+			}
+		}
+	}
+	for _, child := range node.Content {
+		maskSecrets(child, redactor)
+	}
+}
+
+func isPath(key string) bool {
+	return strings.HasSuffix(key, "_path") || strings.HasSuffix(key, "_file") || strings.HasSuffix(key, "_dir")
+}
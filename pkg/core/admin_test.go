@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func adminCore(t *testing.T) (*Core, agentv1.AdminServiceClient) {
+	t.Helper()
+	skew := 3 * time.Second
+	c := &Core{
+		config: &CoreConfig{FullConfig: &config.CoreConfig{
+			Enrollment: config.EnrollmentConfig{
+				CAKeyPath: "/etc/mandau/ca.key",
+				Tokens:    []config.EnrollmentToken{{Token: "s3cret-token", MaxUses: 2}},
+			},
+		}},
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-2", Hostname: "web-2", Status: AgentStatusOffline, DialOutOnly: true},
+			&AgentConnection{ID: "web-1", Hostname: "web-1", Status: AgentStatusOnline, ClockSkew: &skew,
+				Labels: map[string]string{"env": "prod"}, RemovedLabels: map[string]bool{"zone": true}},
+		),
+		plugins: plugin.NewRegistry(),
+	}
+	server := grpc.NewServer()
+	agentv1.RegisterAdminServiceServer(server, c)
+	return c, agentv1.NewAdminServiceClient(serve(t, server))
+}
+
+func TestAdminGetConfigMasksSecrets(t *testing.T) {
+	_, client := adminCore(t)
+
+	dump, err := client.GetConfig(context.Background(), &agentv1.GetConfigRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(dump.Yaml, "s3cret-token") {
+		t.Errorf("config dump shows the enrollment token:\n%s", dump.Yaml)
+	}
+	for _, want := range []string{"token: '******'", "max_uses: 2", "ca_key_path: /etc/mandau/ca.key"} {
+		if !strings.Contains(dump.Yaml, want) {
+			t.Errorf("config dump lacks %q:\n%s", want, dump.Yaml)
+		}
+	}
+}
+
+func TestAdminConnectionsAndRegistry(t *testing.T) {
+	_, client := adminCore(t)
+	ctx := context.Background()
+
+	conns, err := client.ListConnections(ctx, &agentv1.ListConnectionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conns.Connections) != 2 || conns.Connections[0].AgentId != "web-1" || conns.Connections[1].AgentId != "web-2" {
+		t.Fatalf("connections = %v, want web-1 and web-2", conns.Connections)
+	}
+	if got := conns.Connections[0].ClockSkew.AsDuration(); got != 3*time.Second {
+		t.Errorf("web-1 clock skew = %s, want 3s", got)
+	}
+	if !conns.Connections[1].DialOutOnly {
+		t.Error("web-2 is not dial-out only")
+	}
+
+	registry, err := client.GetRegistry(ctx, &agentv1.GetRegistryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Agents) != 2 {
+		t.Fatalf("registry has %d agents, want 2", len(registry.Agents))
+	}
+	web1 := registry.Agents[0]
+	if web1.Labels["env"] != "prod" || len(web1.RemovedLabels) != 1 || web1.RemovedLabels[0] != "zone" {
+		t.Errorf("web-1 labels %v removed %v, want env=prod and zone removed", web1.Labels, web1.RemovedLabels)
+	}
+}
+
+func TestAdminGetProfile(t *testing.T) {
+	_, client := adminCore(t)
+	ctx := context.Background()
+
+	stream, err := client.GetProfile(ctx, &agentv1.GetProfileRequest{Name: "goroutine", Debug: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		profile.Write(chunk.Data)
+	}
+	if !strings.Contains(profile.String(), "goroutine profile:") {
+		t.Errorf("goroutine profile starts %.80q", profile.String())
+	}
+
+	stream, err = client.GetProfile(ctx, &agentv1.GetProfileRequest{Name: "nonsense"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown profile: %v, want InvalidArgument", err)
+	}
+}
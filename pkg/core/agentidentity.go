@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AgentIdentities binds agent IDs to the certificate identity that first
+// registered them, so one agent's certificate cannot be used to claim
+// another agent's ID. With a file set, bindings survive core restarts;
+// without one an ID is bound again on its first registration after a
+// restart.
+type AgentIdentities struct {
+	mu       sync.Mutex
+	bindings map[string]string // Agent ID to certificate identity
+	file     string
+}
+
+func newAgentIdentities(file string) (*AgentIdentities, error) {
+	ids := &AgentIdentities{bindings: make(map[string]string), file: file}
+	if file == "" {
+		return ids, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read identity file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &ids.bindings); err != nil {
+			return nil, fmt.Errorf("parse identity file: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// certIdentity returns the identity a certificate proves and the agent ID
+// it names, if any. URI SANs (SPIFFE IDs, or mandau://agent/<id> from
+// enrollment) identify a single workload; otherwise the subject CN and DNS
+// SANs are used, which certificates shared between agents cannot tell
+// apart.
+func certIdentity(cert *x509.Certificate) (identity, agentID string) {
+	for _, uri := range cert.URIs {
+		switch uri.Scheme {
+		case "mandau":
+			if uri.Host == "agent" {
+				return uri.String(), strings.TrimPrefix(uri.Path, "/")
+			}
+		case "spiffe":
+			// spiffe://<trust domain>/.../agent/<id>
+			segments := strings.Split(strings.Trim(uri.Path, "/"), "/")
+			if n := len(segments); n >= 2 && segments[n-2] == "agent" {
+				return uri.String(), segments[n-1]
+			}
+			return uri.String(), ""
+		}
+	}
+
+	names := append([]string(nil), cert.DNSNames...)
+	sort.Strings(names)
+	identity = "cn:" + cert.Subject.CommonName
+	if len(names) > 0 {
+		identity += ";dns:" + strings.Join(names, ",")
+	}
+	return identity, ""
+}
+
+// peerCertificate returns the verified client certificate of the caller
+func peerCertificate(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no peer found")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("no verified client certificate")
+	}
+	return tlsInfo.State.VerifiedChains[0][0], nil
+}
+
+// check verifies that identity may act as agentID. With bind set, an
+// unbound ID is bound to identity.
+func (a *AgentIdentities) check(agentID, identity string, bind bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	bound, ok := a.bindings[agentID]
+	if ok {
+		if bound != identity {
+			return fmt.Errorf("agent %s is bound to certificate identity %s, not %s", agentID, bound, identity)
+		}
+		return nil
+	}
+	if !bind {
+		return fmt.Errorf("agent %s has not registered with this certificate", agentID)
+	}
+
+	a.bindings[agentID] = identity
+	if err := a.save(); err != nil {
+		delete(a.bindings, agentID)
+		return fmt.Errorf("save identity binding: %w", err)
+	}
+	log.Printf("Agent %s bound to certificate identity %s", agentID, identity)
+	return nil
+}
+
+// save writes the bindings to the identity file, if any.
+// Callers must hold the lock.
+func (a *AgentIdentities) save() error {
+	if a.file == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(a.bindings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := a.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.file)
+}
+
+// verifyAgentIdentity checks the caller's certificate against the agent ID
+// it claims. On registration an unbound ID is bound to the certificate.
+func (c *Core) verifyAgentIdentity(ctx context.Context, method, agentID string, register bool) error {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return c.agentIdentityDenied(ctx, method, agentID, "", err)
+	}
+
+	identity, named := certIdentity(cert)
+	if named != "" && named != agentID {
+		return c.agentIdentityDenied(ctx, method, agentID, identity,
+			fmt.Errorf("certificate is issued to agent %s", named))
+	}
+
+	if err := c.agentIdentities.check(agentID, identity, register); err != nil {
+		return c.agentIdentityDenied(ctx, method, agentID, identity, err)
+	}
+	return nil
+}
+
+// certAgentID returns the agent ID the caller's certificate names, if any
+func certAgentID(ctx context.Context) string {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return ""
+	}
+	_, agentID := certIdentity(cert)
+	return agentID
+}
+
+// agentIdentityDenied audits a call whose certificate does not match the
+// agent ID it claims and returns the error as PermissionDenied
+func (c *Core) agentIdentityDenied(ctx context.Context, method, agentID, identity string, err error) error {
+	log.Printf("Rejected %s for agent %s: %v", method, agentID, err)
+
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		AgentID:   agentID,
+		Identity:  &plugin.Identity{UserID: identity},
+		Action:    method,
+		Resource:  "agent:" + agentID,
+		Result:    "denied",
+		Metadata:  map[string]string{"auth_error": err.Error()},
+	})
+	return status.Errorf(codes.PermissionDenied, "agent identity: %v", err)
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestCertIdentity(t *testing.T) {
+	tests := []struct {
+		name         string
+		cert         *x509.Certificate
+		wantIdentity string
+		wantAgentID  string
+	}{
+		{
+			name:         "enrolled",
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "mandau-agent"}, URIs: []*url.URL{mustURL(t, "mandau://agent/web-1")}},
+			wantIdentity: "mandau://agent/web-1",
+			wantAgentID:  "web-1",
+		},
+		{
+			name:         "spiffe naming an agent",
+			cert:         &x509.Certificate{URIs: []*url.URL{mustURL(t, "spiffe://example.org/mandau/agent/web-1")}},
+			wantIdentity: "spiffe://example.org/mandau/agent/web-1",
+			wantAgentID:  "web-1",
+		},
+		{
+			name:         "spiffe workload",
+			cert:         &x509.Certificate{URIs: []*url.URL{mustURL(t, "spiffe://example.org/ns/prod/sa/mandau")}},
+			wantIdentity: "spiffe://example.org/ns/prod/sa/mandau",
+		},
+		{
+			name:         "subject only",
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}, DNSNames: []string{"web-1.internal", "mandau-agent"}},
+			wantIdentity: "cn:web-1;dns:mandau-agent,web-1.internal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, agentID := certIdentity(tt.cert)
+			if identity != tt.wantIdentity || agentID != tt.wantAgentID {
+				t.Errorf("certIdentity() = %q, %q; want %q, %q", identity, agentID, tt.wantIdentity, tt.wantAgentID)
+			}
+		})
+	}
+}
+
+// peerContext returns a context whose caller presented cert
+func peerContext(cert *x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestVerifyAgentIdentity(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "identities.json")
+	ids, err := newAgentIdentities(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{plugins: plugin.NewRegistry(), agentIdentities: ids}
+
+	web1 := peerContext(&x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}})
+	web2 := peerContext(&x509.Certificate{Subject: pkix.Name{CommonName: "web-2"}})
+	enrolled := peerContext(&x509.Certificate{URIs: []*url.URL{mustURL(t, "mandau://agent/db-1")}})
+	register := agentv1.CoreService_RegisterAgent_FullMethodName
+	heartbeat := agentv1.CoreService_Heartbeat_FullMethodName
+
+	if err := c.verifyAgentIdentity(web1, register, "web-1", true); err != nil {
+		t.Fatalf("first registration: %v", err)
+	}
+	if err := c.verifyAgentIdentity(web1, heartbeat, "web-1", false); err != nil {
+		t.Errorf("heartbeat with the bound certificate: %v", err)
+	}
+	if err := c.verifyAgentIdentity(web2, register, "web-1", true); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("registration with another certificate: %v", err)
+	}
+	if err := c.verifyAgentIdentity(web2, heartbeat, "web-1", false); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("heartbeat with another certificate: %v", err)
+	}
+	if err := c.verifyAgentIdentity(web2, heartbeat, "web-2", false); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("heartbeat before registration: %v", err)
+	}
+
+	// A certificate naming an agent cannot claim any other ID
+	if err := c.verifyAgentIdentity(enrolled, register, "web-3", true); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("enrolled certificate claiming another ID: %v", err)
+	}
+	if err := c.verifyAgentIdentity(enrolled, register, "db-1", true); err != nil {
+		t.Errorf("enrolled certificate: %v", err)
+	}
+	if got := certAgentID(enrolled); got != "db-1" {
+		t.Errorf("certAgentID() = %q", got)
+	}
+
+	if err := c.verifyAgentIdentity(context.Background(), register, "web-4", true); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("registration without a certificate: %v", err)
+	}
+
+	// Bindings survive a restart
+	reloaded, err := newAgentIdentities(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.check("web-1", "cn:web-2", true); err == nil {
+		t.Error("binding lost across restart")
+	}
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/diagnose"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultPings is how many round trips DiagnoseAgent measures when the
+	// request leaves it out, and maxPings the most it measures
+	defaultPings = 5
+	maxPings     = 100
+	// pingTimeout bounds each round trip and each handshake
+	pingTimeout = 5 * time.Second
+)
+
+// DiagnoseAgent reports on the connection between the core and an agent:
+// its heartbeats, round trips over the core's channel to it, the server
+// certificate a fresh handshake gets, the client certificate it last
+// presented and the agent's own check of its way back to the core. Every
+// problem found comes with a remediation.
+func (c *Core) DiagnoseAgent(ctx context.Context, req *agentv1.DiagnoseAgentRequest) (*agentv1.AgentConnectionReport, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	agent, ok := c.agents.get(req.AgentId)
+	var (
+		report     *agentv1.AgentConnectionReport
+		agentState AgentStatus
+		lastSeen   time.Time
+		clientCert *x509.Certificate
+	)
+	if ok {
+		agent.mu.RLock()
+		agentState, lastSeen, clientCert = agent.Status, agent.LastSeen, agent.Certificate
+		report = &agentv1.AgentConnectionReport{
+			AgentId:       agent.ID,
+			Address:       agent.Address,
+			Status:        string(agent.Status),
+			LastHeartbeat: timestamppb.New(agent.LastSeen),
+			DialOutOnly:   agent.DialOutOnly,
+		}
+		agent.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+	if err := c.authorizeAgent(ctx, agent, "read", "diagnostics"); err != nil {
+		return nil, err
+	}
+
+	checks := []diagnose.Check{registrationCheck(agentState, lastSeen)}
+	if clientCert != nil {
+		report.ClientCertificate = diagnose.PeerCertificateToProto(clientCert, "", nil)
+		checks = append(checks, diagnose.Certificate("agent client certificate", clientCert, time.Now()))
+	} else {
+		checks = append(checks, diagnose.Warn("agent client certificate", "none presented since the core started",
+			"Wait for the agent's next heartbeat, or check that it can reach the core"))
+	}
+
+	if report.DialOutOnly {
+		// The core never dials it, so its heartbeats are all there is
+		checks = append(checks, diagnose.OK("core→agent connection", "dial-out only; the agent takes work from its heartbeats"))
+		report.Checks = diagnose.ToProto("core", checks)
+		return report, nil
+	}
+
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		checks = append(checks, diagnose.Fail("core→agent connection", err.Error(),
+			"Make sure the agent's listen address is reachable from the core host"))
+		report.Checks = diagnose.ToProto("core", checks)
+		return report, nil
+	}
+	report.Address = conn.Address
+
+	// The round trips go through an open circuit, and close it when they
+	// reach the agent
+	before := c.breakers.state(req.AgentId)
+	ctx = bypassCircuit(ctx)
+
+	checks = append(checks, c.probeAgentTLS(ctx, conn, report))
+	checks = append(checks, pingAgent(ctx, conn, int(req.Pings), report))
+	checks = append(checks, circuitCheck(before, c.breakers.state(req.AgentId)))
+
+	state := conn.Client.GetState()
+	report.ChannelState = state.String()
+	checks = append(checks, channelCheck(state))
+
+	checks = append(checks, checkReverse(ctx, conn, lastSeen, report))
+	report.Checks = diagnose.ToProto("core", checks)
+	return report, nil
+}
+
+// registrationCheck reports whether an agent still sends heartbeats
+func registrationCheck(agentStatus AgentStatus, lastSeen time.Time) diagnose.Check {
+	age := time.Since(lastSeen).Round(time.Second)
+	if agentStatus == AgentStatusOnline {
+		return diagnose.OK("agent registration", fmt.Sprintf("online, last heartbeat %s ago", age))
+	}
+	return diagnose.Fail("agent registration",
+		fmt.Sprintf("%s, last heartbeat %s ago", agentStatus, age),
+		"Check that mandau-agent is running (journalctl -u mandau-agent) and can reach the core")
+}
+
+// probeAgentTLS makes a fresh TLS connection to the agent, as the core's
+// channel does, and reports the certificate it serves
+func (c *Core) probeAgentTLS(ctx context.Context, conn *AgentConnection, report *agentv1.AgentConnectionReport) diagnose.Check {
+	const name = "agent TLS"
+
+	tlsConfig, err := c.agentTLS.ClientConfig(c.peerTLS(c.config.FullConfig.AgentTLS), "mandau-agent")
+	if err != nil {
+		return diagnose.Fail(name, err.Error(), "Fix agent_tls in the core config")
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	probe := diagnose.ProbeTLS(probeCtx, c.config.AgentDialer, conn.Address, tlsConfig)
+	report.ServerCertificate = diagnose.PeerCertificateToProto(probe.Certificate, tlsConfig.ServerName, probe.Err)
+	if probe.Err != nil {
+		return diagnose.TLSFailure(name, "agent", conn.Address, tlsConfig.ServerName, probe.Err)
+	}
+
+	check := diagnose.Certificate(name, probe.Certificate, time.Now())
+	check.Detail = fmt.Sprintf("%s, handshake %s: %s", conn.Address, probe.Handshake.Round(time.Millisecond), check.Detail)
+	return check
+}
+
+// pingAgent measures round trips over the core's channel to the agent. Any
+// answer counts, including that the agent does not serve the call.
+func pingAgent(ctx context.Context, conn *AgentConnection, pings int, report *agentv1.AgentConnectionReport) diagnose.Check {
+	const name = "round trip"
+
+	switch {
+	case pings <= 0:
+		pings = defaultPings
+	case pings > maxPings:
+		pings = maxPings
+	}
+
+	client := agentv1.NewAgentServiceClient(conn.Client)
+	rt := &agentv1.RoundTrips{Sent: int32(pings)}
+	var total, low, high time.Duration
+	for range pings {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		start := time.Now()
+		_, err := client.GetCapabilities(pingCtx, &agentv1.CapabilitiesRequest{})
+		rtt := time.Since(start)
+		cancel()
+
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			rt.Failed++
+			rt.Error = err.Error()
+			continue
+		}
+		if total == 0 || rtt < low {
+			low = rtt
+		}
+		if rtt > high {
+			high = rtt
+		}
+		total += rtt
+	}
+	report.RoundTrips = rt
+
+	answered := rt.Sent - rt.Failed
+	if answered == 0 {
+		return diagnose.Fail(name, fmt.Sprintf("%d of %d failed: %s", rt.Failed, rt.Sent, rt.Error),
+			"Check that the agent listens on "+conn.Address+", that firewalls allow it and that both sides trust the same CA")
+	}
+	avg := total / time.Duration(answered)
+	rt.Min, rt.Avg, rt.Max = durationpb.New(low), durationpb.New(avg), durationpb.New(high)
+
+	detail := fmt.Sprintf("min %s, avg %s, max %s over %d",
+		low.Round(time.Microsecond), avg.Round(time.Microsecond), high.Round(time.Microsecond), answered)
+	if rt.Failed > 0 {
+		return diagnose.Warn(name, fmt.Sprintf("%s; %d of %d failed: %s", detail, rt.Failed, rt.Sent, rt.Error),
+			"Look for packet loss between the core and the agent host, or an overloaded agent")
+	}
+	return diagnose.OK(name, detail)
+}
+
+// circuitCheck reports the core's circuit breaker for the agent, before
+// and after the round trips
+func circuitCheck(before, after *agentv1.AgentCircuit) diagnose.Check {
+	const name = "circuit breaker"
+
+	switch {
+	case after.State != circuitClosed:
+		return diagnose.Fail(name,
+			fmt.Sprintf("%s since %s after %d failed calls: %s", after.State,
+				after.Since.AsTime().Local().Format(time.DateTime), after.Failures, after.LastError),
+			"The core fails calls to the agent fast until one reaches it; see the round trip check")
+	case before.State != circuitClosed:
+		return diagnose.OK(name, fmt.Sprintf("closed by the round trips, open since %s",
+			before.Since.AsTime().Local().Format(time.DateTime)))
+	case after.Trips > 0:
+		return diagnose.OK(name, fmt.Sprintf("closed, opened %d times since the core started", after.Trips))
+	}
+	return diagnose.OK(name, "closed")
+}
+
+// channelCheck reports the state of the core's gRPC channel to an agent
+func channelCheck(state connectivity.State) diagnose.Check {
+	const name = "core→agent channel"
+
+	switch state {
+	case connectivity.Ready, connectivity.Idle:
+		return diagnose.OK(name, state.String())
+	case connectivity.Connecting:
+		return diagnose.Warn(name, state.String(), "The channel is still connecting; run the ping again")
+	}
+	return diagnose.Fail(name, state.String(),
+		"The core cannot keep a connection to the agent; see the agent TLS and round trip checks")
+}
+
+// checkReverse asks the agent to dial the core afresh, which tells apart a
+// core that cannot reach the agent from an agent that cannot reach the core
+func checkReverse(ctx context.Context, conn *AgentConnection, lastSeen time.Time, report *agentv1.AgentConnectionReport) diagnose.Check {
+	const name = "agent→core connection"
+
+	checkCtx, cancel := context.WithTimeout(ctx, 2*pingTimeout)
+	defer cancel()
+	resp, err := agentv1.NewAgentServiceClient(conn.Client).CheckCoreConnection(checkCtx, &agentv1.CheckCoreConnectionRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return diagnose.Warn(name,
+			fmt.Sprintf("agent does not check it; last heartbeat %s ago", time.Since(lastSeen).Round(time.Second)),
+			"Upgrade mandau-agent to check its connection to the core")
+	case err != nil:
+		return diagnose.Fail(name, "ask the agent: "+err.Error(), "See the round trip check")
+	}
+	report.Reverse = resp
+
+	if resp.Error != "" {
+		return diagnose.Fail(name, fmt.Sprintf("%s: %s", resp.CoreAddress, resp.Error),
+			"Check server_connection.core_addr in the agent config, DNS and firewalls on the agent host, and that the agent trusts the core's CA")
+	}
+	return diagnose.OK(name, fmt.Sprintf("%s, handshake %s, agent channel %s",
+		resp.CoreAddress, resp.Handshake.AsDuration().Round(time.Millisecond), resp.ChannelState))
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// agentWatchResync is how often a watch compares agents without being
+	// told of a change, which catches circuits and groups changing
+	agentWatchResync = 10 * time.Second
+	// agentWatchGap is the least time between two comparisons of a watch,
+	// so that a burst of changes goes out together
+	agentWatchGap = 250 * time.Millisecond
+)
+
+// WatchAgents sends the agents matching the request and a SYNCED delta,
+// then the deltas of agents joining, changing in and leaving that set until
+// the caller hangs up. Callers are authenticated as ListAgents callers are
+// by authInterceptor, which streams do not pass through.
+func (c *Core) WatchAgents(req *agentv1.WatchAgentsRequest, stream grpc.ServerStreamingServer[agentv1.AgentDelta]) error {
+	ctx, err := c.authenticate(stream.Context(), agentv1.CoreService_WatchAgents_FullMethodName, req)
+	if err != nil {
+		return err
+	}
+	if req.Group != "" {
+		if _, ok := c.groups.get(req.Group); !ok {
+			return status.Errorf(codes.NotFound, "group not found: %s", req.Group)
+		}
+	}
+
+	resync := time.NewTicker(agentWatchResync)
+	defer resync.Stop()
+
+	sent := make(map[string]*agentv1.Agent)
+	synced := false
+	for {
+		// Taken before reading the agents, so changes made meanwhile are
+		// compared on the next round
+		changed := c.agents.changed()
+
+		for _, delta := range agentDeltas(sent, c.watchedAgents(req)) {
+			if err := stream.Send(delta); err != nil {
+				return err
+			}
+		}
+		if !synced {
+			if err := stream.Send(&agentv1.AgentDelta{Kind: agentv1.AgentDeltaKind_AGENT_DELTA_KIND_SYNCED, Time: timestamppb.Now()}); err != nil {
+				return err
+			}
+			synced = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-resync.C:
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(agentWatchGap):
+		}
+	}
+}
+
+// watchedAgents returns the agents a watch follows, by ID. A group deleted
+// during the watch has no members.
+func (c *Core) watchedAgents(req *agentv1.WatchAgentsRequest) []*agentv1.Agent {
+	var group *AgentGroup
+	if req.Group != "" {
+		var ok bool
+		if group, ok = c.groups.get(req.Group); !ok {
+			return nil
+		}
+	}
+
+	var agents []*agentv1.Agent
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		if matchLabels(agent.Labels, req.Labels) && (group == nil || group.contains(agent)) {
+			agents = append(agents, c.toProtoAgent(agent))
+		}
+		agent.mu.RUnlock()
+	}
+	return agents
+}
+
+// agentDeltas returns what changed from the agents in sent to agents, and
+// updates sent to match
+func agentDeltas(sent map[string]*agentv1.Agent, agents []*agentv1.Agent) []*agentv1.AgentDelta {
+	now := timestamppb.Now()
+	var deltas []*agentv1.AgentDelta
+
+	seen := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		seen[agent.Id] = true
+		previous, ok := sent[agent.Id]
+		switch {
+		case !ok:
+			deltas = append(deltas, &agentv1.AgentDelta{Kind: agentv1.AgentDeltaKind_AGENT_DELTA_KIND_ADDED, Agent: agent, Time: now})
+		case !sameWatchedAgent(previous, agent):
+			deltas = append(deltas, &agentv1.AgentDelta{Kind: agentv1.AgentDeltaKind_AGENT_DELTA_KIND_UPDATED, Agent: agent, Time: now})
+		default:
+			continue
+		}
+		sent[agent.Id] = agent
+	}
+
+	var removed []string
+	for id := range sent {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		deltas = append(deltas, &agentv1.AgentDelta{Kind: agentv1.AgentDeltaKind_AGENT_DELTA_KIND_REMOVED, Agent: sent[id], Time: now})
+		delete(sent, id)
+	}
+	return deltas
+}
+
+// sameWatchedAgent reports whether two states of an agent differ only in
+// what every heartbeat changes
+func sameWatchedAgent(a, b *agentv1.Agent) bool {
+	a, b = proto.Clone(a).(*agentv1.Agent), proto.Clone(b).(*agentv1.Agent)
+	a.LastSeen, b.LastSeen = nil, nil
+	a.ClockSkew, b.ClockSkew = nil, nil
+	return proto.Equal(a, b)
+}
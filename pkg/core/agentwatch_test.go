@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// watchCore returns a core with agents web-1 and db-1 whose auth plugin
+// knows alice, and a client calling it with user's certificate
+func watchCore(t *testing.T, user string) (*Core, agentv1.CoreServiceClient) {
+	t.Helper()
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{roles: map[string][]string{"alice": {"viewer"}}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, _ := newGroupRegistry(nil, "")
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Labels: map[string]string{"role": "web"}, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "db-1", Labels: map[string]string{"role": "db"}, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		groups:     groups,
+		breakers:   newCircuitBreakers(config.CircuitBreakerConfig{}),
+		plugins:    plugins,
+		breakGlass: newBreakGlassStore(config.BreakGlassConfig{}),
+	}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: user}}
+	withCert := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := peer.NewContext(ss.Context(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+		return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+	}
+	server := grpc.NewServer(grpc.StreamInterceptor(withCert))
+	agentv1.RegisterCoreServiceServer(server, c)
+	return c, agentv1.NewCoreServiceClient(serve(t, server))
+}
+
+func TestWatchAgentsNeedsAuthentication(t *testing.T) {
+	_, client := watchCore(t, "mallory")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.WatchAgents(ctx, &agentv1.WatchAgentsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta, err := stream.Recv(); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("watch by an unknown user = %v, %v; want Unauthenticated", delta, err)
+	}
+}
+
+func TestWatchAgents(t *testing.T) {
+	c, client := watchCore(t, "alice")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.WatchAgents(ctx, &agentv1.WatchAgentsRequest{Labels: map[string]string{"role": "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(kind agentv1.AgentDeltaKind, agentID, status string) {
+		t.Helper()
+		delta, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delta.Kind != kind || delta.Agent.GetId() != agentID || delta.Agent.GetStatus() != status {
+			t.Fatalf("delta = %s %s %s, want %s %s %s", delta.Kind, delta.Agent.GetId(), delta.Agent.GetStatus(), kind, agentID, status)
+		}
+	}
+
+	expect(agentv1.AgentDeltaKind_AGENT_DELTA_KIND_ADDED, "web-1", "online")
+	expect(agentv1.AgentDeltaKind_AGENT_DELTA_KIND_SYNCED, "", "")
+
+	// Heartbeats alone make no delta; going offline does
+	agent, _ := c.agents.get("web-1")
+	c.applyHeartbeat(ctx, agent, &heartbeat{agentID: "web-1", req: &agentv1.HeartbeatRequest{AgentId: "web-1"}, received: time.Now()})
+	c.checkAgent(ctx, agent, time.Now(), -time.Second)
+	expect(agentv1.AgentDeltaKind_AGENT_DELTA_KIND_UPDATED, "web-1", "offline")
+
+	c.agents.put(&AgentConnection{ID: "web-2", Labels: map[string]string{"role": "web"}, Status: AgentStatusOnline})
+	expect(agentv1.AgentDeltaKind_AGENT_DELTA_KIND_ADDED, "web-2", "online")
+
+	// Relabelled out of the watch
+	agent, _ = c.agents.lock("web-1")
+	agent.Labels = map[string]string{"role": "db"}
+	agent.mu.Unlock()
+	expect(agentv1.AgentDeltaKind_AGENT_DELTA_KIND_REMOVED, "web-1", "offline")
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// anomalyDetector is an in-process audit plugin that evaluates anomaly rules
+// over every audit entry and raises notifications through notify plugins
+type anomalyDetector struct {
+	plugins *plugin.Registry
+	mu      sync.Mutex
+	rules   []*anomalyRule
+}
+
+type anomalyRule struct {
+	config.AnomalyRule
+	window time.Duration
+	// Business hours as minutes since midnight; hoursSet is false when the
+	// rule applies at any time
+	hoursSet   bool
+	openMinute int
+	shutMinute int
+	days       map[time.Weekday]bool
+	hits       map[string][]time.Time // Per identity
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func newAnomalyDetector(plugins *plugin.Registry, cfg config.AnomalyConfig) (*anomalyDetector, error) {
+	d := &anomalyDetector{plugins: plugins}
+
+	for _, rc := range cfg.Rules {
+		rule := &anomalyRule{
+			AnomalyRule: rc,
+			window:      time.Minute,
+			hits:        make(map[string][]time.Time),
+		}
+		if rule.Name == "" {
+			return nil, fmt.Errorf("anomaly rule without name")
+		}
+		if rule.Threshold <= 0 {
+			rule.Threshold = 1
+		}
+		if rule.Severity == "" {
+			rule.Severity = "warning"
+		}
+		if rc.Window != "" {
+			w, err := time.ParseDuration(rc.Window)
+			if err != nil {
+				return nil, fmt.Errorf("anomaly rule %s: parse window: %w", rc.Name, err)
+			}
+			rule.window = w
+		}
+
+		if rc.BusinessHours != "" {
+			open, shut, ok := strings.Cut(rc.BusinessHours, "-")
+			if !ok {
+				return nil, fmt.Errorf("anomaly rule %s: business_hours must look like 09:00-18:00", rc.Name)
+			}
+			var err error
+			if rule.openMinute, err = parseClock(open); err != nil {
+				return nil, fmt.Errorf("anomaly rule %s: %w", rc.Name, err)
+			}
+			if rule.shutMinute, err = parseClock(shut); err != nil {
+				return nil, fmt.Errorf("anomaly rule %s: %w", rc.Name, err)
+			}
+			rule.hoursSet = true
+
+			days := rc.BusinessDays
+			if len(days) == 0 {
+				days = []string{"mon", "tue", "wed", "thu", "fri"}
+			}
+			rule.days = make(map[time.Weekday]bool, len(days))
+			for _, day := range days {
+				wd, ok := weekdays[strings.ToLower(day)]
+				if !ok {
+					return nil, fmt.Errorf("anomaly rule %s: unknown day %q", rc.Name, day)
+				}
+				rule.days[wd] = true
+			}
+		}
+
+		d.rules = append(d.rules, rule)
+	}
+
+	return d, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("parse time %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (d *anomalyDetector) Name() string    { return "anomaly-detector" }
+func (d *anomalyDetector) Version() string { return "1.0.0" }
+
+func (d *anomalyDetector) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityAudit, plugin.CapabilityMonitor}
+}
+
+func (d *anomalyDetector) Init(ctx context.Context, config map[string]interface{}) error {
+	return nil
+}
+
+func (d *anomalyDetector) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Query is not supported; the detector keeps no history beyond rule windows
+func (d *anomalyDetector) Query(ctx context.Context, filter *plugin.AuditFilter) ([]plugin.AuditEntry, error) {
+	return nil, fmt.Errorf("anomaly detector does not store audit entries")
+}
+
+// Log evaluates every rule against the entry
+func (d *anomalyDetector) Log(ctx context.Context, entry *plugin.AuditEntry) {
+	identity := "unknown"
+	if entry.Identity != nil && entry.Identity.UserID != "" {
+		identity = entry.Identity.UserID
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, rule := range d.rules {
+		if !rule.matches(entry) {
+			continue
+		}
+
+		cutoff := entry.Timestamp.Add(-rule.window)
+		hits := rule.hits[identity][:0]
+		for _, t := range rule.hits[identity] {
+			if t.After(cutoff) {
+				hits = append(hits, t)
+			}
+		}
+		hits = append(hits, entry.Timestamp)
+
+		if len(hits) < rule.Threshold {
+			rule.hits[identity] = hits
+			continue
+		}
+
+		// Start a fresh window so one burst raises one alert
+		delete(rule.hits, identity)
+
+		n := &plugin.Notification{
+			Title:    "Anomaly: " + rule.Name,
+			Severity: rule.Severity,
+			Message: fmt.Sprintf("%s triggered %d x %s (result %s) within %s",
+				identity, len(hits), entry.Action, entry.Result, rule.window),
+			Timestamp: entry.Timestamp,
+			Labels: map[string]string{
+				"rule":       rule.Name,
+				"identity":   identity,
+				"action":     entry.Action,
+				"resource":   entry.Resource,
+				"agent_id":   entry.AgentID,
+				"request_id": entry.RequestID,
+			},
+		}
+		// Deliver asynchronously: Log runs inside the audit path, which must
+		// never block on slow notification channels
+		go d.plugins.NotifyAll(context.Background(), n)
+	}
+}
+
+func (r *anomalyRule) matches(entry *plugin.AuditEntry) bool {
+	if r.Action != "" && !matchPattern(r.Action, entry.Action) {
+		return false
+	}
+	if r.Result != "" && r.Result != entry.Result {
+		return false
+	}
+	if r.hoursSet && r.withinBusinessHours(entry.Timestamp) {
+		return false
+	}
+	return true
+}
+
+// withinBusinessHours reports whether t falls in a business-hours window.
+// Windows that wrap midnight (22:00-06:00) belong to the day they open on.
+func (r *anomalyRule) withinBusinessHours(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if r.openMinute <= r.shutMinute {
+		return r.days[t.Weekday()] && minute >= r.openMinute && minute < r.shutMinute
+	}
+
+	if minute >= r.openMinute {
+		return r.days[t.Weekday()]
+	}
+	if minute < r.shutMinute {
+		return r.days[(t.Weekday()+6)%7]
+	}
+	return false
+}
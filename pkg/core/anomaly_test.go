@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+func TestAnomalyBusinessHours(t *testing.T) {
+	// 2026-10-12 is a Monday
+	tests := []struct {
+		name  string
+		hours string
+		days  []string
+		when  time.Time
+		want  bool
+	}{
+		{"weekday inside", "09:00-18:00", nil, date(2026, 10, 12, 10, 0), true},
+		{"weekday before open", "09:00-18:00", nil, date(2026, 10, 12, 8, 59), false},
+		{"weekday at close", "09:00-18:00", nil, date(2026, 10, 12, 18, 0), false},
+		{"weekend", "09:00-18:00", nil, date(2026, 10, 17, 10, 0), false},
+		{"custom days", "09:00-18:00", []string{"sat"}, date(2026, 10, 17, 10, 0), true},
+		{"overnight before midnight", "22:00-06:00", nil, date(2026, 10, 12, 23, 0), true},
+		{"overnight after midnight", "22:00-06:00", nil, date(2026, 10, 13, 5, 59), true},
+		{"overnight at close", "22:00-06:00", nil, date(2026, 10, 13, 6, 0), false},
+		{"overnight daytime", "22:00-06:00", nil, date(2026, 10, 12, 12, 0), false},
+		{"overnight from friday", "22:00-06:00", nil, date(2026, 10, 17, 3, 0), true},
+		{"overnight into monday", "22:00-06:00", nil, date(2026, 10, 12, 3, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newAnomalyDetector(plugin.NewRegistry(), config.AnomalyConfig{Rules: []config.AnomalyRule{{
+				Name: "r", BusinessHours: tt.hours, BusinessDays: tt.days,
+			}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.rules[0].withinBusinessHours(tt.when); got != tt.want {
+				t.Fatalf("withinBusinessHours(%s) = %v, want %v", tt.when, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnomalyWindow(t *testing.T) {
+	start := date(2026, 10, 12, 12, 0)
+
+	tests := []struct {
+		name    string
+		offsets []time.Duration // Entry times relative to start
+		alerted bool
+	}{
+		{"threshold reached inside window", []time.Duration{0, 20 * time.Second, 40 * time.Second}, true},
+		{"spread beyond window", []time.Duration{0, 40 * time.Second, 80 * time.Second}, false},
+		{"below threshold", []time.Duration{0, 10 * time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newAnomalyDetector(plugin.NewRegistry(), config.AnomalyConfig{Rules: []config.AnomalyRule{{
+				Name: "denied", Result: "denied", Threshold: 3, Window: "1m",
+			}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, offset := range tt.offsets {
+				d.Log(context.Background(), &plugin.AuditEntry{
+					Timestamp: start.Add(offset),
+					Identity:  &plugin.Identity{UserID: "alice"},
+					Action:    "stack.apply",
+					Result:    "denied",
+				})
+			}
+
+			// An alert starts a fresh window, clearing the identity's hits
+			_, pending := d.rules[0].hits["alice"]
+			if alerted := !pending; alerted != tt.alerted {
+				t.Fatalf("alerted = %v, want %v", alerted, tt.alerted)
+			}
+		})
+	}
+}
+
+func TestAnomalyRuleConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.AnomalyRule
+		ok   bool
+	}{
+		{"defaults", config.AnomalyRule{Name: "r"}, true},
+		{"missing name", config.AnomalyRule{}, false},
+		{"bad window", config.AnomalyRule{Name: "r", Window: "soon"}, false},
+		{"bad hours", config.AnomalyRule{Name: "r", BusinessHours: "9-5"}, false},
+		{"bad day", config.AnomalyRule{Name: "r", BusinessHours: "09:00-17:00", BusinessDays: []string{"funday"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newAnomalyDetector(plugin.NewRegistry(), config.AnomalyConfig{Rules: []config.AnomalyRule{tt.rule}})
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
@@ -0,0 +1,473 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultApprovalTTL is how long a pending or approved request stays usable
+const defaultApprovalTTL = 24 * time.Hour
+
+// ApprovalStore holds approval rules and the requests parked against them
+type ApprovalStore struct {
+	mu        sync.Mutex
+	rules     []config.ApprovalRule
+	ttl       time.Duration
+	approvals map[string]*Approval
+}
+
+// Approval is a request for an action awaiting or holding a second sign-off.
+// It is bound to the digest of the parked request, so an approval cannot be
+// reused for different content.
+type Approval struct {
+	ID          string
+	RequestedBy string
+	AgentID     string
+	Group       string   // Set for group fan-outs
+	Agents      []string // Group members covered, each usable once
+	UsedBy      []string // Members that consumed the approval
+	// UsedKeys holds the idempotency key each member used it with, so a
+	// retry of that request is let through again
+	UsedKeys   map[string]string
+	Action     string
+	Resource   string
+	Digest     string
+	State      agentv1.ApprovalState
+	ReviewedBy string
+	Comment    string
+	CreatedAt  time.Time
+	ReviewedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// approvalRequest describes a call that may need approval
+type approvalRequest struct {
+	RequestedBy string
+	AgentID     string
+	Group       string
+	Members     []string // Current group members when Group is set
+	Action      string
+	Resource    string
+	Digest      string
+	// IdempotencyKey marks retries of one request; empty when it has none
+	IdempotencyKey string
+}
+
+func newApprovalStore(cfg config.ApprovalConfig) *ApprovalStore {
+	ttl := defaultApprovalTTL
+	if cfg.TTL != "" {
+		if d, err := time.ParseDuration(cfg.TTL); err == nil {
+			ttl = d
+		} else {
+			log.Printf("Invalid approval ttl %q, using %s: %v", cfg.TTL, ttl, err)
+		}
+	}
+
+	return &ApprovalStore{
+		rules:     cfg.Rules,
+		ttl:       ttl,
+		approvals: make(map[string]*Approval),
+	}
+}
+
+// requiresApproval reports whether any rule covers action on resource for an
+// agent that belongs to groups
+func (s *ApprovalStore) requiresApproval(action, resource string, groups []string) bool {
+	for _, rule := range s.rules {
+		if rule.Action != "*" && rule.Action != action {
+			continue
+		}
+		if !matchPattern(rule.Resource, resource) {
+			continue
+		}
+		if rule.Group != "" && !containsString(groups, rule.Group) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// expire moves stale pending and approved requests to the expired state.
+// Callers must hold the store lock.
+func (s *ApprovalStore) expire(now time.Time) {
+	for _, a := range s.approvals {
+		if (a.State == agentv1.ApprovalState_APPROVAL_STATE_PENDING ||
+			a.State == agentv1.ApprovalState_APPROVAL_STATE_APPROVED) && now.After(a.ExpiresAt) {
+			a.State = agentv1.ApprovalState_APPROVAL_STATE_EXPIRED
+		}
+	}
+}
+
+// park records req as a pending approval
+func (s *ApprovalStore) park(req approvalRequest, now time.Time) *Approval {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := &Approval{
+		ID:          uuid.New().String(),
+		RequestedBy: req.RequestedBy,
+		AgentID:     req.AgentID,
+		Group:       req.Group,
+		Action:      req.Action,
+		Resource:    req.Resource,
+		Digest:      req.Digest,
+		State:       agentv1.ApprovalState_APPROVAL_STATE_PENDING,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	if req.Group != "" {
+		a.AgentID = ""
+		a.Agents = req.Members
+	}
+	s.approvals[a.ID] = a
+	return a
+}
+
+// use consumes an approved approval for req. Single-agent approvals are used
+// once; group approvals once per member listed when they were parked.
+func (s *ApprovalStore) use(id string, req approvalRequest, now time.Time) (*Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	a, ok := s.approvals[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "approval not found: %s", id)
+	}
+	if a.RequestedBy != req.RequestedBy || a.Action != req.Action || a.Resource != req.Resource ||
+		a.Group != req.Group {
+		return nil, status.Errorf(codes.PermissionDenied, "approval %s does not cover this request", id)
+	}
+	if a.Group == "" && a.AgentID != req.AgentID {
+		return nil, status.Errorf(codes.PermissionDenied, "approval %s does not cover agent %s", id, req.AgentID)
+	}
+	if a.Group != "" && !containsString(a.Agents, req.AgentID) {
+		return nil, status.Errorf(codes.PermissionDenied, "approval %s does not cover agent %s", id, req.AgentID)
+	}
+	if a.Digest != req.Digest {
+		return nil, status.Errorf(codes.PermissionDenied, "approval %s was granted for different request content", id)
+	}
+	if req.IdempotencyKey != "" && a.UsedKeys[req.AgentID] == req.IdempotencyKey {
+		// A retry of the request that used it; the agent hands back the
+		// operation the first attempt started
+		return a, nil
+	}
+	if a.State != agentv1.ApprovalState_APPROVAL_STATE_APPROVED {
+		return nil, status.Errorf(codes.FailedPrecondition, "approval %s is %s", id, approvalStateName(a.State))
+	}
+	if containsString(a.UsedBy, req.AgentID) {
+		return nil, status.Errorf(codes.FailedPrecondition, "approval %s was already used on agent %s", id, req.AgentID)
+	}
+
+	a.UsedBy = append(a.UsedBy, req.AgentID)
+	if req.IdempotencyKey != "" {
+		if a.UsedKeys == nil {
+			a.UsedKeys = make(map[string]string)
+		}
+		a.UsedKeys[req.AgentID] = req.IdempotencyKey
+	}
+	if a.Group == "" || len(a.UsedBy) >= len(a.Agents) {
+		a.State = agentv1.ApprovalState_APPROVAL_STATE_USED
+	}
+	return a, nil
+}
+
+// review approves or rejects a pending approval on behalf of reviewer.
+// authorize is called with the approval's resource before any change.
+func (s *ApprovalStore) review(id, reviewer string, approve bool, comment string, now time.Time, authorize func(resource string) error) (*Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	a, ok := s.approvals[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "approval not found: %s", id)
+	}
+	if a.State != agentv1.ApprovalState_APPROVAL_STATE_PENDING {
+		return nil, status.Errorf(codes.FailedPrecondition, "approval %s is %s", a.ID, approvalStateName(a.State))
+	}
+	if a.RequestedBy == reviewer {
+		return nil, status.Error(codes.PermissionDenied, "requester cannot review their own approval")
+	}
+	if err := authorize(a.Resource); err != nil {
+		return nil, err
+	}
+
+	a.ReviewedBy = reviewer
+	a.ReviewedAt = now
+	a.Comment = comment
+	if approve {
+		a.State = agentv1.ApprovalState_APPROVAL_STATE_APPROVED
+	} else {
+		a.State = agentv1.ApprovalState_APPROVAL_STATE_REJECTED
+	}
+	return a, nil
+}
+
+// requireApproval parks the call as a pending approval when policy demands a
+// second sign-off, or consumes approvalID if the caller already holds one.
+// digest binds the approval to the request content; group is set when the
+// caller fans the same request out to every member of a group. A retry that
+// carries the idempotency key the approval was used with is let through.
+func (c *Core) requireApproval(ctx context.Context, conn *AgentConnection, action, resource, digest, group, approvalID, idempotencyKey string) error {
+	groups := c.agentGroups(conn)
+	if !c.approvals.requiresApproval(action, resource, groups) {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+	// The impersonator could otherwise approve the request themselves
+	if err := notImpersonated(identity, "request approvals"); err != nil {
+		return err
+	}
+
+	req := approvalRequest{
+		RequestedBy: identity.UserID,
+		AgentID:     conn.ID,
+		Group:       group,
+		Action:      action,
+		Resource:    resource,
+		Digest:      digest,
+
+		IdempotencyKey: idempotencyKey,
+	}
+	if group != "" && !containsString(groups, group) {
+		return status.Errorf(codes.InvalidArgument, "agent %s is not in group %s", conn.ID, group)
+	}
+
+	if approvalID != "" {
+		a, err := c.approvals.use(approvalID, req, time.Now())
+		if err != nil {
+			return err
+		}
+		c.auditApproval(ctx, identity, a, "used")
+		return nil
+	}
+
+	if group != "" {
+		req.Members = c.groupMembers(group)
+	}
+	a := c.approvals.park(req, time.Now())
+	c.auditApproval(ctx, identity, a, "requested")
+
+	target := "agent " + conn.ID
+	if group != "" {
+		target = "group " + group
+	}
+	return transport.Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+		Code:     agentv1.ErrorCode_ERROR_CODE_APPROVAL_REQUIRED,
+		Resource: resource,
+		AgentId:  conn.ID,
+		Hint:     "once a reviewer approves " + a.ID + ", rerun with --approval-id " + a.ID,
+	}, "%s %s on %s requires approval; pending approval %s", action, resource, target, a.ID)
+}
+
+// groupMembers returns the sorted IDs of the agents currently in group
+func (c *Core) groupMembers(name string) []string {
+	group, ok := c.groups.get(name)
+	if !ok {
+		return nil
+	}
+
+	var members []string
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		if group.contains(agent) {
+			members = append(members, agent.ID)
+		}
+		agent.mu.RUnlock()
+	}
+	return members
+}
+
+// applyDigest hashes the parts of an apply request that change what gets
+// deployed
+func applyDigest(req *agentv1.ApplyStackRequest) string {
+	h := sha256.New()
+	writeField(h, "stack", req.StackName)
+	writeField(h, "namespace", normalizeNamespace(req.Namespace))
+	writeField(h, "compose", req.ComposeContent)
+	writeMap(h, "env", req.EnvVars)
+	writeMap(h, "labels", req.Labels)
+	writeField(h, "team", req.GetOwner().GetTeam())
+	writeField(h, "owner", req.GetOwner().GetOwner())
+	writeField(h, "ticket", req.GetOwner().GetTicket())
+	writeField(h, "services", strings.Join(req.Services, ","))
+	writeField(h, "force_recreate", fmt.Sprint(req.ForceRecreate))
+	writeField(h, "pull_images", fmt.Sprint(req.PullImages))
+	if hooks := req.Hooks; hooks != nil {
+		writeField(h, "pre_apply", hooks.PreApply)
+		writeField(h, "post_apply", hooks.PostApply)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// removeDigest hashes a remove request
+func removeDigest(req *agentv1.RemoveStackRequest) string {
+	h := sha256.New()
+	writeField(h, "stack", req.StackId)
+	writeField(h, "namespace", normalizeNamespace(req.Namespace))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes a length-prefixed field so values cannot run together
+func writeField(w io.Writer, name, value string) {
+	fmt.Fprintf(w, "%s:%d:%s\n", name, len(value), value)
+}
+
+func writeMap(w io.Writer, name string, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeField(w, name+"."+k, m[k])
+	}
+}
+
+// ListApprovals returns approvals, newest first
+func (c *Core) ListApprovals(ctx context.Context, req *agentv1.ListApprovalsRequest) (*agentv1.ListApprovalsResponse, error) {
+	c.approvals.mu.Lock()
+	defer c.approvals.mu.Unlock()
+
+	c.approvals.expire(time.Now())
+
+	approvals := make([]*agentv1.Approval, 0, len(c.approvals.approvals))
+	for _, a := range c.approvals.approvals {
+		if req.State != agentv1.ApprovalState_APPROVAL_STATE_UNKNOWN && a.State != req.State {
+			continue
+		}
+		approvals = append(approvals, toProtoApproval(a))
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].CreatedAt.AsTime().After(approvals[j].CreatedAt.AsTime())
+	})
+
+	return &agentv1.ListApprovalsResponse{Approvals: approvals}, nil
+}
+
+// ReviewApproval approves or rejects a pending approval. The reviewer must be
+// a different identity from the requester and be allowed to "approve" the
+// resource.
+func (c *Core) ReviewApproval(ctx context.Context, req *agentv1.ReviewApprovalRequest) (*agentv1.Approval, error) {
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+	if err := notImpersonated(identity, "review approvals"); err != nil {
+		return nil, err
+	}
+
+	authorize := func(resource string) error {
+		auth := c.plugins.Auth()
+		if auth == nil {
+			return nil
+		}
+		if err := auth.Authorize(ctx, identity, &plugin.Action{
+			Action:   "approve",
+			Resource: resource,
+		}); err != nil {
+			return status.Errorf(codes.PermissionDenied, "%s may not approve %s: %v", identity.UserID, resource, err)
+		}
+		return nil
+	}
+
+	a, err := c.approvals.review(req.Id, identity.UserID, req.Approve, req.Comment, time.Now(), authorize)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Approve {
+		c.auditApproval(ctx, identity, a, "approved")
+	} else {
+		c.auditApproval(ctx, identity, a, "rejected")
+	}
+
+	return toProtoApproval(a), nil
+}
+
+func (c *Core) auditApproval(ctx context.Context, identity *plugin.Identity, a *Approval, result string) {
+	target := "agent " + a.AgentID
+	if a.Group != "" {
+		target = "group " + a.Group
+	}
+	log.Printf("Approval %s %s by %s: %s %s on %s", a.ID, result, identity.UserID, a.Action, a.Resource, target)
+
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		AgentID:   a.AgentID,
+		Identity:  identity,
+		Action:    "approval." + a.Action,
+		Resource:  a.Resource,
+		Result:    result,
+		Metadata: map[string]string{
+			"approval_id":  a.ID,
+			"requested_by": a.RequestedBy,
+			"comment":      a.Comment,
+			"digest":       a.Digest,
+			"group":        a.Group,
+		},
+	})
+}
+
+func toProtoApproval(a *Approval) *agentv1.Approval {
+	approval := &agentv1.Approval{
+		Id:          a.ID,
+		RequestedBy: a.RequestedBy,
+		AgentId:     a.AgentID,
+		Action:      a.Action,
+		Resource:    a.Resource,
+		State:       a.State,
+		ReviewedBy:  a.ReviewedBy,
+		Comment:     a.Comment,
+		CreatedAt:   timestamppb.New(a.CreatedAt),
+		ExpiresAt:   timestamppb.New(a.ExpiresAt),
+		Digest:      a.Digest,
+		Group:       a.Group,
+		Agents:      a.Agents,
+	}
+	if !a.ReviewedAt.IsZero() {
+		approval.ReviewedAt = timestamppb.New(a.ReviewedAt)
+	}
+	return approval
+}
+
+func approvalStateName(state agentv1.ApprovalState) string {
+	return strings.ToLower(strings.TrimPrefix(state.String(), "APPROVAL_STATE_"))
+}
+
+// matchPattern matches resource against a pattern with an optional trailing
+// wildcard, the same way RBAC permissions do
+func matchPattern(pattern, resource string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(resource, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == resource
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func allowAll(string) error { return nil }
+
+func TestApprovalReview(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		reviewer string
+		at       time.Time
+		want     codes.Code
+	}{
+		{"second identity approves", "bob", now, codes.OK},
+		{"requester cannot self-approve", "alice", now, codes.PermissionDenied},
+		{"expired request cannot be approved", "bob", now.Add(2 * time.Hour), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newApprovalStore(config.ApprovalConfig{TTL: "1h"})
+			a := s.park(approvalRequest{
+				RequestedBy: "alice", AgentID: "agent-1",
+				Action: "delete", Resource: "stack:web", Digest: "d1",
+			}, now)
+
+			_, err := s.review(a.ID, tt.reviewer, true, "", tt.at, allowAll)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("review: got %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestApprovalUse(t *testing.T) {
+	now := time.Now()
+	base := approvalRequest{
+		RequestedBy: "alice", AgentID: "agent-1",
+		Action: "write", Resource: "stack:web", Digest: "d1",
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(r *approvalRequest)
+		at     time.Time
+		want   codes.Code
+	}{
+		{"matching request", func(r *approvalRequest) {}, now, codes.OK},
+		{"different content", func(r *approvalRequest) { r.Digest = "d2" }, now, codes.PermissionDenied},
+		{"different agent", func(r *approvalRequest) { r.AgentID = "agent-2" }, now, codes.PermissionDenied},
+		{"different requester", func(r *approvalRequest) { r.RequestedBy = "mallory" }, now, codes.PermissionDenied},
+		{"different resource", func(r *approvalRequest) { r.Resource = "stack:db" }, now, codes.PermissionDenied},
+		{"expired", func(r *approvalRequest) {}, now.Add(2 * time.Hour), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newApprovalStore(config.ApprovalConfig{TTL: "1h"})
+			a := s.park(base, now)
+			if _, err := s.review(a.ID, "bob", true, "", now, allowAll); err != nil {
+				t.Fatalf("review: %v", err)
+			}
+
+			req := base
+			tt.mutate(&req)
+			_, err := s.use(a.ID, req, tt.at)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("use: got %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestApprovalSingleUse(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		req   approvalRequest
+		uses  []string // Agents using the approval in order
+		fails int      // Index of the first use expected to fail, -1 for none
+		state agentv1.ApprovalState
+	}{
+		{
+			name:  "agent approval is used once",
+			req:   approvalRequest{RequestedBy: "alice", AgentID: "a1", Action: "write", Resource: "stack:web", Digest: "d"},
+			uses:  []string{"a1", "a1"},
+			fails: 1,
+			state: agentv1.ApprovalState_APPROVAL_STATE_USED,
+		},
+		{
+			name: "group approval covers each member once",
+			req: approvalRequest{RequestedBy: "alice", AgentID: "a1", Group: "prod", Members: []string{"a1", "a2"},
+				Action: "write", Resource: "stack:web", Digest: "d"},
+			uses:  []string{"a1", "a2"},
+			fails: -1,
+			state: agentv1.ApprovalState_APPROVAL_STATE_USED,
+		},
+		{
+			name: "group approval cannot be reused by a member",
+			req: approvalRequest{RequestedBy: "alice", AgentID: "a1", Group: "prod", Members: []string{"a1", "a2"},
+				Action: "write", Resource: "stack:web", Digest: "d"},
+			uses:  []string{"a1", "a1"},
+			fails: 1,
+			state: agentv1.ApprovalState_APPROVAL_STATE_APPROVED,
+		},
+		{
+			name: "group approval does not cover later members",
+			req: approvalRequest{RequestedBy: "alice", AgentID: "a1", Group: "prod", Members: []string{"a1"},
+				Action: "write", Resource: "stack:web", Digest: "d"},
+			uses:  []string{"a3"},
+			fails: 0,
+			state: agentv1.ApprovalState_APPROVAL_STATE_APPROVED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newApprovalStore(config.ApprovalConfig{})
+			a := s.park(tt.req, now)
+			if _, err := s.review(a.ID, "bob", true, "", now, allowAll); err != nil {
+				t.Fatalf("review: %v", err)
+			}
+
+			for i, agent := range tt.uses {
+				req := tt.req
+				req.AgentID = agent
+				_, err := s.use(a.ID, req, now)
+				if i == tt.fails {
+					if err == nil {
+						t.Fatalf("use %d on %s: expected an error", i, agent)
+					}
+					break
+				}
+				if err != nil {
+					t.Fatalf("use %d on %s: %v", i, agent, err)
+				}
+			}
+
+			if a.State != tt.state {
+				t.Fatalf("state: got %v, want %v", a.State, tt.state)
+			}
+		})
+	}
+}
+
+func TestRejectedApprovalCannotBeUsed(t *testing.T) {
+	now := time.Now()
+	req := approvalRequest{RequestedBy: "alice", AgentID: "a1", Action: "delete", Resource: "stack:web", Digest: "d"}
+
+	s := newApprovalStore(config.ApprovalConfig{})
+	a := s.park(req, now)
+	if _, err := s.review(a.ID, "bob", false, "no", now, allowAll); err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	if _, err := s.use(a.ID, req, now); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("use: got %v, want FailedPrecondition", err)
+	}
+}
+
+func TestApprovalRetriedWithIdempotencyKey(t *testing.T) {
+	now := time.Now()
+	req := approvalRequest{RequestedBy: "alice", AgentID: "a1", Action: "write", Resource: "stack:web", Digest: "d",
+		IdempotencyKey: "k1"}
+
+	s := newApprovalStore(config.ApprovalConfig{})
+	a := s.park(req, now)
+	if _, err := s.review(a.ID, "bob", true, "", now, allowAll); err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := s.use(a.ID, req, now); err != nil {
+			t.Fatalf("use %d: %v", i, err)
+		}
+	}
+
+	other := req
+	other.IdempotencyKey = "k2"
+	if _, err := s.use(a.ID, other, now); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("use with another key: got %v, want FailedPrecondition", err)
+	}
+	other.IdempotencyKey = ""
+	if _, err := s.use(a.ID, other, now); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("use without a key: got %v, want FailedPrecondition", err)
+	}
+}
+
+func TestApplyDigest(t *testing.T) {
+	base := &agentv1.ApplyStackRequest{
+		StackName:      "web",
+		ComposeContent: "services: {}",
+		Labels:         map[string]string{"env": "prod"},
+	}
+	same := &agentv1.ApplyStackRequest{
+		StackName:      "web",
+		ComposeContent: "services: {}",
+		Labels:         map[string]string{"env": "prod"},
+		Namespace:      "default",
+		ApprovalId:     "ignored",
+		AgentId:        "ignored",
+	}
+	changed := &agentv1.ApplyStackRequest{
+		StackName:      "web",
+		ComposeContent: "services: {app: {}}",
+		Labels:         map[string]string{"env": "prod"},
+	}
+
+	if applyDigest(base) != applyDigest(same) {
+		t.Error("digest changed for equivalent requests")
+	}
+	if applyDigest(base) == applyDigest(changed) {
+		t.Error("digest did not change with compose content")
+	}
+
+	hooked := proto.Clone(base).(*agentv1.ApplyStackRequest)
+	hooked.Hooks = &agentv1.StackHooks{PostApply: "./migrate.sh"}
+	if applyDigest(base) == applyDigest(hooked) {
+		t.Error("digest did not change with hooks")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, resource string
+		want              bool
+	}{
+		{"*", "stack:web", true},
+		{"stack:*", "stack:web", true},
+		{"stack:*", "container:web", false},
+		{"stack:web", "stack:web", true},
+		{"stack:web", "stack:web2", false},
+		{"stack:web*", "stack:web2", true},
+		{"", "stack:web", false},
+		{"stack.*", "stack.apply", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.resource); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.resource, got, tt.want)
+		}
+	}
+}
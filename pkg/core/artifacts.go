@@ -0,0 +1,361 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/artifact"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/bhangun/mandau/pkg/transfer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultArtifactRetention  = 30 * 24 * time.Hour
+	defaultArtifactGCInterval = 24 * time.Hour
+)
+
+// Artifacts is the core's content-addressable store and how long unused
+// content is kept in it
+type Artifacts struct {
+	store     *artifact.Store
+	retention time.Duration
+	interval  time.Duration
+}
+
+// newArtifacts returns nil when artifacts.dir is not set
+func newArtifacts(cfg config.ArtifactsConfig) (*Artifacts, error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+	store, err := artifact.Open(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Artifacts{store: store, retention: defaultArtifactRetention, interval: defaultArtifactGCInterval}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{{"retention", cfg.Retention, &a.retention}, {"gc_interval", cfg.GCInterval, &a.interval}} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("%s: invalid duration %q", d.name, d.value)
+		}
+		*d.dst = v
+	}
+	return a, nil
+}
+
+// collectArtifacts removes unused artifacts every gc_interval until ctx is
+// cancelled
+func (c *Core) collectArtifacts(ctx context.Context) {
+	if c.artifacts == nil {
+		return
+	}
+
+	ticker := time.NewTicker(c.artifacts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := c.artifacts.store.Collect(time.Now().Add(-c.artifacts.retention), false)
+			if err != nil {
+				log.Printf("Artifact garbage collection failed: %v", err)
+			}
+			if len(removed) > 0 {
+				log.Printf("Removed %d artifact(s) unused for %s", len(removed), c.artifacts.retention)
+			}
+		}
+	}
+}
+
+// artifactStore returns the store, or FailedPrecondition when it is
+// disabled
+func (c *Core) artifactStore() (*artifact.Store, error) {
+	if c.artifacts == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the artifact store is disabled; set artifacts.dir in the core config")
+	}
+	return c.artifacts.store, nil
+}
+
+// authorizeArtifacts checks that the caller may perform action on the
+// artifact store
+func (c *Core) authorizeArtifacts(ctx context.Context, action string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	if err := auth.Authorize(ctx, identity, &plugin.Action{
+		Action:   action,
+		Resource: "artifact:*",
+	}); err != nil {
+		return status.Errorf(codes.PermissionDenied, "%s may not %s artifacts", identity.UserID, action)
+	}
+	return nil
+}
+
+// artifactError maps artifact store errors to statuses
+func artifactError(err error) error {
+	switch {
+	case errors.Is(err, artifact.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, artifact.ErrDigest), errors.Is(err, artifact.ErrSize):
+		return status.Error(codes.DataLoss, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toProtoArtifact(a *artifact.Artifact) *agentv1.Artifact {
+	return &agentv1.Artifact{
+		Digest:    a.Digest,
+		Kind:      a.Kind,
+		Names:     a.Names,
+		Size:      a.Size,
+		Pinned:    a.Pinned,
+		CreatedAt: timestamppb.New(a.CreatedAt),
+		UsedAt:    timestamppb.New(a.UsedAt),
+		Pushes:    a.Pushes,
+		Fetches:   a.Fetches,
+	}
+}
+
+// PutArtifact stores the payload its first request describes, once the
+// caller may write artifacts
+func (c *Core) PutArtifact(stream agentv1.ArtifactService_PutArtifactServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty artifact")
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.authorizeArtifacts(ctx, "write"); err != nil {
+		return err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return err
+	}
+	if !artifact.ValidKind(first.Kind) {
+		return status.Errorf(codes.InvalidArgument, "unknown artifact kind %q: want one of %s", first.Kind, strings.Join(artifact.Kinds, ", "))
+	}
+	if !artifact.ValidDigest(first.Digest) {
+		return status.Errorf(codes.InvalidArgument, "invalid digest %q: want 64 lower-case hex digits", first.Digest)
+	}
+
+	// Headers tell the caller the payload is wanted
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	a, err := store.Put(artifact.Meta{
+		Digest: first.Digest,
+		Kind:   first.Kind,
+		Name:   first.Name,
+		Size:   first.Size,
+		Pin:    first.Pin,
+	}, &artifactReader{stream: stream, chunk: first.Chunk})
+	if err != nil {
+		return artifactError(err)
+	}
+	return stream.SendAndClose(toProtoArtifact(a))
+}
+
+// artifactReader reads the chunks of a PutArtifact stream
+type artifactReader struct {
+	stream agentv1.ArtifactService_PutArtifactServer
+	chunk  []byte
+}
+
+func (r *artifactReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = req.Chunk
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// GetArtifact returns the record of an artifact
+func (c *Core) GetArtifact(ctx context.Context, req *agentv1.GetArtifactRequest) (*agentv1.Artifact, error) {
+	if err := c.authorizeArtifacts(ctx, "read"); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	a, err := store.Get(req.Digest)
+	if err != nil {
+		if errors.Is(err, artifact.ErrNotFound) {
+			return nil, artifactError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toProtoArtifact(a), nil
+}
+
+// ListArtifacts lists the stored artifacts, the most recently used first
+func (c *Core) ListArtifacts(ctx context.Context, req *agentv1.ListArtifactsRequest) (*agentv1.ListArtifactsResponse, error) {
+	if err := c.authorizeArtifacts(ctx, "read"); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := store.List(req.Kind)
+	if err != nil {
+		return nil, artifactError(err)
+	}
+
+	resp := &agentv1.ListArtifactsResponse{}
+	for _, a := range artifacts {
+		resp.Artifacts = append(resp.Artifacts, toProtoArtifact(a))
+	}
+	return resp, nil
+}
+
+// DeleteArtifact removes an artifact, pinned or not
+func (c *Core) DeleteArtifact(ctx context.Context, req *agentv1.DeleteArtifactRequest) (*agentv1.Artifact, error) {
+	if err := c.authorizeArtifacts(ctx, "delete"); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	a, err := store.Delete(req.Digest)
+	if err != nil {
+		if errors.Is(err, artifact.ErrNotFound) {
+			return nil, artifactError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toProtoArtifact(a), nil
+}
+
+// CollectArtifactGarbage removes the unpinned artifacts unused within
+// artifacts.retention without waiting for the next collection
+func (c *Core) CollectArtifactGarbage(ctx context.Context, req *agentv1.CollectArtifactGarbageRequest) (*agentv1.CollectArtifactGarbageResponse, error) {
+	if err := c.authorizeArtifacts(ctx, "delete"); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	removed, err := store.Collect(time.Now().Add(-c.artifacts.retention), req.DryRun)
+
+	resp := &agentv1.CollectArtifactGarbageResponse{}
+	for _, a := range removed {
+		resp.Removed = append(resp.Removed, toProtoArtifact(a))
+		resp.Freed += a.Size
+	}
+	if err != nil {
+		return resp, artifactError(err)
+	}
+	return resp, nil
+}
+
+// FetchArtifact returns a chunk of an artifact to the agent the caller's
+// certificate is bound to
+func (c *Core) FetchArtifact(ctx context.Context, req *agentv1.FetchArtifactRequest) (*agentv1.ArtifactChunk, error) {
+	if err := c.verifyAgentIdentity(ctx, agentv1.ArtifactService_FetchArtifact_FullMethodName, req.AgentId, false); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	f, a, err := store.Open(req.Digest)
+	if err != nil {
+		if errors.Is(err, artifact.ErrNotFound) {
+			return nil, artifactError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer f.Close()
+	if req.Offset < 0 || req.Offset > a.Size {
+		return nil, status.Errorf(codes.OutOfRange, "offset %d is outside artifact %s, which has %d bytes", req.Offset, req.Digest, a.Size)
+	}
+
+	data := make([]byte, min(int64(transfer.ChunkSize), a.Size-req.Offset))
+	if _, err := f.ReadAt(data, req.Offset); err != nil && err != io.EOF {
+		return nil, status.Errorf(codes.Internal, "read artifact: %v", err)
+	}
+	if req.Offset == 0 {
+		if err := store.Fetched(req.Digest); err != nil {
+			log.Printf("Record fetch of artifact %s: %v", req.Digest, err)
+		}
+	}
+	return &agentv1.ArtifactChunk{Offset: req.Offset, Data: data, Size: a.Size}, nil
+}
+
+// Fetch has an agent fetch an artifact from the store and deliver it to a
+// target, once the caller may change the target's stack
+func (c *Core) Fetch(ctx context.Context, req *agentv1.FetchRequest) (resp *agentv1.TransferStatus, err error) {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.TransferService_Fetch_FullMethodName); err != nil {
+		return nil, err
+	}
+	if err := c.authorizeTransfer(ctx, conn, req.Target, "write", req.Emergency); err != nil {
+		return nil, err
+	}
+	store, err := c.artifactStore()
+	if err != nil {
+		return nil, err
+	}
+	a, err := store.Get(req.Digest)
+	if err != nil {
+		if errors.Is(err, artifact.ErrNotFound) {
+			return nil, artifactError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	op := c.activity.begin(req.AgentId, "fetch", req.Target.StackName, normalizeNamespace(req.Target.Namespace),
+		c.requesterID(ctx), requestid.From(ctx))
+	defer func() { op.end(err) }()
+
+	fwd := proto.Clone(req).(*agentv1.FetchRequest)
+	fwd.Size = a.Size
+	return agentv1.NewTransferServiceClient(conn.Client).Fetch(ctx, fwd)
+}
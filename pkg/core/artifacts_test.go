@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fetchAgent answers fetches with what it was asked
+type fetchAgent struct {
+	agentv1.UnimplementedTransferServiceServer
+	req *agentv1.FetchRequest
+}
+
+func (a *fetchAgent) Fetch(ctx context.Context, req *agentv1.FetchRequest) (*agentv1.TransferStatus, error) {
+	a.req = req
+	return &agentv1.TransferStatus{Size: req.Size, Received: req.Size, Delivered: true}, nil
+}
+
+func TestArtifacts(t *testing.T) {
+	agentServer := grpc.NewServer()
+	agent := &fetchAgent{}
+	agentv1.RegisterTransferServiceServer(agentServer, agent)
+	agentConn := serve(t, agentServer)
+
+	artifacts, err := newArtifacts(config.ArtifactsConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: []string{"docker", "transfer"}, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		plugins:         plugin.NewRegistry(),
+		activity:        newActivity(),
+		artifacts:       artifacts,
+		agentIdentities: &AgentIdentities{bindings: map[string]string{}},
+	}
+	coreServer := grpc.NewServer()
+	agentv1.RegisterArtifactServiceServer(coreServer, c)
+	agentv1.RegisterTransferServiceServer(coreServer, c)
+	conn := serve(t, coreServer)
+	client := agentv1.NewArtifactServiceClient(conn)
+	ctx := context.Background()
+
+	content := "#!/bin/sh\necho migrate\n"
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+	for _, name := range []string{"migrate.sh", "migrate.sh"} {
+		stream, err := client.PutArtifact(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Send(&agentv1.PutArtifactRequest{Digest: digest, Kind: "script", Name: name, Size: int64(len(content))})
+		if md, _ := stream.Header(); md == nil {
+			_, err := stream.CloseAndRecv()
+			t.Fatalf("no headers: %v", err)
+		}
+		stream.Send(&agentv1.PutArtifactRequest{Chunk: []byte(content[:10])})
+		stream.Send(&agentv1.PutArtifactRequest{Chunk: []byte(content[10:])})
+		if _, err := stream.CloseAndRecv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := client.ListArtifacts(ctx, &agentv1.ListArtifactsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Artifacts) != 1 || list.Artifacts[0].Pushes != 2 || len(list.Artifacts[0].Names) != 1 {
+		t.Errorf("pushed twice: %v", list.Artifacts)
+	}
+
+	// Content that is not what it claims is refused
+	stream, _ := client.PutArtifact(ctx)
+	stream.Send(&agentv1.PutArtifactRequest{Digest: digest, Kind: "script", Size: 5, Chunk: []byte("wrong")})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.DataLoss {
+		t.Errorf("wrong content: error = %v, want data loss", err)
+	}
+
+	// The core tells the agent how much to fetch
+	target := &agentv1.TransferTarget{Kind: agentv1.TransferKind_TRANSFER_KIND_FILE, StackName: "shop", Path: "migrate.sh"}
+	transfers := agentv1.NewTransferServiceClient(conn)
+	if _, err := transfers.Fetch(ctx, &agentv1.FetchRequest{AgentId: "web-1", Target: target, Digest: digest}); err != nil {
+		t.Fatal(err)
+	}
+	if agent.req.Size != int64(len(content)) || agent.req.Digest != digest {
+		t.Errorf("agent was asked for %v", agent.req)
+	}
+	unknown := hex.EncodeToString(make([]byte, 32))
+	if _, err := transfers.Fetch(ctx, &agentv1.FetchRequest{AgentId: "web-1", Target: target, Digest: unknown}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown artifact: error = %v, want not found", err)
+	}
+
+	// Only agents fetch content, with their own certificate
+	if _, err := client.FetchArtifact(ctx, &agentv1.FetchArtifactRequest{AgentId: "web-1", Digest: digest}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("fetch without an agent certificate: error = %v, want permission denied", err)
+	}
+
+	c.artifacts = nil
+	if _, err := client.ListArtifacts(ctx, &agentv1.ListArtifactsRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("disabled store: error = %v, want failed precondition", err)
+	}
+}
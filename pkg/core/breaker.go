@@ -0,0 +1,379 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Circuit breaker defaults when circuit_breaker is not configured
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+	defaultMaxAttempts     = 3
+	defaultRetryBudget     = 0.1
+	// retryTokens is the size of the retry budget: retries stop once half
+	// of it is spent on failures and resume as successful calls refill it
+	retryTokens = 10
+)
+
+// Circuit states
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// readPrefixes name the unary agent calls that change nothing, which are
+// the ones retried when the agent was unavailable
+var readPrefixes = []string{"Get", "List", "Describe", "Diff", "Export", "Inspect", "Read", "Check"}
+
+// agentServices are the services the core calls on agents
+var agentServices = []grpc.ServiceDesc{
+	agentv1.AgentService_ServiceDesc,
+	agentv1.StackService_ServiceDesc,
+	agentv1.ContainerService_ServiceDesc,
+	agentv1.FilesystemService_ServiceDesc,
+	agentv1.OperationsService_ServiceDesc,
+	agentv1.NginxService_ServiceDesc,
+	agentv1.SystemdService_ServiceDesc,
+	agentv1.FirewallService_ServiceDesc,
+	agentv1.ACMEService_ServiceDesc,
+	agentv1.HostEnvironmentService_ServiceDesc,
+	agentv1.CronService_ServiceDesc,
+	agentv1.DNSService_ServiceDesc,
+	agentv1.ServiceDeploymentService_ServiceDesc,
+	agentv1.DriftService_ServiceDesc,
+	agentv1.PortService_ServiceDesc,
+	agentv1.ProcessService_ServiceDesc,
+	agentv1.HostLogService_ServiceDesc,
+	agentv1.HostPowerService_ServiceDesc,
+	agentv1.LogShipService_ServiceDesc,
+	agentv1.TransferService_ServiceDesc,
+}
+
+// circuitBreakers keep one circuit per agent. A circuit opens after
+// consecutive calls to its agent failed to reach it; while open, calls fail
+// fast. After the cooldown one probe call goes through: when it reaches the
+// agent the circuit closes, otherwise it stays open for another cooldown.
+type circuitBreakers struct {
+	failures      int // Zero disables the breakers
+	cooldown      time.Duration
+	serviceConfig string // Retry policy of channels to agents
+	now           func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state     string
+	since     time.Time // When it opened; zero while closed
+	probeAt   time.Time // When the next call may go through while open
+	failures  int       // Consecutive
+	lastError string
+	trips     int64
+	rejected  int64
+}
+
+func newCircuitBreakers(cfg config.CircuitBreakerConfig) *circuitBreakers {
+	b := &circuitBreakers{
+		failures: defaultBreakerFailures,
+		cooldown: defaultBreakerCooldown,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+	switch {
+	case cfg.Failures < 0:
+		b.failures = 0
+	case cfg.Failures > 0:
+		b.failures = cfg.Failures
+	}
+	if cfg.Cooldown != "" {
+		if d, err := time.ParseDuration(cfg.Cooldown); err == nil && d > 0 {
+			b.cooldown = d
+		} else {
+			log.Printf("Invalid circuit_breaker cooldown %q, using %s", cfg.Cooldown, b.cooldown)
+		}
+	}
+
+	maxAttempts, budget := defaultMaxAttempts, defaultRetryBudget
+	if cfg.MaxAttempts > 0 {
+		maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.RetryBudget > 0 {
+		budget = cfg.RetryBudget
+	}
+	b.serviceConfig = retryServiceConfig(maxAttempts, budget)
+	return b
+}
+
+// retryServiceConfig is the gRPC service config that retries reads which
+// found the agent unavailable, up to maxAttempts tries, as long as the
+// retry budget lasts. Each failure spends a token and each success earns
+// budget tokens back.
+func retryServiceConfig(maxAttempts int, budget float64) string {
+	if maxAttempts < 2 {
+		return ""
+	}
+	// gRPC caps attempts at 5
+	maxAttempts = min(maxAttempts, 5)
+
+	var names []map[string]string
+	for _, svc := range agentServices {
+		for _, m := range svc.Methods {
+			for _, prefix := range readPrefixes {
+				if strings.HasPrefix(m.MethodName, prefix) {
+					names = append(names, map[string]string{"service": svc.ServiceName, "method": m.MethodName})
+					break
+				}
+			}
+		}
+	}
+
+	cfg := map[string]any{
+		"methodConfig": []any{map[string]any{
+			"name": names,
+			"retryPolicy": map[string]any{
+				"maxAttempts":          maxAttempts,
+				"initialBackoff":       "0.2s",
+				"maxBackoff":           "2s",
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE"},
+			},
+		}},
+		"retryThrottling": map[string]any{
+			"maxTokens":  retryTokens,
+			"tokenRatio": budget,
+		},
+	}
+	out, _ := json.Marshal(cfg)
+	return string(out)
+}
+
+// dialOptions install the agent's circuit and the retry policy on a
+// channel to it
+func (b *circuitBreakers) dialOptions(agentID string) []grpc.DialOption {
+	opts := []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(b.unaryInterceptor(agentID)),
+		grpc.WithChainStreamInterceptor(b.streamInterceptor(agentID)),
+	}
+	if b.serviceConfig != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(b.serviceConfig))
+	}
+	return opts
+}
+
+// bypassCircuitKey marks calls that go through an open circuit, such as
+// diagnostics. Their outcome still counts.
+type bypassCircuitKey struct{}
+
+func bypassCircuit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCircuitKey{}, true)
+}
+
+// allow lets a call to the agent through, or fails it fast while its
+// circuit is open. Once the cooldown passed, one call goes through as the
+// probe and the next waits for another cooldown.
+func (b *circuitBreakers) allow(ctx context.Context, agentID string) error {
+	if b.failures == 0 || ctx.Value(bypassCircuitKey{}) != nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[agentID]
+	if c == nil || c.state == circuitClosed {
+		return nil
+	}
+	now := b.now()
+	if now.Before(c.probeAt) {
+		c.rejected++
+		return errAgentUnhealthy(agentID, c.since, c.probeAt, c.lastError)
+	}
+	c.state = circuitHalfOpen
+	c.probeAt = now.Add(b.cooldown)
+	return nil
+}
+
+// record counts the outcome of a call to the agent. Only failures to reach
+// it count against it; an error from the agent itself means it answered.
+func (b *circuitBreakers) record(agentID string, err error) {
+	if b.failures == 0 {
+		return
+	}
+	switch status.Code(err) {
+	case codes.Canceled:
+		// The caller gave up, which tells nothing about the agent
+		return
+	case codes.Unavailable, codes.DeadlineExceeded:
+		b.fail(agentID, err)
+	default:
+		b.succeed(agentID)
+	}
+}
+
+func (b *circuitBreakers) fail(agentID string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[agentID]
+	if c == nil {
+		c = &circuit{state: circuitClosed}
+		b.circuits[agentID] = c
+	}
+	c.failures++
+	c.lastError = status.Convert(err).Message()
+
+	now := b.now()
+	switch c.state {
+	case circuitClosed:
+		if c.failures < b.failures {
+			return
+		}
+		c.state, c.since = circuitOpen, now
+		c.trips++
+		log.Printf("Circuit for agent %s opened after %d failed calls: %s", agentID, c.failures, c.lastError)
+	case circuitHalfOpen:
+		c.state = circuitOpen
+	}
+	c.probeAt = now.Add(b.cooldown)
+}
+
+func (b *circuitBreakers) succeed(agentID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[agentID]
+	if c == nil {
+		return
+	}
+	if c.state != circuitClosed {
+		log.Printf("Circuit for agent %s closed, it was open since %s", agentID, c.since.Format(time.RFC3339))
+	}
+	c.state, c.since, c.probeAt, c.failures, c.lastError = circuitClosed, time.Time{}, time.Time{}, 0, ""
+}
+
+// reset closes the agent's circuit, when it registers again
+func (b *circuitBreakers) reset(agentID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.circuits[agentID]; c != nil {
+		c.state, c.since, c.probeAt, c.failures, c.lastError = circuitClosed, time.Time{}, time.Time{}, 0, ""
+	}
+}
+
+// state returns the agent's circuit as reported in agent lists
+func (b *circuitBreakers) state(agentID string) *agentv1.AgentCircuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[agentID]
+	if c == nil {
+		return &agentv1.AgentCircuit{AgentId: agentID, State: circuitClosed}
+	}
+	return c.toProto(agentID)
+}
+
+// open returns the circuits that are not closed, by agent
+func (b *circuitBreakers) open() []*agentv1.AgentCircuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var open []*agentv1.AgentCircuit
+	for id, c := range b.circuits {
+		if c.state != circuitClosed {
+			open = append(open, c.toProto(id))
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].AgentId < open[j].AgentId })
+	return open
+}
+
+func (c *circuit) toProto(agentID string) *agentv1.AgentCircuit {
+	pb := &agentv1.AgentCircuit{
+		AgentId:   agentID,
+		State:     c.state,
+		Failures:  int32(c.failures),
+		LastError: c.lastError,
+		Trips:     c.trips,
+		Rejected:  c.rejected,
+	}
+	if !c.since.IsZero() {
+		pb.Since = timestamppb.New(c.since)
+	}
+	return pb
+}
+
+func (b *circuitBreakers) unaryInterceptor(agentID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.allow(ctx, agentID); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(agentID, err)
+		return err
+	}
+}
+
+func (b *circuitBreakers) streamInterceptor(agentID string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := b.allow(ctx, agentID); err != nil {
+			return nil, err
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			b.record(agentID, err)
+			return nil, err
+		}
+		return &breakerStream{ClientStream: cs, breakers: b, agentID: agentID}, nil
+	}
+}
+
+// breakerStream counts a stream by its first message or error from the
+// agent, which is when it is known whether the agent was reached
+type breakerStream struct {
+	grpc.ClientStream
+	breakers *circuitBreakers
+	agentID  string
+	once     sync.Once
+}
+
+func (s *breakerStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			s.breakers.record(s.agentID, nil)
+			return
+		}
+		s.breakers.record(s.agentID, err)
+	})
+	return err
+}
+
+// errAgentUnhealthy fails a call to an agent whose circuit is open
+func errAgentUnhealthy(agentID string, since, probeAt time.Time, lastErr string) error {
+	return transport.Error(codes.Unavailable, &agentv1.ErrorDetail{
+		Code:      agentv1.ErrorCode_ERROR_CODE_AGENT_UNHEALTHY,
+		AgentId:   agentID,
+		Retryable: true,
+		Hint: "calls to it kept failing (last: " + lastErr + "); the core tries it again after " +
+			probeAt.Format(time.RFC3339) + ", and mandau agent ping " + agentID + " shows why it fails",
+	}, "agent %s unhealthy since %s", agentID, since.Format(time.RFC3339))
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newCircuitBreakers(config.CircuitBreakerConfig{Failures: 3, Cooldown: "30s"})
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	// Answers from the agent, even errors, keep it closed
+	b.record("web-1", unavailable)
+	b.record("web-1", unavailable)
+	b.record("web-1", status.Error(codes.NotFound, "stack not found"))
+	b.record("web-1", unavailable)
+	b.record("web-1", status.Error(codes.Canceled, "caller gave up"))
+	b.record("web-1", unavailable)
+	if st := b.state("web-1"); st.State != circuitClosed || st.Failures != 2 {
+		t.Fatalf("after an answer: %s with %d failures, want closed with 2", st.State, st.Failures)
+	}
+
+	b.record("web-1", unavailable)
+	opened := now
+	err := b.allow(ctx, "web-1")
+	if d := transport.Detail(err); d.Code != agentv1.ErrorCode_ERROR_CODE_AGENT_UNHEALTHY || !d.Retryable {
+		t.Fatalf("open circuit: got %v, want a retryable AGENT_UNHEALTHY error", err)
+	}
+	if !strings.Contains(err.Error(), "agent web-1 unhealthy since 2026-01-01T12:00:00Z") {
+		t.Errorf("open circuit error %q does not say since when", err)
+	}
+	if err := b.allow(bypassCircuit(ctx), "web-1"); err != nil {
+		t.Errorf("bypassed call rejected: %v", err)
+	}
+	if err := b.allow(ctx, "web-2"); err != nil {
+		t.Errorf("other agent rejected: %v", err)
+	}
+
+	// After the cooldown one probe goes through; its failure reopens the
+	// circuit without moving its start
+	now = now.Add(31 * time.Second)
+	if err := b.allow(ctx, "web-1"); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if st := b.state("web-1"); st.State != circuitHalfOpen {
+		t.Fatalf("during the probe: %s, want half-open", st.State)
+	}
+	if err := b.allow(ctx, "web-1"); err == nil {
+		t.Fatal("second call during the probe let through")
+	}
+	b.record("web-1", status.Error(codes.DeadlineExceeded, "context deadline exceeded"))
+	st := b.state("web-1")
+	if st.State != circuitOpen || !st.Since.AsTime().Equal(opened) || st.Trips != 1 || st.Rejected != 2 {
+		t.Fatalf("after a failed probe: %+v, want open since %s, 1 trip and 2 rejected calls", st, opened)
+	}
+	if open := b.open(); len(open) != 1 || open[0].AgentId != "web-1" {
+		t.Errorf("open circuits = %v, want web-1", open)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := b.allow(ctx, "web-1"); err != nil {
+		t.Fatalf("second probe rejected: %v", err)
+	}
+	b.record("web-1", nil)
+	if st := b.state("web-1"); st.State != circuitClosed || st.Since != nil || st.Failures != 0 {
+		t.Fatalf("after a successful probe: %+v, want closed", st)
+	}
+	if err := b.allow(ctx, "web-1"); err != nil || len(b.open()) != 0 {
+		t.Errorf("closed circuit still rejects calls: %v", err)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreakers(config.CircuitBreakerConfig{Failures: -1})
+	for range 10 {
+		b.record("web-1", status.Error(codes.Unavailable, "connection refused"))
+	}
+	if err := b.allow(context.Background(), "web-1"); err != nil {
+		t.Errorf("disabled breaker rejected a call: %v", err)
+	}
+}
+
+func TestRetryServiceConfig(t *testing.T) {
+	for _, cfg := range []config.CircuitBreakerConfig{{}, {MaxAttempts: 9, RetryBudget: 0.5}} {
+		b := newCircuitBreakers(cfg)
+		if b.serviceConfig == "" {
+			t.Fatalf("%+v: no retry policy", cfg)
+		}
+		if !strings.Contains(b.serviceConfig, `"method":"GetStack"`) || strings.Contains(b.serviceConfig, `"method":"ApplyStack"`) {
+			t.Errorf("%+v: retry policy does not cover exactly the reads: %s", cfg, b.serviceConfig)
+		}
+		opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, b.dialOptions("web-1")...)
+		conn, err := grpc.NewClient("passthrough:///web-1:8444", opts...)
+		if err != nil {
+			t.Fatalf("%+v: gRPC rejects the retry policy: %v", cfg, err)
+		}
+		conn.Close()
+	}
+
+	if b := newCircuitBreakers(config.CircuitBreakerConfig{MaxAttempts: 1}); b.serviceConfig != "" {
+		t.Errorf("max_attempts 1 still retries: %s", b.serviceConfig)
+	}
+}
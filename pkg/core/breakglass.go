@@ -0,0 +1,280 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultBreakGlassMaxTTL caps grants when break_glass.max_ttl is not set
+const defaultBreakGlassMaxTTL = 4 * time.Hour
+
+// breakGlassAttribute is the identity attribute listing grants in use, so
+// audit entries made under elevation can be traced back to them
+const breakGlassAttribute = "break_glass_grants"
+
+// BreakGlassStore tracks temporary role elevations
+type BreakGlassStore struct {
+	mu     sync.Mutex
+	maxTTL time.Duration
+	grants map[string]*BreakGlassGrant
+}
+
+// BreakGlassGrant adds Roles to UserID until ExpiresAt or revocation
+type BreakGlassGrant struct {
+	ID        string
+	UserID    string
+	Roles     []string
+	Reason    string
+	GrantedBy string
+	GrantedAt time.Time
+	ExpiresAt time.Time
+	RevokedBy string
+	RevokedAt time.Time
+	expired   bool // Expiry has been audited
+}
+
+func newBreakGlassStore(cfg config.BreakGlassConfig) *BreakGlassStore {
+	maxTTL := defaultBreakGlassMaxTTL
+	if cfg.MaxTTL != "" {
+		if d, err := time.ParseDuration(cfg.MaxTTL); err == nil {
+			maxTTL = d
+		} else {
+			log.Printf("Invalid break_glass max_ttl %q, using %s: %v", cfg.MaxTTL, maxTTL, err)
+		}
+	}
+
+	return &BreakGlassStore{
+		maxTTL: maxTTL,
+		grants: make(map[string]*BreakGlassGrant),
+	}
+}
+
+func (g *BreakGlassGrant) active(now time.Time) bool {
+	return g.RevokedAt.IsZero() && now.Before(g.ExpiresAt)
+}
+
+// expireBreakGlass audits grants that ran out since the last check.
+// Callers must hold the store lock.
+func (c *Core) expireBreakGlass(ctx context.Context, now time.Time) {
+	for _, g := range c.breakGlass.grants {
+		if !g.expired && g.RevokedAt.IsZero() && !now.Before(g.ExpiresAt) {
+			g.expired = true
+			c.auditBreakGlass(ctx, &plugin.Identity{UserID: g.UserID}, g, "expired")
+		}
+	}
+}
+
+// elevate returns identity with the roles of its active break-glass grants
+func (c *Core) elevate(ctx context.Context, identity *plugin.Identity) *plugin.Identity {
+	c.breakGlass.mu.Lock()
+	defer c.breakGlass.mu.Unlock()
+
+	now := time.Now()
+	c.expireBreakGlass(ctx, now)
+
+	var roles, grantIDs []string
+	for _, g := range c.breakGlass.grants {
+		if g.UserID == identity.UserID && g.active(now) {
+			roles = append(roles, g.Roles...)
+			grantIDs = append(grantIDs, g.ID)
+		}
+	}
+	if len(grantIDs) == 0 {
+		return identity
+	}
+
+	elevated := *identity
+	elevated.Roles = append(append([]string{}, identity.Roles...), roles...)
+	elevated.Attributes = make(map[string]string, len(identity.Attributes)+1)
+	for k, v := range identity.Attributes {
+		elevated.Attributes[k] = v
+	}
+	sort.Strings(grantIDs)
+	elevated.Attributes[breakGlassAttribute] = strings.Join(grantIDs, ",")
+
+	return &elevated
+}
+
+// GrantBreakGlass elevates an identity for a limited time. The caller needs
+// the "breakglass" action on "role:<name>" for every role requested.
+func (c *Core) GrantBreakGlass(ctx context.Context, req *agentv1.GrantBreakGlassRequest) (*agentv1.BreakGlassGrant, error) {
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	// Elevation must not be able to extend itself
+	if identity.Attributes[breakGlassAttribute] != "" {
+		return nil, status.Error(codes.PermissionDenied, "break-glass grants cannot be issued while elevated")
+	}
+	if err := notImpersonated(identity, "grant break-glass access"); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Error(codes.InvalidArgument, "a reason is required for break-glass access")
+	}
+	if len(req.Roles) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one role is required")
+	}
+
+	ttl := req.Ttl.AsDuration()
+	if ttl <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "a ttl is required for break-glass access")
+	}
+	if ttl > c.breakGlass.maxTTL {
+		return nil, status.Errorf(codes.InvalidArgument, "ttl %s exceeds maximum %s", ttl, c.breakGlass.maxTTL)
+	}
+
+	if err := c.authorizeBreakGlass(ctx, identity, req.Roles); err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == "" {
+		userID = identity.UserID
+	}
+
+	now := time.Now()
+	grant := &BreakGlassGrant{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Roles:     req.Roles,
+		Reason:    req.Reason,
+		GrantedBy: identity.UserID,
+		GrantedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	c.breakGlass.mu.Lock()
+	c.breakGlass.grants[grant.ID] = grant
+	c.breakGlass.mu.Unlock()
+
+	c.auditBreakGlass(ctx, identity, grant, "granted")
+
+	return toProtoBreakGlass(grant, now), nil
+}
+
+// RevokeBreakGlass ends a grant early. The grantee, the grantor or anyone who
+// could have issued the grant may revoke it.
+func (c *Core) RevokeBreakGlass(ctx context.Context, req *agentv1.RevokeBreakGlassRequest) (*agentv1.BreakGlassGrant, error) {
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	c.breakGlass.mu.Lock()
+	defer c.breakGlass.mu.Unlock()
+
+	grant, ok := c.breakGlass.grants[req.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "break-glass grant not found: %s", req.Id)
+	}
+
+	now := time.Now()
+	if !grant.active(now) {
+		return nil, status.Errorf(codes.FailedPrecondition, "break-glass grant %s is no longer active", req.Id)
+	}
+
+	if identity.UserID != grant.UserID && identity.UserID != grant.GrantedBy {
+		if err := c.authorizeBreakGlass(ctx, identity, grant.Roles); err != nil {
+			return nil, err
+		}
+	}
+
+	grant.RevokedBy = identity.UserID
+	grant.RevokedAt = now
+	c.auditBreakGlass(ctx, identity, grant, "revoked")
+
+	return toProtoBreakGlass(grant, now), nil
+}
+
+// ListBreakGlassGrants returns grants, newest first
+func (c *Core) ListBreakGlassGrants(ctx context.Context, req *agentv1.ListBreakGlassGrantsRequest) (*agentv1.ListBreakGlassGrantsResponse, error) {
+	c.breakGlass.mu.Lock()
+	defer c.breakGlass.mu.Unlock()
+
+	now := time.Now()
+	c.expireBreakGlass(ctx, now)
+
+	grants := make([]*agentv1.BreakGlassGrant, 0, len(c.breakGlass.grants))
+	for _, g := range c.breakGlass.grants {
+		if req.ActiveOnly && !g.active(now) {
+			continue
+		}
+		grants = append(grants, toProtoBreakGlass(g, now))
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].GrantedAt.AsTime().After(grants[j].GrantedAt.AsTime())
+	})
+
+	return &agentv1.ListBreakGlassGrantsResponse{Grants: grants}, nil
+}
+
+func (c *Core) authorizeBreakGlass(ctx context.Context, identity *plugin.Identity, roles []string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	for _, role := range roles {
+		if err := auth.Authorize(ctx, identity, &plugin.Action{
+			Action:   "breakglass",
+			Resource: "role:" + role,
+		}); err != nil {
+			return status.Errorf(codes.PermissionDenied, "%s may not grant role %s: %v", identity.UserID, role, err)
+		}
+	}
+	return nil
+}
+
+func (c *Core) auditBreakGlass(ctx context.Context, identity *plugin.Identity, g *BreakGlassGrant, result string) {
+	log.Printf("BREAK-GLASS %s: grant %s for %s roles=%s by %s until %s reason=%q",
+		result, g.ID, g.UserID, strings.Join(g.Roles, ","), identity.UserID,
+		g.ExpiresAt.Format(time.RFC3339), g.Reason)
+
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		Identity:  identity,
+		Action:    "breakglass." + result,
+		Resource:  "user:" + g.UserID,
+		Result:    result,
+		Metadata: map[string]string{
+			"grant_id":   g.ID,
+			"roles":      strings.Join(g.Roles, ","),
+			"reason":     g.Reason,
+			"granted_by": g.GrantedBy,
+			"expires_at": g.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+func toProtoBreakGlass(g *BreakGlassGrant, now time.Time) *agentv1.BreakGlassGrant {
+	grant := &agentv1.BreakGlassGrant{
+		Id:        g.ID,
+		UserId:    g.UserID,
+		Roles:     g.Roles,
+		Reason:    g.Reason,
+		GrantedBy: g.GrantedBy,
+		GrantedAt: timestamppb.New(g.GrantedAt),
+		ExpiresAt: timestamppb.New(g.ExpiresAt),
+		RevokedBy: g.RevokedBy,
+		Active:    g.active(now),
+	}
+	if !g.RevokedAt.IsZero() {
+		grant.RevokedAt = timestamppb.New(g.RevokedAt)
+	}
+	return grant
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newBreakGlassCore(maxTTL string) *Core {
+	return &Core{
+		plugins:    plugin.NewRegistry(),
+		breakGlass: newBreakGlassStore(config.BreakGlassConfig{MaxTTL: maxTTL}),
+	}
+}
+
+func TestGrantBreakGlass(t *testing.T) {
+	plain := &plugin.Identity{UserID: "oncall"}
+	elevated := &plugin.Identity{
+		UserID:     "oncall",
+		Attributes: map[string]string{breakGlassAttribute: "grant-1"},
+	}
+
+	tests := []struct {
+		name     string
+		maxTTL   string
+		identity *plugin.Identity
+		req      *agentv1.GrantBreakGlassRequest
+		want     codes.Code
+	}{
+		{
+			name:     "within cap",
+			identity: plain,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "incident", Ttl: durationpb.New(time.Hour)},
+			want:     codes.OK,
+		},
+		{
+			name:     "default cap is 4h",
+			identity: plain,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "incident", Ttl: durationpb.New(5 * time.Hour)},
+			want:     codes.InvalidArgument,
+		},
+		{
+			name:     "configured cap",
+			maxTTL:   "30m",
+			identity: plain,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "incident", Ttl: durationpb.New(time.Hour)},
+			want:     codes.InvalidArgument,
+		},
+		{
+			name:     "ttl required",
+			identity: plain,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "incident"},
+			want:     codes.InvalidArgument,
+		},
+		{
+			name:     "reason required",
+			identity: plain,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "  ", Ttl: durationpb.New(time.Hour)},
+			want:     codes.InvalidArgument,
+		},
+		{
+			name:     "no grant while elevated",
+			identity: elevated,
+			req:      &agentv1.GrantBreakGlassRequest{Roles: []string{"admin"}, Reason: "incident", Ttl: durationpb.New(time.Hour)},
+			want:     codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBreakGlassCore(tt.maxTTL)
+			ctx := plugin.WithIdentity(context.Background(), tt.identity)
+
+			_, err := c.GrantBreakGlass(ctx, tt.req)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("got %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestElevateMarksIdentity(t *testing.T) {
+	c := newBreakGlassCore("")
+	ctx := plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: "lead"})
+
+	grant, err := c.GrantBreakGlass(ctx, &agentv1.GrantBreakGlassRequest{
+		UserId: "oncall", Roles: []string{"admin"}, Reason: "incident", Ttl: durationpb.New(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+
+	identity := c.elevate(context.Background(), &plugin.Identity{UserID: "oncall", Roles: []string{"viewer"}})
+	if identity.Attributes[breakGlassAttribute] != grant.Id {
+		t.Fatalf("elevated identity not marked with grant %s: %v", grant.Id, identity.Attributes)
+	}
+	if len(identity.Roles) != 2 || identity.Roles[1] != "admin" {
+		t.Fatalf("roles: got %v", identity.Roles)
+	}
+
+	// The elevated identity cannot extend its own elevation
+	_, err = c.GrantBreakGlass(plugin.WithIdentity(context.Background(), identity), &agentv1.GrantBreakGlassRequest{
+		Roles: []string{"admin"}, Reason: "extend", Ttl: durationpb.New(time.Hour),
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("grant while elevated: got %v, want PermissionDenied", err)
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/codes"
+)
+
+// methodCapabilities maps proxied RPCs to the agent capability they require
+var methodCapabilities = map[string]string{
+	agentv1.StackService_ListStacks_FullMethodName:          capability.Docker,
+	agentv1.StackService_GetStack_FullMethodName:            capability.Docker,
+	agentv1.StackService_DiffStack_FullMethodName:           capability.Docker,
+	agentv1.StackService_ExportStack_FullMethodName:         capability.Docker,
+	agentv1.StackService_CollectStackGarbage_FullMethodName: capability.Stack,
+	agentv1.StackService_ApplyStack_FullMethodName:          capability.Stack,
+	agentv1.StackService_RemoveStack_FullMethodName:         capability.Stack,
+	agentv1.StackService_DiffStackFiles_FullMethodName:      capability.Stack,
+	agentv1.StackService_UploadStackFiles_FullMethodName:    capability.Stack,
+	agentv1.StackService_GetStackLogs_FullMethodName:        capability.Logs,
+	agentv1.StackService_GetStackLogsBatched_FullMethodName: capability.Logs,
+	agentv1.StackService_GetStackEvents_FullMethodName:      capability.Timeline,
+	agentv1.TransferService_Upload_FullMethodName:           capability.Transfer,
+	agentv1.TransferService_GetTransfer_FullMethodName:      capability.Transfer,
+	agentv1.TransferService_ListBackups_FullMethodName:      capability.Transfer,
+	agentv1.TransferService_DownloadBackup_FullMethodName:   capability.Transfer,
+	agentv1.TransferService_Fetch_FullMethodName:            capability.Transfer,
+}
+
+// requireCapability rejects a proxied call when the target agent does not
+// advertise the capability the method needs. Agents that registered without
+// any capabilities are treated as legacy agents and let through.
+func requireCapability(conn *AgentConnection, method string) error {
+	return requireAgentCapability(conn, methodCapabilities[method], method)
+}
+
+// requireAgentCapability rejects a call to method unless conn advertises
+// required. An empty requirement and legacy agents pass.
+func requireAgentCapability(conn *AgentConnection, required, method string) error {
+	if required == "" || len(conn.Capabilities) == 0 {
+		return nil
+	}
+
+	if !capability.Has(conn.Capabilities, required) {
+		hint := "enable the plugin that provides " + required + " on the agent"
+		for _, p := range conn.Unavailable {
+			if p.Capability == required {
+				hint = p.Plugin + " cannot run on the agent's host: " + p.Reason
+			}
+		}
+		return transport.Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+			Code:    agentv1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING,
+			AgentId: conn.ID,
+			Hint:    hint,
+		}, "agent %s lacks capability %q required by %s", conn.ID, required, method)
+	}
+
+	return nil
+}
+
+// unavailablePlugins keeps the plugins an agent reported its host cannot
+// run
+func unavailablePlugins(reported []*agentv1.PluginAvailability) []*agentv1.PluginAvailability {
+	var unavailable []*agentv1.PluginAvailability
+	for _, p := range reported {
+		if !p.Available {
+			unavailable = append(unavailable, p)
+		}
+	}
+	return unavailable
+}
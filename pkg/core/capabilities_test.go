@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireCapability(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities []string
+		method       string
+		want         codes.Code
+	}{
+		{"has capability", []string{capability.Docker, capability.Stack}, agentv1.StackService_ApplyStack_FullMethodName, codes.OK},
+		{"lacks capability", []string{capability.Docker}, agentv1.StackService_ApplyStack_FullMethodName, codes.FailedPrecondition},
+		{"lacks another capability", []string{capability.Docker}, agentv1.StackService_ListStacks_FullMethodName, codes.OK},
+		{"lacks transfers", []string{capability.Docker, capability.Stack}, agentv1.TransferService_Upload_FullMethodName, codes.FailedPrecondition},
+		{"legacy agent", nil, agentv1.StackService_ApplyStack_FullMethodName, codes.OK},
+		{"method without requirement", []string{capability.Docker}, agentv1.OperationsService_GetOperation_FullMethodName, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &AgentConnection{ID: "web-1", Capabilities: tt.capabilities}
+			err := requireCapability(conn, tt.method)
+			if status.Code(err) != tt.want {
+				t.Fatalf("requireCapability = %v, want %v", err, tt.want)
+			}
+			if err == nil {
+				return
+			}
+			d := transport.Detail(err)
+			if d.Code != agentv1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING || d.AgentId != "web-1" ||
+				d.Hint != "enable the plugin that provides "+methodCapabilities[tt.method]+" on the agent" {
+				t.Errorf("error detail = %v", d)
+			}
+		})
+	}
+}
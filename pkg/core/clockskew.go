@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"log"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// defaultMaxClockSkew is how far an agent's clock may drift from the
+// core's before it is flagged. Certificates are checked against the local
+// clock on both ends, and audit entries from agents and the core are
+// ordered by their timestamps.
+const defaultMaxClockSkew = 2 * time.Second
+
+// parseMaxClockSkew reads agent_management.max_clock_skew
+func parseMaxClockSkew(value string) time.Duration {
+	if value == "" {
+		return defaultMaxClockSkew
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid agent_management.max_clock_skew %q, using %s", value, defaultMaxClockSkew)
+		return defaultMaxClockSkew
+	}
+	return d
+}
+
+// heartbeatSkew returns the clock skew a heartbeat reports, agent clock
+// minus core clock. Agents measure it from the round trip of the previous
+// heartbeat; before that, the send time gives an estimate that is off by
+// the network latency.
+func heartbeatSkew(req *agentv1.HeartbeatRequest, received time.Time) (time.Duration, bool) {
+	if req.ClockOffset != nil {
+		return req.ClockOffset.AsDuration(), true
+	}
+	if req.SentAt != nil {
+		return req.SentAt.AsTime().Sub(received), true
+	}
+	return 0, false // Agents predating skew reports
+}
+
+// recordClockSkew stores the skew an agent's heartbeat reports and audits
+// it crossing the limit, as agent.clock_skew with result skewed or synced.
+// The caller holds the agent's write lock.
+func (c *Core) recordClockSkew(ctx context.Context, agent *AgentConnection, req *agentv1.HeartbeatRequest, received time.Time) {
+	skew, ok := heartbeatSkew(req, received)
+	if !ok {
+		return
+	}
+	agent.ClockSkew = &skew
+
+	skewed := absDuration(skew) > c.maxClockSkew
+	if skewed == agent.ClockSkewed {
+		return
+	}
+	agent.ClockSkewed = skewed
+
+	result := "synced"
+	if skewed {
+		result = "skewed"
+		log.Printf("Warning: agent %s clock is off by %s, more than %s; sync it with chrony or NTP", agent.ID, skew.Round(time.Millisecond), c.maxClockSkew)
+	} else {
+		log.Printf("Agent %s clock is back within %s", agent.ID, c.maxClockSkew)
+	}
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: received,
+		AgentID:   agent.ID,
+		Identity:  &plugin.Identity{UserID: "agent:" + agent.ID},
+		Action:    "agent.clock_skew",
+		Resource:  "agent:" + agent.ID,
+		Result:    result,
+		Metadata: map[string]string{
+			"skew":  skew.Round(time.Millisecond).String(),
+			"limit": c.maxClockSkew.String(),
+		},
+	})
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
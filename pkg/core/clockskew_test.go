@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// auditRecorder keeps the entries audited
+type auditRecorder struct{ entries []*plugin.AuditEntry }
+
+func (r *auditRecorder) Name() string    { return "audit-recorder" }
+func (r *auditRecorder) Version() string { return "test" }
+func (r *auditRecorder) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityAudit}
+}
+func (r *auditRecorder) Init(ctx context.Context, config map[string]interface{}) error { return nil }
+func (r *auditRecorder) Shutdown(ctx context.Context) error                            { return nil }
+func (r *auditRecorder) Log(ctx context.Context, entry *plugin.AuditEntry) {
+	r.entries = append(r.entries, entry)
+}
+func (r *auditRecorder) Query(ctx context.Context, filter *plugin.AuditFilter) ([]plugin.AuditEntry, error) {
+	return nil, nil
+}
+
+func TestRecordClockSkew(t *testing.T) {
+	auditor := &auditRecorder{}
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(auditor); err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{plugins: plugins, maxClockSkew: 2 * time.Second}
+	agent := &AgentConnection{ID: "web-1"}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Older agents report nothing
+	c.recordClockSkew(context.Background(), agent, &agentv1.HeartbeatRequest{}, now)
+	if agent.ClockSkew != nil {
+		t.Fatalf("skew recorded without a report: %v", *agent.ClockSkew)
+	}
+
+	// The first heartbeat only has its send time
+	c.recordClockSkew(context.Background(), agent, &agentv1.HeartbeatRequest{SentAt: timestamppb.New(now.Add(-5 * time.Second))}, now)
+	if agent.ClockSkew == nil || *agent.ClockSkew != -5*time.Second || !agent.ClockSkewed {
+		t.Fatalf("skew = %v, skewed %v; want -5s, skewed", agent.ClockSkew, agent.ClockSkewed)
+	}
+
+	// Measured offsets win, and staying over the limit is not audited again
+	c.recordClockSkew(context.Background(), agent, &agentv1.HeartbeatRequest{
+		SentAt:      timestamppb.New(now),
+		ClockOffset: durationpb.New(3 * time.Second),
+	}, now)
+	if *agent.ClockSkew != 3*time.Second {
+		t.Errorf("skew = %v, want the measured 3s", *agent.ClockSkew)
+	}
+
+	c.recordClockSkew(context.Background(), agent, &agentv1.HeartbeatRequest{ClockOffset: durationpb.New(10 * time.Millisecond)}, now)
+	if agent.ClockSkewed {
+		t.Error("still skewed at 10ms")
+	}
+
+	var results []string
+	for _, e := range auditor.entries {
+		if e.Action != "agent.clock_skew" || e.AgentID != "web-1" {
+			t.Errorf("unexpected audit entry %+v", e)
+		}
+		results = append(results, e.Result)
+	}
+	if len(results) != 2 || results[0] != "skewed" || results[1] != "synced" {
+		t.Errorf("audited %v, want skewed then synced", results)
+	}
+}
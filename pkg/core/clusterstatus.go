@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/diagnose"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// recentFailureWindow is how long failed changes show in the status
+	recentFailureWindow = 24 * time.Hour
+	// maxRecentFailures bounds the failed changes kept
+	maxRecentFailures = 20
+)
+
+// Activity tracks the stack changes made through the core, running and
+// recently failed, for the cluster status
+type Activity struct {
+	mu       sync.Mutex
+	next     int
+	running  map[int]*trackedOperation
+	failures []*trackedOperation // Oldest first
+}
+
+type trackedOperation struct {
+	op        *agentv1.ClusterOperation
+	namespace string // Decides who may see the operation
+}
+
+func newActivity() *Activity {
+	return &Activity{running: make(map[int]*trackedOperation)}
+}
+
+// begin records a change in flight; end must be called with its outcome
+func (a *Activity) begin(agentID, kind, stack, namespace, requestedBy, requestID string) *runningOperation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.next++
+	a.running[a.next] = &trackedOperation{
+		op: &agentv1.ClusterOperation{
+			AgentId:     agentID,
+			Kind:        kind,
+			Stack:       stack,
+			RequestedBy: requestedBy,
+			StartedAt:   timestamppb.Now(),
+			RequestId:   requestID,
+		},
+		namespace: namespace,
+	}
+	return &runningOperation{activity: a, id: a.next}
+}
+
+// fail records a change that failed
+func (a *Activity) fail(op *agentv1.ClusterOperation, namespace string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failures = append(a.failures, &trackedOperation{op: op, namespace: namespace})
+	if len(a.failures) > maxRecentFailures {
+		a.failures = a.failures[len(a.failures)-maxRecentFailures:]
+	}
+}
+
+// snapshot returns the changes in flight, oldest first, and the failures
+// of the last recentFailureWindow, newest first
+func (a *Activity) snapshot(now time.Time) (running, failures []*trackedOperation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, t := range a.running {
+		running = append(running, t)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].op.StartedAt.AsTime().Before(running[j].op.StartedAt.AsTime())
+	})
+
+	for i := len(a.failures) - 1; i >= 0; i-- {
+		if now.Sub(a.failures[i].op.FinishedAt.AsTime()) > recentFailureWindow {
+			break
+		}
+		failures = append(failures, a.failures[i])
+	}
+	return running, failures
+}
+
+// runningOperation is a change in flight
+type runningOperation struct {
+	activity *Activity
+	id       int
+	failure  string // Set by a failed operation event
+}
+
+// observe notes a failure the agent reports in an event
+func (r *runningOperation) observe(event *agentv1.OperationEvent) {
+	if event.State == agentv1.OperationState_OPERATION_STATE_FAILED {
+		r.failure = event.Error
+		if r.failure == "" {
+			r.failure = event.Message
+		}
+	}
+}
+
+// end removes the change from those in flight, keeping it as a failure if
+// err is set or the agent reported one
+func (r *runningOperation) end(err error) {
+	a := r.activity
+	a.mu.Lock()
+	t, ok := a.running[r.id]
+	delete(a.running, r.id)
+	a.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if err != nil {
+		r.failure = err.Error()
+	}
+	if r.failure == "" {
+		return
+	}
+	t.op.FinishedAt = timestamppb.Now()
+	t.op.Error = r.failure
+	a.fail(t.op, t.namespace)
+}
+
+// requesterID names the caller in records of its changes
+func (c *Core) requesterID(ctx context.Context) string {
+	if identity, err := c.callerIdentity(ctx); err == nil {
+		if identity.Impersonator != nil {
+			return identity.UserID + " (impersonated by " + identity.Impersonator.UserID + ")"
+		}
+		return identity.UserID
+	}
+	return ""
+}
+
+// forRequester passes the caller of ctx on to an agent a change is forwarded
+// to, so the agent records who made it
+func (c *Core) forRequester(ctx context.Context) context.Context {
+	if id := c.requesterID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, transport.RequesterHeader, id)
+	}
+	return ctx
+}
+
+// failInstruction keeps a queued instruction the agent failed to run
+func (c *Core) failInstruction(agentID string, inst *agentv1.AgentInstruction, reason string) {
+	op := &agentv1.ClusterOperation{
+		AgentId:     agentID,
+		Kind:        instructionKind(inst),
+		RequestedBy: inst.RequestedBy,
+		StartedAt:   inst.CreatedAt,
+		FinishedAt:  timestamppb.Now(),
+		Error:       reason,
+		RequestId:   inst.RequestId,
+	}
+	var namespace string
+	switch kind := inst.Kind.(type) {
+	case *agentv1.AgentInstruction_ApplyStack:
+		op.Stack, namespace = kind.ApplyStack.StackName, kind.ApplyStack.Namespace
+	case *agentv1.AgentInstruction_RemoveStack:
+		op.Stack, namespace = kind.RemoveStack.StackId, kind.RemoveStack.Namespace
+	}
+	c.activity.fail(op, normalizeNamespace(namespace))
+}
+
+// GetClusterStatus summarizes the cluster for an operator: versions, agents,
+// changes in flight and failed, freeze and maintenance, and certificates
+// about to expire. Operations are limited to agents the caller can read.
+func (c *Core) GetClusterStatus(ctx context.Context, req *agentv1.GetClusterStatusRequest) (*agentv1.ClusterStatus, error) {
+	now := time.Now()
+	st := &agentv1.ClusterStatus{
+		Version:            c.config.Version,
+		StartedAt:          timestamppb.New(c.startedAt),
+		Agents:             make(map[string]int32),
+		Freeze:             toProtoFreeze(c.freeze.get()),
+		QueuedInstructions: int32(len(c.instructions.all())),
+	}
+
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		st.Agents[string(agent.Status)]++
+		if agent.inMaintenance(now) {
+			st.Maintenance = append(st.Maintenance, agent.ID)
+		}
+		if agent.Certificate != nil {
+			st.ExpiringCertificates = appendExpiring(st.ExpiringCertificates, "agent:"+agent.ID, agent.Certificate, now)
+		}
+		if agent.ClockSkewed && agent.ClockSkew != nil {
+			st.ClockSkew = append(st.ClockSkew, &agentv1.AgentClockSkew{AgentId: agent.ID, Skew: durationpb.New(*agent.ClockSkew)})
+		}
+		agent.mu.RUnlock()
+	}
+	st.OpenCircuits = c.breakers.open()
+
+	for _, file := range []struct{ name, path string }{
+		{"core", c.config.CertPath},
+		{"ca", c.config.CAPath},
+	} {
+		for _, cert := range readCertificates(file.path) {
+			st.ExpiringCertificates = appendExpiring(st.ExpiringCertificates, file.name, cert, now)
+		}
+	}
+	sort.Slice(st.ExpiringCertificates, func(i, j int) bool {
+		return st.ExpiringCertificates[i].NotAfter.AsTime().Before(st.ExpiringCertificates[j].NotAfter.AsTime())
+	})
+
+	running, failures := c.activity.snapshot(now)
+	for _, t := range running {
+		if c.canReadAgent(ctx, t.op.AgentId, t.namespace) {
+			st.Running = append(st.Running, t.op)
+		}
+	}
+	for _, t := range failures {
+		if c.canReadAgent(ctx, t.op.AgentId, t.namespace) {
+			st.Failures = append(st.Failures, t.op)
+		}
+	}
+
+	return st, nil
+}
+
+// appendExpiring adds cert when it expires within the diagnose warning
+// period, or already has
+func appendExpiring(certs []*agentv1.ExpiringCertificate, name string, cert *x509.Certificate, now time.Time) []*agentv1.ExpiringCertificate {
+	if cert.NotAfter.Sub(now) >= diagnose.CertExpiryWarning {
+		return certs
+	}
+	return append(certs, &agentv1.ExpiringCertificate{
+		Name:     name,
+		Subject:  cert.Subject.CommonName,
+		NotAfter: timestamppb.New(cert.NotAfter),
+	})
+}
+
+// readCertificates returns every certificate in a PEM file, which may hold
+// a chain or a CA bundle
+func readCertificates(path string) []*x509.Certificate {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Cluster status: read %s: %v", path, err)
+		return nil
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestActivityTracksRunningAndFailed(t *testing.T) {
+	a := newActivity()
+
+	ok := a.begin("agent-1", "apply_stack", "web", "default", "alice", "req-1")
+	failed := a.begin("agent-2", "remove_stack", "db", "team-a", "bob", "req-2")
+	reported := a.begin("agent-3", "apply_stack", "cache", "default", "carol", "req-3")
+
+	running, failures := a.snapshot(time.Now())
+	if len(running) != 3 || len(failures) != 0 {
+		t.Fatalf("before end: %d running, %d failed, want 3 and 0", len(running), len(failures))
+	}
+
+	ok.end(nil)
+	failed.end(errors.New("agent unreachable"))
+	reported.observe(&agentv1.OperationEvent{State: agentv1.OperationState_OPERATION_STATE_FAILED, Message: "pull failed"})
+	reported.end(nil)
+
+	running, failures = a.snapshot(time.Now())
+	if len(running) != 0 {
+		t.Errorf("after end: %d running, want 0", len(running))
+	}
+	if len(failures) != 2 {
+		t.Fatalf("after end: %d failed, want 2", len(failures))
+	}
+	// Newest first
+	if got := failures[0].op; got.AgentId != "agent-3" || got.Error != "pull failed" {
+		t.Errorf("failures[0] = %s %q, want agent-3 \"pull failed\"", got.AgentId, got.Error)
+	}
+	if got := failures[1]; got.op.AgentId != "agent-2" || got.op.Error != "agent unreachable" || got.namespace != "team-a" || got.op.RequestId != "req-2" {
+		t.Errorf("failures[1] = %s %q in %s by %s, want agent-2 \"agent unreachable\" in team-a by req-2",
+			got.op.AgentId, got.op.Error, got.namespace, got.op.RequestId)
+	}
+}
+
+func TestActivityDropsOldFailures(t *testing.T) {
+	a := newActivity()
+	a.fail(&agentv1.ClusterOperation{AgentId: "old", FinishedAt: timestamppb.New(time.Now().Add(-2 * recentFailureWindow))}, "default")
+	a.fail(&agentv1.ClusterOperation{AgentId: "new", FinishedAt: timestamppb.Now()}, "default")
+
+	_, failures := a.snapshot(time.Now())
+	if len(failures) != 1 || failures[0].op.AgentId != "new" {
+		t.Errorf("failures = %v, want only the recent one", failures)
+	}
+
+	for i := 0; i < maxRecentFailures+5; i++ {
+		a.fail(&agentv1.ClusterOperation{FinishedAt: timestamppb.Now()}, "default")
+	}
+	if len(a.failures) != maxRecentFailures {
+		t.Errorf("kept %d failures, want %d", len(a.failures), maxRecentFailures)
+	}
+}
+
+func TestForRequester(t *testing.T) {
+	c := &Core{}
+
+	ctx := c.forRequester(plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: "alice"}))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(transport.RequesterHeader); len(got) != 1 || got[0] != "alice" {
+		t.Fatalf("%s = %v, want [alice]", transport.RequesterHeader, got)
+	}
+
+	// Calls without a caller pass nobody on
+	md, _ = metadata.FromOutgoingContext(c.forRequester(context.Background()))
+	if got := md.Get(transport.RequesterHeader); len(got) != 0 {
+		t.Fatalf("%s = %v without a caller", transport.RequesterHeader, got)
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetPatchCompliance asks every online agent serving the host environment
+// for its pending updates and reboots, in parallel. Agents the caller may
+// not read ("read" on "host:host") are left out; those that do not answer
+// are reported in agent_errors.
+func (c *Core) GetPatchCompliance(ctx context.Context, req *agentv1.GetPatchComplianceRequest) (*agentv1.PatchCompliance, error) {
+	var members map[string]bool
+	if req.Group != "" {
+		if _, ok := c.groups.get(req.Group); !ok {
+			return nil, status.Errorf(codes.NotFound, "group not found: %s", req.Group)
+		}
+		members = make(map[string]bool)
+		for _, id := range c.groupMembers(req.Group) {
+			members[id] = true
+		}
+	}
+	include := func(conn *AgentConnection) bool {
+		return (members == nil || members[conn.ID]) &&
+			capability.Has(conn.Capabilities, capability.Host) &&
+			c.authorizeAgent(ctx, conn, "read", "host:"+capability.Host) == nil
+	}
+
+	resp := &agentv1.PatchCompliance{GeneratedAt: timestamppb.Now()}
+	conns, failed := c.onlineAgents()
+	for id, err := range failed {
+		conn, ok := c.agents.get(id)
+		if ok && include(conn) {
+			if resp.AgentErrors == nil {
+				resp.AgentErrors = make(map[string]string)
+			}
+			resp.AgentErrors[id] = err.Error()
+		}
+	}
+
+	readable := make([]*AgentConnection, 0, len(conns))
+	for _, conn := range conns {
+		if include(conn) {
+			readable = append(readable, conn)
+		}
+	}
+	results := fanOut(ctx, c.fanOut, readable, func(ctx context.Context, conn *AgentConnection) (*agentv1.PatchStatus, error) {
+		return agentv1.NewHostEnvironmentServiceClient(conn.Client).GetPatchStatus(ctx, &agentv1.GetPatchStatusRequest{AgentId: conn.ID})
+	})
+	for _, r := range results {
+		if r.err != nil {
+			if resp.AgentErrors == nil {
+				resp.AgentErrors = make(map[string]string)
+			}
+			resp.AgentErrors[r.conn.ID] = status.Convert(r.err).Message()
+			continue
+		}
+		resp.Agents = append(resp.Agents, &agentv1.AgentPatchStatus{AgentId: r.conn.ID, Status: r.value})
+	}
+	return resp, nil
+}
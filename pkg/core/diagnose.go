@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/diagnose"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Diagnose checks the core itself, or one agent: its registration, the
+// core→agent connection, clock skew and the agent's own checks
+func (c *Core) Diagnose(ctx context.Context, req *agentv1.DiagnoseRequest) (*agentv1.DiagnoseResponse, error) {
+	if req.AgentId == "" {
+		return &agentv1.DiagnoseResponse{
+			Checks: diagnose.ToProto("core", c.coreChecks()),
+			Time:   timestamppb.Now(),
+		}, nil
+	}
+
+	agent, ok := c.agents.get(req.AgentId)
+	var (
+		agentStatus AgentStatus
+		lastSeen    time.Time
+		address     string
+	)
+	if ok {
+		agent.mu.RLock()
+		agentStatus, lastSeen, address = agent.Status, agent.LastSeen, agent.Address
+		agent.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+
+	checks := []diagnose.Check{registrationCheck(agentStatus, lastSeen)}
+
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		checks = append(checks, diagnose.Fail("core→agent connection", err.Error(),
+			"Make sure the agent's listen address is reachable from the core host"))
+		return &agentv1.DiagnoseResponse{Checks: diagnose.ToProto("core", checks), Time: timestamppb.Now()}, nil
+	}
+
+	start := time.Now()
+	resp, err := agentv1.NewAgentServiceClient(conn.Client).Diagnose(ctx, &agentv1.DiagnoseRequest{AgentId: req.AgentId})
+	rtt := time.Since(start)
+
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		checks = append(checks, diagnose.Warn("core→agent connection", "agent does not support diagnostics",
+			"Upgrade mandau-agent to run agent-side checks"))
+	case err != nil:
+		checks = append(checks, diagnose.Fail("core→agent connection", fmt.Sprintf("%s: %v", address, err),
+			"Check that the agent listens on "+address+", that firewalls allow it and that both sides trust the same CA"))
+	default:
+		checks = append(checks, diagnose.OK("core→agent connection", fmt.Sprintf("%s, round trip %s", address, rtt.Round(time.Millisecond))))
+		// The agent read its clock roughly halfway through the round trip
+		skew := resp.Time.AsTime().Sub(start.Add(rtt / 2))
+		checks = append(checks, diagnose.ClockSkew("clock skew", skew))
+	}
+
+	result := &agentv1.DiagnoseResponse{
+		Checks: diagnose.ToProto("core", checks),
+		Time:   timestamppb.Now(),
+	}
+	if resp != nil {
+		result.Checks = append(result.Checks, resp.Checks...)
+	}
+	return result, nil
+}
+
+func (c *Core) coreChecks() []diagnose.Check {
+	checks := []diagnose.Check{
+		// Agents and the CLI dial the core with this server name
+		diagnose.ServerCertFile("core certificate", c.config.CertPath, "mandau-core"),
+		diagnose.CertFile("core CA", c.config.CAPath),
+	}
+
+	if len(c.listenAddrs) > 0 {
+		checks = append(checks, diagnose.OK("listeners", strings.Join(c.listenAddrs, ", ")))
+	}
+
+	var names []string
+	for _, p := range c.plugins.ListAll() {
+		names = append(names, p.Name()+"@"+p.Version())
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		checks = append(checks, diagnose.OK("plugins", "none loaded"))
+	} else {
+		checks = append(checks, diagnose.OK("plugins", strings.Join(names, ", ")))
+	}
+
+	var online, offline []string
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		if agent.Status == AgentStatusOnline {
+			online = append(online, agent.ID)
+		} else if !agent.inMaintenance(time.Now()) {
+			offline = append(offline, agent.ID)
+		}
+		agent.mu.RUnlock()
+	}
+
+	if len(offline) > 0 {
+		checks = append(checks, diagnose.Warn("agents",
+			fmt.Sprintf("%d online, offline: %s", len(online), strings.Join(offline, ", ")),
+			"Run \"mandau doctor <agent-id>\" for each offline agent"))
+	} else {
+		checks = append(checks, diagnose.OK("agents", fmt.Sprintf("%d online", len(online))))
+	}
+
+	return checks
+}
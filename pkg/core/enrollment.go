@@ -0,0 +1,317 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultEnrollmentCertTTL applies when enrollment.cert_ttl is not set
+const defaultEnrollmentCertTTL = 365 * 24 * time.Hour
+
+// agentServerName is the name the core expects in agent certificates
+const agentServerName = "mandau-agent"
+
+// errEnrollmentDenied hides which token check failed from the caller
+var errEnrollmentDenied = errors.New("invalid or expired enrollment token")
+
+// errAgentIDTaken is returned for agent IDs bound to another certificate
+var errAgentIDTaken = errors.New("agent ID is bound to another certificate")
+
+var agentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// Enroller issues agent certificates to hosts holding a join token
+type Enroller struct {
+	mu      sync.Mutex
+	caCert  *x509.Certificate
+	caPEM   []byte
+	caKey   crypto.Signer
+	certTTL time.Duration
+	tokens  []*enrollmentToken
+	// bind ties an agent ID to the certificate identity issued for it, and
+	// with it the agent role, before the certificate is signed; nil binds
+	// nothing
+	bind func(agentID, identity string) error
+}
+
+type enrollmentToken struct {
+	token     string
+	labels    map[string]string
+	expiresAt time.Time // Zero never expires
+	maxUses   int
+	uses      int
+}
+
+// newEnroller returns nil when enrollment is not configured
+func newEnroller(cfg config.EnrollmentConfig, caPath string) (*Enroller, error) {
+	if cfg.ListenAddr == "" {
+		return nil, nil
+	}
+
+	caPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("read CA: %w", err)
+	}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		return nil, fmt.Errorf("parse CA: no PEM data")
+	}
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA: %w", err)
+	}
+
+	caKey, err := loadSigner(cfg.CAKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("CA key: %w", err)
+	}
+
+	e := &Enroller{
+		caCert:  caCert,
+		caPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}),
+		caKey:   caKey,
+		certTTL: defaultEnrollmentCertTTL,
+	}
+	if cfg.CertTTL != "" {
+		if d, err := time.ParseDuration(cfg.CertTTL); err == nil && d > 0 {
+			e.certTTL = d
+		} else {
+			log.Printf("Invalid enrollment cert_ttl %q, using %s", cfg.CertTTL, e.certTTL)
+		}
+	}
+
+	for i, t := range cfg.Tokens {
+		if len(t.Token) < 16 {
+			return nil, fmt.Errorf("token %d: must be at least 16 characters", i)
+		}
+		token := &enrollmentToken{token: t.Token, labels: t.Labels, maxUses: t.MaxUses}
+		if t.ExpiresAt != "" {
+			if token.expiresAt, err = time.Parse(time.RFC3339, t.ExpiresAt); err != nil {
+				return nil, fmt.Errorf("token %d: expires_at: %w", i, err)
+			}
+		}
+		e.tokens = append(e.tokens, token)
+	}
+
+	return e, nil
+}
+
+func loadSigner(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key format in %s", path)
+}
+
+// CAHash is the pin new hosts pass to `mandau agent install --ca-hash`
+func (e *Enroller) CAHash() string {
+	return transport.CAHash(e.caCert)
+}
+
+// enroll checks the token and signs the CSR. A token use is only counted
+// for certificates actually issued.
+func (e *Enroller) enroll(req *agentv1.EnrollRequest, now time.Time) (*agentv1.EnrollResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Compare against every token so timing does not reveal a near match
+	var token *enrollmentToken
+	for _, t := range e.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.token), []byte(req.Token)) == 1 {
+			token = t
+		}
+	}
+	if token == nil || (!token.expiresAt.IsZero() && !now.Before(token.expiresAt)) ||
+		(token.maxUses > 0 && token.uses >= token.maxUses) {
+		return nil, errEnrollmentDenied
+	}
+
+	agentID := req.AgentId
+	if agentID == "" {
+		agentID = "agent-" + req.Hostname
+	}
+	if !agentIDPattern.MatchString(agentID) {
+		return nil, fmt.Errorf("invalid agent id %q", agentID)
+	}
+
+	block, _ := pem.Decode(req.CsrPem)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("csr_pem is not a PEM certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse csr: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("csr signature: %w", err)
+	}
+	switch csr.PublicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported csr key type %T", csr.PublicKey)
+	}
+
+	// The certificate names the agent, so its holder, and no other
+	// certificate, calls in the agent role as that agent
+	uri := &url.URL{Scheme: "mandau", Host: "agent", Path: "/" + agentID}
+	if e.bind != nil {
+		if err := e.bind(agentID, uri.String()); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAgentIDTaken, err)
+		}
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	// Agents serve the core and call it with the same certificate
+	dnsNames := []string{agentServerName}
+	if agentIDPattern.MatchString(req.Hostname) && req.Hostname != agentServerName {
+		dnsNames = append(dnsNames, req.Hostname)
+	}
+	expires := now.Add(e.certTTL)
+	if expires.After(e.caCert.NotAfter) {
+		expires = e.caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: agentServerName, Organization: []string{"Mandau"}, OrganizationalUnit: []string{profileAgent}},
+		DNSNames:     dnsNames,
+		URIs:         []*url.URL{uri},
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     expires,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, e.caCert, csr.PublicKey, e.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("sign certificate: %w", err)
+	}
+	token.uses++
+
+	return &agentv1.EnrollResponse{
+		AgentId:        agentID,
+		CertificatePem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		CaPem:          e.caPEM,
+		Labels:         token.labels,
+		ExpiresAt:      timestamppb.New(expires),
+	}, nil
+}
+
+// GetEnrollmentCA returns the CA new hosts pin before enrolling
+func (c *Core) GetEnrollmentCA(ctx context.Context, req *agentv1.GetEnrollmentCARequest) (*agentv1.GetEnrollmentCAResponse, error) {
+	if c.enroller == nil {
+		return nil, status.Error(codes.Unavailable, "enrollment is not enabled")
+	}
+	return &agentv1.GetEnrollmentCAResponse{CaPem: c.enroller.caPEM}, nil
+}
+
+// Enroll issues an agent certificate in exchange for a join token. The
+// certificate carries the agent profile and is bound to the agent ID, so
+// its holder calls the core in the agent role, as that agent, and nothing
+// more.
+func (c *Core) Enroll(ctx context.Context, req *agentv1.EnrollRequest) (*agentv1.EnrollResponse, error) {
+	if c.enroller == nil {
+		return nil, status.Error(codes.Unavailable, "enrollment is not enabled")
+	}
+
+	resp, err := c.enroller.enroll(req, time.Now())
+
+	entry := &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		Action:    agentv1.EnrollmentService_Enroll_FullMethodName,
+		Resource:  "agent:" + req.Hostname,
+		Result:    "success",
+		Metadata:  map[string]string{"hostname": req.Hostname},
+	}
+	if err != nil {
+		entry.Result = "denied"
+		entry.Metadata["auth_error"] = err.Error()
+		c.plugins.AuditAll(ctx, entry)
+		log.Printf("Enrollment of %s rejected: %v", req.Hostname, err)
+		if err == errEnrollmentDenied || errors.Is(err, errAgentIDTaken) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	entry.AgentID = resp.AgentId
+	entry.Metadata["role"] = agentRole
+	c.plugins.AuditAll(ctx, entry)
+	log.Printf("Enrolled agent %s (%s), certificate valid until %s", resp.AgentId, req.Hostname,
+		resp.ExpiresAt.AsTime().Format(time.RFC3339))
+	return resp, nil
+}
+
+// serveEnrollment starts the enrollment listener. It authenticates the core
+// to new hosts but asks them for no certificate, and serves nothing else.
+func (c *Core) serveEnrollment(cert tls.Certificate) (*grpc.Server, error) {
+	addr := c.config.FullConfig.Enrollment.ListenAddr
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("enrollment listen: %w", err)
+	}
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+	})))
+	agentv1.RegisterEnrollmentServiceServer(server, c)
+
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			log.Printf("Enrollment server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Enrollment listening on %s, CA hash %s", addr, c.enroller.CAHash())
+	return server, nil
+}
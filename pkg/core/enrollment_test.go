@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/plugins/auth/rbac"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeTestCA writes a self-signed CA and its key, returning their paths
+func writeTestCA(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Mandau Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	caPath, keyPath := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+	return caPath, keyPath
+}
+
+func testCSR(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestEnroll(t *testing.T) {
+	caPath, keyPath := writeTestCA(t)
+	now := time.Now()
+
+	e, err := newEnroller(config.EnrollmentConfig{
+		ListenAddr: ":0",
+		CAKeyPath:  keyPath,
+		CertTTL:    "1h",
+		Tokens: []config.EnrollmentToken{
+			{Token: "0123456789abcdef", Labels: map[string]string{"env": "prod"}, MaxUses: 1},
+			{Token: "expired-token-0000", ExpiresAt: now.Add(-time.Minute).Format(time.RFC3339)},
+		},
+	}, caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := e.enroll(&agentv1.EnrollRequest{Token: "0123456789abcdef", CsrPem: testCSR(t), Hostname: "web-1"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AgentId != "agent-web-1" || resp.Labels["env"] != "prod" {
+		t.Errorf("response = %v", resp)
+	}
+
+	block, _ := pem.Decode(resp.CertificatePem)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.VerifyHostname(agentServerName); err != nil {
+		t.Error(err)
+	}
+	if len(cert.URIs) != 1 || cert.URIs[0].String() != "mandau://agent/agent-web-1" {
+		t.Errorf("URIs = %v", cert.URIs)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(resp.CaPem)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("issued certificate does not verify: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *agentv1.EnrollRequest
+	}{
+		{name: "used up", req: &agentv1.EnrollRequest{Token: "0123456789abcdef", CsrPem: testCSR(t), Hostname: "web-2"}},
+		{name: "expired", req: &agentv1.EnrollRequest{Token: "expired-token-0000", CsrPem: testCSR(t), Hostname: "web-2"}},
+		{name: "unknown", req: &agentv1.EnrollRequest{Token: "not-a-token-00000", CsrPem: testCSR(t), Hostname: "web-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := e.enroll(tt.req, now); err != errEnrollmentDenied {
+				t.Errorf("enroll() error = %v, want %v", err, errEnrollmentDenied)
+			}
+		})
+	}
+}
+
+func TestEnrollBindsAgentRole(t *testing.T) {
+	caPath, keyPath := writeTestCA(t)
+	e, err := newEnroller(config.EnrollmentConfig{
+		ListenAddr: ":0",
+		CAKeyPath:  keyPath,
+		Tokens:     []config.EnrollmentToken{{Token: "0123456789abcdef", MaxUses: 1}},
+	}, caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := &AgentIdentities{bindings: map[string]string{"db-1": "cn:mandau-agent"}}
+	e.bind = func(agentID, identity string) error { return ids.check(agentID, identity, true) }
+
+	// An ID another certificate holds is not issued, and costs no token use
+	_, err = e.enroll(&agentv1.EnrollRequest{Token: "0123456789abcdef", CsrPem: testCSR(t), AgentId: "db-1"}, time.Now())
+	if !errors.Is(err, errAgentIDTaken) {
+		t.Fatalf("enrolling a bound ID: %v, want errAgentIDTaken", err)
+	}
+
+	resp, err := e.enroll(&agentv1.EnrollRequest{Token: "0123456789abcdef", CsrPem: testCSR(t), AgentId: "web-1"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids.bindings["web-1"]; got != "mandau://agent/web-1" {
+		t.Errorf("web-1 bound to %q at enrollment", got)
+	}
+
+	// The issued certificate calls in the agent role, which the RBAC plugin
+	// defines without being told to
+	block, _ := pem.Decode(resp.CertificatePem)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := rbac.New()
+	if err := auth.Init(context.Background(), map[string]interface{}{"roles": "roles: []\nusers: []\n"}); err != nil {
+		t.Fatal(err)
+	}
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(auth); err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{plugins: plugins, agentIdentities: ids}
+
+	var got *plugin.Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = plugin.IdentityFromContext(ctx)
+		return nil, nil
+	}
+	heartbeat := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_Heartbeat_FullMethodName}
+	if _, err := c.profileInterceptor(peerContext(cert), &agentv1.HeartbeatRequest{}, heartbeat, handler); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if len(got.Roles) != 1 || got.Roles[0] != agentRole {
+		t.Errorf("roles = %v, want %s", got.Roles, agentRole)
+	}
+	listAgents := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_ListAgents_FullMethodName}
+	if _, err := c.profileInterceptor(peerContext(cert), &agentv1.ListAgentsRequest{}, listAgents, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("listing agents: %v", err)
+	}
+}
+
+func TestEnrollRejectsBadRequests(t *testing.T) {
+	caPath, keyPath := writeTestCA(t)
+	e, err := newEnroller(config.EnrollmentConfig{
+		ListenAddr: ":0",
+		CAKeyPath:  keyPath,
+		Tokens:     []config.EnrollmentToken{{Token: "0123456789abcdef"}},
+	}, caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, req := range map[string]*agentv1.EnrollRequest{
+		"no csr": {Token: "0123456789abcdef", Hostname: "web-1"},
+		"bad id": {Token: "0123456789abcdef", CsrPem: testCSR(t), AgentId: "../etc"},
+	} {
+		if _, err := e.enroll(req, time.Now()); err == nil {
+			t.Errorf("%s: enroll() succeeded", name)
+		}
+	}
+}
+
+func TestNewEnrollerDisabled(t *testing.T) {
+	e, err := newEnroller(config.EnrollmentConfig{}, "")
+	if e != nil || err != nil {
+		t.Errorf("newEnroller() = %v, %v, want nil, nil", e, err)
+	}
+}
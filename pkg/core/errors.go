@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// errorInterceptor makes sure every error a caller gets carries an
+// ErrorDetail. Errors that were returned without one get the default for
+// their code, naming the agent of the request. Failures on the core's or an
+// agent's side are logged with the request ID.
+func (c *Core) errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		requestid.LogFailure(ctx, info.FullMethod, err)
+		agentID := agentHeader(ctx)
+		if m, ok := req.(proto.Message); ok && agentID == "" {
+			agentID = messageAgentID(m)
+		}
+		err = transport.WithDetail(err, agentID)
+	}
+	return resp, err
+}
+
+// errorStreamInterceptor is errorInterceptor for streaming calls. The agent
+// comes from the header or the first request the handler read.
+func (c *Core) errorStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	rs := &recordingStream{ServerStream: ss}
+	if err := handler(srv, rs); err != nil {
+		requestid.LogFailure(ss.Context(), info.FullMethod, err)
+		agentID := agentHeader(ss.Context())
+		if agentID == "" {
+			agentID = rs.agentID()
+		}
+		return transport.WithDetail(err, agentID)
+	}
+	return nil
+}
+
+// recordingStream keeps the first request received on a stream
+type recordingStream struct {
+	grpc.ServerStream
+	mu    sync.Mutex // Handlers may receive on another goroutine
+	first interface{}
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		if s.first == nil {
+			s.first = m
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// agentID returns the agent_id of the first request. Forwarded frames have
+// one only if the proxy decoded them.
+func (s *recordingStream) agentID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch m := s.first.(type) {
+	case *transport.Frame:
+		if m.Message != nil {
+			return messageAgentID(m.Message)
+		}
+	case proto.Message:
+		return messageAgentID(m)
+	}
+	return ""
+}
+
+// messageAgentID returns the agent_id field of m, if it has one
+func messageAgentID(m proto.Message) string {
+	msg := m.ProtoReflect()
+	field := msg.Descriptor().Fields().ByName("agent_id")
+	if field == nil || field.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return msg.Get(field).String()
+}
+
+func errAgentNotFound(agentID string) error {
+	return transport.Error(codes.NotFound, &agentv1.ErrorDetail{
+		Code:    agentv1.ErrorCode_ERROR_CODE_AGENT_NOT_FOUND,
+		AgentId: agentID,
+		Hint:    "mandau agent list shows the registered agents",
+	}, "agent not found: %s", agentID)
+}
+
+func errAgentOffline(agentID string) error {
+	return transport.Error(codes.Unavailable, &agentv1.ErrorDetail{
+		Code:      agentv1.ErrorCode_ERROR_CODE_AGENT_OFFLINE,
+		AgentId:   agentID,
+		Retryable: true,
+		Hint:      "check that the agent is running; stack changes can be queued with --queue",
+	}, "agent offline: %s", agentID)
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// Fleet-wide query defaults when fan_out is not configured
+const (
+	defaultFanOutParallelism  = 16
+	defaultFanOutAgentTimeout = 10 * time.Second
+)
+
+// fanOutLimits bound fleet-wide queries: how many agents are asked at once
+// and how long each may take
+type fanOutLimits struct {
+	parallelism  int
+	agentTimeout time.Duration
+}
+
+func newFanOutLimits(cfg config.FanOutConfig) fanOutLimits {
+	limits := fanOutLimits{
+		parallelism:  defaultFanOutParallelism,
+		agentTimeout: defaultFanOutAgentTimeout,
+	}
+	if cfg.Parallelism > 0 {
+		limits.parallelism = cfg.Parallelism
+	}
+	if cfg.AgentTimeout != "" {
+		if d, err := time.ParseDuration(cfg.AgentTimeout); err == nil && d > 0 {
+			limits.agentTimeout = d
+		} else {
+			log.Printf("Invalid fan_out agent_timeout %q, using %s", cfg.AgentTimeout, limits.agentTimeout)
+		}
+	}
+	return limits
+}
+
+// fanOutResult is one agent's answer to a fleet-wide query
+type fanOutResult[T any] struct {
+	conn  *AgentConnection
+	value T
+	err   error
+}
+
+// fanOut calls query for every agent, at most limits.parallelism at a time
+// and each under limits.agentTimeout. Results keep the order of conns; a
+// failing agent only fails its own result, so callers get partial results
+// and can report which agents did not answer.
+func fanOut[T any](ctx context.Context, limits fanOutLimits, conns []*AgentConnection,
+	query func(ctx context.Context, conn *AgentConnection) (T, error)) []fanOutResult[T] {
+
+	if limits.parallelism <= 0 || limits.agentTimeout <= 0 {
+		limits = newFanOutLimits(config.FanOutConfig{})
+	}
+
+	results := make([]fanOutResult[T], len(conns))
+	sem := make(chan struct{}, limits.parallelism)
+	var wg sync.WaitGroup
+
+	for i, conn := range conns {
+		results[i].conn = conn
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *fanOutResult[T]) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			agentCtx, cancel := context.WithTimeout(ctx, limits.agentTimeout)
+			defer cancel()
+			r.value, r.err = query(agentCtx, r.conn)
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestFanOut(t *testing.T) {
+	conns := []*AgentConnection{{ID: "a1"}, {ID: "a2"}, {ID: "a3"}, {ID: "a4"}}
+	limits := fanOutLimits{parallelism: 2, agentTimeout: 50 * time.Millisecond}
+
+	var running, peak atomic.Int32
+	results := fanOut(context.Background(), limits, conns, func(ctx context.Context, conn *AgentConnection) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		switch conn.ID {
+		case "a2":
+			return "", errors.New("refused")
+		case "a3":
+			// Hangs until its own deadline
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "ok-" + conn.ID, nil
+	})
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d queries ran at once, limit 2", p)
+	}
+
+	want := []struct {
+		id    string
+		value string
+		err   bool
+	}{{"a1", "ok-a1", false}, {"a2", "", true}, {"a3", "", true}, {"a4", "ok-a4", false}}
+	for i, w := range want {
+		r := results[i]
+		if r.conn.ID != w.id || r.value != w.value || (r.err != nil) != w.err {
+			t.Errorf("result %d = %s %q %v, want %s %q err=%v", i, r.conn.ID, r.value, r.err, w.id, w.value, w.err)
+		}
+	}
+	if !errors.Is(results[2].err, context.DeadlineExceeded) {
+		t.Errorf("slow agent error = %v, want deadline exceeded", results[2].err)
+	}
+}
+
+func TestNewFanOutLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.FanOutConfig
+		want fanOutLimits
+	}{
+		{"defaults", config.FanOutConfig{}, fanOutLimits{defaultFanOutParallelism, defaultFanOutAgentTimeout}},
+		{"configured", config.FanOutConfig{Parallelism: 4, AgentTimeout: "3s"}, fanOutLimits{4, 3 * time.Second}},
+		{"invalid timeout", config.FanOutConfig{AgentTimeout: "soon"}, fanOutLimits{defaultFanOutParallelism, defaultFanOutAgentTimeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newFanOutLimits(tt.cfg); got != tt.want {
+				t.Errorf("newFanOutLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultFleetCommandTimeout bounds each agent's run when the request
+	// sets no timeout, and maxFleetCommandTimeout any request
+	defaultFleetCommandTimeout = time.Minute
+	maxFleetCommandTimeout     = time.Hour
+	// fleetCommandSlack is the time past the command's timeout the core
+	// waits for an agent to report it
+	fleetCommandSlack = 10 * time.Second
+)
+
+// RunFleetCommand runs a command from the agents' commands allowlist on
+// every selected agent, at most fan_out.parallelism at a time. Callers need
+// "write" on "host:commands" for each agent. Agents they may not run it on,
+// that do not serve commands, cannot be reached or refuse the command are
+// reported in agent_errors; a command that exits non-zero is a result.
+func (c *Core) RunFleetCommand(ctx context.Context, req *agentv1.RunFleetCommandRequest) (*agentv1.FleetCommandReport, error) {
+	if req.Command == "" {
+		return nil, status.Error(codes.InvalidArgument, "command is required")
+	}
+	if len(req.Labels) == 0 && req.Group == "" && len(req.AgentIds) == 0 && !req.All {
+		return nil, status.Error(codes.InvalidArgument, "select agents by labels, group or agent IDs, or set all")
+	}
+	if req.Parallelism < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parallelism %d", req.Parallelism)
+	}
+	timeout := defaultFleetCommandTimeout
+	if req.Timeout != nil {
+		timeout = req.Timeout.AsDuration()
+		if timeout <= 0 || timeout > maxFleetCommandTimeout {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timeout %s: want up to %s", timeout, maxFleetCommandTimeout)
+		}
+	}
+
+	selected, err := c.selectAgents(req.Labels, req.Group, req.AgentIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, status.Error(codes.NotFound, "no agents match the selector")
+	}
+
+	start := time.Now()
+	report := &agentv1.FleetCommandReport{Command: req.Command, StartedAt: timestamppb.New(start)}
+	agentError := func(id string, err error) {
+		if report.AgentErrors == nil {
+			report.AgentErrors = make(map[string]string)
+		}
+		report.AgentErrors[id] = status.Convert(err).Message()
+	}
+
+	var targets []*AgentConnection
+	for _, agent := range selected {
+		err := c.authorizeAgent(ctx, agent, "write", "host:"+capability.Commands)
+		if err == nil {
+			err = requireAgentCapability(agent, capability.Commands, agentv1.CommandService_RunCommand_FullMethodName)
+		}
+		if err != nil {
+			agentError(agent.ID, err)
+			continue
+		}
+		conn, err := c.getAgentConnection(agent.ID)
+		if err != nil {
+			agentError(agent.ID, err)
+			continue
+		}
+		targets = append(targets, conn)
+	}
+
+	limits := fanOutLimits{parallelism: c.fanOut.parallelism, agentTimeout: timeout + fleetCommandSlack}
+	if req.Parallelism > 0 && int(req.Parallelism) < limits.parallelism {
+		limits.parallelism = int(req.Parallelism)
+	}
+	results := fanOut(ctx, limits, targets, func(ctx context.Context, conn *AgentConnection) (*agentv1.CommandResult, error) {
+		return agentv1.NewCommandServiceClient(conn.Client).RunCommand(ctx, &agentv1.RunCommandRequest{
+			AgentId: conn.ID,
+			Name:    req.Command,
+			Timeout: durationpb.New(timeout),
+		})
+	})
+
+	identity := &plugin.Identity{}
+	if caller := plugin.IdentityFromContext(ctx); caller != nil {
+		identity = caller
+	}
+	for _, r := range results {
+		entry := &plugin.AuditEntry{
+			Timestamp: start,
+			AgentID:   r.conn.ID,
+			Identity:  identity,
+			Action:    "command.run",
+			Resource:  "agent:" + r.conn.ID,
+			Result:    "error",
+			Metadata:  map[string]string{"command": req.Command},
+		}
+		if r.err != nil {
+			agentError(r.conn.ID, r.err)
+		} else {
+			r.value.AgentId = r.conn.ID
+			report.Results = append(report.Results, r.value)
+			entry.Duration = r.value.Duration.AsDuration()
+			entry.Metadata["exit_code"] = strconv.Itoa(int(r.value.ExitCode))
+			if r.value.ExitCode == 0 {
+				entry.Result = "success"
+			}
+		}
+		c.plugins.AuditAll(ctx, entry)
+	}
+
+	report.Duration = durationpb.New(time.Since(start))
+	return report, nil
+}
+
+// selectAgents returns the registered agents matching all of the labels,
+// group and IDs given, by ID. Unknown groups and agent IDs are errors.
+func (c *Core) selectAgents(labels map[string]string, group string, ids []string) ([]*AgentConnection, error) {
+	var g *AgentGroup
+	if group != "" {
+		var ok bool
+		if g, ok = c.groups.get(group); !ok {
+			return nil, status.Errorf(codes.NotFound, "group not found: %s", group)
+		}
+	}
+	var only map[string]bool
+	if len(ids) > 0 {
+		only = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if _, ok := c.agents.get(id); !ok {
+				return nil, errAgentNotFound(id)
+			}
+			only[id] = true
+		}
+	}
+
+	var selected []*AgentConnection
+	for _, agent := range c.agents.all() {
+		if only != nil && !only[agent.ID] {
+			continue
+		}
+		agent.mu.RLock()
+		if matchLabels(agent.Labels, labels) && (g == nil || g.contains(agent)) {
+			selected = append(selected, agent)
+		}
+		agent.mu.RUnlock()
+	}
+	return selected, nil
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// commandAgent runs "uptime" and nothing else
+type commandAgent struct {
+	agentv1.UnimplementedCommandServiceServer
+	exitCode int32
+	timeouts chan time.Duration
+}
+
+func (a *commandAgent) RunCommand(ctx context.Context, req *agentv1.RunCommandRequest) (*agentv1.CommandResult, error) {
+	a.timeouts <- req.Timeout.AsDuration()
+	if req.Name != "uptime" {
+		return nil, status.Errorf(codes.NotFound, "%s: not in commands", req.Name)
+	}
+	return &agentv1.CommandResult{ExitCode: a.exitCode, Stdout: []byte("up 3 days\n"), Duration: durationpb.New(time.Second)}, nil
+}
+
+func TestRunFleetCommand(t *testing.T) {
+	timeouts := make(chan time.Duration, 10)
+	serveCommands := func(exitCode int32) *grpc.ClientConn {
+		server := grpc.NewServer()
+		agentv1.RegisterCommandServiceServer(server, &commandAgent{exitCode: exitCode, timeouts: timeouts})
+		return serve(t, server)
+	}
+	web := map[string]string{"role": "web"}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Labels: web, Capabilities: []string{"docker", "commands"}, Client: serveCommands(0), Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-2", Labels: web, Capabilities: []string{"docker", "commands"}, Client: serveCommands(2), Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-3", Labels: web, Capabilities: []string{"docker"}, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-4", Labels: web, Capabilities: []string{"docker", "commands"}, Status: AgentStatusOffline, LastSeen: time.Now().Add(-time.Hour)},
+			&AgentConnection{ID: "db-1", Labels: map[string]string{"role": "db"}, Capabilities: []string{"docker", "commands"}, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		plugins: plugin.NewRegistry(),
+		fanOut:  newFanOutLimits(config.FanOutConfig{}),
+	}
+	ctx := context.Background()
+
+	report, err := c.RunFleetCommand(ctx, &agentv1.RunFleetCommandRequest{
+		Command: "uptime",
+		Labels:  web,
+		Timeout: durationpb.New(30 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || report.Results[0].AgentId != "web-1" || report.Results[0].ExitCode != 0 ||
+		report.Results[1].AgentId != "web-2" || report.Results[1].ExitCode != 2 {
+		t.Errorf("results = %v, want web-1 exiting 0 and web-2 exiting 2", report.Results)
+	}
+	if len(report.AgentErrors) != 2 || report.AgentErrors["web-3"] == "" || report.AgentErrors["web-4"] == "" {
+		t.Errorf("agent errors = %v, want web-3 without commands and web-4 offline", report.AgentErrors)
+	}
+	if got := <-timeouts; got != 30*time.Second {
+		t.Errorf("agents were given %s, want the requested 30s", got)
+	}
+
+	// A command an agent does not allow fails on that agent alone
+	report, err = c.RunFleetCommand(ctx, &agentv1.RunFleetCommandRequest{Command: "reboot", AgentIds: []string{"web-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 0 || report.AgentErrors["web-1"] != "reboot: not in commands" {
+		t.Errorf("unknown command: %v %v", report.Results, report.AgentErrors)
+	}
+
+	for _, bad := range []*agentv1.RunFleetCommandRequest{
+		{Labels: web},
+		{Command: "uptime"},
+		{Command: "uptime", All: true, Timeout: durationpb.New(2 * time.Hour)},
+		{Command: "uptime", All: true, Parallelism: -1},
+	} {
+		if _, err := c.RunFleetCommand(ctx, bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("RunFleetCommand(%v) error = %v, want invalid argument", bad, err)
+		}
+	}
+	if _, err := c.RunFleetCommand(ctx, &agentv1.RunFleetCommandRequest{Command: "uptime", AgentIds: []string{"gone"}}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: error = %v, want not found", err)
+	}
+	if _, err := c.RunFleetCommand(ctx, &agentv1.RunFleetCommandRequest{Command: "uptime", Labels: map[string]string{"role": "cache"}}); status.Code(err) != codes.NotFound {
+		t.Errorf("empty selection: error = %v, want not found", err)
+	}
+}
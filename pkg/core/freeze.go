@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// frozenMethods are the calls a cluster freeze rejects. Reads, agent
+// traffic, approvals, break-glass, cancelling queued changes and the freeze
+// itself stay available so an incident can be handled and the freeze lifted.
+var frozenMethods = map[string]bool{
+	agentv1.StackService_ApplyStack_FullMethodName:           true,
+	agentv1.StackService_RemoveStack_FullMethodName:          true,
+	agentv1.StackService_UploadStackFiles_FullMethodName:     true,
+	agentv1.TransferService_Upload_FullMethodName:            true,
+	agentv1.TransferService_Fetch_FullMethodName:             true,
+	agentv1.CoreService_UpdateAgentLabels_FullMethodName:     true,
+	agentv1.CoreService_SetAgentMaintenance_FullMethodName:   true,
+	agentv1.CoreService_QueueAgentInstruction_FullMethodName: true,
+	agentv1.CoreService_CreateAgentGroup_FullMethodName:      true,
+	agentv1.CoreService_UpdateAgentGroup_FullMethodName:      true,
+	agentv1.CoreService_DeleteAgentGroup_FullMethodName:      true,
+	agentv1.CoreService_InstallPlugin_FullMethodName:         true,
+	agentv1.CoreService_RunFleetCommand_FullMethodName:       true,
+}
+
+// Freeze is the cluster freeze switch, kept in file so a freeze survives
+// core restarts
+type Freeze struct {
+	mu    sync.RWMutex
+	state FreezeState
+	file  string
+}
+
+// FreezeState records who last froze or unfroze the cluster and why
+type FreezeState struct {
+	Frozen bool      `json:"frozen"`
+	Reason string    `json:"reason,omitempty"`
+	SetBy  string    `json:"set_by,omitempty"`
+	SetAt  time.Time `json:"set_at,omitempty"`
+}
+
+func newFreeze(file string) (*Freeze, error) {
+	f := &Freeze{file: file}
+	if file == "" {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read freeze file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f.state); err != nil {
+			return nil, fmt.Errorf("parse freeze file: %w", err)
+		}
+	}
+	if f.state.Frozen {
+		log.Printf("Cluster is frozen since %s by %s: %s",
+			f.state.SetAt.Format(time.RFC3339), f.state.SetBy, f.state.Reason)
+	}
+	return f, nil
+}
+
+func (f *Freeze) get() FreezeState {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.state
+}
+
+// set switches the freeze and writes it to the freeze file, if any
+func (f *Freeze) set(state FreezeState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file != "" {
+		data, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return err
+		}
+		tmp := f.file + ".tmp"
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, f.file); err != nil {
+			return err
+		}
+	}
+	f.state = state
+	return nil
+}
+
+// freezeInterceptor rejects mutating calls while the cluster is frozen
+func (c *Core) freezeInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := c.checkFreeze(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// freezeStreamInterceptor is freezeInterceptor for streaming calls
+func (c *Core) freezeStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.checkFreeze(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// checkFreeze lets method through unless the cluster is frozen and it
+// changes something. Break-glass identities are exempt.
+func (c *Core) checkFreeze(ctx context.Context, method string) error {
+	if !frozenMethods[method] {
+		return nil
+	}
+	return c.requireUnfrozen(ctx)
+}
+
+// requireUnfrozen rejects a change while the cluster is frozen, except from
+// break-glass identities
+func (c *Core) requireUnfrozen(ctx context.Context) error {
+	state := c.freeze.get()
+	if !state.Frozen {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+	if identity.Attributes[breakGlassAttribute] != "" {
+		return nil
+	}
+
+	return transport.Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+		Code:     agentv1.ErrorCode_ERROR_CODE_FROZEN,
+		Resource: "cluster",
+		Hint:     "wait for mandau freeze off, or request break-glass access",
+	}, "cluster is frozen since %s by %s: %s (break-glass access is required for changes)",
+		state.SetAt.Format(time.RFC3339), state.SetBy, state.Reason)
+}
+
+// SetFreeze freezes or unfreezes the cluster. The caller needs the "freeze"
+// action on "cluster".
+func (c *Core) SetFreeze(ctx context.Context, req *agentv1.SetFreezeRequest) (*agentv1.FreezeState, error) {
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	if req.Frozen && strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Error(codes.InvalidArgument, "a reason is required to freeze the cluster")
+	}
+
+	if auth := c.plugins.Auth(); auth != nil {
+		if err := auth.Authorize(ctx, identity, &plugin.Action{
+			Action:   "freeze",
+			Resource: "cluster",
+		}); err != nil {
+			return nil, status.Errorf(codes.PermissionDenied, "%s may not freeze the cluster: %v", identity.UserID, err)
+		}
+	}
+
+	state := FreezeState{
+		Frozen: req.Frozen,
+		Reason: req.Reason,
+		SetBy:  identity.UserID,
+		SetAt:  time.Now(),
+	}
+	if err := c.freeze.set(state); err != nil {
+		return nil, status.Errorf(codes.Internal, "save freeze: %v", err)
+	}
+
+	result := "off"
+	if state.Frozen {
+		result = "on"
+	}
+	log.Printf("FREEZE %s by %s reason=%q", result, state.SetBy, state.Reason)
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: state.SetAt,
+		Identity:  identity,
+		Action:    "freeze." + result,
+		Resource:  "cluster",
+		Result:    "success",
+		Metadata:  map[string]string{"reason": state.Reason},
+	})
+
+	return toProtoFreeze(state), nil
+}
+
+// GetFreeze returns the freeze state
+func (c *Core) GetFreeze(ctx context.Context, req *agentv1.GetFreezeRequest) (*agentv1.FreezeState, error) {
+	return toProtoFreeze(c.freeze.get()), nil
+}
+
+func toProtoFreeze(state FreezeState) *agentv1.FreezeState {
+	pb := &agentv1.FreezeState{
+		Frozen: state.Frozen,
+		Reason: state.Reason,
+		SetBy:  state.SetBy,
+	}
+	if !state.SetAt.IsZero() {
+		pb.SetAt = timestamppb.New(state.SetAt)
+	}
+	return pb
+}
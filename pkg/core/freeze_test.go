@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFreezePersists(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "freeze.json")
+
+	f, err := newFreeze(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FreezeState{Frozen: true, Reason: "incident", SetBy: "ops", SetAt: time.Now().UTC().Truncate(time.Second)}
+	if err := f.set(want); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := newFreeze(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.get(); got != want {
+		t.Errorf("reloaded state = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckFreeze(t *testing.T) {
+	frozen := &Freeze{state: FreezeState{Frozen: true, Reason: "change freeze", SetBy: "ops"}}
+	user := &plugin.Identity{UserID: "dev"}
+	elevated := &plugin.Identity{UserID: "dev", Attributes: map[string]string{breakGlassAttribute: "grant-1"}}
+
+	tests := []struct {
+		name     string
+		freeze   *Freeze
+		identity *plugin.Identity
+		method   string
+		want     codes.Code
+	}{
+		{"not frozen", &Freeze{}, user, agentv1.StackService_ApplyStack_FullMethodName, codes.OK},
+		{"mutation rejected", frozen, user, agentv1.StackService_ApplyStack_FullMethodName, codes.FailedPrecondition},
+		{"unary mutation rejected", frozen, user, agentv1.CoreService_UpdateAgentLabels_FullMethodName, codes.FailedPrecondition},
+		{"read allowed", frozen, user, agentv1.StackService_ListStacks_FullMethodName, codes.OK},
+		{"unfreeze allowed", frozen, user, agentv1.CoreService_SetFreeze_FullMethodName, codes.OK},
+		{"break-glass allowed", frozen, elevated, agentv1.StackService_RemoveStack_FullMethodName, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Core{freeze: tt.freeze, plugins: plugin.NewRegistry()}
+			ctx := plugin.WithIdentity(context.Background(), tt.identity)
+
+			if got := status.Code(c.checkFreeze(ctx, tt.method)); got != tt.want {
+				t.Errorf("checkFreeze() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// garbageAgent reports one orphan and counts the collections it ran
+type garbageAgent struct {
+	agentv1.UnimplementedStackServiceServer
+	collected int
+}
+
+func (a *garbageAgent) CollectStackGarbage(ctx context.Context, req *agentv1.CollectStackGarbageRequest) (*agentv1.CollectStackGarbageResponse, error) {
+	if req.Apply {
+		a.collected++
+	}
+	return &agentv1.CollectStackGarbageResponse{Orphans: []*agentv1.StackOrphan{{}}, Applied: req.Apply}, nil
+}
+
+func TestFreezeStopsGarbageCollection(t *testing.T) {
+	server := grpc.NewServer()
+	agent := &garbageAgent{}
+	agentv1.RegisterStackServiceServer(server, agent)
+	client := serve(t, server)
+
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"dev": {"read stack:*", "delete stack:*"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := newGroupRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: []string{"stack"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		groups:   groups,
+		breakers: newCircuitBreakers(config.CircuitBreakerConfig{}),
+		plugins:  plugins,
+		freeze:   &Freeze{state: FreezeState{Frozen: true, Reason: "incident", SetBy: "ops"}},
+	}
+	dev := plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: "dev"})
+	elevated := plugin.WithIdentity(context.Background(), &plugin.Identity{
+		UserID:     "dev",
+		Attributes: map[string]string{breakGlassAttribute: "grant-1"},
+	})
+
+	// Reporting what would be collected is a read
+	resp, err := c.CollectStackGarbage(dev, &agentv1.CollectStackGarbageRequest{AgentId: "web-1"})
+	if err != nil {
+		t.Fatalf("dry run while frozen: %v", err)
+	}
+	if len(resp.Orphans) != 1 || resp.Applied {
+		t.Errorf("dry run = %v, want the agent's report", resp)
+	}
+
+	// Collecting is refused before the agent is asked
+	_, err = c.CollectStackGarbage(dev, &agentv1.CollectStackGarbageRequest{AgentId: "web-1", Apply: true})
+	if status.Code(err) != codes.FailedPrecondition || transport.Detail(err).Code != agentv1.ErrorCode_ERROR_CODE_FROZEN {
+		t.Errorf("collecting while frozen: %v, want the freeze", err)
+	}
+	if agent.collected != 0 {
+		t.Errorf("agent collected %d times during the freeze", agent.collected)
+	}
+
+	// Break-glass access and lifting the freeze let it through
+	if _, err := c.CollectStackGarbage(elevated, &agentv1.CollectStackGarbageRequest{AgentId: "web-1", Apply: true}); err != nil {
+		t.Errorf("collecting with break-glass access: %v", err)
+	}
+	c.freeze = &Freeze{}
+	if _, err := c.CollectStackGarbage(dev, &agentv1.CollectStackGarbageRequest{AgentId: "web-1", Apply: true}); err != nil {
+		t.Errorf("collecting after the freeze: %v", err)
+	}
+	if agent.collected != 2 {
+		t.Errorf("agent collected %d times, want 2", agent.collected)
+	}
+}
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GroupRegistry holds the named agent groups known to the core. Groups from
+// the core config are read-only; groups managed through the API are kept in
+// file so they survive restarts.
+// Lock ordering: an agent's lock may be held while taking this one, never
+// the other way round.
+type GroupRegistry struct {
+	mu     sync.RWMutex
+	groups map[string]*AgentGroup
+	file   string
+}
+
+// AgentGroup targets agents by explicit membership and/or a label selector
+type AgentGroup struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Agents      []string          `json:"agents,omitempty"` // Agent IDs
+	Selector    map[string]string `json:"selector,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	FromConfig  bool              `json:"-"`
+}
+
+func newGroupRegistry(cfg []config.AgentGroupConfig, file string) (*GroupRegistry, error) {
+	r := &GroupRegistry{groups: make(map[string]*AgentGroup), file: file}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read groups file: %w", err)
+		}
+		if len(data) > 0 {
+			var stored []*AgentGroup
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return nil, fmt.Errorf("parse groups file: %w", err)
+			}
+			for _, g := range stored {
+				r.groups[g.Name] = g
+			}
+		}
+	}
+
+	for _, g := range cfg {
+		if _, exists := r.groups[g.Name]; exists {
+			log.Printf("Group %s is defined in config; ignoring the stored copy", g.Name)
+		}
+		r.groups[g.Name] = &AgentGroup{
+			Name:        g.Name,
+			Description: g.Description,
+			Agents:      g.Agents,
+			Selector:    g.Selector,
+			CreatedAt:   time.Now(),
+			FromConfig:  true,
+		}
+	}
+	return r, nil
+}
+
+// contains reports whether agent is a static member or matches the selector.
+// Static members are matched by ID only since hostnames are self-reported.
+func (g *AgentGroup) contains(agent *AgentConnection) bool {
+	for _, member := range g.Agents {
+		if member == agent.ID {
+			return true
+		}
+	}
+	return len(g.Selector) > 0 && matchLabels(agent.Labels, g.Selector)
+}
+
+// save writes the API-managed groups to the groups file.
+// Callers must hold the write lock.
+func (r *GroupRegistry) save() error {
+	stored := make([]*AgentGroup, 0, len(r.groups))
+	for _, g := range r.groups {
+		if !g.FromConfig {
+			stored = append(stored, g)
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := r.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.file)
+}
+
+// requireManaged rejects changes that could not be persisted or that target
+// a group owned by the config file
+func (r *GroupRegistry) requireManaged(group *AgentGroup) error {
+	if r.file == "" {
+		return status.Error(codes.FailedPrecondition,
+			"groups can only be defined in the core config; set groups_file to manage them through the API")
+	}
+	if group != nil && group.FromConfig {
+		return status.Errorf(codes.FailedPrecondition, "group %s is defined in the core config", group.Name)
+	}
+	return nil
+}
+
+// authorizeGroup checks that the caller may perform action on the named group
+func (c *Core) authorizeGroup(ctx context.Context, action, name string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	if err := auth.Authorize(ctx, identity, &plugin.Action{
+		Action:   action,
+		Resource: "group:" + name,
+	}); err != nil {
+		return status.Errorf(codes.PermissionDenied, "%s may not %s group %s", identity.UserID, action, name)
+	}
+	return nil
+}
+
+// get returns a copy of the named group
+func (r *GroupRegistry) get(name string) (*AgentGroup, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	g, ok := r.groups[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *g
+	return &copied, true
+}
+
+// groupsFor returns the sorted names of all groups containing agent
+func (r *GroupRegistry) groupsFor(agent *AgentConnection) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for name, g := range r.groups {
+		if g.contains(agent) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// agentGroups is groupsFor for callers that do not hold the agent's lock
+func (c *Core) agentGroups(agent *AgentConnection) []string {
+	agent.mu.RLock()
+	defer agent.mu.RUnlock()
+	return c.groups.groupsFor(agent)
+}
+
+// CreateAgentGroup defines a new agent group
+func (c *Core) CreateAgentGroup(ctx context.Context, req *agentv1.CreateAgentGroupRequest) (*agentv1.AgentGroup, error) {
+	if req.Group == nil || req.Group.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "group name is required")
+	}
+
+	if err := c.authorizeGroup(ctx, "write", req.Group.Name); err != nil {
+		return nil, err
+	}
+
+	c.groups.mu.Lock()
+	defer c.groups.mu.Unlock()
+
+	if err := c.groups.requireManaged(nil); err != nil {
+		return nil, err
+	}
+	if _, exists := c.groups.groups[req.Group.Name]; exists {
+		return nil, status.Errorf(codes.AlreadyExists, "group already exists: %s", req.Group.Name)
+	}
+
+	group := &AgentGroup{
+		Name:        req.Group.Name,
+		Description: req.Group.Description,
+		Agents:      req.Group.Agents,
+		Selector:    req.Group.Selector,
+		CreatedAt:   time.Now(),
+	}
+	c.groups.groups[group.Name] = group
+
+	if err := c.groups.save(); err != nil {
+		delete(c.groups.groups, group.Name)
+		return nil, status.Errorf(codes.Internal, "save groups: %v", err)
+	}
+
+	return toProtoGroup(group), nil
+}
+
+// GetAgentGroup returns a group together with its current members
+func (c *Core) GetAgentGroup(ctx context.Context, req *agentv1.GetAgentGroupRequest) (*agentv1.GetAgentGroupResponse, error) {
+	group, ok := c.groups.get(req.Name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "group not found: %s", req.Name)
+	}
+
+	members := make([]*agentv1.Agent, 0)
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		if group.contains(agent) {
+			members = append(members, c.toProtoAgent(agent))
+		}
+		agent.mu.RUnlock()
+	}
+
+	return &agentv1.GetAgentGroupResponse{
+		Group:   toProtoGroup(group),
+		Members: members,
+	}, nil
+}
+
+// ListAgentGroups returns all groups sorted by name
+func (c *Core) ListAgentGroups(ctx context.Context, req *agentv1.ListAgentGroupsRequest) (*agentv1.ListAgentGroupsResponse, error) {
+	c.groups.mu.RLock()
+	defer c.groups.mu.RUnlock()
+
+	groups := make([]*agentv1.AgentGroup, 0, len(c.groups.groups))
+	for _, group := range c.groups.groups {
+		groups = append(groups, toProtoGroup(group))
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	return &agentv1.ListAgentGroupsResponse{Groups: groups}, nil
+}
+
+// UpdateAgentGroup changes the description, membership or selector of a group
+func (c *Core) UpdateAgentGroup(ctx context.Context, req *agentv1.UpdateAgentGroupRequest) (*agentv1.AgentGroup, error) {
+	if err := c.authorizeGroup(ctx, "write", req.Name); err != nil {
+		return nil, err
+	}
+
+	c.groups.mu.Lock()
+	defer c.groups.mu.Unlock()
+
+	existing, exists := c.groups.groups[req.Name]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "group not found: %s", req.Name)
+	}
+	if err := c.groups.requireManaged(existing); err != nil {
+		return nil, err
+	}
+
+	// Work on a copy so readers holding the old group never see a partial update
+	group := *existing
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	agents := make([]string, 0, len(group.Agents)+len(req.AddAgents))
+	for _, member := range group.Agents {
+		if !containsString(req.RemoveAgents, member) {
+			agents = append(agents, member)
+		}
+	}
+	for _, member := range req.AddAgents {
+		if !containsString(agents, member) {
+			agents = append(agents, member)
+		}
+	}
+	group.Agents = agents
+
+	if req.ReplaceSelector {
+		group.Selector = req.Selector
+	} else if len(req.Selector) > 0 {
+		group.Selector = mergeLabels(group.Selector, req.Selector)
+	}
+
+	c.groups.groups[req.Name] = &group
+	if err := c.groups.save(); err != nil {
+		c.groups.groups[req.Name] = existing
+		return nil, status.Errorf(codes.Internal, "save groups: %v", err)
+	}
+
+	return toProtoGroup(&group), nil
+}
+
+// DeleteAgentGroup removes a group; member agents are left untouched
+func (c *Core) DeleteAgentGroup(ctx context.Context, req *agentv1.DeleteAgentGroupRequest) (*agentv1.DeleteAgentGroupResponse, error) {
+	if err := c.authorizeGroup(ctx, "delete", req.Name); err != nil {
+		return nil, err
+	}
+
+	c.groups.mu.Lock()
+	defer c.groups.mu.Unlock()
+
+	group, exists := c.groups.groups[req.Name]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "group not found: %s", req.Name)
+	}
+	if err := c.groups.requireManaged(group); err != nil {
+		return nil, err
+	}
+
+	delete(c.groups.groups, req.Name)
+	if err := c.groups.save(); err != nil {
+		c.groups.groups[req.Name] = group
+		return nil, status.Errorf(codes.Internal, "save groups: %v", err)
+	}
+
+	return &agentv1.DeleteAgentGroupResponse{}, nil
+}
+
+// authorizeAgent checks that the caller may perform action on resource hosted
+// by conn. Permissions can be granted globally ("stack:*"), per agent
+// ("agent:<id>/stack:*") or per group ("group:<name>/stack:*").
+func (c *Core) authorizeAgent(ctx context.Context, conn *AgentConnection, action, resource string) error {
+	return c.authorizeNamespaced(ctx, conn, action, "", resource)
+}
+
+// authorizeNamespaced is authorizeAgent for resources that live in a
+// namespace; grants scoped to it ("namespace:<ns>/stack:*", optionally
+// behind an agent or group prefix) apply in addition to cluster-wide ones.
+func (c *Core) authorizeNamespaced(ctx context.Context, conn *AgentConnection, action, namespace, resource string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	bases := []string{resource}
+	if namespace != "" {
+		bases = append(bases, "namespace:"+namespace+"/"+resource)
+	}
+
+	groups := c.agentGroups(conn)
+	var scopes []string
+	for _, base := range bases {
+		scopes = append(scopes, base, "agent:"+conn.ID+"/"+base)
+		for _, group := range groups {
+			scopes = append(scopes, "group:"+group+"/"+base)
+		}
+	}
+
+	for _, scope := range scopes {
+		err := auth.Authorize(ctx, identity, &plugin.Action{
+			Action:   action,
+			Resource: scope,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	if namespace != "" {
+		return status.Errorf(codes.PermissionDenied, "%s may not %s %s in namespace %s on agent %s",
+			identity.UserID, action, resource, namespace, conn.ID)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s may not %s %s on agent %s",
+		identity.UserID, action, resource, conn.ID)
+}
+
+func toProtoGroup(group *AgentGroup) *agentv1.AgentGroup {
+	return &agentv1.AgentGroup{
+		Name:        group.Name,
+		Description: group.Description,
+		Agents:      group.Agents,
+		Selector:    group.Selector,
+		CreatedAt:   timestamppb.New(group.CreatedAt),
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// configGroups is the core config's one group, prod
+var configGroups = []config.AgentGroupConfig{{Name: "prod", Selector: map[string]string{"env": "prod"}}}
+
+// groupCore returns a core without an auth plugin whose groups come from
+// configGroups and file
+func groupCore(t *testing.T, file string) *Core {
+	t.Helper()
+	groups, err := newGroupRegistry(configGroups, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Core{
+		plugins:  plugin.NewRegistry(),
+		groups:   groups,
+		agents:   newAgentRegistry(),
+		breakers: newCircuitBreakers(config.CircuitBreakerConfig{}),
+	}
+}
+
+func TestGroupsPersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "groups.json")
+	c := groupCore(t, file)
+	ctx := context.Background()
+
+	for _, group := range []*agentv1.AgentGroup{
+		{Name: "web", Description: "frontends", Agents: []string{"web-1", "web-2"}, Selector: map[string]string{"role": "web"}},
+		{Name: "canary", Agents: []string{"web-3"}},
+	} {
+		if _, err := c.CreateAgentGroup(ctx, &agentv1.CreateAgentGroupRequest{Group: group}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := c.CreateAgentGroup(ctx, &agentv1.CreateAgentGroupRequest{Group: &agentv1.AgentGroup{Name: "web"}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("creating web twice: %v, want AlreadyExists", err)
+	}
+
+	_, err = c.UpdateAgentGroup(ctx, &agentv1.UpdateAgentGroupRequest{
+		Name:         "web",
+		Description:  proto.String("all frontends"),
+		AddAgents:    []string{"web-3", "web-1"},
+		RemoveAgents: []string{"web-2"},
+		Selector:     map[string]string{"zone": "eu-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DeleteAgentGroup(ctx, &agentv1.DeleteAgentGroupRequest{Name: "canary"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted core has the changes, and still the config's group
+	reloaded := groupCore(t, file)
+	list, err := reloaded.ListAgentGroups(ctx, &agentv1.ListAgentGroupsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, group := range list.Groups {
+		names = append(names, group.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"prod", "web"}) {
+		t.Errorf("groups after reload = %v, want prod and web", names)
+	}
+	web, ok := reloaded.groups.get("web")
+	if !ok {
+		t.Fatal("web lost on reload")
+	}
+	if web.Description != "all frontends" || !reflect.DeepEqual(web.Agents, []string{"web-1", "web-3"}) ||
+		!reflect.DeepEqual(web.Selector, map[string]string{"role": "web", "zone": "eu-1"}) || web.FromConfig {
+		t.Errorf("web after reload = %+v", web)
+	}
+
+	// Replacing the selector drops the old keys
+	_, err = reloaded.UpdateAgentGroup(ctx, &agentv1.UpdateAgentGroupRequest{
+		Name:            "web",
+		Selector:        map[string]string{"tier": "edge"},
+		ReplaceSelector: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web, _ := groupCore(t, file).groups.get("web"); !reflect.DeepEqual(web.Selector, map[string]string{"tier": "edge"}) {
+		t.Errorf("replaced selector = %v", web.Selector)
+	}
+}
+
+func TestConfigGroupsAreReadOnly(t *testing.T) {
+	c := groupCore(t, filepath.Join(t.TempDir(), "groups.json"))
+	ctx := context.Background()
+
+	_, err := c.UpdateAgentGroup(ctx, &agentv1.UpdateAgentGroupRequest{Name: "prod", AddAgents: []string{"web-1"}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("updating a config group: %v, want FailedPrecondition", err)
+	}
+	if _, err := c.DeleteAgentGroup(ctx, &agentv1.DeleteAgentGroupRequest{Name: "prod"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("deleting a config group: %v, want FailedPrecondition", err)
+	}
+	_, err = c.CreateAgentGroup(ctx, &agentv1.CreateAgentGroupRequest{Group: &agentv1.AgentGroup{Name: "prod"}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("shadowing a config group: %v, want AlreadyExists", err)
+	}
+	if prod, _ := c.groups.get("prod"); len(prod.Agents) != 0 || !prod.FromConfig {
+		t.Errorf("prod changed to %+v", prod)
+	}
+
+	// A stored copy of a config group gives way to the config
+	file := filepath.Join(t.TempDir(), "groups.json")
+	stored := groupCore(t, file)
+	stored.groups.groups["prod"] = &AgentGroup{Name: "prod", Agents: []string{"rogue-1"}}
+	if err := stored.groups.save(); err != nil {
+		t.Fatal(err)
+	}
+	if prod, _ := groupCore(t, file).groups.get("prod"); len(prod.Agents) != 0 || !prod.FromConfig {
+		t.Errorf("prod after reload = %+v, want the config's", prod)
+	}
+
+	// Without a groups file, nothing can be changed through the API
+	c = groupCore(t, "")
+	_, err = c.CreateAgentGroup(ctx, &agentv1.CreateAgentGroupRequest{Group: &agentv1.AgentGroup{Name: "web"}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("creating without a groups file: %v, want FailedPrecondition", err)
+	}
+}
+
+func TestGroupMembership(t *testing.T) {
+	web1 := &AgentConnection{ID: "web-1", Labels: map[string]string{"role": "web", "env": "prod"}}
+	web2 := &AgentConnection{ID: "web-2", Labels: map[string]string{"role": "web", "env": "staging"}}
+	db1 := &AgentConnection{ID: "db-1", Hostname: "web-1", Labels: map[string]string{"role": "db", "env": "prod"}}
+
+	tests := []struct {
+		name  string
+		group AgentGroup
+		want  []*AgentConnection
+	}{
+		{"static", AgentGroup{Agents: []string{"web-2", "gone-1"}}, []*AgentConnection{web2}},
+		{"selector", AgentGroup{Selector: map[string]string{"role": "web"}}, []*AgentConnection{web1, web2}},
+		{"every selector label", AgentGroup{Selector: map[string]string{"role": "web", "env": "prod"}}, []*AgentConnection{web1}},
+		{"static or selector", AgentGroup{Agents: []string{"db-1"}, Selector: map[string]string{"env": "staging"}}, []*AgentConnection{web2, db1}},
+		// Hostnames are self-reported and do not make members
+		{"by hostname", AgentGroup{Agents: []string{"web-1"}}, []*AgentConnection{web1}},
+		{"empty", AgentGroup{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*AgentConnection
+			for _, agent := range []*AgentConnection{web1, web2, db1} {
+				if tt.group.contains(agent) {
+					got = append(got, agent)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("members = %v, want %v", ids(got), ids(tt.want))
+			}
+		})
+	}
+
+	// Agents list the groups they are in, and groups their current members
+	c := groupCore(t, filepath.Join(t.TempDir(), "groups.json"))
+	c.agents = newAgentRegistry(web1, web2, db1)
+	ctx := context.Background()
+	_, err := c.CreateAgentGroup(ctx, &agentv1.CreateAgentGroupRequest{Group: &agentv1.AgentGroup{
+		Name:     "web",
+		Agents:   []string{"db-1"},
+		Selector: map[string]string{"role": "web"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.agentGroups(web1); !reflect.DeepEqual(got, []string{"prod", "web"}) {
+		t.Errorf("web-1 groups = %v, want prod and web", got)
+	}
+	if got := c.agentGroups(web2); !reflect.DeepEqual(got, []string{"web"}) {
+		t.Errorf("web-2 groups = %v, want web", got)
+	}
+	resp, err := c.GetAgentGroup(ctx, &agentv1.GetAgentGroupRequest{Name: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var members []string
+	for _, agent := range resp.Members {
+		members = append(members, agent.Id)
+	}
+	if len(members) != 2 || !containsString(members, "web-1") || !containsString(members, "db-1") {
+		t.Errorf("prod members = %v, want web-1 and db-1", members)
+	}
+}
+
+func ids(agents []*AgentConnection) []string {
+	var result []string
+	for _, agent := range agents {
+		result = append(result, agent.ID)
+	}
+	return result
+}
+
+func TestGroupGrants(t *testing.T) {
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"alice": {"delete group:web/stack:*", "write group:web"},
+		"bob":   {"read agent:db-1/stack:*"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := newGroupRegistry([]config.AgentGroupConfig{{Name: "web", Selector: map[string]string{"role": "web"}}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	web1 := &AgentConnection{ID: "web-1", Labels: map[string]string{"role": "web"}, LastSeen: time.Now()}
+	db1 := &AgentConnection{ID: "db-1", Labels: map[string]string{"role": "db"}, LastSeen: time.Now()}
+	c := &Core{plugins: plugins, groups: groups, agents: newAgentRegistry(web1, db1)}
+	as := func(user string) context.Context {
+		return plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: user})
+	}
+
+	tests := []struct {
+		user    string
+		agent   *AgentConnection
+		action  string
+		allowed bool
+	}{
+		{"alice", web1, "delete", true},
+		{"alice", db1, "delete", false},
+		// The grant is for deleting only
+		{"alice", web1, "read", false},
+		{"bob", db1, "read", true},
+		{"bob", web1, "read", false},
+	}
+	for _, tt := range tests {
+		err := c.authorizeAgent(as(tt.user), tt.agent, tt.action, "stack:*")
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("%s %s stacks on %s: %v, want allowed %v", tt.user, tt.action, tt.agent.ID, err, tt.allowed)
+		} else if !allowed && status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s %s stacks on %s: %v, want PermissionDenied", tt.user, tt.action, tt.agent.ID, err)
+		}
+	}
+
+	// Relabelled out of the group, the agent leaves the grant behind
+	agent, _ := c.agents.lock("web-1")
+	agent.Labels = map[string]string{"role": "db"}
+	agent.mu.Unlock()
+	if err := c.authorizeAgent(as("alice"), web1, "delete", "stack:*"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("alice deleting on web-1 after it left the group: %v, want PermissionDenied", err)
+	}
+
+	// Managing the group itself needs a grant on the group
+	if err := c.authorizeGroup(as("alice"), "write", "web"); err != nil {
+		t.Errorf("alice writing group web: %v", err)
+	}
+	if err := c.authorizeGroup(as("bob"), "write", "web"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("bob writing group web: %v, want PermissionDenied", err)
+	}
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	defaultOfflineTimeout    = 90 * time.Second
+	// defaultMaxHeartbeatRate is how many heartbeats per second the fleet
+	// sends at most; past 3000 agents at 30s, intervals stretch to keep it
+	defaultMaxHeartbeatRate = 100
+	maxHeartbeatInterval    = 5 * time.Minute
+	// missedHeartbeats is how many stretched intervals pass without a
+	// heartbeat before an agent is offline, when that is longer than
+	// offline_timeout
+	missedHeartbeats = 3
+
+	heartbeatShards        = 64
+	heartbeatFlushInterval = time.Second
+)
+
+// heartbeatBook takes heartbeats without locking their agents. Each
+// agent's latest heartbeat waits in its shard until flushHeartbeats folds
+// them all into the registry in one pass, so thousands of agents
+// heartbeating contend on a shard each rather than on the agents the core
+// is reading. The zero value is ready to use.
+type heartbeatBook struct {
+	shards [heartbeatShards]heartbeatShard
+}
+
+type heartbeatShard struct {
+	mu      sync.Mutex
+	pending map[string]*heartbeat
+}
+
+// heartbeat is what a heartbeat changes in the registry
+type heartbeat struct {
+	agentID  string
+	req      *agentv1.HeartbeatRequest
+	received time.Time
+	cert     *x509.Certificate // Nil when the caller presented none
+}
+
+func (b *heartbeatBook) shard(agentID string) *heartbeatShard {
+	h := fnv.New32a()
+	h.Write([]byte(agentID))
+	return &b.shards[h.Sum32()%heartbeatShards]
+}
+
+// record keeps beat until the next flush, in place of any earlier one of
+// its agent
+func (b *heartbeatBook) record(beat *heartbeat) {
+	s := b.shard(beat.agentID)
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[string]*heartbeat)
+	}
+	s.pending[beat.agentID] = beat
+	s.mu.Unlock()
+}
+
+// pending returns when agentID last heartbeat, if that is not flushed yet
+func (b *heartbeatBook) pending(agentID string) (time.Time, bool) {
+	s := b.shard(agentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if beat, ok := s.pending[agentID]; ok {
+		return beat.received, true
+	}
+	return time.Time{}, false
+}
+
+// drain returns the heartbeats recorded since the last drain
+func (b *heartbeatBook) drain() []*heartbeat {
+	var beats []*heartbeat
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		for _, beat := range s.pending {
+			beats = append(beats, beat)
+		}
+		s.pending = nil
+		s.mu.Unlock()
+	}
+	return beats
+}
+
+// heartbeatSettings are the agent_management settings heartbeats follow
+type heartbeatSettings struct {
+	interval       time.Duration
+	offlineTimeout time.Duration
+	maxRate        int
+}
+
+func newHeartbeatSettings(interval, offlineTimeout string, maxRate int) heartbeatSettings {
+	return heartbeatSettings{
+		interval:       parseAgentDuration("heartbeat_interval", interval, defaultHeartbeatInterval),
+		offlineTimeout: parseAgentDuration("offline_timeout", offlineTimeout, defaultOfflineTimeout),
+		maxRate:        maxRate,
+	}
+}
+
+func parseAgentDuration(name, value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid agent_management.%s %q, using %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+// heartbeatInterval is how often agents of a fleet of size agents are told
+// to heartbeat: heartbeat_interval, stretched so that the fleet sends at
+// most max_heartbeat_rate heartbeats a second, up to maxHeartbeatInterval
+func (s heartbeatSettings) heartbeatInterval(agents int) time.Duration {
+	interval := s.interval
+	if interval == 0 {
+		interval = defaultHeartbeatInterval
+	}
+	rate := s.maxRate
+	if rate <= 0 {
+		rate = defaultMaxHeartbeatRate
+	}
+	if spread := time.Duration(agents) * time.Second / time.Duration(rate); spread > interval {
+		interval = min(spread, maxHeartbeatInterval)
+	}
+	return interval
+}
+
+// offlineAfter is how long agents of a fleet of size agents go without a
+// heartbeat before they are offline: offline_timeout, or missedHeartbeats
+// stretched intervals when that is longer
+func (s heartbeatSettings) offlineAfter(agents int) time.Duration {
+	timeout := s.offlineTimeout
+	if timeout == 0 {
+		timeout = defaultOfflineTimeout
+	}
+	return max(timeout, missedHeartbeats*s.heartbeatInterval(agents))
+}
+
+func (c *Core) Heartbeat(ctx context.Context, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	return c.heartbeat(ctx, agentv1.CoreService_Heartbeat_FullMethodName, req)
+}
+
+// HeartbeatStream answers each heartbeat an agent sends on the stream as
+// Heartbeat does. All of them must come from the agent of the first.
+func (c *Core) HeartbeatStream(stream agentv1.CoreService_HeartbeatStreamServer) error {
+	ctx := stream.Context()
+	var agentID string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if agentID == "" {
+			agentID = req.AgentId
+		} else if req.AgentId != agentID {
+			return status.Errorf(codes.InvalidArgument, "heartbeat stream of agent %s carries a heartbeat of %s", agentID, req.AgentId)
+		}
+
+		resp, err := c.heartbeat(ctx, agentv1.CoreService_HeartbeatStream_FullMethodName, req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// heartbeat records a heartbeat for the next flush and answers it with the
+// agent's pending instructions. Only the agent's read lock is taken.
+func (c *Core) heartbeat(ctx context.Context, method string, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	agentID := req.AgentId
+	received := time.Now()
+
+	agent, exists := c.agents.get(agentID)
+	if !exists {
+		return nil, fmt.Errorf("agent not found: %s", agentID)
+	}
+	agent.mu.RLock()
+	skewed := agent.ClockSkewed
+	agent.mu.RUnlock()
+
+	if err := c.verifyAgentIdentity(ctx, method, agentID, false); err != nil {
+		return nil, err
+	}
+
+	beat := &heartbeat{agentID: agentID, req: req, received: received}
+	if cert, err := peerCertificate(ctx); err == nil {
+		beat.cert = cert
+	}
+	c.heartbeats.record(beat)
+
+	if skew, ok := heartbeatSkew(req, received); ok {
+		skewed = absDuration(skew) > c.maxClockSkew
+	}
+	c.recordInstructionResults(ctx, agentID, req.Results)
+
+	return &agentv1.HeartbeatResponse{
+		Status:        "healthy",
+		NextHeartbeat: durationpb.New(c.heartbeatSettings.heartbeatInterval(c.agents.len())),
+		Instructions:  c.instructions.deliver(agentID, time.Now()),
+		CoreTime:      timestamppb.Now(),
+		ClockSkewed:   skewed,
+	}, nil
+}
+
+// flushHeartbeats folds the heartbeats recorded since the last flush into
+// the agent registry
+func (c *Core) flushHeartbeats(ctx context.Context) {
+	for _, beat := range c.heartbeats.drain() {
+		if agent, exists := c.agents.get(beat.agentID); exists {
+			c.applyHeartbeat(ctx, agent, beat)
+		}
+	}
+}
+
+// applyHeartbeat records beat on its agent
+func (c *Core) applyHeartbeat(ctx context.Context, agent *AgentConnection, beat *heartbeat) {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+
+	if beat.received.After(agent.LastSeen) {
+		agent.LastSeen = beat.received
+	}
+	if beat.cert != nil {
+		agent.Certificate = beat.cert
+	}
+	if agent.Status == AgentStatusOffline {
+		fmt.Printf("Agent %s is back online via heartbeat\n", beat.agentID)
+		c.agents.touch()
+	}
+	agent.Status = AgentStatusOnline
+
+	c.recordClockSkew(ctx, agent, beat.req, beat.received)
+}
+
+// runHeartbeatFlushes flushes heartbeats every heartbeatFlushInterval and
+// expires undelivered instructions, until ctx is done
+func (c *Core) runHeartbeatFlushes(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.flushHeartbeats(context.Background())
+			return
+		case now := <-ticker.C:
+			c.flushHeartbeats(ctx)
+			c.expireInstructions(ctx, now)
+		}
+	}
+}
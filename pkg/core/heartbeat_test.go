@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestHeartbeatInterval(t *testing.T) {
+	s := newHeartbeatSettings("30s", "90s", 100)
+
+	tests := []struct {
+		agents       int
+		wantInterval time.Duration
+		wantOffline  time.Duration
+	}{
+		{agents: 10, wantInterval: 30 * time.Second, wantOffline: 90 * time.Second},
+		{agents: 3000, wantInterval: 30 * time.Second, wantOffline: 90 * time.Second},
+		{agents: 10000, wantInterval: 100 * time.Second, wantOffline: 300 * time.Second},
+		{agents: 100000, wantInterval: maxHeartbeatInterval, wantOffline: 3 * maxHeartbeatInterval},
+	}
+	for _, tt := range tests {
+		if got := s.heartbeatInterval(tt.agents); got != tt.wantInterval {
+			t.Errorf("heartbeatInterval(%d) = %s, want %s", tt.agents, got, tt.wantInterval)
+		}
+		if got := s.offlineAfter(tt.agents); got != tt.wantOffline {
+			t.Errorf("offlineAfter(%d) = %s, want %s", tt.agents, got, tt.wantOffline)
+		}
+	}
+
+	if got := (heartbeatSettings{}).heartbeatInterval(1); got != defaultHeartbeatInterval {
+		t.Errorf("unset interval = %s, want %s", got, defaultHeartbeatInterval)
+	}
+}
+
+func TestHeartbeatFlush(t *testing.T) {
+	ids, err := newAgentIdentities("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instructions, err := newInstructionQueue(config.OfflineQueueConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastSeen := time.Now().Add(-time.Hour)
+	agent := &AgentConnection{ID: "web-1", Status: AgentStatusOffline, LastSeen: lastSeen}
+	c := &Core{
+		agents:          newAgentRegistry(agent),
+		plugins:         plugin.NewRegistry(),
+		agentIdentities: ids,
+		instructions:    instructions,
+		maxClockSkew:    defaultMaxClockSkew,
+	}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}}
+	ctx := peerContext(cert)
+	if err := c.verifyAgentIdentity(ctx, agentv1.CoreService_RegisterAgent_FullMethodName, "web-1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Heartbeat(ctx, &agentv1.HeartbeatRequest{AgentId: "web-1", SentAt: timestamppb.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.NextHeartbeat.AsDuration(); got != defaultHeartbeatInterval {
+		t.Errorf("next heartbeat in %s, want %s", got, defaultHeartbeatInterval)
+	}
+
+	// Recorded, but not in the registry until flushed
+	if agent.Status != AgentStatusOffline || !agent.LastSeen.Equal(lastSeen) {
+		t.Fatalf("heartbeat changed the registry before the flush: %s, last seen %s", agent.Status, agent.LastSeen)
+	}
+	if _, ok := c.heartbeats.pending("web-1"); !ok {
+		t.Fatal("heartbeat not pending")
+	}
+
+	c.flushHeartbeats(context.Background())
+	if agent.Status != AgentStatusOnline || !agent.LastSeen.After(lastSeen) || agent.Certificate != cert {
+		t.Errorf("after the flush: %s, last seen %s, certificate %v", agent.Status, agent.LastSeen, agent.Certificate)
+	}
+	if agent.ClockSkew == nil {
+		t.Error("clock skew not recorded")
+	}
+	if _, ok := c.heartbeats.pending("web-1"); ok {
+		t.Error("heartbeat still pending after the flush")
+	}
+
+	if _, err := c.Heartbeat(ctx, &agentv1.HeartbeatRequest{AgentId: "web-9"}); err == nil {
+		t.Error("heartbeat of an unregistered agent accepted")
+	}
+}
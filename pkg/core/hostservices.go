@@ -0,0 +1,103 @@
+package core
+
+import (
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+)
+
+// hostMethod describes a proxied host service RPC: the agent capability it
+// needs and whether it changes the host
+type hostMethod struct {
+	capability string
+	write      bool
+}
+
+// hostMethods are the host service RPCs (nginx, systemd, firewall, ACME,
+// host environment, cron, DNS, deployments, drift, ports, processes, log
+// files, reboots and commands) the core forwards to agents. Callers need
+// "read" or "write" on "host:<capability>", for instance "host:nginx",
+// globally or scoped to the agent or one of its groups.
+var hostMethods = map[string]hostMethod{
+	agentv1.NginxService_CreateVirtualHost_FullMethodName:                {capability.Nginx, true},
+	agentv1.NginxService_EnableVirtualHost_FullMethodName:                {capability.Nginx, true},
+	agentv1.NginxService_DisableVirtualHost_FullMethodName:               {capability.Nginx, true},
+	agentv1.NginxService_DeleteVirtualHost_FullMethodName:                {capability.Nginx, true},
+	agentv1.NginxService_ListVirtualHosts_FullMethodName:                 {capability.Nginx, false},
+	agentv1.NginxService_CreateReverseProxy_FullMethodName:               {capability.Nginx, true},
+	agentv1.NginxService_CreateLoadBalancer_FullMethodName:               {capability.Nginx, true},
+	agentv1.SystemdService_CreateService_FullMethodName:                  {capability.Systemd, true},
+	agentv1.SystemdService_EnableService_FullMethodName:                  {capability.Systemd, true},
+	agentv1.SystemdService_DisableService_FullMethodName:                 {capability.Systemd, true},
+	agentv1.SystemdService_StartService_FullMethodName:                   {capability.Systemd, true},
+	agentv1.SystemdService_StopService_FullMethodName:                    {capability.Systemd, true},
+	agentv1.SystemdService_RestartService_FullMethodName:                 {capability.Systemd, true},
+	agentv1.SystemdService_GetServiceStatus_FullMethodName:               {capability.Systemd, false},
+	agentv1.SystemdService_ListServices_FullMethodName:                   {capability.Systemd, false},
+	agentv1.SystemdService_ListInstances_FullMethodName:                  {capability.Systemd, false},
+	agentv1.SystemdService_SetInstanceEnvironment_FullMethodName:         {capability.Systemd, true},
+	agentv1.SystemdService_ScaleInstances_FullMethodName:                 {capability.Systemd, true},
+	agentv1.FirewallService_AddRule_FullMethodName:                       {capability.Firewall, true},
+	agentv1.FirewallService_DeleteRule_FullMethodName:                    {capability.Firewall, true},
+	agentv1.FirewallService_ListRules_FullMethodName:                     {capability.Firewall, false},
+	agentv1.FirewallService_AllowPort_FullMethodName:                     {capability.Firewall, true},
+	agentv1.FirewallService_DenyPort_FullMethodName:                      {capability.Firewall, true},
+	agentv1.FirewallService_Enable_FullMethodName:                        {capability.Firewall, true},
+	agentv1.FirewallService_Disable_FullMethodName:                       {capability.Firewall, true},
+	agentv1.FirewallService_RemoveRuleSet_FullMethodName:                 {capability.Firewall, true},
+	agentv1.ACMEService_ObtainCertificate_FullMethodName:                 {capability.ACME, true},
+	agentv1.ACMEService_RenewCertificate_FullMethodName:                  {capability.ACME, true},
+	agentv1.ACMEService_RenewAll_FullMethodName:                          {capability.ACME, true},
+	agentv1.ACMEService_RevokeCertificate_FullMethodName:                 {capability.ACME, true},
+	agentv1.ACMEService_ListCertificates_FullMethodName:                  {capability.ACME, false},
+	agentv1.HostEnvironmentService_GetHostInfo_FullMethodName:            {capability.Host, false},
+	agentv1.HostEnvironmentService_InstallPackage_FullMethodName:         {capability.Host, true},
+	agentv1.HostEnvironmentService_RemovePackage_FullMethodName:          {capability.Host, true},
+	agentv1.HostEnvironmentService_UpdatePackages_FullMethodName:         {capability.Host, true},
+	agentv1.HostEnvironmentService_ListPackages_FullMethodName:           {capability.Host, false},
+	agentv1.HostEnvironmentService_SetSysctl_FullMethodName:              {capability.Host, true},
+	agentv1.HostEnvironmentService_GetSysctl_FullMethodName:              {capability.Host, false},
+	agentv1.HostEnvironmentService_GetTimeSync_FullMethodName:            {capability.Host, false},
+	agentv1.HostEnvironmentService_ConfigureTimeSync_FullMethodName:      {capability.Host, true},
+	agentv1.HostEnvironmentService_GetPatchStatus_FullMethodName:         {capability.Host, false},
+	agentv1.HostEnvironmentService_GetUser_FullMethodName:                {capability.Host, false},
+	agentv1.HostEnvironmentService_EnsureUser_FullMethodName:             {capability.Host, true},
+	agentv1.HostEnvironmentService_ListSysctlProfiles_FullMethodName:     {capability.Host, false},
+	agentv1.HostEnvironmentService_ApplySysctlProfile_FullMethodName:     {capability.Host, true},
+	agentv1.HostEnvironmentService_VerifySysctls_FullMethodName:          {capability.Host, false},
+	agentv1.HostEnvironmentService_GetLimits_FullMethodName:              {capability.Host, false},
+	agentv1.HostEnvironmentService_SetLimits_FullMethodName:              {capability.Host, true},
+	agentv1.HostEnvironmentService_GetDockerDaemon_FullMethodName:        {capability.Host, false},
+	agentv1.HostEnvironmentService_SetDockerDaemon_FullMethodName:        {capability.Host, true},
+	agentv1.CronService_AddCronJob_FullMethodName:                        {capability.Cron, true},
+	agentv1.CronService_RemoveCronJob_FullMethodName:                     {capability.Cron, true},
+	agentv1.CronService_ListCronJobs_FullMethodName:                      {capability.Cron, false},
+	agentv1.DNSService_CreateZone_FullMethodName:                         {capability.DNS, true},
+	agentv1.DNSService_AddARecord_FullMethodName:                         {capability.DNS, true},
+	agentv1.DNSService_AddCNAMERecord_FullMethodName:                     {capability.DNS, true},
+	agentv1.DNSService_ListZones_FullMethodName:                          {capability.DNS, false},
+	agentv1.ServiceDeploymentService_DeployWebService_FullMethodName:     {capability.Deploy, true},
+	agentv1.ServiceDeploymentService_RemoveWebService_FullMethodName:     {capability.Deploy, true},
+	agentv1.ServiceDeploymentService_DeployStaticSite_FullMethodName:     {capability.Deploy, true},
+	agentv1.ServiceDeploymentService_DeployDatabase_FullMethodName:       {capability.Deploy, true},
+	agentv1.ServiceDeploymentService_DeployWorker_FullMethodName:         {capability.Deploy, true},
+	agentv1.ServiceDeploymentService_ListDeployedServices_FullMethodName: {capability.Deploy, false},
+	agentv1.DriftService_GetDriftReport_FullMethodName:                   {capability.Drift, false},
+	agentv1.PortService_ListListeningPorts_FullMethodName:                {capability.Ports, false},
+	agentv1.ProcessService_ListProcesses_FullMethodName:                  {capability.Processes, false},
+	agentv1.ProcessService_SignalProcess_FullMethodName:                  {capability.Processes, true},
+	agentv1.HostLogService_ListLogFiles_FullMethodName:                   {capability.LogFiles, false},
+	agentv1.HostLogService_TailLogFile_FullMethodName:                    {capability.LogFiles, false},
+	agentv1.HostPowerService_RebootHost_FullMethodName:                   {capability.Reboot, true},
+	agentv1.LogShipService_GetLogShipping_FullMethodName:                 {capability.LogShip, false},
+	agentv1.CommandService_ListCommands_FullMethodName:                   {capability.Commands, false},
+	agentv1.CommandService_RunCommand_FullMethodName:                     {capability.Commands, true},
+}
+
+func init() {
+	for method, m := range hostMethods {
+		methodCapabilities[method] = m.capability
+		if m.write {
+			frozenMethods[method] = true
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+)
+
+// Every host service RPC needs a route, or the core refuses to forward it
+func TestHostMethodsCoverHostServices(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{
+		agentv1.NginxService_ServiceDesc,
+		agentv1.SystemdService_ServiceDesc,
+		agentv1.FirewallService_ServiceDesc,
+		agentv1.ACMEService_ServiceDesc,
+		agentv1.HostEnvironmentService_ServiceDesc,
+		agentv1.CronService_ServiceDesc,
+		agentv1.DNSService_ServiceDesc,
+		agentv1.ServiceDeploymentService_ServiceDesc,
+		agentv1.DriftService_ServiceDesc,
+		agentv1.PortService_ServiceDesc,
+		agentv1.ProcessService_ServiceDesc,
+		agentv1.HostLogService_ServiceDesc,
+		agentv1.HostPowerService_ServiceDesc,
+		agentv1.LogShipService_ServiceDesc,
+	} {
+		var names []string
+		for _, m := range desc.Methods {
+			names = append(names, m.MethodName)
+		}
+		for _, s := range desc.Streams {
+			names = append(names, s.StreamName)
+		}
+
+		for _, name := range names {
+			method := "/" + desc.ServiceName + "/" + name
+			if _, ok := hostMethods[method]; !ok {
+				t.Errorf("%s is not in hostMethods", method)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grantAuth knows the users in roles and allows each the "action resource"
+// pairs listed in grants
+type grantAuth struct {
+	roles  map[string][]string
+	grants map[string][]string
+}
+
+func (a *grantAuth) Name() string    { return "grant-auth" }
+func (a *grantAuth) Version() string { return "test" }
+func (a *grantAuth) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityAuth}
+}
+func (a *grantAuth) Init(ctx context.Context, config map[string]interface{}) error { return nil }
+func (a *grantAuth) Shutdown(ctx context.Context) error                            { return nil }
+func (a *grantAuth) Authenticate(ctx context.Context, req *plugin.AuthRequest) (*plugin.Identity, error) {
+	roles, ok := a.roles[req.Identity.UserID]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", req.Identity.UserID)
+	}
+	identity := *req.Identity
+	identity.Roles = roles
+	return &identity, nil
+}
+func (a *grantAuth) Authorize(ctx context.Context, identity *plugin.Identity, action *plugin.Action) error {
+	for _, grant := range a.grants[identity.UserID] {
+		if grant == action.Action+" "+action.Resource {
+			return nil
+		}
+	}
+	return fmt.Errorf("denied")
+}
+
+func TestImpersonate(t *testing.T) {
+	auditor := &auditRecorder{}
+	plugins := plugin.NewRegistry()
+	for _, p := range []plugin.Plugin{auditor, &grantAuth{
+		roles:  map[string][]string{"admin": {"admin"}, "bob": {"viewer"}, "carol": {"viewer"}},
+		grants: map[string][]string{"admin": {"impersonate user:bob"}},
+	}} {
+		if err := plugins.Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &Core{plugins: plugins, breakGlass: newBreakGlassStore(config.BreakGlassConfig{})}
+
+	as := func(caller, target string) context.Context {
+		ctx := peerContext(&x509.Certificate{Subject: pkix.Name{CommonName: caller}})
+		if target != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(transport.ImpersonateHeader, target))
+		}
+		return ctx
+	}
+	const method = "/mandau.v1.CoreService/ListAgents"
+
+	// Allowed: the call runs with bob's roles and remembers the admin
+	ctx, err := c.authenticate(as("admin", "bob"), method, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := plugin.IdentityFromContext(ctx)
+	if identity.UserID != "bob" || len(identity.Roles) != 1 || identity.Roles[0] != "viewer" {
+		t.Errorf("effective identity = %+v, want bob as viewer", identity)
+	}
+	if identity.Impersonator == nil || identity.Impersonator.UserID != "admin" {
+		t.Errorf("impersonator = %+v, want admin", identity.Impersonator)
+	}
+	if got := c.requesterID(ctx); got != "bob (impersonated by admin)" {
+		t.Errorf("requester = %q", got)
+	}
+
+	// Audit entries made under it name both identities
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{Identity: identity, Action: method})
+	if got := auditor.entries[len(auditor.entries)-1].Metadata["impersonated_by"]; got != "admin" {
+		t.Errorf("impersonated_by = %q, want admin", got)
+	}
+
+	// Things that need one's own name are refused
+	if err := notImpersonated(identity, "review approvals"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("reviewing while impersonating: %v", err)
+	}
+
+	// Streams without the auth interceptor impersonate the same way
+	if identity, err := c.callerIdentity(as("admin", "bob")); err != nil || identity.Impersonator == nil {
+		t.Errorf("stream identity = %+v, %v", identity, err)
+	}
+
+	// Naming oneself is no impersonation
+	ctx, err = c.authenticate(as("bob", "bob"), method, nil)
+	if err != nil || plugin.IdentityFromContext(ctx).Impersonator != nil {
+		t.Errorf("self impersonation = %v, %v", plugin.IdentityFromContext(ctx), err)
+	}
+
+	// Refused by policy or for unknown users, and audited under the caller
+	for _, tt := range []struct{ caller, target string }{
+		{"admin", "carol"},
+		{"bob", "admin"},
+		{"admin", "nobody"},
+	} {
+		before := len(auditor.entries)
+		if _, err := c.authenticate(as(tt.caller, tt.target), method, nil); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s as %s: err = %v, want PermissionDenied", tt.caller, tt.target, err)
+			continue
+		}
+		if len(auditor.entries) != before+1 {
+			t.Fatalf("%s as %s: refusal not audited", tt.caller, tt.target)
+		}
+		entry := auditor.entries[before]
+		if entry.Identity.UserID != tt.caller || entry.Metadata["impersonate"] != tt.target || entry.Result != "denied" {
+			t.Errorf("%s as %s: audited %+v", tt.caller, tt.target, entry)
+		}
+	}
+}
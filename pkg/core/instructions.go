@@ -0,0 +1,512 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// instructionRedelivery is how long a delivered instruction waits for its
+// result before it is sent again, in case the response was lost
+const instructionRedelivery = 2 * time.Minute
+
+// maxPendingInstructions bounds the queue of an agent that never answers
+const maxPendingInstructions = 100
+
+const (
+	defaultQueueTTL = 24 * time.Hour
+	defaultQueueMax = 7 * 24 * time.Hour
+)
+
+// InstructionQueue holds work for agents until they report its outcome on a
+// heartbeat. Agents may see an instruction more than once and must treat
+// repeated IDs as already handled. With a file set, pending instructions
+// survive core restarts; whether they were delivered does not, so they are
+// delivered again.
+type InstructionQueue struct {
+	mu         sync.Mutex
+	pending    map[string][]*queuedInstruction // By agent ID, oldest first
+	file       string
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+type queuedInstruction struct {
+	instruction *agentv1.AgentInstruction
+	deliveredAt time.Time // Zero until first delivered
+}
+
+func newInstructionQueue(cfg config.OfflineQueueConfig) (*InstructionQueue, error) {
+	q := &InstructionQueue{
+		pending:    make(map[string][]*queuedInstruction),
+		file:       cfg.File,
+		defaultTTL: defaultQueueTTL,
+		maxTTL:     defaultQueueMax,
+	}
+	if cfg.DefaultTTL != "" {
+		if d, err := time.ParseDuration(cfg.DefaultTTL); err == nil && d > 0 {
+			q.defaultTTL = d
+		} else {
+			log.Printf("Invalid offline_queue default_ttl %q, using %s", cfg.DefaultTTL, q.defaultTTL)
+		}
+	}
+	if cfg.MaxTTL != "" {
+		if d, err := time.ParseDuration(cfg.MaxTTL); err == nil && d > 0 {
+			q.maxTTL = d
+		} else {
+			log.Printf("Invalid offline_queue max_ttl %q, using %s", cfg.MaxTTL, q.maxTTL)
+		}
+	}
+	if q.defaultTTL > q.maxTTL {
+		q.defaultTTL = q.maxTTL
+	}
+
+	if q.file == "" {
+		return q, nil
+	}
+	data, err := os.ReadFile(q.file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read offline queue file: %w", err)
+	}
+	if len(data) > 0 {
+		var stored agentv1.ListAgentInstructionsResponse
+		if err := protojson.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("parse offline queue file: %w", err)
+		}
+		for _, inst := range stored.Pending {
+			q.pending[inst.AgentId] = append(q.pending[inst.AgentId], &queuedInstruction{instruction: inst})
+		}
+		log.Printf("Loaded %d pending agent instructions from %s", len(stored.Pending), q.file)
+	}
+	return q, nil
+}
+
+// ttl resolves the TTL a caller asked for; zero means the default
+func (q *InstructionQueue) ttl(requested *durationpb.Duration) (time.Duration, error) {
+	if requested == nil || requested.AsDuration() == 0 {
+		return q.defaultTTL, nil
+	}
+	d := requested.AsDuration()
+	if d < 0 || d > q.maxTTL {
+		return 0, status.Errorf(codes.InvalidArgument, "queue TTL must be between 0 and %s", q.maxTTL)
+	}
+	return d, nil
+}
+
+// save writes the pending instructions to the queue file, if any.
+// Callers must hold the lock.
+func (q *InstructionQueue) save() error {
+	if q.file == "" {
+		return nil
+	}
+
+	data, err := protojson.Marshal(&agentv1.ListAgentInstructionsResponse{Pending: q.all()})
+	if err != nil {
+		return err
+	}
+
+	tmp := q.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.file)
+}
+
+// all returns every pending instruction, oldest first.
+// Callers must hold the lock.
+func (q *InstructionQueue) all() []*agentv1.AgentInstruction {
+	var out []*agentv1.AgentInstruction
+	for _, pending := range q.pending {
+		for _, p := range pending {
+			out = append(out, p.instruction)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].CreatedAt.AsTime().Before(out[j].CreatedAt.AsTime())
+	})
+	return out
+}
+
+// enqueue assigns the instruction an ID and queues it for agentID. A
+// positive ttl drops it if it is still undelivered when the TTL runs out.
+func (q *InstructionQueue) enqueue(agentID string, inst *agentv1.AgentInstruction, ttl time.Duration, now time.Time) (*agentv1.AgentInstruction, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending[agentID]) >= maxPendingInstructions {
+		return nil, status.Errorf(codes.ResourceExhausted, "agent %s has %d undelivered instructions", agentID, maxPendingInstructions)
+	}
+
+	inst.Id = uuid.New().String()
+	inst.AgentId = agentID
+	inst.CreatedAt = timestamppb.New(now)
+	inst.ExpiresAt = nil
+	if ttl > 0 {
+		inst.ExpiresAt = timestamppb.New(now.Add(ttl))
+	}
+	q.pending[agentID] = append(q.pending[agentID], &queuedInstruction{instruction: inst})
+
+	if err := q.save(); err != nil {
+		q.pending[agentID] = q.pending[agentID][:len(q.pending[agentID])-1]
+		return nil, status.Errorf(codes.Internal, "save offline queue: %v", err)
+	}
+	return inst, nil
+}
+
+// expired reports whether p was never delivered and its TTL has run out.
+// Delivered instructions may be running on the agent, so they stay.
+func (p *queuedInstruction) expired(now time.Time) bool {
+	return p.deliveredAt.IsZero() && p.instruction.ExpiresAt != nil && !now.Before(p.instruction.ExpiresAt.AsTime())
+}
+
+// deliver returns the instructions to piggyback on agentID's heartbeat:
+// those never delivered and those whose result is overdue
+func (q *InstructionQueue) deliver(agentID string, now time.Time) []*agentv1.AgentInstruction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var out []*agentv1.AgentInstruction
+	for _, p := range q.pending[agentID] {
+		if p.expired(now) {
+			continue
+		}
+		if p.deliveredAt.IsZero() || now.Sub(p.deliveredAt) >= instructionRedelivery {
+			p.deliveredAt = now
+			out = append(out, p.instruction)
+		}
+	}
+	return out
+}
+
+// complete removes the instructions results report on and returns them,
+// in the order of results; unknown IDs are skipped
+func (q *InstructionQueue) complete(agentID string, results []*agentv1.InstructionResult) []*agentv1.AgentInstruction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var done []*agentv1.AgentInstruction
+	for _, r := range results {
+		pending := q.pending[agentID]
+		for i, p := range pending {
+			if p.instruction.Id == r.InstructionId {
+				done = append(done, p.instruction)
+				q.pending[agentID] = append(pending[:i:i], pending[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(q.pending[agentID]) == 0 {
+		delete(q.pending, agentID)
+	}
+	if len(done) > 0 {
+		if err := q.save(); err != nil {
+			log.Printf("Failed to save offline queue: %v", err)
+		}
+	}
+	return done
+}
+
+// expire drops the instructions whose TTL ran out before delivery and
+// returns them
+func (q *InstructionQueue) expire(now time.Time) []*agentv1.AgentInstruction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var dropped []*agentv1.AgentInstruction
+	for agentID, pending := range q.pending {
+		kept := pending[:0]
+		for _, p := range pending {
+			if p.expired(now) {
+				dropped = append(dropped, p.instruction)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(q.pending, agentID)
+		} else {
+			q.pending[agentID] = kept
+		}
+	}
+	if len(dropped) > 0 {
+		if err := q.save(); err != nil {
+			log.Printf("Failed to save offline queue: %v", err)
+		}
+	}
+	return dropped
+}
+
+// cancel removes an instruction that has not been delivered yet
+func (q *InstructionQueue) cancel(agentID, id string) (*agentv1.AgentInstruction, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := q.pending[agentID]
+	for i, p := range pending {
+		if p.instruction.Id != id {
+			continue
+		}
+		if !p.deliveredAt.IsZero() {
+			return nil, status.Errorf(codes.FailedPrecondition, "instruction %s was already delivered to agent %s", id, agentID)
+		}
+		q.pending[agentID] = append(pending[:i:i], pending[i+1:]...)
+		if len(q.pending[agentID]) == 0 {
+			delete(q.pending, agentID)
+		}
+		if err := q.save(); err != nil {
+			log.Printf("Failed to save offline queue: %v", err)
+		}
+		return p.instruction, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "no pending instruction %s for agent %s", id, agentID)
+}
+
+// list returns agentID's pending instructions, or every agent's when
+// agentID is empty, oldest first
+func (q *InstructionQueue) list(agentID string) []*agentv1.AgentInstruction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if agentID == "" {
+		return q.all()
+	}
+	out := make([]*agentv1.AgentInstruction, 0, len(q.pending[agentID]))
+	for _, p := range q.pending[agentID] {
+		out = append(out, p.instruction)
+	}
+	return out
+}
+
+// has reports whether anything is queued for agentID
+func (q *InstructionQueue) has(agentID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending[agentID]) > 0
+}
+
+// instructionKind names an instruction for logs and audit entries
+func instructionKind(inst *agentv1.AgentInstruction) string {
+	switch inst.Kind.(type) {
+	case *agentv1.AgentInstruction_Config:
+		return "config"
+	case *agentv1.AgentInstruction_ApplyStack:
+		return "apply_stack"
+	case *agentv1.AgentInstruction_RemoveStack:
+		return "remove_stack"
+	case *agentv1.AgentInstruction_Drain:
+		return "drain"
+	}
+	return "unknown"
+}
+
+// recordInstructionResults audits the outcomes an agent reported
+func (c *Core) recordInstructionResults(ctx context.Context, agentID string, results []*agentv1.InstructionResult) {
+	done := c.instructions.complete(agentID, results)
+	byID := make(map[string]*agentv1.InstructionResult, len(results))
+	for _, r := range results {
+		byID[r.InstructionId] = r
+	}
+
+	for _, inst := range done {
+		r := byID[inst.Id]
+		result := "success"
+		if !r.Success {
+			result = "failure"
+			c.failInstruction(agentID, inst, r.Error)
+		}
+		log.Printf("Agent %s instruction %s (%s): %s %s", agentID, inst.Id, instructionKind(inst), result, r.Error)
+
+		c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+			Timestamp: time.Now(),
+			AgentID:   agentID,
+			Identity:  &plugin.Identity{UserID: inst.RequestedBy},
+			Action:    "instruction." + instructionKind(inst),
+			Resource:  "agent:" + agentID,
+			Result:    result,
+			Metadata: map[string]string{
+				"instruction_id": inst.Id,
+				"operation_id":   r.OperationId,
+				"error":          r.Error,
+			},
+		})
+	}
+}
+
+// expireInstructions audits instructions dropped because their TTL ran
+// out before the agent came back
+func (c *Core) expireInstructions(ctx context.Context, now time.Time) {
+	for _, inst := range c.instructions.expire(now) {
+		log.Printf("Agent %s instruction %s (%s) expired undelivered", inst.AgentId, inst.Id, instructionKind(inst))
+
+		c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+			Timestamp: now,
+			AgentID:   inst.AgentId,
+			Identity:  &plugin.Identity{UserID: inst.RequestedBy},
+			Action:    "instruction." + instructionKind(inst),
+			Resource:  "agent:" + inst.AgentId,
+			Result:    "expired",
+			Metadata:  map[string]string{"instruction_id": inst.Id},
+		})
+	}
+}
+
+// queueStackInstruction hands a stack change for a dial-out-only or offline
+// agent to the instruction queue and tells the caller it is pending
+func (c *Core) queueStackInstruction(ctx context.Context, conn *AgentConnection, inst *agentv1.AgentInstruction, ttl *durationpb.Duration, send func(*agentv1.OperationEvent) error) error {
+	if identity, err := c.callerIdentity(ctx); err == nil {
+		inst.RequestedBy = identity.UserID
+	}
+	inst.RequestId = requestid.From(ctx)
+
+	d, err := c.instructions.ttl(ttl)
+	if err != nil {
+		return err
+	}
+	queued, err := c.instructions.enqueue(conn.ID, inst, d, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("Queued %s instruction %s for agent %s", instructionKind(queued), queued.Id, conn.ID)
+
+	return send(&agentv1.OperationEvent{
+		OperationId: queued.Id,
+		State:       agentv1.OperationState_OPERATION_STATE_PENDING,
+		Timestamp:   queued.CreatedAt,
+		Message: fmt.Sprintf("Queued for agent %s; it runs after the agent's next heartbeat unless still undelivered at %s",
+			conn.ID, queued.ExpiresAt.AsTime().Format(time.RFC3339)),
+	})
+}
+
+// instructionAgent returns the agent instructions for agentID are checked
+// against. Instructions loaded from the queue file may belong to an agent
+// that has not registered since the core restarted; those are checked
+// against its ID alone.
+func (c *Core) instructionAgent(agentID string) (*AgentConnection, error) {
+	agent, exists := c.agents.get(agentID)
+	if exists {
+		return agent, nil
+	}
+	if agentID != "" && c.instructions.has(agentID) {
+		return &AgentConnection{ID: agentID}, nil
+	}
+	return nil, errAgentNotFound(agentID)
+}
+
+// QueueAgentInstruction queues a config refresh or drain for an agent
+func (c *Core) QueueAgentInstruction(ctx context.Context, req *agentv1.QueueAgentInstructionRequest) (*agentv1.AgentInstruction, error) {
+	inst := req.Instruction
+	if inst == nil {
+		return nil, status.Error(codes.InvalidArgument, "instruction is required")
+	}
+	switch inst.Kind.(type) {
+	case *agentv1.AgentInstruction_Config, *agentv1.AgentInstruction_Drain:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "only config and drain instructions can be queued; use ApplyStack or RemoveStack")
+	}
+
+	agent, exists := c.agents.get(req.AgentId)
+	if !exists {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+
+	if err := c.authorizeAgent(ctx, agent, "write", "instruction"); err != nil {
+		return nil, err
+	}
+
+	inst.RequestedBy = ""
+	if identity := plugin.IdentityFromContext(ctx); identity != nil {
+		inst.RequestedBy = identity.UserID
+	}
+	inst.RequestId = requestid.From(ctx)
+
+	queued, err := c.instructions.enqueue(agent.ID, inst, 0, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Queued %s instruction %s for agent %s", instructionKind(queued), queued.Id, agent.ID)
+	return queued, nil
+}
+
+// ListAgentInstructions returns the instructions an agent has not yet
+// reported on. Without an agent it returns those of every agent the caller
+// may read instructions of.
+func (c *Core) ListAgentInstructions(ctx context.Context, req *agentv1.ListAgentInstructionsRequest) (*agentv1.ListAgentInstructionsResponse, error) {
+	if req.AgentId == "" {
+		resp := &agentv1.ListAgentInstructionsResponse{}
+		allowed := make(map[string]bool)
+		for _, inst := range c.instructions.list("") {
+			ok, seen := allowed[inst.AgentId]
+			if !seen {
+				agent, err := c.instructionAgent(inst.AgentId)
+				ok = err == nil && c.authorizeAgent(ctx, agent, "read", "instruction") == nil
+				allowed[inst.AgentId] = ok
+			}
+			if ok {
+				resp.Pending = append(resp.Pending, inst)
+			}
+		}
+		return resp, nil
+	}
+
+	agent, err := c.instructionAgent(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.authorizeAgent(ctx, agent, "read", "instruction"); err != nil {
+		return nil, err
+	}
+
+	return &agentv1.ListAgentInstructionsResponse{Pending: c.instructions.list(agent.ID)}, nil
+}
+
+// CancelAgentInstruction drops an instruction the agent has not received yet
+func (c *Core) CancelAgentInstruction(ctx context.Context, req *agentv1.CancelAgentInstructionRequest) (*agentv1.AgentInstruction, error) {
+	agent, err := c.instructionAgent(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.authorizeAgent(ctx, agent, "write", "instruction"); err != nil {
+		return nil, err
+	}
+
+	inst, err := c.instructions.cancel(agent.ID, req.InstructionId)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Cancelled %s instruction %s for agent %s", instructionKind(inst), inst.Id, agent.ID)
+
+	identity := &plugin.Identity{}
+	if caller := plugin.IdentityFromContext(ctx); caller != nil {
+		identity = caller
+	}
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		AgentID:   agent.ID,
+		Identity:  identity,
+		Action:    "instruction." + instructionKind(inst),
+		Resource:  "agent:" + agent.ID,
+		Result:    "cancelled",
+		Metadata: map[string]string{
+			"instruction_id": inst.Id,
+			"requested_by":   inst.RequestedBy,
+		},
+	})
+	return inst, nil
+}
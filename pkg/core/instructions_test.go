@@ -0,0 +1,161 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func drainInstruction() *agentv1.AgentInstruction {
+	return &agentv1.AgentInstruction{Kind: &agentv1.AgentInstruction_Drain{Drain: &agentv1.DrainInstruction{Enabled: true}}}
+}
+
+func TestInstructionQueue(t *testing.T) {
+	q, _ := newInstructionQueue(config.OfflineQueueConfig{})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := q.enqueue("a1", drainInstruction(), 0, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := q.enqueue("a1", drainInstruction(), 0, now)
+	q.enqueue("a2", drainInstruction(), 0, now)
+
+	if got := q.deliver("a1", now); len(got) != 2 || got[0].Id != first.Id || got[1].Id != second.Id {
+		t.Fatalf("first delivery = %v", got)
+	}
+	if got := q.deliver("a1", now.Add(time.Second)); len(got) != 0 {
+		t.Errorf("delivered again before the redelivery delay: %v", got)
+	}
+
+	done := q.complete("a1", []*agentv1.InstructionResult{{InstructionId: first.Id, Success: true}, {InstructionId: "unknown"}})
+	if len(done) != 1 || done[0].Id != first.Id {
+		t.Errorf("complete() = %v", done)
+	}
+
+	// Without a result the second instruction is sent again
+	if got := q.deliver("a1", now.Add(instructionRedelivery)); len(got) != 1 || got[0].Id != second.Id {
+		t.Errorf("redelivery = %v", got)
+	}
+	if got := q.list("a2"); len(got) != 1 {
+		t.Errorf("other agent's queue = %v", got)
+	}
+}
+
+func TestInstructionQueueLimit(t *testing.T) {
+	q, _ := newInstructionQueue(config.OfflineQueueConfig{})
+	for i := 0; i < maxPendingInstructions; i++ {
+		if _, err := q.enqueue("a1", drainInstruction(), 0, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := q.enqueue("a1", drainInstruction(), 0, time.Now()); err == nil {
+		t.Error("enqueue beyond the limit succeeded")
+	}
+}
+
+func TestInstructionQueueExpiry(t *testing.T) {
+	q, _ := newInstructionQueue(config.OfflineQueueConfig{})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stale, _ := q.enqueue("a1", drainInstruction(), time.Hour, now)
+	running, _ := q.enqueue("a2", drainInstruction(), time.Hour, now)
+	forever, _ := q.enqueue("a1", drainInstruction(), 0, now)
+	q.deliver("a2", now)
+
+	later := now.Add(time.Hour)
+	if got := q.deliver("a1", later); len(got) != 1 || got[0].Id != forever.Id {
+		t.Errorf("delivered after expiry = %v", got)
+	}
+
+	dropped := q.expire(later)
+	if len(dropped) != 1 || dropped[0].Id != stale.Id {
+		t.Errorf("expire() = %v", dropped)
+	}
+	// Delivered instructions may be running, so they are kept
+	if got := q.list("a2"); len(got) != 1 || got[0].Id != running.Id {
+		t.Errorf("delivered instruction dropped: %v", got)
+	}
+}
+
+func TestInstructionQueueCancel(t *testing.T) {
+	q, _ := newInstructionQueue(config.OfflineQueueConfig{})
+	now := time.Now()
+
+	waiting, _ := q.enqueue("a1", drainInstruction(), 0, now)
+	sent, _ := q.enqueue("a2", drainInstruction(), 0, now)
+	q.deliver("a2", now)
+
+	if _, err := q.cancel("a1", waiting.Id); err != nil {
+		t.Fatalf("cancel() error = %v", err)
+	}
+	if q.has("a1") {
+		t.Error("cancelled instruction is still queued")
+	}
+	if _, err := q.cancel("a2", sent.Id); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("cancel of a delivered instruction: %v", err)
+	}
+	if _, err := q.cancel("a1", "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("cancel of an unknown instruction: %v", err)
+	}
+}
+
+func TestInstructionQueueFile(t *testing.T) {
+	cfg := config.OfflineQueueConfig{File: filepath.Join(t.TempDir(), "instructions.json")}
+	q, err := newInstructionQueue(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	first, _ := q.enqueue("a1", drainInstruction(), time.Hour, now)
+	second, _ := q.enqueue("a2", drainInstruction(), 0, now.Add(time.Second))
+	q.enqueue("a1", drainInstruction(), 0, now)
+	q.complete("a1", []*agentv1.InstructionResult{{InstructionId: q.list("a1")[1].Id}})
+
+	reloaded, err := newInstructionQueue(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.list("")
+	if len(got) != 2 || got[0].Id != first.Id || got[1].Id != second.Id {
+		t.Fatalf("reloaded queue = %v", got)
+	}
+	if !got[0].ExpiresAt.AsTime().Equal(first.ExpiresAt.AsTime()) {
+		t.Errorf("expiry not kept: %v", got[0].ExpiresAt)
+	}
+	// Delivery state is not stored, so everything goes out again
+	if got := reloaded.deliver("a2", now); len(got) != 1 {
+		t.Errorf("deliver after reload = %v", got)
+	}
+}
+
+func TestInstructionQueueTTL(t *testing.T) {
+	q, _ := newInstructionQueue(config.OfflineQueueConfig{DefaultTTL: "1h", MaxTTL: "2h"})
+
+	tests := []struct {
+		name      string
+		requested *durationpb.Duration
+		want      time.Duration
+		wantErr   bool
+	}{
+		{"default", nil, time.Hour, false},
+		{"zero", durationpb.New(0), time.Hour, false},
+		{"within max", durationpb.New(2 * time.Hour), 2 * time.Hour, false},
+		{"over max", durationpb.New(3 * time.Hour), 0, true},
+		{"negative", durationpb.New(-time.Minute), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.ttl(tt.requested)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ttl() = %v, %v; want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// inventoryServices are the parts of an inventory read from host services,
+// with the capability serving each
+var inventoryServices = map[string]string{
+	"host":          capability.Host,
+	"virtual_hosts": capability.Nginx,
+	"dns_zones":     capability.DNS,
+	"certificates":  capability.ACME,
+}
+
+// GetInventory collects what every selected agent runs, in parallel. Each
+// part is read from agents serving it that the caller may read: "read" on
+// "host:<capability>" for host services, and stacks in namespaces the
+// caller may read. Agents that cannot be reached are listed with their
+// registration alone and reported in agent_errors; parts that fail on an
+// agent are reported in its errors.
+func (c *Core) GetInventory(ctx context.Context, req *agentv1.GetInventoryRequest) (*agentv1.Inventory, error) {
+	selected, err := c.selectAgents(req.Labels, req.Group, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &agentv1.Inventory{GeneratedAt: timestamppb.Now()}
+	agentError := func(id string, err error) {
+		if resp.AgentErrors == nil {
+			resp.AgentErrors = make(map[string]string)
+		}
+		resp.AgentErrors[id] = status.Convert(err).Message()
+	}
+
+	byID := make(map[string]*agentv1.AgentInventory, len(selected))
+	for _, agent := range selected {
+		agent.mu.RLock()
+		inv := &agentv1.AgentInventory{Agent: c.toProtoAgent(agent)}
+		agent.mu.RUnlock()
+		resp.Agents = append(resp.Agents, inv)
+		byID[agent.ID] = inv
+	}
+
+	parts := make(map[string]map[string]bool, len(selected))
+	var reachable []*AgentConnection
+	for _, agent := range selected {
+		conn, err := c.getAgentConnection(agent.ID)
+		if err != nil {
+			agentError(agent.ID, err)
+			continue
+		}
+		parts[conn.ID] = c.inventoryParts(ctx, conn)
+		reachable = append(reachable, conn)
+	}
+
+	results := fanOut(ctx, c.fanOut, reachable, func(ctx context.Context, conn *AgentConnection) (*agentv1.AgentInventory, error) {
+		inv := byID[conn.ID]
+		readInventory(ctx, conn, parts[conn.ID], inv)
+		return inv, nil
+	})
+
+	reader := c.newStackReader(ctx)
+	for _, r := range results {
+		if r.err != nil {
+			agentError(r.conn.ID, r.err)
+			continue
+		}
+		readable := r.value.Stacks[:0]
+		for _, stack := range r.value.Stacks {
+			if reader.can(r.conn.ID, normalizeNamespace(stack.Namespace)) {
+				readable = append(readable, stack)
+			}
+		}
+		r.value.Stacks = readable
+	}
+	return resp, nil
+}
+
+// inventoryParts reports which parts of conn's inventory the caller may
+// read and conn serves
+func (c *Core) inventoryParts(ctx context.Context, conn *AgentConnection) map[string]bool {
+	parts := map[string]bool{
+		"stacks": requireCapability(conn, agentv1.StackService_ListStacks_FullMethodName) == nil,
+	}
+	for part, cap := range inventoryServices {
+		parts[part] = capability.Has(conn.Capabilities, cap) &&
+			c.authorizeAgent(ctx, conn, "read", "host:"+cap) == nil
+	}
+	return parts
+}
+
+// readInventory fills inv with the parts of conn's inventory in parts. A
+// part that fails is recorded in inv.Errors and does not stop the others.
+func readInventory(ctx context.Context, conn *AgentConnection, parts map[string]bool, inv *agentv1.AgentInventory) {
+	fail := func(part string, err error) {
+		if inv.Errors == nil {
+			inv.Errors = make(map[string]string)
+		}
+		inv.Errors[part] = status.Convert(err).Message()
+	}
+
+	if parts["host"] {
+		info, err := agentv1.NewHostEnvironmentServiceClient(conn.Client).GetHostInfo(ctx, &agentv1.GetHostInfoRequest{AgentId: conn.ID})
+		if err != nil {
+			fail("host", err)
+		} else {
+			inv.Host = &agentv1.InventoryHost{
+				Hostname:     info.Hostname,
+				Os:           info.Os,
+				Kernel:       info.Kernel,
+				Architecture: info.Architecture,
+				CpuCores:     info.CpuCores,
+				MemoryMb:     info.MemoryMb,
+				DiskGb:       info.DiskGb,
+			}
+		}
+	}
+
+	if parts["stacks"] {
+		resp, err := agentv1.NewStackServiceClient(conn.Client).ListStacks(ctx, &agentv1.ListStacksRequest{
+			AgentId:      conn.ID,
+			ImageDigests: true,
+		})
+		if err != nil {
+			fail("stacks", err)
+		} else {
+			inv.Stacks = resp.Stacks
+		}
+	}
+
+	if parts["virtual_hosts"] {
+		resp, err := agentv1.NewNginxServiceClient(conn.Client).ListVirtualHosts(ctx, &agentv1.ListVirtualHostsRequest{AgentId: conn.ID})
+		if err != nil {
+			fail("virtual_hosts", err)
+		} else {
+			enabled := make(map[string]bool, len(resp.Enabled))
+			for _, name := range resp.Enabled {
+				enabled[name] = true
+			}
+			for _, name := range resp.Vhosts {
+				inv.VirtualHosts = append(inv.VirtualHosts, &agentv1.InventoryVirtualHost{Name: name, Enabled: enabled[name]})
+			}
+		}
+	}
+
+	if parts["dns_zones"] {
+		resp, err := agentv1.NewDNSServiceClient(conn.Client).ListZones(ctx, &agentv1.ListZonesRequest{AgentId: conn.ID})
+		if err != nil {
+			fail("dns_zones", err)
+		} else {
+			inv.DnsZones = resp.Zones
+		}
+	}
+
+	if parts["certificates"] {
+		resp, err := agentv1.NewACMEServiceClient(conn.Client).ListCertificates(ctx, &agentv1.ListCertificatesRequest{AgentId: conn.ID})
+		if err != nil {
+			fail("certificates", err)
+		} else {
+			for _, cert := range resp.Certificates {
+				inv.Certificates = append(inv.Certificates, &agentv1.InventoryCertificate{
+					Domain:    cert.Domain,
+					Issuer:    cert.Issuer,
+					ExpiresAt: cert.ExpiresAt,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inventoryAgent runs one stack, one vhost and one zone, and cannot list
+// its certificates
+type inventoryAgent struct {
+	agentv1.UnimplementedStackServiceServer
+	agentv1.UnimplementedHostEnvironmentServiceServer
+	agentv1.UnimplementedNginxServiceServer
+	agentv1.UnimplementedDNSServiceServer
+	agentv1.UnimplementedACMEServiceServer
+}
+
+func (a *inventoryAgent) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
+	container := &agentv1.Container{Name: "shop-web-1", Image: "nginx:1.27", ImageId: "sha256:abc"}
+	if req.ImageDigests {
+		container.ImageDigests = []string{"nginx@sha256:def"}
+	}
+	return &agentv1.ListStacksResponse{Stacks: []*agentv1.Stack{{Name: "shop", Containers: []*agentv1.Container{container}}}}, nil
+}
+
+func (a *inventoryAgent) GetHostInfo(ctx context.Context, req *agentv1.GetHostInfoRequest) (*agentv1.GetHostInfoResponse, error) {
+	return &agentv1.GetHostInfoResponse{Hostname: req.AgentId + ".example.com", Os: "Debian 12", CpuCores: 4}, nil
+}
+
+func (a *inventoryAgent) ListVirtualHosts(ctx context.Context, req *agentv1.ListVirtualHostsRequest) (*agentv1.ListVirtualHostsResponse, error) {
+	return &agentv1.ListVirtualHostsResponse{Vhosts: []string{"old.example.com", "shop.example.com"}, Enabled: []string{"shop.example.com"}}, nil
+}
+
+func (a *inventoryAgent) ListZones(ctx context.Context, req *agentv1.ListZonesRequest) (*agentv1.ListZonesResponse, error) {
+	return &agentv1.ListZonesResponse{Zones: []string{"example.com"}}, nil
+}
+
+func (a *inventoryAgent) ListCertificates(ctx context.Context, req *agentv1.ListCertificatesRequest) (*agentv1.ListCertificatesResponse, error) {
+	return nil, status.Error(codes.Internal, "certbot not found")
+}
+
+func TestGetInventory(t *testing.T) {
+	server := grpc.NewServer()
+	fake := &inventoryAgent{}
+	agentv1.RegisterStackServiceServer(server, fake)
+	agentv1.RegisterHostEnvironmentServiceServer(server, fake)
+	agentv1.RegisterNginxServiceServer(server, fake)
+	agentv1.RegisterDNSServiceServer(server, fake)
+	agentv1.RegisterACMEServiceServer(server, fake)
+	client := serve(t, server)
+
+	groups, err := newGroupRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := map[string]string{"role": "web"}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Labels: web, Capabilities: []string{"docker", "host", "nginx", "dns", "acme"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-2", Labels: web, Capabilities: []string{"docker"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-3", Labels: web, Capabilities: []string{"docker", "host"}, Status: AgentStatusOffline, LastSeen: time.Now().Add(-time.Hour)},
+			&AgentConnection{ID: "db-1", Labels: map[string]string{"role": "db"}, Capabilities: []string{"docker"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		groups:   groups,
+		breakers: newCircuitBreakers(config.CircuitBreakerConfig{}),
+		plugins:  plugin.NewRegistry(),
+		fanOut:   newFanOutLimits(config.FanOutConfig{}),
+	}
+
+	inv, err := c.GetInventory(context.Background(), &agentv1.GetInventoryRequest{Labels: web})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Agents) != 3 || inv.Agents[0].Agent.Id != "web-1" || inv.Agents[2].Agent.Id != "web-3" {
+		t.Fatalf("agents = %v, want web-1 to web-3", inv.Agents)
+	}
+
+	full := inv.Agents[0]
+	if full.Host.GetHostname() != "web-1.example.com" || full.Host.GetCpuCores() != 4 {
+		t.Errorf("web-1 host = %v", full.Host)
+	}
+	if len(full.Stacks) != 1 || len(full.Stacks[0].Containers[0].ImageDigests) != 1 {
+		t.Errorf("web-1 stacks = %v, want shop with its image digest", full.Stacks)
+	}
+	if len(full.VirtualHosts) != 2 || full.VirtualHosts[0].Enabled || !full.VirtualHosts[1].Enabled {
+		t.Errorf("web-1 vhosts = %v, want old disabled and shop enabled", full.VirtualHosts)
+	}
+	if len(full.DnsZones) != 1 || full.DnsZones[0] != "example.com" {
+		t.Errorf("web-1 zones = %v", full.DnsZones)
+	}
+	if len(full.Certificates) != 0 || full.Errors["certificates"] != "certbot not found" {
+		t.Errorf("web-1 certificates = %v, errors = %v, want the failure recorded", full.Certificates, full.Errors)
+	}
+
+	// Parts an agent does not serve are left empty rather than failing
+	stacksOnly := inv.Agents[1]
+	if stacksOnly.Host != nil || len(stacksOnly.VirtualHosts) != 0 || len(stacksOnly.Stacks) != 1 || len(stacksOnly.Errors) != 0 {
+		t.Errorf("web-2 = %v, want its stacks alone", stacksOnly)
+	}
+
+	if offline := inv.Agents[2]; offline.Host != nil || len(offline.Stacks) != 0 || inv.AgentErrors["web-3"] == "" || len(inv.AgentErrors) != 1 {
+		t.Errorf("web-3 = %v, agent errors = %v, want its registration and the error", offline, inv.AgentErrors)
+	}
+
+	if _, err := c.GetInventory(context.Background(), &agentv1.GetInventoryRequest{Group: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown group: error = %v, want not found", err)
+	}
+}
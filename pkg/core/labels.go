@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UpdateAgentLabels sets and removes operator labels on a registered agent.
+// Operator labels take precedence over those the agent reports, and removing
+// a reported label hides it until an operator sets it again.
+func (c *Core) UpdateAgentLabels(ctx context.Context, req *agentv1.UpdateAgentLabelsRequest) (*agentv1.UpdateAgentLabelsResponse, error) {
+	agent, exists := c.agents.get(req.AgentId)
+	if !exists {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+
+	if err := c.authorizeAgent(ctx, agent, "write", "labels"); err != nil {
+		return nil, err
+	}
+
+	// The agent may have re-registered while we were authorizing
+	agent, exists = c.agents.lock(req.AgentId)
+	if !exists {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+	defer agent.mu.Unlock()
+
+	operator := mergeLabels(agent.OperatorLabels, req.Set)
+	removed := make(map[string]bool, len(agent.RemovedLabels)+len(req.Remove))
+	for k := range agent.RemovedLabels {
+		if _, set := req.Set[k]; !set {
+			removed[k] = true
+		}
+	}
+	for _, k := range req.Remove {
+		delete(operator, k)
+		removed[k] = true
+	}
+
+	// Readers may hold the previous maps, so replace rather than mutate them
+	agent.OperatorLabels = operator
+	agent.RemovedLabels = removed
+	agent.Labels = agent.effectiveLabels()
+
+	return &agentv1.UpdateAgentLabelsResponse{
+		Agent: c.toProtoAgent(agent),
+	}, nil
+}
+
+// effectiveLabels combines reported and operator labels into a new map
+func (a *AgentConnection) effectiveLabels() map[string]string {
+	labels := make(map[string]string, len(a.ReportedLabels)+len(a.OperatorLabels))
+	for k, v := range a.ReportedLabels {
+		if !a.RemovedLabels[k] {
+			labels[k] = v
+		}
+	}
+	for k, v := range a.OperatorLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// matchLabels reports whether labels contains every key/value in selector
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns a copy of base overlaid with the entries of overlay
+func mergeLabels(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (c *Core) toProtoAgent(agent *AgentConnection) *agentv1.Agent {
+	pa := &agentv1.Agent{
+		Id:                 agent.ID,
+		Hostname:           agent.Hostname,
+		Status:             string(agent.Status),
+		Labels:             mergeLabels(agent.Labels, nil),
+		Capabilities:       append([]string(nil), agent.Capabilities...),
+		LastSeen:           timestamppb.New(agent.LastSeen),
+		Groups:             c.groups.groupsFor(agent),
+		Maintenance:        toProtoMaintenance(agent.Maintenance),
+		Circuit:            c.breakers.state(agent.ID),
+		UnavailablePlugins: agent.Unavailable,
+	}
+	if agent.ClockSkew != nil {
+		pa.ClockSkew = durationpb.New(*agent.ClockSkew)
+	}
+	return pa
+}
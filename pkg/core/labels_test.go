@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"sort"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+func TestAgentLabels(t *testing.T) {
+	ids, err := newAgentIdentities("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := newGroupRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins := plugin.NewRegistry()
+	c := &Core{
+		plugins:         plugins,
+		agentIdentities: ids,
+		groups:          groups,
+		agents:          newAgentRegistry(),
+		breakers:        newCircuitBreakers(config.CircuitBreakerConfig{}),
+		audit:           NewAuditLogger(plugins),
+	}
+	ctx := context.Background()
+
+	register := func(agentID string, labels map[string]string) {
+		t.Helper()
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: agentID}, Raw: []byte(agentID)}
+		if _, err := c.RegisterAgent(peerContext(cert), &agentv1.RegisterRequest{AgentId: agentID, Labels: labels}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	labelsOf := func(agentID string) map[string]string {
+		t.Helper()
+		agent, ok := c.agents.get(agentID)
+		if !ok {
+			t.Fatalf("%s not registered", agentID)
+		}
+		agent.mu.RLock()
+		defer agent.mu.RUnlock()
+		return agent.Labels
+	}
+	update := func(req *agentv1.UpdateAgentLabelsRequest) map[string]string {
+		t.Helper()
+		resp, err := c.UpdateAgentLabels(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := labelsOf(req.AgentId); !reflect.DeepEqual(got, resp.Agent.Labels) {
+			t.Errorf("response labels %v differ from the agent's %v", resp.Agent.Labels, got)
+		}
+		return resp.Agent.Labels
+	}
+
+	register("web-1", map[string]string{"role": "web", "env": "staging", "zone": "eu-1"})
+	register("db-1", map[string]string{"role": "db", "env": "prod"})
+
+	// Operator labels override reported ones and add to them
+	got := update(&agentv1.UpdateAgentLabelsRequest{AgentId: "web-1", Set: map[string]string{"env": "prod", "team": "shop"}})
+	want := map[string]string{"role": "web", "env": "prod", "zone": "eu-1", "team": "shop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after set = %v, want %v", got, want)
+	}
+
+	// Removing hides reported labels and drops operator ones
+	got = update(&agentv1.UpdateAgentLabelsRequest{AgentId: "web-1", Remove: []string{"zone", "team"}})
+	want = map[string]string{"role": "web", "env": "prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after remove = %v, want %v", got, want)
+	}
+
+	// The agent re-registering with other labels keeps the operator's
+	// changes; labels it stopped reporting go away
+	register("web-1", map[string]string{"role": "frontend", "env": "staging", "zone": "eu-2", "arch": "arm64"})
+	want = map[string]string{"role": "frontend", "env": "prod", "arch": "arm64"}
+	if got := labelsOf("web-1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("after re-registration = %v, want %v", got, want)
+	}
+
+	// Setting a removed label shows it again, at the operator's value
+	got = update(&agentv1.UpdateAgentLabelsRequest{AgentId: "web-1", Set: map[string]string{"zone": "eu-3"}})
+	want = map[string]string{"role": "frontend", "env": "prod", "arch": "arm64", "zone": "eu-3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after setting a removed label = %v, want %v", got, want)
+	}
+
+	// Listings filter on the effective labels
+	tests := []struct {
+		selector map[string]string
+		want     []string
+	}{
+		{nil, []string{"db-1", "web-1"}},
+		{map[string]string{"env": "prod"}, []string{"db-1", "web-1"}},
+		// Reported, but overridden by the operator
+		{map[string]string{"env": "staging"}, nil},
+		{map[string]string{"role": "frontend", "zone": "eu-3"}, []string{"web-1"}},
+		// Reported before re-registration only
+		{map[string]string{"role": "web"}, nil},
+	}
+	for _, tt := range tests {
+		resp, err := c.ListAgents(ctx, &agentv1.ListAgentsRequest{Labels: tt.selector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var listed []string
+		for _, agent := range resp.Agents {
+			listed = append(listed, agent.Id)
+		}
+		sort.Strings(listed)
+		if !reflect.DeepEqual(listed, tt.want) {
+			t.Errorf("ListAgents(%v) = %v, want %v", tt.selector, listed, tt.want)
+		}
+	}
+
+	if _, err := c.UpdateAgentLabels(ctx, &agentv1.UpdateAgentLabelsRequest{AgentId: "gone-1"}); err == nil {
+		t.Error("labelling an unknown agent succeeded")
+	}
+}
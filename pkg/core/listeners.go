@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+
+	"github.com/bhangun/mandau/pkg/daemon"
+)
+
+// unixSocketMode lets the mandau group's local tools connect; they still
+// need a client certificate
+const unixSocketMode = 0660
+
+// openListeners opens the API listeners: the sockets systemd passed if the
+// core was socket activated, otherwise listen_addr, each of listen_addrs
+// and unix_socket. The addresses are kept for diagnostics.
+func (c *Core) openListeners() ([]net.Listener, error) {
+	activated, err := daemon.Listeners()
+	if err != nil {
+		return nil, fmt.Errorf("socket activation: %w", err)
+	}
+	if len(activated) > 0 {
+		names := make([]string, 0, len(activated))
+		for name := range activated {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var listeners []net.Listener
+		for _, name := range names {
+			for _, lis := range activated[name] {
+				c.recordListener(lis, "systemd "+name)
+				listeners = append(listeners, lis)
+			}
+		}
+		return listeners, nil
+	}
+
+	addrs := []string{c.config.ListenAddr}
+	var unixSocket string
+	if full := c.config.FullConfig; full != nil {
+		addrs = append(addrs, full.Server.ListenAddrs...)
+		unixSocket = full.Server.UnixSocket
+	}
+
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, lis := range listeners {
+			lis.Close()
+		}
+	}
+	for _, addr := range addrs {
+		if addr == "" {
+			continue
+		}
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("listen on %s: %w", addr, err)
+		}
+		c.recordListener(lis, "")
+		listeners = append(listeners, lis)
+	}
+
+	if unixSocket != "" {
+		lis, err := listenUnix(unixSocket)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		c.recordListener(lis, "")
+		listeners = append(listeners, lis)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listen address configured")
+	}
+	return listeners, nil
+}
+
+// listenUnix listens on a unix socket, replacing a stale socket file left
+// by an earlier run
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("unix socket %s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale unix socket: %w", err)
+		}
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, unixSocketMode); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("unix socket permissions: %w", err)
+	}
+	return lis, nil
+}
+
+// recordListener logs a listener and keeps its address for Diagnose
+func (c *Core) recordListener(lis net.Listener, source string) {
+	addr := lis.Addr().Network() + "://" + lis.Addr().String()
+	if source != "" {
+		addr += " (" + source + ")"
+	}
+	log.Printf("Core listening on %s", addr)
+	c.listenAddrs = append(c.listenAddrs, addr)
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestOpenListeners(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	socket := filepath.Join(t.TempDir(), "core.sock")
+
+	// A socket file left by a crashed core is replaced
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	c := &Core{config: &CoreConfig{
+		ListenAddr: "127.0.0.1:0",
+		FullConfig: &config.CoreConfig{Server: config.ServerConfig{
+			ListenAddrs: []string{"127.0.0.1:0"},
+			UnixSocket:  socket,
+		}},
+	}}
+	listeners, err := c.openListeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, lis := range listeners {
+			lis.Close()
+		}
+	}()
+
+	if len(listeners) != 3 || len(c.listenAddrs) != 3 {
+		t.Fatalf("opened %v", c.listenAddrs)
+	}
+	if !strings.HasPrefix(c.listenAddrs[2], "unix://") {
+		t.Errorf("unix socket not advertised: %v", c.listenAddrs)
+	}
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != unixSocketMode {
+		t.Errorf("socket mode = %v", info.Mode().Perm())
+	}
+
+	// A socket another process serves is left alone
+	other := &Core{config: &CoreConfig{FullConfig: &config.CoreConfig{Server: config.ServerConfig{UnixSocket: socket}}}}
+	if _, err := other.openListeners(); err == nil {
+		t.Error("took over a socket in use")
+	}
+}
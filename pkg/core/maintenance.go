@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"log"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultMaintenanceDuration bounds maintenance windows opened without an
+// explicit duration so an agent is never left in maintenance forever
+const defaultMaintenanceDuration = time.Hour
+
+// MaintenanceWindow marks an agent as under planned downtime. While active the
+// core suppresses offline alerts and rejects non-emergency deploys.
+type MaintenanceWindow struct {
+	Reason string
+	SetBy  string
+	Since  time.Time
+	Until  time.Time
+}
+
+// SetAgentMaintenance turns maintenance mode on or off for an agent
+func (c *Core) SetAgentMaintenance(ctx context.Context, req *agentv1.SetAgentMaintenanceRequest) (*agentv1.SetAgentMaintenanceResponse, error) {
+	agent, exists := c.agents.get(req.AgentId)
+	if !exists {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+
+	if err := c.authorizeAgent(ctx, agent, "write", "maintenance"); err != nil {
+		return nil, err
+	}
+
+	// The agent may have registered again since
+	agent, exists = c.agents.lock(req.AgentId)
+	if !exists {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+	defer agent.mu.Unlock()
+
+	if !req.Enabled {
+		agent.Maintenance = nil
+		log.Printf("Agent %s left maintenance", agent.ID)
+		return &agentv1.SetAgentMaintenanceResponse{Agent: c.toProtoAgent(agent)}, nil
+	}
+
+	duration := req.Duration.AsDuration()
+	if duration <= 0 {
+		duration = defaultMaintenanceDuration
+	}
+
+	setBy := ""
+	if identity := plugin.IdentityFromContext(ctx); identity != nil {
+		setBy = identity.UserID
+	}
+
+	now := time.Now()
+	agent.Maintenance = &MaintenanceWindow{
+		Reason: req.Reason,
+		SetBy:  setBy,
+		Since:  now,
+		Until:  now.Add(duration),
+	}
+	log.Printf("Agent %s entered maintenance until %s: %s",
+		agent.ID, agent.Maintenance.Until.Format(time.RFC3339), req.Reason)
+
+	return &agentv1.SetAgentMaintenanceResponse{Agent: c.toProtoAgent(agent)}, nil
+}
+
+// inMaintenance reports whether the agent has an unexpired maintenance window.
+// Callers must hold the agent's lock.
+func (a *AgentConnection) inMaintenance(now time.Time) bool {
+	return a.Maintenance != nil && now.Before(a.Maintenance.Until)
+}
+
+// expireMaintenance clears the agent's maintenance window once it has run
+// out, and reports whether it did. Callers must hold the agent's write lock.
+func (a *AgentConnection) expireMaintenance(now time.Time) bool {
+	if a.Maintenance == nil || a.inMaintenance(now) {
+		return false
+	}
+	a.Maintenance = nil
+	log.Printf("Maintenance window for agent %s expired", a.ID)
+	return true
+}
+
+// requireDeployAllowed rejects deploys to an agent in maintenance unless the
+// request is flagged as an emergency and the caller holds the "emergency"
+// action on the resource
+func (c *Core) requireDeployAllowed(ctx context.Context, conn *AgentConnection, namespace, resource string, emergency bool) error {
+	conn.mu.RLock()
+	inMaintenance := conn.inMaintenance(time.Now())
+	var window MaintenanceWindow
+	if inMaintenance {
+		window = *conn.Maintenance
+	}
+	conn.mu.RUnlock()
+
+	if !inMaintenance {
+		return nil
+	}
+
+	if !emergency {
+		return transport.Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+			Code:      agentv1.ErrorCode_ERROR_CODE_MAINTENANCE,
+			Resource:  resource,
+			AgentId:   conn.ID,
+			Retryable: true,
+			Hint:      "retry after " + window.Until.Format(time.RFC3339) + ", or pass --emergency",
+		}, "agent %s is in maintenance until %s (%s); retry with emergency to override",
+			conn.ID, window.Until.Format(time.RFC3339), window.Reason)
+	}
+
+	if err := c.authorizeNamespaced(ctx, conn, "emergency", namespace, resource); err != nil {
+		return err
+	}
+	log.Printf("Emergency deploy of %s to agent %s during maintenance", resource, conn.ID)
+	return nil
+}
+
+func toProtoMaintenance(m *MaintenanceWindow) *agentv1.Maintenance {
+	if m == nil || !time.Now().Before(m.Until) {
+		return nil
+	}
+	return &agentv1.Maintenance{
+		Reason: m.Reason,
+		SetBy:  m.SetBy,
+		Since:  timestamppb.New(m.Since),
+		Until:  timestamppb.New(m.Until),
+	}
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/marketplace"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetPluginIndex describes the plugins in the signed index the core hosts
+func (c *Core) GetPluginIndex(ctx context.Context, req *agentv1.GetPluginIndexRequest) (*agentv1.PluginIndex, error) {
+	if err := c.authorizePlugins(ctx, "read"); err != nil {
+		return nil, err
+	}
+
+	data, err := c.pluginIndex()
+	if err != nil {
+		return nil, err
+	}
+	index, signed, err := marketplace.Parse(data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "plugin index: %v", err)
+	}
+
+	result := &agentv1.PluginIndex{KeyId: signed.KeyID}
+	if !index.Published.IsZero() {
+		result.Published = timestamppb.New(index.Published)
+	}
+	for _, p := range index.Plugins {
+		indexed := &agentv1.IndexedPlugin{Name: p.Name, Description: p.Description}
+		platforms := make(map[string]bool)
+		for _, r := range p.Releases {
+			indexed.Versions = append(indexed.Versions, r.Version)
+			for _, a := range r.Artifacts {
+				platforms[a.OS+"/"+a.Arch] = true
+			}
+		}
+		sort.Slice(indexed.Versions, func(i, j int) bool {
+			return marketplace.CompareVersions(indexed.Versions[i], indexed.Versions[j]) > 0
+		})
+		for platform := range platforms {
+			indexed.Platforms = append(indexed.Platforms, platform)
+		}
+		sort.Strings(indexed.Platforms)
+		result.Plugins = append(result.Plugins, indexed)
+	}
+	sort.Slice(result.Plugins, func(i, j int) bool { return result.Plugins[i].Name < result.Plugins[j].Name })
+	return result, nil
+}
+
+// InstallPlugin has an agent install a plugin from the core's index. The
+// agent checks the index signature against its own trusted keys, so a
+// compromised core cannot push binaries the agent's operators did not sign.
+func (c *Core) InstallPlugin(ctx context.Context, req *agentv1.InstallPluginRequest) (*agentv1.InstalledPlugin, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := c.authorizeAgent(ctx, conn, "install", "plugin:"+req.Name); err != nil {
+		return nil, err
+	}
+
+	data, err := c.pluginIndex()
+	if err != nil {
+		return nil, err
+	}
+	forward := proto.Clone(req).(*agentv1.InstallPluginRequest)
+	forward.SignedIndex = data
+
+	installed, err := agentv1.NewAgentServiceClient(conn.Client).InstallPlugin(ctx, forward)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	installed.AgentId = req.AgentId
+
+	c.recordInstalledPlugin(req.AgentId, installed)
+	return installed, nil
+}
+
+// ListInstalledPlugins lists the plugins installed on one agent or on every
+// agent the caller may read plugins of, as last reported to the core
+func (c *Core) ListInstalledPlugins(ctx context.Context, req *agentv1.ListInstalledPluginsRequest) (*agentv1.ListInstalledPluginsResponse, error) {
+	var conns []*AgentConnection
+	if req.AgentId == "" {
+		conns = c.agents.all()
+	} else if agent, ok := c.agents.get(req.AgentId); ok {
+		conns = []*AgentConnection{agent}
+	}
+
+	if req.AgentId != "" && len(conns) == 0 {
+		return nil, errAgentNotFound(req.AgentId)
+	}
+
+	resp := &agentv1.ListInstalledPluginsResponse{}
+	for _, conn := range conns {
+		if err := c.authorizeAgent(ctx, conn, "read", "plugin:*"); err != nil {
+			if req.AgentId != "" {
+				return nil, err
+			}
+			continue
+		}
+
+		conn.mu.RLock()
+		plugins := conn.Plugins
+		conn.mu.RUnlock()
+		for _, p := range plugins {
+			p = proto.Clone(p).(*agentv1.InstalledPlugin)
+			p.AgentId = conn.ID
+			resp.Plugins = append(resp.Plugins, p)
+		}
+	}
+
+	sort.Slice(resp.Plugins, func(i, j int) bool {
+		a, b := resp.Plugins[i], resp.Plugins[j]
+		if a.AgentId != b.AgentId {
+			return a.AgentId < b.AgentId
+		}
+		return a.Name < b.Name
+	})
+	return resp, nil
+}
+
+// DescribePlugin asks an agent about a plugin it runs and the host access
+// the plugin is confined to
+func (c *Core) DescribePlugin(ctx context.Context, req *agentv1.DescribePluginRequest) (*agentv1.PluginDescription, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := c.authorizeAgent(ctx, conn, "read", "plugin:"+req.Name); err != nil {
+		return nil, err
+	}
+
+	desc, err := agentv1.NewAgentServiceClient(conn.Client).DescribePlugin(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	return desc, nil
+}
+
+// recordInstalledPlugin updates the inventory of an agent after an install
+func (c *Core) recordInstalledPlugin(agentID string, installed *agentv1.InstalledPlugin) {
+	agent, ok := c.agents.lock(agentID)
+	if !ok {
+		return
+	}
+	defer agent.mu.Unlock()
+
+	plugins := make([]*agentv1.InstalledPlugin, 0, len(agent.Plugins)+1)
+	for _, p := range agent.Plugins {
+		if p.Name != installed.Name {
+			plugins = append(plugins, p)
+		}
+	}
+	agent.Plugins = append(plugins, installed)
+}
+
+// pluginIndex reads the signed index configured in plugins.marketplace.index
+func (c *Core) pluginIndex() ([]byte, error) {
+	var path string
+	if c.config != nil && c.config.FullConfig != nil {
+		path = c.config.FullConfig.Plugins.Marketplace.Index
+	}
+	if path == "" {
+		return nil, status.Error(codes.FailedPrecondition, "no plugin index is configured (plugins.marketplace.index)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read plugin index: %v", err)
+	}
+	return data, nil
+}
+
+// authorizePlugins checks an action on the plugin index, which belongs to
+// no agent
+func (c *Core) authorizePlugins(ctx context.Context, action string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	if err := auth.Authorize(ctx, identity, &plugin.Action{
+		Action:   action,
+		Resource: "plugin:*",
+	}); err != nil {
+		return status.Errorf(codes.PermissionDenied, "%s may not %s the plugin index", identity.UserID, action)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultNamespace owns stacks applied without a namespace. Agents apply the
+// same default, so core and agents agree on where unqualified stacks live.
+const defaultNamespace = "default"
+
+func normalizeNamespace(ns string) string {
+	if ns == "" {
+		return defaultNamespace
+	}
+	return ns
+}
+
+// authorizeNamespace checks a cluster-wide or namespace-wide grant, for data
+// such as namespace totals that spans agents
+func (c *Core) authorizeNamespace(ctx context.Context, action, namespace, resource string) error {
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	for _, scope := range []string{resource, "namespace:" + namespace + "/" + resource} {
+		if auth.Authorize(ctx, identity, &plugin.Action{Action: action, Resource: scope}) == nil {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%s may not %s %s in namespace %s",
+		identity.UserID, action, resource, namespace)
+}
+
+// stackReader decides which agent and namespace pairs the caller may read
+// stacks in, remembering decisions for the duration of one request
+type stackReader struct {
+	c       *Core
+	ctx     context.Context
+	allowed map[[2]string]bool
+}
+
+func (c *Core) newStackReader(ctx context.Context) *stackReader {
+	return &stackReader{c: c, ctx: ctx, allowed: make(map[[2]string]bool)}
+}
+
+// can reports whether the caller may read stacks in namespace on agentID
+func (r *stackReader) can(agentID, namespace string) bool {
+	key := [2]string{agentID, namespace}
+	if allowed, ok := r.allowed[key]; ok {
+		return allowed
+	}
+
+	conn, ok := r.c.agents.get(agentID)
+	if !ok {
+		// Deregistered since the snapshot; only cluster-wide grants apply
+		conn = &AgentConnection{ID: agentID}
+	}
+
+	allowed := r.c.authorizeNamespaced(r.ctx, conn, "read", namespace, "stack:*") == nil
+	r.allowed[key] = allowed
+	return allowed
+}
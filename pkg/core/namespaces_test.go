@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNamespaceGrants(t *testing.T) {
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"alice": {"read namespace:team-a/stack:*", "write namespace:team-a/stack:web", "delete namespace:team-a/stack:web"},
+		"bob":   {"read namespace:default/stack:*"},
+		"admin": {"read stack:*", "delete stack:web"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := newGroupRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := &AgentConnection{ID: "web-1"}
+	c := &Core{plugins: plugins, groups: groups, agents: newAgentRegistry(conn)}
+	as := func(user string) context.Context {
+		return plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: user})
+	}
+
+	tests := []struct {
+		user, action, namespace, resource string
+		allowed                           bool
+	}{
+		{"alice", "read", "team-a", "stack:*", true},
+		{"alice", "write", "team-a", "stack:web", true},
+		{"alice", "delete", "team-a", "stack:web", true},
+		{"alice", "read", "team-b", "stack:*", false},
+		{"alice", "write", "team-b", "stack:web", false},
+		{"alice", "delete", "team-b", "stack:web", false},
+		// Stacks without a namespace are in the default one, not in any
+		{"alice", "read", normalizeNamespace(""), "stack:*", false},
+		{"bob", "read", normalizeNamespace(""), "stack:*", true},
+		{"bob", "read", "team-a", "stack:*", false},
+		// Cluster-wide grants cover every namespace
+		{"admin", "read", "team-b", "stack:*", true},
+		{"admin", "delete", "team-a", "stack:web", true},
+	}
+	for _, tt := range tests {
+		err := c.authorizeNamespaced(as(tt.user), conn, tt.action, tt.namespace, tt.resource)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("%s %s %s in %s: %v, want allowed %v", tt.user, tt.action, tt.resource, tt.namespace, err, tt.allowed)
+		} else if !allowed && status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s %s %s in %s: %v, want PermissionDenied", tt.user, tt.action, tt.resource, tt.namespace, err)
+		}
+	}
+
+	// Listings keep to the namespaces the caller may read
+	reader := c.newStackReader(as("alice"))
+	if !reader.can("web-1", "team-a") || reader.can("web-1", "team-b") || reader.can("web-1", "default") {
+		t.Error("alice reads stacks outside team-a")
+	}
+}
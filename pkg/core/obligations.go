@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"log"
+
+	"github.com/bhangun/mandau/pkg/obligation"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// obligationInterceptor enforces the obligations the policy plugin attaches
+// to a call, such as a reason or recent MFA. Authorization stays with the
+// handlers: the decision only adds requirements here. It runs inside the
+// audit interceptor so refused calls are audited.
+func (c *Core) obligationInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := c.checkObligations(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// obligationStreamInterceptor is obligationInterceptor for streaming calls
+func (c *Core) obligationStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.checkObligations(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (c *Core) checkObligations(ctx context.Context, method string) error {
+	policy := c.plugins.Policy()
+	if policy == nil || agentMethods[method] != "" {
+		return nil
+	}
+
+	identity, err := c.callerIdentity(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+	}
+
+	decision, err := policy.Evaluate(ctx, &plugin.PolicyRequest{
+		Identity: identity,
+		Action:   &plugin.Action{Method: method},
+	})
+	if err != nil {
+		log.Printf("Policy evaluation of %s for %s failed: %v", method, identity.UserID, err)
+		return status.Errorf(codes.Internal, "policy evaluation failed")
+	}
+
+	enforcer := &obligation.Enforcer{
+		Recording: c.plugins.Auditing(),
+		MFA:       c.plugins.MFA(),
+	}
+	return enforcer.Check(ctx, identity, decision)
+}
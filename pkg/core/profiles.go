@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/audit"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Certificate profiles, taken from the subject OU. Enrolled agent
+// certificates and SPIFFE IDs naming an agent are agent certificates
+// without one.
+const (
+	profileAgent = "agent"
+	profileUser  = "user"
+)
+
+// certProfileAttribute is the identity attribute holding the caller's
+// certificate profile
+const certProfileAttribute = "cert_profile"
+
+// agentRole is the role certificates with the agent profile call under,
+// including those enrollment issues. The core gives it to them without a
+// user entry; the auth plugin decides what it allows.
+const agentRole = "agent"
+
+// agentMethods is everything an agent certificate may call, with the action
+// on "agent:<id>" the agent role needs for it
+var agentMethods = map[string]string{
+	agentv1.CoreService_RegisterAgent_FullMethodName:     "register",
+	agentv1.CoreService_Heartbeat_FullMethodName:         "heartbeat",
+	agentv1.CoreService_HeartbeatStream_FullMethodName:   "heartbeat",
+	agentv1.ArtifactService_FetchArtifact_FullMethodName: "fetch_artifact",
+}
+
+// certProfile returns the profile a certificate is issued for, or "" for
+// certificates that carry none
+func certProfile(cert *x509.Certificate) string {
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		switch strings.ToLower(ou) {
+		case profileAgent:
+			return profileAgent
+		case profileUser:
+			return profileUser
+		}
+	}
+	if _, agentID := certIdentity(cert); agentID != "" {
+		return profileAgent
+	}
+	return ""
+}
+
+// checkProfile decides whether a certificate with profile may call method.
+// Agent certificates are limited to the agent methods and user
+// certificates kept off them; certificates without a profile are rejected
+// in strict mode and otherwise allowed everywhere, as before profiles.
+func (c *Core) checkProfile(profile, method string) error {
+	switch profile {
+	case profileAgent:
+		if agentMethods[method] == "" {
+			return fmt.Errorf("agent certificates may only register, send heartbeats and fetch artifacts")
+		}
+	case profileUser:
+		if agentMethods[method] != "" {
+			return fmt.Errorf("user certificates may not act as an agent")
+		}
+	default:
+		if c.strictProfiles() {
+			return fmt.Errorf("certificate has no profile; set OU=%s or OU=%s", profileAgent, profileUser)
+		}
+	}
+	return nil
+}
+
+func (c *Core) strictProfiles() bool {
+	return c.config != nil && c.config.FullConfig != nil &&
+		c.config.FullConfig.AgentManagement.CertProfiles == "strict"
+}
+
+// profileInterceptor runs before authentication. Agent calls are checked
+// against the certificate profile and the agent identity binding instead
+// of the auth plugin, which only knows users.
+func (c *Core) profileInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return nil, c.authFailed(ctx, nil, info.FullMethod, req, err)
+	}
+
+	profile := certProfile(cert)
+	if err := c.checkProfile(profile, info.FullMethod); err != nil {
+		return nil, c.profileDenied(ctx, cert, info.FullMethod, audit.Metadata(req), err)
+	}
+
+	if agentMethods[info.FullMethod] == "" {
+		return handler(ctx, req)
+	}
+
+	identity, err := c.agentIdentity(ctx, cert, info.FullMethod, audit.Metadata(req))
+	if err != nil {
+		return nil, err
+	}
+	return handler(plugin.WithIdentity(ctx, identity), req)
+}
+
+// profileStreamInterceptor keeps agent certificates off streaming calls
+// other than the heartbeat stream, and names the agent on that one as
+// profileInterceptor does
+func (c *Core) profileStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	cert, err := peerCertificate(ss.Context())
+	if err != nil {
+		return c.authFailed(ss.Context(), nil, info.FullMethod, nil, err)
+	}
+
+	if err := c.checkProfile(certProfile(cert), info.FullMethod); err != nil {
+		return c.profileDenied(ss.Context(), cert, info.FullMethod, map[string]string{}, err)
+	}
+
+	if agentMethods[info.FullMethod] == "" {
+		return handler(srv, ss)
+	}
+
+	identity, err := c.agentIdentity(ss.Context(), cert, info.FullMethod, map[string]string{})
+	if err != nil {
+		return err
+	}
+	return handler(srv, &agentStream{ServerStream: ss, ctx: plugin.WithIdentity(ss.Context(), identity)})
+}
+
+// agentIdentity names the caller of an agent method once the auth plugin
+// has allowed it the call. Certificates with the agent profile call in the
+// agent role, on "agent:<id>". Certificates without a profile, which only
+// permissive mode lets through, get no role of their own: they call as the
+// user they map to, on "agent:<user>", so only users policy grants the
+// agent role act as agents.
+func (c *Core) agentIdentity(ctx context.Context, cert *x509.Certificate, method string, metadata map[string]string) (*plugin.Identity, error) {
+	var identity *plugin.Identity
+	name, agentID := certIdentity(cert)
+	if certProfile(cert) == profileAgent {
+		identity = &plugin.Identity{
+			UserID:     name,
+			Roles:      []string{agentRole},
+			Attributes: map[string]string{certProfileAttribute: profileAgent},
+		}
+		if agentID == "" {
+			agentID = name
+		}
+	} else {
+		user, err := extractIdentity(ctx, c.identities)
+		if err != nil {
+			return nil, c.profileDenied(ctx, cert, method, metadata, err)
+		}
+		identity, agentID = user, user.UserID
+	}
+
+	auth := c.plugins.Auth()
+	if auth == nil {
+		return identity, nil
+	}
+	action := agentMethods[method]
+	if err := auth.Authorize(ctx, identity, &plugin.Action{
+		Method:   method,
+		Action:   action,
+		Resource: "agent:" + agentID,
+	}); err != nil {
+		return nil, c.profileDenied(ctx, cert, method, metadata, fmt.Errorf("%s may not %s as agent %s", identity.UserID, action, agentID))
+	}
+	return identity, nil
+}
+
+// agentStream is a stream whose context names the calling agent
+type agentStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *agentStream) Context() context.Context { return s.ctx }
+
+// profileDenied audits a call made with the wrong kind of certificate and
+// returns the error as PermissionDenied
+func (c *Core) profileDenied(ctx context.Context, cert *x509.Certificate, method string, metadata map[string]string, err error) error {
+	log.Printf("Rejected %s from %s: %v", method, cert.Subject.CommonName, err)
+
+	metadata["auth_error"] = err.Error()
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		Identity: &plugin.Identity{
+			UserID:     cert.Subject.CommonName,
+			Attributes: map[string]string{certProfileAttribute: certProfile(cert)},
+		},
+		Action:   method,
+		Result:   "denied",
+		Metadata: metadata,
+	})
+	return status.Errorf(codes.PermissionDenied, "certificate profile: %v", err)
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCertProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want string
+	}{
+		{"agent OU", &x509.Certificate{Subject: pkix.Name{CommonName: "mandau-agent", OrganizationalUnit: []string{"agent"}}}, profileAgent},
+		{"user OU", &x509.Certificate{Subject: pkix.Name{CommonName: "alice", OrganizationalUnit: []string{"Ops", "User"}}}, profileUser},
+		{"enrolled", &x509.Certificate{URIs: []*url.URL{{Scheme: "mandau", Host: "agent", Path: "/web-1"}}}, profileAgent},
+		{"none", &x509.Certificate{Subject: pkix.Name{CommonName: "mandau-cli"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certProfile(tt.cert); got != tt.want {
+				t.Errorf("certProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	register := agentv1.CoreService_RegisterAgent_FullMethodName
+	listAgents := agentv1.CoreService_ListAgents_FullMethodName
+
+	tests := []struct {
+		name    string
+		mode    string
+		profile string
+		method  string
+		wantErr bool
+	}{
+		{"agent registers", "", profileAgent, register, false},
+		{"agent lists agents", "", profileAgent, listAgents, true},
+		{"user lists agents", "", profileUser, listAgents, false},
+		{"user registers", "", profileUser, register, true},
+		{"unmarked, permissive", "permissive", "", listAgents, false},
+		{"unmarked registers, permissive", "", "", register, false},
+		{"unmarked, strict", "strict", "", listAgents, true},
+		{"unmarked registers, strict", "strict", "", register, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Core{config: &CoreConfig{FullConfig: &config.CoreConfig{
+				AgentManagement: config.AgentManagementConfig{CertProfiles: tt.mode},
+			}}}
+			if err := c.checkProfile(tt.profile, tt.method); (err != nil) != tt.wantErr {
+				t.Errorf("checkProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileInterceptor(t *testing.T) {
+	c := &Core{plugins: plugin.NewRegistry()}
+	agentCtx := peerContext(&x509.Certificate{URIs: []*url.URL{{Scheme: "mandau", Host: "agent", Path: "/web-1"}}})
+
+	var got *plugin.Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = plugin.IdentityFromContext(ctx)
+		return nil, nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_Heartbeat_FullMethodName}
+	if _, err := c.profileInterceptor(agentCtx, &agentv1.HeartbeatRequest{}, info, handler); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if got == nil || got.UserID != "mandau://agent/web-1" || got.Attributes[certProfileAttribute] != profileAgent {
+		t.Errorf("agent identity = %+v", got)
+	}
+
+	if len(got.Roles) != 1 || got.Roles[0] != agentRole {
+		t.Errorf("agent roles = %v, want %s", got.Roles, agentRole)
+	}
+
+	info = &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_ListAgents_FullMethodName}
+	_, err := c.profileInterceptor(agentCtx, &agentv1.ListAgentsRequest{}, info, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("agent listing agents: %v", err)
+	}
+}
+
+func TestProfileInterceptorAuthorizesAgentRole(t *testing.T) {
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"mandau://agent/web-1": {"heartbeat agent:web-1"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{plugins: plugins}
+	agentCtx := peerContext(&x509.Certificate{URIs: []*url.URL{{Scheme: "mandau", Host: "agent", Path: "/web-1"}}})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	heartbeat := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_Heartbeat_FullMethodName}
+	if _, err := c.profileInterceptor(agentCtx, &agentv1.HeartbeatRequest{}, heartbeat, handler); err != nil {
+		t.Errorf("allowed heartbeat: %v", err)
+	}
+
+	// The auth plugin decides what the role may do
+	register := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_RegisterAgent_FullMethodName}
+	if _, err := c.profileInterceptor(agentCtx, &agentv1.RegisterRequest{}, register, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("registering without the register action: %v", err)
+	}
+}
+
+func TestProfileInterceptorUnmarkedCertificates(t *testing.T) {
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"mandau-agent": {"heartbeat agent:mandau-agent"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{plugins: plugins}
+	var got *plugin.Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = plugin.IdentityFromContext(ctx)
+		return nil, nil
+	}
+	heartbeat := &grpc.UnaryServerInfo{FullMethod: agentv1.CoreService_Heartbeat_FullMethodName}
+
+	// A user certificate without a profile gets no agent role of its own
+	cli := peerContext(&x509.Certificate{Subject: pkix.Name{CommonName: "mandau-cli"}})
+	if _, err := c.profileInterceptor(cli, &agentv1.HeartbeatRequest{}, heartbeat, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CLI certificate heartbeat: %v", err)
+	}
+
+	// One whose user policy lets act as an agent calls as that user
+	agent := peerContext(&x509.Certificate{Subject: pkix.Name{CommonName: "mandau-agent"}})
+	if _, err := c.profileInterceptor(agent, &agentv1.HeartbeatRequest{}, heartbeat, handler); err != nil {
+		t.Fatalf("unmarked agent heartbeat: %v", err)
+	}
+	if got.UserID != "mandau-agent" || len(got.Roles) != 0 {
+		t.Errorf("unmarked agent identity = %+v", got)
+	}
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// agentRoute says how a call the core forwards to an agent is authorized
+type agentRoute struct {
+	resource   string // Callers need "read", or "write" with write set
+	capability string // Required of the agent; empty for none
+	write      bool
+	deploy     bool // Refused while the agent is in maintenance
+}
+
+// ProxyRoutes maps methods the core does not serve itself to agents. Exact
+// methods win over whole-service routes.
+type ProxyRoutes struct {
+	methods  map[string]agentRoute
+	services map[string]agentRoute // By "/pkg.Service/"
+}
+
+// operationMethods read the operations agents record for stack changes
+var operationMethods = []string{
+	agentv1.OperationsService_GetOperation_FullMethodName,
+	agentv1.OperationsService_ListOperations_FullMethodName,
+}
+
+func newProxyRoutes(cfg config.ProxyConfig) (*ProxyRoutes, error) {
+	r := &ProxyRoutes{
+		methods:  make(map[string]agentRoute),
+		services: make(map[string]agentRoute),
+	}
+	for method, m := range hostMethods {
+		r.methods[method] = agentRoute{
+			resource:   "host:" + m.capability,
+			capability: m.capability,
+			write:      m.write,
+			deploy:     m.write && m.capability == capability.Deploy,
+		}
+	}
+
+	for _, method := range operationMethods {
+		r.methods[method] = agentRoute{resource: "stack:*"}
+	}
+
+	for _, route := range cfg.Routes {
+		if route.Resource == "" {
+			return nil, fmt.Errorf("route %s: resource is required", route.Method)
+		}
+		service, method, ok := strings.Cut(strings.TrimPrefix(route.Method, "/"), "/")
+		if !strings.HasPrefix(route.Method, "/") || !ok || service == "" || method == "" {
+			return nil, fmt.Errorf("route %q: want /<package>.<Service>/<Method> or /<package>.<Service>/*", route.Method)
+		}
+
+		ar := agentRoute{resource: route.Resource, capability: route.Capability, write: route.Write}
+		if method == "*" {
+			r.services["/"+service+"/"] = ar
+		} else {
+			r.methods[route.Method] = ar
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the route of method
+func (r *ProxyRoutes) lookup(method string) (agentRoute, bool) {
+	if route, ok := r.methods[method]; ok {
+		return route, true
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		route, ok := r.services[method[:i+1]]
+		return route, ok
+	}
+	return agentRoute{}, false
+}
+
+// proxyStream forwards any call the core has no handler for to the agent it
+// names, frame by frame, after the same checks the core applies to its own
+// agent-bound methods. Unary and streaming calls look alike at this level.
+func (c *Core) proxyStream(srv interface{}, ss grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(ss)
+	if !ok {
+		return status.Error(codes.Internal, "no method in stream")
+	}
+	return c.proxy(ss, method)
+}
+
+func (c *Core) proxy(ss grpc.ServerStream, method string) error {
+	route, ok := c.proxyRoutes.lookup(method)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	ctx, err := c.authenticate(ss.Context(), method, nil)
+	if err != nil {
+		return err
+	}
+
+	// The first request names the agent unless the header does, so it is
+	// read before anything is forwarded
+	first := &transport.Frame{}
+	if err := ss.RecvMsg(first); err == io.EOF {
+		first = nil
+	} else if err != nil {
+		return err
+	}
+
+	agentID := agentHeader(ctx)
+	if first != nil {
+		switch named := requestAgentID(method, first); {
+		case agentID == "":
+			agentID = named
+		case named != "" && named != agentID:
+			return status.Errorf(codes.InvalidArgument, "request is for agent %s but the %s header names %s", named, transport.AgentHeader, agentID)
+		}
+	}
+	if agentID == "" {
+		return status.Errorf(codes.InvalidArgument, "no agent named: set agent_id or the %s header", transport.AgentHeader)
+	}
+
+	conn, err := c.getAgentConnection(agentID)
+	if err != nil {
+		return fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := c.authorizeRoute(ctx, conn, method, route); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	agentStream, err := conn.Client.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method,
+		grpc.ForceCodecV2(transport.FrameCodec()))
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+
+	go func() {
+		if err := forwardRequests(ss, agentStream, first); err != nil {
+			cancel()
+		}
+	}()
+	return forwardResponses(agentStream, ss)
+}
+
+// authorizeRoute checks that conn can take method and the caller may send
+// it there
+func (c *Core) authorizeRoute(ctx context.Context, conn *AgentConnection, method string, route agentRoute) error {
+	if err := requireAgentCapability(conn, route.capability, method); err != nil {
+		return err
+	}
+
+	action := "read"
+	if route.write {
+		action = "write"
+	}
+	if err := c.authorizeAgent(ctx, conn, action, route.resource); err != nil {
+		return err
+	}
+
+	// Configured routes are not in frozenMethods, which the freeze
+	// interceptor goes by
+	if route.write && !frozenMethods[method] {
+		if err := c.requireUnfrozen(ctx); err != nil {
+			return err
+		}
+	}
+	if route.deploy {
+		return c.requireDeployAllowed(ctx, conn, "", route.resource, false)
+	}
+	return nil
+}
+
+// forwardRequests relays the caller's messages to the agent, starting with
+// first when it was read already, and half-closes once the caller has
+func forwardRequests(from grpc.ServerStream, to grpc.ClientStream, first *transport.Frame) error {
+	if first == nil {
+		return to.CloseSend()
+	}
+	if err := to.SendMsg(first); err != nil {
+		return err
+	}
+	for {
+		frame := &transport.Frame{}
+		err := from.RecvMsg(frame)
+		if err == io.EOF {
+			return to.CloseSend()
+		}
+		if err != nil {
+			return err
+		}
+		if err := to.SendMsg(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// forwardResponses relays the agent's headers, messages and trailers to the
+// caller until the agent ends the call. An agent error is returned as is, so
+// the caller sees the agent's status.
+func forwardResponses(from grpc.ClientStream, to grpc.ServerStream) error {
+	header, err := from.Header()
+	if err != nil {
+		return err
+	}
+	if err := to.SendHeader(header); err != nil {
+		return err
+	}
+
+	for {
+		frame := &transport.Frame{}
+		err := from.RecvMsg(frame)
+		if err == io.EOF {
+			to.SetTrailer(from.Trailer())
+			return nil
+		}
+		if err != nil {
+			to.SetTrailer(from.Trailer())
+			return err
+		}
+		if err := to.SendMsg(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// agentHeader returns the agent named in the call's metadata
+func agentHeader(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(transport.AgentHeader); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// requestAgentID decodes frame as the request of method, when the method is
+// compiled into the core, and returns its agent_id. The decoded request is
+// kept on the frame for the audit log.
+func requestAgentID(method string, frame *transport.Frame) string {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return ""
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return ""
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return ""
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return ""
+	}
+
+	msg := mt.New().Interface()
+	if err := proto.Unmarshal(frame.Payload, msg); err != nil {
+		return ""
+	}
+	frame.Message = msg
+	return messageAgentID(msg)
+}
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeAgent serves a few agent services for the proxy to reach
+type fakeAgent struct {
+	agentv1.UnimplementedNginxServiceServer
+	agentv1.UnimplementedServiceDeploymentServiceServer
+	agentv1.UnimplementedFilesystemServiceServer
+	agentv1.UnimplementedOperationsServiceServer
+}
+
+func (a *fakeAgent) ListVirtualHosts(ctx context.Context, req *agentv1.ListVirtualHostsRequest) (*agentv1.ListVirtualHostsResponse, error) {
+	return &agentv1.ListVirtualHostsResponse{Vhosts: []string{"example.com"}}, nil
+}
+
+func (a *fakeAgent) DeleteVirtualHost(ctx context.Context, req *agentv1.DeleteVirtualHostRequest) (*agentv1.DeleteVirtualHostResponse, error) {
+	return nil, status.Errorf(codes.NotFound, "virtual host %s not found", req.ServerName)
+}
+
+func (a *fakeAgent) DeployWebService(req *agentv1.DeployWebServiceRequest, stream agentv1.ServiceDeploymentService_DeployWebServiceServer) error {
+	for _, state := range []string{"RUNNING", "COMPLETED"} {
+		if err := stream.Send(&agentv1.ServiceOperationEvent{State: state, Message: req.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *fakeAgent) ListFiles(ctx context.Context, req *agentv1.ListFilesRequest) (*agentv1.ListFilesResponse, error) {
+	return &agentv1.ListFilesResponse{Files: []*agentv1.FileInfo{{Name: "compose.yaml"}}}, nil
+}
+
+func (a *fakeAgent) ListOperations(ctx context.Context, req *agentv1.ListOperationsRequest) (*agentv1.ListOperationsResponse, error) {
+	return &agentv1.ListOperationsResponse{Operations: []*agentv1.Operation{{Id: "op-1", IdempotencyKey: req.IdempotencyKey}}}, nil
+}
+
+// callerStream presents a verified client certificate, as mTLS would
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context { return s.ctx }
+
+func serve(t *testing.T, server *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// proxyCore starts a core that forwards to one agent, web-1, with plugins
+// registered, and returns a connection to it
+func proxyCore(t *testing.T, capabilities []string, routes config.ProxyConfig, plugins ...plugin.Plugin) *grpc.ClientConn {
+	t.Helper()
+
+	agentServer := grpc.NewServer()
+	agent := &fakeAgent{}
+	agentv1.RegisterNginxServiceServer(agentServer, agent)
+	agentv1.RegisterServiceDeploymentServiceServer(agentServer, agent)
+	agentv1.RegisterFilesystemServiceServer(agentServer, agent)
+	agentv1.RegisterOperationsServiceServer(agentServer, agent)
+	agentConn := serve(t, agentServer)
+
+	proxyRoutes, err := newProxyRoutes(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := plugin.NewRegistry()
+	for _, p := range plugins {
+		if err := registry.Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: capabilities, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		plugins:     registry,
+		breakGlass:  newBreakGlassStore(config.BreakGlassConfig{}),
+		freeze:      &Freeze{},
+		proxyRoutes: proxyRoutes,
+	}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}
+	asAlice := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := peer.NewContext(ss.Context(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+		return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+	}
+	return serve(t, grpc.NewServer(
+		grpc.ChainStreamInterceptor(asAlice, c.auditStreamInterceptor),
+		grpc.UnknownServiceHandler(c.proxyStream),
+		grpc.ForceServerCodecV2(transport.FrameCodec()),
+	))
+}
+
+func TestProxyForwardsHostServices(t *testing.T) {
+	conn := proxyCore(t, nil, config.ProxyConfig{})
+	ctx := context.Background()
+
+	nginx := agentv1.NewNginxServiceClient(conn)
+	resp, err := nginx.ListVirtualHosts(ctx, &agentv1.ListVirtualHostsRequest{AgentId: "web-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Vhosts) != 1 || resp.Vhosts[0] != "example.com" {
+		t.Errorf("vhosts = %v, want the agent's", resp.Vhosts)
+	}
+
+	// The agent's status reaches the caller unchanged
+	_, err = nginx.DeleteVirtualHost(ctx, &agentv1.DeleteVirtualHostRequest{AgentId: "web-1", ServerName: "gone.example.com"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteVirtualHost() error = %v, want the agent's not found", err)
+	}
+
+	stream, err := agentv1.NewServiceDeploymentServiceClient(conn).DeployWebService(ctx, &agentv1.DeployWebServiceRequest{AgentId: "web-1", Name: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, event.State)
+	}
+	if len(states) != 2 || states[1] != "COMPLETED" {
+		t.Errorf("events = %v, want the agent's two", states)
+	}
+}
+
+func TestProxyAuditsStreams(t *testing.T) {
+	auditor := &auditRecorder{}
+	conn := proxyCore(t, nil, config.ProxyConfig{}, auditor)
+
+	req := &agentv1.DeployWebServiceRequest{AgentId: "web-1", Name: "api", Command: "node server.js --token hunter2"}
+	stream, err := agentv1.NewServiceDeploymentServiceClient(conn).DeployWebService(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent int
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent += proto.Size(event)
+	}
+
+	// The entry is written before the call ends
+	if len(auditor.entries) != 1 {
+		t.Fatalf("audited %d entries, want 1", len(auditor.entries))
+	}
+	entry := auditor.entries[0]
+	if entry.Action != agentv1.ServiceDeploymentService_DeployWebService_FullMethodName || entry.Result != "success" ||
+		entry.Identity == nil || entry.Identity.UserID != "alice" || entry.Duration <= 0 {
+		t.Errorf("entry = %+v", entry)
+	}
+	want := map[string]string{
+		"agent_id":          "web-1",
+		"name":              "api",
+		"command":           "node",
+		"messages_received": "1",
+		"bytes_received":    strconv.Itoa(proto.Size(req)),
+		"messages_sent":     "2",
+		"bytes_sent":        strconv.Itoa(sent),
+	}
+	for k, v := range want {
+		if entry.Metadata[k] != v {
+			t.Errorf("metadata[%s] = %q, want %q", k, entry.Metadata[k], v)
+		}
+	}
+
+	// Refused streams are audited too
+	_, err = agentv1.NewNginxServiceClient(conn).ListVirtualHosts(context.Background(), &agentv1.ListVirtualHostsRequest{AgentId: "web-2"})
+	if err == nil {
+		t.Fatal("ListVirtualHosts() on an unknown agent succeeded")
+	}
+	if len(auditor.entries) != 2 {
+		t.Fatalf("audited %d entries, want 2", len(auditor.entries))
+	}
+	if entry := auditor.entries[1]; entry.Result != "error" || entry.Metadata["messages_sent"] != "0" {
+		t.Errorf("failed call entry = %+v", entry)
+	}
+}
+
+func TestProxyRouting(t *testing.T) {
+	conn := proxyCore(t, nil, config.ProxyConfig{Routes: []config.ProxyRoute{
+		{Method: "/mandau.agent.v1.FilesystemService/*", Resource: "stack:*"},
+	}})
+	nginx := agentv1.NewNginxServiceClient(conn)
+	files := agentv1.NewFilesystemServiceClient(conn)
+	withHeader := func(agentID string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), transport.AgentHeader, agentID)
+	}
+
+	// Requests without agent_id rely on the header
+	resp, err := files.ListFiles(withHeader("web-1"), &agentv1.ListFilesRequest{StackName: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Files) != 1 {
+		t.Errorf("files = %v, want the agent's", resp.Files)
+	}
+	if _, err := files.ListFiles(context.Background(), &agentv1.ListFilesRequest{StackName: "web"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListFiles() without an agent: %v, want invalid argument", err)
+	}
+
+	// The header and agent_id must agree
+	_, err = nginx.ListVirtualHosts(withHeader("web-2"), &agentv1.ListVirtualHostsRequest{AgentId: "web-1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListVirtualHosts() with a conflicting header: %v, want invalid argument", err)
+	}
+
+	// Operations can be read without a configured route
+	ops := agentv1.NewOperationsServiceClient(conn)
+	list, err := ops.ListOperations(context.Background(), &agentv1.ListOperationsRequest{AgentId: "web-1", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Operations) != 1 || list.Operations[0].IdempotencyKey != "k1" {
+		t.Errorf("operations = %v, want the agent's", list.Operations)
+	}
+
+	// Methods without a route stay closed
+	_, err = ops.CancelOperation(withHeader("web-1"), &agentv1.CancelOperationRequest{OperationId: "op-1"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("CancelOperation() error = %v, want unimplemented", err)
+	}
+}
+
+func TestProxyRequiresCapability(t *testing.T) {
+	conn := proxyCore(t, []string{"docker"}, config.ProxyConfig{})
+
+	_, err := agentv1.NewNginxServiceClient(conn).ListVirtualHosts(context.Background(), &agentv1.ListVirtualHostsRequest{AgentId: "web-1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ListVirtualHosts() error = %v, want the capability check", err)
+	}
+	if d := transport.Detail(err); d.Code != agentv1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING || d.AgentId != "web-1" {
+		t.Errorf("error detail = %v, want the missing capability on web-1", d)
+	}
+}
+
+func TestRequireCapabilityExplainsUnavailablePlugin(t *testing.T) {
+	conn := &AgentConnection{
+		ID:           "mac-1",
+		Capabilities: []string{"docker", "cron"},
+		Unavailable: unavailablePlugins([]*agentv1.PluginAvailability{
+			{Plugin: "cron-manager", Capability: "cron", Available: true},
+			{Plugin: "systemd-manager", Capability: "systemd", Reason: "systemd runs only on Linux, not darwin"},
+		}),
+	}
+
+	err := requireAgentCapability(conn, "systemd", agentv1.SystemdService_ListServices_FullMethodName)
+	d := transport.Detail(err)
+	if d.Code != agentv1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING || d.Hint != "systemd-manager cannot run on the agent's host: systemd runs only on Linux, not darwin" {
+		t.Errorf("error detail = %v, want the reason systemd is unavailable", d)
+	}
+	if err := requireAgentCapability(conn, "cron", agentv1.CronService_ListCronJobs_FullMethodName); err != nil {
+		t.Errorf("available plugin rejected: %v", err)
+	}
+}
+
+func TestNewProxyRoutes(t *testing.T) {
+	for _, route := range []config.ProxyRoute{
+		{Method: "/mandau.agent.v1.FilesystemService/*"},
+		{Method: "mandau.agent.v1.FilesystemService/ListFiles", Resource: "stack:*"},
+		{Method: "/mandau.agent.v1.FilesystemService", Resource: "stack:*"},
+	} {
+		if _, err := newProxyRoutes(config.ProxyConfig{Routes: []config.ProxyRoute{route}}); err == nil {
+			t.Errorf("newProxyRoutes(%+v) succeeded, want an error", route)
+		}
+	}
+}
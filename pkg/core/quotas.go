@@ -0,0 +1,337 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/quota"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QuotaLimits holds the parsed quota configuration
+type QuotaLimits struct {
+	agent      config.AgentQuota
+	namespaces map[string]namespaceLimit
+
+	// applyMu serializes quota checks with the applies they admit, so two
+	// concurrent applies cannot both fit under the same remaining quota
+	applyMu sync.Mutex
+}
+
+type namespaceLimit struct {
+	maxStacks      int
+	maxCPUs        float64
+	maxMemoryBytes int64
+}
+
+func newQuotaLimits(cfg config.QuotaConfig) (*QuotaLimits, error) {
+	q := &QuotaLimits{
+		agent:      cfg.Agent,
+		namespaces: make(map[string]namespaceLimit, len(cfg.Namespaces)),
+	}
+
+	for ns, nc := range cfg.Namespaces {
+		limit := namespaceLimit{
+			maxStacks: nc.MaxStacks,
+			maxCPUs:   nc.MaxCPUs,
+		}
+		if nc.MaxMemory != "" {
+			b, err := quota.ParseMemory(nc.MaxMemory)
+			if err != nil {
+				return nil, fmt.Errorf("namespace %s: %w", ns, err)
+			}
+			limit.maxMemoryBytes = b
+		}
+		q.namespaces[ns] = limit
+	}
+
+	return q, nil
+}
+
+// namespace returns the limit for ns, falling back to the "*" entry
+func (q *QuotaLimits) namespace(ns string) (namespaceLimit, bool) {
+	if limit, ok := q.namespaces[ns]; ok {
+		return limit, true
+	}
+	limit, ok := q.namespaces["*"]
+	return limit, ok
+}
+
+func (q *QuotaLimits) agentLimited() bool {
+	return q.agent.MaxStacks > 0 || q.agent.MaxContainers > 0
+}
+
+// requireQuota rejects an apply that would push the target agent or the
+// stack's namespace over its limits. The stack being applied is replaced, so
+// its current usage is not counted twice.
+//
+// When quotas apply, checks are serialized: the returned release must be
+// called once the agent has accepted the apply (or it failed), and is safe
+// to call more than once.
+func (c *Core) requireQuota(ctx context.Context, conn *AgentConnection, req *agentv1.ApplyStackRequest) (func(), error) {
+	ns := normalizeNamespace(req.Namespace)
+	nsLimit, nsLimited := c.quotas.namespace(ns)
+	if !c.quotas.agentLimited() && !nsLimited {
+		return func() {}, nil
+	}
+
+	requested, err := quota.FromCompose([]byte(req.ComposeContent))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	c.quotas.applyMu.Lock()
+	var once sync.Once
+	release := func() { once.Do(c.quotas.applyMu.Unlock) }
+
+	if err := c.checkQuota(ctx, conn, req, ns, nsLimit, nsLimited, requested); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (c *Core) checkQuota(ctx context.Context, conn *AgentConnection, req *agentv1.ApplyStackRequest,
+	ns string, nsLimit namespaceLimit, nsLimited bool, requested quota.Reservations) error {
+	if c.quotas.agentLimited() {
+		stacks, err := c.agentStacks(ctx, conn, "")
+		if err != nil {
+			return err
+		}
+
+		count, used := sumStacks(stacks, req.StackName)
+		if max := c.quotas.agent.MaxStacks; max > 0 && count+1 > max {
+			return status.Errorf(codes.ResourceExhausted,
+				"quota exceeded: agent %s already runs %d of %d stacks", conn.ID, count, max)
+		}
+		if max := c.quotas.agent.MaxContainers; max > 0 && used.Containers+requested.Containers > max {
+			return status.Errorf(codes.ResourceExhausted,
+				"quota exceeded: agent %s has %d containers, stack %s adds %d, limit %d",
+				conn.ID, used.Containers, req.StackName, requested.Containers, max)
+		}
+	}
+
+	if nsLimited {
+		usage, err := c.namespaceUsage(ctx, ns, conn.ID, req.StackName)
+		if err != nil {
+			return err
+		}
+
+		if nsLimit.maxStacks > 0 && usage.stacks+1 > nsLimit.maxStacks {
+			return status.Errorf(codes.ResourceExhausted,
+				"quota exceeded: namespace %s already has %d of %d stacks", ns, usage.stacks, nsLimit.maxStacks)
+		}
+		if nsLimit.maxCPUs > 0 && usage.CPUs+requested.CPUs > nsLimit.maxCPUs {
+			return status.Errorf(codes.ResourceExhausted,
+				"quota exceeded: namespace %s reserves %.2f CPUs, stack %s adds %.2f, limit %.2f",
+				ns, usage.CPUs, req.StackName, requested.CPUs, nsLimit.maxCPUs)
+		}
+		if nsLimit.maxMemoryBytes > 0 && usage.MemoryBytes+requested.MemoryBytes > nsLimit.maxMemoryBytes {
+			return status.Errorf(codes.ResourceExhausted,
+				"quota exceeded: namespace %s reserves %s memory, stack %s adds %s, limit %s",
+				ns, quota.FormatMemory(usage.MemoryBytes), req.StackName,
+				quota.FormatMemory(requested.MemoryBytes), quota.FormatMemory(nsLimit.maxMemoryBytes))
+		}
+	}
+
+	return nil
+}
+
+type namespaceTotals struct {
+	quota.Reservations
+	stacks int
+}
+
+// namespaceUsage sums ns across every online agent, leaving out stack skip
+// on agent skipAgent. Offline agents cannot report and are not counted;
+// neither are other agents that fail to answer, so one unreachable host
+// does not block every apply in the namespace. The target agent must answer.
+func (c *Core) namespaceUsage(ctx context.Context, ns, skipAgent, skip string) (namespaceTotals, error) {
+	var usage namespaceTotals
+	conns, failed := c.onlineAgents()
+	if err, ok := failed[skipAgent]; ok {
+		return usage, status.Errorf(codes.Unavailable, "agent %s: %v", skipAgent, err)
+	}
+	for id, err := range failed {
+		log.Printf("Quota: not counting namespace %s on agent %s: %v", ns, id, err)
+	}
+	results := fanOut(ctx, c.fanOut, conns, func(ctx context.Context, conn *AgentConnection) ([]*agentv1.Stack, error) {
+		return c.agentStacks(ctx, conn, ns)
+	})
+	for _, r := range results {
+		if r.err != nil {
+			if r.conn.ID == skipAgent {
+				return usage, r.err
+			}
+			log.Printf("Quota: not counting namespace %s on agent %s: %v", ns, r.conn.ID, r.err)
+			continue
+		}
+		skipName := ""
+		if r.conn.ID == skipAgent {
+			skipName = skip
+		}
+		count, used := sumStacks(r.value, skipName)
+		usage.stacks += count
+		usage.Add(used)
+	}
+	return usage, nil
+}
+
+// onlineAgents returns every online agent, dialing those the core has not
+// connected to yet. Agents that cannot be dialed are returned as failures.
+func (c *Core) onlineAgents() ([]*AgentConnection, map[string]error) {
+	var ids []string
+	for _, conn := range c.agents.all() {
+		conn.mu.RLock()
+		if conn.Status == AgentStatusOnline {
+			ids = append(ids, conn.ID)
+		}
+		conn.mu.RUnlock()
+	}
+
+	conns := make([]*AgentConnection, 0, len(ids))
+	failed := make(map[string]error)
+	for _, id := range ids {
+		conn, err := c.getAgentConnection(id)
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		conns = append(conns, conn)
+	}
+	return conns, failed
+}
+
+// agentStacks lists the stacks of one agent, optionally in one namespace
+func (c *Core) agentStacks(ctx context.Context, conn *AgentConnection, ns string) ([]*agentv1.Stack, error) {
+	// Agents that cannot run stacks host none
+	if requireCapability(conn, agentv1.StackService_ListStacks_FullMethodName) != nil {
+		return nil, nil
+	}
+
+	resp, err := agentv1.NewStackServiceClient(conn.Client).ListStacks(ctx, &agentv1.ListStacksRequest{
+		AgentId:   conn.ID,
+		Namespace: ns,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "count quota usage on agent %s: %v", conn.ID, err)
+	}
+	return resp.Stacks, nil
+}
+
+// sumStacks counts stacks and their reservations, leaving out skip
+func sumStacks(stacks []*agentv1.Stack, skip string) (int, quota.Reservations) {
+	var (
+		count int
+		used  quota.Reservations
+	)
+	for _, stack := range stacks {
+		if skip != "" && stack.Name == skip {
+			continue
+		}
+		count++
+		res := stack.GetResources()
+		used.Add(quota.Reservations{
+			Containers:  int(res.GetContainers()),
+			CPUs:        res.GetCpus(),
+			MemoryBytes: res.GetMemoryBytes(),
+		})
+	}
+	return count, used
+}
+
+// GetQuotaUsage reports stack and reservation usage per agent and per
+// namespace alongside the configured limits. Agents are listed when the
+// caller may read all their stacks, namespaces when it may read the
+// namespace cluster-wide.
+func (c *Core) GetQuotaUsage(ctx context.Context, req *agentv1.GetQuotaUsageRequest) (*agentv1.QuotaUsage, error) {
+	result := &agentv1.QuotaUsage{}
+	namespaces := make(map[string]*namespaceTotals)
+
+	// Namespace quotas span agents, so every agent is counted even when the
+	// request asks about a single one
+	conns, failed := c.onlineAgents()
+	if err, ok := failed[req.AgentId]; ok {
+		return nil, status.Errorf(codes.Unavailable, "agent %s: %v", req.AgentId, err)
+	}
+	for id, err := range failed {
+		log.Printf("Quota usage: skipping agent %s: %v", id, err)
+	}
+
+	results := fanOut(ctx, c.fanOut, conns, func(ctx context.Context, conn *AgentConnection) ([]*agentv1.Stack, error) {
+		return c.agentStacks(ctx, conn, "")
+	})
+	for _, r := range results {
+		conn, stacks := r.conn, r.value
+		if r.err != nil {
+			if conn.ID == req.AgentId {
+				return nil, r.err
+			}
+			log.Printf("Quota usage: skipping agent %s: %v", conn.ID, r.err)
+			continue
+		}
+
+		if (req.AgentId == "" || conn.ID == req.AgentId) && c.authorizeAgent(ctx, conn, "read", "stack:*") == nil {
+			count, used := sumStacks(stacks, "")
+			result.Agents = append(result.Agents, &agentv1.AgentQuotaUsage{
+				AgentId:       conn.ID,
+				Stacks:        int32(count),
+				MaxStacks:     int32(c.quotas.agent.MaxStacks),
+				Containers:    int32(used.Containers),
+				MaxContainers: int32(c.quotas.agent.MaxContainers),
+			})
+		}
+
+		for _, stack := range stacks {
+			ns := normalizeNamespace(stack.Namespace)
+			if req.Namespace != "" && ns != req.Namespace {
+				continue
+			}
+			usage, ok := namespaces[ns]
+			if !ok {
+				usage = &namespaceTotals{}
+				namespaces[ns] = usage
+			}
+			_, used := sumStacks([]*agentv1.Stack{stack}, "")
+			usage.stacks++
+			usage.Add(used)
+		}
+	}
+
+	// Namespaces with a configured limit are listed even when empty
+	for ns := range c.quotas.namespaces {
+		if ns == "*" || (req.Namespace != "" && ns != req.Namespace) {
+			continue
+		}
+		if _, ok := namespaces[ns]; !ok {
+			namespaces[ns] = &namespaceTotals{}
+		}
+	}
+
+	for ns, usage := range namespaces {
+		if c.authorizeNamespace(ctx, "read", ns, "stack:*") != nil {
+			continue
+		}
+		limit, _ := c.quotas.namespace(ns)
+		result.Namespaces = append(result.Namespaces, &agentv1.NamespaceQuotaUsage{
+			Namespace:      ns,
+			Stacks:         int32(usage.stacks),
+			MaxStacks:      int32(limit.maxStacks),
+			Cpus:           usage.CPUs,
+			MaxCpus:        limit.maxCPUs,
+			MemoryBytes:    usage.MemoryBytes,
+			MaxMemoryBytes: limit.maxMemoryBytes,
+		})
+	}
+	sort.Slice(result.Namespaces, func(i, j int) bool {
+		return result.Namespaces[i].Namespace < result.Namespaces[j].Namespace
+	})
+
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultReadCacheTTL applies when read_cache.ttl is not set
+const defaultReadCacheTTL = 2 * time.Second
+
+// readCacheSweepSize is the entry count above which expired entries are
+// removed on insert
+const readCacheSweepSize = 1024
+
+// ReadCache keeps proxied agent read responses for a short TTL, keyed by
+// agent, method and request. Callers authorize before looking up, so a hit
+// never bypasses policy.
+type ReadCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[readCacheKey]readCacheEntry
+}
+
+type readCacheKey struct {
+	agentID string
+	method  string
+	request string // Deterministic encoding of the request
+}
+
+type readCacheEntry struct {
+	response proto.Message
+	expires  time.Time
+}
+
+func newReadCache(cfg config.ReadCacheConfig) *ReadCache {
+	ttl := defaultReadCacheTTL
+	if cfg.TTL != "" {
+		if d, err := time.ParseDuration(cfg.TTL); err == nil && d >= 0 {
+			ttl = d
+		} else {
+			log.Printf("Invalid read_cache ttl %q, using %s", cfg.TTL, ttl)
+		}
+	}
+
+	return &ReadCache{ttl: ttl, entries: make(map[readCacheKey]readCacheEntry)}
+}
+
+func (r *ReadCache) key(agentID, method string, req proto.Message) (readCacheKey, bool) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return readCacheKey{}, false
+	}
+	return readCacheKey{agentID: agentID, method: method, request: string(data)}, true
+}
+
+// get returns a copy of the cached response to req, if still fresh
+func (r *ReadCache) get(agentID, method string, req proto.Message, now time.Time) (proto.Message, bool) {
+	if r.ttl == 0 {
+		return nil, false
+	}
+	key, ok := r.key(agentID, method, req)
+	if !ok {
+		return nil, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return proto.Clone(entry.response), true
+}
+
+// put caches a copy of resp as the response to req
+func (r *ReadCache) put(agentID, method string, req, resp proto.Message, now time.Time) {
+	if r.ttl == 0 {
+		return
+	}
+	key, ok := r.key(agentID, method, req)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) >= readCacheSweepSize {
+		for k, e := range r.entries {
+			if !now.Before(e.expires) {
+				delete(r.entries, k)
+			}
+		}
+	}
+	r.entries[key] = readCacheEntry{response: proto.Clone(resp), expires: now.Add(r.ttl)}
+}
+
+// invalidateAgent drops every cached response from an agent; mutations call
+// it so readers never see state older than a change they caused
+func (r *ReadCache) invalidateAgent(agentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.entries {
+		if k.agentID == agentID {
+			delete(r.entries, k)
+		}
+	}
+}
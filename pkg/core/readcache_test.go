@@ -0,0 +1,81 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestReadCache(t *testing.T) {
+	const method = agentv1.StackService_ListStacks_FullMethodName
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &agentv1.ListStacksRequest{AgentId: "a1", Namespace: "team-a"}
+	resp := &agentv1.ListStacksResponse{Stacks: []*agentv1.Stack{{Name: "web"}}}
+
+	tests := []struct {
+		name   string
+		setup  func(r *ReadCache)
+		agent  string
+		req    *agentv1.ListStacksRequest
+		at     time.Time
+		wantOK bool
+	}{
+		{name: "hit", agent: "a1", req: req, at: now.Add(time.Second), wantOK: true},
+		{name: "expired", agent: "a1", req: req, at: now.Add(2 * time.Second)},
+		{name: "other agent", agent: "a2", req: req, at: now},
+		{name: "other request", agent: "a1", req: &agentv1.ListStacksRequest{AgentId: "a1"}, at: now},
+		{
+			name:  "invalidated",
+			setup: func(r *ReadCache) { r.invalidateAgent("a1") },
+			agent: "a1", req: req, at: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReadCache(config.ReadCacheConfig{TTL: "2s"})
+			r.put("a1", method, req, resp, now)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+
+			got, ok := r.get(tt.agent, method, tt.req, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("get() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.(*agentv1.ListStacksResponse).Stacks[0].Name != "web" {
+				t.Errorf("get() = %v", got)
+			}
+		})
+	}
+}
+
+func TestReadCacheReturnsCopies(t *testing.T) {
+	const method = agentv1.StackService_GetStack_FullMethodName
+	now := time.Now()
+	req := &agentv1.GetStackRequest{StackId: "web"}
+	r := newReadCache(config.ReadCacheConfig{})
+	r.put("a1", method, req, &agentv1.GetStackResponse{Stack: &agentv1.Stack{Name: "web"}}, now)
+
+	first, _ := r.get("a1", method, req, now)
+	first.(*agentv1.GetStackResponse).Stack.Name = "changed"
+
+	second, ok := r.get("a1", method, req, now)
+	if !ok || second.(*agentv1.GetStackResponse).Stack.Name != "web" {
+		t.Errorf("cached response was modified through a returned copy: %v", second)
+	}
+}
+
+func TestReadCacheDisabled(t *testing.T) {
+	const method = agentv1.StackService_ListStacks_FullMethodName
+	now := time.Now()
+	req := &agentv1.ListStacksRequest{}
+	r := newReadCache(config.ReadCacheConfig{TTL: "0s"})
+	r.put("a1", method, req, &agentv1.ListStacksResponse{}, now)
+
+	if _, ok := r.get("a1", method, req, now); ok {
+		t.Error("disabled cache returned a response")
+	}
+}
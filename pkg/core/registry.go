@@ -0,0 +1,161 @@
+package core
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// registryShards spreads agents over maps of their own, so that lookups of
+// different agents do not contend
+const registryShards = 32
+
+// AgentRegistry holds the agents the core knows, sharded by a hash of their
+// ID. Lookups lock one shard; listings use a snapshot of the membership
+// that is rebuilt only after agents join or register again. The fields of
+// each agent are guarded by its own mutex.
+type AgentRegistry struct {
+	shards     [registryShards]registryShard
+	count      atomic.Int64
+	generation atomic.Uint64 // Counts joins and replacements
+	snapshot   atomic.Pointer[registrySnapshot]
+
+	changeMu sync.Mutex
+	changes  chan struct{} // Closed at the next change
+}
+
+// registrySnapshot is the membership as of a generation
+type registrySnapshot struct {
+	generation uint64
+	agents     []*AgentConnection
+}
+
+type registryShard struct {
+	mu     sync.RWMutex
+	agents map[string]*AgentConnection
+}
+
+// newAgentRegistry returns a registry holding agents
+func newAgentRegistry(agents ...*AgentConnection) *AgentRegistry {
+	r := &AgentRegistry{}
+	for _, agent := range agents {
+		r.put(agent)
+	}
+	return r
+}
+
+func (r *AgentRegistry) shard(agentID string) *registryShard {
+	h := fnv.New32a()
+	h.Write([]byte(agentID))
+	return &r.shards[h.Sum32()%registryShards]
+}
+
+// get returns the agent with ID agentID
+func (r *AgentRegistry) get(agentID string) (*AgentConnection, bool) {
+	s := r.shard(agentID)
+	s.mu.RLock()
+	agent, ok := s.agents[agentID]
+	s.mu.RUnlock()
+	return agent, ok
+}
+
+// lock returns the agent with ID agentID locked for writing; the caller
+// unlocks it. Registering the agent again waits for the unlock, so what the
+// caller changes is carried over rather than lost with the replaced record.
+func (r *AgentRegistry) lock(agentID string) (*AgentConnection, bool) {
+	s := r.shard(agentID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agent, ok := s.agents[agentID]
+	if ok {
+		agent.mu.Lock()
+		// Watches read the agent once the caller unlocks it
+		r.touch()
+	}
+	return agent, ok
+}
+
+// put adds agent, replacing the agent with its ID, and returns the one it
+// replaced
+func (r *AgentRegistry) put(agent *AgentConnection) *AgentConnection {
+	return r.replace(agent.ID, func(*AgentConnection) *AgentConnection { return agent })
+}
+
+// replace stores what update returns for the agent with ID agentID, given
+// the agent stored now or nil, and returns the agent it replaced. update
+// runs with the shard locked, so concurrent replacements of one agent take
+// turns; it may lock the agent it is given, but no other.
+func (r *AgentRegistry) replace(agentID string, update func(existing *AgentConnection) *AgentConnection) *AgentConnection {
+	s := r.shard(agentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.agents[agentID]
+	agent := update(existing)
+	if s.agents == nil {
+		s.agents = make(map[string]*AgentConnection)
+	}
+	s.agents[agentID] = agent
+	if existing == nil {
+		r.count.Add(1)
+	}
+	r.generation.Add(1)
+	r.touch()
+	return existing
+}
+
+// changed returns a channel closed at the next change to an agent
+func (r *AgentRegistry) changed() <-chan struct{} {
+	r.changeMu.Lock()
+	defer r.changeMu.Unlock()
+	if r.changes == nil {
+		r.changes = make(chan struct{})
+	}
+	return r.changes
+}
+
+// touch tells those waiting on changed that an agent changed. lock and
+// replace call it; writers that lock an agent themselves call it for the
+// changes watches show.
+func (r *AgentRegistry) touch() {
+	r.changeMu.Lock()
+	if r.changes != nil {
+		close(r.changes)
+		r.changes = nil
+	}
+	r.changeMu.Unlock()
+}
+
+// len returns how many agents are registered
+func (r *AgentRegistry) len() int {
+	return int(r.count.Load())
+}
+
+// all returns the registered agents ordered by ID. The slice is shared
+// between callers until agents join or register again, and must not be
+// modified.
+func (r *AgentRegistry) all() []*AgentConnection {
+	generation := r.generation.Load()
+	if snapshot := r.snapshot.Load(); snapshot != nil && snapshot.generation == generation {
+		return snapshot.agents
+	}
+
+	agents := make([]*AgentConnection, 0, r.len())
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.mu.RLock()
+		for _, agent := range s.agents {
+			agents = append(agents, agent)
+		}
+		s.mu.RUnlock()
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+
+	// Changed while the shards were read: correct for this caller only
+	if r.generation.Load() == generation {
+		r.snapshot.Store(&registrySnapshot{generation: generation, agents: agents})
+	}
+	return agents
+}
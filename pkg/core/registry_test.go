@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func TestAgentRegistry(t *testing.T) {
+	r := newAgentRegistry(
+		&AgentConnection{ID: "web-2"},
+		&AgentConnection{ID: "web-1"},
+		&AgentConnection{ID: "db-1"},
+	)
+	if r.len() != 3 {
+		t.Fatalf("len = %d, want 3", r.len())
+	}
+	if _, ok := r.get("web-3"); ok {
+		t.Error("unknown agent found")
+	}
+
+	all := r.all()
+	var ids []string
+	for _, agent := range all {
+		ids = append(ids, agent.ID)
+	}
+	if fmt.Sprint(ids) != "[db-1 web-1 web-2]" {
+		t.Errorf("all = %v, want agents by ID", ids)
+	}
+	if again := r.all(); &again[0] != &all[0] {
+		t.Error("snapshot built again without changes")
+	}
+
+	// A change made under lock carries over to the agent registering again
+	agent, ok := r.lock("web-1")
+	if !ok {
+		t.Fatal("web-1 not found")
+	}
+	agent.OperatorLabels = map[string]string{"env": "prod"}
+	agent.mu.Unlock()
+
+	replaced := r.replace("web-1", func(existing *AgentConnection) *AgentConnection {
+		return &AgentConnection{ID: "web-1", OperatorLabels: existing.OperatorLabels}
+	})
+	if replaced != agent {
+		t.Error("replace did not return the replaced agent")
+	}
+	if current, _ := r.get("web-1"); current == agent || current.OperatorLabels["env"] != "prod" {
+		t.Error("registering again lost the operator labels")
+	}
+	if r.len() != 3 {
+		t.Errorf("len = %d after registering again, want 3", r.len())
+	}
+	if latest := r.all(); latest[1] == agent {
+		t.Error("snapshot still holds the replaced agent")
+	}
+}
+
+// fleetCore returns a core with agents registered agents, all online
+func fleetCore(agents int) *Core {
+	r := newAgentRegistry()
+	for i := 0; i < agents; i++ {
+		id := fmt.Sprintf("agent-%05d", i)
+		r.put(&AgentConnection{
+			ID:       id,
+			Hostname: id,
+			Labels:   map[string]string{"role": []string{"web", "db", "cache"}[i%3]},
+			Status:   AgentStatusOnline,
+			LastSeen: time.Now(),
+		})
+	}
+	groups, _ := newGroupRegistry(nil, "")
+	return &Core{
+		agents:   r,
+		groups:   groups,
+		breakers: newCircuitBreakers(config.CircuitBreakerConfig{}),
+	}
+}
+
+// The benchmarks run against 10k registered agents, looking agents up,
+// listing them and taking their heartbeats from many goroutines at once
+func BenchmarkAgentRegistry(b *testing.B) {
+	const agents = 10000
+	c := fleetCore(agents)
+	ctx := context.Background()
+
+	b.Run("get", func(b *testing.B) {
+		var next atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				id := fmt.Sprintf("agent-%05d", next.Add(1)%agents)
+				if _, ok := c.agents.get(id); !ok {
+					b.Errorf("%s not found", id)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("list", func(b *testing.B) {
+		req := &agentv1.ListAgentsRequest{Labels: map[string]string{"role": "web"}}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := c.ListAgents(ctx, req); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("heartbeat", func(b *testing.B) {
+		var next atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				id := fmt.Sprintf("agent-%05d", next.Add(1)%agents)
+				agent, _ := c.agents.get(id)
+				c.applyHeartbeat(ctx, agent, &heartbeat{agentID: id, req: &agentv1.HeartbeatRequest{AgentId: id}, received: time.Now()})
+			}
+		})
+	})
+
+	// Heartbeats and listings contend only on the agents they share
+	b.Run("list with heartbeats", func(b *testing.B) {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				id := fmt.Sprintf("agent-%05d", i%agents)
+				agent, _ := c.agents.get(id)
+				c.applyHeartbeat(ctx, agent, &heartbeat{agentID: id, req: &agentv1.HeartbeatRequest{AgentId: id}, received: time.Now()})
+			}
+		}()
+
+		req := &agentv1.ListAgentsRequest{Labels: map[string]string{"role": "web"}}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := c.ListAgents(ctx, req); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
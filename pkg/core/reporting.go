@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/report"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Reporter keeps the latest resource snapshot and writes periodic ones
+type Reporter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	dir      string
+	formats  []string
+	last     *report.Snapshot
+}
+
+func newReporter(cfg config.ReportingConfig) (*Reporter, error) {
+	r := &Reporter{
+		dir:     cfg.Dir,
+		formats: cfg.Formats,
+	}
+	if len(r.formats) == 0 {
+		r.formats = []string{"json"}
+	}
+	for _, format := range r.formats {
+		if format != "json" && format != "csv" {
+			return nil, fmt.Errorf("unknown report format %q", format)
+		}
+	}
+	if cfg.Interval != "" {
+		d, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("parse interval: %w", err)
+		}
+		r.interval = d
+	}
+	return r, nil
+}
+
+// runReports takes a snapshot every interval until ctx is cancelled
+func (c *Core) runReports(ctx context.Context) {
+	if c.reporter.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.reporter.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snap, err := c.takeSnapshot(ctx)
+			if err != nil {
+				log.Printf("Resource report failed: %v", err)
+				continue
+			}
+			if err := c.reporter.write(snap); err != nil {
+				log.Printf("Write resource report: %v", err)
+			}
+		}
+	}
+}
+
+// takeSnapshot collects stack resources from every online agent. Agents that
+// cannot be read are recorded in the snapshot's errors instead of failing it.
+func (c *Core) takeSnapshot(ctx context.Context) (*report.Snapshot, error) {
+	var rows []report.StackRow
+	failures := make(map[string]string)
+
+	conns, failed := c.onlineAgents()
+	for id, err := range failed {
+		failures[id] = err.Error()
+	}
+
+	results := fanOut(ctx, c.fanOut, conns, func(ctx context.Context, conn *AgentConnection) ([]*agentv1.Stack, error) {
+		return c.agentStacks(ctx, conn, "")
+	})
+	for _, r := range results {
+		conn, stacks := r.conn, r.value
+		if r.err != nil {
+			failures[conn.ID] = r.err.Error()
+			continue
+		}
+
+		for _, stack := range stacks {
+			running := 0
+			for _, container := range stack.Containers {
+				if container.State == "running" {
+					running++
+				}
+			}
+
+			res := stack.GetResources()
+			rows = append(rows, report.StackRow{
+				AgentID:     conn.ID,
+				Namespace:   normalizeNamespace(stack.Namespace),
+				Stack:       stack.Name,
+				State:       stack.State.String(),
+				Team:        stack.GetOwner().GetTeam(),
+				Owner:       stack.GetOwner().GetOwner(),
+				Labels:      stack.Labels,
+				Containers:  int(res.GetContainers()),
+				Running:     running,
+				CPUs:        res.GetCpus(),
+				MemoryBytes: res.GetMemoryBytes(),
+			})
+		}
+	}
+
+	snap := report.New(time.Now(), rows)
+	if len(failures) > 0 {
+		snap.Errors = failures
+		log.Printf("Resource report is missing %d agent(s): %v", len(failures), failures)
+	}
+
+	c.reporter.mu.Lock()
+	c.reporter.last = snap
+	c.reporter.mu.Unlock()
+
+	return snap, nil
+}
+
+// write stores snap in every configured format under the report directory
+func (r *Reporter) write(snap *report.Snapshot) error {
+	if r.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("create report dir: %w", err)
+	}
+
+	stamp := snap.GeneratedAt.UTC().Format("20060102T150405Z")
+	for _, format := range r.formats {
+		path := filepath.Join(r.dir, "report-"+stamp+"."+format)
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", path, err)
+		}
+		err = snap.Write(f, format)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// GetResourceReport returns the latest snapshot, taking one when none exists
+// yet or a refresh is requested. Only stacks in namespaces the caller may
+// read on their agent are included.
+func (c *Core) GetResourceReport(ctx context.Context, req *agentv1.GetResourceReportRequest) (*agentv1.ResourceReport, error) {
+	c.reporter.mu.Lock()
+	snap := c.reporter.last
+	c.reporter.mu.Unlock()
+
+	if snap == nil || req.Refresh {
+		var err error
+		if snap, err = c.takeSnapshot(ctx); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "take snapshot: %v", err)
+		}
+	}
+
+	reader := c.newStackReader(ctx)
+	result := &agentv1.ResourceReport{
+		GeneratedAt: timestamppb.New(snap.GeneratedAt),
+		Stacks:      make([]*agentv1.StackUsage, 0, len(snap.Stacks)),
+	}
+	for _, row := range snap.Stacks {
+		if !reader.can(row.AgentID, row.Namespace) {
+			continue
+		}
+		result.Stacks = append(result.Stacks, &agentv1.StackUsage{
+			AgentId:           row.AgentID,
+			Namespace:         row.Namespace,
+			Stack:             row.Stack,
+			State:             agentv1.StackState(agentv1.StackState_value[row.State]),
+			Owner:             &agentv1.StackOwner{Team: row.Team, Owner: row.Owner},
+			Labels:            row.Labels,
+			Containers:        int32(row.Containers),
+			RunningContainers: int32(row.Running),
+			Cpus:              row.CPUs,
+			MemoryBytes:       row.MemoryBytes,
+		})
+	}
+
+	for agentID, msg := range snap.Errors {
+		if reader.can(agentID, "") {
+			if result.AgentErrors == nil {
+				result.AgentErrors = make(map[string]string)
+			}
+			result.AgentErrors[agentID] = msg
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/report"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reportAgent answers ListStacks with the fixed stacks of each agent and
+// fails for agents it has none for
+type reportAgent struct {
+	agentv1.UnimplementedStackServiceServer
+	stacks map[string][]*agentv1.Stack
+}
+
+func (a *reportAgent) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
+	stacks, ok := a.stacks[req.AgentId]
+	if !ok {
+		return nil, status.Error(codes.Internal, "docker daemon not running")
+	}
+	return &agentv1.ListStacksResponse{Stacks: stacks}, nil
+}
+
+func TestResourceReport(t *testing.T) {
+	running := []*agentv1.Container{{Name: "shop-web-1", State: "running"}, {Name: "shop-worker-1", State: "exited"}}
+	server := grpc.NewServer()
+	agentv1.RegisterStackServiceServer(server, &reportAgent{stacks: map[string][]*agentv1.Stack{
+		"web-1": {
+			{
+				Name:       "shop",
+				Namespace:  "team-a",
+				State:      agentv1.StackState_STACK_STATE_PARTIAL,
+				Containers: running,
+				Labels:     map[string]string{"tier": "frontend", "app": "shop"},
+				Owner:      &agentv1.StackOwner{Team: "payments", Owner: "alice"},
+				Resources:  &agentv1.StackResources{Containers: 2, Cpus: 1.5, MemoryBytes: 512 << 20},
+			},
+			{Name: "cache", State: agentv1.StackState_STACK_STATE_STOPPED},
+		},
+		"db-1": {{
+			Name:       "orders",
+			Namespace:  "team-a",
+			State:      agentv1.StackState_STACK_STATE_RUNNING,
+			Containers: []*agentv1.Container{{Name: "orders-db-1", State: "running"}},
+			Owner:      &agentv1.StackOwner{Team: "payments"},
+			Resources:  &agentv1.StackResources{Containers: 1, Cpus: 2, MemoryBytes: 1 << 30},
+		}},
+	}})
+	client := serve(t, server)
+
+	plugins := plugin.NewRegistry()
+	if err := plugins.Register(&grantAuth{grants: map[string][]string{
+		"alice": {"read namespace:team-a/stack:*"},
+		"admin": {"read stack:*"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := newGroupRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	reporter, err := newReporter(config.ReportingConfig{Dir: dir, Formats: []string{"json", "csv"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: []string{"docker"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "db-1", Capabilities: []string{"docker"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "broken-1", Capabilities: []string{"docker"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+			// Neither read nor reported as missing
+			&AgentConnection{ID: "gone-1", Capabilities: []string{"docker"}, Status: AgentStatusOffline, LastSeen: time.Now().Add(-time.Hour)},
+			&AgentConnection{ID: "dns-1", Capabilities: []string{"dns"}, Client: client, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		groups:   groups,
+		breakers: newCircuitBreakers(config.CircuitBreakerConfig{}),
+		plugins:  plugins,
+		fanOut:   newFanOutLimits(config.FanOutConfig{}),
+		reporter: reporter,
+	}
+
+	snap, err := c.takeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStacks := []report.StackRow{
+		{AgentID: "web-1", Namespace: "default", Stack: "cache", State: "STACK_STATE_STOPPED"},
+		{AgentID: "db-1", Namespace: "team-a", Stack: "orders", State: "STACK_STATE_RUNNING", Team: "payments",
+			Containers: 1, Running: 1, CPUs: 2, MemoryBytes: 1 << 30},
+		{AgentID: "web-1", Namespace: "team-a", Stack: "shop", State: "STACK_STATE_PARTIAL", Team: "payments", Owner: "alice",
+			Labels: map[string]string{"tier": "frontend", "app": "shop"}, Containers: 2, Running: 1, CPUs: 1.5, MemoryBytes: 512 << 20},
+	}
+	if !reflect.DeepEqual(snap.Stacks, wantStacks) {
+		t.Errorf("stacks = %+v, want %+v", snap.Stacks, wantStacks)
+	}
+	wantNamespaces := []report.Total{
+		{Key: "default", Stacks: 1},
+		{Key: "team-a", Stacks: 2, Containers: 3, Running: 2, CPUs: 3.5, MemoryBytes: 1<<30 + 512<<20},
+	}
+	if !reflect.DeepEqual(snap.Namespaces, wantNamespaces) {
+		t.Errorf("namespace totals = %+v, want %+v", snap.Namespaces, wantNamespaces)
+	}
+	wantTeams := []report.Total{
+		{Key: "-", Stacks: 1},
+		{Key: "payments", Stacks: 2, Containers: 3, Running: 2, CPUs: 3.5, MemoryBytes: 1<<30 + 512<<20},
+	}
+	if !reflect.DeepEqual(snap.Teams, wantTeams) {
+		t.Errorf("team totals = %+v, want %+v", snap.Teams, wantTeams)
+	}
+	if len(snap.Errors) != 1 || snap.Errors["broken-1"] == "" {
+		t.Errorf("errors = %v, want broken-1 only", snap.Errors)
+	}
+
+	// Written in every configured format
+	if err := reporter.write(snap); err != nil {
+		t.Fatal(err)
+	}
+	stamp := snap.GeneratedAt.UTC().Format("20060102T150405Z")
+	csv, err := os.ReadFile(filepath.Join(dir, "report-"+stamp+".csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := snap.GeneratedAt.UTC().Format(time.RFC3339)
+	wantCSV := "generated_at,agent_id,namespace,stack,state,team,owner,containers,running,cpus,memory_bytes,labels\n" +
+		at + ",web-1,default,cache,STACK_STATE_STOPPED,,,0,0,0,0,\n" +
+		at + ",db-1,team-a,orders,STACK_STATE_RUNNING,payments,,1,1,2,1073741824,\n" +
+		at + ",web-1,team-a,shop,STACK_STATE_PARTIAL,payments,alice,2,1,1.5,536870912,app=shop;tier=frontend\n"
+	if string(csv) != wantCSV {
+		t.Errorf("CSV report:\n%s\nwant:\n%s", csv, wantCSV)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "report-"+stamp+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written report.Snapshot
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written.Stacks, wantStacks) || !reflect.DeepEqual(written.Teams, wantTeams) ||
+		!reflect.DeepEqual(written.Errors, snap.Errors) {
+		t.Errorf("JSON report = %+v", written)
+	}
+
+	// Callers see the stacks and agent errors they may read
+	as := func(user string) context.Context {
+		return plugin.WithIdentity(context.Background(), &plugin.Identity{UserID: user})
+	}
+	tests := []struct {
+		user   string
+		stacks []string
+		errors []string
+	}{
+		{"admin", []string{"web-1/cache", "db-1/orders", "web-1/shop"}, []string{"broken-1"}},
+		{"alice", []string{"db-1/orders", "web-1/shop"}, nil},
+		{"mallory", nil, nil},
+	}
+	for _, tt := range tests {
+		resp, err := c.GetResourceReport(as(tt.user), &agentv1.GetResourceReportRequest{})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.user, err)
+		}
+		if !resp.GeneratedAt.AsTime().Equal(snap.GeneratedAt) {
+			t.Errorf("%s: report taken at %v, want the last snapshot's %v", tt.user, resp.GeneratedAt.AsTime(), snap.GeneratedAt)
+		}
+		var stacks, errors []string
+		for _, s := range resp.Stacks {
+			stacks = append(stacks, s.AgentId+"/"+s.Stack)
+		}
+		for id := range resp.AgentErrors {
+			errors = append(errors, id)
+		}
+		if !reflect.DeepEqual(stacks, tt.stacks) || !reflect.DeepEqual(errors, tt.errors) {
+			t.Errorf("%s: stacks %v, errors %v; want %v, %v", tt.user, stacks, errors, tt.stacks, tt.errors)
+		}
+	}
+
+	resp, err := c.GetResourceReport(as("admin"), &agentv1.GetResourceReportRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range resp.Stacks {
+		if s.Stack != "shop" {
+			continue
+		}
+		if s.State != agentv1.StackState_STACK_STATE_PARTIAL || s.Owner.GetTeam() != "payments" || s.Owner.GetOwner() != "alice" ||
+			s.Containers != 2 || s.RunningContainers != 1 || s.Cpus != 1.5 || s.MemoryBytes != 512<<20 || s.Labels["tier"] != "frontend" {
+			t.Errorf("shop usage = %v", s)
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"net"
 	"strings"
@@ -13,27 +13,68 @@ import (
 	"time"
 
 	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/audit"
+	"github.com/bhangun/mandau/pkg/certidentity"
+	"github.com/bhangun/mandau/pkg/chaos"
 	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/obligation"
 	"github.com/bhangun/mandau/pkg/plugin"
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/bhangun/mandau/pkg/transport"
 	"github.com/bhangun/mandau/plugins/auth/rbac"
+	"github.com/bhangun/mandau/plugins/notify/webhook"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Core is the central control plane that manages multiple agents
 type Core struct {
 	agentv1.UnimplementedCoreServiceServer
 	agentv1.UnimplementedStackServiceServer
-	config  *CoreConfig
-	agents  *AgentRegistry
-	plugins *plugin.Registry
-	audit   *AuditLogger
-	authz   *Authorizer
+	agentv1.UnimplementedTransferServiceServer
+	agentv1.UnimplementedArtifactServiceServer
+	agentv1.UnimplementedEnrollmentServiceServer
+	agentv1.UnimplementedAdminServiceServer
+	config       *CoreConfig
+	agents       *AgentRegistry
+	plugins      *plugin.Registry
+	audit        *AuditLogger
+	authz        *Authorizer
+	groups       *GroupRegistry
+	approvals    *ApprovalStore
+	breakGlass   *BreakGlassStore
+	quotas       *QuotaLimits
+	reporter     *Reporter
+	readCache    *ReadCache
+	fanOut       fanOutLimits
+	breakers     *circuitBreakers
+	maxClockSkew time.Duration
+	enroller     *Enroller // Nil when enrollment is disabled
+	instructions *InstructionQueue
+	freeze       *Freeze
+	activity     *Activity
+	artifacts    *Artifacts // Nil unless artifacts.dir is set
+	startedAt    time.Time
+
+	agentIdentities *AgentIdentities
+	listenAddrs     []string // Set by Serve
+	proxyRoutes     *ProxyRoutes
+	chaos           *chaos.Injector // Nil unless chaos testing is enabled
+	redactor        *redact.Redactor
+	identities      *certidentity.Mapper        // Nil when callers are named by CN alone
+	agentTLS        transport.ClientConfigCache // Client configurations of dials to agents
+
+	heartbeats        heartbeatBook
+	heartbeatSettings heartbeatSettings
 }
 
 type CoreConfig struct {
@@ -42,25 +83,42 @@ type CoreConfig struct {
 	KeyPath    string
 	CAPath     string
 	PluginDir  string
+	Version    string // Reported by the cluster status
 	// Add a field to hold the full configuration
 	FullConfig *config.CoreConfig
+	// AgentDialer connects to agents in place of the network, e.g. to
+	// in-memory listeners in tests; nil dials over TCP
+	AgentDialer func(ctx context.Context, addr string) (net.Conn, error)
 }
 
-type AgentRegistry struct {
-	mu     sync.RWMutex
-	agents map[string]*AgentConnection
-}
-
+// AgentConnection is the core's record of one agent. ID, Hostname,
+// Capabilities and DialOutOnly are set at registration, which replaces the
+// record; mu guards the other fields.
 type AgentConnection struct {
+	mu sync.RWMutex
+
 	ID           string
 	Hostname     string
 	Address      string
-	Labels       map[string]string
+	Labels       map[string]string // Effective labels; replaced, never mutated
 	Capabilities []string
-	Client       *grpc.ClientConn  // Changed from grpc.ClientConnInterface to *grpc.ClientConn
+	Client       *grpc.ClientConn // Changed from grpc.ClientConnInterface to *grpc.ClientConn
 	LastSeen     time.Time
 	Status       AgentStatus
+	DialOutOnly  bool     // Takes work from heartbeats; the core never dials it
 	Stacks       []string // List of stack IDs/names on this agent
+	Maintenance  *MaintenanceWindow
+	Certificate  *x509.Certificate             // Last presented client certificate
+	Plugins      []*agentv1.InstalledPlugin    // Installed from the plugin index; replaced, never mutated
+	Unavailable  []*agentv1.PluginAvailability // Enabled host service plugins its host cannot run
+	ClockSkew    *time.Duration                // Agent clock minus core clock; nil until reported
+	ClockSkewed  bool                          // ClockSkew is over agent_management.max_clock_skew
+
+	// Labels reported by the agent at registration, and those set or
+	// removed by operators through UpdateAgentLabels. Operator changes win.
+	ReportedLabels map[string]string
+	OperatorLabels map[string]string
+	RemovedLabels  map[string]bool
 }
 
 type AgentStatus string
@@ -96,8 +154,6 @@ func (a *AuditLogger) LogAgentOffline(ctx context.Context, agentID string) {
 }
 
 func NewCore(cfg *CoreConfig) (*Core, error) {
-	plugins := plugin.NewRegistry()
-
 	// Load the full configuration from file if available
 	configPath := config.GetConfigPath("config/core/config.yaml")
 	fullConfig, err := config.LoadCoreConfig(configPath)
@@ -109,11 +165,55 @@ func NewCore(cfg *CoreConfig) (*Core, error) {
 		log.Printf("Loaded configuration from %s", configPath)
 	}
 
+	return NewCoreWithConfig(cfg, fullConfig)
+}
+
+// NewCoreWithConfig creates a core from fullConfig rather than the config
+// file, for embedding the core in tests and other programs
+func NewCoreWithConfig(cfg *CoreConfig, fullConfig *config.CoreConfig) (*Core, error) {
+	plugins := plugin.NewRegistry()
+
+	redactor, err := redact.New(fullConfig.Redaction.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	plugins.SetRedactor(redactor)
+
+	identities, err := certidentity.New(fullConfig.Server.Identity)
+	if err != nil {
+		return nil, fmt.Errorf("server.%w", err)
+	}
+
 	// Load plugins
 	if err := loadPlugins(plugins, cfg.PluginDir, fullConfig.Plugins); err != nil {
 		return nil, fmt.Errorf("load plugins: %w", err)
 	}
 
+	if len(fullConfig.Anomaly.Rules) > 0 {
+		detector, err := newAnomalyDetector(plugins, fullConfig.Anomaly)
+		if err != nil {
+			return nil, fmt.Errorf("anomaly rules: %w", err)
+		}
+		if err := plugins.Register(detector); err != nil {
+			return nil, fmt.Errorf("register anomaly detector: %w", err)
+		}
+	}
+
+	quotas, err := newQuotaLimits(fullConfig.Quotas)
+	if err != nil {
+		return nil, fmt.Errorf("quotas: %w", err)
+	}
+
+	reporter, err := newReporter(fullConfig.Reporting)
+	if err != nil {
+		return nil, fmt.Errorf("reporting: %w", err)
+	}
+
+	groups, err := newGroupRegistry(fullConfig.Groups, fullConfig.GroupsFile)
+	if err != nil {
+		return nil, fmt.Errorf("groups: %w", err)
+	}
+
 	// Update the CoreConfig with values from the loaded config
 	if fullConfig.Server.ListenAddr != "" {
 		cfg.ListenAddr = fullConfig.Server.ListenAddr
@@ -134,12 +234,76 @@ func NewCore(cfg *CoreConfig) (*Core, error) {
 	// Store the full configuration
 	cfg.FullConfig = fullConfig
 
+	enroller, err := newEnroller(fullConfig.Enrollment, cfg.CAPath)
+	if err != nil {
+		return nil, fmt.Errorf("enrollment: %w", err)
+	}
+
+	instructions, err := newInstructionQueue(fullConfig.OfflineQueue)
+	if err != nil {
+		return nil, fmt.Errorf("offline queue: %w", err)
+	}
+
+	agentIdentities, err := newAgentIdentities(fullConfig.AgentManagement.IdentityFile)
+	if err != nil {
+		return nil, fmt.Errorf("agent identities: %w", err)
+	}
+	if enroller != nil {
+		enroller.bind = func(agentID, identity string) error {
+			return agentIdentities.check(agentID, identity, true)
+		}
+	}
+
+	freeze, err := newFreeze(fullConfig.FreezeFile)
+	if err != nil {
+		return nil, fmt.Errorf("freeze: %w", err)
+	}
+
+	proxyRoutes, err := newProxyRoutes(fullConfig.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+
+	injector, err := chaos.New(fullConfig.Chaos)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := newArtifacts(fullConfig.Artifacts)
+	if err != nil {
+		return nil, fmt.Errorf("artifacts: %w", err)
+	}
+
 	return &Core{
-		config:  cfg,
-		agents:  &AgentRegistry{agents: make(map[string]*AgentConnection)},
-		plugins: plugins,
-		audit:   NewAuditLogger(plugins),
-		authz:   NewAuthorizer(plugins),
+		config:       cfg,
+		agents:       newAgentRegistry(),
+		plugins:      plugins,
+		audit:        NewAuditLogger(plugins),
+		authz:        NewAuthorizer(plugins),
+		groups:       groups,
+		approvals:    newApprovalStore(fullConfig.Approvals),
+		breakGlass:   newBreakGlassStore(fullConfig.BreakGlass),
+		quotas:       quotas,
+		reporter:     reporter,
+		readCache:    newReadCache(fullConfig.ReadCache),
+		fanOut:       newFanOutLimits(fullConfig.FanOut),
+		breakers:     newCircuitBreakers(fullConfig.CircuitBreaker),
+		maxClockSkew: parseMaxClockSkew(fullConfig.AgentManagement.MaxClockSkew),
+		enroller:     enroller,
+		instructions: instructions,
+		freeze:       freeze,
+		activity:     newActivity(),
+		artifacts:    artifacts,
+		startedAt:    time.Now(),
+
+		agentIdentities: agentIdentities,
+		proxyRoutes:     proxyRoutes,
+		chaos:           injector,
+		redactor:        redactor,
+		identities:      identities,
+
+		heartbeatSettings: newHeartbeatSettings(fullConfig.AgentManagement.HeartbeatInterval,
+			fullConfig.AgentManagement.OfflineTimeout, fullConfig.AgentManagement.MaxHeartbeatRate),
 	}, nil
 }
 
@@ -156,6 +320,10 @@ func loadPlugins(plugins *plugin.Registry, dir string, pluginConfig config.Plugi
 			if err := plugins.Register(rbacPlugin); err != nil {
 				return fmt.Errorf("register rbac plugin: %w", err)
 			}
+		case "webhook-notify":
+			if err := plugins.Register(webhook.New()); err != nil {
+				return fmt.Errorf("register webhook plugin: %w", err)
+			}
 		case "file-audit":
 			// For now, we'll log that this plugin is not implemented
 			log.Printf("File audit plugin not implemented in this build")
@@ -179,50 +347,38 @@ func (c *Core) Serve() error {
 		return fmt.Errorf("load cert: %w", err)
 	}
 
-	// Load CA certificate to verify client certificates
-	caCert, err := ioutil.ReadFile(c.config.CAPath)
-	if err != nil {
-		return fmt.Errorf("load CA cert: %w", err)
-	}
-
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return fmt.Errorf("parse CA cert")
-	}
-
 	tlsConfig := &tls.Config{
 		Certificates: []tls.Certificate{cert},
-		ClientCAs:    caCertPool,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
 		MinVersion:   tls.VersionTLS13,
 	}
+	// Verify client certificates
+	if err := transport.ServerClientAuth(tlsConfig, c.peerTLS(c.config.FullConfig.Server.TLS)); err != nil {
+		return err
+	}
 
-	creds := credentials.NewTLS(tlsConfig)
-
-	server := grpc.NewServer(
-		grpc.Creds(creds),
-		grpc.ChainUnaryInterceptor(
-			c.authInterceptor,
-			c.auditInterceptor,
-		),
-	)
-
-	// Register Core API services
-	agentv1.RegisterCoreServiceServer(server, c)
-	agentv1.RegisterStackServiceServer(server, c)
+	server := c.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
 
-	lis, err := net.Listen("tcp", c.config.ListenAddr)
+	listeners, err := c.openListeners()
 	if err != nil {
-		return fmt.Errorf("listen: %w", err)
+		return err
 	}
 
-	fmt.Printf("Core listening on %s\n", c.config.ListenAddr)
-
 	// Start background services
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go c.monitorAgents(ctx)
+	go c.runHeartbeatFlushes(ctx)
+	go c.runReports(ctx)
+	go c.collectArtifacts(ctx)
+
+	if c.enroller != nil {
+		enrollServer, err := c.serveEnrollment(cert)
+		if err != nil {
+			return err
+		}
+		defer enrollServer.GracefulStop()
+	}
 
 	// Graceful shutdown
 	go func() {
@@ -232,61 +388,140 @@ func (c *Core) Serve() error {
 		server.GracefulStop()
 	}()
 
-	return server.Serve(lis)
+	// Serve returns nil on every listener once the server stops
+	errs := make(chan error, len(listeners))
+	for _, lis := range listeners {
+		go func(lis net.Listener) {
+			errs <- server.Serve(lis)
+		}(lis)
+	}
+	return <-errs
+}
+
+// NewServer returns a gRPC server with the core's interceptors and services.
+// opts carry the transport credentials; Serve passes mTLS ones.
+func (c *Core) NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{requestid.UnaryServerInterceptor, c.errorInterceptor}
+	stream := []grpc.StreamServerInterceptor{requestid.StreamServerInterceptor, c.errorStreamInterceptor}
+	if c.chaos != nil {
+		// Injected faults still get error details, as real ones do
+		log.Printf("WARNING: chaos testing is enabled: %s", c.chaos)
+		unary = append(unary, c.chaos.UnaryServerInterceptor)
+		stream = append(stream, c.chaos.StreamServerInterceptor)
+	}
+	unary = append(unary,
+		c.profileInterceptor,
+		c.authInterceptor,
+		c.auditInterceptor,
+		c.obligationInterceptor,
+		c.freezeInterceptor,
+	)
+	stream = append(stream,
+		c.profileStreamInterceptor,
+		c.reflectionAuthInterceptor,
+		c.auditStreamInterceptor,
+		c.obligationStreamInterceptor,
+		c.freezeStreamInterceptor,
+	)
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		// Methods the core does not serve, such as the host services, go
+		// to agents as they are
+		grpc.UnknownServiceHandler(c.proxyStream),
+		grpc.ForceServerCodecV2(transport.FrameCodec()),
+	)
+	server := grpc.NewServer(opts...)
+
+	// Register Core API services
+	agentv1.RegisterCoreServiceServer(server, c)
+	agentv1.RegisterStackServiceServer(server, c)
+	agentv1.RegisterTransferServiceServer(server, c)
+	agentv1.RegisterArtifactServiceServer(server, c)
+	agentv1.RegisterAdminServiceServer(server, c)
+
+	if c.config.FullConfig != nil && c.config.FullConfig.Server.Reflection {
+		reflection.Register(server)
+	}
+	return server
 }
 
 // RegisterAgent handles agent registration
 func (c *Core) RegisterAgent(ctx context.Context, req *agentv1.RegisterRequest) (*agentv1.RegisterResponse, error) {
-	c.agents.mu.Lock()
-	defer c.agents.mu.Unlock()
-
-	// Use provided agent ID if available, otherwise generate new one
-	var agentID string
-	if req.AgentId != "" {
-		agentID = req.AgentId
-	} else {
+	// Use provided agent ID if available, then the one the certificate
+	// names, otherwise generate new one
+	agentID := req.AgentId
+	if agentID == "" {
+		agentID = certAgentID(ctx)
+	}
+	if agentID == "" {
 		agentID = generateAgentID(req.Hostname)
 	}
 
+	if err := c.verifyAgentIdentity(ctx, agentv1.CoreService_RegisterAgent_FullMethodName, agentID, true); err != nil {
+		return nil, err
+	}
+
 	// Create agent connection record without client initially
 	// The agent should provide its address or we need to discover it
 	// For now, we'll create a placeholder and try to connect later
 	agentConn := &AgentConnection{
-		ID:           agentID,
-		Hostname:     req.Hostname,
-		Labels:       req.Labels,
-		Capabilities: req.Capabilities,
-		LastSeen:     time.Now(),
-		Status:       AgentStatusOnline,
-		Stacks:       []string{}, // Initialize empty stack list
+		ID:             agentID,
+		Hostname:       req.Hostname,
+		Capabilities:   req.Capabilities,
+		LastSeen:       time.Now(),
+		Status:         AgentStatusOnline,
+		Stacks:         []string{}, // Initialize empty stack list
+		ReportedLabels: req.Labels,
+		DialOutOnly:    req.DialOutOnly,
+		Plugins:        req.Plugins,
+		Unavailable:    unavailablePlugins(req.PluginAvailability),
 	}
-
-	c.agents.agents[agentID] = agentConn
+	agentConn.Certificate, _ = peerCertificate(ctx)
+
+	// Operator label changes and maintenance survive re-registration; the
+	// agent's reported labels are replaced so removed config labels go away
+	c.agents.replace(agentID, func(existing *AgentConnection) *AgentConnection {
+		if existing != nil {
+			existing.mu.RLock()
+			agentConn.OperatorLabels = existing.OperatorLabels
+			agentConn.RemovedLabels = existing.RemovedLabels
+			agentConn.Maintenance = existing.Maintenance
+			existing.mu.RUnlock()
+		}
+		agentConn.Labels = agentConn.effectiveLabels()
+		return agentConn
+	})
+	c.breakers.reset(agentID)
 
 	c.audit.LogAgentRegistration(ctx, agentID, req.Hostname)
 
 	return &agentv1.RegisterResponse{
 		AgentId:           agentID,
-		HeartbeatInterval: durationpb.New(30 * time.Second),
+		HeartbeatInterval: durationpb.New(c.heartbeatSettings.heartbeatInterval(c.agents.len())),
 	}, nil
 }
 
 // ListAgents returns all registered agents
 func (c *Core) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest) (*agentv1.ListAgentsResponse, error) {
-	c.agents.mu.RLock()
-	defer c.agents.mu.RUnlock()
-
-	agents := make([]*agentv1.Agent, 0, len(c.agents.agents))
-
-	for _, agent := range c.agents.agents {
-		agents = append(agents, &agentv1.Agent{
-			Id:           agent.ID,
-			Hostname:     agent.Hostname,
-			Status:       string(agent.Status),
-			Labels:       agent.Labels,
-			Capabilities: agent.Capabilities,
-			LastSeen:     timestamppb.New(agent.LastSeen),
-		})
+	var group *AgentGroup
+	if req.Group != "" {
+		var ok bool
+		if group, ok = c.groups.get(req.Group); !ok {
+			return nil, status.Errorf(codes.NotFound, "group not found: %s", req.Group)
+		}
+	}
+
+	all := c.agents.all()
+	agents := make([]*agentv1.Agent, 0, len(all))
+
+	for _, agent := range all {
+		agent.mu.RLock()
+		if matchLabels(agent.Labels, req.Labels) && (group == nil || group.contains(agent)) {
+			agents = append(agents, c.toProtoAgent(agent))
+		}
+		agent.mu.RUnlock()
 	}
 
 	return &agentv1.ListAgentsResponse{
@@ -294,29 +529,26 @@ func (c *Core) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest) (
 	}, nil
 }
 
-func (c *Core) Heartbeat(ctx context.Context, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
-	c.agents.mu.Lock()
-	defer c.agents.mu.Unlock()
-
-	agentID := req.AgentId
-
-	agent, exists := c.agents.agents[agentID]
-	if !exists {
-		return nil, fmt.Errorf("agent not found: %s", agentID)
+// stackTarget returns the agent a stack change goes to, and whether the
+// change is queued as an instruction instead of sent now. Dial-out-only
+// agents always take changes that way; offline agents do when the caller
+// asked to queue.
+func (c *Core) stackTarget(agentID string, queue bool) (*AgentConnection, bool, error) {
+	agent, exists := c.agents.get(agentID)
+	if exists && agent.DialOutOnly {
+		return agent, true, nil
 	}
 
-	// Update last seen time and status
-	agent.LastSeen = time.Now()
-
-	// Only update status to online if it was offline, to avoid unnecessary log messages
-	if agent.Status == AgentStatusOffline {
-		fmt.Printf("Agent %s is back online via heartbeat\n", agentID)
+	conn, err := c.getAgentConnection(agentID)
+	if err != nil && exists && queue {
+		agent.mu.RLock()
+		offline := agent.Status == AgentStatusOffline
+		agent.mu.RUnlock()
+		if offline {
+			return agent, true, nil
+		}
 	}
-	agent.Status = AgentStatusOnline
-
-	return &agentv1.HeartbeatResponse{
-		Status: "healthy",
-	}, nil
+	return conn, false, err
 }
 
 // ProxyStackOperation forwards stack operations to the target agent
@@ -326,11 +558,33 @@ func (c *Core) ProxyStackOperation(ctx context.Context, agentID string, req *age
 		return "", err
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_ApplyStack_FullMethodName); err != nil {
+		return "", err
+	}
+
+	if err := c.authorizeNamespaced(ctx, conn, "write", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return "", err
+	}
+
+	if err := c.requireDeployAllowed(ctx, conn, normalizeNamespace(req.Namespace), "stack:"+req.StackName, req.Emergency); err != nil {
+		return "", err
+	}
+
+	releaseQuota, err := c.requireQuota(ctx, conn, req)
+	if err != nil {
+		return "", err
+	}
+	defer releaseQuota()
+
+	if err := c.requireApproval(ctx, conn, "write", "stack:"+req.StackName, applyDigest(req), req.Group, req.ApprovalId, req.IdempotencyKey); err != nil {
+		return "", err
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
 	// Forward the operation
-	stream, err := stackClient.ApplyStack(ctx, req)
+	stream, err := stackClient.ApplyStack(c.forRequester(ctx), req)
 	if err != nil {
 		return "", fmt.Errorf("forward to agent: %w", err)
 	}
@@ -340,28 +594,43 @@ func (c *Core) ProxyStackOperation(ctx context.Context, agentID string, req *age
 	if err != nil {
 		return "", err
 	}
+	c.recordAgentStack(agentID, req.StackName)
 
 	return event.OperationId, nil
 }
 
 func (c *Core) getAgentConnection(agentID string) (*AgentConnection, error) {
-	c.agents.mu.Lock() // Need to write lock since we might update the connection
-	defer c.agents.mu.Unlock()
-
-	agentConn, exists := c.agents.agents[agentID]
+	agentConn, exists := c.agents.get(agentID)
 	if !exists {
-		return nil, fmt.Errorf("agent not found: %s", agentID)
+		return nil, errAgentNotFound(agentID)
 	}
 
+	if agentConn.DialOutOnly {
+		return nil, transport.Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+			Code:    agentv1.ErrorCode_ERROR_CODE_AGENT_DIAL_OUT_ONLY,
+			AgentId: agentID,
+			Hint:    "queue the change with --queue; the agent picks it up on its next heartbeat",
+		}, "agent %s is dial-out only; it takes stack changes through heartbeat instructions", agentID)
+	}
+
+	agentConn.mu.Lock() // Need to write lock since we might update the connection
+	defer agentConn.mu.Unlock()
+
 	// If agent is offline, try to update its status by checking if it's recently sent a heartbeat
 	if agentConn.Status == AgentStatusOffline {
-		// If agent has sent a heartbeat in the last 30 seconds, consider it online again
-		if time.Since(agentConn.LastSeen) <= 30*time.Second {
+		// If agent has sent a heartbeat in the last 30 seconds, consider it
+		// online again; the heartbeat may not be flushed yet
+		lastSeen := agentConn.LastSeen
+		if pending, ok := c.heartbeats.pending(agentID); ok {
+			lastSeen = pending
+		}
+		if time.Since(lastSeen) <= 30*time.Second {
 			agentConn.Status = AgentStatusOnline
+			c.agents.touch()
 			fmt.Printf("Agent %s is back online\n", agentID)
 		} else {
 			// Agent is still offline, return error
-			return nil, fmt.Errorf("agent offline: %s", agentID)
+			return nil, errAgentOffline(agentID)
 		}
 	}
 
@@ -386,36 +655,26 @@ func (c *Core) getAgentConnection(agentID string) (*AgentConnection, error) {
 
 		agentAddr := fmt.Sprintf("%s:8444", hostname) // Default agent port
 
-		// Load certificates for connecting to agent (mTLS)
-		cert, err := tls.LoadX509KeyPair(c.config.CertPath, c.config.KeyPath)
+		// Use mTLS for connection to agent
+		tlsConfig, err := c.agentTLS.ClientConfig(c.peerTLS(c.config.FullConfig.AgentTLS), "mandau-agent")
 		if err != nil {
-			return nil, fmt.Errorf("load core cert for agent connection: %w", err)
+			return nil, fmt.Errorf("agent connection: %w", err)
 		}
 
-		// Load CA certificate to verify agent certificates
-		caCert, err := ioutil.ReadFile(c.config.CAPath)
-		if err != nil {
-			return nil, fmt.Errorf("load CA cert for agent connection: %w", err)
-		}
+		creds := credentials.NewTLS(tlsConfig)
 
-		caCertPool := x509.NewCertPool()
-		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return nil, fmt.Errorf("parse CA cert for agent connection")
+		var compression string
+		if c.config.FullConfig != nil {
+			compression = c.config.FullConfig.AgentManagement.Compression
 		}
-
-		// Use mTLS for connection to agent
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			RootCAs:      caCertPool,
-			ServerName:   "mandau-agent", // Verify agent certificate against this name
-			MinVersion:   tls.VersionTLS13,
+		compress, err := transport.CompressionOption(compression)
+		if err != nil {
+			return nil, err
 		}
 
-		creds := credentials.NewTLS(tlsConfig)
-
-		// Create gRPC connection to agent with retry options
-		conn, err := grpc.Dial(agentAddr,
+		dialOpts := []grpc.DialOption{
 			grpc.WithTransportCredentials(creds),
+			compress,
 			grpc.WithConnectParams(grpc.ConnectParams{
 				Backoff: backoff.Config{
 					BaseDelay:  1.0 * time.Second,
@@ -431,7 +690,17 @@ func (c *Core) getAgentConnection(agentID string) (*AgentConnection, error) {
 				Timeout:             5 * time.Second,
 				PermitWithoutStream: true,
 			}),
-		)
+			// Calls made for a request carry its ID to the agent
+			grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor),
+			grpc.WithChainStreamInterceptor(requestid.StreamClientInterceptor),
+		}
+		if c.config.AgentDialer != nil {
+			dialOpts = append(dialOpts, grpc.WithContextDialer(c.config.AgentDialer))
+		}
+		dialOpts = append(dialOpts, c.breakers.dialOptions(agentID)...)
+
+		// Create gRPC connection to agent with retry options
+		conn, err := grpc.Dial(agentAddr, dialOpts...)
 		if err != nil {
 			return nil, fmt.Errorf("dial agent %s at %s: %w", agentID, agentAddr, err)
 		}
@@ -443,6 +712,18 @@ func (c *Core) getAgentConnection(agentID string) (*AgentConnection, error) {
 	return agentConn, nil
 }
 
+// peerTLS fills in the core's certificate and CA for settings that leave
+// them out
+func (c *Core) peerTLS(tlsCfg config.TLSConfig) config.TLSConfig {
+	if tlsCfg.CertPath == "" {
+		tlsCfg.CertPath, tlsCfg.KeyPath = c.config.CertPath, c.config.KeyPath
+	}
+	if tlsCfg.CAPath == "" && len(tlsCfg.CAPaths) == 0 {
+		tlsCfg.CAPath = c.config.CAPath
+	}
+	return tlsCfg
+}
+
 // monitorAgents checks agent health periodically and attempts reconnection
 func (c *Core) monitorAgents(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
@@ -453,32 +734,52 @@ func (c *Core) monitorAgents(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			c.agents.mu.Lock()
-
-			for id, agent := range c.agents.agents {
-				elapsed := time.Since(agent.LastSeen)
-
-				// Mark as offline if no heartbeat for more than 90 seconds
-				if elapsed > 90*time.Second {
-					if agent.Status != AgentStatusOffline {
-						agent.Status = AgentStatusOffline
-						c.audit.LogAgentOffline(ctx, id)
-						fmt.Printf("Agent %s marked as offline (last seen: %v ago)\n", id, elapsed)
-					}
-				}
+			// Heartbeats not yet flushed must not leave agents offline
+			c.flushHeartbeats(ctx)
 
-				// Attempt to clean up stale connections for offline agents
-				if agent.Status == AgentStatusOffline && agent.Client != nil {
-					// Close the stale connection
-					agent.Client.Close()
-					agent.Client = nil
-					fmt.Printf("Closed stale connection for offline agent %s\n", id)
-				}
+			now := time.Now()
+			c.expireInstructions(ctx, now)
+			offlineAfter := c.heartbeatSettings.offlineAfter(c.agents.len())
+
+			for _, agent := range c.agents.all() {
+				c.checkAgent(ctx, agent, now, offlineAfter)
 			}
+		}
+	}
+}
+
+// checkAgent expires the agent's maintenance window, marks it offline once
+// it missed its heartbeats and closes the connection to it while offline
+func (c *Core) checkAgent(ctx context.Context, agent *AgentConnection, now time.Time, offlineAfter time.Duration) {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
 
-			c.agents.mu.Unlock()
+	id := agent.ID
+	if agent.expireMaintenance(now) {
+		c.agents.touch()
+	}
+	elapsed := time.Since(agent.LastSeen)
+
+	// Mark as offline once it missed its heartbeats
+	if elapsed > offlineAfter {
+		if agent.Status != AgentStatusOffline {
+			agent.Status = AgentStatusOffline
+			c.agents.touch()
+			// Planned downtime is expected, don't alert on it
+			if !agent.inMaintenance(now) {
+				c.audit.LogAgentOffline(ctx, id)
+				fmt.Printf("Agent %s marked as offline (last seen: %v ago)\n", id, elapsed)
+			}
 		}
 	}
+
+	// Attempt to clean up stale connections for offline agents
+	if agent.Status == AgentStatusOffline && agent.Client != nil {
+		// Close the stale connection
+		agent.Client.Close()
+		agent.Client = nil
+		fmt.Printf("Closed stale connection for offline agent %s\n", id)
+	}
 }
 
 func generateAgentID(hostname string) string {
@@ -487,23 +788,84 @@ func generateAgentID(hostname string) string {
 }
 
 func (c *Core) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	identity, err := extractIdentity(ctx)
+	// Agents are not users; profileInterceptor has already checked them
+	if agentMethods[info.FullMethod] != "" {
+		return handler(ctx, req)
+	}
+
+	ctx, err := c.authenticate(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// reflectionAuthInterceptor authenticates server reflection calls as
+// authInterceptor does unary ones; the core's own streaming handlers
+// authenticate themselves, but the reflection service does not
+func (c *Core) reflectionAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+		return handler(srv, ss)
+	}
+
+	ctx, err := c.authenticate(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &userStream{ServerStream: ss, ctx: ctx})
+}
+
+// userStream is a stream whose context names the authenticated user
+type userStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *userStream) Context() context.Context { return s.ctx }
+
+// authenticate establishes the caller of method from its certificate and
+// the auth plugin, and returns ctx carrying the identity
+func (c *Core) authenticate(ctx context.Context, method string, req interface{}) (context.Context, error) {
+	identity, err := extractIdentity(ctx, c.identities)
 	if err != nil {
-		return nil, fmt.Errorf("auth failed: %w", err)
+		return nil, c.authFailed(ctx, nil, method, req, err)
 	}
 
 	if auth := c.plugins.Auth(); auth != nil {
-		identity, err = auth.Authenticate(ctx, &plugin.AuthRequest{
+		authenticated, err := auth.Authenticate(ctx, &plugin.AuthRequest{
 			Identity: identity,
-			Method:   info.FullMethod,
+			Method:   method,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("auth failed: %w", err)
+			return nil, c.authFailed(ctx, identity, method, req, err)
 		}
+		identity = authenticated
 	}
 
-	ctx = plugin.WithIdentity(ctx, identity)
-	return handler(ctx, req)
+	identity = c.elevate(ctx, identity)
+	effective, err := c.impersonate(ctx, method, identity)
+	if err != nil {
+		return nil, c.impersonationFailed(ctx, identity, method, req, err)
+	}
+	return plugin.WithIdentity(ctx, effective), nil
+}
+
+// authFailed audits a rejected call, so anomaly rules see failed logins,
+// and returns the error as Unauthenticated
+func (c *Core) authFailed(ctx context.Context, identity *plugin.Identity, method string, req interface{}, err error) error {
+	st := status.Errorf(codes.Unauthenticated, "auth failed: %v", err)
+
+	metadata := audit.Metadata(req)
+	metadata["auth_error"] = err.Error()
+	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+		Timestamp: time.Now(),
+		Identity:  identity,
+		Action:    method,
+		Result:    resultString(st),
+		Metadata:  metadata,
+	})
+
+	return st
 }
 
 func (c *Core) auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -512,19 +874,58 @@ func (c *Core) auditInterceptor(ctx context.Context, req interface{}, info *grpc
 
 	resp, err := handler(ctx, req)
 
-	c.plugins.AuditAll(ctx, &plugin.AuditEntry{
+	entry := &plugin.AuditEntry{
 		Timestamp: start,
 		Identity:  identity,
 		Action:    info.FullMethod,
 		Result:    resultString(err),
 		Duration:  time.Since(start),
-	})
+		Metadata:  audit.Metadata(req),
+	}
+	if identity != nil && identity.Attributes[breakGlassAttribute] != "" {
+		entry.Metadata[breakGlassAttribute] = identity.Attributes[breakGlassAttribute]
+	}
+	if reason := obligation.Reason(ctx); reason != "" {
+		entry.Metadata["reason"] = reason
+	}
+
+	c.plugins.AuditAll(ctx, entry)
 
 	return resp, err
 }
 
-// extractIdentity extracts the client identity from the gRPC context
-func extractIdentity(ctx context.Context) (*plugin.Identity, error) {
+// auditStreamInterceptor records duration and traffic volume of streaming
+// RPCs such as log tails and stack applies
+func (c *Core) auditStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	identity, _ := c.callerIdentity(ss.Context())
+
+	recorder := audit.NewStream(ss)
+	err := handler(srv, recorder)
+
+	entry := &plugin.AuditEntry{
+		Timestamp: start,
+		Identity:  identity,
+		Action:    info.FullMethod,
+		Result:    resultString(err),
+		Duration:  time.Since(start),
+		Metadata:  recorder.Metadata(),
+	}
+	if identity != nil && identity.Attributes[breakGlassAttribute] != "" {
+		entry.Metadata[breakGlassAttribute] = identity.Attributes[breakGlassAttribute]
+	}
+	if reason := obligation.Reason(ss.Context()); reason != "" {
+		entry.Metadata["reason"] = reason
+	}
+
+	c.plugins.AuditAll(ss.Context(), entry)
+
+	return err
+}
+
+// extractIdentity extracts the client identity from the gRPC context, as
+// identities maps the client certificate
+func extractIdentity(ctx context.Context, identities *certidentity.Mapper) (*plugin.Identity, error) {
 	p, ok := peer.FromContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("no peer found")
@@ -539,18 +940,36 @@ func extractIdentity(ctx context.Context) (*plugin.Identity, error) {
 		return nil, fmt.Errorf("could not verify peer certificate")
 	}
 
-	// Use the subject of the client certificate as identity
-	subject := tlsInfo.State.VerifiedChains[0][0].Subject
-	return &plugin.Identity{
-		UserID: subject.CommonName,
-	}, nil
+	identity := identities.Identity(tlsInfo.State.VerifiedChains[0][0])
+	if identity.UserID == "" {
+		return nil, fmt.Errorf("peer certificate names no user")
+	}
+	return identity, nil
 }
 
-func resultString(err error) string {
+// callerIdentity returns the identity set by the auth interceptor, falling
+// back to the peer certificate for streaming calls which bypass it
+func (c *Core) callerIdentity(ctx context.Context) (*plugin.Identity, error) {
+	if identity := plugin.IdentityFromContext(ctx); identity != nil {
+		return identity, nil
+	}
+
+	identity, err := extractIdentity(ctx, c.identities)
 	if err != nil {
+		return nil, err
+	}
+	return c.impersonate(ctx, "", c.elevate(ctx, identity))
+}
+
+func resultString(err error) string {
+	switch status.Code(err) {
+	case codes.OK:
+		return "success"
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return "denied"
+	default:
 		return "error"
 	}
-	return "success"
 }
 
 // =============================================================================
@@ -558,13 +977,83 @@ func resultString(err error) string {
 // =============================================================================
 
 func (c *Core) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
-	agentID := req.AgentId
+	if req.AgentId == "" {
+		return c.listFleetStacks(ctx, req)
+	}
 
-	conn, err := c.getAgentConnection(agentID)
+	conn, err := c.getAgentConnection(req.AgentId)
 	if err != nil {
 		return nil, fmt.Errorf("get agent connection: %w", err)
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_ListStacks_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	// Listing every namespace needs a cluster-wide grant
+	if err := c.authorizeNamespaced(ctx, conn, "read", req.Namespace, "stack:*"); err != nil {
+		return nil, err
+	}
+
+	return c.listAgentStacks(ctx, conn, req)
+}
+
+// listFleetStacks lists stacks on every online agent the caller may read,
+// querying agents in parallel. Agents that do not answer are reported in
+// the response instead of failing the listing.
+func (c *Core) listFleetStacks(ctx context.Context, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
+	resp := &agentv1.ListStacksResponse{AgentErrors: make(map[string]string)}
+
+	conns, failed := c.onlineAgents()
+	readable := make([]*AgentConnection, 0, len(conns))
+	for _, conn := range conns {
+		if requireCapability(conn, agentv1.StackService_ListStacks_FullMethodName) != nil ||
+			c.authorizeNamespaced(ctx, conn, "read", req.Namespace, "stack:*") != nil {
+			continue
+		}
+		readable = append(readable, conn)
+	}
+	for id, err := range failed {
+		if c.canReadAgent(ctx, id, req.Namespace) {
+			resp.AgentErrors[id] = err.Error()
+		}
+	}
+
+	results := fanOut(ctx, c.fanOut, readable, func(ctx context.Context, conn *AgentConnection) (*agentv1.ListStacksResponse, error) {
+		agentReq := proto.Clone(req).(*agentv1.ListStacksRequest)
+		agentReq.AgentId = conn.ID
+		return c.listAgentStacks(ctx, conn, agentReq)
+	})
+	for _, r := range results {
+		if r.err != nil {
+			resp.AgentErrors[r.conn.ID] = r.err.Error()
+			continue
+		}
+		for _, stack := range r.value.Stacks {
+			stack.AgentId = r.conn.ID
+			resp.Stacks = append(resp.Stacks, stack)
+		}
+	}
+
+	return resp, nil
+}
+
+// canReadAgent reports whether the caller may list stacks on an agent that
+// could not be dialed, so its failure is only shown to those who could see it
+func (c *Core) canReadAgent(ctx context.Context, agentID, namespace string) bool {
+	conn, ok := c.agents.get(agentID)
+	return ok && c.authorizeNamespaced(ctx, conn, "read", namespace, "stack:*") == nil
+}
+
+// listAgentStacks forwards an authorized listing to one agent, through the
+// read cache
+func (c *Core) listAgentStacks(ctx context.Context, conn *AgentConnection, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
+	agentID := conn.ID
+
+	if cached, ok := c.readCache.get(agentID, agentv1.StackService_ListStacks_FullMethodName, req, time.Now()); ok {
+		return cached.(*agentv1.ListStacksResponse), nil
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
@@ -573,20 +1062,24 @@ func (c *Core) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest) (
 	if err != nil {
 		return nil, fmt.Errorf("forward to agent: %w", err)
 	}
-
-	// Update the agent's stack list in our registry
-	stackIDs := make([]string, len(resp.Stacks))
-	for i, stack := range resp.Stacks {
-		stackIDs[i] = stack.Id
+	c.readCache.put(agentID, agentv1.StackService_ListStacks_FullMethodName, req, resp, time.Now())
+
+	// Update the agent's stack list in our registry; filtered listings
+	// only see a subset, so they must not replace it
+	if req.Namespace == "" && len(req.Labels) == 0 {
+		stackIDs := make([]string, len(resp.Stacks))
+		for i, stack := range resp.Stacks {
+			stackIDs[i] = stack.Id
+		}
+		c.updateAgentStacks(agentID, stackIDs)
 	}
-	c.updateAgentStacks(agentID, stackIDs)
 
 	return resp, nil
 }
 
 func (c *Core) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*agentv1.GetStackResponse, error) {
 	// Find which agent has this stack
-	agentID, err := c.findAgentWithStack(req.StackId)
+	agentID, err := c.findAgentWithStack(ctx, req.StackId, req.Namespace)
 	if err != nil {
 		return nil, fmt.Errorf("find agent with stack: %w", err)
 	}
@@ -596,6 +1089,18 @@ func (c *Core) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*age
 		return nil, fmt.Errorf("get agent connection: %w", err)
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_GetStack_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	if err := c.authorizeNamespaced(ctx, conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackId); err != nil {
+		return nil, err
+	}
+
+	if cached, ok := c.readCache.get(agentID, agentv1.StackService_GetStack_FullMethodName, req, time.Now()); ok {
+		return cached.(*agentv1.GetStackResponse), nil
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
@@ -604,23 +1109,69 @@ func (c *Core) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*age
 	if err != nil {
 		return nil, fmt.Errorf("forward to agent: %w", err)
 	}
+	c.readCache.put(agentID, agentv1.StackService_GetStack_FullMethodName, req, resp, time.Now())
 
 	return resp, nil
 }
 
-func (c *Core) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackService_ApplyStackServer) error {
+func (c *Core) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackService_ApplyStackServer) (err error) {
 	agentID := req.AgentId
 
-	conn, err := c.getAgentConnection(agentID)
+	conn, queued, err := c.stackTarget(agentID, req.Queue)
 	if err != nil {
 		return fmt.Errorf("get agent connection: %w", err)
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_ApplyStack_FullMethodName); err != nil {
+		return err
+	}
+
+	if err := c.authorizeNamespaced(stream.Context(), conn, "write", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return err
+	}
+
+	// Hooks run arbitrary scripts on the agent host, so setting them needs
+	// a permission of its own
+	if hooks := req.Hooks; hooks != nil && (hooks.PreApply != "" || hooks.PostApply != "") {
+		if err := c.authorizeNamespaced(stream.Context(), conn, "hooks", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+			return err
+		}
+	}
+
+	if err := c.requireDeployAllowed(stream.Context(), conn, normalizeNamespace(req.Namespace), "stack:"+req.StackName, req.Emergency); err != nil {
+		return err
+	}
+
+	releaseQuota, err := c.requireQuota(stream.Context(), conn, req)
+	if err != nil {
+		return err
+	}
+	defer releaseQuota()
+
+	if err := c.requireApproval(stream.Context(), conn, "write", "stack:"+req.StackName, applyDigest(req), req.Group, req.ApprovalId, req.IdempotencyKey); err != nil {
+		return err
+	}
+
+	if queued {
+		return c.queueStackInstruction(stream.Context(), conn, &agentv1.AgentInstruction{
+			Kind: &agentv1.AgentInstruction_ApplyStack{ApplyStack: req},
+		}, req.QueueTtl, stream.Send)
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
+	// Cached reads of the agent go stale now and again once the
+	// operation ends, whatever its outcome
+	c.readCache.invalidateAgent(agentID)
+	defer c.readCache.invalidateAgent(agentID)
+
+	op := c.activity.begin(agentID, "apply_stack", req.StackName, normalizeNamespace(req.Namespace), c.requesterID(stream.Context()),
+		requestid.From(stream.Context()))
+	defer func() { op.end(err) }()
+
 	// Forward the request to the agent
-	agentStream, err := stackClient.ApplyStack(stream.Context(), req)
+	agentStream, err := stackClient.ApplyStack(c.forRequester(stream.Context()), req)
 	if err != nil {
 		return fmt.Errorf("forward to agent: %w", err)
 	}
@@ -628,9 +1179,17 @@ func (c *Core) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackSe
 	// Stream responses back to client
 	for {
 		event, err := agentStream.Recv()
+		if err == io.EOF {
+			c.recordAgentStack(agentID, req.StackName)
+			return nil
+		}
 		if err != nil {
 			return err
 		}
+		op.observe(event)
+
+		// The agent has taken the stack over; later applies see it
+		releaseQuota()
 
 		if err := stream.Send(event); err != nil {
 			return err
@@ -638,23 +1197,56 @@ func (c *Core) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackSe
 	}
 }
 
-func (c *Core) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.StackService_RemoveStackServer) error {
-	// Find which agent has this stack
-	agentID, err := c.findAgentWithStack(req.StackId)
-	if err != nil {
-		return fmt.Errorf("find agent with stack: %w", err)
+func (c *Core) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.StackService_RemoveStackServer) (err error) {
+	// Find which agent has this stack unless the caller named it
+	agentID := req.AgentId
+	if agentID == "" {
+		if agentID, err = c.findAgentWithStack(stream.Context(), req.StackId, req.Namespace); err != nil {
+			return fmt.Errorf("find agent with stack: %w", err)
+		}
 	}
 
-	conn, err := c.getAgentConnection(agentID)
+	conn, queued, err := c.stackTarget(agentID, req.Queue)
 	if err != nil {
 		return fmt.Errorf("get agent connection: %w", err)
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_RemoveStack_FullMethodName); err != nil {
+		return err
+	}
+
+	if err := c.authorizeNamespaced(stream.Context(), conn, "delete", normalizeNamespace(req.Namespace), "stack:"+req.StackId); err != nil {
+		return err
+	}
+
+	if err := c.requireDeployAllowed(stream.Context(), conn, normalizeNamespace(req.Namespace), "stack:"+req.StackId, req.Emergency); err != nil {
+		return err
+	}
+
+	if err := c.requireApproval(stream.Context(), conn, "delete", "stack:"+req.StackId, removeDigest(req), req.Group, req.ApprovalId, req.IdempotencyKey); err != nil {
+		return err
+	}
+
+	if queued {
+		return c.queueStackInstruction(stream.Context(), conn, &agentv1.AgentInstruction{
+			Kind: &agentv1.AgentInstruction_RemoveStack{RemoveStack: req},
+		}, req.QueueTtl, stream.Send)
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
+	// Cached reads of the agent go stale now and again once the
+	// operation ends, whatever its outcome
+	c.readCache.invalidateAgent(agentID)
+	defer c.readCache.invalidateAgent(agentID)
+
+	op := c.activity.begin(agentID, "remove_stack", req.StackId, normalizeNamespace(req.Namespace), c.requesterID(stream.Context()),
+		requestid.From(stream.Context()))
+	defer func() { op.end(err) }()
+
 	// Forward the request to the agent
-	agentStream, err := stackClient.RemoveStack(stream.Context(), req)
+	agentStream, err := stackClient.RemoveStack(c.forRequester(stream.Context()), req)
 	if err != nil {
 		return fmt.Errorf("forward to agent: %w", err)
 	}
@@ -662,9 +1254,14 @@ func (c *Core) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.Stack
 	// Stream responses back to client
 	for {
 		event, err := agentStream.Recv()
+		if err == io.EOF {
+			c.forgetAgentStack(agentID, req.StackId)
+			return nil
+		}
 		if err != nil {
 			return err
 		}
+		op.observe(event)
 
 		if err := stream.Send(event); err != nil {
 			return err
@@ -672,37 +1269,228 @@ func (c *Core) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.Stack
 	}
 }
 
+// DiffStack compares a compose file with a stack. The request has no agent
+// ID: the agent header names the agent, or else the agent already running
+// the stack is used.
 func (c *Core) DiffStack(ctx context.Context, req *agentv1.DiffStackRequest) (*agentv1.DiffStackResponse, error) {
-	// Since DiffStack doesn't have an agent ID in the request, we need to determine it
-	// For now, we'll return an error indicating this limitation
-	return nil, fmt.Errorf("DiffStack not implemented in core proxy - agent ID required in request")
+	agentID := agentHeader(ctx)
+	if agentID == "" {
+		var err error
+		if agentID, err = c.findAgentWithStack(ctx, req.StackName, req.Namespace); err != nil {
+			return nil, status.Errorf(codes.NotFound, "find agent with stack: %v (name the agent with the %s header)", err, transport.AgentHeader)
+		}
+	}
+
+	conn, err := c.getAgentConnection(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := requireCapability(conn, agentv1.StackService_DiffStack_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	if err := c.authorizeNamespaced(ctx, conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return nil, err
+	}
+
+	resp, err := agentv1.NewStackServiceClient(conn.Client).DiffStack(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	return resp, nil
 }
 
-// findAgentWithStack finds which agent has a specific stack
-func (c *Core) findAgentWithStack(stackID string) (string, error) {
-	c.agents.mu.RLock()
-	defer c.agents.mu.RUnlock()
+// ExportStack returns the stored files and state of a stack. Secret values
+// are masked by the agent unless the caller asks for them and may also
+// write the stack.
+func (c *Core) ExportStack(ctx context.Context, req *agentv1.ExportStackRequest) (*agentv1.StackExport, error) {
+	agentID := req.AgentId
+	if agentID == "" {
+		var err error
+		if agentID, err = c.findAgentWithStack(ctx, req.StackName, req.Namespace); err != nil {
+			return nil, status.Errorf(codes.NotFound, "find agent with stack: %v", err)
+		}
+	}
 
-	for agentID, agent := range c.agents.agents {
-		for _, stack := range agent.Stacks {
-			if stack == stackID {
-				return agentID, nil
-			}
+	conn, err := c.getAgentConnection(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := requireCapability(conn, agentv1.StackService_ExportStack_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	namespace, resource := normalizeNamespace(req.Namespace), "stack:"+req.StackName
+	if err := c.authorizeNamespaced(ctx, conn, "read", namespace, resource); err != nil {
+		return nil, err
+	}
+	if req.RevealSecrets {
+		if err := c.authorizeNamespaced(ctx, conn, "write", namespace, resource); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := agentv1.NewStackServiceClient(conn.Client).ExportStack(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	resp.AgentId = agentID
+	return resp, nil
+}
+
+// GetStackEvents returns a stack's timeline from its agent. Timelines
+// outlive removed stacks, whose agent must be named.
+func (c *Core) GetStackEvents(ctx context.Context, req *agentv1.GetStackEventsRequest) (*agentv1.GetStackEventsResponse, error) {
+	agentID := req.AgentId
+	if agentID == "" {
+		var err error
+		if agentID, err = c.findAgentWithStack(ctx, req.StackName, req.Namespace); err != nil {
+			return nil, status.Errorf(codes.NotFound, "find agent with stack: %v", err)
+		}
+	}
+
+	conn, err := c.getAgentConnection(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := requireCapability(conn, agentv1.StackService_GetStackEvents_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	if err := c.authorizeNamespaced(ctx, conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return nil, err
+	}
+
+	resp, err := agentv1.NewStackServiceClient(conn.Client).GetStackEvents(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	resp.AgentId = agentID
+	return resp, nil
+}
+
+// CollectStackGarbage reports what stacks left behind on an agent and, with
+// apply, removes it. It covers every namespace on the agent, so it needs
+// read, or delete to apply, on all of the agent's stacks.
+func (c *Core) CollectStackGarbage(ctx context.Context, req *agentv1.CollectStackGarbageRequest) (*agentv1.CollectStackGarbageResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	// Reports are reads; collecting deletes stacks, which a freeze forbids
+	if req.Apply {
+		if err := c.requireUnfrozen(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := requireCapability(conn, agentv1.StackService_CollectStackGarbage_FullMethodName); err != nil {
+		return nil, err
+	}
+
+	action := "read"
+	if req.Apply {
+		action = "delete"
+	}
+	if err := c.authorizeAgent(ctx, conn, action, "stack:*"); err != nil {
+		return nil, err
+	}
+
+	resp, err := agentv1.NewStackServiceClient(conn.Client).CollectStackGarbage(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("forward to agent: %w", err)
+	}
+	return resp, nil
+}
+
+// findAgentWithStack finds which agent has a specific stack. The stack cache
+// is only complete after an unfiltered listing, so on a miss every online
+// agent is asked directly, within namespace.
+func (c *Core) findAgentWithStack(ctx context.Context, stackID, namespace string) (string, error) {
+	var candidates []string
+	for _, agent := range c.agents.all() {
+		agent.mu.RLock()
+		has, online := containsString(agent.Stacks, stackID), agent.Status == AgentStatusOnline
+		agent.mu.RUnlock()
+		if has {
+			return agent.ID, nil
+		}
+		if online {
+			candidates = append(candidates, agent.ID)
+		}
+	}
+
+	conns := make([]*AgentConnection, 0, len(candidates))
+	for _, agentID := range candidates {
+		conn, err := c.getAgentConnection(agentID)
+		if err != nil || requireCapability(conn, agentv1.StackService_GetStack_FullMethodName) != nil {
+			continue
+		}
+		conns = append(conns, conn)
+	}
+
+	results := fanOut(ctx, c.fanOut, conns, func(ctx context.Context, conn *AgentConnection) (*agentv1.GetStackResponse, error) {
+		return agentv1.NewStackServiceClient(conn.Client).GetStack(ctx, &agentv1.GetStackRequest{
+			StackId:   stackID,
+			Namespace: namespace,
+		})
+	})
+	for _, r := range results {
+		if r.err == nil {
+			c.recordAgentStack(r.conn.ID, stackID)
+			return r.conn.ID, nil
 		}
 	}
 
 	return "", fmt.Errorf("stack not found on any agent: %s", stackID)
 }
 
+// recordAgentStack adds stack to the cached stack list of an agent
+func (c *Core) recordAgentStack(agentID, stack string) {
+	agent, exists := c.agents.lock(agentID)
+	if !exists {
+		return
+	}
+	defer agent.mu.Unlock()
+
+	if containsString(agent.Stacks, stack) {
+		return
+	}
+	agent.Stacks = append(append([]string{}, agent.Stacks...), stack)
+}
+
+// forgetAgentStack removes stack from the cached stack list of an agent
+func (c *Core) forgetAgentStack(agentID, stack string) {
+	agent, exists := c.agents.lock(agentID)
+	if !exists {
+		return
+	}
+	defer agent.mu.Unlock()
+
+	stacks := make([]string, 0, len(agent.Stacks))
+	for _, s := range agent.Stacks {
+		if s != stack {
+			stacks = append(stacks, s)
+		}
+	}
+	agent.Stacks = stacks
+}
+
 // updateAgentStacks updates the list of stacks for an agent
 func (c *Core) updateAgentStacks(agentID string, stacks []string) error {
-	c.agents.mu.Lock()
-	defer c.agents.mu.Unlock()
-
-	agent, exists := c.agents.agents[agentID]
+	agent, exists := c.agents.lock(agentID)
 	if !exists {
 		return fmt.Errorf("agent not found: %s", agentID)
 	}
+	defer agent.mu.Unlock()
 
 	agent.Stacks = stacks
 	return nil
@@ -716,6 +1504,14 @@ func (c *Core) GetStackLogs(req *agentv1.GetStackLogsRequest, stream agentv1.Sta
 		return fmt.Errorf("get agent connection: %w", err)
 	}
 
+	if err := requireCapability(conn, agentv1.StackService_GetStackLogs_FullMethodName); err != nil {
+		return err
+	}
+
+	if err := c.authorizeNamespaced(stream.Context(), conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return err
+	}
+
 	// Create stack service client for this agent
 	stackClient := agentv1.NewStackServiceClient(conn.Client)
 
@@ -737,3 +1533,71 @@ func (c *Core) GetStackLogs(req *agentv1.GetStackLogsRequest, stream agentv1.Sta
 		}
 	}
 }
+
+// GetStackLogsBatched proxies batched logs. Agents without the batched RPC
+// are read entry by entry and batched here, so the client hop still gets
+// batched frames.
+func (c *Core) GetStackLogsBatched(req *agentv1.GetStackLogsRequest, stream agentv1.StackService_GetStackLogsBatchedServer) error {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return fmt.Errorf("get agent connection: %w", err)
+	}
+
+	if err := requireCapability(conn, agentv1.StackService_GetStackLogsBatched_FullMethodName); err != nil {
+		return err
+	}
+
+	if err := c.authorizeNamespaced(stream.Context(), conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return err
+	}
+
+	stackClient := agentv1.NewStackServiceClient(conn.Client)
+
+	agentStream, err := stackClient.GetStackLogsBatched(stream.Context(), req)
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+
+	first := true
+	for {
+		batch, err := agentStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if first && status.Code(err) == codes.Unimplemented {
+			return c.batchAgentLogs(stackClient, req, stream)
+		}
+		if err != nil {
+			return err
+		}
+		first = false
+
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+	}
+}
+
+// batchAgentLogs reads unbatched logs from an older agent and batches them
+func (c *Core) batchAgentLogs(stackClient agentv1.StackServiceClient, req *agentv1.GetStackLogsRequest, stream agentv1.StackService_GetStackLogsBatchedServer) error {
+	agentStream, err := stackClient.GetStackLogs(stream.Context(), req)
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+
+	size, interval := transport.BatchLimits(req)
+	batcher := transport.NewLogBatcher(size, interval, stream.Send)
+	for {
+		entry, err := agentStream.Recv()
+		if err == io.EOF {
+			return batcher.Flush()
+		}
+		if err != nil {
+			batcher.Flush()
+			return err
+		}
+		if err := batcher.Add(entry); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+// listServices asks the server behind conn for its services by reflection
+// and returns once the call has ended
+func listServices(conn *grpc.ClientConn) ([]string, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		return nil, err
+	}
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.Name)
+	}
+	return names, nil
+}
+
+func TestReflection(t *testing.T) {
+	const method = "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"
+
+	// serveAs serves c's API to the holder of a certificate for user, or to
+	// callers without one
+	serveAs := func(c *Core, user string) *grpc.ClientConn {
+		withCert := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if user == "" {
+				return handler(srv, ss)
+			}
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: user}}
+			ctx := peer.NewContext(ss.Context(), &peer.Peer{
+				AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+			})
+			return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+		}
+		return serve(t, c.NewServer(grpc.StreamInterceptor(withCert)))
+	}
+	newCore := func(reflection bool) (*Core, *auditRecorder) {
+		auditor := &auditRecorder{}
+		plugins := plugin.NewRegistry()
+		for _, p := range []plugin.Plugin{auditor, &grantAuth{roles: map[string][]string{"alice": {"operator"}}}} {
+			if err := plugins.Register(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		routes, err := newProxyRoutes(config.ProxyConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Core{
+			config:      &CoreConfig{FullConfig: &config.CoreConfig{Server: config.ServerConfig{Reflection: reflection}}},
+			plugins:     plugins,
+			breakGlass:  newBreakGlassStore(config.BreakGlassConfig{}),
+			freeze:      &Freeze{},
+			agents:      newAgentRegistry(),
+			proxyRoutes: routes,
+		}
+		return c, auditor
+	}
+
+	// Enabled, it describes the core's API to users the auth plugin knows,
+	// and the call is audited under their authenticated identity
+	c, auditor := newCore(true)
+	services, err := listServices(serveAs(c, "alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"mandau.agent.v1.CoreService", "mandau.agent.v1.StackService", "grpc.reflection.v1.ServerReflection"} {
+		if !slices.Contains(services, want) {
+			t.Errorf("services = %v, missing %s", services, want)
+		}
+	}
+	if len(auditor.entries) != 1 {
+		t.Fatalf("audited %d entries, want 1", len(auditor.entries))
+	}
+	if entry := auditor.entries[0]; entry.Action != method || entry.Result != "success" ||
+		entry.Identity == nil || entry.Identity.UserID != "alice" || !slices.Equal(entry.Identity.Roles, []string{"operator"}) {
+		t.Errorf("entry = %+v, want alice's call as operator", entry)
+	}
+
+	// Users the auth plugin rejects, and callers without a certificate, are
+	// refused as on any other call
+	for _, user := range []string{"mallory", ""} {
+		before := len(auditor.entries)
+		if _, err := listServices(serveAs(c, user)); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%q: %v, want Unauthenticated", user, err)
+		}
+		if len(auditor.entries) != before+1 || auditor.entries[before].Action != method || auditor.entries[before].Result != "denied" {
+			t.Errorf("%q: refusal not audited", user)
+		}
+	}
+
+	// Disabled, there is no reflection service to call
+	c, _ = newCore(false)
+	if _, err := listServices(serveAs(c, "alice")); status.Code(err) != codes.Unimplemented {
+		t.Errorf("disabled reflection: %v, want Unimplemented", err)
+	}
+}
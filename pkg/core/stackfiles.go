@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DiffStackFiles asks an agent which of the files a caller means to apply
+// it lacks, once the caller may read the stack
+func (c *Core) DiffStackFiles(ctx context.Context, req *agentv1.DiffStackFilesRequest) (*agentv1.DiffStackFilesResponse, error) {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.StackService_DiffStackFiles_FullMethodName); err != nil {
+		return nil, err
+	}
+	if err := c.authorizeNamespaced(ctx, conn, "read", normalizeNamespace(req.Namespace), "stack:"+req.StackName); err != nil {
+		return nil, err
+	}
+	return agentv1.NewStackServiceClient(conn.Client).DiffStackFiles(ctx, req)
+}
+
+// UploadStackFiles relays files to stage for a stack's next apply to the
+// agent the first chunk names, once the caller may change the stack. Only
+// the first chunk is read before the checks.
+func (c *Core) UploadStackFiles(stream agentv1.StackService_UploadStackFilesServer) (err error) {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+	if first.StackName == "" {
+		return status.Error(codes.InvalidArgument, "stack files need a stack")
+	}
+
+	conn, err := c.getAgentConnection(first.AgentId)
+	if err != nil {
+		return fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.StackService_UploadStackFiles_FullMethodName); err != nil {
+		return err
+	}
+	ns := normalizeNamespace(first.Namespace)
+	if err := c.authorizeNamespaced(ctx, conn, "write", ns, "stack:"+first.StackName); err != nil {
+		return err
+	}
+	if err := c.requireDeployAllowed(ctx, conn, ns, "stack:"+first.StackName, first.Emergency); err != nil {
+		return err
+	}
+
+	op := c.activity.begin(first.AgentId, "upload_stack_files", first.StackName, ns, c.requesterID(ctx), requestid.From(ctx))
+	defer func() { op.end(err) }()
+
+	agentStream, err := agentv1.NewStackServiceClient(conn.Client).UploadStackFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+
+	// Headers tell the caller the upload was accepted before it sends the
+	// files
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for chunk := first; ; {
+		if err := agentStream.Send(chunk); err != nil {
+			// The agent ended the call; its status says why
+			break
+		}
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := agentStream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stackFilesAgent keeps the chunks it was sent
+type stackFilesAgent struct {
+	agentv1.UnimplementedStackServiceServer
+	chunks []*agentv1.StackFileChunk
+}
+
+func (a *stackFilesAgent) UploadStackFiles(stream agentv1.StackService_UploadStackFilesServer) error {
+	resp := &agentv1.UploadStackFilesResponse{}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		a.chunks = append(a.chunks, chunk)
+		if chunk.Path != "" {
+			resp.Files++
+		}
+		resp.Bytes += int64(len(chunk.Data))
+	}
+}
+
+func TestUploadStackFiles(t *testing.T) {
+	agentServer := grpc.NewServer()
+	agent := &stackFilesAgent{}
+	agentv1.RegisterStackServiceServer(agentServer, agent)
+	agentConn := serve(t, agentServer)
+
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: []string{"docker", "stack"}, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-2", Capabilities: []string{"docker"}, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		plugins:  plugin.NewRegistry(),
+		activity: newActivity(),
+	}
+	coreServer := grpc.NewServer()
+	agentv1.RegisterStackServiceServer(coreServer, c)
+	client := agentv1.NewStackServiceClient(serve(t, coreServer))
+	ctx := context.Background()
+
+	stream, err := client.UploadStackFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []*agentv1.StackFileChunk{
+		{AgentId: "web-1", StackName: "shop"},
+		{Path: "nginx.conf", Data: []byte("events ")},
+		{Data: []byte("{}")},
+		{Path: "empty"},
+	} {
+		if err := stream.Send(chunk); err != nil {
+			t.Fatal(err)
+		}
+		// The core accepts the upload before the files are sent
+		if chunk.AgentId != "" {
+			if md, _ := stream.Header(); md == nil {
+				_, err := stream.CloseAndRecv()
+				t.Fatalf("no headers: %v", err)
+			}
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agent.chunks) != 4 || resp.Files != 2 || resp.Bytes != 9 {
+		t.Errorf("agent got %d chunks and answered %v", len(agent.chunks), resp)
+	}
+
+	// Agents that cannot apply stacks are refused before the files are sent
+	stream, _ = client.UploadStackFiles(ctx)
+	stream.Send(&agentv1.StackFileChunk{AgentId: "web-2", StackName: "shop"})
+	if md, _ := stream.Header(); md != nil {
+		t.Error("core accepted stack files for an agent without the stack capability")
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("agent without stack capability: error = %v, want failed precondition", err)
+	}
+
+	stream, _ = client.UploadStackFiles(ctx)
+	stream.Send(&agentv1.StackFileChunk{AgentId: "web-1"})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("chunk without stack: error = %v, want invalid argument", err)
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupResource is what downloading an agent's backups needs "read" on
+const backupResource = "host:backup"
+
+// authorizeTransfer checks that the caller may write to target on conn:
+// what applying its stack needs, as files and images change how it runs
+func (c *Core) authorizeTransfer(ctx context.Context, conn *AgentConnection, target *agentv1.TransferTarget, action string, emergency bool) error {
+	if target.GetStackName() == "" {
+		return status.Error(codes.InvalidArgument, "transfer target needs a stack")
+	}
+	ns := normalizeNamespace(target.GetNamespace())
+	if err := c.authorizeNamespaced(ctx, conn, action, ns, "stack:"+target.StackName); err != nil {
+		return err
+	}
+	if action != "write" {
+		return nil
+	}
+	return c.requireDeployAllowed(ctx, conn, ns, "stack:"+target.StackName, emergency)
+}
+
+// Upload relays a payload to the agent its first request names, chunk by
+// chunk, once the caller may change the target's stack. Only the first
+// request is read before the checks.
+func (c *Core) Upload(stream agentv1.TransferService_UploadServer) (err error) {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+
+	conn, err := c.getAgentConnection(first.AgentId)
+	if err != nil {
+		return fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.TransferService_Upload_FullMethodName); err != nil {
+		return err
+	}
+	if err := c.authorizeTransfer(ctx, conn, first.Target, "write", first.Emergency); err != nil {
+		return err
+	}
+
+	op := c.activity.begin(first.AgentId, "upload", first.Target.StackName, normalizeNamespace(first.Target.Namespace),
+		c.requesterID(ctx), requestid.From(ctx))
+	defer func() { op.end(err) }()
+
+	agentStream, err := agentv1.NewTransferServiceClient(conn.Client).Upload(ctx)
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+
+	// Headers tell the caller the upload was accepted before it sends the
+	// payload
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for req := first; ; {
+		if err := agentStream.Send(req); err != nil {
+			// The agent ended the call; its status says why
+			break
+		}
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := agentStream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// GetTransfer returns how much of an upload an agent holds, to callers
+// that may read the target's stack
+func (c *Core) GetTransfer(ctx context.Context, req *agentv1.GetTransferRequest) (*agentv1.TransferStatus, error) {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.TransferService_GetTransfer_FullMethodName); err != nil {
+		return nil, err
+	}
+	if err := c.authorizeTransfer(ctx, conn, req.Target, "read", false); err != nil {
+		return nil, err
+	}
+	return agentv1.NewTransferServiceClient(conn.Client).GetTransfer(ctx, req)
+}
+
+// ListBackups lists an agent's backups
+func (c *Core) ListBackups(ctx context.Context, req *agentv1.ListBackupsRequest) (*agentv1.ListBackupsResponse, error) {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return nil, fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.TransferService_ListBackups_FullMethodName); err != nil {
+		return nil, err
+	}
+	if err := c.authorizeAgent(ctx, conn, "read", backupResource); err != nil {
+		return nil, err
+	}
+	return agentv1.NewTransferServiceClient(conn.Client).ListBackups(ctx, req)
+}
+
+// DownloadBackup streams a backup from an agent
+func (c *Core) DownloadBackup(req *agentv1.DownloadBackupRequest, stream agentv1.TransferService_DownloadBackupServer) error {
+	conn, err := c.getAgentConnection(req.AgentId)
+	if err != nil {
+		return fmt.Errorf("get agent connection: %w", err)
+	}
+	if err := requireCapability(conn, agentv1.TransferService_DownloadBackup_FullMethodName); err != nil {
+		return err
+	}
+	if err := c.authorizeAgent(stream.Context(), conn, "read", backupResource); err != nil {
+		return err
+	}
+
+	agentStream, err := agentv1.NewTransferServiceClient(conn.Client).DownloadBackup(stream.Context(), req)
+	if err != nil {
+		return fmt.Errorf("forward to agent: %w", err)
+	}
+	for {
+		chunk, err := agentStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+}
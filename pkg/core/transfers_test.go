@@ -0,0 +1,111 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadAgent keeps what it was sent
+type uploadAgent struct {
+	agentv1.UnimplementedTransferServiceServer
+	first   *agentv1.UploadRequest
+	payload bytes.Buffer
+}
+
+func (a *uploadAgent) Upload(stream agentv1.TransferService_UploadServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if a.first == nil {
+			a.first = req
+		}
+		a.payload.Write(req.Chunk)
+	}
+	return stream.SendAndClose(&agentv1.TransferStatus{
+		TransferId: a.first.TransferId,
+		Received:   a.first.Offset + int64(a.payload.Len()),
+		Delivered:  true,
+		Images:     []string{"shop:1.0"},
+	})
+}
+
+func TestUpload(t *testing.T) {
+	agentServer := grpc.NewServer()
+	agent := &uploadAgent{}
+	agentv1.RegisterTransferServiceServer(agentServer, agent)
+	agentConn := serve(t, agentServer)
+
+	c := &Core{
+		agents: newAgentRegistry(
+			&AgentConnection{ID: "web-1", Capabilities: []string{"docker", "transfer"}, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+			&AgentConnection{ID: "web-2", Capabilities: []string{"docker"}, Client: agentConn, Status: AgentStatusOnline, LastSeen: time.Now()},
+		),
+		plugins:  plugin.NewRegistry(),
+		activity: newActivity(),
+	}
+	coreServer := grpc.NewServer()
+	agentv1.RegisterTransferServiceServer(coreServer, c)
+	client := agentv1.NewTransferServiceClient(serve(t, coreServer))
+	ctx := context.Background()
+	bundle := &agentv1.TransferTarget{Kind: agentv1.TransferKind_TRANSFER_KIND_IMAGE_BUNDLE, StackName: "shop"}
+
+	stream, err := client.Upload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&agentv1.UploadRequest{AgentId: "web-1", TransferId: "t1", Target: bundle, Size: 16, Offset: 4}); err != nil {
+		t.Fatal(err)
+	}
+	// The core accepts the upload before the payload is sent
+	if md, _ := stream.Header(); md == nil {
+		_, err := stream.CloseAndRecv()
+		t.Fatalf("no headers: %v", err)
+	}
+	for _, chunk := range []string{"first ", "second"} {
+		if err := stream.Send(&agentv1.UploadRequest{Chunk: []byte(chunk)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.payload.String() != "first second" || agent.first.Offset != 4 || st.Received != 16 || !st.Delivered {
+		t.Errorf("agent got %q from %d and answered %v", agent.payload.String(), agent.first.Offset, st)
+	}
+
+	// Agents that cannot take transfers are refused before the payload is
+	// sent
+	stream, err = client.Upload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&agentv1.UploadRequest{AgentId: "web-2", TransferId: "t2", Target: bundle})
+	if md, _ := stream.Header(); md != nil {
+		t.Error("core accepted an upload for an agent without the transfer capability")
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("agent without transfer capability: error = %v, want failed precondition", err)
+	}
+
+	// Every target belongs to a stack, which permissions are checked on
+	stream, _ = client.Upload(ctx)
+	stream.Send(&agentv1.UploadRequest{AgentId: "web-1", TransferId: "t3", Target: &agentv1.TransferTarget{Kind: agentv1.TransferKind_TRANSFER_KIND_FILE}})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("target without stack: error = %v, want invalid argument", err)
+	}
+}
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(StateReady); sent || err != nil {
+		t.Fatalf("Notify() without a socket = %v, %v", sent, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	if sent, err := Notify(StateReady); !sent || err != nil {
+		t.Fatalf("Notify() = %v, %v", sent, err)
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != StateReady {
+		t.Errorf("received %q, want %q", got, StateReady)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		usec   string
+		pid    string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "unset"},
+		{name: "half of timeout", usec: "30000000", want: 15 * time.Second, wantOK: true},
+		{name: "this process", usec: "2000000", pid: strconv.Itoa(os.Getpid()), want: time.Second, wantOK: true},
+		{name: "other process", usec: "2000000", pid: "1"},
+		{name: "invalid", usec: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+
+			got, ok := WatchdogInterval()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("WatchdogInterval() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "agent.pid")
+
+	if err := WritePIDFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != strconv.Itoa(os.Getpid())+"\n" {
+		t.Fatalf("pid file = %q, %v", data, err)
+	}
+
+	// A stale file from a dead process is replaced
+	os.WriteFile(path, []byte("999999999\n"), 0644)
+	if err := WritePIDFile(path); err != nil {
+		t.Errorf("stale pid file not replaced: %v", err)
+	}
+
+	if err := RemovePIDFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("pid file not removed: %v", err)
+	}
+}
+
+func TestListenersNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "api")
+
+	listeners, err := Listeners()
+	if err != nil || len(listeners) != 0 {
+		t.Fatalf("Listeners() for another process = %v, %v", listeners, err)
+	}
+	for _, name := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		if _, set := os.LookupEnv(name); set {
+			t.Errorf("%s left in the environment", name)
+		}
+	}
+}
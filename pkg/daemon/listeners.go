@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor systemd passes, see
+// sd_listen_fds(3)
+const listenFDsStart = 3
+
+// Listeners returns the sockets passed by systemd socket activation, named
+// after their FileDescriptorName= (or the unit's socket name when unset).
+// It returns none when the process was not socket activated. The
+// environment is cleared so child processes do not pick the sockets up.
+func Listeners() (map[string][]net.Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make(map[string][]net.Listener)
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(fd), name)
+		lis, err := net.FileListener(file)
+		file.Close() // FileListener holds its own close-on-exec duplicate
+		if err != nil {
+			return nil, fmt.Errorf("socket %d (%s) is not a stream listener: %w", fd, name, err)
+		}
+		listeners[name] = append(listeners[name], lis)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,149 @@
+// Package diagnose holds the checks shared by "mandau doctor", the core and
+// agents. Every failing check carries a remediation hint.
+package diagnose
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusWarn
+	StatusFail
+)
+
+// Check is a single diagnostic result
+type Check struct {
+	Name        string
+	Status      Status
+	Detail      string
+	Remediation string
+}
+
+// OK, Warn and Fail build checks
+func OK(name, detail string) Check {
+	return Check{Name: name, Status: StatusOK, Detail: detail}
+}
+
+func Warn(name, detail, remediation string) Check {
+	return Check{Name: name, Status: StatusWarn, Detail: detail, Remediation: remediation}
+}
+
+func Fail(name, detail, remediation string) Check {
+	return Check{Name: name, Status: StatusFail, Detail: detail, Remediation: remediation}
+}
+
+// CertExpiryWarning is how early an expiring certificate is reported
+const CertExpiryWarning = 14 * 24 * time.Hour
+
+// CertFile checks that a PEM certificate exists, is currently valid and not
+// about to expire
+func CertFile(name, path string) Check {
+	cert, check := readCert(name, path)
+	if cert == nil {
+		return check
+	}
+	return Certificate(name, cert, time.Now())
+}
+
+// ServerCertFile is CertFile for a certificate that peers verify against
+// serverName, which must therefore be one of its SANs
+func ServerCertFile(name, path, serverName string) Check {
+	cert, check := readCert(name, path)
+	if cert == nil {
+		return check
+	}
+
+	check = Certificate(name, cert, time.Now())
+	if check.Status != StatusFail && cert.VerifyHostname(serverName) != nil {
+		return Fail(name, check.Detail+" (missing SAN "+serverName+")",
+			"Reissue the certificate with SAN "+serverName+"; peers verify it against that name")
+	}
+	return check
+}
+
+// readCert loads a PEM certificate, returning a failed check when it cannot
+func readCert(name, path string) (*x509.Certificate, Check) {
+	if path == "" {
+		return nil, Fail(name, "no certificate configured", "Set the certificate path in the config file or via flags")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Fail(name, fmt.Sprintf("read %s: %v", path, err),
+			"Check the path and file permissions, or regenerate certificates with scripts/generate-certs.sh")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, Fail(name, path+" is not PEM encoded", "Point the config at the PEM certificate, not the key or a DER file")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, Fail(name, fmt.Sprintf("parse %s: %v", path, err), "Regenerate the certificate")
+	}
+
+	return cert, Check{}
+}
+
+// Certificate checks the validity window of cert and reports its SANs
+func Certificate(name string, cert *x509.Certificate, now time.Time) Check {
+	detail := fmt.Sprintf("CN=%s, SANs=[%s], expires %s",
+		cert.Subject.CommonName, strings.Join(SANs(cert), ", "), cert.NotAfter.Format("2006-01-02"))
+
+	switch {
+	case now.Before(cert.NotBefore):
+		return Fail(name, detail+" (not yet valid)", "Check the system clock or reissue the certificate")
+	case now.After(cert.NotAfter):
+		return Fail(name, detail+" (expired)", "Renew the certificate and restart the service")
+	case cert.NotAfter.Sub(now) < CertExpiryWarning:
+		return Warn(name, detail+" (expires soon)", "Renew the certificate before it expires")
+	}
+	return OK(name, detail)
+}
+
+// SANs lists DNS names and IP addresses of cert
+func SANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+// Writable checks that files can be created in dir
+func Writable(name, dir string) Check {
+	f, err := os.CreateTemp(dir, ".mandau-doctor-*")
+	if err != nil {
+		return Fail(name, fmt.Sprintf("%s is not writable: %v", dir, err),
+			"Create the directory and give the service user write access")
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return OK(name, filepath.Clean(dir)+" is writable")
+}
+
+// ClockSkew compares a remote clock with the local one. Large skew breaks
+// certificate validation and makes audit timelines misleading.
+func ClockSkew(name string, skew time.Duration) Check {
+	if skew < 0 {
+		skew = -skew
+	}
+	detail := fmt.Sprintf("skew %s", skew.Round(time.Millisecond))
+	switch {
+	case skew > 30*time.Second:
+		return Fail(name, detail, "Enable NTP (e.g. timedatectl set-ntp true) on both hosts")
+	case skew > 2*time.Second:
+		return Warn(name, detail, "Enable NTP (e.g. timedatectl set-ntp true) on both hosts")
+	}
+	return OK(name, detail)
+}
@@ -0,0 +1,77 @@
+package diagnose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, dnsNames []string, notAfter time.Time) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestServerCertFile(t *testing.T) {
+	valid := time.Now().Add(365 * 24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		dnsNames []string
+		notAfter time.Time
+		want     Status
+	}{
+		{"has SAN", []string{"mandau-agent"}, valid, StatusOK},
+		{"missing SAN", []string{"agent-01.example.com"}, valid, StatusFail},
+		{"no SANs", nil, valid, StatusFail},
+		{"expiring with SAN", []string{"mandau-agent"}, time.Now().Add(24 * time.Hour), StatusWarn},
+		{"expired", []string{"mandau-agent"}, time.Now().Add(-time.Minute), StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeCert(t, tt.dnsNames, tt.notAfter)
+			check := ServerCertFile("cert", path, "mandau-agent")
+			if check.Status != tt.want {
+				t.Fatalf("status = %v, want %v (%s)", check.Status, tt.want, check.Detail)
+			}
+			if check.Status != StatusOK && check.Remediation == "" {
+				t.Fatal("failing check without remediation")
+			}
+		})
+	}
+}
+
+func TestServerCertFileMissing(t *testing.T) {
+	if check := ServerCertFile("cert", filepath.Join(t.TempDir(), "nope.pem"), "mandau-agent"); check.Status != StatusFail {
+		t.Fatalf("status = %v, want fail", check.Status)
+	}
+}
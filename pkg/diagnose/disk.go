@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd
+
+package diagnose
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/docker/go-units"
+)
+
+// DiskSpace checks free space on the filesystem holding path
+func DiskSpace(name, path string) Check {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return Fail(name, fmt.Sprintf("stat %s: %v", path, err), "Create the directory or fix the configured path")
+	}
+
+	// Field types differ between platforms
+	total := uint64(fs.Blocks) * uint64(fs.Bsize)
+	free := uint64(fs.Bavail) * uint64(fs.Bsize)
+	if total == 0 {
+		return Warn(name, "filesystem reports no size", "")
+	}
+
+	pct := float64(free) / float64(total) * 100
+	detail := fmt.Sprintf("%s free of %s (%.0f%%) under %s",
+		units.BytesSize(float64(free)), units.BytesSize(float64(total)), pct, path)
+
+	switch {
+	case pct < 2:
+		return Fail(name, detail, "Free disk space: prune images (docker image prune) or grow the volume")
+	case pct < 10:
+		return Warn(name, detail, "Free disk space: prune images (docker image prune) or grow the volume")
+	}
+	return OK(name, detail)
+}
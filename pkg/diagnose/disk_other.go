@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package diagnose
+
+import "runtime"
+
+// DiskSpace is not checked on platforms where agents are not supported
+func DiskSpace(name, path string) Check {
+	return Warn(name, "disk space check not supported on "+runtime.GOOS, "")
+}
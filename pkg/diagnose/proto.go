@@ -0,0 +1,42 @@
+package diagnose
+
+import (
+	"crypto/x509"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts checks for the Diagnose RPCs, tagging them with source
+func ToProto(source string, checks []Check) []*agentv1.DiagnosticCheck {
+	result := make([]*agentv1.DiagnosticCheck, len(checks))
+	for i, c := range checks {
+		result[i] = &agentv1.DiagnosticCheck{
+			Source:      source,
+			Name:        c.Name,
+			Status:      agentv1.CheckStatus(c.Status),
+			Detail:      c.Detail,
+			Remediation: c.Remediation,
+		}
+	}
+	return result
+}
+
+// PeerCertificateToProto describes cert for the connection diagnostics,
+// checked against the name peers verify it with. Either may be missing
+// when the handshake failed with err.
+func PeerCertificateToProto(cert *x509.Certificate, expectedName string, err error) *agentv1.PeerCertificate {
+	pc := &agentv1.PeerCertificate{ExpectedName: expectedName}
+	if err != nil {
+		pc.Error = err.Error()
+	}
+	if cert == nil {
+		return pc
+	}
+	pc.Subject = cert.Subject.String()
+	pc.Issuer = cert.Issuer.String()
+	pc.Sans = SANs(cert)
+	pc.NotAfter = timestamppb.New(cert.NotAfter)
+	pc.NameMatches = expectedName == "" || cert.VerifyHostname(expectedName) == nil
+	return pc
+}
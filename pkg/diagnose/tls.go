@@ -0,0 +1,81 @@
+package diagnose
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"time"
+)
+
+// TLSProbe is the outcome of a TLS handshake made to diagnose a connection
+type TLSProbe struct {
+	// Certificate is what the peer presented, also when it failed
+	// verification; nil when the handshake never got that far
+	Certificate *x509.Certificate
+	Handshake   time.Duration // Dialing included
+	Err         error
+}
+
+// ProbeTLS dials addr and completes a TLS handshake with cfg. A nil dial
+// uses TCP. When the peer's certificate fails verification it is fetched
+// again without verifying, so the probe still tells what was presented.
+func ProbeTLS(ctx context.Context, dial func(ctx context.Context, addr string) (net.Conn, error), addr string, cfg *tls.Config) TLSProbe {
+	if dial == nil {
+		dialer := &net.Dialer{}
+		dial = func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}
+	}
+
+	start := time.Now()
+	cert, err := handshake(ctx, dial, addr, cfg)
+	probe := TLSProbe{Certificate: cert, Handshake: time.Since(start), Err: err}
+
+	var opErr *net.OpError
+	if err != nil && !(errors.As(err, &opErr) && opErr.Op == "dial") && ctx.Err() == nil {
+		insecure := cfg.Clone()
+		insecure.InsecureSkipVerify = true
+		insecure.VerifyConnection = nil
+		probe.Certificate, _ = handshake(ctx, dial, addr, insecure)
+	}
+	return probe
+}
+
+func handshake(ctx context.Context, dial func(ctx context.Context, addr string) (net.Conn, error), addr string, cfg *tls.Config) (*x509.Certificate, error) {
+	raw, err := dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(raw, cfg)
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+// TLSFailure turns a failed handshake with peer, "core" or "agent", at addr
+// into a check with a remediation for its cause
+func TLSFailure(name, peer, addr, serverName string, err error) Check {
+	var (
+		hostErr x509.HostnameError
+		authErr x509.UnknownAuthorityError
+		opErr   *net.OpError
+	)
+	switch {
+	case errors.As(err, &hostErr):
+		return Fail(name, err.Error(),
+			"Reissue the "+peer+" certificate with SAN "+serverName)
+	case errors.As(err, &authErr):
+		return Fail(name, err.Error(),
+			"The "+peer+" certificate is not signed by the configured CA; use the cluster CA")
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return Fail(name, err.Error(),
+			"Check that mandau-"+peer+" is running on "+addr+" and the port is open")
+	default:
+		return Fail(name, err.Error(),
+			"Check that the "+peer+" trusts the client CA and the client certificate is valid")
+	}
+}
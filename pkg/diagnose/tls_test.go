@@ -0,0 +1,104 @@
+package diagnose
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveTLS accepts TLS connections with a self-signed certificate for
+// dnsNames until the test ends, returning its address and certificate
+func serveTLS(t *testing.T, dnsNames ...string) (string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	return lis.Addr().String(), cert
+}
+
+func TestProbeTLS(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addr, cert := serveTLS(t, "mandau-agent")
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	probe := ProbeTLS(ctx, nil, addr, &tls.Config{RootCAs: pool, ServerName: "mandau-agent"})
+	if probe.Err != nil || probe.Certificate == nil || probe.Handshake <= 0 {
+		t.Fatalf("probe = %+v", probe)
+	}
+	if pc := PeerCertificateToProto(probe.Certificate, "mandau-agent", nil); !pc.NameMatches || pc.Sans[0] != "mandau-agent" {
+		t.Errorf("peer certificate = %v", pc)
+	}
+
+	// A certificate without the name still tells what was presented
+	addr, cert = serveTLS(t, "web.example.com")
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	probe = ProbeTLS(ctx, nil, addr, &tls.Config{RootCAs: pool, ServerName: "mandau-agent"})
+	if probe.Err == nil || probe.Certificate == nil {
+		t.Fatalf("probe = %+v, want an error with the certificate", probe)
+	}
+	if pc := PeerCertificateToProto(probe.Certificate, "mandau-agent", probe.Err); pc.NameMatches || pc.Error == "" {
+		t.Errorf("peer certificate = %v", pc)
+	}
+	check := TLSFailure("agent TLS", "agent", addr, "mandau-agent", probe.Err)
+	if check.Status != StatusFail || !strings.Contains(check.Remediation, "SAN mandau-agent") {
+		t.Errorf("check = %+v", check)
+	}
+
+	// Nothing listening: no certificate, and the remediation says so
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := lis.Addr().String()
+	lis.Close()
+	probe = ProbeTLS(ctx, nil, closed, &tls.Config{RootCAs: pool, ServerName: "mandau-agent"})
+	if probe.Err == nil || probe.Certificate != nil {
+		t.Fatalf("probe = %+v, want a dial error", probe)
+	}
+	if check := TLSFailure("agent TLS", "agent", closed, "mandau-agent", probe.Err); !strings.Contains(check.Remediation, "is running on "+closed) {
+		t.Errorf("check = %+v", check)
+	}
+}
@@ -0,0 +1,177 @@
+// Package diff renders unified diffs of the files host service plugins
+// would write, for dry runs.
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// File diffs the current content of path against content. A missing file
+// diffs as empty, and nil content as the file being removed. It returns ""
+// when nothing would change.
+func File(path string, content []byte) (string, error) {
+	current, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	oldName, newName := "a"+path, "b"+path
+	if !exists {
+		if content == nil {
+			return "", nil
+		}
+		oldName = "/dev/null"
+	}
+	if content == nil {
+		newName = "/dev/null"
+	}
+	return Unified(oldName, newName, string(current), string(content)), nil
+}
+
+// Unified returns the unified diff turning a into b, or "" when they are
+// equal
+func Unified(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := edits(lines(a), lines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// Find the next change and the run of changes close enough to it to
+		// share a hunk
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from := max(start-contextLines, 0)
+		to := min(end+contextLines, len(ops))
+		hunk := ops[from:to]
+
+		oldStart, newStart := ops[from].oldLine, ops[from].newLine
+		var oldCount, newCount int
+		for _, op := range hunk {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, op := range hunk {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// op is one line of an edit script: ' ' kept, '-' removed or '+' added.
+// oldLine and newLine are the 1-based positions it is at in each side.
+type op struct {
+	kind             byte
+	text             string
+	oldLine, newLine int
+}
+
+// edits is the shortest edit script turning a into b, by longest common
+// subsequence over what remains after the common prefix and suffix
+func edits(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	add := func(kind byte, text string) {
+		ops = append(ops, op{kind: kind, text: text, oldLine: i + 1, newLine: j + 1})
+		if kind != '+' {
+			i++
+		}
+		if kind != '-' {
+			j++
+		}
+	}
+	for _, line := range a[:prefix] {
+		add(' ', line)
+	}
+	for x, y := 0, 0; x < len(midA) || y < len(midB); {
+		switch {
+		case x < len(midA) && y < len(midB) && midA[x] == midB[y]:
+			add(' ', midA[x])
+			x, y = x+1, y+1
+		case x < len(midA) && (y == len(midB) || lcs[x+1][y] >= lcs[x][y+1]):
+			add('-', midA[x])
+			x++
+		default:
+			add('+', midB[y])
+			y++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		add(' ', line)
+	}
+	return ops
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// hunkRange formats a hunk side; an empty side names the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nthree\n4\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := `--- old
++++ new
+@@ -1,10 +1,11 @@
+ one
+ two
+ three
+-four
++4
+ five
+ six
+ seven
+ eight
+ nine
+ ten
++eleven
+`
+	if got := Unified("old", "new", a, b); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified("old", "new", a, a); got != "" {
+		t.Errorf("equal inputs diffed as\n%s", got)
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var a, b string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a'+i)) + "\n"
+		a += line
+		if i != 2 && i != 17 {
+			b += line
+		}
+	}
+
+	want := `--- old
++++ new
+@@ -1,6 +1,5 @@
+ a
+ b
+-c
+ d
+ e
+ f
+@@ -15,6 +14,5 @@
+ o
+ p
+ q
+-r
+ s
+ t
+`
+	if got := Unified("old", "new", a, b); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.conf")
+
+	got, err := File(path, []byte("listen 80;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "--- /dev/null\n+++ b" + path + "\n@@ -0,0 +1 @@\n+listen 80;\n"; got != want {
+		t.Errorf("new file diffed as\n%s", got)
+	}
+
+	if err := os.WriteFile(path, []byte("listen 80;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := File(path, []byte("listen 80;\n")); got != "" {
+		t.Errorf("unchanged file diffed as\n%s", got)
+	}
+	got, _ = File(path, nil)
+	if want := "--- a" + path + "\n+++ /dev/null\n@@ -1 +0,0 @@\n-listen 80;\n"; got != want {
+		t.Errorf("removed file diffed as\n%s", got)
+	}
+}
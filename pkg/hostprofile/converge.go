@@ -0,0 +1,449 @@
+package hostprofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of checks, one per section of a profile
+const (
+	KindPackage  = "package"
+	KindSysctl   = "sysctl"
+	KindUser     = "user"
+	KindFirewall = "firewall"
+	KindDocker   = "docker"
+	KindLabel    = "label"
+)
+
+// Check compares one item of a profile with a host
+type Check struct {
+	Kind string
+	Name string
+	Want string
+	Have string
+	OK   bool
+}
+
+// Report is the compliance diff of a host against a profile
+type Report struct {
+	Agent   string
+	Profile string
+	Checks  []Check
+	Changed []Check // What Apply changed, as found before the change
+}
+
+// Compliant reports whether every check passed
+func (r *Report) Compliant() bool {
+	return len(r.Drift()) == 0
+}
+
+// Drift returns the checks that failed
+func (r *Report) Drift() []Check {
+	var drift []Check
+	for _, c := range r.Checks {
+		if !c.OK {
+			drift = append(drift, c)
+		}
+	}
+	return drift
+}
+
+// Converger compares hosts with profiles and brings them in line, through
+// the host services the core proxies to agents
+type Converger struct {
+	env      agentv1.HostEnvironmentServiceClient
+	firewall agentv1.FirewallServiceClient
+	core     agentv1.CoreServiceClient
+
+	// Force replaces sysctl.d and authorized_keys files edited outside
+	// Mandau
+	Force bool
+	// OnChange, when set, is called before each change Apply makes
+	OnChange func(agentID string, c Check)
+}
+
+// NewConverger returns a converger calling the host environment and
+// firewall services of agents, and the core for their labels
+func NewConverger(env agentv1.HostEnvironmentServiceClient, firewall agentv1.FirewallServiceClient, core agentv1.CoreServiceClient) *Converger {
+	return &Converger{env: env, firewall: firewall, core: core}
+}
+
+// Diff compares the host of agentID with p without changing it
+func (c *Converger) Diff(ctx context.Context, p *Profile, agentID string) (*Report, error) {
+	r := &Report{Agent: agentID, Profile: p.Name}
+
+	if len(p.Packages) > 0 || p.Docker != nil {
+		if err := c.diffPackages(ctx, p, r); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range sortedKeys(p.Sysctls) {
+		check := Check{Kind: KindSysctl, Name: key, Want: p.Sysctls[key]}
+		resp, err := c.env.GetSysctl(ctx, &agentv1.GetSysctlRequest{AgentId: agentID, Key: key})
+		switch {
+		case status.Code(err) == codes.NotFound:
+			check.Have = "unknown key"
+		case err != nil:
+			return nil, fmt.Errorf("sysctl %s: %w", key, err)
+		default:
+			check.Have = resp.Value
+			check.OK = strings.Join(strings.Fields(resp.Value), " ") == strings.Join(strings.Fields(check.Want), " ")
+		}
+		r.Checks = append(r.Checks, check)
+	}
+	for _, u := range p.Users {
+		resp, err := c.env.GetUser(ctx, &agentv1.GetUserRequest{AgentId: agentID, Name: u.Name})
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", u.Name, err)
+		}
+		r.Checks = append(r.Checks, diffUser(u, resp))
+	}
+	if p.Firewall != nil {
+		if err := c.diffFirewall(ctx, p, r); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Agent.Labels) > 0 {
+		if err := c.diffLabels(ctx, p, r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Apply brings the host of agentID in line with p, changing only what Diff
+// finds different, and returns the diff found afterwards. Changes go on
+// past a failure; the failures are returned together.
+func (c *Converger) Apply(ctx context.Context, p *Profile, agentID string) (*Report, error) {
+	before, err := c.Diff(ctx, p, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		errs    []error
+		changed []Check
+		rules   []Check
+		labels  []Check
+	)
+	change := func(check Check, fn func() error) {
+		if c.OnChange != nil {
+			c.OnChange(agentID, check)
+		}
+		if err := fn(); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", check.Kind, check.Name, err))
+			return
+		}
+		changed = append(changed, check)
+	}
+
+	for _, check := range before.Drift() {
+		switch check.Kind {
+		case KindPackage, KindDocker:
+			change(check, func() error {
+				_, err := c.env.InstallPackage(ctx, &agentv1.InstallPackageRequest{AgentId: agentID, PackageName: check.Name})
+				return err
+			})
+		case KindSysctl:
+			change(check, func() error {
+				_, err := c.env.SetSysctl(ctx, &agentv1.SetSysctlRequest{
+					AgentId: agentID,
+					Key:     check.Name,
+					Value:   check.Want,
+					Persist: true,
+					Force:   c.Force,
+				})
+				return err
+			})
+		case KindUser:
+			u := p.user(check.Name)
+			change(check, func() error {
+				_, err := c.env.EnsureUser(ctx, &agentv1.EnsureUserRequest{
+					AgentId:        agentID,
+					Name:           u.Name,
+					Shell:          u.Shell,
+					Groups:         u.Groups,
+					AuthorizedKeys: u.AuthorizedKeys,
+					Force:          c.Force,
+				})
+				return err
+			})
+		case KindFirewall:
+			if check.Name == firewallEnabled {
+				change(check, func() error {
+					_, err := c.firewall.Enable(ctx, &agentv1.EnableFirewallRequest{AgentId: agentID})
+					return err
+				})
+				continue
+			}
+			rules = append(rules, check)
+		case KindLabel:
+			labels = append(labels, check)
+		}
+	}
+	// Rules are replaced as a set, once for all that differ
+	if len(rules) > 0 {
+		for _, check := range rules {
+			if c.OnChange != nil {
+				c.OnChange(agentID, check)
+			}
+		}
+		if err := c.replaceRules(ctx, p, agentID); err != nil {
+			errs = append(errs, fmt.Errorf("firewall rules: %w", err))
+		} else {
+			changed = append(changed, rules...)
+		}
+	}
+	// Labels are set in one call
+	if len(labels) > 0 {
+		set := make(map[string]string, len(labels))
+		for _, check := range labels {
+			set[check.Name] = check.Want
+			if c.OnChange != nil {
+				c.OnChange(agentID, check)
+			}
+		}
+		if _, err := c.core.UpdateAgentLabels(ctx, &agentv1.UpdateAgentLabelsRequest{AgentId: agentID, Set: set}); err != nil {
+			errs = append(errs, fmt.Errorf("labels: %w", err))
+		} else {
+			changed = append(changed, labels...)
+		}
+	}
+
+	after, err := c.Diff(ctx, p, agentID)
+	if err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+	after.Changed = changed
+	return after, errors.Join(errs...)
+}
+
+func (c *Converger) diffPackages(ctx context.Context, p *Profile, r *Report) error {
+	resp, err := c.env.ListPackages(ctx, &agentv1.ListPackagesRequest{AgentId: r.Agent})
+	if err != nil {
+		return fmt.Errorf("list packages: %w", err)
+	}
+	installed := make(map[string]string, len(resp.Packages))
+	for _, pkg := range resp.Packages {
+		name, version, _ := strings.Cut(pkg, " ")
+		installed[name] = version
+	}
+
+	add := func(kind string, names []string) {
+		for _, name := range names {
+			version, ok := installed[name]
+			check := Check{Kind: kind, Name: name, Want: "installed", Have: "missing", OK: ok}
+			if ok {
+				check.Have = version
+			}
+			r.Checks = append(r.Checks, check)
+		}
+	}
+	add(KindPackage, p.Packages)
+	if p.Docker != nil {
+		add(KindDocker, p.Docker.Packages)
+	}
+	return nil
+}
+
+// diffUser compares an account with its spec: the shell and keys must
+// match and the groups be among the account's
+func diffUser(u UserSpec, have *agentv1.GetUserResponse) Check {
+	check := Check{Kind: KindUser, Name: u.Name, Want: describeUser(u.Shell, u.Groups, u.AuthorizedKeys)}
+	if !have.Exists {
+		check.Have = "missing"
+		return check
+	}
+
+	var problems []string
+	if u.Shell != "" && u.Shell != have.Shell {
+		problems = append(problems, "shell "+have.Shell)
+	}
+	if missing := subtract(u.Groups, have.Groups); len(missing) > 0 {
+		problems = append(problems, "not in "+strings.Join(missing, ","))
+	}
+	if len(u.AuthorizedKeys) > 0 && !sameSet(u.AuthorizedKeys, have.AuthorizedKeys) {
+		problems = append(problems, fmt.Sprintf("%d other key(s)", len(have.AuthorizedKeys)))
+	}
+	check.OK = len(problems) == 0
+	check.Have = strings.Join(problems, "; ")
+	if check.OK {
+		check.Have = check.Want
+	}
+	return check
+}
+
+func describeUser(shell string, groups, keys []string) string {
+	parts := []string{"present"}
+	if shell != "" {
+		parts = append(parts, "shell "+shell)
+	}
+	if len(groups) > 0 {
+		parts = append(parts, "in "+strings.Join(groups, ","))
+	}
+	if len(keys) > 0 {
+		parts = append(parts, fmt.Sprintf("%d key(s)", len(keys)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// firewallEnabled names the check that ufw is on
+const firewallEnabled = "enabled"
+
+// diffFirewall looks for each rule of the profile, by the set tag and key
+// in its comment, and for rules of the set the profile no longer has
+func (c *Converger) diffFirewall(ctx context.Context, p *Profile, r *Report) error {
+	resp, err := c.firewall.ListRules(ctx, &agentv1.ListFirewallRulesRequest{AgentId: r.Agent})
+	if err != nil {
+		return fmt.Errorf("list firewall rules: %w", err)
+	}
+
+	if p.Firewall.Enable {
+		check := Check{Kind: KindFirewall, Name: firewallEnabled, Want: "active", Have: "active", OK: true}
+		for _, line := range resp.Rules {
+			if strings.TrimSpace(line) == "Status: inactive" {
+				check.Have, check.OK = "inactive", false
+			}
+		}
+		r.Checks = append(r.Checks, check)
+	}
+
+	present := ruleKeys(resp.Rules, setTag(p.ruleSet()))
+	wanted := make(map[string]bool, len(p.Firewall.Rules))
+	for _, rule := range p.Firewall.Rules {
+		key := rule.key()
+		wanted[key] = true
+		check := Check{Kind: KindFirewall, Name: key, Want: "present", Have: "missing", OK: present[key]}
+		if check.OK {
+			check.Have = "present"
+		}
+		r.Checks = append(r.Checks, check)
+	}
+
+	var extra []string
+	for key := range present {
+		if !wanted[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		r.Checks = append(r.Checks, Check{Kind: KindFirewall, Name: key, Want: "absent", Have: "present"})
+	}
+	return nil
+}
+
+// replaceRules removes the profile's rule set and adds its rules again
+func (c *Converger) replaceRules(ctx context.Context, p *Profile, agentID string) error {
+	_, err := c.firewall.RemoveRuleSet(ctx, &agentv1.RemoveFirewallRuleSetRequest{AgentId: agentID, Name: p.ruleSet()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	for _, rule := range p.Firewall.Rules {
+		_, err := c.firewall.AddRule(ctx, &agentv1.AddFirewallRuleRequest{
+			AgentId: agentID,
+			Action:  rule.Action,
+			Proto:   rule.Proto,
+			FromIp:  rule.From,
+			ToPort:  int32(rule.Port),
+			Comment: rule.key(),
+			RuleSet: p.ruleSet(),
+		})
+		if err != nil {
+			return fmt.Errorf("add %s: %w", rule.key(), err)
+		}
+	}
+	return nil
+}
+
+// setTag is how the firewall plugin marks the rules of a set in their
+// comments
+func setTag(set string) string {
+	return "mandau:" + set
+}
+
+// ruleKeys finds the keys following tag in the listed rules. Backends
+// quote comments differently, so a key ends at a space, quote or '*'.
+func ruleKeys(lines []string, tag string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, line := range lines {
+		for rest := line; ; {
+			i := strings.Index(rest, tag+" ")
+			if i < 0 {
+				break
+			}
+			rest = rest[i+len(tag)+1:]
+			end := strings.IndexAny(rest, " \"'*")
+			if end < 0 {
+				end = len(rest)
+			}
+			if key := rest[:end]; key != "" {
+				keys[key] = true
+			}
+		}
+	}
+	return keys
+}
+
+func (c *Converger) diffLabels(ctx context.Context, p *Profile, r *Report) error {
+	resp, err := c.core.ListAgents(ctx, &agentv1.ListAgentsRequest{})
+	if err != nil {
+		return fmt.Errorf("list agents: %w", err)
+	}
+	var agent *agentv1.Agent
+	for _, a := range resp.Agents {
+		if a.Id == r.Agent {
+			agent = a
+		}
+	}
+	if agent == nil {
+		return fmt.Errorf("agent %s not found", r.Agent)
+	}
+
+	for _, key := range sortedKeys(p.Agent.Labels) {
+		have, ok := agent.Labels[key]
+		check := Check{Kind: KindLabel, Name: key, Want: p.Agent.Labels[key], Have: have}
+		if !ok {
+			check.Have = "unset"
+		}
+		check.OK = ok && have == check.Want
+		r.Checks = append(r.Checks, check)
+	}
+	return nil
+}
+
+// user returns the spec of the user named name
+func (p *Profile) user(name string) UserSpec {
+	for _, u := range p.Users {
+		if u.Name == name {
+			return u
+		}
+	}
+	return UserSpec{Name: name}
+}
+
+// subtract returns the items of a not in b
+func subtract(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sameSet(a, b []string) bool {
+	return len(subtract(a, b)) == 0 && len(subtract(b, a)) == 0
+}
@@ -0,0 +1,226 @@
+package hostprofile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeHost serves the host services a converger calls from in-memory state,
+// counting the changes made to it
+type fakeHost struct {
+	agentv1.HostEnvironmentServiceClient
+	agentv1.FirewallServiceClient
+	agentv1.CoreServiceClient
+
+	packages map[string]bool
+	sysctls  map[string]string
+	users    map[string]*agentv1.GetUserResponse
+	rules    []string
+	active   bool
+	labels   map[string]string
+	changes  int
+}
+
+func newFakeHost() *fakeHost {
+	return &fakeHost{
+		packages: map[string]bool{"curl": true},
+		sysctls:  map[string]string{"net.ipv4.ip_forward": "0"},
+		users:    make(map[string]*agentv1.GetUserResponse),
+		labels:   map[string]string{"zone": "a"},
+	}
+}
+
+func (h *fakeHost) ListPackages(ctx context.Context, req *agentv1.ListPackagesRequest, _ ...grpc.CallOption) (*agentv1.ListPackagesResponse, error) {
+	resp := &agentv1.ListPackagesResponse{}
+	for name := range h.packages {
+		resp.Packages = append(resp.Packages, name+" 1.0")
+	}
+	return resp, nil
+}
+
+func (h *fakeHost) InstallPackage(ctx context.Context, req *agentv1.InstallPackageRequest, _ ...grpc.CallOption) (*agentv1.InstallPackageResponse, error) {
+	h.changes++
+	h.packages[req.PackageName] = true
+	return &agentv1.InstallPackageResponse{Status: "success"}, nil
+}
+
+func (h *fakeHost) GetSysctl(ctx context.Context, req *agentv1.GetSysctlRequest, _ ...grpc.CallOption) (*agentv1.GetSysctlResponse, error) {
+	value, ok := h.sysctls[req.Key]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "get sysctl %s", req.Key)
+	}
+	return &agentv1.GetSysctlResponse{Value: value}, nil
+}
+
+func (h *fakeHost) SetSysctl(ctx context.Context, req *agentv1.SetSysctlRequest, _ ...grpc.CallOption) (*agentv1.SetSysctlResponse, error) {
+	if !req.Persist {
+		return nil, fmt.Errorf("%s not persisted", req.Key)
+	}
+	h.changes++
+	h.sysctls[req.Key] = req.Value
+	return &agentv1.SetSysctlResponse{Status: "success"}, nil
+}
+
+func (h *fakeHost) GetUser(ctx context.Context, req *agentv1.GetUserRequest, _ ...grpc.CallOption) (*agentv1.GetUserResponse, error) {
+	if u, ok := h.users[req.Name]; ok {
+		return u, nil
+	}
+	return &agentv1.GetUserResponse{}, nil
+}
+
+func (h *fakeHost) EnsureUser(ctx context.Context, req *agentv1.EnsureUserRequest, _ ...grpc.CallOption) (*agentv1.EnsureUserResponse, error) {
+	h.changes++
+	h.users[req.Name] = &agentv1.GetUserResponse{
+		Exists:         true,
+		Shell:          req.Shell,
+		Groups:         append([]string{req.Name}, req.Groups...),
+		AuthorizedKeys: req.AuthorizedKeys,
+	}
+	return &agentv1.EnsureUserResponse{Status: "success"}, nil
+}
+
+func (h *fakeHost) ListRules(ctx context.Context, req *agentv1.ListFirewallRulesRequest, _ ...grpc.CallOption) (*agentv1.ListFirewallRulesResponse, error) {
+	state := "Status: inactive"
+	if h.active {
+		state = "Status: active"
+	}
+	return &agentv1.ListFirewallRulesResponse{Rules: append([]string{state}, h.rules...)}, nil
+}
+
+func (h *fakeHost) AddRule(ctx context.Context, req *agentv1.AddFirewallRuleRequest, _ ...grpc.CallOption) (*agentv1.AddFirewallRuleResponse, error) {
+	h.changes++
+	h.rules = append(h.rules, fmt.Sprintf("[%d] %d/%s ALLOW IN # %s %s", len(h.rules)+1, req.ToPort, req.Proto, setTag(req.RuleSet), req.Comment))
+	return &agentv1.AddFirewallRuleResponse{Status: "success"}, nil
+}
+
+func (h *fakeHost) RemoveRuleSet(ctx context.Context, req *agentv1.RemoveFirewallRuleSetRequest, _ ...grpc.CallOption) (*agentv1.RemoveFirewallRuleSetResponse, error) {
+	var kept []string
+	for _, rule := range h.rules {
+		if !strings.Contains(rule, setTag(req.Name)+" ") {
+			kept = append(kept, rule)
+		}
+	}
+	removed := len(h.rules) - len(kept)
+	if removed == 0 {
+		return nil, status.Errorf(codes.NotFound, "no rules in set %s", req.Name)
+	}
+	h.changes++
+	h.rules = kept
+	return &agentv1.RemoveFirewallRuleSetResponse{Status: "success", Removed: int32(removed)}, nil
+}
+
+func (h *fakeHost) Enable(ctx context.Context, req *agentv1.EnableFirewallRequest, _ ...grpc.CallOption) (*agentv1.EnableFirewallResponse, error) {
+	h.changes++
+	h.active = true
+	return &agentv1.EnableFirewallResponse{Status: "success"}, nil
+}
+
+func (h *fakeHost) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest, _ ...grpc.CallOption) (*agentv1.ListAgentsResponse, error) {
+	return &agentv1.ListAgentsResponse{Agents: []*agentv1.Agent{{Id: "web-1", Labels: h.labels}}}, nil
+}
+
+func (h *fakeHost) UpdateAgentLabels(ctx context.Context, req *agentv1.UpdateAgentLabelsRequest, _ ...grpc.CallOption) (*agentv1.UpdateAgentLabelsResponse, error) {
+	h.changes++
+	for k, v := range req.Set {
+		h.labels[k] = v
+	}
+	return &agentv1.UpdateAgentLabelsResponse{}, nil
+}
+
+func TestApplyConverges(t *testing.T) {
+	p, err := Parse([]byte(golden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := newFakeHost()
+	c := NewConverger(host, host, host)
+	ctx := context.Background()
+
+	before, err := c.Diff(ctx, p, "web-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Compliant() || host.changes != 0 {
+		t.Fatalf("diff of a new host: compliant = %v, changes = %d", before.Compliant(), host.changes)
+	}
+	for _, check := range before.Checks {
+		if check.Kind == KindPackage && check.Name == "curl" && !check.OK {
+			t.Error("curl is installed but reported missing")
+		}
+	}
+
+	report, err := c.Apply(ctx, p, "web-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Compliant() {
+		t.Errorf("host drifts after apply: %+v", report.Drift())
+	}
+	if len(report.Changed) != len(before.Drift()) {
+		t.Errorf("apply changed %d items, want the %d that drifted", len(report.Changed), len(before.Drift()))
+	}
+	if host.labels["zone"] != "a" || host.labels["tier"] != "web" {
+		t.Errorf("labels = %v, want zone kept and tier set", host.labels)
+	}
+
+	// A second apply finds nothing to change
+	changes := host.changes
+	report, err = c.Apply(ctx, p, "web-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changed) != 0 || host.changes != changes {
+		t.Errorf("second apply changed %+v", report.Changed)
+	}
+}
+
+func TestDiffFindsFirewallDrift(t *testing.T) {
+	p, err := Parse([]byte(golden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := newFakeHost()
+	host.active = true
+	host.rules = []string{
+		"[1] 22/tcp ALLOW IN # mandau:profile-web allow-tcp-22-from-10.0.0.0/8",
+		"[2] 8080/tcp ALLOW IN # mandau:profile-web allow-tcp-8080",
+	}
+	c := NewConverger(host, host, host)
+
+	report, err := c.Diff(context.Background(), p, "web-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, check := range report.Checks {
+		if check.Kind == KindFirewall {
+			got[check.Name] = fmt.Sprintf("%s/%s/%v", check.Want, check.Have, check.OK)
+		}
+	}
+	want := map[string]string{
+		firewallEnabled:                "active/active/true",
+		"allow-tcp-22-from-10.0.0.0/8": "present/present/true",
+		"allow-tcp-443":                "present/missing/false",
+		"deny-udp-53":                  "present/missing/false",
+		"allow-tcp-8080":               "absent/present/false",
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %s, want %s", name, got[name], w)
+		}
+	}
+
+	if _, err := c.Apply(context.Background(), p, "web-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(host.rules) != 3 || strings.Contains(strings.Join(host.rules, "\n"), "8080") {
+		t.Errorf("rules after apply = %q", host.rules)
+	}
+}
@@ -0,0 +1,167 @@
+// Package hostprofile converges agent hosts on a profile: the packages,
+// kernel parameters, users, firewall baseline, Docker packages and agent
+// labels every host of one kind should have. Converging only changes what
+// differs from the profile, so applying it again changes nothing.
+package hostprofile
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDockerPackages are Docker's own packages, installed when a profile
+// asks for Docker without naming packages
+var defaultDockerPackages = []string{"docker-ce", "docker-ce-cli", "containerd.io", "docker-compose-plugin"}
+
+// validName keeps profile names usable as a firewall rule set name
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Profile describes a standard host
+type Profile struct {
+	Name     string            `yaml:"name"`
+	Packages []string          `yaml:"packages,omitempty"`
+	Sysctls  map[string]string `yaml:"sysctls,omitempty"` // Set now and persisted to /etc/sysctl.d
+	Users    []UserSpec        `yaml:"users,omitempty"`
+	Firewall *FirewallSpec     `yaml:"firewall,omitempty"`
+	Docker   *DockerSpec       `yaml:"docker,omitempty"`
+	Agent    AgentSpec         `yaml:"agent,omitempty"`
+}
+
+// UserSpec is a local account. Users are created and added to groups but
+// never removed from any.
+type UserSpec struct {
+	Name   string   `yaml:"name"`
+	Shell  string   `yaml:"shell,omitempty"`
+	Groups []string `yaml:"groups,omitempty"`
+	// AuthorizedKeys replace the user's authorized_keys when set
+	AuthorizedKeys []string `yaml:"authorized_keys,omitempty"`
+}
+
+// FirewallSpec is the firewall baseline. Its rules are kept as the rule set
+// "profile-<name>", which converging replaces as a whole when it differs.
+type FirewallSpec struct {
+	Enable bool           `yaml:"enable,omitempty"` // Switch ufw on; other backends are always on
+	Rules  []FirewallRule `yaml:"rules,omitempty"`
+}
+
+// FirewallRule opens, or closes, a port
+type FirewallRule struct {
+	Action string `yaml:"action,omitempty"` // allow (default), deny or reject
+	Port   int    `yaml:"port"`
+	Proto  string `yaml:"proto,omitempty"` // tcp (default) or udp
+	From   string `yaml:"from,omitempty"`  // Address or CIDR; empty for anywhere
+}
+
+// DockerSpec installs the Docker engine. The agent needs Docker to start,
+// so this matters for hosts provisioned before the agent or upgraded to
+// other packages.
+type DockerSpec struct {
+	Packages []string `yaml:"packages,omitempty"` // Default: Docker's own packages
+}
+
+// AgentSpec is what the core records about the agent
+type AgentSpec struct {
+	Labels map[string]string `yaml:"labels,omitempty"` // Set, leaving other labels alone
+}
+
+// Load reads and validates the profile in path
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read profile: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and validates a profile
+func Parse(data []byte) (*Profile, error) {
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse profile: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the profile is named and its users and firewall rules
+// are complete, filling in the defaults. Agents validate the values
+// themselves when the profile is applied.
+func (p *Profile) Validate() error {
+	if !validName.MatchString(p.Name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", p.Name)
+	}
+
+	seen := make(map[string]bool, len(p.Users))
+	for _, u := range p.Users {
+		switch {
+		case u.Name == "":
+			return fmt.Errorf("a user has no name")
+		case seen[u.Name]:
+			return fmt.Errorf("user %s is listed twice", u.Name)
+		}
+		seen[u.Name] = true
+	}
+
+	if p.Firewall != nil {
+		for i := range p.Firewall.Rules {
+			r := &p.Firewall.Rules[i]
+			if r.Action == "" {
+				r.Action = "allow"
+			}
+			if r.Proto == "" {
+				r.Proto = "tcp"
+			}
+			switch {
+			case r.Action != "allow" && r.Action != "deny" && r.Action != "reject":
+				return fmt.Errorf("firewall rule %d: invalid action %q: use allow, deny or reject", i+1, r.Action)
+			case r.Proto != "tcp" && r.Proto != "udp":
+				return fmt.Errorf("firewall rule %d: invalid proto %q: use tcp or udp", i+1, r.Proto)
+			case r.Port < 1 || r.Port > 65535:
+				return fmt.Errorf("firewall rule %d: invalid port %d", i+1, r.Port)
+			case strings.ContainsAny(r.From, " \t"):
+				return fmt.Errorf("firewall rule %d: invalid source %q", i+1, r.From)
+			}
+		}
+	}
+
+	if p.Docker != nil && len(p.Docker.Packages) == 0 {
+		p.Docker.Packages = defaultDockerPackages
+	}
+	return nil
+}
+
+// ruleSet is the firewall rule set the profile's rules are kept in
+func (p *Profile) ruleSet() string {
+	return "profile-" + p.Name
+}
+
+// key names a rule in its comment, so the rule is found again in the
+// backend's listing
+func (r FirewallRule) key() string {
+	key := fmt.Sprintf("%s-%s-%d", r.Action, r.Proto, r.Port)
+	if r.From != "" {
+		key += "-from-" + r.From
+	}
+	return key
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
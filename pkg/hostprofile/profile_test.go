@@ -0,0 +1,84 @@
+package hostprofile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const golden = `
+name: web
+packages: [curl, chrony]
+sysctls:
+  net.ipv4.ip_forward: "1"
+users:
+  - name: deploy
+    shell: /bin/bash
+    groups: [docker]
+    authorized_keys: ["ssh-ed25519 AAAA deploy@ci"]
+firewall:
+  enable: true
+  rules:
+    - port: 22
+      from: 10.0.0.0/8
+    - {port: 443}
+    - {port: 53, proto: udp, action: deny}
+docker: {}
+agent:
+  labels: {tier: web}
+`
+
+func TestParseDefaults(t *testing.T) {
+	p, err := Parse([]byte(golden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Docker.Packages, defaultDockerPackages) {
+		t.Errorf("docker packages = %v, want the defaults", p.Docker.Packages)
+	}
+	var keys []string
+	for _, r := range p.Firewall.Rules {
+		keys = append(keys, r.key())
+	}
+	want := []string{"allow-tcp-22-from-10.0.0.0/8", "allow-tcp-443", "deny-udp-53"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("rule keys = %v, want %v", keys, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		profile string
+		want    string
+	}{
+		"no name":         {"packages: [curl]\n", "invalid profile name"},
+		"name with space": {"name: web tier\n", "invalid profile name"},
+		"duplicate user":  {"name: a\nusers: [{name: deploy}, {name: deploy}]\n", "user deploy is listed twice"},
+		"unnamed user":    {"name: a\nusers: [{shell: /bin/sh}]\n", "a user has no name"},
+		"port":            {"name: a\nfirewall: {rules: [{port: 70000}]}\n", "invalid port 70000"},
+		"action":          {"name: a\nfirewall: {rules: [{port: 22, action: drop}]}\n", "invalid action"},
+		"proto":           {"name: a\nfirewall: {rules: [{port: 22, proto: icmp}]}\n", "invalid proto"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.profile))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Parse error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleKeys(t *testing.T) {
+	lines := []string{
+		"Status: active",
+		"[ 1] 22/tcp                     ALLOW IN    10.0.0.0/8                 # mandau:profile-web allow-tcp-22-from-10.0.0.0/8",
+		"ACCEPT     tcp  --  0.0.0.0/0  0.0.0.0/0  tcp dpt:443 /* mandau:profile-web allow-tcp-443 */",
+		`udp dport 53 drop comment "mandau:profile-web deny-udp-53" # handle 7`,
+		"[ 4] 80/tcp                     ALLOW IN    Anywhere                   # mandau:profile-webapp allow-tcp-80",
+	}
+	got := ruleKeys(lines, setTag("profile-web"))
+	want := map[string]bool{"allow-tcp-22-from-10.0.0.0/8": true, "allow-tcp-443": true, "deny-udp-53": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ruleKeys = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+// Package inventory writes fleet inventories for CMDB ingestion.
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Host is what an agent's host environment reports about the machine
+type Host struct {
+	Hostname     string `json:"hostname"`
+	OS           string `json:"os"`
+	Kernel       string `json:"kernel"`
+	Architecture string `json:"architecture"`
+	CPUCores     int    `json:"cpu_cores"`
+	MemoryMB     int64  `json:"memory_mb"`
+	DiskGB       int64  `json:"disk_gb"`
+}
+
+// Container is one container of a stack and the image it runs. Digests are
+// the image's registry digests; locally built images have none.
+type Container struct {
+	Name    string   `json:"name"`
+	State   string   `json:"state"`
+	Image   string   `json:"image"`
+	ImageID string   `json:"image_id,omitempty"`
+	Digests []string `json:"digests,omitempty"`
+}
+
+// Stack is one stack of an agent
+type Stack struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	State      string            `json:"state"`
+	Team       string            `json:"team,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Containers []Container       `json:"containers"`
+}
+
+// VirtualHost is an nginx virtual host; disabled ones are configured but
+// not served
+type VirtualHost struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Certificate is a certificate the agent's ACME client manages
+type Certificate struct {
+	Domain    string `json:"domain"`
+	Issuer    string `json:"issuer,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// Agent is everything one agent reported. Parts it does not serve are
+// empty; Errors lists the parts that could not be read, by part.
+type Agent struct {
+	ID           string            `json:"id"`
+	Hostname     string            `json:"hostname"`
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Groups       []string          `json:"groups,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	LastSeen     time.Time         `json:"last_seen"`
+	Host         *Host             `json:"host,omitempty"`
+	Stacks       []Stack           `json:"stacks,omitempty"`
+	VirtualHosts []VirtualHost     `json:"virtual_hosts,omitempty"`
+	DNSZones     []string          `json:"dns_zones,omitempty"`
+	Certificates []Certificate     `json:"certificates,omitempty"`
+	Errors       map[string]string `json:"errors,omitempty"`
+}
+
+// Inventory is the fleet at a point in time
+type Inventory struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Agents      []Agent   `json:"agents"`
+	// Errors lists agents that could not be reached, by agent ID; only their
+	// registration is included
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// WriteJSON writes the inventory as one nested document
+func (inv *Inventory) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+// WriteCSV writes one row per configuration item: agents, stacks,
+// containers, virtual hosts, DNS zones and certificates, told apart by kind.
+// Containers carry their stack in parent; attributes that only some kinds
+// have are joined into details as key=value pairs.
+func (inv *Inventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"generated_at", "agent_id", "kind", "name", "parent", "namespace", "state",
+		"image", "digests", "expires_at", "labels", "details",
+	})
+
+	at := inv.GeneratedAt.UTC().Format(time.RFC3339)
+	row := func(agentID, kind, name string, fields map[string]string, labels, details map[string]string) {
+		cw.Write([]string{
+			at, agentID, kind, name, fields["parent"], fields["namespace"], fields["state"],
+			fields["image"], fields["digests"], fields["expires_at"], joinPairs(labels), joinPairs(details),
+		})
+	}
+
+	for _, a := range inv.Agents {
+		details := map[string]string{}
+		if h := a.Host; h != nil {
+			details = map[string]string{
+				"hostname":     h.Hostname,
+				"os":           h.OS,
+				"kernel":       h.Kernel,
+				"architecture": h.Architecture,
+				"cpu_cores":    strconv.Itoa(h.CPUCores),
+				"memory_mb":    strconv.FormatInt(h.MemoryMB, 10),
+				"disk_gb":      strconv.FormatInt(h.DiskGB, 10),
+			}
+		}
+		if len(a.Groups) > 0 {
+			details["groups"] = strings.Join(a.Groups, ",")
+		}
+		for part, msg := range a.Errors {
+			details["error."+part] = msg
+		}
+		row(a.ID, "agent", a.Hostname, map[string]string{"state": a.Status}, a.Labels, details)
+
+		for _, s := range a.Stacks {
+			row(a.ID, "stack", s.Name, map[string]string{"namespace": s.Namespace, "state": s.State},
+				s.Labels, map[string]string{"team": s.Team, "owner": s.Owner})
+			for _, c := range s.Containers {
+				row(a.ID, "container", c.Name, map[string]string{
+					"parent":    s.Name,
+					"namespace": s.Namespace,
+					"state":     c.State,
+					"image":     c.Image,
+					"digests":   strings.Join(c.Digests, ";"),
+				}, nil, map[string]string{"image_id": c.ImageID})
+			}
+		}
+		for _, v := range a.VirtualHosts {
+			state := "disabled"
+			if v.Enabled {
+				state = "enabled"
+			}
+			row(a.ID, "virtual_host", v.Name, map[string]string{"state": state}, nil, nil)
+		}
+		for _, zone := range a.DNSZones {
+			row(a.ID, "dns_zone", zone, nil, nil, nil)
+		}
+		for _, c := range a.Certificates {
+			row(a.ID, "certificate", c.Domain, map[string]string{"expires_at": c.ExpiresAt}, nil,
+				map[string]string{"issuer": c.Issuer})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write encodes the inventory in format "json" or "csv"
+func (inv *Inventory) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return inv.WriteJSON(w)
+	case "csv":
+		return inv.WriteCSV(w)
+	default:
+		return fmt.Errorf("unknown inventory format %q (want json or csv)", format)
+	}
+}
+
+// joinPairs joins the non-empty pairs as key=value, sorted by key
+func joinPairs(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		if v != "" {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,73 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	inv := &Inventory{
+		GeneratedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Agents: []Agent{{
+			ID:       "web-1",
+			Hostname: "web-1.example.com",
+			Status:   "online",
+			Labels:   map[string]string{"role": "web", "env": "prod"},
+			Host:     &Host{OS: "Debian 12", CPUCores: 4},
+			Stacks: []Stack{{
+				Name:      "shop",
+				Namespace: "default",
+				State:     "STACK_STATE_RUNNING",
+				Containers: []Container{{
+					Name:    "shop-web-1",
+					State:   "running",
+					Image:   "nginx:1.27",
+					ImageID: "sha256:abc",
+					Digests: []string{"nginx@sha256:def"},
+				}},
+			}},
+			VirtualHosts: []VirtualHost{{Name: "shop.example.com", Enabled: true}},
+			DNSZones:     []string{"example.com"},
+			Certificates: []Certificate{{Domain: "shop.example.com", ExpiresAt: "2026-05-30"}},
+			Errors:       map[string]string{"certificates": "certbot not found"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("got %d rows, want a header and six items:\n%v", len(rows), rows)
+	}
+
+	kinds := make([]string, 0, len(rows)-1)
+	for _, r := range rows[1:] {
+		kinds = append(kinds, r[2])
+	}
+	if got := strings.Join(kinds, ","); got != "agent,stack,container,virtual_host,dns_zone,certificate" {
+		t.Errorf("kinds = %s", got)
+	}
+
+	agent, container, cert := rows[1], rows[3], rows[6]
+	if agent[10] != "env=prod;role=web" || !strings.Contains(agent[11], "error.certificates=certbot not found") || !strings.Contains(agent[11], "os=Debian 12") {
+		t.Errorf("agent row = %q", agent)
+	}
+	if container[4] != "shop" || container[7] != "nginx:1.27" || container[8] != "nginx@sha256:def" || container[11] != "image_id=sha256:abc" {
+		t.Errorf("container row = %q", container)
+	}
+	if cert[9] != "2026-05-30" {
+		t.Errorf("certificate row = %q", cert)
+	}
+
+	if err := inv.Write(&buf, "xml"); err == nil {
+		t.Error("Write accepted format xml")
+	}
+}
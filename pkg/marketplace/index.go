@@ -0,0 +1,260 @@
+// Package marketplace installs agent plugins from a signed index. The core
+// hosts the index, a list of plugins with their releases and a download URL
+// and checksum per platform, signed with an ed25519 key. Agents trust only
+// indexes signed by keys in their config and only binaries whose checksum
+// the index vouches for.
+package marketplace
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// ErrUntrusted is returned for indexes not signed by a trusted key
+var ErrUntrusted = errors.New("plugin index is not signed by a trusted key")
+
+// Index lists the plugins on offer
+type Index struct {
+	Plugins   []Plugin  `json:"plugins" yaml:"plugins"`
+	Published time.Time `json:"published,omitempty" yaml:"published,omitempty"`
+}
+
+// Plugin is one plugin and its releases
+type Plugin struct {
+	Name        string    `json:"name" yaml:"name"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Releases    []Release `json:"releases" yaml:"releases"`
+}
+
+// Release is one version of a plugin, built for one or more platforms.
+// Agents confine the plugin to the permissions its release declares.
+type Release struct {
+	Version     string             `json:"version" yaml:"version"`
+	Permissions plugin.Permissions `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Artifacts   []Artifact         `json:"artifacts" yaml:"artifacts"`
+}
+
+// Artifact is a plugin binary for one platform
+type Artifact struct {
+	OS     string `json:"os" yaml:"os"`     // GOOS, e.g. linux
+	Arch   string `json:"arch" yaml:"arch"` // GOARCH, e.g. amd64
+	URL    string `json:"url" yaml:"url"`
+	SHA256 string `json:"sha256" yaml:"sha256"` // Hex
+}
+
+// SignedIndex is an index as published: its exact JSON bytes and a
+// signature over them
+type SignedIndex struct {
+	Index     json.RawMessage `json:"index"`
+	KeyID     string          `json:"key_id"`
+	Signature string          `json:"signature"` // Base64 ed25519
+}
+
+// GenerateKey returns a new signing key pair, base64 encoded
+func GenerateKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// KeyID names a public key in signed indexes: the first 8 bytes of its
+// SHA-256, in hex
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sign validates index and signs it with privateKey, as returned by
+// GenerateKey. The result is the document the core hosts.
+func Sign(index *Index, privateKey string) ([]byte, error) {
+	if err := index.Validate(); err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signing key: want a base64 ed25519 private key")
+	}
+	priv := ed25519.PrivateKey(raw)
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	// Not indented: that would reformat the signed bytes
+	return json.Marshal(SignedIndex{
+		Index:     data,
+		KeyID:     KeyID(priv.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)),
+	})
+}
+
+// Parse reads a signed index without checking its signature, for showing
+// what it offers
+func Parse(data []byte) (*Index, *SignedIndex, error) {
+	var signed SignedIndex
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, nil, fmt.Errorf("parse plugin index: %w", err)
+	}
+	var index Index
+	if err := json.Unmarshal(signed.Index, &index); err != nil {
+		return nil, nil, fmt.Errorf("parse plugin index: %w", err)
+	}
+	if err := index.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &index, &signed, nil
+}
+
+// Verify parses a signed index and checks it was signed by one of
+// trustedKeys, base64 encoded ed25519 public keys
+func Verify(data []byte, trustedKeys []string) (*Index, error) {
+	index, signed, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signed.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrUntrusted)
+	}
+
+	for _, key := range trustedKeys {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid trusted key %q: want a base64 ed25519 public key", key)
+		}
+		if ed25519.Verify(ed25519.PublicKey(raw), signed.Index, sig) {
+			return index, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (key %s)", ErrUntrusted, signed.KeyID)
+}
+
+// Validate checks that plugins and releases are named once and that every
+// artifact has a platform, a URL and a SHA-256 checksum
+func (idx *Index) Validate() error {
+	seen := make(map[string]bool, len(idx.Plugins))
+	for _, p := range idx.Plugins {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("a plugin has no name")
+		case seen[p.Name]:
+			return fmt.Errorf("plugin %s is listed twice", p.Name)
+		case strings.ContainsAny(p.Name, `/\`) || strings.HasPrefix(p.Name, "."):
+			return fmt.Errorf("invalid plugin name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		versions := make(map[string]bool, len(p.Releases))
+		for _, r := range p.Releases {
+			switch {
+			case r.Version == "":
+				return fmt.Errorf("plugin %s: a release has no version", p.Name)
+			case versions[r.Version]:
+				return fmt.Errorf("plugin %s: version %s is listed twice", p.Name, r.Version)
+			case strings.ContainsAny(r.Version, `/\`) || strings.HasPrefix(r.Version, "."):
+				return fmt.Errorf("plugin %s: invalid version %q", p.Name, r.Version)
+			}
+			versions[r.Version] = true
+
+			for _, a := range r.Artifacts {
+				if a.OS == "" || a.Arch == "" || a.URL == "" {
+					return fmt.Errorf("plugin %s %s: artifacts need os, arch and url", p.Name, r.Version)
+				}
+				if sum, err := hex.DecodeString(a.SHA256); err != nil || len(sum) != sha256.Size {
+					return fmt.Errorf("plugin %s %s: invalid sha256 for %s/%s", p.Name, r.Version, a.OS, a.Arch)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the release of a plugin and its artifact for a platform.
+// An empty version picks the newest release built for the platform.
+func (idx *Index) Find(name, version, goos, goarch string) (*Release, *Artifact, error) {
+	for i := range idx.Plugins {
+		p := &idx.Plugins[i]
+		if p.Name != name {
+			continue
+		}
+
+		var best *Release
+		var bestArtifact *Artifact
+		for j := range p.Releases {
+			r := &p.Releases[j]
+			if version != "" && r.Version != version {
+				continue
+			}
+			a := r.artifact(goos, goarch)
+			if a == nil {
+				continue
+			}
+			if best == nil || CompareVersions(r.Version, best.Version) > 0 {
+				best, bestArtifact = r, a
+			}
+		}
+		if best == nil {
+			if version != "" {
+				return nil, nil, fmt.Errorf("plugin %s has no release %s for %s/%s", name, version, goos, goarch)
+			}
+			return nil, nil, fmt.Errorf("plugin %s has no release for %s/%s", name, goos, goarch)
+		}
+		return best, bestArtifact, nil
+	}
+	return nil, nil, fmt.Errorf("plugin %s is not in the index", name)
+}
+
+func (r *Release) artifact(goos, goarch string) *Artifact {
+	for i := range r.Artifacts {
+		if r.Artifacts[i].OS == goos && r.Artifacts[i].Arch == goarch {
+			return &r.Artifacts[i]
+		}
+	}
+	return nil
+}
+
+// CompareVersions orders dotted versions such as "1.10.0" and "v1.9" by
+// their numeric parts; parts that are not numbers compare as text
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0" // 1.2 is 1.2.0
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
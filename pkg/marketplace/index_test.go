@@ -0,0 +1,112 @@
+package marketplace
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testIndex() *Index {
+	return &Index{Plugins: []Plugin{{
+		Name: "vault-secrets",
+		Releases: []Release{
+			{Version: "1.9.0", Artifacts: []Artifact{{OS: "linux", Arch: "amd64", URL: "https://example.com/1.9.0.so", SHA256: strings.Repeat("a", 64)}}},
+			{Version: "1.10.0", Artifacts: []Artifact{{OS: "linux", Arch: "amd64", URL: "https://example.com/1.10.0.so", SHA256: strings.Repeat("b", 64)}}},
+			{Version: "2.0.0", Artifacts: []Artifact{{OS: "linux", Arch: "arm64", URL: "https://example.com/2.0.0.so", SHA256: strings.Repeat("c", 64)}}},
+		},
+	}}}
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := Sign(testIndex(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := Verify(signed, []string{otherPub, pub})
+	if err != nil {
+		t.Fatalf("Verify with the signing key: %v", err)
+	}
+	if len(index.Plugins) != 1 || index.Plugins[0].Name != "vault-secrets" {
+		t.Errorf("verified index = %+v", index)
+	}
+
+	if _, err := Verify(signed, []string{otherPub}); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("Verify with another key: err = %v, want ErrUntrusted", err)
+	}
+
+	tampered := []byte(strings.Replace(string(signed), "1.9.0", "1.9.1", 1))
+	if _, err := Verify(tampered, []string{pub}); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("Verify tampered index: err = %v, want ErrUntrusted", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Index)
+	}{
+		{"duplicate plugin", func(idx *Index) { idx.Plugins = append(idx.Plugins, idx.Plugins[0]) }},
+		{"path in name", func(idx *Index) { idx.Plugins[0].Name = "../etc" }},
+		{"duplicate version", func(idx *Index) { idx.Plugins[0].Releases[1].Version = "1.9.0" }},
+		{"bad checksum", func(idx *Index) { idx.Plugins[0].Releases[0].Artifacts[0].SHA256 = "abc" }},
+		{"no url", func(idx *Index) { idx.Plugins[0].Releases[0].Artifacts[0].URL = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := testIndex()
+			tt.modify(idx)
+			if err := idx.Validate(); err == nil {
+				t.Error("Validate() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	idx := testIndex()
+
+	release, artifact, err := idx.Find("vault-secrets", "", "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != "1.10.0" || !strings.HasSuffix(artifact.URL, "1.10.0.so") {
+		t.Errorf("newest linux/amd64 release = %s %s, want 1.10.0", release.Version, artifact.URL)
+	}
+
+	if release, _, err := idx.Find("vault-secrets", "1.9.0", "linux", "amd64"); err != nil || release.Version != "1.9.0" {
+		t.Errorf("Find 1.9.0 = %v, %v", release, err)
+	}
+	if _, _, err := idx.Find("vault-secrets", "2.0.0", "linux", "amd64"); err == nil {
+		t.Error("Find 2.0.0 for linux/amd64 succeeded; it has no build for it")
+	}
+	if _, _, err := idx.Find("missing", "", "linux", "amd64"); err == nil {
+		t.Error("Find of an unknown plugin succeeded")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.2.0", "1.2.1", -1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
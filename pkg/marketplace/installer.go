@@ -0,0 +1,235 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// DefaultInstallDir holds installed plugins unless configured otherwise
+const DefaultInstallDir = "/var/lib/mandau/plugins"
+
+// maxArtifactSize bounds plugin downloads
+const maxArtifactSize = 256 << 20
+
+// inventoryFile records the installed plugins in the install dir
+const inventoryFile = "installed.json"
+
+// ErrDisabled is returned by installers that trust no index key
+var ErrDisabled = errors.New("plugin installation is disabled on this agent (plugins.marketplace.trusted_keys)")
+
+// Installed is a plugin installed on this host
+type Installed struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	SHA256      string    `json:"sha256"`
+	Path        string    `json:"path"`
+	Enabled     bool      `json:"enabled"`
+	InstalledAt time.Time `json:"installed_at"`
+	// Permissions declared by the release in the signed index
+	Permissions plugin.Permissions `json:"permissions"`
+}
+
+// Installer downloads plugins from signed indexes into a directory, one
+// subdirectory per plugin and version, and keeps an inventory of them
+type Installer struct {
+	mu          sync.Mutex
+	dir         string
+	trustedKeys []string
+	client      *http.Client
+}
+
+// NewInstaller installs into dir, DefaultInstallDir when empty, from
+// indexes signed by one of trustedKeys. Without keys it only reads the
+// inventory.
+func NewInstaller(dir string, trustedKeys []string) *Installer {
+	if dir == "" {
+		dir = DefaultInstallDir
+	}
+	return &Installer{
+		dir:         dir,
+		trustedKeys: trustedKeys,
+		client:      &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// Install verifies signedIndex, downloads the release of name for this
+// platform, newest when version is empty, checks it against the index's
+// checksum and records it enabled. A plugin installed again is replaced in
+// the inventory; the files of other versions are kept.
+func (i *Installer) Install(ctx context.Context, signedIndex []byte, name, version string) (*Installed, error) {
+	if len(i.trustedKeys) == 0 {
+		return nil, ErrDisabled
+	}
+	index, err := Verify(signedIndex, i.trustedKeys)
+	if err != nil {
+		return nil, err
+	}
+	release, artifact, err := index.Find(name, version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	dir := filepath.Join(i.dir, name, release.Version)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create plugin dir: %w", err)
+	}
+	path := filepath.Join(dir, name+".so")
+	if err := i.download(ctx, artifact, path); err != nil {
+		return nil, err
+	}
+
+	installed := Installed{
+		Name:        name,
+		Version:     release.Version,
+		SHA256:      artifact.SHA256,
+		Path:        path,
+		Enabled:     true,
+		InstalledAt: time.Now().UTC(),
+		Permissions: release.Permissions,
+	}
+	inventory, err := i.read()
+	if err != nil {
+		return nil, err
+	}
+	replaced := false
+	for j := range inventory {
+		if inventory[j].Name == name {
+			inventory[j], replaced = installed, true
+		}
+	}
+	if !replaced {
+		inventory = append(inventory, installed)
+	}
+	if err := i.write(inventory); err != nil {
+		return nil, err
+	}
+	return &installed, nil
+}
+
+// SetEnabled marks an installed plugin enabled or disabled. Disabled
+// plugins stay installed but are not loaded.
+func (i *Installer) SetEnabled(name string, enabled bool) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	inventory, err := i.read()
+	if err != nil {
+		return err
+	}
+	for j := range inventory {
+		if inventory[j].Name == name {
+			inventory[j].Enabled = enabled
+			return i.write(inventory)
+		}
+	}
+	return fmt.Errorf("plugin %s is not installed", name)
+}
+
+// Inventory returns the installed plugins sorted by name
+func (i *Installer) Inventory() ([]Installed, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.read()
+}
+
+// download fetches an artifact to path, keeping it only if its checksum
+// matches the index
+func (i *Installer) download(ctx context.Context, artifact *Artifact, path string) error {
+	u, err := url.Parse(artifact.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("unsupported plugin url %q: want http or https", artifact.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download plugin: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download plugin: %s returned %s", artifact.URL, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return fmt.Errorf("download plugin: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(resp.Body, maxArtifactSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download plugin: %w", err)
+	}
+	if n > maxArtifactSize {
+		return fmt.Errorf("download plugin: larger than %d bytes", maxArtifactSize)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != artifact.SHA256 {
+		return fmt.Errorf("plugin checksum mismatch: got %s, the index lists %s", sum, artifact.SHA256)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("install plugin: %w", err)
+	}
+	return nil
+}
+
+// read returns the inventory; callers hold the lock
+func (i *Installer) read() ([]Installed, error) {
+	data, err := os.ReadFile(filepath.Join(i.dir, inventoryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read plugin inventory: %w", err)
+	}
+	var inventory []Installed
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("parse plugin inventory: %w", err)
+	}
+	sort.Slice(inventory, func(a, b int) bool { return inventory[a].Name < inventory[b].Name })
+	return inventory, nil
+}
+
+// write replaces the inventory; callers hold the lock
+func (i *Installer) write(inventory []Installed) error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(i.dir, 0700); err != nil {
+		return fmt.Errorf("create plugin dir: %w", err)
+	}
+	tmp := filepath.Join(i.dir, inventoryFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write plugin inventory: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(i.dir, inventoryFile)); err != nil {
+		return fmt.Errorf("write plugin inventory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// serveIndex serves binary and returns an index offering it for this
+// platform, signed with a new key, and that key
+func serveIndex(t *testing.T, binary []byte, checksum string) (signed []byte, pub string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+	t.Cleanup(srv.Close)
+
+	if checksum == "" {
+		sum := sha256.Sum256(binary)
+		checksum = hex.EncodeToString(sum[:])
+	}
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err = Sign(&Index{Plugins: []Plugin{{
+		Name: "metrics",
+		Releases: []Release{{Version: "1.0.0", Permissions: plugin.Permissions{Network: []string{"*:9100"}}, Artifacts: []Artifact{{
+			OS: runtime.GOOS, Arch: runtime.GOARCH, URL: srv.URL + "/metrics.so", SHA256: checksum,
+		}}}},
+	}}}, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed, pub
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("plugin binary")
+	signed, pub := serveIndex(t, binary, "")
+	dir := t.TempDir()
+
+	installed, err := NewInstaller(dir, []string{pub}).Install(context.Background(), signed, "metrics", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if installed.Version != "1.0.0" || !installed.Enabled || len(installed.Permissions.Network) != 1 {
+		t.Errorf("installed = %+v", installed)
+	}
+	data, err := os.ReadFile(installed.Path)
+	if err != nil || string(data) != string(binary) {
+		t.Errorf("installed binary = %q, %v", data, err)
+	}
+
+	// A new installer reads the inventory back
+	inventory, err := NewInstaller(dir, nil).Inventory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inventory) != 1 || inventory[0].Name != "metrics" || inventory[0].Path != installed.Path {
+		t.Errorf("inventory = %+v", inventory)
+	}
+}
+
+func TestInstallRejects(t *testing.T) {
+	signed, pub := serveIndex(t, []byte("tampered binary"), strings.Repeat("0", 64))
+	otherPub, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := NewInstaller(t.TempDir(), nil).Install(ctx, signed, "metrics", ""); !errors.Is(err, ErrDisabled) {
+		t.Errorf("install without trusted keys: err = %v, want ErrDisabled", err)
+	}
+	if _, err := NewInstaller(t.TempDir(), []string{otherPub}).Install(ctx, signed, "metrics", ""); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("install from an untrusted index: err = %v, want ErrUntrusted", err)
+	}
+
+	dir := t.TempDir()
+	installer := NewInstaller(dir, []string{pub})
+	if _, err := installer.Install(ctx, signed, "metrics", ""); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("install with a wrong checksum: err = %v", err)
+	}
+	if inventory, _ := installer.Inventory(); len(inventory) != 0 {
+		t.Errorf("inventory after a failed install = %+v", inventory)
+	}
+}
@@ -0,0 +1,159 @@
+// Package obligation enforces the obligations a policy attaches to a call,
+// and lets clients tell which ones to satisfy before retrying.
+package obligation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ReasonHeader carries the justification for a call
+	ReasonHeader = "x-mandau-reason"
+	// MFAHeader carries the token an MFA plugin verifies
+	MFAHeader = "x-mandau-mfa"
+
+	// violationType marks the precondition violations raised here
+	violationType = "OBLIGATION"
+)
+
+// DefaultMFAMaxAge is how recent a second factor must be when require_mfa
+// gives no age
+const DefaultMFAMaxAge = 15 * time.Minute
+
+// Enforcer checks obligations against what the caller sent
+type Enforcer struct {
+	Recording bool             // Whether calls are recorded by an audit plugin
+	MFA       plugin.MFAPlugin // Nil when no MFA plugin is loaded
+	Now       func() time.Time // Defaults to time.Now
+}
+
+// Check returns a FailedPrecondition status naming every obligation of
+// decision the call in ctx does not meet, or nil. Unknown obligations are
+// left to whoever understands them.
+func (e *Enforcer) Check(ctx context.Context, identity *plugin.Identity, decision *plugin.PolicyDecision) error {
+	if decision == nil {
+		return nil
+	}
+
+	failure := &errdetails.PreconditionFailure{}
+	unmet := func(obligation, description string) {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        violationType,
+			Subject:     obligation,
+			Description: description,
+		})
+	}
+
+	for _, o := range decision.Obligations {
+		name, value, _ := strings.Cut(o, "=")
+		switch name {
+		case plugin.ObligationReason:
+			if Reason(ctx) == "" {
+				unmet(name, "a reason is required")
+			}
+		case plugin.ObligationRecording:
+			if !e.Recording {
+				unmet(name, "the call must be recorded but no audit plugin is loaded")
+			}
+		case plugin.ObligationMFA:
+			if err := e.checkMFA(ctx, identity, value); err != nil {
+				unmet(name, err.Error())
+			}
+		}
+	}
+
+	if len(failure.Violations) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(failure.Violations))
+	for i, v := range failure.Violations {
+		descriptions[i] = v.Description
+	}
+	st := status.New(codes.FailedPrecondition, "policy obligations not met: "+strings.Join(descriptions, "; "))
+	if detailed, err := st.WithDetails(failure); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func (e *Enforcer) checkMFA(ctx context.Context, identity *plugin.Identity, maxAge string) error {
+	age := DefaultMFAMaxAge
+	if maxAge != "" {
+		d, err := time.ParseDuration(maxAge)
+		if err != nil {
+			return fmt.Errorf("invalid MFA age %q in policy", maxAge)
+		}
+		age = d
+	}
+
+	if e.MFA == nil {
+		return fmt.Errorf("MFA is required but no MFA plugin is loaded")
+	}
+	token := header(ctx, MFAHeader)
+	if token == "" {
+		return fmt.Errorf("MFA is required")
+	}
+	at, err := e.MFA.VerifyMFA(ctx, identity, token)
+	if err != nil {
+		return fmt.Errorf("MFA failed: %v", err)
+	}
+
+	now := time.Now
+	if e.Now != nil {
+		now = e.Now
+	}
+	if now().Sub(at) > age {
+		return fmt.Errorf("MFA is older than %s", age)
+	}
+	return nil
+}
+
+// Reason returns the justification sent with the call in ctx
+func Reason(ctx context.Context) string {
+	return header(ctx, ReasonHeader)
+}
+
+func header(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
+
+// Unmet returns the obligations err reports as not met, or nil when err is
+// not an obligation failure
+func Unmet(err error) []string {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return nil
+	}
+
+	var names []string
+	for _, detail := range st.Details() {
+		failure, ok := detail.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, v := range failure.Violations {
+			if v.Type == violationType {
+				names = append(names, v.Subject)
+			}
+		}
+	}
+	return names
+}
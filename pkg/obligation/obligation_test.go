@@ -0,0 +1,147 @@
+package obligation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeMFA struct {
+	at  time.Time
+	err error
+}
+
+func (f *fakeMFA) Name() string                                       { return "fake-mfa" }
+func (f *fakeMFA) Version() string                                    { return "test" }
+func (f *fakeMFA) Init(context.Context, map[string]interface{}) error { return nil }
+func (f *fakeMFA) Shutdown(context.Context) error                     { return nil }
+func (f *fakeMFA) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityMFA}
+}
+
+func (f *fakeMFA) VerifyMFA(ctx context.Context, identity *plugin.Identity, token string) (time.Time, error) {
+	return f.at, f.err
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		obligations []string
+		headers     []string
+		enforcer    Enforcer
+		wantUnmet   []string
+	}{
+		{
+			name: "no obligations",
+		},
+		{
+			name:        "reason missing",
+			obligations: []string{plugin.ObligationReason},
+			wantUnmet:   []string{plugin.ObligationReason},
+		},
+		{
+			name:        "reason given",
+			obligations: []string{plugin.ObligationReason},
+			headers:     []string{ReasonHeader, "INC-42 restart"},
+		},
+		{
+			name:        "recording off",
+			obligations: []string{plugin.ObligationRecording},
+			wantUnmet:   []string{plugin.ObligationRecording},
+		},
+		{
+			name:        "recording on",
+			obligations: []string{plugin.ObligationRecording},
+			enforcer:    Enforcer{Recording: true},
+		},
+		{
+			name:        "mfa without plugin",
+			obligations: []string{plugin.ObligationMFA},
+			headers:     []string{MFAHeader, "token"},
+			wantUnmet:   []string{plugin.ObligationMFA},
+		},
+		{
+			name:        "mfa token missing",
+			obligations: []string{plugin.ObligationMFA},
+			enforcer:    Enforcer{MFA: &fakeMFA{at: now}},
+			wantUnmet:   []string{plugin.ObligationMFA},
+		},
+		{
+			name:        "mfa recent",
+			obligations: []string{plugin.ObligationMFA + "=5m"},
+			headers:     []string{MFAHeader, "token"},
+			enforcer:    Enforcer{MFA: &fakeMFA{at: now.Add(-time.Minute)}},
+		},
+		{
+			name:        "mfa too old",
+			obligations: []string{plugin.ObligationMFA + "=5m"},
+			headers:     []string{MFAHeader, "token"},
+			enforcer:    Enforcer{MFA: &fakeMFA{at: now.Add(-10 * time.Minute)}},
+			wantUnmet:   []string{plugin.ObligationMFA},
+		},
+		{
+			name:        "mfa rejected",
+			obligations: []string{plugin.ObligationMFA},
+			headers:     []string{MFAHeader, "token"},
+			enforcer:    Enforcer{MFA: &fakeMFA{err: errors.New("bad token")}},
+			wantUnmet:   []string{plugin.ObligationMFA},
+		},
+		{
+			name:        "unknown obligations are left alone",
+			obligations: []string{plugin.ObligationExecTimeout + "=15m", "custom"},
+		},
+		{
+			name:        "every unmet obligation reported",
+			obligations: []string{plugin.ObligationReason, plugin.ObligationRecording},
+			wantUnmet:   []string{plugin.ObligationReason, plugin.ObligationRecording},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tt.headers...))
+			e := tt.enforcer
+			e.Now = func() time.Time { return now }
+
+			err := e.Check(ctx, &plugin.Identity{UserID: "ops"}, &plugin.PolicyDecision{
+				Allowed:     true,
+				Obligations: tt.obligations,
+			})
+
+			if len(tt.wantUnmet) == 0 {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Fatalf("Check() = %v, want FailedPrecondition", err)
+			}
+			if got := Unmet(err); !reflect.DeepEqual(got, tt.wantUnmet) {
+				t.Errorf("Unmet() = %v, want %v", got, tt.wantUnmet)
+			}
+		})
+	}
+}
+
+func TestUnmetIgnoresOtherErrors(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		errors.New("plain"),
+		status.Error(codes.FailedPrecondition, "quota exceeded"),
+		status.Error(codes.PermissionDenied, "denied"),
+	} {
+		if got := Unmet(err); got != nil {
+			t.Errorf("Unmet(%v) = %v, want nil", err, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/requestid"
+)
+
+// Host change audit modes, set with plugins.host_audit
+const (
+	HostAuditAll      = "all" // Commands and file changes; the default
+	HostAuditCommands = "commands"
+	HostAuditFiles    = "files"
+	HostAuditOff      = "off"
+)
+
+// HostAuditor records the host changes plugins make through their
+// sandboxes: every command run and every file written, created or removed.
+// Entries name the plugin as the identity, since it is the plugin that acts;
+// the caller and request ID of the call that asked for the change are
+// recorded next to it when the sandbox was given that call's context.
+type HostAuditor struct {
+	commands bool
+	files    bool
+	agentID  string
+	record   func(context.Context, *AuditEntry)
+}
+
+// NewHostAuditor records the changes mode selects through record, usually
+// Registry.AuditAll. An empty mode means HostAuditAll.
+func NewHostAuditor(mode, agentID string, record func(context.Context, *AuditEntry)) (*HostAuditor, error) {
+	a := &HostAuditor{agentID: agentID, record: record}
+	switch mode {
+	case "", HostAuditAll:
+		a.commands, a.files = true, true
+	case HostAuditCommands:
+		a.commands = true
+	case HostAuditFiles:
+		a.files = true
+	case HostAuditOff:
+	default:
+		return nil, fmt.Errorf("invalid host audit mode %q: want all, commands, files or off", mode)
+	}
+	return a, nil
+}
+
+// command records a command a plugin is about to run, or was refused. The
+// command's own outcome is up to the plugin.
+func (a *HostAuditor) command(ctx context.Context, plugin, name string, args []string, err error) {
+	if a == nil || !a.commands {
+		return
+	}
+	result := "started"
+	if err != nil {
+		result = "denied"
+	}
+	a.log(ctx, plugin, "host.exec", name, result, map[string]string{
+		"command": strings.Join(append([]string{name}, args...), " "),
+	})
+}
+
+// file records a file a plugin changes. data is what was written, when the
+// sandbox saw it.
+func (a *HostAuditor) file(ctx context.Context, plugin, action, path string, data []byte, err error) {
+	if a == nil || !a.files {
+		return
+	}
+	md := map[string]string{"path": path}
+	if data != nil {
+		sum := sha256.Sum256(data)
+		md["sha256"] = hex.EncodeToString(sum[:])
+		md["size"] = strconv.Itoa(len(data))
+	}
+	result := "success"
+	switch {
+	case errors.Is(err, ErrNotPermitted):
+		result = "denied"
+	case err != nil:
+		result = "error"
+		md["error"] = err.Error()
+	}
+	a.log(ctx, plugin, "host."+action, path, result, md)
+}
+
+func (a *HostAuditor) log(ctx context.Context, plugin, action, target, result string, md map[string]string) {
+	md["plugin"] = plugin
+	if caller := IdentityFromContext(ctx); caller != nil {
+		md["caller"] = caller.UserID
+	}
+
+	a.record(ctx, &AuditEntry{
+		Timestamp: time.Now(),
+		AgentID:   a.agentID,
+		Identity:  &Identity{UserID: "plugin:" + plugin},
+		Action:    action,
+		Resource:  "host:" + target,
+		Result:    result,
+		Metadata:  md,
+		RequestID: requestid.From(ctx),
+	})
+}
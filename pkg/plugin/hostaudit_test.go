@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bhangun/mandau/pkg/requestid"
+)
+
+func TestHostAuditor(t *testing.T) {
+	var entries []*AuditEntry
+	record := func(ctx context.Context, entry *AuditEntry) { entries = append(entries, entry) }
+
+	auditor, err := NewHostAuditor("", "agent-1", record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	s := NewSandbox("test", Permissions{Exec: []string{"true"}, Write: []string{dir}})
+	s.SetAuditor(auditor)
+
+	s.WriteFile(filepath.Join(dir, "site.conf"), []byte("ok"), 0644)
+	s.WriteFile("/etc/passwd", []byte("no"), 0644)
+	s.Command("true", "--flag").Run()
+
+	want := []struct{ action, result string }{
+		{"host.write", "success"},
+		{"host.write", "denied"},
+		{"host.exec", "started"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.Result != w.result {
+			t.Errorf("entry %d = %s %s, want %s %s", i, e.Action, e.Result, w.action, w.result)
+		}
+		if e.Identity.UserID != "plugin:test" || e.AgentID != "agent-1" {
+			t.Errorf("entry %d attributed to %s on %s", i, e.Identity.UserID, e.AgentID)
+		}
+	}
+	if entries[0].Metadata["sha256"] == "" || entries[0].Metadata["size"] != "2" {
+		t.Errorf("write metadata = %v, want the content's hash and size", entries[0].Metadata)
+	}
+	if got := entries[2].Metadata["command"]; got != "true --flag" {
+		t.Errorf("command = %q", got)
+	}
+}
+
+func TestHostAuditorModes(t *testing.T) {
+	var n int
+	record := func(ctx context.Context, entry *AuditEntry) { n++ }
+
+	auditor, err := NewHostAuditor(HostAuditCommands, "agent-1", record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSandbox("test", Permissions{})
+	s.SetAuditor(auditor)
+	s.WriteFile(filepath.Join(t.TempDir(), "x"), nil, 0644)
+	if n != 0 {
+		t.Errorf("commands mode recorded a file change")
+	}
+
+	if _, err := NewHostAuditor("some", "agent-1", record); err == nil {
+		t.Error("invalid mode accepted")
+	}
+}
+
+func TestHostAuditorCaller(t *testing.T) {
+	var entries []*AuditEntry
+	record := func(ctx context.Context, entry *AuditEntry) { entries = append(entries, entry) }
+
+	auditor, err := NewHostAuditor("", "agent-1", record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	s := NewSandbox("test", Permissions{Exec: []string{"true"}, Write: []string{dir}})
+	s.SetAuditor(auditor)
+
+	ctx := requestid.With(WithIdentity(context.Background(), &Identity{UserID: "alice"}), "req-1")
+	s.WithContext(ctx).WriteFile(filepath.Join(dir, "site.conf"), []byte("ok"), 0644)
+	s.CommandContext(ctx, "true").Run()
+	s.Command("true").Run()
+
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, e := range entries[:2] {
+		if e.Identity.UserID != "plugin:test" || e.Metadata["plugin"] != "test" {
+			t.Errorf("entry %d attributed to %s, want the plugin", i, e.Identity.UserID)
+		}
+		if e.Metadata["caller"] != "alice" || e.RequestID != "req-1" {
+			t.Errorf("entry %d: caller %q, request %q, want alice's req-1", i, e.Metadata["caller"], e.RequestID)
+		}
+	}
+
+	// Outside a call there is no caller to name
+	if e := entries[2]; e.Metadata["caller"] != "" || e.RequestID != "" {
+		t.Errorf("entry without a call: caller %q, request %q", e.Metadata["caller"], e.RequestID)
+	}
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -34,6 +35,7 @@ const (
 	CapabilityMonitor  Capability = "monitoring"
 	CapabilityNotify   Capability = "notifications"
 	CapabilitySecurity Capability = "security"
+	CapabilityMFA      Capability = "mfa"
 )
 
 // AuthPlugin handles authentication and authorization
@@ -68,6 +70,7 @@ type AuditEntry struct {
 	Duration       time.Duration
 	Metadata       map[string]string
 	TranscriptHash string // For terminal sessions
+	RequestID      string // Correlates the entries of one command across the core and agents
 }
 
 // SecretsPlugin manages secret injection
@@ -87,6 +90,23 @@ type SecretsPlugin interface {
 	InjectEnv(ctx context.Context, env map[string]string) error
 }
 
+// NotifyPlugin delivers alerts to people (chat, email, webhooks)
+type NotifyPlugin interface {
+	Plugin
+
+	// Notify sends a notification
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// MFAPlugin verifies second factors, such as ID tokens of an OIDC provider
+type MFAPlugin interface {
+	Plugin
+
+	// VerifyMFA checks that token proves a second factor for identity and
+	// returns when that factor was authenticated
+	VerifyMFA(ctx context.Context, identity *Identity, token string) (time.Time, error)
+}
+
 // PolicyPlugin enforces fine-grained access control
 type PolicyPlugin interface {
 	Plugin
@@ -105,5 +125,32 @@ type PolicyRequest struct {
 type PolicyDecision struct {
 	Allowed     bool
 	Reason      string
-	Obligations []string // Additional requirements
+	Obligations []string // Additional requirements, as key=value
+}
+
+// Obligations understood by core and agent. A call flagged with one is
+// refused with FailedPrecondition until the caller satisfies it.
+const (
+	// ObligationReason requires a justification sent with the call
+	ObligationReason = "require_reason"
+	// ObligationRecording requires the call to be recorded by an audit plugin
+	ObligationRecording = "require_recording"
+	// ObligationMFA requires a second factor verified by an MFA plugin; an
+	// optional value bounds its age, e.g. "require_mfa=5m"
+	ObligationMFA = "require_mfa"
+)
+
+// ObligationExecTimeout overrides security.exec_timeout for the caller's
+// exec sessions and commands, e.g. "exec_timeout=15m"; "exec_timeout=0"
+// lifts the limit
+const ObligationExecTimeout = "exec_timeout"
+
+// Obligation returns the value of the key=value obligation named key
+func (d *PolicyDecision) Obligation(key string) (string, bool) {
+	for _, o := range d.Obligations {
+		if k, v, ok := strings.Cut(o, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
 }
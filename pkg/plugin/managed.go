@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ManagedMarker is in the first line of the files the host service plugins
+// generate
+const ManagedMarker = "Managed by Mandau"
+
+// DefaultManagedFiles keeps the checksums of managed files unless
+// configured otherwise
+const DefaultManagedFiles = "/var/lib/mandau/managed-files.json"
+
+// ErrEdited is returned for a change to a file that Mandau did not write,
+// or that was edited since it did
+var ErrEdited = errors.New("not written by Mandau or edited since")
+
+// ManagedFiles records the checksum of each file the host service plugins
+// generate, so a later change can tell whether a human has been at the
+// file in between. A nil ManagedFiles records nothing and allows every
+// change.
+type ManagedFiles struct {
+	path string
+	mu   sync.Mutex
+	sums map[string]string // File path to hex SHA-256 of what was written
+}
+
+// NewManagedFiles loads the checksums kept in path
+func NewManagedFiles(path string) (*ManagedFiles, error) {
+	m := &ManagedFiles{path: path, sums: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("managed files: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.sums); err != nil {
+		return nil, fmt.Errorf("managed files %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Check returns ErrEdited when name exists and is not as Mandau left it.
+// A file with a recorded checksum must still match it. One without, from
+// before checksums were kept, must carry ManagedMarker in its first line.
+func (m *ManagedFiles) Check(name string) error {
+	if m == nil {
+		return nil
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	sum, recorded := m.sums[name]
+	m.mu.Unlock()
+
+	if recorded && sum == checksum(data) {
+		return nil
+	}
+	if !recorded {
+		first, _, _ := bytes.Cut(data, []byte("\n"))
+		if bytes.Contains(first, []byte(ManagedMarker)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", name, ErrEdited)
+}
+
+// Record notes data as what Mandau wrote to name, or forgets name when data
+// is nil because the file was removed
+func (m *ManagedFiles) Record(name string, data []byte) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if data == nil {
+		delete(m.sums, name)
+	} else {
+		m.sums[name] = checksum(data)
+	}
+	return m.save()
+}
+
+// FileDrift is a managed file that is no longer as Mandau wrote it
+type FileDrift struct {
+	Path    string
+	Missing bool // Removed, rather than edited
+}
+
+// Drift returns the recorded files that were edited or removed since Mandau
+// wrote them, by path
+func (m *ManagedFiles) Drift() []FileDrift {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	sums := make(map[string]string, len(m.sums))
+	for name, sum := range m.sums {
+		sums[name] = sum
+	}
+	m.mu.Unlock()
+
+	var drift []FileDrift
+	for name, sum := range sums {
+		data, err := os.ReadFile(name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			drift = append(drift, FileDrift{Path: name, Missing: true})
+		case err != nil || checksum(data) != sum:
+			drift = append(drift, FileDrift{Path: name})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift
+}
+
+// save writes the checksums through a temporary file, so a crash never
+// leaves a torn state file. Called with mu held.
+func (m *ManagedFiles) save() error {
+	data, err := json.MarshalIndent(m.sums, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return fmt.Errorf("managed files: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("managed files: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("managed files: %w", err)
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManagedFiles(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state", "managed-files.json")
+	managed, err := NewManagedFiles(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSandbox("test", Permissions{Write: []string{dir}})
+	s.SetManaged(managed)
+
+	site := filepath.Join(dir, "site.conf")
+	if err := s.WriteManaged(site, []byte("# Managed by Mandau\nv1\n"), 0644, false); err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+	if err := s.WriteManaged(site, []byte("# Managed by Mandau\nv2\n"), 0644, false); err != nil {
+		t.Fatalf("unchanged file: %v", err)
+	}
+
+	// The checksums survive a restart
+	managed, err = NewManagedFiles(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetManaged(managed)
+
+	os.WriteFile(site, []byte("# Managed by Mandau\nhand edit\n"), 0644)
+	if err := s.WriteManaged(site, []byte("v3\n"), 0644, false); !errors.Is(err, ErrEdited) {
+		t.Errorf("edited file: err = %v, want ErrEdited", err)
+	}
+	if err := s.RemoveManaged(site, false); !errors.Is(err, ErrEdited) {
+		t.Errorf("remove edited file: err = %v, want ErrEdited", err)
+	}
+	if err := s.WriteManaged(site, []byte("v3\n"), 0644, true); err != nil {
+		t.Errorf("forced: %v", err)
+	}
+	if err := s.RemoveManaged(site, false); err != nil {
+		t.Errorf("remove: %v", err)
+	}
+
+	// Files without a checksum are Mandau's only when they carry the marker
+	legacy := filepath.Join(dir, "legacy.conf")
+	os.WriteFile(legacy, []byte("# Managed by Mandau\n"), 0644)
+	if err := s.CheckManaged(legacy); err != nil {
+		t.Errorf("marked file: %v", err)
+	}
+	hand := filepath.Join(dir, "hand.conf")
+	os.WriteFile(hand, []byte("server {}\n"), 0644)
+	if err := s.CheckManaged(hand); !errors.Is(err, ErrEdited) {
+		t.Errorf("hand-written file: err = %v, want ErrEdited", err)
+	}
+}
@@ -0,0 +1,376 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotPermitted is returned for host access a plugin did not declare
+var ErrNotPermitted = errors.New("not permitted by the plugin's declared permissions")
+
+// Permissions is the host access a plugin needs
+type Permissions struct {
+	Exec    []string `json:"exec,omitempty" yaml:"exec,omitempty"`       // Binaries, by name in PATH or absolute path
+	Write   []string `json:"write,omitempty" yaml:"write,omitempty"`     // Files and directories, with everything under them
+	Network []string `json:"network,omitempty" yaml:"network,omitempty"` // host:port dialed; "*" matches any host or port
+}
+
+// IsZero reports whether no access is declared
+func (p Permissions) IsZero() bool {
+	return len(p.Exec) == 0 && len(p.Write) == 0 && len(p.Network) == 0
+}
+
+// Exceeding lists the entries of p that granted does not contain
+func (p Permissions) Exceeding(granted Permissions) []string {
+	var extra []string
+	check := func(kind string, want, have []string) {
+		for _, w := range want {
+			found := false
+			for _, h := range have {
+				found = found || w == h
+			}
+			if !found {
+				extra = append(extra, kind+" "+w)
+			}
+		}
+	}
+	check("exec", p.Exec, granted.Exec)
+	check("write", p.Write, granted.Write)
+	check("network", p.Network, granted.Network)
+	return extra
+}
+
+// SandboxedPlugin declares the host access it needs and goes through the
+// sandbox the agent hands it for that access, which refuses anything
+// undeclared. The sandbox guards the calls made through it; it is not an
+// operating system boundary. Plugins make changes for a call through
+// Sandbox.WithContext, so they are audited against the call's caller.
+type SandboxedPlugin interface {
+	Plugin
+
+	// Permissions is called after Init, so it may depend on the config
+	Permissions() Permissions
+
+	// SetSandbox hands the plugin its sandbox once Permissions is known
+	SetSandbox(sandbox *Sandbox)
+}
+
+// Confine gives a sandboxed plugin a sandbox limited to granted, or to what
+// the plugin declares when granted is nil, recording its host changes with
+// auditor. It returns nil for plugins that are not sandboxed.
+func Confine(p Plugin, granted *Permissions, auditor *HostAuditor) *Sandbox {
+	sp, ok := p.(SandboxedPlugin)
+	if !ok {
+		return nil
+	}
+	perms := sp.Permissions()
+	if granted != nil {
+		perms = *granted
+	}
+	sandbox := NewSandbox(p.Name(), perms)
+	sandbox.SetAuditor(auditor)
+	sp.SetSandbox(sandbox)
+	return sandbox
+}
+
+// Sandbox performs host access on behalf of a plugin, within the plugin's
+// permissions. The methods mirror os, os/exec and net. A nil Sandbox allows
+// everything, so plugins work unconfined outside the agent.
+type Sandbox struct {
+	ctx     context.Context // The call changes are audited against; nil outside one
+	plugin  string
+	perms   Permissions
+	auditor *HostAuditor  // Records host changes; nil records nothing
+	managed *ManagedFiles // Guards generated files; nil guards nothing
+}
+
+// NewSandbox returns a sandbox for the named plugin limited to perms
+func NewSandbox(plugin string, perms Permissions) *Sandbox {
+	return &Sandbox{plugin: plugin, perms: perms}
+}
+
+// SetAuditor records the host changes made through the sandbox. Call it
+// before handing the sandbox to the plugin.
+func (s *Sandbox) SetAuditor(auditor *HostAuditor) {
+	s.auditor = auditor
+}
+
+// WithContext returns a copy of the sandbox whose host changes are audited
+// against ctx, naming the caller and request that asked for them. Plugins
+// call it with the context of the call they serve.
+func (s *Sandbox) WithContext(ctx context.Context) *Sandbox {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.ctx = ctx
+	return &c
+}
+
+// SetManaged guards the files the plugin generates with managed. Call it
+// before the plugin makes changes.
+func (s *Sandbox) SetManaged(managed *ManagedFiles) {
+	s.managed = managed
+}
+
+// Permissions returns what the sandbox allows
+func (s *Sandbox) Permissions() Permissions {
+	if s == nil {
+		return Permissions{}
+	}
+	return s.perms
+}
+
+// CanExec reports whether the plugin may run name. A bare name resolves
+// through PATH, and matches a declared binary that resolves to the same file.
+func (s *Sandbox) CanExec(name string) bool {
+	if s == nil {
+		return true
+	}
+	want, err := exec.LookPath(name)
+	if err != nil {
+		want = name
+	}
+	for _, allowed := range s.perms.Exec {
+		if allowed == name {
+			return true
+		}
+		if resolved, err := exec.LookPath(allowed); err == nil && resolved == want {
+			return true
+		}
+	}
+	return false
+}
+
+// CanWrite reports whether the plugin may write name
+func (s *Sandbox) CanWrite(name string) bool {
+	if s == nil {
+		return true
+	}
+	target, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.perms.Write {
+		dir, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		if target == dir || strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanDial reports whether the plugin may connect to addr, a host:port
+func (s *Sandbox) CanDial(addr string) bool {
+	if s == nil {
+		return true
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.perms.Network {
+		h, p, err := net.SplitHostPort(allowed)
+		if err != nil {
+			continue
+		}
+		hostOK, _ := path.Match(h, host)
+		portOK, _ := path.Match(p, port)
+		if hostOK && portOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Command is exec.Command for a declared binary. Running an undeclared one
+// fails with ErrNotPermitted.
+func (s *Sandbox) Command(name string, arg ...string) *exec.Cmd {
+	cmd := exec.Command(name, arg...)
+	if !s.CanExec(name) {
+		cmd.Err = s.deny("exec", name)
+	}
+	s.auditCommand(name, arg, cmd.Err)
+	return cmd
+}
+
+// CommandContext is exec.CommandContext for a declared binary. The command
+// is audited against ctx.
+func (s *Sandbox) CommandContext(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	if !s.CanExec(name) {
+		cmd.Err = s.deny("exec", name)
+	}
+	s.WithContext(ctx).auditCommand(name, arg, cmd.Err)
+	return cmd
+}
+
+// WriteFile is os.WriteFile within the declared paths
+func (s *Sandbox) WriteFile(name string, data []byte, perm os.FileMode) error {
+	var err error
+	if s.CanWrite(name) {
+		err = os.WriteFile(name, data, perm)
+	} else {
+		err = s.deny("write", name)
+	}
+	s.auditFile("write", name, data, err)
+	return err
+}
+
+// WriteManaged is WriteFile for a file the plugin generates. Unless force
+// is set, it refuses with ErrEdited to overwrite a file Mandau did not
+// write or that was edited since.
+func (s *Sandbox) WriteManaged(name string, data []byte, perm os.FileMode, force bool) error {
+	managed := s.managedFiles()
+	if !force {
+		if err := managed.Check(name); err != nil {
+			s.auditFile("write", name, data, err)
+			return err
+		}
+	}
+	if err := s.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	return managed.Record(name, data)
+}
+
+// Create is os.Create within the declared paths
+func (s *Sandbox) Create(name string) (*os.File, error) {
+	if !s.CanWrite(name) {
+		err := s.deny("write", name)
+		s.auditFile("write", name, nil, err)
+		return nil, err
+	}
+	f, err := os.Create(name)
+	s.auditFile("write", name, nil, err)
+	return f, err
+}
+
+// OpenFile is os.OpenFile; opening for writing needs a declared path
+func (s *Sandbox) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) == 0 {
+		return os.OpenFile(name, flag, perm)
+	}
+	if !s.CanWrite(name) {
+		err := s.deny("write", name)
+		s.auditFile("write", name, nil, err)
+		return nil, err
+	}
+	f, err := os.OpenFile(name, flag, perm)
+	s.auditFile("write", name, nil, err)
+	return f, err
+}
+
+// MkdirAll is os.MkdirAll within the declared paths
+func (s *Sandbox) MkdirAll(name string, perm os.FileMode) error {
+	var err error
+	if s.CanWrite(name) {
+		err = os.MkdirAll(name, perm)
+	} else {
+		err = s.deny("write", name)
+	}
+	s.auditFile("mkdir", name, nil, err)
+	return err
+}
+
+// Remove is os.Remove within the declared paths
+func (s *Sandbox) Remove(name string) error {
+	var err error
+	if s.CanWrite(name) {
+		err = os.Remove(name)
+	} else {
+		err = s.deny("write", name)
+	}
+	s.auditFile("remove", name, nil, err)
+	return err
+}
+
+// CheckManaged returns ErrEdited when name is a file WriteManaged or
+// RemoveManaged would refuse to change without force
+func (s *Sandbox) CheckManaged(name string) error {
+	return s.managedFiles().Check(name)
+}
+
+// RemoveManaged is Remove for a file the plugin generates, refusing like
+// WriteManaged unless force is set
+func (s *Sandbox) RemoveManaged(name string, force bool) error {
+	managed := s.managedFiles()
+	if !force {
+		if err := managed.Check(name); err != nil {
+			s.auditFile("remove", name, nil, err)
+			return err
+		}
+	}
+	err := s.Remove(name)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		if rerr := managed.Record(name, nil); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// Symlink is os.Symlink; the link must be in a declared path
+func (s *Sandbox) Symlink(oldname, newname string) error {
+	var err error
+	if s.CanWrite(newname) {
+		err = os.Symlink(oldname, newname)
+	} else {
+		err = s.deny("write", newname)
+	}
+	s.auditFile("symlink", newname, nil, err)
+	return err
+}
+
+// DialContext dials declared addresses, for use as an http.Transport's
+// DialContext. Host names are checked as given, before resolution.
+func (s *Sandbox) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !s.CanDial(addr) {
+		return nil, s.deny("network", addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
+
+func (s *Sandbox) managedFiles() *ManagedFiles {
+	if s == nil {
+		return nil
+	}
+	return s.managed
+}
+
+func (s *Sandbox) auditCommand(name string, args []string, err error) {
+	if s != nil {
+		s.auditor.command(s.context(), s.plugin, name, args, err)
+	}
+}
+
+func (s *Sandbox) auditFile(action, name string, data []byte, err error) {
+	if s != nil {
+		s.auditor.file(s.context(), s.plugin, action, name, data, err)
+	}
+}
+
+func (s *Sandbox) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+func (s *Sandbox) deny(kind, target string) error {
+	log.Printf("plugin %s: denied %s %s", s.plugin, kind, target)
+	return fmt.Errorf("plugin %s: %s %s: %w", s.plugin, kind, target, ErrNotPermitted)
+}
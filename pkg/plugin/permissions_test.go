@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSandboxWrite(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(allowed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSandbox("test", Permissions{Write: []string{allowed}})
+
+	if err := s.WriteFile(filepath.Join(allowed, "site.conf"), []byte("ok"), 0644); err != nil {
+		t.Errorf("write inside a declared path: %v", err)
+	}
+	for _, name := range []string{
+		filepath.Join(dir, "other.conf"),
+		filepath.Join(allowed, "..", "escape.conf"),
+		allowed + "-sibling",
+	} {
+		if err := s.WriteFile(name, []byte("no"), 0644); !errors.Is(err, ErrNotPermitted) {
+			t.Errorf("write %s: err = %v, want ErrNotPermitted", name, err)
+		}
+	}
+	if _, err := s.OpenFile(filepath.Join(dir, "other.conf"), os.O_RDONLY, 0); errors.Is(err, ErrNotPermitted) {
+		t.Error("opening for reading needs no write permission")
+	}
+}
+
+func TestSandboxExec(t *testing.T) {
+	s := NewSandbox("test", Permissions{Exec: []string{"true"}})
+
+	if err := s.Command("true").Run(); err != nil {
+		t.Errorf("run declared binary: %v", err)
+	}
+	if err := s.Command("false").Run(); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("run undeclared binary: err = %v, want ErrNotPermitted", err)
+	}
+
+	var unconfined *Sandbox
+	if !unconfined.CanExec("false") || !unconfined.CanWrite("/etc/passwd") {
+		t.Error("a nil sandbox should allow everything")
+	}
+}
+
+func TestSandboxDial(t *testing.T) {
+	s := NewSandbox("test", Permissions{Network: []string{"hooks.example.com:443", "*.internal:*"}})
+
+	for addr, want := range map[string]bool{
+		"hooks.example.com:443": true,
+		"hooks.example.com:80":  false,
+		"vault.internal:8200":   true,
+		"example.org:443":       false,
+	} {
+		if got := s.CanDial(addr); got != want {
+			t.Errorf("CanDial(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if _, err := s.DialContext(context.Background(), "tcp", "example.org:443"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("dial undeclared address: err = %v", err)
+	}
+}
+
+func TestPermissionsExceeding(t *testing.T) {
+	declared := Permissions{Exec: []string{"nginx", "sh"}, Write: []string{"/etc/nginx"}}
+	granted := Permissions{Exec: []string{"nginx"}, Write: []string{"/etc/nginx"}}
+
+	extra := declared.Exceeding(granted)
+	if len(extra) != 1 || extra[0] != "exec sh" {
+		t.Errorf("Exceeding = %v, want [exec sh]", extra)
+	}
+	if extra := granted.Exceeding(declared); len(extra) != 0 {
+		t.Errorf("Exceeding a superset = %v", extra)
+	}
+}
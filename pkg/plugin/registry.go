@@ -3,7 +3,11 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"log"
 	"sync"
+
+	"github.com/bhangun/mandau/pkg/redact"
+	"github.com/bhangun/mandau/pkg/requestid"
 )
 
 // Registry manages plugin lifecycle
@@ -14,14 +18,38 @@ type Registry struct {
 	audit   []AuditPlugin
 	secrets []SecretsPlugin
 	policy  []PolicyPlugin
+	notify  []NotifyPlugin
+	mfa     []MFAPlugin
+
+	sandboxes   map[string]*Sandbox // Of sandboxed plugins, by name
+	hostAuditor *HostAuditor        // Records what sandboxed plugins change on the host
+
+	redactor *redact.Redactor // Masks secrets in audit metadata
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
-		plugins: make(map[string]Plugin),
+		plugins:   make(map[string]Plugin),
+		sandboxes: make(map[string]*Sandbox),
+		redactor:  redact.Default(),
 	}
 }
 
+// SetRedactor replaces the redactor applied to audit metadata
+func (r *Registry) SetRedactor(redactor *redact.Redactor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactor = redactor
+}
+
+// SetHostAuditor records the host changes of the plugins confined from now
+// on. Call it before Init.
+func (r *Registry) SetHostAuditor(auditor *HostAuditor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hostAuditor = auditor
+}
+
 // Register adds a plugin to the registry
 func (r *Registry) Register(p Plugin) error {
 	r.mu.Lock()
@@ -47,25 +75,65 @@ func (r *Registry) Register(p Plugin) error {
 	if policy, ok := p.(PolicyPlugin); ok {
 		r.policy = append(r.policy, policy)
 	}
+	if notify, ok := p.(NotifyPlugin); ok {
+		r.notify = append(r.notify, notify)
+	}
+	if mfa, ok := p.(MFAPlugin); ok {
+		r.mfa = append(r.mfa, mfa)
+	}
 
 	return nil
 }
 
-// Init initializes all registered plugins
+// Init initializes all registered plugins, then confines the sandboxed
+// ones to the permissions they declare
 func (r *Registry) Init(ctx context.Context, configs map[string]map[string]interface{}) error {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	for name, p := range r.plugins {
 		config := configs[name]
 		if err := p.Init(ctx, config); err != nil {
 			return fmt.Errorf("plugin %s init failed: %w", name, err)
 		}
+		if sandbox := Confine(p, nil, r.auditorFor(p)); sandbox != nil {
+			r.sandboxes[name] = sandbox
+		}
 	}
 
 	return nil
 }
 
+// Confine confines an initialized plugin registered after Init, see
+// Confine. granted nil means what the plugin declares.
+func (r *Registry) Confine(p Plugin, granted *Permissions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sandbox := Confine(p, granted, r.auditorFor(p)); sandbox != nil {
+		r.sandboxes[p.Name()] = sandbox
+	}
+}
+
+// auditorFor returns the host auditor for p. Audit plugins are left out:
+// their writes are the audit log, and recording them would loop.
+func (r *Registry) auditorFor(p Plugin) *HostAuditor {
+	if _, ok := p.(AuditPlugin); ok {
+		return nil
+	}
+	return r.hostAuditor
+}
+
+// Permissions returns what a plugin is allowed, and false when the plugin
+// is not sandboxed
+func (r *Registry) Permissions(name string) (Permissions, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sandbox, ok := r.sandboxes[name]
+	return sandbox.Permissions(), ok
+}
+
 // Auth returns the first auth plugin (chain support later)
 func (r *Registry) Auth() AuthPlugin {
 	r.mu.RLock()
@@ -82,12 +150,38 @@ func (r *Registry) AuditAll(ctx context.Context, entry *AuditEntry) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	// Entries made while impersonating name who really made the call
+	if entry.Identity != nil && entry.Identity.Impersonator != nil {
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]string)
+		}
+		entry.Metadata["impersonated_by"] = entry.Identity.Impersonator.UserID
+	}
+	// Values such as reasons and errors are free text that may quote a
+	// secret
+	entry.Metadata = r.redactor.Values(entry.Metadata)
+	if entry.RequestID == "" {
+		entry.RequestID = requestid.From(ctx)
+	}
 	for _, audit := range r.audit {
 		// Never fail on audit - just log errors
 		audit.Log(ctx, entry)
 	}
 }
 
+// NotifyAll sends a notification through all notify plugins
+func (r *Registry) NotifyAll(ctx context.Context, n *Notification) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, notify := range r.notify {
+		// Never fail on notify - just log errors
+		if err := notify.Notify(ctx, n); err != nil {
+			log.Printf("notify plugin %s failed: %v", notify.Name(), err)
+		}
+	}
+}
+
 // ListAll returns all registered plugins
 func (r *Registry) ListAll() []Plugin {
 	r.mu.RLock()
@@ -124,3 +218,33 @@ func (r *Registry) Policy() PolicyPlugin {
 	}
 	return nil
 }
+
+// Secrets returns the first secrets plugin
+func (r *Registry) Secrets() SecretsPlugin {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.secrets) > 0 {
+		return r.secrets[0]
+	}
+	return nil
+}
+
+// MFA returns the first MFA plugin
+func (r *Registry) MFA() MFAPlugin {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.mfa) > 0 {
+		return r.mfa[0]
+	}
+	return nil
+}
+
+// Auditing reports whether any audit plugin records calls
+func (r *Registry) Auditing() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.audit) > 0
+}
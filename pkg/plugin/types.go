@@ -12,6 +12,9 @@ type Identity struct {
 	Roles       []string
 	Attributes  map[string]string
 	Certificate []byte
+	// Impersonator is who acts as this identity, for calls made with
+	// another user's permissions; nil otherwise
+	Impersonator *Identity
 }
 
 // Action represents an operation being performed
@@ -40,11 +43,21 @@ type AuditFilter struct {
 	AgentID   string
 	UserID    string
 	Action    string
+	RequestID string
 	StartTime *time.Time
 	EndTime   *time.Time
 	Limit     int
 }
 
+// Notification is an alert sent through notify plugins
+type Notification struct {
+	Title     string
+	Message   string
+	Severity  string // info, warning, critical
+	Timestamp time.Time
+	Labels    map[string]string
+}
+
 // Context helpers
 type contextKey string
 
@@ -1,8 +1,44 @@
 package plugin
 
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
 func GetStringConfig(config map[string]interface{}, key string) string {
 	if val, ok := config[key].(string); ok {
 		return val
 	}
 	return ""
 }
+
+// LoadTemplate parses name from dir when the operator put an override
+// there, else fallback. The template is executed once with sample, which
+// should set every field, so a broken override fails at load rather than
+// on the first request.
+func LoadTemplate(dir, name, fallback string, sample interface{}) (*template.Template, error) {
+	text := fallback
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		switch {
+		case err == nil:
+			text = string(data)
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
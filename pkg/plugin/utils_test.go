@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	type data struct{ Name string }
+	sample := &data{Name: "sample"}
+	dir := t.TempDir()
+
+	render := func(t *testing.T, dir string) string {
+		t.Helper()
+		tmpl, err := LoadTemplate(dir, "site.tmpl", "built-in {{.Name}}", sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, &data{Name: "web"}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	if got := render(t, ""); got != "built-in web" {
+		t.Errorf("no dir: got %q", got)
+	}
+	if got := render(t, dir); got != "built-in web" {
+		t.Errorf("no override: got %q", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "site.tmpl"), []byte("site {{.Name}}"), 0644)
+	if got := render(t, dir); got != "site web" {
+		t.Errorf("override: got %q", got)
+	}
+
+	for _, text := range []string{"{{.Name", "{{.Missing}}"} {
+		os.WriteFile(filepath.Join(dir, "site.tmpl"), []byte(text), 0644)
+		if _, err := LoadTemplate(dir, "site.tmpl", "", sample); err == nil {
+			t.Errorf("%q: loaded", text)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package quota computes the resources a compose file reserves so core and
+// agents account for stacks the same way.
+package quota
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/go-units"
+	"gopkg.in/yaml.v3"
+)
+
+// Reservations are the resources declared by one or more stacks
+type Reservations struct {
+	Containers  int
+	CPUs        float64
+	MemoryBytes int64
+}
+
+// Add accumulates o into r
+func (r *Reservations) Add(o Reservations) {
+	r.Containers += o.Containers
+	r.CPUs += o.CPUs
+	r.MemoryBytes += o.MemoryBytes
+}
+
+type composeFile struct {
+	Services map[string]struct {
+		Scale  *int `yaml:"scale"`
+		Deploy struct {
+			Replicas  *int `yaml:"replicas"`
+			Resources struct {
+				Reservations struct {
+					CPUs   interface{} `yaml:"cpus"`
+					Memory interface{} `yaml:"memory"`
+				} `yaml:"reservations"`
+			} `yaml:"resources"`
+		} `yaml:"deploy"`
+	} `yaml:"services"`
+}
+
+// FromCompose sums the containers and reservations declared by a compose
+// file. Services without reservations count as containers only.
+func FromCompose(content []byte) (Reservations, error) {
+	var compose composeFile
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return Reservations{}, fmt.Errorf("parse compose: %w", err)
+	}
+
+	var total Reservations
+	for name, svc := range compose.Services {
+		replicas := 1
+		if svc.Deploy.Replicas != nil {
+			replicas = *svc.Deploy.Replicas
+		} else if svc.Scale != nil {
+			replicas = *svc.Scale
+		}
+		if replicas < 0 {
+			return Reservations{}, fmt.Errorf("service %s: negative replicas %d", name, replicas)
+		}
+
+		res := svc.Deploy.Resources.Reservations
+		var cpus float64
+		if res.CPUs != nil {
+			v, err := strconv.ParseFloat(fmt.Sprint(res.CPUs), 64)
+			if err != nil {
+				return Reservations{}, fmt.Errorf("service %s: parse cpus: %w", name, err)
+			}
+			if v < 0 {
+				return Reservations{}, fmt.Errorf("service %s: negative cpus %v", name, v)
+			}
+			cpus = v
+		}
+		var memory int64
+		if res.Memory != nil {
+			v, err := ParseMemory(fmt.Sprint(res.Memory))
+			if err != nil {
+				return Reservations{}, fmt.Errorf("service %s: %w", name, err)
+			}
+			memory = v
+		}
+
+		total.Containers += replicas
+		total.CPUs += cpus * float64(replicas)
+		total.MemoryBytes += memory * int64(replicas)
+	}
+
+	return total, nil
+}
+
+// ParseMemory parses sizes such as "512M" or "2g" as binary units, the way
+// docker compose does
+func ParseMemory(s string) (int64, error) {
+	v, err := units.RAMInBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse memory %q: %w", s, err)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("parse memory %q: negative size", s)
+	}
+	return v, nil
+}
+
+// FormatMemory renders bytes for messages and tables
+func FormatMemory(b int64) string {
+	return units.BytesSize(float64(b))
+}
@@ -0,0 +1,128 @@
+package quota
+
+import "testing"
+
+func TestFromCompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		want    Reservations
+		wantErr bool
+	}{
+		{
+			name:    "no reservations",
+			compose: "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n",
+			want:    Reservations{Containers: 2},
+		},
+		{
+			name: "reservations times replicas",
+			compose: `services:
+  web:
+    deploy:
+      replicas: 3
+      resources:
+        reservations:
+          cpus: "0.5"
+          memory: 256M
+`,
+			want: Reservations{Containers: 3, CPUs: 1.5, MemoryBytes: 3 * 256 << 20},
+		},
+		{
+			name: "numeric cpus and scale",
+			compose: `services:
+  worker:
+    scale: 2
+    deploy:
+      resources:
+        reservations:
+          cpus: 1
+          memory: 1g
+`,
+			want: Reservations{Containers: 2, CPUs: 2, MemoryBytes: 2 << 30},
+		},
+		{
+			name:    "replicas win over scale",
+			compose: "services:\n  web:\n    scale: 5\n    deploy:\n      replicas: 2\n",
+			want:    Reservations{Containers: 2},
+		},
+		{
+			name:    "zero replicas",
+			compose: "services:\n  web:\n    deploy:\n      replicas: 0\n",
+			want:    Reservations{},
+		},
+		{
+			name:    "invalid cpus",
+			compose: "services:\n  web:\n    deploy:\n      resources:\n        reservations:\n          cpus: lots\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid memory",
+			compose: "services:\n  web:\n    deploy:\n      resources:\n        reservations:\n          memory: huge\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative replicas",
+			compose: "services:\n  web:\n    deploy:\n      replicas: -3\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative scale",
+			compose: "services:\n  web:\n    scale: -1\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative cpus",
+			compose: "services:\n  web:\n    deploy:\n      resources:\n        reservations:\n          cpus: \"-2\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative memory",
+			compose: "services:\n  web:\n    deploy:\n      resources:\n        reservations:\n          memory: -1g\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			compose: "services: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromCompose([]byte(tt.compose))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromCompose: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"512M", 512 << 20},
+		{"2g", 2 << 30},
+		{"1024", 1024},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.in)
+		if err != nil {
+			t.Fatalf("ParseMemory(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseMemory(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package redact masks the values of secret-looking variables, such as
+// DB_PASSWORD=... or "api_token": "...", before text leaves the process in
+// operation events, logs, diffs and audit records. Whether a variable is
+// secret is decided by its name alone.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "******"
+
+// DefaultPatterns match the variable names redacted when none are configured
+var DefaultPatterns = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "KEY", "CREDENTIAL"}
+
+// assignment finds NAME=value and NAME: value pairs, optionally quoted as in
+// JSON or YAML. Values end at whitespace or a separator unless quoted.
+var assignment = regexp.MustCompile(`["']?([A-Za-z_][A-Za-z0-9_.-]*)["']?(\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s"',;&]+)`)
+
+// Redactor masks the values of variables whose names match its patterns
+type Redactor struct {
+	names *regexp.Regexp
+}
+
+var defaultRedactor = MustNew(DefaultPatterns)
+
+// Default returns the redactor for DefaultPatterns
+func Default() *Redactor {
+	return defaultRedactor
+}
+
+// New returns a redactor for patterns, case-insensitive regular expressions
+// matched anywhere in a variable name. No patterns means DefaultPatterns.
+func New(patterns []string) (*Redactor, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", p, err)
+		}
+	}
+	names, err := regexp.Compile("(?i)(?:" + strings.Join(patterns, ")|(?:") + ")")
+	if err != nil {
+		return nil, err
+	}
+	return &Redactor{names: names}, nil
+}
+
+// MustNew is New for patterns known to be valid
+func MustNew(patterns []string) *Redactor {
+	r, err := New(patterns)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Secret reports whether the variable name holds a secret
+func (r *Redactor) Secret(name string) bool {
+	return r.names.MatchString(name)
+}
+
+// Value returns value, or Mask when name is secret
+func (r *Redactor) Value(name, value string) string {
+	if value != "" && r.Secret(name) {
+		return Mask
+	}
+	return value
+}
+
+// Env returns a copy of env with the values of secret variables masked
+func (r *Redactor) Env(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// String masks the values of secret variables assigned in s
+func (r *Redactor) String(s string) string {
+	var b strings.Builder
+	last, pos := 0, 0
+	for pos < len(s) {
+		m := assignment.FindStringSubmatchIndex(s[pos:])
+		if m == nil {
+			break
+		}
+		for i := range m {
+			m[i] += pos
+		}
+
+		if !r.Secret(s[m[2]:m[3]]) {
+			// The value may itself be an assignment, as in "env: KEY: v"
+			pos = m[5]
+			continue
+		}
+
+		// Keep everything up to the value, and its quotes. Unquoted values
+		// lose a trailing colon, which separates wrapped errors.
+		valueStart, valueEnd := m[6], m[7]
+		if q := s[valueStart]; q == '"' || q == '\'' {
+			valueStart++
+			valueEnd--
+		} else {
+			valueEnd = valueStart + len(strings.TrimRight(s[valueStart:valueEnd], ":"))
+		}
+		b.WriteString(s[last:valueStart])
+		b.WriteString(Mask)
+		last, pos = valueEnd, valueEnd
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Values returns a copy of md with String applied to every value
+func (r *Redactor) Values(md map[string]string) map[string]string {
+	if md == nil {
+		return nil
+	}
+	out := make(map[string]string, len(md))
+	for k, v := range md {
+		out[k] = r.String(v)
+	}
+	return out
+}
+
+// Error returns err with its message redacted. The original stays
+// reachable through errors.Is and errors.As.
+func (r *Redactor) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if redacted := r.String(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+	return err
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,62 @@
+package redact
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	r := Default()
+	for in, want := range map[string]string{
+		"DB_PASSWORD=hunter2 DB_HOST=db1":               "DB_PASSWORD=****** DB_HOST=db1",
+		"environment: API_TOKEN: abc123":                "environment: API_TOKEN: ******",
+		`{"aws_secret_access_key": "AKIA/xyz", "n": 1}`: `{"aws_secret_access_key": "******", "n": 1}`,
+		"export GITHUB_TOKEN='ghp_1'; echo ok":          "export GITHUB_TOKEN='******'; echo ok",
+		"image: nginx:1.27":                             "image: nginx:1.27",
+		"no assignments here":                           "no assignments here",
+	} {
+		if got := r.String(in); got != want {
+			t.Errorf("String(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	r, err := New([]string{"^PG_", "ssn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.String("PG_USER=app SSN=123 PASSWORD=x"); got != "PG_USER=****** SSN=****** PASSWORD=x" {
+		t.Errorf("configured patterns: %q", got)
+	}
+	if _, err := New([]string{"("}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	env := map[string]string{"DB_PASSWORD": "hunter2", "DB_HOST": "db1"}
+	got := Default().Env(env)
+	if got["DB_PASSWORD"] != Mask || got["DB_HOST"] != "db1" {
+		t.Errorf("Env = %v", got)
+	}
+	if env["DB_PASSWORD"] != "hunter2" {
+		t.Error("Env changed its argument")
+	}
+}
+
+func TestError(t *testing.T) {
+	err := Default().Error(&os.PathError{Op: "run", Path: "REDIS_PASSWORD=s3cret", Err: os.ErrPermission})
+	if err.Error() != "run REDIS_PASSWORD=******: permission denied" {
+		t.Errorf("Error = %q", err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Error("redacted error lost its cause")
+	}
+
+	plain := errors.New("compose up failed")
+	if Default().Error(plain) != plain {
+		t.Error("an error without secrets was replaced")
+	}
+}
@@ -0,0 +1,150 @@
+// Package report builds chargeback reports from stack resource snapshots.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StackRow is one stack's resources at snapshot time. Reservations come from
+// the compose file; Running counts containers actually running.
+type StackRow struct {
+	AgentID     string            `json:"agent_id"`
+	Namespace   string            `json:"namespace"`
+	Stack       string            `json:"stack"`
+	State       string            `json:"state"`
+	Team        string            `json:"team,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Containers  int               `json:"containers"`
+	Running     int               `json:"running"`
+	CPUs        float64           `json:"cpus"`
+	MemoryBytes int64             `json:"memory_bytes"`
+}
+
+// Total aggregates rows sharing a namespace or team
+type Total struct {
+	Key         string  `json:"key"`
+	Stacks      int     `json:"stacks"`
+	Containers  int     `json:"containers"`
+	Running     int     `json:"running"`
+	CPUs        float64 `json:"cpus"`
+	MemoryBytes int64   `json:"memory_bytes"`
+}
+
+// Snapshot is a point-in-time report
+type Snapshot struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Stacks      []StackRow `json:"stacks"`
+	Namespaces  []Total    `json:"namespaces"`
+	Teams       []Total    `json:"teams"`
+	// Errors lists agents that could not be read, by agent ID; their stacks
+	// are missing from the snapshot
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// New sorts rows and computes the namespace and team totals
+func New(at time.Time, rows []StackRow) *Snapshot {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		if rows[i].Stack != rows[j].Stack {
+			return rows[i].Stack < rows[j].Stack
+		}
+		return rows[i].AgentID < rows[j].AgentID
+	})
+
+	return &Snapshot{
+		GeneratedAt: at,
+		Stacks:      rows,
+		Namespaces:  totals(rows, func(r StackRow) string { return r.Namespace }),
+		Teams:       totals(rows, func(r StackRow) string { return r.Team }),
+	}
+}
+
+// totals groups rows by key; rows without one are reported as "-"
+func totals(rows []StackRow, key func(StackRow) string) []Total {
+	byKey := make(map[string]*Total)
+	for _, r := range rows {
+		k := key(r)
+		if k == "" {
+			k = "-"
+		}
+		t, ok := byKey[k]
+		if !ok {
+			t = &Total{Key: k}
+			byKey[k] = t
+		}
+		t.Stacks++
+		t.Containers += r.Containers
+		t.Running += r.Running
+		t.CPUs += r.CPUs
+		t.MemoryBytes += r.MemoryBytes
+	}
+
+	result := make([]Total, 0, len(byKey))
+	for _, t := range byKey {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// WriteJSON writes the snapshot including totals
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteCSV writes one row per stack, the shape billing spreadsheets expect
+func (s *Snapshot) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"generated_at", "agent_id", "namespace", "stack", "state", "team", "owner",
+		"containers", "running", "cpus", "memory_bytes", "labels",
+	})
+
+	at := s.GeneratedAt.UTC().Format(time.RFC3339)
+	for _, r := range s.Stacks {
+		cw.Write([]string{
+			at, r.AgentID, r.Namespace, r.Stack, r.State, r.Team, r.Owner,
+			strconv.Itoa(r.Containers),
+			strconv.Itoa(r.Running),
+			strconv.FormatFloat(r.CPUs, 'f', -1, 64),
+			strconv.FormatInt(r.MemoryBytes, 10),
+			joinLabels(r.Labels),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write encodes the snapshot in format "json" or "csv"
+func (s *Snapshot) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return s.WriteJSON(w)
+	case "csv":
+		return s.WriteCSV(w)
+	default:
+		return fmt.Errorf("unknown report format %q (want json or csv)", format)
+	}
+}
+
+func joinLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
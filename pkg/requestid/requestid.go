@@ -0,0 +1,132 @@
+// Package requestid carries the correlation ID of a request from the CLI
+// through the core to agents, so the logs, operation records and audit
+// entries a single command leaves behind can be found together.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Header carries the request ID in gRPC metadata, both ways
+const Header = "x-request-id"
+
+// maxLen bounds IDs sent by callers, which end up in logs
+const maxLen = 128
+
+type contextKey struct{}
+
+// New returns a random request ID
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether id may be used as a request ID: at most 128
+// letters, digits, dots, dashes, underscores and colons
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// With returns ctx carrying id
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the request ID ctx carries, or "" outside a request
+func From(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logf logs a line about the request in ctx, prefixed with its ID
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	if id := From(ctx); id != "" {
+		format = "[" + id + "] " + format
+	}
+	log.Printf(format, args...)
+}
+
+// LogFailure logs a call that failed on the server's side rather than the
+// caller's, so the ID a user reports leads to it
+func LogFailure(ctx context.Context, method string, err error) {
+	switch status.Code(err) {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.DataLoss:
+		Logf(ctx, "%s failed: %v", method, err)
+	}
+}
+
+// fromCaller returns the valid ID the caller sent with the call in ctx, or
+// a new one
+func fromCaller(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(Header); len(values) > 0 && Valid(values[0]) {
+			return values[0]
+		}
+	}
+	return New()
+}
+
+// UnaryServerInterceptor gives each call the request ID its caller sent,
+// or a new one, and sends it back in the response header
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := fromCaller(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(Header, id))
+	return handler(With(ctx, id), req)
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := fromCaller(ss.Context())
+	ss.SetHeader(metadata.Pairs(Header, id))
+	return handler(srv, &serverStream{ServerStream: ss, ctx: With(ss.Context(), id)})
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// outgoing adds the request ID of ctx to the metadata of a call made for
+// it, unless the call already carries one
+func outgoing(ctx context.Context) context.Context {
+	id := From(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(Header)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, Header, id)
+}
+
+// UnaryClientInterceptor passes the request ID of ctx on to the server
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(outgoing(ctx), desc, cc, method, opts...)
+}
@@ -0,0 +1,64 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"3f2a9c41d07e5b86", true},
+		{"deploy-42_retry.1:eu", true},
+		{"", false},
+		{"with space", false},
+		{"line\nbreak", false},
+		{strings.Repeat("a", maxLen), true},
+		{strings.Repeat("a", maxLen+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	if id := New(); !Valid(id) || id == New() {
+		t.Errorf("New() = %q, want a valid random ID", id)
+	}
+}
+
+func TestFromCaller(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "support-123"))
+	if got := fromCaller(ctx); got != "support-123" {
+		t.Errorf("fromCaller() = %q, want the caller's ID", got)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "not valid"))
+	if got := fromCaller(ctx); got == "not valid" || !Valid(got) {
+		t.Errorf("fromCaller() with an invalid ID = %q, want a new one", got)
+	}
+}
+
+func TestOutgoing(t *testing.T) {
+	if ctx := outgoing(context.Background()); ctx != context.Background() {
+		t.Error("outgoing() added metadata outside a request")
+	}
+
+	ctx := outgoing(With(context.Background(), "support-123"))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(Header); len(got) != 1 || got[0] != "support-123" {
+		t.Errorf("outgoing metadata %s = %v, want support-123", Header, got)
+	}
+
+	// Calls that already carry an ID, such as proxied ones, keep it
+	ctx = metadata.AppendToOutgoingContext(With(context.Background(), "support-123"), Header, "upstream")
+	md, _ = metadata.FromOutgoingContext(outgoing(ctx))
+	if got := md.Get(Header); len(got) != 1 || got[0] != "upstream" {
+		t.Errorf("outgoing metadata %s = %v, want only upstream", Header, got)
+	}
+}
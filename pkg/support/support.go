@@ -0,0 +1,89 @@
+// Package support writes support bundles: gzipped tarballs of configuration,
+// versions, logs and health data to attach to bug reports. Every file added
+// to a bundle is redacted first so secrets and keys never leave the host.
+package support
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secret values
+const Redacted = "[REDACTED]"
+
+var (
+	// pemPrivateKey matches PEM blocks holding private keys
+	pemPrivateKey = regexp.MustCompile(`(?s)-----BEGIN ([A-Z0-9 ]*PRIVATE KEY)-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+
+	// secretAssignment matches key/value pairs whose key names a secret, in
+	// YAML ("password: x"), JSON ("token": "x") and environment (SECRET=x) form
+	secretAssignment = regexp.MustCompile(`(?i)("?[\w.-]*(?:password|passwd|secret|token|api[_-]?key|private[_-]?key|credential|access[_-]?key)[\w.-]*"?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,}\]]+)`)
+
+	// bearer matches HTTP authorization values
+	bearer = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+
+	// urlCredentials matches user:password@ in URLs
+	urlCredentials = regexp.MustCompile(`(://[^/\s:@]+):[^/\s@]+@`)
+)
+
+// Redact removes private keys, secret-named values, authorization headers
+// and URL passwords from data
+func Redact(data []byte) []byte {
+	s := pemPrivateKey.ReplaceAllString(string(data), "-----BEGIN $1-----\n"+Redacted+"\n-----END $1-----")
+	s = secretAssignment.ReplaceAllStringFunc(s, func(m string) string {
+		parts := secretAssignment.FindStringSubmatch(m)
+		value := parts[2]
+		if quote := value[0]; quote == '"' || quote == '\'' {
+			return parts[1] + string(quote) + Redacted + string(quote)
+		}
+		return parts[1] + Redacted
+	})
+	s = bearer.ReplaceAllString(s, "$1 "+Redacted)
+	s = urlCredentials.ReplaceAllString(s, "$1:"+Redacted+"@")
+	return []byte(s)
+}
+
+// Bundle writes redacted files into a gzipped tarball under a common
+// directory
+type Bundle struct {
+	gz   *gzip.Writer
+	tw   *tar.Writer
+	dir  string
+	time time.Time
+}
+
+// New starts a bundle on w. Files are placed under dir and stamped with now.
+func New(w io.Writer, dir string, now time.Time) *Bundle {
+	gz := gzip.NewWriter(w)
+	return &Bundle{gz: gz, tw: tar.NewWriter(gz), dir: strings.Trim(dir, "/"), time: now}
+}
+
+// Add redacts data and writes it as name, a slash-separated path inside the
+// bundle directory. Names cannot escape the directory.
+func (b *Bundle) Add(name string, data []byte) error {
+	data = Redact(data)
+	hdr := &tar.Header{
+		Name:    b.dir + path.Clean("/"+name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: b.time,
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+// Close flushes the tarball. It does not close the underlying writer.
+func (b *Bundle) Close() error {
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
@@ -0,0 +1,354 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/agent/operation"
+	"github.com/bhangun/mandau/pkg/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Agent serves the stack, container and operations services the way
+// mandau-agent does, on top of a fake Docker. Operations go through the real
+// operation manager, so idempotency keys and operation listings behave as
+// on an agent.
+type Agent struct {
+	agentv1.UnimplementedStackServiceServer
+	agentv1.UnimplementedContainerServiceServer
+	agentv1.UnimplementedOperationsServiceServer
+
+	ID           string
+	Capabilities []string
+	Docker       *Docker
+	ops          *operation.Manager
+}
+
+// NewAgent returns an agent named id running nothing
+func NewAgent(id string, capabilities ...string) *Agent {
+	return &Agent{
+		ID:           id,
+		Capabilities: capabilities,
+		Docker:       NewDocker(),
+		ops:          operation.NewManager(),
+	}
+}
+
+// Operations returns the agent's operation manager
+func (a *Agent) Operations() *operation.Manager {
+	return a.ops
+}
+
+func (a *Agent) register(server *grpc.Server) {
+	agentv1.RegisterStackServiceServer(server, a)
+	agentv1.RegisterContainerServiceServer(server, a)
+	agentv1.RegisterOperationsServiceServer(server, a)
+}
+
+func (a *Agent) ListStacks(ctx context.Context, req *agentv1.ListStacksRequest) (*agentv1.ListStacksResponse, error) {
+	resp := &agentv1.ListStacksResponse{}
+	for _, s := range a.Docker.Stacks() {
+		if req.Namespace != "" && s.Namespace != req.Namespace {
+			continue
+		}
+		if !matchLabels(s.Labels, req.Labels) {
+			continue
+		}
+		stack := stackToProto(s)
+		if req.DiskUsage {
+			// Only the compose file is stored
+			stack.DiskUsage = &agentv1.StackDiskUsage{DirectoryBytes: int64(len(s.Compose))}
+		}
+		resp.Stacks = append(resp.Stacks, stack)
+	}
+	return resp, nil
+}
+
+func (a *Agent) GetStack(ctx context.Context, req *agentv1.GetStackRequest) (*agentv1.GetStackResponse, error) {
+	s, err := a.stack(req.StackId, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &agentv1.GetStackResponse{Stack: stackToProto(s)}, nil
+}
+
+func (a *Agent) ApplyStack(req *agentv1.ApplyStackRequest, stream agentv1.StackService_ApplyStackServer) error {
+	namespace := normalizeNamespace(req.Namespace)
+	if s, ok := a.Docker.Stack(req.StackName); ok && s.Namespace != namespace {
+		return status.Errorf(codes.FailedPrecondition, "apply stack: stack %s belongs to namespace %s", req.StackName, s.Namespace)
+	}
+
+	return a.runOperation(stream.Context(), operation.OperationTypeStackApply, req.StackName, operation.NewIdempotency(req.IdempotencyKey, req),
+		"Applying stack", func() error {
+			return a.Docker.Up(req.StackName, namespace, req.ComposeContent, req.Labels)
+		}, stream.Send)
+}
+
+func (a *Agent) RemoveStack(req *agentv1.RemoveStackRequest, stream agentv1.StackService_RemoveStackServer) error {
+	idem := operation.NewIdempotency(req.IdempotencyKey, req)
+	// A retry after the stack is gone still gets its operation
+	if opID, _ := a.ops.FindIdempotent(idem); opID == "" {
+		if _, err := a.stack(req.StackId, req.Namespace); err != nil {
+			return err
+		}
+	}
+
+	return a.runOperation(stream.Context(), operation.OperationTypeStackRemove, req.StackId, idem,
+		"Removing stack", func() error {
+			return a.Docker.Down(req.StackId)
+		}, stream.Send)
+}
+
+func (a *Agent) DiffStack(ctx context.Context, req *agentv1.DiffStackRequest) (*agentv1.DiffStackResponse, error) {
+	if s, ok := a.Docker.Stack(req.StackName); ok && s.Namespace != normalizeNamespace(req.Namespace) {
+		return nil, status.Errorf(codes.NotFound, "stack not found: %s", req.StackName)
+	}
+
+	changes, err := a.Docker.Diff(req.StackName, req.NewComposeContent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "diff stack: %v", err)
+	}
+
+	resp := &agentv1.DiffStackResponse{HasChanges: len(changes) > 0}
+	for _, c := range changes {
+		action := agentv1.DiffAction_DIFF_ACTION_UPDATE
+		switch c.Action {
+		case "create":
+			action = agentv1.DiffAction_DIFF_ACTION_CREATE
+		case "delete":
+			action = agentv1.DiffAction_DIFF_ACTION_DELETE
+		}
+		resp.Services = append(resp.Services, &agentv1.ServiceDiff{Name: c.Name, Action: action})
+	}
+	return resp, nil
+}
+
+func (a *Agent) ExportStack(ctx context.Context, req *agentv1.ExportStackRequest) (*agentv1.StackExport, error) {
+	s, err := a.stack(req.StackName, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	compose := s.Compose
+	if !req.RevealSecrets {
+		compose = redact.Default().String(compose)
+	}
+	return &agentv1.StackExport{
+		Name:           s.Name,
+		Namespace:      s.Namespace,
+		ComposeContent: compose,
+		Labels:         s.Labels,
+		State:          agentv1.StackState_STACK_STATE_RUNNING,
+		Containers:     containersToProto(s),
+		ExportedAt:     timestamppb.Now(),
+		Redacted:       !req.RevealSecrets,
+	}, nil
+}
+
+func (a *Agent) ListContainers(ctx context.Context, req *agentv1.ListContainersRequest) (*agentv1.ListContainersResponse, error) {
+	resp := &agentv1.ListContainersResponse{}
+	for _, s := range a.Docker.Stacks() {
+		resp.Containers = append(resp.Containers, containersToProto(s)...)
+	}
+	return resp, nil
+}
+
+// Exec runs the command of the first message and answers with its output and
+// exit code. Stdin and resize messages are not used.
+func (a *Agent) Exec(stream agentv1.ContainerService_ExecServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "exec: no start message")
+	}
+	if err != nil {
+		return err
+	}
+	start := req.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "exec: the first message must start the command")
+	}
+
+	stdout, code, err := a.Docker.RunExec(start.ContainerId, start.Cmd, nil)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "exec: %v", err)
+	}
+	if len(stdout) > 0 {
+		if err := stream.Send(&agentv1.ExecResponse{Payload: &agentv1.ExecResponse_Stdout{Stdout: stdout}}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&agentv1.ExecResponse{Payload: &agentv1.ExecResponse_ExitCode{ExitCode: int32(code)}})
+}
+
+func (a *Agent) GetOperation(ctx context.Context, req *agentv1.GetOperationRequest) (*agentv1.Operation, error) {
+	op, err := a.ops.GetOperation(req.OperationId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+	return operationToProto(op), nil
+}
+
+func (a *Agent) ListOperations(ctx context.Context, req *agentv1.ListOperationsRequest) (*agentv1.ListOperationsResponse, error) {
+	ops := a.ops.ListOperations(func(op *operation.Operation) bool {
+		return req.IdempotencyKey == "" || op.IdempotencyKey == req.IdempotencyKey
+	})
+	sort.Slice(ops, func(i, j int) bool { return ops[i].CreatedAt.After(ops[j].CreatedAt) })
+
+	resp := &agentv1.ListOperationsResponse{}
+	for _, op := range ops {
+		resp.Operations = append(resp.Operations, operationToProto(op))
+	}
+	return resp, nil
+}
+
+// stack returns the stack name, hiding stacks of other namespaces as the
+// agent does
+func (a *Agent) stack(name, namespace string) (Stack, error) {
+	s, ok := a.Docker.Stack(name)
+	if !ok || s.Namespace != normalizeNamespace(namespace) {
+		return Stack{}, status.Errorf(codes.NotFound, "stack not found: %s", name)
+	}
+	return s, nil
+}
+
+// runOperation starts run as an operation, or finds the one a request with
+// idem's key started, and streams its events until it ends
+func (a *Agent) runOperation(ctx context.Context, opType operation.OperationType, stack string, idem operation.Idempotency,
+	message string, run func() error, send func(*agentv1.OperationEvent) error) error {
+	opID, err := a.ops.FindIdempotent(idem)
+	if errors.Is(err, operation.ErrKeyReused) {
+		return status.Errorf(codes.AlreadyExists, "%v", err)
+	}
+
+	started := opID == ""
+	if started {
+		opID = a.ops.CreateIdempotentOperation(ctx, opType, map[string]string{"stack": stack}, idem)
+	}
+
+	events := a.ops.Subscribe(opID)
+	defer a.ops.Unsubscribe(opID, events)
+
+	if started {
+		go func() {
+			a.ops.SetState(opID, operation.OperationStateRunning)
+			a.ops.EmitEvent(opID, message)
+			if err := run(); err != nil {
+				a.ops.SetError(opID, err)
+				return
+			}
+			a.ops.SetCompleted(opID)
+		}()
+	}
+
+	for event := range events {
+		resp := &agentv1.OperationEvent{
+			OperationId: event.OperationID,
+			State:       operationStateToProto(event.State),
+			Timestamp:   timestamppb.New(event.Timestamp),
+			Message:     event.Message,
+			Progress:    int32(event.Progress),
+		}
+		if event.Error != nil {
+			resp.Error = event.Error.Error()
+		}
+		if err := send(resp); err != nil {
+			return err
+		}
+
+		switch event.State {
+		case operation.OperationStateCompleted, operation.OperationStateFailed, operation.OperationStateCancelled:
+			return nil
+		}
+	}
+	return nil
+}
+
+func stackToProto(s Stack) *agentv1.Stack {
+	return &agentv1.Stack{
+		Id:         s.Name,
+		Name:       s.Name,
+		State:      agentv1.StackState_STACK_STATE_RUNNING,
+		Containers: containersToProto(s),
+		CreatedAt:  timestamppb.New(s.CreatedAt),
+		UpdatedAt:  timestamppb.New(s.UpdatedAt),
+		Labels:     s.Labels,
+		Namespace:  s.Namespace,
+	}
+}
+
+func containersToProto(s Stack) []*agentv1.Container {
+	var containers []*agentv1.Container
+	for _, svc := range s.Services {
+		name := containerName(s.Name, svc)
+		containers = append(containers, &agentv1.Container{
+			Id:      name,
+			Name:    name,
+			State:   "running",
+			Status:  "Up",
+			Created: timestamppb.New(s.UpdatedAt),
+			Labels: map[string]string{
+				"com.docker.compose.project": s.Name,
+				"com.docker.compose.service": svc,
+			},
+		})
+	}
+	return containers
+}
+
+func operationToProto(op *operation.Operation) *agentv1.Operation {
+	result := &agentv1.Operation{
+		Id:             op.ID,
+		Type:           string(op.Type),
+		State:          operationStateToProto(op.State),
+		CreatedAt:      timestamppb.New(op.CreatedAt),
+		Metadata:       op.Metadata,
+		Progress:       int32(op.Progress),
+		IdempotencyKey: op.IdempotencyKey,
+		RequestId:      op.RequestID,
+	}
+	if op.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*op.CompletedAt)
+	}
+	if op.Error != nil {
+		result.Error = op.Error.Error()
+	}
+	return result
+}
+
+func operationStateToProto(state operation.OperationState) agentv1.OperationState {
+	switch state {
+	case operation.OperationStateRunning:
+		return agentv1.OperationState_OPERATION_STATE_RUNNING
+	case operation.OperationStateCompleted:
+		return agentv1.OperationState_OPERATION_STATE_COMPLETED
+	case operation.OperationStateFailed:
+		return agentv1.OperationState_OPERATION_STATE_FAILED
+	case operation.OperationStateCancelled:
+		return agentv1.OperationState_OPERATION_STATE_CANCELLED
+	default:
+		return agentv1.OperationState_OPERATION_STATE_PENDING
+	}
+}
+
+func normalizeNamespace(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
+// matchLabels reports whether labels contain every key/value in selector
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,554 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"github.com/bhangun/mandau/pkg/capability"
+	"github.com/bhangun/mandau/pkg/config"
+	"github.com/bhangun/mandau/pkg/requestid"
+	"github.com/bhangun/mandau/pkg/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const webCompose = `services:
+  web:
+    image: nginx:1.27
+  cache:
+    image: redis:7
+`
+
+// events drains an operation stream
+func events(t *testing.T, stream grpc.ServerStreamingClient[agentv1.OperationEvent]) []*agentv1.OperationEvent {
+	t.Helper()
+	var all []*agentv1.OperationEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return all
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, event)
+	}
+}
+
+func lastState(events []*agentv1.OperationEvent) agentv1.OperationState {
+	if len(events) == 0 {
+		return agentv1.OperationState_OPERATION_STATE_PENDING
+	}
+	return events[len(events)-1].State
+}
+
+func TestStackLifecycle(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+	ctx := context.Background()
+
+	stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lastState(events(t, stream)); got != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+		t.Fatalf("apply ended %v, want completed", got)
+	}
+	if s, ok := cluster.Agent("agent-1").Docker.Stack("web"); !ok || len(s.Services) != 2 {
+		t.Fatalf("agent runs %+v, want web with two services", s)
+	}
+
+	list, err := stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Stacks) != 1 || len(list.Stacks[0].Containers) != 2 {
+		t.Fatalf("stacks = %v, want web with two containers", list.Stacks)
+	}
+
+	// The core finds the agent running the stack
+	diff, err := stacks.DiffStack(ctx, &agentv1.DiffStackRequest{
+		StackName:         "web",
+		NewComposeContent: "services:\n  web:\n    image: nginx:1.28\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]agentv1.DiffAction{
+		"web":   agentv1.DiffAction_DIFF_ACTION_UPDATE,
+		"cache": agentv1.DiffAction_DIFF_ACTION_DELETE,
+	}
+	if !diff.HasChanges || len(diff.Services) != len(want) {
+		t.Fatalf("diff = %v, want %v", diff.Services, want)
+	}
+	for _, svc := range diff.Services {
+		if want[svc.Name] != svc.Action {
+			t.Errorf("diff of %s = %v, want %v", svc.Name, svc.Action, want[svc.Name])
+		}
+	}
+
+	stream, err = stacks.RemoveStack(ctx, &agentv1.RemoveStackRequest{StackId: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lastState(events(t, stream)); got != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+		t.Fatalf("remove ended %v, want completed", got)
+	}
+	if _, ok := cluster.Agent("agent-1").Docker.Stack("web"); ok {
+		t.Error("stack web still runs after removal")
+	}
+}
+
+func TestExportStack(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+	ctx := context.Background()
+
+	compose := "services:\n  db:\n    image: postgres:16\n    environment:\n      POSTGRES_PASSWORD: hunter2\n"
+	stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "db", ComposeContent: compose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events(t, stream)
+
+	export, err := stacks.ExportStack(ctx, &agentv1.ExportStackRequest{StackName: "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.AgentId != "agent-1" || len(export.Containers) != 1 {
+		t.Errorf("export = %v, want db on agent-1 with one container", export)
+	}
+	if !export.Redacted || strings.Contains(export.ComposeContent, "hunter2") {
+		t.Errorf("export shows the password:\n%s", export.ComposeContent)
+	}
+
+	export, err = stacks.ExportStack(ctx, &agentv1.ExportStackRequest{StackName: "db", RevealSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Redacted || export.ComposeContent != compose {
+		t.Errorf("revealed export = %q, want the stored compose file", export.ComposeContent)
+	}
+}
+
+func TestListStacksDiskUsage(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+	ctx := context.Background()
+
+	stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events(t, stream)
+
+	resp, err := stacks.ListStacks(ctx, &agentv1.ListStacksRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Stacks) != 1 || resp.Stacks[0].DiskUsage != nil {
+		t.Fatalf("stacks = %v, want web without disk usage", resp.Stacks)
+	}
+
+	// Cached plain listings must not answer listings asking for usage
+	resp, err = stacks.ListStacks(ctx, &agentv1.ListStacksRequest{DiskUsage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Stacks) != 1 || resp.Stacks[0].GetDiskUsage().GetDirectoryBytes() != int64(len(webCompose)) {
+		t.Errorf("stacks = %v, want web using %d bytes", resp.Stacks, len(webCompose))
+	}
+}
+
+func TestApplyFailure(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	cluster.Agent("agent-1").Docker.FailNext("web", errors.New("pull access denied"))
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+
+	stream, err := stacks.ApplyStack(context.Background(), &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := events(t, stream)
+	if got := lastState(all); got != agentv1.OperationState_OPERATION_STATE_FAILED {
+		t.Fatalf("apply ended %v, want failed", got)
+	}
+	if all[len(all)-1].Error != "pull access denied" {
+		t.Errorf("error = %q, want the engine's", all[len(all)-1].Error)
+	}
+}
+
+func TestIdempotentApply(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	conn := cluster.Dial("alice")
+	stacks := agentv1.NewStackServiceClient(conn)
+	ctx := context.Background()
+
+	req := &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose, IdempotencyKey: "deploy-42"}
+	var ids []string
+	for i := 0; i < 2; i++ {
+		stream, err := stacks.ApplyStack(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all := events(t, stream)
+		if lastState(all) != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+			t.Fatalf("apply %d ended %v", i, lastState(all))
+		}
+		ids = append(ids, all[0].OperationId)
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("retry started operation %s, want %s", ids[1], ids[0])
+	}
+
+	ops, err := agentv1.NewOperationsServiceClient(conn).ListOperations(ctx, &agentv1.ListOperationsRequest{AgentId: "agent-1", IdempotencyKey: "deploy-42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops.Operations) != 1 || ops.Operations[0].Id != ids[0] {
+		t.Errorf("operations = %v, want only %s", ops.Operations, ids[0])
+	}
+
+	// The same key on a different request is refused
+	changed := proto.Clone(req).(*agentv1.ApplyStackRequest)
+	changed.ComposeContent = "services:\n  web:\n    image: nginx:1.28\n"
+	stream, err := stacks.ApplyStack(ctx, changed)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("apply with a reused key: %v, want already exists", err)
+	}
+}
+
+func TestRequestIDReachesAgent(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	conn := cluster.Dial("alice")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestid.Header, "support-123")
+
+	stream, err := agentv1.NewStackServiceClient(conn).ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(requestid.Header); len(got) != 1 || got[0] != "support-123" {
+		t.Errorf("response header %s = %v, want support-123", requestid.Header, got)
+	}
+	all := events(t, stream)
+	if lastState(all) != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+		t.Fatalf("apply ended %v", lastState(all))
+	}
+
+	op, err := cluster.Agent("agent-1").Operations().GetOperation(all[0].OperationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.RequestID != "support-123" {
+		t.Errorf("agent operation request ID = %q, want support-123", op.RequestID)
+	}
+
+	// Calls without one get a fresh ID
+	var md metadata.MD
+	if _, err := agentv1.NewCoreServiceClient(conn).ListAgents(context.Background(), &agentv1.ListAgentsRequest{}, grpc.Header(&md)); err != nil {
+		t.Fatal(err)
+	}
+	if got := md.Get(requestid.Header); len(got) != 1 || !requestid.Valid(got[0]) {
+		t.Errorf("response header %s = %v, want a generated ID", requestid.Header, got)
+	}
+}
+
+func TestExecThroughProxy(t *testing.T) {
+	cluster := NewCluster(t, Options{Config: &config.CoreConfig{Proxy: config.ProxyConfig{Routes: []config.ProxyRoute{
+		{Method: "/mandau.agent.v1.ContainerService/*", Resource: "container:*", Write: true, Capability: "container"},
+	}}}})
+	conn := cluster.Dial("alice")
+	ctx := context.Background()
+
+	stream, err := agentv1.NewStackServiceClient(conn).ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events(t, stream)
+
+	// Exec requests carry no agent ID; the header names the agent
+	ctx = metadata.AppendToOutgoingContext(ctx, transport.AgentHeader, "agent-1")
+	exec, err := agentv1.NewContainerServiceClient(conn).Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = exec.Send(&agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Start{Start: &agentv1.ExecStart{
+		ContainerId: "web-web-1",
+		Cmd:         []string{"echo", "hello"},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.CloseSend()
+
+	var stdout string
+	exitCode := int32(-1)
+	for {
+		resp, err := exec.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch p := resp.Payload.(type) {
+		case *agentv1.ExecResponse_Stdout:
+			stdout += string(p.Stdout)
+		case *agentv1.ExecResponse_ExitCode:
+			exitCode = p.ExitCode
+		}
+	}
+	if stdout != "hello\n" || exitCode != 0 {
+		t.Errorf("exec = %q exit %d, want hello exit 0", stdout, exitCode)
+	}
+}
+
+func TestUnknownAgent(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+
+	stream, err := stacks.ApplyStack(context.Background(), &agentv1.ApplyStackRequest{AgentId: "agent-9", StackName: "web", ComposeContent: webCompose})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if d := transport.Detail(err); d.Code != agentv1.ErrorCode_ERROR_CODE_AGENT_NOT_FOUND || d.AgentId != "agent-9" {
+		t.Errorf("apply to an unknown agent: %v (%v), want agent not found", err, d)
+	}
+}
+
+func TestApplyNeedsStackCapability(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	cluster.AddAgent(NewAgent("viewer-1", capability.Docker))
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+	ctx := context.Background()
+
+	stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "viewer-1", StackName: "web", ComposeContent: webCompose})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.FailedPrecondition || transport.Detail(err).Code != agentv1.ErrorCode_ERROR_CODE_CAPABILITY_MISSING {
+		t.Errorf("apply to an agent without the stack capability: %v", err)
+	}
+	if _, ok := cluster.Agent("viewer-1").Docker.Stack("web"); ok {
+		t.Error("the agent ran the stack")
+	}
+
+	// What the agent can do still goes through
+	if _, err := stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "viewer-1"}); err != nil {
+		t.Errorf("list stacks: %v", err)
+	}
+	stream, err = stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", ComposeContent: webCompose})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lastState(events(t, stream)); got != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+		t.Errorf("apply to an agent with the capability ended %v", got)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	stacks := agentv1.NewStackServiceClient(cluster.Dial("alice"))
+	ctx := context.Background()
+
+	for _, req := range []*agentv1.ApplyStackRequest{
+		{AgentId: "agent-1", StackName: "web", Namespace: "team-a", ComposeContent: webCompose},
+		{AgentId: "agent-1", StackName: "legacy", ComposeContent: webCompose},
+	} {
+		stream, err := stacks.ApplyStack(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lastState(events(t, stream)); got != agentv1.OperationState_OPERATION_STATE_COMPLETED {
+			t.Fatalf("apply %s ended %v", req.StackName, got)
+		}
+	}
+
+	// Stacks applied without a namespace are in the default one
+	resp, err := stacks.GetStack(ctx, &agentv1.GetStackRequest{StackId: "legacy", Namespace: "default"})
+	if err != nil || resp.Stack.Namespace != "default" {
+		t.Errorf("legacy stack = %v, %v; want it in the default namespace", resp, err)
+	}
+
+	// web cannot be read, changed or removed from another namespace,
+	// including the default one
+	for _, ns := range []string{"team-b", ""} {
+		if _, err := stacks.GetStack(ctx, &agentv1.GetStackRequest{StackId: "web", Namespace: ns}); status.Code(err) != codes.NotFound {
+			t.Errorf("get from %q: %v, want not found", ns, err)
+		}
+		stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: "web", Namespace: ns, ComposeContent: "services:\n  evil:\n    image: evil\n"})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if err == nil {
+			t.Errorf("apply from %q succeeded", ns)
+		}
+		remove, err := stacks.RemoveStack(ctx, &agentv1.RemoveStackRequest{AgentId: "agent-1", StackId: "web", Namespace: ns})
+		if err == nil {
+			_, err = remove.Recv()
+		}
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("remove from %q: %v, want not found", ns, err)
+		}
+	}
+	if s, ok := cluster.Agent("agent-1").Docker.Stack("web"); !ok || s.Namespace != "team-a" || len(s.Services) != 2 {
+		t.Errorf("web = %+v, want it untouched in team-a", s)
+	}
+
+	// Listing a namespace shows its stacks only
+	list, err := stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "agent-1", Namespace: "team-b"})
+	if err != nil || len(list.Stacks) != 0 {
+		t.Errorf("team-b stacks = %v, %v; want none", list, err)
+	}
+	list, err = stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "agent-1", Namespace: "default"})
+	if err != nil || len(list.Stacks) != 1 || list.Stacks[0].Name != "legacy" {
+		t.Errorf("default stacks = %v, %v; want legacy", list, err)
+	}
+}
+
+func TestMaintenanceRefusesDeploys(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	conn := cluster.Dial("alice")
+	core := agentv1.NewCoreServiceClient(conn)
+	stacks := agentv1.NewStackServiceClient(conn)
+	ctx := context.Background()
+
+	apply := func(name string, emergency bool) error {
+		stream, err := stacks.ApplyStack(ctx, &agentv1.ApplyStackRequest{AgentId: "agent-1", StackName: name, ComposeContent: webCompose, Emergency: emergency})
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	maintenance := func(req *agentv1.SetAgentMaintenanceRequest) {
+		t.Helper()
+		req.AgentId = "agent-1"
+		if _, err := core.SetAgentMaintenance(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	maintenance(&agentv1.SetAgentMaintenanceRequest{Enabled: true, Reason: "kernel upgrade"})
+	err := apply("web", false)
+	if d := transport.Detail(err); status.Code(err) != codes.FailedPrecondition || d.Code != agentv1.ErrorCode_ERROR_CODE_MAINTENANCE || !d.Retryable {
+		t.Errorf("apply in maintenance: %v (%v), want refused as retryable", err, d)
+	}
+	remove, err := stacks.RemoveStack(ctx, &agentv1.RemoveStackRequest{AgentId: "agent-1", StackId: "web"})
+	if err == nil {
+		_, err = remove.Recv()
+	}
+	if transport.Detail(err).Code != agentv1.ErrorCode_ERROR_CODE_MAINTENANCE {
+		t.Errorf("remove in maintenance: %v, want refused", err)
+	}
+	if _, ok := cluster.Agent("agent-1").Docker.Stack("web"); ok {
+		t.Fatal("the agent ran a stack during maintenance")
+	}
+
+	// Reads go on, and emergencies go through
+	if _, err := stacks.ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "agent-1"}); err != nil {
+		t.Errorf("list stacks in maintenance: %v", err)
+	}
+	if err := apply("hotfix", true); err != nil {
+		t.Errorf("emergency apply in maintenance: %v", err)
+	}
+
+	// Lifting maintenance lets deploys through again
+	maintenance(&agentv1.SetAgentMaintenanceRequest{Enabled: false})
+	if err := apply("web", false); err != nil {
+		t.Errorf("apply after maintenance: %v", err)
+	}
+
+	// So does the window running out
+	maintenance(&agentv1.SetAgentMaintenanceRequest{Enabled: true, Duration: durationpb.New(50 * time.Millisecond)})
+	if err := apply("web", false); transport.Detail(err).Code != agentv1.ErrorCode_ERROR_CODE_MAINTENANCE {
+		t.Errorf("apply in a short window: %v, want refused", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := apply("web", false); err != nil {
+		t.Errorf("apply after the window ran out: %v", err)
+	}
+}
+
+func TestDiagnoseAgent(t *testing.T) {
+	cluster := NewCluster(t, Options{})
+	core := agentv1.NewCoreServiceClient(cluster.Dial("alice"))
+
+	report, err := core.DiagnoseAgent(context.Background(), &agentv1.DiagnoseAgentRequest{AgentId: "agent-1", Pings: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rt := report.RoundTrips; rt.Sent != 3 || rt.Failed != 0 || rt.Avg == nil {
+		t.Errorf("round trips = %v", rt)
+	}
+	if sc := report.ServerCertificate; !sc.NameMatches || sc.ExpectedName != "mandau-agent" || sc.Error != "" {
+		t.Errorf("server certificate = %v", sc)
+	}
+	if report.ChannelState != "READY" {
+		t.Errorf("channel state = %s", report.ChannelState)
+	}
+
+	statuses := make(map[string]agentv1.CheckStatus)
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	for name, want := range map[string]agentv1.CheckStatus{
+		"agent registration": agentv1.CheckStatus_CHECK_STATUS_OK,
+		"round trip":         agentv1.CheckStatus_CHECK_STATUS_OK,
+		// Test certificates expire within a day
+		"agent TLS": agentv1.CheckStatus_CHECK_STATUS_WARN,
+		// Fake agents neither heartbeat nor check the core
+		"agent client certificate": agentv1.CheckStatus_CHECK_STATUS_WARN,
+		"agent→core connection":    agentv1.CheckStatus_CHECK_STATUS_WARN,
+	} {
+		if got, ok := statuses[name]; !ok || got != want {
+			t.Errorf("check %q = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := core.DiagnoseAgent(context.Background(), &agentv1.DiagnoseAgentRequest{AgentId: "agent-9"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: %v", err)
+	}
+}
+
+func TestChaosFailsCalls(t *testing.T) {
+	cluster := NewCluster(t, Options{Config: &config.CoreConfig{Chaos: config.ChaosConfig{
+		Enabled:      true,
+		Methods:      []string{"/mandau.agent.v1.StackService/ListStacks"},
+		ErrorPercent: 100,
+	}}})
+	conn := cluster.Dial("alice")
+	ctx := context.Background()
+
+	_, err := agentv1.NewStackServiceClient(conn).ListStacks(ctx, &agentv1.ListStacksRequest{AgentId: "agent-1"})
+	if d := transport.Detail(err); d.Code != agentv1.ErrorCode_ERROR_CODE_UNAVAILABLE || !d.Retryable {
+		t.Errorf("list stacks: %v (%v), want a retryable unavailable error", err, d)
+	}
+
+	// Other methods are left alone
+	if _, err := agentv1.NewCoreServiceClient(conn).ListAgents(ctx, &agentv1.ListAgentsRequest{}); err != nil {
+		t.Errorf("list agents: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Docker stands in for the Docker engine and compose on a fake agent. Stacks
+// are kept in memory; each compose service runs as one container named
+// "<stack>-<service>-1".
+type Docker struct {
+	mu       sync.Mutex
+	stacks   map[string]*Stack
+	failures map[string]error
+
+	// Exec answers exec calls. Nil runs "echo" and fails anything else
+	// with exit code 127.
+	Exec func(container string, cmd []string, stdin []byte) (stdout []byte, exitCode int)
+}
+
+// Stack is a stack as the fake Docker runs it
+type Stack struct {
+	Name      string
+	Namespace string
+	Compose   string
+	Services  []string
+	Labels    map[string]string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ServiceChange is a service the diff of a compose file touches
+type ServiceChange struct {
+	Name   string
+	Action string // "create", "update" or "delete"
+}
+
+// NewDocker returns a Docker running no stacks
+func NewDocker() *Docker {
+	return &Docker{
+		stacks:   make(map[string]*Stack),
+		failures: make(map[string]error),
+	}
+}
+
+// FailNext makes the next apply or remove of stack fail with err
+func (d *Docker) FailNext(stack string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failures[stack] = err
+}
+
+// Up creates or updates a stack from compose
+func (d *Docker) Up(name, namespace, compose string, labels map[string]string) error {
+	services, err := composeServices(compose)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.takeFailure(name); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s, ok := d.stacks[name]
+	if !ok {
+		s = &Stack{Name: name, CreatedAt: now}
+		d.stacks[name] = s
+	}
+	s.Namespace = namespace
+	s.Compose = compose
+	s.Services = sortedKeys(services)
+	s.UpdatedAt = now
+	if labels != nil {
+		s.Labels = labels
+	}
+	return nil
+}
+
+// Down removes a stack
+func (d *Docker) Down(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.takeFailure(name); err != nil {
+		return err
+	}
+	if _, ok := d.stacks[name]; !ok {
+		return fmt.Errorf("stack not found: %s", name)
+	}
+	delete(d.stacks, name)
+	return nil
+}
+
+// Stack returns a copy of the stack name
+func (d *Docker) Stack(name string) (Stack, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.stacks[name]
+	if !ok {
+		return Stack{}, false
+	}
+	return *s, true
+}
+
+// Stacks returns copies of the running stacks, sorted by name
+func (d *Docker) Stacks() []Stack {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stacks := make([]Stack, 0, len(d.stacks))
+	for _, s := range d.stacks {
+		stacks = append(stacks, *s)
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return stacks
+}
+
+// Diff compares compose with the running stack name, service by service
+func (d *Docker) Diff(name, compose string) ([]ServiceChange, error) {
+	next, err := composeServices(compose)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]string{}
+	if s, ok := d.Stack(name); ok {
+		if current, err = composeServices(s.Compose); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []ServiceChange
+	for _, svc := range sortedKeys(next) {
+		old, ok := current[svc]
+		switch {
+		case !ok:
+			changes = append(changes, ServiceChange{Name: svc, Action: "create"})
+		case old != next[svc]:
+			changes = append(changes, ServiceChange{Name: svc, Action: "update"})
+		}
+	}
+	for _, svc := range sortedKeys(current) {
+		if _, ok := next[svc]; !ok {
+			changes = append(changes, ServiceChange{Name: svc, Action: "delete"})
+		}
+	}
+	return changes, nil
+}
+
+// RunExec runs cmd in container, which must belong to a running stack
+func (d *Docker) RunExec(container string, cmd []string, stdin []byte) ([]byte, int, error) {
+	if !d.hasContainer(container) {
+		return nil, 0, fmt.Errorf("container not found: %s", container)
+	}
+	if d.Exec != nil {
+		stdout, code := d.Exec(container, cmd, stdin)
+		return stdout, code, nil
+	}
+	if len(cmd) > 0 && cmd[0] == "echo" {
+		return []byte(strings.Join(cmd[1:], " ") + "\n"), 0, nil
+	}
+	return nil, 127, nil
+}
+
+func (d *Docker) hasContainer(container string) bool {
+	for _, s := range d.Stacks() {
+		for _, svc := range s.Services {
+			if containerName(s.Name, svc) == container {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// takeFailure returns and clears the failure set for stack. Callers must
+// hold the lock.
+func (d *Docker) takeFailure(stack string) error {
+	err := d.failures[stack]
+	delete(d.failures, stack)
+	return err
+}
+
+func containerName(stack, service string) string {
+	return stack + "-" + service + "-1"
+}
+
+// composeServices returns each service of a compose file, re-encoded with
+// sorted keys so that equal definitions compare equal
+func composeServices(compose string) (map[string]string, error) {
+	var file struct {
+		Services map[string]interface{} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(compose), &file); err != nil {
+		return nil, fmt.Errorf("parse compose file: %w", err)
+	}
+	if len(file.Services) == 0 {
+		return nil, fmt.Errorf("compose file defines no services")
+	}
+
+	services := make(map[string]string, len(file.Services))
+	for name, def := range file.Services {
+		data, err := yaml.Marshal(def)
+		if err != nil {
+			return nil, err
+		}
+		services[name] = string(data)
+	}
+	return services, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
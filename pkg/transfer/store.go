@@ -0,0 +1,345 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for uploads the store does not hold
+	ErrNotFound = errors.New("upload not found")
+	// ErrMismatch is returned when an upload is resumed with another
+	// target, size or digest than it began with
+	ErrMismatch = errors.New("upload does not match the one begun under its ID")
+	// ErrOffset is returned when an upload resumes elsewhere than at what
+	// the store holds of it
+	ErrOffset = errors.New("upload resumed at the wrong offset")
+	// ErrBusy is returned while another call writes the upload
+	ErrBusy = errors.New("upload is in progress elsewhere")
+	// ErrDigest is returned when a complete payload does not have its
+	// digest. Its data is dropped so the upload starts over.
+	ErrDigest = errors.New("payload does not match its digest")
+	// ErrTooLarge is returned for data past the payload's size
+	ErrTooLarge = errors.New("more data than the payload's size")
+)
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// Target is what an upload is for
+type Target struct {
+	Kind      string `json:"kind"`
+	Stack     string `json:"stack"`
+	Namespace string `json:"namespace,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+// Upload is what the store records of an upload
+type Upload struct {
+	ID        string    `json:"id"`
+	Target    Target    `json:"target"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Received  int64     `json:"received"`
+	Delivered bool      `json:"delivered,omitempty"`
+	Images    []string  `json:"images,omitempty"` // Loaded from an image bundle
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Complete reports whether all of the payload has arrived
+func (u *Upload) Complete() bool {
+	return u.Received == u.Size
+}
+
+// Store keeps uploads in a directory: a record of each and the data
+// received so far, until delivered or expired
+type Store struct {
+	dir string
+
+	mu   sync.Mutex
+	busy map[string]bool
+}
+
+// Open opens the store in dir, creating it
+func Open(dir string) (*Store, error) {
+	// Payloads may be anything a stack holds, credentials included
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create transfer dir: %w", err)
+	}
+	return &Store{dir: dir, busy: make(map[string]bool)}, nil
+}
+
+func (s *Store) recordPath(id string) string { return filepath.Join(s.dir, id+".json") }
+func (s *Store) dataPath(id string) string   { return filepath.Join(s.dir, id+".data") }
+
+// Get returns the upload with id
+func (s *Store) Get(id string) (*Upload, error) {
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid upload id %q", id)
+	}
+	data, err := os.ReadFile(s.recordPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var u Upload
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("read upload %s: %w", id, err)
+	}
+	return &u, nil
+}
+
+func (s *Store) save(u *Upload) error {
+	u.UpdatedAt = time.Now()
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	tmp := s.recordPath(u.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.recordPath(u.ID))
+}
+
+// Begin starts or resumes the upload u describes at offset, which must be
+// what the store holds of it. The Writer must be released once the upload
+// is delivered or abandoned.
+func (s *Store) Begin(u Upload, offset int64) (*Writer, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOffset, offset)
+	}
+	return s.start(u, offset)
+}
+
+// Resume is Begin at whatever the store holds of u, for payloads the agent
+// fetches itself
+func (s *Store) Resume(u Upload) (*Writer, error) {
+	return s.start(u, resumeOffset)
+}
+
+// resumeOffset has begin take the upload where it stopped
+const resumeOffset = -1
+
+func (s *Store) start(u Upload, offset int64) (*Writer, error) {
+	if !validID.MatchString(u.ID) {
+		return nil, fmt.Errorf("invalid upload id %q", u.ID)
+	}
+	if u.Size < 0 || len(u.SHA256) != 64 {
+		return nil, errors.New("upload needs its size and SHA-256 digest")
+	}
+
+	s.mu.Lock()
+	if s.busy[u.ID] {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrBusy, u.ID)
+	}
+	s.busy[u.ID] = true
+	s.mu.Unlock()
+
+	w, err := s.begin(u, offset)
+	if err != nil {
+		s.release(u.ID)
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *Store) begin(u Upload, offset int64) (*Writer, error) {
+	stored, err := s.Get(u.ID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		stored = &Upload{ID: u.ID, Target: u.Target, Size: u.Size, SHA256: u.SHA256}
+		// Data left behind without its record is not trusted
+		if err := os.Remove(s.dataPath(u.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err := s.save(stored); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case stored.Target != u.Target || stored.Size != u.Size || stored.SHA256 != u.SHA256:
+		return nil, fmt.Errorf("%w: %s", ErrMismatch, u.ID)
+	}
+
+	if !stored.Delivered {
+		if err := s.reconcile(stored); err != nil {
+			return nil, err
+		}
+	}
+	if offset != resumeOffset && offset != stored.Received {
+		return nil, fmt.Errorf("%w: the agent holds %d bytes of %s, the upload starts at %d", ErrOffset, stored.Received, u.ID, offset)
+	}
+
+	w := &Writer{store: s, upload: stored}
+	if !stored.Delivered {
+		f, err := os.OpenFile(s.dataPath(u.ID), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("open upload data: %w", err)
+		}
+		w.file = f
+	}
+	return w, nil
+}
+
+// reconcile makes u's data what its record says was received: data written
+// after the record was last saved, as by a crash, is cut off, and the
+// record is wound back to data lost before reaching the disk
+func (s *Store) reconcile(u *Upload) error {
+	info, err := os.Stat(s.dataPath(u.ID))
+	var size int64
+	if err == nil {
+		size = info.Size()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	switch {
+	case size < u.Received:
+		u.Received = size
+		return s.save(u)
+	case size > u.Received:
+		return os.Truncate(s.dataPath(u.ID), u.Received)
+	}
+	return nil
+}
+
+func (s *Store) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.busy, id)
+}
+
+// Writer appends to an upload
+type Writer struct {
+	store  *Store
+	upload *Upload
+	file   *os.File // nil once closed, or for delivered uploads
+}
+
+// Upload returns the upload being written
+func (w *Writer) Upload() *Upload {
+	return w.upload
+}
+
+// Write appends p to the payload
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.file == nil {
+		return 0, errors.New("write to a closed or delivered upload")
+	}
+	if w.upload.Received+int64(len(p)) > w.upload.Size {
+		return 0, ErrTooLarge
+	}
+	n, err := w.file.Write(p)
+	w.upload.Received += int64(n)
+	return n, err
+}
+
+// Close syncs what was written and records it, then returns the upload
+func (w *Writer) Close() (*Upload, error) {
+	if w.file != nil {
+		err := w.file.Sync()
+		if cerr := w.file.Close(); err == nil {
+			err = cerr
+		}
+		w.file = nil
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := w.store.save(w.upload); err != nil {
+		return nil, err
+	}
+	return w.upload, nil
+}
+
+// Verify checks a complete payload against its digest and returns the path
+// of its data. A payload that does not match is dropped.
+func (w *Writer) Verify() (string, error) {
+	path := w.store.dataPath(w.upload.ID)
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	digest, err := Digest(f)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+	if digest != w.upload.SHA256 {
+		w.upload.Received = 0
+		if err := os.Remove(path); err != nil {
+			return "", err
+		}
+		if err := w.store.save(w.upload); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: got sha256 %s, want %s", ErrDigest, digest, w.upload.SHA256)
+	}
+	return path, nil
+}
+
+// Delivered records that the payload reached its target and drops its
+// data. The record stays until it expires, so a sender that missed the
+// answer learns the outcome.
+func (w *Writer) Delivered(images []string) error {
+	w.upload.Delivered = true
+	w.upload.Images = images
+	if err := w.store.save(w.upload); err != nil {
+		return err
+	}
+	if err := os.Remove(w.store.dataPath(w.upload.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Release lets the upload be written again
+func (w *Writer) Release() {
+	if w.file != nil {
+		w.Close()
+	}
+	w.store.release(w.upload.ID)
+}
+
+// Expire removes the uploads not written to for age, delivered or not, and
+// returns how many it removed
+func (s *Store) Expire(age time.Duration) (int, error) {
+	records, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, record := range records {
+		id := strings.TrimSuffix(filepath.Base(record), ".json")
+		u, err := s.Get(id)
+		if err != nil || time.Since(u.UpdatedAt) < age {
+			continue
+		}
+
+		s.mu.Lock()
+		if s.busy[id] {
+			s.mu.Unlock()
+			continue
+		}
+		s.busy[id] = true
+		s.mu.Unlock()
+
+		os.Remove(s.dataPath(id))
+		if err := os.Remove(record); err == nil {
+			removed++
+		}
+		s.release(id)
+	}
+	return removed, nil
+}
@@ -0,0 +1,78 @@
+// Package transfer moves large payloads between the CLI and agents in
+// chunks, paced to a bandwidth limit. Agents keep partial uploads on disk so
+// an interrupted upload resumes where it stopped.
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/quota"
+	"golang.org/x/time/rate"
+)
+
+// ChunkSize is the payload carried per message, well below gRPC's default
+// message size limit
+const ChunkSize = 1 << 20
+
+// Limiter paces transfers to a number of bytes per second. Transfers that
+// share a Limiter share its bandwidth. A nil Limiter does not limit.
+type Limiter struct {
+	r *rate.Limiter
+}
+
+// NewLimiter returns a Limiter for bytesPerSecond, or nil for zero
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &Limiter{r: rate.NewLimiter(rate.Limit(bytesPerSecond), ChunkSize)}
+}
+
+// Wait blocks until n more bytes may be sent or ctx is done
+func (l *Limiter) Wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		k := min(n, l.r.Burst())
+		if err := l.r.WaitN(ctx, k); err != nil {
+			return err
+		}
+		n -= k
+	}
+	return nil
+}
+
+// ParseRate parses a bandwidth such as "10M" or "512k/s" in bytes per
+// second, binary units as for sizes; empty means unlimited
+func ParseRate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	bps, err := quota.ParseMemory(strings.TrimSuffix(s, "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: want bytes per second, e.g. 10M", s)
+	}
+	return bps, nil
+}
+
+// Digest returns the hex SHA-256 digest of what r holds
+func Digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ID names the upload of a payload to a target, so that uploading the same
+// payload to the same target again resumes the earlier upload
+func ID(kind, stack, namespace, path, digest string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{kind, stack, namespace, path, digest}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
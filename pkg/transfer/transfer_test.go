@@ -0,0 +1,141 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreResumes(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := "a multi-gigabyte image bundle"
+	digest, _ := Digest(strings.NewReader(payload))
+	target := Target{Kind: "image_bundle", Stack: "shop"}
+	up := Upload{ID: ID(target.Kind, target.Stack, "", "", digest), Target: target, Size: int64(len(payload)), SHA256: digest}
+
+	// The link drops after the first part
+	w, err := s.Begin(up, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(payload[:10]))
+	if _, err := s.Begin(up, 10); !errors.Is(err, ErrBusy) {
+		t.Errorf("second writer: error = %v, want busy", err)
+	}
+	w.Release()
+
+	got, err := s.Get(up.ID)
+	if err != nil || got.Received != 10 || got.Complete() {
+		t.Fatalf("after the drop: %+v, %v", got, err)
+	}
+	if _, err := s.Begin(up, 0); !errors.Is(err, ErrOffset) {
+		t.Errorf("restart from zero: error = %v, want wrong offset", err)
+	}
+	other := up
+	other.Target.Stack = "blog"
+	if _, err := s.Begin(other, 10); !errors.Is(err, ErrMismatch) {
+		t.Errorf("other target under the same ID: error = %v, want mismatch", err)
+	}
+
+	if _, err := s.Begin(up, -1); !errors.Is(err, ErrOffset) {
+		t.Errorf("negative offset: error = %v, want wrong offset", err)
+	}
+
+	// Fetches take the upload where it stopped
+	w, err = s.Resume(up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Release()
+	if w.Upload().Received != 10 {
+		t.Fatalf("resumed at %d, want 10", w.Upload().Received)
+	}
+	if _, err := w.Write([]byte(payload[10:] + "!")); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("past the size: error = %v", err)
+	}
+	w.Write([]byte(payload[10:]))
+	if u, err := w.Close(); err != nil || !u.Complete() {
+		t.Fatalf("after the rest: %+v, %v", u, err)
+	}
+	path, err := w.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != payload {
+		t.Errorf("payload = %q", data)
+	}
+	if err := w.Delivered([]string{"shop:1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get(up.ID); !got.Delivered || len(got.Images) != 1 {
+		t.Errorf("delivered upload = %+v", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("data of a delivered upload was kept")
+	}
+}
+
+func TestStoreDropsCorruptPayload(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, _ := Digest(strings.NewReader("right"))
+	up := Upload{ID: "corrupt", Target: Target{Kind: "file", Stack: "shop", Path: "seed.sql"}, Size: 5, SHA256: digest}
+
+	w, err := s.Begin(up, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("wrong"))
+	w.Close()
+	if _, err := w.Verify(); !errors.Is(err, ErrDigest) {
+		t.Errorf("error = %v, want digest mismatch", err)
+	}
+	w.Release()
+
+	// The upload starts over
+	if got, _ := s.Get(up.ID); got.Received != 0 {
+		t.Errorf("received = %d after a digest mismatch", got.Received)
+	}
+
+	if n, err := s.Expire(0); err != nil || n != 1 {
+		t.Errorf("expired %d uploads, %v", n, err)
+	}
+	if _, err := s.Get(up.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired upload: error = %v", err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	if rate, err := ParseRate("2M/s"); err != nil || rate != 2<<20 {
+		t.Errorf("ParseRate = %d, %v", rate, err)
+	}
+	if _, err := ParseRate("fast"); err == nil {
+		t.Error("ParseRate accepted fast")
+	}
+
+	// The first chunk is the burst; the next waits for its share
+	l := NewLimiter(4 * ChunkSize)
+	start := time.Now()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx, ChunkSize); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("two chunks at four per second took %s", elapsed)
+	}
+
+	var none *Limiter
+	if err := none.Wait(ctx, 100*ChunkSize); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,25 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+)
+
+// CAHash identifies a CA certificate for pinning: "sha256:" followed by the
+// hex digest of its DER encoding
+func CAHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// MatchCAHash reports whether cert has the pinned hash, ignoring case and
+// an omitted "sha256:" prefix
+func MatchCAHash(cert *x509.Certificate, pinned string) bool {
+	pinned = strings.ToLower(strings.TrimSpace(pinned))
+	if !strings.HasPrefix(pinned, "sha256:") {
+		pinned = "sha256:" + pinned
+	}
+	return CAHash(cert) == pinned
+}
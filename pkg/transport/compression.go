@@ -0,0 +1,34 @@
+// Package transport holds gRPC wire settings shared by the core, agent and
+// CLI: message compression and batching of high-volume log streams.
+package transport
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Compression names accepted in configuration
+const (
+	CompressionGzip = gzip.Name
+	CompressionZstd = zstdName
+	CompressionNone = "none"
+)
+
+// CompressionOption returns the dial option that compresses outgoing calls
+// with the named compressor. An empty name selects gzip. Servers in every
+// binary importing this package accept gzip and zstd and reply in kind, so
+// each hop negotiates compression independently.
+func CompressionOption(name string) (grpc.DialOption, error) {
+	switch name {
+	case "", CompressionGzip:
+		return grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)), nil
+	case CompressionZstd:
+		return grpc.WithDefaultCallOptions(grpc.UseCompressor(zstdName)), nil
+	case CompressionNone:
+		return grpc.EmptyDialOption{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q (want %s, %s or %s)", name, CompressionGzip, CompressionZstd, CompressionNone)
+	}
+}
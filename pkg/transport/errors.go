@@ -0,0 +1,86 @@
+package transport
+
+import (
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error returns a status error of code that carries detail, so clients can
+// act on it without parsing the message
+func Error(code codes.Code, detail *agentv1.ErrorDetail, format string, args ...interface{}) error {
+	st := status.Newf(code, format, args...)
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+// Detail returns the ErrorDetail err carries, or one derived from its status
+// code when it carries none. It returns nil for a nil error.
+func Detail(err error) *agentv1.ErrorDetail {
+	if err == nil {
+		return nil
+	}
+	st, _ := status.FromError(err)
+	for _, d := range st.Details() {
+		if detail, ok := d.(*agentv1.ErrorDetail); ok {
+			return detail
+		}
+	}
+	return defaultDetail(st.Code())
+}
+
+// WithDetail returns err as a status that carries an ErrorDetail: the one it
+// has, or the default for its code naming agentID. The message and code are
+// kept.
+func WithDetail(err error, agentID string) error {
+	if err == nil {
+		return nil
+	}
+	st, _ := status.FromError(err)
+	for _, d := range st.Details() {
+		if _, ok := d.(*agentv1.ErrorDetail); ok {
+			return err
+		}
+	}
+
+	detail := defaultDetail(st.Code())
+	detail.AgentId = agentID
+	withDetail, derr := st.WithDetails(detail)
+	if derr != nil {
+		return err
+	}
+	return withDetail.Err()
+}
+
+// defaultDetail describes a status that was returned without a detail
+func defaultDetail(code codes.Code) *agentv1.ErrorDetail {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_INVALID_ARGUMENT}
+	case codes.NotFound:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_NOT_FOUND}
+	case codes.AlreadyExists:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_ALREADY_EXISTS}
+	case codes.Unauthenticated:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_UNAUTHENTICATED,
+			Hint: "check the client certificate with mandau doctor"}
+	case codes.PermissionDenied:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_PERMISSION_DENIED,
+			Hint: "ask an administrator for a role that allows this"}
+	case codes.FailedPrecondition:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_FAILED_PRECONDITION}
+	case codes.ResourceExhausted:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_QUOTA_EXCEEDED}
+	case codes.Unavailable, codes.Aborted:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_UNAVAILABLE, Retryable: true}
+	case codes.DeadlineExceeded:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_TIMEOUT, Retryable: true}
+	case codes.Unimplemented:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_UNIMPLEMENTED,
+			Hint: "the core or agent may be older than this client"}
+	default:
+		return &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_INTERNAL}
+	}
+}
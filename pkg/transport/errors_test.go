@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"fmt"
+	"testing"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDetail(t *testing.T) {
+	attached := Error(codes.FailedPrecondition, &agentv1.ErrorDetail{
+		Code:     agentv1.ErrorCode_ERROR_CODE_FROZEN,
+		Resource: "cluster",
+	}, "cluster is frozen")
+
+	tests := []struct {
+		name      string
+		err       error
+		code      agentv1.ErrorCode
+		retryable bool
+	}{
+		{"attached", attached, agentv1.ErrorCode_ERROR_CODE_FROZEN, false},
+		{"wrapped", fmt.Errorf("agent web-1: %w", attached), agentv1.ErrorCode_ERROR_CODE_FROZEN, false},
+		{"derived", status.Error(codes.Unavailable, "connection refused"), agentv1.ErrorCode_ERROR_CODE_UNAVAILABLE, true},
+		{"plain", fmt.Errorf("boom"), agentv1.ErrorCode_ERROR_CODE_INTERNAL, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Detail(tt.err)
+			if d.Code != tt.code || d.Retryable != tt.retryable {
+				t.Errorf("Detail() = %v, want code %v retryable %v", d, tt.code, tt.retryable)
+			}
+		})
+	}
+
+	if Detail(nil) != nil {
+		t.Error("Detail(nil) != nil")
+	}
+}
+
+func TestWithDetail(t *testing.T) {
+	err := WithDetail(status.Error(codes.NotFound, "stack not found: web"), "web-1")
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound || st.Message() != "stack not found: web" {
+		t.Errorf("WithDetail() changed the status to %v", st)
+	}
+	d := Detail(err)
+	if d.Code != agentv1.ErrorCode_ERROR_CODE_NOT_FOUND || d.AgentId != "web-1" {
+		t.Errorf("Detail() = %v, want not found on web-1", d)
+	}
+
+	// A detail the server chose is kept
+	attached := Error(codes.Unavailable, &agentv1.ErrorDetail{Code: agentv1.ErrorCode_ERROR_CODE_AGENT_OFFLINE}, "agent offline: web-1")
+	if d := Detail(WithDetail(attached, "web-2")); d.Code != agentv1.ErrorCode_ERROR_CODE_AGENT_OFFLINE || d.AgentId != "" {
+		t.Errorf("Detail() = %v, want the attached one", d)
+	}
+}
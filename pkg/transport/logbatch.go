@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+// Log batching bounds. Requests asking for more are capped.
+const (
+	DefaultBatchSize     = 100
+	DefaultBatchInterval = 200 * time.Millisecond
+	MaxBatchSize         = 1000
+	MaxBatchInterval     = 5 * time.Second
+)
+
+// BatchLimits returns the batch size and interval a GetStackLogsRequest asks
+// for, with defaults for unset fields and caps applied
+func BatchLimits(req *agentv1.GetStackLogsRequest) (int, time.Duration) {
+	size := int(req.GetBatchSize())
+	switch {
+	case size <= 0:
+		size = DefaultBatchSize
+	case size > MaxBatchSize:
+		size = MaxBatchSize
+	}
+
+	interval := time.Duration(req.GetBatchIntervalMs()) * time.Millisecond
+	switch {
+	case interval <= 0:
+		interval = DefaultBatchInterval
+	case interval > MaxBatchInterval:
+		interval = MaxBatchInterval
+	}
+
+	return size, interval
+}
+
+// LogBatcher groups log entries into LogBatch frames. A frame is sent when
+// it holds size entries or when interval has passed since its first entry,
+// so quiet streams still deliver promptly. Send is never called
+// concurrently.
+type LogBatcher struct {
+	mu       sync.Mutex
+	size     int
+	interval time.Duration
+	send     func(*agentv1.LogBatch) error
+	pending  []*agentv1.LogEntry
+	timer    *time.Timer
+	err      error
+}
+
+// NewLogBatcher returns a batcher delivering frames to send
+func NewLogBatcher(size int, interval time.Duration, send func(*agentv1.LogBatch) error) *LogBatcher {
+	return &LogBatcher{size: size, interval: interval, send: send}
+}
+
+// Add queues an entry, sending the frame if it is full. It returns the
+// first send error, after which the batcher drops everything.
+func (b *LogBatcher) Add(entry *agentv1.LogEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.pending = append(b.pending, entry)
+	if len(b.pending) >= b.size {
+		return b.flushLocked()
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.flushLocked()
+		})
+	}
+	return nil
+}
+
+// Flush sends any queued entries and returns the first send error
+func (b *LogBatcher) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked()
+}
+
+func (b *LogBatcher) flushLocked() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.err != nil || len(b.pending) == 0 {
+		return b.err
+	}
+
+	batch := &agentv1.LogBatch{Entries: b.pending}
+	b.pending = nil
+	b.err = b.send(batch)
+	return b.err
+}
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	agentv1 "github.com/bhangun/mandau/api/v1"
+)
+
+func TestBatchLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *agentv1.GetStackLogsRequest
+		wantSize     int
+		wantInterval time.Duration
+	}{
+		{"defaults", &agentv1.GetStackLogsRequest{}, DefaultBatchSize, DefaultBatchInterval},
+		{"requested", &agentv1.GetStackLogsRequest{BatchSize: 10, BatchIntervalMs: 50}, 10, 50 * time.Millisecond},
+		{"capped", &agentv1.GetStackLogsRequest{BatchSize: 1e6, BatchIntervalMs: 1e6}, MaxBatchSize, MaxBatchInterval},
+		{"negative", &agentv1.GetStackLogsRequest{BatchSize: -1, BatchIntervalMs: -1}, DefaultBatchSize, DefaultBatchInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, interval := BatchLimits(tt.req)
+			if size != tt.wantSize || interval != tt.wantInterval {
+				t.Errorf("BatchLimits() = %d, %v, want %d, %v", size, interval, tt.wantSize, tt.wantInterval)
+			}
+		})
+	}
+}
+
+// recorder collects sent batch sizes
+type recorder struct {
+	mu    sync.Mutex
+	sizes []int
+	err   error
+}
+
+func (r *recorder) send(b *agentv1.LogBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sizes = append(r.sizes, len(b.Entries))
+	return r.err
+}
+
+func (r *recorder) batches() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.sizes...)
+}
+
+func TestLogBatcherSize(t *testing.T) {
+	r := &recorder{}
+	b := NewLogBatcher(3, time.Hour, r.send)
+	for i := 0; i < 7; i++ {
+		if err := b.Add(&agentv1.LogEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.batches()
+	if len(got) != 3 || got[0] != 3 || got[1] != 3 || got[2] != 1 {
+		t.Errorf("batches = %v, want [3 3 1]", got)
+	}
+}
+
+func TestLogBatcherInterval(t *testing.T) {
+	r := &recorder{}
+	b := NewLogBatcher(100, 10*time.Millisecond, r.send)
+	if err := b.Add(&agentv1.LogEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(r.batches()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not sent after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := r.batches(); got[0] != 1 {
+		t.Errorf("batches = %v, want [1]", got)
+	}
+}
+
+func TestLogBatcherSendError(t *testing.T) {
+	r := &recorder{err: errors.New("client gone")}
+	b := NewLogBatcher(1, time.Hour, r.send)
+	if err := b.Add(&agentv1.LogEntry{}); err == nil {
+		t.Fatal("expected send error")
+	}
+	if err := b.Add(&agentv1.LogEntry{}); err == nil {
+		t.Fatal("expected the error to stick")
+	}
+	if got := r.batches(); len(got) != 1 {
+		t.Errorf("sent %d batches after failure, want 1", len(got))
+	}
+}
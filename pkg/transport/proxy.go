@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"google.golang.org/grpc/encoding"
+	protoenc "google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/proto"
+)
+
+// AgentHeader names the agent a call to the core is meant for. The core
+// forwards methods it does not serve itself to that agent; requests with an
+// agent_id field may leave the header out.
+const AgentHeader = "x-mandau-agent"
+
+// RequesterHeader names who a call the core forwards to an agent was made
+// by, for the records the agent keeps of its changes
+const RequesterHeader = "x-mandau-requester"
+
+// ImpersonateHeader names the user a caller acts as. The core lets the call
+// through with that user's permissions if policy allows the caller the
+// "impersonate" action on "user:<id>".
+const ImpersonateHeader = "x-mandau-impersonate"
+
+// Frame is a message the core forwards without decoding it
+type Frame struct {
+	Payload []byte
+	// Message is the decoded request, set when the core had to look inside
+	// it to route the call
+	Message proto.Message
+}
+
+// frameCodec passes Frames through as they are and encodes everything else
+// as protobuf, so one server can proxy unknown methods and serve its own
+type frameCodec struct {
+	proto encoding.CodecV2
+}
+
+// FrameCodec returns the codec that carries Frames. It keeps the "proto"
+// name, so peers see ordinary protobuf calls.
+func FrameCodec() encoding.CodecV2 {
+	return frameCodec{proto: encoding.GetCodecV2(protoenc.Name)}
+}
+
+func (c frameCodec) Marshal(v any) (mem.BufferSlice, error) {
+	if f, ok := v.(*Frame); ok {
+		return mem.BufferSlice{mem.SliceBuffer(f.Payload)}, nil
+	}
+	return c.proto.Marshal(v)
+}
+
+func (c frameCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	if f, ok := v.(*Frame); ok {
+		f.Payload = data.Materialize()
+		return nil
+	}
+	return c.proto.Unmarshal(data, v)
+}
+
+func (c frameCodec) Name() string {
+	return protoenc.Name
+}
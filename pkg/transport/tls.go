@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// CAFiles returns the CA bundles cfg trusts: ca_path, then ca_paths
+func CAFiles(cfg config.TLSConfig) []string {
+	var files []string
+	if cfg.CAPath != "" {
+		files = append(files, cfg.CAPath)
+	}
+	return append(files, cfg.CAPaths...)
+}
+
+// CertPool loads every certificate in the given PEM bundles. Bundles may
+// hold several CAs, as when a new CA is trusted alongside the one it
+// replaces, and intermediates peers do not send themselves.
+func CertPool(files ...string) (*x509.CertPool, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no CA configured")
+	}
+
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("load CA: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("parse CA cert %s: no certificates found", file)
+		}
+	}
+	return pool, nil
+}
+
+// PinCAs returns a tls.Config VerifyConnection func accepting peers only if
+// one of their verified chains includes a certificate with a pinned hash
+// (see CAHash). It returns nil when nothing is pinned.
+func PinCAs(pins []string) func(tls.ConnectionState) error {
+	if len(pins) == 0 {
+		return nil
+	}
+
+	return func(state tls.ConnectionState) error {
+		for _, chain := range state.VerifiedChains {
+			for _, cert := range chain {
+				for _, pin := range pins {
+					if MatchCAHash(cert, pin) {
+						return nil
+					}
+				}
+			}
+		}
+		return errors.New("peer certificate does not chain to a pinned CA")
+	}
+}
+
+// ClientConfig builds the mTLS client configuration for cfg. The server
+// certificate must be valid for cfg.ServerName, or defaultServerName when
+// that is empty. The certificate file may include intermediates, which are
+// sent along with it.
+func ClientConfig(cfg config.TLSConfig, defaultServerName string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load cert: %w", err)
+	}
+
+	roots, err := CertPool(CAFiles(cfg)...)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName = defaultServerName
+	}
+
+	return &tls.Config{
+		Certificates:     []tls.Certificate{cert},
+		RootCAs:          roots,
+		ServerName:       serverName,
+		MinVersion:       tls.VersionTLS13,
+		VerifyConnection: PinCAs(cfg.PinnedCAs),
+	}, nil
+}
+
+// ServerClientAuth sets the client certificate verification of a server
+// configuration from cfg
+func ServerClientAuth(tlsConfig *tls.Config, cfg config.TLSConfig) error {
+	pool, err := CertPool(CAFiles(cfg)...)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	tlsConfig.ClientCAs = pool
+	tlsConfig.VerifyConnection = PinCAs(cfg.PinnedCAs)
+	return nil
+}
@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+func testCA(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func writePEM(t *testing.T, path string, certs ...*x509.Certificate) {
+	t.Helper()
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertPool(t *testing.T) {
+	dir := t.TempDir()
+	oldCA, newCA, otherCA := testCA(t, "old"), testCA(t, "new"), testCA(t, "other")
+	bundle := filepath.Join(dir, "bundle.crt")
+	writePEM(t, bundle, oldCA, newCA)
+	other := filepath.Join(dir, "other.crt")
+	writePEM(t, other, otherCA)
+	empty := filepath.Join(dir, "empty.crt")
+	os.WriteFile(empty, []byte("not a certificate"), 0600)
+
+	cfg := config.TLSConfig{CAPath: bundle, CAPaths: []string{other}}
+	pool, err := CertPool(CAFiles(cfg)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ca := range []*x509.Certificate{oldCA, newCA, otherCA} {
+		if _, err := ca.Verify(x509.VerifyOptions{Roots: pool}); err != nil {
+			t.Errorf("%s not trusted: %v", ca.Subject.CommonName, err)
+		}
+	}
+
+	if _, err := CertPool(); err == nil {
+		t.Error("CertPool() without files succeeded")
+	}
+	if _, err := CertPool(empty); err == nil {
+		t.Error("CertPool() of a file without certificates succeeded")
+	}
+}
+
+func TestPinCAs(t *testing.T) {
+	pinned, other := testCA(t, "pinned"), testCA(t, "other")
+
+	if PinCAs(nil) != nil {
+		t.Error("PinCAs(nil) should not verify anything")
+	}
+
+	verify := PinCAs([]string{CAHash(pinned)})
+	if err := verify(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other}, {other, pinned}}}); err != nil {
+		t.Errorf("chain through the pinned CA rejected: %v", err)
+	}
+	if err := verify(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other}}}); err == nil {
+		t.Error("chain without the pinned CA accepted")
+	}
+}
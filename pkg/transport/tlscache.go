@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// ClientConfigCache keeps the configurations ClientConfig builds, so that
+// dialing many peers loads and parses the certificate, key and CAs once
+// rather than per dial. A configuration is built again once one of its
+// files changes size or modification time, as when certificates are
+// rotated in place. The zero value is ready to use.
+//
+// The configurations returned are shared and must not be modified;
+// credentials.NewTLS and tls.Client copy what they use.
+type ClientConfigCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedConfig
+}
+
+type cachedConfig struct {
+	config *tls.Config
+	files  []fileStamp
+}
+
+// fileStamp is what a configuration was built from: a file as it was stat'ed
+// before being read
+type fileStamp struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// ClientConfig returns ClientConfig(cfg, defaultServerName), built again
+// only when its files changed since it was last built
+func (c *ClientConfigCache) ClientConfig(cfg config.TLSConfig, defaultServerName string) (*tls.Config, error) {
+	key := cacheKey(cfg, defaultServerName)
+	files := append([]string{cfg.CertPath, cfg.KeyPath}, CAFiles(cfg)...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Stat'ing first means a file rewritten while it is read is read
+	// again on the next call
+	stamps, err := stampFiles(files)
+	if err == nil {
+		if entry, ok := c.entries[key]; ok && sameStamps(entry.files, stamps) {
+			return entry.config, nil
+		}
+	}
+
+	tlsConfig, err := ClientConfig(cfg, defaultServerName)
+	if err != nil {
+		delete(c.entries, key)
+		return nil, err
+	}
+	if stamps != nil {
+		if c.entries == nil {
+			c.entries = make(map[string]*cachedConfig)
+		}
+		c.entries[key] = &cachedConfig{config: tlsConfig, files: stamps}
+	}
+	return tlsConfig, nil
+}
+
+// cacheKey tells apart the configurations built from different settings
+func cacheKey(cfg config.TLSConfig, defaultServerName string) string {
+	return fmt.Sprintf("%q %q %q %q %q %q", cfg.CertPath, cfg.KeyPath, strings.Join(CAFiles(cfg), "\x00"),
+		cfg.ServerName, defaultServerName, strings.Join(cfg.PinnedCAs, "\x00"))
+}
+
+func stampFiles(paths []string) ([]fileStamp, error) {
+	stamps := make([]fileStamp, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps[i] = fileStamp{path: path, size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps, nil
+}
+
+func sameStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].path != b[i].path || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
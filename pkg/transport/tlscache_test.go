@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/config"
+)
+
+// writeKeyPair writes a self-signed certificate and its key to dir, and
+// returns a configuration using them with the certificate as its CA
+func writeKeyPair(tb testing.TB, dir, name string) config.TLSConfig {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	cfg := config.TLSConfig{
+		CertPath: filepath.Join(dir, "client.crt"),
+		KeyPath:  filepath.Join(dir, "client.key"),
+		CAPath:   filepath.Join(dir, "ca.crt"),
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	for path, data := range map[string][]byte{
+		cfg.CertPath: certPEM,
+		cfg.KeyPath:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cfg.CAPath:   certPEM,
+	} {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return cfg
+}
+
+func TestClientConfigCache(t *testing.T) {
+	dir := t.TempDir()
+	cfg := writeKeyPair(t, dir, "first")
+	var cache ClientConfigCache
+
+	first, err := cache.ClientConfig(cfg, "mandau-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := cache.ClientConfig(cfg, "mandau-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Error("unchanged files were loaded again")
+	}
+
+	other, err := cache.ClientConfig(cfg, "other-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first || other.ServerName != "other-server" {
+		t.Errorf("server name %q shared the configuration for mandau-agent", other.ServerName)
+	}
+
+	// Rotating the certificate in place is picked up
+	writeKeyPair(t, dir, "second")
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{cfg.CertPath, cfg.KeyPath, cfg.CAPath} {
+		os.Chtimes(path, future, future)
+	}
+	rotated, err := cache.ClientConfig(cfg, "mandau-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == first {
+		t.Fatal("rotated files were not loaded")
+	}
+	leaf, err := x509.ParseCertificate(rotated.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Subject.CommonName != "second" {
+		t.Errorf("certificate is %s, want second", leaf.Subject.CommonName)
+	}
+
+	// Files gone fail like ClientConfig does
+	os.Remove(cfg.KeyPath)
+	if _, err := cache.ClientConfig(cfg, "mandau-agent"); err == nil {
+		t.Error("missing key accepted")
+	}
+}
+
+// The benchmarks build the configuration of one agent dial from many
+// goroutines at once, as a fan-out to a fleet does
+func BenchmarkClientConfig(b *testing.B) {
+	cfg := writeKeyPair(b, b.TempDir(), "client")
+
+	b.Run("uncached", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := ClientConfig(cfg, "mandau-agent"); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		var cache ClientConfigCache
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := cache.ClientConfig(cfg, "mandau-agent"); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
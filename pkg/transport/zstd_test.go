@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	c := encoding.GetCompressor(CompressionZstd)
+	if c == nil {
+		t.Fatal("zstd compressor not registered")
+	}
+
+	// Twice, so the second round reuses the pooled encoder and decoder
+	for _, msg := range []string{strings.Repeat("log line\n", 1000), "short"} {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != msg {
+			t.Errorf("round trip of %d bytes returned %d bytes", len(msg), len(got))
+		}
+	}
+
+	r, err := c.Decompress(strings.NewReader("not zstd"))
+	if err == nil {
+		_, err = io.ReadAll(r)
+	}
+	if err == nil {
+		t.Error("garbage decompressed")
+	}
+}
+
+func TestCompressionOption(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	for _, name := range []string{"", CompressionGzip, CompressionZstd, CompressionNone} {
+		opt, err := CompressionOption(name)
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+		conn.Close()
+	}
+
+	if _, err := CompressionOption("brotli"); err == nil {
+		t.Error("unknown compression accepted")
+	}
+}
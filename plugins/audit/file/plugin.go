@@ -20,6 +20,7 @@ type FileAuditPlugin struct {
 	currentLog *os.File
 	mu         sync.Mutex
 	rotateSize int64
+	sandbox    *plugin.Sandbox
 }
 
 func New() *FileAuditPlugin {
@@ -51,11 +52,18 @@ func (p *FileAuditPlugin) Init(ctx context.Context, config map[string]interface{
 	return p.openLogFile()
 }
 
+// Permissions covers the log directory
+func (p *FileAuditPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{Write: []string{p.logDir}}
+}
+
+func (p *FileAuditPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
+
 func (p *FileAuditPlugin) openLogFile() error {
 	filename := filepath.Join(p.logDir, fmt.Sprintf("audit-%s.jsonl",
 		time.Now().Format("2006-01-02")))
 
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	f, err := p.sandbox.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
 	if err != nil {
 		return err
 	}
@@ -156,6 +164,10 @@ func (p *FileAuditPlugin) matchesFilter(entry *plugin.AuditEntry, filter *plugin
 		return false
 	}
 
+	if filter.RequestID != "" && entry.RequestID != filter.RequestID {
+		return false
+	}
+
 	if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
 		return false
 	}
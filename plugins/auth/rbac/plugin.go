@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bhangun/mandau/pkg/plugin"
 	"gopkg.in/yaml.v3"
@@ -21,6 +22,15 @@ type RBACPlugin struct {
 type Role struct {
 	Name        string
 	Permissions []Permission
+	ExecTimeout string           `yaml:"exec_timeout,omitempty"` // Overrides security.exec_timeout; "0" for no limit
+	Obligations []ObligationRule `yaml:"obligations,omitempty"`
+}
+
+// ObligationRule flags calls of members of a role, e.g. requiring a reason
+// and MFA before RemoveStack
+type ObligationRule struct {
+	Methods []string `yaml:"methods"` // Full gRPC method or bare name like "RemoveStack"; "*" for all
+	Require []string `yaml:"require"` // e.g. ["require_reason", "require_mfa=5m"]
 }
 
 type Permission struct {
@@ -34,6 +44,21 @@ type User struct {
 	Roles []string
 }
 
+// agentRoleName is the role the core gives agent certificates. Role tables
+// that do not define it get agentRole, so agents keep registering and
+// sending heartbeats without being listed as users.
+const agentRoleName = "agent"
+
+// agentRole allows only what agents call the core for
+func agentRole() *Role {
+	return &Role{
+		Name: agentRoleName,
+		Permissions: []Permission{
+			{Resource: "agent:*", Actions: []string{"register", "heartbeat", "fetch_artifact"}},
+		},
+	}
+}
+
 func New() *RBACPlugin {
 	return &RBACPlugin{
 		name:    "rbac-auth",
@@ -53,11 +78,22 @@ func (p *RBACPlugin) Capabilities() []plugin.Capability {
 func (p *RBACPlugin) Init(ctx context.Context, config map[string]interface{}) error {
 	// Load roles from config
 	rolesConfig, ok := config["roles"].(string)
-	if !ok {
-		return p.loadDefaultRoles()
+	var err error
+	if ok {
+		err = p.loadRolesFromYAML([]byte(rolesConfig))
+	} else {
+		err = p.loadDefaultRoles()
+	}
+	if err != nil {
+		return err
 	}
 
-	return p.loadRolesFromYAML([]byte(rolesConfig))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.roles[agentRoleName]; !ok {
+		p.roles[agentRoleName] = agentRole()
+	}
+	return nil
 }
 
 func (p *RBACPlugin) loadDefaultRoles() error {
@@ -104,6 +140,11 @@ func (p *RBACPlugin) loadRolesFromYAML(data []byte) error {
 	defer p.mu.Unlock()
 
 	for _, role := range config.Roles {
+		if role.ExecTimeout != "" {
+			if _, err := time.ParseDuration(role.ExecTimeout); err != nil {
+				return fmt.Errorf("role %s: invalid exec_timeout %q: %w", role.Name, role.ExecTimeout, err)
+			}
+		}
 		p.roles[role.Name] = &role
 	}
 
@@ -120,9 +161,10 @@ func (p *RBACPlugin) Authenticate(ctx context.Context, req *plugin.AuthRequest)
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	// Check if the identity's UserID (which comes from certificate CN) exists as a user
+	// Check if the identity's UserID (which comes from certificate CN) exists as a user.
+	// Identities given roles by the certificate identity mapping need not be listed.
 	_, exists := p.users[req.Identity.UserID]
-	if !exists {
+	if !exists && len(req.Identity.Roles) == 0 {
 		return nil, fmt.Errorf("user not found: %s", req.Identity.UserID)
 	}
 
@@ -134,13 +176,14 @@ func (p *RBACPlugin) Authorize(ctx context.Context, identity *plugin.Identity, a
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	user, exists := p.users[identity.UserID]
-	if !exists {
+	if _, exists := p.users[identity.UserID]; !exists && len(identity.Roles) == 0 {
 		return fmt.Errorf("user not found")
 	}
 
-	// Check all user roles
-	for _, roleName := range user.Roles {
+	// Check all user roles, plus any this identity carries: mapped from its
+	// certificate, or granted for the current request (e.g. temporary
+	// break-glass elevation)
+	for _, roleName := range p.identityRoles(identity) {
 		role, exists := p.roles[roleName]
 		if !exists {
 			continue
@@ -203,5 +246,88 @@ func (p *RBACPlugin) Evaluate(ctx context.Context, req *plugin.PolicyRequest) (*
 		decision.Reason = err.Error()
 	}
 
+	decision.Obligations = p.obligations(req.Identity, req.Action.Method)
+	if timeout := p.execTimeout(req.Identity); timeout != "" {
+		decision.Obligations = append(decision.Obligations, plugin.ObligationExecTimeout+"="+timeout)
+	}
+
 	return decision, nil
 }
+
+// identityRoles returns the configured roles of identity plus those granted
+// for the current request. Callers hold p.mu.
+func (p *RBACPlugin) identityRoles(identity *plugin.Identity) []string {
+	roles := identity.Roles
+	if user, ok := p.users[identity.UserID]; ok {
+		roles = append(append([]string{}, user.Roles...), roles...)
+	}
+	return roles
+}
+
+// obligations collects what every role of identity requires for method
+func (p *RBACPlugin) obligations(identity *plugin.Identity, method string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var obligations []string
+	seen := make(map[string]bool)
+	for _, name := range p.identityRoles(identity) {
+		role, ok := p.roles[name]
+		if !ok {
+			continue
+		}
+		for _, rule := range role.Obligations {
+			if !matchesMethod(rule.Methods, method) {
+				continue
+			}
+			for _, o := range rule.Require {
+				if !seen[o] {
+					seen[o] = true
+					obligations = append(obligations, o)
+				}
+			}
+		}
+	}
+	return obligations
+}
+
+func matchesMethod(patterns []string, method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == method || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// execTimeout returns the most generous exec timeout among the roles of
+// identity that set one, or "" when none does
+func (p *RBACPlugin) execTimeout(identity *plugin.Identity) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	roles := p.identityRoles(identity)
+
+	var (
+		best    string
+		longest time.Duration
+	)
+	for _, name := range roles {
+		role, ok := p.roles[name]
+		if !ok || role.ExecTimeout == "" {
+			continue
+		}
+		d, err := time.ParseDuration(role.ExecTimeout)
+		if err != nil {
+			continue
+		}
+		if d == 0 {
+			return role.ExecTimeout
+		}
+		if d > longest {
+			best, longest = role.ExecTimeout, d
+		}
+	}
+	return best
+}
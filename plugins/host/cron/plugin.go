@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/bhangun/mandau/pkg/diff"
 	"github.com/bhangun/mandau/pkg/plugin"
 )
 
@@ -14,6 +15,7 @@ type CronPlugin struct {
 	name    string
 	version string
 	config  *CronConfig
+	sandbox *plugin.Sandbox
 }
 
 type CronConfig struct {
@@ -60,32 +62,59 @@ func (p *CronPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// AddCronJob adds a cron job
-func (p *CronPlugin) AddCronJob(job *CronJob) error {
-	cronFile := filepath.Join(p.config.CronDir, "mandau-"+job.Name)
+// Permissions covers the cron directory
+func (p *CronPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{Write: []string{p.config.CronDir}}
+}
+
+func (p *CronPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
+
+// AddCronJob adds a cron job. Unless force is set, a cron file Mandau did
+// not write or that was edited since is left alone.
+func (p *CronPlugin) AddCronJob(ctx context.Context, job *CronJob, force bool) error {
+	if err := p.sandbox.WithContext(ctx).WriteManaged(p.jobPath(job.Name), p.render(job), 0644, force); err != nil {
+		return fmt.Errorf("write cron file: %w", err)
+	}
+
+	return nil
+}
 
+// PlanCronJob diffs the file AddCronJob would write against the current
+// one, without writing it
+func (p *CronPlugin) PlanCronJob(job *CronJob) (string, error) {
+	return diff.File(p.jobPath(job.Name), p.render(job))
+}
+
+func (p *CronPlugin) render(job *CronJob) []byte {
 	user := job.User
 	if user == "" {
 		user = p.config.User
 	}
 
-	content := fmt.Sprintf("# Managed by Mandau\n%s %s %s\n",
+	return []byte(fmt.Sprintf("# Managed by Mandau\n%s %s %s\n",
 		job.Schedule,
 		user,
 		job.Command,
-	)
+	))
+}
 
-	if err := os.WriteFile(cronFile, []byte(content), 0644); err != nil {
-		return fmt.Errorf("write cron file: %w", err)
-	}
+// RemoveCronJob removes a cron job, refusing like AddCronJob unless force
+// is set
+func (p *CronPlugin) RemoveCronJob(ctx context.Context, name string, force bool) error {
+	return p.sandbox.WithContext(ctx).RemoveManaged(p.jobPath(name), force)
+}
 
-	return nil
+// PlanRemoveCronJob diffs the removal of a cron job's file
+func (p *CronPlugin) PlanRemoveCronJob(name string) (string, error) {
+	cronFile := p.jobPath(name)
+	if _, err := os.Stat(cronFile); err != nil {
+		return "", err
+	}
+	return diff.File(cronFile, nil)
 }
 
-// RemoveCronJob removes a cron job
-func (p *CronPlugin) RemoveCronJob(name string) error {
-	cronFile := filepath.Join(p.config.CronDir, "mandau-"+name)
-	return os.Remove(cronFile)
+func (p *CronPlugin) jobPath(name string) string {
+	return filepath.Join(p.config.CronDir, "mandau-"+name)
 }
 
 // ListCronJobs lists all Mandau-managed cron jobs
@@ -0,0 +1,234 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/diff"
+)
+
+// dockerDaemonFile is the Docker daemon's configuration
+const dockerDaemonFile = "/etc/docker/daemon.json"
+
+// dockerHealthTimeout is how long Docker has to answer again after a
+// restart before the previous configuration is restored
+const dockerHealthTimeout = 90 * time.Second
+
+// DockerDaemon holds the settings of daemon.json Mandau manages. Settings
+// left empty are removed from the file; keys outside these are kept as
+// they are.
+type DockerDaemon struct {
+	LogDriver           string            `json:"log-driver,omitempty"`
+	LogOpts             map[string]string `json:"log-opts,omitempty"`
+	RegistryMirrors     []string          `json:"registry-mirrors,omitempty"`
+	StorageDriver       string            `json:"storage-driver,omitempty"`
+	StorageOpts         []string          `json:"storage-opts,omitempty"`
+	DefaultAddressPools []AddressPool     `json:"default-address-pools,omitempty"`
+}
+
+// AddressPool is a range Docker carves the subnets of new networks from
+type AddressPool struct {
+	Base string `json:"base"`
+	Size int    `json:"size"`
+}
+
+// dockerDaemonKeys are the daemon.json keys DockerDaemon covers
+var dockerDaemonKeys = []string{"log-driver", "log-opts", "registry-mirrors", "storage-driver", "storage-opts", "default-address-pools"}
+
+// DockerDaemonState is the configured and the running daemon
+type DockerDaemonState struct {
+	Settings      DockerDaemon
+	Content       string // All of daemon.json
+	LogDriver     string // As Docker runs now
+	StorageDriver string
+	Running       bool
+}
+
+// DockerDaemonChange is the outcome of ApplyDockerDaemon
+type DockerDaemonChange struct {
+	Changed    bool
+	Restarted  bool
+	RolledBack bool
+	Diff       string
+}
+
+// ErrDockerUnhealthy is returned when Docker did not come back after a
+// restart with the new configuration
+var ErrDockerUnhealthy = errors.New("docker did not come back healthy")
+
+// GetDockerDaemon reads daemon.json and asks Docker what it runs with
+func (p *EnvironmentPlugin) GetDockerDaemon(ctx context.Context) (*DockerDaemonState, error) {
+	state := &DockerDaemonState{}
+	data, err := os.ReadFile(dockerDaemonFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state.Settings); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", dockerDaemonFile, err)
+		}
+	}
+	state.Content = string(data)
+
+	out, err := p.sandbox.CommandContext(ctx, "docker", "info", "--format", "{{.LoggingDriver}} {{.Driver}}").Output()
+	if err == nil {
+		state.Running = true
+		state.LogDriver, state.StorageDriver, _ = strings.Cut(strings.TrimSpace(string(out)), " ")
+	}
+	return state, nil
+}
+
+// ApplyDockerDaemon writes settings to daemon.json. With restart set,
+// Docker is restarted to take them up; if it does not answer again within
+// dockerHealthTimeout, the previous file is restored and Docker restarted
+// once more. Containers without a restart policy stay stopped unless
+// live-restore is enabled. A dry run only diffs the file.
+func (p *EnvironmentPlugin) ApplyDockerDaemon(ctx context.Context, settings DockerDaemon, restart, dryRun bool) (*DockerDaemonChange, error) {
+	old, err := os.ReadFile(dockerDaemonFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	content, err := mergeDockerDaemon(old, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &DockerDaemonChange{Changed: !bytes.Equal(old, content)}
+	if dryRun {
+		change.Diff, err = diff.File(dockerDaemonFile, content)
+		return change, err
+	}
+	if !change.Changed {
+		return change, nil
+	}
+
+	if err := p.validateDockerDaemon(ctx, content); err != nil {
+		return nil, err
+	}
+	if err := p.sandbox.WithContext(ctx).MkdirAll("/etc/docker", 0755); err != nil {
+		return nil, err
+	}
+	if err := p.sandbox.WithContext(ctx).WriteFile(dockerDaemonFile, content, 0644); err != nil {
+		return nil, err
+	}
+	if !restart {
+		return change, nil
+	}
+
+	change.Restarted = true
+	err = p.restartDocker(ctx)
+	if err == nil {
+		return change, nil
+	}
+	// Roll back even when the request was cancelled meanwhile
+	if rerr := p.restoreDockerDaemon(context.WithoutCancel(ctx), old); rerr != nil {
+		return change, fmt.Errorf("%w: %v; restoring the previous daemon.json failed too: %v", ErrDockerUnhealthy, err, rerr)
+	}
+	change.RolledBack = true
+	return change, fmt.Errorf("%w: %v; the previous daemon.json was restored", ErrDockerUnhealthy, err)
+}
+
+// restoreDockerDaemon puts back the daemon.json Docker last ran with, or
+// removes the file when there was none, and restarts Docker
+func (p *EnvironmentPlugin) restoreDockerDaemon(ctx context.Context, old []byte) error {
+	var err error
+	if old == nil {
+		err = p.sandbox.WithContext(ctx).Remove(dockerDaemonFile)
+	} else {
+		err = p.sandbox.WithContext(ctx).WriteFile(dockerDaemonFile, old, 0644)
+	}
+	if err != nil {
+		return err
+	}
+	return p.restartDocker(ctx)
+}
+
+// restartDocker restarts the Docker service and waits until the daemon
+// answers
+func (p *EnvironmentPlugin) restartDocker(ctx context.Context) error {
+	if out, err := p.sandbox.CommandContext(ctx, "systemctl", "restart", "docker").CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl restart docker failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dockerHealthTimeout)
+	defer cancel()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		if p.sandbox.CommandContext(ctx, "docker", "info", "--format", "{{.ServerVersion}}").Run() == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no answer within %s", dockerHealthTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// validateDockerDaemon has dockerd check content, where it is new enough
+// to have --validate (Docker 23). Older daemons only fail on restart,
+// which rolls the change back.
+func (p *EnvironmentPlugin) validateDockerDaemon(ctx context.Context, content []byte) error {
+	if _, err := exec.LookPath("dockerd"); err != nil {
+		return nil
+	}
+	if help, _ := p.sandbox.CommandContext(ctx, "dockerd", "--help").Output(); !bytes.Contains(help, []byte("--validate")) {
+		return nil
+	}
+
+	check := dockerDaemonFile + ".mandau-check"
+	if err := p.sandbox.WithContext(ctx).MkdirAll("/etc/docker", 0755); err != nil {
+		return err
+	}
+	if err := p.sandbox.WithContext(ctx).WriteFile(check, content, 0644); err != nil {
+		return err
+	}
+	defer p.sandbox.WithContext(ctx).Remove(check)
+
+	if out, err := p.sandbox.CommandContext(ctx, "dockerd", "--validate", "--config-file", check).CombinedOutput(); err != nil {
+		return fmt.Errorf("dockerd rejects the configuration: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// mergeDockerDaemon replaces the keys Mandau manages in the daemon.json
+// old with settings, keeping the rest
+func mergeDockerDaemon(old []byte, settings DockerDaemon) ([]byte, error) {
+	doc := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(old)) > 0 {
+		if err := json.Unmarshal(old, &doc); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", dockerDaemonFile, err)
+		}
+	}
+	for _, key := range dockerDaemonKeys {
+		delete(doc, key)
+	}
+
+	managed, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(managed, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc) == 0 && old == nil {
+		return nil, nil
+	}
+
+	// Marshalling a map sorts the keys, so the same settings always give
+	// the same file
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
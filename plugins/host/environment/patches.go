@@ -0,0 +1,307 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PatchStatus is what the package manager has pending, as of its last
+// package index refresh; checking does not refresh it
+type PatchStatus struct {
+	PackageManager  string
+	SecurityUpdates []PackageUpdate
+	Updates         int // Security updates included
+	RebootRequired  bool
+	RebootPackages  []string
+	RunningKernel   string
+	InstalledKernel string // Newest kernel in /boot
+}
+
+// PackageUpdate is an upgrade available for an installed package
+type PackageUpdate struct {
+	Name      string
+	Current   string // Empty where the package manager omits it
+	Available string
+}
+
+// Where Debian and Ubuntu record that an upgrade needs a reboot
+const (
+	rebootRequiredFile = "/var/run/reboot-required"
+	rebootPackagesFile = "/var/run/reboot-required.pkgs"
+)
+
+// GetPatchStatus lists pending updates, security ones separately, and
+// whether the host needs a reboot to run what is installed: because a
+// package asks for one or a newer kernel than the running one is installed
+func (p *EnvironmentPlugin) GetPatchStatus(ctx context.Context) (*PatchStatus, error) {
+	var (
+		ps  *PatchStatus
+		err error
+	)
+	if _, lookErr := exec.LookPath("apt-get"); lookErr == nil {
+		ps, err = p.aptPatchStatus(ctx)
+	} else if _, lookErr := exec.LookPath("yum"); lookErr == nil {
+		ps, err = p.yumPatchStatus(ctx)
+	} else if _, lookErr := exec.LookPath("apk"); lookErr == nil {
+		ps, err = p.apkPatchStatus(ctx)
+	} else {
+		return nil, fmt.Errorf("no package manager found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	running, _ := p.sandbox.WithContext(ctx).Command("uname", "-r").Output()
+	ps.RunningKernel = strings.TrimSpace(string(running))
+	ps.InstalledKernel = newestKernel("/boot")
+	if ps.InstalledKernel != "" && ps.RunningKernel != "" && ps.InstalledKernel != ps.RunningKernel {
+		ps.RebootRequired = true
+	}
+	return ps, nil
+}
+
+func (p *EnvironmentPlugin) aptPatchStatus(ctx context.Context) (*PatchStatus, error) {
+	out, err := p.sandbox.WithContext(ctx).Command("apt-get", "-s", "-q", "dist-upgrade").Output()
+	if err != nil {
+		return nil, fmt.Errorf("apt-get failed: %v", err)
+	}
+	ps := &PatchStatus{PackageManager: "apt"}
+	ps.SecurityUpdates, ps.Updates = parseAptSimulation(string(out))
+
+	if _, err := os.Stat(rebootRequiredFile); err == nil {
+		ps.RebootRequired = true
+		if pkgs, err := os.ReadFile(rebootPackagesFile); err == nil {
+			ps.RebootPackages = uniqueLines(string(pkgs))
+		}
+	}
+	return ps, nil
+}
+
+func (p *EnvironmentPlugin) yumPatchStatus(ctx context.Context) (*PatchStatus, error) {
+	ps := &PatchStatus{PackageManager: "yum"}
+
+	// check-update exits with 100 when there are updates; -C keeps to the
+	// metadata cache
+	all, err := p.sandbox.WithContext(ctx).Command("yum", "-q", "-C", "check-update").Output()
+	if err != nil && !hasExitCode(err, 100) {
+		return nil, fmt.Errorf("yum check-update failed: %v", err)
+	}
+	ps.Updates = len(parseYumUpdates(string(all)))
+
+	security, err := p.sandbox.WithContext(ctx).Command("yum", "-q", "-C", "check-update", "--security").Output()
+	if err != nil && !hasExitCode(err, 100) {
+		return nil, fmt.Errorf("yum check-update --security failed: %v", err)
+	}
+	ps.SecurityUpdates = parseYumUpdates(string(security))
+
+	// needs-restarting -r exits with 1 when a reboot is needed
+	if _, err := exec.LookPath("needs-restarting"); err == nil {
+		if err := p.sandbox.WithContext(ctx).Command("needs-restarting", "-r").Run(); hasExitCode(err, 1) {
+			ps.RebootRequired = true
+		}
+	}
+	return ps, nil
+}
+
+// apkPatchStatus lists the upgrades of the cached Alpine index. apk does
+// not tell security updates apart, so none are listed as such.
+func (p *EnvironmentPlugin) apkPatchStatus(ctx context.Context) (*PatchStatus, error) {
+	out, err := p.sandbox.WithContext(ctx).Command("apk", "list", "--upgradable").Output()
+	if err != nil {
+		return nil, fmt.Errorf("apk list failed: %v", err)
+	}
+	return &PatchStatus{PackageManager: "apk", Updates: len(parseApkUpgradable(string(out)))}, nil
+}
+
+func hasExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
+
+// aptInst matches the upgrades in apt-get -s output, such as
+// "Inst libssl3 [3.0.2-0ubuntu1.10] (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-security [amd64])".
+// New packages, such as the next kernel's, have no current version.
+var aptInst = regexp.MustCompile(`^Inst (\S+) (?:\[([^\]]+)\] )?\((\S+) ([^)]*)\)`)
+
+// parseAptSimulation returns the security updates apt-get -s would install,
+// those coming from a security archive, and the number of all updates
+func parseAptSimulation(out string) ([]PackageUpdate, int) {
+	var security []PackageUpdate
+	updates := 0
+	for _, line := range strings.Split(out, "\n") {
+		m := aptInst.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		updates++
+		if strings.Contains(strings.ToLower(m[4]), "security") {
+			security = append(security, PackageUpdate{Name: m[1], Current: m[2], Available: m[3]})
+		}
+	}
+	return security, updates
+}
+
+// parseYumUpdates reads the "name.arch version repository" lines of yum or
+// dnf check-update, up to the obsoleted packages it lists after them. Names
+// too long for their column are printed on a line of their own.
+func parseYumUpdates(out string) []PackageUpdate {
+	var updates []PackageUpdate
+	wrapped := ""
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Obsoleting Packages") {
+			break
+		}
+		fields := strings.Fields(line)
+		if wrapped != "" && len(fields) == 2 {
+			fields = append([]string{wrapped}, fields...)
+		}
+		wrapped = ""
+		if len(fields) == 1 && !strings.HasPrefix(line, " ") && strings.Contains(fields[0], ".") {
+			wrapped = fields[0]
+			continue
+		}
+		if len(fields) != 3 || !strings.Contains(fields[0], ".") {
+			continue
+		}
+		name := fields[0][:strings.LastIndex(fields[0], ".")]
+		updates = append(updates, PackageUpdate{Name: name, Available: fields[1]})
+	}
+	return updates
+}
+
+// apkUpgradable matches the lines of apk list --upgradable, such as
+// "busybox-1.36.1-r7 x86_64 {busybox} (GPL-2.0-only) [upgradable from: busybox-1.36.1-r5]"
+var apkUpgradable = regexp.MustCompile(`^(\S+) \S+ \{[^}]*\} \(.*\) \[upgradable from: (\S+)\]$`)
+
+// parseApkUpgradable returns the upgrades apk list --upgradable prints
+func parseApkUpgradable(out string) []PackageUpdate {
+	var updates []PackageUpdate
+	for _, line := range strings.Split(out, "\n") {
+		m := apkUpgradable.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		name, available, ok := splitApkPackage(m[1])
+		if !ok {
+			continue
+		}
+		updates = append(updates, PackageUpdate{
+			Name:      name,
+			Current:   strings.TrimPrefix(m[2], name+"-"),
+			Available: available,
+		})
+	}
+	return updates
+}
+
+// splitApkPackage splits "name-version-rN" into name and version; names
+// may contain dashes, versions end in a release
+func splitApkPackage(s string) (string, string, bool) {
+	release := strings.LastIndex(s, "-")
+	if release <= 0 {
+		return "", "", false
+	}
+	version := strings.LastIndex(s[:release], "-")
+	if version <= 0 {
+		return "", "", false
+	}
+	return s[:version], s[version+1:], true
+}
+
+func uniqueLines(s string) []string {
+	seen := make(map[string]bool)
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// newestKernel returns the release of the newest kernel image in dir, as
+// uname -r would print it once booted, leaving out rescue images and those
+// named after their flavor, such as Alpine's vmlinuz-lts
+func newestKernel(dir string) string {
+	images, _ := filepath.Glob(filepath.Join(dir, "vmlinuz-*"))
+	newest := ""
+	for _, image := range images {
+		release := strings.TrimPrefix(filepath.Base(image), "vmlinuz-")
+		if release == "" || strings.Contains(release, "rescue") || !unicode.IsDigit(rune(release[0])) {
+			continue
+		}
+		if newest == "" || compareVersions(release, newest) > 0 {
+			newest = release
+		}
+	}
+	return newest
+}
+
+// compareVersions orders kernel releases segment by segment, numbers
+// numerically and the rest by text, so 5.15.0-100 is newer than 5.15.0-91
+func compareVersions(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := as[i], bs[i]
+		xNum, yNum := unicode.IsDigit(rune(x[0])), unicode.IsDigit(rune(y[0]))
+		switch {
+		case xNum && yNum:
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if len(x) != len(y) {
+				if len(x) < len(y) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		case xNum:
+			return 1 // Numbers are newer than text, as in rpm
+		case yNum:
+			return -1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// versionSegments splits a version into runs of digits and of letters,
+// dropping separators
+func versionSegments(v string) []string {
+	var segments []string
+	start := -1
+	for i, r := range v + "." {
+		isDigit := unicode.IsDigit(r)
+		isLetter := unicode.IsLetter(r)
+		if start >= 0 {
+			prevDigit := unicode.IsDigit(rune(v[start]))
+			if (isDigit && prevDigit) || (isLetter && !prevDigit) {
+				continue
+			}
+			segments = append(segments, v[start:i])
+			start = -1
+		}
+		if isDigit || isLetter {
+			start = i
+		}
+	}
+	return segments
+}
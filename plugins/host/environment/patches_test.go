@@ -0,0 +1,178 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// aptOutput is apt-get -s -q dist-upgrade on Ubuntu 22.04, with a line cut
+// short at the end
+const aptOutput = `NOTE: This is only a simulation!
+      apt-get needs root privileges for real execution.
+      Keep also in mind that locking is deactivated,
+      so don't depend on the relevance to the real current situation!
+Reading package lists...
+Building dependency tree...
+Reading state information...
+Calculating upgrade...
+The following NEW packages will be installed:
+  linux-image-5.15.0-92-generic
+The following packages will be upgraded:
+  libssl3 openssl tzdata
+3 upgraded, 1 newly installed, 0 to remove and 0 not upgraded.
+Inst libssl3 [3.0.2-0ubuntu1.10] (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-updates, Ubuntu:22.04/jammy-security [amd64])
+Inst openssl [3.0.2-0ubuntu1.10] (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-updates, Ubuntu:22.04/jammy-security [amd64])
+Inst tzdata [2023c-0ubuntu0.22.04.2] (2024a-0ubuntu0.22.04 Ubuntu:22.04/jammy-updates [all])
+Inst linux-image-5.15.0-92-generic (5.15.0-92.102 Ubuntu:22.04/jammy-security [amd64])
+Conf libssl3 (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-updates, Ubuntu:22.04/jammy-security [amd64])
+Conf openssl (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-updates, Ubuntu:22.04/jammy-security [amd64])
+Inst curl [7.81.0-1ubuntu1.14
+`
+
+func TestParseAptSimulation(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		security []PackageUpdate
+		updates  int
+	}{
+		{name: "empty"},
+		{name: "up to date", out: "Reading package lists...\n0 upgraded, 0 newly installed, 0 to remove and 0 not upgraded.\n"},
+		{
+			name: "updates",
+			out:  aptOutput,
+			security: []PackageUpdate{
+				{Name: "libssl3", Current: "3.0.2-0ubuntu1.10", Available: "3.0.2-0ubuntu1.12"},
+				{Name: "openssl", Current: "3.0.2-0ubuntu1.10", Available: "3.0.2-0ubuntu1.12"},
+				{Name: "linux-image-5.15.0-92-generic", Available: "5.15.0-92.102"},
+			},
+			updates: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			security, updates := parseAptSimulation(tt.out)
+			if !reflect.DeepEqual(security, tt.security) || updates != tt.updates {
+				t.Errorf("parseAptSimulation = %+v, %d; want %+v, %d", security, updates, tt.security, tt.updates)
+			}
+		})
+	}
+}
+
+// dnfOutput is dnf -q -C check-update on Fedora 39, with a name too long
+// for its column, a garbled line and the obsoleted packages
+const dnfOutput = `
+kernel.x86_64                          6.6.9-200.fc39                updates
+openssl-libs.x86_64                    1:3.1.1-4.fc39                updates
+python3-setuptools-wheel-compat.noarch
+                                       67.7.2-7.fc39                 updates
+this line is not a package
+vim-minimal.x86_64                     2:9.0.2167-1.fc39
+Obsoleting Packages
+grub2-tools.x86_64                     1:2.06-110.fc39               updates
+    grub2-tools-extra.x86_64           1:2.06-95.fc39                @anaconda
+`
+
+func TestParseYumUpdates(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []PackageUpdate
+	}{
+		{name: "empty"},
+		{name: "blank lines", out: "\n\n"},
+		{
+			name: "dnf",
+			out:  dnfOutput,
+			want: []PackageUpdate{
+				{Name: "kernel", Available: "6.6.9-200.fc39"},
+				{Name: "openssl-libs", Available: "1:3.1.1-4.fc39"},
+				{Name: "python3-setuptools-wheel-compat", Available: "67.7.2-7.fc39"},
+			},
+		},
+		{
+			name: "yum",
+			out:  "\nbind-libs.x86_64    32:9.11.4-26.P2.el7_9.15    updates\n",
+			want: []PackageUpdate{{Name: "bind-libs", Available: "32:9.11.4-26.P2.el7_9.15"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseYumUpdates(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYumUpdates = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// apkOutput is apk list --upgradable on Alpine 3.19, with a line lacking
+// what it upgrades from
+const apkOutput = `busybox-1.36.1-r15 x86_64 {busybox} (GPL-2.0-only) [upgradable from: busybox-1.36.1-r14]
+libcrypto3-3.1.4-r5 x86_64 {openssl} (Apache-2.0) [upgradable from: libcrypto3-3.1.4-r3]
+py3-setuptools-70.3.0-r0 noarch {py3-setuptools} (MIT AND (Apache-2.0 OR BSD-2-Clause)) [upgradable from: py3-setuptools-68.2.2-r0]
+musl-1.2.4_git20230717-r4 x86_64 {musl} (MIT)
+WARNING: opening from cache https://dl-cdn.alpinelinux.org/alpine/v3.19/main: No such file or directory
+`
+
+func TestParseApkUpgradable(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []PackageUpdate
+	}{
+		{name: "empty"},
+		{
+			name: "updates",
+			out:  apkOutput,
+			want: []PackageUpdate{
+				{Name: "busybox", Current: "1.36.1-r14", Available: "1.36.1-r15"},
+				{Name: "libcrypto3", Current: "3.1.4-r3", Available: "3.1.4-r5"},
+				{Name: "py3-setuptools", Current: "68.2.2-r0", Available: "70.3.0-r0"},
+			},
+		},
+		{name: "no release", out: "busybox x86_64 {busybox} (GPL-2.0-only) [upgradable from: busybox-1.36.1-r14]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseApkUpgradable(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseApkUpgradable = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewestKernel(t *testing.T) {
+	tests := []struct {
+		name   string
+		images []string
+		want   string
+	}{
+		{name: "none"},
+		{
+			name:   "ubuntu",
+			images: []string{"vmlinuz-5.15.0-91-generic", "vmlinuz-5.15.0-100-generic"},
+			want:   "5.15.0-100-generic",
+		},
+		{
+			name:   "fedora",
+			images: []string{"vmlinuz-6.6.9-200.fc39.x86_64", "vmlinuz-0-rescue-0123456789abcdef"},
+			want:   "6.6.9-200.fc39.x86_64",
+		},
+		{name: "alpine", images: []string{"vmlinuz-lts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, image := range tt.images {
+				if err := os.WriteFile(filepath.Join(dir, image), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := newestKernel(dir); got != tt.want {
+				t.Errorf("newestKernel = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
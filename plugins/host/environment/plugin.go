@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/bhangun/mandau/pkg/plugin"
@@ -13,6 +14,7 @@ import (
 type EnvironmentPlugin struct {
 	name    string
 	version string
+	sandbox *plugin.Sandbox
 }
 
 type HostInfo struct {
@@ -54,8 +56,21 @@ func (p *EnvironmentPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// Permissions covers the commands that report on the host, its package
+// managers and pending reboots, sysctl and time sync, and local users, and
+// the files of chrony, sysctl.d, limits.d and users' authorized keys
+func (p *EnvironmentPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{
+		Exec: []string{"uname", "nproc", "apt-get", "yum", "dpkg", "rpm", "needs-restarting", "sysctl", "chronyc", "ntpq",
+			"timedatectl", "systemctl", "getent", "id", "useradd", "usermod", "chown", "docker", "dockerd"},
+		Write: []string{"/etc/chrony", "/etc/chrony.d", sysctlDir, limitsDir, "/home", "/root/.ssh", "/etc/docker"},
+	}
+}
+
+func (p *EnvironmentPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
+
 // GetHostInfo retrieves host system information
-func (p *EnvironmentPlugin) GetHostInfo() (*HostInfo, error) {
+func (p *EnvironmentPlugin) GetHostInfo(ctx context.Context) (*HostInfo, error) {
 	info := &HostInfo{}
 
 	// Hostname
@@ -63,35 +78,35 @@ func (p *EnvironmentPlugin) GetHostInfo() (*HostInfo, error) {
 	info.Hostname = hostname
 
 	// OS Info
-	osInfo, _ := exec.Command("uname", "-s").Output()
+	osInfo, _ := p.sandbox.WithContext(ctx).Command("uname", "-s").Output()
 	info.OS = strings.TrimSpace(string(osInfo))
 
 	// Kernel
-	kernel, _ := exec.Command("uname", "-r").Output()
+	kernel, _ := p.sandbox.WithContext(ctx).Command("uname", "-r").Output()
 	info.Kernel = strings.TrimSpace(string(kernel))
 
 	// Architecture
-	arch, _ := exec.Command("uname", "-m").Output()
+	arch, _ := p.sandbox.WithContext(ctx).Command("uname", "-m").Output()
 	info.Architecture = strings.TrimSpace(string(arch))
 
 	// CPU cores
-	cpuInfo, _ := exec.Command("nproc").Output()
+	cpuInfo, _ := p.sandbox.WithContext(ctx).Command("nproc").Output()
 	fmt.Sscanf(string(cpuInfo), "%d", &info.CPUCores)
 
 	return info, nil
 }
 
 // InstallPackage installs a system package
-func (p *EnvironmentPlugin) InstallPackage(packageName string) error {
+func (p *EnvironmentPlugin) InstallPackage(ctx context.Context, packageName string) error {
 	// Detect package manager
 	if _, err := exec.LookPath("apt-get"); err == nil {
-		cmd := exec.Command("apt-get", "install", "-y", packageName)
+		cmd := p.sandbox.WithContext(ctx).Command("apt-get", "install", "-y", packageName)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("apt-get failed: %s", output)
 		}
 	} else if _, err := exec.LookPath("yum"); err == nil {
-		cmd := exec.Command("yum", "install", "-y", packageName)
+		cmd := p.sandbox.WithContext(ctx).Command("yum", "install", "-y", packageName)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("yum failed: %s", output)
@@ -104,15 +119,15 @@ func (p *EnvironmentPlugin) InstallPackage(packageName string) error {
 }
 
 // RemovePackage removes a system package
-func (p *EnvironmentPlugin) RemovePackage(packageName string) error {
+func (p *EnvironmentPlugin) RemovePackage(ctx context.Context, packageName string) error {
 	if _, err := exec.LookPath("apt-get"); err == nil {
-		cmd := exec.Command("apt-get", "remove", "-y", packageName)
+		cmd := p.sandbox.WithContext(ctx).Command("apt-get", "remove", "-y", packageName)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("apt-get failed: %s", output)
 		}
 	} else if _, err := exec.LookPath("yum"); err == nil {
-		cmd := exec.Command("yum", "remove", "-y", packageName)
+		cmd := p.sandbox.WithContext(ctx).Command("yum", "remove", "-y", packageName)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("yum failed: %s", output)
@@ -123,21 +138,21 @@ func (p *EnvironmentPlugin) RemovePackage(packageName string) error {
 }
 
 // UpdatePackages updates all system packages
-func (p *EnvironmentPlugin) UpdatePackages() error {
+func (p *EnvironmentPlugin) UpdatePackages(ctx context.Context) error {
 	if _, err := exec.LookPath("apt-get"); err == nil {
 		// Update package list
-		cmd := exec.Command("apt-get", "update")
+		cmd := p.sandbox.WithContext(ctx).Command("apt-get", "update")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			return fmt.Errorf("apt-get update failed: %s", output)
 		}
 
 		// Upgrade packages
-		cmd = exec.Command("apt-get", "upgrade", "-y")
+		cmd = p.sandbox.WithContext(ctx).Command("apt-get", "upgrade", "-y")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			return fmt.Errorf("apt-get upgrade failed: %s", output)
 		}
 	} else if _, err := exec.LookPath("yum"); err == nil {
-		cmd := exec.Command("yum", "update", "-y")
+		cmd := p.sandbox.WithContext(ctx).Command("yum", "update", "-y")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			return fmt.Errorf("yum update failed: %s", output)
 		}
@@ -147,11 +162,11 @@ func (p *EnvironmentPlugin) UpdatePackages() error {
 }
 
 // ListPackages lists installed packages
-func (p *EnvironmentPlugin) ListPackages() ([]*Package, error) {
+func (p *EnvironmentPlugin) ListPackages(ctx context.Context) ([]*Package, error) {
 	packages := []*Package{}
 
 	if _, err := exec.LookPath("dpkg"); err == nil {
-		cmd := exec.Command("dpkg", "-l")
+		cmd := p.sandbox.WithContext(ctx).Command("dpkg", "-l")
 		output, err := cmd.Output()
 		if err != nil {
 			return nil, err
@@ -170,14 +185,30 @@ func (p *EnvironmentPlugin) ListPackages() ([]*Package, error) {
 				}
 			}
 		}
+	} else if _, err := exec.LookPath("rpm"); err == nil {
+		cmd := p.sandbox.WithContext(ctx).Command("rpm", "-qa", "--qf", "%{NAME} %{VERSION}-%{RELEASE}\n")
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(string(output), "\n") {
+			if name, version, ok := strings.Cut(line, " "); ok {
+				packages = append(packages, &Package{
+					Name:    name,
+					Version: version,
+					Status:  "installed",
+				})
+			}
+		}
 	}
 
 	return packages, nil
 }
 
 // SetSysctl sets a kernel parameter
-func (p *EnvironmentPlugin) SetSysctl(key, value string) error {
-	cmd := exec.Command("sysctl", "-w", fmt.Sprintf("%s=%s", key, value))
+func (p *EnvironmentPlugin) SetSysctl(ctx context.Context, key, value string) error {
+	cmd := p.sandbox.WithContext(ctx).Command("sysctl", "-w", fmt.Sprintf("%s=%s", key, value))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("sysctl failed: %s", output)
@@ -185,9 +216,19 @@ func (p *EnvironmentPlugin) SetSysctl(key, value string) error {
 	return nil
 }
 
+// sysctlDir is read by systemd-sysctl at boot
+const sysctlDir = "/etc/sysctl.d"
+
+// PersistSysctl writes a kernel parameter to a file of its own in
+// /etc/sysctl.d, so it is set again at boot
+func (p *EnvironmentPlugin) PersistSysctl(ctx context.Context, key, value string, force bool) error {
+	content := fmt.Sprintf("# %s\n%s = %s\n", plugin.ManagedMarker, key, value)
+	return p.sandbox.WithContext(ctx).WriteManaged(filepath.Join(sysctlDir, sysctlFilePrefix+key+".conf"), []byte(content), 0644, force)
+}
+
 // GetSysctl gets a kernel parameter
-func (p *EnvironmentPlugin) GetSysctl(key string) (string, error) {
-	cmd := exec.Command("sysctl", "-n", key)
+func (p *EnvironmentPlugin) GetSysctl(ctx context.Context, key string) (string, error) {
+	cmd := p.sandbox.WithContext(ctx).Command("sysctl", "-n", key)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
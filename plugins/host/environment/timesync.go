@@ -0,0 +1,196 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// TimeSync is the state of the daemon keeping the host clock in sync
+type TimeSync struct {
+	Daemon        string // chrony, systemd-timesyncd or ntpd; empty when none runs
+	Synchronized  bool
+	OffsetSeconds float64 // Local clock minus the time sources', when known
+	Stratum       int
+	Servers       []string
+}
+
+// chronySourcesFile is written into chrony's sourcedir, leaving the
+// distribution's chrony.conf alone
+const chronySourcesFile = "mandau.sources"
+
+// chronyConfigs are where distributions keep chrony.conf
+var chronyConfigs = []string{"/etc/chrony/chrony.conf", "/etc/chrony.conf"}
+
+// GetTimeSync reports on chrony, systemd-timesyncd or ntpd, whichever the
+// host runs
+func (p *EnvironmentPlugin) GetTimeSync(ctx context.Context) (*TimeSync, error) {
+	if _, err := exec.LookPath("chronyc"); err == nil {
+		if out, err := p.sandbox.WithContext(ctx).Command("chronyc", "-c", "tracking").Output(); err == nil {
+			ts := parseChronyTracking(string(out))
+			if out, err := p.sandbox.WithContext(ctx).Command("chronyc", "-c", "sources").Output(); err == nil {
+				ts.Servers = parseChronySources(string(out))
+			}
+			return ts, nil
+		}
+	}
+	if _, err := exec.LookPath("ntpq"); err == nil {
+		if out, err := p.sandbox.WithContext(ctx).Command("ntpq", "-pn").Output(); err == nil {
+			return parseNTPPeers(string(out)), nil
+		}
+	}
+	if _, err := exec.LookPath("timedatectl"); err == nil {
+		out, err := p.sandbox.WithContext(ctx).Command("timedatectl", "show").Output()
+		if err != nil {
+			return nil, fmt.Errorf("timedatectl failed: %v", err)
+		}
+		ts := parseTimedatectl(string(out))
+		if ts.Daemon != "" {
+			if server, err := p.sandbox.WithContext(ctx).Command("timedatectl", "show-timesync", "--property=ServerName", "--value").Output(); err == nil {
+				if name := strings.TrimSpace(string(server)); name != "" {
+					ts.Servers = []string{name}
+				}
+			}
+		}
+		return ts, nil
+	}
+	return &TimeSync{}, nil
+}
+
+// ConfigureTimeSync points chrony at servers, installing and starting it
+// when missing. The servers go in a file in chrony's sourcedir; step
+// corrects the clock at once rather than slewing it gradually.
+func (p *EnvironmentPlugin) ConfigureTimeSync(ctx context.Context, servers []string, step, force bool) error {
+	if _, err := exec.LookPath("chronyc"); err != nil {
+		if err := p.InstallPackage(ctx, "chrony"); err != nil {
+			return fmt.Errorf("install chrony: %w", err)
+		}
+	}
+
+	dir, err := chronySourceDir()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", plugin.ManagedMarker)
+	for _, server := range servers {
+		fmt.Fprintf(&b, "server %s iburst\n", server)
+	}
+	if err := p.sandbox.WithContext(ctx).MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := p.sandbox.WithContext(ctx).WriteManaged(filepath.Join(dir, chronySourcesFile), []byte(b.String()), 0644, force); err != nil {
+		return err
+	}
+
+	// Debian names the unit chrony, Red Hat chronyd
+	if out, err := p.sandbox.WithContext(ctx).Command("systemctl", "enable", "--now", "chronyd").CombinedOutput(); err != nil {
+		if out2, err := p.sandbox.WithContext(ctx).Command("systemctl", "enable", "--now", "chrony").CombinedOutput(); err != nil {
+			return fmt.Errorf("start chrony: %s%s", out, out2)
+		}
+	}
+	if out, err := p.sandbox.WithContext(ctx).Command("chronyc", "reload", "sources").CombinedOutput(); err != nil {
+		return fmt.Errorf("chronyc reload sources failed: %s", out)
+	}
+	if step {
+		if out, err := p.sandbox.WithContext(ctx).Command("chronyc", "makestep").CombinedOutput(); err != nil {
+			return fmt.Errorf("chronyc makestep failed: %s", out)
+		}
+	}
+	return nil
+}
+
+// chronySourceDir finds the sourcedir chrony.conf reads server directives
+// from, skipping the ones under /run that DHCP clients fill
+func chronySourceDir() (string, error) {
+	for _, path := range chronyConfigs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == "sourcedir" && !strings.HasPrefix(fields[1], "/run/") {
+				return fields[1], nil
+			}
+		}
+		return "", fmt.Errorf("%s has no sourcedir for Mandau to add servers in: add \"sourcedir /etc/chrony/sources.d\"", path)
+	}
+	return "", fmt.Errorf("chrony.conf not found in %s", strings.Join(chronyConfigs, " or "))
+}
+
+// parseChronyTracking reads chronyc -c tracking: the stratum is the third
+// field, the correction the system clock needs the fifth and the leap
+// status the last
+func parseChronyTracking(out string) *TimeSync {
+	ts := &TimeSync{Daemon: "chrony"}
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) < 14 {
+		return ts
+	}
+	ts.Stratum, _ = strconv.Atoi(fields[2])
+	if correction, err := strconv.ParseFloat(fields[4], 64); err == nil {
+		ts.OffsetSeconds = -correction // The correction is positive when the clock is slow
+	}
+	ts.Synchronized = fields[len(fields)-1] != "Not synchronised" && ts.Stratum > 0
+	return ts
+}
+
+// parseChronySources reads the addresses from chronyc -c sources
+func parseChronySources(out string) []string {
+	var servers []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) > 2 && fields[0] == "^" {
+			servers = append(servers, fields[2])
+		}
+	}
+	return servers
+}
+
+// parseNTPPeers reads ntpq -pn; the peer ntpd syncs to is marked with *
+func parseNTPPeers(out string) *TimeSync {
+	ts := &TimeSync{Daemon: "ntpd"}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 2 || !strings.ContainsRune("*+-#ox ", rune(line[0])) {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 9 || fields[0] == "remote" {
+			continue
+		}
+		ts.Servers = append(ts.Servers, fields[0])
+		if line[0] == '*' {
+			ts.Synchronized = true
+			ts.Stratum, _ = strconv.Atoi(fields[2])
+			if ms, err := strconv.ParseFloat(fields[8], 64); err == nil {
+				ts.OffsetSeconds = -ms / 1000 // The peer's offset from this host
+			}
+		}
+	}
+	return ts
+}
+
+// parseTimedatectl reads timedatectl show, whose NTP property says whether
+// systemd-timesyncd is enabled
+func parseTimedatectl(out string) *TimeSync {
+	ts := &TimeSync{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "NTP":
+			if value == "yes" {
+				ts.Daemon = "systemd-timesyncd"
+			}
+		case "NTPSynchronized":
+			ts.Synchronized = value == "yes"
+		}
+	}
+	return ts
+}
@@ -0,0 +1,276 @@
+package environment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// SysctlProfile is a named set of kernel parameters tuned for one workload
+type SysctlProfile struct {
+	Name        string
+	Description string
+	Settings    map[string]string
+}
+
+// SysctlProfiles are the built-in profiles
+var SysctlProfiles = []SysctlProfile{
+	{
+		Name:        "high-network",
+		Description: "Deep accept and SYN queues, wide port range and large socket buffers for busy proxies and APIs",
+		Settings: map[string]string{
+			"net.core.somaxconn":           "65535",
+			"net.core.netdev_max_backlog":  "16384",
+			"net.ipv4.tcp_max_syn_backlog": "8192",
+			"net.ipv4.ip_local_port_range": "1024 65535",
+			"net.ipv4.tcp_tw_reuse":        "1",
+			"net.ipv4.tcp_fin_timeout":     "15",
+			"net.core.rmem_max":            "16777216",
+			"net.core.wmem_max":            "16777216",
+		},
+	},
+	{
+		Name:        "container-host",
+		Description: "Forwarding, and inotify and memory map limits for many containers",
+		Settings: map[string]string{
+			"net.ipv4.ip_forward":           "1",
+			"fs.inotify.max_user_watches":   "524288",
+			"fs.inotify.max_user_instances": "512",
+			"vm.max_map_count":              "262144",
+			"kernel.keys.maxkeys":           "20000",
+		},
+	},
+	{
+		Name:        "database",
+		Description: "Little swapping, early writeback and many open files for database servers",
+		Settings: map[string]string{
+			"vm.swappiness":             "1",
+			"vm.dirty_ratio":            "15",
+			"vm.dirty_background_ratio": "5",
+			"fs.file-max":               "2097152",
+		},
+	},
+}
+
+// Kernel parameters set one by one go in files of their own, profiles in
+// one file each. Profiles sort first, so a parameter set by itself
+// overrides the same one in a profile.
+const (
+	sysctlFilePrefix    = "60-mandau-"
+	sysctlProfilePrefix = "55-mandau-profile-"
+)
+
+// SysctlCheck compares a persisted kernel parameter with the running value
+type SysctlCheck struct {
+	Key       string
+	Persisted string
+	Runtime   string // Empty when the kernel does not know the key
+	File      string // Where the persisted value comes from
+	OK        bool
+}
+
+// sysctlProfile returns the built-in profile named name
+func sysctlProfile(name string) (SysctlProfile, bool) {
+	for _, p := range SysctlProfiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return SysctlProfile{}, false
+}
+
+// AppliedSysctlProfiles returns the names of the profiles persisted on the
+// host
+func (p *EnvironmentPlugin) AppliedSysctlProfiles() []string {
+	files, _ := filepath.Glob(filepath.Join(sysctlDir, sysctlProfilePrefix+"*.conf"))
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), sysctlProfilePrefix), ".conf"))
+	}
+	return names
+}
+
+// ApplySysctlProfile persists a built-in profile to /etc/sysctl.d and loads
+// it
+func (p *EnvironmentPlugin) ApplySysctlProfile(ctx context.Context, name string, force bool) error {
+	profile, ok := sysctlProfile(name)
+	if !ok {
+		return fmt.Errorf("unknown sysctl profile %q", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n# Sysctl profile %s: %s\n", plugin.ManagedMarker, profile.Name, profile.Description)
+	keys := make([]string, 0, len(profile.Settings))
+	for key := range profile.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s = %s\n", key, profile.Settings[key])
+	}
+
+	file := filepath.Join(sysctlDir, sysctlProfilePrefix+name+".conf")
+	if err := p.sandbox.WithContext(ctx).WriteManaged(file, []byte(b.String()), 0644, force); err != nil {
+		return err
+	}
+	if out, err := p.sandbox.WithContext(ctx).Command("sysctl", "-p", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("sysctl -p failed: %s", out)
+	}
+	return nil
+}
+
+// RemoveSysctlProfile deletes a persisted profile. The running values stay
+// until the next boot.
+func (p *EnvironmentPlugin) RemoveSysctlProfile(ctx context.Context, name string, force bool) error {
+	if _, ok := sysctlProfile(name); !ok {
+		return fmt.Errorf("unknown sysctl profile %q", name)
+	}
+	return p.sandbox.WithContext(ctx).RemoveManaged(filepath.Join(sysctlDir, sysctlProfilePrefix+name+".conf"), force)
+}
+
+// VerifySysctls compares every kernel parameter Mandau persisted with its
+// running value, as after a reboot. Where files set one key, the last in
+// the order systemd-sysctl reads them wins.
+func (p *EnvironmentPlugin) VerifySysctls(ctx context.Context) ([]SysctlCheck, error) {
+	files, err := filepath.Glob(filepath.Join(sysctlDir, "*-mandau-*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	persisted := make(map[string]SysctlCheck)
+	for _, file := range files {
+		settings, err := readSysctlFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range settings {
+			persisted[key] = SysctlCheck{Key: key, Persisted: value, File: file}
+		}
+	}
+
+	checks := make([]SysctlCheck, 0, len(persisted))
+	for _, check := range persisted {
+		runtime, err := p.GetSysctl(ctx, check.Key)
+		if err == nil {
+			check.Runtime = runtime
+			check.OK = strings.Join(strings.Fields(runtime), " ") == strings.Join(strings.Fields(check.Persisted), " ")
+		}
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Key < checks[j].Key })
+	return checks, nil
+}
+
+// readSysctlFile reads the "key = value" lines of a sysctl.d file
+func readSysctlFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	settings := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		// A leading '-' only tells systemd-sysctl to ignore a failure
+		key = strings.TrimPrefix(strings.TrimSpace(key), "-")
+		settings[strings.ReplaceAll(key, "/", ".")] = strings.TrimSpace(value)
+	}
+	return settings, scanner.Err()
+}
+
+// Limit is a line of limits.conf, applied by pam_limits to login sessions.
+// Services started by systemd take their limits from their units instead.
+type Limit struct {
+	Domain  string // User, @group or *
+	Type    string // soft, hard or - for both
+	Item    string // nofile, nproc, memlock...
+	Value   string
+	File    string
+	Managed bool // In a file Mandau wrote
+}
+
+// Where pam_limits reads limits from
+const (
+	limitsFile = "/etc/security/limits.conf"
+	limitsDir  = "/etc/security/limits.d"
+)
+
+// limitsFilePrefix leads the limits.d files Mandau writes
+const limitsFilePrefix = "60-mandau-"
+
+// GetLimits lists the limits of limits.conf and limits.d, in the order
+// pam_limits reads them
+func (p *EnvironmentPlugin) GetLimits() ([]Limit, error) {
+	files, _ := filepath.Glob(filepath.Join(limitsDir, "*.conf"))
+	sort.Strings(files)
+	files = append([]string{limitsFile}, files...)
+
+	var limits []Limit
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		managed := strings.HasPrefix(filepath.Base(file), limitsFilePrefix)
+		for _, l := range parseLimits(string(data)) {
+			l.File = file
+			l.Managed = managed
+			limits = append(limits, l)
+		}
+	}
+	return limits, nil
+}
+
+// SetLimits writes limits as the limits.d file name, replacing what it
+// held. Without limits the file is removed.
+func (p *EnvironmentPlugin) SetLimits(ctx context.Context, name string, limits []Limit, force bool) error {
+	file := filepath.Join(limitsDir, limitsFilePrefix+name+".conf")
+	if len(limits) == 0 {
+		return p.sandbox.WithContext(ctx).RemoveManaged(file, force)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", plugin.ManagedMarker)
+	for _, l := range limits {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", l.Domain, l.Type, l.Item, l.Value)
+	}
+	if err := p.sandbox.WithContext(ctx).MkdirAll(limitsDir, 0755); err != nil {
+		return err
+	}
+	return p.sandbox.WithContext(ctx).WriteManaged(file, []byte(b.String()), 0644, force)
+}
+
+// parseLimits reads the "domain type item value" lines of a limits file
+func parseLimits(content string) []Limit {
+	var limits []Limit
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		limits = append(limits, Limit{Domain: fields[0], Type: fields[1], Item: fields[2], Value: fields[3]})
+	}
+	return limits
+}
@@ -0,0 +1,137 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// User is a local account as getent and id report it
+type User struct {
+	Name           string
+	Exists         bool
+	Shell          string
+	Home           string
+	Groups         []string // Primary and supplementary
+	AuthorizedKeys []string
+}
+
+// GetUser looks up a local account; a missing one is returned with Exists
+// false rather than as an error
+func (p *EnvironmentPlugin) GetUser(ctx context.Context, name string) (*User, error) {
+	user := &User{Name: name}
+	out, err := p.sandbox.WithContext(ctx).Command("getent", "passwd", name).Output()
+	if hasExitCode(err, 2) {
+		return user, nil // getent exits with 2 for an unknown key
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getent failed: %v", err)
+	}
+	// name:password:uid:gid:gecos:home:shell
+	fields := strings.Split(strings.TrimSpace(string(out)), ":")
+	if len(fields) != 7 {
+		return nil, fmt.Errorf("unexpected passwd entry for %s", name)
+	}
+	user.Exists = true
+	user.Home = fields[5]
+	user.Shell = fields[6]
+
+	groups, err := p.sandbox.WithContext(ctx).Command("id", "-nG", name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("id failed: %v", err)
+	}
+	user.Groups = strings.Fields(string(groups))
+
+	if keys, err := os.ReadFile(authorizedKeysFile(user.Home)); err == nil {
+		user.AuthorizedKeys = parseAuthorizedKeys(string(keys))
+	}
+	return user, nil
+}
+
+// EnsureUser creates the account when missing, otherwise sets its shell and
+// adds it to the groups it is not in. When keys are given they replace the
+// account's authorized_keys.
+func (p *EnvironmentPlugin) EnsureUser(ctx context.Context, name, shell string, groups, keys []string, force bool) error {
+	user, err := p.GetUser(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !user.Exists {
+		args := []string{"-m"}
+		if shell != "" {
+			args = append(args, "-s", shell)
+		}
+		if len(groups) > 0 {
+			args = append(args, "-G", strings.Join(groups, ","))
+		}
+		if out, err := p.sandbox.WithContext(ctx).Command("useradd", append(args, name)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("useradd failed: %s", out)
+		}
+		if user, err = p.GetUser(ctx, name); err != nil {
+			return err
+		}
+	} else {
+		if shell != "" && shell != user.Shell {
+			if out, err := p.sandbox.WithContext(ctx).Command("usermod", "-s", shell, name).CombinedOutput(); err != nil {
+				return fmt.Errorf("usermod failed: %s", out)
+			}
+		}
+		if missing := missingGroups(user.Groups, groups); len(missing) > 0 {
+			if out, err := p.sandbox.WithContext(ctx).Command("usermod", "-a", "-G", strings.Join(missing, ","), name).CombinedOutput(); err != nil {
+				return fmt.Errorf("usermod failed: %s", out)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	sshDir := filepath.Dir(authorizedKeysFile(user.Home))
+	if err := p.sandbox.WithContext(ctx).MkdirAll(sshDir, 0700); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("# %s\n%s\n", plugin.ManagedMarker, strings.Join(keys, "\n"))
+	if err := p.sandbox.WithContext(ctx).WriteManaged(authorizedKeysFile(user.Home), []byte(content), 0600, force); err != nil {
+		return err
+	}
+	if out, err := p.sandbox.WithContext(ctx).Command("chown", "-R", name+":", sshDir).CombinedOutput(); err != nil {
+		return fmt.Errorf("chown failed: %s", out)
+	}
+	return nil
+}
+
+func authorizedKeysFile(home string) string {
+	return filepath.Join(home, ".ssh", "authorized_keys")
+}
+
+// parseAuthorizedKeys returns the keys of an authorized_keys file, leaving
+// out comments and blank lines
+func parseAuthorizedKeys(content string) []string {
+	var keys []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	return keys
+}
+
+// missingGroups returns the groups of want that have does not contain
+func missingGroups(have, want []string) []string {
+	in := make(map[string]bool, len(have))
+	for _, g := range have {
+		in[g] = true
+	}
+	var missing []string
+	for _, g := range want {
+		if !in[g] {
+			missing = append(missing, g)
+		}
+	}
+	return missing
+}
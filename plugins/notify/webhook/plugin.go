@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// WebhookPlugin posts notifications as JSON to an HTTP endpoint, which works
+// with Slack/Mattermost incoming webhooks and most alert routers
+type WebhookPlugin struct {
+	name    string
+	version string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func New() *WebhookPlugin {
+	return &WebhookPlugin{
+		name:    "webhook-notify",
+		version: "1.0.0",
+	}
+}
+
+func (p *WebhookPlugin) Name() string    { return p.name }
+func (p *WebhookPlugin) Version() string { return p.version }
+
+func (p *WebhookPlugin) Capabilities() []plugin.Capability {
+	return []plugin.Capability{plugin.CapabilityNotify}
+}
+
+func (p *WebhookPlugin) Init(ctx context.Context, config map[string]interface{}) error {
+	url, _ := config["url"].(string)
+	if url == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	p.url = url
+
+	timeout := 10 * time.Second
+	if s, ok := config["timeout"].(string); ok && s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("parse timeout: %w", err)
+		}
+		timeout = d
+	}
+	p.client = &http.Client{Timeout: timeout}
+
+	p.headers = make(map[string]string)
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for k, v := range headers {
+			p.headers[k] = fmt.Sprint(v)
+		}
+	}
+
+	return nil
+}
+
+// Permissions covers the webhook's host
+func (p *WebhookPlugin) Permissions() plugin.Permissions {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return plugin.Permissions{}
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return plugin.Permissions{Network: []string{net.JoinHostPort(u.Hostname(), port)}}
+}
+
+// SetSandbox routes the webhook's connections through the sandbox. Proxies
+// from the environment are not used, as they would hide the destination.
+func (p *WebhookPlugin) SetSandbox(sandbox *plugin.Sandbox) {
+	p.client.Transport = &http.Transport{DialContext: sandbox.DialContext}
+}
+
+func (p *WebhookPlugin) Notify(ctx context.Context, n *plugin.Notification) error {
+	payload := map[string]interface{}{
+		// "text" makes the payload render directly in Slack-style webhooks
+		"text":      fmt.Sprintf("[%s] %s: %s", n.Severity, n.Title, n.Message),
+		"title":     n.Title,
+		"message":   n.Message,
+		"severity":  n.Severity,
+		"timestamp": n.Timestamp,
+		"labels":    n.Labels,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (p *WebhookPlugin) Shutdown(ctx context.Context) error {
+	return nil
+}
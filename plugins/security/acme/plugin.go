@@ -3,7 +3,6 @@ package acme
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -14,6 +13,7 @@ type ACMEPlugin struct {
 	name    string
 	version string
 	config  *ACMEConfig
+	sandbox *plugin.Sandbox
 }
 
 type ACMEConfig struct {
@@ -67,14 +67,34 @@ func (p *ACMEPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// Permissions covers certbot, which keeps the certificates itself
+func (p *ACMEPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{Exec: []string{"certbot"}}
+}
+
+func (p *ACMEPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
+
+// CertDir is where certbot keeps a directory per certificate
+func (p *ACMEPlugin) CertDir() string { return p.config.CertDir }
+
 // ObtainCertificate obtains a new SSL certificate using certbot
-func (p *ACMEPlugin) ObtainCertificate(domain string) (*Certificate, error) {
+func (p *ACMEPlugin) ObtainCertificate(ctx context.Context, domain string) (*Certificate, error) {
+	return p.ObtainCertificateFor(ctx, domain, "")
+}
+
+// ObtainCertificateFor obtains a certificate registered to email, or to the
+// configured email when it is empty
+func (p *ACMEPlugin) ObtainCertificateFor(ctx context.Context, domain, email string) (*Certificate, error) {
+	if email == "" {
+		email = p.config.Email
+	}
+
 	args := []string{
 		"certonly",
 		"--webroot",
 		"-w", p.config.Webroot,
 		"-d", domain,
-		"--email", p.config.Email,
+		"--email", email,
 		"--agree-tos",
 		"--non-interactive",
 	}
@@ -83,7 +103,7 @@ func (p *ACMEPlugin) ObtainCertificate(domain string) (*Certificate, error) {
 		args = append(args, "--staging")
 	}
 
-	cmd := exec.Command("certbot", args...)
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("certbot failed: %s", output)
@@ -99,8 +119,8 @@ func (p *ACMEPlugin) ObtainCertificate(domain string) (*Certificate, error) {
 }
 
 // RenewCertificate renews an existing certificate
-func (p *ACMEPlugin) RenewCertificate(domain string) error {
-	cmd := exec.Command("certbot", "renew", "--cert-name", domain)
+func (p *ACMEPlugin) RenewCertificate(ctx context.Context, domain string) error {
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", "renew", "--cert-name", domain)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("renew failed: %s", output)
@@ -110,8 +130,8 @@ func (p *ACMEPlugin) RenewCertificate(domain string) error {
 }
 
 // RenewAllCertificates renews all certificates
-func (p *ACMEPlugin) RenewAllCertificates() error {
-	cmd := exec.Command("certbot", "renew")
+func (p *ACMEPlugin) RenewAllCertificates(ctx context.Context) error {
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", "renew")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("renew all failed: %s", output)
@@ -121,10 +141,10 @@ func (p *ACMEPlugin) RenewAllCertificates() error {
 }
 
 // RevokeCertificate revokes a certificate
-func (p *ACMEPlugin) RevokeCertificate(domain string) error {
+func (p *ACMEPlugin) RevokeCertificate(ctx context.Context, domain string) error {
 	certPath := filepath.Join(p.config.CertDir, domain, "fullchain.pem")
 
-	cmd := exec.Command("certbot", "revoke", "--cert-path", certPath)
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", "revoke", "--cert-path", certPath)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("revoke failed: %s", output)
@@ -133,11 +153,23 @@ func (p *ACMEPlugin) RevokeCertificate(domain string) error {
 	return nil
 }
 
+// DeleteCertificate removes a certificate and its renewal configuration
+// without revoking it
+func (p *ACMEPlugin) DeleteCertificate(ctx context.Context, domain string) error {
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", "delete", "--cert-name", domain, "--non-interactive")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("delete failed: %s", output)
+	}
+
+	return nil
+}
+
 // ListCertificates lists all managed certificates
-func (p *ACMEPlugin) ListCertificates() ([]*Certificate, error) {
+func (p *ACMEPlugin) ListCertificates(ctx context.Context) ([]*Certificate, error) {
 	certs := []*Certificate{}
 
-	cmd := exec.Command("certbot", "certificates")
+	cmd := p.sandbox.WithContext(ctx).Command("certbot", "certificates")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
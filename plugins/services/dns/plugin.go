@@ -1,13 +1,16 @@
 package dns
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
+	"github.com/bhangun/mandau/pkg/diff"
 	"github.com/bhangun/mandau/pkg/plugin"
 )
 
@@ -15,6 +18,7 @@ type DNSPlugin struct {
 	name    string
 	version string
 	config  *DNSConfig
+	sandbox *plugin.Sandbox
 }
 
 type DNSConfig struct {
@@ -105,50 +109,115 @@ func (p *DNSPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// CreateZone creates a DNS zone file
-func (p *DNSPlugin) CreateZone(zone *DNSZone) error {
-	zoneFile := filepath.Join(p.config.ZoneDir, "db."+zone.Domain)
+// Permissions covers the zone files, named.conf and the reload command
+func (p *DNSPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{
+		Exec:  []string{strings.Fields(p.config.ReloadCmd)[0]},
+		Write: []string{p.config.ZoneDir, p.config.NamedConf},
+	}
+}
 
-	tmpl := template.Must(template.New("zone").Parse(dnsZoneTemplate))
+func (p *DNSPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
 
-	file, err := os.Create(zoneFile)
+// CreateZone creates a DNS zone file. Unless force is set, a zone file
+// Mandau did not write or that was edited since is left alone.
+func (p *DNSPlugin) CreateZone(ctx context.Context, zone *DNSZone, force bool) error {
+	zoneFile := p.zonePath(zone.Domain)
+
+	content, err := renderZone(zone)
 	if err != nil {
-		return fmt.Errorf("create zone file: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	if err := tmpl.Execute(file, zone); err != nil {
-		return fmt.Errorf("execute template: %w", err)
+	if err := p.sandbox.WithContext(ctx).WriteManaged(zoneFile, content, 0644, force); err != nil {
+		return fmt.Errorf("create zone file: %w", err)
 	}
 
 	// Add zone to named.conf
-	if err := p.addZoneConfig(zone.Domain, zoneFile); err != nil {
+	if err := p.addZoneConfig(ctx, zone.Domain, zoneFile); err != nil {
 		return fmt.Errorf("add zone config: %w", err)
 	}
 
-	return p.reloadDNS()
+	return p.reloadDNS(ctx)
 }
 
-func (p *DNSPlugin) addZoneConfig(domain, zoneFile string) error {
-	config := fmt.Sprintf(`
-zone "%s" {
-    type master;
-    file "%s";
-};
-`, domain, zoneFile)
+// PlanZone diffs the zone file and named.conf entry CreateZone would write
+// against the current files, without writing them
+func (p *DNSPlugin) PlanZone(zone *DNSZone) (string, error) {
+	zoneFile := p.zonePath(zone.Domain)
 
-	f, err := os.OpenFile(p.config.NamedConf, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	content, err := renderZone(zone)
+	if err != nil {
+		return "", err
+	}
+	zoneDiff, err := diff.File(zoneFile, content)
+	if err != nil {
+		return "", err
+	}
+
+	named, err := os.ReadFile(p.config.NamedConf)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	namedDiff, err := diff.File(p.config.NamedConf, append(named, zoneConfig(zone.Domain, zoneFile)...))
+	if err != nil {
+		return "", err
+	}
+	return zoneDiff + namedDiff, nil
+}
+
+// ListZones returns the domains that have a zone file, sorted
+func (p *DNSPlugin) ListZones() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(p.config.ZoneDir, "db.*"))
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0, len(files))
+	for _, path := range files {
+		zones = append(zones, strings.TrimPrefix(filepath.Base(path), "db."))
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+func (p *DNSPlugin) zonePath(domain string) string {
+	return filepath.Join(p.config.ZoneDir, "db."+domain)
+}
+
+func renderZone(zone *DNSZone) ([]byte, error) {
+	tmpl := template.Must(template.New("zone").Parse(dnsZoneTemplate))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, zone); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *DNSPlugin) addZoneConfig(ctx context.Context, domain, zoneFile string) error {
+	f, err := p.sandbox.WithContext(ctx).OpenFile(p.config.NamedConf, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(config)
+	_, err = f.WriteString(zoneConfig(domain, zoneFile))
 	return err
 }
 
-func (p *DNSPlugin) reloadDNS() error {
-	cmd := exec.Command("sh", "-c", p.config.ReloadCmd)
+// zoneConfig is the named.conf entry of a zone
+func zoneConfig(domain, zoneFile string) string {
+	return fmt.Sprintf(`
+zone "%s" {
+    type master;
+    file "%s";
+};
+`, domain, zoneFile)
+}
+
+func (p *DNSPlugin) reloadDNS(ctx context.Context) error {
+	args := strings.Fields(p.config.ReloadCmd)
+	cmd := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("reload failed: %s", output)
@@ -156,47 +225,68 @@ func (p *DNSPlugin) reloadDNS() error {
 	return nil
 }
 
-// AddARecord adds an A record to a zone
-func (p *DNSPlugin) AddARecord(domain, name, ip string, ttl int) error {
-	// Read existing zone
-	zoneFile := filepath.Join(p.config.ZoneDir, "db."+domain)
+// AddARecord adds an A record to a zone, refusing like CreateZone unless
+// force is set
+func (p *DNSPlugin) AddARecord(ctx context.Context, domain, name, ip string, ttl int, force bool) error {
+	return p.addRecord(ctx, domain, aRecord(name, ip, ttl), force)
+}
+
+// PlanARecord diffs the zone file AddARecord would write
+func (p *DNSPlugin) PlanARecord(domain, name, ip string, ttl int) (string, error) {
+	return p.planRecord(domain, aRecord(name, ip, ttl))
+}
+
+// AddCNAMERecord adds a CNAME record, refusing like CreateZone unless force
+// is set
+func (p *DNSPlugin) AddCNAMERecord(ctx context.Context, domain, name, target string, ttl int, force bool) error {
+	return p.addRecord(ctx, domain, cnameRecord(name, target, ttl), force)
+}
+
+// PlanCNAMERecord diffs the zone file AddCNAMERecord would write
+func (p *DNSPlugin) PlanCNAMERecord(domain, name, target string, ttl int) (string, error) {
+	return p.planRecord(domain, cnameRecord(name, target, ttl))
+}
+
+func aRecord(name, ip string, ttl int) string {
+	return fmt.Sprintf("%s\t%d\tIN\tA\t%s\n", name, ttl, ip)
+}
+
+func cnameRecord(name, target string, ttl int) string {
+	return fmt.Sprintf("%s\t%d\tIN\tCNAME\t%s.\n", name, ttl, target)
+}
 
-	content, err := os.ReadFile(zoneFile)
+// withRecord returns a zone file with record appended
+func (p *DNSPlugin) withRecord(domain, record string) ([]byte, error) {
+	content, err := os.ReadFile(p.zonePath(domain))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Append new record
-	record := fmt.Sprintf("%s\t%d\tIN\tA\t%s\n", name, ttl, ip)
-	content = append(content, []byte(record)...)
-
 	// Increment serial
 	// (simplified - in production would parse and increment properly)
 
-	if err := os.WriteFile(zoneFile, content, 0644); err != nil {
-		return err
-	}
-
-	return p.reloadDNS()
+	return append(content, record...), nil
 }
 
-// AddCNAMERecord adds a CNAME record
-func (p *DNSPlugin) AddCNAMERecord(domain, name, target string, ttl int) error {
-	zoneFile := filepath.Join(p.config.ZoneDir, "db."+domain)
-
-	content, err := os.ReadFile(zoneFile)
+func (p *DNSPlugin) addRecord(ctx context.Context, domain, record string, force bool) error {
+	content, err := p.withRecord(domain, record)
 	if err != nil {
 		return err
 	}
 
-	record := fmt.Sprintf("%s\t%d\tIN\tCNAME\t%s.\n", name, ttl, target)
-	content = append(content, []byte(record)...)
-
-	if err := os.WriteFile(zoneFile, content, 0644); err != nil {
+	if err := p.sandbox.WithContext(ctx).WriteManaged(p.zonePath(domain), content, 0644, force); err != nil {
 		return err
 	}
 
-	return p.reloadDNS()
+	return p.reloadDNS(ctx)
+}
+
+func (p *DNSPlugin) planRecord(domain, record string) (string, error) {
+	content, err := p.withRecord(domain, record)
+	if err != nil {
+		return "", err
+	}
+	return diff.File(p.zonePath(domain), content)
 }
 
 const dnsZoneTemplate = `; Managed by Mandau
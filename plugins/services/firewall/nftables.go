@@ -0,0 +1,141 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rules of the nftables backend live in their own table of the inet
+// family, which covers IPv4 and IPv6 alike. Its input chain accepts what
+// no rule drops, leaving the host's default policy to the other tables.
+const (
+	nftTable = "mandau"
+	nftChain = "input"
+)
+
+// ensureNftChain creates Mandau's table and chain unless they exist
+func (p *FirewallPlugin) ensureNftChain(ctx context.Context) error {
+	for _, args := range [][]string{
+		{"add", "table", "inet", nftTable},
+		{"add", "chain", "inet", nftTable, nftChain, "{ type filter hook input priority 0 ; policy accept ; }"},
+	} {
+		if output, err := p.sandbox.WithContext(ctx).Command("nft", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("nft failed: %s", output)
+		}
+	}
+	return nil
+}
+
+// nftRuleExpr is rule as nft prints it back, so listed rules can be
+// matched to the ones added
+func nftRuleExpr(rule *FirewallRule) []string {
+	var expr []string
+	family, _ := rule.family()
+	if rule.FromIP != "" && rule.FromIP != "any" {
+		expr = append(expr, family, "saddr", rule.FromIP)
+	}
+	if rule.ToIP != "" && rule.ToIP != "any" {
+		expr = append(expr, family, "daddr", rule.ToIP)
+	}
+
+	// th matches the ports of any transport protocol
+	proto := rule.Proto
+	if proto == "" || proto == "any" {
+		proto = "th"
+		if rule.FromPort == 0 && rule.ToPort == 0 {
+			proto = ""
+		}
+	} else if rule.FromPort == 0 && rule.ToPort == 0 {
+		expr = append(expr, "meta", "l4proto", proto)
+	}
+	if rule.FromPort > 0 {
+		expr = append(expr, proto, "sport", strconv.Itoa(rule.FromPort))
+	}
+	if rule.ToPort > 0 {
+		expr = append(expr, proto, "dport", strconv.Itoa(rule.ToPort))
+	}
+
+	if rule.Limit != nil {
+		expr = append(expr, "ct", "state", "new", "limit", "rate", rule.Limit.String(),
+			"burst", strconv.Itoa(rule.Limit.burst()), "packets")
+	}
+
+	expr = append(expr, map[string]string{"allow": "accept", "deny": "drop", "reject": "reject"}[rule.Action])
+	if comment := rule.comment(); comment != "" {
+		expr = append(expr, "comment", strconv.Quote(comment))
+	}
+	return expr
+}
+
+// nftRule is a rule of Mandau's chain and its handle
+type nftRule struct {
+	expr   string
+	handle int
+}
+
+// listNftRules lists the rules of Mandau's chain. A missing table has none.
+func (p *FirewallPlugin) listNftRules(ctx context.Context) ([]nftRule, error) {
+	output, err := p.sandbox.WithContext(ctx).Command("nft", "-a", "list", "chain", "inet", nftTable, nftChain).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "No such file or directory") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("nft failed: %s", output)
+	}
+
+	var rules []nftRule
+	for _, line := range strings.Split(string(output), "\n") {
+		expr, handle, ok := strings.Cut(strings.TrimSpace(line), " # handle ")
+		if !ok || strings.HasPrefix(expr, "type ") || strings.HasSuffix(expr, "{") {
+			continue
+		}
+		n, err := strconv.Atoi(handle)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, nftRule{expr: expr, handle: n})
+	}
+	return rules, nil
+}
+
+func (p *FirewallPlugin) nftRules(ctx context.Context) ([]string, error) {
+	rules, err := p.listNftRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		lines[i] = rule.expr + " # handle " + strconv.Itoa(rule.handle)
+	}
+	return lines, nil
+}
+
+func (p *FirewallPlugin) deleteNftRule(ctx context.Context, handle int) error {
+	output, err := p.sandbox.WithContext(ctx).Command("nft", "delete", "rule", "inet", nftTable, nftChain, "handle", strconv.Itoa(handle)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("delete failed: %s", output)
+	}
+	return nil
+}
+
+// deleteNftRules deletes the rules of Mandau's chain that match reports,
+// returning how many it deleted
+func (p *FirewallPlugin) deleteNftRules(ctx context.Context, match func(expr string) bool) (int, error) {
+	rules, err := p.listNftRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, rule := range rules {
+		if !match(rule.expr) {
+			continue
+		}
+		if err := p.deleteNftRule(ctx, rule.handle); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -3,10 +3,12 @@ package firewall
 import (
 	"context"
 	"fmt"
+	"net"
 	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/bhangun/mandau/pkg/diff"
 	"github.com/bhangun/mandau/pkg/plugin"
 )
 
@@ -14,7 +16,9 @@ type FirewallPlugin struct {
 	name    string
 	version string
 	config  *FirewallConfig
-	backend string // ufw or iptables
+	backend string // ufw, iptables or nftables
+	ip6     bool   // ip6tables is there for IPv6 rules of the iptables backend
+	sandbox *plugin.Sandbox
 }
 
 type FirewallConfig struct {
@@ -25,11 +29,20 @@ type FirewallConfig struct {
 type FirewallRule struct {
 	Action   string // allow, deny, reject
 	Proto    string // tcp, udp, any
-	FromIP   string
+	FromIP   string // Address or CIDR, IPv4 or IPv6
 	FromPort int
 	ToIP     string
 	ToPort   int
 	Comment  string
+	Limit    *RateLimit // Accept new connections only up to a rate
+	Set      string     // Named rule set the rule is removed with
+}
+
+// backendCommands are the binaries each backend runs
+var backendCommands = map[string][]string{
+	"ufw":      {"ufw"},
+	"iptables": {"iptables", "ip6tables"},
+	"nftables": {"nft"},
 }
 
 func New() *FirewallPlugin {
@@ -46,26 +59,38 @@ func (p *FirewallPlugin) Capabilities() []plugin.Capability {
 	return []plugin.Capability{plugin.CapabilityStorage}
 }
 
+// Init uses the configured backend, or the first of ufw, iptables and
+// nftables found
 func (p *FirewallPlugin) Init(ctx context.Context, config map[string]interface{}) error {
-	backend := "ufw"
-	if b, ok := config["backend"].(string); ok {
-		backend = b
-	}
-
 	p.config = &FirewallConfig{
-		Backend:       backend,
+		Backend:       plugin.GetStringConfig(config, "backend"),
 		DefaultPolicy: "deny",
 	}
 
-	// Detect available backend
-	if _, err := exec.LookPath("ufw"); err == nil {
-		p.backend = "ufw"
-	} else if _, err := exec.LookPath("iptables"); err == nil {
-		p.backend = "iptables"
-	} else {
-		return fmt.Errorf("no firewall backend found")
+	switch p.config.Backend {
+	case "":
+		for _, backend := range []string{"ufw", "iptables", "nftables"} {
+			if _, err := exec.LookPath(backendCommands[backend][0]); err == nil {
+				p.backend = backend
+				break
+			}
+		}
+		if p.backend == "" {
+			return fmt.Errorf("no firewall backend found")
+		}
+	case "ufw", "iptables", "nftables":
+		if _, err := exec.LookPath(backendCommands[p.config.Backend][0]); err != nil {
+			return fmt.Errorf("firewall backend %s: %w", p.config.Backend, err)
+		}
+		p.backend = p.config.Backend
+	default:
+		return fmt.Errorf("unknown firewall backend %q: use ufw, iptables or nftables", p.config.Backend)
 	}
 
+	if p.backend == "iptables" {
+		_, err := exec.LookPath("ip6tables")
+		p.ip6 = err == nil
+	}
 	return nil
 }
 
@@ -73,16 +98,149 @@ func (p *FirewallPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// Permissions covers the detected backend's commands
+func (p *FirewallPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{Exec: backendCommands[p.backend]}
+}
+
+func (p *FirewallPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
+
+// Validate checks the addresses, rate limit and set name of a rule
+func (rule *FirewallRule) Validate() error {
+	switch rule.Action {
+	case "allow", "deny", "reject":
+	default:
+		return fmt.Errorf("invalid action %q: use allow, deny or reject", rule.Action)
+	}
+	if _, err := rule.family(); err != nil {
+		return err
+	}
+	if rule.Set != "" && !validSetName.MatchString(rule.Set) {
+		return fmt.Errorf("invalid rule set name %q: use letters, digits, '.', '_' and '-'", rule.Set)
+	}
+	if rule.Limit != nil && rule.Action != "allow" {
+		return fmt.Errorf("only allow rules take a rate limit")
+	}
+	return nil
+}
+
+// family is "ip" or "ip6" for a rule naming addresses of one family, and
+// empty for rules applying to both
+func (rule *FirewallRule) family() (string, error) {
+	var family string
+	for _, addr := range []string{rule.FromIP, rule.ToIP} {
+		if addr == "" || addr == "any" {
+			continue
+		}
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			var err error
+			if ip, _, err = net.ParseCIDR(addr); err != nil {
+				return "", fmt.Errorf("invalid address %q: want an IP address or CIDR", addr)
+			}
+		}
+		f := "ip6"
+		if ip.To4() != nil {
+			f = "ip"
+		}
+		if family != "" && family != f {
+			return "", fmt.Errorf("rule mixes IPv4 and IPv6 addresses")
+		}
+		family = f
+	}
+	return family, nil
+}
+
+// comment is the rule's comment, led by the tag of its set
+func (rule *FirewallRule) comment() string {
+	if rule.Set == "" {
+		return rule.Comment
+	}
+	if rule.Comment == "" {
+		return setTag(rule.Set)
+	}
+	return setTag(rule.Set) + " " + rule.Comment
+}
+
 // AddRule adds a firewall rule
-func (p *FirewallPlugin) AddRule(rule *FirewallRule) error {
-	if p.backend == "ufw" {
-		return p.addRuleUFW(rule)
+func (p *FirewallPlugin) AddRule(ctx context.Context, rule *FirewallRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if p.backend == "nftables" {
+		if err := p.ensureNftChain(ctx); err != nil {
+			return err
+		}
 	}
-	return p.addRuleIPTables(rule)
+
+	for _, args := range p.ruleCommands("add", rule) {
+		output, err := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %s", args[0], output)
+		}
+	}
+	return nil
+}
+
+// PlanRule diffs the rules before and after AddRule, without adding the
+// rule. The backends keep no rule file, so the new rule shows as the
+// commands that add it, after the current rules.
+func (p *FirewallPlugin) PlanRule(ctx context.Context, rule *FirewallRule) (string, error) {
+	if err := rule.Validate(); err != nil {
+		return "", err
+	}
+	rules, err := p.ListRules(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var commands string
+	for _, args := range p.ruleCommands("add", rule) {
+		commands += shellJoin(args) + "\n"
+	}
+
+	current := joinLines(rules)
+	label := p.backend + " rules"
+	return diff.Unified(label, label, current, current+commands), nil
+}
+
+// ruleCommands are the commands adding rule, or deleting it when op is
+// "delete". An iptables rule without addresses goes to ip6tables too.
+func (p *FirewallPlugin) ruleCommands(op string, rule *FirewallRule) [][]string {
+	switch p.backend {
+	case "ufw":
+		args := ufwRuleArgs(rule)
+		if op == "delete" {
+			args = append([]string{"delete"}, args...)
+		}
+		return [][]string{append([]string{"ufw"}, args...)}
+	case "nftables":
+		return [][]string{append([]string{"nft", "add", "rule", "inet", nftTable, nftChain}, nftRuleExpr(rule)...)}
+	}
+
+	flag := "-A"
+	if op == "delete" {
+		flag = "-D"
+	}
+	family, _ := rule.family()
+	var commands [][]string
+	if family != "ip6" {
+		commands = append(commands, append([]string{"iptables"}, iptablesRuleArgs(flag, rule)...))
+	}
+	if family != "ip" && p.ip6 {
+		commands = append(commands, append([]string{"ip6tables"}, iptablesRuleArgs(flag, rule)...))
+	}
+	return commands
 }
 
-func (p *FirewallPlugin) addRuleUFW(rule *FirewallRule) error {
-	args := []string{rule.Action}
+func ufwRuleArgs(rule *FirewallRule) []string {
+	// ufw has one rate limit of its own: six connections from an address
+	// in thirty seconds
+	action := rule.Action
+	if rule.Limit != nil {
+		action = "limit"
+	}
+	args := []string{action}
 
 	if rule.Proto != "" && rule.Proto != "any" {
 		args = append(args, "proto", rule.Proto)
@@ -98,67 +256,73 @@ func (p *FirewallPlugin) addRuleUFW(rule *FirewallRule) error {
 
 	if rule.ToIP != "" {
 		args = append(args, "to", rule.ToIP)
+	} else if rule.FromIP != "" {
+		args = append(args, "to", "any")
 	}
 
 	if rule.ToPort > 0 {
 		args = append(args, "port", strconv.Itoa(rule.ToPort))
 	}
 
-	if rule.Comment != "" {
-		args = append(args, "comment", rule.Comment)
-	}
-
-	cmd := exec.Command("ufw", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ufw failed: %s", output)
+	if comment := rule.comment(); comment != "" {
+		args = append(args, "comment", comment)
 	}
 
-	return nil
+	return args
 }
 
-func (p *FirewallPlugin) addRuleIPTables(rule *FirewallRule) error {
-	chain := "INPUT"
-	args := []string{"-A", chain}
+func iptablesRuleArgs(op string, rule *FirewallRule) []string {
+	args := []string{op, "INPUT"}
 
 	if rule.Proto != "" && rule.Proto != "any" {
 		args = append(args, "-p", rule.Proto)
 	}
 
-	if rule.FromIP != "" {
+	if rule.FromIP != "" && rule.FromIP != "any" {
 		args = append(args, "-s", rule.FromIP)
 	}
 
-	if rule.ToIP != "" {
+	if rule.ToIP != "" && rule.ToIP != "any" {
 		args = append(args, "-d", rule.ToIP)
 	}
 
+	if rule.FromPort > 0 {
+		args = append(args, "--sport", strconv.Itoa(rule.FromPort))
+	}
+
 	if rule.ToPort > 0 {
 		args = append(args, "--dport", strconv.Itoa(rule.ToPort))
 	}
 
-	target := strings.ToUpper(rule.Action)
-	args = append(args, "-j", target)
+	if rule.Limit != nil {
+		args = append(args, "-m", "conntrack", "--ctstate", "NEW",
+			"-m", "limit", "--limit", rule.Limit.String(), "--limit-burst", strconv.Itoa(rule.Limit.burst()))
+	}
 
-	cmd := exec.Command("iptables", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("iptables failed: %s", output)
+	if comment := rule.comment(); comment != "" {
+		args = append(args, "-m", "comment", "--comment", comment)
 	}
 
-	return nil
+	target := map[string]string{"allow": "ACCEPT", "deny": "DROP", "reject": "REJECT"}[rule.Action]
+	args = append(args, "-j", target)
+
+	return args
 }
 
-// DeleteRule deletes a firewall rule
-func (p *FirewallPlugin) DeleteRule(ruleNumber int) error {
+// DeleteRule deletes a firewall rule by its number in ListRules, which for
+// nftables is the rule's handle
+func (p *FirewallPlugin) DeleteRule(ctx context.Context, ruleNumber int) error {
+	if p.backend == "nftables" {
+		return p.deleteNftRule(ctx, ruleNumber)
+	}
 	if p.backend == "ufw" {
-		cmd := exec.Command("ufw", "delete", strconv.Itoa(ruleNumber))
+		cmd := p.sandbox.WithContext(ctx).Command("ufw", "delete", strconv.Itoa(ruleNumber))
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("delete failed: %s", output)
 		}
 	} else {
-		cmd := exec.Command("iptables", "-D", "INPUT", strconv.Itoa(ruleNumber))
+		cmd := p.sandbox.WithContext(ctx).Command("iptables", "-D", "INPUT", strconv.Itoa(ruleNumber))
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("delete failed: %s", output)
@@ -168,28 +332,151 @@ func (p *FirewallPlugin) DeleteRule(ruleNumber int) error {
 	return nil
 }
 
+// DeleteMatchingRule deletes the rule AddRule added for rule
+func (p *FirewallPlugin) DeleteMatchingRule(ctx context.Context, rule *FirewallRule) error {
+	if p.backend == "nftables" {
+		expr := strings.Join(nftRuleExpr(rule), " ")
+		deleted, err := p.deleteNftRules(ctx, func(line string) bool { return line == expr })
+		if err == nil && deleted == 0 {
+			err = fmt.Errorf("delete failed: no rule %s", expr)
+		}
+		return err
+	}
+
+	for _, args := range p.ruleCommands("delete", rule) {
+		output, err := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("delete failed: %s", output)
+		}
+	}
+	return nil
+}
+
 // AllowPort is a convenience method to allow a port
-func (p *FirewallPlugin) AllowPort(port int, proto string) error {
-	return p.AddRule(&FirewallRule{
-		Action: "allow",
+func (p *FirewallPlugin) AllowPort(ctx context.Context, port int, proto string) error {
+	return p.AddRule(ctx, portRule("allow", port, proto))
+}
+
+// PlanAllowPort diffs the rule AllowPort would add
+func (p *FirewallPlugin) PlanAllowPort(ctx context.Context, port int, proto string) (string, error) {
+	return p.PlanRule(ctx, portRule("allow", port, proto))
+}
+
+func portRule(action string, port int, proto string) *FirewallRule {
+	return &FirewallRule{
+		Action: action,
 		Proto:  proto,
 		ToPort: port,
-	})
+	}
+}
+
+// AllowFrom allows port only from cidr, an IPv4 or IPv6 address or network
+func (p *FirewallPlugin) AllowFrom(ctx context.Context, cidr string, port int, proto string) error {
+	rule := portRule("allow", port, proto)
+	rule.FromIP = cidr
+	return p.AddRule(ctx, rule)
+}
+
+// AllowLimited allows new connections to port up to limit. Beyond it they
+// fall through to the default policy.
+func (p *FirewallPlugin) AllowLimited(ctx context.Context, port int, proto string, limit RateLimit) error {
+	rule := portRule("allow", port, proto)
+	rule.Limit = &limit
+	return p.AddRule(ctx, rule)
+}
+
+// RateLimit caps how often a rule accepts new connections, e.g. 10 per
+// minute with bursts of 20. ufw applies its own limit instead.
+type RateLimit struct {
+	Rate  int
+	Per   string // second, minute, hour or day
+	Burst int    // Default 5, as in iptables
+}
+
+// ParseRateLimit parses "<rate>/<unit>", e.g. "10/minute"
+func ParseRateLimit(value string, burst int) (*RateLimit, error) {
+	rate, per, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(rate)
+	if !ok || err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid rate limit %q: want e.g. 10/minute", value)
+	}
+	switch per {
+	case "second", "minute", "hour", "day":
+	default:
+		return nil, fmt.Errorf("invalid rate limit %q: the unit is second, minute, hour or day", value)
+	}
+	if burst < 0 {
+		return nil, fmt.Errorf("invalid burst %d", burst)
+	}
+	return &RateLimit{Rate: n, Per: per, Burst: burst}, nil
+}
+
+func (l *RateLimit) String() string {
+	return strconv.Itoa(l.Rate) + "/" + l.Per
+}
+
+func (l *RateLimit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return 5
+}
+
+// RevokePort deletes the rule AllowPort added
+func (p *FirewallPlugin) RevokePort(ctx context.Context, port int, proto string) error {
+	return p.DeleteMatchingRule(ctx, portRule("allow", port, proto))
+}
+
+// PortAllowed reports whether a rule already allows port, so callers only
+// undo rules they added themselves
+func (p *FirewallPlugin) PortAllowed(ctx context.Context, port int, proto string) (bool, error) {
+	rules, err := p.ListRules(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range rules {
+		fields := strings.Fields(line)
+		if p.backend == "ufw" {
+			// "[ 1] 80/tcp    ALLOW IN    Anywhere"
+			for i, f := range fields {
+				if (f == strconv.Itoa(port)+"/"+proto || f == strconv.Itoa(port)) && i+1 < len(fields) && fields[i+1] == "ALLOW" {
+					return true, nil
+				}
+			}
+			continue
+		}
+		if p.backend == "nftables" {
+			// "tcp dport 80 accept # handle 4"
+			rule, _, _ := strings.Cut(strings.TrimSpace(line), " # handle ")
+			if rule == proto+" dport "+strconv.Itoa(port)+" accept" {
+				return true, nil
+			}
+			continue
+		}
+		// "1    ACCEPT     tcp  --  0.0.0.0/0  0.0.0.0/0  tcp dpt:80"
+		if len(fields) > 2 && fields[1] == "ACCEPT" && fields[2] == proto && fields[len(fields)-1] == "dpt:"+strconv.Itoa(port) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // DenyPort is a convenience method to deny a port
-func (p *FirewallPlugin) DenyPort(port int, proto string) error {
-	return p.AddRule(&FirewallRule{
-		Action: "deny",
-		Proto:  proto,
-		ToPort: port,
-	})
+func (p *FirewallPlugin) DenyPort(ctx context.Context, port int, proto string) error {
+	return p.AddRule(ctx, portRule("deny", port, proto))
+}
+
+// PlanDenyPort diffs the rule DenyPort would add
+func (p *FirewallPlugin) PlanDenyPort(ctx context.Context, port int, proto string) (string, error) {
+	return p.PlanRule(ctx, portRule("deny", port, proto))
 }
 
-// Enable enables the firewall
-func (p *FirewallPlugin) Enable() error {
+// Enable enables the firewall. Only ufw can be switched on and off; the
+// rules of the other backends apply as soon as they are added.
+func (p *FirewallPlugin) Enable(ctx context.Context) error {
 	if p.backend == "ufw" {
-		cmd := exec.Command("ufw", "--force", "enable")
+		cmd := p.sandbox.WithContext(ctx).Command("ufw", "--force", "enable")
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("enable failed: %s", output)
@@ -199,9 +486,9 @@ func (p *FirewallPlugin) Enable() error {
 }
 
 // Disable disables the firewall
-func (p *FirewallPlugin) Disable() error {
+func (p *FirewallPlugin) Disable(ctx context.Context) error {
 	if p.backend == "ufw" {
-		cmd := exec.Command("ufw", "disable")
+		cmd := p.sandbox.WithContext(ctx).Command("ufw", "disable")
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("disable failed: %s", output)
@@ -210,21 +497,42 @@ func (p *FirewallPlugin) Disable() error {
 	return nil
 }
 
-// ListRules lists all firewall rules
-func (p *FirewallPlugin) ListRules() ([]string, error) {
-	var cmd *exec.Cmd
-
-	if p.backend == "ufw" {
-		cmd = exec.Command("ufw", "status", "numbered")
-	} else {
-		cmd = exec.Command("iptables", "-L", "-n", "--line-numbers")
+// ListRules lists all firewall rules. The iptables backend lists the IPv6
+// rules after the IPv4 ones; nftables lists the rules of Mandau's table
+// with their handles.
+func (p *FirewallPlugin) ListRules(ctx context.Context) ([]string, error) {
+	var commands [][]string
+	switch p.backend {
+	case "ufw":
+		commands = [][]string{{"ufw", "status", "numbered"}}
+	case "nftables":
+		return p.nftRules(ctx)
+	default:
+		commands = [][]string{{"iptables", "-L", "-n", "--line-numbers"}}
+		if p.ip6 {
+			commands = append(commands, []string{"ip6tables", "-L", "-n", "--line-numbers"})
+		}
 	}
 
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+	var lines []string
+	for _, args := range commands {
+		output, err := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, strings.Split(string(output), "\n")...)
 	}
-
-	lines := strings.Split(string(output), "\n")
 	return lines, nil
 }
+
+// shellJoin quotes the arguments of a command that a shell would split
+func shellJoin(args []string) string {
+	command := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t\"'{};") {
+			arg = strconv.Quote(arg)
+		}
+		command[i] = arg
+	}
+	return strings.Join(command, " ")
+}
@@ -0,0 +1,187 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/diff"
+)
+
+// Rule sets group the rules of one application. Each rule of a set carries
+// the set's tag in its comment, so the set is found again in the backend's
+// own rules without keeping state of its own.
+
+// validSetName keeps set names free of what a comment or shell would split
+var validSetName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func setTag(name string) string {
+	return "mandau:" + name
+}
+
+// inSet reports whether a listed rule carries the tag of set name
+func inSet(line, name string) bool {
+	tag := setTag(name)
+	for rest := line; ; {
+		i := strings.Index(rest, tag)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(tag):]
+		if rest == "" || !isSetNameChar(rest[0]) {
+			return true
+		}
+	}
+}
+
+func isSetNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// AddRuleSet adds rules as the set name. When one fails, the rules added
+// before it are deleted again.
+func (p *FirewallPlugin) AddRuleSet(ctx context.Context, name string, rules []*FirewallRule) error {
+	for _, rule := range rules {
+		rule.Set = name
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i, rule := range rules {
+		if err := p.AddRule(ctx, rule); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				p.DeleteMatchingRule(ctx, rules[j])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveRuleSet deletes every rule of the set name, returning how many it
+// deleted
+func (p *FirewallPlugin) RemoveRuleSet(ctx context.Context, name string) (int, error) {
+	if !validSetName.MatchString(name) {
+		return 0, fmt.Errorf("invalid rule set name %q", name)
+	}
+
+	switch p.backend {
+	case "nftables":
+		return p.deleteNftRules(ctx, func(expr string) bool { return inSet(expr, name) })
+	case "ufw":
+		return p.removeUFWSet(ctx, name)
+	}
+
+	deleted := 0
+	commands := []string{"iptables"}
+	if p.ip6 {
+		commands = append(commands, "ip6tables")
+	}
+	for _, command := range commands {
+		output, err := p.sandbox.WithContext(ctx).Command(command, "-S", "INPUT").Output()
+		if err != nil {
+			return deleted, fmt.Errorf("%s failed: %w", command, err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if !strings.HasPrefix(line, "-A ") || !inSet(line, name) {
+				continue
+			}
+			args := splitQuoted(line)
+			args[0] = "-D"
+			if output, err := p.sandbox.WithContext(ctx).Command(command, args...).CombinedOutput(); err != nil {
+				return deleted, fmt.Errorf("delete failed: %s", output)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// removeUFWSet deletes the numbered rules of a set, last first so the
+// numbers of the others stay put
+func (p *FirewallPlugin) removeUFWSet(ctx context.Context, name string) (int, error) {
+	rules, err := p.ListRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// "[ 3] 22/tcp    ALLOW IN    10.0.0.0/8    # mandau:web"
+	var numbers []int
+	for _, line := range rules {
+		number, rest, ok := strings.Cut(strings.TrimPrefix(line, "["), "]")
+		n, err := strconv.Atoi(strings.TrimSpace(number))
+		if !ok || err != nil || !inSet(rest, name) {
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(numbers)))
+
+	for i, n := range numbers {
+		if output, err := p.sandbox.WithContext(ctx).Command("ufw", "--force", "delete", strconv.Itoa(n)).CombinedOutput(); err != nil {
+			return i, fmt.Errorf("delete failed: %s", output)
+		}
+	}
+	return len(numbers), nil
+}
+
+// PlanRemoveRuleSet diffs the rules before and after RemoveRuleSet
+func (p *FirewallPlugin) PlanRemoveRuleSet(ctx context.Context, name string) (string, error) {
+	rules, err := p.ListRules(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var kept []string
+	for _, line := range rules {
+		if !inSet(line, name) {
+			kept = append(kept, line)
+		}
+	}
+	label := p.backend + " rules"
+	return diff.Unified(label, label, joinLines(rules), joinLines(kept)), nil
+}
+
+func joinLines(lines []string) string {
+	text := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+// splitQuoted splits a rule as iptables -S prints it, which quotes
+// arguments holding spaces
+func splitQuoted(line string) []string {
+	var args []string
+	var arg strings.Builder
+	quoted, inArg := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(line):
+			i++
+			arg.WriteByte(line[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case c == ' ' && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
@@ -1,20 +1,25 @@
 package nginx
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/bhangun/mandau/pkg/diff"
 	"github.com/bhangun/mandau/pkg/plugin"
 )
 
 type NginxPlugin struct {
-	name    string
-	version string
-	config  *NginxConfig
+	name     string
+	version  string
+	config   *NginxConfig
+	sandbox  *plugin.Sandbox
+	vhost    *template.Template
+	upstream *template.Template
 }
 
 type NginxConfig struct {
@@ -24,6 +29,7 @@ type NginxConfig struct {
 	ReloadCommand string
 	TestCommand   string
 	AutoReload    bool
+	TemplateDir   string // Overrides of vhost.conf.tmpl and upstream.conf.tmpl
 }
 
 type VirtualHost struct {
@@ -37,7 +43,7 @@ type VirtualHost struct {
 	ProxyPass    string
 	AccessLog    string
 	ErrorLog     string
-	CustomConfig string
+	CustomConfig string // Extra directives at the end of the server block
 }
 
 type Location struct {
@@ -82,6 +88,17 @@ func (p *NginxPlugin) Init(ctx context.Context, config map[string]interface{}) e
 	if configDir, ok := config["config_dir"].(string); ok {
 		p.config.ConfigDir = configDir
 	}
+	p.config.TemplateDir = plugin.GetStringConfig(config, "template_dir")
+
+	var err error
+	p.vhost, err = plugin.LoadTemplate(p.config.TemplateDir, "vhost.conf.tmpl", nginxVhostTemplate, sampleVirtualHost)
+	if err != nil {
+		return err
+	}
+	p.upstream, err = plugin.LoadTemplate(p.config.TemplateDir, "upstream.conf.tmpl", nginxUpstreamTemplate, upstreamData("app", []string{"127.0.0.1:8080"}, "least_conn"))
+	if err != nil {
+		return err
+	}
 
 	// Ensure directories exist
 	os.MkdirAll(p.config.EnabledDir, 0755)
@@ -94,34 +111,56 @@ func (p *NginxPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// CreateVirtualHost creates a new nginx virtual host configuration
-func (p *NginxPlugin) CreateVirtualHost(vhost *VirtualHost) error {
-	configPath := filepath.Join(p.config.AvailableDir, vhost.ServerName+".conf")
+// Permissions covers the nginx config directories and the test and reload
+// commands
+func (p *NginxPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{
+		Exec:  []string{strings.Fields(p.config.TestCommand)[0], strings.Fields(p.config.ReloadCommand)[0]},
+		Write: []string{p.config.ConfigDir, p.config.EnabledDir, p.config.AvailableDir},
+	}
+}
 
-	// Generate config from template
-	tmpl := template.Must(template.New("vhost").Parse(nginxVhostTemplate))
+func (p *NginxPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
 
-	file, err := os.Create(configPath)
+// CreateVirtualHost creates a new nginx virtual host configuration. Unless
+// force is set, a config Mandau did not write or that was edited since is
+// left alone.
+func (p *NginxPlugin) CreateVirtualHost(ctx context.Context, vhost *VirtualHost, force bool) error {
+	configPath := p.vhostPath(vhost.ServerName)
+
+	config, err := render(p.vhost, vhost)
 	if err != nil {
-		return fmt.Errorf("create config: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	if err := tmpl.Execute(file, vhost); err != nil {
-		return fmt.Errorf("execute template: %w", err)
+	if err := p.sandbox.WithContext(ctx).WriteManaged(configPath, config, 0644, force); err != nil {
+		return fmt.Errorf("create config: %w", err)
 	}
 
 	// Test configuration
-	if err := p.testConfig(); err != nil {
-		os.Remove(configPath)
+	if err := p.testConfig(ctx); err != nil {
+		p.sandbox.WithContext(ctx).RemoveManaged(configPath, true)
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
 	return nil
 }
 
+// PlanVirtualHost diffs the config CreateVirtualHost would write against
+// the current one, without writing it
+func (p *NginxPlugin) PlanVirtualHost(vhost *VirtualHost) (string, error) {
+	config, err := render(p.vhost, vhost)
+	if err != nil {
+		return "", err
+	}
+	return diff.File(p.vhostPath(vhost.ServerName), config)
+}
+
+func (p *NginxPlugin) vhostPath(serverName string) string {
+	return filepath.Join(p.config.AvailableDir, serverName+".conf")
+}
+
 // EnableVirtualHost enables a virtual host by creating symlink
-func (p *NginxPlugin) EnableVirtualHost(serverName string) error {
+func (p *NginxPlugin) EnableVirtualHost(ctx context.Context, serverName string) error {
 	source := filepath.Join(p.config.AvailableDir, serverName+".conf")
 	target := filepath.Join(p.config.EnabledDir, serverName+".conf")
 
@@ -130,51 +169,85 @@ func (p *NginxPlugin) EnableVirtualHost(serverName string) error {
 	}
 
 	// Remove existing symlink if any
-	os.Remove(target)
+	p.sandbox.WithContext(ctx).Remove(target)
 
 	// Create symlink
-	if err := os.Symlink(source, target); err != nil {
+	if err := p.sandbox.WithContext(ctx).Symlink(source, target); err != nil {
 		return fmt.Errorf("create symlink: %w", err)
 	}
 
 	if p.config.AutoReload {
-		return p.reload()
+		return p.reload(ctx)
 	}
 
 	return nil
 }
 
 // DisableVirtualHost disables a virtual host
-func (p *NginxPlugin) DisableVirtualHost(serverName string) error {
+func (p *NginxPlugin) DisableVirtualHost(ctx context.Context, serverName string) error {
 	target := filepath.Join(p.config.EnabledDir, serverName+".conf")
 
-	if err := os.Remove(target); err != nil {
+	if err := p.sandbox.WithContext(ctx).Remove(target); err != nil {
 		return fmt.Errorf("remove symlink: %w", err)
 	}
 
 	if p.config.AutoReload {
-		return p.reload()
+		return p.reload(ctx)
 	}
 
 	return nil
 }
 
-// DeleteVirtualHost deletes a virtual host configuration
-func (p *NginxPlugin) DeleteVirtualHost(serverName string) error {
+// DeleteVirtualHost deletes a virtual host configuration, refusing like
+// CreateVirtualHost unless force is set
+func (p *NginxPlugin) DeleteVirtualHost(ctx context.Context, serverName string, force bool) error {
+	configPath := p.vhostPath(serverName)
+	if !force {
+		if err := p.sandbox.CheckManaged(configPath); err != nil {
+			return fmt.Errorf("delete config: %w", err)
+		}
+	}
+
 	// First disable it
-	p.DisableVirtualHost(serverName)
+	p.DisableVirtualHost(ctx, serverName)
 
 	// Then delete the config
-	configPath := filepath.Join(p.config.AvailableDir, serverName+".conf")
-	if err := os.Remove(configPath); err != nil {
+	if err := p.sandbox.WithContext(ctx).RemoveManaged(configPath, true); err != nil {
 		return fmt.Errorf("delete config: %w", err)
 	}
 
 	return nil
 }
 
-func (p *NginxPlugin) testConfig() error {
-	cmd := exec.Command("sh", "-c", p.config.TestCommand)
+// PlanDeleteVirtualHost diffs the removal of a virtual host's config
+func (p *NginxPlugin) PlanDeleteVirtualHost(serverName string) (string, error) {
+	configPath := p.vhostPath(serverName)
+	if _, err := os.Stat(configPath); err != nil {
+		return "", fmt.Errorf("delete config: %w", err)
+	}
+	return diff.File(configPath, nil)
+}
+
+// ListVirtualHosts returns the names of the available virtual hosts and
+// whether each is enabled
+func (p *NginxPlugin) ListVirtualHosts() (map[string]bool, error) {
+	available, err := filepath.Glob(filepath.Join(p.config.AvailableDir, "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+
+	vhosts := make(map[string]bool, len(available))
+	for _, path := range available {
+		name := strings.TrimSuffix(filepath.Base(path), ".conf")
+		_, err := os.Lstat(filepath.Join(p.config.EnabledDir, name+".conf"))
+		vhosts[name] = err == nil
+	}
+	return vhosts, nil
+}
+
+func (p *NginxPlugin) testConfig(ctx context.Context) error {
+	args := strings.Fields(p.config.TestCommand)
+	cmd := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("test failed: %s", output)
@@ -182,8 +255,9 @@ func (p *NginxPlugin) testConfig() error {
 	return nil
 }
 
-func (p *NginxPlugin) reload() error {
-	cmd := exec.Command("sh", "-c", p.config.ReloadCommand)
+func (p *NginxPlugin) reload(ctx context.Context) error {
+	args := strings.Fields(p.config.ReloadCommand)
+	cmd := p.sandbox.WithContext(ctx).Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("reload failed: %s", output)
@@ -192,8 +266,14 @@ func (p *NginxPlugin) reload() error {
 }
 
 // CreateReverseProxy creates a reverse proxy configuration
-func (p *NginxPlugin) CreateReverseProxy(serverName, upstream string, port int) error {
-	vhost := &VirtualHost{
+func (p *NginxPlugin) CreateReverseProxy(ctx context.Context, serverName, upstream string, port int, force bool) error {
+	return p.CreateVirtualHost(ctx, ReverseProxy(serverName, upstream, port), force)
+}
+
+// ReverseProxy is the virtual host CreateReverseProxy creates, for callers
+// that add to it
+func ReverseProxy(serverName, upstream string, port int) *VirtualHost {
+	return &VirtualHost{
 		ServerName: serverName,
 		Listen:     port,
 		ProxyPass:  upstream,
@@ -210,37 +290,71 @@ func (p *NginxPlugin) CreateReverseProxy(serverName, upstream string, port int)
 			},
 		},
 	}
-
-	return p.CreateVirtualHost(vhost)
 }
 
-// CreateLoadBalancer creates a load balancer configuration
-func (p *NginxPlugin) CreateLoadBalancer(name string, backends []string, algorithm string) error {
-	upstreamPath := filepath.Join(p.config.ConfigDir, "conf.d", name+"-upstream.conf")
+// CreateLoadBalancer creates a load balancer configuration, refusing like
+// CreateVirtualHost unless force is set
+func (p *NginxPlugin) CreateLoadBalancer(ctx context.Context, name string, backends []string, algorithm string, force bool) error {
+	upstream, err := render(p.upstream, upstreamData(name, backends, algorithm))
+	if err != nil {
+		return err
+	}
+	if err := p.sandbox.WithContext(ctx).WriteManaged(p.upstreamPath(name), upstream, 0644, force); err != nil {
+		return fmt.Errorf("create upstream: %w", err)
+	}
 
-	tmpl := template.Must(template.New("upstream").Parse(nginxUpstreamTemplate))
+	if p.config.AutoReload {
+		return p.reload(ctx)
+	}
 
-	file, err := os.Create(upstreamPath)
+	return nil
+}
+
+// PlanLoadBalancer diffs the upstream CreateLoadBalancer would write
+func (p *NginxPlugin) PlanLoadBalancer(name string, backends []string, algorithm string) (string, error) {
+	upstream, err := render(p.upstream, upstreamData(name, backends, algorithm))
 	if err != nil {
-		return fmt.Errorf("create upstream: %w", err)
+		return "", err
 	}
-	defer file.Close()
+	return diff.File(p.upstreamPath(name), upstream)
+}
+
+func (p *NginxPlugin) upstreamPath(name string) string {
+	return filepath.Join(p.config.ConfigDir, "conf.d", name+"-upstream.conf")
+}
 
-	data := map[string]interface{}{
+// upstreamData is what the upstream template is executed with
+func upstreamData(name string, backends []string, algorithm string) map[string]interface{} {
+	return map[string]interface{}{
 		"Name":      name,
 		"Backends":  backends,
 		"Algorithm": algorithm,
 	}
+}
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("execute template: %w", err)
-	}
-
-	if p.config.AutoReload {
-		return p.reload()
+// render executes one of the config templates
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
 	}
+	return buf.Bytes(), nil
+}
 
-	return nil
+// sampleVirtualHost sets every field, so a template override that refers to
+// a field that does not exist fails when it is loaded
+var sampleVirtualHost = &VirtualHost{
+	ServerName:   "example.com",
+	Listen:       443,
+	Root:         "/var/www/example",
+	Index:        []string{"index.html"},
+	Locations:    []Location{{Path: "/", ProxyPass: "http://127.0.0.1:8080", Root: "/var/www/example", TryFiles: []string{"$uri"}, Headers: map[string]string{"Host": "$host"}}},
+	SSL:          &SSLConfig{Certificate: "/etc/ssl/example.crt", CertificateKey: "/etc/ssl/example.key", Protocols: []string{"TLSv1.3"}, Ciphers: "HIGH"},
+	UpstreamName: "example",
+	ProxyPass:    "http://127.0.0.1:8080",
+	AccessLog:    "/var/log/nginx/example-access.log",
+	ErrorLog:     "/var/log/nginx/example-error.log",
+	CustomConfig: "client_max_body_size 10m;",
 }
 
 const nginxVhostTemplate = `# Managed by Mandau
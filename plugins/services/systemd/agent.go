@@ -0,0 +1,82 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AgentUnitOptions describe how the Mandau agent is run as a systemd service
+type AgentUnitOptions struct {
+	Name        string   // Unit name, default mandau-agent
+	Command     []string // Agent binary followed by its flags
+	User        string
+	Group       string
+	PIDFile     string
+	WatchdogSec int      // 0 disables the watchdog
+	WritePaths  []string // Paths the agent may write under ProtectSystem=strict
+}
+
+// AgentUnit returns the unit for the agent: a notify service that reports
+// readiness and feeds the watchdog, reloads on SIGHUP and restarts on
+// failure
+func AgentUnit(opts AgentUnitOptions) *ServiceUnit {
+	name := opts.Name
+	if name == "" {
+		name = "mandau-agent"
+	}
+
+	service := []string{"NotifyAccess=main"}
+	if opts.WatchdogSec > 0 {
+		service = append(service, fmt.Sprintf("WatchdogSec=%d", opts.WatchdogSec))
+	}
+	if opts.PIDFile != "" {
+		service = append(service, "PIDFile="+opts.PIDFile)
+	}
+
+	return &ServiceUnit{
+		Name:            name,
+		Description:     "Mandau Infrastructure Agent",
+		After:           []string{"network-online.target", "docker.service"},
+		Requires:        []string{"docker.service"},
+		Type:            "notify",
+		User:            opts.User,
+		Group:           opts.Group,
+		ExecStart:       execLine(opts.Command),
+		ExecReload:      "/bin/kill -HUP $MAINPID",
+		Restart:         "on-failure",
+		RestartSec:      10,
+		LimitNOFILE:     65536,
+		PrivateTmp:      true,
+		ProtectSystem:   "strict",
+		NoNewPrivileges: true,
+		ReadWritePaths:  opts.WritePaths,
+		CustomService:   strings.Join(service, "\n"),
+	}
+}
+
+// InstallAgent writes the agent unit and enables it to start at boot
+func (p *SystemdPlugin) InstallAgent(ctx context.Context, opts AgentUnitOptions) error {
+	if len(opts.Command) == 0 {
+		return fmt.Errorf("agent command is required")
+	}
+
+	unit := AgentUnit(opts)
+	if err := p.CreateService(ctx, unit, false); err != nil {
+		return err
+	}
+	return p.EnableService(ctx, unit.Name)
+}
+
+// execLine joins args for ExecStart, quoting those systemd would split
+func execLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'\\") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -1,13 +1,15 @@
 package systemd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/bhangun/mandau/pkg/diff"
 	"github.com/bhangun/mandau/pkg/plugin"
 )
 
@@ -15,11 +17,15 @@ type SystemdPlugin struct {
 	name    string
 	version string
 	config  *SystemdConfig
+	sandbox *plugin.Sandbox
+	unit    *template.Template
 }
 
 type SystemdConfig struct {
 	UnitDir      string
 	SystemctlCmd string
+	TemplateDir  string // Override of service.tmpl
+	EnvDir       string // Environment files of template instances
 }
 
 type ServiceUnit struct {
@@ -35,9 +41,11 @@ type ServiceUnit struct {
 	ExecStop    string
 	ExecReload  string
 	Environment map[string]string
-	Restart     string
-	RestartSec  int
-	KillMode    string
+	// File of KEY=value lines; a leading '-' allows it to be missing
+	EnvironmentFile string
+	Restart         string
+	RestartSec      int
+	KillMode        string
 	// Resource limits
 	LimitNOFILE int
 	LimitNPROC  int
@@ -51,7 +59,7 @@ type ServiceUnit struct {
 	ReadWritePaths    []string
 	ReadOnlyPaths     []string
 	InaccessiblePaths []string
-	// Custom sections
+	// Custom sections, extra lines at the end of each
 	CustomUnit    string
 	CustomService string
 	CustomInstall string
@@ -75,38 +83,73 @@ func (p *SystemdPlugin) Init(ctx context.Context, config map[string]interface{})
 	p.config = &SystemdConfig{
 		UnitDir:      "/etc/systemd/system",
 		SystemctlCmd: "systemctl",
+		TemplateDir:  plugin.GetStringConfig(config, "template_dir"),
+		EnvDir:       plugin.GetStringConfig(config, "env_dir"),
+	}
+	if p.config.EnvDir == "" {
+		p.config.EnvDir = "/etc/mandau/units"
 	}
 
-	return nil
+	var err error
+	p.unit, err = plugin.LoadTemplate(p.config.TemplateDir, "service.tmpl", systemdServiceTemplate, sampleUnit)
+	return err
 }
 
 func (p *SystemdPlugin) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// CreateService creates a systemd service unit
-func (p *SystemdPlugin) CreateService(unit *ServiceUnit) error {
-	unitPath := filepath.Join(p.config.UnitDir, unit.Name+".service")
+// Permissions covers systemctl, the unit directory and the environment
+// files of template instances
+func (p *SystemdPlugin) Permissions() plugin.Permissions {
+	return plugin.Permissions{
+		Exec:  []string{p.config.SystemctlCmd},
+		Write: []string{p.config.UnitDir, p.config.EnvDir},
+	}
+}
 
-	tmpl := template.Must(template.New("service").Parse(systemdServiceTemplate))
+func (p *SystemdPlugin) SetSandbox(sandbox *plugin.Sandbox) { p.sandbox = sandbox }
 
-	file, err := os.Create(unitPath)
+// CreateService creates a systemd service unit. Unless force is set, a unit
+// Mandau did not write or that was edited since is left alone.
+func (p *SystemdPlugin) CreateService(ctx context.Context, unit *ServiceUnit, force bool) error {
+	content, err := p.renderUnit(unit)
 	if err != nil {
+		return err
+	}
+	if err := p.sandbox.WithContext(ctx).WriteManaged(p.unitPath(unit.Name), content, 0644, force); err != nil {
 		return fmt.Errorf("create unit: %w", err)
 	}
-	defer file.Close()
 
-	if err := tmpl.Execute(file, unit); err != nil {
-		return fmt.Errorf("execute template: %w", err)
+	// Reload systemd
+	return p.daemonReload(ctx)
+}
+
+// PlanService diffs the unit CreateService would write against the current
+// one, without writing it
+func (p *SystemdPlugin) PlanService(unit *ServiceUnit) (string, error) {
+	content, err := p.renderUnit(unit)
+	if err != nil {
+		return "", err
 	}
+	return diff.File(p.unitPath(unit.Name), content)
+}
 
-	// Reload systemd
-	return p.daemonReload()
+func (p *SystemdPlugin) unitPath(name string) string {
+	return filepath.Join(p.config.UnitDir, name+".service")
+}
+
+func (p *SystemdPlugin) renderUnit(unit *ServiceUnit) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := p.unit.Execute(&buf, unit); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 // EnableService enables a systemd service
-func (p *SystemdPlugin) EnableService(serviceName string) error {
-	cmd := exec.Command(p.config.SystemctlCmd, "enable", serviceName)
+func (p *SystemdPlugin) EnableService(ctx context.Context, serviceName string) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "enable", serviceName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("enable failed: %s", output)
@@ -115,8 +158,8 @@ func (p *SystemdPlugin) EnableService(serviceName string) error {
 }
 
 // DisableService disables a systemd service
-func (p *SystemdPlugin) DisableService(serviceName string) error {
-	cmd := exec.Command(p.config.SystemctlCmd, "disable", serviceName)
+func (p *SystemdPlugin) DisableService(ctx context.Context, serviceName string) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "disable", serviceName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("disable failed: %s", output)
@@ -125,8 +168,8 @@ func (p *SystemdPlugin) DisableService(serviceName string) error {
 }
 
 // StartService starts a systemd service
-func (p *SystemdPlugin) StartService(serviceName string) error {
-	cmd := exec.Command(p.config.SystemctlCmd, "start", serviceName)
+func (p *SystemdPlugin) StartService(ctx context.Context, serviceName string) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "start", serviceName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("start failed: %s", output)
@@ -135,8 +178,8 @@ func (p *SystemdPlugin) StartService(serviceName string) error {
 }
 
 // StopService stops a systemd service
-func (p *SystemdPlugin) StopService(serviceName string) error {
-	cmd := exec.Command(p.config.SystemctlCmd, "stop", serviceName)
+func (p *SystemdPlugin) StopService(ctx context.Context, serviceName string) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "stop", serviceName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("stop failed: %s", output)
@@ -145,8 +188,8 @@ func (p *SystemdPlugin) StopService(serviceName string) error {
 }
 
 // RestartService restarts a systemd service
-func (p *SystemdPlugin) RestartService(serviceName string) error {
-	cmd := exec.Command(p.config.SystemctlCmd, "restart", serviceName)
+func (p *SystemdPlugin) RestartService(ctx context.Context, serviceName string) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "restart", serviceName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("restart failed: %s", output)
@@ -154,9 +197,30 @@ func (p *SystemdPlugin) RestartService(serviceName string) error {
 	return nil
 }
 
+// RemoveService stops and disables a service and deletes its unit file,
+// refusing like CreateService unless force is set. Stopping and disabling
+// are best effort, so a half-created service can still be removed.
+func (p *SystemdPlugin) RemoveService(ctx context.Context, serviceName string, force bool) error {
+	unitPath := p.unitPath(serviceName)
+	if !force {
+		if err := p.sandbox.CheckManaged(unitPath); err != nil {
+			return fmt.Errorf("remove unit: %w", err)
+		}
+	}
+
+	p.StopService(ctx, serviceName)
+	p.DisableService(ctx, serviceName)
+
+	if err := p.sandbox.WithContext(ctx).RemoveManaged(unitPath, true); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove unit: %w", err)
+	}
+
+	return p.daemonReload(ctx)
+}
+
 // GetServiceStatus returns the status of a service
-func (p *SystemdPlugin) GetServiceStatus(serviceName string) (string, error) {
-	cmd := exec.Command(p.config.SystemctlCmd, "is-active", serviceName)
+func (p *SystemdPlugin) GetServiceStatus(ctx context.Context, serviceName string) (string, error) {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "is-active", serviceName)
 	output, err := cmd.Output()
 	if err != nil {
 		return "unknown", nil
@@ -164,8 +228,26 @@ func (p *SystemdPlugin) GetServiceStatus(serviceName string) (string, error) {
 	return string(output), nil
 }
 
-func (p *SystemdPlugin) daemonReload() error {
-	cmd := exec.Command(p.config.SystemctlCmd, "daemon-reload")
+// ListServices returns the names of the units Mandau manages
+func (p *SystemdPlugin) ListServices() ([]string, error) {
+	units, err := filepath.Glob(filepath.Join(p.config.UnitDir, "*.service"))
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, path := range units {
+		content, err := os.ReadFile(path)
+		if err != nil || !strings.HasPrefix(string(content), "# Managed by Mandau") {
+			continue
+		}
+		services = append(services, strings.TrimSuffix(filepath.Base(path), ".service"))
+	}
+	return services, nil
+}
+
+func (p *SystemdPlugin) daemonReload(ctx context.Context) error {
+	cmd := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "daemon-reload")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("daemon-reload failed: %s", output)
@@ -173,6 +255,41 @@ func (p *SystemdPlugin) daemonReload() error {
 	return nil
 }
 
+// sampleUnit sets every field, so a template override that refers to a
+// field that does not exist fails when it is loaded
+var sampleUnit = &ServiceUnit{
+	Name:              "example",
+	Description:       "Example service",
+	After:             []string{"network.target"},
+	Requires:          []string{"network-online.target"},
+	Type:              "simple",
+	User:              "example",
+	Group:             "example",
+	WorkingDir:        "/srv/example",
+	ExecStart:         "/usr/bin/example",
+	ExecStop:          "/bin/kill -TERM $MAINPID",
+	ExecReload:        "/bin/kill -HUP $MAINPID",
+	Environment:       map[string]string{"PORT": "8080"},
+	EnvironmentFile:   "-/etc/mandau/units/example/%i.env",
+	Restart:           "always",
+	RestartSec:        5,
+	KillMode:          "process",
+	LimitNOFILE:       65536,
+	LimitNPROC:        4096,
+	CPUQuota:          "50%",
+	MemoryLimit:       "512M",
+	PrivateTmp:        true,
+	ProtectSystem:     "strict",
+	ProtectHome:       true,
+	NoNewPrivileges:   true,
+	ReadWritePaths:    []string{"/srv/example/data"},
+	ReadOnlyPaths:     []string{"/etc/example"},
+	InaccessiblePaths: []string{"/root"},
+	CustomUnit:        "StartLimitBurst=3",
+	CustomService:     "TimeoutStopSec=30",
+	CustomInstall:     "Alias=example-alias.service",
+}
+
 const systemdServiceTemplate = `# Managed by Mandau
 [Unit]
 Description={{.Description}}
@@ -193,6 +310,7 @@ ExecStart={{.ExecStart}}
 {{range $key, $value := .Environment}}
 Environment="{{$key}}={{$value}}"
 {{end}}
+{{if .EnvironmentFile}}EnvironmentFile={{.EnvironmentFile}}{{end}}
 
 {{if .Restart}}Restart={{.Restart}}{{end}}
 {{if .RestartSec}}RestartSec={{.RestartSec}}{{end}}
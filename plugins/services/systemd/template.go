@@ -0,0 +1,201 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bhangun/mandau/pkg/diff"
+	"github.com/bhangun/mandau/pkg/plugin"
+)
+
+// Instance is a unit started from a template, such as worker@2.service
+type Instance struct {
+	Name        string // worker@2
+	Instance    string // 2
+	State       string // active, inactive, failed...
+	Enabled     bool
+	Environment map[string]string // From the instance's environment file
+}
+
+// CreateTemplate creates the template unit <name>@.service. Each instance
+// reads its environment from a file of its own, so instances of one
+// template can differ in configuration.
+func (p *SystemdPlugin) CreateTemplate(ctx context.Context, unit *ServiceUnit, force bool) error {
+	return p.CreateService(ctx, p.templateUnit(unit), force)
+}
+
+// PlanTemplate diffs the unit CreateTemplate would write against the
+// current one, without writing it
+func (p *SystemdPlugin) PlanTemplate(unit *ServiceUnit) (string, error) {
+	t := p.templateUnit(unit)
+	content, err := p.renderUnit(t)
+	if err != nil {
+		return "", err
+	}
+	return diff.File(p.unitPath(t.Name), content)
+}
+
+func (p *SystemdPlugin) templateUnit(unit *ServiceUnit) *ServiceUnit {
+	t := *unit
+	t.Name = unit.Name + "@"
+	// The leading '-' lets instances without a file start
+	t.EnvironmentFile = "-" + p.envFile(unit.Name, "%i")
+	return &t
+}
+
+// envFile is where the instance of template reads its environment from
+func (p *SystemdPlugin) envFile(template, instance string) string {
+	return filepath.Join(p.config.EnvDir, template, instance+".env")
+}
+
+// ListInstances returns the instances of template that are loaded, enabled
+// or have an environment file
+func (p *SystemdPlugin) ListInstances(ctx context.Context, template string) ([]Instance, error) {
+	prefix := template + "@"
+	instances := make(map[string]*Instance)
+	instance := func(name string) *Instance {
+		if i, ok := instances[name]; ok {
+			return i
+		}
+		i := &Instance{Name: prefix + name, Instance: name, State: "inactive"}
+		instances[name] = i
+		return i
+	}
+
+	out, err := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "list-units", "--all", "--plain", "--no-legend", "--no-pager", prefix+"*.service").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list units: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], prefix) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(fields[0], prefix), ".service")
+		instance(name).State = fields[2]
+	}
+
+	wants, _ := filepath.Glob(filepath.Join(p.config.UnitDir, "*.wants", prefix+"*.service"))
+	for _, link := range wants {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(link), prefix), ".service")
+		instance(name).Enabled = true
+	}
+
+	envFiles, _ := filepath.Glob(p.envFile(template, "*"))
+	for _, file := range envFiles {
+		env, err := readEnvFile(file)
+		if err != nil {
+			return nil, err
+		}
+		instance(strings.TrimSuffix(filepath.Base(file), ".env")).Environment = env
+	}
+
+	list := make([]Instance, 0, len(instances))
+	for _, i := range instances {
+		list = append(list, *i)
+	}
+	sort.Slice(list, func(i, j int) bool { return instanceLess(list[i].Instance, list[j].Instance) })
+	return list, nil
+}
+
+// SetInstanceEnvironment replaces the environment file of an instance,
+// which takes effect when the instance is next started. An empty env
+// removes the file.
+func (p *SystemdPlugin) SetInstanceEnvironment(ctx context.Context, template, instance string, env map[string]string, force bool) error {
+	file := p.envFile(template, instance)
+	if len(env) == 0 {
+		return p.sandbox.WithContext(ctx).RemoveManaged(file, force)
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", plugin.ManagedMarker)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, strconv.Quote(env[key]))
+	}
+
+	if err := p.sandbox.WithContext(ctx).MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	// Environment files often hold credentials
+	return p.sandbox.WithContext(ctx).WriteManaged(file, []byte(b.String()), 0600, force)
+}
+
+// ScaleInstances runs instances 1 to count of template, enabled so they
+// start at boot, and stops and disables the numbered instances above
+// count. Instances with other names are left alone.
+func (p *SystemdPlugin) ScaleInstances(ctx context.Context, template string, count int) (started, stopped []string, err error) {
+	current, err := p.ListInstances(ctx, template)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for n := 1; n <= count; n++ {
+		name := fmt.Sprintf("%s@%d", template, n)
+		if out, err := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "enable", "--now", name).CombinedOutput(); err != nil {
+			return started, stopped, fmt.Errorf("start %s failed: %s", name, out)
+		}
+		started = append(started, name)
+	}
+
+	for _, i := range current {
+		n, err := strconv.Atoi(i.Instance)
+		if err != nil || n <= count {
+			continue
+		}
+		if out, err := p.sandbox.WithContext(ctx).Command(p.config.SystemctlCmd, "disable", "--now", i.Name).CombinedOutput(); err != nil {
+			return started, stopped, fmt.Errorf("stop %s failed: %s", i.Name, out)
+		}
+		stopped = append(stopped, i.Name)
+	}
+	return started, stopped, nil
+}
+
+// readEnvFile reads the KEY=value lines of a systemd environment file
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `'"`)
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, nil
+}
+
+// instanceLess orders numbered instances by number, before named ones
+func instanceLess(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil || errB == nil:
+		return errA == nil
+	}
+	return a < b
+}
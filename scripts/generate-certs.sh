@@ -35,7 +35,7 @@ echo "Generating Agent certificate..."
 openssl genrsa -out "$CERT_DIR/agent.key" 4096
 openssl req -new -key "$CERT_DIR/agent.key" \
   -out "$CERT_DIR/agent.csr" \
-  -subj "/CN=mandau-agent/O=Mandau/C=US"
+  -subj "/CN=mandau-agent/O=Mandau/OU=agent/C=US"
 
 cat > "$CERT_DIR/agent.ext" <<EOF
 subjectAltName = DNS:mandau-agent,DNS:localhost,IP:127.0.0.1
@@ -51,7 +51,7 @@ echo "Generating CLI client certificate..."
 openssl genrsa -out "$CERT_DIR/client.key" 4096
 openssl req -new -key "$CERT_DIR/client.key" \
   -out "$CERT_DIR/client.csr" \
-  -subj "/CN=mandau-cli/O=Mandau/C=US"
+  -subj "/CN=mandau-cli/O=Mandau/OU=user/C=US"
 
 cat > "$CERT_DIR/client.ext" <<EOF
 extendedKeyUsage = clientAuth
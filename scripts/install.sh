@@ -131,7 +131,7 @@ EOF
     openssl genrsa -out "$cert_dir/agent.key" 4096
     openssl req -new -key "$cert_dir/agent.key" \
         -out "$cert_dir/agent.csr" \
-        -subj "/CN=mandau-agent/O=Mandau/C=US" -nodes
+        -subj "/CN=mandau-agent/O=Mandau/OU=agent/C=US" -nodes
 
     cat > "$cert_dir/agent.ext" <<EOF
 subjectAltName = DNS:mandau-agent,DNS:localhost,IP:127.0.0.1
@@ -147,7 +147,7 @@ EOF
     openssl genrsa -out "$cert_dir/client.key" 4096
     openssl req -new -key "$cert_dir/client.key" \
         -out "$cert_dir/client.csr" \
-        -subj "/CN=mandau-cli/O=Mandau/C=US" -nodes
+        -subj "/CN=mandau-cli/O=Mandau/OU=user/C=US" -nodes
 
     cat > "$cert_dir/client.ext" <<EOF
 extendedKeyUsage = clientAuth